@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// defaultMaxBatchBytes bounds the uncompressed JSON size of a batch.
+	defaultMaxBatchBytes = 256 * 1024
+	// maxBatchSamples matches the control plane's per-request sample limit.
+	maxBatchSamples = 10000
+	// batchEnvelopeBytes is reserved for the agent_id/pair_key envelope.
+	batchEnvelopeBytes = 512
+
+	// defaultMaxRetryBytes bounds the encoded size of the batches kept for
+	// resending while the control plane is unreachable, a day or more of
+	// samples at the default interval.
+	defaultMaxRetryBytes = 16 << 20
+	// retryBackoffMin and retryBackoffMax bound the wait between attempts
+	// to send queued batches, which doubles with every failed attempt.
+	retryBackoffMin = time.Second
+	retryBackoffMax = time.Minute
+)
+
+// metricsBatcher buffers samples and posts them to the control plane in
+// batches. A batch is sent when the flush interval elapses or when adding a
+// sample would push the encoded batch past maxBytes. Batches that fail to
+// send are queued and resent in order once the control plane is reachable
+// again; samples carry sequence numbers so it can tell which were lost. It
+// is not safe for concurrent use; collectAndSend owns it.
+type metricsBatcher struct {
+	client  *http.Client
+	baseURL string
+	token   string
+	agentID string
+	pairKey string
+
+	maxBytes int
+	gzip     bool
+
+	samples []metricsSample
+	size    int // encoded size of the buffered samples, including separators
+
+	// clock converts local times to the control plane's clock; it is
+	// updated from the server time in every response. Nil keeps local time.
+	clock *clockSync
+
+	seq uint64 // sequence number of the last sample added
+
+	// queued holds the batches waiting to be sent, oldest first, and
+	// queuedSize their encoded size. Beyond maxRetryBytes the oldest are
+	// dropped. After a failed send, nothing is sent before retryAt.
+	queued        []queuedBatch
+	queuedSize    int
+	maxRetryBytes int
+	backoff       time.Duration
+	retryAt       time.Time
+	now           func() time.Time
+}
+
+// queuedBatch is a batch waiting to be sent.
+type queuedBatch struct {
+	samples []metricsSample
+	size    int
+}
+
+func newMetricsBatcher(baseURL, token, agentID, pairKey string, maxBytes int, useGzip bool) *metricsBatcher {
+	if maxBytes <= 0 {
+		maxBytes = defaultMaxBatchBytes
+	}
+	return &metricsBatcher{
+		client:   http.DefaultClient,
+		baseURL:  baseURL,
+		token:    token,
+		agentID:  agentID,
+		pairKey:  pairKey,
+		maxBytes: maxBytes,
+		gzip:     useGzip,
+
+		maxRetryBytes: defaultMaxRetryBytes,
+		now:           time.Now,
+	}
+}
+
+// add numbers and buffers a sample, flushing the current batch first if the
+// sample would not fit. A single sample larger than the budget is sent on
+// its own.
+func (b *metricsBatcher) add(ctx context.Context, sample metricsSample) error {
+	b.seq++
+	sample.Seq = b.seq
+	encoded, err := json.Marshal(sample)
+	if err != nil {
+		return fmt.Errorf("encode sample: %w", err)
+	}
+	sampleSize := len(encoded) + 1
+
+	var flushErr error
+	if len(b.samples) > 0 && (batchEnvelopeBytes+b.size+sampleSize > b.maxBytes || len(b.samples) >= maxBatchSamples) {
+		flushErr = b.flush(ctx)
+	}
+
+	b.samples = append(b.samples, sample)
+	b.size += sampleSize
+	return flushErr
+}
+
+// pending returns the number of samples not sent yet, queued ones included.
+func (b *metricsBatcher) pending() int {
+	n := len(b.samples)
+	for _, batch := range b.queued {
+		n += len(batch.samples)
+	}
+	return n
+}
+
+// flush queues the buffered samples as a batch and sends the queued
+// batches, oldest first, unless a failed send is still backing off. A batch
+// that fails to send stays queued; one the control plane rejects, such as
+// with 400 Bad Request, is dropped, as resending it cannot succeed.
+func (b *metricsBatcher) flush(ctx context.Context) error {
+	b.enqueue()
+	if b.now().Before(b.retryAt) {
+		return nil
+	}
+	return b.sendQueued(ctx)
+}
+
+// enqueue moves the buffered samples to the queue, dropping the oldest
+// queued batches, all but the new one, while the queue is over
+// maxRetryBytes.
+func (b *metricsBatcher) enqueue() {
+	if len(b.samples) == 0 {
+		return
+	}
+	b.queued = append(b.queued, queuedBatch{samples: b.samples, size: b.size})
+	b.queuedSize += b.size
+	b.samples = nil
+	b.size = 0
+
+	dropped := 0
+	for len(b.queued) > 1 && b.queuedSize > b.maxRetryBytes {
+		dropped += len(b.queued[0].samples)
+		b.queuedSize -= b.queued[0].size
+		b.queued = b.queued[1:]
+	}
+	if dropped > 0 {
+		log.Printf("Warning: dropped %d unsent samples; the retry buffer is full", dropped)
+	}
+}
+
+// sendQueued sends the queued batches in order, stopping at the first that
+// fails to send and backing off before the next attempt.
+func (b *metricsBatcher) sendQueued(ctx context.Context) error {
+	for len(b.queued) > 0 {
+		batch := b.queued[0]
+		err := b.send(ctx, batch.samples)
+		if err != nil && retryable(err) {
+			b.backoff = min(max(2*b.backoff, retryBackoffMin), retryBackoffMax)
+			b.retryAt = b.now().Add(b.backoff)
+			return fmt.Errorf("send %d samples (%d queued, retrying in %s): %w", len(batch.samples), b.pending(), b.backoff, err)
+		}
+		b.queued = b.queued[1:]
+		b.queuedSize -= batch.size
+		b.backoff = 0
+		b.retryAt = time.Time{}
+		if err != nil {
+			return fmt.Errorf("send %d samples, dropped: %w", len(batch.samples), err)
+		}
+	}
+	return nil
+}
+
+func (b *metricsBatcher) send(ctx context.Context, samples []metricsSample) (err error) {
+	ctx, span := otel.GetGlobalTracer().StartSpan(ctx, "mcpdrill.agent.flush",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.Int("mcpdrill.samples", len(samples))),
+	)
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	err = postBatch(ctx, b.client, b.baseURL+"/agents/v1/metrics", b.token, b.gzip, metricsRequest{
+		AgentID:       b.agentID,
+		PairKey:       b.pairKey,
+		Samples:       samples,
+		SentAt:        b.clock.now(time.Now().UnixMilli()),
+		ClockOffsetMs: b.clock.offset(),
+		ClockDriftPPM: b.clock.drift(),
+	}, b.clock)
+	return err
+}
+
+// statusError is a non-200 response to a batch.
+type statusError struct {
+	code   int
+	status string
+}
+
+func (e *statusError) Error() string {
+	return "control plane returned " + e.status
+}
+
+// retryable reports whether sending a batch again may succeed: after
+// network errors, server errors, timeouts and rate limiting.
+func retryable(err error) bool {
+	var se *statusError
+	if !errors.As(err, &se) {
+		return true
+	}
+	return se.code >= 500 || se.code == http.StatusRequestTimeout || se.code == http.StatusTooManyRequests
+}
+
+// postBatch posts payload as JSON, gzip-compressed if useGzip, and feeds the
+// server time in the response to clock.
+func postBatch(ctx context.Context, client *http.Client, url, token string, useGzip bool, payload any, clock *clockSync) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode batch: %w", err)
+	}
+
+	if useGzip {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(body); err != nil {
+			return fmt.Errorf("compress batch: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return fmt.Errorf("compress batch: %w", err)
+		}
+		body = buf.Bytes()
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if useGzip {
+		httpReq.Header.Set("Content-Encoding", "gzip")
+	}
+	if token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+	otel.InjectHeaders(ctx, httpReq.Header, otel.GetGlobalTracer())
+
+	beforeMs := time.Now().UnixMilli()
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	afterMs := time.Now().UnixMilli()
+
+	if resp.StatusCode != http.StatusOK {
+		return &statusError{code: resp.StatusCode, status: resp.Status}
+	}
+	var result batchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err == nil {
+		clock.observe(beforeMs, afterMs, result.ServerTime)
+	}
+	return nil
+}
+
+// flushOnShutdown sends any buffered and queued samples after the agent's
+// context has been cancelled, bounded by timeout and regardless of backoff.
+func (b *metricsBatcher) flushOnShutdown(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	b.enqueue()
+	return b.sendQueued(ctx)
+}
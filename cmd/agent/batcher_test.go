@@ -0,0 +1,224 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// batchRecorder is a fake control plane that records received batches.
+type batchRecorder struct {
+	mu        sync.Mutex
+	batches   [][]metricsSample
+	encodings []string
+}
+
+func (r *batchRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var body io.Reader = req.Body
+	if req.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(req.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		body = zr
+	}
+	var mr metricsRequest
+	if err := json.NewDecoder(body).Decode(&mr); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.mu.Lock()
+	r.batches = append(r.batches, mr.Samples)
+	r.encodings = append(r.encodings, req.Header.Get("Content-Encoding"))
+	r.mu.Unlock()
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestMetricsBatcher_FlushSendsGzipBatch(t *testing.T) {
+	rec := &batchRecorder{}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	b := newMetricsBatcher(srv.URL, "", "agent_1", "pair", 0, true)
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		if err := b.add(ctx, metricsSample{Timestamp: int64(i)}); err != nil {
+			t.Fatalf("add failed: %v", err)
+		}
+	}
+	if len(rec.batches) != 0 {
+		t.Fatalf("expected no requests before flush, got %d", len(rec.batches))
+	}
+	if err := b.flush(ctx); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	if len(rec.batches) != 1 || len(rec.batches[0]) != 5 {
+		t.Fatalf("expected one batch of 5 samples, got %v", rec.batches)
+	}
+	if rec.encodings[0] != "gzip" {
+		t.Errorf("expected gzip encoding, got %q", rec.encodings[0])
+	}
+	if b.pending() != 0 {
+		t.Errorf("expected empty buffer after flush, got %d", b.pending())
+	}
+	if err := b.flush(ctx); err != nil || len(rec.batches) != 1 {
+		t.Errorf("expected empty flush to send nothing, got err=%v batches=%d", err, len(rec.batches))
+	}
+}
+
+func TestMetricsBatcher_SizeBudget(t *testing.T) {
+	rec := &batchRecorder{}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	// add numbers the samples; single-digit sequence numbers encode alike.
+	sample := metricsSample{Timestamp: 1700000000000, Seq: 1}
+	encoded, _ := json.Marshal(sample)
+	// Room for the envelope plus three samples.
+	budget := batchEnvelopeBytes + 3*(len(encoded)+1)
+
+	b := newMetricsBatcher(srv.URL, "", "agent_1", "pair", budget, false)
+	ctx := context.Background()
+	for i := 0; i < 7; i++ {
+		if err := b.add(ctx, sample); err != nil {
+			t.Fatalf("add failed: %v", err)
+		}
+	}
+
+	if len(rec.batches) != 2 {
+		t.Fatalf("expected 2 full batches sent, got %d", len(rec.batches))
+	}
+	for i, batch := range rec.batches {
+		if len(batch) != 3 {
+			t.Errorf("batch %d: expected 3 samples, got %d", i, len(batch))
+		}
+		if rec.encodings[i] != "" {
+			t.Errorf("batch %d: expected no encoding, got %q", i, rec.encodings[i])
+		}
+	}
+	if b.pending() != 1 {
+		t.Errorf("expected 1 pending sample, got %d", b.pending())
+	}
+}
+
+func TestMetricsBatcher_RetriesAfterOutage(t *testing.T) {
+	rec := &batchRecorder{}
+	var down atomic.Bool
+	down.Store(true)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		rec.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	now := time.Unix(1700000000, 0)
+	b := newMetricsBatcher(srv.URL, "", "agent_1", "pair", 0, true)
+	b.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_ = b.add(ctx, metricsSample{Timestamp: 1})
+	if err := b.flush(ctx); err == nil {
+		t.Fatal("expected flush error for 503 response")
+	}
+	if b.pending() != 1 || b.backoff != retryBackoffMin {
+		t.Fatalf("expected the batch queued with %s backoff, got %d pending, %s", retryBackoffMin, b.pending(), b.backoff)
+	}
+
+	// Within the backoff, flushes only queue.
+	_ = b.add(ctx, metricsSample{Timestamp: 2})
+	if err := b.flush(ctx); err != nil {
+		t.Fatalf("expected no attempt during backoff, got %v", err)
+	}
+	now = now.Add(retryBackoffMin)
+	if err := b.flush(ctx); err == nil || b.backoff != 2*retryBackoffMin {
+		t.Fatalf("expected a failed retry doubling the backoff, got %v, %s", err, b.backoff)
+	}
+
+	down.Store(false)
+	now = now.Add(b.backoff)
+	_ = b.add(ctx, metricsSample{Timestamp: 3})
+	if err := b.flush(ctx); err != nil {
+		t.Fatalf("flush after recovery failed: %v", err)
+	}
+	if b.pending() != 0 || b.backoff != 0 {
+		t.Errorf("expected the queue drained and backoff reset, got %d pending, %s", b.pending(), b.backoff)
+	}
+	var seqs []uint64
+	for _, batch := range rec.batches {
+		for _, s := range batch {
+			seqs = append(seqs, s.Seq)
+		}
+	}
+	if len(rec.batches) != 3 || !reflect.DeepEqual(seqs, []uint64{1, 2, 3}) {
+		t.Errorf("expected 3 batches resent in order, got %d batches with seqs %v", len(rec.batches), seqs)
+	}
+}
+
+func TestMetricsBatcher_RejectedBatchDropped(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	b := newMetricsBatcher(srv.URL, "", "agent_1", "pair", 0, true)
+	ctx := context.Background()
+	_ = b.add(ctx, metricsSample{Timestamp: 1})
+	if err := b.flush(ctx); err == nil {
+		t.Error("expected flush error for 400 response")
+	}
+	if b.pending() != 0 || !b.retryAt.IsZero() {
+		t.Errorf("expected a rejected batch dropped without backoff, got %d pending", b.pending())
+	}
+}
+
+func TestMetricsBatcher_RetryBufferBound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	sample := metricsSample{Timestamp: 1700000000000, Seq: 1}
+	encoded, _ := json.Marshal(sample)
+	b := newMetricsBatcher(srv.URL, "", "agent_1", "pair", 0, false)
+	b.maxRetryBytes = 2 * (len(encoded) + 1)
+	b.now = func() time.Time { return time.Unix(0, 0) }
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		_ = b.add(ctx, sample)
+		_ = b.flush(ctx)
+	}
+	if b.pending() != 2 || b.queued[0].samples[0].Seq != 3 {
+		t.Errorf("expected the two newest samples kept, got %d pending", b.pending())
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	for _, tt := range []struct {
+		err  error
+		want bool
+	}{
+		{errors.New("connection refused"), true},
+		{&statusError{code: http.StatusServiceUnavailable}, true},
+		{&statusError{code: http.StatusTooManyRequests}, true},
+		{&statusError{code: http.StatusRequestEntityTooLarge}, false},
+		{&statusError{code: http.StatusUnauthorized}, false},
+	} {
+		if got := retryable(tt.err); got != tt.want {
+			t.Errorf("retryable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
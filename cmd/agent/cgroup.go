@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/agent"
+)
+
+const (
+	defaultProcRoot   = "/proc"
+	defaultCgroupRoot = "/sys/fs/cgroup"
+
+	// cgroupUnlimited is the smallest v1 limit read as "no limit"; the
+	// kernel reports an unset limit as the largest page-aligned int64.
+	cgroupUnlimited = 1 << 60
+)
+
+// containerIDPattern matches the 64-hex-digit IDs Docker, containerd and
+// CRI-O put in cgroup paths.
+var containerIDPattern = regexp.MustCompile(`[0-9a-f]{64}`)
+
+// cgroupPaths locates the cgroup directories of a process. With cgroup v2
+// all controllers share unified; with v1 each has its own hierarchy.
+type cgroupPaths struct {
+	version int
+	unified string
+	cpu     string
+	cpuacct string
+	memory  string
+}
+
+// findCgroup reads /proc/<pid>/cgroup. On hybrid hosts the v1 hierarchies
+// are used, as the unified one has no controllers there.
+func findCgroup(procRoot, cgroupRoot string, pid int) (*cgroupPaths, error) {
+	f, err := os.Open(filepath.Join(procRoot, strconv.Itoa(pid), "cgroup"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	paths := &cgroupPaths{}
+	var unified string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// hierarchy-ID:controller-list:path
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if parts[0] == "0" && parts[1] == "" {
+			unified = filepath.Join(cgroupRoot, parts[2])
+			continue
+		}
+		dir := filepath.Join(cgroupRoot, parts[1], parts[2])
+		for _, controller := range strings.Split(parts[1], ",") {
+			switch controller {
+			case "cpu":
+				paths.cpu = dir
+			case "cpuacct":
+				paths.cpuacct = dir
+			case "memory":
+				paths.memory = dir
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case paths.cpu != "" || paths.memory != "":
+		paths.version = 1
+	case unified != "":
+		paths.version = 2
+		paths.unified = unified
+	default:
+		return nil, errors.New("no cgroup found")
+	}
+	return paths, nil
+}
+
+// containerID returns the container ID in the cgroup paths, if any.
+func (p *cgroupPaths) containerID() string {
+	for _, dir := range []string{p.unified, p.memory, p.cpu} {
+		if ids := containerIDPattern.FindAllString(dir, -1); len(ids) > 0 {
+			return ids[len(ids)-1]
+		}
+	}
+	return ""
+}
+
+// cgroupCollector samples the cgroup of the monitored process, turning the
+// kernel's lifetime CPU counters into rates since its previous sample. It is
+// not safe for concurrent use; collectAndSend owns it.
+type cgroupCollector struct {
+	procRoot   string
+	cgroupRoot string
+
+	pid   int
+	paths *cgroupPaths
+
+	prevAt        time.Time
+	prevUsageUsec uint64
+	prevPeriods   uint64
+	prevThrottled uint64
+}
+
+func newCgroupCollector() *cgroupCollector {
+	return &cgroupCollector{procRoot: defaultProcRoot, cgroupRoot: defaultCgroupRoot}
+}
+
+// collect returns the cgroup metrics of pid at now, or nil if pid is 0 or its
+// cgroup cannot be read, as on hosts without cgroups.
+func (c *cgroupCollector) collect(pid int, now time.Time) *agent.ContainerMetrics {
+	if c == nil || pid <= 0 {
+		return nil
+	}
+	if pid != c.pid || c.paths == nil {
+		paths, err := findCgroup(c.procRoot, c.cgroupRoot, pid)
+		if err != nil {
+			return nil
+		}
+		*c = cgroupCollector{procRoot: c.procRoot, cgroupRoot: c.cgroupRoot, pid: pid, paths: paths}
+	}
+
+	m := &agent.ContainerMetrics{CgroupVersion: c.paths.version, ID: c.paths.containerID()}
+	var usageUsec, periods uint64
+	var ok bool
+	if c.paths.version == 2 {
+		usageUsec, periods, ok = c.readV2(m)
+	} else {
+		usageUsec, periods, ok = c.readV1(m)
+	}
+	if !ok {
+		return nil
+	}
+
+	if !c.prevAt.IsZero() && usageUsec >= c.prevUsageUsec && periods >= c.prevPeriods {
+		if elapsed := now.Sub(c.prevAt).Microseconds(); elapsed > 0 {
+			m.CPUUsageCores = float64(usageUsec-c.prevUsageUsec) / float64(elapsed)
+		}
+		if dp := periods - c.prevPeriods; dp > 0 && m.CPUThrottledPeriods >= c.prevThrottled {
+			m.CPUThrottledPercent = float64(m.CPUThrottledPeriods-c.prevThrottled) / float64(dp) * 100
+		}
+	}
+	c.prevAt = now
+	c.prevUsageUsec = usageUsec
+	c.prevPeriods = periods
+	c.prevThrottled = m.CPUThrottledPeriods
+	return m
+}
+
+// readV2 fills m from a unified hierarchy and returns the lifetime CPU usage
+// and scheduler period counters.
+func (c *cgroupCollector) readV2(m *agent.ContainerMetrics) (usageUsec, periods uint64, ok bool) {
+	dir := c.paths.unified
+	mem, err := readUint(filepath.Join(dir, "memory.current"))
+	if err != nil {
+		return 0, 0, false
+	}
+	m.MemUsage = mem
+	if limit, err := readUint(filepath.Join(dir, "memory.max")); err == nil {
+		m.MemLimit = limit
+	}
+	if events, err := readKeyed(filepath.Join(dir, "memory.events")); err == nil {
+		m.OOMKills = events["oom_kill"]
+	}
+
+	if fields, err := readFields(filepath.Join(dir, "cpu.max")); err == nil && len(fields) == 2 && fields[0] != "max" {
+		quota, _ := strconv.ParseFloat(fields[0], 64)
+		period, _ := strconv.ParseFloat(fields[1], 64)
+		if period > 0 {
+			m.CPULimitCores = quota / period
+		}
+	}
+	stat, err := readKeyed(filepath.Join(dir, "cpu.stat"))
+	if err != nil {
+		return 0, 0, true
+	}
+	m.CPUThrottledPeriods = stat["nr_throttled"]
+	m.CPUThrottledUsec = stat["throttled_usec"]
+	return stat["usage_usec"], stat["nr_periods"], true
+}
+
+// readV1 fills m from the v1 cpu, cpuacct and memory hierarchies and returns
+// the lifetime CPU usage and scheduler period counters.
+func (c *cgroupCollector) readV1(m *agent.ContainerMetrics) (usageUsec, periods uint64, ok bool) {
+	if dir := c.paths.memory; dir != "" {
+		mem, err := readUint(filepath.Join(dir, "memory.usage_in_bytes"))
+		if err != nil {
+			return 0, 0, false
+		}
+		m.MemUsage = mem
+		if limit, err := readUint(filepath.Join(dir, "memory.limit_in_bytes")); err == nil && limit < cgroupUnlimited {
+			m.MemLimit = limit
+		}
+		if oom, err := readKeyed(filepath.Join(dir, "memory.oom_control")); err == nil {
+			m.OOMKills = oom["oom_kill"]
+		}
+	}
+
+	if dir := c.paths.cpu; dir != "" {
+		quota, err := readInt(filepath.Join(dir, "cpu.cfs_quota_us"))
+		period, err2 := readInt(filepath.Join(dir, "cpu.cfs_period_us"))
+		if err == nil && err2 == nil && quota > 0 && period > 0 {
+			m.CPULimitCores = float64(quota) / float64(period)
+		}
+		if stat, err := readKeyed(filepath.Join(dir, "cpu.stat")); err == nil {
+			periods = stat["nr_periods"]
+			m.CPUThrottledPeriods = stat["nr_throttled"]
+			m.CPUThrottledUsec = stat["throttled_time"] / 1000
+		}
+	}
+	if dir := c.paths.cpuacct; dir != "" {
+		if ns, err := readUint(filepath.Join(dir, "cpuacct.usage")); err == nil {
+			usageUsec = ns / 1000
+		}
+	}
+	return usageUsec, periods, c.paths.memory != "" || c.paths.cpu != ""
+}
+
+func readFields(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(data)), nil
+}
+
+// readUint reads a file holding a single number; "max" reads as 0, as in
+// memory.max without a limit.
+func readUint(path string) (uint64, error) {
+	fields, err := readFields(path)
+	if err != nil {
+		return 0, err
+	}
+	if len(fields) != 1 {
+		return 0, errors.New("unexpected format in " + path)
+	}
+	if fields[0] == "max" {
+		return 0, nil
+	}
+	return strconv.ParseUint(fields[0], 10, 64)
+}
+
+func readInt(path string) (int64, error) {
+	fields, err := readFields(path)
+	if err != nil {
+		return 0, err
+	}
+	if len(fields) != 1 {
+		return 0, errors.New("unexpected format in " + path)
+	}
+	return strconv.ParseInt(fields[0], 10, 64)
+}
+
+// readKeyed reads a file of "key value" lines such as cpu.stat.
+func readKeyed(path string) (map[string]uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]uint64)
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		if v, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+			values[fields[0]] = v
+		}
+	}
+	return values, nil
+}
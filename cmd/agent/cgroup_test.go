@@ -0,0 +1,103 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+const testContainerID = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestCgroupCollector_V2(t *testing.T) {
+	root := t.TempDir()
+	cg := "/system.slice/docker-" + testContainerID + ".scope"
+	writeFiles(t, root, map[string]string{
+		"proc/42/cgroup":           "0::" + cg + "\n",
+		"cgroup" + cg + "/cpu.max": "200000 100000\n",
+		"cgroup" + cg + "/cpu.stat": "usage_usec 1000000\nuser_usec 800000\nsystem_usec 200000\n" +
+			"nr_periods 100\nnr_throttled 10\nthrottled_usec 50000\n",
+		"cgroup" + cg + "/memory.current": "268435456\n",
+		"cgroup" + cg + "/memory.max":     "536870912\n",
+		"cgroup" + cg + "/memory.events":  "low 0\nhigh 0\nmax 3\noom 1\noom_kill 1\n",
+	})
+	c := &cgroupCollector{procRoot: filepath.Join(root, "proc"), cgroupRoot: filepath.Join(root, "cgroup")}
+
+	start := time.Unix(1000, 0)
+	m := c.collect(42, start)
+	if m == nil {
+		t.Fatal("expected container metrics")
+	}
+	if m.CgroupVersion != 2 || m.ID != testContainerID || m.CPULimitCores != 2 || m.MemUsage != 256<<20 || m.MemLimit != 512<<20 || m.OOMKills != 1 {
+		t.Errorf("unexpected metrics %+v", m)
+	}
+	if m.CPUUsageCores != 0 || m.CPUThrottledPercent != 0 {
+		t.Errorf("expected no rates from the first sample, got %+v", m)
+	}
+
+	// Over 2s the container used 3s of CPU and was throttled in 30 of 50 periods.
+	writeFiles(t, root, map[string]string{
+		"cgroup" + cg + "/cpu.stat": "usage_usec 4000000\nnr_periods 150\nnr_throttled 40\nthrottled_usec 90000\n",
+	})
+	m = c.collect(42, start.Add(2*time.Second))
+	if m.CPUUsageCores != 1.5 || m.CPUThrottledPercent != 60 || m.CPUThrottledPeriods != 40 {
+		t.Errorf("unexpected rates %+v", m)
+	}
+
+	writeFiles(t, root, map[string]string{"cgroup" + cg + "/memory.max": "max\n"})
+	if m := c.collect(42, start.Add(4*time.Second)); m.MemLimit != 0 {
+		t.Errorf("expected no memory limit, got %d", m.MemLimit)
+	}
+}
+
+func TestCgroupCollector_V1(t *testing.T) {
+	root := t.TempDir()
+	cg := "/docker/" + testContainerID
+	writeFiles(t, root, map[string]string{
+		"proc/7/cgroup": "12:memory:" + cg + "\n4:cpu,cpuacct:" + cg + "\n1:name=systemd:" + cg + "\n0::/\n",
+		"cgroup/cpu,cpuacct" + cg + "/cpu.cfs_quota_us":  "-1\n",
+		"cgroup/cpu,cpuacct" + cg + "/cpu.cfs_period_us": "100000\n",
+		"cgroup/cpu,cpuacct" + cg + "/cpu.stat":          "nr_periods 0\nnr_throttled 0\nthrottled_time 0\n",
+		"cgroup/cpu,cpuacct" + cg + "/cpuacct.usage":     "2000000000\n",
+		"cgroup/memory" + cg + "/memory.usage_in_bytes":  "1048576\n",
+		"cgroup/memory" + cg + "/memory.limit_in_bytes":  "9223372036854771712\n",
+		"cgroup/memory" + cg + "/memory.oom_control":     "oom_kill_disable 0\nunder_oom 0\noom_kill 2\n",
+	})
+	c := &cgroupCollector{procRoot: filepath.Join(root, "proc"), cgroupRoot: filepath.Join(root, "cgroup")}
+
+	m := c.collect(7, time.Unix(1000, 0))
+	if m == nil {
+		t.Fatal("expected container metrics")
+	}
+	if m.CgroupVersion != 1 || m.ID != testContainerID || m.CPULimitCores != 0 || m.MemUsage != 1<<20 || m.MemLimit != 0 || m.OOMKills != 2 {
+		t.Errorf("unexpected metrics %+v", m)
+	}
+
+	writeFiles(t, root, map[string]string{"cgroup/cpu,cpuacct" + cg + "/cpuacct.usage": "2500000000\n"})
+	if m := c.collect(7, time.Unix(1001, 0)); m.CPUUsageCores != 0.5 {
+		t.Errorf("expected half a core, got %v", m.CPUUsageCores)
+	}
+}
+
+func TestCgroupCollector_NoCgroup(t *testing.T) {
+	c := &cgroupCollector{procRoot: t.TempDir(), cgroupRoot: t.TempDir()}
+	if m := c.collect(42, time.Now()); m != nil {
+		t.Errorf("expected no metrics without a cgroup, got %+v", m)
+	}
+	if m := c.collect(0, time.Now()); m != nil {
+		t.Errorf("expected no metrics without a process, got %+v", m)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"math"
+	"sync"
+)
+
+const (
+	// minDriftWindowMs is how long after the first exchange the agent waits
+	// before estimating drift; over shorter spans network jitter dominates.
+	minDriftWindowMs = 60_000
+	// syncRTTSlackMs is added to twice the best round trip seen before an
+	// exchange is judged too slow to measure the offset from.
+	syncRTTSlackMs = 5
+	// maxSyncAgeMs is how long the estimate may go without an accepted
+	// exchange before a slow one is used anyway, with its round trip as the
+	// new best, so a lasting rise in latency does not freeze the estimate.
+	maxSyncAgeMs = 10 * 60_000
+)
+
+// clockSync estimates the offset of the control plane's clock from the
+// agent's, and how fast that offset drifts, from the server time returned
+// with registration and with every metrics batch. Each exchange is read like
+// an NTP probe: the server time is taken to fall at the midpoint of the
+// round trip. Exchanges with a round trip well above the best seen are
+// skipped, as their midpoint says little about when the server answered.
+//
+// A nil clockSync leaves timestamps on the agent's clock. It is shared by
+// the metrics batcher and the log tailer.
+type clockSync struct {
+	mu sync.Mutex
+
+	offsetMs   float64 // server - local at syncedAtMs
+	driftPPM   float64 // change in offset per million ms of local time
+	syncedAtMs int64   // local time of the latest accepted exchange
+	bestRTTMs  int64
+
+	firstAtMs     int64 // local time of the first accepted exchange
+	firstOffsetMs float64
+}
+
+// observe records an exchange sent at beforeMs and answered at afterMs, both
+// on the local clock, in which the server reported serverMs. It reports
+// whether the exchange was used.
+func (c *clockSync) observe(beforeMs, afterMs, serverMs int64) bool {
+	if c == nil || serverMs <= 0 || afterMs < beforeMs {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	rtt := afterMs - beforeMs
+	midpoint := beforeMs + rtt/2
+	stale := midpoint-c.syncedAtMs > maxSyncAgeMs
+	if c.syncedAtMs != 0 && !stale && rtt > 2*c.bestRTTMs+syncRTTSlackMs {
+		return false
+	}
+	if c.syncedAtMs == 0 || stale || rtt < c.bestRTTMs {
+		c.bestRTTMs = rtt
+	}
+
+	offset := float64(serverMs - midpoint)
+	if c.syncedAtMs == 0 {
+		c.firstAtMs = midpoint
+		c.firstOffsetMs = offset
+	} else if elapsed := midpoint - c.firstAtMs; elapsed >= minDriftWindowMs {
+		c.driftPPM = (offset - c.firstOffsetMs) / float64(elapsed) * 1e6
+	}
+	c.offsetMs = offset
+	c.syncedAtMs = midpoint
+	return true
+}
+
+// now converts a local time to the control plane's clock, extrapolating
+// the drift since the latest exchange.
+func (c *clockSync) now(localMs int64) int64 {
+	if c == nil {
+		return localMs
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.syncedAtMs == 0 {
+		return localMs
+	}
+	drift := c.driftPPM * float64(localMs-c.syncedAtMs) / 1e6
+	return localMs + int64(math.Round(c.offsetMs+drift))
+}
+
+// offset returns the current offset estimate, server minus local, in ms.
+func (c *clockSync) offset() int64 {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return int64(math.Round(c.offsetMs))
+}
+
+// drift returns the current drift estimate in parts per million.
+func (c *clockSync) drift() float64 {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.driftPPM
+}
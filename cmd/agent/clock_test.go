@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+func TestClockSync_OffsetAndDrift(t *testing.T) {
+	c := &clockSync{}
+	// The server runs 500ms ahead at registration.
+	if !c.observe(1_000, 1_020, 1_510) {
+		t.Fatal("expected the first exchange to be used")
+	}
+	if got := c.now(2_000); got != 2_500 {
+		t.Errorf("expected 2500 on the server clock, got %d", got)
+	}
+
+	// Two minutes later it is 620ms ahead: the local clock runs 1000ppm slow.
+	if !c.observe(121_000, 121_020, 121_630) {
+		t.Fatal("expected a fast exchange to be used")
+	}
+	if d := c.drift(); d < 999 || d > 1001 {
+		t.Errorf("expected drift of about 1000ppm, got %.1f", d)
+	}
+	if got := c.now(131_010); got != 131_640 {
+		t.Errorf("expected the offset to be extrapolated to 131640, got %d", got)
+	}
+
+	// A round trip far above the best says little about the offset.
+	if c.observe(125_000, 125_400, 130_000) {
+		t.Error("expected a slow exchange to be skipped")
+	}
+	if c.offset() != 620 {
+		t.Errorf("expected offset to stay 620ms, got %d", c.offset())
+	}
+	// Unless no exchange was used for too long.
+	if !c.observe(800_000, 800_400, 800_900) {
+		t.Error("expected a slow exchange to be used once the estimate is stale")
+	}
+}
+
+func TestClockSync_Nil(t *testing.T) {
+	var c *clockSync
+	if c.observe(1_000, 1_020, 1_510) {
+		t.Error("expected a nil clock to ignore exchanges")
+	}
+	if got := c.now(2_000); got != 2_000 {
+		t.Errorf("expected a nil clock to keep local time, got %d", got)
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultDockerHost = "unix:///var/run/docker.sock"
+	// defaultPodLogRoot is where the kubelet keeps a directory per pod,
+	// named <namespace>_<name>_<uid>, which maps a pod name to the UID in
+	// its cgroup paths.
+	defaultPodLogRoot = "/var/log/pods"
+)
+
+// findPIDWithRetry calls find until it returns a PID, up to maxRetries more
+// times, waiting retryDelay in between.
+func findPIDWithRetry(ctx context.Context, find func() int, maxRetries int, retryDelay time.Duration, onRetry func(attempt int)) int {
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if pid := find(); pid > 0 {
+			return pid
+		}
+
+		if attempt == maxRetries {
+			return 0
+		}
+
+		if onRetry != nil {
+			onRetry(attempt + 1)
+		}
+
+		select {
+		case <-ctx.Done():
+			return 0
+		case <-time.After(retryDelay):
+		}
+	}
+
+	return 0
+}
+
+// dockerClient queries the Docker Engine API.
+type dockerClient struct {
+	client  *http.Client
+	baseURL string
+}
+
+// newDockerClient connects to host, a unix:// socket or a tcp:// or http://
+// address as in DOCKER_HOST.
+func newDockerClient(host string) (*dockerClient, error) {
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid docker host %q: %w", host, err)
+	}
+	switch u.Scheme {
+	case "unix":
+		socket := u.Path
+		transport := &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socket)
+			},
+		}
+		return &dockerClient{client: &http.Client{Transport: transport, Timeout: 5 * time.Second}, baseURL: "http://docker"}, nil
+	case "tcp", "http":
+		return &dockerClient{client: &http.Client{Timeout: 5 * time.Second}, baseURL: "http://" + u.Host}, nil
+	case "https":
+		return &dockerClient{client: &http.Client{Timeout: 5 * time.Second}, baseURL: "https://" + u.Host}, nil
+	default:
+		return nil, fmt.Errorf("unsupported docker host %q", host)
+	}
+}
+
+func (d *dockerClient) get(ctx context.Context, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("docker API %s: %s", path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// containerPID returns the PID of the main process of the running container
+// matching selector: a container name, or label=value. When several match,
+// the most recently created is used.
+func (d *dockerClient) containerPID(ctx context.Context, selector string) (int, error) {
+	filters := map[string][]string{"status": {"running"}}
+	if strings.Contains(selector, "=") {
+		filters["label"] = []string{selector}
+	} else {
+		filters["name"] = []string{"^/" + regexp.QuoteMeta(strings.TrimPrefix(selector, "/")) + "$"}
+	}
+	encoded, _ := json.Marshal(filters)
+
+	var containers []struct {
+		ID      string `json:"Id"`
+		Created int64  `json:"Created"`
+	}
+	if err := d.get(ctx, "/containers/json?filters="+url.QueryEscape(string(encoded)), &containers); err != nil {
+		return 0, err
+	}
+	if len(containers) == 0 {
+		return 0, fmt.Errorf("no running container matches %q", selector)
+	}
+	sort.Slice(containers, func(i, j int) bool { return containers[i].Created > containers[j].Created })
+
+	var inspect struct {
+		State struct {
+			Pid int `json:"Pid"`
+		} `json:"State"`
+	}
+	if err := d.get(ctx, "/containers/"+containers[0].ID+"/json", &inspect); err != nil {
+		return 0, err
+	}
+	if inspect.State.Pid <= 0 {
+		return 0, fmt.Errorf("container %s has no running process", containers[0].ID[:12])
+	}
+	return inspect.State.Pid, nil
+}
+
+// parsePodSelector splits namespace/name, defaulting to the default
+// namespace.
+func parsePodSelector(selector string) (namespace, name string) {
+	if ns, n, ok := strings.Cut(selector, "/"); ok {
+		return ns, n
+	}
+	return "default", selector
+}
+
+// findPodPID returns the lowest PID on this node running in a container of
+// the pod named by selector, skipping the pod's pause process. Pods are
+// found through the kubelet's per-pod log directories under podLogRoot and
+// matched to processes by the pod UID in their cgroup paths.
+func findPodPID(procRoot, podLogRoot, selector string) (int, error) {
+	namespace, name := parsePodSelector(selector)
+	dirs, err := filepath.Glob(filepath.Join(podLogRoot, namespace+"_"+name+"_*"))
+	if err != nil {
+		return 0, err
+	}
+	if len(dirs) == 0 {
+		return 0, fmt.Errorf("pod %s/%s not found in %s", namespace, name, podLogRoot)
+	}
+	var uids []string
+	for _, dir := range dirs {
+		uid := strings.TrimPrefix(filepath.Base(dir), namespace+"_"+name+"_")
+		// The systemd cgroup driver writes the UID with underscores.
+		uids = append(uids, "pod"+uid, "pod"+strings.ReplaceAll(uid, "-", "_"))
+	}
+
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return 0, err
+	}
+	best := 0
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || (best > 0 && pid >= best) {
+			continue
+		}
+		cgroup, err := os.ReadFile(filepath.Join(procRoot, entry.Name(), "cgroup"))
+		if err != nil || !containsAny(string(cgroup), uids) {
+			continue
+		}
+		if comm, err := os.ReadFile(filepath.Join(procRoot, entry.Name(), "comm")); err == nil && strings.TrimSpace(string(comm)) == "pause" {
+			continue
+		}
+		best = pid
+	}
+	if best == 0 {
+		return 0, fmt.Errorf("no process of pod %s/%s runs on this node", namespace, name)
+	}
+	return best, nil
+}
+
+func containsAny(s string, subs []string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
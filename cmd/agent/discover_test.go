@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDockerClient_ContainerPID(t *testing.T) {
+	var filters []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/containers/json":
+			filters = append(filters, r.URL.Query().Get("filters"))
+			if strings.Contains(r.URL.Query().Get("filters"), "missing") {
+				_, _ = w.Write([]byte("[]"))
+				return
+			}
+			_ = json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"Id": "old", "Created": 100},
+				{"Id": "new", "Created": 200},
+			})
+		case r.URL.Path == "/containers/new/json":
+			_, _ = w.Write([]byte(`{"State":{"Pid":4242}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	d, err := newDockerClient("tcp://" + strings.TrimPrefix(srv.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if pid, err := d.containerPID(ctx, "mcp-server"); err != nil || pid != 4242 {
+		t.Fatalf("expected the newest container's PID 4242, got %d, %v", pid, err)
+	}
+	if !strings.Contains(filters[0], `"name":["^/mcp-server$"]`) || !strings.Contains(filters[0], `"status":["running"]`) {
+		t.Errorf("unexpected name filter %s", filters[0])
+	}
+
+	if _, err := d.containerPID(ctx, "app=mcp"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(filters[1], `"label":["app=mcp"]`) {
+		t.Errorf("unexpected label filter %s", filters[1])
+	}
+
+	if _, err := d.containerPID(ctx, "missing"); err == nil {
+		t.Error("expected an error when no container matches")
+	}
+}
+
+func TestNewDockerClient(t *testing.T) {
+	if _, err := newDockerClient(defaultDockerHost); err != nil {
+		t.Errorf("expected the default socket to be accepted: %v", err)
+	}
+	if _, err := newDockerClient("npipe:////./pipe/docker_engine"); err == nil {
+		t.Error("expected an unsupported scheme to be rejected")
+	}
+}
+
+func TestFindPodPID(t *testing.T) {
+	root := t.TempDir()
+	uid := "8c1e4a2f-1111-2222-3333-444455556666"
+	systemdUID := strings.ReplaceAll(uid, "-", "_")
+	writeFiles(t, root, map[string]string{
+		"pods/prod_mcp-server-0_" + uid + "/server/0.log": "",
+		// The pause container, the server and an unrelated process.
+		"proc/100/cgroup": "0::/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod" + systemdUID + ".slice/cri-containerd-aaa.scope\n",
+		"proc/100/comm":   "pause\n",
+		"proc/230/cgroup": "0::/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod" + systemdUID + ".slice/cri-containerd-bbb.scope\n",
+		"proc/230/comm":   "node\n",
+		"proc/231/cgroup": "0::/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod" + systemdUID + ".slice/cri-containerd-bbb.scope\n",
+		"proc/50/cgroup":  "0::/system.slice/kubelet.service\n",
+	})
+	proc, pods := filepath.Join(root, "proc"), filepath.Join(root, "pods")
+
+	if pid, err := findPodPID(proc, pods, "prod/mcp-server-0"); err != nil || pid != 230 {
+		t.Fatalf("expected PID 230, got %d, %v", pid, err)
+	}
+	if _, err := findPodPID(proc, pods, "mcp-server-0"); err == nil {
+		t.Error("expected the pod not to be found in the default namespace")
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/agent"
+)
+
+const (
+	// gpuQueryTimeout bounds one nvidia-smi call, which can hang while the
+	// driver is busy resetting a GPU.
+	gpuQueryTimeout = 5 * time.Second
+
+	mib = 1 << 20
+)
+
+// gpuCollector reads NVIDIA GPU metrics through nvidia-smi, the command-line
+// front end to NVML that ships with the driver, so the agent needs neither
+// cgo nor the NVML headers. It is not safe for concurrent use;
+// collectAndSend owns it.
+type gpuCollector struct {
+	// run executes nvidia-smi with args and returns its output.
+	run func(ctx context.Context, args ...string) ([]byte, error)
+}
+
+// newGPUCollector returns a collector using the nvidia-smi on PATH, or an
+// error if there is none.
+func newGPUCollector() (*gpuCollector, error) {
+	path, err := exec.LookPath("nvidia-smi")
+	if err != nil {
+		return nil, errors.New("nvidia-smi not found; install the NVIDIA driver or drop --gpu")
+	}
+	return &gpuCollector{run: func(ctx context.Context, args ...string) ([]byte, error) {
+		return exec.CommandContext(ctx, path, args...).Output()
+	}}, nil
+}
+
+// query runs an nvidia-smi CSV query and returns its rows.
+func (g *gpuCollector) query(args ...string) ([][]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), gpuQueryTimeout)
+	defer cancel()
+	out, err := g.run(ctx, append(args, "--format=csv,noheader,nounits")...)
+	if err != nil {
+		return nil, err
+	}
+	r := csv.NewReader(strings.NewReader(string(out)))
+	r.TrimLeadingSpace = true
+	r.FieldsPerRecord = -1
+	return r.ReadAll()
+}
+
+// devices returns the metrics of every GPU on the host. Values a GPU does
+// not support, reported as "[N/A]" or "[Not Supported]", are left at 0.
+func (g *gpuCollector) devices() ([]agent.GPUMetrics, error) {
+	rows, err := g.query("--query-gpu=index,name,utilization.gpu,memory.used,memory.total,temperature.gpu,power.draw")
+	if err != nil {
+		return nil, err
+	}
+	gpus := make([]agent.GPUMetrics, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < 7 {
+			continue
+		}
+		gpus = append(gpus, agent.GPUMetrics{
+			Index:              int(parseGPUValue(row[0])),
+			Name:               row[1],
+			UtilizationPercent: parseGPUValue(row[2]),
+			MemUsed:            uint64(parseGPUValue(row[3]) * mib),
+			MemTotal:           uint64(parseGPUValue(row[4]) * mib),
+			TemperatureC:       parseGPUValue(row[5]),
+			PowerWatts:         parseGPUValue(row[6]),
+		})
+	}
+	return gpus, nil
+}
+
+// processMemory returns the GPU memory held by each process using a GPU,
+// summed across GPUs, in bytes.
+func (g *gpuCollector) processMemory() (map[int]uint64, error) {
+	rows, err := g.query("--query-compute-apps=pid,used_memory")
+	if err != nil {
+		return nil, err
+	}
+	mem := make(map[int]uint64)
+	for _, row := range rows {
+		if len(row) < 2 {
+			continue
+		}
+		pid, err := strconv.Atoi(strings.TrimSpace(row[0]))
+		if err != nil {
+			continue
+		}
+		mem[pid] += uint64(parseGPUValue(row[1]) * mib)
+	}
+	return mem, nil
+}
+
+// collect adds GPU metrics to sample. Process GPU memory is only queried
+// when the sample has process metrics, and covers every process they sum.
+func (g *gpuCollector) collect(sample *metricsSample) error {
+	if g == nil || sample.Host == nil {
+		return nil
+	}
+	gpus, err := g.devices()
+	if err != nil {
+		return err
+	}
+	sample.Host.GPUs = gpus
+	procs := sample.processes()
+	if len(procs) == 0 || len(gpus) == 0 {
+		return nil
+	}
+	mem, err := g.processMemory()
+	if err != nil {
+		return err
+	}
+	for _, p := range procs {
+		p.GPUMemUsed = 0
+		for _, pid := range p.PIDList() {
+			p.GPUMemUsed += mem[pid]
+		}
+	}
+	return nil
+}
+
+func parseGPUValue(s string) float64 {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/agent"
+)
+
+// fakeNvidiaSMI answers nvidia-smi queries with canned CSV.
+func fakeNvidiaSMI(gpus, apps string) *gpuCollector {
+	return &gpuCollector{run: func(_ context.Context, args ...string) ([]byte, error) {
+		switch {
+		case strings.HasPrefix(args[0], "--query-gpu="):
+			return []byte(gpus), nil
+		case strings.HasPrefix(args[0], "--query-compute-apps="):
+			return []byte(apps), nil
+		}
+		return nil, errors.New("unexpected query")
+	}}
+}
+
+func TestGPUCollector_Collect(t *testing.T) {
+	g := fakeNvidiaSMI(
+		"0, NVIDIA A100-SXM4-40GB, 87, 30720, 40960, 64, 312.45\n"+
+			"1, NVIDIA A100-SXM4-40GB, 3, 512, 40960, 41, [N/A]\n",
+		"4242, 20480\n999, 1024\n4242, 2048\n",
+	)
+	sample := metricsSample{Host: &agent.HostMetrics{}, Process: &agent.ProcessMetrics{PID: 4242}}
+	if err := g.collect(&sample); err != nil {
+		t.Fatalf("collect failed: %v", err)
+	}
+
+	gpus := sample.Host.GPUs
+	if len(gpus) != 2 {
+		t.Fatalf("expected 2 GPUs, got %+v", gpus)
+	}
+	if gpus[0].Name != "NVIDIA A100-SXM4-40GB" || gpus[0].UtilizationPercent != 87 || gpus[0].MemUsed != 30720*mib ||
+		gpus[0].MemTotal != 40960*mib || gpus[0].TemperatureC != 64 || gpus[0].PowerWatts != 312.45 {
+		t.Errorf("unexpected GPU 0: %+v", gpus[0])
+	}
+	if gpus[1].Index != 1 || gpus[1].PowerWatts != 0 {
+		t.Errorf("expected unsupported power left at 0, got %+v", gpus[1])
+	}
+	if sample.Process.GPUMemUsed != 22528*mib {
+		t.Errorf("expected the process's memory summed across GPUs, got %d", sample.Process.GPUMemUsed)
+	}
+}
+
+func TestGPUCollector_Targets(t *testing.T) {
+	g := fakeNvidiaSMI("0, Tesla T4, 10, 100, 15360, 50, 30\n", "10, 100\n11, 200\n12, 400\n")
+	sample := metricsSample{Host: &agent.HostMetrics{}, Targets: []agent.TargetMetrics{
+		{Label: "workers", Process: &agent.ProcessMetrics{PID: 10, PIDs: []int{10, 11}}},
+		{Label: "idle"},
+		{Label: "other", Process: &agent.ProcessMetrics{PID: 12}},
+	}}
+	if err := g.collect(&sample); err != nil {
+		t.Fatal(err)
+	}
+	if got := sample.Targets[0].Process.GPUMemUsed; got != 300*mib {
+		t.Errorf("expected GPU memory summed over the target's processes, got %d", got)
+	}
+	if got := sample.Targets[2].Process.GPUMemUsed; got != 400*mib {
+		t.Errorf("expected 400 MiB for the second target, got %d", got)
+	}
+}
+
+func TestGPUCollector_HostOnlyAndNil(t *testing.T) {
+	g := fakeNvidiaSMI("0, Tesla T4, 10, 100, 15360, 50, 30\n", "")
+	sample := metricsSample{Host: &agent.HostMetrics{}}
+	if err := g.collect(&sample); err != nil || len(sample.Host.GPUs) != 1 {
+		t.Fatalf("expected 1 GPU without a process, got %+v, %v", sample.Host.GPUs, err)
+	}
+
+	var none *gpuCollector
+	if err := none.collect(&sample); err != nil {
+		t.Errorf("expected a nil collector to do nothing, got %v", err)
+	}
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/agent"
+)
+
+const (
+	// logPollInterval is how often the tailer checks its files for new lines
+	// and ships what it read.
+	logPollInterval = time.Second
+	// defaultLogRateLimit is the default number of entries shipped per second.
+	defaultLogRateLimit = 50
+	// maxLogLineBytes truncates single lines; maxLogEntryLines caps the
+	// continuation lines grouped into one entry.
+	maxLogLineBytes  = 4096
+	maxLogEntryLines = 100
+	// maxLogReadBytes bounds how much of one file is read per poll, so a
+	// burst of logging is spread over several polls.
+	maxLogReadBytes = 1 << 20
+	// maxPendingLogEntries matches the control plane's per-request limit.
+	maxPendingLogEntries = 1000
+
+	redactedText = "[REDACTED]"
+)
+
+// redactFlags collects repeated --log-redact patterns.
+type redactFlags []*regexp.Regexp
+
+func (r redactFlags) String() string {
+	return ""
+}
+
+func (r *redactFlags) Set(value string) error {
+	re, err := regexp.Compile(value)
+	if err != nil {
+		return err
+	}
+	*r = append(*r, re)
+	return nil
+}
+
+// splitGlobs splits a comma-separated flag value, such as --log-file, into
+// globs, rejecting malformed ones.
+func splitGlobs(value string) ([]string, error) {
+	var patterns []string
+	for _, pattern := range strings.Split(value, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// logTailer follows the files matching a set of globs and ships new lines to
+// the control plane. Files present when it starts are followed from their
+// end; files that appear later, and files that are truncated or replaced by
+// rotation, are read from the start. A line that starts with whitespace or
+// "Caused by" continues the previous one, so a stack trace arrives as a
+// single entry. Entries beyond the rate limit are counted and dropped.
+//
+// It is not safe for concurrent use; run owns it.
+type logTailer struct {
+	client  *http.Client
+	baseURL string
+	token   string
+	agentID string
+	pairKey string
+	gzip    bool
+
+	patterns []string
+	redact   []*regexp.Regexp
+	rate     float64 // entries per second; 0 is unlimited
+	clock    *clockSync
+	now      func() time.Time
+
+	files      map[string]*tailedFile
+	entries    []agent.LogEntry
+	dropped    int
+	tokens     float64
+	refilledAt time.Time
+}
+
+// tailedFile is the read position in one followed file.
+type tailedFile struct {
+	path    string
+	info    os.FileInfo
+	offset  int64
+	partial []byte // an unterminated last line, completed by the next read
+
+	open  *agent.LogEntry // entry still collecting continuation lines
+	lines int
+}
+
+func newLogTailer(baseURL, token, agentID, pairKey string, useGzip bool, patterns []string, redact []*regexp.Regexp, rate float64) *logTailer {
+	return &logTailer{
+		client:   http.DefaultClient,
+		baseURL:  baseURL,
+		token:    token,
+		agentID:  agentID,
+		pairKey:  pairKey,
+		gzip:     useGzip,
+		patterns: patterns,
+		redact:   redact,
+		rate:     rate,
+		now:      time.Now,
+		files:    make(map[string]*tailedFile),
+	}
+}
+
+// run polls the files every logPollInterval until ctx is cancelled, then
+// ships what is left.
+func (t *logTailer) run(ctx context.Context) {
+	t.poll(true)
+	ticker := time.NewTicker(logPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			t.poll(false)
+			for _, f := range t.files {
+				t.finish(f)
+			}
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownFlushTimeout)
+			if err := t.send(shutdownCtx); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to send logs: %v\n", err)
+			}
+			cancel()
+			return
+		case <-ticker.C:
+			t.poll(false)
+			if err := t.send(ctx); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to send logs: %v\n", err)
+			}
+		}
+	}
+}
+
+// poll reads new lines from every file matching the patterns. On the initial
+// poll, files are only opened, at their end.
+func (t *logTailer) poll(initial bool) {
+	seen := make(map[string]bool)
+	for _, pattern := range t.patterns {
+		matches, _ := filepath.Glob(pattern)
+		for _, path := range matches {
+			if seen[path] {
+				continue
+			}
+			seen[path] = true
+			t.read(path, initial)
+		}
+	}
+	for path, f := range t.files {
+		if !seen[path] {
+			t.finish(f)
+			delete(t.files, path)
+		}
+	}
+}
+
+func (t *logTailer) read(path string, initial bool) {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return
+	}
+
+	f := t.files[path]
+	switch {
+	case f == nil:
+		f = &tailedFile{path: path}
+		if initial {
+			f.offset = info.Size()
+		}
+		t.files[path] = f
+	case !os.SameFile(f.info, info) || info.Size() < f.offset:
+		// Rotated or truncated: read the new contents from the start.
+		t.finish(f)
+		f.offset = 0
+		f.partial = nil
+	}
+	f.info = info
+
+	if info.Size() == f.offset {
+		// Nothing new, so nothing more will continue the open entry.
+		t.finish(f)
+		return
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	buf := make([]byte, min(info.Size()-f.offset, maxLogReadBytes))
+	n, err := file.ReadAt(buf, f.offset)
+	if err != nil && err != io.EOF {
+		return
+	}
+	f.offset += int64(n)
+
+	data := append(f.partial, buf[:n]...)
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		t.addLine(f, string(bytes.TrimRight(data[:i], "\r")))
+		data = data[i+1:]
+	}
+	if len(data) > maxLogLineBytes {
+		t.addLine(f, string(data))
+		data = nil
+	}
+	f.partial = append([]byte(nil), data...)
+}
+
+func (t *logTailer) addLine(f *tailedFile, line string) {
+	if len(line) > maxLogLineBytes {
+		line = strings.ToValidUTF8(line[:maxLogLineBytes], "")
+	}
+	if f.open != nil && isContinuationLine(line) {
+		if f.lines < maxLogEntryLines {
+			f.open.Text += "\n" + line
+			f.lines++
+		}
+		return
+	}
+	t.finish(f)
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	f.open = &agent.LogEntry{
+		Timestamp: t.clock.now(t.now().UnixMilli()),
+		File:      f.path,
+		Text:      line,
+	}
+	f.lines = 1
+}
+
+func isContinuationLine(line string) bool {
+	return strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "Caused by")
+}
+
+// finish queues a file's open entry, if any.
+func (t *logTailer) finish(f *tailedFile) {
+	if f.open == nil {
+		return
+	}
+	entry := *f.open
+	f.open = nil
+
+	for _, re := range t.redact {
+		entry.Text = re.ReplaceAllString(entry.Text, redactedText)
+	}
+	if !t.allow() || len(t.entries) >= maxPendingLogEntries {
+		t.dropped++
+		return
+	}
+	t.entries = append(t.entries, entry)
+}
+
+// allow takes a token from a bucket refilled at the rate limit and holding
+// at most a second's worth.
+func (t *logTailer) allow() bool {
+	if t.rate <= 0 {
+		return true
+	}
+	now := t.now()
+	burst := math.Max(t.rate, 1)
+	if t.refilledAt.IsZero() {
+		t.tokens = burst
+	} else {
+		t.tokens = math.Min(burst, t.tokens+now.Sub(t.refilledAt).Seconds()*t.rate)
+	}
+	t.refilledAt = now
+	if t.tokens < 1 {
+		return false
+	}
+	t.tokens--
+	return true
+}
+
+// send ships the queued entries and the count of dropped ones. Like the
+// metrics batcher, it discards them even if the send fails.
+func (t *logTailer) send(ctx context.Context) error {
+	if len(t.entries) == 0 && t.dropped == 0 {
+		return nil
+	}
+	entries, dropped := t.entries, t.dropped
+	t.entries, t.dropped = nil, 0
+
+	err := postBatch(ctx, t.client, t.baseURL+"/agents/v1/logs", t.token, t.gzip, logsRequest{
+		AgentID: t.agentID,
+		PairKey: t.pairKey,
+		Entries: entries,
+		Dropped: dropped,
+		SentAt:  t.clock.now(t.now().UnixMilli()),
+	}, t.clock)
+	if err != nil {
+		return fmt.Errorf("send %d log entries: %w", len(entries), err)
+	}
+	return nil
+}
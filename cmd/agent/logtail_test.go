@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/agent"
+)
+
+func appendFile(t *testing.T, path, text string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(text); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func TestLogTailer_GroupsStackTraces(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "server.log")
+	appendFile(t, path, "old line before the agent started\n")
+
+	tailer := newLogTailer("", "", "agent_1", "pair", false, []string{filepath.Join(dir, "*.log")}, nil, 0)
+	tailer.poll(true)
+
+	appendFile(t, path, "INFO started\nERROR handler failed\n\tat Handler.call(Handler.java:10)\nCaused by: timeout\n\tat Pool.get(Pool.java:5)\npartial")
+	tailer.poll(false)
+	appendFile(t, path, " line\n")
+	tailer.poll(false)
+	tailer.poll(false)
+
+	if len(tailer.entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d: %+v", len(tailer.entries), tailer.entries)
+	}
+	if got := tailer.entries[1].Text; !strings.HasPrefix(got, "ERROR handler failed\n") || strings.Count(got, "\n") != 3 {
+		t.Errorf("expected the stack trace grouped with its error, got %q", got)
+	}
+	if got := tailer.entries[2].Text; got != "partial line" {
+		t.Errorf("expected the partial line completed, got %q", got)
+	}
+	if tailer.entries[0].File != path {
+		t.Errorf("expected file %s, got %s", path, tailer.entries[0].File)
+	}
+}
+
+func TestLogTailer_NewAndRotatedFiles(t *testing.T) {
+	dir := t.TempDir()
+	tailer := newLogTailer("", "", "agent_1", "pair", false, []string{filepath.Join(dir, "*.log")}, nil, 0)
+	tailer.poll(true)
+
+	// A file that appears after start is read from its beginning.
+	path := filepath.Join(dir, "app.log")
+	appendFile(t, path, "first\nsecond\n")
+	tailer.poll(false)
+	tailer.poll(false)
+	if len(tailer.entries) != 2 {
+		t.Fatalf("expected 2 entries from the new file, got %d", len(tailer.entries))
+	}
+
+	// Rotation replaces the file with a new, shorter one.
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	appendFile(t, path, "rotated\n")
+	tailer.poll(false)
+	tailer.poll(false)
+	if len(tailer.entries) != 3 || tailer.entries[2].Text != "rotated" {
+		t.Fatalf("expected the rotated file read from its start, got %+v", tailer.entries)
+	}
+
+	// Truncation in place starts over too.
+	if err := os.Truncate(path, 0); err != nil {
+		t.Fatal(err)
+	}
+	tailer.poll(false)
+	appendFile(t, path, "after truncate\n")
+	tailer.poll(false)
+	tailer.poll(false)
+	if len(tailer.entries) != 4 || tailer.entries[3].Text != "after truncate" {
+		t.Fatalf("expected the truncated file read from its start, got %+v", tailer.entries)
+	}
+}
+
+func TestLogTailer_RedactsAndRateLimits(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "server.log")
+	redact := []*regexp.Regexp{regexp.MustCompile(`token=\S+`)}
+	tailer := newLogTailer("", "", "agent_1", "pair", false, []string{path}, redact, 2)
+	now := time.UnixMilli(1_000_000)
+	tailer.now = func() time.Time { return now }
+	tailer.poll(true)
+
+	appendFile(t, path, "auth failed token=secret123 user=a\nb\nc\nd\n")
+	tailer.poll(false)
+	tailer.poll(false)
+	if len(tailer.entries) != 2 || tailer.dropped != 2 {
+		t.Fatalf("expected 2 entries and 2 dropped, got %d and %d", len(tailer.entries), tailer.dropped)
+	}
+	if got := tailer.entries[0].Text; got != "auth failed [REDACTED] user=a" {
+		t.Errorf("expected the token redacted, got %q", got)
+	}
+
+	now = now.Add(time.Second)
+	appendFile(t, path, "e\n")
+	tailer.poll(false)
+	tailer.poll(false)
+	if len(tailer.entries) != 3 {
+		t.Errorf("expected the bucket refilled after a second, got %d entries", len(tailer.entries))
+	}
+}
+
+func TestSplitLogPatterns(t *testing.T) {
+	patterns, err := splitGlobs("/var/log/app/*.log, /tmp/server.log,")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(patterns) != 2 || patterns[1] != "/tmp/server.log" {
+		t.Errorf("unexpected patterns %v", patterns)
+	}
+	if _, err := splitGlobs("/var/log/[.log"); err == nil {
+		t.Error("expected an error for a malformed glob")
+	}
+}
+
+func TestLogTailer_Send(t *testing.T) {
+	var got logsRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/agents/v1/logs" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_ = json.NewEncoder(w).Encode(batchResponse{Accepted: len(got.Entries), ServerTime: time.Now().UnixMilli()})
+	}))
+	defer srv.Close()
+
+	tailer := newLogTailer(srv.URL, "", "agent_1", "pair", false, nil, nil, 0)
+	tailer.entries = []agent.LogEntry{{Timestamp: 1, File: "server.log", Text: "ERROR boom"}}
+	tailer.dropped = 4
+	if err := tailer.send(context.Background()); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	if got.AgentID != "agent_1" || len(got.Entries) != 1 || got.Dropped != 4 || got.SentAt == 0 {
+		t.Errorf("unexpected request %+v", got)
+	}
+	if len(tailer.entries) != 0 || tailer.dropped != 0 {
+		t.Error("expected the queue cleared after sending")
+	}
+}
@@ -15,10 +15,13 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/bc-dunia/mcpdrill/internal/agent"
+	"github.com/bc-dunia/mcpdrill/internal/otel"
+	"github.com/bc-dunia/mcpdrill/internal/tlsconfig"
 	"github.com/shirou/gopsutil/v3/cpu"
 	"github.com/shirou/gopsutil/v3/load"
 	"github.com/shirou/gopsutil/v3/mem"
@@ -32,6 +35,11 @@ type registerRequest struct {
 	OS       string `json:"os"`
 	Arch     string `json:"arch"`
 	Version  string `json:"version"`
+	Project  string `json:"project,omitempty"`
+
+	// PairKeys lists every pair key the agent serves, PairKey first, when
+	// there is more than one.
+	PairKeys []string `json:"pair_keys,omitempty"`
 }
 
 type registerResponse struct {
@@ -43,43 +51,179 @@ type metricsRequest struct {
 	AgentID string          `json:"agent_id"`
 	PairKey string          `json:"pair_key"`
 	Samples []metricsSample `json:"samples"`
+
+	// SentAt is when the batch was sent, on the same corrected clock as the
+	// samples, so the control plane can check it against its own.
+	SentAt        int64   `json:"sent_at,omitempty"`
+	ClockOffsetMs int64   `json:"clock_offset_ms,omitempty"`
+	ClockDriftPPM float64 `json:"clock_drift_ppm,omitempty"`
+}
+
+type logsRequest struct {
+	AgentID string           `json:"agent_id"`
+	PairKey string           `json:"pair_key"`
+	Entries []agent.LogEntry `json:"entries"`
+	Dropped int              `json:"dropped,omitempty"`
+	SentAt  int64            `json:"sent_at,omitempty"`
+}
+
+// batchResponse is the response to a metrics or log batch.
+type batchResponse struct {
+	Accepted   int   `json:"accepted"`
+	ServerTime int64 `json:"server_time"`
 }
 
 type metricsSample struct {
 	Timestamp int64                 `json:"timestamp"`
 	Host      *agent.HostMetrics    `json:"host,omitempty"`
 	Process   *agent.ProcessMetrics `json:"process,omitempty"`
+
+	Container *agent.ContainerMetrics `json:"container,omitempty"`
+
+	// Targets holds the metrics of each monitored process, in place of
+	// Process and Container, when there are several or they are labelled.
+	Targets []agent.TargetMetrics `json:"targets,omitempty"`
+
+	// Seq numbers the agent's samples from 1, so the control plane can
+	// detect lost and resent ones.
+	Seq uint64 `json:"seq,omitempty"`
+
+	// Scraped holds the allowed series of the server's own metrics
+	// endpoint when --scrape-url is set.
+	Scraped []agent.ScrapedMetric `json:"scraped,omitempty"`
+}
+
+// processes returns the process metrics in the sample.
+func (s *metricsSample) processes() []*agent.ProcessMetrics {
+	var procs []*agent.ProcessMetrics
+	if s.Process != nil {
+		procs = append(procs, s.Process)
+	}
+	for i := range s.Targets {
+		if s.Targets[i].Process != nil {
+			procs = append(procs, s.Targets[i].Process)
+		}
+	}
+	return procs
 }
 
 func main() {
 	controlPlaneURL := flag.String("control-plane-url", "http://localhost:8080", "Control plane URL")
 	agentToken := flag.String("agent-token", "", "Agent authentication token")
-	pairKey := flag.String("pair-key", "", "Pair key to link with test runs")
+	caCert := flag.String("ca-cert", "", "Trust only the CA certificates in this PEM file for an https control plane, instead of the system roots")
+	tlsServerName := flag.String("tls-server-name", "", "Check the control plane's certificate against this name instead of the URL's host")
+	pairKey := flag.String("pair-key", "", "Pair key to link with test runs; comma-separated to serve several concurrent runs")
+	project := flag.String("project", "", "Project the monitored server belongs to (default: the token's only project, or the default project)")
 	listenPort := flag.Int("listen-port", 0, "Port of the MCP server process to monitor (0 = host metrics only)")
 	pid := flag.Int("pid", 0, "PID of the process to monitor (mutually exclusive with --listen-port)")
+	containerSelector := flag.String("container", "", "Monitor the main process of the running Docker container with this name, or with label=value")
+	podSelector := flag.String("pod", "", "Monitor a Kubernetes pod on this node, as namespace/name or name in the default namespace")
+	var targets targetFlags
+	flag.Var(&targets, "target", "Monitor another process, as label=<name>,<pid|port|process|container|pod>=<value>[,pair-key=<key>]; process takes a name glob and sums every match (repeatable)")
+	collectGPU := flag.Bool("gpu", false, "Collect NVIDIA GPU utilization, memory and temperature through nvidia-smi")
+	dockerHost := flag.String("docker-host", envOr("DOCKER_HOST", defaultDockerHost), "Docker Engine API address used by --container and container targets")
 	collectInterval := flag.Duration("collect-interval", 5*time.Second, "Metrics collection interval")
+	flushInterval := flag.Duration("flush-interval", 15*time.Second, "Maximum time samples are buffered before they are sent (0 = send every sample)")
+	maxBatchBytes := flag.Int("max-batch-bytes", defaultMaxBatchBytes, "Maximum uncompressed size of a metrics batch in bytes")
+	gzipBatches := flag.Bool("gzip", true, "Gzip-compress metrics batches")
+	retryBufferBytes := flag.Int("retry-buffer-bytes", defaultMaxRetryBytes, "Maximum uncompressed size of unsent metrics kept for retry while the control plane is unreachable; the oldest are dropped first")
+	otlpEndpoint := flag.String("otlp-endpoint", "", "Export traces to this OTLP collector, e.g. localhost:4317 or https://collector:4318 (\"stdout\" prints spans)")
+	otlpProtocol := flag.String("otlp-protocol", "grpc", "OTLP protocol: grpc or http")
+	logFile := flag.String("log-file", "", "Tail the server's log files matching these comma-separated globs and ship new lines to the control plane")
+	var logRedact redactFlags
+	flag.Var(&logRedact, "log-redact", "Replace matches of this regular expression in shipped log lines with "+redactedText+" (repeatable)")
+	scrapeURL := flag.String("scrape-url", "", "Scrape the server's Prometheus metrics endpoint at this URL with every sample, e.g. http://localhost:3000/metrics")
+	scrapeAllow := flag.String("scrape-allow", "", "Comma-separated globs of the series names to ship from --scrape-url, e.g. 'mcp_queue_depth,mcp_handler_seconds_*'")
+	logRateLimit := flag.Float64("log-rate-limit", defaultLogRateLimit, "Maximum log entries shipped per second; the rest are counted as dropped (0 = unlimited)")
 	flag.Parse()
 
-	if *pairKey == "" {
+	// Validate mutual exclusion: only one way of selecting the process
+	selectors := 0
+	for _, set := range []bool{*pid > 0, *listenPort > 0, *containerSelector != "", *podSelector != ""} {
+		if set {
+			selectors++
+		}
+	}
+	if selectors > 1 {
+		fmt.Fprintln(os.Stderr, "Error: --pid, --listen-port, --container and --pod are mutually exclusive (use one, or --target for more processes)")
+		os.Exit(1)
+	}
+
+	var specs []targetSpec
+	if selectors == 1 {
+		spec := targetSpec{pid: *pid, port: *listenPort, container: *containerSelector, pod: *podSelector}
+		if len(targets) > 0 {
+			spec.label = defaultTargetLabel
+		}
+		specs = append(specs, spec)
+	}
+	specs = append(specs, targets...)
+	if err := checkTargetLabels(specs); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	tlsConfig, err := tlsconfig.Client(*caCert, *tlsServerName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	client := tlsconfig.HTTPClient(tlsConfig)
+
+	pairKeys := collectPairKeys(*pairKey, specs)
+	if len(pairKeys) == 0 {
 		fmt.Fprintln(os.Stderr, "Error: --pair-key is required")
 		os.Exit(1)
 	}
 
-	// Validate mutual exclusion: --pid and --listen-port cannot both be provided
-	if *pid > 0 && *listenPort > 0 {
-		fmt.Fprintln(os.Stderr, "Error: --pid and --listen-port are mutually exclusive (cannot use both)")
+	var docker *dockerClient
+	if needsDocker(specs) {
+		client, err := newDockerClient(*dockerHost)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		docker = client
+	}
+
+	var gpus *gpuCollector
+	if *collectGPU {
+		collector, err := newGPUCollector()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		gpus = collector
+	}
+
+	logPatterns, err := splitGlobs(*logFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	// Validate PID exists if provided
-	var targetPID int
-	if *pid > 0 {
-		_, err := process.NewProcess(int32(*pid))
+	var scrape *scraper
+	if *scrapeURL != "" {
+		allow, err := splitGlobs(*scrapeAllow)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: PID %d does not exist or is not accessible: %v\n", *pid, err)
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(allow) == 0 {
+			fmt.Fprintln(os.Stderr, "Error: --scrape-allow is required with --scrape-url")
 			os.Exit(1)
 		}
-		targetPID = *pid
+		scrape = newScraper(*scrapeURL, allow)
+	}
+
+	// Validate PIDs exist if provided
+	for _, spec := range specs {
+		if spec.pid > 0 {
+			if _, err := process.NewProcess(int32(spec.pid)); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: PID %d does not exist or is not accessible: %v\n", spec.pid, err)
+				os.Exit(1)
+			}
+		}
 	}
 
 	hostname, _ := os.Hostname()
@@ -87,7 +231,13 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	reg, err := register(ctx, *controlPlaneURL, *agentToken, *pairKey, hostname)
+	tracer, err := otel.SetupTracing(ctx, "mcpdrill-agent", *otlpEndpoint, *otlpProtocol)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to set up tracing: %v\n", err)
+		os.Exit(1)
+	}
+
+	reg, err := register(ctx, client, *controlPlaneURL, *agentToken, pairKeys, *project, hostname)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to register with control plane: %v\n", err)
 		os.Exit(1)
@@ -95,33 +245,62 @@ func main() {
 
 	fmt.Printf("Agent registered: %s\n", reg.agentID)
 	fmt.Printf("Control plane: %s\n", *controlPlaneURL)
-	fmt.Printf("Pair key: %s\n", *pairKey)
+	fmt.Printf("Pair key: %s\n", strings.Join(pairKeys, ", "))
 	fmt.Printf("Registration RTT: %dms\n", reg.rttMs)
-	if reg.clockOffsetMs != 0 {
-		fmt.Printf("Clock offset: %+dms (server - local)\n", reg.clockOffsetMs)
+	if offset := reg.clock.offset(); offset != 0 {
+		fmt.Printf("Clock offset: %+dms (server - local)\n", offset)
 	}
-	if *listenPort > 0 {
-		fmt.Printf("Monitoring port: %d\n", *listenPort)
-	}
-	if *pid > 0 {
-		fmt.Printf("Monitoring PID: %d\n", *pid)
-	}
-
-	// Resolve targetPID from --listen-port if not already set from --pid
-	if *listenPort > 0 {
-		// Try to find process with retry (process may still be starting up)
-		foundPID := findProcessByPortWithRetry(ctx, *listenPort, 5, 1*time.Second, func(attempt int) {
-			log.Printf("PID lookup attempt %d failed for port %d, retrying...", attempt, *listenPort)
-		})
-		if foundPID > 0 {
-			targetPID = foundPID
-			fmt.Printf("Found process PID: %d\n", targetPID)
+	for _, spec := range specs {
+		if spec.label == "" {
+			fmt.Printf("Monitoring %s\n", spec.describe())
 		} else {
-			log.Printf("Warning: No process found on port %d after retries, continuing with host metrics only", *listenPort)
+			fmt.Printf("Monitoring %s: %s\n", spec.label, spec.describe())
 		}
 	}
-
-	go collectAndSend(ctx, *controlPlaneURL, *agentToken, reg.agentID, *pairKey, targetPID, *listenPort, *collectInterval, reg.clockOffsetMs)
+	if len(logPatterns) > 0 {
+		fmt.Printf("Tailing logs: %s\n", strings.Join(logPatterns, ", "))
+	}
+	if scrape != nil {
+		fmt.Printf("Scraping: %s (%s)\n", *scrapeURL, strings.Join(scrape.allow, ", "))
+	}
+	if *otlpEndpoint != "" {
+		fmt.Printf("Tracing to: %s (%s)\n", *otlpEndpoint, *otlpProtocol)
+	}
+
+	// Find each target's processes, retrying while they may still be
+	// starting up. The same lookup finds them again if they restart.
+	monitored := make([]*monitoredTarget, 0, len(specs))
+	for _, spec := range specs {
+		t := newMonitoredTarget(ctx, spec, docker)
+		t.resolve(ctx, 5, 1*time.Second)
+		monitored = append(monitored, t)
+	}
+
+	batcher := newMetricsBatcher(*controlPlaneURL, *agentToken, reg.agentID, pairKeys[0], *maxBatchBytes, *gzipBatches)
+	batcher.client = client
+	batcher.clock = reg.clock
+	batcher.maxRetryBytes = *retryBufferBytes
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		collectAndSend(ctx, batcher, monitored, gpus, scrape, *collectInterval, *flushInterval)
+	}()
+	if len(logPatterns) > 0 {
+		tailer := newLogTailer(*controlPlaneURL, *agentToken, reg.agentID, pairKeys[0], *gzipBatches, logPatterns, logRedact, *logRateLimit)
+		tailer.client = client
+		tailer.clock = reg.clock
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tailer.run(ctx)
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
 
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -129,24 +308,36 @@ func main() {
 
 	fmt.Println("\nShutting down agent...")
 	cancel()
-	time.Sleep(1 * time.Second)
+	select {
+	case <-done:
+	case <-time.After(shutdownFlushTimeout + time.Second):
+	}
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if err := tracer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Failed to flush traces: %v", err)
+	}
+	shutdownCancel()
 	fmt.Println("Agent stopped")
 }
 
 type registerResult struct {
-	agentID       string
-	clockOffsetMs int64
-	rttMs         int64
+	agentID string
+	clock   *clockSync
+	rttMs   int64
 }
 
-func register(ctx context.Context, baseURL, token, pairKey, hostname string) (*registerResult, error) {
+func register(ctx context.Context, client *http.Client, baseURL, token string, pairKeys []string, project, hostname string) (*registerResult, error) {
 	req := registerRequest{
-		PairKey:  pairKey,
+		Project:  project,
+		PairKey:  pairKeys[0],
 		Hostname: hostname,
 		OS:       runtime.GOOS,
 		Arch:     runtime.GOARCH,
 		Version:  "1.0.0",
 	}
+	if len(pairKeys) > 1 {
+		req.PairKeys = pairKeys
+	}
 	body, _ := json.Marshal(req)
 
 	beforeMs := time.Now().UnixMilli()
@@ -159,7 +350,7 @@ func register(ctx context.Context, baseURL, token, pairKey, hostname string) (*r
 		httpReq.Header.Set("Authorization", "Bearer "+token)
 	}
 
-	resp, err := http.DefaultClient.Do(httpReq)
+	resp, err := client.Do(httpReq)
 	if err != nil {
 		return nil, err
 	}
@@ -176,57 +367,92 @@ func register(ctx context.Context, baseURL, token, pairKey, hostname string) (*r
 		return nil, err
 	}
 
-	rttMs := afterMs - beforeMs
-	var clockOffsetMs int64
-	if result.ServerTime > 0 {
-		localMidpointMs := (beforeMs + afterMs) / 2
-		clockOffsetMs = result.ServerTime - localMidpointMs
-	}
+	clock := &clockSync{}
+	clock.observe(beforeMs, afterMs, result.ServerTime)
 
 	return &registerResult{
-		agentID:       result.AgentID,
-		clockOffsetMs: clockOffsetMs,
-		rttMs:         rttMs,
+		agentID: result.AgentID,
+		clock:   clock,
+		rttMs:   afterMs - beforeMs,
 	}, nil
 }
 
-func collectAndSend(ctx context.Context, baseURL, token, agentID, pairKey string, targetPID int, listenPort int, interval time.Duration, clockOffsetMs int64) {
+// shutdownFlushTimeout bounds the final flush of buffered samples on exit.
+const shutdownFlushTimeout = 5 * time.Second
+
+// collectAndSend collects a sample every interval and hands it to the
+// batcher, which is flushed every flushInterval and once more on shutdown.
+// Samples are stamped on the batcher's clock, which its flushes keep in sync
+// with the control plane. Each target is sampled in turn and looked for
+// again if its process exits. A single unlabelled target fills the sample's
+// Process and Container; otherwise every target is listed in Targets. GPU
+// metrics are added when gpus is set, and the server's own metrics when
+// scrape is.
+func collectAndSend(ctx context.Context, batcher *metricsBatcher, targets []*monitoredTarget, gpus *gpuCollector, scrape *scraper, interval, flushInterval time.Duration) {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
-	pidValid := targetPID > 0
-	currentPID := targetPID
+	var flushC <-chan time.Time
+	if flushInterval > 0 {
+		flushTicker := time.NewTicker(flushInterval)
+		defer flushTicker.Stop()
+		flushC = flushTicker.C
+	}
+
+	var gpuErr, scrapeErr string
 
 	for {
 		select {
 		case <-ctx.Done():
+			if err := batcher.flushOnShutdown(shutdownFlushTimeout); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to send metrics: %v\n", err)
+			}
 			return
+		case <-flushC:
+			if err := batcher.flush(ctx); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to send metrics: %v\n", err)
+			}
 		case <-ticker.C:
-			if !pidValid && listenPort > 0 {
-				newPID := findProcessByPort(listenPort)
-				if newPID > 0 {
-					currentPID = newPID
-					pidValid = true
-					log.Printf("Re-discovered process on port %d: PID %d", listenPort, newPID)
+			sample := collectMetrics(0, batcher.clock)
+			now := time.Now()
+			if len(targets) == 1 && targets[0].label == "" {
+				m := targets[0].collect(now)
+				sample.Process, sample.Container = m.Process, m.Container
+			} else {
+				for _, t := range targets {
+					sample.Targets = append(sample.Targets, t.collect(now))
 				}
 			}
-
-			sample := collectMetrics(currentPID, clockOffsetMs)
-
-			if currentPID > 0 && sample.Process == nil && pidValid {
-				pidValid = false
+			// Log GPU failures when they start or change, not every sample.
+			if err := gpus.collect(&sample); err != nil && err.Error() != gpuErr {
+				gpuErr = err.Error()
+				log.Printf("Warning: GPU metrics unavailable: %v", err)
+			} else if err == nil {
+				gpuErr = ""
+			}
+			var err error
+			sample.Scraped, err = scrape.scrape(ctx)
+			if err != nil && err.Error() != scrapeErr {
+				scrapeErr = err.Error()
+				log.Printf("Warning: scraping server metrics: %v", err)
+			} else if err == nil {
+				scrapeErr = ""
 			}
 
-			if err := sendMetrics(ctx, baseURL, token, agentID, pairKey, sample); err != nil {
+			err = batcher.add(ctx, sample)
+			if err == nil && flushInterval <= 0 {
+				err = batcher.flush(ctx)
+			}
+			if err != nil {
 				fmt.Fprintf(os.Stderr, "Failed to send metrics: %v\n", err)
 			}
 		}
 	}
 }
 
-func collectMetrics(targetPID int, clockOffsetMs int64) metricsSample {
+func collectMetrics(targetPID int, clock *clockSync) metricsSample {
 	sample := metricsSample{
-		Timestamp: time.Now().UnixMilli() + clockOffsetMs,
+		Timestamp: clock.now(time.Now().UnixMilli()),
 	}
 
 	// Collect host metrics
@@ -235,6 +461,9 @@ func collectMetrics(targetPID int, clockOffsetMs int64) metricsSample {
 		sample.Host = &agent.HostMetrics{
 			CPUPercent: cpuPercent[0],
 		}
+		if cores, err := cpu.Counts(true); err == nil {
+			sample.Host.CPUCores = cores
+		}
 
 		// Memory info
 		if memInfo, err := mem.VirtualMemory(); err == nil && memInfo != nil {
@@ -258,92 +487,59 @@ func collectMetrics(targetPID int, clockOffsetMs int64) metricsSample {
 
 	// Collect process metrics if monitoring a specific process
 	if targetPID > 0 {
-		proc, err := process.NewProcess(int32(targetPID))
-		if err != nil {
+		sample.Process = collectProcessMetrics(targetPID)
+		if sample.Process == nil {
 			log.Printf("Warning: process %d no longer exists, continuing with host metrics only", targetPID)
-		} else {
-			cpuPct, _ := proc.CPUPercent()
-			numThreads, _ := proc.NumThreads()
-
-			sample.Process = &agent.ProcessMetrics{
-				PID:        targetPID,
-				CPUPercent: cpuPct,
-				NumThreads: int(numThreads),
-			}
-
-			// Memory info
-			if memInfo, err := proc.MemoryInfo(); err == nil && memInfo != nil {
-				sample.Process.MemRSS = memInfo.RSS
-				sample.Process.MemVMS = memInfo.VMS
-			}
-
-			// File descriptors (Unix only, ignore error on Windows)
-			if numFDs, err := proc.NumFDs(); err == nil {
-				sample.Process.NumFDs = int(numFDs)
-			}
-
-			// Open connections
-			if conns, err := proc.Connections(); err == nil {
-				sample.Process.OpenConnections = len(conns)
-			}
 		}
 	}
 
 	return sample
 }
 
-func sendMetrics(ctx context.Context, baseURL, token, agentID, pairKey string, sample metricsSample) error {
-	req := metricsRequest{
-		AgentID: agentID,
-		PairKey: pairKey,
-		Samples: []metricsSample{sample},
+// collectProcessMetrics returns the metrics of pid, or nil if it does not
+// exist.
+func collectProcessMetrics(pid int) *agent.ProcessMetrics {
+	proc, err := process.NewProcess(int32(pid))
+	if err != nil {
+		return nil
 	}
-	body, _ := json.Marshal(req)
+	cpuPct, _ := proc.CPUPercent()
+	numThreads, _ := proc.NumThreads()
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/agents/v1/metrics", bytes.NewReader(body))
-	if err != nil {
-		return err
+	m := &agent.ProcessMetrics{
+		PID:        pid,
+		CPUPercent: cpuPct,
+		NumThreads: int(numThreads),
 	}
-	httpReq.Header.Set("Content-Type", "application/json")
-	if token != "" {
-		httpReq.Header.Set("Authorization", "Bearer "+token)
+
+	// Memory info
+	if memInfo, err := proc.MemoryInfo(); err == nil && memInfo != nil {
+		m.MemRSS = memInfo.RSS
+		m.MemVMS = memInfo.VMS
 	}
 
-	resp, err := http.DefaultClient.Do(httpReq)
-	if err != nil {
-		return err
+	// File descriptors (Unix only, ignore error on Windows)
+	if numFDs, err := proc.NumFDs(); err == nil {
+		m.NumFDs = int(numFDs)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("send metrics failed: %s", resp.Status)
+	// Open connections
+	if conns, err := proc.Connections(); err == nil {
+		m.OpenConnections = len(conns)
 	}
-	return nil
+	return m
 }
 
 func findProcessByPortWithRetry(ctx context.Context, port int, maxRetries int, retryDelay time.Duration, onRetry func(attempt int)) int {
-	for attempt := 0; attempt <= maxRetries; attempt++ {
-		pid := findProcessByPort(port)
-		if pid > 0 {
-			return pid
-		}
-
-		if attempt == maxRetries {
-			return 0
-		}
-
-		if onRetry != nil {
-			onRetry(attempt + 1)
-		}
+	return findPIDWithRetry(ctx, func() int { return findProcessByPort(port) }, maxRetries, retryDelay, onRetry)
+}
 
-		select {
-		case <-ctx.Done():
-			return 0
-		case <-time.After(retryDelay):
-		}
+// envOr returns the environment variable key, or def if it is unset.
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
 	}
-
-	return 0
+	return def
 }
 
 func findProcessByPort(port int) int {
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/agent"
+)
+
+const (
+	// scrapeTimeout bounds one scrape, which runs in the collection loop.
+	scrapeTimeout = 2 * time.Second
+	// maxScrapeBytes bounds the metrics page read per scrape.
+	maxScrapeBytes = 4 << 20
+	// maxScrapedSeries caps the series shipped per sample, so a broad
+	// allowlist cannot bloat every batch.
+	maxScrapedSeries = 500
+)
+
+// scraper reads the monitored server's Prometheus metrics endpoint and keeps
+// the series whose names match an allowlist. It is not safe for concurrent
+// use; collectAndSend owns it.
+type scraper struct {
+	client *http.Client
+	url    string
+	allow  []string // globs matched against series names
+}
+
+func newScraper(url string, allow []string) *scraper {
+	return &scraper{client: &http.Client{Timeout: scrapeTimeout}, url: url, allow: allow}
+}
+
+// scrape returns the allowed series, in page order. It returns an error
+// along with the series kept when there are more than maxScrapedSeries.
+func (s *scraper) scrape(ctx context.Context) ([]agent.ScrapedMetric, error) {
+	if s == nil {
+		return nil, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/plain;version=0.0.4")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", s.url, resp.Status)
+	}
+	return parsePrometheusText(io.LimitReader(resp.Body, maxScrapeBytes), s.allowed)
+}
+
+func (s *scraper) allowed(name string) bool {
+	for _, pattern := range s.allow {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePrometheusText parses the Prometheus text exposition format, which
+// OpenMetrics pages also parse as, keeping the series allow accepts.
+// Timestamps are ignored, as are values that are not finite, which JSON
+// cannot carry.
+func parsePrometheusText(r io.Reader, allow func(name string) bool) ([]agent.ScrapedMetric, error) {
+	types := make(map[string]string)
+	var metrics []agent.ScrapedMetric
+	truncated := false
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxScrapeBytes)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			// # TYPE <family> <type>
+			if fields := strings.Fields(line); len(fields) == 4 && fields[1] == "TYPE" {
+				types[fields[2]] = fields[3]
+			}
+			continue
+		}
+
+		name, labels, rest, err := parseSeries(line)
+		if err != nil {
+			return metrics, err
+		}
+		if !allow(name) {
+			continue
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			return metrics, fmt.Errorf("no value for %s", name)
+		}
+		value, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return metrics, fmt.Errorf("invalid value for %s: %w", name, err)
+		}
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			continue
+		}
+		if len(metrics) >= maxScrapedSeries {
+			truncated = true
+			continue
+		}
+		metrics = append(metrics, agent.ScrapedMetric{Name: name, Labels: labels, Type: familyType(types, name), Value: value})
+	}
+	if err := scanner.Err(); err != nil {
+		return metrics, err
+	}
+	if truncated {
+		return metrics, fmt.Errorf("more than %d series match the allowlist; the rest were dropped", maxScrapedSeries)
+	}
+	return metrics, nil
+}
+
+// familyType returns the declared type of the family a series belongs to,
+// looking through the suffixes of histogram, summary and counter series.
+func familyType(types map[string]string, name string) string {
+	if t, ok := types[name]; ok {
+		return t
+	}
+	for _, suffix := range []string{"_bucket", "_sum", "_count", "_total", "_created"} {
+		if base, ok := strings.CutSuffix(name, suffix); ok {
+			if t, ok := types[base]; ok {
+				return t
+			}
+		}
+	}
+	return ""
+}
+
+// parseSeries splits a sample line into its series name, labels and the
+// rest of the line.
+func parseSeries(line string) (name string, labels map[string]string, rest string, err error) {
+	end := strings.IndexAny(line, "{ \t")
+	if end <= 0 {
+		return "", nil, "", fmt.Errorf("invalid sample line %q", line)
+	}
+	name, rest = line[:end], line[end:]
+	if !strings.HasPrefix(rest, "{") {
+		return name, nil, rest, nil
+	}
+
+	labels = make(map[string]string)
+	rest = rest[1:]
+	for {
+		rest = strings.TrimLeft(rest, " \t,")
+		if strings.HasPrefix(rest, "}") {
+			return name, labels, rest[1:], nil
+		}
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 || len(rest) < eq+2 || rest[eq+1] != '"' {
+			return "", nil, "", fmt.Errorf("invalid labels in %q", line)
+		}
+		key := strings.TrimSpace(rest[:eq])
+		value, n, err := unquoteLabel(rest[eq+2:])
+		if err != nil {
+			return "", nil, "", fmt.Errorf("invalid labels in %q: %w", line, err)
+		}
+		labels[key] = value
+		rest = rest[eq+2+n:]
+	}
+}
+
+// unquoteLabel reads a label value up to its closing quote, resolving the
+// \\, \" and \n escapes, and returns the bytes consumed.
+func unquoteLabel(s string) (string, int, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			return b.String(), i + 1, nil
+		case '\\':
+			i++
+			if i == len(s) {
+				break
+			}
+			if s[i] == 'n' {
+				b.WriteByte('\n')
+			} else {
+				b.WriteByte(s[i])
+			}
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return "", 0, errors.New("unterminated label value")
+}
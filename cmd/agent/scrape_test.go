@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/agent"
+)
+
+const testMetricsPage = `# HELP mcp_queue_depth Requests waiting for a handler.
+# TYPE mcp_queue_depth gauge
+mcp_queue_depth 7
+# TYPE mcp_handler_seconds histogram
+mcp_handler_seconds_bucket{tool="search",le="0.1"} 40
+mcp_handler_seconds_bucket{tool="search",le="+Inf"} 42
+mcp_handler_seconds_sum{tool="search"} 3.5 1700000000000
+mcp_handler_seconds_count{tool="search"} 42
+# TYPE mcp_requests counter
+mcp_requests_total{path="C:\\tmp",msg="say \"hi\"\nbye"} 1027
+mcp_ratio NaN
+process_open_fds 12
+`
+
+func TestParsePrometheusText(t *testing.T) {
+	s := &scraper{allow: []string{"mcp_*"}}
+	metrics, err := parsePrometheusText(strings.NewReader(testMetricsPage), s.allowed)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	want := []agent.ScrapedMetric{
+		{Name: "mcp_queue_depth", Type: "gauge", Value: 7},
+		{Name: "mcp_handler_seconds_bucket", Labels: map[string]string{"tool": "search", "le": "0.1"}, Type: "histogram", Value: 40},
+		{Name: "mcp_handler_seconds_bucket", Labels: map[string]string{"tool": "search", "le": "+Inf"}, Type: "histogram", Value: 42},
+		{Name: "mcp_handler_seconds_sum", Labels: map[string]string{"tool": "search"}, Type: "histogram", Value: 3.5},
+		{Name: "mcp_handler_seconds_count", Labels: map[string]string{"tool": "search"}, Type: "histogram", Value: 42},
+		{Name: "mcp_requests_total", Labels: map[string]string{"path": `C:\tmp`, "msg": "say \"hi\"\nbye"}, Type: "counter", Value: 1027},
+	}
+	if !reflect.DeepEqual(metrics, want) {
+		t.Errorf("unexpected metrics:\n got %+v\nwant %+v", metrics, want)
+	}
+
+	if _, err := parsePrometheusText(strings.NewReader(`bad{label="x 1`), s.allowed); err == nil {
+		t.Error("expected an error for an unterminated label")
+	}
+}
+
+func TestParsePrometheusText_SeriesCap(t *testing.T) {
+	var page strings.Builder
+	for i := 0; i < maxScrapedSeries+10; i++ {
+		fmt.Fprintf(&page, "mcp_conn{id=\"%d\"} 1\n", i)
+	}
+	metrics, err := parsePrometheusText(strings.NewReader(page.String()), func(string) bool { return true })
+	if err == nil || len(metrics) != maxScrapedSeries {
+		t.Errorf("expected %d series and an error, got %d, %v", maxScrapedSeries, len(metrics), err)
+	}
+}
+
+func TestScraper_Scrape(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/metrics" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(testMetricsPage))
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	metrics, err := newScraper(srv.URL+"/metrics", []string{"mcp_queue_depth", "process_*"}).scrape(ctx)
+	if err != nil {
+		t.Fatalf("scrape failed: %v", err)
+	}
+	if len(metrics) != 2 || metrics[0].Name != "mcp_queue_depth" || metrics[1].Name != "process_open_fds" {
+		t.Errorf("expected the two allowed series, got %+v", metrics)
+	}
+
+	if _, err := newScraper(srv.URL+"/missing", []string{"*"}).scrape(ctx); err == nil {
+		t.Error("expected an error for a 404")
+	}
+	var none *scraper
+	if metrics, err := none.scrape(ctx); metrics != nil || err != nil {
+		t.Errorf("expected a nil scraper to do nothing, got %v, %v", metrics, err)
+	}
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/agent"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// defaultTargetLabel names the process picked by --pid, --listen-port,
+// --container or --pod when --target adds others.
+const defaultTargetLabel = "default"
+
+// targetSpec selects a process to monitor, from --target or from the
+// single-process flags.
+type targetSpec struct {
+	label   string
+	pairKey string
+
+	pid       int
+	port      int
+	process   string // glob matched against process names
+	container string
+	pod       string
+}
+
+// describe returns how the target's processes are found, for log messages.
+func (s targetSpec) describe() string {
+	switch {
+	case s.pid > 0:
+		return fmt.Sprintf("PID %d", s.pid)
+	case s.port > 0:
+		return fmt.Sprintf("port %d", s.port)
+	case s.process != "":
+		return "processes named " + s.process
+	case s.container != "":
+		return "container " + s.container
+	case s.pod != "":
+		return "pod " + s.pod
+	}
+	return "host"
+}
+
+// targetFlags collects repeated --target specs.
+type targetFlags []targetSpec
+
+func (t targetFlags) String() string {
+	return ""
+}
+
+func (t *targetFlags) Set(value string) error {
+	spec, err := parseTargetSpec(value)
+	if err != nil {
+		return err
+	}
+	*t = append(*t, spec)
+	return nil
+}
+
+// parseTargetSpec parses comma-separated key=value pairs: exactly one of
+// pid, port, process, container or pod, and optionally label and pair-key.
+// The label defaults to the selector, e.g. "port:3000".
+func parseTargetSpec(value string) (targetSpec, error) {
+	var spec targetSpec
+	var selector string
+	for _, field := range strings.Split(value, ",") {
+		key, val, ok := strings.Cut(strings.TrimSpace(field), "=")
+		val = strings.TrimSpace(val)
+		if !ok || val == "" {
+			return targetSpec{}, fmt.Errorf("invalid target %q: expected key=value, got %q", value, field)
+		}
+		switch key {
+		case "label":
+			spec.label = val
+			continue
+		case "pair-key":
+			spec.pairKey = val
+			continue
+		case "pid", "port":
+			n, err := strconv.Atoi(val)
+			if err != nil || n <= 0 {
+				return targetSpec{}, fmt.Errorf("invalid target %q: %s must be a positive integer", value, key)
+			}
+			if key == "pid" {
+				spec.pid = n
+			} else {
+				spec.port = n
+			}
+		case "process":
+			if _, err := filepath.Match(val, ""); err != nil {
+				return targetSpec{}, fmt.Errorf("invalid target %q: bad process pattern: %w", value, err)
+			}
+			spec.process = val
+		case "container":
+			spec.container = val
+		case "pod":
+			spec.pod = val
+		default:
+			return targetSpec{}, fmt.Errorf("invalid target %q: unknown key %q", value, key)
+		}
+		if selector != "" {
+			return targetSpec{}, fmt.Errorf("invalid target %q: %s and %s are mutually exclusive", value, selector, key)
+		}
+		selector = key + ":" + val
+	}
+	if selector == "" {
+		return targetSpec{}, fmt.Errorf("invalid target %q: set one of pid, port, process, container or pod", value)
+	}
+	if spec.label == "" {
+		spec.label = selector
+	}
+	return spec, nil
+}
+
+// checkTargetLabels rejects targets sharing a label, which would make
+// their samples indistinguishable.
+func checkTargetLabels(specs []targetSpec) error {
+	seen := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		if seen[spec.label] {
+			return fmt.Errorf("duplicate target label %q", spec.label)
+		}
+		seen[spec.label] = true
+	}
+	return nil
+}
+
+// collectPairKeys returns the comma-separated pair keys of --pair-key
+// followed by those of the targets, without duplicates.
+func collectPairKeys(flagValue string, specs []targetSpec) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	add := func(key string) {
+		if key = strings.TrimSpace(key); key != "" && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range strings.Split(flagValue, ",") {
+		add(key)
+	}
+	for _, spec := range specs {
+		add(spec.pairKey)
+	}
+	return keys
+}
+
+// needsDocker reports whether any target selects a Docker container.
+func needsDocker(specs []targetSpec) bool {
+	for _, spec := range specs {
+		if spec.container != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// monitoredTarget follows the processes of one target across restarts. It
+// is not safe for concurrent use; collectAndSend owns it.
+type monitoredTarget struct {
+	label   string
+	pairKey string
+	desc    string
+
+	// discover finds the target's processes, lowest PID first; it is nil
+	// for a fixed PID. With rescan it runs before every sample, as the
+	// processes matching a name come and go; otherwise only once the
+	// process is lost.
+	discover func() []int
+	rescan   bool
+
+	pids    []int
+	valid   bool
+	cgroups *cgroupCollector
+}
+
+func newMonitoredTarget(ctx context.Context, spec targetSpec, docker *dockerClient) *monitoredTarget {
+	t := &monitoredTarget{
+		label:   spec.label,
+		pairKey: spec.pairKey,
+		desc:    spec.describe(),
+		cgroups: newCgroupCollector(),
+	}
+	switch {
+	case spec.pid > 0:
+		t.pids = []int{spec.pid}
+		t.valid = true
+	case spec.port > 0:
+		t.discover = single(func() int { return findProcessByPort(spec.port) })
+	case spec.process != "":
+		t.discover = func() []int { return findProcessesByName(spec.process) }
+		t.rescan = true
+	case spec.container != "":
+		t.discover = single(func() int {
+			pid, err := docker.containerPID(ctx, spec.container)
+			if err != nil {
+				log.Printf("Container lookup failed: %v", err)
+			}
+			return pid
+		})
+	case spec.pod != "":
+		t.discover = single(func() int {
+			pid, err := findPodPID(defaultProcRoot, defaultPodLogRoot, spec.pod)
+			if err != nil {
+				log.Printf("Pod lookup failed: %v", err)
+			}
+			return pid
+		})
+	}
+	return t
+}
+
+// single adapts a lookup of one PID, 0 if none, to monitoredTarget.discover.
+func single(find func() int) func() []int {
+	return func() []int {
+		if pid := find(); pid > 0 {
+			return []int{pid}
+		}
+		return nil
+	}
+}
+
+// name returns the target as it appears in log messages.
+func (t *monitoredTarget) name() string {
+	if t.label == "" {
+		return t.desc
+	}
+	return fmt.Sprintf("target %s (%s)", t.label, t.desc)
+}
+
+// resolve looks for the target's processes, retrying while they may still
+// be starting up.
+func (t *monitoredTarget) resolve(ctx context.Context, maxRetries int, retryDelay time.Duration) {
+	if t.discover == nil {
+		return
+	}
+	findPIDWithRetry(ctx, func() int {
+		t.pids = t.discover()
+		if len(t.pids) == 0 {
+			return 0
+		}
+		return t.pids[0]
+	}, maxRetries, retryDelay, func(attempt int) {
+		log.Printf("PID lookup attempt %d failed for %s, retrying...", attempt, t.name())
+	})
+	t.valid = len(t.pids) > 0
+	if t.valid {
+		fmt.Printf("Found process PID for %s: %s\n", t.name(), joinPIDs(t.pids))
+	} else {
+		log.Printf("Warning: No process found for %s after retries, continuing without it", t.name())
+	}
+}
+
+// collect samples the target's processes at now, looking for them again
+// first if they were lost or matched by name.
+func (t *monitoredTarget) collect(now time.Time) agent.TargetMetrics {
+	if t.discover != nil && (t.rescan || !t.valid) {
+		pids := t.discover()
+		if len(pids) > 0 && !t.valid {
+			log.Printf("Re-discovered %s: PID %s", t.name(), joinPIDs(pids))
+		}
+		t.pids = pids
+		t.valid = len(pids) > 0
+	}
+
+	m := agent.TargetMetrics{Label: t.label, PairKey: t.pairKey}
+	if !t.valid {
+		return m
+	}
+	for _, pid := range t.pids {
+		p := collectProcessMetrics(pid)
+		if p == nil {
+			continue
+		}
+		if m.Process == nil {
+			m.Process = p
+		} else {
+			m.Process.Add(p)
+		}
+	}
+	if m.Process == nil {
+		t.valid = false
+		if !t.rescan {
+			log.Printf("Warning: %s (PID %s) no longer exists, looking for it again", t.name(), joinPIDs(t.pids))
+		}
+		return m
+	}
+	m.Container = t.cgroups.collect(m.Process.PID, now)
+	return m
+}
+
+// findProcessesByName returns the processes whose name matches pattern, a
+// glob, lowest PID first. The agent itself is never matched.
+func findProcessesByName(pattern string) []int {
+	procs, err := process.Processes()
+	if err != nil {
+		return nil
+	}
+	self := int32(os.Getpid())
+	var pids []int
+	for _, p := range procs {
+		if p.Pid == self {
+			continue
+		}
+		name, err := p.Name()
+		if err != nil {
+			continue
+		}
+		if ok, _ := filepath.Match(pattern, name); ok {
+			pids = append(pids, int(p.Pid))
+		}
+	}
+	sort.Ints(pids)
+	return pids
+}
+
+func joinPIDs(pids []int) string {
+	s := make([]string, len(pids))
+	for i, pid := range pids {
+		s[i] = strconv.Itoa(pid)
+	}
+	return strings.Join(s, ", ")
+}
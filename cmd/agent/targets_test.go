@@ -0,0 +1,129 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseTargetSpec(t *testing.T) {
+	tests := []struct {
+		value string
+		want  targetSpec
+	}{
+		{"label=api,port=3000", targetSpec{label: "api", port: 3000}},
+		{"pid=42,pair-key=run-a", targetSpec{label: "pid:42", pairKey: "run-a", pid: 42}},
+		{"process=gunicorn*", targetSpec{label: "process:gunicorn*", process: "gunicorn*"}},
+		{"label=web, container=app=web", targetSpec{label: "web", container: "app=web"}},
+		{"pod=mcp/server-0", targetSpec{label: "pod:mcp/server-0", pod: "mcp/server-0"}},
+	}
+	for _, tt := range tests {
+		got, err := parseTargetSpec(tt.value)
+		if err != nil {
+			t.Errorf("parseTargetSpec(%q) failed: %v", tt.value, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseTargetSpec(%q) = %+v, want %+v", tt.value, got, tt.want)
+		}
+	}
+
+	for _, value := range []string{"", "label=api", "port=0", "port=80,pid=1", "process=[", "host=x", "port"} {
+		if _, err := parseTargetSpec(value); err == nil {
+			t.Errorf("parseTargetSpec(%q) should fail", value)
+		}
+	}
+}
+
+func TestCollectPairKeysAndLabels(t *testing.T) {
+	specs := []targetSpec{{label: "a", pairKey: "run-b"}, {label: "b"}, {label: "c", pairKey: "run-c"}}
+	got := collectPairKeys("run-a, run-b,", specs)
+	if want := []string{"run-a", "run-b", "run-c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("collectPairKeys = %v, want %v", got, want)
+	}
+	if err := checkTargetLabels(specs); err != nil {
+		t.Errorf("unexpected error for distinct labels: %v", err)
+	}
+	if err := checkTargetLabels(append(specs, targetSpec{label: "b"})); err == nil {
+		t.Error("expected an error for a duplicate label")
+	}
+}
+
+func TestMonitoredTarget_Collect(t *testing.T) {
+	self, parent := os.Getpid(), os.Getppid()
+	var found []int
+	target := &monitoredTarget{
+		label:    "workers",
+		pairKey:  "run-a",
+		discover: func() []int { return found },
+		rescan:   true,
+	}
+
+	m := target.collect(time.Now())
+	if m.Label != "workers" || m.PairKey != "run-a" || m.Process != nil {
+		t.Fatalf("expected labelled metrics without a process, got %+v", m)
+	}
+
+	found = []int{min(self, parent), max(self, parent)}
+	m = target.collect(time.Now())
+	if m.Process == nil {
+		t.Fatal("expected process metrics once processes are found")
+	}
+	if !reflect.DeepEqual(m.Process.PIDs, found) || m.Process.PID != found[0] {
+		t.Errorf("expected metrics summed over %v, got PID %d PIDs %v", found, m.Process.PID, m.Process.PIDs)
+	}
+	if m.Process.NumThreads < 2 {
+		t.Errorf("expected threads of both processes, got %d", m.Process.NumThreads)
+	}
+
+	found = nil
+	if m = target.collect(time.Now()); m.Process != nil || target.valid {
+		t.Errorf("expected the target lost once no process matches, got %+v", m)
+	}
+}
+
+func TestMonitoredTarget_FixedPIDLost(t *testing.T) {
+	target := &monitoredTarget{label: "gone", pids: []int{999999999}, valid: true}
+	if m := target.collect(time.Now()); m.Process != nil {
+		t.Fatalf("expected no metrics for a missing PID, got %+v", m.Process)
+	}
+	if target.valid {
+		t.Error("expected the target marked lost")
+	}
+}
+
+func TestFindProcessesByName(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep not available")
+	}
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+
+	pids := findProcessesByName("sle?p")
+	if !containsPID(pids, cmd.Process.Pid) {
+		t.Errorf("expected PID %d among %v", cmd.Process.Pid, pids)
+	}
+	if pids := findProcessesByName("no-such-process-*"); len(pids) != 0 {
+		t.Errorf("expected no match, got %v", pids)
+	}
+	if joined := joinPIDs([]int{1, 22}); joined != "1, 22" {
+		t.Errorf("joinPIDs = %q", joined)
+	}
+}
+
+func containsPID(pids []int, pid int) bool {
+	for _, p := range pids {
+		if p == pid {
+			return true
+		}
+	}
+	return false
+}
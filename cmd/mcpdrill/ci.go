@@ -0,0 +1,408 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Run states a run does not leave.
+var terminalStates = map[string]bool{"completed": true, "failed": true, "aborted": true}
+
+// sloFailedError is returned for a run that completed with failed SLOs.
+type sloFailedError struct {
+	runID  string
+	failed int
+}
+
+func (e *sloFailedError) Error() string {
+	return fmt.Sprintf("run %s failed %d SLO(s)", e.runID, e.failed)
+}
+
+// runEndedError is returned for a run that ended failed or aborted.
+type runEndedError struct {
+	runID, state, reason string
+}
+
+func (e *runEndedError) Error() string {
+	if e.reason == "" {
+		return fmt.Sprintf("run %s %s", e.runID, e.state)
+	}
+	return fmt.Sprintf("run %s %s: %s", e.runID, e.state, e.reason)
+}
+
+// ciEnv is where the CI system the CLI runs in takes its outputs.
+type ciEnv struct {
+	github      bool   // GitHub Actions: workflow commands for annotations
+	summaryFile string // Markdown summary, appended to
+	outputFile  string // GitHub step outputs, appended to
+}
+
+// runExec implements "mcpdrill run [flags] <config>": it creates and starts
+// a run and, with --wait or --ci, follows it until it ends.
+func (c *cli) runExec(ctx context.Context, args []string) error {
+	fs := c.newFlagSet("run")
+	var start startFlags
+	start.register(fs)
+	var tags tagFlags
+	fs.Var(&tags, "tag", "Tag the run as key=value (repeatable)")
+	wait := fs.Bool("wait", false, "Wait for the run to end; exit 1 if it fails or is aborted, 4 if an SLO fails")
+	ci := fs.Bool("ci", false, "CI mode: --wait, plus a Markdown step summary and failure annotations")
+	summaryFile := fs.String("summary-file", os.Getenv("GITHUB_STEP_SUMMARY"), "Append the --ci Markdown summary to this file (default $GITHUB_STEP_SUMMARY)")
+	artifactsDir := fs.String("artifacts-dir", "", "Download the run's report artifacts into this directory once it ends")
+	waitTimeout := fs.Duration("wait-timeout", 0, "Abort the run if it has not ended within this duration (0 waits indefinitely)")
+	pollInterval := fs.Duration("poll-interval", 2*time.Second, "How often to poll the run's state while waiting")
+	positional, err := parseArgs(fs, args, 1, 1, startFlagsUsage+" [--tag key=value] [--wait] [--ci] [--summary-file FILE] [--artifacts-dir DIR] [--wait-timeout D] <config.json|config.yaml|->")
+	if err != nil {
+		return err
+	}
+	*wait = *wait || *ci
+	if *artifactsDir != "" && !*wait {
+		return usagef("--artifacts-dir requires --wait or --ci")
+	}
+	if *pollInterval <= 0 {
+		return usagef("invalid --poll-interval %s: must be positive", *pollInterval)
+	}
+
+	// Progress goes to stderr in JSON mode, so stdout holds one document.
+	progress := c.stdout
+	if c.output == "json" {
+		progress = c.stderr
+	}
+
+	runID, _, err := c.createRun(ctx, positional[0], tags)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(progress, "Created run %s\n", runID)
+	var started runStateResponse
+	data, err := c.client.do(ctx, http.MethodPost, runPath(runID, "start"), start.body(c.actor), &started)
+	if err != nil {
+		return err
+	}
+	if !*wait {
+		if c.output == "json" {
+			return c.printJSON(data)
+		}
+		fmt.Fprintf(c.stdout, "Started run %s (state: %s)\n", runID, started.State)
+		return nil
+	}
+	fmt.Fprintf(progress, "Started run %s (state: %s)\n", runID, started.State)
+
+	runData, run, err := c.waitForRun(ctx, progress, runID, started.State, *waitTimeout, *pollInterval)
+	if err != nil {
+		return err
+	}
+
+	// A run that failed before any operation has no metrics.
+	var metrics *runMetrics
+	metricsData, err := c.client.do(ctx, http.MethodGet, runPath(runID, "metrics"), nil, &metrics)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "Warning: no metrics for run %s: %v\n", runID, err)
+		metrics, metricsData = nil, nil
+	}
+	if *artifactsDir != "" {
+		if err := c.downloadReports(ctx, progress, runID, *artifactsDir); err != nil {
+			fmt.Fprintf(c.stderr, "Warning: downloading report artifacts of run %s: %v\n", runID, err)
+		}
+	}
+
+	if err := c.printOutcome(runData, metricsData, run, metrics); err != nil {
+		return err
+	}
+	if *ci {
+		env := ciEnv{
+			github:      os.Getenv("GITHUB_ACTIONS") == "true",
+			summaryFile: *summaryFile,
+			outputFile:  os.Getenv("GITHUB_OUTPUT"),
+		}
+		if err := c.reportToCI(progress, env, run, metrics); err != nil {
+			return err
+		}
+	}
+	return runOutcome(run)
+}
+
+// waitForRun polls a run until it reaches a terminal state, printing each
+// state it passes through. The run is aborted if it does not end within
+// timeout or the wait is interrupted, so a cancelled pipeline does not leave
+// it running.
+func (c *cli) waitForRun(ctx context.Context, progress io.Writer, runID, state string, timeout, interval time.Duration) (json.RawMessage, *runView, error) {
+	waitCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-waitCtx.Done():
+			reason := "interrupted while waiting"
+			if ctx.Err() == nil {
+				reason = fmt.Sprintf("did not end within %s", timeout)
+			}
+			return nil, nil, c.abortWaited(runID, reason)
+		case <-ticker.C:
+		}
+
+		var run runView
+		data, err := c.client.do(waitCtx, http.MethodGet, runPath(runID), nil, &run)
+		var connErr *connError
+		switch {
+		case err == nil:
+		case waitCtx.Err() != nil:
+			continue
+		case errors.As(err, &connErr):
+			// Ride out a control plane restart rather than failing the
+			// pipeline; the wait timeout still bounds the wait.
+			fmt.Fprintf(c.stderr, "Warning: polling run %s: %v\n", runID, err)
+			continue
+		default:
+			return nil, nil, err
+		}
+		if run.State != state {
+			fmt.Fprintf(progress, "Run %s: %s\n", runID, run.State)
+			state = run.State
+		}
+		if terminalStates[run.State] {
+			return data, &run, nil
+		}
+	}
+}
+
+// abortWaited aborts a run the CLI gave up waiting for and returns the
+// error to exit with.
+func (c *cli) abortWaited(runID, reason string) error {
+	// The wait's context is done; the request is bounded by --timeout.
+	body := map[string]interface{}{"actor": c.actor, "reason": "mcpdrill " + reason}
+	if _, err := c.client.do(context.Background(), http.MethodPost, "/runs/"+url.PathEscape(runID)+":abort", body, nil); err != nil {
+		return fmt.Errorf("run %s %s, and aborting it failed: %w", runID, reason, err)
+	}
+	return &runEndedError{runID: runID, state: "aborted", reason: reason}
+}
+
+// printOutcome prints the ended run and its metrics: as one JSON document
+// of both raw responses, or as run status and report tables.
+func (c *cli) printOutcome(runData, metricsData json.RawMessage, run *runView, metrics *runMetrics) error {
+	if c.output == "json" {
+		if metricsData == nil {
+			metricsData = json.RawMessage("null")
+		}
+		return c.printJSON(json.RawMessage(fmt.Sprintf(`{"run":%s,"metrics":%s}`, runData, metricsData)))
+	}
+	fmt.Fprintln(c.stdout)
+	if err := c.printRun(run); err != nil {
+		return err
+	}
+	if metrics == nil {
+		return nil
+	}
+	fmt.Fprintln(c.stdout)
+	return c.printMetrics(metrics)
+}
+
+// runOutcome maps how a run ended to the error the CLI exits with.
+func runOutcome(run *runView) error {
+	if run.State != "completed" {
+		reason := ""
+		if run.StopReason != nil {
+			reason = run.StopReason.Reason
+		}
+		return &runEndedError{runID: run.RunID, state: run.State, reason: reason}
+	}
+	if failed := failedSLOs(run); len(failed) > 0 {
+		return &sloFailedError{runID: run.RunID, failed: len(failed)}
+	}
+	return nil
+}
+
+type sloResult struct {
+	Name    string
+	Message string
+}
+
+func failedSLOs(run *runView) []sloResult {
+	if run.SLOs == nil {
+		return nil
+	}
+	var failed []sloResult
+	for _, r := range run.SLOs.Results {
+		if !r.Passed {
+			failed = append(failed, sloResult{Name: r.Name, Message: r.Message})
+		}
+	}
+	return failed
+}
+
+// downloadReports saves the run's report artifacts into dir.
+func (c *cli) downloadReports(ctx context.Context, progress io.Writer, runID, dir string) error {
+	var list struct {
+		Artifacts []struct {
+			ArtifactType string `json:"artifact_type"`
+			Filename     string `json:"filename"`
+			DownloadURL  string `json:"download_url"`
+		} `json:"artifacts"`
+	}
+	if _, err := c.client.do(ctx, http.MethodGet, runPath(runID, "artifacts"), nil, &list); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, a := range list.Artifacts {
+		if a.ArtifactType != "report" {
+			continue
+		}
+		path := filepath.Join(dir, filepath.Base(a.Filename))
+		if err := c.client.download(ctx, a.DownloadURL, path); err != nil {
+			return fmt.Errorf("%s: %w", a.Filename, err)
+		}
+		fmt.Fprintf(progress, "Saved %s\n", path)
+	}
+	return nil
+}
+
+// reportToCI annotates the failures of an ended run and writes its summary
+// and step outputs.
+func (c *cli) reportToCI(progress io.Writer, env ciEnv, run *runView, metrics *runMetrics) error {
+	for _, a := range annotations(run) {
+		fmt.Fprintln(progress, a.format(env.github))
+	}
+	if env.summaryFile != "" {
+		if err := appendFile(env.summaryFile, markdownSummary(run, metrics)); err != nil {
+			return fmt.Errorf("write step summary: %w", err)
+		}
+	}
+	if env.github && env.outputFile != "" {
+		verdict := ""
+		if run.SLOs != nil {
+			verdict = run.SLOs.Verdict
+		}
+		outputs := fmt.Sprintf("run_id=%s\nstate=%s\nslo_verdict=%s\n", run.RunID, run.State, verdict)
+		if err := appendFile(env.outputFile, outputs); err != nil {
+			return fmt.Errorf("write step outputs: %w", err)
+		}
+	}
+	return nil
+}
+
+// annotation is one failure reported to the CI system.
+type annotation struct {
+	title, message string
+}
+
+// format renders the annotation as a GitHub Actions workflow command, or
+// elsewhere as a line a problem matcher or grep can pick out.
+func (a annotation) format(github bool) string {
+	if github {
+		return "::error title=" + escapeWorkflowProperty(a.title) + "::" + escapeWorkflowData(a.message)
+	}
+	return "error: " + a.title + ": " + strings.ReplaceAll(a.message, "\n", " ")
+}
+
+func annotations(run *runView) []annotation {
+	var out []annotation
+	if run.State != "completed" {
+		msg := "run " + run.RunID + " " + run.State
+		if run.StopReason != nil && run.StopReason.Reason != "" {
+			msg += ": " + run.StopReason.Reason
+		}
+		out = append(out, annotation{title: "mcpdrill run " + run.State, message: msg})
+	}
+	for _, r := range failedSLOs(run) {
+		msg := "SLO " + r.Name + " failed in run " + run.RunID
+		if r.Message != "" {
+			msg += ": " + r.Message
+		}
+		out = append(out, annotation{title: "SLO " + r.Name, message: msg})
+	}
+	return out
+}
+
+// escapeWorkflowData escapes the message of a workflow command.
+func escapeWorkflowData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeWorkflowProperty escapes a property value of a workflow command.
+func escapeWorkflowProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// markdownSummary renders an ended run as a Markdown step summary.
+func markdownSummary(run *runView, metrics *runMetrics) string {
+	var b strings.Builder
+	verdict := ""
+	if run.SLOs != nil {
+		verdict = ", SLOs " + run.SLOs.Verdict
+	}
+	fmt.Fprintf(&b, "## mcpdrill run `%s`: %s%s\n\n", run.RunID, run.State, verdict)
+	if run.StopReason != nil && run.StopReason.Reason != "" && run.State != "completed" {
+		fmt.Fprintf(&b, "Reason: %s\n\n", markdownCell(run.StopReason.Reason))
+	}
+
+	if metrics != nil {
+		b.WriteString("| Metric | Value |\n|---|---|\n")
+		fmt.Fprintf(&b, "| Duration | %s |\n", time.Duration(metrics.DurationMs)*time.Millisecond)
+		fmt.Fprintf(&b, "| Operations | %d (%d failed) |\n", metrics.TotalOps, metrics.FailedOps)
+		fmt.Fprintf(&b, "| Error rate | %.2f%% |\n", 100*metrics.ErrorRate)
+		fmt.Fprintf(&b, "| Throughput | %.1f ops/s |\n", metrics.Throughput)
+		fmt.Fprintf(&b, "| Latency p50 / p95 / p99 | %.0f / %.0f / %.0f ms |\n\n", metrics.LatencyP50, metrics.LatencyP95, metrics.LatencyP99)
+	}
+
+	if run.SLOs != nil && len(run.SLOs.Results) > 0 {
+		b.WriteString("### SLOs\n\n| SLO | Result | Details |\n|---|---|---|\n")
+		for _, r := range run.SLOs.Results {
+			result := "**FAIL**"
+			if r.Passed {
+				result = "PASS"
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", markdownCell(r.Name), result, markdownCell(r.Message))
+		}
+		b.WriteString("\n")
+	}
+
+	if metrics != nil && len(metrics.ByTool) > 0 {
+		tools := make([]string, 0, len(metrics.ByTool))
+		for name := range metrics.ByTool {
+			tools = append(tools, name)
+		}
+		sort.Strings(tools)
+		b.WriteString("### Tools\n\n| Tool | Ops | Failed | Error rate | p50 ms | p95 ms | p99 ms |\n|---|---:|---:|---:|---:|---:|---:|\n")
+		for _, name := range tools {
+			t := metrics.ByTool[name]
+			fmt.Fprintf(&b, "| %s | %d | %d | %.2f%% | %d | %d | %d |\n", markdownCell(name), t.TotalOps, t.FailureOps, 100*t.ErrorRate, t.LatencyP50, t.LatencyP95, t.LatencyP99)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// markdownCell keeps text from breaking out of a table cell.
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\r", " ", "\n", " ").Replace(s)
+}
+
+func appendFile(path, content string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
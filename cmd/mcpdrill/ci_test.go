@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// fakeRunServer serves a run that is running for the first polls, then in
+// finalState, and records aborts.
+func fakeRunServer(t *testing.T, finalState string, pollsUntilEnd int32) (*httptest.Server, *atomic.Bool) {
+	t.Helper()
+	var polls atomic.Int32
+	aborted := &atomic.Bool{}
+	writeJSON := func(w http.ResponseWriter, v interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(v)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /runs", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]string{"run_id": "run_1"})
+	})
+	mux.HandleFunc("POST /runs/run_1/start", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]string{"run_id": "run_1", "state": "preflight_running"})
+	})
+	mux.HandleFunc("POST /runs/run_1:abort", func(w http.ResponseWriter, r *http.Request) {
+		aborted.Store(true)
+		writeJSON(w, map[string]string{"run_id": "run_1", "state": "aborted"})
+	})
+	mux.HandleFunc("GET /runs/run_1", func(w http.ResponseWriter, r *http.Request) {
+		if polls.Add(1) <= pollsUntilEnd {
+			writeJSON(w, map[string]string{"run_id": "run_1", "state": "baseline_running"})
+			return
+		}
+		writeJSON(w, map[string]interface{}{
+			"run_id": "run_1",
+			"state":  finalState,
+			"slos": map[string]interface{}{
+				"verdict": "FAIL",
+				"results": []map[string]interface{}{
+					{"name": "fast", "passed": false, "message": "p95_latency_ms was 812, objective < 500"},
+					{"name": "reliable", "passed": true},
+				},
+			},
+		})
+	})
+	mux.HandleFunc("GET /runs/run_1/metrics", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{
+			"run_id": "run_1", "total_ops": 1000, "failed_ops": 5, "error_rate": 0.005, "throughput": 50,
+			"latency_p50_ms": 120, "latency_p95_ms": 812, "latency_p99_ms": 990, "duration_ms": 20000,
+			"by_tool": map[string]interface{}{"search": map[string]interface{}{"total_ops": 1000, "failure_ops": 5}},
+		})
+	})
+	mux.HandleFunc("GET /runs/run_1/artifacts", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{"run_id": "run_1", "artifacts": []map[string]string{
+			{"artifact_type": "report", "filename": "report.junit.xml", "download_url": "/runs/run_1/artifacts/report/report.junit.xml"},
+			{"artifact_type": "telemetry", "filename": "operations.jsonl.gz", "download_url": "/runs/run_1/artifacts/telemetry/operations.jsonl.gz"},
+		}})
+	})
+	mux.HandleFunc("GET /runs/run_1/artifacts/report/report.junit.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<testsuites/>"))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, aborted
+}
+
+func TestCLI_RunCI(t *testing.T) {
+	server, _ := fakeRunServer(t, "completed", 2)
+	dir := t.TempDir()
+	summary := filepath.Join(dir, "summary.md")
+	outputs := filepath.Join(dir, "outputs")
+	reports := filepath.Join(dir, "reports")
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_OUTPUT", outputs)
+
+	code, out, errOut := runCLI(t, "--endpoint", server.URL, "run", "--ci", "--poll-interval", "10ms",
+		"--summary-file", summary, "--artifacts-dir", reports, fixturePath(t))
+	if code != exitSLOFailed {
+		t.Fatalf("expected exit %d for a failed SLO, got %d: %s%s", exitSLOFailed, code, out, errOut)
+	}
+	for _, want := range []string{"Created run run_1", "Run run_1: baseline_running", "Run run_1: completed", "SLO verdict:", "Throughput:",
+		"::error title=SLO fast::SLO fast failed in run run_1: p95_latency_ms was 812, objective < 500"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	data, err := os.ReadFile(summary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"## mcpdrill run `run_1`: completed, SLOs FAIL", "| fast | **FAIL** |", "| reliable | PASS |", "| search | 1000 | 5 |"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected summary to contain %q, got:\n%s", want, data)
+		}
+	}
+	if data, _ := os.ReadFile(outputs); string(data) != "run_id=run_1\nstate=completed\nslo_verdict=FAIL\n" {
+		t.Errorf("unexpected step outputs %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(reports, "report.junit.xml")); string(data) != "<testsuites/>" {
+		t.Errorf("expected the JUnit report to be downloaded, got %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(reports, "operations.jsonl.gz")); err == nil {
+		t.Error("expected only report artifacts to be downloaded")
+	}
+}
+
+func TestCLI_RunWait(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "")
+	server, _ := fakeRunServer(t, "failed", 0)
+	code, out, _ := runCLI(t, "--endpoint", server.URL, "--output", "json", "run", "--wait", "--poll-interval", "10ms", fixturePath(t))
+	if code != exitError {
+		t.Errorf("expected exit %d for a failed run, got %d", exitError, code)
+	}
+	var doc struct {
+		Run     runView    `json:"run"`
+		Metrics runMetrics `json:"metrics"`
+	}
+	if err := json.Unmarshal([]byte(out), &doc); err != nil || doc.Run.State != "failed" || doc.Metrics.TotalOps != 1000 {
+		t.Errorf("expected one JSON document of the run and its metrics, got %v: %s", err, out)
+	}
+
+	server, aborted := fakeRunServer(t, "completed", 1<<30)
+	code, _, errOut := runCLI(t, "--endpoint", server.URL, "run", "--wait", "--wait-timeout", "50ms", "--poll-interval", "10ms", fixturePath(t))
+	if code != exitError || !aborted.Load() || !strings.Contains(errOut, "did not end within 50ms") {
+		t.Errorf("expected the run to be aborted after the wait timeout, got exit %d, aborted %v: %s", code, aborted.Load(), errOut)
+	}
+
+	code, _, _ = runCLI(t, "--endpoint", server.URL, "run", "--artifacts-dir", t.TempDir(), fixturePath(t))
+	if code != exitConfig {
+		t.Errorf("expected exit %d for --artifacts-dir without --wait, got %d", exitConfig, code)
+	}
+}
+
+func TestAnnotationFormat(t *testing.T) {
+	a := annotation{title: "SLO a:b,c", message: "100% over\nbudget"}
+	if got, want := a.format(true), "::error title=SLO a%3Ab%2Cc::100%25 over%0Abudget"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := a.format(false), "error: SLO a:b,c: 100% over budget"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Auth modes, matching the server's --auth-mode.
+const (
+	authModeNone   = "none"
+	authModeAPIKey = "api_key"
+	authModeJWT    = "jwt"
+)
+
+// client calls the control plane API.
+type client struct {
+	baseURL  string
+	authMode string
+	apiKey   string
+	token    string
+	http     *http.Client
+}
+
+// apiError is an error response from the control plane.
+type apiError struct {
+	StatusCode   int                    `json:"-"`
+	ErrorType    string                 `json:"error_type"`
+	ErrorCode    string                 `json:"error_code"`
+	ErrorMessage string                 `json:"error_message"`
+	Details      map[string]interface{} `json:"details,omitempty"`
+}
+
+func (e *apiError) Error() string {
+	if e.ErrorMessage == "" {
+		return fmt.Sprintf("control plane returned %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("%s (%s)", e.ErrorMessage, e.ErrorCode)
+}
+
+// connError wraps failures to reach the control plane.
+type connError struct{ err error }
+
+func (e *connError) Error() string { return "cannot reach control plane: " + e.err.Error() }
+func (e *connError) Unwrap() error { return e.err }
+
+// authenticate sets the credentials for the configured auth mode: api_key
+// sends X-API-Key, jwt sends a bearer token.
+func (c *client) authenticate(req *http.Request) {
+	switch c.authMode {
+	case authModeAPIKey:
+		if c.apiKey != "" {
+			req.Header.Set("X-API-Key", c.apiKey)
+		}
+	case authModeJWT:
+		if c.token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+	}
+}
+
+// rawBody is a request body that is sent as is rather than encoded as JSON.
+type rawBody struct {
+	contentType string
+	data        []byte
+}
+
+func (c *client) newRequest(ctx context.Context, method, path string, body interface{}) (*http.Request, error) {
+	var reader io.Reader
+	contentType := "application/json"
+	switch b := body.(type) {
+	case nil:
+	case rawBody:
+		reader = bytes.NewReader(b.data)
+		contentType = b.contentType
+	default:
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(c.baseURL, "/")+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if reader != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	c.authenticate(req)
+	return req, nil
+}
+
+// do sends a request and decodes a successful JSON response into out, which
+// may be nil. The raw response body is returned for JSON output.
+func (c *client) do(ctx context.Context, method, path string, body, out interface{}) (json.RawMessage, error) {
+	req, err := c.newRequest(ctx, method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, &connError{err}
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &connError{err}
+	}
+	if resp.StatusCode >= 400 {
+		return nil, decodeAPIError(resp.StatusCode, data)
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return nil, fmt.Errorf("invalid response from %s: %w", path, err)
+		}
+	}
+	return data, nil
+}
+
+// download saves the response body of a GET to the file at dest, streaming
+// it rather than holding it in memory.
+func (c *client) download(ctx context.Context, path, dest string) error {
+	req, err := c.newRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return &connError{err}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		data, _ := io.ReadAll(resp.Body)
+		return decodeAPIError(resp.StatusCode, data)
+	}
+
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return &connError{err}
+	}
+	return f.Close()
+}
+
+func decodeAPIError(status int, data []byte) error {
+	apiErr := &apiError{StatusCode: status}
+	if err := json.Unmarshal(data, apiErr); err != nil || apiErr.ErrorMessage == "" {
+		apiErr.ErrorMessage = strings.TrimSpace(string(data))
+	}
+	return apiErr
+}
+
+// maxSSELineBytes bounds a single event line; events carry one run event.
+const maxSSELineBytes = 4 << 20
+
+// sseEvent is one server-sent event.
+type sseEvent struct {
+	Event string
+	ID    string
+	Data  string
+}
+
+// stream opens an SSE endpoint and calls fn for each event until fn returns
+// false, the stream ends or ctx is cancelled.
+func (c *client) stream(ctx context.Context, path string, fn func(sseEvent) bool) error {
+	req, err := c.newRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	// Streams stay open indefinitely; only the context bounds them.
+	streamClient := *c.http
+	streamClient.Timeout = 0
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		return &connError{err}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		data, _ := io.ReadAll(resp.Body)
+		return decodeAPIError(resp.StatusCode, data)
+	}
+
+	err = readSSE(resp.Body, fn)
+	if err != nil && ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// readSSE parses an event stream. Comment lines (keepalives) are skipped.
+func readSSE(r io.Reader, fn func(sseEvent) bool) error {
+	var (
+		event sseEvent
+		data  []string
+	)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxSSELineBytes)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if len(data) > 0 || event.Event != "" {
+				event.Data = strings.Join(data, "\n")
+				if !fn(event) {
+					return nil
+				}
+			}
+			event, data = sseEvent{}, nil
+		case strings.HasPrefix(line, ":"):
+		case strings.HasPrefix(line, "event:"):
+			event.Event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "id:"):
+			event.ID = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil && !errors.Is(err, io.EOF) {
+		return &connError{err}
+	}
+	return nil
+}
@@ -0,0 +1,727 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Wire types. Only the fields the CLI prints are declared; JSON output
+// prints the server's response unchanged.
+
+type runView struct {
+	RunID       string `json:"run_id"`
+	ExecutionID string `json:"execution_id"`
+	State       string `json:"state"`
+	ScenarioID  string `json:"scenario_id"`
+	CreatedAtMs int64  `json:"created_at_ms"`
+	UpdatedAtMs int64  `json:"updated_at_ms"`
+	ActiveStage *struct {
+		Stage   string `json:"stage"`
+		StageID string `json:"stage_id"`
+	} `json:"active_stage,omitempty"`
+	StopReason *struct {
+		Mode   string `json:"mode"`
+		Reason string `json:"reason"`
+		Actor  string `json:"actor"`
+	} `json:"stop_reason,omitempty"`
+	Priority       int               `json:"priority,omitempty"`
+	QueuePosition  int               `json:"queue_position,omitempty"`
+	Tags           map[string]string `json:"tags,omitempty"`
+	PreviousRunIDs []string          `json:"previous_run_ids,omitempty"`
+	SLOs           *struct {
+		Verdict string `json:"verdict"`
+		Results []struct {
+			Name    string `json:"name"`
+			Passed  bool   `json:"passed"`
+			Message string `json:"message"`
+		} `json:"results"`
+	} `json:"slos,omitempty"`
+}
+
+type runStateResponse struct {
+	RunID string `json:"run_id"`
+	State string `json:"state"`
+}
+
+type runEvent struct {
+	EventID     string          `json:"event_id"`
+	TimestampMs int64           `json:"ts_ms"`
+	Type        string          `json:"type"`
+	Actor       string          `json:"actor"`
+	Payload     json.RawMessage `json:"payload"`
+}
+
+type operationMetrics struct {
+	TotalOps   int     `json:"total_ops"`
+	FailureOps int     `json:"failure_ops"`
+	LatencyP50 int     `json:"latency_p50"`
+	LatencyP95 int     `json:"latency_p95"`
+	LatencyP99 int     `json:"latency_p99"`
+	ErrorRate  float64 `json:"error_rate"`
+}
+
+type runMetrics struct {
+	RunID               string                       `json:"run_id"`
+	Throughput          float64                      `json:"throughput"`
+	LatencyP50          float64                      `json:"latency_p50_ms"`
+	LatencyP95          float64                      `json:"latency_p95_ms"`
+	LatencyP99          float64                      `json:"latency_p99_ms"`
+	ErrorRate           float64                      `json:"error_rate"`
+	TotalOps            int64                        `json:"total_ops"`
+	FailedOps           int64                        `json:"failed_ops"`
+	DurationMs          int64                        `json:"duration_ms"`
+	ByTool              map[string]*operationMetrics `json:"by_tool,omitempty"`
+	OperationsTruncated bool                         `json:"operations_truncated,omitempty"`
+}
+
+type workerInfo struct {
+	WorkerID string `json:"worker_id"`
+	HostInfo struct {
+		Hostname string `json:"hostname"`
+		Platform string `json:"platform"`
+	} `json:"host_info"`
+	Capacity struct {
+		MaxVUs int `json:"max_vus"`
+	} `json:"capacity"`
+	Saturated     bool  `json:"saturated"`
+	LastHeartbeat int64 `json:"last_heartbeat"`
+	Health        *struct {
+		CPUPercent float64 `json:"cpu_percent"`
+		ActiveVUs  int     `json:"active_vus"`
+	} `json:"health,omitempty"`
+}
+
+type agentInfo struct {
+	AgentID  string    `json:"agent_id"`
+	PairKey  string    `json:"pair_key"`
+	Hostname string    `json:"hostname"`
+	OS       string    `json:"os"`
+	Version  string    `json:"version"`
+	LastSeen time.Time `json:"last_seen"`
+	Online   bool      `json:"online"`
+}
+
+// newFlagSet returns a flag set for a subcommand that reports errors as
+// usage errors instead of exiting.
+func (c *cli) newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	return fs
+}
+
+// parseArgs parses flags, which may follow the positional arguments, and
+// checks the number of positional arguments.
+func parseArgs(fs *flag.FlagSet, args []string, minArgs, maxArgs int, argsUsage string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, usagef("%s: %v", fs.Name(), err)
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+	if len(positional) < minArgs || len(positional) > maxArgs {
+		return nil, usagef("usage: mcpdrill %s %s", fs.Name(), argsUsage)
+	}
+	return positional, nil
+}
+
+func runPath(runID string, parts ...string) string {
+	return "/runs/" + url.PathEscape(runID) + strings.Join(append([]string{""}, parts...), "/")
+}
+
+// printJSON writes a response body indented.
+func (c *cli) printJSON(data json.RawMessage) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		_, err = c.stdout.Write(data)
+		return err
+	}
+	enc := json.NewEncoder(c.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func (c *cli) runCreate(ctx context.Context, args []string) error {
+	fs := c.newFlagSet("run create")
+	var tags tagFlags
+	fs.Var(&tags, "tag", "Tag the run as key=value (repeatable)")
+	positional, err := parseArgs(fs, args, 1, 1, "[--tag key=value] <config.json|config.yaml|->")
+	if err != nil {
+		return err
+	}
+	runID, data, err := c.createRun(ctx, positional[0], tags)
+	if err != nil {
+		return err
+	}
+	if c.output == "json" {
+		return c.printJSON(data)
+	}
+	fmt.Fprintf(c.stdout, "Created run %s\n", runID)
+	return nil
+}
+
+// createRun creates a run with tags from the config file at path, "-" for
+// stdin, and returns its ID and the raw response. Warnings of the server,
+// such as of runs of the same config in progress, go to stderr.
+func (c *cli) createRun(ctx context.Context, path string, tags tagFlags) (string, json.RawMessage, error) {
+	var config []byte
+	var err error
+	if path == "-" {
+		config, err = io.ReadAll(c.stdin)
+	} else {
+		config, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", nil, usagef("read config: %v", err)
+	}
+	baseDir := "."
+	if path != "-" {
+		baseDir = filepath.Dir(path)
+	}
+	if config, err = inlineDataFeeds(config, baseDir); err != nil {
+		return "", nil, usagef("read config: %v", err)
+	}
+
+	var resp struct {
+		RunID    string   `json:"run_id"`
+		Warnings []string `json:"warnings"`
+	}
+	var data json.RawMessage
+	if json.Valid(config) {
+		body := map[string]interface{}{"config": json.RawMessage(config), "actor": c.actor}
+		if len(tags) > 0 {
+			body["tags"] = map[string]string(tags)
+		}
+		data, err = c.client.do(ctx, http.MethodPost, "/runs", body, &resp)
+	} else {
+		// Anything else is sent as YAML so the server can report errors
+		// against YAML paths and line numbers.
+		query := url.Values{"actor": {c.actor}}
+		for key, value := range tags {
+			query.Add("tag", key+":"+value)
+		}
+		body := rawBody{contentType: "application/yaml", data: config}
+		data, err = c.client.do(ctx, http.MethodPost, "/runs?"+query.Encode(), body, &resp)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	for _, warning := range resp.Warnings {
+		fmt.Fprintf(c.stderr, "Warning: %s\n", warning)
+	}
+	return resp.RunID, data, nil
+}
+
+// startFlagsUsage documents the flags of startFlags.
+const startFlagsUsage = "[--priority N] [--target-url URL] [--vu-multiplier X] [--duration-multiplier X] [--header 'Name: value']"
+
+// startFlags are the flags of the commands that start a run.
+type startFlags struct {
+	priority           int
+	targetURL          string
+	vuMultiplier       float64
+	durationMultiplier float64
+	headers            headerFlags
+}
+
+func (f *startFlags) register(fs *flag.FlagSet) {
+	fs.IntVar(&f.priority, "priority", 0, "Priority in the server's run queue; higher starts first")
+	fs.StringVar(&f.targetURL, "target-url", "", "Replace the config's target URL for this run")
+	fs.Float64Var(&f.vuMultiplier, "vu-multiplier", 0, "Scale every stage's VU counts")
+	fs.Float64Var(&f.durationMultiplier, "duration-multiplier", 0, "Scale every stage's durations and hold times")
+	fs.Var(&f.headers, "header", "Add a target header as Name: value (repeatable)")
+}
+
+// body returns the start request for the flags.
+func (f *startFlags) body(actor string) map[string]interface{} {
+	body := map[string]interface{}{"actor": actor}
+	if f.priority != 0 {
+		body["priority"] = f.priority
+	}
+	params := map[string]interface{}{}
+	if f.targetURL != "" {
+		params["target_url"] = f.targetURL
+	}
+	if f.vuMultiplier != 0 {
+		params["vu_multiplier"] = f.vuMultiplier
+	}
+	if f.durationMultiplier != 0 {
+		params["duration_multiplier"] = f.durationMultiplier
+	}
+	if len(f.headers) > 0 {
+		params["headers"] = map[string]string(f.headers)
+	}
+	if len(params) > 0 {
+		body["parameters"] = params
+	}
+	return body
+}
+
+func (c *cli) runStart(ctx context.Context, args []string) error {
+	fs := c.newFlagSet("run start")
+	var start startFlags
+	start.register(fs)
+	positional, err := parseArgs(fs, args, 1, 1, startFlagsUsage+" <run_id>")
+	if err != nil {
+		return err
+	}
+	return c.changeState(ctx, runPath(positional[0], "start"), start.body(c.actor), "Started")
+}
+
+func (c *cli) runStop(ctx context.Context, args []string) error {
+	fs := c.newFlagSet("run stop")
+	mode := fs.String("mode", "drain", "Stop mode: drain waits for in-flight operations, immediate does not")
+	emergency := fs.Bool("emergency", false, "Emergency stop: terminate workers immediately")
+	positional, err := parseArgs(fs, args, 1, 1, "[--mode drain|immediate] [--emergency] <run_id>")
+	if err != nil {
+		return err
+	}
+	if *emergency {
+		return c.changeState(ctx, runPath(positional[0], "emergency-stop"), map[string]interface{}{"actor": c.actor}, "Emergency-stopped")
+	}
+	if *mode != "drain" && *mode != "immediate" {
+		return usagef("invalid --mode %q: expected drain or immediate", *mode)
+	}
+	return c.changeState(ctx, runPath(positional[0], "stop"), map[string]interface{}{"mode": *mode, "actor": c.actor}, "Stopping")
+}
+
+func (c *cli) runAbort(ctx context.Context, args []string) error {
+	fs := c.newFlagSet("run abort")
+	reason := fs.String("reason", "", "Why the run is aborted, recorded with it")
+	positional, err := parseArgs(fs, args, 1, 1, "[--reason TEXT] <run_id>")
+	if err != nil {
+		return err
+	}
+	body := map[string]interface{}{"actor": c.actor}
+	if *reason != "" {
+		body["reason"] = *reason
+	}
+	return c.changeState(ctx, "/runs/"+url.PathEscape(positional[0])+":abort", body, "Aborted")
+}
+
+func (c *cli) runPause(ctx context.Context, args []string) error {
+	fs := c.newFlagSet("run pause")
+	reason := fs.String("reason", "", "Why the run is paused, recorded with it")
+	positional, err := parseArgs(fs, args, 1, 1, "[--reason TEXT] <run_id>")
+	if err != nil {
+		return err
+	}
+	body := map[string]interface{}{"actor": c.actor}
+	if *reason != "" {
+		body["reason"] = *reason
+	}
+	return c.changeState(ctx, "/runs/"+url.PathEscape(positional[0])+":pause", body, "Paused")
+}
+
+func (c *cli) runResume(ctx context.Context, args []string) error {
+	fs := c.newFlagSet("run resume")
+	positional, err := parseArgs(fs, args, 1, 1, "<run_id>")
+	if err != nil {
+		return err
+	}
+	return c.changeState(ctx, "/runs/"+url.PathEscape(positional[0])+":resume", map[string]interface{}{"actor": c.actor}, "Resumed")
+}
+
+// headerFlags collects repeated --header "Name: value" flags.
+type headerFlags map[string]string
+
+func (h headerFlags) String() string {
+	return ""
+}
+
+func (h *headerFlags) Set(value string) error {
+	name, val, ok := strings.Cut(value, ":")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return fmt.Errorf("expected Name: value, got %q", value)
+	}
+	if *h == nil {
+		*h = make(headerFlags)
+	}
+	(*h)[name] = strings.TrimSpace(val)
+	return nil
+}
+
+// tagFlags collects repeated --tag key=value flags.
+type tagFlags map[string]string
+
+func (f tagFlags) String() string {
+	return ""
+}
+
+func (f *tagFlags) Set(value string) error {
+	key, val, _ := strings.Cut(value, "=")
+	if key == "" {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+	if *f == nil {
+		*f = make(tagFlags)
+	}
+	(*f)[key] = val
+	return nil
+}
+
+// changeState posts a run action and prints the resulting state.
+func (c *cli) changeState(ctx context.Context, path string, body interface{}, verb string) error {
+	var resp runStateResponse
+	data, err := c.client.do(ctx, http.MethodPost, path, body, &resp)
+	if err != nil {
+		return err
+	}
+	if c.output == "json" {
+		return c.printJSON(data)
+	}
+	fmt.Fprintf(c.stdout, "%s run %s (state: %s)\n", verb, resp.RunID, resp.State)
+	return nil
+}
+
+func (c *cli) runStatus(ctx context.Context, args []string) error {
+	fs := c.newFlagSet("run status")
+	var tags tagFlags
+	fs.Var(&tags, "tag", "Only list runs tagged key=value, or with key when given without a value (repeatable)")
+	state := fs.String("state", "", "Only list runs in these comma-separated states")
+	scenario := fs.String("scenario", "", "Only list runs of this scenario ID")
+	targetHost := fs.String("target-host", "", "Only list runs against this target host")
+	since := fs.Duration("since", 0, "Only list runs created within this duration, e.g. 168h")
+	limit := fs.Int("limit", 0, "List at most this many runs, newest first")
+	positional, err := parseArgs(fs, args, 0, 1, "[--tag key=value] [--state S1,S2] [--scenario ID] [--target-host HOST] [--since D] [--limit N] [run_id]")
+	if err != nil {
+		return err
+	}
+
+	if len(positional) == 0 {
+		query := url.Values{}
+		for key, value := range tags {
+			query.Add("tag", strings.TrimSuffix(key+":"+value, ":"))
+		}
+		for name, value := range map[string]string{"state": *state, "scenario_id": *scenario, "target_host": *targetHost} {
+			if value != "" {
+				query.Set(name, value)
+			}
+		}
+		if *since > 0 {
+			query.Set("since_ms", fmt.Sprint(time.Now().Add(-*since).UnixMilli()))
+		}
+		if *limit > 0 {
+			query.Set("limit", fmt.Sprint(*limit))
+		}
+		path := "/runs"
+		if len(query) > 0 {
+			path += "?" + query.Encode()
+		}
+
+		var resp struct {
+			Runs []*runView `json:"runs"`
+		}
+		data, err := c.client.do(ctx, http.MethodGet, path, nil, &resp)
+		if err != nil {
+			return err
+		}
+		if c.output == "json" {
+			return c.printJSON(data)
+		}
+		sort.SliceStable(resp.Runs, func(i, j int) bool { return resp.Runs[i].CreatedAtMs > resp.Runs[j].CreatedAtMs })
+		tw := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "RUN ID\tSTATE\tSTAGE\tSCENARIO\tCREATED\tTAGS")
+		for _, run := range resp.Runs {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", run.RunID, run.State, stageName(run), dash(run.ScenarioID), formatMs(run.CreatedAtMs), formatTags(run.Tags))
+		}
+		return tw.Flush()
+	}
+
+	var run runView
+	data, err := c.client.do(ctx, http.MethodGet, runPath(positional[0]), nil, &run)
+	if err != nil {
+		return err
+	}
+	if c.output == "json" {
+		return c.printJSON(data)
+	}
+	return c.printRun(&run)
+}
+
+// printRun prints one run's state and SLO verdict.
+func (c *cli) printRun(run *runView) error {
+	tw := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Run ID:\t%s\n", run.RunID)
+	fmt.Fprintf(tw, "Execution ID:\t%s\n", run.ExecutionID)
+	fmt.Fprintf(tw, "State:\t%s\n", run.State)
+	if run.QueuePosition > 0 {
+		fmt.Fprintf(tw, "Queue position:\t%d (priority %d)\n", run.QueuePosition, run.Priority)
+	}
+	fmt.Fprintf(tw, "Stage:\t%s\n", stageName(run))
+	fmt.Fprintf(tw, "Scenario:\t%s\n", dash(run.ScenarioID))
+	if len(run.Tags) > 0 {
+		fmt.Fprintf(tw, "Tags:\t%s\n", formatTags(run.Tags))
+	}
+	fmt.Fprintf(tw, "Created:\t%s\n", formatMs(run.CreatedAtMs))
+	fmt.Fprintf(tw, "Updated:\t%s\n", formatMs(run.UpdatedAtMs))
+	if len(run.PreviousRunIDs) > 0 {
+		fmt.Fprintf(tw, "Same config as:\t%s\n", strings.Join(run.PreviousRunIDs, ", "))
+	}
+	if run.StopReason != nil {
+		fmt.Fprintf(tw, "Stop reason:\t%s (%s, by %s)\n", run.StopReason.Reason, run.StopReason.Mode, run.StopReason.Actor)
+	}
+	if run.SLOs != nil {
+		fmt.Fprintf(tw, "SLO verdict:\t%s\n", run.SLOs.Verdict)
+		for _, r := range run.SLOs.Results {
+			switch {
+			case r.Passed:
+			case r.Message != "":
+				fmt.Fprintf(tw, "  failed:\t%s (%s)\n", r.Name, r.Message)
+			default:
+				fmt.Fprintf(tw, "  failed:\t%s\n", r.Name)
+			}
+		}
+	}
+	return tw.Flush()
+}
+
+func (c *cli) runEvents(ctx context.Context, args []string) error {
+	fs := c.newFlagSet("run events")
+	follow := fs.Bool("follow", false, "Keep streaming new events until interrupted")
+	types := fs.String("types", "", "Comma-separated event types to show, e.g. STATE_TRANSITION,DECISION")
+	positional, err := parseArgs(fs, args, 1, 1, "[--follow] [--types T1,T2] <run_id>")
+	if err != nil {
+		return err
+	}
+
+	// The replay endpoint sends the full history, marks its end, then
+	// continues live, so one stream serves both modes.
+	path := runPath(positional[0], "events", "replay")
+	if *types != "" {
+		path += "?types=" + url.QueryEscape(*types)
+	}
+
+	var printErr error
+	err = c.client.stream(ctx, path, func(ev sseEvent) bool {
+		switch ev.Event {
+		case "replay_complete":
+			return *follow
+		case "run_event":
+			if printErr = c.printEvent(ev.Data); printErr != nil {
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	return printErr
+}
+
+func (c *cli) printEvent(data string) error {
+	if c.output == "json" {
+		_, err := fmt.Fprintln(c.stdout, data)
+		return err
+	}
+	var ev runEvent
+	if err := json.Unmarshal([]byte(data), &ev); err != nil {
+		return fmt.Errorf("invalid event: %w", err)
+	}
+	_, err := fmt.Fprintf(c.stdout, "%s  %-26s %-10s %s\n", formatMs(ev.TimestampMs), ev.Type, ev.Actor, compactJSON(ev.Payload))
+	return err
+}
+
+func (c *cli) runReport(ctx context.Context, args []string) error {
+	positional, err := parseArgs(c.newFlagSet("run report"), args, 1, 1, "<run_id>")
+	if err != nil {
+		return err
+	}
+
+	var m runMetrics
+	data, err := c.client.do(ctx, http.MethodGet, runPath(positional[0], "metrics"), nil, &m)
+	if err != nil {
+		return err
+	}
+	if c.output == "json" {
+		return c.printJSON(data)
+	}
+	return c.printMetrics(&m)
+}
+
+// printMetrics prints a run's aggregated metrics and its per-tool
+// breakdown.
+func (c *cli) printMetrics(m *runMetrics) error {
+	tw := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Run ID:\t%s\n", m.RunID)
+	fmt.Fprintf(tw, "Duration:\t%s\n", (time.Duration(m.DurationMs) * time.Millisecond).String())
+	fmt.Fprintf(tw, "Operations:\t%d (%d failed)\n", m.TotalOps, m.FailedOps)
+	fmt.Fprintf(tw, "Error rate:\t%.2f%%\n", 100*m.ErrorRate)
+	fmt.Fprintf(tw, "Throughput:\t%.1f ops/s\n", m.Throughput)
+	fmt.Fprintf(tw, "Latency p50/p95/p99:\t%.0f / %.0f / %.0f ms\n", m.LatencyP50, m.LatencyP95, m.LatencyP99)
+	if m.OperationsTruncated {
+		fmt.Fprintln(tw, "Note:\toperation telemetry was truncated; metrics cover stored operations only")
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(m.ByTool) == 0 {
+		return nil
+	}
+	tools := make([]string, 0, len(m.ByTool))
+	for name := range m.ByTool {
+		tools = append(tools, name)
+	}
+	sort.Strings(tools)
+
+	fmt.Fprintln(c.stdout)
+	tw = tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TOOL\tOPS\tFAILED\tERROR RATE\tP50 MS\tP95 MS\tP99 MS")
+	for _, name := range tools {
+		t := m.ByTool[name]
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.2f%%\t%d\t%d\t%d\n", name, t.TotalOps, t.FailureOps, 100*t.ErrorRate, t.LatencyP50, t.LatencyP95, t.LatencyP99)
+	}
+	return tw.Flush()
+}
+
+func (c *cli) workersList(ctx context.Context, args []string) error {
+	if _, err := parseArgs(c.newFlagSet("workers list"), args, 0, 0, ""); err != nil {
+		return err
+	}
+
+	var resp struct {
+		Workers []*workerInfo `json:"workers"`
+	}
+	data, err := c.client.do(ctx, http.MethodGet, "/workers", nil, &resp)
+	if err != nil {
+		return err
+	}
+	if c.output == "json" {
+		return c.printJSON(data)
+	}
+
+	tw := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "WORKER ID\tHOST\tPLATFORM\tMAX VUS\tACTIVE VUS\tCPU\tSATURATED\tLAST HEARTBEAT")
+	for _, w := range resp.Workers {
+		activeVUs, cpu := "-", "-"
+		if w.Health != nil {
+			activeVUs = fmt.Sprint(w.Health.ActiveVUs)
+			cpu = fmt.Sprintf("%.0f%%", w.Health.CPUPercent)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\t%t\t%s\n", w.WorkerID, dash(w.HostInfo.Hostname), dash(w.HostInfo.Platform),
+			w.Capacity.MaxVUs, activeVUs, cpu, w.Saturated, formatMs(w.LastHeartbeat))
+	}
+	return tw.Flush()
+}
+
+func (c *cli) agentsList(ctx context.Context, args []string) error {
+	fs := c.newFlagSet("agents list")
+	pairKey := fs.String("pair-key", "", "Only list agents with this pair key")
+	if _, err := parseArgs(fs, args, 0, 0, "[--pair-key KEY]"); err != nil {
+		return err
+	}
+
+	path := "/agents"
+	if *pairKey != "" {
+		path += "?pair_key=" + url.QueryEscape(*pairKey)
+	}
+	var resp struct {
+		Agents []*agentInfo `json:"agents"`
+	}
+	data, err := c.client.do(ctx, http.MethodGet, path, nil, &resp)
+	if err != nil {
+		return err
+	}
+	if c.output == "json" {
+		return c.printJSON(data)
+	}
+
+	tw := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "AGENT ID\tPAIR KEY\tHOST\tOS\tVERSION\tONLINE\tLAST SEEN")
+	for _, a := range resp.Agents {
+		lastSeen := "-"
+		if !a.LastSeen.IsZero() {
+			lastSeen = a.LastSeen.Local().Format(time.DateTime)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%t\t%s\n", a.AgentID, dash(a.PairKey), dash(a.Hostname), dash(a.OS), dash(a.Version), a.Online, lastSeen)
+	}
+	return tw.Flush()
+}
+
+// printErrorDetails lists the individual validation errors of a rejected
+// config.
+func printErrorDetails(w io.Writer, err error) {
+	apiErr, ok := err.(*apiError)
+	if !ok {
+		return
+	}
+	issues, _ := apiErr.Details["errors"].([]interface{})
+	for _, issue := range issues {
+		m, _ := issue.(map[string]interface{})
+		if path, _ := m["yaml_path"].(string); path != "" {
+			fmt.Fprintf(w, "  %s (line %v): %v (%v)\n", path, m["line"], m["message"], m["code"])
+		} else if pointer, _ := m["json_pointer"].(string); pointer != "" {
+			fmt.Fprintf(w, "  %s: %v (%v)\n", pointer, m["message"], m["code"])
+		} else {
+			fmt.Fprintf(w, "  %v (%v)\n", m["message"], m["code"])
+		}
+	}
+}
+
+func stageName(run *runView) string {
+	if run.ActiveStage == nil || run.ActiveStage.Stage == "" {
+		return "-"
+	}
+	return run.ActiveStage.Stage
+}
+
+func formatMs(ms int64) string {
+	if ms <= 0 {
+		return "-"
+	}
+	return time.UnixMilli(ms).Local().Format("2006-01-02 15:04:05.000")
+}
+
+func compactJSON(data json.RawMessage) string {
+	if len(data) == 0 {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return string(data)
+	}
+	return buf.String()
+}
+
+// formatTags lists tags as key=value, sorted by key.
+func formatTags(tags map[string]string) string {
+	if len(tags) == 0 {
+		return "-"
+	}
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for i, key := range keys {
+		keys[i] = key + "=" + tags[key]
+	}
+	return strings.Join(keys, ",")
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+	"github.com/bc-dunia/mcpdrill/internal/conformance"
+)
+
+// privateNetworks are the networks a conformance run may target: servers
+// under test usually run on loopback or in a private network.
+var privateNetworks = []string{
+	"127.0.0.0/8", "::1/128", "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "fc00::/7", "fe80::/10", "169.254.0.0/16",
+}
+
+// conformanceFailedError is returned for a conformance run with failed
+// checks.
+type conformanceFailedError struct {
+	target string
+	failed int
+}
+
+func (e *conformanceFailedError) Error() string {
+	return fmt.Sprintf("%s failed %d conformance check(s)", e.target, e.failed)
+}
+
+// runConformance implements "mcpdrill run conformance": it checks the MCP
+// server at the target URL against the protocol directly, without the
+// control plane, and prints a pass or fail per check.
+func (c *cli) runConformance(ctx context.Context, args []string) error {
+	fs := c.newFlagSet("run conformance")
+	var headers headerFlags
+	fs.Var(&headers, "header", "Add a request header as Name: value (repeatable)")
+	version := fs.String("protocol-version", "", "Protocol version to request (default: the latest supported)")
+	tool := fs.String("tool", "", "Tool to call for the SSE and cancellation checks, which are skipped without one")
+	toolArgs := fs.String("tool-args", "", "Arguments of --tool as a JSON object")
+	cancelAfter := fs.Duration("cancel-after", conformance.DefaultCancelAfter, "How long the cancellation check waits before cancelling --tool")
+	timeout := fs.Duration("request-timeout", conformance.DefaultTimeout, "Timeout of each request")
+	jsonOut := fs.String("json", "", "Also write the report as JSON to FILE")
+	htmlOut := fs.String("html", "", "Also write the report as HTML to FILE")
+	positional, err := parseArgs(fs, args, 1, 1, "[--tool NAME [--tool-args JSON]] [--header 'Name: value'] [--json FILE] [--html FILE] <target-url>")
+	if err != nil {
+		return err
+	}
+
+	cfg := conformance.Config{
+		Endpoint:             positional[0],
+		Headers:              headers,
+		ProtocolVersion:      *version,
+		Tool:                 *tool,
+		CancelAfter:          *cancelAfter,
+		Timeout:              *timeout,
+		AllowPrivateNetworks: privateNetworks,
+	}
+	if *toolArgs != "" {
+		if err := json.Unmarshal([]byte(*toolArgs), &cfg.ToolArguments); err != nil {
+			return usagef("--tool-args must be a JSON object: %v", err)
+		}
+	}
+
+	start := time.Now()
+	result, err := conformance.Run(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	report := &analysis.Report{
+		StartTime:   start.UnixMilli(),
+		EndTime:     time.Now().UnixMilli(),
+		Duration:    time.Since(start).Milliseconds(),
+		Conformance: result,
+	}
+	reporter := analysis.NewReporter()
+	if *jsonOut != "" {
+		if err := writeReport(*jsonOut, report, reporter.GenerateJSON); err != nil {
+			return err
+		}
+	}
+	if *htmlOut != "" {
+		if err := writeReport(*htmlOut, report, reporter.GenerateHTML); err != nil {
+			return err
+		}
+	}
+
+	if c.output == "json" {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		if err := c.printJSON(data); err != nil {
+			return err
+		}
+	} else {
+		tw := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "CHECK\tSTATUS\tDETAIL")
+		for _, check := range result.Checks {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", check.ID, check.Status, dash(check.Detail))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		fmt.Fprintf(c.stdout, "\n%d passed, %d failed, %d skipped (protocol version %s)\n", result.Passed, result.Failed, result.Skipped, dash(result.ProtocolVersion))
+	}
+
+	if !result.OK() {
+		return &conformanceFailedError{target: result.Target, failed: result.Failed}
+	}
+	return nil
+}
+
+// writeReport writes report to path in the format of generate.
+func writeReport(path string, report *analysis.Report, generate func(*analysis.Report) ([]byte, error)) error {
+	data, err := generate(report)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("write report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/bc-dunia/mcpdrill/internal/validation"
+)
+
+// inlineDataFeeds replaces the path of each workload data feed with the
+// file's content as data, since the control plane never reads local files.
+// Relative paths are resolved against baseDir. A YAML config that uses
+// paths is returned as JSON; configs without paths are returned unchanged.
+func inlineDataFeeds(config []byte, baseDir string) ([]byte, error) {
+	doc := config
+	if !json.Valid(config) {
+		parsed, err := validation.ParseYAML(config)
+		if err != nil {
+			// Let the control plane report the syntax error with its location.
+			return config, nil
+		}
+		doc = parsed.JSON
+	}
+
+	var root map[string]interface{}
+	if err := json.Unmarshal(doc, &root); err != nil {
+		return config, nil
+	}
+	workload, _ := root["workload"].(map[string]interface{})
+	feeds, _ := workload["data_feeds"].([]interface{})
+
+	inlined := false
+	for i, f := range feeds {
+		feed, ok := f.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		path, ok := feed["path"].(string)
+		if !ok {
+			continue
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(baseDir, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("data feed %d: %w", i, err)
+		}
+		feed["data"] = string(data)
+		delete(feed, "path")
+		inlined = true
+	}
+	if !inlined {
+		return config, nil
+	}
+	return json.Marshal(root)
+}
@@ -0,0 +1,224 @@
+// Command mcpdrill is the command-line client of the MCP Drill control plane.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Exit codes.
+const (
+	exitOK                = 0
+	exitError             = 1
+	exitConfig            = 2 // usage, config file or validation errors
+	exitConnection        = 3
+	exitSLOFailed         = 4 // a waited-for run completed with failed SLOs
+	exitConformanceFailed = 5 // a conformance run had failed checks
+)
+
+const usage = `Usage: mcpdrill [global flags] <command> [flags] [args]
+
+Commands:
+  run [flags] <config>       Create and start a run; --wait follows it to the end,
+                             --ci also writes a step summary and annotations
+                             (--artifacts-dir to download its reports)
+  run create <config>        Create a run from a JSON or YAML file ("-" reads stdin;
+                             --tag key=value to tag it)
+  run start <run_id>         Start a created run (--priority to jump the run queue,
+                             --target-url, --header, --vu-multiplier and
+                             --duration-multiplier to parameterize it)
+  run stop <run_id>          Stop a run (--mode drain|immediate)
+  run abort <run_id>         End a run at once without analysis or a report
+  run pause <run_id>         Hold a running run's load, keeping its sessions open
+  run resume <run_id>        Resume a paused run, re-ramping to its previous load
+  run status [run_id]        Show a run, or list runs (--tag, --state, --scenario,
+                             --target-host, --since and --limit to filter)
+  run events <run_id>        Print the run's events (--follow to keep streaming)
+  run report <run_id>        Show the run's aggregated metrics
+  run conformance <url>      Check an MCP server against the protocol instead of
+                             loading it (--tool to cover SSE and cancellation,
+                             --json and --html to write the report)
+  workers list               List registered workers
+  agents list                List server telemetry agents (--pair-key to filter)
+  record proxy               Record MCP requests through a reverse proxy to --target
+                             (--listen, --out traffic.jsonl)
+  record convert <capture>   Write --base with the workload of a JSONL or HAR capture
+
+Global flags:
+`
+
+// cli holds the global options and output streams of one invocation.
+type cli struct {
+	client *client
+	actor  string
+	output string // table or json
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// run executes one invocation and returns its exit code.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	global := flag.NewFlagSet("mcpdrill", flag.ContinueOnError)
+	global.SetOutput(stderr)
+	global.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		global.PrintDefaults()
+	}
+	endpoint := global.String("endpoint", envOr("MCPDRILL_ENDPOINT", "http://localhost:8080"), "Control plane API URL (env MCPDRILL_ENDPOINT)")
+	authMode := global.String("auth-mode", envOr("MCPDRILL_AUTH_MODE", authModeAPIKey), "Authentication mode: none, api_key, jwt (env MCPDRILL_AUTH_MODE)")
+	apiKey := global.String("api-key", os.Getenv("MCPDRILL_API_KEY"), "API key for api_key mode (env MCPDRILL_API_KEY)")
+	token := global.String("token", os.Getenv("MCPDRILL_TOKEN"), "JWT for jwt mode (env MCPDRILL_TOKEN)")
+	actor := global.String("actor", "user", "Actor name recorded in run events")
+	output := global.String("output", "table", "Output format: table, json")
+	timeout := global.Duration("timeout", 30*time.Second, "Request timeout (event streams are not limited)")
+	if err := global.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitConfig
+	}
+
+	switch *authMode {
+	case authModeNone, authModeAPIKey, authModeJWT:
+	default:
+		fmt.Fprintf(stderr, "invalid --auth-mode %q: expected none, api_key or jwt\n", *authMode)
+		return exitConfig
+	}
+	if *output != "table" && *output != "json" {
+		fmt.Fprintf(stderr, "invalid --output %q: expected table or json\n", *output)
+		return exitConfig
+	}
+
+	c := &cli{
+		client: &client{
+			baseURL:  *endpoint,
+			authMode: *authMode,
+			apiKey:   *apiKey,
+			token:    *token,
+			http:     &http.Client{Timeout: *timeout},
+		},
+		actor:  *actor,
+		output: *output,
+		stdin:  stdin,
+		stdout: stdout,
+		stderr: stderr,
+	}
+
+	rest := global.Args()
+	if len(rest) < 2 {
+		global.Usage()
+		return exitConfig
+	}
+
+	var err error
+	if rest[0] == "run" && isRunConfigArg(rest[1]) {
+		return c.exitCode(c.runExec(ctx, rest[1:]))
+	}
+	switch rest[0] + " " + rest[1] {
+	case "run create":
+		err = c.runCreate(ctx, rest[2:])
+	case "run start":
+		err = c.runStart(ctx, rest[2:])
+	case "run stop":
+		err = c.runStop(ctx, rest[2:])
+	case "run abort":
+		err = c.runAbort(ctx, rest[2:])
+	case "run pause":
+		err = c.runPause(ctx, rest[2:])
+	case "run resume":
+		err = c.runResume(ctx, rest[2:])
+	case "run status":
+		err = c.runStatus(ctx, rest[2:])
+	case "run events":
+		err = c.runEvents(ctx, rest[2:])
+	case "run report":
+		err = c.runReport(ctx, rest[2:])
+	case "run conformance":
+		err = c.runConformance(ctx, rest[2:])
+	case "workers list":
+		err = c.workersList(ctx, rest[2:])
+	case "agents list":
+		err = c.agentsList(ctx, rest[2:])
+	case "record proxy":
+		err = c.recordProxy(ctx, rest[2:])
+	case "record convert":
+		err = c.recordConvert(ctx, rest[2:])
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\n\n", rest[0]+" "+rest[1])
+		global.Usage()
+		return exitConfig
+	}
+	return c.exitCode(err)
+}
+
+// exitCode reports err and maps it to an exit code.
+func (c *cli) exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+	fmt.Fprintf(c.stderr, "Error: %v\n", err)
+	printErrorDetails(c.stderr, err)
+
+	var (
+		usageErr *usageError
+		connErr  *connError
+		apiErr   *apiError
+		sloErr   *sloFailedError
+		confErr  *conformanceFailedError
+	)
+	switch {
+	case errors.As(err, &sloErr):
+		return exitSLOFailed
+	case errors.As(err, &confErr):
+		return exitConformanceFailed
+	case errors.As(err, &usageErr):
+		return exitConfig
+	case errors.As(err, &connErr):
+		return exitConnection
+	case errors.As(err, &apiErr) && apiErr.ErrorCode == "VALIDATION_FAILED":
+		return exitConfig
+	default:
+		return exitError
+	}
+}
+
+// isRunConfigArg reports whether the word after "run" starts the flags or
+// config of "mcpdrill run <config>" rather than naming a subcommand. Config
+// paths are told apart from mistyped subcommands by their extension or
+// directory.
+func isRunConfigArg(arg string) bool {
+	return strings.HasPrefix(arg, "-") || strings.ContainsAny(arg, "./"+string(os.PathSeparator))
+}
+
+// usageError is a problem with the command line or config file.
+type usageError struct{ msg string }
+
+func (e *usageError) Error() string { return e.msg }
+
+func usagef(format string, args ...interface{}) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/auth"
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/api"
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/runmanager"
+	"github.com/bc-dunia/mcpdrill/internal/mockserver"
+	"github.com/bc-dunia/mcpdrill/internal/validation"
+)
+
+const testAPIKey = "cli-test-key"
+
+// startControlPlane starts a control plane that requires testAPIKey.
+func startControlPlane(t *testing.T) string {
+	t.Helper()
+	validator, err := validation.NewUnifiedValidator(nil)
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+	rm := runmanager.NewRunManager(validator)
+	t.Cleanup(rm.Shutdown)
+
+	server := api.NewServer("127.0.0.1:0", rm)
+	server.SetAuthConfig(&auth.Config{Mode: auth.AuthModeAPIKey, APIKeys: []string{testAPIKey}})
+	if err := server.Start(); err != nil {
+		t.Fatalf("failed to start server: %v", err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+	})
+	return server.URL()
+}
+
+func runCLI(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	code := run(ctx, args, strings.NewReader(""), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func fixturePath(t *testing.T) string {
+	t.Helper()
+	path, err := filepath.Abs("../../testdata/fixtures/valid/minimal_preflight_baseline_ramp.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCLI_RunLifecycle(t *testing.T) {
+	endpoint := startControlPlane(t)
+	global := []string{"--endpoint", endpoint, "--api-key", testAPIKey}
+
+	code, out, errOut := runCLI(t, append(global, "run", "create", fixturePath(t))...)
+	if code != exitOK {
+		t.Fatalf("run create exited %d: %s", code, errOut)
+	}
+	runID := strings.TrimPrefix(strings.TrimSpace(out), "Created run ")
+	if !strings.HasPrefix(runID, "run_") {
+		t.Fatalf("unexpected create output: %q", out)
+	}
+
+	code, out, errOut = runCLI(t, append(global, "run", "status", runID)...)
+	if code != exitOK || !strings.Contains(out, "State:") || !strings.Contains(out, "created") {
+		t.Fatalf("run status exited %d: %s%s", code, out, errOut)
+	}
+
+	code, out, _ = runCLI(t, append(global, "--output", "json", "run", "status")...)
+	if code != exitOK || !strings.Contains(out, `"run_id": "`+runID+`"`) {
+		t.Errorf("expected run list JSON to contain %s, got %s", runID, out)
+	}
+
+	code, out, errOut = runCLI(t, append(global, "run", "create", "--tag", "schedule=nightly", fixturePath(t))...)
+	if code != exitOK {
+		t.Fatalf("run create --tag exited %d: %s", code, errOut)
+	}
+	taggedID := strings.TrimPrefix(strings.TrimSpace(out), "Created run ")
+	code, out, errOut = runCLI(t, append(global, "run", "status", "--tag", "schedule=nightly", "--state", "created", "--since", "1h")...)
+	if code != exitOK || !strings.Contains(out, taggedID) || !strings.Contains(out, "schedule=nightly") || strings.Contains(out, runID) {
+		t.Errorf("expected only the tagged run to be listed, got exit %d: %s%s", code, out, errOut)
+	}
+
+	code, out, errOut = runCLI(t, append(global, "run", "events", runID)...)
+	if code != exitOK || !strings.Contains(out, "RUN_CREATED") {
+		t.Errorf("run events exited %d: %s%s", code, out, errOut)
+	}
+
+	code, _, errOut = runCLI(t, append(global, "run", "stop", "--mode", "sideways", runID)...)
+	if code != exitConfig {
+		t.Errorf("expected exit %d for invalid stop mode, got %d: %s", exitConfig, code, errOut)
+	}
+
+	code, _, errOut = runCLI(t, append(global, "run", "pause", runID)...)
+	if code != exitError {
+		t.Errorf("expected exit %d for pausing a created run, got %d: %s", exitError, code, errOut)
+	}
+
+	code, out, errOut = runCLI(t, append(global, "run", "abort", "--reason", "wrong target", runID)...)
+	if code != exitOK || !strings.Contains(out, "Aborted run "+runID+" (state: aborted)") {
+		t.Errorf("run abort exited %d: %s%s", code, out, errOut)
+	}
+
+	code, out, _ = runCLI(t, append(global, "workers", "list")...)
+	if code != exitOK || !strings.HasPrefix(out, "WORKER ID") {
+		t.Errorf("workers list exited %d: %s", code, out)
+	}
+}
+
+func TestCLI_Errors(t *testing.T) {
+	endpoint := startControlPlane(t)
+
+	code, _, errOut := runCLI(t, "--endpoint", endpoint, "--api-key", "wrong", "run", "status")
+	if code != exitError || !strings.Contains(errOut, "Error:") {
+		t.Errorf("expected exit %d for rejected key, got %d: %s", exitError, code, errOut)
+	}
+
+	invalid := filepath.Join(t.TempDir(), "invalid.json")
+	if err := os.WriteFile(invalid, []byte(`{"scenario_id":"x"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	code, _, errOut = runCLI(t, "--endpoint", endpoint, "--api-key", testAPIKey, "run", "create", invalid)
+	if code != exitConfig {
+		t.Errorf("expected exit %d for invalid config, got %d: %s", exitConfig, code, errOut)
+	}
+
+	invalidYAML := filepath.Join(t.TempDir(), "invalid.yaml")
+	if err := os.WriteFile(invalidYAML, []byte("schema_version: run-config/v1\nstages: []\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	code, _, errOut = runCLI(t, "--endpoint", endpoint, "--api-key", testAPIKey, "run", "create", invalidYAML)
+	if code != exitConfig || !strings.Contains(errOut, "(line ") {
+		t.Errorf("expected exit %d with YAML locations for invalid YAML config, got %d: %s", exitConfig, code, errOut)
+	}
+
+	code, _, _ = runCLI(t, "--endpoint", "http://127.0.0.1:1", "run", "status")
+	if code != exitConnection {
+		t.Errorf("expected exit %d for unreachable endpoint, got %d", exitConnection, code)
+	}
+
+	code, _, _ = runCLI(t, "run", "explode")
+	if code != exitConfig {
+		t.Errorf("expected exit %d for unknown command, got %d", exitConfig, code)
+	}
+}
+
+func TestClient_AuthHeaders(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Write([]byte(`{"workers":[]}`))
+	}))
+	defer srv.Close()
+
+	code, _, errOut := runCLI(t, "--endpoint", srv.URL, "--auth-mode", "jwt", "--token", "tok", "workers", "list")
+	if code != exitOK {
+		t.Fatalf("workers list exited %d: %s", code, errOut)
+	}
+	if got.Get("Authorization") != "Bearer tok" || got.Get("X-API-Key") != "" {
+		t.Errorf("unexpected jwt headers: %v", got)
+	}
+
+	runCLI(t, "--endpoint", srv.URL, "--api-key", "key", "workers", "list")
+	if got.Get("X-API-Key") != "key" || got.Get("Authorization") != "" {
+		t.Errorf("unexpected api_key headers: %v", got)
+	}
+}
+
+func TestReadSSE(t *testing.T) {
+	stream := ":keepalive\n\nevent: run_event\nid: evt_1\ndata: {\"a\":1}\n\nevent: replay_complete\ndata: {}\n\nevent: run_event\ndata: {}\n\n"
+	var events []sseEvent
+	err := readSSE(strings.NewReader(stream), func(ev sseEvent) bool {
+		events = append(events, ev)
+		return ev.Event != "replay_complete"
+	})
+	if err != nil {
+		t.Fatalf("readSSE failed: %v", err)
+	}
+	if len(events) != 2 || events[0].ID != "evt_1" || events[0].Data != `{"a":1}` {
+		t.Errorf("unexpected events: %+v", events)
+	}
+}
+
+func TestInlineDataFeeds(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "users.csv"), []byte("email\na@example.com\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	static := []byte(`{"workload": {"data_feeds": [{"name": "users", "format": "csv", "data": "email\na@example.com\n"}]}}`)
+	if out, err := inlineDataFeeds(static, dir); err != nil || !bytes.Equal(out, static) {
+		t.Errorf("expected config without paths to be unchanged, got %s, %v", out, err)
+	}
+
+	yamlConfig := []byte("workload:\n  data_feeds:\n    - name: users\n      format: csv\n      path: users.csv\n")
+	out, err := inlineDataFeeds(yamlConfig, dir)
+	if err != nil {
+		t.Fatalf("inlineDataFeeds: %v", err)
+	}
+	if !strings.Contains(string(out), `"data":"email\na@example.com\n"`) || strings.Contains(string(out), "path") {
+		t.Errorf("expected path to be inlined as JSON data, got %s", out)
+	}
+
+	missing := []byte(`{"workload": {"data_feeds": [{"name": "users", "format": "csv", "path": "nope.csv"}]}}`)
+	if _, err := inlineDataFeeds(missing, dir); err == nil {
+		t.Error("expected an error for a missing feed file")
+	}
+}
+
+func TestCLI_RecordConvert(t *testing.T) {
+	capture := filepath.Join(t.TempDir(), "traffic.jsonl")
+	traffic := `{"method":"initialize"}
+{"method":"tools/call","tool":"search","arguments":{"query":"mcp"}}
+{"method":"tools/call","tool":"search","arguments":{"query":"go"}}
+{"method":"tools/list"}
+`
+	if err := os.WriteFile(capture, []byte(traffic), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	code, out, errOut := runCLI(t, "record", "convert", "--base", fixturePath(t), capture)
+	if code != exitOK {
+		t.Fatalf("expected exit %d, got %d: %s", exitOK, code, errOut)
+	}
+	if !strings.Contains(out, `"tool_name": "search"`) || !strings.Contains(out, `"operation": "tools_list"`) {
+		t.Errorf("expected the recorded workload in the config, got %s", out)
+	}
+	if !strings.Contains(errOut, "Replaying 3 requests") || !strings.Contains(errOut, "Ignored 1 initialize requests") {
+		t.Errorf("expected a summary of the conversion, got %s", errOut)
+	}
+
+	code, _, _ = runCLI(t, "record", "convert", capture)
+	if code != exitConfig {
+		t.Errorf("expected exit %d without --base, got %d", exitConfig, code)
+	}
+}
+
+func TestCLI_RunConformance(t *testing.T) {
+	srv := mockserver.New(mockserver.DefaultConfig())
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop(context.Background())
+
+	htmlPath := filepath.Join(t.TempDir(), "conformance.html")
+	code, out, errOut := runCLI(t, "run", "conformance", "--html", htmlPath, srv.MCPURL())
+	if code != exitOK {
+		t.Fatalf("expected exit %d, got %d: %s%s", exitOK, code, out, errOut)
+	}
+	if !strings.Contains(out, "errors.unknown_method") || !strings.Contains(out, "0 failed") {
+		t.Errorf("expected a pass per check, got %s", out)
+	}
+	if html, err := os.ReadFile(htmlPath); err != nil || !strings.Contains(string(html), "Protocol Conformance") {
+		t.Errorf("expected the HTML report written, got %v", err)
+	}
+
+	// A server that answers everything with an empty object fails most checks.
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer bad.Close()
+	code, _, errOut = runCLI(t, "run", "conformance", bad.URL)
+	if code != exitConformanceFailed || !strings.Contains(errOut, "conformance check(s)") {
+		t.Errorf("expected exit %d for failed checks, got %d: %s", exitConformanceFailed, code, errOut)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/recorder"
+)
+
+// recordProxy implements "mcpdrill record proxy": it serves a reverse proxy
+// to an MCP server that records the requests of clients pointed at it, until
+// interrupted.
+func (c *cli) recordProxy(ctx context.Context, args []string) error {
+	fs := c.newFlagSet("record proxy")
+	target := fs.String("target", "", "URL of the MCP server to forward to, without the MCP path, e.g. http://localhost:3000")
+	listen := fs.String("listen", "127.0.0.1:9000", "Address to serve the recording proxy on")
+	out := fs.String("out", "traffic.jsonl", "File to append recorded requests to, as JSONL")
+	if _, err := parseArgs(fs, args, 0, 0, "--target URL [--listen ADDR] [--out FILE]"); err != nil {
+		return err
+	}
+	targetURL, err := url.Parse(*target)
+	if err != nil || targetURL.Host == "" || (targetURL.Scheme != "http" && targetURL.Scheme != "https") {
+		return usagef("--target must be an http(s) URL, got %q", *target)
+	}
+
+	file, err := os.OpenFile(*out, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return usagef("open --out: %v", err)
+	}
+	defer file.Close()
+
+	listener, err := net.Listen("tcp", *listen)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", *listen, err)
+	}
+	proxy := recorder.NewProxy(targetURL, file)
+	server := &http.Server{Handler: proxy, ReadHeaderTimeout: 10 * time.Second}
+	fmt.Fprintf(c.stderr, "Recording requests to %s through http://%s into %s; press Ctrl-C to stop\n", targetURL, listener.Addr(), *out)
+
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(listener) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	fmt.Fprintf(c.stderr, "Recorded %d requests\n", proxy.Count())
+	return nil
+}
+
+// recordConvert implements "mcpdrill record convert": it derives a workload
+// from a capture and writes the base config with that workload to stdout.
+func (c *cli) recordConvert(ctx context.Context, args []string) error {
+	fs := c.newFlagSet("record convert")
+	base := fs.String("base", "", "Run config (JSON or YAML) whose workload the recorded one replaces")
+	positional, err := parseArgs(fs, args, 1, 1, "--base CONFIG <traffic.jsonl|capture.har|->")
+	if err != nil {
+		return err
+	}
+	if *base == "" {
+		return usagef("record convert: --base is required")
+	}
+	config, err := os.ReadFile(*base)
+	if err != nil {
+		return usagef("read --base: %v", err)
+	}
+
+	var capture io.Reader = c.stdin
+	if positional[0] != "-" {
+		file, err := os.Open(positional[0])
+		if err != nil {
+			return usagef("read capture: %v", err)
+		}
+		defer file.Close()
+		capture = file
+	}
+	entries, err := recorder.Read(capture)
+	if err != nil {
+		return usagef("read capture: %v", err)
+	}
+	workload, err := recorder.BuildWorkload(entries)
+	if err != nil {
+		return usagef("%v", err)
+	}
+	converted, err := recorder.Apply(config, workload)
+	if err != nil {
+		return usagef("read --base: %v", err)
+	}
+	if _, err := c.stdout.Write(append(converted, '\n')); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stderr, "Replaying %d requests as %d operations and %d tool templates\n",
+		workload.Replayed, len(workload.OperationMix), len(workload.Templates))
+	methods := make([]string, 0, len(workload.Ignored))
+	for method := range workload.Ignored {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	for _, method := range methods {
+		fmt.Fprintf(c.stderr, "Ignored %d %s requests\n", workload.Ignored[method], method)
+	}
+	if workload.Dropped > 0 {
+		fmt.Fprintf(c.stderr, "Dropped %d requests of the least frequent kinds past the run config limits\n", workload.Dropped)
+	}
+	return nil
+}
@@ -16,13 +16,44 @@ import (
 
 func main() {
 	addr := flag.String("addr", ":3000", "HTTP server address")
+	stdio := flag.Bool("stdio", false, "Serve MCP over stdin and stdout instead of HTTP")
+	strict := flag.Bool("strict", false, "Require sessions, the initialized notification and supported protocol versions, as the MCP spec does")
+	stateFrom := flag.String("state-from", "", "URL of a mock server whose counters, rate limit and circuit breaker to share, e.g. http://10.0.0.5:3000")
+	timelineFile := flag.String("timeline", "", "JSON or YAML file of behavior phases to go through from startup")
 	flag.Parse()
 
 	config := mockserver.DefaultConfig()
 	config.Addr = *addr
+	config.Strict = *strict
+	config.StateURL = *stateFrom
+	if *timelineFile != "" {
+		timeline, err := mockserver.LoadTimeline(*timelineFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading timeline: %v\n", err)
+			os.Exit(1)
+		}
+		config.SetTimeline(timeline)
+	}
 
 	server := mockserver.New(config)
 
+	if *stdio {
+		if *strict {
+			fmt.Fprintln(os.Stderr, "Error: --strict applies to HTTP and cannot be used with --stdio")
+			os.Exit(2)
+		}
+		// stdout carries the protocol, so status goes to stderr.
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+		fmt.Fprintln(os.Stderr, "Mock MCP server serving on stdio")
+		if err := server.ServeStdio(ctx, os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error serving on stdio: %v\n", err)
+			stop()
+			os.Exit(1)
+		}
+		return
+	}
+
 	if err := server.Start(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error starting mock server: %v\n", err)
 		os.Exit(1)
@@ -30,6 +61,13 @@ func main() {
 
 	fmt.Printf("Mock MCP server listening on %s\n", server.Addr())
 	fmt.Printf("MCP endpoint: %s\n", server.MCPURL())
+	fmt.Printf("Behavior API: http://%s%s\n", server.Addr(), mockserver.BehaviorPath)
+	if *stateFrom != "" {
+		fmt.Printf("Sharing state with %s\n", *stateFrom)
+	}
+	if *timelineFile != "" {
+		fmt.Printf("Timeline status: http://%s%s\n", server.Addr(), mockserver.TimelinePath)
+	}
 	fmt.Println("Press Ctrl+C to stop")
 
 	sigChan := make(chan os.Signal, 1)
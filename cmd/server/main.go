@@ -2,51 +2,150 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"flag"
 	"fmt"
 	"log/slog"
+	"net"
 	"os"
 	"os/signal"
+	"runtime"
 	"strings"
 	"syscall"
 	"time"
 
+	"github.com/bc-dunia/mcpdrill/internal/artifacts"
 	"github.com/bc-dunia/mcpdrill/internal/auth"
 	"github.com/bc-dunia/mcpdrill/internal/controlplane/api"
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/audit"
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/export"
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/launcher"
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/notify"
 	"github.com/bc-dunia/mcpdrill/internal/controlplane/runmanager"
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/runstore"
 	"github.com/bc-dunia/mcpdrill/internal/controlplane/scheduler"
 	"github.com/bc-dunia/mcpdrill/internal/metrics"
+	"github.com/bc-dunia/mcpdrill/internal/mockserver"
+	"github.com/bc-dunia/mcpdrill/internal/otel"
+	"github.com/bc-dunia/mcpdrill/internal/retention"
+	"github.com/bc-dunia/mcpdrill/internal/secrets"
+	"github.com/bc-dunia/mcpdrill/internal/tlsconfig"
+	"github.com/bc-dunia/mcpdrill/internal/types"
 	"github.com/bc-dunia/mcpdrill/internal/validation"
+	"github.com/bc-dunia/mcpdrill/internal/worker"
 )
 
 func main() {
 	addr := flag.String("addr", ":8080", "HTTP server address")
+	tlsCert := flag.String("tls-cert", "", "Serve HTTPS with this PEM certificate (chain); requires --tls-key")
+	tlsKey := flag.String("tls-key", "", "PEM private key of --tls-cert")
 	authMode := flag.String("auth-mode", "api_key", "Authentication mode: none, api_key, jwt")
-	apiKeys := flag.String("api-keys", "", "Comma-separated API keys (for api_key mode)")
-	jwtSecret := flag.String("jwt-secret", "", "JWT secret (for jwt mode)")
+	apiKeys := flag.String("api-keys", "", "Comma-separated bootstrap API keys with the admin role (for api_key mode); further keys are managed via /api-keys")
+	jwtSecret := flag.String("jwt-secret", "", "JWT secret for HS256 tokens (for jwt mode)")
+	jwksURL := flag.String("jwks-url", "", "JWKS URL with the keys of RS256/ES256 tokens, e.g. https://idp.example.com/.well-known/jwks.json (for jwt mode)")
+	jwtIssuer := flag.String("jwt-issuer", "", "Required iss claim of JWT tokens")
+	jwtAudience := flag.String("jwt-audience", "", "Required aud claim of JWT tokens")
+	jwtRolesClaim := flag.String("jwt-roles-claim", "roles", "JWT claim holding roles; dotted paths reach nested claims, e.g. realm_access.roles")
+	jwtProjectsClaim := flag.String("jwt-projects-claim", "projects", "JWT claim listing the projects a token may act in; tokens without it may act in every project")
+	jwtRoleMap := flag.String("jwt-role-map", "", "Comma-separated claim=role mappings, e.g. 'drill-admins=admin,sre=operator'")
 	insecure := flag.Bool("insecure", false, "Allow unauthenticated mode (only safe on loopback)")
 	enableAgentIngest := flag.Bool("enable-agent-ingest", false, "Enable agent telemetry ingestion endpoints")
 	agentTokens := flag.String("agent-tokens", "", "Comma-separated tokens for agent authentication")
 	allowPrivateNetworks := flag.String("allow-private-networks", "", "Comma-separated CIDR ranges to allow (e.g., '127.0.0.0/8,10.0.0.0/8' for local testing)")
 	allowPrivateDiscovery := flag.Bool("allow-private-discovery", false, "Allow discovery endpoints to access private networks")
 	insecureWorkerAuth := flag.Bool("insecure-worker-auth", false, "Disable worker token authentication (not recommended)")
+	workerTokenTTL := flag.Duration("worker-token-ttl", 24*time.Hour, "Worker tokens expire after this long; heartbeats hand workers a new one halfway through (0=never expire)")
 	redactAssignmentSecrets := flag.Bool("redact-assignment-secrets", false, "Redact sensitive headers and tokens in worker assignments")
+	disableWorkerChannel := flag.Bool("disable-worker-channel", false, "Make workers poll for assignments instead of offering them a WebSocket control channel")
+	scaleHookSpec := flag.String("scale-hook", "", "Webhook URL or shell command to call when a run cannot be allocated for lack of worker capacity")
+	k8sPodTemplate := flag.String("k8s-pod-template", "", "Launch worker pods for each run from this pod template (YAML or JSON) and delete them when the run ends")
+	k8sKubeconfig := flag.String("k8s-kubeconfig", "", "Kubeconfig for --k8s-pod-template (default: in-cluster service account)")
+	k8sNamespace := flag.String("k8s-namespace", "", "Namespace to launch worker pods in (default: the kubeconfig context's namespace)")
+	k8sVUsPerWorker := flag.Int("k8s-vus-per-worker", launcher.DefaultVUsPerWorker, "VUs each launched worker pod runs")
+	k8sMaxWorkers := flag.Int("k8s-max-workers", launcher.DefaultMaxWorkers, "Max worker pods launched for a single run")
+	k8sReadyTimeout := flag.Duration("k8s-worker-ready-timeout", 45*time.Second, "How long starting a run waits for its launched workers to register")
+	maxConcurrentRuns := flag.Int("max-concurrent-runs", 0, "Queue started runs so at most this many execute at once (0=unlimited)")
+	exclusiveTargets := flag.Bool("exclusive-targets", false, "Queue started runs so no two execute against the same target host at once")
+	warnDuplicateRuns := flag.Bool("warn-duplicate-runs", false, "Warn in the create response when a run of the identical config against the same target is in progress")
 	rateLimit := flag.Float64("rate-limit", 100, "API rate limit in requests/second (0 to disable)")
 	rateBurst := flag.Int("rate-burst", 200, "API rate limit burst size")
+	projectRateLimit := flag.Float64("project-rate-limit", 0, "API rate limit per project in requests/second, shared by all of a project's callers (0 to disable)")
+	projectRateBurst := flag.Int("project-rate-burst", 400, "API rate limit burst size per project")
 	maxOpsPerRun := flag.Int("max-ops-per-run", 20000000, "Max operations stored per run (0=unlimited)")
 	maxLogsPerRun := flag.Int("max-logs-per-run", 20000000, "Max logs stored per run (0=unlimited)")
 	maxTotalRuns := flag.Int("max-total-runs", 100, "Max runs in memory before eviction (0=unlimited)")
+	maxRunsPerProject := flag.Int("max-runs-per-project", 0, "Max runs of one project in memory before its oldest is evicted (0=unlimited)")
+	retainRawOps := flag.Bool("retain-raw-operations", false, "Keep every operation for analysis instead of aggregating them into histograms as they arrive")
+	telemetrySpillDir := flag.String("telemetry-spill-dir", "", "Spill operation logs, and with --retain-raw-operations operations, beyond --max-memory-ops-per-run to files in this directory")
+	maxMemoryOpsPerRun := flag.Int("max-memory-ops-per-run", 2000000, "Max recent operations and operation logs kept in memory per run; older operations are aggregated, or spilled with --telemetry-spill-dir, and older logs are spilled with it")
+	retainRunsDays := flag.Int("retain-runs-days", 0, "Delete ended runs created more than this many days ago, with their events, telemetry and artifacts (0=keep)")
+	retainRunsPerScenario := flag.Int("retain-runs-per-scenario", 0, "Keep the newest N ended runs of each scenario and delete older ones likewise (0=no limit)")
+	retainTag := flag.String("retain-tag", "baseline", "Tag key of runs that run retention never deletes, such as baselines")
+	retentionIntervalHours := flag.Int("retention-interval-hours", 24, "Hours between run retention sweeps; the first runs at startup")
+	storeSpec := flag.String("store", "", "Persist runs to sqlite:<path> or postgres:<dsn> (default: in memory only)")
+	artifactStoreSpec := flag.String("artifact-store", "", "Store reports in <dir>, s3://bucket/prefix or gs://bucket/prefix (default: runs are not analyzed)")
+	otlpEndpoint := flag.String("otlp-endpoint", "", "Export traces to this OTLP collector, e.g. localhost:4317 or https://collector:4318 (\"stdout\" prints spans)")
+	otlpProtocol := flag.String("otlp-protocol", "grpc", "OTLP protocol: grpc or http")
+	exportSpec := flag.String("export", "", "Comma-separated sinks to stream windowed run metrics to: influx:<url>?org=&bucket= (or ?db=), timescale:<postgres dsn>, datadog[:<site>], statsd:<host:port>")
+	influxToken := flag.String("influx-token", "", "InfluxDB API token for --export influx (a value or secret reference)")
+	datadogAPIKey := flag.String("datadog-api-key", "", "Datadog API key for --export datadog (a value or secret reference)")
+	notifySlackWebhook := flag.String("notify-slack-webhook", "", "Slack incoming webhook to post finished runs to (a value or secret reference)")
+	notifySMTPAddr := flag.String("notify-smtp-addr", "", "SMTP server (host:port) to email finished runs through")
+	notifySMTPUsername := flag.String("notify-smtp-username", "", "SMTP username for --notify-smtp-addr")
+	notifySMTPPassword := flag.String("notify-smtp-password", "", "SMTP password for --notify-smtp-addr (a value or secret reference)")
+	notifyEmailFrom := flag.String("notify-email-from", "", "Sender address of run notification emails")
+	notifyEmailTo := flag.String("notify-email-to", "", "Comma-separated recipients of run notification emails")
+	notifyOn := flag.String("notify-on", "completed,failed,aborted", "Comma-separated final run states to notify of")
+	publicURL := flag.String("public-url", "", "Control plane URL as people reach it, for links in run notifications, e.g. https://drill.example.com")
+	allowedSecretRefs := flag.String("allowed-secret-refs", "", "Comma-separated secret reference patterns run configs may use besides env://MCPDRILL_* and file:///run/secrets/mcpdrill/* (e.g., 'vault://secret/mcpdrill/*')")
 	devMode := flag.Bool("dev", false, "Development mode: binds to loopback, disables auth, allows private networks")
+	embeddedWorker := flag.Bool("embedded-worker", false, "Run a worker in this process that registers with this control plane (requires auth disabled, as with --dev)")
+	embeddedWorkerVUs := flag.Int("embedded-worker-vus", 100, "Maximum VUs of the --embedded-worker")
+	mockServerAddr := flag.String("mockserver-addr", "", "Also serve the mock MCP server on this address, e.g. 127.0.0.1:3000")
+	localMode := flag.Bool("local", false, "Single-binary local mode: --dev with an --embedded-worker and a mock MCP server on 127.0.0.1:3000 unless --mockserver-addr is set")
+	var secretsConfig secrets.Config
+	secretsConfig.RegisterFlags(flag.CommandLine)
 	flag.Parse()
 
-	if *maxOpsPerRun < 0 || *maxLogsPerRun < 0 || *maxTotalRuns < 0 {
+	if *maxOpsPerRun < 0 || *maxLogsPerRun < 0 || *maxTotalRuns < 0 || *maxMemoryOpsPerRun < 0 {
 		slog.Error("telemetry limits cannot be negative")
 		os.Exit(1)
 	}
-	if *maxOpsPerRun == 0 || *maxLogsPerRun == 0 {
+	if *retainRunsDays < 0 || *retainRunsPerScenario < 0 {
+		slog.Error("run retention limits cannot be negative")
+		os.Exit(1)
+	}
+	if *workerTokenTTL < 0 {
+		slog.Error("--worker-token-ttl cannot be negative")
+		os.Exit(1)
+	}
+	if *embeddedWorkerVUs <= 0 {
+		slog.Error("--embedded-worker-vus must be positive")
+		os.Exit(1)
+	}
+	if *localMode {
+		*devMode = true
+		*embeddedWorker = true
+		if *mockServerAddr == "" {
+			*mockServerAddr = "127.0.0.1:3000"
+		}
+	}
+	var tlsConfig *tls.Config
+	if *tlsCert != "" || *tlsKey != "" {
+		var err error
+		if tlsConfig, err = tlsconfig.Server(*tlsCert, *tlsKey); err != nil {
+			slog.Error("invalid --tls-cert/--tls-key", "error", err)
+			os.Exit(1)
+		}
+	}
+	// Aggregated and spilled operations, and spilled logs, do not stay in
+	// memory.
+	boundedOps := *maxMemoryOpsPerRun > 0 && (!*retainRawOps || *telemetrySpillDir != "")
+	boundedLogs := *maxMemoryOpsPerRun > 0 && *telemetrySpillDir != ""
+	if (*maxOpsPerRun == 0 && !boundedOps) || (*maxLogsPerRun == 0 && !boundedLogs) {
 		slog.Warn("unlimited telemetry storage enabled, monitor memory usage to avoid OOM")
 	}
-	if *maxOpsPerRun > 50000000 || *maxLogsPerRun > 50000000 {
+	if (*maxOpsPerRun > 50000000 && !boundedOps) || (*maxLogsPerRun > 50000000 && !boundedLogs) {
 		slog.Warn("telemetry limits exceed recommended maximum (50M), very high memory usage expected (25GB+)")
 	}
 
@@ -67,6 +166,18 @@ func main() {
 		fmt.Println("╚════════════════════════════════════════════════════════════╝")
 		fmt.Println("")
 	}
+	if *embeddedWorker {
+		// The embedded worker registers over loopback like any other worker,
+		// without an API key.
+		if tlsConfig != nil {
+			slog.Error("--embedded-worker cannot be combined with --tls-cert")
+			os.Exit(1)
+		}
+		if !*insecure && !strings.EqualFold(*authMode, string(auth.AuthModeNone)) {
+			slog.Error("--embedded-worker requires auth to be disabled; use --dev, --local or --insecure")
+			os.Exit(1)
+		}
+	}
 
 	// Build system policy with optional private network allowlist
 	systemPolicy := validation.DefaultSystemPolicy()
@@ -77,6 +188,11 @@ func main() {
 		}
 		systemPolicy.AllowPrivateNetworks = cidrs
 	}
+	if *allowedSecretRefs != "" {
+		for _, pattern := range strings.Split(*allowedSecretRefs, ",") {
+			systemPolicy.AllowedSecretRefs = append(systemPolicy.AllowedSecretRefs, strings.TrimSpace(pattern))
+		}
+	}
 
 	validator, err := validation.NewUnifiedValidator(systemPolicy)
 	if err != nil {
@@ -84,12 +200,57 @@ func main() {
 		os.Exit(1)
 	}
 
+	tracer, err := otel.SetupTracing(context.Background(), "mcpdrill-server", *otlpEndpoint, *otlpProtocol)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error configuring tracing: %v\n", err)
+		os.Exit(1)
+	}
+	if tracer.Enabled() {
+		slog.Info("tracing enabled", "otlp_endpoint", *otlpEndpoint, "otlp_protocol", *otlpProtocol)
+	}
+
 	rm := runmanager.NewRunManager(validator)
+	rm.SetTracer(tracer)
 
 	registry := scheduler.NewRegistry()
 	leaseManager := scheduler.NewLeaseManager(60000)
 	allocator := scheduler.NewAllocator(registry, leaseManager)
 	rm.SetScheduler(registry, allocator, leaseManager)
+	if *scaleHookSpec != "" {
+		scaleHook, err := runmanager.OpenScaleHook(*scaleHookSpec)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error configuring scale hook: %v\n", err)
+			os.Exit(1)
+		}
+		rm.SetScaleHook(scaleHook)
+	}
+	if *k8sPodTemplate != "" {
+		k8sLauncher, err := launcher.NewKubernetes(launcher.KubernetesConfig{
+			Kubeconfig:   *k8sKubeconfig,
+			Namespace:    *k8sNamespace,
+			PodTemplate:  *k8sPodTemplate,
+			VUsPerWorker: *k8sVUsPerWorker,
+			MaxWorkers:   *k8sMaxWorkers,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error configuring Kubernetes worker launcher: %v\n", err)
+			os.Exit(1)
+		}
+		rm.SetWorkerLauncher(k8sLauncher, *k8sReadyTimeout)
+		slog.Info("kubernetes worker launcher enabled", "pod_template", *k8sPodTemplate)
+	}
+
+	if *maxConcurrentRuns < 0 {
+		fmt.Fprintf(os.Stderr, "Error: --max-concurrent-runs cannot be negative\n")
+		os.Exit(1)
+	}
+	if *maxConcurrentRuns > 0 || *exclusiveTargets {
+		rm.SetRunQueue(runmanager.RunQueueConfig{
+			MaxConcurrentRuns: *maxConcurrentRuns,
+			ExclusiveTargets:  *exclusiveTargets,
+		})
+		slog.Info("run queue enabled", "max_concurrent_runs", *maxConcurrentRuns, "exclusive_targets", *exclusiveTargets)
+	}
 
 	heartbeatMonitor := scheduler.NewHeartbeatMonitor(registry, leaseManager, 0, 0)
 	heartbeatMonitor.SetOnWorkerLost(func(workerID scheduler.WorkerID, affectedRunIDs []string) {
@@ -105,23 +266,53 @@ func main() {
 	server.SetRegistry(registry)
 	server.SetLeaseManager(leaseManager)
 	telemetryStore := api.NewTelemetryStoreWithConfig(&api.TelemetryStoreConfig{
-		MaxOperationsPerRun: *maxOpsPerRun,
-		MaxLogsPerRun:       *maxLogsPerRun,
-		MaxTotalRuns:        *maxTotalRuns,
+		MaxOperationsPerRun:  *maxOpsPerRun,
+		MaxLogsPerRun:        *maxLogsPerRun,
+		MaxTotalRuns:         *maxTotalRuns,
+		MaxRunsPerProject:    *maxRunsPerProject,
+		MaxInMemoryOpsPerRun: *maxMemoryOpsPerRun,
+		SpillDir:             *telemetrySpillDir,
+		AggregateOperations:  !*retainRawOps,
 	})
+	telemetryStore.SetProjectResolver(rm.GetRunProject)
 	server.SetTelemetryStore(telemetryStore)
 	rm.SetTelemetryStore(telemetryStore)
+	var artifactStore artifacts.Store
+	if *artifactStoreSpec != "" {
+		var err error
+		artifactStore, err = artifacts.Open(*artifactStoreSpec)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening artifact store: %v\n", err)
+			os.Exit(1)
+		}
+		rm.SetArtifactStore(artifactStore)
+		server.SetArtifactStore(artifactStore)
+		slog.Info("artifact store opened", "store", *artifactStoreSpec)
+	}
 	server.SetMetricsCollector(metrics.NewCollector())
 	rm.SetAssignmentSender(api.NewServerAssignmentAdapter(server))
 	server.SetAllowPrivateNetworks(*allowPrivateDiscovery)
 	server.SetWorkerAuthEnabled(!*insecureWorkerAuth)
+	server.SetWorkerTokenTTL(*workerTokenTTL)
+	if tlsConfig != nil {
+		server.SetTLSConfig(tlsConfig)
+	} else if !*insecureWorkerAuth && !isLoopbackAddr(*addr) {
+		slog.Warn("serving plain HTTP on a non-loopback address: worker tokens and assignments cross the network unencrypted unless a TLS proxy fronts the control plane (see --tls-cert)")
+	}
 	server.SetRedactAssignmentSecrets(*redactAssignmentSecrets)
+	server.SetWarnDuplicateRuns(*warnDuplicateRuns)
+	server.SetWorkerChannelEnabled(!*disableWorkerChannel)
 
 	server.SetRateLimiterConfig(&api.RateLimiterConfig{
 		RequestsPerSecond: *rateLimit,
 		BurstSize:         *rateBurst,
 		Enabled:           *rateLimit > 0,
 	})
+	server.SetProjectRateLimiterConfig(&api.RateLimiterConfig{
+		RequestsPerSecond: *projectRateLimit,
+		BurstSize:         *projectRateBurst,
+		Enabled:           *projectRateLimit > 0,
+	})
 
 	if strings.EqualFold(*authMode, string(auth.AuthModeNone)) && !*insecure {
 		fmt.Fprintln(os.Stderr, "Refusing to start with auth disabled without --insecure")
@@ -136,18 +327,41 @@ func main() {
 	if *insecure {
 		authConfig.Mode = auth.AuthModeNone
 	}
+	secretResolver := secrets.NewResolver(secretsConfig)
 	if *apiKeys != "" {
-		authConfig.APIKeys = strings.Split(*apiKeys, ",")
+		authConfig.APIKeys = mustResolveSecretList(secretResolver, "--api-keys", *apiKeys)
+		authConfig.APIKeyRoles = make(map[string][]auth.Role, len(authConfig.APIKeys))
+		for _, key := range authConfig.APIKeys {
+			authConfig.APIKeyRoles[key] = []auth.Role{auth.RoleAdmin}
+		}
+	}
+	if authConfig.Mode == auth.AuthModeAPIKey {
+		authConfig.KeyStore = auth.NewKeyStore()
 	}
 	if *jwtSecret != "" {
-		authConfig.JWTSecret = []byte(*jwtSecret)
+		authConfig.JWTSecret = []byte(mustResolveSecretList(secretResolver, "--jwt-secret", *jwtSecret)[0])
+	}
+	authConfig.JWKSURL = *jwksURL
+	authConfig.JWTIssuer = *jwtIssuer
+	authConfig.JWTAudience = *jwtAudience
+	authConfig.JWTRolesClaim = *jwtRolesClaim
+	authConfig.JWTProjectsClaim = *jwtProjectsClaim
+	if *jwtRoleMap != "" {
+		roleMapping, err := parseRoleMap(*jwtRoleMap)
+		if err != nil {
+			slog.Error("invalid --jwt-role-map", "error", err)
+			os.Exit(1)
+		}
+		authConfig.JWTRoleMapping = roleMapping
 	}
 	server.SetAuthConfig(authConfig)
 
 	if *enableAgentIngest {
-		server.SetAgentStore(api.NewAgentStore())
+		agentStore := api.NewAgentStore()
+		server.SetAgentStore(agentStore)
+		rm.SetServerMetricsSource(api.NewAgentMetricsSource(agentStore))
 		if *agentTokens != "" {
-			tokens := strings.Split(*agentTokens, ",")
+			tokens := mustResolveSecretList(secretResolver, "--agent-tokens", *agentTokens)
 			server.SetAgentAuthConfig(&api.AgentAuthConfig{
 				Enabled: true,
 				Tokens:  tokens,
@@ -155,12 +369,186 @@ func main() {
 		}
 	}
 
+	auditLog := audit.NewLog(audit.DefaultCapacity)
+	server.SetAuditLog(auditLog)
+
+	var runStore runstore.Store
+	if *storeSpec != "" {
+		storeKind, _, _ := strings.Cut(*storeSpec, ":")
+		openCtx, cancelOpen := context.WithTimeout(context.Background(), 30*time.Second)
+		runStore, err = runstore.Open(openCtx, *storeSpec)
+		if err != nil {
+			cancelOpen()
+			fmt.Fprintf(os.Stderr, "Error opening %s run store: %v\n", storeKind, err)
+			os.Exit(1)
+		}
+		rm.SetRunStore(runStore)
+		auditLog.SetStore(runStore)
+		recovered, err := rm.RecoverRuns(openCtx)
+		if err != nil {
+			cancelOpen()
+			fmt.Fprintf(os.Stderr, "Error recovering runs: %v\n", err)
+			os.Exit(1)
+		}
+		scenarioVersions, err := rm.LoadScenarios(openCtx)
+		cancelOpen()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading scenarios: %v\n", err)
+			os.Exit(1)
+		}
+		apiKeyCount := 0
+		if authConfig.KeyStore != nil {
+			apiKeyCount, err = loadAPIKeys(runStore, authConfig.KeyStore)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error loading API keys: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		slog.Info("run store opened", "store", storeKind, "recovered_runs", recovered, "scenario_versions", scenarioVersions, "api_keys", apiKeyCount)
+	} else {
+		if authConfig.KeyStore != nil {
+			slog.Warn("API keys created via /api-keys are kept in memory only; use --store to persist them")
+		}
+		slog.Info("audit log kept in memory", "max_entries", audit.DefaultCapacity)
+	}
+
+	if *notifySlackWebhook != "" || *notifySMTPAddr != "" {
+		var senders []notify.Sender
+		if *notifySlackWebhook != "" {
+			slack, err := notify.NewSlackSender(mustResolveSecret(secretResolver, "--notify-slack-webhook", *notifySlackWebhook))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error configuring Slack notifications: %v\n", err)
+				os.Exit(1)
+			}
+			senders = append(senders, slack)
+		}
+		if *notifySMTPAddr != "" {
+			smtpConfig := notify.SMTPConfig{
+				Addr:     *notifySMTPAddr,
+				Username: *notifySMTPUsername,
+				From:     *notifyEmailFrom,
+			}
+			if *notifySMTPPassword != "" {
+				smtpConfig.Password = mustResolveSecret(secretResolver, "--notify-smtp-password", *notifySMTPPassword)
+			}
+			for _, to := range strings.Split(*notifyEmailTo, ",") {
+				if to = strings.TrimSpace(to); to != "" {
+					smtpConfig.To = append(smtpConfig.To, to)
+				}
+			}
+			email, err := notify.NewEmailSender(smtpConfig)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error configuring email notifications: %v\n", err)
+				os.Exit(1)
+			}
+			senders = append(senders, email)
+		}
+		states, err := notify.ParseStates(*notifyOn)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing --notify-on: %v\n", err)
+			os.Exit(1)
+		}
+		rm.SetRunNotifier(notify.New(senders, states, *publicURL))
+		slog.Info("run notifications enabled", "senders", len(senders), "states", *notifyOn)
+	}
+
+	// Retention deletes runs through the run manager, which deletes them
+	// from every store; the artifact and telemetry TTLs then only reach
+	// what the run manager no longer knows of.
+	var retentionManager *retention.Manager
+	if *retainRunsDays > 0 || *retainRunsPerScenario > 0 {
+		var artifactSweep retention.ArtifactStore
+		if fs, ok := artifactStore.(retention.ArtifactStore); ok {
+			artifactSweep = fs
+		}
+		retentionManager = retention.NewManager(retention.Config{
+			CleanupIntervalHours: *retentionIntervalHours,
+			RunsTTLHours:         *retainRunsDays * 24,
+			KeepRunsPerScenario:  *retainRunsPerScenario,
+			KeepTag:              *retainTag,
+		}, artifactSweep, retention.NewTelemetryStoreAdapter(telemetryStore))
+		retentionManager.SetRunStore(retention.NewRunManagerAdapter(rm))
+		retentionManager.Start()
+		slog.Info("run retention enabled", "days", *retainRunsDays, "per_scenario", *retainRunsPerScenario, "keep_tag", *retainTag)
+	}
+
+	var stopExport func()
+	if *exportSpec != "" {
+		var creds export.Credentials
+		if *influxToken != "" {
+			creds.InfluxToken = mustResolveSecretList(secretResolver, "--influx-token", *influxToken)[0]
+		}
+		if *datadogAPIKey != "" {
+			creds.DatadogAPIKey = mustResolveSecretList(secretResolver, "--datadog-api-key", *datadogAPIKey)[0]
+		}
+		openCtx, cancelOpen := context.WithTimeout(context.Background(), 30*time.Second)
+		sinks, err := export.OpenAll(openCtx, *exportSpec, creds)
+		cancelOpen()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening metrics export: %v\n", err)
+			os.Exit(1)
+		}
+		exportCtx, cancelExport := context.WithCancel(context.Background())
+		exportDone := make(chan struct{})
+		go func() {
+			defer close(exportDone)
+			export.New(telemetryStore, sinks).Run(exportCtx)
+		}()
+		stopExport = func() {
+			cancelExport()
+			<-exportDone
+		}
+		slog.Info("metrics export started", "sinks", len(sinks))
+	}
+
 	if err := server.Start(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error starting server: %v\n", err)
 		os.Exit(1)
 	}
 
 	fmt.Printf("MCP Drill control plane listening on %s\n", server.URL())
+	fmt.Printf("Web console at %s/console/\n", server.URL())
+
+	var mockServer mockserver.Server
+	if *mockServerAddr != "" {
+		mockConfig := mockserver.DefaultConfig()
+		mockConfig.Addr = *mockServerAddr
+		mockServer = mockserver.New(mockConfig)
+		if err := mockServer.Start(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error starting mock server: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Mock MCP server at %s\n", mockServer.MCPURL())
+	}
+
+	var embedded *worker.Runner
+	if *embeddedWorker {
+		hostname, _ := os.Hostname()
+		embedded, err = worker.StartRunner(context.Background(), worker.RunnerConfig{
+			ControlPlaneURL: "http://" + loopbackAddr(server.Addr()),
+			HostInfo: types.HostInfo{
+				Hostname: hostname,
+				Platform: runtime.GOOS,
+				Labels:   map[string]string{"embedded": "true"},
+			},
+			Capacity: types.WorkerCapacity{
+				MaxVUs:           *embeddedWorkerVUs,
+				MaxConcurrentOps: *embeddedWorkerVUs * 10,
+				MaxRPS:           float64(*embeddedWorkerVUs) * 10,
+			},
+			ControlChannel:   true,
+			PrivateNetworks:  systemPolicy.AllowPrivateNetworks,
+			Secrets:          secretResolver,
+			Limits:           worker.DefaultResourceLimits(),
+			ThrottleInterval: 2 * time.Second,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error starting embedded worker: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Embedded worker registered: %s (max %d VUs)\n", embedded.Registration().WorkerID, *embeddedWorkerVUs)
+		fmt.Printf("Start a run with: mcpdrill --endpoint %s --auth-mode none run --wait <config>\n", server.URL())
+	}
 
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -170,11 +558,180 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	// The embedded worker flushes its telemetry to the server, so it stops
+	// first.
+	if embedded != nil {
+		shipped, dropped := embedded.Close(ctx)
+		fmt.Printf("Embedded worker stopped: %d operations shipped, %d dropped\n", shipped, dropped)
+	}
+	if mockServer != nil {
+		mockServer.Stop(ctx)
+	}
 	if err := server.Shutdown(ctx); err != nil {
 		fmt.Fprintf(os.Stderr, "Error during shutdown: %v\n", err)
 	}
 
+	if retentionManager != nil {
+		retentionManager.Stop()
+	}
+	rm.Shutdown()
+	if stopExport != nil {
+		stopExport()
+	}
+	if runStore != nil {
+		if err := runStore.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing run store: %v\n", err)
+		}
+	}
+
 	heartbeatMonitor.Stop()
 	registry.Close()
+	if err := tracer.Shutdown(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error flushing traces: %v\n", err)
+	}
 	fmt.Println("Server stopped")
 }
+
+// parseRoleMap parses claim=role pairs such as "drill-admins=admin".
+// isLoopbackAddr reports whether the listen address addr only accepts
+// connections from this host.
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// loopbackAddr returns addr with an unspecified host, such as that of ":8080",
+// replaced by 127.0.0.1 so this process can dial itself.
+func loopbackAddr(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "127.0.0.1"
+	}
+	return net.JoinHostPort(host, port)
+}
+
+func parseRoleMap(value string) (map[string]auth.Role, error) {
+	mapping := make(map[string]auth.Role)
+	for _, pair := range strings.Split(value, ",") {
+		claim, role, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		switch auth.Role(role) {
+		case auth.RoleAdmin, auth.RoleOperator, auth.RoleViewer:
+		default:
+			ok = false
+		}
+		if !ok || claim == "" {
+			return nil, fmt.Errorf("%q is not claim=admin|operator|viewer", pair)
+		}
+		mapping[claim] = auth.Role(role)
+	}
+	return mapping, nil
+}
+
+// loadAPIKeys loads the managed API keys from store into keys and makes
+// keys save every change back to it.
+func loadAPIKeys(store runstore.Store, keys *auth.KeyStore) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	stored, err := store.ListAPIKeys(ctx)
+	if err != nil {
+		return 0, err
+	}
+	managed := make([]auth.ManagedKey, 0, len(stored))
+	for _, key := range stored {
+		scopes := make([]auth.Scope, 0, len(key.Scopes))
+		for _, scope := range key.Scopes {
+			scopes = append(scopes, auth.Scope(scope))
+		}
+		managed = append(managed, auth.ManagedKey{
+			ID:           key.ID,
+			Name:         key.Name,
+			Prefix:       key.Prefix,
+			Hash:         key.KeyHash,
+			Scopes:       scopes,
+			CreatedBy:    key.CreatedBy,
+			CreatedAtMs:  key.CreatedAtMs,
+			RotatedAtMs:  key.RotatedAtMs,
+			RevokedAtMs:  key.RevokedAtMs,
+			LastUsedAtMs: key.LastUsedAtMs,
+		})
+	}
+	keys.Load(managed)
+
+	keys.SetPersistFunc(func(key auth.ManagedKey) error {
+		scopes := make([]string, 0, len(key.Scopes))
+		for _, scope := range key.Scopes {
+			scopes = append(scopes, string(scope))
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		err := store.SaveAPIKey(ctx, &runstore.APIKey{
+			ID:           key.ID,
+			Name:         key.Name,
+			Prefix:       key.Prefix,
+			KeyHash:      key.Hash,
+			Scopes:       scopes,
+			CreatedBy:    key.CreatedBy,
+			CreatedAtMs:  key.CreatedAtMs,
+			RotatedAtMs:  key.RotatedAtMs,
+			RevokedAtMs:  key.RevokedAtMs,
+			LastUsedAtMs: key.LastUsedAtMs,
+		})
+		if err != nil {
+			slog.Error("failed to persist API key", "id", key.ID, "error", err)
+		}
+		return err
+	})
+	return len(managed), nil
+}
+
+// mustResolveSecret resolves a flag value that is a single secret or secret
+// reference. http(s) URLs, such as webhooks, are taken as they are.
+func mustResolveSecret(resolver *secrets.Resolver, name, value string) string {
+	if !strings.Contains(value, "://") || strings.HasPrefix(value, "https://") || strings.HasPrefix(value, "http://") {
+		return value
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	resolved, err := resolver.Resolve(ctx, strings.TrimSpace(value))
+	if err != nil {
+		slog.Error("failed to resolve secret", "flag", name, "error", err)
+		os.Exit(1)
+	}
+	slog.Info("resolved secret reference", "flag", name, "ref", strings.TrimSpace(value))
+	return resolved
+}
+
+// mustResolveSecretList resolves a comma-separated flag value whose items
+// may be secret references such as vault://secret/mcpdrill#api_keys. A
+// referenced secret may itself hold a comma-separated list.
+func mustResolveSecretList(resolver *secrets.Resolver, name, value string) []string {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var values []string
+	for _, item := range strings.Split(value, ",") {
+		if !strings.Contains(item, "://") {
+			values = append(values, item)
+			continue
+		}
+		resolved, err := resolver.Resolve(ctx, strings.TrimSpace(item))
+		if err != nil {
+			slog.Error("failed to resolve secret", "flag", name, "error", err)
+			os.Exit(1)
+		}
+		slog.Info("resolved secret reference", "flag", name, "ref", strings.TrimSpace(item))
+		values = append(values, strings.Split(resolved, ",")...)
+	}
+	return values
+}
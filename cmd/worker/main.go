@@ -1,13 +1,11 @@
 package main
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"flag"
 	"fmt"
-	"io"
-	"net/http"
+	"net"
+	"net/url"
 	"os"
 	"os/signal"
 	"runtime"
@@ -15,50 +13,63 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/bc-dunia/mcpdrill/internal/otel"
+	"github.com/bc-dunia/mcpdrill/internal/secrets"
+	"github.com/bc-dunia/mcpdrill/internal/tlsconfig"
 	"github.com/bc-dunia/mcpdrill/internal/types"
 	"github.com/bc-dunia/mcpdrill/internal/worker"
 )
 
-type registerRequest struct {
-	HostInfo types.HostInfo       `json:"host_info"`
-	Capacity types.WorkerCapacity `json:"capacity"`
-}
-
-type registerResponse struct {
-	WorkerID    string `json:"worker_id"`
-	WorkerToken string `json:"worker_token,omitempty"`
-}
-
-type heartbeatRequest struct {
-	Health *types.WorkerHealth `json:"health,omitempty"`
-}
-
-type heartbeatResponse struct {
-	OK                  bool     `json:"ok"`
-	StopRunIDs          []string `json:"stop_run_ids,omitempty"`
-	ImmediateStopRunIDs []string `json:"immediate_stop_run_ids,omitempty"`
-}
-
-type assignmentsResponse struct {
-	Assignments []types.WorkerAssignment `json:"assignments"`
-}
-
-type ackAssignmentsRequest struct {
-	LeaseIDs []string `json:"lease_ids"`
-}
-
 func main() {
-	controlPlane := flag.String("control-plane", "http://localhost:8080", "Control plane URL")
+	if len(os.Args) > 1 && os.Args[1] == "selftest" {
+		os.Exit(runSelfTest(os.Args[2:]))
+	}
+
+	controlPlaneURL := flag.String("control-plane", "http://localhost:8080", "Control plane URL")
+	caCert := flag.String("ca-cert", "", "Trust only the CA certificates in this PEM file for an https control plane, instead of the system roots")
+	tlsServerName := flag.String("tls-server-name", "", "Check the control plane's certificate against this name instead of the URL's host")
 	maxVUs := flag.Int("max-vus", 100, "Maximum virtual users this worker can handle")
+	project := flag.String("project", "", "Only run runs of this project (default: shared by every project)")
+	labelsFlag := flag.String("labels", "", "Comma-separated key=value labels placing this worker, e.g. 'region=eu-west-1,zone=eu-west-1a'")
 	heartbeatInterval := flag.Duration("heartbeat-interval", 10*time.Second, "Heartbeat interval")
 	pollInterval := flag.Duration("poll-interval", 1*time.Second, "Assignment poll interval")
 	allowPrivateNetworks := flag.String("allow-private-networks", "", "Comma-separated CIDR ranges to allow (e.g., '127.0.0.0/8,10.0.0.0/8')")
+	otlpEndpoint := flag.String("otlp-endpoint", "", "Export traces to this OTLP collector, e.g. localhost:4317 or https://collector:4318 (\"stdout\" prints spans)")
+	otlpProtocol := flag.String("otlp-protocol", "grpc", "OTLP protocol: grpc or http")
+	telemetrySummaries := flag.Bool("telemetry-summaries", false, "Ship per-second summaries of operations instead of every operation")
+	useControlChannel := flag.Bool("control-channel", true, "Receive assignments and stop commands over a WebSocket control channel when the control plane offers one, polling otherwise")
+	defaultLimits := worker.DefaultResourceLimits()
+	throttleCPU := flag.Float64("throttle-cpu-percent", defaultLimits.CPUPercent, "Shed load while host CPU use is at or above this percent (0 disables)")
+	throttleMem := flag.Float64("throttle-mem-percent", defaultLimits.MemPercent, "Shed load while host memory use is at or above this percent (0 disables)")
+	throttleFDs := flag.Float64("throttle-fd-percent", defaultLimits.FDPercent, "Shed load while open file descriptors are at or above this percent of their limit (0 disables)")
+	throttleInterval := flag.Duration("throttle-interval", 2*time.Second, "How often to sample resource use for self-throttling")
+	telemetrySampleRate := flag.Float64("telemetry-sample-rate", 0.01, "With --telemetry-summaries, fraction of successful operations also shipped in full (failures always are)")
+	var secretsConfig secrets.Config
+	secretsConfig.RegisterFlags(flag.CommandLine)
 	flag.Parse()
 
+	if *telemetrySampleRate < 0 || *telemetrySampleRate > 1 {
+		fmt.Fprintf(os.Stderr, "--telemetry-sample-rate must be between 0 and 1\n")
+		os.Exit(1)
+	}
+
+	tlsConfig, err := tlsconfig.Client(*caCert, *tlsServerName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid TLS settings: %v\n", err)
+		os.Exit(1)
+	}
+
+	labels, err := parseLabels(*labelsFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid --labels: %v\n", err)
+		os.Exit(1)
+	}
+
 	hostname, _ := os.Hostname()
 	hostInfo := types.HostInfo{
 		Hostname: hostname,
 		Platform: runtime.GOOS,
+		Labels:   labels,
 	}
 	capacity := types.WorkerCapacity{
 		MaxVUs:           *maxVUs,
@@ -69,59 +80,78 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	workerID, workerToken, err := register(ctx, *controlPlane, hostInfo, capacity)
+	tracer, err := otel.SetupTracing(ctx, "mcpdrill-worker", *otlpEndpoint, *otlpProtocol)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to configure tracing: %v\n", err)
+		os.Exit(1)
+	}
+
+	privateNets := parsePrivateNetworks(*allowPrivateNetworks)
+	runner, err := worker.StartRunner(ctx, worker.RunnerConfig{
+		ControlPlaneURL:   *controlPlaneURL,
+		TLSConfig:         tlsConfig,
+		Project:           *project,
+		HostInfo:          hostInfo,
+		Capacity:          capacity,
+		ControlChannel:    *useControlChannel,
+		HeartbeatInterval: *heartbeatInterval,
+		PollInterval:      *pollInterval,
+		PrivateNetworks:   privateNets,
+		Telemetry: worker.TelemetryShipperConfig{
+			Summarize:  *telemetrySummaries,
+			SampleRate: *telemetrySampleRate,
+		},
+		Secrets:          secrets.NewResolver(secretsConfig),
+		Limits:           worker.ResourceLimits{CPUPercent: *throttleCPU, MemPercent: *throttleMem, FDPercent: *throttleFDs},
+		ThrottleInterval: *throttleInterval,
+	})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to register with control plane: %v\n", err)
 		os.Exit(1)
 	}
+	registration := runner.Registration()
 
-	fmt.Printf("Worker registered: %s\n", workerID)
-	fmt.Printf("Control plane: %s\n", *controlPlane)
+	fmt.Printf("Worker registered: %s\n", registration.WorkerID)
+	fmt.Printf("Control plane: %s\n", *controlPlaneURL)
+	if strings.HasPrefix(*controlPlaneURL, "http://") && registration.WorkerToken != "" && !isLoopbackURL(*controlPlaneURL) {
+		fmt.Fprintf(os.Stderr, "Warning: the worker token is sent in plaintext; serve the control plane over https\n")
+	}
+	if registration.WorkerTokenExpiresAtMs > 0 {
+		fmt.Printf("Worker token: rotated by heartbeats, expires %s unless renewed\n", time.UnixMilli(registration.WorkerTokenExpiresAtMs).Format(time.RFC3339))
+	}
 	fmt.Printf("Max VUs: %d\n", *maxVUs)
-
-	privateNets := parsePrivateNetworks(*allowPrivateNetworks)
+	if *project != "" {
+		fmt.Printf("Project: %s\n", *project)
+	}
+	if *labelsFlag != "" {
+		fmt.Printf("Labels: %s\n", *labelsFlag)
+	}
+	if tracer.Enabled() {
+		fmt.Printf("Tracing to: %s (%s)\n", *otlpEndpoint, *otlpProtocol)
+	}
+	if registration.ControlChannel != "" {
+		fmt.Printf("Control channel: %s\n", registration.ControlChannel)
+	}
+	if *telemetrySummaries {
+		fmt.Printf("Telemetry: per-second summaries, sampling %g%% of successful operations\n", *telemetrySampleRate*100)
+	}
 	if len(privateNets) > 0 {
 		fmt.Printf("Allowed private networks: %v\n", privateNets)
 	}
 
-	retryClient := worker.NewRetryHTTPClient(ctx, *controlPlane, http.DefaultClient, worker.RetryConfig{
-		MaxRetries: 3,
-		Backoff:    100 * time.Millisecond,
-		MaxBackoff: 5 * time.Second,
-	})
-	retryClient.SetWorkerToken(workerToken)
-
-	telemetryShipper := worker.NewTelemetryShipper(ctx, workerID, retryClient)
-	defer telemetryShipper.Close()
-
-	executor := worker.NewAssignmentExecutor(workerID, privateNets, telemetryShipper)
-
-	go heartbeatLoop(ctx, *controlPlane, workerID, workerToken, *heartbeatInterval, executor)
-	go pollAssignments(ctx, *controlPlane, workerID, workerToken, *pollInterval, executor)
-
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 	<-sigChan
 
 	fmt.Println("\nShutting down worker...")
-	cancel()
-
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer shutdownCancel()
 
-	for executor.ActiveAssignments() > 0 {
-		select {
-		case <-shutdownCtx.Done():
-			fmt.Println("Shutdown timeout, forcing exit")
-			goto done
-		case <-time.After(500 * time.Millisecond):
-			fmt.Printf("Waiting for %d active assignment(s) to complete...\n", executor.ActiveAssignments())
-		}
-	}
-
-done:
-	shipped, dropped := telemetryShipper.Stats()
+	shipped, dropped := runner.Close(shutdownCtx)
 	fmt.Printf("Telemetry stats: shipped=%d dropped=%d\n", shipped, dropped)
+	if err := tracer.Shutdown(shutdownCtx); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to flush traces: %v\n", err)
+	}
 	fmt.Println("Worker stopped")
 }
 
@@ -140,191 +170,37 @@ func parsePrivateNetworks(s string) []string {
 	return result
 }
 
-func register(ctx context.Context, baseURL string, hostInfo types.HostInfo, capacity types.WorkerCapacity) (string, string, error) {
-	req := registerRequest{HostInfo: hostInfo, Capacity: capacity}
-	body, _ := json.Marshal(req)
-
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/workers/register", bytes.NewReader(body))
-	if err != nil {
-		return "", "", err
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	resp, err := http.DefaultClient.Do(httpReq)
-	if err != nil {
-		return "", "", err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusCreated {
-		respBody, _ := io.ReadAll(resp.Body)
-		return "", "", fmt.Errorf("registration failed: %s - %s", resp.Status, string(respBody))
-	}
-
-	var result registerResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", "", err
+// parseLabels parses the --labels flag: comma-separated key=value pairs.
+func parseLabels(s string) (map[string]string, error) {
+	if s == "" {
+		return nil, nil
 	}
-	return result.WorkerID, result.WorkerToken, nil
-}
-
-func heartbeatLoop(ctx context.Context, baseURL, workerID, workerToken string, interval time.Duration, executor *worker.AssignmentExecutor) {
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			resp, err := sendHeartbeat(ctx, baseURL, workerID, workerToken, executor)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Heartbeat failed: %v\n", err)
-				continue
-			}
-
-			for _, runID := range resp.StopRunIDs {
-				executor.StopRun(runID, false)
-			}
-			for _, runID := range resp.ImmediateStopRunIDs {
-				executor.StopRun(runID, true)
-			}
+	labels := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
 		}
-	}
-}
-
-func sendHeartbeat(ctx context.Context, baseURL, workerID, workerToken string, executor *worker.AssignmentExecutor) (*heartbeatResponse, error) {
-	var memStats runtime.MemStats
-	runtime.ReadMemStats(&memStats)
-
-	req := heartbeatRequest{
-		Health: &types.WorkerHealth{
-			MemBytes:  int64(memStats.Alloc),
-			ActiveVUs: executor.ActiveVUs(),
-		},
-	}
-	body, _ := json.Marshal(req)
-
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/workers/"+workerID+"/heartbeat", bytes.NewReader(body))
-	if err != nil {
-		return nil, err
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
-	if workerToken != "" {
-		httpReq.Header.Set("X-Worker-Token", workerToken)
-	}
-
-	resp, err := http.DefaultClient.Do(httpReq)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("heartbeat failed: %s", resp.Status)
-	}
-
-	var result heartbeatResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, err
-	}
-	return &result, nil
-}
-
-func pollAssignments(ctx context.Context, baseURL, workerID, workerToken string, interval time.Duration, executor *worker.AssignmentExecutor) {
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			assignments, err := getAssignments(ctx, baseURL, workerID, workerToken)
-			if err != nil {
-				continue
-			}
-			var started []types.WorkerAssignment
-			for _, a := range assignments {
-				if err := executor.Execute(ctx, a); err != nil {
-					fmt.Fprintf(os.Stderr, "Failed to execute assignment %s: %v\n", a.LeaseID, err)
-					continue
-				}
-				started = append(started, a)
-			}
-			if len(started) > 0 {
-				if err := ackAssignments(ctx, baseURL, workerID, workerToken, started); err != nil {
-					fmt.Fprintf(os.Stderr, "Failed to ack assignments: %v\n", err)
-				}
-			}
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || !types.ValidWorkerLabel(key, value) {
+			return nil, fmt.Errorf("%q is not a valid key=value label", pair)
 		}
+		labels[key] = value
 	}
+	return labels, nil
 }
 
-func getAssignments(ctx context.Context, baseURL, workerID, workerToken string) ([]types.WorkerAssignment, error) {
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/workers/"+workerID+"/assignments", nil)
+// isLoopbackURL reports whether rawURL points at this host, where a
+// plaintext worker token does not cross the network.
+func isLoopbackURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
 	if err != nil {
-		return nil, err
+		return false
 	}
-	if workerToken != "" {
-		httpReq.Header.Set("X-Worker-Token", workerToken)
+	host := u.Hostname()
+	if host == "localhost" {
+		return true
 	}
-
-	resp, err := http.DefaultClient.Do(httpReq)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("get assignments failed: %s", resp.Status)
-	}
-
-	var result assignmentsResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, err
-	}
-	return result.Assignments, nil
-}
-
-func ackAssignments(ctx context.Context, baseURL, workerID, workerToken string, assignments []types.WorkerAssignment) error {
-	if len(assignments) == 0 {
-		return nil
-	}
-
-	leaseIDs := make([]string, 0, len(assignments))
-	for _, assignment := range assignments {
-		if assignment.LeaseID == "" {
-			continue
-		}
-		leaseIDs = append(leaseIDs, assignment.LeaseID)
-	}
-	if len(leaseIDs) == 0 {
-		return nil
-	}
-
-	req := ackAssignmentsRequest{LeaseIDs: leaseIDs}
-	body, _ := json.Marshal(req)
-
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/workers/"+workerID+"/assignments/ack", bytes.NewReader(body))
-	if err != nil {
-		return err
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
-	if workerToken != "" {
-		httpReq.Header.Set("X-Worker-Token", workerToken)
-	}
-
-	resp, err := http.DefaultClient.Do(httpReq)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("ack assignments failed: %s - %s", resp.Status, string(respBody))
-	}
-
-	return nil
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
 }
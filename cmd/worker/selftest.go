@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/bc-dunia/mcpdrill/internal/worker"
+)
+
+// runSelfTest implements `worker selftest`, which benchmarks this host against an
+// in-process mock MCP endpoint and prints the throughput and latency it can sustain.
+// Returns the process exit code.
+func runSelfTest(args []string) int {
+	defaults := worker.DefaultSelfTestConfig()
+
+	fs := flag.NewFlagSet("selftest", flag.ContinueOnError)
+	rps := fs.Float64("rps", defaults.TargetRPS, "Target requests per second (0 = unlimited, find max throughput)")
+	vus := fs.Int("vus", defaults.VUs, "Number of virtual users")
+	duration := fs.Duration("duration", defaults.Duration, "How long to drive load")
+	op := fs.String("op", defaults.Operation, "Operation to exercise: ping, tools_list or tools_call")
+	jsonOutput := fs.Bool("json", false, "Print the result as JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	if !*jsonOutput {
+		fmt.Printf("Running self-test: rps=%.0f vus=%d duration=%s op=%s\n", *rps, *vus, *duration, *op)
+	}
+
+	result, err := worker.RunSelfTest(ctx, worker.SelfTestConfig{
+		TargetRPS: *rps,
+		VUs:       *vus,
+		Duration:  *duration,
+		Operation: *op,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Self-test failed: %v\n", err)
+		return 1
+	}
+
+	if *jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(result); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to encode result: %v\n", err)
+			return 1
+		}
+	} else {
+		printSelfTestResult(result)
+	}
+
+	if result.TargetRPS > 0 && !result.TargetReached {
+		return 3
+	}
+	return 0
+}
+
+func printSelfTestResult(r *worker.SelfTestResult) {
+	fmt.Printf("CPUs:             %d\n", r.NumCPU)
+	fmt.Printf("Operations:       %d (failed %d)\n", r.TotalOps, r.FailedOps)
+	fmt.Printf("Throughput:       %.1f ops/s\n", r.AchievedRPS)
+	fmt.Printf("Latency overhead: p50=%.2fms p95=%.2fms p99=%.2fms max=%.2fms\n", r.LatencyP50Ms, r.LatencyP95Ms, r.LatencyP99Ms, r.LatencyMaxMs)
+	if r.TargetRPS > 0 {
+		if r.TargetReached {
+			fmt.Printf("Result:           PASS (target %.0f ops/s reached)\n", r.TargetRPS)
+		} else {
+			fmt.Printf("Result:           FAIL (reached %.1f of %.0f ops/s target)\n", r.AchievedRPS, r.TargetRPS)
+		}
+	}
+}
@@ -2,11 +2,17 @@
 // The agent collects host and process metrics and reports them to the control plane.
 package agent
 
+import "sort"
+
 // HostMetrics contains system-level metrics collected from the host.
 type HostMetrics struct {
 	// CPUPercent is the overall CPU usage percentage (0-100).
 	CPUPercent float64 `json:"cpu_percent"`
 
+	// CPUCores is the number of logical CPUs, used to convert CPUPercent
+	// into cores in use when rolling up clustered targets.
+	CPUCores int `json:"cpu_cores,omitempty"`
+
 	// MemTotal is the total system memory in bytes.
 	MemTotal uint64 `json:"mem_total"`
 
@@ -36,6 +42,32 @@ type HostMetrics struct {
 
 	// SwapUsed is the used swap memory in bytes.
 	SwapUsed uint64 `json:"swap_used,omitempty"`
+
+	// GPUs lists the host's NVIDIA GPUs when GPU collection is enabled.
+	GPUs []GPUMetrics `json:"gpus,omitempty"`
+}
+
+// GPUMetrics contains metrics of one NVIDIA GPU, as reported by NVML.
+type GPUMetrics struct {
+	// Index is the GPU's NVML index on the host.
+	Index int `json:"index"`
+
+	// Name is the product name, e.g. "NVIDIA A100-SXM4-40GB".
+	Name string `json:"name,omitempty"`
+
+	// UtilizationPercent is the share of time a kernel ran on the GPU
+	// over the driver's last sample period (0-100).
+	UtilizationPercent float64 `json:"utilization_percent"`
+
+	// MemUsed and MemTotal are the GPU's framebuffer memory in bytes.
+	MemUsed  uint64 `json:"mem_used"`
+	MemTotal uint64 `json:"mem_total"`
+
+	// TemperatureC is the core temperature in degrees Celsius.
+	TemperatureC float64 `json:"temperature_c,omitempty"`
+
+	// PowerWatts is the current power draw, if the GPU reports it.
+	PowerWatts float64 `json:"power_watts,omitempty"`
 }
 
 // ProcessMetrics contains metrics for the monitored MCP server process.
@@ -60,4 +92,123 @@ type ProcessMetrics struct {
 
 	// OpenConnections is the number of open network connections.
 	OpenConnections int `json:"open_connections,omitempty"`
+
+	// GPUMemUsed is the GPU memory the process holds across all GPUs, in
+	// bytes, when GPU collection is enabled.
+	GPUMemUsed uint64 `json:"gpu_mem_used,omitempty"`
+
+	// PIDs lists the processes, lowest first, when these metrics sum
+	// several; PID is then the first of them.
+	PIDs []int `json:"pids,omitempty"`
+}
+
+// Add adds o's metrics to p, so a group of processes reports as one.
+func (p *ProcessMetrics) Add(o *ProcessMetrics) {
+	pids := append(p.PIDList(), o.PIDList()...)
+	sort.Ints(pids)
+	p.PIDs = pids
+	p.PID = pids[0]
+	p.CPUPercent += o.CPUPercent
+	p.MemRSS += o.MemRSS
+	p.MemVMS += o.MemVMS
+	p.NumThreads += o.NumThreads
+	p.NumFDs += o.NumFDs
+	p.OpenConnections += o.OpenConnections
+	p.GPUMemUsed += o.GPUMemUsed
+}
+
+// PIDList returns the processes the metrics cover: PIDs if set, else PID.
+func (p *ProcessMetrics) PIDList() []int {
+	if len(p.PIDs) > 0 {
+		return append([]int(nil), p.PIDs...)
+	}
+	return []int{p.PID}
+}
+
+// LogEntry is a line of the monitored server's log, with any continuation
+// lines, such as a stack trace, that followed it.
+type LogEntry struct {
+	// Timestamp is when the agent read the line, in Unix milliseconds on
+	// the control plane's clock.
+	Timestamp int64 `json:"timestamp"`
+
+	// File is the path of the log file the line was read from.
+	File string `json:"file"`
+
+	// Text is the line, continuation lines separated by newlines, with
+	// anything matching the agent's redaction patterns replaced.
+	Text string `json:"text"`
+}
+
+// ContainerMetrics contains the cgroup metrics of the container, or other
+// cgroup, the monitored process runs in. Limits are 0 when unlimited.
+type ContainerMetrics struct {
+	// CgroupVersion is 1 or 2.
+	CgroupVersion int `json:"cgroup_version"`
+
+	// ID is the container ID found in the cgroup path, if any.
+	ID string `json:"id,omitempty"`
+
+	// CPULimitCores is the CPU quota in cores.
+	CPULimitCores float64 `json:"cpu_limit_cores,omitempty"`
+
+	// CPUUsageCores is the CPU time used per second since the previous
+	// sample, in cores.
+	CPUUsageCores float64 `json:"cpu_usage_cores"`
+
+	// CPUThrottledPercent is the share of scheduler periods since the
+	// previous sample in which the cgroup hit its quota (0-100).
+	CPUThrottledPercent float64 `json:"cpu_throttled_percent"`
+
+	// CPUThrottledPeriods and CPUThrottledUsec are the cgroup's lifetime
+	// throttling counters.
+	CPUThrottledPeriods uint64 `json:"cpu_throttled_periods,omitempty"`
+	CPUThrottledUsec    uint64 `json:"cpu_throttled_usec,omitempty"`
+
+	// MemUsage is the cgroup's memory usage in bytes, page cache included.
+	MemUsage uint64 `json:"mem_usage"`
+
+	// MemLimit is the cgroup's memory limit in bytes.
+	MemLimit uint64 `json:"mem_limit,omitempty"`
+
+	// OOMKills is the lifetime count of processes killed in the cgroup for
+	// running out of memory.
+	OOMKills uint64 `json:"oom_kills,omitempty"`
+}
+
+// TargetMetrics contains the metrics of one of several processes an agent
+// monitors, such as the servers of a fleet sharing a host.
+type TargetMetrics struct {
+	// Label names the target, unique within the agent.
+	Label string `json:"label"`
+
+	// PairKey binds the target to the runs using this pair key. Targets
+	// without one belong to every pair key the agent serves.
+	PairKey string `json:"pair_key,omitempty"`
+
+	// Process holds the target's process metrics. A target matching
+	// several processes, such as a pre-forking server's workers, sums
+	// them, with PID set to the lowest.
+	Process *ProcessMetrics `json:"process,omitempty"`
+
+	// Container holds the cgroup metrics of the target's container.
+	Container *ContainerMetrics `json:"container,omitempty"`
+}
+
+// ScrapedMetric is a series read from the monitored server's own
+// Prometheus metrics endpoint.
+type ScrapedMetric struct {
+	// Name is the series name, e.g. "mcp_queue_depth" or
+	// "mcp_handler_seconds_sum".
+	Name string `json:"name"`
+
+	// Labels are the series labels.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Type is the metric family's type: counter, gauge, histogram or
+	// summary, or empty when untyped.
+	Type string `json:"type,omitempty"`
+
+	// Value is the series value when scraped.
+	Value float64 `json:"value"`
 }
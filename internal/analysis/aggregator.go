@@ -2,6 +2,7 @@
 package analysis
 
 import (
+	"fmt"
 	"sort"
 	"sync"
 )
@@ -14,6 +15,45 @@ type OperationResult struct {
 	OK        bool   // whether operation succeeded
 	ErrorType string // error classification if failed
 	SessionID string // session identifier for session metrics tracking
+	Group     string // generator group of the VU, empty if the run has no groups
+	Target    string // target the VU sent to, empty if the run has a single target
+	Stage     string // stage the operation ran in (preflight, baseline, ramp, soak)
+	WorkerID  string // worker that ran the operation
+
+	Connection   *ConnectionSample   // connection and DNS data, nil if not traced
+	RequestID    *RequestIDSample    // JSON-RPC id variant, nil unless ids are varied
+	Checks       []CheckSample       // response check results, nil if none configured
+	StreamResume *StreamResumeSample // resumption of a dropped response stream, nil if none
+	Stream       *StreamSample       // timing and progress of a streamed response, nil if not streamed
+	Cancellation *CancellationSample // cancellation in flight, nil unless the call was picked for one
+
+	ServerMessages *ServerMessageSample // server-initiated messages on the response stream, nil if none
+
+	FuzzMutation string // mutation applied to the call's arguments, empty if not fuzzed
+	Attempt      int    // attempt index of a retried operation, 0 for the first
+
+	RetryAfterMs   int64 // delay a rate limited response asked for, 0 if none
+	ThrottleWaitMs int64 // time the VU held the operation back after rate limited responses
+	ThinkTimeMs    int64 // time the VU paused after starting the operation, before its next one
+
+	Warmup bool // ran during the stage's warmup; counted apart from the measured operations
+
+	Shadow *ShadowSample // the shadow target's answer to the same call, nil without a shadow
+	Chaos  *ChaosSample  // network faults injected into the operation, nil if none
+
+	Workflow *WorkflowSample // the workflow run the operation was a step of, nil if none
+
+	// Summarized marks an operation standing in for one of a worker
+	// summary, for readers of single operations such as stop conditions.
+	// Reports count the summary through AddSummary and skip it.
+	Summarized bool
+
+	TimestampMs int64 // when the operation ran, Unix ms; 0 if unknown
+
+	OpID         string // operation ID, referenced by error signatures
+	ErrorCode    string // error code if failed
+	JSONRPCCode  *int   // JSON-RPC error code of the response, nil if none
+	ErrorMessage string // error text, or the text a tool returned with its error
 }
 
 // normalizeOpName converts operation names to canonical form.
@@ -60,9 +100,47 @@ type AggregatedMetrics struct {
 	ErrorRate      float64                      `json:"error_rate"`
 	ByOperation    map[string]*OperationMetrics `json:"by_operation"`
 	ByTool         map[string]*OperationMetrics `json:"by_tool"`
+	ByGroup        map[string]*OperationMetrics `json:"by_group,omitempty"`
+	ByTarget       map[string]*OperationMetrics `json:"by_target,omitempty"`
 	SessionMetrics *SessionReportMetrics        `json:"session_metrics,omitempty"`
 	WorkerHealth   *WorkerHealthMetrics         `json:"worker_health,omitempty"`
 	ChurnMetrics   *ChurnReportMetrics          `json:"churn_metrics,omitempty"`
+	Connections    *ConnectionReportMetrics     `json:"connections,omitempty"`
+	RequestIDs     *RequestIDReportMetrics      `json:"request_ids,omitempty"`
+	Checks         *CheckReportMetrics          `json:"checks,omitempty"`
+	Fuzz           *FuzzReportMetrics           `json:"fuzz,omitempty"`
+	Resumption     *ResumptionReportMetrics     `json:"resumption,omitempty"`
+	ServerMessages *ServerMessageReportMetrics  `json:"server_messages,omitempty"`
+	Cancellation   *CancellationReportMetrics   `json:"cancellation,omitempty"`
+	Throttling     *ThrottleReportMetrics       `json:"throttling,omitempty"`
+	Pacing         *PacingReportMetrics         `json:"pacing,omitempty"`
+	Retries        *RetryReportMetrics          `json:"retries,omitempty"`
+	Shadow         *ShadowReportMetrics         `json:"shadow,omitempty"`
+	Chaos          *ChaosReportMetrics          `json:"chaos,omitempty"`
+	LatencyBuckets *LatencyBucketReportMetrics  `json:"latency_buckets,omitempty"`
+
+	// ErrorSignatures are the most frequent kinds of failure, grouped by
+	// normalized error message and classification.
+	ErrorSignatures []ErrorSignature `json:"error_signatures,omitempty"`
+
+	// TailOpsByOperation counts the operations at or above the run's p99
+	// latency, by operation, to show which operations make up the tail.
+	TailOpsByOperation map[string]int `json:"tail_ops_by_operation,omitempty"`
+
+	// WarmupOps counts the operations run during stage warmups. They are
+	// left out of every other metric.
+	WarmupOps int `json:"warmup_ops,omitempty"`
+
+	// StreamingByTool summarizes the streamed tools/call responses by tool.
+	StreamingByTool map[string]*StreamingMetrics `json:"streaming_by_tool,omitempty"`
+
+	// Workflows reports the runs of each workflow, by workflow name.
+	Workflows map[string]*WorkflowReportMetrics `json:"workflows,omitempty"`
+
+	// ByWorkerLabel breaks the operations down by the labels of the workers
+	// that ran them, by label key and then value. It is set only if worker
+	// labels were given.
+	ByWorkerLabel map[string]map[string]*OperationMetrics `json:"by_worker_label,omitempty"`
 }
 
 // SessionReportMetrics contains session-specific metrics for A/B comparison.
@@ -85,6 +163,24 @@ type WorkerHealthMetrics struct {
 	WorkerCount        int     `json:"worker_count"`                // Total workers used
 	SaturationDetected bool    `json:"saturation_detected"`         // CPU > 80% or VUs at cap
 	SaturationReason   string  `json:"saturation_reason,omitempty"` // Reason for saturation if detected
+
+	// PeakShedRate is the largest share of load any worker shed to relieve
+	// its own resources; Warnings lists each change in shedding.
+	PeakShedRate float64            `json:"peak_shed_rate,omitempty"`
+	Warnings     []GeneratorWarning `json:"warnings,omitempty"`
+}
+
+// GeneratorWarning is a warning a worker raised about its own health, such
+// as starting to shed load because it ran short of CPU, memory or file
+// descriptors. Usage and Limit are in percent.
+type GeneratorWarning struct {
+	WorkerID    string  `json:"worker_id"`
+	TimestampMs int64   `json:"timestamp_ms"`
+	Kind        string  `json:"kind"`
+	Resource    string  `json:"resource,omitempty"`
+	Usage       float64 `json:"usage,omitempty"`
+	Limit       float64 `json:"limit,omitempty"`
+	ShedRate    float64 `json:"shed_rate"`
 }
 
 // WorkerHealthSample represents a single health sample from a worker.
@@ -113,10 +209,33 @@ type ChurnSample struct {
 	ReconnectAttempts int64
 }
 
-// Aggregator collects operation results and computes metrics.
+// Aggregator computes metrics from operation results. Operations are folded
+// into counters and latency histograms as they are added, so its memory does
+// not grow with the number of operations.
 type Aggregator struct {
 	mu             sync.RWMutex
-	operations     []OperationResult
+	total          operationStats
+	byOperation    map[string]*operationStats
+	byTool         map[string]*operationStats
+	byGroup        map[string]*operationStats
+	byTarget       map[string]*operationStats
+	byWorker       map[string]*operationStats
+	sessions       map[string]struct{}
+	connections    connectionStats
+	requestIDs     requestIDStats
+	checks         checkStats
+	fuzz           fuzzStats
+	resumption     resumptionStats
+	serverMessages serverMessageStats
+	cancellation   cancellationStats
+	throttle       throttleStats
+	pacing         pacingStats
+	workflows      workflowStats
+	retries        retryStats
+	shadow         shadowStats
+	chaos          chaosStats
+	warmup         int
+	streaming      map[string]*streamingStats
 	startTime      int64
 	endTime        int64
 	sessionMode    string
@@ -125,6 +244,89 @@ type Aggregator struct {
 	workersSeen    map[string]struct{}
 	maxVUsConfig   int
 	churnSamples   []ChurnSample
+	latencyBuckets *LatencyBucketConfig
+	workerLabels   map[string]map[string]string
+
+	generatorWarnings []GeneratorWarning
+	// failuresBySecond counts failed operations by the start of the second
+	// they ran in, for correlation with server logs.
+	failuresBySecond map[int64]int
+	// loadBuckets tracks operations by the start of the LoadBucketMs bucket
+	// they ran in, for lining load up with server metrics.
+	loadBuckets map[int64]*loadStats
+	// signatures groups the failed operations into error signatures.
+	signatures errorSignatureStats
+}
+
+// TopErrorSignatures is the number of error signatures reported.
+const TopErrorSignatures = 10
+
+// loadStats accumulates the operations of one bucket of the load timeline.
+type loadStats struct {
+	operationStats
+	stalls int
+}
+
+func (s *loadStats) add(op OperationResult) {
+	s.operationStats.add(op)
+	if op.ErrorType == streamStallErrorType || op.Stream != nil && op.Stream.Stalled {
+		s.stalls++
+	}
+}
+
+func (s *loadStats) merge(o *loadStats) {
+	s.operationStats.merge(&o.operationStats)
+	s.stalls += o.stalls
+}
+
+// streamStallErrorType is the error type of operations whose response
+// stream stalled past the stall timeout.
+const streamStallErrorType = "stream_stall"
+
+// operationStats accumulates the outcomes and latencies of one operation,
+// tool, generator group, target or worker.
+type operationStats struct {
+	success int
+	failure int
+	latency Histogram
+}
+
+func (s *operationStats) add(op OperationResult) {
+	s.latency.Record(int64(op.LatencyMs))
+	if op.OK {
+		s.success++
+	} else {
+		s.failure++
+	}
+}
+
+func (s *operationStats) merge(o *operationStats) {
+	s.success += o.success
+	s.failure += o.failure
+	s.latency.Merge(&o.latency)
+}
+
+func (s *operationStats) metrics() *OperationMetrics {
+	total := s.success + s.failure
+	return &OperationMetrics{
+		TotalOps:   total,
+		SuccessOps: s.success,
+		FailureOps: s.failure,
+		LatencyP50: int(s.latency.Percentile(50)),
+		LatencyP95: int(s.latency.Percentile(95)),
+		LatencyP99: int(s.latency.Percentile(99)),
+		ErrorRate:  float64(s.failure) / float64(total),
+	}
+}
+
+// statsFor returns the stats for key, creating them if needed.
+func statsFor[K comparable](m map[K]*operationStats, key K) *operationStats {
+	s := m[key]
+	if s == nil {
+		s = &operationStats{}
+		m[key] = s
+	}
+	return s
 }
 
 // SessionManagerMetrics holds metrics from the session manager for reporting.
@@ -137,10 +339,19 @@ type SessionManagerMetrics struct {
 // NewAggregator creates a new Aggregator instance.
 func NewAggregator() *Aggregator {
 	return &Aggregator{
-		operations:    make([]OperationResult, 0),
+		byOperation:   make(map[string]*operationStats),
+		byTool:        make(map[string]*operationStats),
+		byGroup:       make(map[string]*operationStats),
+		byTarget:      make(map[string]*operationStats),
+		byWorker:      make(map[string]*operationStats),
+		streaming:     make(map[string]*streamingStats),
+		sessions:      make(map[string]struct{}),
 		healthSamples: make([]WorkerHealthSample, 0),
 		workersSeen:   make(map[string]struct{}),
 		churnSamples:  make([]ChurnSample, 0),
+
+		failuresBySecond: make(map[int64]int),
+		loadBuckets:      make(map[int64]*loadStats),
 	}
 }
 
@@ -167,6 +378,23 @@ func (a *Aggregator) SetMaxVUsConfig(maxVUs int) {
 	a.maxVUsConfig = maxVUs
 }
 
+// SetLatencyBuckets sets the bucket boundaries and deadlines latencies are
+// reported against. Without them the metrics carry no latency buckets.
+func (a *Aggregator) SetLatencyBuckets(cfg *LatencyBucketConfig) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.latencyBuckets = cfg
+}
+
+// SetWorkerLabels sets the labels of each worker, by worker ID, that the
+// operations are broken down by. Without them the metrics carry no worker
+// label breakdown.
+func (a *Aggregator) SetWorkerLabels(labels map[string]map[string]string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.workerLabels = labels
+}
+
 // AddWorkerHealth adds a worker health sample for aggregation.
 func (a *Aggregator) AddWorkerHealth(sample WorkerHealthSample) {
 	a.mu.Lock()
@@ -177,6 +405,16 @@ func (a *Aggregator) AddWorkerHealth(sample WorkerHealthSample) {
 	}
 }
 
+// AddGeneratorWarning adds a warning a worker raised about its own health.
+func (a *Aggregator) AddGeneratorWarning(warning GeneratorWarning) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.generatorWarnings = append(a.generatorWarnings, warning)
+	if warning.WorkerID != "" {
+		a.workersSeen[warning.WorkerID] = struct{}{}
+	}
+}
+
 // AddChurnSample adds a churn metrics sample for aggregation.
 func (a *Aggregator) AddChurnSample(sample ChurnSample) {
 	a.mu.Lock()
@@ -189,125 +427,306 @@ func (a *Aggregator) AddChurnSample(sample ChurnSample) {
 func (a *Aggregator) AddOperation(op OperationResult) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
-	a.operations = append(a.operations, op)
-}
 
-// Compute calculates all aggregated metrics from collected operations.
-func (a *Aggregator) Compute() *AggregatedMetrics {
-	a.mu.RLock()
-	defer a.mu.RUnlock()
-
-	metrics := &AggregatedMetrics{
-		ByOperation: make(map[string]*OperationMetrics, len(a.operations)),
-		ByTool:      make(map[string]*OperationMetrics, len(a.operations)),
+	if op.Warmup {
+		a.warmup++
+		return
+	}
+	a.total.add(op)
+	if !op.OK {
+		a.signatures.add(ErrorLog{
+			TimestampMs: op.TimestampMs,
+			Operation:   normalizeOpName(op.Operation),
+			ToolName:    op.ToolName,
+			ErrorType:   op.ErrorType,
+			ErrorCode:   op.ErrorCode,
+			JSONRPCCode: op.JSONRPCCode,
+			Message:     op.ErrorMessage,
+			OpID:        op.OpID,
+		})
+	}
+	if op.TimestampMs > 0 {
+		if !op.OK {
+			a.failuresBySecond[op.TimestampMs-op.TimestampMs%1000]++
+		}
+		bucket := op.TimestampMs - op.TimestampMs%LoadBucketMs
+		if a.loadBuckets[bucket] == nil {
+			a.loadBuckets[bucket] = &loadStats{}
+		}
+		a.loadBuckets[bucket].add(op)
 	}
 
-	metrics.WorkerHealth = a.computeWorkerHealthMetrics()
-	metrics.ChurnMetrics = a.computeChurnMetrics()
+	normalizedOp := normalizeOpName(op.Operation)
+	statsFor(a.byOperation, normalizedOp).add(op)
+	if (normalizedOp == "tools/call" || normalizedOp == "tools_call") && op.ToolName != "" {
+		statsFor(a.byTool, op.ToolName).add(op)
+		if op.Stream != nil {
+			streamingFor(a.streaming, op.ToolName).add(op.Stream)
+		}
+	}
+	if op.Group != "" {
+		statsFor(a.byGroup, op.Group).add(op)
+	}
+	if op.Target != "" {
+		statsFor(a.byTarget, op.Target).add(op)
+	}
+	if op.WorkerID != "" {
+		statsFor(a.byWorker, op.WorkerID).add(op)
+	}
+	if op.SessionID != "" {
+		a.sessions[op.SessionID] = struct{}{}
+	}
 
-	if len(a.operations) == 0 {
-		return metrics
+	if op.Connection != nil {
+		a.connections.add(op.Connection)
+	}
+	if op.RequestID != nil {
+		a.requestIDs.add(op)
+	}
+	if len(op.Checks) > 0 {
+		a.checks.add(normalizedOp, op)
+	}
+	if op.FuzzMutation != "" {
+		a.fuzz.add(op)
+	}
+	if op.StreamResume != nil {
+		a.resumption.add(op.StreamResume)
+	}
+	if op.ServerMessages != nil {
+		a.serverMessages.add(op.ServerMessages)
+	}
+	if op.Cancellation != nil {
+		a.cancellation.add(op.Cancellation)
+	}
+	a.throttle.add(op)
+	a.pacing.add(op)
+	if op.Workflow != nil {
+		a.workflows.add(op)
 	}
+	a.retries.add(op)
+	if op.Shadow != nil {
+		a.shadow.add(normalizedOp, op)
+	}
+	if op.Chaos != nil {
+		a.chaos.add(op.Chaos)
+	}
+}
 
-	// Collect all latencies for global percentiles
-	allLatencies := make([]int, 0, len(a.operations))
+// Merge adds everything o has collected: operations, worker health and
+// churn samples. The time range, session info, latency buckets and worker
+// labels of a are kept.
+// Thread-safe.
+func (a *Aggregator) Merge(o *Aggregator) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	a.mu.Lock()
+	defer a.mu.Unlock()
 
-	// Group operations by type and tool
-	opLatencies := make(map[string][]int, len(a.operations))
-	opSuccess := make(map[string]int, len(a.operations))
-	opFailure := make(map[string]int, len(a.operations))
+	a.total.merge(&o.total)
+	for _, pair := range []struct{ dst, src map[string]*operationStats }{
+		{a.byOperation, o.byOperation},
+		{a.byTool, o.byTool},
+		{a.byGroup, o.byGroup},
+		{a.byTarget, o.byTarget},
+		{a.byWorker, o.byWorker},
+	} {
+		for key, stats := range pair.src {
+			statsFor(pair.dst, key).merge(stats)
+		}
+	}
+	for id := range o.sessions {
+		a.sessions[id] = struct{}{}
+	}
+	a.connections.merge(&o.connections)
+	a.requestIDs.merge(&o.requestIDs)
+	a.checks.merge(&o.checks)
+	a.fuzz.merge(&o.fuzz)
+	a.resumption.merge(&o.resumption)
+	a.serverMessages.merge(&o.serverMessages)
+	a.cancellation.merge(&o.cancellation)
+	a.throttle.merge(&o.throttle)
+	a.pacing.merge(&o.pacing)
+	a.workflows.merge(&o.workflows)
+	a.retries.merge(&o.retries)
+	a.shadow.merge(&o.shadow)
+	a.chaos.merge(&o.chaos)
+	a.signatures.merge(&o.signatures)
+	a.warmup += o.warmup
+	for tool, s := range o.streaming {
+		streamingFor(a.streaming, tool).merge(s)
+	}
 
-	toolLatencies := make(map[string][]int, len(a.operations))
-	toolSuccess := make(map[string]int, len(a.operations))
-	toolFailure := make(map[string]int, len(a.operations))
+	a.healthSamples = append(a.healthSamples, o.healthSamples...)
+	for id := range o.workersSeen {
+		a.workersSeen[id] = struct{}{}
+	}
+	a.churnSamples = append(a.churnSamples, o.churnSamples...)
+	for sec, n := range o.failuresBySecond {
+		a.failuresBySecond[sec] += n
+	}
+	for bucket, stats := range o.loadBuckets {
+		if a.loadBuckets[bucket] == nil {
+			a.loadBuckets[bucket] = &loadStats{}
+		}
+		a.loadBuckets[bucket].merge(stats)
+	}
+}
 
-	for _, op := range a.operations {
-		metrics.TotalOps++
-		allLatencies = append(allLatencies, op.LatencyMs)
+// FailuresBySecond returns how many operations failed in each second, keyed
+// by the second's start in Unix ms. Operations without a timestamp are left
+// out. Thread-safe.
+func (a *Aggregator) FailuresBySecond() map[int64]int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	failures := make(map[int64]int, len(a.failuresBySecond))
+	for sec, n := range a.failuresBySecond {
+		failures[sec] = n
+	}
+	return failures
+}
 
-		if op.OK {
-			metrics.SuccessOps++
-		} else {
-			metrics.FailureOps++
-		}
+// LoadTimeline returns the operations run in each LoadBucketMs bucket that
+// had any, ordered by time. Operations without a timestamp are left out.
+// Thread-safe.
+func (a *Aggregator) LoadTimeline() []LoadPoint {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	points := make([]LoadPoint, 0, len(a.loadBuckets))
+	for bucket, s := range a.loadBuckets {
+		points = append(points, LoadPoint{
+			TimestampMs:  bucket,
+			Ops:          s.success + s.failure,
+			Failures:     s.failure,
+			Stalls:       s.stalls,
+			LatencyP95Ms: int(s.latency.Percentile(95)),
+		})
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].TimestampMs < points[j].TimestampMs })
+	return points
+}
 
-		normalizedOp := normalizeOpName(op.Operation)
+// Compute calculates all aggregated metrics from collected operations.
+func (a *Aggregator) Compute() *AggregatedMetrics {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
 
-		opLatencies[normalizedOp] = append(opLatencies[normalizedOp], op.LatencyMs)
-		if op.OK {
-			opSuccess[normalizedOp]++
-		} else {
-			opFailure[normalizedOp]++
-		}
+	metrics := &AggregatedMetrics{
+		ByOperation: make(map[string]*OperationMetrics, len(a.byOperation)),
+		ByTool:      make(map[string]*OperationMetrics, len(a.byTool)),
+	}
 
-		if (normalizedOp == "tools/call" || normalizedOp == "tools_call") && op.ToolName != "" {
-			toolLatencies[op.ToolName] = append(toolLatencies[op.ToolName], op.LatencyMs)
-			if op.OK {
-				toolSuccess[op.ToolName]++
-			} else {
-				toolFailure[op.ToolName]++
-			}
+	metrics.WorkerHealth = a.computeWorkerHealthMetrics()
+	metrics.ChurnMetrics = a.computeChurnMetrics()
+	metrics.Connections = a.connections.metrics()
+	metrics.RequestIDs = a.requestIDs.metrics()
+	metrics.Checks = a.checks.metrics()
+	metrics.Fuzz = a.fuzz.metrics()
+	metrics.Resumption = a.resumption.metrics()
+	metrics.ServerMessages = a.serverMessages.metrics()
+	metrics.Cancellation = a.cancellation.metrics()
+	metrics.Pacing = a.pacing.metrics()
+	metrics.Workflows = a.workflows.metrics()
+	metrics.Retries = a.retries.metrics()
+	metrics.Shadow = a.shadow.metrics()
+	metrics.WarmupOps = a.warmup
+	if len(a.streaming) > 0 {
+		metrics.StreamingByTool = make(map[string]*StreamingMetrics, len(a.streaming))
+		for tool, s := range a.streaming {
+			metrics.StreamingByTool[tool] = s.metrics()
 		}
 	}
 
+	totalOps := a.total.success + a.total.failure
+	if totalOps == 0 {
+		return metrics
+	}
+
 	// Compute global metrics
-	metrics.LatencyP50 = computePercentile(allLatencies, 50)
-	metrics.LatencyP95 = computePercentile(allLatencies, 95)
-	metrics.LatencyP99 = computePercentile(allLatencies, 99)
+	metrics.TotalOps = totalOps
+	metrics.SuccessOps = a.total.success
+	metrics.FailureOps = a.total.failure
+	metrics.LatencyP50 = int(a.total.latency.Percentile(50))
+	metrics.LatencyP95 = int(a.total.latency.Percentile(95))
+	metrics.LatencyP99 = int(a.total.latency.Percentile(99))
 	metrics.ErrorRate = float64(metrics.FailureOps) / float64(metrics.TotalOps)
+	if sigs := a.signatures.top(TopErrorSignatures); len(sigs) > 0 {
+		metrics.ErrorSignatures = sigs
+	}
+	metrics.TailOpsByOperation = make(map[string]int, len(a.byOperation))
+	for opName, stats := range a.byOperation {
+		if n := stats.latency.CountAtLeast(int64(metrics.LatencyP99)); n > 0 {
+			metrics.TailOpsByOperation[opName] = int(n)
+		}
+	}
 
 	// Compute RPS
+	var durationSec float64
 	if a.endTime > a.startTime {
-		durationSec := float64(a.endTime-a.startTime) / 1000.0
+		durationSec = float64(a.endTime-a.startTime) / 1000.0
 		metrics.RPS = float64(metrics.TotalOps) / durationSec
 	}
+	metrics.Throttling = a.throttle.metrics(totalOps, metrics.SuccessOps, durationSec)
+	metrics.Chaos = a.chaos.metrics(totalOps, metrics.FailureOps)
 
-	// Compute per-operation metrics
-	for opName, latencies := range opLatencies {
-		total := opSuccess[opName] + opFailure[opName]
-		metrics.ByOperation[opName] = &OperationMetrics{
-			TotalOps:   total,
-			SuccessOps: opSuccess[opName],
-			FailureOps: opFailure[opName],
-			LatencyP50: computePercentile(latencies, 50),
-			LatencyP95: computePercentile(latencies, 95),
-			LatencyP99: computePercentile(latencies, 99),
-			ErrorRate:  float64(opFailure[opName]) / float64(total),
-		}
+	for opName, stats := range a.byOperation {
+		metrics.ByOperation[opName] = stats.metrics()
 	}
-
-	// Compute per-tool metrics
-	for toolName, latencies := range toolLatencies {
-		total := toolSuccess[toolName] + toolFailure[toolName]
-		metrics.ByTool[toolName] = &OperationMetrics{
-			TotalOps:   total,
-			SuccessOps: toolSuccess[toolName],
-			FailureOps: toolFailure[toolName],
-			LatencyP50: computePercentile(latencies, 50),
-			LatencyP95: computePercentile(latencies, 95),
-			LatencyP99: computePercentile(latencies, 99),
-			ErrorRate:  float64(toolFailure[toolName]) / float64(total),
-		}
+	for toolName, stats := range a.byTool {
+		metrics.ByTool[toolName] = stats.metrics()
+	}
+	if len(a.byGroup) > 0 {
+		metrics.ByGroup = make(map[string]*OperationMetrics, len(a.byGroup))
 	}
+	for groupName, stats := range a.byGroup {
+		metrics.ByGroup[groupName] = stats.metrics()
+	}
+	if len(a.byTarget) > 0 {
+		metrics.ByTarget = make(map[string]*OperationMetrics, len(a.byTarget))
+	}
+	for targetName, stats := range a.byTarget {
+		metrics.ByTarget[targetName] = stats.metrics()
+	}
+	metrics.ByWorkerLabel = a.workerLabelMetrics()
+	metrics.LatencyBuckets = a.latencyBucketMetrics(a.latencyBuckets)
 
 	metrics.SessionMetrics = a.computeSessionMetrics()
 
 	return metrics
 }
 
-func (a *Aggregator) computeSessionMetrics() *SessionReportMetrics {
-	if a.sessionMode == "" {
-		uniqueSessions := make(map[string]struct{})
-		for _, op := range a.operations {
-			if op.SessionID != "" {
-				uniqueSessions[op.SessionID] = struct{}{}
+// workerLabelMetrics rolls the per-worker stats up by worker label. Workers
+// without labels are left out.
+func (a *Aggregator) workerLabelMetrics() map[string]map[string]*OperationMetrics {
+	byLabel := make(map[string]map[string]*operationStats)
+	for workerID, stats := range a.byWorker {
+		for key, value := range a.workerLabels[workerID] {
+			if byLabel[key] == nil {
+				byLabel[key] = make(map[string]*operationStats)
 			}
+			statsFor(byLabel[key], value).merge(stats)
+		}
+	}
+	if len(byLabel) == 0 {
+		return nil
+	}
+	metrics := make(map[string]map[string]*OperationMetrics, len(byLabel))
+	for key, values := range byLabel {
+		metrics[key] = make(map[string]*OperationMetrics, len(values))
+		for value, stats := range values {
+			metrics[key][value] = stats.metrics()
 		}
-		if len(uniqueSessions) == 0 {
+	}
+	return metrics
+}
+
+func (a *Aggregator) computeSessionMetrics() *SessionReportMetrics {
+	totalSessions := len(a.sessions)
+	totalOps := a.total.success + a.total.failure
+
+	if a.sessionMode == "" {
+		if totalSessions == 0 {
 			return nil
 		}
-		totalSessions := len(uniqueSessions)
-		totalOps := len(a.operations)
 		opsPerSession := float64(totalOps) / float64(totalSessions)
 		var reuseRate float64
 		if totalOps > 0 {
@@ -321,16 +740,6 @@ func (a *Aggregator) computeSessionMetrics() *SessionReportMetrics {
 		}
 	}
 
-	uniqueSessions := make(map[string]struct{})
-	for _, op := range a.operations {
-		if op.SessionID != "" {
-			uniqueSessions[op.SessionID] = struct{}{}
-		}
-	}
-
-	totalSessions := len(uniqueSessions)
-	totalOps := len(a.operations)
-
 	var opsPerSession float64
 	if totalSessions > 0 {
 		opsPerSession = float64(totalOps) / float64(totalSessions)
@@ -387,7 +796,7 @@ func (a *Aggregator) computeChurnMetrics() *ChurnReportMetrics {
 }
 
 func (a *Aggregator) computeWorkerHealthMetrics() *WorkerHealthMetrics {
-	if len(a.healthSamples) == 0 {
+	if len(a.healthSamples) == 0 && len(a.generatorWarnings) == 0 {
 		return nil
 	}
 
@@ -406,7 +815,10 @@ func (a *Aggregator) computeWorkerHealthMetrics() *WorkerHealthMetrics {
 		totalVUs += sample.ActiveVUs
 	}
 
-	avgVUs := float64(totalVUs) / float64(sampleCount)
+	var avgVUs float64
+	if sampleCount > 0 {
+		avgVUs = float64(totalVUs) / float64(sampleCount)
+	}
 	peakMemMB := float64(peakMemBytes) / (1024 * 1024)
 
 	metrics := &WorkerHealthMetrics{
@@ -436,6 +848,26 @@ func (a *Aggregator) computeWorkerHealthMetrics() *WorkerHealthMetrics {
 		}
 	}
 
+	if len(a.generatorWarnings) > 0 {
+		metrics.Warnings = make([]GeneratorWarning, len(a.generatorWarnings))
+		copy(metrics.Warnings, a.generatorWarnings)
+		sort.SliceStable(metrics.Warnings, func(i, j int) bool {
+			return metrics.Warnings[i].TimestampMs < metrics.Warnings[j].TimestampMs
+		})
+		for _, w := range metrics.Warnings {
+			metrics.PeakShedRate = max(metrics.PeakShedRate, w.ShedRate)
+		}
+	}
+	if metrics.PeakShedRate > 0 {
+		metrics.SaturationDetected = true
+		reason := fmt.Sprintf("workers shed up to %.0f%% of load under resource pressure", metrics.PeakShedRate*100)
+		if metrics.SaturationReason != "" {
+			metrics.SaturationReason += "; " + reason
+		} else {
+			metrics.SaturationReason = reason
+		}
+	}
+
 	return metrics
 }
 
@@ -465,7 +897,7 @@ func computePercentile(latencies []int, p float64) int {
 func (a *Aggregator) OperationCount() int {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
-	return len(a.operations)
+	return a.total.success + a.total.failure
 }
 
 // Reset clears all collected operations.
@@ -473,11 +905,37 @@ func (a *Aggregator) OperationCount() int {
 func (a *Aggregator) Reset() {
 	a.mu.Lock()
 	defer a.mu.Unlock()
-	a.operations = make([]OperationResult, 0)
+	a.total = operationStats{}
+	a.byOperation = make(map[string]*operationStats)
+	a.byTool = make(map[string]*operationStats)
+	a.byGroup = make(map[string]*operationStats)
+	a.byTarget = make(map[string]*operationStats)
+	a.byWorker = make(map[string]*operationStats)
+	a.sessions = make(map[string]struct{})
+	a.connections = connectionStats{}
+	a.requestIDs = requestIDStats{}
+	a.checks = checkStats{}
+	a.fuzz = fuzzStats{}
+	a.resumption = resumptionStats{}
+	a.serverMessages = serverMessageStats{}
+	a.cancellation = cancellationStats{}
+	a.throttle = throttleStats{}
+	a.pacing = pacingStats{}
+	a.workflows = workflowStats{}
+	a.retries = retryStats{}
+	a.shadow = shadowStats{}
+	a.chaos = chaosStats{}
+	a.signatures = errorSignatureStats{}
+	a.warmup = 0
+	a.streaming = make(map[string]*streamingStats)
 	a.healthSamples = make([]WorkerHealthSample, 0)
 	a.workersSeen = make(map[string]struct{})
 	a.churnSamples = make([]ChurnSample, 0)
+	a.generatorWarnings = nil
+	a.failuresBySecond = make(map[int64]int)
+	a.loadBuckets = make(map[int64]*loadStats)
 	a.startTime = 0
 	a.endTime = 0
 	a.maxVUsConfig = 0
+	a.latencyBuckets = nil
 }
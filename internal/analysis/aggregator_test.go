@@ -1,6 +1,7 @@
 package analysis
 
 import (
+	"reflect"
 	"sync"
 	"testing"
 )
@@ -298,6 +299,47 @@ func TestReset(t *testing.T) {
 	}
 }
 
+func TestLoadTimeline(t *testing.T) {
+	agg := NewAggregator()
+	agg.AddOperation(OperationResult{Operation: "ping", LatencyMs: 10, OK: true, TimestampMs: 1_000})
+	agg.AddOperation(OperationResult{Operation: "ping", LatencyMs: 30, OK: false, ErrorType: "stream_stall", TimestampMs: 9_999})
+	agg.AddOperation(OperationResult{Operation: "ping", LatencyMs: 50, OK: true, TimestampMs: 25_000})
+	agg.AddOperation(OperationResult{Operation: "ping", LatencyMs: 70, OK: true})
+
+	other := NewAggregator()
+	other.AddOperation(OperationResult{Operation: "tools/call", LatencyMs: 90, OK: true, TimestampMs: 21_000, Stream: &StreamSample{Stalled: true}})
+	agg.Merge(other)
+
+	got := agg.LoadTimeline()
+	want := []LoadPoint{
+		{TimestampMs: 0, Ops: 2, Failures: 1, Stalls: 1, LatencyP95Ms: 30},
+		{TimestampMs: 20_000, Ops: 2, Stalls: 1, LatencyP95Ms: 90},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadTimeline() = %+v, want %+v", got, want)
+	}
+
+	agg.Reset()
+	if got := agg.LoadTimeline(); len(got) != 0 {
+		t.Errorf("expected an empty timeline after reset, got %+v", got)
+	}
+}
+
+func TestTailOpsByOperation(t *testing.T) {
+	agg := NewAggregator()
+	for i := 0; i < 196; i++ {
+		agg.AddOperation(OperationResult{Operation: "ping", LatencyMs: 10, OK: true})
+	}
+	for _, latency := range []int{500, 600, 700, 800} {
+		agg.AddOperation(OperationResult{Operation: "initialize", LatencyMs: latency, OK: true})
+	}
+
+	metrics := agg.Compute()
+	if got := metrics.TailOpsByOperation; len(got) != 1 || got["initialize"] != 2 {
+		t.Errorf("expected the 2 initialize operations at or above p99 %d, got %v", metrics.LatencyP99, got)
+	}
+}
+
 func TestLatencyPercentiles(t *testing.T) {
 	agg := NewAggregator()
 
@@ -684,6 +726,29 @@ func TestWorkerHealthMetricsBothSaturation(t *testing.T) {
 	}
 }
 
+func TestWorkerHealthMetricsGeneratorWarnings(t *testing.T) {
+	agg := NewAggregator()
+
+	agg.AddGeneratorWarning(GeneratorWarning{WorkerID: "worker-1", TimestampMs: 2000, Kind: "throttle_ended"})
+	agg.AddGeneratorWarning(GeneratorWarning{WorkerID: "worker-1", TimestampMs: 1000, Kind: "throttle", Resource: "cpu", Usage: 95, Limit: 90, ShedRate: 0.2})
+
+	metrics := agg.Compute()
+
+	if metrics.WorkerHealth == nil {
+		t.Fatal("expected worker health metrics from warnings alone")
+	}
+	health := metrics.WorkerHealth
+	if health.PeakShedRate != 0.2 || health.WorkerCount != 1 {
+		t.Errorf("expected peak shed rate 0.2 on 1 worker, got %v on %d", health.PeakShedRate, health.WorkerCount)
+	}
+	if len(health.Warnings) != 2 || health.Warnings[0].Kind != "throttle" {
+		t.Errorf("expected warnings in time order, got %+v", health.Warnings)
+	}
+	if !health.SaturationDetected || health.SaturationReason != "workers shed up to 20% of load under resource pressure" {
+		t.Errorf("unexpected saturation: %v %q", health.SaturationDetected, health.SaturationReason)
+	}
+}
+
 func TestWorkerHealthMetricsReset(t *testing.T) {
 	agg := NewAggregator()
 	agg.SetMaxVUsConfig(10)
@@ -739,3 +804,186 @@ func TestWorkerHealthMetricsConcurrent(t *testing.T) {
 		t.Errorf("expected 5 unique workers, got %d", metrics.WorkerHealth.WorkerCount)
 	}
 }
+
+func TestAggregatorByGroup(t *testing.T) {
+	agg := NewAggregator()
+	agg.AddOperation(OperationResult{Operation: "tools/call", LatencyMs: 10, OK: true, Group: "steady"})
+	agg.AddOperation(OperationResult{Operation: "tools/call", LatencyMs: 20, OK: true, Group: "steady"})
+	agg.AddOperation(OperationResult{Operation: "tools/call", LatencyMs: 300, OK: false, ErrorType: "timeout", Group: "bursty"})
+
+	metrics := agg.Compute()
+	if len(metrics.ByGroup) != 2 {
+		t.Fatalf("expected 2 groups, got %d", len(metrics.ByGroup))
+	}
+	steady := metrics.ByGroup["steady"]
+	if steady.TotalOps != 2 || steady.SuccessOps != 2 || steady.ErrorRate != 0 {
+		t.Errorf("unexpected steady metrics: %+v", steady)
+	}
+	bursty := metrics.ByGroup["bursty"]
+	if bursty.TotalOps != 1 || bursty.FailureOps != 1 || bursty.LatencyP50 != 300 {
+		t.Errorf("unexpected bursty metrics: %+v", bursty)
+	}
+
+	ungrouped := NewAggregator()
+	ungrouped.AddOperation(OperationResult{Operation: "ping", LatencyMs: 5, OK: true})
+	if metrics := ungrouped.Compute(); metrics.ByGroup != nil {
+		t.Errorf("expected no group breakdown without groups, got %+v", metrics.ByGroup)
+	}
+}
+
+func TestAggregatorByTarget(t *testing.T) {
+	agg := NewAggregator()
+	agg.AddOperation(OperationResult{Operation: "tools/call", LatencyMs: 10, OK: true, Target: "east"})
+	agg.AddOperation(OperationResult{Operation: "tools/call", LatencyMs: 400, OK: false, ErrorType: "timeout", Target: "west"})
+	agg.AddOperation(OperationResult{Operation: "tools/call", LatencyMs: 30, OK: true, Target: "west"})
+
+	other := NewAggregator()
+	other.AddOperation(OperationResult{Operation: "tools/call", LatencyMs: 20, OK: true, Target: "east"})
+	agg.Merge(other)
+
+	metrics := agg.Compute()
+	if east := metrics.ByTarget["east"]; east == nil || east.TotalOps != 2 || east.ErrorRate != 0 {
+		t.Errorf("unexpected east metrics: %+v", east)
+	}
+	if west := metrics.ByTarget["west"]; west == nil || west.TotalOps != 2 || west.FailureOps != 1 {
+		t.Errorf("unexpected west metrics: %+v", west)
+	}
+
+	agg.Reset()
+	agg.AddOperation(OperationResult{Operation: "ping", LatencyMs: 5, OK: true})
+	if metrics := agg.Compute(); metrics.ByTarget != nil {
+		t.Errorf("expected no target breakdown for a single-target run, got %+v", metrics.ByTarget)
+	}
+}
+
+func TestAggregatorByWorkerLabel(t *testing.T) {
+	agg := NewAggregator()
+	agg.AddOperation(OperationResult{Operation: "tools/call", LatencyMs: 10, OK: true, WorkerID: "wkr_eu1"})
+	agg.AddOperation(OperationResult{Operation: "tools/call", LatencyMs: 20, OK: true, WorkerID: "wkr_eu2"})
+	agg.AddOperation(OperationResult{Operation: "tools/call", LatencyMs: 200, OK: false, WorkerID: "wkr_us1"})
+	agg.AddOperation(OperationResult{Operation: "tools/call", LatencyMs: 30, OK: true, WorkerID: "wkr_unlabelled"})
+	if metrics := agg.Compute(); metrics.ByWorkerLabel != nil {
+		t.Fatalf("expected no worker label breakdown without labels, got %+v", metrics.ByWorkerLabel)
+	}
+
+	agg.SetWorkerLabels(map[string]map[string]string{
+		"wkr_eu1": {"region": "eu", "zone": "eu-a"},
+		"wkr_eu2": {"region": "eu", "zone": "eu-b"},
+		"wkr_us1": {"region": "us", "zone": "us-a"},
+	})
+	metrics := agg.Compute()
+	if eu := metrics.ByWorkerLabel["region"]["eu"]; eu == nil || eu.TotalOps != 2 || eu.FailureOps != 0 {
+		t.Errorf("unexpected region=eu metrics: %+v", eu)
+	}
+	if us := metrics.ByWorkerLabel["region"]["us"]; us == nil || us.TotalOps != 1 || us.LatencyP50 != 200 {
+		t.Errorf("unexpected region=us metrics: %+v", us)
+	}
+	if len(metrics.ByWorkerLabel["zone"]) != 3 {
+		t.Errorf("expected 3 zones, got %+v", metrics.ByWorkerLabel["zone"])
+	}
+}
+
+func TestAggregatorWarmup(t *testing.T) {
+	agg := NewAggregator()
+	agg.AddOperation(OperationResult{Operation: "tools/call", ToolName: "echo", LatencyMs: 900, OK: false, ErrorType: "timeout", Warmup: true})
+	agg.AddOperation(OperationResult{Operation: "tools/call", ToolName: "echo", LatencyMs: 10, OK: true})
+	agg.AddOperation(OperationResult{Operation: "tools/call", ToolName: "echo", LatencyMs: 20, OK: true})
+
+	other := NewAggregator()
+	other.AddOperation(OperationResult{Operation: "ping", LatencyMs: 500, OK: true, Warmup: true})
+	agg.Merge(other)
+
+	metrics := agg.Compute()
+	if metrics.WarmupOps != 2 {
+		t.Errorf("expected 2 warmup ops, got %d", metrics.WarmupOps)
+	}
+	if metrics.TotalOps != 2 || metrics.FailureOps != 0 || metrics.LatencyP99 != 20 {
+		t.Errorf("expected warmup ops left out of the totals, got %+v", metrics)
+	}
+	if metrics.ByTool["echo"].TotalOps != 2 || metrics.ByOperation["ping"] != nil {
+		t.Errorf("expected warmup ops left out of the breakdowns, got tools %+v operations %+v", metrics.ByTool, metrics.ByOperation)
+	}
+
+	agg.Reset()
+	if metrics := agg.Compute(); metrics.WarmupOps != 0 {
+		t.Errorf("expected no warmup ops after reset, got %d", metrics.WarmupOps)
+	}
+}
+
+func TestAggregatorMerge(t *testing.T) {
+	ops := []OperationResult{
+		{Operation: "tools/call", ToolName: "echo", LatencyMs: 10, OK: true, SessionID: "s1", Group: "readers"},
+		{Operation: "tools/call", ToolName: "echo", LatencyMs: 30, OK: false, ErrorType: "timeout", SessionID: "s2"},
+		{Operation: "tools/list", LatencyMs: 5, OK: true, SessionID: "s1",
+			Connection: &ConnectionSample{DNSLookup: true, DNSLookupUs: 400},
+			RequestID:  &RequestIDSample{Kind: "integer"},
+			Checks:     []CheckSample{{Name: "non_empty", Passed: false}}},
+		{Operation: "tools/call", ToolName: "add", LatencyMs: 20, OK: true, SessionID: "s3",
+			RequestID: &RequestIDSample{Kind: "string", Duplicate: true},
+			Checks:    []CheckSample{{Name: "non_empty", Passed: true}}},
+	}
+
+	whole := NewAggregator()
+	first, second := NewAggregator(), NewAggregator()
+	for i, op := range ops {
+		whole.AddOperation(op)
+		if i < 2 {
+			first.AddOperation(op)
+		} else {
+			second.AddOperation(op)
+		}
+	}
+	merged := NewAggregator()
+	merged.Merge(first)
+	merged.Merge(second)
+
+	want, got := whole.Compute(), merged.Compute()
+	if got.TotalOps != 4 || got.FailureOps != 1 || got.LatencyP50 != want.LatencyP50 || got.LatencyP99 != want.LatencyP99 {
+		t.Errorf("merged totals differ: got %+v, want %+v", got, want)
+	}
+	if got.ByTool["echo"].TotalOps != 2 || got.ByTool["add"].TotalOps != 1 || got.ByGroup["readers"].TotalOps != 1 {
+		t.Errorf("merged breakdowns differ: tools %+v groups %+v", got.ByTool, got.ByGroup)
+	}
+	if got.SessionMetrics.TotalSessions != 3 {
+		t.Errorf("expected 3 sessions, got %d", got.SessionMetrics.TotalSessions)
+	}
+	if got.Connections.DNSLookups != 1 || got.RequestIDs.Duplicates.Requests != 1 || got.RequestIDs.ByKind["integer"].Requests != 1 {
+		t.Errorf("merged samples differ: connections %+v request ids %+v", got.Connections, got.RequestIDs)
+	}
+	if got.Checks.CheckedOps != 2 || got.Checks.FailedOps != 1 || len(got.Checks.Checks) != 2 {
+		t.Errorf("merged checks differ: %+v", got.Checks)
+	}
+	if merged.OperationCount() != 4 {
+		t.Errorf("expected 4 operations, got %d", merged.OperationCount())
+	}
+}
+
+func TestErrorSignatures(t *testing.T) {
+	a, b := NewAggregator(), NewAggregator()
+	a.AddOperation(OperationResult{Operation: "tools_call", ToolName: "search", ErrorType: "tool_error", ErrorMessage: "quota 5 exceeded", OpID: "op_1", TimestampMs: 2000})
+	a.AddOperation(OperationResult{Operation: "ping", OK: true, TimestampMs: 2500})
+	b.AddOperation(OperationResult{Operation: "tools/call", ToolName: "search", ErrorType: "tool_error", ErrorMessage: "quota 6 exceeded", OpID: "op_2", TimestampMs: 1000})
+	b.AddOperation(OperationResult{Operation: "ping", ErrorType: "timeout", TimestampMs: 3000})
+	b.AddOperation(OperationResult{Operation: "ping", ErrorType: "timeout", Warmup: true})
+	a.Merge(b)
+
+	sigs := a.Compute().ErrorSignatures
+	if len(sigs) != 2 {
+		t.Fatalf("expected 2 signatures, got %+v", sigs)
+	}
+	quota := sigs[0]
+	if quota.Pattern != "quota <NUM> exceeded" || quota.Count != 2 || quota.FirstSeenMs != 1000 || quota.LastSeenMs != 2000 {
+		t.Errorf("unexpected signature %+v", quota)
+	}
+	if !reflect.DeepEqual(quota.AffectedOperations, []string{"tools/call"}) || !reflect.DeepEqual(quota.ExampleOpIDs, []string{"op_1", "op_2"}) {
+		t.Errorf("unexpected operations %v or examples %v", quota.AffectedOperations, quota.ExampleOpIDs)
+	}
+	if sigs[1].Pattern != "timeout" || sigs[1].Count != 1 {
+		t.Errorf("expected the one measured timeout, got %+v", sigs[1])
+	}
+
+	a.Reset()
+	if sigs := a.Compute().ErrorSignatures; sigs != nil {
+		t.Errorf("expected no signatures after reset, got %+v", sigs)
+	}
+}
@@ -0,0 +1,59 @@
+package analysis
+
+// CancellationSample describes a tools/call picked for cancellation in
+// flight.
+type CancellationSample struct {
+	Sent    bool // notifications/cancelled went out before the response
+	Honored bool // the server stopped without responding
+}
+
+// CancellationReportMetrics reports how the server handled cancelled calls.
+// Calls whose response arrived before their cancellation was due count as
+// completed first and are left out of the honor rate.
+type CancellationReportMetrics struct {
+	Picked          int     `json:"picked"`
+	Sent            int     `json:"sent"`
+	Honored         int     `json:"honored"`
+	CompletedAnyway int     `json:"completed_anyway"`
+	CompletedFirst  int     `json:"completed_first"`
+	HonorRate       float64 `json:"honor_rate"`
+}
+
+// cancellationStats accumulates cancellation samples.
+type cancellationStats struct {
+	result CancellationReportMetrics
+}
+
+func (s *cancellationStats) add(sample *CancellationSample) {
+	s.result.Picked++
+	switch {
+	case !sample.Sent:
+		s.result.CompletedFirst++
+	case sample.Honored:
+		s.result.Sent++
+		s.result.Honored++
+	default:
+		s.result.Sent++
+		s.result.CompletedAnyway++
+	}
+}
+
+func (s *cancellationStats) merge(o *cancellationStats) {
+	s.result.Picked += o.result.Picked
+	s.result.Sent += o.result.Sent
+	s.result.Honored += o.result.Honored
+	s.result.CompletedAnyway += o.result.CompletedAnyway
+	s.result.CompletedFirst += o.result.CompletedFirst
+}
+
+// metrics summarizes the cancelled calls. Returns nil if none were picked.
+func (s *cancellationStats) metrics() *CancellationReportMetrics {
+	if s.result.Picked == 0 {
+		return nil
+	}
+	result := s.result
+	if result.Sent > 0 {
+		result.HonorRate = float64(result.Honored) / float64(result.Sent)
+	}
+	return &result
+}
@@ -0,0 +1,34 @@
+package analysis
+
+import "testing"
+
+func TestCancellationMetrics(t *testing.T) {
+	agg := NewAggregator()
+	agg.AddOperation(OperationResult{Operation: "tools/call", LatencyMs: 10, OK: true})
+	if metrics := agg.Compute(); metrics.Cancellation != nil {
+		t.Fatalf("expected nil cancellation metrics without cancelled calls, got %+v", metrics.Cancellation)
+	}
+
+	for _, sample := range []*CancellationSample{
+		{Sent: true, Honored: true},
+		{Sent: true, Honored: true},
+		{Sent: true},
+		{},
+	} {
+		agg.AddOperation(OperationResult{Operation: "tools/call", LatencyMs: 10, OK: true, Cancellation: sample})
+	}
+	other := NewAggregator()
+	other.AddOperation(OperationResult{Operation: "tools/call", LatencyMs: 10, OK: true, Cancellation: &CancellationSample{Sent: true, Honored: true}})
+	agg.Merge(other)
+
+	c := agg.Compute().Cancellation
+	if c == nil {
+		t.Fatal("expected cancellation metrics")
+	}
+	if c.Picked != 5 || c.Sent != 4 || c.Honored != 3 || c.CompletedAnyway != 1 || c.CompletedFirst != 1 {
+		t.Errorf("unexpected cancellation counts: %+v", c)
+	}
+	if c.HonorRate != 0.75 {
+		t.Errorf("expected honor rate 0.75, got %f", c.HonorRate)
+	}
+}
@@ -0,0 +1,52 @@
+package analysis
+
+import "math"
+
+// CapacityProbe is one load level held by an adaptive ramp and judged
+// against its guardrails.
+type CapacityProbe struct {
+	VUs        int     `json:"vus"`
+	DurationMs int64   `json:"duration_ms"` // span the probe was judged over
+	Operations int     `json:"operations"`
+	RPS        float64 `json:"rps"`
+	Passed     bool    `json:"passed"`
+
+	// Guardrail is the guardrail that tripped and Observed its value; both
+	// are empty if the probe passed.
+	Guardrail string  `json:"guardrail,omitempty"`
+	Observed  float64 `json:"observed,omitempty"`
+}
+
+// CapacityReport is the outcome of an adaptive ramp: the most load the
+// target sustained without tripping a guardrail.
+type CapacityReport struct {
+	MaxSustainableVUs int     `json:"max_sustainable_vus"`
+	MaxSustainableRPS float64 `json:"max_sustainable_rps"`
+
+	// VUsLowerBound is the highest level that held and VUsUpperBound the
+	// lowest that tripped a guardrail. VUsUpperBound is 0 if no level
+	// tripped, so capacity lies beyond the ramp's max_vus.
+	VUsLowerBound int `json:"vus_lower_bound"`
+	VUsUpperBound int `json:"vus_upper_bound"`
+
+	// RPSLow and RPSHigh bound the throughput at MaxSustainableVUs with 95%
+	// confidence.
+	RPSLow  float64 `json:"rps_low"`
+	RPSHigh float64 `json:"rps_high"`
+
+	// Converged is set if the search narrowed the bounds to the ramp's
+	// resolution before the stage ended.
+	Converged bool            `json:"converged"`
+	Probes    []CapacityProbe `json:"probes"`
+}
+
+// ThroughputInterval returns the rate of ops operations over durationMs and
+// its 95% confidence bounds, treating arrivals as a Poisson process.
+func ThroughputInterval(ops int, durationMs int64) (rps, low, high float64) {
+	if durationMs <= 0 {
+		return 0, 0, 0
+	}
+	seconds := float64(durationMs) / 1000
+	margin := 1.96 * math.Sqrt(float64(ops))
+	return float64(ops) / seconds, math.Max(float64(ops)-margin, 0) / seconds, (float64(ops) + margin) / seconds
+}
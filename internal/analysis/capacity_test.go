@@ -0,0 +1,24 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+)
+
+func TestThroughputInterval(t *testing.T) {
+	rps, low, high := ThroughputInterval(400, 10000)
+	if rps != 40 {
+		t.Fatalf("expected 40 RPS, got %v", rps)
+	}
+	// 400 ± 1.96·20 operations over 10 s.
+	if math.Abs(low-36.08) > 1e-9 || math.Abs(high-43.92) > 1e-9 {
+		t.Errorf("expected bounds of 36.08 and 43.92, got %v and %v", low, high)
+	}
+
+	if _, low, _ = ThroughputInterval(1, 1000); low != 0 {
+		t.Errorf("expected the lower bound to stop at 0, got %v", low)
+	}
+	if rps, low, high = ThroughputInterval(10, 0); rps != 0 || low != 0 || high != 0 {
+		t.Errorf("expected zeros without a duration, got %v [%v, %v]", rps, low, high)
+	}
+}
@@ -0,0 +1,80 @@
+package analysis
+
+// ChaosSample is the network faults injected into an operation.
+type ChaosSample struct {
+	DelayMs     int64 // latency added before the request
+	Reset       bool  // the connection was reset in place of the response
+	ThrottledMs int64 // time spent waiting on the bandwidth cap
+}
+
+// ChaosReportMetrics reports the network faults injected into the run, so
+// its latency and errors can be read against them. The injected latency is
+// part of every latency in the report; ErrorRateExcludingResets leaves out
+// the failures that were the injected resets themselves.
+type ChaosReportMetrics struct {
+	Operations               int     `json:"operations"`
+	Delayed                  int     `json:"delayed"`
+	AvgDelayMs               float64 `json:"avg_delay_ms"`
+	Resets                   int     `json:"resets"`
+	Throttled                int     `json:"throttled"`
+	AvgThrottledMs           float64 `json:"avg_throttled_ms"`
+	ErrorRateExcludingResets float64 `json:"error_rate_excluding_resets"`
+}
+
+// chaosStats accumulates the operations faults were injected into.
+type chaosStats struct {
+	operations  int
+	delayed     int
+	delayMs     int64
+	resets      int
+	throttled   int
+	throttledMs int64
+}
+
+func (s *chaosStats) add(sample *ChaosSample) {
+	s.operations++
+	if sample.DelayMs > 0 {
+		s.delayed++
+		s.delayMs += sample.DelayMs
+	}
+	if sample.Reset {
+		s.resets++
+	}
+	if sample.ThrottledMs > 0 {
+		s.throttled++
+		s.throttledMs += sample.ThrottledMs
+	}
+}
+
+func (s *chaosStats) merge(o *chaosStats) {
+	s.operations += o.operations
+	s.delayed += o.delayed
+	s.delayMs += o.delayMs
+	s.resets += o.resets
+	s.throttled += o.throttled
+	s.throttledMs += o.throttledMs
+}
+
+// metrics summarizes the injected faults given the run's operation and
+// failure counts. Returns nil if no fault was injected.
+func (s *chaosStats) metrics(totalOps, failures int) *ChaosReportMetrics {
+	if s.operations == 0 {
+		return nil
+	}
+	result := &ChaosReportMetrics{
+		Operations: s.operations,
+		Delayed:    s.delayed,
+		Resets:     s.resets,
+		Throttled:  s.throttled,
+	}
+	if s.delayed > 0 {
+		result.AvgDelayMs = float64(s.delayMs) / float64(s.delayed)
+	}
+	if s.throttled > 0 {
+		result.AvgThrottledMs = float64(s.throttledMs) / float64(s.throttled)
+	}
+	if rest := totalOps - s.resets; rest > 0 {
+		result.ErrorRateExcludingResets = float64(max(failures-s.resets, 0)) / float64(rest)
+	}
+	return result
+}
@@ -0,0 +1,36 @@
+package analysis
+
+import "testing"
+
+func TestChaosMetrics(t *testing.T) {
+	agg := NewAggregator()
+	agg.AddOperation(OperationResult{Operation: "tools/call", LatencyMs: 10, OK: true})
+	if metrics := agg.Compute(); metrics.Chaos != nil {
+		t.Fatalf("expected nil chaos metrics without faults, got %+v", metrics.Chaos)
+	}
+
+	for _, op := range []OperationResult{
+		{OK: true, Chaos: &ChaosSample{DelayMs: 100}},
+		{OK: true, Chaos: &ChaosSample{DelayMs: 300, ThrottledMs: 40}},
+		{OK: false, ErrorType: "connect_error", Chaos: &ChaosSample{DelayMs: 200, Reset: true}},
+		{OK: false, ErrorType: "timeout"},
+	} {
+		op.Operation = "tools/call"
+		agg.AddOperation(op)
+	}
+	other := NewAggregator()
+	other.AddOperation(OperationResult{Operation: "tools/call", Chaos: &ChaosSample{Reset: true}})
+	agg.Merge(other)
+
+	c := agg.Compute().Chaos
+	if c == nil {
+		t.Fatal("expected chaos metrics")
+	}
+	if c.Operations != 4 || c.Delayed != 3 || c.AvgDelayMs != 200 || c.Resets != 2 || c.Throttled != 1 || c.AvgThrottledMs != 40 {
+		t.Errorf("unexpected chaos counts: %+v", c)
+	}
+	// 6 operations with 3 failures, 2 of them injected resets.
+	if c.ErrorRateExcludingResets != 0.25 {
+		t.Errorf("expected 1 failure in 4 operations without resets, got %v", c.ErrorRateExcludingResets)
+	}
+}
@@ -0,0 +1,112 @@
+package analysis
+
+import "sort"
+
+// CheckSample is the result of one response check on a single operation.
+type CheckSample struct {
+	Name   string
+	Passed bool
+}
+
+// CheckMetrics summarizes one response check of one operation and tool.
+type CheckMetrics struct {
+	Name      string  `json:"name"`
+	Operation string  `json:"operation"`
+	ToolName  string  `json:"tool_name,omitempty"`
+	Total     int     `json:"total"`
+	Passed    int     `json:"passed"`
+	Failed    int     `json:"failed"`
+	PassRate  float64 `json:"pass_rate"`
+}
+
+// CheckReportMetrics reports response check pass rates. Check failures are
+// counted apart from operation failures: an operation can succeed at the
+// transport level and still fail its checks.
+type CheckReportMetrics struct {
+	Checks []*CheckMetrics `json:"checks"`
+
+	// CheckedOps is the number of operations that ran at least one check.
+	CheckedOps int `json:"checked_ops"`
+
+	// FailedOps is the number of operations with at least one failed check.
+	FailedOps int `json:"failed_ops"`
+}
+
+type checkKey struct{ operation, tool, name string }
+
+// checkStats accumulates response check results.
+type checkStats struct {
+	byKey      map[checkKey]*CheckMetrics
+	checkedOps int
+	failedOps  int
+}
+
+func (s *checkStats) check(key checkKey) *CheckMetrics {
+	if s.byKey == nil {
+		s.byKey = make(map[checkKey]*CheckMetrics)
+	}
+	m := s.byKey[key]
+	if m == nil {
+		m = &CheckMetrics{Name: key.name, Operation: key.operation, ToolName: key.tool}
+		s.byKey[key] = m
+	}
+	return m
+}
+
+func (s *checkStats) add(opName string, op OperationResult) {
+	s.checkedOps++
+	failed := false
+	for _, c := range op.Checks {
+		m := s.check(checkKey{opName, op.ToolName, c.Name})
+		m.Total++
+		if c.Passed {
+			m.Passed++
+		} else {
+			m.Failed++
+			failed = true
+		}
+	}
+	if failed {
+		s.failedOps++
+	}
+}
+
+func (s *checkStats) merge(o *checkStats) {
+	s.checkedOps += o.checkedOps
+	s.failedOps += o.failedOps
+	for key, m := range o.byKey {
+		dst := s.check(key)
+		dst.Total += m.Total
+		dst.Passed += m.Passed
+		dst.Failed += m.Failed
+	}
+}
+
+// metrics summarizes the check results. Returns nil if no operation ran a
+// check.
+func (s *checkStats) metrics() *CheckReportMetrics {
+	if s.checkedOps == 0 {
+		return nil
+	}
+	result := &CheckReportMetrics{
+		Checks:     make([]*CheckMetrics, 0, len(s.byKey)),
+		CheckedOps: s.checkedOps,
+		FailedOps:  s.failedOps,
+	}
+	for _, m := range s.byKey {
+		copied := *m
+		copied.PassRate = float64(m.Passed) / float64(m.Total)
+		result.Checks = append(result.Checks, &copied)
+	}
+	sort.Slice(result.Checks, func(i, j int) bool {
+		a, b := result.Checks[i], result.Checks[j]
+		if a.Operation != b.Operation {
+			return a.Operation < b.Operation
+		}
+		if a.ToolName != b.ToolName {
+			return a.ToolName < b.ToolName
+		}
+		return a.Name < b.Name
+	})
+	return result
+}
@@ -0,0 +1,51 @@
+package analysis
+
+import "testing"
+
+func TestComputeCheckMetrics(t *testing.T) {
+	agg := NewAggregator()
+	for i := 0; i < 4; i++ {
+		agg.AddOperation(OperationResult{
+			Operation: "tools/call", ToolName: "search", LatencyMs: 5, OK: true,
+			Checks: []CheckSample{{Name: "has_hits", Passed: i > 0}, {Name: "fast", Passed: true}},
+		})
+	}
+	agg.AddOperation(OperationResult{Operation: "tools_call", ToolName: "fetch", LatencyMs: 5, OK: false,
+		Checks: []CheckSample{{Name: "fast", Passed: false}}})
+	agg.AddOperation(OperationResult{Operation: "ping", LatencyMs: 5, OK: true})
+
+	metrics := agg.Compute()
+	checks := metrics.Checks
+	if checks == nil {
+		t.Fatal("expected check metrics")
+	}
+	if checks.CheckedOps != 5 || checks.FailedOps != 2 {
+		t.Errorf("checked/failed ops = %d/%d, want 5/2", checks.CheckedOps, checks.FailedOps)
+	}
+	if metrics.FailureOps != 1 {
+		t.Errorf("check failures must not count as operation failures, got %d", metrics.FailureOps)
+	}
+
+	want := []CheckMetrics{
+		{Name: "fast", Operation: "tools/call", ToolName: "fetch", Total: 1, Failed: 1, PassRate: 0},
+		{Name: "fast", Operation: "tools/call", ToolName: "search", Total: 4, Passed: 4, PassRate: 1},
+		{Name: "has_hits", Operation: "tools/call", ToolName: "search", Total: 4, Passed: 3, Failed: 1, PassRate: 0.75},
+	}
+	if len(checks.Checks) != len(want) {
+		t.Fatalf("got %d checks, want %d", len(checks.Checks), len(want))
+	}
+	for i, w := range want {
+		if *checks.Checks[i] != w {
+			t.Errorf("check %d = %+v, want %+v", i, *checks.Checks[i], w)
+		}
+	}
+}
+
+func TestComputeCheckMetrics_NoChecks(t *testing.T) {
+	agg := NewAggregator()
+	agg.AddOperation(OperationResult{Operation: "ping", LatencyMs: 5, OK: true})
+
+	if metrics := agg.Compute(); metrics.Checks != nil {
+		t.Errorf("expected no check metrics, got %+v", metrics.Checks)
+	}
+}
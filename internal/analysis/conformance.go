@@ -0,0 +1,51 @@
+package analysis
+
+// Statuses of a conformance check.
+const (
+	ConformancePass = "pass"
+	ConformanceFail = "fail"
+	// ConformanceSkip is a check that does not apply to the target, such as
+	// session checks against a server without sessions.
+	ConformanceSkip = "skip"
+)
+
+// ConformanceReport is the outcome of a conformance run: protocol checks
+// against the target in place of load.
+type ConformanceReport struct {
+	Target string `json:"target"`
+	// ProtocolVersion is the version the target negotiated.
+	ProtocolVersion string             `json:"protocol_version,omitempty"`
+	Passed          int                `json:"passed"`
+	Failed          int                `json:"failed"`
+	Skipped         int                `json:"skipped"`
+	Checks          []ConformanceCheck `json:"checks"`
+}
+
+// ConformanceCheck is the result of one protocol check.
+type ConformanceCheck struct {
+	ID          string `json:"id"`
+	Category    string `json:"category"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+	// Detail says what was wrong, or why the check was skipped.
+	Detail    string `json:"detail,omitempty"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
+// Add records a check and counts it by status.
+func (r *ConformanceReport) Add(check ConformanceCheck) {
+	switch check.Status {
+	case ConformancePass:
+		r.Passed++
+	case ConformanceFail:
+		r.Failed++
+	case ConformanceSkip:
+		r.Skipped++
+	}
+	r.Checks = append(r.Checks, check)
+}
+
+// OK reports whether no check failed.
+func (r *ConformanceReport) OK() bool {
+	return r.Failed == 0
+}
@@ -0,0 +1,99 @@
+package analysis
+
+import "math"
+
+// DNSCacheHitThresholdUs is the lookup time below which a DNS lookup is
+// counted as answered from a cache. Resolver caches on the load generator
+// host (nscd, systemd-resolved, dnsmasq) answer in well under a millisecond,
+// while a round trip to a network resolver rarely does.
+const DNSCacheHitThresholdUs = 1000
+
+// ConnectionSample describes how a single operation obtained its connection.
+type ConnectionSample struct {
+	Reused       bool  // existing connection was reused, so no DNS lookup or dial
+	DNSLookup    bool  // a DNS lookup was performed for a new connection
+	DNSLookupUs  int64 // DNS lookup time in microseconds
+	DNSCoalesced bool  // lookup was shared with a concurrent lookup for the same host
+	DNSFailed    bool  // lookup returned an error
+}
+
+// ConnectionReportMetrics summarizes connection reuse and DNS resolution
+// across a run. DNS metrics only cover requests that opened a new connection
+// to a hostname; reused connections and IP literal targets need no lookup.
+type ConnectionReportMetrics struct {
+	TracedRequests      int     `json:"traced_requests"`
+	ReusedConnections   int     `json:"reused_connections"`
+	NewConnections      int     `json:"new_connections"`
+	ConnectionReuseRate float64 `json:"connection_reuse_rate"`
+	DNSLookups          int     `json:"dns_lookups"`
+	DNSCacheHits        int     `json:"dns_cache_hits"`
+	DNSCacheHitRatio    float64 `json:"dns_cache_hit_ratio"`
+	DNSFailures         int     `json:"dns_failures"`
+	DNSLookupAvgMs      float64 `json:"dns_lookup_avg_ms"`
+	DNSLookupP50Ms      float64 `json:"dns_lookup_p50_ms"`
+	DNSLookupP95Ms      float64 `json:"dns_lookup_p95_ms"`
+	DNSLookupP99Ms      float64 `json:"dns_lookup_p99_ms"`
+	DNSLookupMaxMs      float64 `json:"dns_lookup_max_ms"`
+}
+
+// connectionStats accumulates connection samples. Resolved DNS lookup
+// times are kept in microseconds.
+type connectionStats struct {
+	result  ConnectionReportMetrics
+	lookups Histogram
+}
+
+func (s *connectionStats) add(conn *ConnectionSample) {
+	s.result.TracedRequests++
+	if conn.Reused {
+		s.result.ReusedConnections++
+		return
+	}
+	s.result.NewConnections++
+	if !conn.DNSLookup {
+		return
+	}
+	s.result.DNSLookups++
+	if conn.DNSFailed {
+		s.result.DNSFailures++
+		return
+	}
+	if conn.DNSCoalesced || conn.DNSLookupUs < DNSCacheHitThresholdUs {
+		s.result.DNSCacheHits++
+	}
+	s.lookups.Record(conn.DNSLookupUs)
+}
+
+func (s *connectionStats) merge(o *connectionStats) {
+	s.result.TracedRequests += o.result.TracedRequests
+	s.result.ReusedConnections += o.result.ReusedConnections
+	s.result.NewConnections += o.result.NewConnections
+	s.result.DNSLookups += o.result.DNSLookups
+	s.result.DNSFailures += o.result.DNSFailures
+	s.result.DNSCacheHits += o.result.DNSCacheHits
+	s.lookups.Merge(&o.lookups)
+}
+
+// metrics summarizes the connection samples. Returns nil if no operation
+// carried connection data.
+func (s *connectionStats) metrics() *ConnectionReportMetrics {
+	if s.result.TracedRequests == 0 {
+		return nil
+	}
+	result := s.result
+	result.ConnectionReuseRate = float64(result.ReusedConnections) / float64(result.TracedRequests)
+	if resolved := result.DNSLookups - result.DNSFailures; resolved > 0 {
+		result.DNSCacheHitRatio = float64(result.DNSCacheHits) / float64(resolved)
+		result.DNSLookupAvgMs = usToMs(float64(s.lookups.Sum()) / float64(resolved))
+		result.DNSLookupP50Ms = usToMs(float64(s.lookups.Percentile(50)))
+		result.DNSLookupP95Ms = usToMs(float64(s.lookups.Percentile(95)))
+		result.DNSLookupP99Ms = usToMs(float64(s.lookups.Percentile(99)))
+		result.DNSLookupMaxMs = usToMs(float64(s.lookups.Max()))
+	}
+	return &result
+}
+
+// usToMs converts microseconds to milliseconds rounded to 3 decimal places.
+func usToMs(us float64) float64 {
+	return math.Round(us) / 1000.0
+}
@@ -0,0 +1,56 @@
+package analysis
+
+import "testing"
+
+func TestConnectionMetricsNoSamples(t *testing.T) {
+	agg := NewAggregator()
+	agg.AddOperation(OperationResult{Operation: "ping", LatencyMs: 10, OK: true})
+
+	if metrics := agg.Compute(); metrics.Connections != nil {
+		t.Errorf("expected nil connection metrics without connection data, got %+v", metrics.Connections)
+	}
+}
+
+func TestConnectionMetricsDNS(t *testing.T) {
+	agg := NewAggregator()
+	samples := []*ConnectionSample{
+		{Reused: true},
+		{Reused: true},
+		{Reused: true},
+		{DNSLookup: true, DNSLookupUs: 200},
+		{DNSLookup: true, DNSLookupUs: 12000, DNSCoalesced: true},
+		{DNSLookup: true, DNSLookupUs: 30000},
+		{DNSLookup: true, DNSFailed: true},
+		{},
+	}
+	for _, sample := range samples {
+		agg.AddOperation(OperationResult{Operation: "ping", LatencyMs: 10, OK: true, Connection: sample})
+	}
+	agg.AddOperation(OperationResult{Operation: "ping", LatencyMs: 10, OK: true})
+
+	conn := agg.Compute().Connections
+	if conn == nil {
+		t.Fatal("expected connection metrics")
+	}
+	if conn.TracedRequests != 8 || conn.ReusedConnections != 3 || conn.NewConnections != 5 {
+		t.Errorf("unexpected connection counts: %+v", conn)
+	}
+	if conn.ConnectionReuseRate != 3.0/8.0 {
+		t.Errorf("expected reuse rate 0.375, got %f", conn.ConnectionReuseRate)
+	}
+	if conn.DNSLookups != 4 || conn.DNSFailures != 1 {
+		t.Errorf("expected 4 lookups with 1 failure, got %d and %d", conn.DNSLookups, conn.DNSFailures)
+	}
+	if conn.DNSCacheHits != 2 {
+		t.Errorf("expected 2 cache hits (fast and coalesced), got %d", conn.DNSCacheHits)
+	}
+	if conn.DNSCacheHitRatio != 2.0/3.0 {
+		t.Errorf("expected cache hit ratio 2/3, got %f", conn.DNSCacheHitRatio)
+	}
+	if conn.DNSLookupMaxMs != 30 || conn.DNSLookupP50Ms != 12 {
+		t.Errorf("expected p50 12ms and max 30ms, got %f and %f", conn.DNSLookupP50Ms, conn.DNSLookupMaxMs)
+	}
+	if conn.DNSLookupAvgMs != 14.067 {
+		t.Errorf("expected avg 14.067ms, got %f", conn.DNSLookupAvgMs)
+	}
+}
@@ -3,6 +3,7 @@ package analysis
 import (
 	"regexp"
 	"sort"
+	"strconv"
 )
 
 // ErrorSignature represents a normalized error pattern with metadata.
@@ -14,6 +15,16 @@ type ErrorSignature struct {
 	AffectedOperations []string `json:"affected_operations"`
 	AffectedTools      []string `json:"affected_tools"`
 	SampleError        string   `json:"sample_error"`
+
+	// ErrorType, ErrorCode and JSONRPCCode classify the errors. Errors with
+	// the same pattern but another classification get a signature of their
+	// own.
+	ErrorType   string `json:"error_type,omitempty"`
+	ErrorCode   string `json:"error_code,omitempty"`
+	JSONRPCCode *int   `json:"jsonrpc_code,omitempty"`
+	// ExampleOpIDs are the IDs of a few operations that failed this way,
+	// for looking them up in the operation logs.
+	ExampleOpIDs []string `json:"example_op_ids,omitempty"`
 }
 
 // ErrorLog represents an error log entry for signature extraction.
@@ -22,8 +33,26 @@ type ErrorLog struct {
 	Operation   string
 	ToolName    string
 	ErrorType   string
+
+	ErrorCode   string
+	JSONRPCCode *int
+	// Message is the error text, or the text a tool returned with its
+	// error. The pattern is taken from it when set, from ErrorType when not.
+	Message string
+	OpID    string
 }
 
+const (
+	// maxErrorSignatures caps the signatures tracked at once. Errors that
+	// would start another are counted under otherErrorsPattern, so messages
+	// that escape normalization cannot grow the set without bound.
+	maxErrorSignatures = 1000
+	otherErrorsPattern = "(other errors)"
+	// maxExampleOps is the number of example operation IDs kept per
+	// signature.
+	maxExampleOps = 3
+)
+
 // Regex patterns for error normalization.
 // Order matters: more specific patterns should come before more general ones.
 var (
@@ -50,101 +79,190 @@ func NormalizeError(msg string) string {
 	return msg
 }
 
+// signatureKey identifies a signature: its pattern and classification.
+type signatureKey struct {
+	pattern     string
+	errorType   string
+	errorCode   string
+	jsonrpcCode string
+}
+
 // signatureData holds intermediate data during signature extraction.
 type signatureData struct {
+	jsonrpcCode *int
 	count       int
 	firstSeenMs int64
 	lastSeenMs  int64
 	operations  map[string]struct{}
 	tools       map[string]struct{}
 	sampleError string
+	exampleOps  []string
 }
 
-// ExtractSignatures extracts and ranks error signatures from a list of error logs.
-// Returns the top N signatures sorted by count descending.
-func ExtractSignatures(errors []ErrorLog, topN int) []ErrorSignature {
-	if len(errors) == 0 {
-		return []ErrorSignature{}
+func (sig *signatureData) seen(tsMs int64) {
+	if sig.count == 0 || tsMs < sig.firstSeenMs {
+		sig.firstSeenMs = tsMs
 	}
+	if sig.count == 0 || tsMs > sig.lastSeenMs {
+		sig.lastSeenMs = tsMs
+	}
+}
 
-	// Group errors by normalized pattern
-	signatures := make(map[string]*signatureData)
-
-	for _, err := range errors {
-		if err.ErrorType == "" {
-			continue
-		}
+// errorSignatureStats groups errors into signatures as they come in.
+type errorSignatureStats struct {
+	byKey map[signatureKey]*signatureData
+}
 
-		pattern := NormalizeError(err.ErrorType)
-
-		sig, ok := signatures[pattern]
-		if !ok {
-			sig = &signatureData{
-				count:       0,
-				firstSeenMs: err.TimestampMs,
-				lastSeenMs:  err.TimestampMs,
-				operations:  make(map[string]struct{}),
-				tools:       make(map[string]struct{}),
-				sampleError: err.ErrorType,
+// data returns the signature for key, starting it if there is room.
+func (s *errorSignatureStats) data(key signatureKey) *signatureData {
+	if s.byKey == nil {
+		s.byKey = make(map[signatureKey]*signatureData)
+	}
+	sig := s.byKey[key]
+	if sig == nil {
+		if len(s.byKey) >= maxErrorSignatures {
+			key = signatureKey{pattern: otherErrorsPattern}
+			if sig = s.byKey[key]; sig != nil {
+				return sig
 			}
-			signatures[pattern] = sig
 		}
+		sig = &signatureData{
+			operations: make(map[string]struct{}),
+			tools:      make(map[string]struct{}),
+		}
+		s.byKey[key] = sig
+	}
+	return sig
+}
 
-		sig.count++
+func (s *errorSignatureStats) add(e ErrorLog) {
+	s.addN(e, 1)
+}
 
-		if err.TimestampMs < sig.firstSeenMs {
-			sig.firstSeenMs = err.TimestampMs
-		}
-		if err.TimestampMs > sig.lastSeenMs {
-			sig.lastSeenMs = err.TimestampMs
-		}
+// addN adds n errors alike to e.
+func (s *errorSignatureStats) addN(e ErrorLog, n int) {
+	if n <= 0 || e.ErrorType == "" && e.Message == "" {
+		return
+	}
+	key := signatureKey{
+		errorType: NormalizeError(e.ErrorType),
+		errorCode: e.ErrorCode,
+	}
+	if e.JSONRPCCode != nil {
+		key.jsonrpcCode = strconv.Itoa(*e.JSONRPCCode)
+	}
+	sample := e.Message
+	if sample == "" {
+		sample = e.ErrorType
+	}
+	key.pattern = NormalizeError(sample)
 
-		if err.Operation != "" {
-			sig.operations[err.Operation] = struct{}{}
-		}
-		if err.ToolName != "" {
-			sig.tools[err.ToolName] = struct{}{}
+	sig := s.data(key)
+	if sig.count == 0 {
+		sig.sampleError = sample
+		if e.JSONRPCCode != nil {
+			code := *e.JSONRPCCode
+			sig.jsonrpcCode = &code
 		}
 	}
+	sig.seen(e.TimestampMs)
+	sig.count += n
+	if e.Operation != "" {
+		sig.operations[e.Operation] = struct{}{}
+	}
+	if e.ToolName != "" {
+		sig.tools[e.ToolName] = struct{}{}
+	}
+	if e.OpID != "" && len(sig.exampleOps) < maxExampleOps {
+		sig.exampleOps = append(sig.exampleOps, e.OpID)
+	}
+}
 
-	// Convert to slice for sorting
-	result := make([]ErrorSignature, 0, len(signatures))
-	for pattern, sig := range signatures {
-		operations := make([]string, 0, len(sig.operations))
-		for op := range sig.operations {
-			operations = append(operations, op)
+func (s *errorSignatureStats) merge(o *errorSignatureStats) {
+	for key, src := range o.byKey {
+		sig := s.data(key)
+		if sig.count == 0 {
+			sig.sampleError = src.sampleError
+			sig.jsonrpcCode = src.jsonrpcCode
 		}
-		sort.Strings(operations)
-
-		tools := make([]string, 0, len(sig.tools))
-		for tool := range sig.tools {
-			tools = append(tools, tool)
+		sig.seen(src.firstSeenMs)
+		sig.seen(src.lastSeenMs)
+		sig.count += src.count
+		for op := range src.operations {
+			sig.operations[op] = struct{}{}
+		}
+		for tool := range src.tools {
+			sig.tools[tool] = struct{}{}
 		}
-		sort.Strings(tools)
+		for _, id := range src.exampleOps {
+			if len(sig.exampleOps) < maxExampleOps {
+				sig.exampleOps = append(sig.exampleOps, id)
+			}
+		}
+	}
+}
 
+// top returns the topN most frequent signatures, or all of them if topN is
+// not positive, sorted by count descending.
+func (s *errorSignatureStats) top(topN int) []ErrorSignature {
+	result := make([]ErrorSignature, 0, len(s.byKey))
+	for key, sig := range s.byKey {
 		result = append(result, ErrorSignature{
-			Pattern:            pattern,
+			Pattern:            key.pattern,
 			Count:              sig.count,
 			FirstSeenMs:        sig.firstSeenMs,
 			LastSeenMs:         sig.lastSeenMs,
-			AffectedOperations: operations,
-			AffectedTools:      tools,
+			AffectedOperations: sortedKeys(sig.operations),
+			AffectedTools:      sortedKeys(sig.tools),
 			SampleError:        sig.sampleError,
+			ErrorType:          key.errorType,
+			ErrorCode:          key.errorCode,
+			JSONRPCCode:        sig.jsonrpcCode,
+			ExampleOpIDs:       append([]string(nil), sig.exampleOps...),
 		})
 	}
 
-	// Sort by count descending, then by pattern for deterministic ordering
+	// Sort by count descending, then by pattern and classification for
+	// deterministic ordering
 	sort.Slice(result, func(i, j int) bool {
-		if result[i].Count != result[j].Count {
-			return result[i].Count > result[j].Count
+		a, b := result[i], result[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Pattern != b.Pattern {
+			return a.Pattern < b.Pattern
+		}
+		if a.ErrorType != b.ErrorType {
+			return a.ErrorType < b.ErrorType
 		}
-		return result[i].Pattern < result[j].Pattern
+		if a.ErrorCode != b.ErrorCode {
+			return a.ErrorCode < b.ErrorCode
+		}
+		return a.JSONRPCCode != nil && (b.JSONRPCCode == nil || *a.JSONRPCCode < *b.JSONRPCCode)
 	})
 
 	// Return top N
 	if topN > 0 && len(result) > topN {
 		result = result[:topN]
 	}
-
 	return result
 }
+
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ExtractSignatures extracts and ranks error signatures from a list of error logs.
+// Returns the top N signatures sorted by count descending.
+func ExtractSignatures(errors []ErrorLog, topN int) []ErrorSignature {
+	var stats errorSignatureStats
+	for _, err := range errors {
+		stats.add(err)
+	}
+	return stats.top(topN)
+}
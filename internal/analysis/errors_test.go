@@ -1,6 +1,8 @@
 package analysis
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -387,3 +389,58 @@ func TestExtractSignatures_NoToolOrOperation(t *testing.T) {
 		t.Errorf("AffectedTools = %v, want empty", sig.AffectedTools)
 	}
 }
+
+func TestExtractSignatures_MessagesAndCodes(t *testing.T) {
+	invalidParams := -32602
+	errors := []ErrorLog{
+		{TimestampMs: 3000, Operation: "tools/call", ToolName: "search", ErrorType: "tool_error", ErrorCode: "TOOL_ERROR", Message: "index shard 3 unavailable", OpID: "op_1"},
+		{TimestampMs: 1000, Operation: "tools/call", ToolName: "fetch", ErrorType: "tool_error", ErrorCode: "TOOL_ERROR", Message: "index shard 12 unavailable", OpID: "op_2"},
+		{TimestampMs: 2000, Operation: "tools/call", ToolName: "search", ErrorType: "tool_error", ErrorCode: "TOOL_ERROR", Message: "index shard 7 unavailable", OpID: "op_3"},
+		{TimestampMs: 4000, Operation: "tools/call", ToolName: "search", ErrorType: "tool_error", ErrorCode: "TOOL_ERROR", Message: "index shard 9 unavailable", OpID: "op_4"},
+		{TimestampMs: 5000, Operation: "tools/call", ToolName: "search", ErrorType: "protocol_error", ErrorCode: "INVALID_PARAMS", JSONRPCCode: &invalidParams, Message: "index shard 1 unavailable", OpID: "op_5"},
+	}
+
+	result := ExtractSignatures(errors, 10)
+	if len(result) != 2 {
+		t.Fatalf("expected the JSON-RPC error apart from the tool errors, got %+v", result)
+	}
+
+	sig := result[0]
+	if sig.Pattern != "index shard <NUM> unavailable" || sig.Count != 4 {
+		t.Errorf("unexpected top signature %q (count %d)", sig.Pattern, sig.Count)
+	}
+	if sig.ErrorType != "tool_error" || sig.ErrorCode != "TOOL_ERROR" || sig.JSONRPCCode != nil {
+		t.Errorf("unexpected classification %q/%q/%v", sig.ErrorType, sig.ErrorCode, sig.JSONRPCCode)
+	}
+	if sig.FirstSeenMs != 1000 || sig.LastSeenMs != 4000 {
+		t.Errorf("expected seen from 1000 to 4000, got %d to %d", sig.FirstSeenMs, sig.LastSeenMs)
+	}
+	if !reflect.DeepEqual(sig.AffectedTools, []string{"fetch", "search"}) {
+		t.Errorf("unexpected tools %v", sig.AffectedTools)
+	}
+	if !reflect.DeepEqual(sig.ExampleOpIDs, []string{"op_1", "op_2", "op_3"}) {
+		t.Errorf("expected the first %d operations as examples, got %v", maxExampleOps, sig.ExampleOpIDs)
+	}
+	if sig.SampleError != "index shard 3 unavailable" {
+		t.Errorf("SampleError = %q, want the first message", sig.SampleError)
+	}
+
+	if code := result[1].JSONRPCCode; code == nil || *code != invalidParams {
+		t.Errorf("expected JSON-RPC code %d, got %v", invalidParams, code)
+	}
+}
+
+func TestExtractSignatures_Cap(t *testing.T) {
+	errors := make([]ErrorLog, 0, maxErrorSignatures+5)
+	for i := 0; i < maxErrorSignatures+5; i++ {
+		errors = append(errors, ErrorLog{ErrorType: "x", Message: "failed at step " + string(rune('a'+i%26)) + strings.Repeat("b", i/26)})
+	}
+
+	result := ExtractSignatures(errors, 0)
+	if len(result) != maxErrorSignatures+1 {
+		t.Fatalf("expected %d signatures and one for the rest, got %d", maxErrorSignatures, len(result))
+	}
+	if result[0].Pattern != otherErrorsPattern || result[0].Count != 5 {
+		t.Errorf("expected 5 errors under %q, got %q (count %d)", otherErrorsPattern, result[0].Pattern, result[0].Count)
+	}
+}
@@ -0,0 +1,171 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// fuzzMutations is the report order of fuzz mutations.
+var fuzzMutations = []string{"wrong_type", "boundary_number", "oversized_string", "missing_required", "deep_nesting"}
+
+// fuzzRejectionErrors are the error types of a server that turned malformed
+// arguments down as it should: with a JSON-RPC, MCP or tool error.
+var fuzzRejectionErrors = map[string]bool{
+	"jsonrpc_error": true,
+	"mcp_error":     true,
+	"tool_error":    true,
+}
+
+// fuzzInvalidMutations are the mutations that always make arguments invalid,
+// so a server accepting them is worth a finding. Boundary numbers, long
+// strings and deep nesting may be valid for a tool.
+var fuzzInvalidMutations = map[string]bool{
+	"wrong_type":       true,
+	"missing_required": true,
+}
+
+// FuzzMutationMetrics summarizes the tools/call operations sent with one
+// kind of mutated arguments.
+type FuzzMutationMetrics struct {
+	Requests int `json:"requests"`
+	// Accepted calls succeeded despite the mutation.
+	Accepted int `json:"accepted"`
+	// Rejected calls failed with a JSON-RPC, MCP or tool error.
+	Rejected int `json:"rejected"`
+	// Unhandled calls failed any other way, such as an HTTP error or a
+	// timeout.
+	Unhandled  int            `json:"unhandled"`
+	ErrorTypes map[string]int `json:"error_types,omitempty"`
+}
+
+// FuzzReportMetrics reports how the server handled fuzzed arguments, by
+// mutation.
+type FuzzReportMetrics struct {
+	ByMutation map[string]*FuzzMutationMetrics `json:"by_mutation"`
+	Findings   []string                        `json:"findings,omitempty"`
+}
+
+// fuzzStats accumulates fuzzed operations by mutation.
+type fuzzStats struct {
+	byMutation map[string]*FuzzMutationMetrics
+}
+
+func (s *fuzzStats) add(op OperationResult) {
+	m := s.mutation(op.FuzzMutation)
+	m.Requests++
+	switch {
+	case op.OK:
+		m.Accepted++
+	case fuzzRejectionErrors[op.ErrorType]:
+		m.Rejected++
+	default:
+		m.Unhandled++
+	}
+	if !op.OK && op.ErrorType != "" {
+		if m.ErrorTypes == nil {
+			m.ErrorTypes = make(map[string]int)
+		}
+		m.ErrorTypes[op.ErrorType]++
+	}
+}
+
+func (s *fuzzStats) mutation(name string) *FuzzMutationMetrics {
+	if s.byMutation == nil {
+		s.byMutation = make(map[string]*FuzzMutationMetrics)
+	}
+	m := s.byMutation[name]
+	if m == nil {
+		m = &FuzzMutationMetrics{}
+		s.byMutation[name] = m
+	}
+	return m
+}
+
+func (s *fuzzStats) merge(o *fuzzStats) {
+	for name, src := range o.byMutation {
+		dst := s.mutation(name)
+		dst.Requests += src.Requests
+		dst.Accepted += src.Accepted
+		dst.Rejected += src.Rejected
+		dst.Unhandled += src.Unhandled
+		for errorType, n := range src.ErrorTypes {
+			if dst.ErrorTypes == nil {
+				dst.ErrorTypes = make(map[string]int)
+			}
+			dst.ErrorTypes[errorType] += n
+		}
+	}
+}
+
+// metrics summarizes the fuzzed operations. Returns nil if none were fuzzed.
+func (s *fuzzStats) metrics() *FuzzReportMetrics {
+	if s.byMutation == nil {
+		return nil
+	}
+	result := &FuzzReportMetrics{ByMutation: make(map[string]*FuzzMutationMetrics, len(s.byMutation))}
+	for name, m := range s.byMutation {
+		copied := *m
+		if m.ErrorTypes != nil {
+			copied.ErrorTypes = make(map[string]int, len(m.ErrorTypes))
+			for errorType, n := range m.ErrorTypes {
+				copied.ErrorTypes[errorType] = n
+			}
+		}
+		result.ByMutation[name] = &copied
+	}
+	result.Findings = fuzzFindings(result)
+	return result
+}
+
+// fuzzMutationOrder returns the mutations in metrics in report order, with
+// unknown ones last by name.
+func fuzzMutationOrder(metrics *FuzzReportMetrics) []string {
+	order := make([]string, 0, len(metrics.ByMutation))
+	known := make(map[string]bool, len(fuzzMutations))
+	for _, name := range fuzzMutations {
+		known[name] = true
+		if metrics.ByMutation[name] != nil {
+			order = append(order, name)
+		}
+	}
+	var unknown []string
+	for name := range metrics.ByMutation {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	return append(order, unknown...)
+}
+
+// fuzzFindings describes malformed inputs the server mishandled: invalid
+// arguments it accepted, and mutations that made calls fail other than with
+// a JSON-RPC, MCP or tool error.
+func fuzzFindings(metrics *FuzzReportMetrics) []string {
+	var findings []string
+	for _, name := range fuzzMutationOrder(metrics) {
+		m := metrics.ByMutation[name]
+		if fuzzInvalidMutations[name] && m.Accepted > 0 {
+			findings = append(findings, fmt.Sprintf(
+				"server accepted %d of %d %s calls", m.Accepted, m.Requests, name))
+		}
+		if m.Unhandled > 0 {
+			var errorTypes []string
+			for errorType := range m.ErrorTypes {
+				if !fuzzRejectionErrors[errorType] {
+					errorTypes = append(errorTypes, errorType)
+				}
+			}
+			sort.Strings(errorTypes)
+			how := "an unclassified error"
+			if len(errorTypes) > 0 {
+				how = strings.Join(errorTypes, ", ")
+			}
+			findings = append(findings, fmt.Sprintf(
+				"%d of %d %s calls failed with %s instead of a JSON-RPC or tool error",
+				m.Unhandled, m.Requests, name, how))
+		}
+	}
+	return findings
+}
@@ -0,0 +1,50 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestComputeFuzzMetrics(t *testing.T) {
+	agg := NewAggregator()
+	for i := 0; i < 10; i++ {
+		agg.AddOperation(OperationResult{Operation: "tools/call", ToolName: "search", LatencyMs: 5, OK: true})
+		agg.AddOperation(OperationResult{Operation: "tools/call", ToolName: "search", LatencyMs: 5, OK: i < 2, ErrorType: "jsonrpc_error", FuzzMutation: "missing_required"})
+		agg.AddOperation(OperationResult{Operation: "tools/call", ToolName: "search", LatencyMs: 5, OK: true, FuzzMutation: "boundary_number"})
+	}
+	agg.AddOperation(OperationResult{Operation: "tools/call", ToolName: "search", LatencyMs: 5, OK: false, ErrorType: "http_error", FuzzMutation: "deep_nesting"})
+	agg.AddOperation(OperationResult{Operation: "tools/call", ToolName: "search", LatencyMs: 5, OK: false, ErrorType: "tool_error", FuzzMutation: "deep_nesting"})
+
+	metrics := agg.Compute().Fuzz
+	if metrics == nil {
+		t.Fatal("expected fuzz metrics")
+	}
+	if len(metrics.ByMutation) != 3 {
+		t.Fatalf("expected three mutations, got %v", metrics.ByMutation)
+	}
+	if m := metrics.ByMutation["missing_required"]; m.Requests != 10 || m.Accepted != 2 || m.Rejected != 8 || m.ErrorTypes["jsonrpc_error"] != 8 {
+		t.Errorf("unexpected missing_required metrics: %+v", m)
+	}
+	if m := metrics.ByMutation["deep_nesting"]; m.Rejected != 1 || m.Unhandled != 1 {
+		t.Errorf("unexpected deep_nesting metrics: %+v", m)
+	}
+
+	if len(metrics.Findings) != 2 {
+		t.Fatalf("expected 2 findings, got %v", metrics.Findings)
+	}
+	if !strings.HasPrefix(metrics.Findings[0], "server accepted 2 of 10 missing_required calls") {
+		t.Errorf("expected accepted missing_required finding first, got %q", metrics.Findings[0])
+	}
+	if metrics.Findings[1] != "1 of 2 deep_nesting calls failed with http_error instead of a JSON-RPC or tool error" {
+		t.Errorf("unexpected deep_nesting finding %q", metrics.Findings[1])
+	}
+}
+
+func TestComputeFuzzMetrics_NotFuzzed(t *testing.T) {
+	agg := NewAggregator()
+	agg.AddOperation(OperationResult{Operation: "tools/call", ToolName: "search", LatencyMs: 5, OK: true})
+
+	if metrics := agg.Compute(); metrics.Fuzz != nil {
+		t.Errorf("expected no fuzz metrics, got %+v", metrics.Fuzz)
+	}
+}
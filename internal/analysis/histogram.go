@@ -0,0 +1,150 @@
+package analysis
+
+import "math/bits"
+
+// histogramSubBucketBits sets the precision of a Histogram: each power of
+// two range is split into 1<<histogramSubBucketBits linear buckets.
+const histogramSubBucketBits = 10
+
+const histogramSubBuckets = 1 << histogramSubBucketBits
+
+// Histogram is an HDR-style log-linear histogram of non-negative integer
+// values. Values below 2048 are recorded exactly; larger values land in
+// buckets no wider than 1/1024 of their value. Memory grows with the
+// largest value recorded, not with the number of values: latencies up to
+// an hour in milliseconds need about 13,000 buckets.
+//
+// A Histogram is not safe for concurrent use.
+type Histogram struct {
+	counts []int64
+	count  int64
+	sum    int64
+	min    int64
+	max    int64
+}
+
+// histogramIndex returns the bucket of v.
+func histogramIndex(v int64) int {
+	if v < 2*histogramSubBuckets {
+		return int(v)
+	}
+	shift := bits.Len64(uint64(v)) - 1 - histogramSubBucketBits
+	return shift*histogramSubBuckets + int(v>>shift)
+}
+
+// histogramValue returns the lowest value of bucket i.
+func histogramValue(i int) int64 {
+	if i < 2*histogramSubBuckets {
+		return int64(i)
+	}
+	shift := i/histogramSubBuckets - 1
+	return int64(i-shift*histogramSubBuckets) << shift
+}
+
+// Record adds a value. Negative values are recorded as 0.
+func (h *Histogram) Record(v int64) {
+	h.RecordN(v, 1)
+}
+
+// Merge adds all values recorded in o.
+func (h *Histogram) Merge(o *Histogram) {
+	if o == nil || o.count == 0 {
+		return
+	}
+	if len(o.counts) > len(h.counts) {
+		h.counts = append(h.counts, make([]int64, len(o.counts)-len(h.counts))...)
+	}
+	for i, c := range o.counts {
+		h.counts[i] += c
+	}
+	if h.count == 0 || o.min < h.min {
+		h.min = o.min
+	}
+	if o.max > h.max {
+		h.max = o.max
+	}
+	h.count += o.count
+	h.sum += o.sum
+}
+
+// RecordN adds n occurrences of a value. Negative values are recorded as 0.
+func (h *Histogram) RecordN(v, n int64) {
+	if n <= 0 {
+		return
+	}
+	if v < 0 {
+		v = 0
+	}
+	i := histogramIndex(v)
+	if i >= len(h.counts) {
+		h.counts = append(h.counts, make([]int64, i+1-len(h.counts))...)
+	}
+	h.counts[i] += n
+	if h.count == 0 || v < h.min {
+		h.min = v
+	}
+	if v > h.max {
+		h.max = v
+	}
+	h.count += n
+	h.sum += v * n
+}
+
+// Buckets calls fn with the lowest value and count of every non-empty
+// bucket, in increasing order of value.
+func (h *Histogram) Buckets(fn func(value, count int64)) {
+	for i, c := range h.counts {
+		if c > 0 {
+			fn(max(histogramValue(i), h.min), c)
+		}
+	}
+}
+
+// CountAtLeast returns the number of values recorded in v's bucket or
+// above it, so for v from Percentile it counts the values at or above the
+// percentile.
+func (h *Histogram) CountAtLeast(v int64) int64 {
+	var n int64
+	for i := histogramIndex(max(v, 0)); i < len(h.counts); i++ {
+		n += h.counts[i]
+	}
+	return n
+}
+
+// Count returns the number of recorded values.
+func (h *Histogram) Count() int64 { return h.count }
+
+// Sum returns the sum of recorded values.
+func (h *Histogram) Sum() int64 { return h.sum }
+
+// Min returns the smallest recorded value, or 0 if none were recorded.
+func (h *Histogram) Min() int64 { return h.min }
+
+// Max returns the largest recorded value, or 0 if none were recorded.
+func (h *Histogram) Max() int64 { return h.max }
+
+// Percentile returns the value at percentile p (0-100), picked the same way
+// as from a sorted slice of all values: the value at index p/100*count. It
+// returns the lowest value of the bucket that holds it, so results above
+// 2047 may be up to 0.1% low.
+func (h *Histogram) Percentile(p float64) int64 {
+	if h.count == 0 {
+		return 0
+	}
+	rank := int64((p / 100.0) * float64(h.count))
+	if rank >= h.count {
+		rank = h.count - 1
+	}
+	if rank < 0 {
+		rank = 0
+	}
+
+	var seen int64
+	for i, c := range h.counts {
+		seen += c
+		if seen > rank {
+			return max(histogramValue(i), h.min)
+		}
+	}
+	return h.max
+}
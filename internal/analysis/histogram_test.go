@@ -0,0 +1,130 @@
+package analysis
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestHistogramMatchesExactPercentiles(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	values := make([]int, 5000)
+	var h Histogram
+	for i := range values {
+		values[i] = rng.Intn(2000)
+		h.Record(int64(values[i]))
+	}
+
+	for _, p := range []float64{0, 25, 50, 90, 95, 99, 99.9, 100} {
+		if got, want := h.Percentile(p), int64(computePercentile(values, p)); got != want {
+			t.Errorf("p%v: expected %d, got %d", p, want, got)
+		}
+	}
+	if h.Count() != 5000 {
+		t.Errorf("expected count 5000, got %d", h.Count())
+	}
+}
+
+func TestHistogramLargeValuesWithinPrecision(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	values := make([]int, 5000)
+	var h Histogram
+	for i := range values {
+		values[i] = 2048 + rng.Intn(10_000_000)
+		h.Record(int64(values[i]))
+	}
+
+	for _, p := range []float64{50, 95, 99} {
+		got, want := h.Percentile(p), int64(computePercentile(values, p))
+		if got > want || float64(want-got) > float64(want)/histogramSubBuckets {
+			t.Errorf("p%v: expected %d within 0.1%%, got %d", p, want, got)
+		}
+	}
+	if h.Max() != int64(computePercentile(values, 100)) {
+		t.Errorf("expected exact max, got %d", h.Max())
+	}
+}
+
+func TestHistogramBucketBoundaries(t *testing.T) {
+	for _, v := range []int64{0, 1, 2047, 2048, 2049, 4095, 4096, 1 << 40, 1<<62 + 12345} {
+		i := histogramIndex(v)
+		low := histogramValue(i)
+		if low > v {
+			t.Errorf("value %d: bucket %d starts above it at %d", v, i, low)
+		}
+		if next := histogramValue(i + 1); next <= v {
+			t.Errorf("value %d: bucket %d ends at %d, before it", v, i, next)
+		}
+	}
+}
+
+func TestHistogramMerge(t *testing.T) {
+	var a, b, all Histogram
+	for v := int64(0); v < 3000; v++ {
+		all.Record(v * 7)
+		if v%2 == 0 {
+			a.Record(v * 7)
+		} else {
+			b.Record(v * 7)
+		}
+	}
+	a.Merge(&b)
+
+	if a.Count() != all.Count() || a.Sum() != all.Sum() || a.Min() != all.Min() || a.Max() != all.Max() {
+		t.Errorf("merged stats differ: count %d/%d sum %d/%d", a.Count(), all.Count(), a.Sum(), all.Sum())
+	}
+	for _, p := range []float64{1, 50, 99} {
+		if a.Percentile(p) != all.Percentile(p) {
+			t.Errorf("p%v: expected %d, got %d", p, all.Percentile(p), a.Percentile(p))
+		}
+	}
+}
+
+func TestHistogramEmptyAndNegative(t *testing.T) {
+	var h Histogram
+	if h.Percentile(50) != 0 || h.Max() != 0 {
+		t.Error("expected zero percentiles for an empty histogram")
+	}
+	h.Record(-5)
+	if h.Min() != 0 || h.Percentile(50) != 0 {
+		t.Errorf("expected negative values recorded as 0, got min %d", h.Min())
+	}
+}
+
+func TestHistogramCountAtLeast(t *testing.T) {
+	var h Histogram
+	for v := int64(1); v <= 200; v++ {
+		h.Record(v * 100)
+	}
+	if got := h.CountAtLeast(h.Percentile(99)); got != 2 {
+		t.Errorf("expected the 2 values at or above p99, got %d", got)
+	}
+	if got := h.CountAtLeast(-1); got != 200 {
+		t.Errorf("expected every value at or above -1, got %d", got)
+	}
+	if got := h.CountAtLeast(30_000); got != 0 {
+		t.Errorf("expected none above the max, got %d", got)
+	}
+}
+
+func TestHistogramBucketsRoundTrip(t *testing.T) {
+	var h Histogram
+	for v := int64(0); v < 5000; v++ {
+		h.Record(v * 13)
+	}
+
+	var rebuilt Histogram
+	var total int64
+	h.Buckets(func(value, count int64) {
+		rebuilt.RecordN(value, count)
+		total += count
+	})
+
+	if total != h.Count() || rebuilt.Count() != h.Count() {
+		t.Fatalf("expected %d values, got %d", h.Count(), total)
+	}
+	for _, p := range []float64{1, 50, 95, 99, 99.9} {
+		if rebuilt.Percentile(p) != h.Percentile(p) {
+			t.Errorf("p%v: expected %d, got %d", p, h.Percentile(p), rebuilt.Percentile(p))
+		}
+	}
+}
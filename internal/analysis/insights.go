@@ -0,0 +1,338 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Insight severities, most severe first.
+const (
+	InsightCritical = "critical"
+	InsightWarning  = "warning"
+	InsightInfo     = "info"
+)
+
+// Insight is a finding about a run, stated for a reader, with the metrics
+// of the report it rests on.
+type Insight struct {
+	Kind     string `json:"kind"`
+	Severity string `json:"severity"`
+	// Score ranks insights of the same severity, from 0 to 1.
+	Score    float64    `json:"score"`
+	Title    string     `json:"title"`
+	Detail   string     `json:"detail"`
+	Evidence []Evidence `json:"evidence"`
+}
+
+// Evidence is a metric an insight rests on, by its path in report.json,
+// e.g. metrics.by_tool["search"].error_rate.
+type Evidence struct {
+	Metric string  `json:"metric"`
+	Value  float64 `json:"value"`
+}
+
+const (
+	// insightMinFailures is how many failures a run needs before their
+	// breakdown is judged.
+	insightMinFailures = 10
+	// errorDrivenShare is the share of failures one tool or operation must
+	// account for to drive the error rate.
+	errorDrivenShare = 0.5
+
+	// insightMinOps is how many operations a run needs before its tail
+	// latency is judged; with fewer, the p99 is a handful of operations.
+	insightMinOps = 100
+	// tailDominatedShare is the share of the operations at or above p99
+	// one operation must make up to dominate it, and tailOverrepresentation
+	// how much larger that share must be than its share of all operations.
+	tailDominatedShare     = 0.5
+	tailOverrepresentation = 2
+
+	// kneeMinLoadSpread is how much the busiest bucket's load must exceed
+	// the quietest's for a latency knee to be looked for; a steady load
+	// has no knee, only noise.
+	kneeMinLoadSpread = 1.5
+
+	// stallCPUPercent is the node CPU above which stalled streams are
+	// counted as coinciding with a busy server, and insightMinStalls how
+	// many stalls are needed to judge.
+	stallCPUPercent  = 80
+	insightMinStalls = 3
+)
+
+// insightRules each look for one kind of insight in a report.
+var insightRules = []func(*Report) []Insight{
+	errorConcentrationInsights,
+	latencyKneeInsights,
+	stallCPUInsights,
+	tailLatencyInsights,
+	serverBottleneckInsights,
+}
+
+// GenerateInsights inspects a report's metrics and returns what stands out,
+// most severe first and then by score.
+func GenerateInsights(report *Report) []Insight {
+	if report == nil {
+		return nil
+	}
+	var insights []Insight
+	for _, rule := range insightRules {
+		insights = append(insights, rule(report)...)
+	}
+	rank := map[string]int{InsightCritical: 0, InsightWarning: 1, InsightInfo: 2}
+	sort.SliceStable(insights, func(i, j int) bool {
+		a, b := insights[i], insights[j]
+		if rank[a.Severity] != rank[b.Severity] {
+			return rank[a.Severity] < rank[b.Severity]
+		}
+		return a.Score > b.Score
+	})
+	return insights
+}
+
+// metricPath returns the path of a breakdown entry's field in report.json.
+func metricPath(breakdown, key, field string) string {
+	return fmt.Sprintf("%s[%q].%s", breakdown, key, field)
+}
+
+// errorConcentrationInsights reports a tool, or else an operation, that
+// accounts for most of the failures.
+func errorConcentrationInsights(r *Report) []Insight {
+	m := r.Metrics
+	if m == nil || m.FailureOps < insightMinFailures {
+		return nil
+	}
+	// Tools only break down tools/call, so fall back to operations when
+	// most failures are elsewhere.
+	breakdown, path, noun := m.ByTool, "metrics.by_tool", "tool"
+	toolFailures := 0
+	for _, t := range m.ByTool {
+		toolFailures += t.FailureOps
+	}
+	if toolFailures*2 < m.FailureOps {
+		breakdown, path, noun = m.ByOperation, "metrics.by_operation", "operation"
+	}
+	if len(breakdown) < 2 {
+		return nil
+	}
+
+	var name string
+	var top *OperationMetrics
+	for key, om := range breakdown {
+		if top == nil || om.FailureOps > top.FailureOps || om.FailureOps == top.FailureOps && key < name {
+			name, top = key, om
+		}
+	}
+	share := float64(top.FailureOps) / float64(m.FailureOps)
+	if share < errorDrivenShare {
+		return nil
+	}
+
+	restRate := 0.0
+	if rest := m.TotalOps - top.TotalOps; rest > 0 {
+		restRate = float64(m.FailureOps-top.FailureOps) / float64(rest)
+	}
+	severity := InsightWarning
+	if m.ErrorRate >= 0.05 {
+		severity = InsightCritical
+	}
+	return []Insight{{
+		Kind:     "error_concentration",
+		Severity: severity,
+		Score:    share,
+		Title:    fmt.Sprintf("Error rate driven by %s %s", noun, name),
+		Detail: fmt.Sprintf("%s accounts for %.0f%% of failures (%d of %d). Its error rate is %.1f%%, against %.1f%% for everything else.",
+			name, share*100, top.FailureOps, m.FailureOps, top.ErrorRate*100, restRate*100),
+		Evidence: []Evidence{
+			{Metric: metricPath(path, name, "failure_ops"), Value: float64(top.FailureOps)},
+			{Metric: metricPath(path, name, "error_rate"), Value: top.ErrorRate},
+			{Metric: "metrics.failure_ops", Value: float64(m.FailureOps)},
+		},
+	}}
+}
+
+// latencyKneeInsights reports the load beyond which p95 latency climbs,
+// found by the knee detector over the load timeline.
+func latencyKneeInsights(r *Report) []Insight {
+	secs := float64(LoadBucketMs) / 1000
+	var points []TimeSeriesPoint
+	minRPS, maxRPS := math.Inf(1), 0.0
+	for _, l := range r.Load {
+		if l.Ops == 0 {
+			continue
+		}
+		rps := float64(l.Ops) / secs
+		minRPS, maxRPS = math.Min(minRPS, rps), math.Max(maxRPS, rps)
+		points = append(points, TimeSeriesPoint{LoadLevel: int(math.Round(rps)), Timestamp: l.TimestampMs, LatencyP95: l.LatencyP95Ms})
+	}
+	if len(points) == 0 || maxRPS < kneeMinLoadSpread*minRPS {
+		return nil
+	}
+	result := NewKneeDetector().DetectLatencyKnee(points, false)
+	if !result.Detected {
+		return nil
+	}
+
+	knee := result.KneePoint
+	at := -1
+	for i, l := range r.Load {
+		if l.Ops > 0 && int(math.Round(float64(l.Ops)/secs)) == knee.LoadLevel && float64(l.LatencyP95Ms) == knee.MetricValue {
+			at = i
+			break
+		}
+	}
+	insight := Insight{
+		Kind:     "latency_knee",
+		Severity: InsightWarning,
+		Score:    math.Min(1, (knee.ChangeRatio-1)/4),
+		Title:    fmt.Sprintf("Latency knee at ~%d RPS", knee.LoadLevel),
+		Detail: fmt.Sprintf("From about %d RPS on, p95 latency averages %.1fx what it was at lower load (%.0f ms at the knee).",
+			knee.LoadLevel, knee.ChangeRatio, knee.MetricValue),
+	}
+	if at >= 0 {
+		insight.Evidence = []Evidence{
+			{Metric: fmt.Sprintf("load[%d].ops", at), Value: float64(r.Load[at].Ops)},
+			{Metric: fmt.Sprintf("load[%d].latency_p95_ms", at), Value: float64(r.Load[at].LatencyP95Ms)},
+		}
+	}
+	return []Insight{insight}
+}
+
+// stallCPUInsights reports stalled streams bunching up while a node of the
+// target was busy.
+func stallCPUInsights(r *Report) []Insight {
+	sm := r.ServerMetrics
+	if sm == nil || sm.Cluster == nil {
+		return nil
+	}
+	var hotOps, hotStalls, coldOps, coldStalls int
+	hotNodes := make(map[int]bool)
+	for _, l := range r.Load {
+		hot := false
+		for i, n := range sm.Nodes {
+			if n.peakCPUPercent(l.TimestampMs, l.TimestampMs+LoadBucketMs, sm.Cluster.BucketMs) > stallCPUPercent {
+				hot = true
+				if l.Stalls > 0 {
+					hotNodes[i] = true
+				}
+			}
+		}
+		if hot {
+			hotOps += l.Ops
+			hotStalls += l.Stalls
+		} else {
+			coldOps += l.Ops
+			coldStalls += l.Stalls
+		}
+	}
+	stalls := hotStalls + coldStalls
+	if stalls < insightMinStalls || hotOps == 0 || coldOps == 0 || hotStalls*2 < stalls {
+		return nil
+	}
+	hotRate, coldRate := float64(hotStalls)/float64(hotOps), float64(coldStalls)/float64(coldOps)
+	if hotRate < 2*coldRate {
+		return nil
+	}
+
+	detail := fmt.Sprintf("%d of %d stalled streams came while a node's CPU was above %d%%, when %.2f%% of operations stalled against %.2f%% otherwise.",
+		hotStalls, stalls, stallCPUPercent, hotRate*100, coldRate*100)
+	evidence := []Evidence{{Metric: "load[*].stalls", Value: float64(stalls)}}
+	for i, n := range sm.Nodes {
+		if hotNodes[i] {
+			evidence = append(evidence, Evidence{Metric: fmt.Sprintf("server_metrics.nodes[%d].cpu_max_percent", i), Value: n.CPUMaxPercent})
+		}
+	}
+	return []Insight{{
+		Kind:     "stall_cpu_correlation",
+		Severity: InsightWarning,
+		Score:    float64(hotStalls) / float64(stalls),
+		Title:    fmt.Sprintf("Stream stalls correlate with >%d%% target CPU", stallCPUPercent),
+		Detail:   detail,
+		Evidence: evidence,
+	}}
+}
+
+// peakCPUPercent returns the node's highest saturation over the buckets
+// overlapping [fromMs, toMs).
+func (m NodeMetrics) peakCPUPercent(fromMs, toMs, bucketMs int64) float64 {
+	peak := 0.0
+	for _, p := range m.Series {
+		if p.TimestampMs < toMs && p.TimestampMs+bucketMs > fromMs {
+			peak = math.Max(peak, p.saturationPercent())
+		}
+	}
+	return peak
+}
+
+// tailLatencyInsights reports an operation that makes up most of the
+// operations at or above the run's p99 latency, well beyond its share of
+// all operations.
+func tailLatencyInsights(r *Report) []Insight {
+	m := r.Metrics
+	if m == nil || m.TotalOps < insightMinOps {
+		return nil
+	}
+	tail, top := 0, 0
+	var name string
+	for op, n := range m.TailOpsByOperation {
+		tail += n
+		if n > top || n == top && op < name {
+			name, top = op, n
+		}
+	}
+	om := m.ByOperation[name]
+	if tail == 0 || om == nil {
+		return nil
+	}
+	share := float64(top) / float64(tail)
+	opsShare := float64(om.TotalOps) / float64(m.TotalOps)
+	if share < tailDominatedShare || share < tailOverrepresentation*opsShare {
+		return nil
+	}
+	return []Insight{{
+		Kind:     "tail_latency_dominated",
+		Severity: InsightInfo,
+		Score:    share - opsShare,
+		Title:    fmt.Sprintf("p99 dominated by %s operations", name),
+		Detail: fmt.Sprintf("%s makes up %.0f%% of the operations at or above the p99 of %d ms but %.0f%% of all operations. Its own p99 is %d ms.",
+			name, share*100, m.LatencyP99, opsShare*100, om.LatencyP99),
+		Evidence: []Evidence{
+			{Metric: fmt.Sprintf("metrics.tail_ops_by_operation[%q]", name), Value: float64(top)},
+			{Metric: "metrics.latency_p99", Value: float64(m.LatencyP99)},
+			{Metric: metricPath("metrics.by_operation", name, "latency_p99"), Value: float64(om.LatencyP99)},
+		},
+	}}
+}
+
+// serverBottleneckInsights restates the server-side bottlenecks found by
+// CorrelateLoad.
+func serverBottleneckInsights(r *Report) []Insight {
+	if r.ServerMetrics == nil {
+		return nil
+	}
+	var insights []Insight
+	for i, b := range r.ServerMetrics.Bottlenecks {
+		host := b.Hostname
+		if host == "" {
+			host = b.AgentID
+		}
+		rise := 0.0
+		if b.BaselineP95Ms > 0 {
+			rise = float64(b.PeakP95Ms) / float64(b.BaselineP95Ms)
+		}
+		insights = append(insights, Insight{
+			Kind:     "server_cpu_saturation",
+			Severity: InsightWarning,
+			Score:    math.Min(1, (rise-1)/4),
+			Title:    fmt.Sprintf("CPU saturation on %s slowed clients", host),
+			Detail: fmt.Sprintf("CPU peaked at %.1f%% for %s, while p95 latency reached %d ms against %d ms otherwise.",
+				b.PeakCPUPercent, formatDuration(b.EndMs-b.StartMs), b.PeakP95Ms, b.BaselineP95Ms),
+			Evidence: []Evidence{
+				{Metric: fmt.Sprintf("server_metrics.bottlenecks[%d].peak_cpu_percent", i), Value: b.PeakCPUPercent},
+				{Metric: fmt.Sprintf("server_metrics.bottlenecks[%d].peak_p95_ms", i), Value: float64(b.PeakP95Ms)},
+			},
+		})
+	}
+	return insights
+}
@@ -0,0 +1,136 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+)
+
+// insightsOfKind returns the insights of one kind.
+func insightsOfKind(insights []Insight, kind string) []Insight {
+	var found []Insight
+	for _, in := range insights {
+		if in.Kind == kind {
+			found = append(found, in)
+		}
+	}
+	return found
+}
+
+func TestGenerateInsights_ErrorConcentration(t *testing.T) {
+	agg := NewAggregator()
+	for i := 0; i < 200; i++ {
+		agg.AddOperation(OperationResult{Operation: "tools/call", ToolName: "search", LatencyMs: 10, OK: i%10 != 0})
+		agg.AddOperation(OperationResult{Operation: "tools/call", ToolName: "fetch", LatencyMs: 10, OK: i != 0})
+	}
+	insights := GenerateInsights(&Report{Metrics: agg.Compute()})
+
+	found := insightsOfKind(insights, "error_concentration")
+	if len(found) != 1 {
+		t.Fatalf("expected an error concentration insight, got %+v", insights)
+	}
+	in := found[0]
+	if in.Title != "Error rate driven by tool search" || in.Severity != InsightCritical {
+		t.Errorf("unexpected insight %+v", in)
+	}
+	if !strings.Contains(in.Detail, "95% of failures (20 of 21)") {
+		t.Errorf("unexpected detail %q", in.Detail)
+	}
+	if in.Evidence[0].Metric != `metrics.by_tool["search"].failure_ops` || in.Evidence[0].Value != 20 {
+		t.Errorf("unexpected evidence %+v", in.Evidence)
+	}
+}
+
+func TestGenerateInsights_TailLatency(t *testing.T) {
+	agg := NewAggregator()
+	for i := 0; i < 1000; i++ {
+		agg.AddOperation(OperationResult{Operation: "tools/call", ToolName: "search", LatencyMs: 20 + i%10, OK: true})
+	}
+	for i := 0; i < 30; i++ {
+		agg.AddOperation(OperationResult{Operation: "initialize", LatencyMs: 900, OK: true})
+	}
+	insights := GenerateInsights(&Report{Metrics: agg.Compute()})
+
+	found := insightsOfKind(insights, "tail_latency_dominated")
+	if len(found) != 1 || found[0].Title != "p99 dominated by initialize operations" {
+		t.Fatalf("expected the tail dominated by initialize, got %+v", insights)
+	}
+	if ev := found[0].Evidence[0]; ev.Metric != `metrics.tail_ops_by_operation["initialize"]` || ev.Value != 30 {
+		t.Errorf("unexpected evidence %+v", found[0].Evidence)
+	}
+}
+
+func TestGenerateInsights_LatencyKnee(t *testing.T) {
+	var load []LoadPoint
+	for i, p95 := range []int{50, 52, 51, 55, 60, 240, 400, 520} {
+		load = append(load, LoadPoint{TimestampMs: int64(i) * LoadBucketMs, Ops: (i + 1) * 200, LatencyP95Ms: p95})
+	}
+	insights := GenerateInsights(&Report{Load: load})
+
+	found := insightsOfKind(insights, "latency_knee")
+	if len(found) != 1 {
+		t.Fatalf("expected a latency knee, got %+v", insights)
+	}
+	if !strings.HasPrefix(found[0].Title, "Latency knee at ~") || len(found[0].Evidence) != 2 {
+		t.Errorf("unexpected insight %+v", found[0])
+	}
+
+	// A steady load has no knee to find.
+	for i := range load {
+		load[i].Ops = 1000
+	}
+	if found := insightsOfKind(GenerateInsights(&Report{Load: load}), "latency_knee"); len(found) != 0 {
+		t.Errorf("expected no knee under steady load, got %+v", found)
+	}
+}
+
+func TestGenerateInsights_StallsAndSaturation(t *testing.T) {
+	nodes, load := saturationRun(400)
+	for i := range load {
+		load[i].Stalls = 1
+		if load[i].TimestampMs >= 20_000 && load[i].TimestampMs < 40_000 {
+			load[i].Stalls = 8
+		}
+	}
+	report := &Report{Load: load, ServerMetrics: ComputeServerMetrics(nodes, 0)}
+	report.ServerMetrics.CorrelateLoad(load)
+	insights := GenerateInsights(report)
+
+	stalls := insightsOfKind(insights, "stall_cpu_correlation")
+	if len(stalls) != 1 {
+		t.Fatalf("expected stalls correlated with CPU, got %+v", insights)
+	}
+	if !strings.Contains(stalls[0].Detail, "16 of 20 stalled streams") {
+		t.Errorf("unexpected detail %q", stalls[0].Detail)
+	}
+	if ev := stalls[0].Evidence[1]; ev.Metric != "server_metrics.nodes[0].cpu_max_percent" || ev.Value != 97 {
+		t.Errorf("unexpected evidence %+v", stalls[0].Evidence)
+	}
+
+	saturation := insightsOfKind(insights, "server_cpu_saturation")
+	if len(saturation) != 1 || saturation[0].Title != "CPU saturation on node-a slowed clients" {
+		t.Errorf("expected the bottleneck restated, got %+v", saturation)
+	}
+}
+
+func TestGenerateInsights_Ranking(t *testing.T) {
+	insights := []Insight{
+		{Kind: "a", Severity: InsightInfo, Score: 0.9},
+		{Kind: "b", Severity: InsightWarning, Score: 0.2},
+		{Kind: "c", Severity: InsightCritical, Score: 0.1},
+		{Kind: "d", Severity: InsightWarning, Score: 0.7},
+	}
+	saved := insightRules
+	defer func() { insightRules = saved }()
+	insightRules = []func(*Report) []Insight{func(*Report) []Insight { return insights }}
+
+	var kinds string
+	for _, in := range GenerateInsights(&Report{}) {
+		kinds += in.Kind
+	}
+	if kinds != "cdba" {
+		t.Errorf("expected severity then score order, got %s", kinds)
+	}
+	if got := GenerateInsights(nil); got != nil {
+		t.Errorf("expected no insights for a nil report, got %+v", got)
+	}
+}
@@ -0,0 +1,179 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DefaultLatencyBoundariesMs are the bucket boundaries used when a run sets
+// deadlines but no boundaries.
+var DefaultLatencyBoundariesMs = []int{10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// maxLatencyBoundaries caps the buckets a run can ask for.
+const maxLatencyBoundaries = 50
+
+// LatencyBucketConfig is the reporting.latency_buckets block of a run
+// config: the boundaries of the latency histograms in the report and the
+// deadlines operations are held to.
+type LatencyBucketConfig struct {
+	// BoundariesMs are the bucket boundaries in increasing order. Bucket i
+	// holds latencies below BoundariesMs[i] and at or above the boundary
+	// before it; a last bucket holds the rest.
+	BoundariesMs []int             `json:"boundaries_ms,omitempty"`
+	Deadlines    []LatencyDeadline `json:"deadlines,omitempty"`
+}
+
+// LatencyDeadline is a latency the operations in its scope should not
+// exceed. It applies to the whole run unless Operation or ToolName narrows
+// it.
+type LatencyDeadline struct {
+	Name       string `json:"name,omitempty"`
+	Operation  string `json:"operation,omitempty"`
+	ToolName   string `json:"tool_name,omitempty"`
+	DeadlineMs int    `json:"deadline_ms"`
+}
+
+// ValidateLatencyBuckets reports whether cfg can be evaluated.
+func ValidateLatencyBuckets(cfg LatencyBucketConfig) error {
+	if len(cfg.BoundariesMs) > maxLatencyBoundaries {
+		return fmt.Errorf("at most %d boundaries are allowed", maxLatencyBoundaries)
+	}
+	for i, b := range cfg.BoundariesMs {
+		if b <= 0 {
+			return fmt.Errorf("boundaries_ms must be positive")
+		}
+		if i > 0 && b <= cfg.BoundariesMs[i-1] {
+			return fmt.Errorf("boundaries_ms must be in increasing order")
+		}
+	}
+	for i, d := range cfg.Deadlines {
+		if d.DeadlineMs <= 0 {
+			return fmt.Errorf("deadlines[%d]: deadline_ms must be positive", i)
+		}
+		if d.Operation != "" && d.ToolName != "" {
+			return fmt.Errorf("deadlines[%d]: set operation or tool_name, not both", i)
+		}
+	}
+	return nil
+}
+
+// LatencyDistribution counts the operations of one scope per latency
+// bucket. Counts has one entry more than the boundaries.
+type LatencyDistribution struct {
+	Total  int64   `json:"total"`
+	Counts []int64 `json:"counts"`
+}
+
+// DeadlineResult is how often the operations in a deadline's scope
+// exceeded it.
+type DeadlineResult struct {
+	LatencyDeadline
+	Operations int64   `json:"operations"`
+	Missed     int64   `json:"missed"`
+	MissRate   float64 `json:"miss_rate"`
+}
+
+// LatencyBucketReportMetrics reports operation latencies as histograms over
+// the run's bucket boundaries, for the whole run and by operation and tool,
+// along with the deadline miss rates. Latencies above 2 s are bucketed from
+// the aggregator's histograms, so they may land up to 0.1% low.
+type LatencyBucketReportMetrics struct {
+	BoundariesMs []int                           `json:"boundaries_ms"`
+	All          *LatencyDistribution            `json:"all"`
+	ByOperation  map[string]*LatencyDistribution `json:"by_operation,omitempty"`
+	ByTool       map[string]*LatencyDistribution `json:"by_tool,omitempty"`
+	Deadlines    []DeadlineResult                `json:"deadlines,omitempty"`
+}
+
+// latencyDistribution counts the values of h per bucket.
+func latencyDistribution(h *Histogram, boundaries []int) *LatencyDistribution {
+	d := &LatencyDistribution{Total: h.Count(), Counts: make([]int64, len(boundaries)+1)}
+	i := 0
+	h.Buckets(func(value, count int64) {
+		for i < len(boundaries) && value >= int64(boundaries[i]) {
+			i++
+		}
+		d.Counts[i] += count
+	})
+	return d
+}
+
+// missedDeadline counts the values of h above deadlineMs.
+func missedDeadline(h *Histogram, deadlineMs int) int64 {
+	var missed int64
+	h.Buckets(func(value, count int64) {
+		if value > int64(deadlineMs) {
+			missed += count
+		}
+	})
+	return missed
+}
+
+// latencyBucketMetrics buckets the collected latencies per cfg. Returns nil
+// if cfg is nil. Must be called with a.mu held.
+func (a *Aggregator) latencyBucketMetrics(cfg *LatencyBucketConfig) *LatencyBucketReportMetrics {
+	if cfg == nil {
+		return nil
+	}
+	boundaries := cfg.BoundariesMs
+	if len(boundaries) == 0 {
+		boundaries = DefaultLatencyBoundariesMs
+	}
+	result := &LatencyBucketReportMetrics{
+		BoundariesMs: append([]int(nil), boundaries...),
+		All:          latencyDistribution(&a.total.latency, boundaries),
+		ByOperation:  make(map[string]*LatencyDistribution, len(a.byOperation)),
+	}
+	for name, stats := range a.byOperation {
+		result.ByOperation[name] = latencyDistribution(&stats.latency, boundaries)
+	}
+	if len(a.byTool) > 0 {
+		result.ByTool = make(map[string]*LatencyDistribution, len(a.byTool))
+		for name, stats := range a.byTool {
+			result.ByTool[name] = latencyDistribution(&stats.latency, boundaries)
+		}
+	}
+
+	for _, d := range cfg.Deadlines {
+		d.Operation = normalizeOpName(d.Operation)
+		stats := &a.total
+		switch {
+		case d.ToolName != "":
+			stats = a.byTool[d.ToolName]
+		case d.Operation != "":
+			stats = a.byOperation[d.Operation]
+		}
+		dr := DeadlineResult{LatencyDeadline: d}
+		if stats != nil && stats.latency.Count() > 0 {
+			dr.Operations = stats.latency.Count()
+			dr.Missed = missedDeadline(&stats.latency, d.DeadlineMs)
+			dr.MissRate = float64(dr.Missed) / float64(dr.Operations)
+		}
+		result.Deadlines = append(result.Deadlines, dr)
+	}
+	return result
+}
+
+// latencyBucketLabels names the buckets of boundaries, such as "< 10 ms",
+// "10-25 ms" and ">= 25 ms".
+func latencyBucketLabels(boundaries []int) []string {
+	labels := make([]string, 0, len(boundaries)+1)
+	for i, b := range boundaries {
+		if i == 0 {
+			labels = append(labels, fmt.Sprintf("< %d ms", b))
+			continue
+		}
+		labels = append(labels, fmt.Sprintf("%d-%d ms", boundaries[i-1], b))
+	}
+	return append(labels, fmt.Sprintf(">= %d ms", boundaries[len(boundaries)-1]))
+}
+
+// sortedDistributionNames returns the keys of m in order.
+func sortedDistributionNames(m map[string]*LatencyDistribution) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,84 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLatencyBucketMetrics(t *testing.T) {
+	agg := NewAggregator()
+	for _, op := range []OperationResult{
+		{Operation: "tools/call", ToolName: "search", LatencyMs: 5, OK: true},
+		{Operation: "tools/call", ToolName: "search", LatencyMs: 50, OK: true},
+		{Operation: "tools/call", ToolName: "search", LatencyMs: 120, OK: true},
+		{Operation: "tools/call", ToolName: "search", LatencyMs: 900, OK: false},
+		{Operation: "tools/list", LatencyMs: 10, OK: true},
+		{Operation: "tools/list", LatencyMs: 99, OK: true},
+	} {
+		agg.AddOperation(op)
+	}
+	if metrics := agg.Compute(); metrics.LatencyBuckets != nil {
+		t.Fatalf("expected no latency buckets without config, got %+v", metrics.LatencyBuckets)
+	}
+
+	agg.SetLatencyBuckets(&LatencyBucketConfig{
+		BoundariesMs: []int{10, 100, 500},
+		Deadlines: []LatencyDeadline{
+			{DeadlineMs: 100},
+			{ToolName: "search", DeadlineMs: 500},
+			{Operation: "tools_list", DeadlineMs: 50},
+			{ToolName: "missing", DeadlineMs: 50},
+		},
+	})
+	buckets := agg.Compute().LatencyBuckets
+	if buckets == nil {
+		t.Fatal("expected latency buckets")
+	}
+	if !reflect.DeepEqual(buckets.All.Counts, []int64{1, 3, 1, 1}) || buckets.All.Total != 6 {
+		t.Errorf("unexpected overall distribution %+v", buckets.All)
+	}
+	if got := buckets.ByTool["search"].Counts; !reflect.DeepEqual(got, []int64{1, 1, 1, 1}) {
+		t.Errorf("unexpected search distribution %v", got)
+	}
+	if got := buckets.ByOperation["tools/list"].Counts; !reflect.DeepEqual(got, []int64{0, 2, 0, 0}) {
+		t.Errorf("unexpected tools/list distribution %v", got)
+	}
+
+	want := []struct {
+		ops, missed int64
+		rate        float64
+	}{{6, 2, 2.0 / 6}, {4, 1, 0.25}, {2, 1, 0.5}, {0, 0, 0}}
+	for i, w := range want {
+		d := buckets.Deadlines[i]
+		if d.Operations != w.ops || d.Missed != w.missed || d.MissRate != w.rate {
+			t.Errorf("deadline %d: expected %d ops, %d missed, rate %v, got %+v", i, w.ops, w.missed, w.rate, d)
+		}
+	}
+	if buckets.Deadlines[2].Operation != "tools/list" {
+		t.Errorf("expected the operation to be normalized, got %q", buckets.Deadlines[2].Operation)
+	}
+
+	agg.SetLatencyBuckets(&LatencyBucketConfig{Deadlines: []LatencyDeadline{{DeadlineMs: 100}}})
+	if got := agg.Compute().LatencyBuckets.BoundariesMs; !reflect.DeepEqual(got, DefaultLatencyBoundariesMs) {
+		t.Errorf("expected the default boundaries, got %v", got)
+	}
+}
+
+func TestValidateLatencyBuckets(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     LatencyBucketConfig
+		wantErr bool
+	}{
+		{"valid", LatencyBucketConfig{BoundariesMs: []int{10, 50, 100}, Deadlines: []LatencyDeadline{{ToolName: "search", DeadlineMs: 200}}}, false},
+		{"not increasing", LatencyBucketConfig{BoundariesMs: []int{10, 10}}, true},
+		{"not positive", LatencyBucketConfig{BoundariesMs: []int{0, 10}}, true},
+		{"deadline not positive", LatencyBucketConfig{Deadlines: []LatencyDeadline{{DeadlineMs: 0}}}, true},
+		{"operation and tool", LatencyBucketConfig{Deadlines: []LatencyDeadline{{Operation: "tools_call", ToolName: "search", DeadlineMs: 100}}}, true},
+	}
+	for _, tt := range tests {
+		if err := ValidateLatencyBuckets(tt.cfg); (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
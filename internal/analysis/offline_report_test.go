@@ -0,0 +1,84 @@
+package analysis
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// offlineReport points TestOfflineReport at an existing report, e.g.
+//
+//	go test ./internal/analysis -run TestOfflineReport -offline-report=/path/to/report.html
+var offlineReport = flag.String("offline-report", "", "check this report.html instead of a generated one")
+
+var (
+	// Attributes that make a browser fetch a resource or navigate.
+	resourceAttrRe = regexp.MustCompile(`(?i)\s(src|href|srcset|action|formaction|poster|data|xlink:href|background)\s*=\s*["']?([^"'\s>]*)`)
+	// CSS that loads a resource.
+	cssURLRe = regexp.MustCompile(`(?i)url\(\s*["']?([^"')\s]*)|@import`)
+	// Elements and script APIs that load or connect to anything.
+	fetchingRe   = regexp.MustCompile(`(?i)<(link|iframe|object|embed|base|img|audio|video|source)[\s>]|\b(fetch|XMLHttpRequest|WebSocket|EventSource|importScripts|sendBeacon)\s*\(|\bimport\s*\(`)
+	reportDataRe = regexp.MustCompile(`(?s)<script type="application/json" id="report-data">(.*?)</script>`)
+)
+
+// TestOfflineReport checks that a report renders with no network access: it
+// must not reference any external resource, must forbid fetches through its
+// Content-Security-Policy, and must carry its chart data inline.
+func TestOfflineReport(t *testing.T) {
+	var html string
+	if *offlineReport != "" {
+		data, err := os.ReadFile(*offlineReport)
+		if err != nil {
+			t.Fatalf("failed to read report: %v", err)
+		}
+		html = string(data)
+	} else {
+		report := createFullReport()
+		report.ServerMetrics = ComputeServerMetrics([]NodeSeries{{
+			AgentID:  "agent_a",
+			Hostname: "node-a",
+			Samples: []NodeSample{
+				{TimestampMs: 1700000000000, CPUPercent: 40, CPUCores: 4},
+				{TimestampMs: 1700000010000, CPUPercent: 80, CPUCores: 4},
+			},
+		}}, 0)
+		data, err := NewReporter().GenerateHTML(report)
+		if err != nil {
+			t.Fatalf("GenerateHTML failed: %v", err)
+		}
+		html = string(data)
+		assertContains(t, html, "<polyline")
+	}
+
+	assertValidHTML(t, html)
+	assertContains(t, html, `<meta http-equiv="Content-Security-Policy" content="default-src 'none';`)
+
+	for _, m := range resourceAttrRe.FindAllStringSubmatch(html, -1) {
+		if v := m[2]; !strings.HasPrefix(v, "#") && !strings.HasPrefix(v, "data:") {
+			t.Errorf("report references external resource: %s", strings.TrimSpace(m[0]))
+		}
+	}
+	for _, m := range cssURLRe.FindAllStringSubmatch(html, -1) {
+		if m[0] == "@import" || !strings.HasPrefix(m[1], "data:") {
+			t.Errorf("report CSS loads external resource: %s", m[0])
+		}
+	}
+	for _, m := range fetchingRe.FindAllString(html, -1) {
+		t.Errorf("report can load content at view time: %s", m)
+	}
+
+	m := reportDataRe.FindStringSubmatch(html)
+	if m == nil {
+		t.Fatal("report does not embed its data")
+	}
+	var embedded Report
+	if err := json.Unmarshal([]byte(m[1]), &embedded); err != nil {
+		t.Fatalf("embedded report data is not valid JSON: %v", err)
+	}
+	if embedded.RunID == "" || embedded.Metrics == nil {
+		t.Errorf("embedded report data is incomplete: %+v", embedded)
+	}
+}
@@ -0,0 +1,134 @@
+package analysis
+
+import "sort"
+
+// PacingReportMetrics reports the think time VUs paused for between
+// operations: the pacing the run actually applied, as opposed to the one
+// configured. MeanThinkMs is over every operation, paused or not, so it is
+// the average pause that followed an operation.
+type PacingReportMetrics struct {
+	Operations  int     `json:"operations"`
+	Paused      int     `json:"paused"`
+	ThinkTimeMs int64   `json:"think_time_ms"`
+	MeanThinkMs float64 `json:"mean_think_ms"`
+	MaxThinkMs  int64   `json:"max_think_ms"`
+	// ByTool is the pacing after the calls to each tool.
+	ByTool map[string]*ToolPacingMetrics `json:"by_tool,omitempty"`
+}
+
+// ToolPacingMetrics is the think time that followed the calls to a tool.
+type ToolPacingMetrics struct {
+	Calls       int     `json:"calls"`
+	Paused      int     `json:"paused"`
+	MeanThinkMs float64 `json:"mean_think_ms"`
+	MaxThinkMs  int64   `json:"max_think_ms"`
+}
+
+// pauseStats accumulates the pauses that followed operations.
+type pauseStats struct {
+	operations int
+	paused     int
+	totalMs    int64
+	maxMs      int64
+}
+
+func (s *pauseStats) add(thinkTimeMs int64) {
+	s.operations++
+	if thinkTimeMs > 0 {
+		s.paused++
+		s.totalMs += thinkTimeMs
+		s.maxMs = max(s.maxMs, thinkTimeMs)
+	}
+}
+
+func (s *pauseStats) merge(o *pauseStats) {
+	s.operations += o.operations
+	s.paused += o.paused
+	s.totalMs += o.totalMs
+	s.maxMs = max(s.maxMs, o.maxMs)
+}
+
+func (s *pauseStats) meanMs() float64 {
+	if s.operations == 0 {
+		return 0
+	}
+	return float64(s.totalMs) / float64(s.operations)
+}
+
+// pacingStats accumulates think times overall and per tool. Retries are
+// left out: the pause follows an operation's last attempt, and is recorded
+// on its first.
+type pacingStats struct {
+	all    pauseStats
+	byTool map[string]*pauseStats
+}
+
+func (s *pacingStats) add(op OperationResult) {
+	if op.Attempt > 0 {
+		return
+	}
+	s.all.add(op.ThinkTimeMs)
+	if op.ToolName == "" {
+		return
+	}
+	if s.byTool == nil {
+		s.byTool = make(map[string]*pauseStats)
+	}
+	tool, ok := s.byTool[op.ToolName]
+	if !ok {
+		tool = &pauseStats{}
+		s.byTool[op.ToolName] = tool
+	}
+	tool.add(op.ThinkTimeMs)
+}
+
+func (s *pacingStats) merge(o *pacingStats) {
+	s.all.merge(&o.all)
+	for name, stats := range o.byTool {
+		if s.byTool == nil {
+			s.byTool = make(map[string]*pauseStats)
+		}
+		tool, ok := s.byTool[name]
+		if !ok {
+			tool = &pauseStats{}
+			s.byTool[name] = tool
+		}
+		tool.merge(stats)
+	}
+}
+
+// metrics summarizes the pacing. Returns nil if no VU paused.
+func (s *pacingStats) metrics() *PacingReportMetrics {
+	if s.all.paused == 0 {
+		return nil
+	}
+	result := &PacingReportMetrics{
+		Operations:  s.all.operations,
+		Paused:      s.all.paused,
+		ThinkTimeMs: s.all.totalMs,
+		MeanThinkMs: s.all.meanMs(),
+		MaxThinkMs:  s.all.maxMs,
+	}
+	if len(s.byTool) > 0 {
+		result.ByTool = make(map[string]*ToolPacingMetrics, len(s.byTool))
+		for name, tool := range s.byTool {
+			result.ByTool[name] = &ToolPacingMetrics{
+				Calls:       tool.operations,
+				Paused:      tool.paused,
+				MeanThinkMs: tool.meanMs(),
+				MaxThinkMs:  tool.maxMs,
+			}
+		}
+	}
+	return result
+}
+
+// pacedTools returns the tools of m in name order.
+func pacedTools(m *PacingReportMetrics) []string {
+	tools := make([]string, 0, len(m.ByTool))
+	for name := range m.ByTool {
+		tools = append(tools, name)
+	}
+	sort.Strings(tools)
+	return tools
+}
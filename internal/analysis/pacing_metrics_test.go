@@ -0,0 +1,33 @@
+package analysis
+
+import "testing"
+
+func TestPacingMetrics(t *testing.T) {
+	agg := NewAggregator()
+	agg.AddOperation(OperationResult{Operation: "tools/list", LatencyMs: 10, OK: true})
+	if metrics := agg.Compute(); metrics.Pacing != nil {
+		t.Fatalf("expected nil pacing metrics without think time, got %+v", metrics.Pacing)
+	}
+
+	agg.AddOperation(OperationResult{Operation: "tools/call", ToolName: "search", LatencyMs: 10, OK: true, ThinkTimeMs: 3000})
+	agg.AddOperation(OperationResult{Operation: "tools/call", ToolName: "search", LatencyMs: 10, OK: true, ThinkTimeMs: 1000})
+	// A retry does not count as another operation to pace.
+	agg.AddOperation(OperationResult{Operation: "tools/call", ToolName: "search", LatencyMs: 10, OK: true, Attempt: 1})
+	other := NewAggregator()
+	other.AddOperation(OperationResult{Operation: "tools/call", ToolName: "fetch", LatencyMs: 10, OK: true})
+	agg.Merge(other)
+
+	p := agg.Compute().Pacing
+	if p == nil {
+		t.Fatal("expected pacing metrics")
+	}
+	if p.Operations != 4 || p.Paused != 2 || p.ThinkTimeMs != 4000 || p.MeanThinkMs != 1000 || p.MaxThinkMs != 3000 {
+		t.Errorf("unexpected pacing: %+v", p)
+	}
+	if search := p.ByTool["search"]; search == nil || search.Calls != 2 || search.MeanThinkMs != 2000 || search.MaxThinkMs != 3000 {
+		t.Errorf("unexpected search pacing: %+v", search)
+	}
+	if fetch := p.ByTool["fetch"]; fetch == nil || fetch.Calls != 1 || fetch.Paused != 0 {
+		t.Errorf("expected the unpaced fetch calls counted, got %+v", fetch)
+	}
+}
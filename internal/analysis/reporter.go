@@ -6,7 +6,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"html/template"
+	"math"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -19,6 +22,42 @@ type Report struct {
 	Duration   int64              `json:"duration_ms"` // duration in ms
 	Metrics    *AggregatedMetrics `json:"metrics"`
 	StopReason string             `json:"stop_reason"`
+
+	// Load is the client load per LoadBucketMs bucket of the run.
+	Load []LoadPoint `json:"load,omitempty"`
+
+	// Insights are the findings of GenerateInsights, most severe first.
+	Insights []Insight `json:"insights,omitempty"`
+
+	// ServerMetrics summarizes agent metrics from the target's nodes, if
+	// server telemetry was paired with the run.
+	ServerMetrics *ServerMetricsReport `json:"server_metrics,omitempty"`
+
+	// ServerLogs correlates spikes in client errors with the errors the
+	// target's agents logged, if they shipped logs.
+	ServerLogs *ServerLogReport `json:"server_logs,omitempty"`
+
+	// Partial is set on reports generated at a stage boundary while the run
+	// is still in progress.
+	Partial *PartialReport `json:"partial,omitempty"`
+
+	// SLOs is the verdict of the run's SLOs, if the run config defines any.
+	SLOs *SLOReport `json:"slos,omitempty"`
+
+	// Capacity is the capacity found by an adaptive ramp stage, if the run
+	// had one.
+	Capacity *CapacityReport `json:"capacity,omitempty"`
+
+	// Conformance is the outcome of the protocol checks of a conformance
+	// run, which runs no load.
+	Conformance *ConformanceReport `json:"conformance,omitempty"`
+}
+
+// PartialReport marks a report that covers a single completed stage rather
+// than the whole run. Its numbers are superseded by the final report.
+type PartialReport struct {
+	Stage string `json:"stage"`
+	Note  string `json:"note"`
 }
 
 // Reporter generates HTML and JSON reports from aggregated metrics.
@@ -71,6 +110,7 @@ func (r *Reporter) GenerateHTML(report *Report) ([]byte, error) {
 		TotalOps:      report.Metrics.TotalOps,
 		SuccessOps:    report.Metrics.SuccessOps,
 		FailureOps:    report.Metrics.FailureOps,
+		WarmupOps:     report.Metrics.WarmupOps,
 		RPS:           fmt.Sprintf("%.2f", report.Metrics.RPS),
 		ErrorRate:     fmt.Sprintf("%.2f%%", report.Metrics.ErrorRate),
 		LatencyP50:    report.Metrics.LatencyP50,
@@ -80,8 +120,14 @@ func (r *Reporter) GenerateHTML(report *Report) ([]byte, error) {
 		Tools:         buildOperationRows(report.Metrics.ByTool),
 		HasOperations: len(report.Metrics.ByOperation) > 0,
 		HasTools:      len(report.Metrics.ByTool) > 0,
+		Groups:        buildOperationRows(report.Metrics.ByGroup),
+		HasGroups:     len(report.Metrics.ByGroup) > 0,
+		Targets:       buildOperationRows(report.Metrics.ByTarget),
+		HasTargets:    len(report.Metrics.ByTarget) > 0,
 		GeneratedAt:   time.Now().UTC().Format(time.RFC3339),
 	}
+	data.WorkerLabels = buildWorkerLabelRows(report.Metrics.ByWorkerLabel)
+	data.HasWorkerLabels = len(data.WorkerLabels) > 0
 
 	if report.Metrics.SessionMetrics != nil {
 		data.HasSessionMetrics = true
@@ -102,6 +148,10 @@ func (r *Reporter) GenerateHTML(report *Report) ([]byte, error) {
 		data.WorkerCount = report.Metrics.WorkerHealth.WorkerCount
 		data.SaturationDetected = report.Metrics.WorkerHealth.SaturationDetected
 		data.SaturationReason = report.Metrics.WorkerHealth.SaturationReason
+		if shed := report.Metrics.WorkerHealth.PeakShedRate; shed > 0 {
+			data.PeakShedRate = fmt.Sprintf("%.0f%%", shed*100)
+		}
+		data.GeneratorWarningRows = buildGeneratorWarningRows(report.Metrics.WorkerHealth.Warnings)
 	}
 
 	if report.Metrics.ChurnMetrics != nil {
@@ -113,6 +163,245 @@ func (r *Reporter) GenerateHTML(report *Report) ([]byte, error) {
 		data.ChurnRate = fmt.Sprintf("%.2f", report.Metrics.ChurnMetrics.ChurnRate)
 	}
 
+	if conn := report.Metrics.Connections; conn != nil {
+		data.HasConnections = true
+		data.ConnectionReuseRate = fmt.Sprintf("%.1f%%", 100*conn.ConnectionReuseRate)
+		data.NewConnections = conn.NewConnections
+		data.DNSLookups = conn.DNSLookups
+		data.DNSCacheHitRatio = fmt.Sprintf("%.1f%%", 100*conn.DNSCacheHitRatio)
+		data.DNSFailures = conn.DNSFailures
+		data.DNSLookupP50 = fmt.Sprintf("%.2f ms", conn.DNSLookupP50Ms)
+		data.DNSLookupP95 = fmt.Sprintf("%.2f ms", conn.DNSLookupP95Ms)
+		data.DNSLookupMax = fmt.Sprintf("%.2f ms", conn.DNSLookupMaxMs)
+	}
+
+	if ids := report.Metrics.RequestIDs; ids != nil {
+		data.HasRequestIDs = true
+		data.RequestIDRows = buildRequestIDRows(ids)
+		data.RequestIDFindings = ids.Findings
+	}
+
+	data.Insights = buildInsightRows(report.Insights)
+	data.ErrorSignatures = buildErrorSignatureRows(report.Metrics.ErrorSignatures, report.Metrics.FailureOps)
+	if slos := report.SLOs; slos != nil {
+		data.HasSLOs = true
+		data.SLOVerdict = slos.Verdict
+		data.SLOPassed = slos.Verdict == SLOVerdictPass
+		data.SLORows = buildSLORows(slos)
+	}
+
+	if conformance := report.Conformance; conformance != nil {
+		data.HasConformance = true
+		data.ConformancePassed = conformance.OK()
+		data.ConformanceSummary = fmt.Sprintf("%d passed, %d failed, %d skipped", conformance.Passed, conformance.Failed, conformance.Skipped)
+		data.ConformanceVersion = conformance.ProtocolVersion
+		data.ConformanceChecks = conformance.Checks
+	}
+
+	if capacity := report.Capacity; capacity != nil {
+		data.HasCapacity = true
+		data.CapacityVUs = capacity.MaxSustainableVUs
+		data.CapacityRPS = fmt.Sprintf("%.1f (%.1f-%.1f)", capacity.MaxSustainableRPS, capacity.RPSLow, capacity.RPSHigh)
+		data.CapacityBounds = fmt.Sprintf(">= %d, < %d", capacity.VUsLowerBound, capacity.VUsUpperBound)
+		if capacity.VUsUpperBound == 0 {
+			data.CapacityBounds = fmt.Sprintf(">= %d, no limit reached", capacity.VUsLowerBound)
+		}
+		data.CapacityConverged = capacity.Converged
+		data.CapacityProbeRows = buildCapacityProbeRows(capacity.Probes)
+	}
+
+	if checks := report.Metrics.Checks; checks != nil {
+		data.HasChecks = true
+		data.CheckedOps = checks.CheckedOps
+		data.CheckFailedOps = checks.FailedOps
+		data.CheckRows = buildCheckRows(checks)
+	}
+
+	if f := report.Metrics.Fuzz; f != nil {
+		data.HasFuzz = true
+		data.FuzzRows = buildFuzzRows(f)
+		data.FuzzFindings = f.Findings
+	}
+
+	if r := report.Metrics.Resumption; r != nil {
+		data.HasResumption = true
+		data.DroppedStreams = r.DroppedStreams
+		data.ResumeAttempts = r.ResumeAttempts
+		data.ResumedStreams = r.Resumed
+		data.FailedResumes = r.Failed
+		data.ResumeSuccessRate = fmt.Sprintf("%.1f%%", 100*r.SuccessRate)
+	}
+
+	if c := report.Metrics.Chaos; c != nil {
+		data.HasChaos = true
+		data.ChaosOps = c.Operations
+		data.ChaosAvgDelay = fmt.Sprintf("%.0fms", c.AvgDelayMs)
+		data.ChaosResets = c.Resets
+		data.ChaosThrottled = c.Throttled
+		data.ChaosAvgThrottled = fmt.Sprintf("%.0fms", c.AvgThrottledMs)
+		data.ChaosErrorRate = fmt.Sprintf("%.2f%%", 100*c.ErrorRateExcludingResets)
+	}
+
+	if c := report.Metrics.Cancellation; c != nil {
+		data.HasCancellation = true
+		data.CancelsSent = c.Sent
+		data.CancelsHonored = c.Honored
+		data.CancelsCompletedAnyway = c.CompletedAnyway
+		data.CancelsCompletedFirst = c.CompletedFirst
+		data.CancelHonorRate = fmt.Sprintf("%.1f%%", 100*c.HonorRate)
+	}
+
+	if t := report.Metrics.Throttling; t != nil {
+		data.HasThrottling = true
+		data.Throttled = t.Throttled
+		data.ThrottleRate = fmt.Sprintf("%.1f%%", 100*t.ThrottleRate)
+		data.ThrottleWithRetryAfter = t.WithRetryAfter
+		data.MeanRetryAfter = formatDuration(int64(t.MeanRetryAfterMs))
+		data.ThrottleHeldBack = t.HeldBack
+		data.ThrottleWait = formatDuration(t.WaitMs)
+		data.EffectiveRPS = fmt.Sprintf("%.2f", t.EffectiveRPS)
+	}
+
+	if p := report.Metrics.Pacing; p != nil {
+		data.HasPacing = true
+		data.PacedOperations = fmt.Sprintf("%d of %d", p.Paused, p.Operations)
+		data.TotalThinkTime = formatDuration(p.ThinkTimeMs)
+		data.MeanThinkTime = formatDuration(int64(p.MeanThinkMs))
+		data.MaxThinkTime = formatDuration(p.MaxThinkMs)
+		for _, tool := range pacedTools(p) {
+			m := p.ByTool[tool]
+			data.PacingRows = append(data.PacingRows, pacingRow{
+				Tool:      tool,
+				Calls:     m.Calls,
+				Paused:    m.Paused,
+				MeanThink: formatDuration(int64(m.MeanThinkMs)),
+				MaxThink:  formatDuration(m.MaxThinkMs),
+			})
+		}
+	}
+
+	for _, name := range workflowNames(report.Metrics.Workflows) {
+		w := report.Metrics.Workflows[name]
+		data.WorkflowRows = append(data.WorkflowRows, workflowRow{
+			Name:           name,
+			Runs:           w.Runs,
+			Completed:      w.Completed,
+			Failed:         w.Failed,
+			ExtractFailed:  w.ExtractFailed,
+			CompletionRate: fmt.Sprintf("%.1f%%", 100*w.CompletionRate),
+		})
+		for _, s := range w.Steps {
+			data.WorkflowStepRows = append(data.WorkflowStepRows, workflowStepRow{
+				Workflow:    name,
+				Step:        fmt.Sprintf("%d. %s", s.Index+1, s.Step),
+				TotalOps:    s.TotalOps,
+				ErrorRate:   fmt.Sprintf("%.2f%%", 100*s.ErrorRate),
+				LatencyP50:  s.LatencyP50,
+				LatencyP95:  s.LatencyP95,
+				RunsStopped: s.RunsFailed + s.RunsExtractFailed,
+			})
+		}
+	}
+
+	if r := report.Metrics.Retries; r != nil {
+		data.HasRetries = true
+		data.RetriedOperations = r.Operations
+		data.RetryAttempts = r.Retries
+		data.RecoveredByRetry = r.RecoveredByRetry
+		data.FirstAttemptSuccessRate = fmt.Sprintf("%.1f%%", 100*r.FirstAttemptSuccessRate)
+		data.EventualSuccessRate = fmt.Sprintf("%.1f%%", 100*r.EventualSuccessRate)
+		for _, m := range r.ByAttempt {
+			row := attemptRow{Attempt: m.Attempt + 1, Operations: m.Operations, Successes: m.Successes, SuccessRate: "N/A"}
+			if m.Operations > 0 {
+				row.SuccessRate = fmt.Sprintf("%.1f%%", 100*float64(m.Successes)/float64(m.Operations))
+			}
+			data.AttemptRows = append(data.AttemptRows, row)
+		}
+	}
+
+	if s := report.Metrics.Shadow; s != nil {
+		data.HasShadow = true
+		data.ShadowRows = buildShadowRows(s)
+		data.ShadowFindings = s.Findings
+	}
+
+	if b := report.Metrics.LatencyBuckets; b != nil && b.All != nil {
+		data.HasLatencyBuckets = true
+		data.LatencyBucketLabels = latencyBucketLabels(b.BoundariesMs)
+		data.LatencyBucketRows = buildLatencyBucketRows(b)
+		data.LatencyHistogram = buildLatencyHistogram(data.LatencyBucketLabels, b.All.Counts)
+		data.DeadlineRows = buildDeadlineRows(b.Deadlines)
+	}
+
+	if len(report.Metrics.StreamingByTool) > 0 {
+		data.HasStreaming = true
+		for _, tool := range streamingTools(report.Metrics.StreamingByTool) {
+			m := report.Metrics.StreamingByTool[tool]
+			data.StreamingRows = append(data.StreamingRows, streamingRow{
+				Tool:          tool,
+				Streams:       m.Streams,
+				FirstEventP50: m.TimeToFirstEventP50,
+				FirstEventP95: m.TimeToFirstEventP95,
+				MaxGapP95:     m.MaxEventGapP95,
+				MaxGap:        m.MaxEventGap,
+				Progress:      m.ProgressNotifications,
+				BadProgress:   m.ProgressTokenMismatches + m.ProgressInvalid,
+			})
+		}
+	}
+
+	if m := report.Metrics.ServerMessages; m != nil {
+		data.HasServerMessages = true
+		data.ServerRequests = m.Requests
+		data.ServerNotifications = m.Notifications
+		data.ServerReplies = m.Replies
+		data.ServerReplyErrors = m.ReplyErrors
+		for _, method := range serverMessageMethods(m) {
+			kind := "request"
+			if isNotificationMethod(method) {
+				kind = "notification"
+			}
+			data.ServerMessageRows = append(data.ServerMessageRows, serverMessageRow{Method: method, Kind: kind, Count: m.ByMethod[method]})
+		}
+	}
+
+	if report.Partial != nil {
+		data.IsPartial = true
+		data.PartialStage = report.Partial.Stage
+		data.PartialNote = report.Partial.Note
+	}
+
+	if sm := report.ServerMetrics; sm != nil && sm.Cluster != nil {
+		data.HasServerMetrics = true
+		data.ServerNodes = buildServerNodeRows(sm.Nodes)
+		data.ServerClockSkews = buildClockSkewNotes(sm.Nodes)
+		data.ServerSampleGaps = buildSampleGapNotes(sm.Nodes)
+		data.ServerContainers, data.ServerOOMKills = buildServerContainerRows(sm.Nodes)
+		data.ServerGPUs = buildServerGPURows(sm.Nodes)
+		data.ServerScraped = buildServerScrapedRows(sm.Nodes)
+		data.ServerNodeCharts = buildServerNodeCharts(sm.Nodes, report.Load)
+		data.ServerBottlenecks = buildServerBottleneckRows(sm.Bottlenecks)
+		data.ClusterNodes = sm.Cluster.Nodes
+		data.ClusterTotalCores = "-"
+		if sm.Cluster.TotalCores > 0 {
+			data.ClusterTotalCores = strconv.Itoa(sm.Cluster.TotalCores)
+		}
+		data.ClusterCoresAvg = formatCores(sm.Cluster.CoresUsedAvg)
+		data.ClusterCoresPeak = formatCores(sm.Cluster.CoresUsedPeak)
+		data.ClusterNodeMemPeak = formatBytes(sm.Cluster.NodeMemUsedPeak)
+		data.ClusterNodeMemPeakHost = sm.Cluster.NodeMemPeakHost
+		data.ClusterCoresChart = buildClusterCoresChart(sm.Cluster.Series)
+	}
+
+	if sl := report.ServerLogs; sl != nil {
+		data.HasServerLogs = true
+		data.ServerLogEntries = sl.Entries
+		data.ServerLogErrorEntries = sl.ErrorEntries
+		data.ServerLogSpikes = buildServerLogSpikeRows(sl.Spikes)
+	}
+
+	data.ReportData = report
+
 	tmpl, err := template.New("report").Parse(htmlTemplate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse template: %w", err)
@@ -128,292 +417,1408 @@ func (r *Reporter) GenerateHTML(report *Report) ([]byte, error) {
 
 // htmlReportData holds data for HTML template rendering.
 type htmlReportData struct {
-	RunID                  string
-	ScenarioID             string
-	StartTime              string
-	EndTime                string
-	Duration               string
-	StopReason             string
-	TotalOps               int
-	SuccessOps             int
-	FailureOps             int
-	RPS                    string
-	ErrorRate              string
-	LatencyP50             int
-	LatencyP95             int
-	LatencyP99             int
-	Operations             []operationRow
-	Tools                  []operationRow
-	HasOperations          bool
-	HasTools               bool
-	GeneratedAt            string
-	HasSessionMetrics      bool
-	SessionMode            string
-	TotalSessions          int
-	OpsPerSession          string
-	SessionReuseRate       string
-	SessionCreated         int64
-	SessionEvicted         int64
-	SessionReconnects      int64
-	HasWorkerHealth        bool
-	PeakCPUPercent         string
-	PeakMemoryMB           string
-	AvgActiveVUs           string
-	WorkerCount            int
-	SaturationDetected     bool
-	SaturationReason       string
-	HasChurnMetrics        bool
-	ChurnSessionsCreated   int64
-	ChurnSessionsDestroyed int64
-	ChurnActiveSessions    int
-	ChurnReconnectAttempts int64
-	ChurnRate              string
+	RunID                   string
+	ScenarioID              string
+	StartTime               string
+	EndTime                 string
+	Duration                string
+	StopReason              string
+	TotalOps                int
+	SuccessOps              int
+	FailureOps              int
+	WarmupOps               int
+	RPS                     string
+	ErrorRate               string
+	LatencyP50              int
+	LatencyP95              int
+	LatencyP99              int
+	Operations              []operationRow
+	Tools                   []operationRow
+	HasOperations           bool
+	HasTools                bool
+	Groups                  []operationRow
+	HasGroups               bool
+	Targets                 []operationRow
+	HasTargets              bool
+	WorkerLabels            []operationRow
+	HasWorkerLabels         bool
+	GeneratedAt             string
+	HasSessionMetrics       bool
+	SessionMode             string
+	TotalSessions           int
+	OpsPerSession           string
+	SessionReuseRate        string
+	SessionCreated          int64
+	SessionEvicted          int64
+	SessionReconnects       int64
+	HasWorkerHealth         bool
+	PeakCPUPercent          string
+	PeakMemoryMB            string
+	AvgActiveVUs            string
+	WorkerCount             int
+	SaturationDetected      bool
+	SaturationReason        string
+	HasChurnMetrics         bool
+	ChurnSessionsCreated    int64
+	ChurnSessionsDestroyed  int64
+	ChurnActiveSessions     int
+	ChurnReconnectAttempts  int64
+	ChurnRate               string
+	HasConnections          bool
+	ConnectionReuseRate     string
+	NewConnections          int
+	DNSLookups              int
+	DNSCacheHitRatio        string
+	DNSFailures             int
+	DNSLookupP50            string
+	DNSLookupP95            string
+	DNSLookupMax            string
+	HasRequestIDs           bool
+	RequestIDRows           []requestIDRow
+	RequestIDFindings       []string
+	Insights                []insightRow
+	ErrorSignatures         []errorSignatureRow
+	HasSLOs                 bool
+	HasConformance          bool
+	ConformancePassed       bool
+	ConformanceSummary      string
+	ConformanceVersion      string
+	ConformanceChecks       []ConformanceCheck
+	SLOVerdict              string
+	SLOPassed               bool
+	SLORows                 []sloRow
+	HasCapacity             bool
+	CapacityVUs             int
+	CapacityRPS             string
+	CapacityBounds          string
+	CapacityConverged       bool
+	CapacityProbeRows       []capacityProbeRow
+	PeakShedRate            string
+	GeneratorWarningRows    []generatorWarningRow
+	HasChecks               bool
+	CheckedOps              int
+	CheckFailedOps          int
+	CheckRows               []checkRow
+	HasFuzz                 bool
+	FuzzRows                []fuzzRow
+	FuzzFindings            []string
+	HasResumption           bool
+	DroppedStreams          int
+	ResumeAttempts          int
+	ResumedStreams          int
+	FailedResumes           int
+	ResumeSuccessRate       string
+	HasChaos                bool
+	ChaosOps                int
+	ChaosAvgDelay           string
+	ChaosResets             int
+	ChaosThrottled          int
+	ChaosAvgThrottled       string
+	ChaosErrorRate          string
+	HasCancellation         bool
+	CancelsSent             int
+	CancelsHonored          int
+	CancelsCompletedAnyway  int
+	CancelsCompletedFirst   int
+	CancelHonorRate         string
+	HasThrottling           bool
+	Throttled               int
+	ThrottleRate            string
+	ThrottleWithRetryAfter  int
+	MeanRetryAfter          string
+	ThrottleHeldBack        int
+	ThrottleWait            string
+	EffectiveRPS            string
+	HasPacing               bool
+	PacedOperations         string
+	TotalThinkTime          string
+	MeanThinkTime           string
+	MaxThinkTime            string
+	PacingRows              []pacingRow
+	WorkflowRows            []workflowRow
+	WorkflowStepRows        []workflowStepRow
+	HasRetries              bool
+	RetriedOperations       int
+	RetryAttempts           int
+	RecoveredByRetry        int
+	FirstAttemptSuccessRate string
+	EventualSuccessRate     string
+	AttemptRows             []attemptRow
+	HasShadow               bool
+	ShadowRows              []shadowRow
+	ShadowFindings          []string
+	HasLatencyBuckets       bool
+	LatencyBucketLabels     []string
+	LatencyBucketRows       []latencyBucketRow
+	LatencyHistogram        *barChart
+	DeadlineRows            []deadlineRow
+	HasStreaming            bool
+	StreamingRows           []streamingRow
+	HasServerMessages       bool
+	ServerRequests          int
+	ServerNotifications     int
+	ServerReplies           int
+	ServerReplyErrors       int
+	ServerMessageRows       []serverMessageRow
+	HasServerMetrics        bool
+	ServerNodes             []serverNodeRow
+	ServerClockSkews        []string
+	ServerSampleGaps        []string
+	ServerContainers        []serverContainerRow
+	ServerOOMKills          uint64
+	ServerGPUs              []serverGPURow
+	ServerScraped           []serverScrapedRow
+	ServerNodeCharts        []serverNodeCharts
+	ServerBottlenecks       []serverBottleneckRow
+	HasServerLogs           bool
+	ServerLogEntries        int
+	ServerLogErrorEntries   int
+	ServerLogSpikes         []serverLogSpikeRow
+	ClusterNodes            int
+	ClusterTotalCores       string
+	ClusterCoresAvg         string
+	ClusterCoresPeak        string
+	ClusterNodeMemPeak      string
+	ClusterNodeMemPeakHost  string
+	IsPartial               bool
+	PartialStage            string
+	PartialNote             string
+	ClusterCoresChart       *lineChart
+	ReportData              *Report // embedded as JSON so the file carries its own chart data
 }
 
-// operationRow represents a row in the operations/tools table.
-type operationRow struct {
-	Name       string
-	TotalOps   int
-	SuccessOps int
-	FailureOps int
-	ErrorRate  string
-	LatencyP50 int
-	LatencyP95 int
-	LatencyP99 int
+// Dimensions of inline SVG charts, in viewBox units.
+const (
+	chartWidth  = 600
+	chartHeight = 120
+)
+
+// lineChart is a pre-computed inline SVG line chart. Charts are drawn on the
+// server so the report renders without scripts or external libraries.
+type lineChart struct {
+	Points string // SVG polyline points
+	Max    string // label for the top of the y axis
+	Start  string
+	End    string
 }
 
-// formatTimestamp formats a unix timestamp (ms) to RFC3339.
-func formatTimestamp(ts int64) string {
-	if ts == 0 {
-		return "N/A"
+// buildClusterCoresChart charts cluster cores used per bucket. Returns nil
+// when there are too few buckets to draw a line.
+func buildClusterCoresChart(series []ClusterPoint) *lineChart {
+	if len(series) < 2 {
+		return nil
+	}
+
+	maxCores := 0.0
+	for _, p := range series {
+		if p.CoresUsed > maxCores {
+			maxCores = p.CoresUsed
+		}
+	}
+	if maxCores == 0 {
+		maxCores = 1
+	}
+
+	first, last := series[0].TimestampMs, series[len(series)-1].TimestampMs
+	points := chartPoints(len(series), first, last, maxCores, func(i int) (int64, float64) {
+		return series[i].TimestampMs, series[i].CoresUsed
+	})
+
+	return &lineChart{
+		Points: points,
+		Max:    formatCores(maxCores),
+		Start:  formatTimestamp(first),
+		End:    formatTimestamp(last),
 	}
-	return time.UnixMilli(ts).UTC().Format(time.RFC3339)
 }
 
-// formatDuration formats duration in ms to human-readable string.
-func formatDuration(ms int64) string {
-	if ms == 0 {
-		return "0s"
+// chartPoints returns the SVG polyline points of n values, placing the
+// value at timestamp first on the left edge, last on the right and maxValue
+// at the top.
+func chartPoints(n int, first, last int64, maxValue float64, at func(i int) (tsMs int64, value float64)) string {
+	span := float64(last - first)
+	var points strings.Builder
+	for i := 0; i < n; i++ {
+		ts, v := at(i)
+		x := float64(chartWidth) * float64(ts-first) / span
+		y := float64(chartHeight) * (1 - v/maxValue)
+		if i > 0 {
+			points.WriteByte(' ')
+		}
+		fmt.Fprintf(&points, "%.1f,%.1f", x, y)
 	}
-	d := time.Duration(ms) * time.Millisecond
-	if d < time.Second {
-		return fmt.Sprintf("%dms", ms)
+	return points.String()
+}
+
+// overlayChart is a lineChart of a server metric drawn over the client
+// load, each scaled to its own peak.
+type overlayChart struct {
+	lineChart
+	Title   string
+	Load    string // SVG polyline points of the load
+	LoadMax string
+}
+
+// serverNodeCharts holds a node's overlay charts.
+type serverNodeCharts struct {
+	Host   string
+	Charts []overlayChart
+}
+
+// buildServerNodeCharts charts each node's CPU, server process RSS, open
+// files and connections over the load, all on the time axis of the run.
+// Metrics a node never reported are left out.
+func buildServerNodeCharts(nodes []NodeMetrics, load []LoadPoint) []serverNodeCharts {
+	if len(load) < 2 {
+		return nil
 	}
-	if d < time.Minute {
-		return fmt.Sprintf("%.1fs", d.Seconds())
+	first, last := load[0].TimestampMs, load[len(load)-1].TimestampMs
+	for _, n := range nodes {
+		if len(n.Series) > 0 {
+			first = min(first, n.Series[0].TimestampMs)
+			last = max(last, n.Series[len(n.Series)-1].TimestampMs)
+		}
 	}
-	if d < time.Hour {
-		return fmt.Sprintf("%.1fm", d.Minutes())
+
+	secs := float64(LoadBucketMs) / 1000
+	maxOps := 0
+	for _, p := range load {
+		maxOps = max(maxOps, p.Ops)
 	}
-	return fmt.Sprintf("%.1fh", d.Hours())
+	loadPoints := chartPoints(len(load), first, last, float64(max(maxOps, 1))/secs, func(i int) (int64, float64) {
+		return load[i].TimestampMs, float64(load[i].Ops) / secs
+	})
+	loadMax := fmt.Sprintf("%.1f ops/s", float64(maxOps)/secs)
+
+	metrics := []struct {
+		title  string
+		value  func(NodePoint) float64
+		format func(float64) string
+	}{
+		{"Host CPU", func(p NodePoint) float64 { return p.CPUPercent }, func(v float64) string { return fmt.Sprintf("%.0f%%", v) }},
+		{"Server process RSS", func(p NodePoint) float64 { return float64(p.ProcessRSS) }, func(v float64) string { return formatBytes(uint64(v)) }},
+		{"Open file descriptors", func(p NodePoint) float64 { return float64(p.ProcessFDs) }, func(v float64) string { return fmt.Sprintf("%.0f", v) }},
+		{"Open connections", func(p NodePoint) float64 { return float64(p.ProcessConns) }, func(v float64) string { return fmt.Sprintf("%.0f", v) }},
+	}
+
+	var charts []serverNodeCharts
+	for _, n := range nodes {
+		if len(n.Series) < 2 {
+			continue
+		}
+		node := serverNodeCharts{Host: nodeLabel(n)}
+		for _, m := range metrics {
+			peak := 0.0
+			for _, p := range n.Series {
+				peak = math.Max(peak, m.value(p))
+			}
+			if peak == 0 {
+				continue
+			}
+			node.Charts = append(node.Charts, overlayChart{
+				lineChart: lineChart{
+					Points: chartPoints(len(n.Series), first, last, peak, func(i int) (int64, float64) {
+						return n.Series[i].TimestampMs, m.value(n.Series[i])
+					}),
+					Max:   m.format(peak),
+					Start: formatTimestamp(first),
+					End:   formatTimestamp(last),
+				},
+				Title:   m.title,
+				Load:    loadPoints,
+				LoadMax: loadMax,
+			})
+		}
+		if len(node.Charts) > 0 {
+			charts = append(charts, node)
+		}
+	}
+	return charts
 }
 
-// buildOperationRows converts a map of operation metrics to sorted rows.
-func buildOperationRows(metrics map[string]*OperationMetrics) []operationRow {
-	if len(metrics) == 0 {
+// barChart is a pre-computed inline SVG bar chart, drawn on the server like
+// lineChart.
+type barChart struct {
+	Bars []chartBar
+	Max  string // label for the tallest bar
+}
+
+// chartBar is one bar of a barChart, in viewBox units.
+type chartBar struct {
+	X, Y, Width, Height string
+	Title               string
+}
+
+// buildLatencyHistogram charts the operations per latency bucket. Returns
+// nil if there are none.
+func buildLatencyHistogram(labels []string, counts []int64) *barChart {
+	var maxCount int64
+	for _, n := range counts {
+		maxCount = max(maxCount, n)
+	}
+	if maxCount == 0 {
 		return nil
 	}
 
-	// Get sorted keys for deterministic output
-	keys := make([]string, 0, len(metrics))
-	for k := range metrics {
-		keys = append(keys, k)
+	slot := float64(chartWidth) / float64(len(counts))
+	chart := &barChart{Max: strconv.FormatInt(maxCount, 10)}
+	for i, n := range counts {
+		height := float64(chartHeight) * float64(n) / float64(maxCount)
+		chart.Bars = append(chart.Bars, chartBar{
+			X:      fmt.Sprintf("%.1f", slot*float64(i)+slot*0.1),
+			Y:      fmt.Sprintf("%.1f", float64(chartHeight)-height),
+			Width:  fmt.Sprintf("%.1f", slot*0.8),
+			Height: fmt.Sprintf("%.1f", height),
+			Title:  fmt.Sprintf("%s: %d", labels[i], n),
+		})
 	}
-	sort.Strings(keys)
+	return chart
+}
 
-	rows := make([]operationRow, 0, len(keys))
-	for _, name := range keys {
-		m := metrics[name]
-		rows = append(rows, operationRow{
-			Name:       name,
-			TotalOps:   m.TotalOps,
-			SuccessOps: m.SuccessOps,
-			FailureOps: m.FailureOps,
-			ErrorRate:  fmt.Sprintf("%.2f%%", m.ErrorRate),
-			LatencyP50: m.LatencyP50,
-			LatencyP95: m.LatencyP95,
-			LatencyP99: m.LatencyP99,
+// latencyBucketRow represents a row in the latency distribution table.
+type latencyBucketRow struct {
+	Scope  string
+	Total  int64
+	Counts []int64
+}
+
+func buildLatencyBucketRows(b *LatencyBucketReportMetrics) []latencyBucketRow {
+	rows := []latencyBucketRow{{Scope: "run", Total: b.All.Total, Counts: b.All.Counts}}
+	for _, name := range sortedDistributionNames(b.ByOperation) {
+		d := b.ByOperation[name]
+		rows = append(rows, latencyBucketRow{Scope: name, Total: d.Total, Counts: d.Counts})
+	}
+	for _, name := range sortedDistributionNames(b.ByTool) {
+		d := b.ByTool[name]
+		rows = append(rows, latencyBucketRow{Scope: "tool " + name, Total: d.Total, Counts: d.Counts})
+	}
+	return rows
+}
+
+// deadlineRow represents a row in the deadlines table.
+type deadlineRow struct {
+	Name       string
+	Scope      string
+	DeadlineMs int
+	Operations int64
+	Missed     int64
+	MissRate   string
+}
+
+func buildDeadlineRows(results []DeadlineResult) []deadlineRow {
+	rows := make([]deadlineRow, len(results))
+	for i, r := range results {
+		row := deadlineRow{Name: r.Name, Scope: "run", DeadlineMs: r.DeadlineMs, Operations: r.Operations, Missed: r.Missed, MissRate: "N/A"}
+		switch {
+		case r.ToolName != "":
+			row.Scope = "tool " + r.ToolName
+		case r.Operation != "":
+			row.Scope = r.Operation
+		}
+		if row.Name == "" {
+			row.Name = fmt.Sprintf("%d ms [%s]", r.DeadlineMs, row.Scope)
+		}
+		if r.Operations > 0 {
+			row.MissRate = fmt.Sprintf("%.2f%%", 100*r.MissRate)
+		}
+		rows[i] = row
+	}
+	return rows
+}
+
+// serverNodeRow represents a row in the server nodes table.
+type serverNodeRow struct {
+	Host       string
+	Samples    int
+	Cores      string
+	CPUAvg     string
+	CPUMax     string
+	CoresAvg   string
+	CoresMax   string
+	MemUsedMax string
+}
+
+// buildServerNodeRows formats per-node server metrics in report order.
+func buildServerNodeRows(nodes []NodeMetrics) []serverNodeRow {
+	rows := make([]serverNodeRow, 0, len(nodes))
+	for _, n := range nodes {
+		cores := "-"
+		if n.CPUCores > 0 {
+			cores = strconv.Itoa(n.CPUCores)
+		}
+		rows = append(rows, serverNodeRow{
+			Host:       nodeLabel(n),
+			Samples:    n.Samples,
+			Cores:      cores,
+			CPUAvg:     fmt.Sprintf("%.1f%%", n.CPUAvgPercent),
+			CPUMax:     fmt.Sprintf("%.1f%%", n.CPUMaxPercent),
+			CoresAvg:   formatCores(n.CoresUsedAvg),
+			CoresMax:   formatCores(n.CoresUsedMax),
+			MemUsedMax: formatBytes(n.MemUsedMax),
 		})
 	}
 	return rows
 }
 
-// htmlTemplate is the self-contained HTML template with embedded CSS.
-const htmlTemplate = `<!DOCTYPE html>
-<html lang="en">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>MCP Drill Report - {{.RunID}}</title>
-    <style>
-        * {
-            margin: 0;
-            padding: 0;
-            box-sizing: border-box;
-        }
-        body {
-            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, Oxygen, Ubuntu, sans-serif;
-            line-height: 1.6;
-            color: #333;
-            background: #f5f5f5;
-            padding: 20px;
-        }
-        .container {
-            max-width: 1200px;
-            margin: 0 auto;
-            background: #fff;
-            border-radius: 8px;
-            box-shadow: 0 2px 4px rgba(0,0,0,0.1);
-            padding: 30px;
-        }
-        h1 {
-            color: #2c3e50;
-            border-bottom: 3px solid #3498db;
-            padding-bottom: 10px;
-            margin-bottom: 20px;
-        }
-        h2 {
-            color: #34495e;
-            margin: 25px 0 15px 0;
-            padding-bottom: 5px;
-            border-bottom: 1px solid #eee;
-        }
-        .summary-grid {
-            display: grid;
-            grid-template-columns: repeat(auto-fit, minmax(200px, 1fr));
-            gap: 15px;
-            margin-bottom: 20px;
-        }
-        .summary-card {
-            background: #f8f9fa;
-            border-radius: 6px;
-            padding: 15px;
-            border-left: 4px solid #3498db;
-        }
-        .summary-card.success {
-            border-left-color: #27ae60;
-        }
-        .summary-card.error {
-            border-left-color: #e74c3c;
-        }
-        .summary-card.latency {
-            border-left-color: #9b59b6;
-        }
-        .summary-card.session {
-            border-left-color: #f39c12;
-        }
-        .summary-card label {
-            display: block;
-            font-size: 12px;
-            color: #7f8c8d;
-            text-transform: uppercase;
-            margin-bottom: 5px;
-        }
-        .summary-card .value {
-            font-size: 24px;
-            font-weight: bold;
-            color: #2c3e50;
-        }
-        .meta-info {
-            background: #ecf0f1;
-            border-radius: 6px;
-            padding: 15px;
-            margin-bottom: 20px;
-        }
-        .meta-info dl {
-            display: grid;
-            grid-template-columns: repeat(auto-fit, minmax(250px, 1fr));
-            gap: 10px;
-        }
-        .meta-info dt {
-            font-weight: bold;
-            color: #7f8c8d;
-            font-size: 12px;
-            text-transform: uppercase;
-        }
-        .meta-info dd {
-            color: #2c3e50;
-            margin-bottom: 10px;
-        }
-        table {
-            width: 100%;
-            border-collapse: collapse;
-            margin-top: 10px;
-        }
-        th, td {
-            padding: 12px;
-            text-align: left;
-            border-bottom: 1px solid #eee;
-        }
-        th {
-            background: #f8f9fa;
-            font-weight: 600;
-            color: #34495e;
-            font-size: 12px;
-            text-transform: uppercase;
+// serverContainerRow represents a row in the server containers table.
+type serverContainerRow struct {
+	Host         string
+	CPULimit     string
+	CoresMax     string
+	ThrottledAvg string
+	ThrottledMax string
+	MemMax       string
+	MemLimit     string
+	OOMKills     uint64
+}
+
+// buildServerContainerRows lists the nodes that reported container metrics,
+// and the OOM kills across them.
+func buildServerContainerRows(nodes []NodeMetrics) ([]serverContainerRow, uint64) {
+	var rows []serverContainerRow
+	var kills uint64
+	for _, n := range nodes {
+		c := n.Container
+		if c == nil {
+			continue
+		}
+		row := serverContainerRow{
+			Host:         nodeLabel(n),
+			CPULimit:     "-",
+			CoresMax:     formatCores(c.CPUUsageCoresMax),
+			ThrottledAvg: fmt.Sprintf("%.1f%%", c.ThrottledPercentAvg),
+			ThrottledMax: fmt.Sprintf("%.1f%%", c.ThrottledPercentMax),
+			MemMax:       formatBytes(c.MemUsageMax),
+			MemLimit:     "-",
+			OOMKills:     c.OOMKills,
+		}
+		if c.CPULimitCores > 0 {
+			row.CPULimit = formatCores(c.CPULimitCores)
+		}
+		if c.MemLimit > 0 {
+			row.MemLimit = fmt.Sprintf("%s (%.0f%% peak)", formatBytes(c.MemLimit), c.MemLimitPercentMax)
+		}
+		rows = append(rows, row)
+		kills += c.OOMKills
+	}
+	return rows, kills
+}
+
+// serverGPURow represents a row in the server GPUs table.
+type serverGPURow struct {
+	Host          string
+	GPUs          int
+	UtilAvg       string
+	UtilMax       string
+	MemMax        string
+	ProcessMemMax string
+	TempMax       string
+}
+
+func buildServerGPURows(nodes []NodeMetrics) []serverGPURow {
+	var rows []serverGPURow
+	for _, n := range nodes {
+		g := n.GPU
+		if g == nil {
+			continue
+		}
+		row := serverGPURow{
+			Host:          nodeLabel(n),
+			GPUs:          g.GPUs,
+			UtilAvg:       fmt.Sprintf("%.1f%%", g.UtilAvgPercent),
+			UtilMax:       fmt.Sprintf("%.1f%%", g.UtilMaxPercent),
+			MemMax:        fmt.Sprintf("%s / %s", formatBytes(g.MemUsedMax), formatBytes(g.MemTotal)),
+			ProcessMemMax: "-",
+			TempMax:       "-",
+		}
+		if g.ProcessMemMax > 0 {
+			row.ProcessMemMax = formatBytes(g.ProcessMemMax)
+		}
+		if g.TempMaxC > 0 {
+			row.TempMax = fmt.Sprintf("%.0f °C", g.TempMaxC)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// serverScrapedRow represents a series in the server-reported metrics
+// table.
+type serverScrapedRow struct {
+	Host   string
+	Series string
+	Type   string
+	Last   string
+	Min    string
+	Avg    string
+	Max    string
+	Rate   string
+	Mean   string
+}
+
+func buildServerScrapedRows(nodes []NodeMetrics) []serverScrapedRow {
+	var rows []serverScrapedRow
+	for _, n := range nodes {
+		for _, s := range n.Scraped {
+			row := serverScrapedRow{
+				Host:   nodeLabel(n),
+				Series: s.Series,
+				Type:   s.Type,
+				Last:   "-",
+				Min:    "-",
+				Avg:    "-",
+				Max:    "-",
+				Rate:   "-",
+				Mean:   "-",
+			}
+			if row.Type == "" {
+				row.Type = "untyped"
+			}
+			if s.Distribution {
+				if s.Mean > 0 {
+					row.Mean = fmt.Sprintf("%.4g", s.Mean)
+				}
+			} else {
+				row.Last = fmt.Sprintf("%.4g", s.Last)
+				row.Min = fmt.Sprintf("%.4g", s.Min)
+				row.Avg = fmt.Sprintf("%.4g", s.Avg)
+				row.Max = fmt.Sprintf("%.4g", s.Max)
+			}
+			if s.Distribution || s.Type == "counter" {
+				row.Rate = fmt.Sprintf("%.4g", s.RatePerSec)
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// serverBottleneckRow represents a stretch of CPU saturation that slowed
+// clients down.
+type serverBottleneckRow struct {
+	Host        string
+	Start       string
+	End         string
+	PeakCPU     string
+	BaselineP95 string
+	PeakP95     string
+}
+
+func buildServerBottleneckRows(bottlenecks []ServerBottleneck) []serverBottleneckRow {
+	rows := make([]serverBottleneckRow, len(bottlenecks))
+	for i, b := range bottlenecks {
+		host := b.Hostname
+		if host == "" {
+			host = b.AgentID
+		}
+		rows[i] = serverBottleneckRow{
+			Host:        host,
+			Start:       formatTimestamp(b.StartMs),
+			End:         formatTimestamp(b.EndMs),
+			PeakCPU:     fmt.Sprintf("%.1f%%", b.PeakCPUPercent),
+			BaselineP95: fmt.Sprintf("%d ms", b.BaselineP95Ms),
+			PeakP95:     fmt.Sprintf("%d ms", b.PeakP95Ms),
+		}
+	}
+	return rows
+}
+
+// serverLogSpikeRow represents a spike in client errors with the server
+// log errors around it.
+type serverLogSpikeRow struct {
+	Start        string
+	End          string
+	ClientErrors int
+	ServerErrors int
+	Signatures   []ServerLogSignature
+}
+
+func buildServerLogSpikeRows(spikes []ErrorSpike) []serverLogSpikeRow {
+	rows := make([]serverLogSpikeRow, len(spikes))
+	for i, s := range spikes {
+		rows[i] = serverLogSpikeRow{
+			Start:        time.UnixMilli(s.StartMs).UTC().Format(time.RFC3339),
+			End:          time.UnixMilli(s.EndMs).UTC().Format(time.RFC3339),
+			ClientErrors: s.ClientErrors,
+			ServerErrors: s.ServerErrors,
+			Signatures:   s.Signatures,
+		}
+	}
+	return rows
+}
+
+// buildClockSkewNotes describes, per node, the samples whose timestamps were
+// corrected because the agent's clock was off.
+func buildClockSkewNotes(nodes []NodeMetrics) []string {
+	var notes []string
+	for _, n := range nodes {
+		if n.SkewedSamples == 0 {
+			continue
+		}
+		notes = append(notes, fmt.Sprintf("%s: %d of %d samples shifted by up to %+.1fs",
+			nodeLabel(n), n.SkewedSamples, n.Samples, float64(n.MaxClockSkewMs)/1000))
+	}
+	return notes
+}
+
+// buildSampleGapNotes describes, per node, the samples the agent collected
+// but never delivered.
+func buildSampleGapNotes(nodes []NodeMetrics) []string {
+	var notes []string
+	for _, n := range nodes {
+		if n.MissingSamples == 0 {
+			continue
+		}
+		notes = append(notes, fmt.Sprintf("%s: %d samples missing, %d received",
+			nodeLabel(n), n.MissingSamples, n.Samples))
+	}
+	return notes
+}
+
+// formatCores formats a number of CPU cores.
+func formatCores(cores float64) string {
+	return fmt.Sprintf("%.2f", cores)
+}
+
+// formatBytes formats a byte count using binary units.
+func formatBytes(b uint64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := uint64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
+
+// requestIDRow represents a row in the request id table.
+type requestIDRow struct {
+	Kind               string
+	Requests           int
+	Failures           int
+	ErrorRate          string
+	EchoTypeMismatches int
+}
+
+// buildRequestIDRows lists id kinds in report order, followed by duplicates.
+func buildRequestIDRows(metrics *RequestIDReportMetrics) []requestIDRow {
+	var rows []requestIDRow
+	add := func(kind string, m *RequestIDKindMetrics) {
+		rows = append(rows, requestIDRow{
+			Kind:               kind,
+			Requests:           m.Requests,
+			Failures:           m.Failures,
+			ErrorRate:          fmt.Sprintf("%.1f%%", 100*m.ErrorRate),
+			EchoTypeMismatches: m.EchoTypeMismatches,
+		})
+	}
+	for _, kind := range requestIDKinds {
+		if m := metrics.ByKind[kind]; m != nil {
+			add(kind, m)
+		}
+	}
+	if metrics.Duplicates != nil {
+		add("duplicate", metrics.Duplicates)
+	}
+	return rows
+}
+
+// insightRow represents an insight with its evidence formatted.
+type insightRow struct {
+	Severity string
+	Title    string
+	Detail   string
+	Evidence []string
+}
+
+func buildInsightRows(insights []Insight) []insightRow {
+	rows := make([]insightRow, len(insights))
+	for i, in := range insights {
+		rows[i] = insightRow{Severity: in.Severity, Title: in.Title, Detail: in.Detail}
+		for _, e := range in.Evidence {
+			rows[i].Evidence = append(rows[i].Evidence, e.Metric+" = "+strconv.FormatFloat(e.Value, 'g', 6, 64))
+		}
+	}
+	return rows
+}
+
+// errorSignatureRow represents a row in the error signatures table.
+type errorSignatureRow struct {
+	Pattern        string
+	Sample         string
+	Classification string
+	Count          int
+	Share          string
+	FirstSeen      string
+	LastSeen       string
+	Tools          string
+	ExampleOps     string
+}
+
+// buildErrorSignatureRows lists signatures in ranked order, each with its
+// share of all failures.
+func buildErrorSignatureRows(signatures []ErrorSignature, failures int) []errorSignatureRow {
+	rows := make([]errorSignatureRow, len(signatures))
+	for i, s := range signatures {
+		var class []string
+		for _, c := range []string{s.ErrorType, s.ErrorCode} {
+			if c != "" && c != s.Pattern {
+				class = append(class, c)
+			}
+		}
+		if s.JSONRPCCode != nil {
+			class = append(class, "JSON-RPC "+strconv.Itoa(*s.JSONRPCCode))
+		}
+		row := errorSignatureRow{
+			Pattern:        s.Pattern,
+			Classification: strings.Join(class, ", "),
+			Count:          s.Count,
+			Share:          "-",
+			FirstSeen:      formatTimestamp(s.FirstSeenMs),
+			LastSeen:       formatTimestamp(s.LastSeenMs),
+			Tools:          strings.Join(s.AffectedTools, ", "),
+			ExampleOps:     strings.Join(s.ExampleOpIDs, ", "),
+		}
+		if s.SampleError != s.Pattern {
+			row.Sample = s.SampleError
+		}
+		if row.Classification == "" {
+			row.Classification = "-"
+		}
+		if row.Tools == "" {
+			row.Tools = "-"
+		}
+		if row.ExampleOps == "" {
+			row.ExampleOps = "-"
+		}
+		if failures > 0 {
+			row.Share = fmt.Sprintf("%.1f%%", 100*float64(s.Count)/float64(failures))
+		}
+		rows[i] = row
+	}
+	return rows
+}
+
+type sloRow struct {
+	Name      string
+	Objective string
+	Scope     string
+	Actual    string
+	Result    string
+}
+
+func buildSLORows(report *SLOReport) []sloRow {
+	rows := make([]sloRow, len(report.Results))
+	for i, r := range report.Results {
+		row := sloRow{Name: r.Name, Objective: r.Objective, Scope: "run", Actual: "-", Result: SLOVerdictFail}
+		switch {
+		case r.ToolName != "":
+			row.Scope = "tool " + r.ToolName
+		case r.Operation != "":
+			row.Scope = r.Operation
+		}
+		if r.Actual != nil {
+			row.Actual = strconv.FormatFloat(*r.Actual, 'f', -1, 64)
+		}
+		if r.Passed {
+			row.Result = SLOVerdictPass
+		} else if r.Message != "" {
+			row.Result += ": " + r.Message
+		}
+		rows[i] = row
+	}
+	return rows
+}
+
+// capacityProbeRow represents a probe of an adaptive ramp.
+type capacityProbeRow struct {
+	VUs        int
+	Operations int
+	RPS        string
+	Result     string
+}
+
+func buildCapacityProbeRows(probes []CapacityProbe) []capacityProbeRow {
+	rows := make([]capacityProbeRow, len(probes))
+	for i, p := range probes {
+		row := capacityProbeRow{VUs: p.VUs, Operations: p.Operations, RPS: fmt.Sprintf("%.1f", p.RPS), Result: "held"}
+		switch {
+		case p.Guardrail != "":
+			row.Result = fmt.Sprintf("tripped %s (%s)", p.Guardrail, strconv.FormatFloat(p.Observed, 'f', -1, 64))
+		case !p.Passed:
+			row.Result = "no operations"
+		}
+		rows[i] = row
+	}
+	return rows
+}
+
+// generatorWarningRow represents a warning a worker raised about its own
+// health.
+type generatorWarningRow struct {
+	Time     string
+	WorkerID string
+	Kind     string
+	Resource string
+	ShedRate string
+}
+
+func buildGeneratorWarningRows(warnings []GeneratorWarning) []generatorWarningRow {
+	rows := make([]generatorWarningRow, len(warnings))
+	for i, w := range warnings {
+		row := generatorWarningRow{
+			Time:     time.UnixMilli(w.TimestampMs).UTC().Format(time.RFC3339),
+			WorkerID: w.WorkerID,
+			Kind:     w.Kind,
+			ShedRate: fmt.Sprintf("%.0f%%", w.ShedRate*100),
+		}
+		if w.Resource != "" {
+			row.Resource = fmt.Sprintf("%s at %.1f%% (limit %.0f%%)", w.Resource, w.Usage, w.Limit)
+		}
+		rows[i] = row
+	}
+	return rows
+}
+
+// attemptRow represents a row in the retries table. Attempt counts from 1.
+type attemptRow struct {
+	Attempt     int
+	Operations  int
+	Successes   int
+	SuccessRate string
+}
+
+// shadowRow represents a row in the shadow comparison table.
+type shadowRow struct {
+	Name         string
+	Operations   int
+	TargetErrors string
+	ShadowErrors string
+	TargetP95    int
+	ShadowP95    int
+	Delta        string
+	Mismatches   string
+}
+
+// buildShadowRows lists the shadow comparison overall, then by operation
+// and by tool in name order.
+func buildShadowRows(metrics *ShadowReportMetrics) []shadowRow {
+	row := func(name string, c *ShadowComparison) shadowRow {
+		r := shadowRow{
+			Name:         name,
+			Operations:   c.Operations,
+			TargetErrors: fmt.Sprintf("%.1f%%", 100*c.TargetErrorRate),
+			ShadowErrors: fmt.Sprintf("%.1f%%", 100*c.ShadowErrorRate),
+			TargetP95:    c.TargetLatencyP95,
+			ShadowP95:    c.ShadowLatencyP95,
+			Delta:        fmt.Sprintf("%+.1f ms", c.LatencyDeltaMs),
+			Mismatches:   "N/A",
+		}
+		if c.ResultsCompared > 0 {
+			r.Mismatches = fmt.Sprintf("%d / %d", c.ResultMismatches, c.ResultsCompared)
+		}
+		return r
+	}
+	rows := []shadowRow{row("All operations", &metrics.ShadowComparison)}
+	for _, group := range []map[string]*ShadowComparison{metrics.ByOperation, metrics.ByTool} {
+		names := make([]string, 0, len(group))
+		for name := range group {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			rows = append(rows, row(name, group[name]))
+		}
+	}
+	return rows
+}
+
+// fuzzRow represents a row in the fuzzing table.
+type fuzzRow struct {
+	Mutation  string
+	Requests  int
+	Accepted  int
+	Rejected  int
+	Unhandled int
+}
+
+// pacingRow is a tool's row in the pacing table.
+type pacingRow struct {
+	Tool      string
+	Calls     int
+	Paused    int
+	MeanThink string
+	MaxThink  string
+}
+
+// workflowRow is a workflow's row in the workflows table.
+type workflowRow struct {
+	Name           string
+	Runs           int
+	Completed      int
+	Failed         int
+	ExtractFailed  int
+	CompletionRate string
+}
+
+// workflowStepRow is a workflow step's row in the workflow steps table.
+type workflowStepRow struct {
+	Workflow    string
+	Step        string
+	TotalOps    int
+	ErrorRate   string
+	LatencyP50  int
+	LatencyP95  int
+	RunsStopped int
+}
+
+// streamingRow represents a row in the streaming table.
+type streamingRow struct {
+	Tool          string
+	Streams       int
+	FirstEventP50 int64
+	FirstEventP95 int64
+	MaxGapP95     int64
+	MaxGap        int64
+	Progress      int
+	BadProgress   int
+}
+
+// serverMessageRow represents a row in the server messages table.
+type serverMessageRow struct {
+	Method string
+	Kind   string
+	Count  int
+}
+
+// buildFuzzRows lists mutations in report order.
+func buildFuzzRows(metrics *FuzzReportMetrics) []fuzzRow {
+	order := fuzzMutationOrder(metrics)
+	rows := make([]fuzzRow, len(order))
+	for i, name := range order {
+		m := metrics.ByMutation[name]
+		rows[i] = fuzzRow{
+			Mutation:  name,
+			Requests:  m.Requests,
+			Accepted:  m.Accepted,
+			Rejected:  m.Rejected,
+			Unhandled: m.Unhandled,
+		}
+	}
+	return rows
+}
+
+type checkRow struct {
+	Name     string
+	Target   string
+	Total    int
+	Failed   int
+	PassRate string
+}
+
+func buildCheckRows(metrics *CheckReportMetrics) []checkRow {
+	rows := make([]checkRow, len(metrics.Checks))
+	for i, m := range metrics.Checks {
+		target := m.Operation
+		if m.ToolName != "" {
+			target += " " + m.ToolName
+		}
+		rows[i] = checkRow{
+			Name:     m.Name,
+			Target:   target,
+			Total:    m.Total,
+			Failed:   m.Failed,
+			PassRate: fmt.Sprintf("%.1f%%", 100*m.PassRate),
+		}
+	}
+	return rows
+}
+
+// operationRow represents a row in the operations/tools table.
+type operationRow struct {
+	Name       string
+	TotalOps   int
+	SuccessOps int
+	FailureOps int
+	ErrorRate  string
+	LatencyP50 int
+	LatencyP95 int
+	LatencyP99 int
+}
+
+// buildWorkerLabelRows converts worker label metrics to rows named
+// key=value, sorted by key and then value.
+func buildWorkerLabelRows(metrics map[string]map[string]*OperationMetrics) []operationRow {
+	keys := make([]string, 0, len(metrics))
+	for k := range metrics {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var rows []operationRow
+	for _, key := range keys {
+		for _, row := range buildOperationRows(metrics[key]) {
+			row.Name = key + "=" + row.Name
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// formatTimestamp formats a unix timestamp (ms) to RFC3339.
+func formatTimestamp(ts int64) string {
+	if ts == 0 {
+		return "N/A"
+	}
+	return time.UnixMilli(ts).UTC().Format(time.RFC3339)
+}
+
+// formatDuration formats duration in ms to human-readable string.
+func formatDuration(ms int64) string {
+	if ms == 0 {
+		return "0s"
+	}
+	d := time.Duration(ms) * time.Millisecond
+	if d < time.Second {
+		return fmt.Sprintf("%dms", ms)
+	}
+	if d < time.Minute {
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	}
+	if d < time.Hour {
+		return fmt.Sprintf("%.1fm", d.Minutes())
+	}
+	return fmt.Sprintf("%.1fh", d.Hours())
+}
+
+// buildOperationRows converts a map of operation metrics to sorted rows.
+func buildOperationRows(metrics map[string]*OperationMetrics) []operationRow {
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	// Get sorted keys for deterministic output
+	keys := make([]string, 0, len(metrics))
+	for k := range metrics {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	rows := make([]operationRow, 0, len(keys))
+	for _, name := range keys {
+		m := metrics[name]
+		rows = append(rows, operationRow{
+			Name:       name,
+			TotalOps:   m.TotalOps,
+			SuccessOps: m.SuccessOps,
+			FailureOps: m.FailureOps,
+			ErrorRate:  fmt.Sprintf("%.2f%%", m.ErrorRate),
+			LatencyP50: m.LatencyP50,
+			LatencyP95: m.LatencyP95,
+			LatencyP99: m.LatencyP99,
+		})
+	}
+	return rows
+}
+
+// htmlTemplate is the self-contained HTML template with embedded CSS.
+// All data is rendered server-side as semantic tables and description lists, so the
+// report stays fully readable with JavaScript disabled; the inline script only adds
+// column sorting on top. Colors meet WCAG AA contrast against their backgrounds.
+const htmlTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <meta name="color-scheme" content="light">
+    <meta http-equiv="Content-Security-Policy" content="default-src 'none'; style-src 'unsafe-inline'; script-src 'unsafe-inline'; img-src data:">
+    <title>MCP Drill Report - {{.RunID}}</title>
+    <style>
+        * {
+            margin: 0;
+            padding: 0;
+            box-sizing: border-box;
         }
-        tr:hover {
-            background: #f8f9fa;
+        body {
+            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, Oxygen, Ubuntu, sans-serif;
+            line-height: 1.6;
+            color: #222;
+            background: #f5f5f5;
+            padding: 20px;
         }
-        .latency-section {
-            display: grid;
-            grid-template-columns: repeat(3, 1fr);
-            gap: 15px;
-            margin-bottom: 20px;
+        .skip-link {
+            position: absolute;
+            left: -9999px;
+            top: 0;
+            background: #1f4e79;
+            color: #fff;
+            padding: 8px 12px;
+            z-index: 10;
         }
-        .latency-card {
-            background: #f8f9fa;
+        .skip-link:focus {
+            left: 20px;
+        }
+        a:focus, button:focus {
+            outline: 3px solid #1f4e79;
+            outline-offset: 2px;
+        }
+        .container {
+            max-width: 1200px;
+            margin: 0 auto;
+            background: #fff;
+            border-radius: 8px;
+            box-shadow: 0 2px 4px rgba(0,0,0,0.1);
+            padding: 30px;
+        }
+        h1 {
+            color: #2c3e50;
+            border-bottom: 3px solid #2471a3;
+            padding-bottom: 10px;
+            margin-bottom: 20px;
+        }
+        h2 {
+            color: #2c3e50;
+            margin: 25px 0 15px 0;
+            padding-bottom: 5px;
+            border-bottom: 1px solid #ddd;
+        }
+        .summary-grid {
+            display: grid;
+            grid-template-columns: repeat(auto-fit, minmax(200px, 1fr));
+            gap: 15px;
+            margin-bottom: 20px;
+        }
+        .summary-card {
+            background: #f8f9fa;
+            border-radius: 6px;
+            padding: 15px;
+            border-left: 4px solid #2471a3;
+        }
+        .summary-card.success {
+            border-left-color: #1e8449;
+        }
+        .summary-card.error {
+            border-left-color: #c0392b;
+        }
+        .summary-card.latency {
+            border-left-color: #7d3c98;
+        }
+        .summary-card.session {
+            border-left-color: #b9770e;
+        }
+        .summary-card dt {
+            display: block;
+            font-size: 12px;
+            color: #4d5656;
+            text-transform: uppercase;
+            margin-bottom: 5px;
+        }
+        .summary-card dd {
+            font-size: 24px;
+            font-weight: bold;
+            color: #2c3e50;
+        }
+        .meta-info {
+            background: #ecf0f1;
+            border-radius: 6px;
+            padding: 15px;
+            margin-bottom: 20px;
+        }
+        .meta-info dl {
+            display: grid;
+            grid-template-columns: repeat(auto-fit, minmax(250px, 1fr));
+            gap: 10px;
+        }
+        .meta-info dt {
+            font-weight: bold;
+            color: #4d5656;
+            font-size: 12px;
+            text-transform: uppercase;
+        }
+        .meta-info dd {
+            color: #2c3e50;
+            margin-bottom: 10px;
+        }
+        .table-wrapper {
+            overflow-x: auto;
+        }
+        table {
+            width: 100%;
+            border-collapse: collapse;
+            margin-top: 10px;
+        }
+        caption {
+            text-align: left;
+            font-size: 14px;
+            color: #4d5656;
+            padding-bottom: 6px;
+        }
+        th, td {
+            padding: 12px;
+            text-align: left;
+            border-bottom: 1px solid #ddd;
+        }
+        td.num {
+            text-align: right;
+            font-variant-numeric: tabular-nums;
+        }
+        thead th {
+            background: #f8f9fa;
+            font-weight: 600;
+            color: #2c3e50;
+            font-size: 12px;
+            text-transform: uppercase;
+        }
+        tbody th {
+            font-weight: 600;
+            color: #222;
+        }
+        thead th button {
+            font: inherit;
+            color: inherit;
+            text-transform: inherit;
+            background: none;
+            border: none;
+            cursor: pointer;
+            padding: 0;
+        }
+        th[aria-sort="ascending"] button::after {
+            content: " \25B2";
+        }
+        th[aria-sort="descending"] button::after {
+            content: " \25BC";
+        }
+        tbody tr:hover {
+            background: #f2f4f5;
+        }
+        .latency-section {
+            display: grid;
+            grid-template-columns: repeat(3, 1fr);
+            gap: 15px;
+            margin-bottom: 20px;
+        }
+        .latency-card {
+            background: #f8f9fa;
             border-radius: 6px;
             padding: 20px;
             text-align: center;
         }
-        .latency-card label {
+        .latency-card dt {
             display: block;
             font-size: 14px;
-            color: #7f8c8d;
+            color: #4d5656;
             margin-bottom: 5px;
         }
-        .latency-card .value {
+        .latency-card dd {
             font-size: 28px;
             font-weight: bold;
-            color: #9b59b6;
+            color: #6c3483;
         }
         .latency-card .unit {
             font-size: 14px;
-            color: #7f8c8d;
+            color: #4d5656;
         }
         .no-data {
-            color: #95a5a6;
+            color: #4d5656;
             font-style: italic;
             padding: 20px;
             text-align: center;
             background: #f8f9fa;
             border-radius: 6px;
         }
+        .chart {
+            margin: 0 0 20px;
+        }
+        .chart svg {
+            width: 100%;
+            height: 120px;
+            background: #f8f9fa;
+            border-radius: 6px;
+        }
+        .chart figcaption {
+            font-size: 12px;
+            color: #4d5656;
+            margin-top: 5px;
+        }
+        pre.log-sample {
+            background: #f8f9fa;
+            border: 1px solid #dee2e6;
+            border-radius: 4px;
+            padding: 10px;
+            overflow-x: auto;
+            white-space: pre-wrap;
+            font-size: 0.85em;
+        }
+        .insight {
+            border: 1px solid #dee2e6;
+            border-left: 4px solid #4d5656;
+            border-radius: 6px;
+            padding: 10px 15px;
+            margin-bottom: 10px;
+        }
+        .insight.critical {
+            border-left-color: #c0392b;
+        }
+        .insight.warning {
+            border-left-color: #b38600;
+        }
+        .insight h3 {
+            margin: 0 0 5px;
+        }
+        .insight ul {
+            font-size: 12px;
+            color: #4d5656;
+            margin: 5px 0 0;
+        }
         .warning-banner {
             background: #fff3cd;
-            border: 1px solid #ffc107;
-            border-left: 4px solid #ffc107;
+            border: 1px solid #b38600;
+            border-left: 4px solid #b38600;
             border-radius: 6px;
             padding: 15px;
             margin-bottom: 15px;
-            color: #856404;
+            color: #664d03;
         }
         footer {
             margin-top: 30px;
             padding-top: 15px;
-            border-top: 1px solid #eee;
-            color: #95a5a6;
+            border-top: 1px solid #ddd;
+            color: #4d5656;
             font-size: 12px;
             text-align: center;
         }
+        @media print {
+            body {
+                background: #fff;
+                padding: 0;
+            }
+            .container {
+                box-shadow: none;
+                padding: 0;
+            }
+            .skip-link {
+                display: none;
+            }
+        }
     </style>
 </head>
 <body>
+    <a class="skip-link" href="#report-main">Skip to report</a>
     <div class="container">
-        <h1>MCP Drill Report</h1>
-        
-        <div class="meta-info">
+        <header>
+            <h1>MCP Drill Report{{if .IsPartial}} (Partial: {{.PartialStage}}){{end}}</h1>
+        </header>
+
+        <main id="report-main">
+        {{if .IsPartial}}
+        <div class="warning-banner" role="note">
+            <strong>Partial report:</strong> {{.PartialNote}}
+        </div>
+        {{end}}
+        <section class="meta-info" aria-label="Run details">
             <dl>
                 <div>
                     <dt>Run ID</dt>
@@ -425,11 +1830,11 @@ const htmlTemplate = `<!DOCTYPE html>
                 </div>
                 <div>
                     <dt>Start Time</dt>
-                    <dd>{{.StartTime}}</dd>
+                    <dd><time datetime="{{.StartTime}}">{{.StartTime}}</time></dd>
                 </div>
                 <div>
                     <dt>End Time</dt>
-                    <dd>{{.EndTime}}</dd>
+                    <dd><time datetime="{{.EndTime}}">{{.EndTime}}</time></dd>
                 </div>
                 <div>
                     <dt>Duration</dt>
@@ -440,212 +1845,1499 @@ const htmlTemplate = `<!DOCTYPE html>
                     <dd>{{.StopReason}}</dd>
                 </div>
             </dl>
+        </section>
+
+        {{if .HasSLOs}}
+        <section aria-labelledby="slo-heading">
+        <h2 id="slo-heading">SLOs</h2>
+        <dl class="summary-grid">
+            <div class="summary-card {{if .SLOPassed}}success{{else}}error{{end}}">
+                <dt>Verdict</dt>
+                <dd>{{.SLOVerdict}}</dd>
+            </div>
+        </dl>
+        <div class="table-wrapper">
+        <table>
+            <caption>Objectives evaluated against the whole run</caption>
+            <thead>
+                <tr>
+                    <th scope="col">SLO</th>
+                    <th scope="col">Objective</th>
+                    <th scope="col">Scope</th>
+                    <th scope="col">Actual</th>
+                    <th scope="col">Result</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .SLORows}}
+                <tr>
+                    <th scope="row">{{.Name}}</th>
+                    <td>{{.Objective}}</td>
+                    <td>{{.Scope}}</td>
+                    <td class="num">{{.Actual}}</td>
+                    <td>{{.Result}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        </div>
+        </section>
+        {{end}}
+
+        {{if .HasConformance}}
+        <section aria-labelledby="conformance-heading">
+        <h2 id="conformance-heading">Protocol Conformance</h2>
+        <dl class="summary-grid">
+            <div class="summary-card {{if .ConformancePassed}}success{{else}}error{{end}}">
+                <dt>Checks</dt>
+                <dd>{{.ConformanceSummary}}</dd>
+            </div>
+            {{if .ConformanceVersion}}
+            <div class="summary-card">
+                <dt>Negotiated Version</dt>
+                <dd>{{.ConformanceVersion}}</dd>
+            </div>
+            {{end}}
+        </dl>
+        <div class="table-wrapper">
+        <table>
+            <caption>Protocol checks run against the target</caption>
+            <thead>
+                <tr>
+                    <th scope="col">Check</th>
+                    <th scope="col">Category</th>
+                    <th scope="col">Description</th>
+                    <th scope="col">Result</th>
+                    <th scope="col">Detail</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .ConformanceChecks}}
+                <tr>
+                    <th scope="row">{{.ID}}</th>
+                    <td>{{.Category}}</td>
+                    <td>{{.Description}}</td>
+                    <td>{{.Status}}</td>
+                    <td>{{.Detail}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        </div>
+        </section>
+        {{end}}
+
+        {{if .Insights}}
+        <section aria-labelledby="insights-heading">
+        <h2 id="insights-heading">Insights</h2>
+        {{range .Insights}}
+        <article class="insight {{.Severity}}">
+            <h3>{{.Title}}</h3>
+            <p>{{.Detail}}</p>
+            <ul aria-label="Evidence">
+                {{range .Evidence}}<li><code>{{.}}</code></li>{{end}}
+            </ul>
+        </article>
+        {{end}}
+        </section>
+        {{end}}
+
+        {{if .HasCapacity}}
+        <section aria-labelledby="capacity-heading">
+        <h2 id="capacity-heading">Capacity</h2>
+        <dl class="summary-grid">
+            <div class="summary-card{{if .CapacityConverged}} success{{end}}">
+                <dt>Max Sustainable VUs</dt>
+                <dd>{{.CapacityVUs}}</dd>
+            </div>
+            <div class="summary-card">
+                <dt>Sustainable RPS (95% CI)</dt>
+                <dd>{{.CapacityRPS}}</dd>
+            </div>
+            <div class="summary-card">
+                <dt>VU Bounds</dt>
+                <dd>{{.CapacityBounds}}</dd>
+            </div>
+        </dl>
+        <div class="table-wrapper">
+        <table>
+            <caption>Load levels probed by the adaptive ramp, in order</caption>
+            <thead>
+                <tr>
+                    <th scope="col">VUs</th>
+                    <th scope="col">Operations</th>
+                    <th scope="col">RPS</th>
+                    <th scope="col">Result</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .CapacityProbeRows}}
+                <tr>
+                    <td class="num">{{.VUs}}</td>
+                    <td class="num">{{.Operations}}</td>
+                    <td class="num">{{.RPS}}</td>
+                    <td>{{.Result}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        </div>
+        </section>
+        {{end}}
+
+        <section aria-labelledby="summary-heading">
+        <h2 id="summary-heading">Summary</h2>
+        <dl class="summary-grid">
+            <div class="summary-card">
+                <dt>Total Operations</dt>
+                <dd>{{.TotalOps}}</dd>
+            </div>
+            <div class="summary-card success">
+                <dt>Successful</dt>
+                <dd>{{.SuccessOps}}</dd>
+            </div>
+            <div class="summary-card error">
+                <dt>Failed</dt>
+                <dd>{{.FailureOps}}</dd>
+            </div>
+            <div class="summary-card">
+                <dt>Requests/Second</dt>
+                <dd>{{.RPS}}</dd>
+            </div>
+            <div class="summary-card error">
+                <dt>Error Rate</dt>
+                <dd>{{.ErrorRate}}</dd>
+            </div>
+            {{if .WarmupOps}}
+            <div class="summary-card">
+                <dt>Warmup Operations (excluded)</dt>
+                <dd>{{.WarmupOps}}</dd>
+            </div>
+            {{end}}
+        </dl>
+        </section>
+
+        <section aria-labelledby="latency-heading">
+        <h2 id="latency-heading">Latency Percentiles</h2>
+        <dl class="latency-section">
+            <div class="latency-card">
+                <dt>P50 (Median)</dt>
+                <dd>{{.LatencyP50}}<span class="unit">ms</span></dd>
+            </div>
+            <div class="latency-card">
+                <dt>P95</dt>
+                <dd>{{.LatencyP95}}<span class="unit">ms</span></dd>
+            </div>
+            <div class="latency-card">
+                <dt>P99</dt>
+                <dd>{{.LatencyP99}}<span class="unit">ms</span></dd>
+            </div>
+        </dl>
+        </section>
+
+        {{if .HasLatencyBuckets}}
+        <section aria-labelledby="latency-buckets-heading">
+        <h2 id="latency-buckets-heading">Latency Distribution</h2>
+        {{with .LatencyHistogram}}
+        <figure class="chart">
+            <svg viewBox="0 0 600 120" preserveAspectRatio="none" role="img" aria-label="Operations per latency bucket, largest bucket {{.Max}}">
+                {{range .Bars}}
+                <rect x="{{.X}}" y="{{.Y}}" width="{{.Width}}" height="{{.Height}}" fill="#2471a3"><title>{{.Title}}</title></rect>
+                {{end}}
+            </svg>
+            <figcaption>Operations per latency bucket across the run, shortest first (largest bucket {{.Max}})</figcaption>
+        </figure>
+        {{end}}
+        <div class="table-wrapper">
+        <table>
+            <caption>Operations per latency bucket</caption>
+            <thead>
+                <tr>
+                    <th scope="col">Scope</th>
+                    <th scope="col">Total</th>
+                    {{range .LatencyBucketLabels}}
+                    <th scope="col">{{.}}</th>
+                    {{end}}
+                </tr>
+            </thead>
+            <tbody>
+                {{range .LatencyBucketRows}}
+                <tr>
+                    <th scope="row">{{.Scope}}</th>
+                    <td class="num">{{.Total}}</td>
+                    {{range .Counts}}
+                    <td class="num">{{.}}</td>
+                    {{end}}
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        </div>
+        {{if .DeadlineRows}}
+        <div class="table-wrapper">
+        <table>
+            <caption>Operations that exceeded their latency deadline</caption>
+            <thead>
+                <tr>
+                    <th scope="col">Deadline</th>
+                    <th scope="col">Scope</th>
+                    <th scope="col">Limit (ms)</th>
+                    <th scope="col">Operations</th>
+                    <th scope="col">Missed</th>
+                    <th scope="col">Miss Rate</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .DeadlineRows}}
+                <tr>
+                    <th scope="row">{{.Name}}</th>
+                    <td>{{.Scope}}</td>
+                    <td class="num">{{.DeadlineMs}}</td>
+                    <td class="num">{{.Operations}}</td>
+                    <td class="num">{{.Missed}}</td>
+                    <td class="num">{{.MissRate}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        </div>
+        {{end}}
+        </section>
+        {{end}}
+
+        {{if .HasSessionMetrics}}
+        <section aria-labelledby="session-heading">
+        <h2 id="session-heading">Session Metrics</h2>
+        <dl class="summary-grid">
+            <div class="summary-card session">
+                <dt>Session Mode</dt>
+                <dd>{{.SessionMode}}</dd>
+            </div>
+            <div class="summary-card session">
+                <dt>Total Sessions</dt>
+                <dd>{{.TotalSessions}}</dd>
+            </div>
+            <div class="summary-card session">
+                <dt>Ops/Session</dt>
+                <dd>{{.OpsPerSession}}</dd>
+            </div>
+            <div class="summary-card session">
+                <dt>Reuse Rate</dt>
+                <dd>{{.SessionReuseRate}}</dd>
+            </div>
+            {{if .SessionCreated}}
+            <div class="summary-card">
+                <dt>Sessions Created</dt>
+                <dd>{{.SessionCreated}}</dd>
+            </div>
+            {{end}}
+            {{if .SessionEvicted}}
+            <div class="summary-card">
+                <dt>Sessions Evicted</dt>
+                <dd>{{.SessionEvicted}}</dd>
+            </div>
+            {{end}}
+            {{if .SessionReconnects}}
+            <div class="summary-card">
+                <dt>Reconnects</dt>
+                <dd>{{.SessionReconnects}}</dd>
+            </div>
+            {{end}}
+        </dl>
+        </section>
+        {{end}}
+
+        {{if .HasWorkerHealth}}
+        <section aria-labelledby="health-heading">
+        <h2 id="health-heading">Runner Health</h2>
+        {{if .SaturationDetected}}
+        <div class="warning-banner" role="note">
+            <strong>Warning:</strong> Load generator saturation detected - {{.SaturationReason}}
+        </div>
+        {{end}}
+        <dl class="summary-grid">
+            <div class="summary-card{{if .SaturationDetected}} error{{end}}">
+                <dt>Peak CPU</dt>
+                <dd>{{.PeakCPUPercent}}</dd>
+            </div>
+            <div class="summary-card">
+                <dt>Peak Memory</dt>
+                <dd>{{.PeakMemoryMB}}</dd>
+            </div>
+            <div class="summary-card">
+                <dt>Avg Active VUs</dt>
+                <dd>{{.AvgActiveVUs}}</dd>
+            </div>
+            <div class="summary-card">
+                <dt>Worker Count</dt>
+                <dd>{{.WorkerCount}}</dd>
+            </div>
+            {{if .PeakShedRate}}
+            <div class="summary-card error">
+                <dt>Peak Load Shed</dt>
+                <dd>{{.PeakShedRate}}</dd>
+            </div>
+            {{end}}
+        </dl>
+        {{if .GeneratorWarningRows}}
+        <div class="table-wrapper">
+        <table>
+            <caption>Load generator warnings raised by workers short of resources</caption>
+            <thead>
+                <tr>
+                    <th scope="col">Time</th>
+                    <th scope="col">Worker</th>
+                    <th scope="col">Warning</th>
+                    <th scope="col">Resource</th>
+                    <th scope="col">Load Shed</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .GeneratorWarningRows}}
+                <tr>
+                    <td>{{.Time}}</td>
+                    <td>{{.WorkerID}}</td>
+                    <td>{{.Kind}}</td>
+                    <td>{{.Resource}}</td>
+                    <td class="num">{{.ShedRate}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        </div>
+        {{end}}
+        </section>
+        {{end}}
+
+        {{if .HasChurnMetrics}}
+        <section aria-labelledby="churn-heading">
+        <h2 id="churn-heading">Session Churn</h2>
+        <dl class="summary-grid">
+            <div class="summary-card session">
+                <dt>Sessions Created</dt>
+                <dd>{{.ChurnSessionsCreated}}</dd>
+            </div>
+            <div class="summary-card session">
+                <dt>Sessions Destroyed</dt>
+                <dd>{{.ChurnSessionsDestroyed}}</dd>
+            </div>
+            <div class="summary-card session">
+                <dt>Active Sessions</dt>
+                <dd>{{.ChurnActiveSessions}}</dd>
+            </div>
+            <div class="summary-card session">
+                <dt>Reconnect Attempts</dt>
+                <dd>{{.ChurnReconnectAttempts}}</dd>
+            </div>
+            <div class="summary-card">
+                <dt>Churn Rate (sessions/sec)</dt>
+                <dd>{{.ChurnRate}}</dd>
+            </div>
+        </dl>
+        </section>
+        {{end}}
+
+        {{if .HasConnections}}
+        <section aria-labelledby="connections-heading">
+        <h2 id="connections-heading">Connections</h2>
+        <dl class="summary-grid">
+            <div class="summary-card">
+                <dt>Connection Reuse</dt>
+                <dd>{{.ConnectionReuseRate}}</dd>
+            </div>
+            <div class="summary-card">
+                <dt>New Connections</dt>
+                <dd>{{.NewConnections}}</dd>
+            </div>
+            <div class="summary-card">
+                <dt>DNS Lookups</dt>
+                <dd>{{.DNSLookups}}</dd>
+            </div>
+            <div class="summary-card">
+                <dt>DNS Cache Hit Ratio</dt>
+                <dd>{{.DNSCacheHitRatio}}</dd>
+            </div>
+            <div class="summary-card{{if .DNSFailures}} error{{end}}">
+                <dt>DNS Failures</dt>
+                <dd>{{.DNSFailures}}</dd>
+            </div>
+            <div class="summary-card">
+                <dt>DNS P50</dt>
+                <dd>{{.DNSLookupP50}}</dd>
+            </div>
+            <div class="summary-card">
+                <dt>DNS P95</dt>
+                <dd>{{.DNSLookupP95}}</dd>
+            </div>
+            <div class="summary-card">
+                <dt>DNS Max</dt>
+                <dd>{{.DNSLookupMax}}</dd>
+            </div>
+        </dl>
+        </section>
+        {{end}}
+
+        {{if .HasRequestIDs}}
+        <section aria-labelledby="request-ids-heading">
+        <h2 id="request-ids-heading">Request ID Handling</h2>
+        <div class="table-wrapper">
+        <table>
+            <caption>Outcomes by JSON-RPC id variant</caption>
+            <thead>
+                <tr>
+                    <th scope="col">ID Kind</th>
+                    <th scope="col">Requests</th>
+                    <th scope="col">Failed</th>
+                    <th scope="col">Error Rate</th>
+                    <th scope="col">Echo Type Mismatches</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .RequestIDRows}}
+                <tr>
+                    <th scope="row">{{.Kind}}</th>
+                    <td class="num">{{.Requests}}</td>
+                    <td class="num">{{.Failures}}</td>
+                    <td class="num">{{.ErrorRate}}</td>
+                    <td class="num">{{.EchoTypeMismatches}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        </div>
+        {{if .RequestIDFindings}}
+        <h3>Compatibility Findings</h3>
+        <ul>
+            {{range .RequestIDFindings}}
+            <li>{{.}}</li>
+            {{end}}
+        </ul>
+        {{else}}
+        <p class="no-data">No id handling differences found</p>
+        {{end}}
+        </section>
+        {{end}}
+
+        {{if .HasChecks}}
+        <section aria-labelledby="checks-heading">
+        <h2 id="checks-heading">Response Checks</h2>
+        <p>{{.CheckFailedOps}} of {{.CheckedOps}} checked operations failed at least one check.</p>
+        <div class="table-wrapper">
+        <table>
+            <caption>Pass rate per check</caption>
+            <thead>
+                <tr>
+                    <th scope="col">Check</th>
+                    <th scope="col">Operation</th>
+                    <th scope="col">Evaluated</th>
+                    <th scope="col">Failed</th>
+                    <th scope="col">Pass Rate</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .CheckRows}}
+                <tr>
+                    <th scope="row">{{.Name}}</th>
+                    <td>{{.Target}}</td>
+                    <td class="num">{{.Total}}</td>
+                    <td class="num">{{.Failed}}</td>
+                    <td class="num">{{.PassRate}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        </div>
+        </section>
+        {{end}}
+
+        {{if .HasFuzz}}
+        <section aria-labelledby="fuzz-heading">
+        <h2 id="fuzz-heading">Malformed Input Handling</h2>
+        <div class="table-wrapper">
+        <table>
+            <caption>Outcomes of fuzzed tool calls by mutation</caption>
+            <thead>
+                <tr>
+                    <th scope="col">Mutation</th>
+                    <th scope="col">Requests</th>
+                    <th scope="col">Accepted</th>
+                    <th scope="col">Rejected</th>
+                    <th scope="col">Unhandled</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .FuzzRows}}
+                <tr>
+                    <th scope="row">{{.Mutation}}</th>
+                    <td class="num">{{.Requests}}</td>
+                    <td class="num">{{.Accepted}}</td>
+                    <td class="num">{{.Rejected}}</td>
+                    <td class="num">{{.Unhandled}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        </div>
+        {{if .FuzzFindings}}
+        <h3>Findings</h3>
+        <ul>
+            {{range .FuzzFindings}}
+            <li>{{.}}</li>
+            {{end}}
+        </ul>
+        {{else}}
+        <p class="no-data">No mishandled inputs found</p>
+        {{end}}
+        </section>
+        {{end}}
+
+        {{if .HasResumption}}
+        <section aria-labelledby="resumption-heading">
+        <h2 id="resumption-heading">Stream Resumption</h2>
+        <dl class="summary-grid">
+            <div class="summary-card">
+                <dt>Dropped Streams</dt>
+                <dd>{{.DroppedStreams}}</dd>
+            </div>
+            <div class="summary-card">
+                <dt>Resume Attempts</dt>
+                <dd>{{.ResumeAttempts}}</dd>
+            </div>
+            <div class="summary-card">
+                <dt>Resumed</dt>
+                <dd>{{.ResumedStreams}}</dd>
+            </div>
+            <div class="summary-card{{if .FailedResumes}} error{{end}}">
+                <dt>Failed to Resume</dt>
+                <dd>{{.FailedResumes}}</dd>
+            </div>
+            <div class="summary-card">
+                <dt>Resume Success Rate</dt>
+                <dd>{{.ResumeSuccessRate}}</dd>
+            </div>
+        </dl>
+        </section>
+        {{end}}
+
+        {{if .HasChaos}}
+        <section aria-labelledby="chaos-heading">
+        <h2 id="chaos-heading">Injected Network Faults</h2>
+        <p>Latencies in this report include the injected delay and bandwidth waits.</p>
+        <dl class="summary-grid">
+            <div class="summary-card">
+                <dt>Operations Affected</dt>
+                <dd>{{.ChaosOps}}</dd>
+            </div>
+            <div class="summary-card">
+                <dt>Avg Injected Delay</dt>
+                <dd>{{.ChaosAvgDelay}}</dd>
+            </div>
+            <div class="summary-card">
+                <dt>Connection Resets</dt>
+                <dd>{{.ChaosResets}}</dd>
+            </div>
+            <div class="summary-card">
+                <dt>Bandwidth Limited</dt>
+                <dd>{{.ChaosThrottled}} (avg {{.ChaosAvgThrottled}})</dd>
+            </div>
+            <div class="summary-card">
+                <dt>Error Rate Excluding Resets</dt>
+                <dd>{{.ChaosErrorRate}}</dd>
+            </div>
+        </dl>
+        </section>
+        {{end}}
+
+        {{if .HasCancellation}}
+        <section aria-labelledby="cancellation-heading">
+        <h2 id="cancellation-heading">Cancellation Handling</h2>
+        <dl class="summary-grid">
+            <div class="summary-card">
+                <dt>Cancellations Sent</dt>
+                <dd>{{.CancelsSent}}</dd>
+            </div>
+            <div class="summary-card success">
+                <dt>Honored</dt>
+                <dd>{{.CancelsHonored}}</dd>
+            </div>
+            <div class="summary-card{{if .CancelsCompletedAnyway}} error{{end}}">
+                <dt>Completed Anyway</dt>
+                <dd>{{.CancelsCompletedAnyway}}</dd>
+            </div>
+            <div class="summary-card">
+                <dt>Completed Before Cancel</dt>
+                <dd>{{.CancelsCompletedFirst}}</dd>
+            </div>
+            <div class="summary-card">
+                <dt>Honor Rate</dt>
+                <dd>{{.CancelHonorRate}}</dd>
+            </div>
+        </dl>
+        </section>
+        {{end}}
+
+        {{if .HasThrottling}}
+        <section aria-labelledby="throttling-heading">
+        <h2 id="throttling-heading">Rate Limiting</h2>
+        <dl class="summary-grid">
+            <div class="summary-card error">
+                <dt>Rate Limited</dt>
+                <dd>{{.Throttled}} ({{.ThrottleRate}})</dd>
+            </div>
+            <div class="summary-card">
+                <dt>With Retry-After</dt>
+                <dd>{{.ThrottleWithRetryAfter}}</dd>
+            </div>
+            <div class="summary-card">
+                <dt>Mean Retry-After</dt>
+                <dd>{{.MeanRetryAfter}}</dd>
+            </div>
+            <div class="summary-card">
+                <dt>Operations Held Back</dt>
+                <dd>{{.ThrottleHeldBack}}</dd>
+            </div>
+            <div class="summary-card">
+                <dt>Time Held Back</dt>
+                <dd>{{.ThrottleWait}}</dd>
+            </div>
+            <div class="summary-card success">
+                <dt>Effective RPS</dt>
+                <dd>{{.EffectiveRPS}}</dd>
+            </div>
+        </dl>
+        </section>
+        {{end}}
+
+        {{if .HasPacing}}
+        <section aria-labelledby="pacing-heading">
+        <h2 id="pacing-heading">Pacing</h2>
+        <dl class="summary-grid">
+            <div class="summary-card">
+                <dt>Operations Followed by a Pause</dt>
+                <dd>{{.PacedOperations}}</dd>
+            </div>
+            <div class="summary-card">
+                <dt>Mean Think Time</dt>
+                <dd>{{.MeanThinkTime}}</dd>
+            </div>
+            <div class="summary-card">
+                <dt>Longest Think Time</dt>
+                <dd>{{.MaxThinkTime}}</dd>
+            </div>
+            <div class="summary-card">
+                <dt>Total Think Time</dt>
+                <dd>{{.TotalThinkTime}}</dd>
+            </div>
+        </dl>
+        {{if .PacingRows}}
+        <div class="table-wrapper">
+        <table>
+            <caption>Think time VUs paused for after calling each tool; the mean is over all calls, paused or not</caption>
+            <thead>
+                <tr>
+                    <th scope="col">Tool</th>
+                    <th scope="col">Calls</th>
+                    <th scope="col">Paused</th>
+                    <th scope="col">Mean Think Time</th>
+                    <th scope="col">Longest Think Time</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .PacingRows}}
+                <tr>
+                    <th scope="row">{{.Tool}}</th>
+                    <td class="num">{{.Calls}}</td>
+                    <td class="num">{{.Paused}}</td>
+                    <td class="num">{{.MeanThink}}</td>
+                    <td class="num">{{.MaxThink}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        </div>
+        {{end}}
+        </section>
+        {{end}}
+
+        {{if .WorkflowRows}}
+        <section aria-labelledby="workflows-heading">
+        <h2 id="workflows-heading">Workflows</h2>
+        <div class="table-wrapper">
+        <table>
+            <caption>Workflow runs and how they ended; runs still going when the run ended are counted as runs only</caption>
+            <thead>
+                <tr>
+                    <th scope="col">Workflow</th>
+                    <th scope="col">Runs</th>
+                    <th scope="col">Completed</th>
+                    <th scope="col">Failed</th>
+                    <th scope="col">Extraction Failed</th>
+                    <th scope="col">Completion Rate</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .WorkflowRows}}
+                <tr>
+                    <th scope="row">{{.Name}}</th>
+                    <td class="num">{{.Runs}}</td>
+                    <td class="num">{{.Completed}}</td>
+                    <td class="num">{{.Failed}}</td>
+                    <td class="num">{{.ExtractFailed}}</td>
+                    <td class="num">{{.CompletionRate}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        </div>
+        <div class="table-wrapper">
+        <table>
+            <caption>Operations of each workflow step, and the runs that stopped at the step</caption>
+            <thead>
+                <tr>
+                    <th scope="col">Workflow</th>
+                    <th scope="col">Step</th>
+                    <th scope="col">Operations</th>
+                    <th scope="col">Error Rate</th>
+                    <th scope="col">P50 (ms)</th>
+                    <th scope="col">P95 (ms)</th>
+                    <th scope="col">Runs Stopped</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .WorkflowStepRows}}
+                <tr>
+                    <td>{{.Workflow}}</td>
+                    <th scope="row">{{.Step}}</th>
+                    <td class="num">{{.TotalOps}}</td>
+                    <td class="num">{{.ErrorRate}}</td>
+                    <td class="num">{{.LatencyP50}}</td>
+                    <td class="num">{{.LatencyP95}}</td>
+                    <td class="num">{{.RunsStopped}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
         </div>
+        </section>
+        {{end}}
 
-        <h2>Summary</h2>
-        <div class="summary-grid">
+        {{if .HasRetries}}
+        <section aria-labelledby="retries-heading">
+        <h2 id="retries-heading">Retries</h2>
+        <dl class="summary-grid">
             <div class="summary-card">
-                <label>Total Operations</label>
-                <div class="value">{{.TotalOps}}</div>
+                <dt>Operations</dt>
+                <dd>{{.RetriedOperations}}</dd>
             </div>
-            <div class="summary-card success">
-                <label>Successful</label>
-                <div class="value">{{.SuccessOps}}</div>
+            <div class="summary-card">
+                <dt>Retries</dt>
+                <dd>{{.RetryAttempts}}</dd>
             </div>
-            <div class="summary-card error">
-                <label>Failed</label>
-                <div class="value">{{.FailureOps}}</div>
+            <div class="summary-card success">
+                <dt>Recovered by Retry</dt>
+                <dd>{{.RecoveredByRetry}}</dd>
             </div>
             <div class="summary-card">
-                <label>Requests/Second</label>
-                <div class="value">{{.RPS}}</div>
+                <dt>First-Attempt Success</dt>
+                <dd>{{.FirstAttemptSuccessRate}}</dd>
             </div>
-            <div class="summary-card error">
-                <label>Error Rate</label>
-                <div class="value">{{.ErrorRate}}</div>
+            <div class="summary-card">
+                <dt>Eventual Success</dt>
+                <dd>{{.EventualSuccessRate}}</dd>
             </div>
+        </dl>
+        <div class="table-wrapper">
+        <table>
+            <caption>Outcomes by attempt</caption>
+            <thead>
+                <tr>
+                    <th scope="col">Attempt</th>
+                    <th scope="col">Operations</th>
+                    <th scope="col">Successes</th>
+                    <th scope="col">Success Rate</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .AttemptRows}}
+                <tr>
+                    <th scope="row">{{.Attempt}}</th>
+                    <td class="num">{{.Operations}}</td>
+                    <td class="num">{{.Successes}}</td>
+                    <td class="num">{{.SuccessRate}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
         </div>
+        </section>
+        {{end}}
 
-        <h2>Latency Percentiles</h2>
-        <div class="latency-section">
-            <div class="latency-card">
-                <label>P50 (Median)</label>
-                <div class="value">{{.LatencyP50}}<span class="unit">ms</span></div>
-            </div>
-            <div class="latency-card">
-                <label>P95</label>
-                <div class="value">{{.LatencyP95}}<span class="unit">ms</span></div>
-            </div>
-            <div class="latency-card">
-                <label>P99</label>
-                <div class="value">{{.LatencyP99}}<span class="unit">ms</span></div>
-            </div>
+        {{if .HasShadow}}
+        <section aria-labelledby="shadow-heading">
+        <h2 id="shadow-heading">Shadow Comparison</h2>
+        <div class="table-wrapper">
+        <table>
+            <caption>Target and shadow answers to the same operations</caption>
+            <thead>
+                <tr>
+                    <th scope="col">Operation</th>
+                    <th scope="col">Operations</th>
+                    <th scope="col">Target Errors</th>
+                    <th scope="col">Shadow Errors</th>
+                    <th scope="col">Target P95 (ms)</th>
+                    <th scope="col">Shadow P95 (ms)</th>
+                    <th scope="col">Mean Latency Delta</th>
+                    <th scope="col">Result Mismatches</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .ShadowRows}}
+                <tr>
+                    <th scope="row">{{.Name}}</th>
+                    <td class="num">{{.Operations}}</td>
+                    <td class="num">{{.TargetErrors}}</td>
+                    <td class="num">{{.ShadowErrors}}</td>
+                    <td class="num">{{.TargetP95}}</td>
+                    <td class="num">{{.ShadowP95}}</td>
+                    <td class="num">{{.Delta}}</td>
+                    <td class="num">{{.Mismatches}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
         </div>
+        {{if .ShadowFindings}}
+        <h3>Findings</h3>
+        <ul>
+            {{range .ShadowFindings}}
+            <li>{{.}}</li>
+            {{end}}
+        </ul>
+        {{else}}
+        <p class="no-data">No regressions found on the shadow</p>
+        {{end}}
+        </section>
+        {{end}}
 
-        {{if .HasSessionMetrics}}
-        <h2>Session Metrics</h2>
-        <div class="summary-grid">
-            <div class="summary-card session">
-                <label>Session Mode</label>
-                <div class="value">{{.SessionMode}}</div>
-            </div>
-            <div class="summary-card session">
-                <label>Total Sessions</label>
-                <div class="value">{{.TotalSessions}}</div>
-            </div>
-            <div class="summary-card session">
-                <label>Ops/Session</label>
-                <div class="value">{{.OpsPerSession}}</div>
-            </div>
-            <div class="summary-card session">
-                <label>Reuse Rate</label>
-                <div class="value">{{.SessionReuseRate}}</div>
-            </div>
-            {{if .SessionCreated}}
+        {{if .HasStreaming}}
+        <section aria-labelledby="streaming-heading">
+        <h2 id="streaming-heading">Streaming by Tool</h2>
+        <div class="table-wrapper">
+        <table>
+            <caption>First-event latency, event gaps and progress notifications of streamed tool calls</caption>
+            <thead>
+                <tr>
+                    <th scope="col">Tool</th>
+                    <th scope="col">Streams</th>
+                    <th scope="col">First Event P50 (ms)</th>
+                    <th scope="col">First Event P95 (ms)</th>
+                    <th scope="col">Max Gap P95 (ms)</th>
+                    <th scope="col">Longest Gap (ms)</th>
+                    <th scope="col">Progress</th>
+                    <th scope="col">Bad Progress</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .StreamingRows}}
+                <tr>
+                    <th scope="row">{{.Tool}}</th>
+                    <td class="num">{{.Streams}}</td>
+                    <td class="num">{{.FirstEventP50}}</td>
+                    <td class="num">{{.FirstEventP95}}</td>
+                    <td class="num">{{.MaxGapP95}}</td>
+                    <td class="num">{{.MaxGap}}</td>
+                    <td class="num">{{.Progress}}</td>
+                    <td class="num">{{.BadProgress}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        </div>
+        </section>
+        {{end}}
+
+        {{if .HasServerMessages}}
+        <section aria-labelledby="server-messages-heading">
+        <h2 id="server-messages-heading">Server-Initiated Messages</h2>
+        <dl class="summary-grid">
             <div class="summary-card">
-                <label>Sessions Created</label>
-                <div class="value">{{.SessionCreated}}</div>
+                <dt>Requests</dt>
+                <dd>{{.ServerRequests}}</dd>
             </div>
-            {{end}}
-            {{if .SessionEvicted}}
             <div class="summary-card">
-                <label>Sessions Evicted</label>
-                <div class="value">{{.SessionEvicted}}</div>
+                <dt>Notifications</dt>
+                <dd>{{.ServerNotifications}}</dd>
             </div>
-            {{end}}
-            {{if .SessionReconnects}}
             <div class="summary-card">
-                <label>Reconnects</label>
-                <div class="value">{{.SessionReconnects}}</div>
+                <dt>Answered</dt>
+                <dd>{{.ServerReplies}}</dd>
             </div>
-            {{end}}
+            <div class="summary-card{{if .ServerReplyErrors}} error{{end}}">
+                <dt>Answers Refused</dt>
+                <dd>{{.ServerReplyErrors}}</dd>
+            </div>
+        </dl>
+        <div class="table-wrapper">
+        <table>
+            <caption>Messages the server sent on response streams by method</caption>
+            <thead>
+                <tr>
+                    <th scope="col">Method</th>
+                    <th scope="col">Kind</th>
+                    <th scope="col">Count</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .ServerMessageRows}}
+                <tr>
+                    <th scope="row">{{.Method}}</th>
+                    <td>{{.Kind}}</td>
+                    <td class="num">{{.Count}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
         </div>
+        </section>
         {{end}}
 
-        {{if .HasWorkerHealth}}
-        <h2>Runner Health</h2>
-        {{if .SaturationDetected}}
-        <div class="warning-banner">
-            <strong>Warning:</strong> Load generator saturation detected - {{.SaturationReason}}
+        <section aria-labelledby="operations-heading">
+        <h2 id="operations-heading">Operations Breakdown</h2>
+        {{if .HasOperations}}
+        <div class="table-wrapper">
+        <table class="sortable">
+            <caption>Per-operation totals and latency percentiles</caption>
+            <thead>
+                <tr>
+                    <th scope="col">Operation</th>
+                    <th scope="col">Total</th>
+                    <th scope="col">Success</th>
+                    <th scope="col">Failed</th>
+                    <th scope="col">Error Rate</th>
+                    <th scope="col">P50 (ms)</th>
+                    <th scope="col">P95 (ms)</th>
+                    <th scope="col">P99 (ms)</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .Operations}}
+                <tr>
+                    <th scope="row">{{.Name}}</th>
+                    <td class="num">{{.TotalOps}}</td>
+                    <td class="num">{{.SuccessOps}}</td>
+                    <td class="num">{{.FailureOps}}</td>
+                    <td class="num">{{.ErrorRate}}</td>
+                    <td class="num">{{.LatencyP50}}</td>
+                    <td class="num">{{.LatencyP95}}</td>
+                    <td class="num">{{.LatencyP99}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
         </div>
+        {{else}}
+        <p class="no-data">No operation data available</p>
         {{end}}
-        <div class="summary-grid">
-            <div class="summary-card{{if .SaturationDetected}} error{{end}}">
-                <label>Peak CPU</label>
-                <div class="value">{{.PeakCPUPercent}}</div>
+        </section>
+
+        <section aria-labelledby="tools-heading">
+        <h2 id="tools-heading">Tools Breakdown</h2>
+        {{if .HasTools}}
+        <div class="table-wrapper">
+        <table class="sortable">
+            <caption>Per-tool totals and latency percentiles</caption>
+            <thead>
+                <tr>
+                    <th scope="col">Tool</th>
+                    <th scope="col">Total</th>
+                    <th scope="col">Success</th>
+                    <th scope="col">Failed</th>
+                    <th scope="col">Error Rate</th>
+                    <th scope="col">P50 (ms)</th>
+                    <th scope="col">P95 (ms)</th>
+                    <th scope="col">P99 (ms)</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .Tools}}
+                <tr>
+                    <th scope="row">{{.Name}}</th>
+                    <td class="num">{{.TotalOps}}</td>
+                    <td class="num">{{.SuccessOps}}</td>
+                    <td class="num">{{.FailureOps}}</td>
+                    <td class="num">{{.ErrorRate}}</td>
+                    <td class="num">{{.LatencyP50}}</td>
+                    <td class="num">{{.LatencyP95}}</td>
+                    <td class="num">{{.LatencyP99}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        </div>
+        {{else}}
+        <p class="no-data">No tool data available</p>
+        {{end}}
+        </section>
+
+        {{if .ErrorSignatures}}
+        <section aria-labelledby="error-signatures-heading">
+        <h2 id="error-signatures-heading">Error Signatures</h2>
+        <div class="table-wrapper">
+        <table class="sortable">
+            <caption>Most frequent failures, grouped by normalized error message and code</caption>
+            <thead>
+                <tr>
+                    <th scope="col">Signature</th>
+                    <th scope="col">Classification</th>
+                    <th scope="col">Count</th>
+                    <th scope="col">Share of Failures</th>
+                    <th scope="col">First Seen</th>
+                    <th scope="col">Last Seen</th>
+                    <th scope="col">Tools</th>
+                    <th scope="col">Example Operations</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .ErrorSignatures}}
+                <tr>
+                    <th scope="row">{{if .Sample}}<details><summary>{{.Pattern}}</summary><pre class="log-sample">{{.Sample}}</pre></details>{{else}}{{.Pattern}}{{end}}</th>
+                    <td>{{.Classification}}</td>
+                    <td class="num">{{.Count}}</td>
+                    <td class="num">{{.Share}}</td>
+                    <td><time datetime="{{.FirstSeen}}">{{.FirstSeen}}</time></td>
+                    <td><time datetime="{{.LastSeen}}">{{.LastSeen}}</time></td>
+                    <td>{{.Tools}}</td>
+                    <td>{{.ExampleOps}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        </div>
+        </section>
+        {{end}}
+
+        {{if .HasGroups}}
+        <section aria-labelledby="groups-heading">
+        <h2 id="groups-heading">Generator Groups</h2>
+        <div class="table-wrapper">
+        <table class="sortable">
+            <caption>Per-generator-group totals and latency percentiles</caption>
+            <thead>
+                <tr>
+                    <th scope="col">Group</th>
+                    <th scope="col">Total</th>
+                    <th scope="col">Success</th>
+                    <th scope="col">Failed</th>
+                    <th scope="col">Error Rate</th>
+                    <th scope="col">P50 (ms)</th>
+                    <th scope="col">P95 (ms)</th>
+                    <th scope="col">P99 (ms)</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .Groups}}
+                <tr>
+                    <th scope="row">{{.Name}}</th>
+                    <td class="num">{{.TotalOps}}</td>
+                    <td class="num">{{.SuccessOps}}</td>
+                    <td class="num">{{.FailureOps}}</td>
+                    <td class="num">{{.ErrorRate}}</td>
+                    <td class="num">{{.LatencyP50}}</td>
+                    <td class="num">{{.LatencyP95}}</td>
+                    <td class="num">{{.LatencyP99}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        </div>
+        </section>
+        {{end}}
+
+        {{if .HasTargets}}
+        <section aria-labelledby="targets-heading">
+        <h2 id="targets-heading">Targets</h2>
+        <div class="table-wrapper">
+        <table class="sortable">
+            <caption>Per-target totals and latency percentiles</caption>
+            <thead>
+                <tr>
+                    <th scope="col">Target</th>
+                    <th scope="col">Total</th>
+                    <th scope="col">Success</th>
+                    <th scope="col">Failed</th>
+                    <th scope="col">Error Rate</th>
+                    <th scope="col">P50 (ms)</th>
+                    <th scope="col">P95 (ms)</th>
+                    <th scope="col">P99 (ms)</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .Targets}}
+                <tr>
+                    <th scope="row">{{.Name}}</th>
+                    <td class="num">{{.TotalOps}}</td>
+                    <td class="num">{{.SuccessOps}}</td>
+                    <td class="num">{{.FailureOps}}</td>
+                    <td class="num">{{.ErrorRate}}</td>
+                    <td class="num">{{.LatencyP50}}</td>
+                    <td class="num">{{.LatencyP95}}</td>
+                    <td class="num">{{.LatencyP99}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        </div>
+        </section>
+        {{end}}
+
+        {{if .HasWorkerLabels}}
+        <section aria-labelledby="worker-labels-heading">
+        <h2 id="worker-labels-heading">Worker Labels</h2>
+        <div class="table-wrapper">
+        <table class="sortable">
+            <caption>Per-worker-label totals and latency percentiles</caption>
+            <thead>
+                <tr>
+                    <th scope="col">Label</th>
+                    <th scope="col">Total</th>
+                    <th scope="col">Success</th>
+                    <th scope="col">Failed</th>
+                    <th scope="col">Error Rate</th>
+                    <th scope="col">P50 (ms)</th>
+                    <th scope="col">P95 (ms)</th>
+                    <th scope="col">P99 (ms)</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .WorkerLabels}}
+                <tr>
+                    <th scope="row">{{.Name}}</th>
+                    <td class="num">{{.TotalOps}}</td>
+                    <td class="num">{{.SuccessOps}}</td>
+                    <td class="num">{{.FailureOps}}</td>
+                    <td class="num">{{.ErrorRate}}</td>
+                    <td class="num">{{.LatencyP50}}</td>
+                    <td class="num">{{.LatencyP95}}</td>
+                    <td class="num">{{.LatencyP99}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        </div>
+        </section>
+        {{end}}
+
+        {{if .HasServerMetrics}}
+        <section aria-labelledby="server-metrics-heading">
+        <h2 id="server-metrics-heading">Server Resources</h2>
+        <dl class="summary-grid">
+            <div class="summary-card">
+                <dt>Nodes</dt>
+                <dd>{{.ClusterNodes}}</dd>
             </div>
             <div class="summary-card">
-                <label>Peak Memory</label>
-                <div class="value">{{.PeakMemoryMB}}</div>
+                <dt>Avg Cores Used</dt>
+                <dd>{{.ClusterCoresAvg}}</dd>
             </div>
             <div class="summary-card">
-                <label>Avg Active VUs</label>
-                <div class="value">{{.AvgActiveVUs}}</div>
+                <dt>Peak Cores Used</dt>
+                <dd>{{.ClusterCoresPeak}}</dd>
             </div>
             <div class="summary-card">
-                <label>Worker Count</label>
-                <div class="value">{{.WorkerCount}}</div>
+                <dt>Peak Node Memory</dt>
+                <dd>{{.ClusterNodeMemPeak}}{{if .ClusterNodeMemPeakHost}} ({{.ClusterNodeMemPeakHost}}){{end}}</dd>
             </div>
+        </dl>
+        {{if .ServerClockSkews}}
+        <div class="warning-banner" role="note">
+            <strong>Clock skew:</strong> some agents' clocks were off the control plane's, so their samples were moved onto its clock and may be misaligned with operations by up to the agent's network latency.
+            <ul>
+                {{range .ServerClockSkews}}<li>{{.}}</li>{{end}}
+            </ul>
         </div>
         {{end}}
-
-        {{if .HasChurnMetrics}}
-        <h2>Session Churn</h2>
-        <div class="summary-grid">
-            <div class="summary-card session">
-                <label>Sessions Created</label>
-                <div class="value">{{.ChurnSessionsCreated}}</div>
-            </div>
-            <div class="summary-card session">
-                <label>Sessions Destroyed</label>
-                <div class="value">{{.ChurnSessionsDestroyed}}</div>
-            </div>
-            <div class="summary-card session">
-                <label>Active Sessions</label>
-                <div class="value">{{.ChurnActiveSessions}}</div>
-            </div>
-            <div class="summary-card session">
-                <label>Reconnect Attempts</label>
-                <div class="value">{{.ChurnReconnectAttempts}}</div>
-            </div>
-            <div class="summary-card">
-                <label>Churn Rate (sessions/sec)</label>
-                <div class="value">{{.ChurnRate}}</div>
-            </div>
+        {{if .ServerSampleGaps}}
+        <div class="warning-banner" role="note">
+            <strong>Missing samples:</strong> some agents could not deliver every sample, usually because the control plane was unreachable for longer than their retry buffer covers, so server metrics have gaps.
+            <ul>
+                {{range .ServerSampleGaps}}<li>{{.}}</li>{{end}}
+            </ul>
         </div>
         {{end}}
-
-        <h2>Operations Breakdown</h2>
-        {{if .HasOperations}}
+        {{with .ClusterCoresChart}}
+        <figure class="chart">
+            <svg viewBox="0 0 600 120" preserveAspectRatio="none" role="img" aria-label="Cluster cores used over time, peak {{.Max}}">
+                <polyline fill="none" stroke="#2c3e50" stroke-width="2" vector-effect="non-scaling-stroke" points="{{.Points}}"/>
+            </svg>
+            <figcaption>Cluster cores used from <time datetime="{{.Start}}">{{.Start}}</time> to <time datetime="{{.End}}">{{.End}}</time> (peak {{.Max}})</figcaption>
+        </figure>
+        {{end}}
+        <div class="table-wrapper">
         <table>
+            <caption>Per-node host usage; the cluster row sums cores in use across nodes and takes the highest per-node memory</caption>
             <thead>
                 <tr>
-                    <th>Operation</th>
-                    <th>Total</th>
-                    <th>Success</th>
-                    <th>Failed</th>
-                    <th>Error Rate</th>
-                    <th>P50 (ms)</th>
-                    <th>P95 (ms)</th>
-                    <th>P99 (ms)</th>
+                    <th scope="col">Node</th>
+                    <th scope="col">Samples</th>
+                    <th scope="col">Cores</th>
+                    <th scope="col">CPU Avg</th>
+                    <th scope="col">CPU Max</th>
+                    <th scope="col">Cores Used Avg</th>
+                    <th scope="col">Cores Used Max</th>
+                    <th scope="col">Memory Used Max</th>
                 </tr>
             </thead>
             <tbody>
-                {{range .Operations}}
+                {{range .ServerNodes}}
                 <tr>
-                    <td>{{.Name}}</td>
-                    <td>{{.TotalOps}}</td>
-                    <td>{{.SuccessOps}}</td>
-                    <td>{{.FailureOps}}</td>
-                    <td>{{.ErrorRate}}</td>
-                    <td>{{.LatencyP50}}</td>
-                    <td>{{.LatencyP95}}</td>
-                    <td>{{.LatencyP99}}</td>
+                    <th scope="row">{{.Host}}</th>
+                    <td class="num">{{.Samples}}</td>
+                    <td class="num">{{.Cores}}</td>
+                    <td class="num">{{.CPUAvg}}</td>
+                    <td class="num">{{.CPUMax}}</td>
+                    <td class="num">{{.CoresAvg}}</td>
+                    <td class="num">{{.CoresMax}}</td>
+                    <td class="num">{{.MemUsedMax}}</td>
                 </tr>
                 {{end}}
             </tbody>
+            <tfoot>
+                <tr>
+                    <th scope="row">Cluster</th>
+                    <td class="num"></td>
+                    <td class="num">{{.ClusterTotalCores}}</td>
+                    <td class="num"></td>
+                    <td class="num"></td>
+                    <td class="num">{{.ClusterCoresAvg}}</td>
+                    <td class="num">{{.ClusterCoresPeak}}</td>
+                    <td class="num">{{.ClusterNodeMemPeak}}</td>
+                </tr>
+            </tfoot>
         </table>
-        {{else}}
-        <div class="no-data">No operation data available</div>
+        </div>
+        {{if .ServerContainers}}
+        {{if .ServerOOMKills}}
+        <div class="warning-banner" role="note">
+            <strong>Out of memory:</strong> the kernel killed {{.ServerOOMKills}} process(es) in the server's containers for exceeding their memory limit during the run.
+        </div>
+        {{end}}
+        <div class="table-wrapper">
+        <table>
+            <caption>Per-node container usage against cgroup limits; throttled is the share of CPU scheduler periods in which the container hit its quota</caption>
+            <thead>
+                <tr>
+                    <th scope="col">Node</th>
+                    <th scope="col">CPU Limit</th>
+                    <th scope="col">Cores Used Max</th>
+                    <th scope="col">Throttled Avg</th>
+                    <th scope="col">Throttled Max</th>
+                    <th scope="col">Memory Max</th>
+                    <th scope="col">Memory Limit</th>
+                    <th scope="col">OOM Kills</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .ServerContainers}}
+                <tr>
+                    <th scope="row">{{.Host}}</th>
+                    <td class="num">{{.CPULimit}}</td>
+                    <td class="num">{{.CoresMax}}</td>
+                    <td class="num">{{.ThrottledAvg}}</td>
+                    <td class="num">{{.ThrottledMax}}</td>
+                    <td class="num">{{.MemMax}}</td>
+                    <td class="num">{{.MemLimit}}</td>
+                    <td class="num">{{.OOMKills}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        </div>
+        {{end}}
+        {{if .ServerGPUs}}
+        <div class="table-wrapper">
+        <table>
+            <caption>Per-node NVIDIA GPU usage; utilization is averaged across each node's GPUs and memory summed over them</caption>
+            <thead>
+                <tr>
+                    <th scope="col">Node</th>
+                    <th scope="col">GPUs</th>
+                    <th scope="col">Utilization Avg</th>
+                    <th scope="col">Utilization Max</th>
+                    <th scope="col">Memory Used Max</th>
+                    <th scope="col">Server Process Memory Max</th>
+                    <th scope="col">Temperature Max</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .ServerGPUs}}
+                <tr>
+                    <th scope="row">{{.Host}}</th>
+                    <td class="num">{{.GPUs}}</td>
+                    <td class="num">{{.UtilAvg}}</td>
+                    <td class="num">{{.UtilMax}}</td>
+                    <td class="num">{{.MemMax}}</td>
+                    <td class="num">{{.ProcessMemMax}}</td>
+                    <td class="num">{{.TempMax}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        </div>
+        {{end}}
+        {{if .ServerScraped}}
+        <h3>Server-Reported Metrics</h3>
+        <div class="table-wrapper">
+        <table>
+            <caption>Series scraped from the server's own metrics endpoint; histograms and summaries show observations per second and their mean observation</caption>
+            <thead>
+                <tr>
+                    <th scope="col">Node</th>
+                    <th scope="col">Series</th>
+                    <th scope="col">Type</th>
+                    <th scope="col">Last</th>
+                    <th scope="col">Min</th>
+                    <th scope="col">Avg</th>
+                    <th scope="col">Max</th>
+                    <th scope="col">Rate/s</th>
+                    <th scope="col">Mean</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .ServerScraped}}
+                <tr>
+                    <th scope="row">{{.Host}}</th>
+                    <td><code>{{.Series}}</code></td>
+                    <td>{{.Type}}</td>
+                    <td class="num">{{.Last}}</td>
+                    <td class="num">{{.Min}}</td>
+                    <td class="num">{{.Avg}}</td>
+                    <td class="num">{{.Max}}</td>
+                    <td class="num">{{.Rate}}</td>
+                    <td class="num">{{.Mean}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        </div>
+        {{end}}
+        {{range .ServerNodeCharts}}
+        <h3>{{.Host}} Against Load</h3>
+        {{range .Charts}}
+        <figure class="chart">
+            <svg viewBox="0 0 600 120" preserveAspectRatio="none" role="img" aria-label="{{.Title}} over time, peak {{.Max}}, against load peaking at {{.LoadMax}}">
+                <polyline fill="none" stroke="#95a5a6" stroke-width="1" stroke-dasharray="4 3" vector-effect="non-scaling-stroke" points="{{.Load}}"/>
+                <polyline fill="none" stroke="#2c3e50" stroke-width="2" vector-effect="non-scaling-stroke" points="{{.Points}}"/>
+            </svg>
+            <figcaption>{{.Title}} (solid, peak {{.Max}}) and client load (dashed, peak {{.LoadMax}}) from <time datetime="{{.Start}}">{{.Start}}</time> to <time datetime="{{.End}}">{{.End}}</time></figcaption>
+        </figure>
+        {{end}}
+        {{end}}
+        </section>
         {{end}}
 
-        <h2>Tools Breakdown</h2>
-        {{if .HasTools}}
+        {{if .ServerBottlenecks}}
+        <section aria-labelledby="server-bottlenecks-heading">
+        <h2 id="server-bottlenecks-heading">Server-Side Bottlenecks</h2>
+        <div class="warning-banner" role="note">
+            <strong>CPU saturation:</strong> these nodes ran above 90% CPU, of the host or of their container's limit, while p95 latency rose to at least 1.5 times its level the rest of the run. Adding CPU to them is likely to raise the throughput the server can sustain.
+        </div>
+        <div class="table-wrapper">
         <table>
+            <caption>Stretches of CPU saturation with the p95 latency clients saw during them and while the node was not saturated</caption>
             <thead>
                 <tr>
-                    <th>Tool</th>
-                    <th>Total</th>
-                    <th>Success</th>
-                    <th>Failed</th>
-                    <th>Error Rate</th>
-                    <th>P50 (ms)</th>
-                    <th>P95 (ms)</th>
-                    <th>P99 (ms)</th>
+                    <th scope="col">Node</th>
+                    <th scope="col">From</th>
+                    <th scope="col">To</th>
+                    <th scope="col">Peak CPU</th>
+                    <th scope="col">P95 Baseline</th>
+                    <th scope="col">P95 Peak</th>
                 </tr>
             </thead>
             <tbody>
-                {{range .Tools}}
+                {{range .ServerBottlenecks}}
                 <tr>
-                    <td>{{.Name}}</td>
-                    <td>{{.TotalOps}}</td>
-                    <td>{{.SuccessOps}}</td>
-                    <td>{{.FailureOps}}</td>
-                    <td>{{.ErrorRate}}</td>
-                    <td>{{.LatencyP50}}</td>
-                    <td>{{.LatencyP95}}</td>
-                    <td>{{.LatencyP99}}</td>
+                    <th scope="row">{{.Host}}</th>
+                    <td><time datetime="{{.Start}}">{{.Start}}</time></td>
+                    <td><time datetime="{{.End}}">{{.End}}</time></td>
+                    <td class="num">{{.PeakCPU}}</td>
+                    <td class="num">{{.BaselineP95}}</td>
+                    <td class="num">{{.PeakP95}}</td>
                 </tr>
                 {{end}}
             </tbody>
         </table>
+        </div>
+        </section>
+        {{end}}
+
+        {{if .HasServerLogs}}
+        <section aria-labelledby="server-logs-heading">
+        <h2 id="server-logs-heading">Server Logs</h2>
+        <dl class="summary-grid">
+            <div class="summary-card">
+                <dt>Log Entries</dt>
+                <dd>{{.ServerLogEntries}}</dd>
+            </div>
+            <div class="summary-card{{if .ServerLogErrorEntries}} error{{end}}">
+                <dt>Error Entries</dt>
+                <dd>{{.ServerLogErrorEntries}}</dd>
+            </div>
+        </dl>
+        {{if .ServerLogSpikes}}
+        {{range .ServerLogSpikes}}
+        <h3>Client errors spiked from <time datetime="{{.Start}}">{{.Start}}</time> to <time datetime="{{.End}}">{{.End}}</time></h3>
+        <p>{{.ClientErrors}} client errors; the server logged {{.ServerErrors}} errors from 5s before.</p>
+        {{range .Signatures}}
+        <details>
+            <summary>{{.Count}} &times; {{.Signature}} ({{.Host}})</summary>
+            <pre class="log-sample">{{.Sample}}</pre>
+        </details>
+        {{end}}
+        {{end}}
         {{else}}
-        <div class="no-data">No tool data available</div>
+        <p>No spikes in client errors to correlate with the server's logs.</p>
+        {{end}}
+        </section>
         {{end}}
+        </main>
 
         <footer>
-            Generated by MCP Drill at {{.GeneratedAt}}
+            Generated by MCP Drill at <time datetime="{{.GeneratedAt}}">{{.GeneratedAt}}</time>
         </footer>
     </div>
+    <script type="application/json" id="report-data">{{.ReportData}}</script>
+    <script>
+    (function () {
+        // Progressive enhancement: make table columns sortable. Without JS the
+        // tables above render as plain static tables in server-side order.
+        var tables = document.querySelectorAll('table.sortable');
+        Array.prototype.forEach.call(tables, function (table) {
+            var headers = table.querySelectorAll('thead th');
+            Array.prototype.forEach.call(headers, function (th, col) {
+                var button = document.createElement('button');
+                button.type = 'button';
+                button.textContent = th.textContent;
+                th.textContent = '';
+                th.appendChild(button);
+                button.addEventListener('click', function () {
+                    var ascending = th.getAttribute('aria-sort') !== 'ascending';
+                    Array.prototype.forEach.call(headers, function (h) { h.removeAttribute('aria-sort'); });
+                    th.setAttribute('aria-sort', ascending ? 'ascending' : 'descending');
+                    var tbody = table.tBodies[0];
+                    var rows = Array.prototype.slice.call(tbody.rows);
+                    rows.sort(function (a, b) {
+                        var x = a.cells[col].textContent.trim();
+                        var y = b.cells[col].textContent.trim();
+                        var nx = parseFloat(x), ny = parseFloat(y);
+                        var cmp = (!isNaN(nx) && !isNaN(ny)) ? nx - ny : x.localeCompare(y);
+                        return ascending ? cmp : -cmp;
+                    });
+                    rows.forEach(function (row) { tbody.appendChild(row); });
+                });
+            });
+        });
+    })();
+    </script>
 </body>
 </html>`
@@ -430,3 +430,699 @@ func assertContains(t *testing.T, s, substr string) {
 		t.Errorf("expected string to contain %q", substr)
 	}
 }
+
+func TestGenerateHTML_Accessibility(t *testing.T) {
+	r := NewReporter()
+	report := createFullReport()
+	report.Metrics.WorkerHealth = &WorkerHealthMetrics{
+		PeakCPUPercent:     92.0,
+		SaturationDetected: true,
+		SaturationReason:   "CPU above 80%",
+	}
+
+	data, err := r.GenerateHTML(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	html := string(data)
+	assertContains(t, html, "<main id=\"report-main\">")
+	assertContains(t, html, "<a class=\"skip-link\" href=\"#report-main\">")
+	assertContains(t, html, "<caption>Per-operation totals and latency percentiles</caption>")
+	assertContains(t, html, "<th scope=\"col\">Operation</th>")
+	assertContains(t, html, "<th scope=\"row\">tools_call</th>")
+	assertContains(t, html, "role=\"note\"")
+	assertContains(t, html, "aria-labelledby=\"latency-heading\"")
+
+	// Summary values use description lists rather than orphan <label> elements
+	if strings.Contains(html, "<label>") {
+		t.Error("report should not use <label> outside of form controls")
+	}
+}
+
+func TestGenerateHTML_NoJSFallback(t *testing.T) {
+	r := NewReporter()
+	report := createFullReport()
+
+	data, err := r.GenerateHTML(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	html := string(data)
+	scriptStart := strings.Index(html, "<script>")
+	if scriptStart == -1 {
+		t.Fatal("expected inline enhancement script")
+	}
+	static := html[:scriptStart]
+
+	// All report data must be present in markup before any script runs
+	for _, want := range []string{"tools_list", "tools_call", "echo", "add", "1000", "5.00%"} {
+		if !strings.Contains(static, want) {
+			t.Errorf("expected %q to be rendered statically", want)
+		}
+	}
+	if strings.Contains(static, "<button") {
+		t.Error("sort buttons should only be added by script")
+	}
+}
+
+func TestGenerateHTML_Connections(t *testing.T) {
+	r := NewReporter()
+	report := createFullReport()
+
+	data, err := r.GenerateHTML(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(string(data), "connections-heading") {
+		t.Error("expected no connections section without connection metrics")
+	}
+
+	report.Metrics.Connections = &ConnectionReportMetrics{
+		TracedRequests:      100,
+		ReusedConnections:   90,
+		NewConnections:      10,
+		ConnectionReuseRate: 0.9,
+		DNSLookups:          10,
+		DNSCacheHits:        4,
+		DNSCacheHitRatio:    0.4,
+		DNSLookupP50Ms:      0.25,
+		DNSLookupP95Ms:      18.5,
+		DNSLookupMaxMs:      42,
+	}
+	data, err = r.GenerateHTML(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	html := string(data)
+	assertValidHTML(t, html)
+	assertContains(t, html, `<h2 id="connections-heading">Connections</h2>`)
+	assertContains(t, html, "90.0%")
+	assertContains(t, html, "40.0%")
+	assertContains(t, html, "18.50 ms")
+	assertContains(t, html, "42.00 ms")
+}
+
+func TestGenerateHTML_RequestIDs(t *testing.T) {
+	r := NewReporter()
+	report := createFullReport()
+	report.Metrics.RequestIDs = &RequestIDReportMetrics{
+		ByKind: map[string]*RequestIDKindMetrics{
+			"string":  {Requests: 10},
+			"integer": {Requests: 10, Failures: 4, ErrorRate: 0.4},
+		},
+		Duplicates: &RequestIDKindMetrics{Requests: 2, Failures: 2, ErrorRate: 1},
+		Findings:   []string{"integer ids failed 4 of 10 requests (40.0%, string ids 0.0%)"},
+	}
+
+	data, err := r.GenerateHTML(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	html := string(data)
+	assertValidHTML(t, html)
+	assertContains(t, html, `<h2 id="request-ids-heading">Request ID Handling</h2>`)
+	assertContains(t, html, `<th scope="row">duplicate</th>`)
+	assertContains(t, html, "<li>integer ids failed 4 of 10 requests (40.0%, string ids 0.0%)</li>")
+}
+
+func TestGenerateHTML_Fuzz(t *testing.T) {
+	r := NewReporter()
+	report := createFullReport()
+	report.Metrics.Fuzz = &FuzzReportMetrics{
+		ByMutation: map[string]*FuzzMutationMetrics{
+			"deep_nesting": {Requests: 4, Rejected: 1, Unhandled: 3},
+			"wrong_type":   {Requests: 5, Rejected: 5},
+		},
+		Findings: []string{"3 of 4 deep_nesting calls failed with timeout instead of a JSON-RPC or tool error"},
+	}
+
+	data, err := r.GenerateHTML(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	html := string(data)
+	assertValidHTML(t, html)
+	assertContains(t, html, `<h2 id="fuzz-heading">Malformed Input Handling</h2>`)
+	if strings.Index(html, `<th scope="row">wrong_type</th>`) > strings.Index(html, `<th scope="row">deep_nesting</th>`) {
+		t.Error("expected mutations in report order")
+	}
+	assertContains(t, html, "<li>3 of 4 deep_nesting calls failed with timeout instead of a JSON-RPC or tool error</li>")
+}
+
+func TestGenerateHTML_Resumption(t *testing.T) {
+	r := NewReporter()
+	report := createFullReport()
+	report.Metrics.Resumption = &ResumptionReportMetrics{DroppedStreams: 8, ResumeAttempts: 10, Resumed: 6, Failed: 2, SuccessRate: 0.75}
+
+	data, err := r.GenerateHTML(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	html := string(data)
+	assertValidHTML(t, html)
+	assertContains(t, html, `<h2 id="resumption-heading">Stream Resumption</h2>`)
+	assertContains(t, html, "<dd>75.0%</dd>")
+}
+
+func TestGenerateHTML_Cancellation(t *testing.T) {
+	r := NewReporter()
+	report := createFullReport()
+	report.Metrics.Cancellation = &CancellationReportMetrics{Picked: 10, Sent: 8, Honored: 6, CompletedAnyway: 2, CompletedFirst: 2, HonorRate: 0.75}
+
+	data, err := r.GenerateHTML(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	html := string(data)
+	assertValidHTML(t, html)
+	assertContains(t, html, `<h2 id="cancellation-heading">Cancellation Handling</h2>`)
+	assertContains(t, html, "<dd>75.0%</dd>")
+}
+
+func TestGenerateHTML_Throttling(t *testing.T) {
+	r := NewReporter()
+	report := createFullReport()
+	report.Metrics.Throttling = &ThrottleReportMetrics{Throttled: 40, ThrottleRate: 0.04, WithRetryAfter: 30, MeanRetryAfterMs: 1500, HeldBack: 35, WaitMs: 52000, EffectiveRPS: 12.5}
+
+	data, err := r.GenerateHTML(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	html := string(data)
+	assertValidHTML(t, html)
+	assertContains(t, html, `<h2 id="throttling-heading">Rate Limiting</h2>`)
+	assertContains(t, html, "<dd>40 (4.0%)</dd>")
+	assertContains(t, html, "<dd>12.50</dd>")
+}
+
+func TestGenerateHTML_Pacing(t *testing.T) {
+	r := NewReporter()
+	report := createFullReport()
+	report.Metrics.Pacing = &PacingReportMetrics{
+		Operations: 100, Paused: 80, ThinkTimeMs: 120000, MeanThinkMs: 1200, MaxThinkMs: 9000,
+		ByTool: map[string]*ToolPacingMetrics{"search": {Calls: 50, Paused: 50, MeanThinkMs: 2000, MaxThinkMs: 9000}},
+	}
+
+	data, err := r.GenerateHTML(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	html := string(data)
+	assertValidHTML(t, html)
+	assertContains(t, html, `<h2 id="pacing-heading">Pacing</h2>`)
+	assertContains(t, html, "<dd>80 of 100</dd>")
+	assertContains(t, html, `<th scope="row">search</th>`)
+}
+
+func TestGenerateHTML_Workflows(t *testing.T) {
+	r := NewReporter()
+	report := createFullReport()
+	report.Metrics.Workflows = map[string]*WorkflowReportMetrics{
+		"discover": {
+			Runs: 40, Completed: 30, Failed: 6, ExtractFailed: 4, CompletionRate: 0.75,
+			Steps: []*WorkflowStepMetrics{
+				{Index: 0, Step: "list", OperationMetrics: OperationMetrics{TotalOps: 40}, RunsExtractFailed: 4},
+				{Index: 1, Step: "call", OperationMetrics: OperationMetrics{TotalOps: 36, ErrorRate: 0.1}, RunsFailed: 6},
+			},
+		},
+	}
+
+	data, err := r.GenerateHTML(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	html := string(data)
+	assertValidHTML(t, html)
+	assertContains(t, html, `<h2 id="workflows-heading">Workflows</h2>`)
+	assertContains(t, html, `<th scope="row">discover</th>`)
+	assertContains(t, html, `<td class="num">75.0%</td>`)
+	assertContains(t, html, `<th scope="row">2. call</th>`)
+}
+
+func TestGenerateHTML_Retries(t *testing.T) {
+	r := NewReporter()
+	report := createFullReport()
+	report.Metrics.Retries = &RetryReportMetrics{
+		Operations: 100, Retries: 12, FirstAttemptSuccesses: 90, FirstAttemptSuccessRate: 0.9,
+		EventualSuccesses: 98, EventualSuccessRate: 0.98, RecoveredByRetry: 8,
+		ByAttempt: []AttemptMetrics{{Attempt: 0, Operations: 100, Successes: 90}, {Attempt: 1, Operations: 10, Successes: 8}, {Attempt: 2, Operations: 2}},
+	}
+
+	data, err := r.GenerateHTML(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	html := string(data)
+	assertValidHTML(t, html)
+	assertContains(t, html, `<h2 id="retries-heading">Retries</h2>`)
+	assertContains(t, html, "<dd>98.0%</dd>")
+	assertContains(t, html, `<td class="num">80.0%</td>`)
+}
+
+func TestGenerateHTML_LatencyBuckets(t *testing.T) {
+	r := NewReporter()
+	report := createFullReport()
+	report.Metrics.LatencyBuckets = &LatencyBucketReportMetrics{
+		BoundariesMs: []int{100, 500},
+		All:          &LatencyDistribution{Total: 100, Counts: []int64{70, 25, 5}},
+		ByTool:       map[string]*LatencyDistribution{"search": {Total: 40, Counts: []int64{20, 15, 5}}},
+		Deadlines: []DeadlineResult{
+			{LatencyDeadline: LatencyDeadline{ToolName: "search", DeadlineMs: 500}, Operations: 40, Missed: 5, MissRate: 0.125},
+		},
+	}
+
+	data, err := r.GenerateHTML(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	html := string(data)
+	assertValidHTML(t, html)
+	assertContains(t, html, `<h2 id="latency-buckets-heading">Latency Distribution</h2>`)
+	assertContains(t, html, `<th scope="col">100-500 ms</th>`)
+	assertContains(t, html, `<th scope="row">tool search</th>`)
+	assertContains(t, html, "<title>&lt; 100 ms: 70</title>")
+	assertContains(t, html, `<th scope="row">500 ms [tool search]</th>`)
+	assertContains(t, html, `<td class="num">12.50%</td>`)
+}
+
+func TestGenerateHTML_Warmup(t *testing.T) {
+	r := NewReporter()
+	report := createFullReport()
+
+	data, err := r.GenerateHTML(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(string(data), "Warmup Operations") {
+		t.Error("expected no warmup card without warmup operations")
+	}
+
+	report.Metrics.WarmupOps = 42
+	data, err = r.GenerateHTML(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	html := string(data)
+	assertValidHTML(t, html)
+	assertContains(t, html, "<dt>Warmup Operations (excluded)</dt>")
+	assertContains(t, html, "<dd>42</dd>")
+}
+
+func TestGenerateHTML_Targets(t *testing.T) {
+	r := NewReporter()
+	report := createFullReport()
+
+	data, err := r.GenerateHTML(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(string(data), "targets-heading") {
+		t.Error("expected no targets section for a single-target run")
+	}
+
+	report.Metrics.ByTarget = map[string]*OperationMetrics{
+		"east": {TotalOps: 600, SuccessOps: 590, FailureOps: 10},
+		"west": {TotalOps: 400, SuccessOps: 360, FailureOps: 40},
+	}
+	data, err = r.GenerateHTML(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	html := string(data)
+	assertValidHTML(t, html)
+	assertContains(t, html, `<h2 id="targets-heading">Targets</h2>`)
+	assertContains(t, html, `<th scope="row">east</th>`)
+	assertContains(t, html, `<th scope="row">west</th>`)
+}
+
+func TestGenerateHTML_WorkerLabels(t *testing.T) {
+	r := NewReporter()
+	report := createFullReport()
+	report.Metrics.ByWorkerLabel = map[string]map[string]*OperationMetrics{
+		"region": {
+			"us-east-1": {TotalOps: 400, SuccessOps: 390, FailureOps: 10},
+			"eu-west-1": {TotalOps: 600, SuccessOps: 560, FailureOps: 40},
+		},
+	}
+
+	data, err := r.GenerateHTML(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	html := string(data)
+	assertValidHTML(t, html)
+	assertContains(t, html, `<h2 id="worker-labels-heading">Worker Labels</h2>`)
+	assertContains(t, html, `<th scope="row">region=eu-west-1</th>`)
+	if strings.Index(html, "region=eu-west-1") > strings.Index(html, "region=us-east-1") {
+		t.Error("expected worker label rows sorted by value")
+	}
+}
+
+func TestGenerateHTML_Streaming(t *testing.T) {
+	r := NewReporter()
+	report := createFullReport()
+	report.Metrics.StreamingByTool = map[string]*StreamingMetrics{
+		"summarize": {Streams: 12, TimeToFirstEventP50: 40, TimeToFirstEventP95: 180, MaxEventGapP95: 900, MaxEventGap: 4200, ProgressNotifications: 48, ProgressTokenMismatches: 2},
+	}
+
+	data, err := r.GenerateHTML(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	html := string(data)
+	assertValidHTML(t, html)
+	assertContains(t, html, `<h2 id="streaming-heading">Streaming by Tool</h2>`)
+	assertContains(t, html, `<td class="num">4200</td>`)
+	assertContains(t, html, `<th scope="row">summarize</th>`)
+}
+
+func TestGenerateHTML_ServerMessages(t *testing.T) {
+	r := NewReporter()
+	report := createFullReport()
+	report.Metrics.ServerMessages = &ServerMessageReportMetrics{
+		ByMethod:      map[string]int{"sampling/createMessage": 4, "notifications/resources/updated": 2},
+		Requests:      4,
+		Notifications: 2,
+		Replies:       3,
+		ReplyErrors:   1,
+	}
+
+	data, err := r.GenerateHTML(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	html := string(data)
+	assertValidHTML(t, html)
+	assertContains(t, html, `<h2 id="server-messages-heading">Server-Initiated Messages</h2>`)
+	assertContains(t, html, `<th scope="row">sampling/createMessage</th>`)
+	assertContains(t, html, `<th scope="row">notifications/resources/updated</th>`)
+}
+
+func TestGenerateHTML_SLOs(t *testing.T) {
+	r := NewReporter()
+	report := createFullReport()
+	actual := 0.02
+	report.SLOs = &SLOReport{
+		Verdict: SLOVerdictFail,
+		Results: []SLOResult{
+			{Name: "errors", Objective: "error_rate < 1%", Actual: &actual},
+			{Name: "fetch", Objective: "p95_latency_ms < 500", ToolName: "fetch", Message: "no operations in scope"},
+		},
+	}
+
+	data, err := r.GenerateHTML(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	html := string(data)
+	assertValidHTML(t, html)
+	assertContains(t, html, `<h2 id="slo-heading">SLOs</h2>`)
+	assertContains(t, html, "<dd>FAIL</dd>")
+	assertContains(t, html, `<td class="num">0.02</td>`)
+	assertContains(t, html, "<td>tool fetch</td>")
+	assertContains(t, html, "<td>FAIL: no operations in scope</td>")
+}
+
+func TestGenerateHTML_Capacity(t *testing.T) {
+	r := NewReporter()
+	report := createFullReport()
+	report.Capacity = &CapacityReport{
+		MaxSustainableVUs: 40,
+		MaxSustainableRPS: 80,
+		VUsLowerBound:     40,
+		VUsUpperBound:     45,
+		RPSLow:            74.5,
+		RPSHigh:           85.5,
+		Converged:         true,
+		Probes: []CapacityProbe{
+			{VUs: 40, Operations: 800, RPS: 80, Passed: true},
+			{VUs: 45, Operations: 850, RPS: 85, Guardrail: "error_rate", Observed: 0.05},
+			{VUs: 50},
+		},
+	}
+
+	data, err := r.GenerateHTML(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	html := string(data)
+	assertValidHTML(t, html)
+	assertContains(t, html, `<h2 id="capacity-heading">Capacity</h2>`)
+	assertContains(t, html, "<dd>80.0 (74.5-85.5)</dd>")
+	assertContains(t, html, "<dd>&gt;= 40, &lt; 45</dd>")
+	assertContains(t, html, "<td>tripped error_rate (0.05)</td>")
+	assertContains(t, html, "<td>no operations</td>")
+}
+
+func TestGenerateHTML_Checks(t *testing.T) {
+	r := NewReporter()
+	report := createFullReport()
+	report.Metrics.Checks = &CheckReportMetrics{
+		Checks: []*CheckMetrics{
+			{Name: "has_hits", Operation: "tools/call", ToolName: "search", Total: 4, Passed: 3, Failed: 1, PassRate: 0.75},
+		},
+		CheckedOps: 4,
+		FailedOps:  1,
+	}
+
+	data, err := r.GenerateHTML(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	html := string(data)
+	assertValidHTML(t, html)
+	assertContains(t, html, `<h2 id="checks-heading">Response Checks</h2>`)
+	assertContains(t, html, `<th scope="row">has_hits</th>`)
+	assertContains(t, html, "<td>tools/call search</td>")
+	assertContains(t, html, "75.0%")
+}
+
+func TestGenerateHTML_ServerMetrics(t *testing.T) {
+	r := NewReporter()
+	report := createFullReport()
+	report.ServerMetrics = ComputeServerMetrics([]NodeSeries{
+		{AgentID: "agent_a", Hostname: "node-a", Samples: []NodeSample{{TimestampMs: 0, CPUPercent: 50, CPUCores: 8, MemUsed: 2 << 30}}},
+		{AgentID: "agent_b", Hostname: "node-b", Samples: []NodeSample{{TimestampMs: 0, CPUPercent: 25, CPUCores: 4, MemUsed: 3 << 30}}},
+	}, 0)
+
+	data, err := r.GenerateHTML(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	html := string(data)
+	assertValidHTML(t, html)
+	assertContains(t, html, `<h2 id="server-metrics-heading">Server Resources</h2>`)
+	assertContains(t, html, `<th scope="row">node-a</th>`)
+	assertContains(t, html, `<th scope="row">Cluster</th>`)
+	assertContains(t, html, "5.00")
+	assertContains(t, html, "3.0 GiB (node-b)")
+}
+
+func TestGenerateHTML_ServerContainers(t *testing.T) {
+	r := NewReporter()
+	report := createFullReport()
+	report.ServerMetrics = ComputeServerMetrics([]NodeSeries{
+		{AgentID: "agent_a", Hostname: "node-a", Samples: []NodeSample{
+			{TimestampMs: 0, Container: &ContainerSample{CPULimitCores: 2, CPUUsageCores: 2, ThrottledPercent: 35, MemUsage: 256 << 20, MemLimit: 512 << 20}},
+			{TimestampMs: 5_000, Container: &ContainerSample{CPULimitCores: 2, CPUUsageCores: 2, ThrottledPercent: 45, MemUsage: 256 << 20, MemLimit: 512 << 20, OOMKills: 1}},
+		}},
+	}, 0)
+
+	data, err := r.GenerateHTML(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	html := string(data)
+	assertValidHTML(t, html)
+	assertContains(t, html, "Per-node container usage against cgroup limits")
+	assertContains(t, html, "40.0%")
+	assertContains(t, html, "512.0 MiB (50% peak)")
+	assertContains(t, html, "<strong>Out of memory:</strong> the kernel killed 1 process(es)")
+}
+
+func TestGenerateHTML_ServerGPUs(t *testing.T) {
+	r := NewReporter()
+	report := createFullReport()
+	report.ServerMetrics = ComputeServerMetrics([]NodeSeries{
+		{AgentID: "agent_a", Hostname: "gpu-node", Samples: []NodeSample{
+			{TimestampMs: 0, GPU: &GPUSample{GPUs: 1, UtilPercent: 75, MemUsed: 12 << 30, MemTotal: 16 << 30, TempMaxC: 68}},
+		}},
+	}, 0)
+
+	data, err := r.GenerateHTML(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	html := string(data)
+	assertValidHTML(t, html)
+	assertContains(t, html, "Per-node NVIDIA GPU usage")
+	assertContains(t, html, `<th scope="row">gpu-node</th>`)
+	assertContains(t, html, "12.0 GiB / 16.0 GiB")
+	assertContains(t, html, "68 °C")
+}
+
+func TestGenerateHTML_ServerBottlenecks(t *testing.T) {
+	r := NewReporter()
+	report := createFullReport()
+	nodes, load := saturationRun(400)
+	report.Load = load
+	report.ServerMetrics = ComputeServerMetrics(nodes, 0)
+	report.ServerMetrics.CorrelateLoad(load)
+
+	data, err := r.GenerateHTML(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	html := string(data)
+	assertValidHTML(t, html)
+	assertContains(t, html, "<h3>node-a Against Load</h3>")
+	assertContains(t, html, "Server process RSS (solid, peak 100.0 MiB)")
+	assertContains(t, html, "Open connections (solid, peak 12)")
+	assertContains(t, html, "client load (dashed, peak 50.0 ops/s)")
+	assertContains(t, html, "Server-Side Bottlenecks")
+	assertContains(t, html, `<td class="num">97.0%</td>`)
+	assertContains(t, html, `<td class="num">400 ms</td>`)
+}
+
+func TestGenerateHTML_Insights(t *testing.T) {
+	r := NewReporter()
+	report := createFullReport()
+	report.Insights = []Insight{{
+		Kind:     "error_concentration",
+		Severity: InsightCritical,
+		Title:    "Error rate driven by tool search",
+		Detail:   "search accounts for 95% of failures (20 of 21).",
+		Evidence: []Evidence{{Metric: `metrics.by_tool["search"].error_rate`, Value: 0.1}},
+	}}
+
+	data, err := r.GenerateHTML(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	html := string(data)
+	assertValidHTML(t, html)
+	assertContains(t, html, `<article class="insight critical">`)
+	assertContains(t, html, "<h3>Error rate driven by tool search</h3>")
+	assertContains(t, html, "<code>metrics.by_tool[&#34;search&#34;].error_rate = 0.1</code>")
+}
+
+func TestGenerateHTML_ErrorSignatures(t *testing.T) {
+	r := NewReporter()
+	report := createFullReport()
+	code := -32603
+	report.Metrics.FailureOps = 8
+	report.Metrics.ErrorSignatures = []ErrorSignature{{
+		Pattern:       "index shard <NUM> unavailable",
+		Count:         6,
+		FirstSeenMs:   1700000000000,
+		LastSeenMs:    1700000060000,
+		AffectedTools: []string{"fetch", "search"},
+		SampleError:   "index shard 3 unavailable",
+		ErrorType:     "tool_error",
+		ErrorCode:     "TOOL_ERROR",
+		JSONRPCCode:   &code,
+		ExampleOpIDs:  []string{"op_1", "op_2"},
+	}}
+
+	data, err := r.GenerateHTML(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	html := string(data)
+	assertValidHTML(t, html)
+	assertContains(t, html, `<h2 id="error-signatures-heading">Error Signatures</h2>`)
+	assertContains(t, html, "<summary>index shard &lt;NUM&gt; unavailable</summary>")
+	assertContains(t, html, "<td>tool_error, TOOL_ERROR, JSON-RPC -32603</td>")
+	assertContains(t, html, `<td class="num">75.0%</td>`)
+	assertContains(t, html, "<td>fetch, search</td>")
+	assertContains(t, html, "<td>op_1, op_2</td>")
+}
+
+func TestGenerateHTML_ServerScraped(t *testing.T) {
+	r := NewReporter()
+	report := createFullReport()
+	report.ServerMetrics = ComputeServerMetrics([]NodeSeries{
+		{AgentID: "agent_a", Hostname: "node-a", Samples: []NodeSample{
+			{TimestampMs: 0, Scraped: []ScrapedValue{{Name: "mcp_queue_depth", Labels: map[string]string{"pool": "io"}, Type: "gauge", Value: 4}}},
+			{TimestampMs: 5_000, Scraped: []ScrapedValue{{Name: "mcp_queue_depth", Labels: map[string]string{"pool": "io"}, Type: "gauge", Value: 12}}},
+		}},
+	}, 0)
+
+	data, err := r.GenerateHTML(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	html := string(data)
+	assertValidHTML(t, html)
+	assertContains(t, html, "Server-Reported Metrics")
+	assertContains(t, html, `<code>mcp_queue_depth{pool=&#34;io&#34;}</code>`)
+	assertContains(t, html, `<td class="num">12</td>`)
+}
+
+func TestGenerateHTML_ServerLogs(t *testing.T) {
+	r := NewReporter()
+	report := createFullReport()
+	report.ServerLogs = &ServerLogReport{
+		Entries:      40,
+		ErrorEntries: 3,
+		Spikes: []ErrorSpike{{
+			StartMs:      30_000,
+			EndMs:        40_000,
+			ClientErrors: 21,
+			ServerErrors: 3,
+			Signatures: []ServerLogSignature{
+				{Signature: "ERROR db pool exhausted", Count: 3, Host: "node-a", Sample: "ERROR db pool exhausted\n\tat <Pool.get>"},
+			},
+		}},
+	}
+
+	data, err := r.GenerateHTML(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	html := string(data)
+	assertValidHTML(t, html)
+	assertContains(t, html, `<h2 id="server-logs-heading">Server Logs</h2>`)
+	assertContains(t, html, "1970-01-01T00:00:30Z")
+	assertContains(t, html, "3 &times; ERROR db pool exhausted (node-a)")
+	assertContains(t, html, "at &lt;Pool.get&gt;")
+}
+
+func TestGenerateHTML_NoServerMetrics(t *testing.T) {
+	r := NewReporter()
+	data, err := r.GenerateHTML(createFullReport())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(string(data), "server-metrics-heading") {
+		t.Error("expected no server resources section without server metrics")
+	}
+}
@@ -0,0 +1,151 @@
+package analysis
+
+import "fmt"
+
+// RequestIDFindingMargin is how much higher than the string id baseline an
+// id kind's error rate must be before it is reported as rejected by the
+// server. It keeps unrelated failures, such as timeouts, from producing
+// findings on their own.
+const RequestIDFindingMargin = 0.05
+
+// requestIDKinds is the report order of id kinds; string ids are the baseline.
+var requestIDKinds = []string{"string", "integer", "long"}
+
+// RequestIDSample describes the JSON-RPC id sent with a single operation.
+type RequestIDSample struct {
+	Kind             string // string, integer or long
+	Duplicate        bool   // id repeated the previous request's id
+	EchoTypeMismatch bool   // response echoed the id as a different JSON type
+}
+
+// RequestIDKindMetrics summarizes the operations sent with one kind of id.
+type RequestIDKindMetrics struct {
+	Requests           int     `json:"requests"`
+	Failures           int     `json:"failures"`
+	ErrorRate          float64 `json:"error_rate"`
+	EchoTypeMismatches int     `json:"echo_type_mismatches"`
+}
+
+// RequestIDReportMetrics reports how the server handled varied JSON-RPC ids.
+// Duplicates are counted separately from the kind they repeated.
+type RequestIDReportMetrics struct {
+	ByKind     map[string]*RequestIDKindMetrics `json:"by_kind"`
+	Duplicates *RequestIDKindMetrics            `json:"duplicates,omitempty"`
+	Findings   []string                         `json:"findings,omitempty"`
+}
+
+// requestIDStats accumulates request id samples.
+type requestIDStats struct {
+	byKind     map[string]*RequestIDKindMetrics
+	duplicates *RequestIDKindMetrics
+}
+
+func (s *requestIDStats) add(op OperationResult) {
+	sample := op.RequestID
+	var m *RequestIDKindMetrics
+	if sample.Duplicate {
+		if s.duplicates == nil {
+			s.duplicates = &RequestIDKindMetrics{}
+		}
+		m = s.duplicates
+	} else {
+		m = s.kind(sample.Kind)
+	}
+	m.Requests++
+	if !op.OK {
+		m.Failures++
+	}
+	if sample.EchoTypeMismatch {
+		m.EchoTypeMismatches++
+	}
+}
+
+func (s *requestIDStats) kind(kind string) *RequestIDKindMetrics {
+	if s.byKind == nil {
+		s.byKind = make(map[string]*RequestIDKindMetrics)
+	}
+	m := s.byKind[kind]
+	if m == nil {
+		m = &RequestIDKindMetrics{}
+		s.byKind[kind] = m
+	}
+	return m
+}
+
+func (s *requestIDStats) merge(o *requestIDStats) {
+	for kind, m := range o.byKind {
+		addRequestIDCounts(s.kind(kind), m)
+	}
+	if o.duplicates != nil {
+		if s.duplicates == nil {
+			s.duplicates = &RequestIDKindMetrics{}
+		}
+		addRequestIDCounts(s.duplicates, o.duplicates)
+	}
+}
+
+func addRequestIDCounts(dst, src *RequestIDKindMetrics) {
+	dst.Requests += src.Requests
+	dst.Failures += src.Failures
+	dst.EchoTypeMismatches += src.EchoTypeMismatches
+}
+
+// metrics summarizes the request id samples. Returns nil if the run did not
+// vary request ids.
+func (s *requestIDStats) metrics() *RequestIDReportMetrics {
+	if s.byKind == nil && s.duplicates == nil {
+		return nil
+	}
+	result := &RequestIDReportMetrics{ByKind: make(map[string]*RequestIDKindMetrics, len(s.byKind))}
+	for kind, m := range s.byKind {
+		copied := *m
+		copied.ErrorRate = float64(m.Failures) / float64(m.Requests)
+		result.ByKind[kind] = &copied
+	}
+	if s.duplicates != nil {
+		copied := *s.duplicates
+		copied.ErrorRate = float64(copied.Failures) / float64(copied.Requests)
+		result.Duplicates = &copied
+	}
+	result.Findings = requestIDFindings(result)
+	return result
+}
+
+// requestIDFindings describes server behavior that differs between id
+// variants: kinds failing more often than string ids, ids echoed with a
+// different JSON type, and duplicate ids the server accepted.
+func requestIDFindings(metrics *RequestIDReportMetrics) []string {
+	var findings []string
+
+	baseline := 0.0
+	if m := metrics.ByKind["string"]; m != nil {
+		baseline = m.ErrorRate
+	}
+
+	for _, kind := range requestIDKinds {
+		m := metrics.ByKind[kind]
+		if m == nil {
+			continue
+		}
+		if kind != "string" && m.ErrorRate > baseline+RequestIDFindingMargin {
+			findings = append(findings, fmt.Sprintf(
+				"%s ids failed %d of %d requests (%.1f%%, string ids %.1f%%)",
+				kind, m.Failures, m.Requests, 100*m.ErrorRate, 100*baseline))
+		}
+		if m.EchoTypeMismatches > 0 {
+			findings = append(findings, fmt.Sprintf(
+				"%s ids were echoed as a different JSON type in %d responses",
+				kind, m.EchoTypeMismatches))
+		}
+	}
+
+	if d := metrics.Duplicates; d != nil {
+		if accepted := d.Requests - d.Failures; accepted > 0 {
+			findings = append(findings, fmt.Sprintf(
+				"server accepted %d of %d duplicate request ids; MCP requires ids to be unique within a session",
+				accepted, d.Requests))
+		}
+	}
+
+	return findings
+}
@@ -0,0 +1,55 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestComputeRequestIDMetrics(t *testing.T) {
+	agg := NewAggregator()
+	for i := 0; i < 10; i++ {
+		agg.AddOperation(OperationResult{Operation: "ping", LatencyMs: 5, OK: true, RequestID: &RequestIDSample{Kind: "string"}})
+		agg.AddOperation(OperationResult{Operation: "ping", LatencyMs: 5, OK: i < 5, RequestID: &RequestIDSample{Kind: "integer", EchoTypeMismatch: i < 5}})
+		agg.AddOperation(OperationResult{Operation: "ping", LatencyMs: 5, OK: true, RequestID: &RequestIDSample{Kind: "long"}})
+	}
+	agg.AddOperation(OperationResult{Operation: "ping", LatencyMs: 5, OK: true, RequestID: &RequestIDSample{Kind: "string", Duplicate: true}})
+	agg.AddOperation(OperationResult{Operation: "ping", LatencyMs: 5, OK: false, RequestID: &RequestIDSample{Kind: "string", Duplicate: true}})
+
+	metrics := agg.Compute().RequestIDs
+	if metrics == nil {
+		t.Fatal("expected request id metrics")
+	}
+
+	if m := metrics.ByKind["string"]; m.Requests != 10 || m.Failures != 0 {
+		t.Errorf("expected duplicates excluded from string kind, got %+v", m)
+	}
+	integer := metrics.ByKind["integer"]
+	if integer.Failures != 5 || integer.ErrorRate != 0.5 || integer.EchoTypeMismatches != 5 {
+		t.Errorf("unexpected integer metrics: %+v", integer)
+	}
+	if d := metrics.Duplicates; d == nil || d.Requests != 2 || d.Failures != 1 {
+		t.Errorf("unexpected duplicate metrics: %+v", d)
+	}
+
+	if len(metrics.Findings) != 3 {
+		t.Fatalf("expected 3 findings, got %v", metrics.Findings)
+	}
+	if !strings.HasPrefix(metrics.Findings[0], "integer ids failed 5 of 10") {
+		t.Errorf("expected integer rejection finding first, got %q", metrics.Findings[0])
+	}
+	if !strings.Contains(metrics.Findings[1], "different JSON type in 5 responses") {
+		t.Errorf("expected echo mismatch finding, got %q", metrics.Findings[1])
+	}
+	if !strings.HasPrefix(metrics.Findings[2], "server accepted 1 of 2 duplicate") {
+		t.Errorf("expected accepted duplicate finding, got %q", metrics.Findings[2])
+	}
+}
+
+func TestComputeRequestIDMetrics_NotVaried(t *testing.T) {
+	agg := NewAggregator()
+	agg.AddOperation(OperationResult{Operation: "ping", LatencyMs: 5, OK: true})
+
+	if metrics := agg.Compute(); metrics.RequestIDs != nil {
+		t.Errorf("expected no request id metrics, got %+v", metrics.RequestIDs)
+	}
+}
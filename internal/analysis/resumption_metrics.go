@@ -0,0 +1,52 @@
+package analysis
+
+// StreamResumeSample describes an SSE response stream that dropped before
+// its response arrived and was reopened from its last event ID.
+type StreamResumeSample struct {
+	Attempts int  // times the stream was reopened
+	Resumed  bool // the response arrived on a reopened stream
+}
+
+// ResumptionReportMetrics summarizes how the target handled the resumption
+// of dropped response streams. Streams dropped before the server sent an
+// event ID cannot be resumed and are not counted.
+type ResumptionReportMetrics struct {
+	DroppedStreams int     `json:"dropped_streams"`
+	ResumeAttempts int     `json:"resume_attempts"`
+	Resumed        int     `json:"resumed"`
+	Failed         int     `json:"failed"`
+	SuccessRate    float64 `json:"success_rate"`
+}
+
+// resumptionStats accumulates stream resume samples.
+type resumptionStats struct {
+	result ResumptionReportMetrics
+}
+
+func (s *resumptionStats) add(sample *StreamResumeSample) {
+	s.result.DroppedStreams++
+	s.result.ResumeAttempts += sample.Attempts
+	if sample.Resumed {
+		s.result.Resumed++
+	} else {
+		s.result.Failed++
+	}
+}
+
+func (s *resumptionStats) merge(o *resumptionStats) {
+	s.result.DroppedStreams += o.result.DroppedStreams
+	s.result.ResumeAttempts += o.result.ResumeAttempts
+	s.result.Resumed += o.result.Resumed
+	s.result.Failed += o.result.Failed
+}
+
+// metrics summarizes the resumed streams. Returns nil if no stream had to be
+// resumed.
+func (s *resumptionStats) metrics() *ResumptionReportMetrics {
+	if s.result.DroppedStreams == 0 {
+		return nil
+	}
+	result := s.result
+	result.SuccessRate = float64(result.Resumed) / float64(result.DroppedStreams)
+	return &result
+}
@@ -0,0 +1,34 @@
+package analysis
+
+import "testing"
+
+func TestResumptionMetrics(t *testing.T) {
+	agg := NewAggregator()
+	agg.AddOperation(OperationResult{Operation: "tools/call", LatencyMs: 10, OK: true})
+	if metrics := agg.Compute(); metrics.Resumption != nil {
+		t.Fatalf("expected nil resumption metrics without dropped streams, got %+v", metrics.Resumption)
+	}
+
+	samples := []*StreamResumeSample{
+		{Attempts: 1, Resumed: true},
+		{Attempts: 2, Resumed: true},
+		{Attempts: 3},
+	}
+	for _, sample := range samples {
+		agg.AddOperation(OperationResult{Operation: "tools/call", LatencyMs: 10, OK: sample.Resumed, StreamResume: sample})
+	}
+	other := NewAggregator()
+	other.AddOperation(OperationResult{Operation: "tools/call", LatencyMs: 10, OK: true, StreamResume: &StreamResumeSample{Attempts: 1, Resumed: true}})
+	agg.Merge(other)
+
+	r := agg.Compute().Resumption
+	if r == nil {
+		t.Fatal("expected resumption metrics")
+	}
+	if r.DroppedStreams != 4 || r.ResumeAttempts != 7 || r.Resumed != 3 || r.Failed != 1 {
+		t.Errorf("unexpected resumption counts: %+v", r)
+	}
+	if r.SuccessRate != 0.75 {
+		t.Errorf("expected success rate 0.75, got %f", r.SuccessRate)
+	}
+}
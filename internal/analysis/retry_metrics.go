@@ -0,0 +1,89 @@
+package analysis
+
+// MaxAttempt is the highest attempt index tracked, matching the run
+// config's limit on workload.retry.max_retries. Operations with higher or
+// negative indexes are left out of retry metrics.
+const MaxAttempt = 10
+
+// AttemptMetrics counts the operations made at one attempt index.
+type AttemptMetrics struct {
+	Attempt    int `json:"attempt"`
+	Operations int `json:"operations"`
+	Successes  int `json:"successes"`
+}
+
+// RetryReportMetrics compares first-attempt and eventual success when
+// failed operations are retried. Operations counts first attempts, so each
+// operation counts once however often it was retried; an operation
+// succeeds eventually if any of its attempts did.
+type RetryReportMetrics struct {
+	Operations              int     `json:"operations"`
+	Retries                 int     `json:"retries"`
+	FirstAttemptSuccesses   int     `json:"first_attempt_successes"`
+	FirstAttemptSuccessRate float64 `json:"first_attempt_success_rate"`
+	EventualSuccesses       int     `json:"eventual_successes"`
+	EventualSuccessRate     float64 `json:"eventual_success_rate"`
+	// RecoveredByRetry is the number of operations a retry made succeed.
+	RecoveredByRetry int              `json:"recovered_by_retry"`
+	ByAttempt        []AttemptMetrics `json:"by_attempt"`
+}
+
+// retryStats accumulates operations by attempt index.
+type retryStats struct {
+	byAttempt []AttemptMetrics
+}
+
+func (s *retryStats) add(op OperationResult) {
+	successes := 0
+	if op.OK {
+		successes = 1
+	}
+	s.addN(op.Attempt, 1, successes)
+}
+
+// addN counts operations of one attempt index, successes of which
+// succeeded.
+func (s *retryStats) addN(attempt, operations, successes int) {
+	if attempt < 0 || attempt > MaxAttempt {
+		return
+	}
+	for len(s.byAttempt) <= attempt {
+		s.byAttempt = append(s.byAttempt, AttemptMetrics{Attempt: len(s.byAttempt)})
+	}
+	s.byAttempt[attempt].Operations += operations
+	s.byAttempt[attempt].Successes += successes
+}
+
+func (s *retryStats) merge(o *retryStats) {
+	for _, m := range o.byAttempt {
+		for len(s.byAttempt) <= m.Attempt {
+			s.byAttempt = append(s.byAttempt, AttemptMetrics{Attempt: len(s.byAttempt)})
+		}
+		s.byAttempt[m.Attempt].Operations += m.Operations
+		s.byAttempt[m.Attempt].Successes += m.Successes
+	}
+}
+
+// metrics summarizes the attempts. Returns nil if nothing was retried.
+func (s *retryStats) metrics() *RetryReportMetrics {
+	if len(s.byAttempt) < 2 {
+		return nil
+	}
+	first := s.byAttempt[0]
+	result := &RetryReportMetrics{
+		Operations:            first.Operations,
+		FirstAttemptSuccesses: first.Successes,
+		EventualSuccesses:     first.Successes,
+		ByAttempt:             append([]AttemptMetrics(nil), s.byAttempt...),
+	}
+	for _, m := range s.byAttempt[1:] {
+		result.Retries += m.Operations
+		result.RecoveredByRetry += m.Successes
+		result.EventualSuccesses += m.Successes
+	}
+	if first.Operations > 0 {
+		result.FirstAttemptSuccessRate = float64(first.Successes) / float64(first.Operations)
+		result.EventualSuccessRate = float64(result.EventualSuccesses) / float64(first.Operations)
+	}
+	return result
+}
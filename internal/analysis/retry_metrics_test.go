@@ -0,0 +1,43 @@
+package analysis
+
+import "testing"
+
+func TestRetryMetrics(t *testing.T) {
+	agg := NewAggregator()
+	agg.AddOperation(OperationResult{Operation: "tools/call", LatencyMs: 10, OK: true})
+	if metrics := agg.Compute(); metrics.Retries != nil {
+		t.Fatalf("expected nil retry metrics without retries, got %+v", metrics.Retries)
+	}
+
+	// Four operations: one succeeds first time, two after one retry, and
+	// one fails all three attempts.
+	for _, op := range []OperationResult{
+		{Attempt: 0, OK: false, ErrorType: "http_error"},
+		{Attempt: 1, OK: true},
+		{Attempt: 0, OK: false, ErrorType: "connect_error"},
+		{Attempt: 1, OK: false, ErrorType: "connect_error"},
+		{Attempt: 2, OK: false, ErrorType: "connect_error"},
+		{Attempt: 42, OK: true},
+	} {
+		op.Operation = "tools/call"
+		agg.AddOperation(op)
+	}
+	other := NewAggregator()
+	other.AddOperation(OperationResult{Operation: "tools/call", Attempt: 0, ErrorType: "timeout"})
+	other.AddOperation(OperationResult{Operation: "tools/call", Attempt: 1, OK: true})
+	agg.Merge(other)
+
+	r := agg.Compute().Retries
+	if r == nil {
+		t.Fatal("expected retry metrics")
+	}
+	if r.Operations != 4 || r.Retries != 4 || r.FirstAttemptSuccesses != 1 || r.EventualSuccesses != 3 || r.RecoveredByRetry != 2 {
+		t.Errorf("unexpected retry counts: %+v", r)
+	}
+	if r.FirstAttemptSuccessRate != 0.25 || r.EventualSuccessRate != 0.75 {
+		t.Errorf("expected 25%% first-attempt and 75%% eventual success, got %+v", r)
+	}
+	if len(r.ByAttempt) != 3 || r.ByAttempt[2].Operations != 1 {
+		t.Errorf("expected three attempt indexes without the out of range one, got %+v", r.ByAttempt)
+	}
+}
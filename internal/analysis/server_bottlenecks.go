@@ -0,0 +1,117 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+)
+
+// LoadBucketMs is the width of the buckets of the load timeline, matching
+// the default server metrics buckets so the two line up.
+const LoadBucketMs = DefaultServerMetricsBucketMs
+
+// LoadPoint is the client load over one bucket of the run.
+type LoadPoint struct {
+	TimestampMs  int64 `json:"timestamp"`
+	Ops          int   `json:"ops"`
+	Failures     int   `json:"failures,omitempty"`
+	Stalls       int   `json:"stalls,omitempty"` // operations whose response stream stalled
+	LatencyP95Ms int   `json:"latency_p95_ms"`
+}
+
+const (
+	// SaturationCPUPercent is the CPU usage, of the host or of a
+	// container's limit, above which a node counts as saturated.
+	SaturationCPUPercent = 90
+	// latencyRiseFactor is how far p95 latency must rise over its level
+	// outside saturation for the saturation to count as a bottleneck.
+	latencyRiseFactor = 1.5
+)
+
+// ServerBottleneck is a stretch of the run in which a node's CPU was
+// saturated while client latency rose.
+type ServerBottleneck struct {
+	AgentID  string `json:"agent_id"`
+	Hostname string `json:"hostname"`
+	StartMs  int64  `json:"start"`
+	EndMs    int64  `json:"end"`
+
+	PeakCPUPercent float64 `json:"peak_cpu_percent"`
+	// BaselineP95Ms is the median p95 latency of the buckets in which the
+	// node was not saturated; PeakP95Ms the highest p95 during the stretch.
+	BaselineP95Ms int `json:"baseline_p95_ms"`
+	PeakP95Ms     int `json:"peak_p95_ms"`
+}
+
+// CorrelateLoad looks for server-side bottlenecks in the client load:
+// stretches of consecutive buckets in which a node was CPU saturated and
+// p95 latency rose to latencyRiseFactor times its level while the node was
+// not.
+func (r *ServerMetricsReport) CorrelateLoad(load []LoadPoint) {
+	if r == nil || len(load) == 0 {
+		return
+	}
+	r.Bottlenecks = nil
+	bucketMs := int64(DefaultServerMetricsBucketMs)
+	if r.Cluster != nil && r.Cluster.BucketMs > 0 {
+		bucketMs = r.Cluster.BucketMs
+	}
+
+	for _, node := range r.Nodes {
+		var windows [][]NodePoint
+		for _, p := range node.Series {
+			if p.saturationPercent() <= SaturationCPUPercent {
+				continue
+			}
+			if n := len(windows); n > 0 && p.TimestampMs-windows[n-1][len(windows[n-1])-1].TimestampMs <= bucketMs {
+				windows[n-1] = append(windows[n-1], p)
+			} else {
+				windows = append(windows, []NodePoint{p})
+			}
+		}
+		if len(windows) == 0 {
+			continue
+		}
+
+		saturated := func(tsMs int64) bool {
+			for _, w := range windows {
+				if tsMs+LoadBucketMs > w[0].TimestampMs && tsMs < w[len(w)-1].TimestampMs+bucketMs {
+					return true
+				}
+			}
+			return false
+		}
+		var baseline []int
+		for _, l := range load {
+			if l.Ops > 0 && !saturated(l.TimestampMs) {
+				baseline = append(baseline, l.LatencyP95Ms)
+			}
+		}
+		if len(baseline) == 0 {
+			continue
+		}
+		sort.Ints(baseline)
+		baselineP95 := baseline[len(baseline)/2]
+
+		for _, w := range windows {
+			b := ServerBottleneck{
+				AgentID:       node.AgentID,
+				Hostname:      node.Hostname,
+				StartMs:       w[0].TimestampMs,
+				EndMs:         w[len(w)-1].TimestampMs + bucketMs,
+				BaselineP95Ms: baselineP95,
+			}
+			for _, p := range w {
+				b.PeakCPUPercent = math.Max(b.PeakCPUPercent, p.saturationPercent())
+			}
+			for _, l := range load {
+				if l.Ops > 0 && l.TimestampMs+LoadBucketMs > b.StartMs && l.TimestampMs < b.EndMs {
+					b.PeakP95Ms = max(b.PeakP95Ms, l.LatencyP95Ms)
+				}
+			}
+			if baselineP95 > 0 && float64(b.PeakP95Ms) >= latencyRiseFactor*float64(baselineP95) {
+				r.Bottlenecks = append(r.Bottlenecks, b)
+			}
+		}
+	}
+	sort.SliceStable(r.Bottlenecks, func(i, j int) bool { return r.Bottlenecks[i].StartMs < r.Bottlenecks[j].StartMs })
+}
@@ -0,0 +1,72 @@
+package analysis
+
+import "testing"
+
+// saturationRun returns a node sampled every 5s for a minute that ran hot
+// from 20s to 40s, and the load over it, whose p95 latency is p95During
+// while the node ran hot and 100ms otherwise.
+func saturationRun(p95During int) ([]NodeSeries, []LoadPoint) {
+	node := NodeSeries{AgentID: "agent_a", Hostname: "node-a"}
+	var load []LoadPoint
+	for ts := int64(0); ts < 60_000; ts += 5_000 {
+		cpu, p95 := 40.0, 100
+		if ts >= 20_000 && ts < 40_000 {
+			cpu, p95 = 97, p95During
+		}
+		node.Samples = append(node.Samples, NodeSample{TimestampMs: ts, CPUPercent: cpu, CPUCores: 4, ProcessRSS: 100 << 20, ProcessFDs: 40, ProcessConns: 12})
+		if ts%LoadBucketMs == 0 {
+			load = append(load, LoadPoint{TimestampMs: ts, Ops: 500, LatencyP95Ms: p95})
+		}
+	}
+	return []NodeSeries{node}, load
+}
+
+func TestCorrelateLoad_CPUSaturation(t *testing.T) {
+	nodes, load := saturationRun(400)
+	report := ComputeServerMetrics(nodes, 0)
+	report.CorrelateLoad(load)
+
+	if got := len(report.Nodes[0].Series); got != 6 {
+		t.Fatalf("expected 6 buckets of node series, got %d", got)
+	}
+	if p := report.Nodes[0].Series[0]; p.CPUPercent != 40 || p.ProcessRSS != 100<<20 || p.ProcessFDs != 40 || p.ProcessConns != 12 {
+		t.Errorf("unexpected first bucket %+v", p)
+	}
+
+	if len(report.Bottlenecks) != 1 {
+		t.Fatalf("expected one bottleneck, got %+v", report.Bottlenecks)
+	}
+	b := report.Bottlenecks[0]
+	if b.Hostname != "node-a" || b.StartMs != 20_000 || b.EndMs != 40_000 {
+		t.Errorf("expected node-a saturated from 20s to 40s, got %+v", b)
+	}
+	if b.PeakCPUPercent != 97 || b.BaselineP95Ms != 100 || b.PeakP95Ms != 400 {
+		t.Errorf("unexpected bottleneck %+v", b)
+	}
+}
+
+func TestCorrelateLoad_NoLatencyRise(t *testing.T) {
+	nodes, load := saturationRun(120)
+	report := ComputeServerMetrics(nodes, 0)
+	report.CorrelateLoad(load)
+	if len(report.Bottlenecks) != 0 {
+		t.Errorf("expected no bottleneck while latency held, got %+v", report.Bottlenecks)
+	}
+}
+
+func TestCorrelateLoad_ContainerLimit(t *testing.T) {
+	nodes, load := saturationRun(400)
+	for i := range nodes[0].Samples {
+		s := &nodes[0].Samples[i]
+		s.Container = &ContainerSample{CPULimitCores: 1, CPUUsageCores: 0.5}
+		if s.CPUPercent > 90 {
+			s.CPUPercent = 30
+			s.Container.CPUUsageCores = 0.98
+		}
+	}
+	report := ComputeServerMetrics(nodes, 0)
+	report.CorrelateLoad(load)
+	if len(report.Bottlenecks) != 1 || report.Bottlenecks[0].PeakCPUPercent != 98 {
+		t.Errorf("expected the container limit to count as saturation, got %+v", report.Bottlenecks)
+	}
+}
@@ -0,0 +1,178 @@
+package analysis
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ServerLogEntry is a line of a target node's log shipped by its agent,
+// together with the continuation lines, such as a stack trace, that followed
+// it.
+type ServerLogEntry struct {
+	TimestampMs int64
+	AgentID     string
+	Hostname    string
+	File        string
+	Text        string
+}
+
+const (
+	// ServerLogWindowMs is the width of the windows client errors are
+	// counted in when looking for spikes.
+	ServerLogWindowMs = 10_000
+	// serverLogLeadMs widens a spike's window backwards: a server usually
+	// logs a failure before the client that sees it gives up.
+	serverLogLeadMs = 5_000
+	// A window is a spike if it has at least minSpikeErrors client errors
+	// and spikeFactor times the run's average per window.
+	minSpikeErrors = 5
+	spikeFactor    = 2.0
+	maxSpikes      = 5
+	// maxSpikeSignatures is how many server error signatures are kept per
+	// spike, and maxLogSampleBytes how much of each sample entry.
+	maxSpikeSignatures = 3
+	maxLogSampleBytes  = 4096
+)
+
+// serverLogErrorPattern picks out log entries that report errors.
+var serverLogErrorPattern = regexp.MustCompile(`(?i)\b(error|exception|panic|fatal|critical|traceback)\b`)
+
+// ServerLogReport correlates the target's logs with the errors the client
+// saw.
+type ServerLogReport struct {
+	Entries      int          `json:"entries"`
+	ErrorEntries int          `json:"error_entries"`
+	Spikes       []ErrorSpike `json:"spikes,omitempty"`
+}
+
+// ErrorSpike is a window in which client errors spiked, with the errors the
+// target's nodes logged around it.
+type ErrorSpike struct {
+	StartMs      int64                `json:"start_ms"`
+	EndMs        int64                `json:"end_ms"`
+	ClientErrors int                  `json:"client_errors"`
+	ServerErrors int                  `json:"server_errors"`
+	Signatures   []ServerLogSignature `json:"signatures,omitempty"`
+}
+
+// ServerLogSignature groups server log errors whose first lines match once
+// ids, numbers and paths are normalized, with one entry as a sample.
+type ServerLogSignature struct {
+	Signature string `json:"signature"`
+	Count     int    `json:"count"`
+	Host      string `json:"host"`
+	Sample    string `json:"sample"`
+}
+
+// CorrelateServerLogs finds the windows between fromMs and toMs in which
+// client errors spiked and lists, for each, the errors the server logged
+// from serverLogLeadMs before it to its end. failuresBySecond is keyed by
+// the start of each second, as returned by Aggregator.FailuresBySecond.
+// Returns nil without logs.
+func CorrelateServerLogs(failuresBySecond map[int64]int, logs []ServerLogEntry, fromMs, toMs int64) *ServerLogReport {
+	if len(logs) == 0 {
+		return nil
+	}
+	report := &ServerLogReport{Entries: len(logs)}
+	var errorLogs []ServerLogEntry
+	for _, entry := range logs {
+		if serverLogErrorPattern.MatchString(firstLine(entry.Text)) {
+			errorLogs = append(errorLogs, entry)
+		}
+	}
+	report.ErrorEntries = len(errorLogs)
+
+	for _, spike := range errorSpikes(failuresBySecond, fromMs, toMs) {
+		counts := make(map[string]*ServerLogSignature)
+		for _, entry := range errorLogs {
+			if entry.TimestampMs < spike.StartMs-serverLogLeadMs || entry.TimestampMs >= spike.EndMs {
+				continue
+			}
+			spike.ServerErrors++
+			key := NormalizeError(firstLine(entry.Text))
+			sig := counts[key]
+			if sig == nil {
+				sig = &ServerLogSignature{Signature: key, Host: entry.Hostname, Sample: truncateLogSample(entry.Text)}
+				if sig.Host == "" {
+					sig.Host = entry.AgentID
+				}
+				counts[key] = sig
+			}
+			sig.Count++
+		}
+		for _, sig := range counts {
+			spike.Signatures = append(spike.Signatures, *sig)
+		}
+		sort.Slice(spike.Signatures, func(i, j int) bool {
+			a, b := spike.Signatures[i], spike.Signatures[j]
+			if a.Count != b.Count {
+				return a.Count > b.Count
+			}
+			return a.Signature < b.Signature
+		})
+		if len(spike.Signatures) > maxSpikeSignatures {
+			spike.Signatures = spike.Signatures[:maxSpikeSignatures]
+		}
+		report.Spikes = append(report.Spikes, spike)
+	}
+	return report
+}
+
+// errorSpikes returns the windows of ServerLogWindowMs in which client
+// errors spiked, the largest maxSpikes of them in time order. The average
+// they are compared with spans fromMs to toMs, or just the windows with
+// errors where those are 0.
+func errorSpikes(failuresBySecond map[int64]int, fromMs, toMs int64) []ErrorSpike {
+	windows := make(map[int64]int)
+	first, last := fromMs-fromMs%ServerLogWindowMs, toMs-toMs%ServerLogWindowMs
+	for sec, n := range failuresBySecond {
+		key := sec - sec%ServerLogWindowMs
+		windows[key] += n
+		if first <= 0 || key < first {
+			first = key
+		}
+		if key > last {
+			last = key
+		}
+	}
+	if len(windows) == 0 {
+		return nil
+	}
+
+	total := 0
+	for _, n := range windows {
+		total += n
+	}
+	mean := float64(total) / float64((last-first)/ServerLogWindowMs+1)
+
+	var spikes []ErrorSpike
+	for key, n := range windows {
+		if n >= minSpikeErrors && float64(n) >= spikeFactor*mean {
+			spikes = append(spikes, ErrorSpike{StartMs: key, EndMs: key + ServerLogWindowMs, ClientErrors: n})
+		}
+	}
+	sort.Slice(spikes, func(i, j int) bool {
+		if spikes[i].ClientErrors != spikes[j].ClientErrors {
+			return spikes[i].ClientErrors > spikes[j].ClientErrors
+		}
+		return spikes[i].StartMs < spikes[j].StartMs
+	})
+	if len(spikes) > maxSpikes {
+		spikes = spikes[:maxSpikes]
+	}
+	sort.Slice(spikes, func(i, j int) bool { return spikes[i].StartMs < spikes[j].StartMs })
+	return spikes
+}
+
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(text, "\n")
+	return line
+}
+
+func truncateLogSample(text string) string {
+	if len(text) <= maxLogSampleBytes {
+		return text
+	}
+	return strings.ToValidUTF8(text[:maxLogSampleBytes], "") + "\n..."
+}
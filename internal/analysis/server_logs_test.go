@@ -0,0 +1,75 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCorrelateServerLogs(t *testing.T) {
+	// A minute of runs with a failure every 10s, and a burst of 20 failures
+	// between 30s and 40s.
+	failures := map[int64]int{}
+	for sec := int64(0); sec < 60_000; sec += 10_000 {
+		failures[sec+1_000] = 1
+	}
+	failures[32_000] += 12
+	failures[35_000] += 8
+
+	trace := "ERROR db pool exhausted after 3000ms\n\tat Pool.get(Pool.java:42)"
+	logs := []ServerLogEntry{
+		{TimestampMs: 5_000, Hostname: "node-a", Text: "ERROR unrelated early failure"},
+		{TimestampMs: 26_000, Hostname: "node-a", Text: trace},
+		{TimestampMs: 31_000, Hostname: "node-a", Text: "ERROR db pool exhausted after 5000ms\n\tat Pool.get(Pool.java:42)"},
+		{TimestampMs: 33_000, AgentID: "agent_b", Text: "panic: nil map"},
+		{TimestampMs: 34_000, Hostname: "node-a", Text: "INFO request served"},
+		{TimestampMs: 45_000, Hostname: "node-a", Text: "ERROR after the spike"},
+	}
+
+	report := CorrelateServerLogs(failures, logs, 0, 60_000)
+	if report == nil {
+		t.Fatal("expected a server log report")
+	}
+	if report.Entries != 6 || report.ErrorEntries != 5 {
+		t.Errorf("expected 6 entries and 5 errors, got %d and %d", report.Entries, report.ErrorEntries)
+	}
+	if len(report.Spikes) != 1 {
+		t.Fatalf("expected one spike, got %+v", report.Spikes)
+	}
+	spike := report.Spikes[0]
+	if spike.StartMs != 30_000 || spike.EndMs != 40_000 || spike.ClientErrors != 21 {
+		t.Errorf("unexpected spike window %+v", spike)
+	}
+	if spike.ServerErrors != 3 || len(spike.Signatures) != 2 {
+		t.Fatalf("expected 3 server errors in 2 signatures, got %+v", spike)
+	}
+	top := spike.Signatures[0]
+	if top.Count != 2 || top.Host != "node-a" || top.Sample != trace {
+		t.Errorf("expected the pool errors grouped with the first as sample, got %+v", top)
+	}
+	if spike.Signatures[1].Host != "agent_b" {
+		t.Errorf("expected the agent ID without a hostname, got %q", spike.Signatures[1].Host)
+	}
+}
+
+func TestCorrelateServerLogs_NoSpikes(t *testing.T) {
+	if CorrelateServerLogs(map[int64]int{1_000: 50}, nil, 0, 10_000) != nil {
+		t.Error("expected no report without logs")
+	}
+
+	logs := []ServerLogEntry{{TimestampMs: 1_000, Text: "ERROR x"}}
+	// Errors spread evenly never reach twice the average.
+	failures := map[int64]int{}
+	for sec := int64(0); sec < 60_000; sec += 1_000 {
+		failures[sec] = 1
+	}
+	if report := CorrelateServerLogs(failures, logs, 0, 60_000); report == nil || len(report.Spikes) != 0 {
+		t.Errorf("expected a report without spikes, got %+v", report)
+	}
+}
+
+func TestTruncateLogSample(t *testing.T) {
+	long := strings.Repeat("a", maxLogSampleBytes+10)
+	if got := truncateLogSample(long); len(got) != maxLogSampleBytes+4 || !strings.HasSuffix(got, "\n...") {
+		t.Errorf("expected the sample truncated, got %d bytes", len(got))
+	}
+}
@@ -0,0 +1,91 @@
+package analysis
+
+import (
+	"sort"
+	"strings"
+)
+
+// ServerMessageSample counts the server-initiated messages received while
+// an operation's response streamed.
+type ServerMessageSample struct {
+	Methods     map[string]int // requests and notifications, by method
+	Replies     int            // requests answered
+	ReplyErrors int            // answers the server did not accept
+}
+
+// ServerMessageReportMetrics summarizes the requests and notifications the
+// server sent to clients on response streams.
+type ServerMessageReportMetrics struct {
+	ByMethod      map[string]int `json:"by_method"`
+	Requests      int            `json:"requests"`
+	Notifications int            `json:"notifications"`
+	Replies       int            `json:"replies"`
+	ReplyErrors   int            `json:"reply_errors"`
+}
+
+// serverMessageStats accumulates server message samples.
+type serverMessageStats struct {
+	byMethod    map[string]int
+	replies     int
+	replyErrors int
+}
+
+func (s *serverMessageStats) add(sample *ServerMessageSample) {
+	if s.byMethod == nil {
+		s.byMethod = make(map[string]int)
+	}
+	for method, n := range sample.Methods {
+		s.byMethod[method] += n
+	}
+	s.replies += sample.Replies
+	s.replyErrors += sample.ReplyErrors
+}
+
+func (s *serverMessageStats) merge(o *serverMessageStats) {
+	s.add(&ServerMessageSample{Methods: o.byMethod, Replies: o.replies, ReplyErrors: o.replyErrors})
+}
+
+// metrics summarizes the server messages. Returns nil if the server sent
+// none.
+func (s *serverMessageStats) metrics() *ServerMessageReportMetrics {
+	if len(s.byMethod) == 0 {
+		return nil
+	}
+	result := &ServerMessageReportMetrics{
+		ByMethod:    make(map[string]int, len(s.byMethod)),
+		Replies:     s.replies,
+		ReplyErrors: s.replyErrors,
+	}
+	for method, n := range s.byMethod {
+		result.ByMethod[method] = n
+		if isNotificationMethod(method) {
+			result.Notifications += n
+		} else {
+			result.Requests += n
+		}
+	}
+	return result
+}
+
+// isNotificationMethod reports whether method names an MCP notification
+// rather than a request.
+func isNotificationMethod(method string) bool {
+	return strings.HasPrefix(method, "notifications/")
+}
+
+// serverMessageMethods returns the methods in metrics, requests first, each
+// kind sorted by name.
+func serverMessageMethods(metrics *ServerMessageReportMetrics) []string {
+	methods := make([]string, 0, len(metrics.ByMethod))
+	for method := range metrics.ByMethod {
+		methods = append(methods, method)
+	}
+	sort.Slice(methods, func(i, j int) bool {
+		ni, nj := isNotificationMethod(methods[i]), isNotificationMethod(methods[j])
+		if ni != nj {
+			return nj
+		}
+		return methods[i] < methods[j]
+	})
+	return methods
+}
@@ -0,0 +1,38 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestServerMessageMetrics(t *testing.T) {
+	agg := NewAggregator()
+	agg.AddOperation(OperationResult{Operation: "tools/call", LatencyMs: 10, OK: true})
+	if metrics := agg.Compute(); metrics.ServerMessages != nil {
+		t.Fatalf("expected nil server message metrics without messages, got %+v", metrics.ServerMessages)
+	}
+
+	agg.AddOperation(OperationResult{Operation: "tools/call", LatencyMs: 10, OK: true, ServerMessages: &ServerMessageSample{
+		Methods: map[string]int{"sampling/createMessage": 1, "notifications/progress": 3},
+		Replies: 1,
+	}})
+	other := NewAggregator()
+	other.AddOperation(OperationResult{Operation: "tools/call", LatencyMs: 10, OK: true, ServerMessages: &ServerMessageSample{
+		Methods:     map[string]int{"roots/list": 1, "notifications/tools/list_changed": 1, "sampling/createMessage": 1},
+		Replies:     1,
+		ReplyErrors: 1,
+	}})
+	agg.Merge(other)
+
+	m := agg.Compute().ServerMessages
+	if m == nil {
+		t.Fatal("expected server message metrics")
+	}
+	if m.Requests != 3 || m.Notifications != 4 || m.Replies != 2 || m.ReplyErrors != 1 {
+		t.Errorf("unexpected server message counts: %+v", m)
+	}
+	want := []string{"roots/list", "sampling/createMessage", "notifications/progress", "notifications/tools/list_changed"}
+	if got := serverMessageMethods(m); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected methods %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,445 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+)
+
+// DefaultServerMetricsBucketMs is the width of the time buckets used to line
+// up samples from different agents. It is twice the agent's default
+// collection interval, so every node normally has a sample in each bucket.
+const DefaultServerMetricsBucketMs = 10_000
+
+// NodeSample is one agent sample reduced to the values used for rollups.
+type NodeSample struct {
+	TimestampMs int64
+	CPUPercent  float64 // host CPU usage, 0-100 across all cores
+	CPUCores    int     // logical CPUs; 0 if the agent did not report it
+	MemUsed     uint64
+	MemTotal    uint64
+	ProcessRSS  uint64 // RSS of the monitored server process; 0 if none
+
+	// ProcessCPU is the CPU percent of the monitored server process.
+	ProcessCPU float64
+	// ProcessFDs and ProcessConns are the open file descriptors and network
+	// connections of the monitored server process.
+	ProcessFDs   int
+	ProcessConns int
+	// ClockSkewMs is how far the agent's clock was off when the sample's
+	// timestamp had to be corrected; 0 if it was in sync.
+	ClockSkewMs int64
+	// Container is set when the server process runs in a cgroup the agent
+	// could read.
+	Container *ContainerSample
+	// GPU is set when the agent collects GPU metrics.
+	GPU *GPUSample
+	// Seq is the agent's sequence number for the sample; 0 if the agent
+	// does not number samples.
+	Seq uint64
+	// Scraped holds the series the agent read from the server's own
+	// metrics endpoint.
+	Scraped []ScrapedValue
+}
+
+// GPUSample is the GPU usage of a node at one sample, across its GPUs.
+type GPUSample struct {
+	GPUs        int
+	UtilPercent float64 // mean utilization across the GPUs
+	MemUsed     uint64  // summed across the GPUs
+	MemTotal    uint64
+	TempMaxC    float64 // hottest GPU
+	ProcessMem  uint64  // GPU memory held by the server process
+}
+
+// ContainerSample is the cgroup usage and limits of the server process's
+// container. Limits are 0 when unlimited.
+type ContainerSample struct {
+	CPULimitCores    float64
+	CPUUsageCores    float64
+	ThrottledPercent float64 // share of scheduler periods throttled since the previous sample
+	MemUsage         uint64
+	MemLimit         uint64
+	OOMKills         uint64 // lifetime counter of the cgroup
+}
+
+// coresUsed converts host CPU percent to cores in use. Agents that do not
+// report a core count are counted as a single core.
+func (s NodeSample) coresUsed() float64 {
+	cores := s.CPUCores
+	if cores <= 0 {
+		cores = 1
+	}
+	return s.CPUPercent / 100 * float64(cores)
+}
+
+// NodeSeries is the samples reported by one agent, ordered by time.
+type NodeSeries struct {
+	AgentID  string
+	Hostname string
+	Samples  []NodeSample
+}
+
+// NodeMetrics summarizes the resource usage of one node.
+type NodeMetrics struct {
+	AgentID       string  `json:"agent_id"`
+	Hostname      string  `json:"hostname"`
+	Samples       int     `json:"samples"`
+	CPUCores      int     `json:"cpu_cores,omitempty"`
+	CPUAvgPercent float64 `json:"cpu_avg_percent"`
+	CPUMaxPercent float64 `json:"cpu_max_percent"`
+	CoresUsedAvg  float64 `json:"cores_used_avg"`
+	CoresUsedMax  float64 `json:"cores_used_max"`
+	MemUsedMax    uint64  `json:"mem_used_max"`
+	MemTotal      uint64  `json:"mem_total,omitempty"`
+
+	// SkewedSamples counts samples whose timestamp was corrected for the
+	// agent's clock being off; MaxClockSkewMs is the largest correction.
+	SkewedSamples  int   `json:"skewed_samples,omitempty"`
+	MaxClockSkewMs int64 `json:"max_clock_skew_ms,omitempty"`
+
+	// MissingSamples counts the samples the agent collected but never
+	// delivered, found from gaps in their sequence numbers.
+	MissingSamples int `json:"missing_samples,omitempty"`
+
+	Container *ContainerMetrics      `json:"container,omitempty"`
+	GPU       *GPUMetrics            `json:"gpu,omitempty"`
+	Scraped   []ScrapedSeriesMetrics `json:"scraped,omitempty"`
+
+	// Series holds the node's samples averaged per bucket of the cluster
+	// rollup, for charting them against the load.
+	Series []NodePoint `json:"series,omitempty"`
+}
+
+// NodePoint is a node's usage averaged over one time bucket.
+type NodePoint struct {
+	TimestampMs int64   `json:"timestamp"`
+	CPUPercent  float64 `json:"cpu_percent"`
+	MemUsed     uint64  `json:"mem_used"`
+
+	// ContainerCPUPercent is the container's CPU usage as a share of its
+	// limit; 0 without a limit.
+	ContainerCPUPercent float64 `json:"container_cpu_percent,omitempty"`
+
+	ProcessCPU   float64 `json:"process_cpu_percent,omitempty"`
+	ProcessRSS   uint64  `json:"process_rss,omitempty"`
+	ProcessFDs   int     `json:"process_fds,omitempty"`
+	ProcessConns int     `json:"process_connections,omitempty"`
+}
+
+// saturationPercent is the busiest of the node's host CPU and its
+// container's CPU limit, which a container can exhaust long before the host.
+func (p NodePoint) saturationPercent() float64 {
+	return math.Max(p.CPUPercent, p.ContainerCPUPercent)
+}
+
+// GPUMetrics summarizes the GPU usage of a node.
+type GPUMetrics struct {
+	GPUs           int     `json:"gpus"`
+	UtilAvgPercent float64 `json:"util_avg_percent"`
+	UtilMaxPercent float64 `json:"util_max_percent"`
+	MemUsedMax     uint64  `json:"mem_used_max"`
+	MemTotal       uint64  `json:"mem_total"`
+	TempMaxC       float64 `json:"temp_max_c,omitempty"`
+	// ProcessMemMax is the most GPU memory the server process held.
+	ProcessMemMax uint64 `json:"process_mem_max,omitempty"`
+}
+
+// summarizeGPU rolls up the GPU samples of a node, or returns nil if it
+// reported none.
+func summarizeGPU(samples []NodeSample) *GPUMetrics {
+	var m GPUMetrics
+	var n int
+	var utilSum float64
+	for _, s := range samples {
+		g := s.GPU
+		if g == nil {
+			continue
+		}
+		n++
+		utilSum += g.UtilPercent
+		m.GPUs = max(m.GPUs, g.GPUs)
+		m.UtilMaxPercent = math.Max(m.UtilMaxPercent, g.UtilPercent)
+		m.MemUsedMax = max(m.MemUsedMax, g.MemUsed)
+		m.MemTotal = max(m.MemTotal, g.MemTotal)
+		m.TempMaxC = math.Max(m.TempMaxC, g.TempMaxC)
+		m.ProcessMemMax = max(m.ProcessMemMax, g.ProcessMem)
+	}
+	if n == 0 {
+		return nil
+	}
+	m.UtilAvgPercent = utilSum / float64(n)
+	return &m
+}
+
+// countMissingSamples counts the sequence numbers skipped between a node's
+// consecutive samples. A sequence that starts over, from a restarted agent,
+// is not a gap.
+func countMissingSamples(samples []NodeSample) int {
+	missing := 0
+	var prev uint64
+	for _, s := range samples {
+		if s.Seq == 0 {
+			continue
+		}
+		if prev != 0 && s.Seq > prev+1 {
+			missing += int(s.Seq - prev - 1)
+		}
+		prev = s.Seq
+	}
+	return missing
+}
+
+// ContainerMetrics summarizes a node's container against its cgroup limits.
+type ContainerMetrics struct {
+	CPULimitCores       float64 `json:"cpu_limit_cores,omitempty"`
+	CPUUsageCoresMax    float64 `json:"cpu_usage_cores_max"`
+	ThrottledPercentAvg float64 `json:"throttled_percent_avg"`
+	ThrottledPercentMax float64 `json:"throttled_percent_max"`
+	MemUsageMax         uint64  `json:"mem_usage_max"`
+	MemLimit            uint64  `json:"mem_limit,omitempty"`
+	// MemLimitPercentMax is the peak memory usage as a percent of the
+	// limit; 0 without a limit.
+	MemLimitPercentMax float64 `json:"mem_limit_percent_max,omitempty"`
+	// OOMKills counts the out-of-memory kills during the run, across
+	// container restarts.
+	OOMKills uint64 `json:"oom_kills,omitempty"`
+}
+
+// summarizeContainer rolls up the container samples of a node, or returns
+// nil if it reported none.
+func summarizeContainer(samples []NodeSample) *ContainerMetrics {
+	var m ContainerMetrics
+	var n int
+	var throttledSum float64
+	var prevKills uint64
+	for _, s := range samples {
+		c := s.Container
+		if c == nil {
+			continue
+		}
+		throttledSum += c.ThrottledPercent
+		m.ThrottledPercentMax = math.Max(m.ThrottledPercentMax, c.ThrottledPercent)
+		m.CPUUsageCoresMax = math.Max(m.CPUUsageCoresMax, c.CPUUsageCores)
+		if c.CPULimitCores > 0 {
+			m.CPULimitCores = c.CPULimitCores
+		}
+		if c.MemUsage > m.MemUsageMax {
+			m.MemUsageMax = c.MemUsage
+		}
+		if c.MemLimit > 0 {
+			m.MemLimit = c.MemLimit
+			m.MemLimitPercentMax = math.Max(m.MemLimitPercentMax, float64(c.MemUsage)/float64(c.MemLimit)*100)
+		}
+		// The counter restarts with a new cgroup when the container does.
+		switch {
+		case n == 0:
+		case c.OOMKills >= prevKills:
+			m.OOMKills += c.OOMKills - prevKills
+		default:
+			m.OOMKills += c.OOMKills
+		}
+		prevKills = c.OOMKills
+		n++
+	}
+	if n == 0 {
+		return nil
+	}
+	m.ThrottledPercentAvg = throttledSum / float64(n)
+	return &m
+}
+
+// ClusterPoint is the cluster rollup for one time bucket. Nodes without a
+// sample in the bucket are left out of it.
+type ClusterPoint struct {
+	TimestampMs int64   `json:"timestamp"`
+	Nodes       int     `json:"nodes"`
+	CoresUsed   float64 `json:"cores_used"`
+	MemUsedMax  uint64  `json:"mem_used_max"`
+}
+
+// ClusterMetrics rolls node usage up to the cluster: CPU is summed as cores
+// in use across nodes, memory is the highest usage of any single node.
+type ClusterMetrics struct {
+	Nodes           int            `json:"nodes"`
+	TotalCores      int            `json:"total_cores,omitempty"`
+	CoresUsedAvg    float64        `json:"cores_used_avg"`
+	CoresUsedPeak   float64        `json:"cores_used_peak"`
+	NodeMemUsedPeak uint64         `json:"node_mem_used_peak"`
+	NodeMemPeakHost string         `json:"node_mem_peak_host,omitempty"`
+	BucketMs        int64          `json:"bucket_ms"`
+	Series          []ClusterPoint `json:"series"`
+}
+
+// ServerMetricsReport holds per-node summaries and the cluster rollup of the
+// agent metrics collected during a run.
+type ServerMetricsReport struct {
+	Nodes   []NodeMetrics   `json:"nodes"`
+	Cluster *ClusterMetrics `json:"cluster"`
+
+	// Bottlenecks are the stretches in which a node's CPU saturation
+	// slowed clients down, set by CorrelateLoad.
+	Bottlenecks []ServerBottleneck `json:"bottlenecks,omitempty"`
+}
+
+// ComputeServerMetrics summarizes agent samples per node and across nodes.
+// Samples are grouped into buckets of bucketMs; within a bucket each node's
+// samples are averaged before they are summed across nodes. Returns nil if no
+// node reported samples.
+func ComputeServerMetrics(nodes []NodeSeries, bucketMs int64) *ServerMetricsReport {
+	if bucketMs <= 0 {
+		bucketMs = DefaultServerMetricsBucketMs
+	}
+
+	report := &ServerMetricsReport{}
+	cluster := &ClusterMetrics{BucketMs: bucketMs}
+
+	// bucketAcc accumulates one node's samples within a bucket.
+	type bucketAcc struct {
+		cores   float64
+		memSum  uint64
+		samples int
+
+		cpuSum, containerCPUSum, processCPUSum float64
+		rssSum                                 uint64
+		fdsSum, connsSum                       int
+	}
+	buckets := make(map[int64]*ClusterPoint)
+
+	for _, node := range nodes {
+		if len(node.Samples) == 0 {
+			continue
+		}
+
+		m := NodeMetrics{AgentID: node.AgentID, Hostname: node.Hostname, Samples: len(node.Samples)}
+		nodeBuckets := make(map[int64]*bucketAcc)
+		var cpuSum, coresSum float64
+		for _, s := range node.Samples {
+			cores := s.coresUsed()
+			cpuSum += s.CPUPercent
+			coresSum += cores
+			if s.CPUPercent > m.CPUMaxPercent {
+				m.CPUMaxPercent = s.CPUPercent
+			}
+			if cores > m.CoresUsedMax {
+				m.CoresUsedMax = cores
+			}
+			if s.MemUsed > m.MemUsedMax {
+				m.MemUsedMax = s.MemUsed
+			}
+			if s.CPUCores > 0 {
+				m.CPUCores = s.CPUCores
+			}
+			if s.MemTotal > 0 {
+				m.MemTotal = s.MemTotal
+			}
+			if s.ClockSkewMs != 0 {
+				m.SkewedSamples++
+				if abs64(s.ClockSkewMs) > abs64(m.MaxClockSkewMs) {
+					m.MaxClockSkewMs = s.ClockSkewMs
+				}
+			}
+
+			key := s.TimestampMs - s.TimestampMs%bucketMs
+			acc := nodeBuckets[key]
+			if acc == nil {
+				acc = &bucketAcc{}
+				nodeBuckets[key] = acc
+			}
+			acc.cores += cores
+			acc.memSum += s.MemUsed
+			acc.samples++
+			acc.cpuSum += s.CPUPercent
+			if c := s.Container; c != nil && c.CPULimitCores > 0 {
+				acc.containerCPUSum += c.CPUUsageCores / c.CPULimitCores * 100
+			}
+			acc.processCPUSum += s.ProcessCPU
+			acc.rssSum += s.ProcessRSS
+			acc.fdsSum += s.ProcessFDs
+			acc.connsSum += s.ProcessConns
+		}
+		m.CPUAvgPercent = cpuSum / float64(len(node.Samples))
+		m.CoresUsedAvg = coresSum / float64(len(node.Samples))
+		m.Container = summarizeContainer(node.Samples)
+		m.GPU = summarizeGPU(node.Samples)
+		m.MissingSamples = countMissingSamples(node.Samples)
+		m.Scraped = summarizeScraped(node.Samples)
+
+		cluster.Nodes++
+		cluster.TotalCores += m.CPUCores
+		if m.MemUsedMax > cluster.NodeMemUsedPeak {
+			cluster.NodeMemUsedPeak = m.MemUsedMax
+			cluster.NodeMemPeakHost = nodeLabel(m)
+		}
+
+		for key, acc := range nodeBuckets {
+			point := buckets[key]
+			if point == nil {
+				point = &ClusterPoint{TimestampMs: key}
+				buckets[key] = point
+			}
+			point.Nodes++
+			point.CoresUsed += acc.cores / float64(acc.samples)
+			if mem := acc.memSum / uint64(acc.samples); mem > point.MemUsedMax {
+				point.MemUsedMax = mem
+			}
+
+			n := float64(acc.samples)
+			m.Series = append(m.Series, NodePoint{
+				TimestampMs:         key,
+				CPUPercent:          acc.cpuSum / n,
+				MemUsed:             acc.memSum / uint64(acc.samples),
+				ContainerCPUPercent: acc.containerCPUSum / n,
+				ProcessCPU:          acc.processCPUSum / n,
+				ProcessRSS:          acc.rssSum / uint64(acc.samples),
+				ProcessFDs:          int(math.Round(float64(acc.fdsSum) / n)),
+				ProcessConns:        int(math.Round(float64(acc.connsSum) / n)),
+			})
+		}
+		sort.Slice(m.Series, func(i, j int) bool { return m.Series[i].TimestampMs < m.Series[j].TimestampMs })
+		report.Nodes = append(report.Nodes, m)
+	}
+
+	if cluster.Nodes == 0 {
+		return nil
+	}
+
+	sort.Slice(report.Nodes, func(i, j int) bool {
+		a, b := report.Nodes[i], report.Nodes[j]
+		if nodeLabel(a) != nodeLabel(b) {
+			return nodeLabel(a) < nodeLabel(b)
+		}
+		return a.AgentID < b.AgentID
+	})
+
+	cluster.Series = make([]ClusterPoint, 0, len(buckets))
+	var coresTotal float64
+	for _, point := range buckets {
+		cluster.Series = append(cluster.Series, *point)
+		coresTotal += point.CoresUsed
+		if point.CoresUsed > cluster.CoresUsedPeak {
+			cluster.CoresUsedPeak = point.CoresUsed
+		}
+	}
+	sort.Slice(cluster.Series, func(i, j int) bool {
+		return cluster.Series[i].TimestampMs < cluster.Series[j].TimestampMs
+	})
+	cluster.CoresUsedAvg = coresTotal / float64(len(cluster.Series))
+	report.Cluster = cluster
+
+	return report
+}
+
+// nodeLabel names a node by hostname, falling back to its agent ID.
+func nodeLabel(m NodeMetrics) string {
+	if m.Hostname != "" {
+		return m.Hostname
+	}
+	return m.AgentID
+}
+
+func abs64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,184 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+)
+
+func TestComputeServerMetrics_ClusterRollup(t *testing.T) {
+	const gib = 1 << 30
+	nodes := []NodeSeries{
+		{AgentID: "agent_b", Hostname: "node-b", Samples: []NodeSample{
+			{TimestampMs: 1_000, CPUPercent: 50, CPUCores: 4, MemUsed: 6 * gib, MemTotal: 16 * gib},
+			{TimestampMs: 11_000, CPUPercent: 100, CPUCores: 4, MemUsed: 7 * gib, MemTotal: 16 * gib},
+		}},
+		{AgentID: "agent_a", Hostname: "node-a", Samples: []NodeSample{
+			{TimestampMs: 2_000, CPUPercent: 25, CPUCores: 8, MemUsed: 4 * gib},
+			{TimestampMs: 6_000, CPUPercent: 75, CPUCores: 8, MemUsed: 8 * gib},
+			{TimestampMs: 12_000, CPUPercent: 50, CPUCores: 8, MemUsed: 5 * gib},
+		}},
+		{AgentID: "agent_idle", Hostname: "node-idle"},
+	}
+
+	report := ComputeServerMetrics(nodes, 10_000)
+	if report == nil || report.Cluster == nil {
+		t.Fatal("expected a server metrics report")
+	}
+
+	if len(report.Nodes) != 2 || report.Nodes[0].Hostname != "node-a" || report.Nodes[1].Hostname != "node-b" {
+		t.Fatalf("expected node-a and node-b in order, got %+v", report.Nodes)
+	}
+	a := report.Nodes[0]
+	if a.Samples != 3 || a.CPUCores != 8 || a.CPUMaxPercent != 75 || a.CoresUsedMax != 6 || a.MemUsedMax != 8*gib {
+		t.Errorf("unexpected node-a summary: %+v", a)
+	}
+	if math.Abs(a.CPUAvgPercent-50) > 1e-9 || math.Abs(a.CoresUsedAvg-4) > 1e-9 {
+		t.Errorf("expected node-a averages 50%% / 4 cores, got %+v", a)
+	}
+
+	c := report.Cluster
+	if c.Nodes != 2 || c.TotalCores != 12 {
+		t.Errorf("expected 2 nodes with 12 cores, got %+v", c)
+	}
+	if c.NodeMemUsedPeak != 8*gib || c.NodeMemPeakHost != "node-a" {
+		t.Errorf("expected peak node memory 8 GiB on node-a, got %d on %q", c.NodeMemUsedPeak, c.NodeMemPeakHost)
+	}
+
+	// Bucket 0: node-a averages 4 cores, node-b uses 2. Bucket 10000: 4 + 4.
+	if len(c.Series) != 2 {
+		t.Fatalf("expected 2 buckets, got %+v", c.Series)
+	}
+	if p := c.Series[0]; p.TimestampMs != 0 || p.Nodes != 2 || p.CoresUsed != 6 || p.MemUsedMax != 6*gib {
+		t.Errorf("unexpected first bucket: %+v", p)
+	}
+	if p := c.Series[1]; p.TimestampMs != 10_000 || p.CoresUsed != 8 || p.MemUsedMax != 7*gib {
+		t.Errorf("unexpected second bucket: %+v", p)
+	}
+	if c.CoresUsedPeak != 8 || c.CoresUsedAvg != 7 {
+		t.Errorf("expected cores used avg 7 and peak 8, got %v and %v", c.CoresUsedAvg, c.CoresUsedPeak)
+	}
+}
+
+func TestComputeServerMetrics_UnknownCores(t *testing.T) {
+	report := ComputeServerMetrics([]NodeSeries{
+		{AgentID: "agent_old", Samples: []NodeSample{{TimestampMs: 1, CPUPercent: 80}}},
+	}, 0)
+	if report == nil {
+		t.Fatal("expected a report")
+	}
+	if got := report.Nodes[0].CoresUsedMax; got != 0.8 {
+		t.Errorf("expected a node without a core count to count as one core, got %v", got)
+	}
+	if report.Cluster.TotalCores != 0 || report.Cluster.BucketMs != DefaultServerMetricsBucketMs {
+		t.Errorf("unexpected cluster: %+v", report.Cluster)
+	}
+	if nodeLabel(report.Nodes[0]) != "agent_old" {
+		t.Errorf("expected agent ID label without hostname, got %q", nodeLabel(report.Nodes[0]))
+	}
+}
+
+func TestComputeServerMetrics_ClockSkew(t *testing.T) {
+	report := ComputeServerMetrics([]NodeSeries{
+		{AgentID: "agent_1", Hostname: "node-a", Samples: []NodeSample{
+			{TimestampMs: 1000, CPUPercent: 10},
+			{TimestampMs: 2000, CPUPercent: 10, ClockSkewMs: 1500},
+			{TimestampMs: 3000, CPUPercent: 10, ClockSkewMs: -2500},
+		}},
+	}, 0)
+	if report == nil {
+		t.Fatal("expected a report")
+	}
+	node := report.Nodes[0]
+	if node.SkewedSamples != 2 || node.MaxClockSkewMs != -2500 {
+		t.Errorf("expected 2 skewed samples up to -2500ms, got %d up to %dms", node.SkewedSamples, node.MaxClockSkewMs)
+	}
+	notes := buildClockSkewNotes(report.Nodes)
+	if len(notes) != 1 || notes[0] != "node-a: 2 of 3 samples shifted by up to -2.5s" {
+		t.Errorf("unexpected clock skew notes: %q", notes)
+	}
+}
+
+func TestComputeServerMetrics_MissingSamples(t *testing.T) {
+	report := ComputeServerMetrics([]NodeSeries{
+		{AgentID: "agent_1", Hostname: "node-a", Samples: []NodeSample{
+			{TimestampMs: 1000, Seq: 4},
+			{TimestampMs: 2000, Seq: 5},
+			{TimestampMs: 9000, Seq: 12},
+			// The agent restarted and numbers from 1 again.
+			{TimestampMs: 10000, Seq: 1},
+			{TimestampMs: 11000, Seq: 3},
+		}},
+		{AgentID: "agent_2", Hostname: "node-b", Samples: []NodeSample{
+			{TimestampMs: 1000}, {TimestampMs: 5000},
+		}},
+	}, 0)
+	if got := report.Nodes[0].MissingSamples; got != 7 {
+		t.Errorf("expected 7 missing samples, got %d", got)
+	}
+	if got := report.Nodes[1].MissingSamples; got != 0 {
+		t.Errorf("expected unnumbered samples never counted missing, got %d", got)
+	}
+	notes := buildSampleGapNotes(report.Nodes)
+	if len(notes) != 1 || notes[0] != "node-a: 7 samples missing, 5 received" {
+		t.Errorf("unexpected sample gap notes: %q", notes)
+	}
+}
+
+func TestComputeServerMetrics_NoSamples(t *testing.T) {
+	if report := ComputeServerMetrics(nil, 0); report != nil {
+		t.Errorf("expected nil without nodes, got %+v", report)
+	}
+	if report := ComputeServerMetrics([]NodeSeries{{AgentID: "agent_a"}}, 0); report != nil {
+		t.Errorf("expected nil without samples, got %+v", report)
+	}
+}
+
+func TestComputeServerMetrics_Container(t *testing.T) {
+	const mib = 1 << 20
+	nodes := []NodeSeries{
+		{AgentID: "agent_a", Hostname: "node-a", Samples: []NodeSample{
+			{TimestampMs: 0, Container: &ContainerSample{CPULimitCores: 2, CPUUsageCores: 1, ThrottledPercent: 0, MemUsage: 200 * mib, MemLimit: 512 * mib, OOMKills: 3}},
+			{TimestampMs: 5_000, Container: &ContainerSample{CPULimitCores: 2, CPUUsageCores: 2, ThrottledPercent: 40, MemUsage: 500 * mib, MemLimit: 512 * mib, OOMKills: 4}},
+			// The container restarted in a new cgroup and was killed again.
+			{TimestampMs: 10_000, Container: &ContainerSample{CPULimitCores: 2, CPUUsageCores: 1.5, ThrottledPercent: 20, MemUsage: 100 * mib, MemLimit: 512 * mib, OOMKills: 1}},
+		}},
+		{AgentID: "agent_b", Hostname: "node-b", Samples: []NodeSample{{TimestampMs: 0, CPUPercent: 10}}},
+	}
+
+	report := ComputeServerMetrics(nodes, 0)
+	if report.Nodes[1].Container != nil {
+		t.Errorf("expected no container summary for a bare host, got %+v", report.Nodes[1].Container)
+	}
+	c := report.Nodes[0].Container
+	if c == nil {
+		t.Fatal("expected a container summary")
+	}
+	if c.CPULimitCores != 2 || c.CPUUsageCoresMax != 2 || c.ThrottledPercentMax != 40 || c.ThrottledPercentAvg != 20 {
+		t.Errorf("unexpected CPU summary %+v", c)
+	}
+	if c.MemUsageMax != 500*mib || c.MemLimit != 512*mib || math.Abs(c.MemLimitPercentMax-97.65625) > 1e-9 {
+		t.Errorf("unexpected memory summary %+v", c)
+	}
+	if c.OOMKills != 2 {
+		t.Errorf("expected 2 OOM kills during the run, got %d", c.OOMKills)
+	}
+}
+
+func TestComputeServerMetrics_GPU(t *testing.T) {
+	const gib = 1 << 30
+	nodes := []NodeSeries{
+		{AgentID: "agent_a", Hostname: "node-a", Samples: []NodeSample{
+			{TimestampMs: 0, GPU: &GPUSample{GPUs: 2, UtilPercent: 40, MemUsed: 10 * gib, MemTotal: 80 * gib, TempMaxC: 55, ProcessMem: 8 * gib}},
+			{TimestampMs: 5_000, GPU: &GPUSample{GPUs: 2, UtilPercent: 90, MemUsed: 30 * gib, MemTotal: 80 * gib, TempMaxC: 71, ProcessMem: 28 * gib}},
+			{TimestampMs: 10_000},
+		}},
+	}
+
+	g := ComputeServerMetrics(nodes, 0).Nodes[0].GPU
+	if g == nil {
+		t.Fatal("expected a GPU summary")
+	}
+	if g.GPUs != 2 || g.UtilAvgPercent != 65 || g.UtilMaxPercent != 90 || g.MemUsedMax != 30*gib || g.MemTotal != 80*gib || g.TempMaxC != 71 || g.ProcessMemMax != 28*gib {
+		t.Errorf("unexpected GPU summary %+v", g)
+	}
+}
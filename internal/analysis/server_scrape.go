@@ -0,0 +1,176 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// ScrapedValue is a series from the server's own Prometheus metrics
+// endpoint at one agent sample.
+type ScrapedValue struct {
+	Name   string
+	Labels map[string]string
+	Type   string // counter, gauge, histogram or summary; empty if untyped
+	Value  float64
+}
+
+// ScrapedSeriesMetrics summarizes a series the server reported about
+// itself over the run.
+type ScrapedSeriesMetrics struct {
+	Series  string `json:"series"` // name{label="value",...}
+	Type    string `json:"type,omitempty"`
+	Samples int    `json:"samples"`
+
+	// Distribution is set when the row summarizes a histogram or summary
+	// as a whole, per label set, from its _sum and _count series. A
+	// summary's quantiles get rows of their own.
+	Distribution bool `json:"distribution,omitempty"`
+
+	// Last, Min, Max and Avg are the series' values; unset for
+	// distributions.
+	Last float64 `json:"last"`
+	Min  float64 `json:"min"`
+	Max  float64 `json:"max"`
+	Avg  float64 `json:"avg"`
+
+	// RatePerSec is a counter's increase per second, counted across
+	// resets, or a histogram's or summary's observations per second.
+	RatePerSec float64 `json:"rate_per_sec,omitempty"`
+	// Mean is a histogram's or summary's mean observation: the increase of
+	// its _sum over that of its _count.
+	Mean float64 `json:"mean,omitempty"`
+}
+
+// scrapedAcc accumulates the values of one series.
+type scrapedAcc struct {
+	name, labels, typ string
+	n                 int
+	firstMs, lastMs   int64
+	last, min, max    float64
+	sum               float64
+	increase          float64
+}
+
+func (a *scrapedAcc) add(tsMs int64, v float64) {
+	if a.n == 0 {
+		a.firstMs, a.min, a.max = tsMs, v, v
+	} else {
+		// A counter below its previous value was reset, and has counted
+		// v since.
+		if v >= a.last {
+			a.increase += v - a.last
+		} else {
+			a.increase += v
+		}
+		a.min = math.Min(a.min, v)
+		a.max = math.Max(a.max, v)
+	}
+	a.n++
+	a.lastMs = tsMs
+	a.last = v
+	a.sum += v
+}
+
+func (a *scrapedAcc) ratePerSec() float64 {
+	if secs := float64(a.lastMs-a.firstMs) / 1000; secs > 0 {
+		return a.increase / secs
+	}
+	return 0
+}
+
+// summarizeScraped summarizes a node's scraped series, ordered by series.
+// Histogram buckets are left out; they are too many to list and add
+// nothing to the mean.
+func summarizeScraped(samples []NodeSample) []ScrapedSeriesMetrics {
+	accs := make(map[string]*scrapedAcc)
+	for _, s := range samples {
+		for _, v := range s.Scraped {
+			if strings.HasSuffix(v.Name, "_bucket") && v.Type == "histogram" {
+				continue
+			}
+			labels := formatLabels(v.Labels)
+			key := v.Name + labels
+			acc := accs[key]
+			if acc == nil {
+				acc = &scrapedAcc{name: v.Name, labels: labels, typ: v.Type}
+				accs[key] = acc
+			}
+			acc.add(s.TimestampMs, v.Value)
+		}
+	}
+	if len(accs) == 0 {
+		return nil
+	}
+
+	var result []ScrapedSeriesMetrics
+	for _, acc := range accs {
+		if acc.typ == "histogram" || acc.typ == "summary" {
+			base, ok := strings.CutSuffix(acc.name, "_sum")
+			if !ok {
+				// _count is reported with _sum; a summary's quantiles are
+				// listed like gauges.
+				if strings.HasSuffix(acc.name, "_count") || acc.typ == "histogram" {
+					continue
+				}
+			} else {
+				if count := accs[base+"_count"+acc.labels]; count != nil {
+					m := ScrapedSeriesMetrics{
+						Series:       base + acc.labels,
+						Type:         acc.typ,
+						Samples:      acc.n,
+						Distribution: true,
+						RatePerSec:   count.ratePerSec(),
+					}
+					if count.increase > 0 {
+						m.Mean = acc.increase / count.increase
+					}
+					result = append(result, m)
+				}
+				continue
+			}
+		}
+
+		m := ScrapedSeriesMetrics{
+			Series:  acc.name + acc.labels,
+			Type:    acc.typ,
+			Samples: acc.n,
+			Last:    acc.last,
+			Min:     acc.min,
+			Max:     acc.max,
+			Avg:     acc.sum / float64(acc.n),
+		}
+		if acc.typ == "counter" {
+			m.RatePerSec = acc.ratePerSec()
+		}
+		result = append(result, m)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Series < result[j].Series })
+	return result
+}
+
+// formatLabels formats labels as Prometheus does, sorted by name, or as ""
+// without any.
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(k)
+		b.WriteString(`="`)
+		b.WriteString(strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(labels[k]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
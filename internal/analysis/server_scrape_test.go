@@ -0,0 +1,55 @@
+package analysis
+
+import "testing"
+
+func TestSummarizeScraped(t *testing.T) {
+	tool := map[string]string{"tool": "search"}
+	sample := func(tsMs int64, requests, depth, sum, count float64) NodeSample {
+		return NodeSample{TimestampMs: tsMs, Scraped: []ScrapedValue{
+			{Name: "mcp_requests_total", Type: "counter", Value: requests},
+			{Name: "mcp_queue_depth", Type: "gauge", Value: depth},
+			{Name: "mcp_handler_seconds_bucket", Labels: map[string]string{"tool": "search", "le": "+Inf"}, Type: "histogram", Value: count},
+			{Name: "mcp_handler_seconds_sum", Labels: tool, Type: "histogram", Value: sum},
+			{Name: "mcp_handler_seconds_count", Labels: tool, Type: "histogram", Value: count},
+		}}
+	}
+	// The server restarts between the second and third samples, resetting
+	// its counters.
+	samples := []NodeSample{
+		sample(0, 100, 2, 10, 100),
+		sample(5_000, 150, 8, 15, 150),
+		sample(10_000, 20, 5, 3, 50),
+	}
+
+	got := summarizeScraped(samples)
+	if len(got) != 3 {
+		t.Fatalf("expected 3 series, got %+v", got)
+	}
+
+	h := got[0]
+	if h.Series != `mcp_handler_seconds{tool="search"}` || !h.Distribution {
+		t.Fatalf("expected the histogram first, got %+v", h)
+	}
+	// 100 observations over 10s taking 8s in all.
+	if h.RatePerSec != 10 || h.Mean != 0.08 {
+		t.Errorf("expected 10/s with a mean of 0.08, got %+v", h)
+	}
+
+	if g := got[1]; g.Series != "mcp_queue_depth" || g.Last != 5 || g.Min != 2 || g.Max != 8 || g.Avg != 5 || g.RatePerSec != 0 {
+		t.Errorf("unexpected gauge summary %+v", g)
+	}
+	if c := got[2]; c.Series != "mcp_requests_total" || c.Samples != 3 || c.RatePerSec != 7 {
+		t.Errorf("expected 70 requests over 10s, got %+v", c)
+	}
+
+	if got := summarizeScraped([]NodeSample{{TimestampMs: 0}}); got != nil {
+		t.Errorf("expected nil without scraped series, got %+v", got)
+	}
+}
+
+func TestFormatLabels(t *testing.T) {
+	got := formatLabels(map[string]string{"path": `C:\tmp`, "code": "200", "msg": "say \"hi\""})
+	if want := `{code="200",msg="say \"hi\"",path="C:\\tmp"}`; got != want {
+		t.Errorf("formatLabels = %s, want %s", got, want)
+	}
+}
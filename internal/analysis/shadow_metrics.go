@@ -0,0 +1,208 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Thresholds above which the shadow's answers are reported as a finding.
+const (
+	// shadowErrorRateMargin is how much higher, in points, the shadow's
+	// error rate may be than the target's.
+	shadowErrorRateMargin = 0.01
+	// shadowLatencyRegression is how much slower the shadow's p95 may be,
+	// as a share of the target's.
+	shadowLatencyRegression = 0.2
+)
+
+// ShadowSample is the shadow target's answer to an operation.
+type ShadowSample struct {
+	LatencyMs   int
+	OK          bool
+	ResultMatch *bool // whether the results were equal, nil if not compared
+}
+
+// ShadowComparison compares how the target and the shadow answered the same
+// operations. LatencyDeltaMs is the shadow's latency minus the target's,
+// averaged over the operations.
+type ShadowComparison struct {
+	Operations       int     `json:"operations"`
+	TargetErrors     int     `json:"target_errors"`
+	ShadowErrors     int     `json:"shadow_errors"`
+	TargetErrorRate  float64 `json:"target_error_rate"`
+	ShadowErrorRate  float64 `json:"shadow_error_rate"`
+	TargetLatencyP50 int     `json:"target_latency_p50"`
+	TargetLatencyP95 int     `json:"target_latency_p95"`
+	TargetLatencyP99 int     `json:"target_latency_p99"`
+	ShadowLatencyP50 int     `json:"shadow_latency_p50"`
+	ShadowLatencyP95 int     `json:"shadow_latency_p95"`
+	ShadowLatencyP99 int     `json:"shadow_latency_p99"`
+	LatencyDeltaMs   float64 `json:"latency_delta_ms"`
+	// ResultsCompared counts the stable tool calls both answered without
+	// error, and ResultMismatches those whose results differed.
+	ResultsCompared  int `json:"results_compared"`
+	ResultMismatches int `json:"result_mismatches"`
+}
+
+// ShadowReportMetrics compares the target with the shadow target its
+// operations were duplicated to, overall, by operation and by tool. Only
+// the target's answers count anywhere else in the report.
+type ShadowReportMetrics struct {
+	ShadowComparison
+	ByOperation map[string]*ShadowComparison `json:"by_operation"`
+	ByTool      map[string]*ShadowComparison `json:"by_tool,omitempty"`
+	Findings    []string                     `json:"findings,omitempty"`
+}
+
+// shadowPairStats accumulates operations answered by both targets.
+type shadowPairStats struct {
+	operations   int
+	targetErrors int
+	shadowErrors int
+	target       Histogram
+	shadow       Histogram
+	deltaSum     int64
+	compared     int
+	mismatches   int
+}
+
+func (s *shadowPairStats) add(op OperationResult) {
+	s.operations++
+	if !op.OK {
+		s.targetErrors++
+	}
+	if !op.Shadow.OK {
+		s.shadowErrors++
+	}
+	s.target.Record(int64(op.LatencyMs))
+	s.shadow.Record(int64(op.Shadow.LatencyMs))
+	s.deltaSum += int64(op.Shadow.LatencyMs - op.LatencyMs)
+	if match := op.Shadow.ResultMatch; match != nil {
+		s.compared++
+		if !*match {
+			s.mismatches++
+		}
+	}
+}
+
+func (s *shadowPairStats) merge(o *shadowPairStats) {
+	s.operations += o.operations
+	s.targetErrors += o.targetErrors
+	s.shadowErrors += o.shadowErrors
+	s.target.Merge(&o.target)
+	s.shadow.Merge(&o.shadow)
+	s.deltaSum += o.deltaSum
+	s.compared += o.compared
+	s.mismatches += o.mismatches
+}
+
+func (s *shadowPairStats) comparison() ShadowComparison {
+	c := ShadowComparison{
+		Operations:       s.operations,
+		TargetErrors:     s.targetErrors,
+		ShadowErrors:     s.shadowErrors,
+		TargetLatencyP50: int(s.target.Percentile(50)),
+		TargetLatencyP95: int(s.target.Percentile(95)),
+		TargetLatencyP99: int(s.target.Percentile(99)),
+		ShadowLatencyP50: int(s.shadow.Percentile(50)),
+		ShadowLatencyP95: int(s.shadow.Percentile(95)),
+		ShadowLatencyP99: int(s.shadow.Percentile(99)),
+		ResultsCompared:  s.compared,
+		ResultMismatches: s.mismatches,
+	}
+	if s.operations > 0 {
+		c.TargetErrorRate = float64(s.targetErrors) / float64(s.operations)
+		c.ShadowErrorRate = float64(s.shadowErrors) / float64(s.operations)
+		c.LatencyDeltaMs = float64(s.deltaSum) / float64(s.operations)
+	}
+	return c
+}
+
+// shadowStats accumulates shadowed operations overall, by operation and
+// by tool.
+type shadowStats struct {
+	total       shadowPairStats
+	byOperation map[string]*shadowPairStats
+	byTool      map[string]*shadowPairStats
+}
+
+func shadowPairFor(m *map[string]*shadowPairStats, key string) *shadowPairStats {
+	if *m == nil {
+		*m = make(map[string]*shadowPairStats)
+	}
+	s := (*m)[key]
+	if s == nil {
+		s = &shadowPairStats{}
+		(*m)[key] = s
+	}
+	return s
+}
+
+func (s *shadowStats) add(operation string, op OperationResult) {
+	s.total.add(op)
+	shadowPairFor(&s.byOperation, operation).add(op)
+	if operation == "tools/call" && op.ToolName != "" {
+		shadowPairFor(&s.byTool, op.ToolName).add(op)
+	}
+}
+
+func (s *shadowStats) merge(o *shadowStats) {
+	s.total.merge(&o.total)
+	for key, stats := range o.byOperation {
+		shadowPairFor(&s.byOperation, key).merge(stats)
+	}
+	for key, stats := range o.byTool {
+		shadowPairFor(&s.byTool, key).merge(stats)
+	}
+}
+
+// metrics summarizes the shadowed operations. Returns nil if none were
+// shadowed.
+func (s *shadowStats) metrics() *ShadowReportMetrics {
+	if s.total.operations == 0 {
+		return nil
+	}
+	result := &ShadowReportMetrics{
+		ShadowComparison: s.total.comparison(),
+		ByOperation:      make(map[string]*ShadowComparison, len(s.byOperation)),
+	}
+	for key, stats := range s.byOperation {
+		c := stats.comparison()
+		result.ByOperation[key] = &c
+	}
+	if len(s.byTool) > 0 {
+		result.ByTool = make(map[string]*ShadowComparison, len(s.byTool))
+		for key, stats := range s.byTool {
+			c := stats.comparison()
+			result.ByTool[key] = &c
+		}
+	}
+	result.Findings = shadowFindings(result)
+	return result
+}
+
+// shadowFindings points out where the shadow did worse than the target:
+// more errors, a slower p95, or different results from stable tools.
+func shadowFindings(m *ShadowReportMetrics) []string {
+	var findings []string
+	if m.ShadowErrorRate > m.TargetErrorRate+shadowErrorRateMargin {
+		findings = append(findings, fmt.Sprintf("The shadow failed %.1f%% of operations, against %.1f%% on the target",
+			100*m.ShadowErrorRate, 100*m.TargetErrorRate))
+	}
+	if m.TargetLatencyP95 > 0 && float64(m.ShadowLatencyP95) > float64(m.TargetLatencyP95)*(1+shadowLatencyRegression) {
+		findings = append(findings, fmt.Sprintf("The shadow's p95 latency is %dms, against %dms on the target (+%.0f%%)",
+			m.ShadowLatencyP95, m.TargetLatencyP95, 100*(float64(m.ShadowLatencyP95)/float64(m.TargetLatencyP95)-1)))
+	}
+	tools := make([]string, 0, len(m.ByTool))
+	for tool := range m.ByTool {
+		tools = append(tools, tool)
+	}
+	sort.Strings(tools)
+	for _, tool := range tools {
+		if c := m.ByTool[tool]; c.ResultMismatches > 0 {
+			findings = append(findings, fmt.Sprintf("%s returned a different result on the shadow for %d of %d compared calls",
+				tool, c.ResultMismatches, c.ResultsCompared))
+		}
+	}
+	return findings
+}
@@ -0,0 +1,54 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShadowMetrics(t *testing.T) {
+	agg := NewAggregator()
+	agg.AddOperation(OperationResult{Operation: "tools/call", ToolName: "search", LatencyMs: 10, OK: true})
+	if metrics := agg.Compute(); metrics.Shadow != nil {
+		t.Fatalf("expected nil shadow metrics without a shadow, got %+v", metrics.Shadow)
+	}
+
+	match, mismatch := true, false
+	agg = NewAggregator()
+	for i := 0; i < 8; i++ {
+		agg.AddOperation(OperationResult{Operation: "tools/call", ToolName: "search", LatencyMs: 10, OK: true,
+			Shadow: &ShadowSample{LatencyMs: 20, OK: true, ResultMatch: &match}})
+	}
+	agg.AddOperation(OperationResult{Operation: "tools/call", ToolName: "search", LatencyMs: 10, OK: true,
+		Shadow: &ShadowSample{LatencyMs: 20, OK: true, ResultMatch: &mismatch}})
+	other := NewAggregator()
+	other.AddOperation(OperationResult{Operation: "tools/list", LatencyMs: 10, OK: true,
+		Shadow: &ShadowSample{LatencyMs: 20, OK: false}})
+	agg.Merge(other)
+
+	s := agg.Compute().Shadow
+	if s == nil {
+		t.Fatal("expected shadow metrics")
+	}
+	if s.Operations != 10 || s.TargetErrors != 0 || s.ShadowErrors != 1 || s.ShadowErrorRate != 0.1 {
+		t.Errorf("unexpected error counts: %+v", s.ShadowComparison)
+	}
+	if s.LatencyDeltaMs != 10 || s.TargetLatencyP95 >= s.ShadowLatencyP95 {
+		t.Errorf("expected the shadow 10ms slower, got %+v", s.ShadowComparison)
+	}
+	if s.ResultsCompared != 9 || s.ResultMismatches != 1 {
+		t.Errorf("expected 1 of 9 results to differ, got %+v", s.ShadowComparison)
+	}
+	if len(s.ByOperation) != 2 || s.ByOperation["tools/list"].ShadowErrors != 1 {
+		t.Errorf("unexpected by-operation comparison: %+v", s.ByOperation)
+	}
+	if search := s.ByTool["search"]; len(s.ByTool) != 1 || search == nil || search.Operations != 9 || search.ResultMismatches != 1 {
+		t.Errorf("unexpected by-tool comparison: %+v", s.ByTool)
+	}
+
+	findings := strings.Join(s.Findings, "\n")
+	for _, want := range []string{"failed 10.0% of operations", "p95 latency", "search returned a different result on the shadow for 1 of 9"} {
+		if !strings.Contains(findings, want) {
+			t.Errorf("expected a finding with %q, got %v", want, s.Findings)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package analysis
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SLO verdicts.
+const (
+	SLOVerdictPass = "PASS"
+	SLOVerdictFail = "FAIL"
+)
+
+// SLO metrics an objective can name. Rates are fractions; an objective may
+// write them as percentages, e.g. "error_rate < 1%".
+const (
+	SLOMetricP50LatencyMs = "p50_latency_ms"
+	SLOMetricP95LatencyMs = "p95_latency_ms"
+	SLOMetricP99LatencyMs = "p99_latency_ms"
+	SLOMetricErrorRate    = "error_rate"
+	SLOMetricRPS          = "rps"
+	SLOMetricCheckPass    = "check_pass_rate"
+)
+
+var sloObjectivePattern = regexp.MustCompile(`^\s*([a-z0-9_]+)\s*(<=|>=|<|>)\s*([0-9]+(?:\.[0-9]+)?)\s*(%?)\s*$`)
+
+// SLO is a service level objective from the run config, such as
+// "p95_latency_ms < 500". It applies to the whole run unless Operation or
+// ToolName narrows it.
+type SLO struct {
+	Name      string `json:"name,omitempty"`
+	Objective string `json:"objective"`
+	Operation string `json:"operation,omitempty"`
+	ToolName  string `json:"tool_name,omitempty"`
+}
+
+// SLOResult is the outcome of one SLO.
+type SLOResult struct {
+	Name      string `json:"name"`
+	Objective string `json:"objective"`
+	Operation string `json:"operation,omitempty"`
+	ToolName  string `json:"tool_name,omitempty"`
+
+	// Actual is the measured value, nil when no operation fell in scope.
+	Actual  *float64 `json:"actual"`
+	Passed  bool     `json:"passed"`
+	Message string   `json:"message,omitempty"`
+}
+
+// SLOReport is the machine-readable verdict of a run: PASS only if every
+// SLO passed.
+type SLOReport struct {
+	Verdict string      `json:"verdict"`
+	Results []SLOResult `json:"results"`
+}
+
+type sloObjective struct {
+	metric    string
+	op        string
+	threshold float64
+}
+
+// ValidateSLO reports whether an SLO can be evaluated.
+func ValidateSLO(slo SLO) error {
+	_, err := parseSLO(slo)
+	return err
+}
+
+func parseSLO(slo SLO) (*sloObjective, error) {
+	m := sloObjectivePattern.FindStringSubmatch(slo.Objective)
+	if m == nil {
+		return nil, fmt.Errorf("objective must look like \"p95_latency_ms < 500\"")
+	}
+	obj := &sloObjective{metric: m[1], op: m[2]}
+	obj.threshold, _ = strconv.ParseFloat(m[3], 64)
+	percent := m[4] == "%"
+
+	switch obj.metric {
+	case SLOMetricP50LatencyMs, SLOMetricP95LatencyMs, SLOMetricP99LatencyMs:
+		if percent {
+			return nil, fmt.Errorf("%s takes milliseconds, not a percentage", obj.metric)
+		}
+	case SLOMetricErrorRate, SLOMetricCheckPass:
+		if percent {
+			obj.threshold /= 100
+		}
+		if obj.threshold > 1 {
+			return nil, fmt.Errorf("%s is a fraction between 0 and 1; write percentages with %%", obj.metric)
+		}
+	case SLOMetricRPS:
+		if percent {
+			return nil, fmt.Errorf("rps takes operations per second, not a percentage")
+		}
+		if slo.Operation != "" || slo.ToolName != "" {
+			return nil, fmt.Errorf("rps applies to the whole run only")
+		}
+	default:
+		return nil, fmt.Errorf("unknown metric %q", obj.metric)
+	}
+
+	if slo.Operation != "" && slo.ToolName != "" {
+		return nil, fmt.Errorf("set operation or tool_name, not both")
+	}
+	return obj, nil
+}
+
+func (o *sloObjective) met(actual float64) bool {
+	switch o.op {
+	case "<":
+		return actual < o.threshold
+	case "<=":
+		return actual <= o.threshold
+	case ">":
+		return actual > o.threshold
+	default:
+		return actual >= o.threshold
+	}
+}
+
+// EvaluateSLOs evaluates SLOs against a run's metrics. Returns nil if no SLO
+// is defined. An SLO fails if it is invalid or nothing was measured in its
+// scope, so a misconfigured gate never passes silently.
+func EvaluateSLOs(slos []SLO, metrics *AggregatedMetrics) *SLOReport {
+	if len(slos) == 0 {
+		return nil
+	}
+	report := &SLOReport{Verdict: SLOVerdictPass, Results: make([]SLOResult, len(slos))}
+	for i, slo := range slos {
+		result := SLOResult{
+			Name:      slo.Name,
+			Objective: slo.Objective,
+			Operation: normalizeOpName(slo.Operation),
+			ToolName:  slo.ToolName,
+		}
+		if result.Name == "" {
+			result.Name = sloDefaultName(slo)
+		}
+
+		obj, err := parseSLO(slo)
+		switch {
+		case err != nil:
+			result.Message = "invalid objective: " + err.Error()
+		default:
+			actual, ok := sloActual(obj.metric, result.Operation, slo.ToolName, metrics)
+			if !ok {
+				result.Message = "no operations in scope"
+				break
+			}
+			result.Actual = &actual
+			result.Passed = obj.met(actual)
+		}
+
+		if !result.Passed {
+			report.Verdict = SLOVerdictFail
+		}
+		report.Results[i] = result
+	}
+	return report
+}
+
+func sloDefaultName(slo SLO) string {
+	name := strings.Join(strings.Fields(slo.Objective), " ")
+	switch {
+	case slo.ToolName != "":
+		name += " [" + slo.ToolName + "]"
+	case slo.Operation != "":
+		name += " [" + normalizeOpName(slo.Operation) + "]"
+	}
+	return name
+}
+
+// sloActual returns the value of metric in the scope of operation or tool.
+func sloActual(metric, operation, tool string, metrics *AggregatedMetrics) (float64, bool) {
+	if metrics == nil {
+		return 0, false
+	}
+	if metric == SLOMetricCheckPass {
+		return checkPassRate(metrics.Checks, operation, tool)
+	}
+	if metric == SLOMetricRPS {
+		return metrics.RPS, metrics.TotalOps > 0
+	}
+
+	scoped := &OperationMetrics{
+		TotalOps:   metrics.TotalOps,
+		LatencyP50: metrics.LatencyP50,
+		LatencyP95: metrics.LatencyP95,
+		LatencyP99: metrics.LatencyP99,
+		ErrorRate:  metrics.ErrorRate,
+	}
+	switch {
+	case tool != "":
+		scoped = metrics.ByTool[tool]
+	case operation != "":
+		scoped = metrics.ByOperation[operation]
+	}
+	if scoped == nil || scoped.TotalOps == 0 {
+		return 0, false
+	}
+
+	switch metric {
+	case SLOMetricP50LatencyMs:
+		return float64(scoped.LatencyP50), true
+	case SLOMetricP95LatencyMs:
+		return float64(scoped.LatencyP95), true
+	case SLOMetricP99LatencyMs:
+		return float64(scoped.LatencyP99), true
+	default:
+		return scoped.ErrorRate, true
+	}
+}
+
+// checkPassRate pools the response checks in scope.
+func checkPassRate(checks *CheckReportMetrics, operation, tool string) (float64, bool) {
+	if checks == nil {
+		return 0, false
+	}
+	var total, passed int
+	for _, c := range checks.Checks {
+		if (tool != "" && c.ToolName != tool) || (operation != "" && c.Operation != operation) {
+			continue
+		}
+		total += c.Total
+		passed += c.Passed
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return float64(passed) / float64(total), true
+}
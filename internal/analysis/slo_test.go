@@ -0,0 +1,94 @@
+package analysis
+
+import "testing"
+
+func TestEvaluateSLOs(t *testing.T) {
+	metrics := &AggregatedMetrics{
+		TotalOps:   100,
+		RPS:        50,
+		LatencyP95: 400,
+		ErrorRate:  0.02,
+		ByOperation: map[string]*OperationMetrics{
+			"tools/call": {TotalOps: 80, LatencyP95: 450, ErrorRate: 0.025},
+		},
+		ByTool: map[string]*OperationMetrics{
+			"search": {TotalOps: 80, LatencyP99: 900, ErrorRate: 0.025},
+		},
+		Checks: &CheckReportMetrics{Checks: []*CheckMetrics{
+			{Name: "a", Operation: "tools/call", ToolName: "search", Total: 80, Passed: 79},
+		}},
+	}
+
+	tests := []struct {
+		slo    SLO
+		passed bool
+		actual float64
+	}{
+		{SLO{Objective: "p95_latency_ms < 500"}, true, 400},
+		{SLO{Objective: "error_rate < 1%"}, false, 0.02},
+		{SLO{Objective: "error_rate<=0.03", Operation: "tools_call"}, true, 0.025},
+		{SLO{Objective: "p99_latency_ms < 800", ToolName: "search"}, false, 900},
+		{SLO{Objective: "rps >= 50"}, true, 50},
+		{SLO{Objective: "check_pass_rate > 98%", ToolName: "search"}, true, 79.0 / 80},
+	}
+	for _, tt := range tests {
+		report := EvaluateSLOs([]SLO{tt.slo}, metrics)
+		r := report.Results[0]
+		if r.Passed != tt.passed || r.Actual == nil || *r.Actual != tt.actual {
+			t.Errorf("%+v: got %+v", tt.slo, r)
+		}
+		if (report.Verdict == SLOVerdictPass) != tt.passed {
+			t.Errorf("%+v: verdict %s", tt.slo, report.Verdict)
+		}
+	}
+
+	report := EvaluateSLOs([]SLO{
+		{Objective: "p95_latency_ms < 500"},
+		{Name: "fetch latency", Objective: "p95_latency_ms < 500", ToolName: "fetch"},
+		{Objective: "p95_latency_ms < 5%"},
+	}, metrics)
+	if report.Verdict != SLOVerdictFail {
+		t.Errorf("verdict = %s, want FAIL", report.Verdict)
+	}
+	if r := report.Results[0]; r.Name != "p95_latency_ms < 500" || !r.Passed {
+		t.Errorf("unexpected result %+v", r)
+	}
+	if r := report.Results[1]; r.Name != "fetch latency" || r.Passed || r.Actual != nil || r.Message != "no operations in scope" {
+		t.Errorf("expected an SLO without data to fail, got %+v", r)
+	}
+	if r := report.Results[2]; r.Passed || r.Message == "" {
+		t.Errorf("expected an invalid SLO to fail, got %+v", r)
+	}
+
+	if EvaluateSLOs(nil, metrics) != nil {
+		t.Error("expected no report without SLOs")
+	}
+}
+
+func TestValidateSLO(t *testing.T) {
+	valid := []SLO{
+		{Objective: "p50_latency_ms <= 100"},
+		{Objective: "error_rate < 0.5%", Operation: "ping"},
+		{Objective: "check_pass_rate >= 0.99", ToolName: "search"},
+	}
+	for _, slo := range valid {
+		if err := ValidateSLO(slo); err != nil {
+			t.Errorf("ValidateSLO(%+v): %v", slo, err)
+		}
+	}
+
+	invalid := []SLO{
+		{Objective: ""},
+		{Objective: "p95 < 500"},
+		{Objective: "p95_latency_ms == 500"},
+		{Objective: "p95_latency_ms < 50%"},
+		{Objective: "error_rate < 5"},
+		{Objective: "rps > 10", ToolName: "search"},
+		{Objective: "error_rate < 1%", Operation: "tools/call", ToolName: "search"},
+	}
+	for _, slo := range invalid {
+		if err := ValidateSLO(slo); err == nil {
+			t.Errorf("ValidateSLO(%+v) succeeded, want error", slo)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package analysis
+
+import "sort"
+
+// StreamSample describes the SSE stream an operation's response arrived on.
+type StreamSample struct {
+	TimeToFirstEventMs      int64 // from the request to the first event
+	MaxEventGapMs           int64 // longest wait between two events
+	Progress                int   // progress notifications received
+	TimeToFirstProgressMs   int64 // from the request to the first progress notification
+	ProgressTokenMismatches int   // progress notifications with another request's token
+	ProgressInvalid         int   // progress notifications without increasing progress
+	Stalled                 bool  // no event arrived within the stall timeout
+}
+
+// StreamingMetrics summarizes the streamed responses of one tool. Large
+// first-event latencies show a slow first chunk; large event gaps show
+// stalls too short to hit the stall timeout.
+type StreamingMetrics struct {
+	Streams                 int   `json:"streams"`
+	TimeToFirstEventP50     int64 `json:"time_to_first_event_p50_ms"`
+	TimeToFirstEventP95     int64 `json:"time_to_first_event_p95_ms"`
+	MaxEventGapP95          int64 `json:"max_event_gap_p95_ms"`
+	MaxEventGap             int64 `json:"max_event_gap_ms"`
+	ProgressNotifications   int   `json:"progress_notifications"`
+	TimeToFirstProgressP95  int64 `json:"time_to_first_progress_p95_ms,omitempty"`
+	ProgressTokenMismatches int   `json:"progress_token_mismatches"`
+	ProgressInvalid         int   `json:"progress_invalid"`
+}
+
+// streamingStats accumulates the stream samples of one tool.
+type streamingStats struct {
+	firstEvent      Histogram
+	maxGap          Histogram
+	firstProgress   Histogram
+	progress        int
+	tokenMismatches int
+	invalid         int
+}
+
+func (s *streamingStats) add(sample *StreamSample) {
+	s.firstEvent.Record(sample.TimeToFirstEventMs)
+	s.maxGap.Record(sample.MaxEventGapMs)
+	if sample.Progress > 0 {
+		s.firstProgress.Record(sample.TimeToFirstProgressMs)
+	}
+	s.progress += sample.Progress
+	s.tokenMismatches += sample.ProgressTokenMismatches
+	s.invalid += sample.ProgressInvalid
+}
+
+func (s *streamingStats) merge(o *streamingStats) {
+	s.firstEvent.Merge(&o.firstEvent)
+	s.maxGap.Merge(&o.maxGap)
+	s.firstProgress.Merge(&o.firstProgress)
+	s.progress += o.progress
+	s.tokenMismatches += o.tokenMismatches
+	s.invalid += o.invalid
+}
+
+func (s *streamingStats) metrics() *StreamingMetrics {
+	m := &StreamingMetrics{
+		Streams:                 int(s.firstEvent.Count()),
+		TimeToFirstEventP50:     s.firstEvent.Percentile(50),
+		TimeToFirstEventP95:     s.firstEvent.Percentile(95),
+		MaxEventGapP95:          s.maxGap.Percentile(95),
+		MaxEventGap:             s.maxGap.Max(),
+		ProgressNotifications:   s.progress,
+		ProgressTokenMismatches: s.tokenMismatches,
+		ProgressInvalid:         s.invalid,
+	}
+	if s.firstProgress.Count() > 0 {
+		m.TimeToFirstProgressP95 = s.firstProgress.Percentile(95)
+	}
+	return m
+}
+
+// streamingFor returns the streaming stats of tool, creating them if needed.
+func streamingFor(m map[string]*streamingStats, tool string) *streamingStats {
+	s := m[tool]
+	if s == nil {
+		s = &streamingStats{}
+		m[tool] = s
+	}
+	return s
+}
+
+// streamingTools returns the tools in metrics sorted by name.
+func streamingTools(metrics map[string]*StreamingMetrics) []string {
+	tools := make([]string, 0, len(metrics))
+	for tool := range metrics {
+		tools = append(tools, tool)
+	}
+	sort.Strings(tools)
+	return tools
+}
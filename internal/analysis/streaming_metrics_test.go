@@ -0,0 +1,42 @@
+package analysis
+
+import "testing"
+
+func TestStreamingMetrics(t *testing.T) {
+	agg := NewAggregator()
+	agg.AddOperation(OperationResult{Operation: "tools/call", ToolName: "plain", LatencyMs: 10, OK: true})
+	if metrics := agg.Compute(); metrics.StreamingByTool != nil {
+		t.Fatalf("expected no streaming metrics without streams, got %+v", metrics.StreamingByTool)
+	}
+
+	for i := 1; i <= 10; i++ {
+		agg.AddOperation(OperationResult{Operation: "tools/call", ToolName: "summarize", LatencyMs: 100, OK: true, Stream: &StreamSample{
+			TimeToFirstEventMs: int64(10 * i),
+			MaxEventGapMs:      int64(100 * i),
+		}})
+	}
+	other := NewAggregator()
+	other.AddOperation(OperationResult{Operation: "tools/call", ToolName: "summarize", LatencyMs: 100, OK: true, Stream: &StreamSample{
+		TimeToFirstEventMs:      5,
+		MaxEventGapMs:           5000,
+		Progress:                4,
+		TimeToFirstProgressMs:   20,
+		ProgressTokenMismatches: 1,
+		ProgressInvalid:         2,
+	}})
+	agg.Merge(other)
+
+	m := agg.Compute().StreamingByTool["summarize"]
+	if m == nil {
+		t.Fatal("expected streaming metrics for summarize")
+	}
+	if m.Streams != 11 || m.MaxEventGap != 5000 || m.ProgressNotifications != 4 || m.ProgressTokenMismatches != 1 || m.ProgressInvalid != 2 {
+		t.Errorf("unexpected streaming metrics: %+v", m)
+	}
+	if m.TimeToFirstEventP95 < 90 || m.TimeToFirstEventP50 > m.TimeToFirstEventP95 || m.TimeToFirstProgressP95 != 20 {
+		t.Errorf("unexpected streaming percentiles: %+v", m)
+	}
+	if _, ok := agg.Compute().StreamingByTool["plain"]; ok {
+		t.Error("expected tools without streams left out")
+	}
+}
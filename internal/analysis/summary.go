@@ -0,0 +1,168 @@
+package analysis
+
+// OperationSummary is a worker's condensed record of the operations it ran
+// within one second that shared an operation, tool, stage, generator group,
+// target, worker, fuzz mutation, attempt index, warmup flag and workflow
+// step. It carries counts and a latency histogram rather than operations.
+type OperationSummary struct {
+	Operation    string
+	ToolName     string
+	Group        string
+	Target       string
+	Stage        string
+	WorkerID     string
+	FuzzMutation string
+	Attempt      int
+	Warmup       bool
+	TimestampMs  int64 // start of the second, Unix ms
+
+	Count      int
+	Errors     int
+	ErrorTypes map[string]int // failures by error type; untyped ones are left out
+	// Latency holds [latency_ms, count] pairs whose counts add up to Count.
+	Latency [][2]int
+
+	Workflow *WorkflowSummary // the workflow step the operations were, nil if none
+}
+
+// WorkflowSummary places summarized operations within a workflow and
+// counts the workflow runs that ended with them, by status.
+type WorkflowSummary struct {
+	Name          string
+	Step          string
+	Index         int
+	Completed     int
+	Failed        int
+	ExtractFailed int
+}
+
+// AddSummary adds the operations of a worker summary, folding its counts
+// and latency histogram into the aggregates without going through them one
+// by one. Summaries carry no sessions, connections, checks or think times,
+// so those sections only cover operations added in full.
+// Thread-safe for concurrent ingestion.
+func (a *Aggregator) AddSummary(s OperationSummary) {
+	if s.Count <= 0 {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if s.Warmup {
+		a.warmup += s.Count
+		return
+	}
+	normalizedOp := normalizeOpName(s.Operation)
+	a.total.addSummary(&s)
+	for errorType, n := range s.ErrorTypes {
+		a.signatures.addN(ErrorLog{
+			TimestampMs: s.TimestampMs,
+			Operation:   normalizedOp,
+			ToolName:    s.ToolName,
+			ErrorType:   errorType,
+		}, n)
+	}
+	if s.TimestampMs > 0 {
+		if s.Errors > 0 {
+			a.failuresBySecond[s.TimestampMs-s.TimestampMs%1000] += s.Errors
+		}
+		bucket := s.TimestampMs - s.TimestampMs%LoadBucketMs
+		if a.loadBuckets[bucket] == nil {
+			a.loadBuckets[bucket] = &loadStats{}
+		}
+		a.loadBuckets[bucket].addSummary(&s)
+		a.loadBuckets[bucket].stalls += s.ErrorTypes[streamStallErrorType]
+	}
+
+	statsFor(a.byOperation, normalizedOp).addSummary(&s)
+	if normalizedOp == "tools/call" && s.ToolName != "" {
+		statsFor(a.byTool, s.ToolName).addSummary(&s)
+	}
+	if s.Group != "" {
+		statsFor(a.byGroup, s.Group).addSummary(&s)
+	}
+	if s.Target != "" {
+		statsFor(a.byTarget, s.Target).addSummary(&s)
+	}
+	if s.WorkerID != "" {
+		statsFor(a.byWorker, s.WorkerID).addSummary(&s)
+	}
+
+	if s.FuzzMutation != "" {
+		a.fuzz.addSummary(&s)
+	}
+	a.throttle.throttled += s.ErrorTypes[errorTypeRateLimited]
+	if s.Workflow != nil {
+		a.workflows.addSummary(&s)
+	}
+	a.retries.addN(s.Attempt, s.Count, s.Count-s.Errors)
+}
+
+func (s *operationStats) addSummary(sum *OperationSummary) {
+	for _, bucket := range sum.Latency {
+		s.latency.RecordN(int64(bucket[0]), int64(bucket[1]))
+	}
+	s.success += sum.Count - sum.Errors
+	s.failure += sum.Errors
+}
+
+func (s *fuzzStats) addSummary(sum *OperationSummary) {
+	m := s.mutation(sum.FuzzMutation)
+	m.Requests += sum.Count
+	m.Accepted += sum.Count - sum.Errors
+	m.Unhandled += sum.Errors
+	for errorType, n := range sum.ErrorTypes {
+		if fuzzRejectionErrors[errorType] {
+			m.Rejected += n
+			m.Unhandled -= n
+		}
+		if m.ErrorTypes == nil {
+			m.ErrorTypes = make(map[string]int)
+		}
+		m.ErrorTypes[errorType] += n
+	}
+}
+
+func (s *workflowStats) addSummary(sum *OperationSummary) {
+	wf := s.workflow(sum.Workflow.Name)
+	if sum.Workflow.Index == 0 && sum.Attempt == 0 {
+		wf.runs += sum.Count
+	}
+	step := wf.step(sum.Workflow.Index, sum.Workflow.Step)
+	step.ops.addSummary(sum)
+	wf.completed += sum.Workflow.Completed
+	wf.failed += sum.Workflow.Failed
+	step.failed += sum.Workflow.Failed
+	wf.extractFailed += sum.Workflow.ExtractFailed
+	step.extractFailed += sum.Workflow.ExtractFailed
+}
+
+// AddSummary adds the operations of a worker summary. Summaries without a
+// timestamp are left out.
+func (t *TimeSeries) AddSummary(s OperationSummary) {
+	if s.TimestampMs <= 0 || s.Count <= 0 {
+		return
+	}
+	w := t.window(s.TimestampMs, s.Stage)
+	if w == nil {
+		return
+	}
+	w.stats(seriesKey{}).addSummary(&s)
+	opName := normalizeOpName(s.Operation)
+	if k := (seriesKey{name: opName}); t.track(k) {
+		w.stats(k).addSummary(&s)
+	}
+	if opName == "tools/call" && s.ToolName != "" {
+		if k := (seriesKey{tool: true, name: s.ToolName}); t.track(k) {
+			w.stats(k).addSummary(&s)
+		}
+	}
+}
+
+func (s *seriesStats) addSummary(sum *OperationSummary) {
+	s.success += sum.Count - sum.Errors
+	s.failure += sum.Errors
+	for _, bucket := range sum.Latency {
+		s.latency.recordN(int64(bucket[0]), int64(bucket[1]))
+	}
+}
@@ -0,0 +1,119 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+)
+
+// summaryOperations returns operations a worker would summarize as s.
+func summaryOperations(s OperationSummary) []OperationResult {
+	var ops []OperationResult
+	errorTypes := make([]string, 0, s.Errors)
+	for errorType, n := range s.ErrorTypes {
+		for i := 0; i < n; i++ {
+			errorTypes = append(errorTypes, errorType)
+		}
+	}
+	for _, bucket := range s.Latency {
+		for i := 0; i < bucket[1]; i++ {
+			op := OperationResult{
+				Operation:   s.Operation,
+				ToolName:    s.ToolName,
+				Stage:       s.Stage,
+				WorkerID:    s.WorkerID,
+				Attempt:     s.Attempt,
+				TimestampMs: s.TimestampMs,
+				LatencyMs:   bucket[0],
+				OK:          len(ops) >= s.Errors,
+			}
+			if !op.OK && len(ops) < len(errorTypes) {
+				op.ErrorType = errorTypes[len(ops)]
+			}
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
+
+func TestAggregator_AddSummary(t *testing.T) {
+	summaries := []OperationSummary{
+		{Operation: "tools_call", ToolName: "echo", Stage: "baseline", WorkerID: "w1", TimestampMs: 1000,
+			Count: 10, Errors: 3, ErrorTypes: map[string]int{"timeout": 2},
+			Latency: [][2]int{{5, 4}, {20, 5}, {3000, 1}}},
+		{Operation: "tools_call", ToolName: "echo", Stage: "baseline", WorkerID: "w1", TimestampMs: 2000, Attempt: 1,
+			Count: 2, Errors: 1, ErrorTypes: map[string]int{"rate_limited": 1},
+			Latency: [][2]int{{7, 2}}},
+		{Operation: "tools_list", Stage: "baseline", WorkerID: "w2", TimestampMs: 2000,
+			Count: 5, Latency: [][2]int{{1, 5}}},
+	}
+
+	summarized, full := NewAggregator(), NewAggregator()
+	for _, s := range summaries {
+		summarized.AddSummary(s)
+		for _, op := range summaryOperations(s) {
+			full.AddOperation(op)
+		}
+	}
+	summarized.AddSummary(OperationSummary{Operation: "tools_list", Warmup: true, Count: 4, Latency: [][2]int{{1, 4}}})
+	full.AddOperation(OperationResult{Operation: "tools_list", Warmup: true, OK: true})
+
+	got, want := summarized.Compute(), full.Compute()
+	if got.TotalOps != want.TotalOps || got.FailureOps != want.FailureOps {
+		t.Errorf("expected %d operations with %d failures, got %d with %d", want.TotalOps, want.FailureOps, got.TotalOps, got.FailureOps)
+	}
+	if got.LatencyP50 != want.LatencyP50 || got.LatencyP99 != want.LatencyP99 {
+		t.Errorf("expected p50 %d and p99 %d, got %d and %d", want.LatencyP50, want.LatencyP99, got.LatencyP50, got.LatencyP99)
+	}
+	if !reflect.DeepEqual(got.ByTool, want.ByTool) || !reflect.DeepEqual(got.ByOperation, want.ByOperation) {
+		t.Errorf("expected breakdowns %+v and %+v, got %+v and %+v", want.ByTool, want.ByOperation, got.ByTool, got.ByOperation)
+	}
+	if !reflect.DeepEqual(got.Retries, want.Retries) {
+		t.Errorf("expected retries %+v, got %+v", want.Retries, got.Retries)
+	}
+	if len(got.ErrorSignatures) != len(want.ErrorSignatures) {
+		t.Fatalf("expected %d error signatures, got %d", len(want.ErrorSignatures), len(got.ErrorSignatures))
+	}
+	for i := range got.ErrorSignatures {
+		if got.ErrorSignatures[i].Count != want.ErrorSignatures[i].Count {
+			t.Errorf("signature %d: expected count %d, got %d", i, want.ErrorSignatures[i].Count, got.ErrorSignatures[i].Count)
+		}
+	}
+	if summarized.warmup != 4 {
+		t.Errorf("expected 4 warmup operations, got %d", summarized.warmup)
+	}
+}
+
+func TestAggregator_AddSummaryWorkflow(t *testing.T) {
+	a := NewAggregator()
+	a.AddSummary(OperationSummary{Operation: "tools_call", ToolName: "search", Count: 6, Errors: 1,
+		Latency:  [][2]int{{10, 6}},
+		Workflow: &WorkflowSummary{Name: "discover", Step: "search", Index: 0}})
+	a.AddSummary(OperationSummary{Operation: "tools_call", ToolName: "fetch", Count: 5, Errors: 2,
+		Latency:  [][2]int{{10, 5}},
+		Workflow: &WorkflowSummary{Name: "discover", Step: "fetch", Index: 1, Completed: 3, Failed: 2}})
+
+	wf := a.Compute().Workflows["discover"]
+	if wf == nil {
+		t.Fatal("expected the discover workflow reported")
+	}
+	if wf.Runs != 6 || wf.Completed != 3 || wf.Failed != 2 {
+		t.Errorf("expected 6 runs, 3 completed and 2 failed, got %+v", wf)
+	}
+	if len(wf.Steps) != 2 || wf.Steps[1].TotalOps != 5 || wf.Steps[1].RunsFailed != 2 {
+		t.Errorf("expected 5 operations and 2 failed runs at step fetch, got %+v", wf.Steps)
+	}
+}
+
+func TestTimeSeries_AddSummary(t *testing.T) {
+	s := OperationSummary{Operation: "tools_call", ToolName: "echo", Stage: "baseline", TimestampMs: 3000,
+		Count: 10, Errors: 3, Latency: [][2]int{{5, 4}, {20, 5}, {3000, 1}}}
+
+	summarized, full := NewTimeSeries(), NewTimeSeries()
+	summarized.AddSummary(s)
+	for _, op := range summaryOperations(s) {
+		full.Add(op)
+	}
+	if got, want := summarized.Report(0), full.Report(0); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
@@ -0,0 +1,84 @@
+package analysis
+
+// errorTypeRateLimited is the error type of operations the target rate
+// limited.
+const errorTypeRateLimited = "rate_limited"
+
+// ThrottleReportMetrics reports how often the target rate limited the run
+// and what the run achieved under throttling. EffectiveRPS counts only
+// successful operations, so it is the throughput the target actually
+// served.
+type ThrottleReportMetrics struct {
+	Throttled    int     `json:"throttled"`
+	ThrottleRate float64 `json:"throttle_rate"`
+	// WithRetryAfter is the number of rate limited responses that said when
+	// to retry.
+	WithRetryAfter   int     `json:"with_retry_after"`
+	MeanRetryAfterMs float64 `json:"mean_retry_after_ms,omitempty"`
+	MaxRetryAfterMs  int64   `json:"max_retry_after_ms,omitempty"`
+	// HeldBack is the number of operations VUs delayed after rate limited
+	// responses, for WaitMs in total.
+	HeldBack     int     `json:"held_back"`
+	WaitMs       int64   `json:"wait_ms"`
+	EffectiveRPS float64 `json:"effective_rps"`
+}
+
+// throttleStats accumulates rate limited operations and throttling delays.
+type throttleStats struct {
+	throttled       int
+	withRetryAfter  int
+	retryAfterTotal int64
+	maxRetryAfter   int64
+	heldBack        int
+	waitMs          int64
+}
+
+func (s *throttleStats) add(op OperationResult) {
+	if op.ErrorType == errorTypeRateLimited {
+		s.throttled++
+		if op.RetryAfterMs > 0 {
+			s.withRetryAfter++
+			s.retryAfterTotal += op.RetryAfterMs
+			s.maxRetryAfter = max(s.maxRetryAfter, op.RetryAfterMs)
+		}
+	}
+	if op.ThrottleWaitMs > 0 {
+		s.heldBack++
+		s.waitMs += op.ThrottleWaitMs
+	}
+}
+
+func (s *throttleStats) merge(o *throttleStats) {
+	s.throttled += o.throttled
+	s.withRetryAfter += o.withRetryAfter
+	s.retryAfterTotal += o.retryAfterTotal
+	s.maxRetryAfter = max(s.maxRetryAfter, o.maxRetryAfter)
+	s.heldBack += o.heldBack
+	s.waitMs += o.waitMs
+}
+
+// metrics summarizes throttling over total operations, success of which
+// succeeded within durationSec. Returns nil if nothing was rate limited or
+// held back.
+func (s *throttleStats) metrics(total, success int, durationSec float64) *ThrottleReportMetrics {
+	if s.throttled == 0 && s.heldBack == 0 {
+		return nil
+	}
+	result := &ThrottleReportMetrics{
+		Throttled:       s.throttled,
+		WithRetryAfter:  s.withRetryAfter,
+		MaxRetryAfterMs: s.maxRetryAfter,
+		HeldBack:        s.heldBack,
+		WaitMs:          s.waitMs,
+	}
+	if total > 0 {
+		result.ThrottleRate = float64(s.throttled) / float64(total)
+	}
+	if s.withRetryAfter > 0 {
+		result.MeanRetryAfterMs = float64(s.retryAfterTotal) / float64(s.withRetryAfter)
+	}
+	if durationSec > 0 {
+		result.EffectiveRPS = float64(success) / durationSec
+	}
+	return result
+}
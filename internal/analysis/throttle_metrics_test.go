@@ -0,0 +1,39 @@
+package analysis
+
+import "testing"
+
+func TestThrottleMetrics(t *testing.T) {
+	agg := NewAggregator()
+	agg.SetTimeRange(0, 10000)
+	for i := 0; i < 5; i++ {
+		agg.AddOperation(OperationResult{Operation: "tools/call", LatencyMs: 10, OK: true})
+	}
+	if metrics := agg.Compute(); metrics.Throttling != nil {
+		t.Fatalf("expected nil throttling metrics without rate limiting, got %+v", metrics.Throttling)
+	}
+
+	agg.AddOperation(OperationResult{Operation: "tools/call", LatencyMs: 10, ErrorType: "rate_limited", RetryAfterMs: 1000})
+	agg.AddOperation(OperationResult{Operation: "tools/call", LatencyMs: 10, ErrorType: "rate_limited"})
+	agg.AddOperation(OperationResult{Operation: "tools/call", LatencyMs: 10, OK: true, ThrottleWaitMs: 1000})
+	other := NewAggregator()
+	other.AddOperation(OperationResult{Operation: "tools/call", LatencyMs: 10, ErrorType: "rate_limited", RetryAfterMs: 3000})
+	other.AddOperation(OperationResult{Operation: "tools/call", LatencyMs: 10, OK: true, ThrottleWaitMs: 500})
+	agg.Merge(other)
+
+	th := agg.Compute().Throttling
+	if th == nil {
+		t.Fatal("expected throttling metrics")
+	}
+	if th.Throttled != 3 || th.WithRetryAfter != 2 || th.HeldBack != 2 || th.WaitMs != 1500 {
+		t.Errorf("unexpected throttling counts: %+v", th)
+	}
+	if th.MeanRetryAfterMs != 2000 || th.MaxRetryAfterMs != 3000 {
+		t.Errorf("expected Retry-After mean 2000 and max 3000, got %+v", th)
+	}
+	if th.ThrottleRate != 0.3 {
+		t.Errorf("expected throttle rate 0.3, got %f", th.ThrottleRate)
+	}
+	if th.EffectiveRPS != 0.7 {
+		t.Errorf("expected 7 successes over 10s, got %f", th.EffectiveRPS)
+	}
+}
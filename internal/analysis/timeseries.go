@@ -0,0 +1,324 @@
+package analysis
+
+import (
+	"math"
+	"math/bits"
+	"sort"
+)
+
+const (
+	// DefaultTimeSeriesWindowMs is the width of the windows a TimeSeries
+	// collects operations in. Coarser series are merged from them.
+	DefaultTimeSeriesWindowMs = 5000
+	// maxTimeSeriesWindows caps the windows kept, 24 hours of 5s windows.
+	// Operations later than that are left out and the series flagged
+	// truncated.
+	maxTimeSeriesWindows = 17280
+	// maxTimeSeriesKeys caps the operations and tools given a series of
+	// their own; operations of others only count in the total.
+	maxTimeSeriesKeys = 200
+)
+
+// WindowPoint holds the operations of one window of a time series.
+type WindowPoint struct {
+	TimestampMs int64 `json:"timestamp"`
+	// Stage is the stage of the window's last operation.
+	Stage string `json:"stage,omitempty"`
+
+	Ops        int     `json:"ops"`
+	Failures   int     `json:"failures"`
+	RPS        float64 `json:"rps"`
+	ErrorRate  float64 `json:"error_rate"`
+	LatencyP50 int     `json:"latency_p50"`
+	LatencyP95 int     `json:"latency_p95"`
+	LatencyP99 int     `json:"latency_p99"`
+}
+
+// TimeSeriesReport is a run's operations over time, for the run as a whole
+// and by operation and tool. Windows without operations are left out of
+// each series.
+type TimeSeriesReport struct {
+	WindowMs    int64                    `json:"window_ms"`
+	Total       []WindowPoint            `json:"total"`
+	ByOperation map[string][]WindowPoint `json:"by_operation,omitempty"`
+	ByTool      map[string][]WindowPoint `json:"by_tool,omitempty"`
+	// Truncated is set when operations past maxTimeSeriesWindows windows
+	// were left out.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// TimeSeries collects operations into windows of DefaultTimeSeriesWindowMs
+// as they are added, keeping counts and a latency sketch per window rather
+// than the operations. It is not safe for concurrent use.
+type TimeSeries struct {
+	windows   map[int64]seriesWindow
+	stages    map[int64]string
+	keys      map[seriesKey]struct{}
+	truncated bool
+}
+
+// seriesKey names a series: "" for the total, or an operation or tool.
+type seriesKey struct {
+	tool bool
+	name string
+}
+
+// seriesWindow holds one window of each series.
+type seriesWindow map[seriesKey]*seriesStats
+
+type seriesStats struct {
+	success, failure int
+	latency          latencySketch
+}
+
+// NewTimeSeries returns an empty TimeSeries.
+func NewTimeSeries() *TimeSeries {
+	return &TimeSeries{
+		windows: make(map[int64]seriesWindow),
+		stages:  make(map[int64]string),
+		keys:    make(map[seriesKey]struct{}),
+	}
+}
+
+// Add adds an operation. Operations without a timestamp are left out.
+func (t *TimeSeries) Add(op OperationResult) {
+	if op.TimestampMs <= 0 {
+		return
+	}
+	w := t.window(op.TimestampMs, op.Stage)
+	if w == nil {
+		return
+	}
+
+	w.stats(seriesKey{}).add(op)
+	opName := normalizeOpName(op.Operation)
+	if k := (seriesKey{name: opName}); t.track(k) {
+		w.stats(k).add(op)
+	}
+	if opName == "tools/call" && op.ToolName != "" {
+		if k := (seriesKey{tool: true, name: op.ToolName}); t.track(k) {
+			w.stats(k).add(op)
+		}
+	}
+}
+
+// window returns the window of timestampMs, noting stage as its stage, or
+// nil once there are too many windows.
+func (t *TimeSeries) window(timestampMs int64, stage string) seriesWindow {
+	start := timestampMs - timestampMs%DefaultTimeSeriesWindowMs
+	w := t.windows[start]
+	if w == nil {
+		if len(t.windows) >= maxTimeSeriesWindows {
+			t.truncated = true
+			return nil
+		}
+		w = make(seriesWindow)
+		t.windows[start] = w
+	}
+	if stage != "" {
+		t.stages[start] = stage
+	}
+	return w
+}
+
+// track reports whether k gets a series of its own.
+func (t *TimeSeries) track(k seriesKey) bool {
+	if _, ok := t.keys[k]; ok {
+		return true
+	}
+	if len(t.keys) >= maxTimeSeriesKeys {
+		return false
+	}
+	t.keys[k] = struct{}{}
+	return true
+}
+
+func (w seriesWindow) stats(k seriesKey) *seriesStats {
+	s := w[k]
+	if s == nil {
+		s = &seriesStats{}
+		w[k] = s
+	}
+	return s
+}
+
+func (s *seriesStats) add(op OperationResult) {
+	if op.OK {
+		s.success++
+	} else {
+		s.failure++
+	}
+	s.latency.record(int64(op.LatencyMs))
+}
+
+func (s *seriesStats) merge(o *seriesStats) {
+	s.success += o.success
+	s.failure += o.failure
+	s.latency.merge(&o.latency)
+}
+
+func (s *seriesStats) point(startMs, windowMs int64, stage string) WindowPoint {
+	ops := s.success + s.failure
+	p := WindowPoint{
+		TimestampMs: startMs,
+		Stage:       stage,
+		Ops:         ops,
+		Failures:    s.failure,
+		RPS:         float64(ops) / (float64(windowMs) / 1000),
+		LatencyP50:  int(s.latency.percentile(50)),
+		LatencyP95:  int(s.latency.percentile(95)),
+		LatencyP99:  int(s.latency.percentile(99)),
+	}
+	if ops > 0 {
+		p.ErrorRate = float64(s.failure) / float64(ops)
+	}
+	return p
+}
+
+// Report returns the series in windows of windowMs, which is rounded up to
+// a multiple of DefaultTimeSeriesWindowMs; windows are aligned to multiples
+// of it in Unix time. It does not modify t.
+func (t *TimeSeries) Report(windowMs int64) *TimeSeriesReport {
+	if windowMs < DefaultTimeSeriesWindowMs {
+		windowMs = DefaultTimeSeriesWindowMs
+	}
+	if r := windowMs % DefaultTimeSeriesWindowMs; r != 0 {
+		windowMs += DefaultTimeSeriesWindowMs - r
+	}
+	return t.report(windowMs, math.MinInt64, math.MaxInt64)
+}
+
+// Windows returns the series in windows of DefaultTimeSeriesWindowMs,
+// keeping those that start from fromMs up to toMs.
+func (t *TimeSeries) Windows(fromMs, toMs int64) *TimeSeriesReport {
+	return t.report(DefaultTimeSeriesWindowMs, fromMs, toMs)
+}
+
+func (t *TimeSeries) report(windowMs, fromMs, toMs int64) *TimeSeriesReport {
+	merged := make(map[int64]seriesWindow)
+	stages := make(map[int64]string)
+	latest := make(map[int64]int64)
+	for start, w := range t.windows {
+		if start < fromMs || start >= toMs {
+			continue
+		}
+		mergedStart := start - start%windowMs
+		m := merged[mergedStart]
+		if m == nil {
+			m = make(seriesWindow, len(w))
+			merged[mergedStart] = m
+		}
+		for k, s := range w {
+			m.stats(k).merge(s)
+		}
+		if stage, ok := t.stages[start]; ok && (stages[mergedStart] == "" || start > latest[mergedStart]) {
+			stages[mergedStart], latest[mergedStart] = stage, start
+		}
+	}
+	starts := make([]int64, 0, len(merged))
+	for start := range merged {
+		starts = append(starts, start)
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i] < starts[j] })
+
+	report := &TimeSeriesReport{
+		WindowMs:  windowMs,
+		Total:     make([]WindowPoint, 0, len(starts)),
+		Truncated: t.truncated,
+	}
+	for _, start := range starts {
+		for k, s := range merged[start] {
+			p := s.point(start, windowMs, stages[start])
+			switch {
+			case k == (seriesKey{}):
+				report.Total = append(report.Total, p)
+			case k.tool:
+				if report.ByTool == nil {
+					report.ByTool = make(map[string][]WindowPoint)
+				}
+				report.ByTool[k.name] = append(report.ByTool[k.name], p)
+			default:
+				if report.ByOperation == nil {
+					report.ByOperation = make(map[string][]WindowPoint)
+				}
+				report.ByOperation[k.name] = append(report.ByOperation[k.name], p)
+			}
+		}
+	}
+	return report
+}
+
+// sketchSubBucketBits sets the precision of a latencySketch, coarser than
+// a Histogram's: values below 64 are exact, larger ones within 1/32.
+const sketchSubBucketBits = 5
+
+// latencySketch is a sparse log-linear histogram. A run has thousands of
+// windows per series, too many for a dense Histogram each.
+type latencySketch struct {
+	counts map[int32]int64
+	count  int64
+}
+
+func sketchIndex(v int64) int32 {
+	if v < 2<<sketchSubBucketBits {
+		return int32(v)
+	}
+	shift := bits.Len64(uint64(v)) - 1 - sketchSubBucketBits
+	return int32(shift<<sketchSubBucketBits) + int32(v>>shift)
+}
+
+func sketchValue(i int32) int64 {
+	if i < 2<<sketchSubBucketBits {
+		return int64(i)
+	}
+	shift := i>>sketchSubBucketBits - 1
+	return int64(i-shift<<sketchSubBucketBits) << shift
+}
+
+func (s *latencySketch) record(v int64) {
+	s.recordN(v, 1)
+}
+
+// recordN adds n occurrences of v.
+func (s *latencySketch) recordN(v, n int64) {
+	if n <= 0 {
+		return
+	}
+	if s.counts == nil {
+		s.counts = make(map[int32]int64)
+	}
+	s.counts[sketchIndex(max(v, 0))] += n
+	s.count += n
+}
+
+func (s *latencySketch) merge(o *latencySketch) {
+	if s.counts == nil {
+		s.counts = make(map[int32]int64, len(o.counts))
+	}
+	for i, c := range o.counts {
+		s.counts[i] += c
+	}
+	s.count += o.count
+}
+
+// percentile picks the value at percentile p the way Histogram.Percentile
+// does, returning the lowest value of its bucket.
+func (s *latencySketch) percentile(p float64) int64 {
+	if s.count == 0 {
+		return 0
+	}
+	rank := min(max(int64(p/100*float64(s.count)), 0), s.count-1)
+	idx := make([]int32, 0, len(s.counts))
+	for i := range s.counts {
+		idx = append(idx, i)
+	}
+	sort.Slice(idx, func(a, b int) bool { return idx[a] < idx[b] })
+	var seen int64
+	for _, i := range idx {
+		seen += s.counts[i]
+		if seen > rank {
+			return sketchValue(i)
+		}
+	}
+	return sketchValue(idx[len(idx)-1])
+}
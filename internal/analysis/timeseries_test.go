@@ -0,0 +1,80 @@
+package analysis
+
+import "testing"
+
+func TestTimeSeries_Report(t *testing.T) {
+	ts := NewTimeSeries()
+	const start = 1_700_000_010_000 // aligned to 15s
+	for i := 0; i < 100; i++ {
+		ts.Add(OperationResult{Operation: "tools_call", ToolName: "search", LatencyMs: 10 + i, OK: true, TimestampMs: start + 1000})
+	}
+	ts.Add(OperationResult{Operation: "ping", LatencyMs: 5, OK: false, Stage: "baseline", TimestampMs: start + 6000})
+	ts.Add(OperationResult{Operation: "ping", LatencyMs: 5, OK: true, Stage: "ramp", TimestampMs: start + 12_000})
+	ts.Add(OperationResult{Operation: "ping", LatencyMs: 5, OK: true})
+
+	report := ts.Report(0)
+	if report.WindowMs != DefaultTimeSeriesWindowMs || len(report.Total) != 3 {
+		t.Fatalf("expected 3 windows of %dms, got %+v", DefaultTimeSeriesWindowMs, report)
+	}
+	// 109 falls in the sketch's bucket from 108.
+	first := report.Total[0]
+	if first.TimestampMs != start || first.Ops != 100 || first.RPS != 20 || first.LatencyP50 != 60 || first.LatencyP99 != 108 {
+		t.Errorf("unexpected first window %+v", first)
+	}
+	if p := report.Total[1]; p.Failures != 1 || p.ErrorRate != 1 || p.Stage != "baseline" {
+		t.Errorf("expected the failed ping in the second window, got %+v", p)
+	}
+	if got := report.ByTool["search"]; len(got) != 1 || got[0].Ops != 100 {
+		t.Errorf("unexpected search series %+v", got)
+	}
+	if got := report.ByOperation["ping"]; len(got) != 2 {
+		t.Errorf("expected 2 ping windows, got %+v", got)
+	}
+
+	coarse := ts.Report(12_000)
+	if coarse.WindowMs != 15_000 || len(coarse.Total) != 1 || coarse.Total[0].Ops != 102 || coarse.Total[0].Failures != 1 {
+		t.Errorf("expected one 15s window of 102 ops, got %+v", coarse)
+	}
+	if stage := coarse.Total[0].Stage; stage != "ramp" {
+		t.Errorf("expected a merged window to take the stage of its last, got %q", stage)
+	}
+
+	windows := ts.Windows(start+5000, start+15_000)
+	if len(windows.Total) != 2 || windows.Total[0].TimestampMs != start+5000 || len(windows.ByTool) != 0 {
+		t.Errorf("expected the second and third windows, got %+v", windows)
+	}
+}
+
+func TestTimeSeries_Limits(t *testing.T) {
+	ts := NewTimeSeries()
+	for i := 0; i < maxTimeSeriesKeys+10; i++ {
+		ts.Add(OperationResult{Operation: "tools/call", ToolName: string(rune('a'+i%26)) + string(rune('a'+i/26)), OK: true, TimestampMs: 5000})
+	}
+	report := ts.Report(DefaultTimeSeriesWindowMs)
+	if n := len(report.ByTool) + len(report.ByOperation); n != maxTimeSeriesKeys {
+		t.Errorf("expected %d series, got %d", maxTimeSeriesKeys, n)
+	}
+	if report.Total[0].Ops != maxTimeSeriesKeys+10 {
+		t.Errorf("expected every operation in the total, got %d", report.Total[0].Ops)
+	}
+
+	for i := 1; i <= maxTimeSeriesWindows; i++ {
+		ts.Add(OperationResult{Operation: "ping", OK: true, TimestampMs: int64(i+1) * DefaultTimeSeriesWindowMs})
+	}
+	if report := ts.Report(DefaultTimeSeriesWindowMs); !report.Truncated || len(report.Total) != maxTimeSeriesWindows {
+		t.Errorf("expected %d windows and truncation, got %d, %v", maxTimeSeriesWindows, len(report.Total), report.Truncated)
+	}
+}
+
+func TestLatencySketch(t *testing.T) {
+	var s latencySketch
+	for _, v := range []int64{-3, 7, 63, 64, 1000, 123_456} {
+		s.record(v)
+		got := s.percentile(100)
+		want := max(v, 0)
+		if got > want || float64(want-got) > float64(want)/32 {
+			t.Errorf("value %d came back as %d", v, got)
+		}
+		s = latencySketch{}
+	}
+}
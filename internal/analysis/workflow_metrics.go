@@ -0,0 +1,166 @@
+package analysis
+
+import "sort"
+
+// Statuses a workflow run ends with, as set on the step that ended it.
+const (
+	workflowCompleted     = "completed"
+	workflowFailed        = "failed"
+	workflowExtractFailed = "extract_failed"
+)
+
+// WorkflowSample places an operation within a workflow run.
+type WorkflowSample struct {
+	Name   string // workflow the operation was a step of
+	Step   string // name of the step
+	Index  int    // index of the step, 0 for the first
+	Status string // how the run ended at this step, empty if it went on
+}
+
+// WorkflowReportMetrics reports the runs of a workflow: how many got through
+// every step, and where the others stopped. Runs still going when the run
+// ended count towards Runs only.
+type WorkflowReportMetrics struct {
+	Runs           int     `json:"runs"`
+	Completed      int     `json:"completed"`
+	Failed         int     `json:"failed"`
+	ExtractFailed  int     `json:"extract_failed"`
+	CompletionRate float64 `json:"completion_rate"`
+	// Steps are the operations of each step, in workflow order.
+	Steps []*WorkflowStepMetrics `json:"steps"`
+}
+
+// WorkflowStepMetrics is the operations run as one step of a workflow.
+// RunsFailed and RunsExtractFailed count the runs that stopped at the step:
+// because it failed, or because its result lacked a value later steps use.
+type WorkflowStepMetrics struct {
+	Index int    `json:"index"`
+	Step  string `json:"step"`
+	OperationMetrics
+	RunsFailed        int `json:"runs_failed"`
+	RunsExtractFailed int `json:"runs_extract_failed"`
+}
+
+// workflowStats accumulates the steps of workflow runs by workflow.
+type workflowStats struct {
+	byWorkflow map[string]*workflowRunStats
+}
+
+type workflowRunStats struct {
+	runs          int
+	completed     int
+	failed        int
+	extractFailed int
+	steps         map[int]*workflowStepStats
+}
+
+type workflowStepStats struct {
+	name          string
+	ops           operationStats
+	failed        int
+	extractFailed int
+}
+
+func (s *workflowStats) add(op OperationResult) {
+	sample := op.Workflow
+	wf := s.workflow(sample.Name)
+	if sample.Index == 0 && op.Attempt == 0 {
+		wf.runs++
+	}
+	step := wf.step(sample.Index, sample.Step)
+	step.ops.add(op)
+	switch sample.Status {
+	case workflowCompleted:
+		wf.completed++
+	case workflowFailed:
+		wf.failed++
+		step.failed++
+	case workflowExtractFailed:
+		wf.extractFailed++
+		step.extractFailed++
+	}
+}
+
+// workflow returns the stats of a workflow, creating them if needed.
+func (s *workflowStats) workflow(name string) *workflowRunStats {
+	if s.byWorkflow == nil {
+		s.byWorkflow = make(map[string]*workflowRunStats)
+	}
+	wf := s.byWorkflow[name]
+	if wf == nil {
+		wf = &workflowRunStats{steps: make(map[int]*workflowStepStats)}
+		s.byWorkflow[name] = wf
+	}
+	return wf
+}
+
+func (s *workflowRunStats) step(index int, name string) *workflowStepStats {
+	step := s.steps[index]
+	if step == nil {
+		step = &workflowStepStats{name: name}
+		s.steps[index] = step
+	}
+	return step
+}
+
+func (s *workflowStats) merge(o *workflowStats) {
+	for name, src := range o.byWorkflow {
+		wf := s.workflow(name)
+		wf.runs += src.runs
+		wf.completed += src.completed
+		wf.failed += src.failed
+		wf.extractFailed += src.extractFailed
+		for index, srcStep := range src.steps {
+			step := wf.step(index, srcStep.name)
+			step.ops.merge(&srcStep.ops)
+			step.failed += srcStep.failed
+			step.extractFailed += srcStep.extractFailed
+		}
+	}
+}
+
+// metrics reports each workflow. Returns nil if no workflow ran.
+func (s *workflowStats) metrics() map[string]*WorkflowReportMetrics {
+	if len(s.byWorkflow) == 0 {
+		return nil
+	}
+	result := make(map[string]*WorkflowReportMetrics, len(s.byWorkflow))
+	for name, wf := range s.byWorkflow {
+		m := &WorkflowReportMetrics{
+			Runs:          wf.runs,
+			Completed:     wf.completed,
+			Failed:        wf.failed,
+			ExtractFailed: wf.extractFailed,
+		}
+		if wf.runs > 0 {
+			m.CompletionRate = float64(wf.completed) / float64(wf.runs)
+		}
+		indexes := make([]int, 0, len(wf.steps))
+		for index := range wf.steps {
+			indexes = append(indexes, index)
+		}
+		sort.Ints(indexes)
+		for _, index := range indexes {
+			step := wf.steps[index]
+			m.Steps = append(m.Steps, &WorkflowStepMetrics{
+				Index:             index,
+				Step:              step.name,
+				OperationMetrics:  *step.ops.metrics(),
+				RunsFailed:        step.failed,
+				RunsExtractFailed: step.extractFailed,
+			})
+		}
+		result[name] = m
+	}
+	return result
+}
+
+// workflowNames returns the workflows of m in name order.
+func workflowNames(m map[string]*WorkflowReportMetrics) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
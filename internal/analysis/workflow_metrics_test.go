@@ -0,0 +1,47 @@
+package analysis
+
+import "testing"
+
+func TestWorkflowMetrics(t *testing.T) {
+	agg := NewAggregator()
+	agg.AddOperation(OperationResult{Operation: "tools/list", LatencyMs: 10, OK: true})
+	if metrics := agg.Compute(); metrics.Workflows != nil {
+		t.Fatalf("expected nil workflow metrics without workflows, got %+v", metrics.Workflows)
+	}
+
+	step := func(index int, name, status string) *WorkflowSample {
+		return &WorkflowSample{Name: "discover", Step: name, Index: index, Status: status}
+	}
+	// A run that completes.
+	agg.AddOperation(OperationResult{Operation: "tools/list", LatencyMs: 10, OK: true, Workflow: step(0, "list", "")})
+	agg.AddOperation(OperationResult{Operation: "tools/call", ToolName: "echo", LatencyMs: 20, OK: true, Workflow: step(1, "call", workflowCompleted)})
+	// A run whose call fails once, is retried, and fails for good.
+	agg.AddOperation(OperationResult{Operation: "tools/list", LatencyMs: 10, OK: true, Workflow: step(0, "list", "")})
+	agg.AddOperation(OperationResult{Operation: "tools/call", ToolName: "echo", LatencyMs: 20, Workflow: step(1, "call", "")})
+	agg.AddOperation(OperationResult{Operation: "tools/call", ToolName: "echo", LatencyMs: 20, Attempt: 1, Workflow: step(1, "call", workflowFailed)})
+	other := NewAggregator()
+	// A run whose listing lacks the value the call needs.
+	other.AddOperation(OperationResult{Operation: "tools/list", LatencyMs: 10, OK: true, Workflow: step(0, "list", workflowExtractFailed)})
+	agg.Merge(other)
+
+	w := agg.Compute().Workflows["discover"]
+	if w == nil {
+		t.Fatal("expected discover workflow metrics")
+	}
+	if w.Runs != 3 || w.Completed != 1 || w.Failed != 1 || w.ExtractFailed != 1 {
+		t.Errorf("unexpected workflow runs: %+v", w)
+	}
+	if w.CompletionRate < 0.33 || w.CompletionRate > 0.34 {
+		t.Errorf("CompletionRate = %v, want 1/3", w.CompletionRate)
+	}
+	if len(w.Steps) != 2 {
+		t.Fatalf("expected 2 steps, got %d", len(w.Steps))
+	}
+	list, call := w.Steps[0], w.Steps[1]
+	if list.Step != "list" || list.TotalOps != 3 || list.RunsExtractFailed != 1 || list.RunsFailed != 0 {
+		t.Errorf("unexpected list step: %+v", list)
+	}
+	if call.Step != "call" || call.Index != 1 || call.TotalOps != 3 || call.RunsFailed != 1 {
+		t.Errorf("unexpected call step: %+v", call)
+	}
+}
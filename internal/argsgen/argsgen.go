@@ -0,0 +1,495 @@
+// Package argsgen synthesizes tool call arguments from the JSON Schema a
+// tool advertises as its inputSchema, so tools can be called without
+// hand-written argument templates.
+//
+// Generated arguments carry every required property and a random share of
+// the optional ones, honour type, enum, const, numeric and length bounds,
+// and produce well-formed values for the common string formats. Keywords
+// that cannot be satisfied by construction, such as pattern, are ignored.
+package argsgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// maxOptionalDepth is how deep optional properties and array items are
+	// still generated; below it only required properties are.
+	maxOptionalDepth = 4
+	// maxDepth stops generation of schemas that recurse through required
+	// properties or $refs.
+	maxDepth = 16
+	// defaultMaxItems caps arrays whose schema has no maxItems.
+	defaultMaxItems = 3
+	// defaultMaxLength caps strings whose schema has no maxLength.
+	defaultMaxLength = 12
+	// defaultRange is the width of the range numbers are drawn from when
+	// the schema bounds them on one side only, or not at all.
+	defaultRange = 100
+
+	// The schema comes from the server under test, so its bounds are
+	// capped: arrays get at most maxItemsCap items and strings at most
+	// maxLengthCap bytes, whatever minItems and minLength ask for, and one
+	// set of arguments holds at most maxValues values.
+	maxItemsCap  = 64
+	maxLengthCap = 1024
+	maxValues    = 10000
+)
+
+const alphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// Generator synthesizes arguments for one input schema. It is safe for
+// concurrent use as long as each caller passes its own rand.Rand.
+type Generator struct {
+	root map[string]interface{}
+}
+
+// New returns a generator for schema, a JSON Schema describing an object.
+// An empty schema generates empty arguments.
+func New(schema json.RawMessage) (*Generator, error) {
+	if len(schema) == 0 {
+		return &Generator{root: map[string]interface{}{"type": "object"}}, nil
+	}
+	var root map[string]interface{}
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return nil, fmt.Errorf("parse input schema: %w", err)
+	}
+	return &Generator{root: root}, nil
+}
+
+// Generate returns a fresh set of arguments drawn from rng.
+func (g *Generator) Generate(rng *rand.Rand) map[string]interface{} {
+	budget := maxValues
+	args, ok := g.value(g.root, rng, 0, &budget).(map[string]interface{})
+	if !ok {
+		return map[string]interface{}{}
+	}
+	return args
+}
+
+// Required returns the names of the top-level properties the schema
+// requires, sorted and without duplicates.
+func (g *Generator) Required() []string {
+	schema := g.root
+	if ref, ok := schema["$ref"].(string); ok {
+		if schema = g.resolve(ref); schema == nil {
+			return nil
+		}
+	}
+	if all := subschemas(schema["allOf"]); len(all) > 0 {
+		schema = mergeAllOf(schema, all)
+	}
+	names, _ := schema["required"].([]interface{})
+	seen := make(map[string]bool, len(names))
+	var required []string
+	for _, name := range names {
+		if s, ok := name.(string); ok && !seen[s] {
+			seen[s] = true
+			required = append(required, s)
+		}
+	}
+	sort.Strings(required)
+	return required
+}
+
+// value generates a value for schema. budget is the number of values the
+// arguments may still hold; once it runs out, values are null.
+func (g *Generator) value(schema map[string]interface{}, rng *rand.Rand, depth int, budget *int) interface{} {
+	if depth > maxDepth || *budget <= 0 {
+		return nil
+	}
+	*budget--
+	if ref, ok := schema["$ref"].(string); ok {
+		resolved := g.resolve(ref)
+		if resolved == nil {
+			return nil
+		}
+		*budget++
+		return g.value(resolved, rng, depth+1, budget)
+	}
+	if c, ok := schema["const"]; ok {
+		return c
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[rng.Intn(len(enum))]
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if options := subschemas(schema[key]); len(options) > 0 {
+			*budget++
+			return g.value(options[rng.Intn(len(options))], rng, depth+1, budget)
+		}
+	}
+	if all := subschemas(schema["allOf"]); len(all) > 0 {
+		*budget++
+		return g.value(mergeAllOf(schema, all), rng, depth+1, budget)
+	}
+
+	switch schemaType(schema, rng) {
+	case "object":
+		return g.object(schema, rng, depth, budget)
+	case "array":
+		return g.array(schema, rng, depth, budget)
+	case "integer":
+		return integer(schema, rng)
+	case "number":
+		return number(schema, rng)
+	case "boolean":
+		return rng.Intn(2) == 0
+	case "null":
+		return nil
+	default:
+		return str(schema, rng)
+	}
+}
+
+func (g *Generator) object(schema map[string]interface{}, rng *rand.Rand, depth int, budget *int) map[string]interface{} {
+	properties, _ := schema["properties"].(map[string]interface{})
+	required := make(map[string]bool)
+	if names, ok := schema["required"].([]interface{}); ok {
+		for _, name := range names {
+			if s, ok := name.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+
+	// Properties are visited in order so a seed always gives the same
+	// arguments.
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	obj := make(map[string]interface{})
+	for _, name := range names {
+		if !required[name] && (depth >= maxOptionalDepth || rng.Intn(2) == 0) {
+			continue
+		}
+		prop, _ := properties[name].(map[string]interface{})
+		obj[name] = g.value(prop, rng, depth+1, budget)
+	}
+	// Required properties the schema does not describe get a string.
+	var undescribed []string
+	for name := range required {
+		if _, ok := obj[name]; !ok {
+			undescribed = append(undescribed, name)
+		}
+	}
+	sort.Strings(undescribed)
+	for _, name := range undescribed {
+		obj[name] = str(nil, rng)
+	}
+	return obj
+}
+
+func (g *Generator) array(schema map[string]interface{}, rng *rand.Rand, depth int, budget *int) []interface{} {
+	minItems := intKeyword(schema, "minItems", 0, maxItemsCap)
+	maxItems := intKeyword(schema, "maxItems", min(minItems+defaultMaxItems, maxItemsCap), maxItemsCap)
+	if depth >= maxOptionalDepth {
+		maxItems = minItems
+	}
+	n := minItems
+	if maxItems > minItems {
+		n += rng.Intn(maxItems - minItems + 1)
+	}
+	n = min(n, *budget)
+
+	items, _ := schema["items"].(map[string]interface{})
+	arr := make([]interface{}, n)
+	for i := range arr {
+		arr[i] = g.value(items, rng, depth+1, budget)
+	}
+	return arr
+}
+
+// resolve returns the schema a local $ref such as "#/$defs/point" points
+// at, or nil for refs outside the document.
+func (g *Generator) resolve(ref string) map[string]interface{} {
+	path, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return nil
+	}
+	var node interface{} = g.root
+	for _, part := range strings.Split(strings.TrimPrefix(path, "/"), "/") {
+		if part == "" {
+			continue
+		}
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		node = m[part]
+	}
+	resolved, _ := node.(map[string]interface{})
+	return resolved
+}
+
+// schemaType picks the type to generate: one of the listed types, not null
+// if another is allowed, or one inferred from the other keywords.
+func schemaType(schema map[string]interface{}, rng *rand.Rand) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		var types []string
+		for _, v := range t {
+			if s, ok := v.(string); ok && s != "null" {
+				types = append(types, s)
+			}
+		}
+		if len(types) == 0 {
+			return "null"
+		}
+		return types[rng.Intn(len(types))]
+	}
+	switch {
+	case schema["properties"] != nil || schema["required"] != nil:
+		return "object"
+	case schema["items"] != nil:
+		return "array"
+	case schema["minimum"] != nil || schema["maximum"] != nil:
+		return "number"
+	default:
+		return "string"
+	}
+}
+
+// mergeAllOf folds the allOf subschemas into one, joining their properties
+// and required lists.
+func mergeAllOf(schema map[string]interface{}, all []map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{})
+	properties := make(map[string]interface{})
+	var required []interface{}
+	for _, s := range append([]map[string]interface{}{schema}, all...) {
+		for key, value := range s {
+			switch key {
+			case "allOf":
+			case "properties":
+				if props, ok := value.(map[string]interface{}); ok {
+					for name, prop := range props {
+						properties[name] = prop
+					}
+				}
+			case "required":
+				if names, ok := value.([]interface{}); ok {
+					required = append(required, names...)
+				}
+			default:
+				merged[key] = value
+			}
+		}
+	}
+	if len(properties) > 0 {
+		merged["properties"] = properties
+	}
+	if len(required) > 0 {
+		merged["required"] = required
+	}
+	return merged
+}
+
+func subschemas(v interface{}) []map[string]interface{} {
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+	var schemas []map[string]interface{}
+	for _, item := range list {
+		if s, ok := item.(map[string]interface{}); ok {
+			schemas = append(schemas, s)
+		}
+	}
+	return schemas
+}
+
+// bounds returns the range numbers are drawn from and whether each end of
+// it is exclusive. Draft 4's boolean exclusiveMinimum and exclusiveMaximum
+// are understood too.
+func bounds(schema map[string]interface{}) (lo, hi float64, loExclusive, hiExclusive bool) {
+	lo, hasLo := floatKeyword(schema, "minimum")
+	hi, hasHi := floatKeyword(schema, "maximum")
+	loExclusive = schema["exclusiveMinimum"] == true
+	hiExclusive = schema["exclusiveMaximum"] == true
+	if v, ok := floatKeyword(schema, "exclusiveMinimum"); ok && (!hasLo || v >= lo) {
+		lo, hasLo, loExclusive = v, true, true
+	}
+	if v, ok := floatKeyword(schema, "exclusiveMaximum"); ok && (!hasHi || v <= hi) {
+		hi, hasHi, hiExclusive = v, true, true
+	}
+	switch {
+	case !hasLo && !hasHi:
+		return 0, defaultRange, false, false
+	case !hasLo:
+		return hi - defaultRange, hi, false, hiExclusive
+	case !hasHi:
+		return lo, lo + defaultRange, loExclusive, false
+	}
+	return lo, hi, loExclusive, hiExclusive
+}
+
+func integer(schema map[string]interface{}, rng *rand.Rand) int64 {
+	lo, hi, loExclusive, hiExclusive := bounds(schema)
+	min, max := toInt64(math.Ceil(lo)), toInt64(math.Floor(hi))
+	if loExclusive && float64(min) == lo && min < math.MaxInt64 {
+		min++
+	}
+	if hiExclusive && float64(max) == hi && max > math.MinInt64 {
+		max--
+	}
+	step := int64(1)
+	if v, ok := floatKeyword(schema, "multipleOf"); ok && v >= 1 && v < math.MaxInt64 && v == math.Trunc(v) {
+		step = int64(v)
+	}
+	kmin, kmax := ceilDiv(min, step), floorDiv(max, step)
+	if kmax < kmin {
+		return min
+	}
+	return step * (kmin + int64(uint64n(rng, uint64(kmax)-uint64(kmin))))
+}
+
+// toInt64 converts f to an int64, saturating at the ends of its range.
+func toInt64(f float64) int64 {
+	switch {
+	case f >= math.MaxInt64:
+		return math.MaxInt64
+	case f <= math.MinInt64:
+		return math.MinInt64
+	}
+	return int64(f)
+}
+
+// uint64n returns a uniform integer in [0, span]. Unlike Int63n it takes
+// spans too wide for an int64, such as that of a schema allowing any
+// integer.
+func uint64n(rng *rand.Rand, span uint64) uint64 {
+	if span < math.MaxInt64 {
+		return uint64(rng.Int63n(int64(span) + 1))
+	}
+	if span == math.MaxUint64 {
+		return rng.Uint64()
+	}
+	// span+1 is above 2^63, so fewer than half of the draws are rejected.
+	for {
+		if v := rng.Uint64(); v <= span {
+			return v
+		}
+	}
+}
+
+func number(schema map[string]interface{}, rng *rand.Rand) float64 {
+	lo, hi, loExclusive, hiExclusive := bounds(schema)
+	if loExclusive {
+		lo = math.Nextafter(lo, math.Inf(1))
+	}
+	if hiExclusive {
+		hi = math.Nextafter(hi, math.Inf(-1))
+	}
+	if hi < lo {
+		return lo
+	}
+	if step, ok := floatKeyword(schema, "multipleOf"); ok && step > 0 {
+		kmin, kmax := math.Ceil(lo/step), math.Floor(hi/step)
+		if span := kmax - kmin; span >= 0 && span < 1<<62 {
+			return step * (kmin + float64(rng.Int63n(int64(span)+1)))
+		}
+		// Too many multiples to count in an int64: any draw will do.
+	}
+	if span := hi - lo; !math.IsInf(span, 0) {
+		return lo + rng.Float64()*span
+	}
+	r := rng.Float64()
+	return lo*(1-r) + hi*r
+}
+
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b != 0 && a < 0 {
+		q--
+	}
+	return q
+}
+
+func ceilDiv(a, b int64) int64 {
+	return -floorDiv(-a, b)
+}
+
+func str(schema map[string]interface{}, rng *rand.Rand) string {
+	if format, ok := schema["format"].(string); ok {
+		if s, ok := formatted(format, rng); ok {
+			return s
+		}
+	}
+	minLength := intKeyword(schema, "minLength", 1, maxLengthCap)
+	maxLength := intKeyword(schema, "maxLength", max(minLength, defaultMaxLength), maxLengthCap)
+	n := minLength
+	if maxLength > minLength {
+		n += rng.Intn(maxLength - minLength + 1)
+	}
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = alphabet[rng.Intn(len(alphabet))]
+	}
+	return string(b)
+}
+
+// formatted returns a random value of a string format, and false for
+// formats it does not know.
+func formatted(format string, rng *rand.Rand) (string, bool) {
+	word := func(n int) string {
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = alphabet[rng.Intn(26)]
+		}
+		return string(b)
+	}
+	// Dates fall within a year before a fixed day, so a seed always gives
+	// the same arguments.
+	at := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).Add(-time.Duration(rng.Int63n(int64(365 * 24 * time.Hour))))
+
+	switch format {
+	case "date-time":
+		return at.Format(time.RFC3339), true
+	case "date":
+		return at.Format(time.DateOnly), true
+	case "time":
+		return at.Format("15:04:05Z"), true
+	case "email":
+		return word(8) + "@example.com", true
+	case "hostname":
+		return word(8) + ".example.com", true
+	case "uri", "url", "iri":
+		return "https://example.com/" + word(8), true
+	case "uuid":
+		b := make([]byte, 16)
+		rng.Read(b)
+		b[6] = b[6]&0x0f | 0x40
+		b[8] = b[8]&0x3f | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), true
+	case "ipv4":
+		return fmt.Sprintf("192.0.2.%d", 1+rng.Intn(254)), true
+	case "ipv6":
+		return fmt.Sprintf("2001:db8::%x", 1+rng.Intn(0xfffe)), true
+	}
+	return "", false
+}
+
+func floatKeyword(schema map[string]interface{}, key string) (float64, bool) {
+	v, ok := schema[key].(float64)
+	return v, ok
+}
+
+// intKeyword returns a count keyword such as minItems, capped at limit.
+func intKeyword(schema map[string]interface{}, key string, fallback, limit int) int {
+	if v, ok := floatKeyword(schema, key); ok && v >= 0 {
+		return int(math.Min(v, float64(limit)))
+	}
+	return fallback
+}
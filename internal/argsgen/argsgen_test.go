@@ -0,0 +1,240 @@
+package argsgen
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func mustNew(t *testing.T, schema string) *Generator {
+	t.Helper()
+	g, err := New(json.RawMessage(schema))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return g
+}
+
+func TestGenerate_RequiredPropertiesAndTypes(t *testing.T) {
+	g := mustNew(t, `{
+		"type": "object",
+		"properties": {
+			"query": {"type": "string", "minLength": 3, "maxLength": 5},
+			"limit": {"type": "integer", "minimum": 1, "maximum": 10},
+			"ratio": {"type": "number", "exclusiveMinimum": 0, "maximum": 1},
+			"exact": {"type": "boolean"},
+			"tags": {"type": "array", "items": {"type": "string"}, "minItems": 1, "maxItems": 2},
+			"mode": {"enum": ["fast", "slow"]},
+			"kind": {"const": "search"},
+			"note": {"type": "string"}
+		},
+		"required": ["query", "limit", "ratio", "exact", "tags", "mode", "kind", "id"]
+	}`)
+
+	rng := rand.New(rand.NewSource(1))
+	sawOptional := false
+	for i := 0; i < 200; i++ {
+		args := g.Generate(rng)
+		for _, name := range []string{"query", "limit", "ratio", "exact", "tags", "mode", "kind", "id"} {
+			if _, ok := args[name]; !ok {
+				t.Fatalf("expected required property %s, got %v", name, args)
+			}
+		}
+		if _, ok := args["note"]; ok {
+			sawOptional = true
+		}
+		if q := args["query"].(string); len(q) < 3 || len(q) > 5 {
+			t.Errorf("query %q is outside its length bounds", q)
+		}
+		if l := args["limit"].(int64); l < 1 || l > 10 {
+			t.Errorf("limit %d is outside its bounds", l)
+		}
+		if r := args["ratio"].(float64); r <= 0 || r > 1 {
+			t.Errorf("ratio %v is outside its bounds", r)
+		}
+		if _, ok := args["exact"].(bool); !ok {
+			t.Errorf("exact %v is not a boolean", args["exact"])
+		}
+		if tags := args["tags"].([]interface{}); len(tags) < 1 || len(tags) > 2 {
+			t.Errorf("tags %v is outside its item bounds", tags)
+		}
+		if m := args["mode"]; m != "fast" && m != "slow" {
+			t.Errorf("mode %v is not in its enum", m)
+		}
+		if args["kind"] != "search" {
+			t.Errorf("kind %v is not its const", args["kind"])
+		}
+		if _, ok := args["id"].(string); !ok {
+			t.Errorf("expected an undescribed required property to get a string, got %v", args["id"])
+		}
+	}
+	if !sawOptional {
+		t.Error("expected optional properties to be generated sometimes")
+	}
+}
+
+func TestGenerate_Formats(t *testing.T) {
+	g := mustNew(t, `{
+		"type": "object",
+		"properties": {
+			"at": {"type": "string", "format": "date-time"},
+			"day": {"type": "string", "format": "date"},
+			"email": {"type": "string", "format": "email"},
+			"link": {"type": "string", "format": "uri"},
+			"id": {"type": "string", "format": "uuid"},
+			"ip": {"type": "string", "format": "ipv4"},
+			"ip6": {"type": "string", "format": "ipv6"}
+		},
+		"required": ["at", "day", "email", "link", "id", "ip", "ip6"]
+	}`)
+	uuidPattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	args := g.Generate(rand.New(rand.NewSource(7)))
+	if _, err := time.Parse(time.RFC3339, args["at"].(string)); err != nil {
+		t.Errorf("at: %v", err)
+	}
+	if _, err := time.Parse(time.DateOnly, args["day"].(string)); err != nil {
+		t.Errorf("day: %v", err)
+	}
+	if _, err := mail.ParseAddress(args["email"].(string)); err != nil {
+		t.Errorf("email: %v", err)
+	}
+	if u, err := url.Parse(args["link"].(string)); err != nil || !u.IsAbs() {
+		t.Errorf("link %q is not an absolute URI", args["link"])
+	}
+	if !uuidPattern.MatchString(args["id"].(string)) {
+		t.Errorf("id %q is not a v4 UUID", args["id"])
+	}
+	if ip := net.ParseIP(args["ip"].(string)); ip == nil || ip.To4() == nil {
+		t.Errorf("ip %q is not an IPv4 address", args["ip"])
+	}
+	if ip := net.ParseIP(args["ip6"].(string)); ip == nil || ip.To4() != nil {
+		t.Errorf("ip6 %q is not an IPv6 address", args["ip6"])
+	}
+}
+
+func TestGenerate_RefsAndCombinators(t *testing.T) {
+	g := mustNew(t, `{
+		"type": "object",
+		"$defs": {
+			"point": {"type": "object", "properties": {"x": {"type": "integer"}}, "required": ["x"]}
+		},
+		"properties": {
+			"origin": {"$ref": "#/$defs/point"},
+			"either": {"oneOf": [{"type": "integer", "minimum": 5, "maximum": 5}, {"const": "five"}]},
+			"both": {"allOf": [
+				{"type": "object", "properties": {"a": {"type": "string"}}, "required": ["a"]},
+				{"properties": {"b": {"type": "boolean"}}, "required": ["b"]}
+			]},
+			"maybe": {"type": ["null", "integer"], "multipleOf": 10, "minimum": 1, "maximum": 100}
+		},
+		"required": ["origin", "either", "both", "maybe"]
+	}`)
+
+	rng := rand.New(rand.NewSource(3))
+	for i := 0; i < 50; i++ {
+		args := g.Generate(rng)
+		origin, ok := args["origin"].(map[string]interface{})
+		if !ok || origin["x"] == nil {
+			t.Fatalf("expected the $ref resolved to a point, got %v", args["origin"])
+		}
+		if e := args["either"]; e != int64(5) && e != "five" {
+			t.Errorf("either %v matches neither oneOf branch", e)
+		}
+		both, ok := args["both"].(map[string]interface{})
+		if !ok || both["a"] == nil || both["b"] == nil {
+			t.Errorf("expected allOf required properties merged, got %v", args["both"])
+		}
+		if m, ok := args["maybe"].(int64); !ok || m%10 != 0 || m < 10 || m > 100 {
+			t.Errorf("maybe %v is not a multiple of 10 within bounds", args["maybe"])
+		}
+	}
+}
+
+func TestRequired(t *testing.T) {
+	g := mustNew(t, `{
+		"$defs": {"args": {"allOf": [{"required": ["b", "a"]}, {"required": ["a", "c"]}]}},
+		"$ref": "#/$defs/args"
+	}`)
+	if got := g.Required(); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Errorf("expected a, b and c required, got %v", got)
+	}
+	if got := mustNew(t, `{"type": "object"}`).Required(); len(got) != 0 {
+		t.Errorf("expected nothing required, got %v", got)
+	}
+}
+
+func TestGenerate_SameSeedSameArguments(t *testing.T) {
+	g := mustNew(t, `{"type": "object", "properties": {
+		"a": {"type": "string"}, "b": {"type": "integer"}, "c": {"type": "array", "items": {"type": "number"}}
+	}}`)
+	first := g.Generate(rand.New(rand.NewSource(42)))
+	second := g.Generate(rand.New(rand.NewSource(42)))
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("expected the same arguments for the same seed, got %v and %v", first, second)
+	}
+}
+
+func TestGenerate_RecursiveSchemaTerminates(t *testing.T) {
+	g := mustNew(t, `{
+		"$defs": {"node": {"type": "object", "properties": {"child": {"$ref": "#/$defs/node"}}, "required": ["child"]}},
+		"$ref": "#/$defs/node"
+	}`)
+	if args := g.Generate(rand.New(rand.NewSource(1))); args["child"] == nil {
+		t.Errorf("expected a bounded chain of children, got %v", args)
+	}
+}
+
+func TestGenerate_HostileSchemas(t *testing.T) {
+	schemas := map[string]string{
+		"wide integer":    `{"type":"integer","minimum":-9e18,"maximum":9e18}`,
+		"huge integer":    `{"type":"integer","minimum":-1e300,"maximum":1e300}`,
+		"huge multipleOf": `{"type":"integer","minimum":0,"maximum":10,"multipleOf":1e300}`,
+		"tiny multipleOf": `{"type":"number","minimum":0,"maximum":1e300,"multipleOf":1e-300}`,
+		"widest number":   `{"type":"number","minimum":-1e308,"maximum":1e308}`,
+		"long string":     `{"type":"string","minLength":1e300}`,
+		"many items":      `{"type":"array","items":{"type":"string"},"minItems":1e9}`,
+		"nested arrays":   `{"type":"array","minItems":64,"items":{"type":"array","minItems":64,"items":{"type":"array","minItems":64,"items":{"type":"array","minItems":64}}}}`,
+	}
+	for name, schema := range schemas {
+		g := mustNew(t, `{"type":"object","properties":{"v":`+schema+`},"required":["v"]}`)
+		rng := rand.New(rand.NewSource(1))
+		for i := 0; i < 20; i++ {
+			args := g.Generate(rng)
+			data, err := json.Marshal(args)
+			if err != nil {
+				t.Fatalf("%s: arguments do not marshal: %v", name, err)
+			}
+			if len(data) > 1<<20 {
+				t.Fatalf("%s: expected capped arguments, got %d bytes", name, len(data))
+			}
+		}
+	}
+
+	g := mustNew(t, `{"type":"object","properties":{"v":{"type":"integer","minimum":-9e18,"maximum":9e18}},"required":["v"]}`)
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		if v := g.Generate(rng)["v"].(int64); v < -9e18 || v > 9e18 {
+			t.Fatalf("integer %d is outside its bounds", v)
+		}
+	}
+}
+
+func TestNew_EmptyAndInvalidSchemas(t *testing.T) {
+	g, err := New(nil)
+	if err != nil {
+		t.Fatalf("New(nil) failed: %v", err)
+	}
+	if args := g.Generate(rand.New(rand.NewSource(1))); len(args) != 0 {
+		t.Errorf("expected empty arguments without a schema, got %v", args)
+	}
+	if _, err := New(json.RawMessage(`[`)); err == nil {
+		t.Error("expected an invalid schema to fail")
+	}
+}
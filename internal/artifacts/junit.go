@@ -0,0 +1,142 @@
+package artifacts
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+)
+
+// JUnit test suite names. CI systems group test cases by suite.
+const (
+	junitSuiteSLOs   = "slos"
+	junitSuiteChecks = "checks"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// ExportJUnit renders a report as JUnit XML: one test case per SLO and one
+// per response check. A check fails if any of its evaluations failed; an
+// SLO on check_pass_rate expresses a tolerance instead. A run without SLOs
+// or checks yields an empty test suite list.
+func ExportJUnit(report *analysis.Report) ([]byte, error) {
+	if report == nil {
+		return nil, fmt.Errorf("report cannot be nil")
+	}
+	duration := junitSeconds(report.Duration)
+	root := junitTestSuites{Name: "mcpdrill " + report.RunID, Time: duration}
+
+	if slos := report.SLOs; slos != nil {
+		suite := junitTestSuite{Name: junitSuiteSLOs, Time: duration}
+		for _, r := range slos.Results {
+			tc := junitTestCase{Name: r.Name, Classname: "mcpdrill.slo", Time: "0"}
+			if !r.Passed {
+				tc.Failure = &junitFailure{
+					Message: sloFailureMessage(r),
+					Type:    "slo",
+					Text:    "objective: " + r.Objective + "\nscope: " + sloScope(r),
+				}
+			}
+			suite.add(tc)
+		}
+		root.add(suite)
+	}
+
+	if report.Metrics != nil && report.Metrics.Checks != nil {
+		suite := junitTestSuite{Name: junitSuiteChecks, Time: duration}
+		for _, c := range report.Metrics.Checks.Checks {
+			tc := junitTestCase{Name: c.Name, Classname: checkClassname(c), Time: "0"}
+			if c.Failed > 0 {
+				tc.Failure = &junitFailure{
+					Message: checkFailureMessage(c),
+					Type:    "check",
+				}
+			}
+			suite.add(tc)
+		}
+		root.add(suite)
+	}
+
+	data, err := xml.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode JUnit XML: %w", err)
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+func (s *junitTestSuite) add(tc junitTestCase) {
+	s.Tests++
+	if tc.Failure != nil {
+		s.Failures++
+	}
+	s.TestCases = append(s.TestCases, tc)
+}
+
+func (s *junitTestSuites) add(suite junitTestSuite) {
+	s.Tests += suite.Tests
+	s.Failures += suite.Failures
+	s.Suites = append(s.Suites, suite)
+}
+
+func junitSeconds(ms int64) string {
+	return strconv.FormatFloat(float64(ms)/1000, 'f', 3, 64)
+}
+
+func sloScope(r analysis.SLOResult) string {
+	switch {
+	case r.ToolName != "":
+		return "tool " + r.ToolName
+	case r.Operation != "":
+		return "operation " + r.Operation
+	default:
+		return "run"
+	}
+}
+
+func sloFailureMessage(r analysis.SLOResult) string {
+	if r.Actual == nil {
+		return r.Objective + ": " + r.Message
+	}
+	return fmt.Sprintf("%s: actual %s", r.Objective, strconv.FormatFloat(*r.Actual, 'f', -1, 64))
+}
+
+func checkClassname(c *analysis.CheckMetrics) string {
+	name := "mcpdrill.check." + c.Operation
+	if c.ToolName != "" {
+		name += "." + c.ToolName
+	}
+	return name
+}
+
+func checkFailureMessage(c *analysis.CheckMetrics) string {
+	return fmt.Sprintf("%d of %d evaluations failed (%.1f%% pass rate)", c.Failed, c.Total, 100*c.PassRate)
+}
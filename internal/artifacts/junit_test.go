@@ -0,0 +1,95 @@
+package artifacts
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+)
+
+func float64Ptr(f float64) *float64 { return &f }
+
+func createCIReport() *analysis.Report {
+	return &analysis.Report{
+		RunID:    "run_0000000000000001",
+		Duration: 60500,
+		Metrics: &analysis.AggregatedMetrics{
+			Checks: &analysis.CheckReportMetrics{
+				Checks: []*analysis.CheckMetrics{
+					{Name: "has_text", Operation: "tools/call", ToolName: "echo", Total: 10, Passed: 10, PassRate: 1},
+					{Name: "fast", Operation: "tools/call", ToolName: "search", Total: 10, Passed: 8, Failed: 2, PassRate: 0.8},
+				},
+			},
+		},
+		SLOs: &analysis.SLOReport{
+			Verdict: analysis.SLOVerdictFail,
+			Results: []analysis.SLOResult{
+				{Name: "p95", Objective: "p95_latency_ms < 500", Actual: float64Ptr(120), Passed: true},
+				{Name: "errors", Objective: "error_rate < 1%", ToolName: "search", Actual: float64Ptr(0.05)},
+				{Name: "list", Objective: "p95_latency_ms < 100", Operation: "tools/list", Message: "no operations in scope"},
+			},
+		},
+	}
+}
+
+func TestExportJUnit(t *testing.T) {
+	data, err := ExportJUnit(createCIReport())
+	if err != nil {
+		t.Fatalf("ExportJUnit failed: %v", err)
+	}
+	if !strings.HasPrefix(string(data), xml.Header) {
+		t.Error("expected XML header")
+	}
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal(data, &suites); err != nil {
+		t.Fatalf("invalid XML: %v", err)
+	}
+	if suites.Tests != 5 || suites.Failures != 3 {
+		t.Errorf("expected 5 tests and 3 failures, got %d and %d", suites.Tests, suites.Failures)
+	}
+	if len(suites.Suites) != 2 || suites.Suites[0].Name != junitSuiteSLOs || suites.Suites[1].Name != junitSuiteChecks {
+		t.Fatalf("unexpected suites: %+v", suites.Suites)
+	}
+	if suites.Suites[0].Time != "60.500" {
+		t.Errorf("expected suite time 60.500, got %s", suites.Suites[0].Time)
+	}
+
+	slos := suites.Suites[0].TestCases
+	if slos[0].Failure != nil {
+		t.Error("passing SLO should have no failure")
+	}
+	if slos[1].Failure == nil || slos[1].Failure.Message != "error_rate < 1%: actual 0.05" {
+		t.Errorf("unexpected SLO failure: %+v", slos[1].Failure)
+	}
+	if slos[2].Failure == nil || !strings.Contains(slos[2].Failure.Text, "scope: operation tools/list") {
+		t.Errorf("unexpected SLO failure: %+v", slos[2].Failure)
+	}
+
+	checks := suites.Suites[1].TestCases
+	if checks[1].Classname != "mcpdrill.check.tools/call.search" {
+		t.Errorf("unexpected classname %q", checks[1].Classname)
+	}
+	if checks[1].Failure == nil || checks[1].Failure.Message != "2 of 10 evaluations failed (80.0% pass rate)" {
+		t.Errorf("unexpected check failure: %+v", checks[1].Failure)
+	}
+}
+
+func TestExportJUnit_NoSLOsOrChecks(t *testing.T) {
+	data, err := ExportJUnit(&analysis.Report{RunID: "run_0000000000000001", Metrics: &analysis.AggregatedMetrics{}})
+	if err != nil {
+		t.Fatalf("ExportJUnit failed: %v", err)
+	}
+	var suites junitTestSuites
+	if err := xml.Unmarshal(data, &suites); err != nil {
+		t.Fatalf("invalid XML: %v", err)
+	}
+	if suites.Tests != 0 || len(suites.Suites) != 0 {
+		t.Errorf("expected no test cases, got %+v", suites)
+	}
+
+	if _, err := ExportJUnit(nil); err == nil {
+		t.Error("expected error for nil report")
+	}
+}
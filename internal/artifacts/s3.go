@@ -0,0 +1,538 @@
+package artifacts
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/sigv4"
+)
+
+const (
+	// DefaultS3PartSize is the size above which artifacts are uploaded in
+	// parts, and the size of each part.
+	DefaultS3PartSize = 16 << 20
+
+	// MinS3PartSize is the smallest part S3 accepts, except for the last.
+	MinS3PartSize = 5 << 20
+
+	// MaxSignedURLExpiry is the longest validity SigV4 allows for a
+	// presigned URL.
+	MaxSignedURLExpiry = 7 * 24 * time.Hour
+
+	defaultS3Region = "us-east-1"
+)
+
+// URLSigner is implemented by stores that can hand out time-limited
+// download links, so clients can fetch reports without going through the
+// control plane.
+type URLSigner interface {
+	SignedURL(runID string, artifactType ArtifactType, filename string, expires time.Duration) (string, error)
+}
+
+// S3Config configures an S3Store.
+type S3Config struct {
+	Bucket string
+	// Prefix is prepended to every key, e.g. "mcpdrill/prod".
+	Prefix string
+	// Region defaults to us-east-1.
+	Region string
+	// Endpoint is the base URL of an S3-compatible service. Defaults to
+	// AWS: https://s3.{region}.amazonaws.com.
+	Endpoint string
+	// PathStyle addresses the bucket as {endpoint}/{bucket} instead of
+	// {bucket}.{endpoint host}, as most self-hosted services require.
+	PathStyle bool
+
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	// PartSize defaults to DefaultS3PartSize and cannot be below
+	// MinS3PartSize.
+	PartSize int64
+
+	HTTPClient *http.Client
+}
+
+// S3Store implements Store on S3 or an S3-compatible object store.
+// Artifacts are stored at {prefix}/{runID}/{artifactType}/{filename}.
+type S3Store struct {
+	bucket    string
+	prefix    string
+	endpoint  *url.URL
+	pathStyle bool
+	partSize  int64
+	signer    *sigv4.Signer
+	client    *http.Client
+	now       func() time.Time
+}
+
+// NewS3Store creates an S3Store. It does not contact the service.
+func NewS3Store(cfg S3Config) (*S3Store, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("bucket cannot be empty")
+	}
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, fmt.Errorf("access key ID and secret access key are required")
+	}
+	region := cfg.Region
+	if region == "" {
+		region = defaultS3Region
+	}
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = "https://s3." + region + ".amazonaws.com"
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid endpoint %q: expected http(s)://host[:port]", endpoint)
+	}
+	partSize := cfg.PartSize
+	if partSize == 0 {
+		partSize = DefaultS3PartSize
+	}
+	if partSize < MinS3PartSize {
+		return nil, fmt.Errorf("part size must be at least %d bytes", MinS3PartSize)
+	}
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Minute}
+	}
+
+	return &S3Store{
+		bucket:    cfg.Bucket,
+		prefix:    strings.Trim(cfg.Prefix, "/"),
+		endpoint:  &url.URL{Scheme: u.Scheme, Host: u.Host, Path: strings.TrimSuffix(u.Path, "/")},
+		pathStyle: cfg.PathStyle,
+		partSize:  partSize,
+		signer: &sigv4.Signer{
+			AccessKeyID:     cfg.AccessKeyID,
+			SecretAccessKey: cfg.SecretAccessKey,
+			SessionToken:    cfg.SessionToken,
+			Region:          region,
+			Service:         "s3",
+		},
+		client: client,
+		now:    time.Now,
+	}, nil
+}
+
+// SaveArtifact stores an artifact for a run. Artifacts larger than the part
+// size are sent as a multipart upload.
+func (s *S3Store) SaveArtifact(runID string, artifactType ArtifactType, filename string, data []byte) (*ArtifactInfo, error) {
+	if err := checkArtifactRef(runID, artifactType, filename); err != nil {
+		return nil, err
+	}
+	if strings.Contains(filename, "/") || filename == "." || filename == ".." {
+		return nil, fmt.Errorf("filename cannot contain path separators")
+	}
+
+	key := s.key(runID, string(artifactType), filename)
+	var err error
+	if int64(len(data)) > s.partSize {
+		err = s.putMultipart(key, data)
+	} else {
+		_, err = s.do(http.MethodPut, key, nil, data, http.StatusOK)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write artifact: %w", err)
+	}
+
+	return &ArtifactInfo{
+		RunID:        runID,
+		ArtifactType: artifactType,
+		Filename:     filename,
+		Path:         s.location(key),
+		SizeBytes:    int64(len(data)),
+	}, nil
+}
+
+// SaveArtifactStream stores an artifact as write produces it. At most one
+// part is held in memory; larger artifacts are uploaded in parts while
+// write runs.
+func (s *S3Store) SaveArtifactStream(runID string, artifactType ArtifactType, filename string, write func(io.Writer) error) (*ArtifactInfo, error) {
+	if err := checkArtifactRef(runID, artifactType, filename); err != nil {
+		return nil, err
+	}
+	if strings.Contains(filename, "/") || filename == "." || filename == ".." {
+		return nil, fmt.Errorf("filename cannot contain path separators")
+	}
+
+	key := s.key(runID, string(artifactType), filename)
+	w := &s3PartWriter{upload: &s3Upload{store: s, key: key}}
+	err := write(w)
+	if err == nil {
+		err = w.close()
+	} else {
+		w.upload.abort()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write artifact: %w", err)
+	}
+
+	return &ArtifactInfo{
+		RunID:        runID,
+		ArtifactType: artifactType,
+		Filename:     filename,
+		Path:         s.location(key),
+		SizeBytes:    w.size,
+	}, nil
+}
+
+// GetArtifact retrieves an artifact for a run.
+func (s *S3Store) GetArtifact(runID string, artifactType ArtifactType, filename string) ([]byte, error) {
+	if err := checkArtifactRef(runID, artifactType, filename); err != nil {
+		return nil, err
+	}
+
+	data, err := s.do(http.MethodGet, s.key(runID, string(artifactType), filename), nil, nil, http.StatusOK)
+	if err != nil {
+		if isS3NotFound(err) {
+			return nil, fmt.Errorf("artifact not found: %s/%s/%s", runID, artifactType, filename)
+		}
+		return nil, fmt.Errorf("failed to read artifact: %w", err)
+	}
+	return data, nil
+}
+
+// ListArtifacts lists all artifacts for a run.
+func (s *S3Store) ListArtifacts(runID string) ([]ArtifactInfo, error) {
+	if runID == "" {
+		return nil, fmt.Errorf("run ID cannot be empty")
+	}
+
+	runPrefix := s.key(runID) + "/"
+	artifacts := []ArtifactInfo{}
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {runPrefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		body, err := s.do(http.MethodGet, "", query, nil, http.StatusOK)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list artifacts: %w", err)
+		}
+		var page struct {
+			Contents []struct {
+				Key  string
+				Size int64
+			}
+			IsTruncated           bool
+			NextContinuationToken string
+		}
+		if err := xml.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("failed to parse artifact listing: %w", err)
+		}
+
+		for _, obj := range page.Contents {
+			artifactType, filename, ok := strings.Cut(strings.TrimPrefix(obj.Key, runPrefix), "/")
+			if !ok || filename == "" || strings.Contains(filename, "/") {
+				continue
+			}
+			artifacts = append(artifacts, ArtifactInfo{
+				RunID:        runID,
+				ArtifactType: ArtifactType(artifactType),
+				Filename:     filename,
+				Path:         s.location(obj.Key),
+				SizeBytes:    obj.Size,
+			})
+		}
+
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return artifacts, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+// DeleteArtifacts deletes all artifacts for a run.
+func (s *S3Store) DeleteArtifacts(runID string) error {
+	artifacts, err := s.ListArtifacts(runID)
+	if err != nil {
+		return err
+	}
+	for _, a := range artifacts {
+		_, err := s.do(http.MethodDelete, s.key(runID, string(a.ArtifactType), a.Filename), nil, nil, http.StatusNoContent, http.StatusOK)
+		if err != nil && !isS3NotFound(err) {
+			return fmt.Errorf("failed to delete artifacts: %w", err)
+		}
+	}
+	return nil
+}
+
+// SignedURL returns a presigned GET URL for an artifact. The artifact is
+// not checked for existence.
+func (s *S3Store) SignedURL(runID string, artifactType ArtifactType, filename string, expires time.Duration) (string, error) {
+	if err := checkArtifactRef(runID, artifactType, filename); err != nil {
+		return "", err
+	}
+	if expires < time.Second || expires > MaxSignedURLExpiry {
+		return "", fmt.Errorf("expiry must be between 1s and %v", MaxSignedURLExpiry)
+	}
+	return s.signer.Presign(http.MethodGet, s.objectURL(s.key(runID, string(artifactType), filename), nil), expires, s.now()), nil
+}
+
+// Bucket returns the bucket of the store.
+func (s *S3Store) Bucket() string {
+	return s.bucket
+}
+
+// putMultipart uploads data in parts of the part size.
+func (s *S3Store) putMultipart(key string, data []byte) error {
+	upload := &s3Upload{store: s, key: key}
+	for offset := int64(0); offset < int64(len(data)); offset += s.partSize {
+		if err := upload.put(data[offset:min(offset+s.partSize, int64(len(data)))]); err != nil {
+			upload.abort()
+			return err
+		}
+	}
+	return upload.complete()
+}
+
+// s3Upload is a multipart upload, initiated with its first part.
+type s3Upload struct {
+	store    *S3Store
+	key      string
+	uploadID string
+	parts    []s3CompletedPart
+}
+
+type s3CompletedPart struct {
+	PartNumber int
+	ETag       string
+}
+
+func (u *s3Upload) put(part []byte) error {
+	if u.uploadID == "" {
+		body, err := u.store.do(http.MethodPost, u.key, url.Values{"uploads": {""}}, nil, http.StatusOK)
+		if err != nil {
+			return fmt.Errorf("initiate multipart upload: %w", err)
+		}
+		var initiated struct {
+			UploadID string `xml:"UploadId"`
+		}
+		if err := xml.Unmarshal(body, &initiated); err != nil || initiated.UploadID == "" {
+			return fmt.Errorf("initiate multipart upload: no upload ID in response")
+		}
+		u.uploadID = initiated.UploadID
+	}
+
+	number := len(u.parts) + 1
+	query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {u.uploadID}}
+	etag, err := u.store.uploadPart(u.key, query, part)
+	if err != nil {
+		return fmt.Errorf("upload part %d: %w", number, err)
+	}
+	u.parts = append(u.parts, s3CompletedPart{PartNumber: number, ETag: etag})
+	return nil
+}
+
+func (u *s3Upload) complete() error {
+	complete, _ := xml.Marshal(struct {
+		XMLName xml.Name          `xml:"CompleteMultipartUpload"`
+		Parts   []s3CompletedPart `xml:"Part"`
+	}{Parts: u.parts})
+	body, err := u.store.do(http.MethodPost, u.key, url.Values{"uploadId": {u.uploadID}}, complete, http.StatusOK)
+	if err == nil && bytes.Contains(body, []byte("<Error>")) {
+		// Completion can fail after a 200 status; the body then holds an
+		// error instead of a result.
+		err = parseS3Error(http.StatusOK, body)
+	}
+	if err != nil {
+		u.abort()
+		return fmt.Errorf("complete multipart upload: %w", err)
+	}
+	return nil
+}
+
+// abort discards the uploaded parts, if any.
+func (u *s3Upload) abort() {
+	if u.uploadID != "" {
+		_, _ = u.store.do(http.MethodDelete, u.key, url.Values{"uploadId": {u.uploadID}}, nil, http.StatusNoContent)
+	}
+}
+
+// s3PartWriter buffers one part at a time. The upload becomes multipart
+// once more than one part has been written.
+type s3PartWriter struct {
+	upload *s3Upload
+	buf    []byte
+	size   int64
+	err    error
+}
+
+func (w *s3PartWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	partSize := int(w.upload.store.partSize)
+	written := 0
+	for written < len(p) {
+		if len(w.buf) == partSize {
+			// Only send a full part once more data follows, so the last
+			// part is never empty.
+			if w.err = w.upload.put(w.buf); w.err != nil {
+				return written, w.err
+			}
+			w.buf = w.buf[:0]
+		}
+		n := min(partSize-len(w.buf), len(p)-written)
+		w.buf = append(w.buf, p[written:written+n]...)
+		written += n
+	}
+	w.size += int64(written)
+	return written, nil
+}
+
+func (w *s3PartWriter) close() error {
+	if w.err != nil {
+		w.upload.abort()
+		return w.err
+	}
+	if w.upload.uploadID == "" {
+		_, err := w.upload.store.do(http.MethodPut, w.upload.key, nil, w.buf, http.StatusOK)
+		return err
+	}
+	if err := w.upload.put(w.buf); err != nil {
+		w.upload.abort()
+		return err
+	}
+	return w.upload.complete()
+}
+
+func (s *S3Store) uploadPart(key string, query url.Values, part []byte) (string, error) {
+	resp, err := s.send(http.MethodPut, key, query, part)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return "", parseS3Error(resp.StatusCode, body)
+	}
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		return "", fmt.Errorf("no ETag in response")
+	}
+	return etag, nil
+}
+
+// do sends a request and returns the response body if the status is one of
+// want.
+func (s *S3Store) do(method, key string, query url.Values, body []byte, want ...int) ([]byte, error) {
+	resp, err := s.send(method, key, query, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", method, s.location(key), err)
+	}
+	for _, status := range want {
+		if resp.StatusCode == status {
+			return data, nil
+		}
+	}
+	return nil, fmt.Errorf("%s %s: %w", method, s.location(key), parseS3Error(resp.StatusCode, data))
+}
+
+func (s *S3Store) send(method, key string, query url.Values, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(method, s.objectURL(key, query).String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	s.signer.Sign(req, sigv4.SHA256Hex(body), s.now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", method, s.location(key), err)
+	}
+	return resp, nil
+}
+
+// objectURL returns the URL of key, or of the bucket if key is empty.
+func (s *S3Store) objectURL(key string, query url.Values) *url.URL {
+	u := *s.endpoint
+	p := u.Path
+	if s.pathStyle {
+		p += "/" + s.bucket
+	} else {
+		u.Host = s.bucket + "." + u.Host
+	}
+	p += "/" + key
+	u.Path = p
+	u.RawPath = sigv4.Escape(p, false)
+	if query != nil {
+		u.RawQuery = sigv4.CanonicalQuery(query)
+	}
+	return &u
+}
+
+func (s *S3Store) key(elems ...string) string {
+	return path.Join(append([]string{s.prefix}, elems...)...)
+}
+
+func (s *S3Store) location(key string) string {
+	return "s3://" + s.bucket + "/" + key
+}
+
+func checkArtifactRef(runID string, artifactType ArtifactType, filename string) error {
+	if runID == "" {
+		return fmt.Errorf("run ID cannot be empty")
+	}
+	if artifactType == "" {
+		return fmt.Errorf("artifact type cannot be empty")
+	}
+	if filename == "" {
+		return fmt.Errorf("filename cannot be empty")
+	}
+	return nil
+}
+
+// s3Error is an error response from the object store.
+type s3Error struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *s3Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("status %d", e.Status)
+	}
+	return fmt.Sprintf("status %d: %s: %s", e.Status, e.Code, e.Message)
+}
+
+// parseS3Error returns the error of an S3 response, with the code and
+// message from its body if it has one.
+func parseS3Error(status int, body []byte) error {
+	e := &s3Error{Status: status}
+	var parsed struct {
+		XMLName xml.Name
+		Code    string
+		Message string
+	}
+	if xml.Unmarshal(body, &parsed) == nil && parsed.XMLName.Local == "Error" {
+		e.Code = parsed.Code
+		e.Message = parsed.Message
+	}
+	return e
+}
+
+func isS3NotFound(err error) bool {
+	var e *s3Error
+	return errors.As(err, &e) && e.Status == http.StatusNotFound
+}
@@ -0,0 +1,272 @@
+package artifacts
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeS3 is an in-memory S3 subset, addressed path style.
+type fakeS3 struct {
+	t       *testing.T
+	bucket  string
+	mu      sync.Mutex
+	objects map[string][]byte
+	uploads map[string]map[string][]byte
+	parts   int
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *S3Store) {
+	t.Helper()
+	fake := &fakeS3{t: t, bucket: "drill", objects: map[string][]byte{}, uploads: map[string]map[string][]byte{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	store, err := NewS3Store(S3Config{
+		Bucket:          "drill",
+		Prefix:          "/ci/",
+		Endpoint:        server.URL,
+		PathStyle:       true,
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+	})
+	if err != nil {
+		t.Fatalf("NewS3Store failed: %v", err)
+	}
+	return fake, store
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+		f.t.Errorf("%s %s: unsigned request", r.Method, r.URL)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := strings.TrimPrefix(r.URL.Path, "/"+f.bucket+"/")
+	query := r.URL.Query()
+	body, _ := io.ReadAll(r.Body)
+
+	switch {
+	case r.Method == http.MethodGet && query.Get("list-type") == "2":
+		f.list(w, query)
+	case r.Method == http.MethodGet:
+		data, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "<Error><Code>NoSuchKey</Code><Message>not found</Message></Error>")
+			return
+		}
+		_, _ = w.Write(data)
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		id := fmt.Sprintf("upload-%d", len(f.uploads)+1)
+		f.uploads[id] = map[string][]byte{}
+		fmt.Fprintf(w, "<InitiateMultipartUploadResult><UploadId>%s</UploadId></InitiateMultipartUploadResult>", id)
+	case r.Method == http.MethodPut && query.Has("uploadId"):
+		f.parts++
+		f.uploads[query.Get("uploadId")][query.Get("partNumber")] = body
+		w.Header().Set("ETag", `"etag-`+query.Get("partNumber")+`"`)
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		var complete struct {
+			Parts []struct {
+				PartNumber string
+				ETag       string
+			} `xml:"Part"`
+		}
+		_ = xml.Unmarshal(body, &complete)
+		var data []byte
+		for _, p := range complete.Parts {
+			if p.ETag != `"etag-`+p.PartNumber+`"` {
+				f.t.Errorf("part %s completed with ETag %s", p.PartNumber, p.ETag)
+			}
+			data = append(data, f.uploads[query.Get("uploadId")][p.PartNumber]...)
+		}
+		f.objects[key] = data
+		fmt.Fprint(w, "<CompleteMultipartUploadResult/>")
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		delete(f.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		f.objects[key] = body
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// list returns one key per page to exercise continuation.
+func (f *fakeS3) list(w http.ResponseWriter, query url.Values) {
+	var keys []string
+	for k := range f.objects {
+		if strings.HasPrefix(k, query.Get("prefix")) && k > query.Get("continuation-token") {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	fmt.Fprint(w, "<ListBucketResult>")
+	if len(keys) > 0 {
+		fmt.Fprintf(w, "<Contents><Key>%s</Key><Size>%d</Size></Contents>", keys[0], len(f.objects[keys[0]]))
+	}
+	if len(keys) > 1 {
+		fmt.Fprintf(w, "<IsTruncated>true</IsTruncated><NextContinuationToken>%s</NextContinuationToken>", keys[0])
+	}
+	fmt.Fprint(w, "</ListBucketResult>")
+}
+
+func TestS3Store_RoundTrip(t *testing.T) {
+	fake, store := newFakeS3(t)
+
+	info, err := store.SaveArtifact("run-1", ArtifactTypeReport, "report.json", []byte(`{"ok":true}`))
+	if err != nil {
+		t.Fatalf("SaveArtifact failed: %v", err)
+	}
+	if info.Path != "s3://drill/ci/run-1/reports/report.json" || info.SizeBytes != 11 {
+		t.Errorf("unexpected info: %+v", info)
+	}
+	if _, ok := fake.objects["ci/run-1/reports/report.json"]; !ok {
+		t.Errorf("object not stored under prefix, have %v", fake.objects)
+	}
+	_, _ = store.SaveArtifact("run-1", ArtifactTypeTelemetry, "ops log.jsonl", []byte("{}\n"))
+	_, _ = store.SaveArtifact("run-2", ArtifactTypeReport, "report.json", []byte("{}"))
+
+	data, err := store.GetArtifact("run-1", ArtifactTypeReport, "report.json")
+	if err != nil || string(data) != `{"ok":true}` {
+		t.Errorf("GetArtifact = %q, %v", data, err)
+	}
+	if _, err := store.GetArtifact("run-1", ArtifactTypeReport, "missing.json"); err == nil || !strings.Contains(err.Error(), "artifact not found") {
+		t.Errorf("expected not found error, got %v", err)
+	}
+
+	list, err := store.ListArtifacts("run-1")
+	if err != nil {
+		t.Fatalf("ListArtifacts failed: %v", err)
+	}
+	if len(list) != 2 || list[0].Filename != "report.json" || list[1].ArtifactType != ArtifactTypeTelemetry || list[1].Filename != "ops log.jsonl" {
+		t.Errorf("unexpected listing: %+v", list)
+	}
+
+	if err := store.DeleteArtifacts("run-1"); err != nil {
+		t.Fatalf("DeleteArtifacts failed: %v", err)
+	}
+	if list, _ := store.ListArtifacts("run-1"); len(list) != 0 {
+		t.Errorf("expected no artifacts after delete, got %+v", list)
+	}
+	if list, _ := store.ListArtifacts("run-2"); len(list) != 1 {
+		t.Errorf("expected other runs to be kept, got %+v", list)
+	}
+}
+
+func TestS3Store_Multipart(t *testing.T) {
+	fake, store := newFakeS3(t)
+	store.partSize = 8
+
+	data := []byte("0123456789abcdefghij")
+	if _, err := store.SaveArtifact("run-1", ArtifactTypeTelemetry, "ops.jsonl", data); err != nil {
+		t.Fatalf("SaveArtifact failed: %v", err)
+	}
+	if fake.parts != 3 {
+		t.Errorf("expected 3 parts, got %d", fake.parts)
+	}
+	if got := string(fake.objects["ci/run-1/telemetry/ops.jsonl"]); got != string(data) {
+		t.Errorf("reassembled object = %q", got)
+	}
+}
+
+func TestS3Store_SaveArtifactStream(t *testing.T) {
+	fake, store := newFakeS3(t)
+	store.partSize = 8
+
+	info, err := store.SaveArtifactStream("run-1", ArtifactTypeTelemetry, "small.jsonl", func(w io.Writer) error {
+		_, err := io.WriteString(w, "12345678")
+		return err
+	})
+	if err != nil || info.SizeBytes != 8 {
+		t.Fatalf("SaveArtifactStream = %+v, %v", info, err)
+	}
+	if fake.parts != 0 || string(fake.objects["ci/run-1/telemetry/small.jsonl"]) != "12345678" {
+		t.Errorf("expected a single PUT, got %d parts", fake.parts)
+	}
+
+	info, err = store.SaveArtifactStream("run-1", ArtifactTypeTelemetry, "ops.jsonl", func(w io.Writer) error {
+		for _, chunk := range []string{"0123", "456789abc", "d", "efghij"} {
+			if _, err := io.WriteString(w, chunk); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil || info.SizeBytes != 20 {
+		t.Fatalf("SaveArtifactStream = %+v, %v", info, err)
+	}
+	if fake.parts != 3 || string(fake.objects["ci/run-1/telemetry/ops.jsonl"]) != "0123456789abcdefghij" {
+		t.Errorf("got %d parts, object %q", fake.parts, fake.objects["ci/run-1/telemetry/ops.jsonl"])
+	}
+
+	_, err = store.SaveArtifactStream("run-1", ArtifactTypeTelemetry, "failed.jsonl", func(w io.Writer) error {
+		_, _ = io.WriteString(w, "0123456789abcdefghij")
+		return fmt.Errorf("source failed")
+	})
+	if err == nil {
+		t.Fatal("expected error from failed write")
+	}
+	if _, ok := fake.objects["ci/run-1/telemetry/failed.jsonl"]; ok || len(fake.uploads) != 1 {
+		t.Errorf("expected failed upload to be aborted, have %d uploads", len(fake.uploads))
+	}
+}
+
+func TestS3Store_SignedURL(t *testing.T) {
+	_, store := newFakeS3(t)
+	store.now = func() time.Time { return time.Date(2013, 5, 24, 0, 0, 0, 0, time.UTC) }
+
+	signed, err := store.SignedURL("run-1", ArtifactTypeReport, "report.html", time.Hour)
+	if err != nil {
+		t.Fatalf("SignedURL failed: %v", err)
+	}
+	u, _ := url.Parse(signed)
+	if u.Path != "/drill/ci/run-1/reports/report.html" {
+		t.Errorf("unexpected path %s", u.Path)
+	}
+	q := u.Query()
+	if q.Get("X-Amz-Expires") != "3600" || q.Get("X-Amz-Date") != "20130524T000000Z" || len(q.Get("X-Amz-Signature")) != 64 {
+		t.Errorf("unexpected query %v", q)
+	}
+
+	if _, err := store.SignedURL("run-1", ArtifactTypeReport, "report.html", 8*24*time.Hour); err == nil {
+		t.Error("expected error for expiry beyond 7 days")
+	}
+}
+
+func TestNewS3Store_Invalid(t *testing.T) {
+	valid := S3Config{Bucket: "b", AccessKeyID: "a", SecretAccessKey: "s"}
+	tests := []func(*S3Config){
+		func(c *S3Config) { c.Bucket = "" },
+		func(c *S3Config) { c.SecretAccessKey = "" },
+		func(c *S3Config) { c.Endpoint = "localhost:9000" },
+		func(c *S3Config) { c.PartSize = 1024 },
+	}
+	for i, mutate := range tests {
+		cfg := valid
+		mutate(&cfg)
+		if _, err := NewS3Store(cfg); err == nil {
+			t.Errorf("case %d: expected error for %+v", i, cfg)
+		}
+	}
+
+	store, err := NewS3Store(valid)
+	if err != nil {
+		t.Fatalf("NewS3Store failed: %v", err)
+	}
+	if got := store.objectURL("k", nil).String(); got != "https://b.s3.us-east-1.amazonaws.com/k" {
+		t.Errorf("unexpected virtual-hosted URL %s", got)
+	}
+}
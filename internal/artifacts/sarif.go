@@ -0,0 +1,138 @@
+package artifacts
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+
+	// SARIF rule ids. Each failed SLO or check is a result of one of them.
+	sarifRuleSLO   = "mcpdrill/slo"
+	sarifRuleCheck = "mcpdrill/check"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool       sarifTool              `json:"tool"`
+	Results    []sarifResult          `json:"results"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName,omitempty"`
+	Kind               string `json:"kind"`
+}
+
+// ExportSARIF renders the failed SLOs and response checks of a report as a
+// SARIF 2.1.0 log. Failed SLOs are errors and failed checks warnings;
+// passing ones produce no result. Results are located logically at the
+// tool or operation they measured, since a load test has no source file.
+func ExportSARIF(report *analysis.Report) ([]byte, error) {
+	if report == nil {
+		return nil, fmt.Errorf("report cannot be nil")
+	}
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "mcpdrill",
+			InformationURI: "https://github.com/bc-dunia/mcpdrill",
+			Rules: []sarifRule{
+				{ID: sarifRuleSLO, Name: "ServiceLevelObjective", ShortDescription: sarifMessage{Text: "A service level objective of the run was not met."}},
+				{ID: sarifRuleCheck, Name: "ResponseCheck", ShortDescription: sarifMessage{Text: "A response check failed for some operations."}},
+			},
+		}},
+		Results:    []sarifResult{},
+		Properties: map[string]interface{}{"run_id": report.RunID},
+	}
+
+	if slos := report.SLOs; slos != nil {
+		run.Properties["slo_verdict"] = slos.Verdict
+		for _, r := range slos.Results {
+			if r.Passed {
+				continue
+			}
+			run.Results = append(run.Results, sarifResult{
+				RuleID:    sarifRuleSLO,
+				Level:     "error",
+				Message:   sarifMessage{Text: "SLO " + r.Name + " failed: " + sloFailureMessage(r)},
+				Locations: sarifLocations(r.Operation, r.ToolName),
+			})
+		}
+	}
+
+	if report.Metrics != nil && report.Metrics.Checks != nil {
+		for _, c := range report.Metrics.Checks.Checks {
+			if c.Failed == 0 {
+				continue
+			}
+			run.Results = append(run.Results, sarifResult{
+				RuleID:    sarifRuleCheck,
+				Level:     "warning",
+				Message:   sarifMessage{Text: "Check " + c.Name + " failed: " + checkFailureMessage(c)},
+				Locations: sarifLocations(c.Operation, c.ToolName),
+			})
+		}
+	}
+
+	data, err := json.MarshalIndent(sarifLog{Version: sarifVersion, Schema: sarifSchema, Runs: []sarifRun{run}}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode SARIF: %w", err)
+	}
+	return data, nil
+}
+
+func sarifLocations(operation, tool string) []sarifLocation {
+	switch {
+	case tool != "":
+		return []sarifLocation{{LogicalLocations: []sarifLogicalLocation{
+			{Name: tool, FullyQualifiedName: "tools/call/" + tool, Kind: "function"},
+		}}}
+	case operation != "":
+		return []sarifLocation{{LogicalLocations: []sarifLogicalLocation{
+			{Name: operation, Kind: "member"},
+		}}}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,64 @@
+package artifacts
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+)
+
+func TestExportSARIF(t *testing.T) {
+	data, err := ExportSARIF(createCIReport())
+	if err != nil {
+		t.Fatalf("ExportSARIF failed: %v", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if log.Version != sarifVersion || len(log.Runs) != 1 {
+		t.Fatalf("unexpected log: version %s, %d runs", log.Version, len(log.Runs))
+	}
+	run := log.Runs[0]
+	if run.Tool.Driver.Name != "mcpdrill" || len(run.Tool.Driver.Rules) != 2 {
+		t.Errorf("unexpected driver: %+v", run.Tool.Driver)
+	}
+	if run.Properties["slo_verdict"] != analysis.SLOVerdictFail {
+		t.Errorf("expected FAIL verdict property, got %v", run.Properties["slo_verdict"])
+	}
+
+	if len(run.Results) != 3 {
+		t.Fatalf("expected 3 results for failures only, got %d", len(run.Results))
+	}
+	if r := run.Results[0]; r.RuleID != sarifRuleSLO || r.Level != "error" {
+		t.Errorf("unexpected SLO result: %+v", r)
+	}
+	if loc := run.Results[0].Locations; len(loc) != 1 || loc[0].LogicalLocations[0].Name != "search" {
+		t.Errorf("expected tool location, got %+v", loc)
+	}
+	if loc := run.Results[1].Locations; len(loc) != 1 || loc[0].LogicalLocations[0].Kind != "member" {
+		t.Errorf("expected operation location, got %+v", loc)
+	}
+	if r := run.Results[2]; r.RuleID != sarifRuleCheck || r.Level != "warning" {
+		t.Errorf("unexpected check result: %+v", r)
+	}
+}
+
+func TestExportSARIF_AllPassed(t *testing.T) {
+	data, err := ExportSARIF(&analysis.Report{
+		RunID: "run_0000000000000001",
+		SLOs:  &analysis.SLOReport{Verdict: analysis.SLOVerdictPass},
+	})
+	if err != nil {
+		t.Fatalf("ExportSARIF failed: %v", err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	results := raw["runs"].([]interface{})[0].(map[string]interface{})["results"]
+	if results == nil || len(results.([]interface{})) != 0 {
+		t.Errorf("expected empty results array, got %v", results)
+	}
+}
@@ -2,9 +2,15 @@
 package artifacts
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 )
 
@@ -47,6 +53,46 @@ type Store interface {
 	DeleteArtifacts(runID string) error
 }
 
+// StreamStore is implemented by stores that can save an artifact while it
+// is being produced, without holding all of it in memory.
+type StreamStore interface {
+	// SaveArtifactStream stores the output of write. Nothing is stored if
+	// write fails.
+	SaveArtifactStream(runID string, artifactType ArtifactType, filename string, write func(io.Writer) error) (*ArtifactInfo, error)
+}
+
+// ReaderStore is implemented by stores that can read an artifact without
+// loading it into memory.
+type ReaderStore interface {
+	// OpenArtifact opens an artifact for reading. The caller closes it.
+	OpenArtifact(runID string, artifactType ArtifactType, filename string) (io.ReadCloser, error)
+}
+
+// SaveStream saves the output of write to store, streaming it if the store
+// is a StreamStore and buffering it otherwise.
+func SaveStream(store Store, runID string, artifactType ArtifactType, filename string, write func(io.Writer) error) (*ArtifactInfo, error) {
+	if ss, ok := store.(StreamStore); ok {
+		return ss.SaveArtifactStream(runID, artifactType, filename, write)
+	}
+	var buf bytes.Buffer
+	if err := write(&buf); err != nil {
+		return nil, err
+	}
+	return store.SaveArtifact(runID, artifactType, filename, buf.Bytes())
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
 // FilesystemStore implements Store using the local filesystem.
 // Artifacts are stored in {baseDir}/{runID}/{artifactType}/{filename}.
 type FilesystemStore struct {
@@ -142,6 +188,27 @@ func (fs *FilesystemStore) GetArtifact(runID string, artifactType ArtifactType,
 	return data, nil
 }
 
+// OpenArtifact opens an artifact for reading.
+func (fs *FilesystemStore) OpenArtifact(runID string, artifactType ArtifactType, filename string) (io.ReadCloser, error) {
+	if err := checkArtifactRef(runID, artifactType, filename); err != nil {
+		return nil, err
+	}
+	for _, name := range []string{runID, string(artifactType), filename} {
+		if name == "." || name == ".." || filepath.Base(name) != name {
+			return nil, fmt.Errorf("artifact reference cannot contain path separators")
+		}
+	}
+
+	f, err := os.Open(filepath.Join(fs.baseDir, runID, string(artifactType), filename))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("artifact not found: %s/%s/%s", runID, artifactType, filename)
+		}
+		return nil, fmt.Errorf("failed to read artifact: %w", err)
+	}
+	return f, nil
+}
+
 // ListArtifacts lists all artifacts for a run.
 func (fs *FilesystemStore) ListArtifacts(runID string) ([]ArtifactInfo, error) {
 	if runID == "" {
@@ -171,7 +238,8 @@ func (fs *FilesystemStore) ListArtifacts(runID string) ([]ArtifactInfo, error) {
 		}
 
 		for _, entry := range entries {
-			if entry.IsDir() {
+			// Dot files are artifacts still being streamed.
+			if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
 				continue
 			}
 
@@ -193,6 +261,60 @@ func (fs *FilesystemStore) ListArtifacts(runID string) ([]ArtifactInfo, error) {
 	return artifacts, nil
 }
 
+// SaveArtifactStream stores an artifact as write produces it. It is written
+// to a hidden temporary file and renamed into place when complete, so
+// readers never see a partial artifact.
+func (fs *FilesystemStore) SaveArtifactStream(runID string, artifactType ArtifactType, filename string, write func(io.Writer) error) (*ArtifactInfo, error) {
+	if err := checkArtifactRef(runID, artifactType, filename); err != nil {
+		return nil, err
+	}
+	if filepath.Base(filename) != filename {
+		return nil, fmt.Errorf("filename cannot contain path separators")
+	}
+
+	dir := filepath.Join(fs.baseDir, runID, string(artifactType))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create artifact directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, "."+filename+".*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create artifact: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	buffered := bufio.NewWriterSize(tmp, 256<<10)
+	counter := &countingWriter{w: buffered}
+	err = write(counter)
+	if err == nil {
+		err = buffered.Flush()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write artifact: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write artifact: %w", err)
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	filePath := filepath.Join(dir, filename)
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return nil, fmt.Errorf("failed to write artifact: %w", err)
+	}
+
+	return &ArtifactInfo{
+		RunID:        runID,
+		ArtifactType: artifactType,
+		Filename:     filename,
+		Path:         filePath,
+		SizeBytes:    counter.n,
+	}, nil
+}
+
 // DeleteArtifacts deletes all artifacts for a run.
 func (fs *FilesystemStore) DeleteArtifacts(runID string) error {
 	if runID == "" {
@@ -218,3 +340,69 @@ func (fs *FilesystemStore) DeleteArtifacts(runID string) error {
 func (fs *FilesystemStore) BaseDir() string {
 	return fs.baseDir
 }
+
+// Open opens the artifact store named by spec:
+//
+//   - a directory, or file:<dir>, for a FilesystemStore
+//   - s3://bucket/prefix for an S3Store on AWS or an S3-compatible service
+//   - gs://bucket/prefix for an S3Store on Google Cloud Storage's XML API,
+//     with HMAC keys
+//
+// Object store URLs take region, endpoint and path_style query parameters.
+// Credentials, and the region and endpoint if not given, come from the
+// standard AWS_* environment variables.
+func Open(spec string) (Store, error) {
+	return openStore(spec, os.Getenv)
+}
+
+func openStore(spec string, getenv func(string) string) (Store, error) {
+	scheme, rest, ok := strings.Cut(spec, ":")
+	if !ok || len(scheme) < 2 {
+		// A bare path, possibly with a Windows drive letter.
+		if spec == "" {
+			return nil, fmt.Errorf("artifact store cannot be empty")
+		}
+		return NewFilesystemStore(spec)
+	}
+
+	switch scheme {
+	case "file":
+		return NewFilesystemStore(strings.TrimPrefix(rest, "//"))
+	case "s3", "gs":
+		u, err := url.Parse(spec)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid artifact store %q: expected %s://bucket/prefix", spec, scheme)
+		}
+		query := u.Query()
+		cfg := S3Config{
+			Bucket:          u.Host,
+			Prefix:          u.Path,
+			Region:          firstNonEmpty(query.Get("region"), getenv("AWS_REGION"), getenv("AWS_DEFAULT_REGION")),
+			Endpoint:        firstNonEmpty(query.Get("endpoint"), getenv("AWS_ENDPOINT_URL_S3"), getenv("AWS_ENDPOINT_URL")),
+			AccessKeyID:     getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    getenv("AWS_SESSION_TOKEN"),
+		}
+		if v := query.Get("path_style"); v != "" {
+			if cfg.PathStyle, err = strconv.ParseBool(v); err != nil {
+				return nil, fmt.Errorf("invalid path_style %q: %w", v, err)
+			}
+		}
+		if scheme == "gs" {
+			cfg.Region = firstNonEmpty(query.Get("region"), "auto")
+			cfg.Endpoint = firstNonEmpty(query.Get("endpoint"), "https://storage.googleapis.com")
+		}
+		return NewS3Store(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported artifact store %q: expected a directory, file:, s3:// or gs://", scheme)
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
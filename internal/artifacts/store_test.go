@@ -1,6 +1,8 @@
 package artifacts
 
 import (
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sync"
@@ -385,3 +387,101 @@ func TestArtifactTypes(t *testing.T) {
 		t.Errorf("expected 3 artifacts, got %d", len(artifacts))
 	}
 }
+
+func TestOpen(t *testing.T) {
+	env := map[string]string{
+		"AWS_ACCESS_KEY_ID":     "AKID",
+		"AWS_SECRET_ACCESS_KEY": "secret",
+		"AWS_REGION":            "eu-west-1",
+	}
+	getenv := func(k string) string { return env[k] }
+
+	dir := t.TempDir()
+	for _, spec := range []string{dir, "file:" + dir} {
+		store, err := openStore(spec, getenv)
+		if err != nil {
+			t.Fatalf("openStore(%q) failed: %v", spec, err)
+		}
+		if fs, ok := store.(*FilesystemStore); !ok || fs.BaseDir() != dir {
+			t.Errorf("openStore(%q) = %#v, want filesystem store in %s", spec, store, dir)
+		}
+	}
+
+	store, err := openStore("s3://reports/mcpdrill?endpoint=http://localhost:9000&path_style=true", getenv)
+	if err != nil {
+		t.Fatalf("openStore s3 failed: %v", err)
+	}
+	s3 := store.(*S3Store)
+	if s3.Bucket() != "reports" || s3.prefix != "mcpdrill" || s3.signer.Region != "eu-west-1" {
+		t.Errorf("unexpected S3 store: bucket %s, prefix %s, region %s", s3.Bucket(), s3.prefix, s3.signer.Region)
+	}
+	if got := s3.objectURL("k", nil).String(); got != "http://localhost:9000/reports/k" {
+		t.Errorf("unexpected object URL %s", got)
+	}
+
+	store, err = openStore("gs://reports", getenv)
+	if err != nil {
+		t.Fatalf("openStore gs failed: %v", err)
+	}
+	if got := store.(*S3Store).objectURL("k", nil).String(); got != "https://reports.storage.googleapis.com/k" {
+		t.Errorf("unexpected GCS object URL %s", got)
+	}
+
+	for _, spec := range []string{"", "s3://", "az://container/prefix", "s3://b?path_style=maybe"} {
+		if _, err := openStore(spec, getenv); err == nil {
+			t.Errorf("openStore(%q) succeeded, want error", spec)
+		}
+	}
+	if _, err := openStore("s3://reports", func(string) string { return "" }); err == nil {
+		t.Error("expected error without credentials")
+	}
+}
+
+func TestFilesystemStore_SaveArtifactStream(t *testing.T) {
+	store, _ := NewFilesystemStore(t.TempDir())
+
+	info, err := SaveStream(store, "run-1", ArtifactTypeTelemetry, "ops.jsonl", func(w io.Writer) error {
+		for i := 0; i < 3; i++ {
+			fmt.Fprintf(w, "{\"n\":%d}\n", i)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("SaveStream failed: %v", err)
+	}
+	if info.SizeBytes != 24 || info.Path != filepath.Join(store.BaseDir(), "run-1", "telemetry", "ops.jsonl") {
+		t.Errorf("unexpected info: %+v", info)
+	}
+
+	_, err = SaveStream(store, "run-1", ArtifactTypeTelemetry, "failed.jsonl", func(w io.Writer) error {
+		_, _ = io.WriteString(w, "partial")
+		return fmt.Errorf("source failed")
+	})
+	if err == nil {
+		t.Fatal("expected error from failed write")
+	}
+
+	rc, err := store.OpenArtifact("run-1", ArtifactTypeTelemetry, "ops.jsonl")
+	if err != nil {
+		t.Fatalf("OpenArtifact failed: %v", err)
+	}
+	data, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(data) != "{\"n\":0}\n{\"n\":1}\n{\"n\":2}\n" {
+		t.Errorf("unexpected content %q", data)
+	}
+	for _, name := range []string{"..", "../ops.jsonl", "missing.jsonl"} {
+		if _, err := store.OpenArtifact("run-1", ArtifactTypeTelemetry, name); err == nil {
+			t.Errorf("OpenArtifact(%q) succeeded, want error", name)
+		}
+	}
+
+	list, _ := store.ListArtifacts("run-1")
+	if len(list) != 1 || list[0].Filename != "ops.jsonl" {
+		t.Errorf("expected only the complete artifact, got %+v", list)
+	}
+	entries, _ := os.ReadDir(filepath.Join(store.BaseDir(), "run-1", "telemetry"))
+	if len(entries) != 1 {
+		t.Errorf("expected temporary files to be removed, got %d entries", len(entries))
+	}
+}
@@ -7,10 +7,12 @@ import (
 	"strings"
 )
 
-// APIKeyAuthenticator validates API keys from request headers.
+// APIKeyAuthenticator validates API keys from request headers, both the
+// configured ones and those in the managed key store.
 type APIKeyAuthenticator struct {
 	keyHashes  map[string]bool
 	keyToRoles map[string][]Role
+	keyStore   *KeyStore
 }
 
 // NewAPIKeyAuthenticator creates a new API key authenticator.
@@ -18,6 +20,7 @@ func NewAPIKeyAuthenticator(config *Config) *APIKeyAuthenticator {
 	a := &APIKeyAuthenticator{
 		keyHashes:  make(map[string]bool),
 		keyToRoles: make(map[string][]Role),
+		keyStore:   config.KeyStore,
 	}
 
 	for _, key := range config.APIKeys {
@@ -42,6 +45,11 @@ func (a *APIKeyAuthenticator) Authenticate(r *http.Request) (*User, error) {
 	}
 
 	if !a.validateKey(key) {
+		if a.keyStore != nil {
+			if managed, ok := a.keyStore.Lookup(key); ok {
+				return managed.User(), nil
+			}
+		}
 		return nil, ErrInvalidCredentials
 	}
 
@@ -46,6 +46,24 @@ type Config struct {
 	JWTSecret []byte `json:"-"`
 	// JWTIssuer is the expected issuer for JWT tokens.
 	JWTIssuer string `json:"jwt_issuer,omitempty"`
+	// JWTAudience, if set, must be one of a token's aud values.
+	JWTAudience string `json:"jwt_audience,omitempty"`
+	// JWKSURL serves the public keys for RS256/384/512 and ES256/384
+	// tokens, as published by OIDC providers.
+	JWKSURL string `json:"jwks_url,omitempty"`
+	// JWTRolesClaim is the claim holding a token's roles, a dotted path for
+	// nested claims such as realm_access.roles. Defaults to "roles".
+	JWTRolesClaim string `json:"jwt_roles_claim,omitempty"`
+	// JWTRoleMapping maps values of the roles claim, such as IdP group
+	// names, to roles. Values named like a role map to that role.
+	JWTRoleMapping map[string]Role `json:"jwt_role_mapping,omitempty"`
+	// JWTProjectsClaim is the claim listing the projects a token may act
+	// in, a dotted path like JWTRolesClaim. Tokens without it may act in
+	// every project. Defaults to "projects".
+	JWTProjectsClaim string `json:"jwt_projects_claim,omitempty"`
+	// KeyStore holds the API keys managed through the API. They are accepted
+	// in api_key mode next to APIKeys, which bootstrap the first admin.
+	KeyStore *KeyStore `json:"-"`
 	// SkipPaths are paths that don't require authentication.
 	// /healthz and /readyz are always skipped.
 	SkipPaths []string `json:"skip_paths,omitempty"`
@@ -65,6 +83,14 @@ type User struct {
 	ID string
 	// Roles are the roles assigned to this user.
 	Roles []Role
+	// Scopes restrict a managed API key further than its roles. Nil means
+	// the user is limited by roles alone.
+	Scopes []Scope
+	// Claims are the claims of a JWT user's token.
+	Claims map[string]interface{}
+	// Projects are the projects the user may act in. Nil allows every
+	// project.
+	Projects []string
 }
 
 // HasRole checks if the user has a specific role.
@@ -80,6 +106,39 @@ func (u *User) HasRole(role Role) bool {
 	return false
 }
 
+// HasScope checks if the user may act within scope. Users without scopes
+// may, as may keys with the admin scope.
+func (u *User) HasScope(scope Scope) bool {
+	if u == nil {
+		return false
+	}
+	if u.Scopes == nil {
+		return true
+	}
+	for _, s := range u.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// CanAccessProject checks if the user may act in project.
+func (u *User) CanAccessProject(project string) bool {
+	if u == nil {
+		return false
+	}
+	if u.Projects == nil {
+		return true
+	}
+	for _, p := range u.Projects {
+		if p == project {
+			return true
+		}
+	}
+	return false
+}
+
 // HasAnyRole checks if the user has any of the specified roles.
 func (u *User) HasAnyRole(roles ...Role) bool {
 	for _, role := range roles {
@@ -2,12 +2,17 @@ package auth
 
 import (
 	"context"
+	"crypto"
 	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -166,6 +171,79 @@ func TestAPIKeyAuthenticator(t *testing.T) {
 	}
 }
 
+func TestAPIKeyAuthenticator_ManagedKeys(t *testing.T) {
+	store := NewKeyStore()
+	var saved []ManagedKey
+	store.SetPersistFunc(func(k ManagedKey) error {
+		saved = append(saved, k)
+		return nil
+	})
+	auth := NewAPIKeyAuthenticator(&Config{Mode: AuthModeAPIKey, KeyStore: store})
+
+	if _, _, err := store.Create("ci", []Scope{"runs:delete"}, nil, "admin"); err == nil {
+		t.Fatal("expected error for unknown scope")
+	}
+	if _, _, err := store.Create("ci", []Scope{ScopeRunsRead}, []string{"Team A"}, "admin"); err == nil {
+		t.Fatal("expected error for invalid project name")
+	}
+	key, secret, err := store.Create("ci", []Scope{ScopeRunsRead, ScopeWorkersRegister}, []string{"team-a"}, "admin")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if len(saved) != 1 || saved[0].Hash == "" || saved[0].Hash == secret {
+		t.Fatalf("expected the hash to be persisted, got %+v", saved)
+	}
+
+	authenticate := func(secret string) (*User, error) {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("X-API-Key", secret)
+		return auth.Authenticate(req)
+	}
+	user, err := authenticate(secret)
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if user.ID != key.ID || !user.HasRole(RoleOperator) || user.HasRole(RoleAdmin) {
+		t.Errorf("unexpected user %+v", user)
+	}
+	if !user.HasScope(ScopeWorkersRegister) || user.HasScope(ScopeRunsWrite) {
+		t.Errorf("unexpected scopes %v", user.Scopes)
+	}
+	if !user.CanAccessProject("team-a") || user.CanAccessProject("team-b") {
+		t.Errorf("unexpected projects %v", user.Projects)
+	}
+	if store.List()[0].LastUsedAtMs == 0 {
+		t.Error("expected last use to be recorded")
+	}
+
+	rotated, newSecret, err := store.Rotate(key.ID)
+	if err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if rotated.ID != key.ID || newSecret == secret {
+		t.Errorf("unexpected rotation %+v", rotated)
+	}
+	if _, err := authenticate(secret); err == nil {
+		t.Error("expected the old secret to stop working")
+	}
+	if _, err := authenticate(newSecret); err != nil {
+		t.Errorf("expected the new secret to work: %v", err)
+	}
+
+	if _, err := store.Revoke(key.ID); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if _, err := authenticate(newSecret); err == nil {
+		t.Error("expected a revoked key to be rejected")
+	}
+	if _, _, err := store.Rotate(key.ID); err != ErrKeyNotFound {
+		t.Errorf("expected ErrKeyNotFound rotating a revoked key, got %v", err)
+	}
+	if keys := store.List(); len(keys) != 1 || !keys[0].Revoked() {
+		t.Errorf("expected the revoked key to stay listed, got %+v", keys)
+	}
+}
+
 func TestMiddlewareNoAuth(t *testing.T) {
 	config := &Config{Mode: AuthModeNone}
 	mw := NewMiddleware(config, nil)
@@ -417,3 +495,102 @@ func createTestJWT(t *testing.T, secret []byte, sub, iss string, exp int64, role
 
 	return signData + "." + sig
 }
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	headerBytes, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid})
+	claimsBytes, _ := json.Marshal(claims)
+	signData := base64.RawURLEncoding.EncodeToString(headerBytes) + "." + base64.RawURLEncoding.EncodeToString(claimsBytes)
+	digest := sha256.Sum256([]byte(signData))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signData + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestJWTAuthenticator_JWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	var fetches atomic.Int32
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "k1",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	}))
+	defer jwks.Close()
+
+	authn := NewJWTAuthenticator(&Config{
+		Mode:           AuthModeJWT,
+		JWKSURL:        jwks.URL,
+		JWTIssuer:      "https://idp.example.com",
+		JWTAudience:    "mcpdrill",
+		JWTRolesClaim:  "realm_access.roles",
+		JWTRoleMapping: map[string]Role{"load-testers": RoleOperator},
+	})
+	claims := func(aud interface{}, roles ...string) map[string]interface{} {
+		return map[string]interface{}{
+			"sub":          "alice",
+			"iss":          "https://idp.example.com",
+			"aud":          aud,
+			"exp":          time.Now().Add(time.Hour).Unix(),
+			"realm_access": map[string]interface{}{"roles": roles},
+		}
+	}
+	authenticate := func(token string) (*User, error) {
+		req := httptest.NewRequest("GET", "/runs", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		return authn.Authenticate(req)
+	}
+
+	user, err := authenticate(signRS256(t, key, "k1", claims([]string{"other", "mcpdrill"}, "load-testers", "offline_access")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if user.ID != "alice" || len(user.Roles) != 1 || user.Roles[0] != RoleOperator {
+		t.Errorf("expected alice with the mapped operator role, got %+v", user)
+	}
+	if user.Projects != nil || !user.CanAccessProject("team-a") {
+		t.Errorf("expected a token without a projects claim to reach every project, got %v", user.Projects)
+	}
+
+	withProjects := claims("mcpdrill")
+	withProjects["projects"] = []string{"team-a"}
+	if user, _ := authenticate(signRS256(t, key, "k1", withProjects)); user == nil || !user.CanAccessProject("team-a") || user.CanAccessProject("team-b") {
+		t.Errorf("expected the projects claim to bind the token to team-a, got %+v", user)
+	}
+
+	if user, _ := authenticate(signRS256(t, key, "k1", claims("mcpdrill"))); user == nil || !user.HasRole(RoleViewer) || user.HasRole(RoleOperator) {
+		t.Errorf("expected a token without roles to get viewer, got %+v", user)
+	}
+
+	_, err = authenticate(signRS256(t, key, "k1", claims("someone-else", "admin")))
+	if authErr, ok := err.(*AuthError); !ok || authErr.ErrorCode != "INVALID_AUDIENCE" {
+		t.Errorf("expected INVALID_AUDIENCE, got %v", err)
+	}
+
+	other, _ := rsa.GenerateKey(rand.Reader, 2048)
+	if _, err := authenticate(signRS256(t, other, "k1", claims("mcpdrill", "admin"))); err != ErrInvalidCredentials {
+		t.Errorf("expected a token signed by another key to be rejected, got %v", err)
+	}
+	if _, err := authenticate(signRS256(t, key, "k2", claims("mcpdrill", "admin"))); err != ErrInvalidCredentials {
+		t.Errorf("expected an unknown key id to be rejected, got %v", err)
+	}
+	if fetches.Load() != 1 {
+		t.Errorf("expected the key set to be fetched once, got %d", fetches.Load())
+	}
+
+	// An HS256 token cannot be used when only JWKS keys are configured.
+	hsToken := createTestJWT(t, []byte("secret"), "alice", "https://idp.example.com", time.Now().Add(time.Hour).Unix(), []string{"admin"})
+	_, err = authenticate(hsToken)
+	if authErr, ok := err.(*AuthError); !ok || authErr.ErrorCode != "UNSUPPORTED_ALGORITHM" {
+		t.Errorf("expected UNSUPPORTED_ALGORITHM, got %v", err)
+	}
+}
@@ -1,41 +1,89 @@
 package auth
 
 import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
 	"crypto/hmac"
+	"crypto/rsa"
 	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"hash"
+	"math/big"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 )
 
-// JWTAuthenticator validates JWT tokens from request headers.
-// This is a minimal implementation for future use - does not support RS256 or key rotation.
+// JWTAuthenticator validates JWT tokens from request headers. HS256 tokens
+// are checked against a shared secret; RS256/384/512 and ES256/384 tokens
+// against the keys published at a JWKS URL.
 type JWTAuthenticator struct {
-	secret []byte
-	issuer string
+	secret        []byte
+	issuer        string
+	audience      string
+	jwks          *jwksKeySet
+	rolesClaim    string
+	roleMapping   map[string]Role
+	projectsClaim string
 }
 
 // NewJWTAuthenticator creates a new JWT authenticator.
 func NewJWTAuthenticator(config *Config) *JWTAuthenticator {
-	return &JWTAuthenticator{
-		secret: config.JWTSecret,
-		issuer: config.JWTIssuer,
+	a := &JWTAuthenticator{
+		secret:        config.JWTSecret,
+		issuer:        config.JWTIssuer,
+		audience:      config.JWTAudience,
+		rolesClaim:    config.JWTRolesClaim,
+		roleMapping:   config.JWTRoleMapping,
+		projectsClaim: config.JWTProjectsClaim,
 	}
+	if a.rolesClaim == "" {
+		a.rolesClaim = "roles"
+	}
+	if a.projectsClaim == "" {
+		a.projectsClaim = "projects"
+	}
+	if config.JWKSURL != "" {
+		a.jwks = newJWKSKeySet(config.JWKSURL)
+	}
+	return a
 }
 
 type jwtHeader struct {
 	Alg string `json:"alg"`
 	Typ string `json:"typ"`
+	Kid string `json:"kid,omitempty"`
 }
 
 type jwtClaims struct {
-	Sub   string   `json:"sub"`
-	Iss   string   `json:"iss"`
-	Exp   int64    `json:"exp"`
-	Iat   int64    `json:"iat"`
-	Roles []string `json:"roles,omitempty"`
+	Sub string      `json:"sub"`
+	Iss string      `json:"iss"`
+	Aud jwtAudience `json:"aud"`
+	Exp int64       `json:"exp"`
+	Nbf int64       `json:"nbf"`
+	Iat int64       `json:"iat"`
+}
+
+// jwtAudience is the aud claim, which is a string or an array of strings.
+type jwtAudience []string
+
+func (a *jwtAudience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = jwtAudience{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
 }
 
 // Authenticate extracts and validates the JWT from the request.
@@ -45,23 +93,25 @@ func (a *JWTAuthenticator) Authenticate(r *http.Request) (*User, error) {
 		return nil, ErrMissingCredentials
 	}
 
-	claims, err := a.validateToken(token)
+	claims, rawClaims, err := a.validateToken(r.Context(), token)
 	if err != nil {
 		return nil, err
 	}
 
-	roles := make([]Role, 0, len(claims.Roles))
-	for _, r := range claims.Roles {
-		roles = append(roles, Role(r))
-	}
+	roles := a.mapRoles(rawClaims)
 	if len(roles) == 0 {
 		roles = []Role{RoleViewer}
 	}
 
-	return &User{
-		ID:    claims.Sub,
-		Roles: roles,
-	}, nil
+	user := &User{
+		ID:     claims.Sub,
+		Roles:  roles,
+		Claims: rawClaims,
+	}
+	if projects, ok := claimStrings(rawClaims, a.projectsClaim); ok {
+		user.Projects = append([]string{}, projects...)
+	}
+	return user, nil
 }
 
 func (a *JWTAuthenticator) extractToken(r *http.Request) string {
@@ -78,72 +128,73 @@ func (a *JWTAuthenticator) extractToken(r *http.Request) string {
 	return ""
 }
 
-func (a *JWTAuthenticator) validateToken(token string) (*jwtClaims, error) {
-	if len(a.secret) == 0 {
-		return nil, &AuthError{
+func (a *JWTAuthenticator) validateToken(ctx context.Context, token string) (*jwtClaims, map[string]interface{}, error) {
+	if len(a.secret) == 0 && a.jwks == nil {
+		return nil, nil, &AuthError{
 			StatusCode: http.StatusInternalServerError,
 			ErrorType:  "configuration_error",
 			ErrorCode:  "JWT_SECRET_REQUIRED",
-			Message:    "JWT secret is not configured",
+			Message:    "JWT secret or JWKS URL is not configured",
 		}
 	}
 
 	parts := strings.Split(token, ".")
 	if len(parts) != 3 {
-		return nil, ErrInvalidCredentials
+		return nil, nil, ErrInvalidCredentials
 	}
 
 	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
 	if err != nil {
-		return nil, ErrInvalidCredentials
+		return nil, nil, ErrInvalidCredentials
 	}
 
 	var header jwtHeader
 	if err := json.Unmarshal(headerBytes, &header); err != nil {
-		return nil, ErrInvalidCredentials
+		return nil, nil, ErrInvalidCredentials
 	}
 
-	if header.Alg != "HS256" {
-		return nil, &AuthError{
-			StatusCode: http.StatusUnauthorized,
-			ErrorType:  "unauthorized",
-			ErrorCode:  "UNSUPPORTED_ALGORITHM",
-			Message:    "Only HS256 algorithm is supported",
-		}
+	signatureBytes, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, nil, ErrInvalidCredentials
+	}
+	if err := a.verifySignature(ctx, header, parts[0]+"."+parts[1], signatureBytes); err != nil {
+		return nil, nil, err
 	}
 
 	claimsBytes, err := base64.RawURLEncoding.DecodeString(parts[1])
 	if err != nil {
-		return nil, ErrInvalidCredentials
+		return nil, nil, ErrInvalidCredentials
 	}
 
 	var claims jwtClaims
 	if err := json.Unmarshal(claimsBytes, &claims); err != nil {
-		return nil, ErrInvalidCredentials
+		return nil, nil, ErrInvalidCredentials
 	}
-
-	signatureBytes, err := base64.RawURLEncoding.DecodeString(parts[2])
-	if err != nil {
-		return nil, ErrInvalidCredentials
-	}
-
-	expectedSig := a.computeSignature(parts[0] + "." + parts[1])
-	if !hmac.Equal(signatureBytes, expectedSig) {
-		return nil, ErrInvalidCredentials
+	var rawClaims map[string]interface{}
+	if err := json.Unmarshal(claimsBytes, &rawClaims); err != nil {
+		return nil, nil, ErrInvalidCredentials
 	}
 
 	now := time.Now().Unix()
 	if claims.Exp > 0 && claims.Exp < now {
-		return nil, &AuthError{
+		return nil, nil, &AuthError{
 			StatusCode: http.StatusUnauthorized,
 			ErrorType:  "unauthorized",
 			ErrorCode:  "TOKEN_EXPIRED",
 			Message:    "Token has expired",
 		}
 	}
+	if claims.Nbf > now {
+		return nil, nil, &AuthError{
+			StatusCode: http.StatusUnauthorized,
+			ErrorType:  "unauthorized",
+			ErrorCode:  "TOKEN_NOT_YET_VALID",
+			Message:    "Token is not valid yet",
+		}
+	}
 
 	if a.issuer != "" && claims.Iss != a.issuer {
-		return nil, &AuthError{
+		return nil, nil, &AuthError{
 			StatusCode: http.StatusUnauthorized,
 			ErrorType:  "unauthorized",
 			ErrorCode:  "INVALID_ISSUER",
@@ -151,7 +202,159 @@ func (a *JWTAuthenticator) validateToken(token string) (*jwtClaims, error) {
 		}
 	}
 
-	return &claims, nil
+	if a.audience != "" && !claims.Aud.contains(a.audience) {
+		return nil, nil, &AuthError{
+			StatusCode: http.StatusUnauthorized,
+			ErrorType:  "unauthorized",
+			ErrorCode:  "INVALID_AUDIENCE",
+			Message:    "Invalid token audience",
+		}
+	}
+
+	return &claims, rawClaims, nil
+}
+
+func (aud jwtAudience) contains(want string) bool {
+	for _, a := range aud {
+		if a == want {
+			return true
+		}
+	}
+	return false
+}
+
+// verifySignature checks the signature with the key the algorithm calls
+// for: the shared secret for HS256, a JWKS key for the others. Tokens
+// cannot pick a weaker key type than the one they were issued with, since
+// each key only verifies its own algorithm family.
+func (a *JWTAuthenticator) verifySignature(ctx context.Context, header jwtHeader, signed string, signature []byte) error {
+	if header.Alg == "HS256" {
+		if len(a.secret) == 0 {
+			return errUnsupportedAlgorithm(header.Alg)
+		}
+		if !hmac.Equal(signature, a.computeSignature(signed)) {
+			return ErrInvalidCredentials
+		}
+		return nil
+	}
+
+	hashFunc, ok := jwtAsymmetricHashes[header.Alg]
+	if !ok || a.jwks == nil {
+		return errUnsupportedAlgorithm(header.Alg)
+	}
+	key, err := a.jwks.key(ctx, header.Kid)
+	if err != nil {
+		if errors.Is(err, errUnknownKeyID) {
+			return ErrInvalidCredentials
+		}
+		return &AuthError{
+			StatusCode: http.StatusServiceUnavailable,
+			ErrorType:  "unavailable",
+			ErrorCode:  "JWKS_UNAVAILABLE",
+			Message:    "Token signing keys could not be fetched",
+		}
+	}
+
+	h := newHash(hashFunc)
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+
+	switch pub := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(header.Alg, "RS") || rsa.VerifyPKCS1v15(pub, hashFunc, digest, signature) != nil {
+			return ErrInvalidCredentials
+		}
+	case *ecdsa.PublicKey:
+		bits := pub.Curve.Params().BitSize
+		size := (bits + 7) / 8
+		if header.Alg != "ES"+strconv.Itoa(bits) || len(signature) != 2*size {
+			return ErrInvalidCredentials
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return ErrInvalidCredentials
+		}
+	default:
+		return ErrInvalidCredentials
+	}
+	return nil
+}
+
+var jwtAsymmetricHashes = map[string]crypto.Hash{
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+	"ES256": crypto.SHA256,
+	"ES384": crypto.SHA384,
+}
+
+func newHash(h crypto.Hash) hash.Hash {
+	switch h {
+	case crypto.SHA384:
+		return sha512.New384()
+	case crypto.SHA512:
+		return sha512.New()
+	default:
+		return sha256.New()
+	}
+}
+
+func errUnsupportedAlgorithm(alg string) *AuthError {
+	return &AuthError{
+		StatusCode: http.StatusUnauthorized,
+		ErrorType:  "unauthorized",
+		ErrorCode:  "UNSUPPORTED_ALGORITHM",
+		Message:    "Unsupported token algorithm " + alg,
+	}
+}
+
+// mapRoles reads the roles claim, which may be a dotted path into nested
+// objects (realm_access.roles) and a string or an array, and maps its
+// values to roles. Values neither mapped nor named like a role are ignored.
+func (a *JWTAuthenticator) mapRoles(claims map[string]interface{}) []Role {
+	names, _ := claimStrings(claims, a.rolesClaim)
+
+	var roles []Role
+	for _, name := range names {
+		role, ok := a.roleMapping[name]
+		if !ok {
+			role = Role(name)
+		}
+		switch role {
+		case RoleAdmin, RoleOperator, RoleViewer:
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// claimStrings returns the strings of the claim at the dotted path, which
+// holds a space-separated string or an array. ok is false when the claim
+// is absent.
+func claimStrings(claims map[string]interface{}, path string) (values []string, ok bool) {
+	var value interface{} = claims
+	for _, key := range strings.Split(path, ".") {
+		obj, isObj := value.(map[string]interface{})
+		if !isObj {
+			return nil, false
+		}
+		if value, ok = obj[key]; !ok {
+			return nil, false
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		values = strings.Fields(v)
+	case []interface{}:
+		for _, item := range v {
+			if s, isString := item.(string); isString {
+				values = append(values, s)
+			}
+		}
+	}
+	return values, true
 }
 
 func (a *JWTAuthenticator) computeSignature(data string) []byte {
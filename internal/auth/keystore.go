@@ -0,0 +1,307 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+// Scope limits what a managed API key may do, on top of the role it
+// implies.
+type Scope string
+
+const (
+	// ScopeRunsRead allows reading runs, events, reports and workers.
+	ScopeRunsRead Scope = "runs:read"
+	// ScopeRunsWrite allows creating, starting and stopping runs.
+	ScopeRunsWrite Scope = "runs:write"
+	// ScopeWorkersRegister allows registering workers and the calls
+	// workers make afterwards.
+	ScopeWorkersRegister Scope = "workers:register"
+	// ScopeAgentsIngest allows server agents to push telemetry.
+	ScopeAgentsIngest Scope = "agents:ingest"
+	// ScopeAdmin allows everything, including managing API keys.
+	ScopeAdmin Scope = "admin"
+)
+
+// IsValidScope reports whether s is a known scope.
+func IsValidScope(s Scope) bool {
+	switch s {
+	case ScopeRunsRead, ScopeRunsWrite, ScopeWorkersRegister, ScopeAgentsIngest, ScopeAdmin:
+		return true
+	}
+	return false
+}
+
+// managedKeyPrefix starts every managed key, so leaked keys are easy to
+// recognize in logs and by secret scanners.
+const managedKeyPrefix = "mcpd_"
+
+// lastUsedPersistInterval limits how often last-used updates of a key are
+// persisted.
+const lastUsedPersistInterval = time.Minute
+
+var (
+	// ErrKeyNotFound is returned for unknown managed key ids.
+	ErrKeyNotFound = errors.New("api key not found")
+	// ErrInvalidKey is returned when a key is created without a name or
+	// with unknown scopes.
+	ErrInvalidKey = errors.New("invalid api key")
+)
+
+// ManagedKey is an API key created through the API. Only the SHA-256 of
+// the key is kept; the key itself is returned once, when it is created or
+// rotated.
+type ManagedKey struct {
+	ID     string  `json:"id"`
+	Name   string  `json:"name"`
+	Prefix string  `json:"prefix"`
+	Hash   string  `json:"-"`
+	Scopes []Scope `json:"scopes"`
+	// Projects binds the key to these projects; empty allows every project.
+	Projects     []string `json:"projects,omitempty"`
+	CreatedBy    string   `json:"created_by"`
+	CreatedAtMs  int64    `json:"created_at_ms"`
+	RotatedAtMs  int64    `json:"rotated_at_ms,omitempty"`
+	RevokedAtMs  int64    `json:"revoked_at_ms,omitempty"`
+	LastUsedAtMs int64    `json:"last_used_at_ms,omitempty"`
+}
+
+// Revoked reports whether the key has been revoked.
+func (k *ManagedKey) Revoked() bool {
+	return k.RevokedAtMs > 0
+}
+
+// Roles returns the role a key's scopes imply: admin for the admin scope,
+// operator for scopes that change state, viewer otherwise.
+func (k *ManagedKey) Roles() []Role {
+	role := RoleViewer
+	for _, scope := range k.Scopes {
+		switch scope {
+		case ScopeAdmin:
+			return []Role{RoleAdmin}
+		case ScopeRunsWrite, ScopeWorkersRegister:
+			role = RoleOperator
+		}
+	}
+	return []Role{role}
+}
+
+// User returns the user a request authenticated with the key acts as.
+func (k *ManagedKey) User() *User {
+	user := &User{ID: k.ID, Roles: k.Roles(), Scopes: k.Scopes}
+	if len(k.Projects) > 0 {
+		user.Projects = k.Projects
+	}
+	return user
+}
+
+// KeyStore holds the managed API keys. Changes are passed to the persist
+// function, if one is set, so keys survive restarts.
+type KeyStore struct {
+	mu        sync.RWMutex
+	keys      map[string]*ManagedKey // ID -> key
+	byHash    map[string]*ManagedKey
+	persisted map[string]int64 // ID -> last persisted LastUsedAtMs
+	persist   func(ManagedKey) error
+	now       func() time.Time
+}
+
+// NewKeyStore creates an empty key store.
+func NewKeyStore() *KeyStore {
+	return &KeyStore{
+		keys:      make(map[string]*ManagedKey),
+		byHash:    make(map[string]*ManagedKey),
+		persisted: make(map[string]int64),
+		now:       time.Now,
+	}
+}
+
+// SetPersistFunc sets the function that stores a key after every change.
+func (s *KeyStore) SetPersistFunc(persist func(ManagedKey) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.persist = persist
+}
+
+// Load adds previously persisted keys.
+func (s *KeyStore) Load(keys []ManagedKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range keys {
+		key := keys[i]
+		if key.Scopes == nil {
+			key.Scopes = []Scope{} // a nil scope list would mean unrestricted
+		}
+		s.keys[key.ID] = &key
+		s.byHash[key.Hash] = &key
+		s.persisted[key.ID] = key.LastUsedAtMs
+	}
+}
+
+// Create issues a new key, bound to projects unless that is empty, and
+// returns it with its secret value.
+func (s *KeyStore) Create(name string, scopes []Scope, projects []string, actor string) (ManagedKey, string, error) {
+	if strings.TrimSpace(name) == "" {
+		return ManagedKey{}, "", fmt.Errorf("%w: name is required", ErrInvalidKey)
+	}
+	if len(scopes) == 0 {
+		return ManagedKey{}, "", fmt.Errorf("%w: at least one scope is required", ErrInvalidKey)
+	}
+	for _, scope := range scopes {
+		if !IsValidScope(scope) {
+			return ManagedKey{}, "", fmt.Errorf("%w: unknown scope %q", ErrInvalidKey, scope)
+		}
+	}
+	for _, project := range projects {
+		if !types.ValidProjectName(project) {
+			return ManagedKey{}, "", fmt.Errorf("%w: invalid project name %q", ErrInvalidKey, project)
+		}
+	}
+	id, err := randomHex(8)
+	if err != nil {
+		return ManagedKey{}, "", err
+	}
+	secret, err := newManagedSecret()
+	if err != nil {
+		return ManagedKey{}, "", err
+	}
+
+	key := &ManagedKey{
+		ID:          "key_" + id,
+		Name:        name,
+		Prefix:      secret[:len(managedKeyPrefix)+8],
+		Hash:        hashKey(secret),
+		Scopes:      append([]Scope(nil), scopes...),
+		Projects:    append([]string(nil), projects...),
+		CreatedBy:   actor,
+		CreatedAtMs: s.now().UnixMilli(),
+	}
+	// Persist first, so a key the caller never learned of cannot be used.
+	if err := s.save(*key); err != nil {
+		return ManagedKey{}, "", err
+	}
+	s.mu.Lock()
+	s.keys[key.ID] = key
+	s.byHash[key.Hash] = key
+	s.mu.Unlock()
+	return *key, secret, nil
+}
+
+// Rotate replaces the secret of a key. The old secret stops working at
+// once; the key keeps its id, scopes and history.
+func (s *KeyStore) Rotate(id string) (ManagedKey, string, error) {
+	secret, err := newManagedSecret()
+	if err != nil {
+		return ManagedKey{}, "", err
+	}
+	s.mu.Lock()
+	key, ok := s.keys[id]
+	if !ok || key.Revoked() {
+		s.mu.Unlock()
+		return ManagedKey{}, "", ErrKeyNotFound
+	}
+	delete(s.byHash, key.Hash)
+	key.Hash = hashKey(secret)
+	key.Prefix = secret[:len(managedKeyPrefix)+8]
+	key.RotatedAtMs = s.now().UnixMilli()
+	s.byHash[key.Hash] = key
+	snapshot := *key
+	s.mu.Unlock()
+	return snapshot, secret, s.save(snapshot)
+}
+
+// Revoke disables a key. Revoked keys stay listed for auditing.
+func (s *KeyStore) Revoke(id string) (ManagedKey, error) {
+	s.mu.Lock()
+	key, ok := s.keys[id]
+	if !ok {
+		s.mu.Unlock()
+		return ManagedKey{}, ErrKeyNotFound
+	}
+	if !key.Revoked() {
+		key.RevokedAtMs = s.now().UnixMilli()
+	}
+	delete(s.byHash, key.Hash)
+	snapshot := *key
+	s.mu.Unlock()
+	return snapshot, s.save(snapshot)
+}
+
+// List returns all keys, oldest first.
+func (s *KeyStore) List() []ManagedKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := make([]ManagedKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, *key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].CreatedAtMs != keys[j].CreatedAtMs {
+			return keys[i].CreatedAtMs < keys[j].CreatedAtMs
+		}
+		return keys[i].ID < keys[j].ID
+	})
+	return keys
+}
+
+// Lookup returns the active key with the given secret and records its use.
+func (s *KeyStore) Lookup(secret string) (ManagedKey, bool) {
+	if !strings.HasPrefix(secret, managedKeyPrefix) {
+		return ManagedKey{}, false
+	}
+	hash := hashKey(secret)
+	now := s.now().UnixMilli()
+
+	s.mu.Lock()
+	key, ok := s.byHash[hash]
+	if !ok || key.Revoked() {
+		s.mu.Unlock()
+		return ManagedKey{}, false
+	}
+	key.LastUsedAtMs = now
+	snapshot := *key
+	persist := now-s.persisted[key.ID] >= lastUsedPersistInterval.Milliseconds()
+	if persist {
+		s.persisted[key.ID] = now
+	}
+	s.mu.Unlock()
+
+	if persist {
+		_ = s.save(snapshot)
+	}
+	return snapshot, true
+}
+
+func (s *KeyStore) save(key ManagedKey) error {
+	s.mu.RLock()
+	persist := s.persist
+	s.mu.RUnlock()
+	if persist == nil {
+		return nil
+	}
+	return persist(key)
+}
+
+func newManagedSecret() (string, error) {
+	random, err := randomHex(24)
+	if err != nil {
+		return "", err
+	}
+	return managedKeyPrefix + random, nil
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate key: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,361 @@
+// Package checks evaluates response assertions configured on op-mix
+// entries: JSONPath and regex checks on the result, the expected tools/call
+// isError flag, a latency ceiling and result size bounds. Check failures are
+// reported next to, not instead of, the operation's transport outcome.
+package checks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+// Response is what a check sees of one operation.
+type Response struct {
+	// Result is the raw JSON-RPC result, nil if the server sent none.
+	Result json.RawMessage
+
+	// ResultBytes is the size of the result. It is used instead of
+	// len(Result) when the result itself is no longer available.
+	ResultBytes int64
+
+	LatencyMs int64
+}
+
+// Check is a compiled CheckConfig.
+type Check struct {
+	name  string
+	err   error // set when the config is invalid; the check always fails
+	check func(r *Response, doc *document) (bool, string)
+}
+
+// Name returns the configured name, or one derived from the check.
+func (c *Check) Name() string {
+	return c.name
+}
+
+// Validate reports whether a check config is usable.
+func Validate(cfg types.CheckConfig) error {
+	_, err := compile(cfg)
+	return err
+}
+
+// Compile compiles check configs. An invalid config yields a check that
+// always fails with the reason, so a bad check is visible in telemetry
+// rather than silently skipped.
+func Compile(configs []types.CheckConfig) []*Check {
+	if len(configs) == 0 {
+		return nil
+	}
+	compiled := make([]*Check, len(configs))
+	for i, cfg := range configs {
+		c, err := compile(cfg)
+		if err != nil {
+			c = &Check{name: cfg.Name, err: err}
+			if c.name == "" {
+				c.name = fmt.Sprintf("check_%d", i+1)
+			}
+		}
+		compiled[i] = c
+	}
+	return compiled
+}
+
+// Evaluate runs the checks against a response.
+func Evaluate(checks []*Check, r *Response) []types.CheckResult {
+	if len(checks) == 0 {
+		return nil
+	}
+	doc := &document{raw: r.Result}
+	results := make([]types.CheckResult, len(checks))
+	for i, c := range checks {
+		result := types.CheckResult{Name: c.name}
+		if c.err != nil {
+			result.Message = "invalid check: " + c.err.Error()
+		} else {
+			result.Passed, result.Message = c.check(r, doc)
+		}
+		results[i] = result
+	}
+	return results
+}
+
+func compile(cfg types.CheckConfig) (*Check, error) {
+	var kinds []string
+	if cfg.JSONPath != "" {
+		kinds = append(kinds, "jsonpath")
+	}
+	if cfg.Regex != "" {
+		kinds = append(kinds, "regex")
+	}
+	if cfg.IsError != nil {
+		kinds = append(kinds, "is_error")
+	}
+	if cfg.MaxLatencyMs != 0 {
+		kinds = append(kinds, "max_latency_ms")
+	}
+	if cfg.MinResultBytes != nil || cfg.MaxResultBytes != nil {
+		kinds = append(kinds, "result bytes")
+	}
+	switch len(kinds) {
+	case 0:
+		return nil, fmt.Errorf("set one of jsonpath, regex, is_error, max_latency_ms, min_result_bytes or max_result_bytes")
+	case 1:
+	default:
+		return nil, fmt.Errorf("set only one kind of check, got %s", strings.Join(kinds, " and "))
+	}
+	if kinds[0] != "jsonpath" && (len(cfg.Equals) > 0 || cfg.Matches != "") {
+		return nil, fmt.Errorf("equals and matches need jsonpath")
+	}
+
+	c := &Check{name: cfg.Name}
+	switch kinds[0] {
+	case "jsonpath":
+		return compileJSONPath(c, cfg)
+	case "regex":
+		re, err := regexp.Compile(cfg.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("regex: %w", err)
+		}
+		c.defaultName("regex " + cfg.Regex)
+		c.check = func(_ *Response, doc *document) (bool, string) {
+			if doc.raw == nil {
+				return false, "no result"
+			}
+			if !re.MatchString(doc.text()) {
+				return false, "result text does not match"
+			}
+			return true, ""
+		}
+	case "is_error":
+		want := *cfg.IsError
+		c.defaultName(fmt.Sprintf("is_error=%t", want))
+		c.check = func(_ *Response, doc *document) (bool, string) {
+			got, ok := doc.isError()
+			if !ok {
+				return false, "no result"
+			}
+			if got != want {
+				return false, fmt.Sprintf("isError is %t", got)
+			}
+			return true, ""
+		}
+	case "max_latency_ms":
+		if cfg.MaxLatencyMs < 0 {
+			return nil, fmt.Errorf("max_latency_ms must be positive")
+		}
+		limit := cfg.MaxLatencyMs
+		c.defaultName(fmt.Sprintf("max_latency_ms=%d", limit))
+		c.check = func(r *Response, _ *document) (bool, string) {
+			if r.LatencyMs > limit {
+				return false, fmt.Sprintf("latency %d ms", r.LatencyMs)
+			}
+			return true, ""
+		}
+	default:
+		lo, hi := int64(0), int64(-1)
+		if cfg.MinResultBytes != nil {
+			lo = *cfg.MinResultBytes
+		}
+		if cfg.MaxResultBytes != nil {
+			hi = *cfg.MaxResultBytes
+		}
+		if lo < 0 || (cfg.MaxResultBytes != nil && hi < lo) {
+			return nil, fmt.Errorf("result byte bounds must satisfy 0 <= min_result_bytes <= max_result_bytes")
+		}
+		c.defaultName("result_bytes")
+		c.check = func(r *Response, _ *document) (bool, string) {
+			size := r.ResultBytes
+			if r.Result != nil {
+				size = int64(len(r.Result))
+			}
+			if size < lo || (hi >= 0 && size > hi) {
+				return false, fmt.Sprintf("result is %d bytes", size)
+			}
+			return true, ""
+		}
+	}
+	return c, nil
+}
+
+func compileJSONPath(c *Check, cfg types.CheckConfig) (*Check, error) {
+	path, err := parsePath(cfg.JSONPath)
+	if err != nil {
+		return nil, fmt.Errorf("jsonpath: %w", err)
+	}
+	if len(cfg.Equals) > 0 && cfg.Matches != "" {
+		return nil, fmt.Errorf("set equals or matches, not both")
+	}
+	c.defaultName("jsonpath " + cfg.JSONPath)
+
+	var match func(v interface{}) bool
+	switch {
+	case len(cfg.Equals) > 0:
+		var want interface{}
+		if err := json.Unmarshal(cfg.Equals, &want); err != nil {
+			return nil, fmt.Errorf("equals: %w", err)
+		}
+		match = func(v interface{}) bool { return jsonEqual(v, want) }
+	case cfg.Matches != "":
+		re, err := regexp.Compile(cfg.Matches)
+		if err != nil {
+			return nil, fmt.Errorf("matches: %w", err)
+		}
+		match = func(v interface{}) bool { return re.MatchString(scalarText(v)) }
+	}
+
+	c.check = func(_ *Response, doc *document) (bool, string) {
+		root, ok := doc.value()
+		if !ok {
+			return false, "no result"
+		}
+		values := path.selectFrom(root)
+		if len(values) == 0 {
+			return false, "path selected nothing"
+		}
+		if match == nil {
+			return true, ""
+		}
+		for _, v := range values {
+			if match(v) {
+				return true, ""
+			}
+		}
+		return false, "got " + truncate(scalarText(values[0]), 100)
+	}
+	return c, nil
+}
+
+func (c *Check) defaultName(name string) {
+	if c.name == "" {
+		c.name = name
+	}
+}
+
+// document decodes the result lazily, once for all checks of a response.
+type document struct {
+	raw     json.RawMessage
+	decoded bool
+	root    interface{}
+	valid   bool
+}
+
+func (d *document) value() (interface{}, bool) {
+	if !d.decoded {
+		d.decoded = true
+		if d.raw != nil {
+			dec := json.NewDecoder(bytes.NewReader(d.raw))
+			dec.UseNumber()
+			d.valid = dec.Decode(&d.root) == nil
+		}
+	}
+	return d.root, d.valid
+}
+
+func (d *document) isError() (bool, bool) {
+	root, ok := d.value()
+	if !ok {
+		return false, false
+	}
+	obj, _ := root.(map[string]interface{})
+	isError, _ := obj["isError"].(bool)
+	return isError, true
+}
+
+// text returns the text content of the result: the text items of
+// tools/call content, resources/read contents and prompts/get messages.
+// Results without text items are matched as raw JSON.
+func (d *document) text() string {
+	root, ok := d.value()
+	if !ok {
+		return string(d.raw)
+	}
+	obj, _ := root.(map[string]interface{})
+	var texts []string
+	collect := func(item interface{}) {
+		if m, ok := item.(map[string]interface{}); ok {
+			if text, ok := m["text"].(string); ok {
+				texts = append(texts, text)
+			}
+		}
+	}
+	for _, key := range []string{"content", "contents"} {
+		items, _ := obj[key].([]interface{})
+		for _, item := range items {
+			collect(item)
+		}
+	}
+	messages, _ := obj["messages"].([]interface{})
+	for _, m := range messages {
+		if msg, ok := m.(map[string]interface{}); ok {
+			collect(msg["content"])
+		}
+	}
+	if len(texts) == 0 {
+		return string(d.raw)
+	}
+	return strings.Join(texts, "\n")
+}
+
+func jsonEqual(got, want interface{}) bool {
+	switch w := want.(type) {
+	case float64:
+		n, ok := got.(json.Number)
+		if !ok {
+			return false
+		}
+		f, err := n.Float64()
+		return err == nil && f == w
+	case map[string]interface{}, []interface{}:
+		a, _ := json.Marshal(got)
+		b, _ := json.Marshal(normalizeNumbers(want))
+		return bytes.Equal(a, b)
+	default:
+		return got == want
+	}
+}
+
+// normalizeNumbers converts float64 values to json.Number so structures
+// decoded without UseNumber marshal like the response.
+func normalizeNumbers(v interface{}) interface{} {
+	switch val := v.(type) {
+	case float64:
+		n, _ := json.Marshal(val)
+		return json.Number(n)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, child := range val {
+			out[k] = normalizeNumbers(child)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, child := range val {
+			out[i] = normalizeNumbers(child)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// scalarText formats a selected value for regex matching: strings as is,
+// everything else as JSON.
+func scalarText(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,114 @@
+package checks
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+func boolPtr(b bool) *bool    { return &b }
+func int64Ptr(n int64) *int64 { return &n }
+
+const toolResult = `{"content":[{"type":"text","text":"hello alice"},{"type":"text","text":"id=42"}],"isError":false,"meta":{"count":3,"tags":["a","b"],"odd key]":true}}`
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		cfg  types.CheckConfig
+		pass bool
+	}{
+		{types.CheckConfig{JSONPath: "$.content[0].text"}, true},
+		{types.CheckConfig{JSONPath: "$.content[5].text"}, false},
+		{types.CheckConfig{JSONPath: "$.content[-1].text", Equals: json.RawMessage(`"id=42"`)}, true},
+		{types.CheckConfig{JSONPath: "$.meta.count", Equals: json.RawMessage(`3`)}, true},
+		{types.CheckConfig{JSONPath: "$.meta.count", Equals: json.RawMessage(`"3"`)}, false},
+		{types.CheckConfig{JSONPath: "$.meta.tags", Equals: json.RawMessage(`["a","b"]`)}, true},
+		{types.CheckConfig{JSONPath: "$.isError", Equals: json.RawMessage(`false`)}, true},
+		{types.CheckConfig{JSONPath: "$.content[*].text", Matches: `^id=\d+$`}, true},
+		{types.CheckConfig{JSONPath: "$.meta['odd key]']"}, true},
+		{types.CheckConfig{JSONPath: "$.meta.*", Equals: json.RawMessage(`3`)}, true},
+		{types.CheckConfig{Regex: `alice\nid=`}, true},
+		{types.CheckConfig{Regex: `bob`}, false},
+		{types.CheckConfig{IsError: boolPtr(false)}, true},
+		{types.CheckConfig{IsError: boolPtr(true)}, false},
+		{types.CheckConfig{MaxLatencyMs: 100}, true},
+		{types.CheckConfig{MaxLatencyMs: 10}, false},
+		{types.CheckConfig{MinResultBytes: int64Ptr(10), MaxResultBytes: int64Ptr(1000)}, true},
+		{types.CheckConfig{MaxResultBytes: int64Ptr(10)}, false},
+	}
+	r := &Response{Result: json.RawMessage(toolResult), LatencyMs: 50}
+	for _, tt := range tests {
+		results := Evaluate(Compile([]types.CheckConfig{tt.cfg}), r)
+		if len(results) != 1 || results[0].Passed != tt.pass {
+			t.Errorf("%+v: got %+v, want passed=%t", tt.cfg, results, tt.pass)
+		}
+		if results[0].Passed && results[0].Message != "" {
+			t.Errorf("%+v: passed check has message %q", tt.cfg, results[0].Message)
+		}
+	}
+}
+
+func TestEvaluate_NoResult(t *testing.T) {
+	checks := Compile([]types.CheckConfig{
+		{Name: "has_text", JSONPath: "$.content"},
+		{IsError: boolPtr(false)},
+		{MaxResultBytes: int64Ptr(100)},
+	})
+	results := Evaluate(checks, &Response{ResultBytes: 500})
+	if results[0].Name != "has_text" || results[0].Passed || results[0].Message != "no result" {
+		t.Errorf("jsonpath without result = %+v", results[0])
+	}
+	if results[1].Name != "is_error=false" || results[1].Passed {
+		t.Errorf("is_error without result = %+v", results[1])
+	}
+	if results[2].Passed {
+		t.Errorf("expected summarized size to be checked, got %+v", results[2])
+	}
+}
+
+func TestCompile_Invalid(t *testing.T) {
+	invalid := []types.CheckConfig{
+		{},
+		{JSONPath: "content"},
+		{JSONPath: "$.a[x]"},
+		{JSONPath: "$.a['b"},
+		{JSONPath: "$..a"},
+		{JSONPath: "$.a", Equals: json.RawMessage(`1`), Matches: "x"},
+		{Regex: "("},
+		{Regex: "a", IsError: boolPtr(true)},
+		{MaxLatencyMs: -1},
+		{MinResultBytes: int64Ptr(10), MaxResultBytes: int64Ptr(5)},
+		{IsError: boolPtr(true), Matches: "x"},
+	}
+	for _, cfg := range invalid {
+		if err := Validate(cfg); err == nil {
+			t.Errorf("Validate(%+v) succeeded, want error", cfg)
+		}
+	}
+
+	results := Evaluate(Compile([]types.CheckConfig{{Regex: "("}}), &Response{})
+	if results[0].Passed || results[0].Name != "check_1" {
+		t.Errorf("invalid check should always fail, got %+v", results[0])
+	}
+}
+
+func TestPathSelect(t *testing.T) {
+	p, err := ParsePath("$.content[*].text")
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := p.Select(json.RawMessage(toolResult))
+	if len(values) != 2 || values[0] != "hello alice" || values[1] != "id=42" {
+		t.Errorf("unexpected values %v", values)
+	}
+	count, _ := ParsePath("$.meta.count")
+	if values := count.Select(json.RawMessage(toolResult)); len(values) != 1 || values[0] != json.Number("3") {
+		t.Errorf("expected the count as a number, got %v", values)
+	}
+	if values := p.Select(json.RawMessage(`not json`)); values != nil {
+		t.Errorf("expected nothing from a non-JSON result, got %v", values)
+	}
+	if _, err := ParsePath("content"); err == nil {
+		t.Error("expected a path without $ to be rejected")
+	}
+}
@@ -0,0 +1,148 @@
+package checks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// path is a parsed JSONPath subset: $ followed by .name, ['name'], [index]
+// (negative counts from the end) and the wildcards .* and [*].
+type path []segment
+
+type segment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+func parsePath(src string) (path, error) {
+	if !strings.HasPrefix(src, "$") {
+		return nil, fmt.Errorf("must start with $")
+	}
+	var p path
+	for i := 1; i < len(src); {
+		switch src[i] {
+		case '.':
+			i++
+			start := i
+			for i < len(src) && src[i] != '.' && src[i] != '[' {
+				i++
+			}
+			name := src[start:i]
+			switch name {
+			case "":
+				return nil, fmt.Errorf("empty name at offset %d", start)
+			case "*":
+				p = append(p, segment{wildcard: true})
+			default:
+				p = append(p, segment{key: name})
+			}
+		case '[':
+			if i+1 < len(src) && (src[i+1] == '\'' || src[i+1] == '"') {
+				// Quoted names may contain ] and .
+				quote := src[i+1]
+				closing := strings.IndexByte(src[i+2:], quote)
+				if closing < 0 {
+					return nil, fmt.Errorf("unterminated name at offset %d", i)
+				}
+				nameEnd := i + 2 + closing
+				if nameEnd+1 >= len(src) || src[nameEnd+1] != ']' {
+					return nil, fmt.Errorf("expected ] after name at offset %d", nameEnd)
+				}
+				p = append(p, segment{key: src[i+2 : nameEnd]})
+				i = nameEnd + 2
+				continue
+			}
+			end := strings.IndexByte(src[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("missing ] at offset %d", i)
+			}
+			inner := strings.TrimSpace(src[i+1 : i+end])
+			if inner == "*" {
+				p = append(p, segment{wildcard: true})
+			} else {
+				n, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid index %q", inner)
+				}
+				p = append(p, segment{index: n, isIndex: true})
+			}
+			i += end + 1
+		default:
+			return nil, fmt.Errorf("unexpected %q at offset %d", src[i], i)
+		}
+	}
+	return p, nil
+}
+
+// Path is a compiled JSONPath, for callers that take values out of results
+// rather than assert on them.
+type Path struct {
+	src  string
+	path path
+}
+
+// ParsePath compiles a JSONPath in the subset checks accept.
+func ParsePath(src string) (*Path, error) {
+	p, err := parsePath(src)
+	if err != nil {
+		return nil, err
+	}
+	return &Path{src: src, path: p}, nil
+}
+
+// String returns the path as written.
+func (p *Path) String() string {
+	return p.src
+}
+
+// Select returns the values p selects from a JSON result, nil if the result
+// is not JSON. Numbers are json.Number, so they are sent on unchanged.
+func (p *Path) Select(result json.RawMessage) []interface{} {
+	var root interface{}
+	dec := json.NewDecoder(bytes.NewReader(result))
+	dec.UseNumber()
+	if err := dec.Decode(&root); err != nil {
+		return nil
+	}
+	return p.path.selectFrom(root)
+}
+
+// selectFrom returns the values the path selects from root.
+func (p path) selectFrom(root interface{}) []interface{} {
+	current := []interface{}{root}
+	for _, seg := range p {
+		var next []interface{}
+		for _, v := range current {
+			switch node := v.(type) {
+			case map[string]interface{}:
+				if seg.wildcard {
+					for _, child := range node {
+						next = append(next, child)
+					}
+				} else if child, ok := node[seg.key]; ok && !seg.isIndex {
+					next = append(next, child)
+				}
+			case []interface{}:
+				switch {
+				case seg.wildcard:
+					next = append(next, node...)
+				case seg.isIndex:
+					i := seg.index
+					if i < 0 {
+						i += len(node)
+					}
+					if i >= 0 && i < len(node) {
+						next = append(next, node[i])
+					}
+				}
+			}
+		}
+		current = next
+	}
+	return current
+}
@@ -0,0 +1,566 @@
+// Package conformance checks an MCP server's protocol handling rather than
+// its performance: it runs a battery of checks against the server over the
+// streamable HTTP transport, from initialize negotiation to session
+// termination, and reports which pass.
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+	"github.com/bc-dunia/mcpdrill/internal/mcp"
+	"github.com/bc-dunia/mcpdrill/internal/transport"
+)
+
+// Defaults for the Config fields left at zero.
+const (
+	DefaultTimeout     = 10 * time.Second
+	DefaultCancelAfter = 100 * time.Millisecond
+)
+
+// Config configures a conformance run.
+type Config struct {
+	// Endpoint is the URL of the server's MCP endpoint.
+	Endpoint string
+	Headers  map[string]string
+	// ProtocolVersion is the version to request, mcp.DefaultProtocolVersion
+	// if empty.
+	ProtocolVersion string
+	// Tool, if set, is called with ToolArguments by the SSE and
+	// cancellation checks, which are skipped without it. A tool that takes
+	// longer than CancelAfter lets the cancellation check cancel it, after
+	// which the server has CancelGrace (transport.DefaultCancelGrace if
+	// zero) to stop.
+	Tool          string
+	ToolArguments map[string]interface{}
+	CancelAfter   time.Duration
+	CancelGrace   time.Duration
+	// Timeout bounds each request.
+	Timeout time.Duration
+	// AllowPrivateNetworks lists the CIDRs of private networks the server
+	// may be in.
+	AllowPrivateNetworks []string
+}
+
+// result is the verdict of a check.
+type result struct {
+	status string
+	detail string
+}
+
+func pass() result { return result{status: analysis.ConformancePass} }
+
+func fail(format string, args ...interface{}) result {
+	return result{status: analysis.ConformanceFail, detail: fmt.Sprintf(format, args...)}
+}
+
+func skip(format string, args ...interface{}) result {
+	return result{status: analysis.ConformanceSkip, detail: fmt.Sprintf(format, args...)}
+}
+
+// check is one protocol check.
+type check struct {
+	id          string
+	category    string
+	description string
+	run         func(ctx context.Context, r *runner) result
+}
+
+// checks run in order: the first ones open the session the later ones use,
+// and the last one ends it.
+var checks = []check{
+	{"initialize.negotiation", "initialize", "initialize answers with a protocol version the client supports", checkNegotiation},
+	{"initialize.capabilities", "initialize", "initialize answers with serverInfo and a capabilities object", checkCapabilities},
+	{"initialize.unsupported_version", "initialize", "An unsupported requested version gets a supported one or an error, not an echo", checkUnsupportedVersion},
+	{"notifications.initialized", "notifications", "notifications/initialized is accepted with 202 and no body", checkInitialized},
+	{"ping.result", "ping", "ping answers with an object result", checkPing},
+	{"tools.list", "tools", "tools/list lists tools with names and object input schemas", checkToolsList},
+	{"errors.unknown_method", "errors", "An unknown method gets a -32601 error", checkUnknownMethod},
+	{"errors.invalid_params", "errors", "tools/call without a tool name gets a -32602 error", checkInvalidParams},
+	{"errors.invalid_request", "errors", "A message that is not JSON-RPC 2.0 gets a -32600 error or a 400", checkInvalidRequest},
+	{"errors.parse_error", "errors", "A body that is not JSON gets a -32700 error or a 400", checkParseError},
+	{"session.id", "session", "Mcp-Session-Id holds only visible ASCII characters", checkSessionID},
+	{"session.missing_id", "session", "A request without the session's Mcp-Session-Id gets a 400", checkMissingSession},
+	{"session.unknown_id", "session", "A request with an unknown Mcp-Session-Id gets a 404", checkUnknownSession},
+	{"sse.response_format", "sse", "A streamed response is well-formed SSE ending with the response to the request", checkSSEFormat},
+	{"sse.get_stream", "sse", "GET on the endpoint opens an SSE stream or answers 405", checkGetStream},
+	{"cancellation.honored", "cancellation", "A tools/call cancelled with notifications/cancelled gets no response", checkCancellation},
+	{"session.terminate", "session", "DELETE ends the session, or answers 405 if clients may not", checkTerminate},
+}
+
+// runner holds the state checks share.
+type runner struct {
+	cfg    Config
+	client *http.Client
+	conn   transport.Connection
+
+	// Set by the initialize checks.
+	initialized bool
+	initResult  *transport.InitializeResult
+	session     string
+	version     string
+}
+
+// Run runs the checks against cfg.Endpoint. An error means the run could
+// not start; failing checks are reported in the report.
+func Run(ctx context.Context, cfg Config) (*analysis.ConformanceReport, error) {
+	if cfg.Endpoint == "" {
+		return nil, errors.New("endpoint is required")
+	}
+	if cfg.ProtocolVersion == "" {
+		cfg.ProtocolVersion = mcp.DefaultProtocolVersion
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.CancelAfter <= 0 {
+		cfg.CancelAfter = DefaultCancelAfter
+	}
+
+	conn, err := transport.NewStreamableHTTPAdapter().Connect(ctx, &transport.TransportConfig{
+		Endpoint:             cfg.Endpoint,
+		Headers:              cfg.Headers,
+		AllowPrivateNetworks: cfg.AllowPrivateNetworks,
+		Timeouts: transport.TimeoutConfig{
+			ConnectTimeout:     cfg.Timeout,
+			RequestTimeout:     cfg.Timeout,
+			StreamStallTimeout: cfg.Timeout,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("connect to %s: %w", cfg.Endpoint, err)
+	}
+	defer conn.Close()
+
+	r := &runner{cfg: cfg, client: &http.Client{Timeout: cfg.Timeout}, conn: conn}
+	report := &analysis.ConformanceReport{Target: cfg.Endpoint}
+	for _, c := range checks {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		start := time.Now()
+		var res result
+		if c.category != "initialize" && !r.initialized {
+			res = skip("the server could not be initialized")
+		} else {
+			res = c.run(ctx, r)
+		}
+		report.Add(analysis.ConformanceCheck{
+			ID:          c.id,
+			Category:    c.category,
+			Description: c.description,
+			Status:      res.status,
+			Detail:      res.detail,
+			LatencyMs:   time.Since(start).Milliseconds(),
+		})
+	}
+	report.ProtocolVersion = r.version
+	return report, nil
+}
+
+func checkNegotiation(ctx context.Context, r *runner) result {
+	outcome, _ := r.conn.Initialize(ctx, &transport.InitializeParams{
+		ProtocolVersion: r.cfg.ProtocolVersion,
+		Capabilities:    map[string]interface{}{},
+		ClientInfo:      transport.ClientInfo{Name: mcp.ClientName, Version: mcp.ClientVersion},
+	})
+	if !outcome.OK {
+		return fail("initialize failed: %s", outcomeError(outcome))
+	}
+	var init transport.InitializeResult
+	if err := json.Unmarshal(outcome.Result, &init); err != nil {
+		return fail("invalid initialize result: %v", err)
+	}
+	r.initialized = true
+	r.initResult = &init
+	r.session = r.conn.SessionID()
+	r.version = init.ProtocolVersion
+	if !mcp.IsSupported(init.ProtocolVersion) {
+		return fail("negotiated unsupported version %q", init.ProtocolVersion)
+	}
+	return pass()
+}
+
+func checkCapabilities(_ context.Context, r *runner) result {
+	if r.initResult == nil {
+		return skip("initialize failed")
+	}
+	if r.initResult.Capabilities == nil {
+		return fail("capabilities is missing")
+	}
+	if r.initResult.ServerInfo.Name == "" {
+		return fail("serverInfo.name is missing")
+	}
+	return pass()
+}
+
+func checkUnsupportedVersion(ctx context.Context, r *runner) result {
+	const unsupported = "1999-01-01"
+	resp, err := r.post(ctx, map[string]interface{}{
+		"jsonrpc": "2.0", "id": "conformance-version", "method": "initialize",
+		"params": map[string]interface{}{
+			"protocolVersion": unsupported,
+			"capabilities":    map[string]interface{}{},
+			"clientInfo":      transport.ClientInfo{Name: mcp.ClientName, Version: mcp.ClientVersion},
+		},
+	}, "", false)
+	if err != nil {
+		return fail("%v", err)
+	}
+	if resp.message == nil {
+		if resp.status >= 400 && resp.status < 500 {
+			return pass()
+		}
+		return fail("no JSON-RPC response (HTTP %d)", resp.status)
+	}
+	if resp.message.Error != nil {
+		return pass()
+	}
+	var init transport.InitializeResult
+	if err := json.Unmarshal(resp.message.Result, &init); err != nil {
+		return fail("invalid initialize result: %v", err)
+	}
+	if init.ProtocolVersion == unsupported {
+		return fail("the server accepted version %s", unsupported)
+	}
+	return pass()
+}
+
+func checkInitialized(ctx context.Context, r *runner) result {
+	outcome, _ := r.conn.SendInitialized(ctx)
+	if outcome.HTTPStatus == nil {
+		return fail("notification failed: %s", outcomeError(outcome))
+	}
+	if *outcome.HTTPStatus != http.StatusAccepted {
+		return fail("answered HTTP %d", *outcome.HTTPStatus)
+	}
+	return pass()
+}
+
+func checkPing(ctx context.Context, r *runner) result {
+	outcome, _ := r.conn.Ping(ctx)
+	if !outcome.OK {
+		return fail("ping failed: %s", outcomeError(outcome))
+	}
+	var v map[string]interface{}
+	if err := json.Unmarshal(outcome.Result, &v); err != nil || v == nil {
+		return fail("result is not an object: %s", outcome.Result)
+	}
+	return pass()
+}
+
+func checkToolsList(ctx context.Context, r *runner) result {
+	outcome, _ := r.conn.ToolsList(ctx, nil)
+	if !outcome.OK {
+		return fail("tools/list failed: %s", outcomeError(outcome))
+	}
+	var list transport.ToolsListResult
+	if err := json.Unmarshal(outcome.Result, &list); err != nil {
+		return fail("invalid tools/list result: %v", err)
+	}
+	for i, tool := range list.Tools {
+		if tool.Name == "" {
+			return fail("tool %d has no name", i)
+		}
+		var schema struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(tool.InputSchema, &schema); err != nil || schema.Type != "object" {
+			return fail("tool %s: inputSchema is not an object schema", tool.Name)
+		}
+	}
+	return pass()
+}
+
+func checkUnknownMethod(ctx context.Context, r *runner) result {
+	return r.expectError(ctx, map[string]interface{}{
+		"jsonrpc": "2.0", "id": "conformance-unknown", "method": "mcpdrill/unknown_method",
+	}, -32601, false)
+}
+
+func checkInvalidParams(ctx context.Context, r *runner) result {
+	return r.expectError(ctx, map[string]interface{}{
+		"jsonrpc": "2.0", "id": "conformance-params", "method": "tools/call",
+		"params": map[string]interface{}{"arguments": map[string]interface{}{}},
+	}, -32602, false)
+}
+
+func checkInvalidRequest(ctx context.Context, r *runner) result {
+	return r.expectError(ctx, map[string]interface{}{
+		"jsonrpc": "1.0", "id": "conformance-request", "method": "ping",
+	}, -32600, true)
+}
+
+func checkParseError(ctx context.Context, r *runner) result {
+	return r.expectError(ctx, []byte(`{"jsonrpc": "2.0", "id": `), -32700, true)
+}
+
+// expectError sends msg and checks it gets a JSON-RPC error with code, or
+// an HTTP 400 if orBadRequest.
+func (r *runner) expectError(ctx context.Context, msg interface{}, code int, orBadRequest bool) result {
+	resp, err := r.post(ctx, msg, r.session, true)
+	if err != nil {
+		return fail("%v", err)
+	}
+	if orBadRequest && resp.status == http.StatusBadRequest {
+		return pass()
+	}
+	if resp.message == nil {
+		return fail("no JSON-RPC response (HTTP %d)", resp.status)
+	}
+	if resp.message.Error == nil {
+		return fail("answered with a result instead of error %d", code)
+	}
+	if resp.message.Error.Code != code {
+		return fail("got error %d (%s), want %d", resp.message.Error.Code, resp.message.Error.Message, code)
+	}
+	return pass()
+}
+
+func checkSessionID(_ context.Context, r *runner) result {
+	if r.session == "" {
+		return skip("the server does not use sessions")
+	}
+	for _, ch := range r.session {
+		if ch < 0x21 || ch > 0x7e {
+			return fail("session ID %q has characters outside visible ASCII", r.session)
+		}
+	}
+	return pass()
+}
+
+func checkMissingSession(ctx context.Context, r *runner) result {
+	return r.expectSessionStatus(ctx, "", http.StatusBadRequest)
+}
+
+func checkUnknownSession(ctx context.Context, r *runner) result {
+	return r.expectSessionStatus(ctx, "mcpdrill-conformance-unknown-session", http.StatusNotFound)
+}
+
+// expectSessionStatus sends a ping with the given session ID and checks it
+// is answered with status.
+func (r *runner) expectSessionStatus(ctx context.Context, session string, status int) result {
+	if r.session == "" {
+		return skip("the server does not use sessions")
+	}
+	resp, err := r.post(ctx, map[string]interface{}{"jsonrpc": "2.0", "id": "conformance-session", "method": "ping"}, session, true)
+	if err != nil {
+		return fail("%v", err)
+	}
+	if resp.status != status {
+		return fail("answered HTTP %d, want %d", resp.status, status)
+	}
+	return pass()
+}
+
+func checkSSEFormat(ctx context.Context, r *runner) result {
+	if r.cfg.Tool == "" {
+		return skip("no tool to call was given")
+	}
+	const id = "conformance-sse"
+	resp, err := r.post(ctx, map[string]interface{}{
+		"jsonrpc": "2.0", "id": id, "method": "tools/call",
+		"params": transport.ToolsCallParams{Name: r.cfg.Tool, Arguments: r.cfg.ToolArguments},
+	}, r.session, true)
+	if err != nil {
+		return fail("%v", err)
+	}
+	if !strings.HasPrefix(resp.header.Get("Content-Type"), transport.ContentTypeSSE) {
+		return skip("the server answered with %q rather than a stream", resp.header.Get("Content-Type"))
+	}
+	events, err := transport.ParseSSEFromBytes(resp.body)
+	if err != nil {
+		return fail("unreadable stream: %v", err)
+	}
+	responses := 0
+	for i, event := range events {
+		if event.Data == "" {
+			continue
+		}
+		var msg struct {
+			JSONRPC string           `json:"jsonrpc"`
+			ID      interface{}      `json:"id"`
+			Method  string           `json:"method"`
+			Result  json.RawMessage  `json:"result"`
+			Error   *json.RawMessage `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(event.Data), &msg); err != nil {
+			return fail("event %d is not JSON: %v", i+1, err)
+		}
+		if msg.JSONRPC != "2.0" {
+			return fail("event %d is not a JSON-RPC 2.0 message", i+1)
+		}
+		if msg.Method != "" {
+			continue
+		}
+		if msg.ID != id {
+			return fail("event %d answers request %v, not %s", i+1, msg.ID, id)
+		}
+		responses++
+		if i != len(events)-1 {
+			return fail("the stream went on after the response")
+		}
+	}
+	if responses != 1 {
+		return fail("the stream had %d responses, want 1", responses)
+	}
+	return pass()
+}
+
+func checkGetStream(ctx context.Context, r *runner) result {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.cfg.Endpoint, nil)
+	if err != nil {
+		return fail("%v", err)
+	}
+	r.setHeaders(req, r.session)
+	req.Header.Set(transport.HeaderAccept, transport.ContentTypeSSE)
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fail("%v", err)
+	}
+	// The stream stays open; the headers are all there is to check.
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusMethodNotAllowed:
+		return pass()
+	case resp.StatusCode != http.StatusOK:
+		return fail("answered HTTP %d, want 200 or 405", resp.StatusCode)
+	case !strings.HasPrefix(resp.Header.Get("Content-Type"), transport.ContentTypeSSE):
+		return fail("answered 200 with %q rather than a stream", resp.Header.Get("Content-Type"))
+	}
+	return pass()
+}
+
+func checkCancellation(ctx context.Context, r *runner) result {
+	if r.cfg.Tool == "" {
+		return skip("no tool to call was given")
+	}
+	outcome, _ := r.conn.ToolsCall(transport.WithCancellation(ctx, r.cfg.CancelAfter, r.cfg.CancelGrace), &transport.ToolsCallParams{
+		Name:      r.cfg.Tool,
+		Arguments: r.cfg.ToolArguments,
+	})
+	c := outcome.Cancellation
+	switch {
+	case c == nil || !c.Sent:
+		if !outcome.OK {
+			return fail("the call failed before it could be cancelled: %s", outcomeError(outcome))
+		}
+		return skip("%s answered within %s, before it could be cancelled", r.cfg.Tool, r.cfg.CancelAfter)
+	case !c.Honored:
+		return fail("the server answered the call after it was cancelled")
+	}
+	return pass()
+}
+
+func checkTerminate(ctx context.Context, r *runner) result {
+	if r.session == "" {
+		return skip("the server does not use sessions")
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, r.cfg.Endpoint, nil)
+	if err != nil {
+		return fail("%v", err)
+	}
+	r.setHeaders(req, r.session)
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fail("%v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusMethodNotAllowed {
+		return pass()
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fail("DELETE answered HTTP %d", resp.StatusCode)
+	}
+	return r.expectSessionStatus(ctx, r.session, http.StatusNotFound)
+}
+
+// response is the answer to a request sent outside the transport.
+type response struct {
+	status int
+	header http.Header
+	body   []byte
+	// message is the JSON-RPC response, from a JSON body or the stream.
+	message *transport.JSONRPCResponse
+}
+
+// post sends msg, or the bytes given as msg as they are, so checks can
+// send what the transport never would. session is sent as Mcp-Session-Id if set, and the negotiated
+// protocol version if negotiated.
+func (r *runner) post(ctx context.Context, msg interface{}, session string, negotiated bool) (*response, error) {
+	body, ok := msg.([]byte)
+	if !ok {
+		var err error
+		if body, err = json.Marshal(msg); err != nil {
+			return nil, err
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.cfg.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	r.setHeaders(req, session)
+	req.Header.Set(transport.HeaderContentType, transport.ContentTypeJSON)
+	req.Header.Set(transport.HeaderAccept, transport.AcceptBoth)
+	if negotiated && r.version != "" {
+		req.Header.Set("MCP-Protocol-Version", r.version)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	out := &response{status: resp.StatusCode, header: resp.Header, body: data}
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), transport.ContentTypeSSE) {
+		events, _ := transport.ParseSSEFromBytes(data)
+		for _, event := range events {
+			if msg, err := transport.ParseSSEData(event.Data); err == nil && msg.ID != nil && (msg.Result != nil || msg.Error != nil) {
+				out.message = msg
+			}
+		}
+	} else {
+		var msg transport.JSONRPCResponse
+		if json.Unmarshal(data, &msg) == nil && (msg.Result != nil || msg.Error != nil) {
+			out.message = &msg
+		}
+	}
+	return out, nil
+}
+
+func (r *runner) setHeaders(req *http.Request, session string) {
+	for name, value := range r.cfg.Headers {
+		req.Header.Set(name, value)
+	}
+	if session != "" {
+		req.Header.Set(transport.HeaderMCPSessionID, session)
+	}
+}
+
+func outcomeError(outcome *transport.OperationOutcome) string {
+	if outcome.Error != nil {
+		return outcome.Error.Message
+	}
+	if outcome.HTTPStatus != nil {
+		return fmt.Sprintf("HTTP %d", *outcome.HTTPStatus)
+	}
+	return "unknown error"
+}
@@ -0,0 +1,73 @@
+package conformance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+	"github.com/bc-dunia/mcpdrill/internal/mockserver"
+)
+
+func TestRun_MockServer(t *testing.T) {
+	for _, strict := range []bool{false, true} {
+		cfg := mockserver.DefaultConfig()
+		cfg.Strict = strict
+		srv := mockserver.New(cfg)
+		if err := srv.Start(); err != nil {
+			t.Fatal(err)
+		}
+		defer srv.Stop(context.Background())
+
+		report, err := Run(context.Background(), Config{
+			Endpoint:             srv.MCPURL(),
+			Tool:                 "streaming_tool",
+			ToolArguments:        map[string]interface{}{"chunks": 10, "delay_ms": 100},
+			CancelAfter:          100 * time.Millisecond,
+			CancelGrace:          200 * time.Millisecond,
+			AllowPrivateNetworks: []string{"127.0.0.0/8"},
+		})
+		if err != nil {
+			t.Fatalf("strict=%v: Run failed: %v", strict, err)
+		}
+		statuses := make(map[string]string)
+		for _, c := range report.Checks {
+			statuses[c.ID] = c.Status
+			if c.Status == analysis.ConformanceFail {
+				t.Errorf("strict=%v: %s failed: %s", strict, c.ID, c.Detail)
+			}
+		}
+		if len(report.Checks) != len(checks) || report.ProtocolVersion == "" {
+			t.Errorf("strict=%v: unexpected report %+v", strict, report)
+		}
+		for _, id := range []string{"sse.response_format", "cancellation.honored"} {
+			if statuses[id] != analysis.ConformancePass {
+				t.Errorf("strict=%v: expected %s to pass, got %s", strict, id, statuses[id])
+			}
+		}
+		wantSession := analysis.ConformanceSkip
+		if strict {
+			wantSession = analysis.ConformancePass
+		}
+		if statuses["session.terminate"] != wantSession {
+			t.Errorf("strict=%v: expected session.terminate %s, got %s", strict, wantSession, statuses["session.terminate"])
+		}
+	}
+}
+
+func TestRun_Unreachable(t *testing.T) {
+	report, err := Run(context.Background(), Config{
+		Endpoint:             "http://127.0.0.1:1/mcp",
+		Timeout:              time.Second,
+		AllowPrivateNetworks: []string{"127.0.0.0/8"},
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if report.OK() || report.Checks[0].Status != analysis.ConformanceFail {
+		t.Errorf("expected initialize to fail, got %+v", report.Checks[0])
+	}
+	if report.Skipped != len(checks)-2 {
+		t.Errorf("expected the checks after initialize skipped, got %d skipped", report.Skipped)
+	}
+}
@@ -1,15 +1,22 @@
 package api
 
 import (
+	"compress/gzip"
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/agent"
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+	"github.com/bc-dunia/mcpdrill/internal/auth"
+	"github.com/bc-dunia/mcpdrill/internal/types"
 )
 
 // AgentAuthConfig holds configuration for agent authentication
@@ -26,6 +33,14 @@ type AgentRegisterRequest struct {
 	Arch     string            `json:"arch"`
 	Version  string            `json:"version"`
 	Tags     map[string]string `json:"tags,omitempty"`
+	// Project is the project the agent's server belongs to, the default
+	// project when empty. Agents pushing with a key bound to a single
+	// project default to it.
+	Project string `json:"project,omitempty"`
+
+	// PairKeys lists further pair keys the agent serves, so that one agent
+	// can report to several concurrent runs. PairKey may then be empty.
+	PairKeys []string `json:"pair_keys,omitempty"`
 }
 
 // AgentRegisterResponse is the response body for POST /agents/v1/register
@@ -39,11 +54,36 @@ type AgentMetricsRequest struct {
 	AgentID string               `json:"agent_id"`
 	PairKey string               `json:"pair_key"`
 	Samples []AgentMetricsSample `json:"samples"`
+
+	// SentAt is when the agent sent the batch, on the clock its samples
+	// are stamped with. Agents that keep their clock in sync also report
+	// their offset and drift estimates.
+	SentAt        int64   `json:"sent_at,omitempty"`
+	ClockOffsetMs int64   `json:"clock_offset_ms,omitempty"`
+	ClockDriftPPM float64 `json:"clock_drift_ppm,omitempty"`
 }
 
 // AgentMetricsResponse is the response body for POST /agents/v1/metrics
 type AgentMetricsResponse struct {
-	Accepted int `json:"accepted"`
+	Accepted   int   `json:"accepted"`
+	ServerTime int64 `json:"server_time"` // Unix milliseconds
+}
+
+// AgentLogsRequest is the request body for POST /agents/v1/logs
+type AgentLogsRequest struct {
+	AgentID string           `json:"agent_id"`
+	PairKey string           `json:"pair_key"`
+	Entries []agent.LogEntry `json:"entries"`
+	// Dropped is how many entries the agent's rate limit discarded since
+	// its previous batch.
+	Dropped int   `json:"dropped,omitempty"`
+	SentAt  int64 `json:"sent_at,omitempty"`
+}
+
+// AgentLogsResponse is the response body for POST /agents/v1/logs
+type AgentLogsResponse struct {
+	Accepted   int   `json:"accepted"`
+	ServerTime int64 `json:"server_time"` // Unix milliseconds
 }
 
 // ListAgentsResponse is the response body for GET /agents
@@ -72,6 +112,12 @@ type ServerMetricsResponse struct {
 	RunID      string               `json:"run_id"`
 	Samples    []AgentMetricsSample `json:"samples"`
 	Aggregated *AggregatedMetrics   `json:"aggregated,omitempty"`
+
+	// Nodes and Rollup are only set with by_node=true. Nodes keeps each
+	// agent's samples separate; Rollup summarizes them per node and across
+	// the cluster.
+	Nodes  []AgentSeries                 `json:"nodes,omitempty"`
+	Rollup *analysis.ServerMetricsReport `json:"rollup,omitempty"`
 }
 
 func generateAgentID() (string, error) {
@@ -98,6 +144,9 @@ func (s *Server) handleAgentRegister(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.PairKey == "" && len(req.PairKeys) > 0 {
+		req.PairKey = req.PairKeys[0]
+	}
 	if req.PairKey == "" {
 		s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(
 			"pair_key is required",
@@ -114,6 +163,28 @@ func (s *Server) handleAgentRegister(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	project := req.Project
+	keyUser := auth.GetUserFromContext(r.Context())
+	if project == "" {
+		project = types.DefaultProject
+		if keyUser != nil && len(keyUser.Projects) == 1 {
+			project = keyUser.Projects[0]
+		}
+	}
+	if !types.ValidProjectName(project) {
+		s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(
+			"project must be 1-63 lowercase letters, digits or '-'",
+			map[string]interface{}{"field": "project"},
+		))
+		return
+	}
+	if keyUser != nil && !keyUser.CanAccessProject(project) {
+		s.writeError(w, http.StatusForbidden, NewErrorResponse(ErrorTypeForbidden, "PROJECT_FORBIDDEN",
+			fmt.Sprintf("Not allowed to register agents in project %q", project), false,
+			map[string]interface{}{"project": project}))
+		return
+	}
+
 	if s.agentStore == nil {
 		s.writeError(w, http.StatusInternalServerError, NewInternalErrorResponse("agent store not configured"))
 		return
@@ -129,6 +200,10 @@ func (s *Server) handleAgentRegister(w http.ResponseWriter, r *http.Request) {
 		s.writeError(w, http.StatusInternalServerError, NewInternalErrorResponse(err.Error()))
 		return
 	}
+	s.agentStore.SetAgentProject(agentID, project)
+	if len(req.PairKeys) > 0 {
+		s.agentStore.SetPairKeys(agentID, append([]string{req.PairKey}, req.PairKeys...))
+	}
 
 	s.writeJSON(w, http.StatusCreated, &AgentRegisterResponse{
 		AgentID:    agentID,
@@ -142,9 +217,27 @@ func (s *Server) handleAgentMetrics(w http.ResponseWriter, r *http.Request) {
 		s.writeMethodNotAllowed(w, r.Method, "POST")
 		return
 	}
+	receivedAt := time.Now().UnixMilli()
+
+	body, err := agentMetricsBody(w, r)
+	if err != nil {
+		s.writeError(w, http.StatusUnsupportedMediaType, NewInvalidRequestErrorResponse(
+			err.Error(),
+			map[string]interface{}{"content_encoding": r.Header.Get("Content-Encoding")},
+		))
+		return
+	}
+	defer body.Close()
 
 	var req AgentMetricsRequest
-	if err := json.NewDecoder(limitedBody(w, r)).Decode(&req); err != nil {
+	if err := json.NewDecoder(body).Decode(&req); err != nil {
+		if errors.Is(err, errAgentBodyTooLarge) {
+			s.writeError(w, http.StatusRequestEntityTooLarge, NewInvalidRequestErrorResponse(
+				"Decompressed request body too large",
+				map[string]interface{}{"max_decompressed_bytes": maxAgentMetricsDecompressedSize},
+			))
+			return
+		}
 		s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(
 			"Invalid JSON request body",
 			map[string]interface{}{"parse_error": err.Error()},
@@ -177,8 +270,13 @@ func (s *Server) handleAgentMetrics(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := s.agentStore.IngestMetrics(req.AgentID, req.Samples)
-	if err != nil {
+	var skewMs int64
+	if req.SentAt > 0 {
+		skewMs = receivedAt - req.SentAt
+		correctClockSkew(req.Samples, skewMs)
+	}
+
+	if err := s.agentStore.IngestMetrics(req.AgentID, req.Samples); err != nil {
 		if errors.Is(err, ErrTooManySamples) {
 			s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(
 				"Too many samples in request",
@@ -193,11 +291,150 @@ func (s *Server) handleAgentMetrics(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	s.agentStore.SetAgentClock(req.AgentID, req.ClockOffsetMs, req.ClockDriftPPM, skewMs)
+
 	s.writeJSON(w, http.StatusOK, &AgentMetricsResponse{
-		Accepted: len(req.Samples),
+		Accepted:   len(req.Samples),
+		ServerTime: time.Now().UnixMilli(),
+	})
+}
+
+// handleAgentLogs handles POST /agents/v1/logs
+func (s *Server) handleAgentLogs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.writeMethodNotAllowed(w, r.Method, "POST")
+		return
+	}
+	receivedAt := time.Now().UnixMilli()
+
+	body, err := agentMetricsBody(w, r)
+	if err != nil {
+		s.writeError(w, http.StatusUnsupportedMediaType, NewInvalidRequestErrorResponse(
+			err.Error(),
+			map[string]interface{}{"content_encoding": r.Header.Get("Content-Encoding")},
+		))
+		return
+	}
+	defer body.Close()
+
+	var req AgentLogsRequest
+	if err := json.NewDecoder(body).Decode(&req); err != nil {
+		if errors.Is(err, errAgentBodyTooLarge) {
+			s.writeError(w, http.StatusRequestEntityTooLarge, NewInvalidRequestErrorResponse(
+				"Decompressed request body too large",
+				map[string]interface{}{"max_decompressed_bytes": maxAgentMetricsDecompressedSize},
+			))
+			return
+		}
+		s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(
+			"Invalid JSON request body",
+			map[string]interface{}{"parse_error": err.Error()},
+		))
+		return
+	}
+
+	if req.AgentID == "" {
+		s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(
+			"agent_id is required",
+			map[string]interface{}{"field": "agent_id"},
+		))
+		return
+	}
+
+	if s.agentStore == nil {
+		s.writeError(w, http.StatusInternalServerError, NewInternalErrorResponse("agent store not configured"))
+		return
+	}
+
+	if _, ok := s.agentStore.GetAgent(req.AgentID); !ok {
+		s.writeError(w, http.StatusNotFound, &ErrorResponse{
+			ErrorType:    ErrorTypeNotFound,
+			ErrorCode:    "AGENT_NOT_FOUND",
+			ErrorMessage: "Agent not found",
+			Retryable:    false,
+			Details:      map[string]interface{}{"agent_id": req.AgentID},
+		})
+		return
+	}
+
+	if req.SentAt > 0 {
+		correctLogClockSkew(req.Entries, receivedAt-req.SentAt)
+	}
+
+	if err := s.agentStore.IngestLogs(req.AgentID, req.Entries, req.Dropped); err != nil {
+		if errors.Is(err, ErrTooManyLogEntries) {
+			s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(
+				"Too many log entries in request",
+				map[string]interface{}{
+					"max_entries_per_request": MaxLogEntriesPerRequest,
+					"entry_count":             len(req.Entries),
+				},
+			))
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, NewInternalErrorResponse(err.Error()))
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, &AgentLogsResponse{
+		Accepted:   len(req.Entries),
+		ServerTime: time.Now().UnixMilli(),
 	})
 }
 
+// maxAgentMetricsDecompressedSize caps gzip metric batches after
+// decompression; the compressed body is still limited by limitedBody.
+const maxAgentMetricsDecompressedSize = maxRequestBodySize
+
+var errAgentBodyTooLarge = errors.New("decompressed request body too large")
+
+// agentMetricsBody returns the metrics request body, decompressing it when
+// the agent sent Content-Encoding: gzip.
+func agentMetricsBody(w http.ResponseWriter, r *http.Request) (io.ReadCloser, error) {
+	body := limitedBody(w, r)
+	switch strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))) {
+	case "", "identity":
+		return io.NopCloser(body), nil
+	case "gzip":
+		zr, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip body: %w", err)
+		}
+		return &cappedReader{r: zr, closer: zr, remaining: maxAgentMetricsDecompressedSize}, nil
+	default:
+		return nil, fmt.Errorf("unsupported Content-Encoding %q", r.Header.Get("Content-Encoding"))
+	}
+}
+
+// cappedReader fails with errAgentBodyTooLarge once more than remaining bytes
+// have been read.
+type cappedReader struct {
+	r         io.Reader
+	closer    io.Closer
+	remaining int64
+}
+
+func (c *cappedReader) Read(p []byte) (int, error) {
+	if c.remaining <= 0 {
+		// Distinguish a body that ends exactly at the cap from one that exceeds it.
+		var probe [1]byte
+		if n, _ := c.r.Read(probe[:]); n > 0 {
+			return 0, errAgentBodyTooLarge
+		}
+		return 0, io.EOF
+	}
+	if int64(len(p)) > c.remaining {
+		p = p[:c.remaining]
+	}
+	n, err := c.r.Read(p)
+	c.remaining -= int64(n)
+	return n, err
+}
+
+func (c *cappedReader) Close() error {
+	return c.closer.Close()
+}
+
 // handleListAgents handles GET /agents
 func (s *Server) handleListAgents(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -220,11 +457,15 @@ func (s *Server) handleListAgents(w http.ResponseWriter, r *http.Request) {
 		agents = s.agentStore.ListAgents()
 	}
 
-	if agents == nil {
-		agents = []*AgentInfo{}
+	project := requestProject(r)
+	visible := []*AgentInfo{}
+	for _, agent := range agents {
+		if agent.Project == project {
+			visible = append(visible, agent)
+		}
 	}
 
-	s.writeJSON(w, http.StatusOK, &ListAgentsResponse{Agents: agents})
+	s.writeJSON(w, http.StatusOK, &ListAgentsResponse{Agents: visible})
 }
 
 // handleGetAgent handles GET /agents/{id}
@@ -246,6 +487,9 @@ func (s *Server) handleGetAgent(w http.ResponseWriter, r *http.Request, agentID
 	}
 
 	agent, ok := s.agentStore.GetAgent(agentID)
+	if ok && s.authEnabled() && !auth.GetUserFromContext(r.Context()).CanAccessProject(agent.Project) {
+		ok = false
+	}
 	if !ok {
 		s.writeError(w, http.StatusNotFound, &ErrorResponse{
 			ErrorType:    ErrorTypeNotFound,
@@ -291,6 +535,21 @@ func (s *Server) handleGetServerMetrics(w http.ResponseWriter, r *http.Request,
 	pairKey := query.Get("pair_key")
 	agentID := query.Get("agent_id")
 	aggregate := query.Get("aggregate") // max, avg, sum
+	target := query.Get("target")       // label of one process of agents monitoring several
+	byNode := query.Get("by_node") == "true"
+
+	bucketMs := int64(analysis.DefaultServerMetricsBucketMs)
+	if v := query.Get("bucket_ms"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || parsed <= 0 {
+			s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(
+				"Invalid 'bucket_ms' parameter: must be a positive integer",
+				map[string]interface{}{"field": "bucket_ms", "value": v},
+			))
+			return
+		}
+		bucketMs = parsed
+	}
 
 	var from, to int64
 	fromExplicit := query.Get("from") != ""
@@ -356,17 +615,34 @@ func (s *Server) handleGetServerMetrics(w http.ResponseWriter, r *http.Request,
 		}
 	}
 
+	// Fall back to the run's configured server_telemetry.pair_key
+	if agentID == "" && pairKey == "" {
+		pairKey = s.runManager.GetRunServerTelemetryPairKey(runID)
+	}
+
 	// Get samples
 	var samples []AgentMetricsSample
+	var nodes []AgentSeries
 	if agentID != "" {
 		samples = s.agentStore.GetMetrics(agentID, from, to)
+		scopeSamples(samples, "", target)
+		if byNode && len(samples) > 0 {
+			series := AgentSeries{AgentID: agentID, Samples: samples}
+			if info, ok := s.agentStore.GetAgent(agentID); ok {
+				series.Hostname = info.Hostname
+			}
+			nodes = []AgentSeries{series}
+		}
 	} else if pairKey != "" {
 		samples = s.agentStore.GetMetricsByPairKey(pairKey, from, to)
-	} else {
-		// Look up the run's configured server_telemetry.pair_key
-		runPairKey := s.runManager.GetRunServerTelemetryPairKey(runID)
-		if runPairKey != "" {
-			samples = s.agentStore.GetMetricsByPairKey(runPairKey, from, to)
+		if byNode {
+			nodes = s.agentStore.GetSeriesByPairKey(pairKey, from, to)
+		}
+		if target != "" {
+			scopeSamples(samples, pairKey, target)
+			for _, node := range nodes {
+				scopeSamples(node.Samples, pairKey, target)
+			}
 		}
 	}
 
@@ -385,6 +661,11 @@ func (s *Server) handleGetServerMetrics(w http.ResponseWriter, r *http.Request,
 		response.Aggregated = agg
 	}
 
+	if byNode {
+		response.Nodes = nodes
+		response.Rollup = analysis.ComputeServerMetrics(toNodeSeries(nodes), bucketMs)
+	}
+
 	s.writeJSON(w, http.StatusOK, response)
 }
 
@@ -514,6 +795,14 @@ func (s *Server) agentAuthMiddleware(next http.Handler) http.Handler {
 				break
 			}
 		}
+		if !valid && s.authConfig != nil && s.authConfig.KeyStore != nil {
+			if key, ok := s.authConfig.KeyStore.Lookup(token); ok {
+				user := key.User()
+				valid = user.HasScope(auth.ScopeAgentsIngest)
+				// Registration checks the key's projects.
+				r = r.WithContext(auth.SetUserInContext(r.Context(), user))
+			}
+		}
 
 		if !valid {
 			s.writeError(w, http.StatusUnauthorized, &ErrorResponse{
@@ -0,0 +1,366 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/agent"
+)
+
+func newAgentTestServer(t *testing.T) (*Server, string) {
+	t.Helper()
+	store := NewAgentStore()
+	if err := store.Register("agent_1", "pair", "host", "linux", "amd64", "1.0.0", nil); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	s := NewServer("127.0.0.1:0", nil)
+	s.SetAgentStore(store)
+	return s, "agent_1"
+}
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		t.Fatalf("gzip write failed: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("gzip close failed: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func postAgentMetrics(s *Server, body []byte, encoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/agents/v1/metrics", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+	w := httptest.NewRecorder()
+	s.handleAgentMetrics(w, req)
+	return w
+}
+
+func TestHandleAgentMetrics_Gzip(t *testing.T) {
+	s, agentID := newAgentTestServer(t)
+
+	body, _ := json.Marshal(AgentMetricsRequest{
+		AgentID: agentID,
+		PairKey: "pair",
+		Samples: []AgentMetricsSample{{Timestamp: 1}, {Timestamp: 2}, {Timestamp: 3}},
+	})
+	w := postAgentMetrics(s, gzipBytes(t, body), "gzip")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp AgentMetricsResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Accepted != 3 {
+		t.Errorf("expected 3 accepted samples, got %d", resp.Accepted)
+	}
+	if got := len(s.GetAgentStore().GetMetrics(agentID, 0, 10)); got != 3 {
+		t.Errorf("expected 3 stored samples, got %d", got)
+	}
+}
+
+func TestHandleAgentMetrics_ClockSkew(t *testing.T) {
+	s, agentID := newAgentTestServer(t)
+
+	// An agent whose clock is a minute behind, and one within tolerance.
+	sentAt := time.Now().Add(-time.Minute).UnixMilli()
+	body, _ := json.Marshal(AgentMetricsRequest{
+		AgentID: agentID,
+		PairKey: "pair",
+		Samples: []AgentMetricsSample{{Timestamp: sentAt - 1000}, {Timestamp: sentAt}},
+		SentAt:  sentAt,
+	})
+	w := postAgentMetrics(s, body, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp AgentMetricsResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.ServerTime < sentAt+time.Minute.Milliseconds() {
+		t.Errorf("expected the server time in the response, got %d", resp.ServerTime)
+	}
+
+	samples := s.GetAgentStore().GetMetrics(agentID, 0, 0)
+	if len(samples) != 2 {
+		t.Fatalf("expected 2 samples, got %d", len(samples))
+	}
+	skew := samples[1].ClockSkewMs
+	if skew < time.Minute.Milliseconds() || skew > time.Minute.Milliseconds()+5000 {
+		t.Fatalf("expected a skew of about a minute, got %dms", skew)
+	}
+	if samples[0].ClockSkewMs != skew || samples[0].Timestamp != sentAt-1000+skew || samples[1].Timestamp != sentAt+skew {
+		t.Errorf("expected both samples shifted by %dms, got %+v", skew, samples)
+	}
+	if info, _ := s.GetAgentStore().GetAgent(agentID); info.ClockSkewMs != skew {
+		t.Errorf("expected the agent's skew to be recorded, got %dms", info.ClockSkewMs)
+	}
+
+	now := time.Now().UnixMilli()
+	body, _ = json.Marshal(AgentMetricsRequest{
+		AgentID: agentID,
+		PairKey: "pair",
+		Samples: []AgentMetricsSample{{Timestamp: now}},
+		SentAt:  now,
+	})
+	if w := postAgentMetrics(s, body, ""); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	samples = s.GetAgentStore().GetMetrics(agentID, 0, 0)
+	if latest := samples[len(samples)-1]; latest.Timestamp != now || latest.ClockSkewMs != 0 {
+		t.Errorf("expected an in-sync sample to be stored as sent, got %+v", latest)
+	}
+}
+
+func TestHandleAgentMetrics_Seq(t *testing.T) {
+	s, agentID := newAgentTestServer(t)
+
+	post := func(seqs ...uint64) {
+		t.Helper()
+		var samples []AgentMetricsSample
+		for _, seq := range seqs {
+			samples = append(samples, AgentMetricsSample{Timestamp: int64(seq) * 1000, Seq: seq})
+		}
+		body, _ := json.Marshal(AgentMetricsRequest{AgentID: agentID, PairKey: "pair", Samples: samples})
+		if w := postAgentMetrics(s, body, ""); w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+	post(1, 2, 3)
+	// A resend after a lost response, then a batch after samples 6-8 were dropped.
+	post(2, 3, 4, 5)
+	post(9, 10)
+
+	samples := s.GetAgentStore().GetMetrics(agentID, 0, 0)
+	if len(samples) != 7 {
+		t.Fatalf("expected resent samples stored once, got %d samples", len(samples))
+	}
+	info, _ := s.GetAgentStore().GetAgent(agentID)
+	if info.LastSeq != 10 || info.MissingSamples != 3 {
+		t.Errorf("expected last seq 10 with 3 missing, got %d with %d", info.LastSeq, info.MissingSamples)
+	}
+}
+
+func TestHandleAgentMetrics_Encodings(t *testing.T) {
+	s, agentID := newAgentTestServer(t)
+	body, _ := json.Marshal(AgentMetricsRequest{AgentID: agentID, Samples: []AgentMetricsSample{{Timestamp: 1}}})
+
+	if w := postAgentMetrics(s, body, "br"); w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("expected 415 for unsupported encoding, got %d", w.Code)
+	}
+	if w := postAgentMetrics(s, body, "gzip"); w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("expected 415 for invalid gzip body, got %d", w.Code)
+	}
+	if w := postAgentMetrics(s, body, "identity"); w.Code != http.StatusOK {
+		t.Errorf("expected 200 for identity encoding, got %d", w.Code)
+	}
+}
+
+func TestHandleAgentMetrics_GzipDecompressedLimit(t *testing.T) {
+	s, agentID := newAgentTestServer(t)
+
+	// Highly compressible padding keeps the compressed body small.
+	padding := strings.Repeat("x", maxAgentMetricsDecompressedSize)
+	body := []byte(`{"agent_id":"` + agentID + `","pair_key":"` + padding + `","samples":[]}`)
+	w := postAgentMetrics(s, gzipBytes(t, body), "gzip")
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestAgentStore_GetSeriesByPairKey(t *testing.T) {
+	store := NewAgentStore()
+	store.Register("agent_b", "cluster", "node-b", "linux", "amd64", "1.0.0", nil)
+	store.Register("agent_a", "cluster", "node-a", "linux", "amd64", "1.0.0", nil)
+	store.Register("agent_c", "cluster", "node-c", "linux", "amd64", "1.0.0", nil)
+	store.Register("agent_x", "other", "node-x", "linux", "amd64", "1.0.0", nil)
+
+	store.IngestMetrics("agent_b", []AgentMetricsSample{
+		{Timestamp: 20, Host: &agent.HostMetrics{CPUPercent: 50, CPUCores: 4}},
+		{Timestamp: 10, Host: &agent.HostMetrics{CPUPercent: 25, CPUCores: 4}},
+	})
+	store.IngestMetrics("agent_a", []AgentMetricsSample{{
+		Timestamp: 15,
+		Host: &agent.HostMetrics{CPUPercent: 10, GPUs: []agent.GPUMetrics{
+			{UtilizationPercent: 20, MemUsed: 1, MemTotal: 4, TemperatureC: 50},
+			{UtilizationPercent: 60, MemUsed: 2, MemTotal: 4, TemperatureC: 70},
+		}},
+		Process: &agent.ProcessMetrics{GPUMemUsed: 3},
+	}})
+	store.IngestMetrics("agent_c", []AgentMetricsSample{{Timestamp: 500}})
+	store.IngestMetrics("agent_x", []AgentMetricsSample{{Timestamp: 15}})
+
+	series := store.GetSeriesByPairKey("cluster", 0, 100)
+	if len(series) != 2 {
+		t.Fatalf("expected 2 agents with samples in range, got %+v", series)
+	}
+	if series[0].Hostname != "node-a" || series[1].Hostname != "node-b" {
+		t.Errorf("expected series ordered by hostname, got %s, %s", series[0].Hostname, series[1].Hostname)
+	}
+	if b := series[1].Samples; len(b) != 2 || b[0].Timestamp != 10 {
+		t.Errorf("expected node-b samples sorted by time, got %+v", b)
+	}
+
+	nodes := NewAgentMetricsSource(store).NodeSeries("cluster", 0, 100)
+	if len(nodes) != 2 || len(nodes[1].Samples) != 2 || nodes[1].Samples[0].CPUCores != 4 {
+		t.Errorf("unexpected node series: %+v", nodes)
+	}
+	if g := nodes[0].Samples[0].GPU; g == nil || g.GPUs != 2 || g.UtilPercent != 40 || g.MemUsed != 3 || g.MemTotal != 8 || g.TempMaxC != 70 || g.ProcessMem != 3 {
+		t.Errorf("expected node-a's GPUs rolled up, got %+v", g)
+	}
+}
+
+func TestHandleAgentLogs(t *testing.T) {
+	s, agentID := newAgentTestServer(t)
+
+	// An agent a minute behind ships an error with its stack trace.
+	sentAt := time.Now().Add(-time.Minute).UnixMilli()
+	body, _ := json.Marshal(AgentLogsRequest{
+		AgentID: agentID,
+		PairKey: "pair",
+		Entries: []agent.LogEntry{{Timestamp: sentAt, File: "/var/log/server.log", Text: "ERROR boom\n\tat main.go:10"}},
+		Dropped: 7,
+		SentAt:  sentAt,
+	})
+	req := httptest.NewRequest(http.MethodPost, "/agents/v1/logs", bytes.NewReader(gzipBytes(t, body)))
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	s.handleAgentLogs(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	logs := NewAgentMetricsSource(s.GetAgentStore()).ServerLogs("pair", 0, 0)
+	if len(logs) != 1 {
+		t.Fatalf("expected 1 log entry, got %+v", logs)
+	}
+	if logs[0].Hostname != "host" || logs[0].Text != "ERROR boom\n\tat main.go:10" {
+		t.Errorf("unexpected log entry %+v", logs[0])
+	}
+	if shift := logs[0].TimestampMs - sentAt; shift < time.Minute.Milliseconds() || shift > time.Minute.Milliseconds()+5000 {
+		t.Errorf("expected the entry shifted by about a minute, got %dms", shift)
+	}
+	if info, _ := s.GetAgentStore().GetAgent(agentID); info.LogEntriesDropped != 7 {
+		t.Errorf("expected 7 dropped entries recorded, got %d", info.LogEntriesDropped)
+	}
+
+	body, _ = json.Marshal(AgentLogsRequest{AgentID: "agent_missing", PairKey: "pair"})
+	w = httptest.NewRecorder()
+	s.handleAgentLogs(w, httptest.NewRequest(http.MethodPost, "/agents/v1/logs", bytes.NewReader(body)))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown agent, got %d", w.Code)
+	}
+}
+
+func TestHandleAgentRegister_PairKeys(t *testing.T) {
+	s := NewServer("127.0.0.1:0", nil)
+	store := NewAgentStore()
+	s.SetAgentStore(store)
+
+	body, _ := json.Marshal(AgentRegisterRequest{
+		PairKeys: []string{"run-a", "run-b", "run-a"},
+		Hostname: "fleet-host",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/agents/v1/register", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	s.handleAgentRegister(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp AgentRegisterResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"run-a", "run-b"} {
+		if agents := store.GetAgentsByPairKey(key); len(agents) != 1 || agents[0].AgentID != resp.AgentID {
+			t.Errorf("expected the agent under pair key %s, got %+v", key, agents)
+		}
+	}
+	info, _ := store.GetAgent(resp.AgentID)
+	if info.PairKey != "run-a" || len(info.PairKeys) != 2 {
+		t.Errorf("expected pair keys [run-a run-b], got %q %v", info.PairKey, info.PairKeys)
+	}
+
+	// Re-registering with one pair key drops the others.
+	if err := store.Register(resp.AgentID, "run-b", "fleet-host", "linux", "amd64", "1.0.0", nil); err != nil {
+		t.Fatal(err)
+	}
+	if agents := store.GetAgentsByPairKey("run-a"); len(agents) != 0 {
+		t.Errorf("expected the agent removed from run-a, got %d agents", len(agents))
+	}
+
+	store.SetPairKeys(resp.AgentID, []string{"run-b", "run-c"})
+	if removed := store.CleanupStale(-time.Second); len(removed) != 1 {
+		t.Fatalf("expected the agent cleaned up, got %v", removed)
+	}
+	if len(store.GetAgentsByPairKey("run-b"))+len(store.GetAgentsByPairKey("run-c")) != 0 {
+		t.Error("expected the agent removed from every pair key")
+	}
+}
+
+func TestAgentStore_ScopesTargetsByPairKey(t *testing.T) {
+	store := NewAgentStore()
+	if err := store.Register("agent_1", "run-a", "fleet-host", "linux", "amd64", "1.0.0", nil); err != nil {
+		t.Fatal(err)
+	}
+	store.SetPairKeys("agent_1", []string{"run-a", "run-b"})
+	sample := AgentMetricsSample{
+		Timestamp: 1000,
+		Host:      &agent.HostMetrics{CPUPercent: 50},
+		Targets: []agent.TargetMetrics{
+			{Label: "search", PairKey: "run-a", Process: &agent.ProcessMetrics{PID: 20, MemRSS: 100, CPUPercent: 10},
+				Container: &agent.ContainerMetrics{CgroupVersion: 2, MemUsage: 150}},
+			{Label: "files", PairKey: "run-b", Process: &agent.ProcessMetrics{PID: 30, MemRSS: 200}},
+			{Label: "sidecar", Process: &agent.ProcessMetrics{PID: 10, MemRSS: 50, CPUPercent: 5}},
+		},
+	}
+	if err := store.IngestMetrics("agent_1", []AgentMetricsSample{sample}); err != nil {
+		t.Fatal(err)
+	}
+
+	series := store.GetSeriesByPairKey("run-a", 0, 0)
+	if len(series) != 1 || len(series[0].Samples) != 1 {
+		t.Fatalf("expected one sample for run-a, got %+v", series)
+	}
+	got := series[0].Samples[0]
+	if len(got.Targets) != 2 || got.Targets[0].Label != "search" || got.Targets[1].Label != "sidecar" {
+		t.Errorf("expected the search and unbound sidecar targets, got %+v", got.Targets)
+	}
+	if got.Process == nil || got.Process.MemRSS != 150 || got.Process.CPUPercent != 15 || got.Process.PID != 10 {
+		t.Errorf("expected the targets' processes summed, got %+v", got.Process)
+	}
+	if got.Container == nil || got.Container.MemUsage != 150 {
+		t.Errorf("expected the only target container kept, got %+v", got.Container)
+	}
+
+	samples := store.GetMetricsByPairKey("run-b", 0, 0)
+	if len(samples) != 1 || samples[0].Process.MemRSS != 250 || samples[0].Container != nil {
+		t.Errorf("expected files and sidecar summed for run-b, got %+v", samples)
+	}
+
+	one := scopeSample(samples[0], "run-b", "files")
+	if len(one.Targets) != 1 || one.Process.MemRSS != 200 || one.Process.PIDs != nil {
+		t.Errorf("expected only the files target, got %+v", one)
+	}
+
+	// The stored sample is left untouched.
+	if raw := store.GetMetrics("agent_1", 0, 0); raw[0].Process != nil || raw[0].Targets[2].Process.MemRSS != 50 {
+		t.Errorf("expected the stored sample unchanged, got %+v", raw[0])
+	}
+}
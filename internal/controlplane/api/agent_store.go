@@ -4,23 +4,32 @@ import (
 	"context"
 	"errors"
 	"log"
+	"math"
 	"sort"
 	"sync"
 	"time"
 
 	"github.com/bc-dunia/mcpdrill/internal/agent"
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
 )
 
 const (
 	defaultMaxSamplesPerAgent = 1000
 	MaxSamplesPerRequest      = 10000
+
+	defaultMaxLogEntriesPerAgent = 10000
+	MaxLogEntriesPerRequest      = 1000
 )
 
-var ErrTooManySamples = errors.New("too many samples in request")
+var (
+	ErrTooManySamples    = errors.New("too many samples in request")
+	ErrTooManyLogEntries = errors.New("too many log entries in request")
+)
 
 // AgentInfo holds connected agent information
 type AgentInfo struct {
 	AgentID      string            `json:"agent_id"`
+	Project      string            `json:"project"`
 	PairKey      string            `json:"pair_key"`
 	Tags         map[string]string `json:"tags,omitempty"`
 	Hostname     string            `json:"hostname"`
@@ -30,6 +39,39 @@ type AgentInfo struct {
 	RegisteredAt time.Time         `json:"registered_at"`
 	LastSeen     time.Time         `json:"last_seen"`
 	Online       bool              `json:"online"`
+
+	// ClockOffsetMs and ClockDriftPPM are the agent's latest estimate of
+	// how far the control plane's clock is ahead of its own, and how fast
+	// that changes. ClockSkewMs is how far the agent's corrected clock was
+	// off when its latest batch arrived, network latency included.
+	ClockOffsetMs int64   `json:"clock_offset_ms,omitempty"`
+	ClockDriftPPM float64 `json:"clock_drift_ppm,omitempty"`
+	ClockSkewMs   int64   `json:"clock_skew_ms,omitempty"`
+
+	// LogEntriesDropped counts the log entries the agent's rate limit kept
+	// it from sending.
+	LogEntriesDropped int64 `json:"log_entries_dropped,omitempty"`
+
+	// PairKeys lists every pair key the agent serves, PairKey first, when
+	// it serves more than one.
+	PairKeys []string `json:"pair_keys,omitempty"`
+
+	// LastSeq is the highest sample sequence number received from the
+	// agent. MissingSamples counts the sequence numbers skipped below it:
+	// samples the agent dropped while it could not reach the control plane.
+	LastSeq        uint64 `json:"last_seq,omitempty"`
+	MissingSamples int64  `json:"missing_samples,omitempty"`
+}
+
+// pairKeys returns every pair key the agent serves.
+func (a *AgentInfo) pairKeys() []string {
+	if len(a.PairKeys) > 0 {
+		return a.PairKeys
+	}
+	if a.PairKey != "" {
+		return []string{a.PairKey}
+	}
+	return nil
 }
 
 // AgentMetricsSample stores a single metrics sample
@@ -37,6 +79,101 @@ type AgentMetricsSample struct {
 	Timestamp int64                 `json:"timestamp"`
 	Host      *agent.HostMetrics    `json:"host,omitempty"`
 	Process   *agent.ProcessMetrics `json:"process,omitempty"`
+
+	// Container holds the cgroup metrics of the monitored process's
+	// container, when the agent could read them.
+	Container *agent.ContainerMetrics `json:"container,omitempty"`
+
+	// ClockSkewMs is set when the agent's clock was off by more than
+	// MaxAgentClockSkewMs; Timestamp was then shifted by it onto the
+	// control plane's clock.
+	ClockSkewMs int64 `json:"clock_skew_ms,omitempty"`
+
+	// Targets holds the metrics of each process an agent monitoring
+	// several reported. Queries scope them with scopeSample, which fills
+	// Process and Container from the targets kept.
+	Targets []agent.TargetMetrics `json:"targets,omitempty"`
+
+	// Seq numbers the agent's samples from 1; 0 from agents that predate
+	// sequence numbers.
+	Seq uint64 `json:"seq,omitempty"`
+
+	// Scraped holds the series the agent read from the server's own
+	// metrics endpoint.
+	Scraped []agent.ScrapedMetric `json:"scraped,omitempty"`
+}
+
+// scopeSample narrows a sample's targets to those serving pairKey, which
+// includes targets bound to no pair key, and, when label is set, to the
+// target with that label; an empty pairKey keeps all. Process then sums the
+// remaining targets' processes, and Container is set when exactly one of
+// them has one. Samples without targets are returned unchanged.
+func scopeSample(sample AgentMetricsSample, pairKey, label string) AgentMetricsSample {
+	if len(sample.Targets) == 0 {
+		return sample
+	}
+	var targets []agent.TargetMetrics
+	for _, t := range sample.Targets {
+		if (pairKey == "" || t.PairKey == "" || t.PairKey == pairKey) && (label == "" || t.Label == label) {
+			targets = append(targets, t)
+		}
+	}
+	sample.Targets = targets
+	sample.Process, sample.Container = nil, nil
+	containers := 0
+	for _, t := range targets {
+		if t.Process != nil {
+			if sample.Process == nil {
+				p := *t.Process
+				sample.Process = &p
+			} else {
+				sample.Process.Add(t.Process)
+			}
+		}
+		if t.Container != nil {
+			sample.Container = t.Container
+			containers++
+		}
+	}
+	if containers > 1 {
+		sample.Container = nil
+	}
+	return sample
+}
+
+// scopeSamples applies scopeSample to each of samples in place.
+func scopeSamples(samples []AgentMetricsSample, pairKey, label string) {
+	for i := range samples {
+		samples[i] = scopeSample(samples[i], pairKey, label)
+	}
+}
+
+// MaxAgentClockSkewMs is how far an agent's clock may be off the control
+// plane's before its samples are shifted and flagged. Agents correct their
+// own clocks, so skew beyond it means the correction failed or the agent
+// predates it.
+const MaxAgentClockSkewMs = 1000
+
+// correctClockSkew shifts samples by skewMs, the control plane's clock minus
+// the agent's, and flags them, if the skew is over MaxAgentClockSkewMs.
+func correctClockSkew(samples []AgentMetricsSample, skewMs int64) {
+	if skewMs >= -MaxAgentClockSkewMs && skewMs <= MaxAgentClockSkewMs {
+		return
+	}
+	for i := range samples {
+		samples[i].Timestamp += skewMs
+		samples[i].ClockSkewMs = skewMs
+	}
+}
+
+// correctLogClockSkew shifts log entries like correctClockSkew does samples.
+func correctLogClockSkew(entries []agent.LogEntry, skewMs int64) {
+	if skewMs >= -MaxAgentClockSkewMs && skewMs <= MaxAgentClockSkewMs {
+		return
+	}
+	for i := range entries {
+		entries[i].Timestamp += skewMs
+	}
 }
 
 // AgentStore manages agent state and metrics
@@ -48,6 +185,9 @@ type AgentStore struct {
 	maxSamplesPerAgent   int
 	maxSamplesPerRequest int
 	agentTimeout         time.Duration
+
+	logs                  map[string][]agent.LogEntry // agent_id -> server log entries (ring buffer)
+	maxLogEntriesPerAgent int
 }
 
 // NewAgentStore creates a new AgentStore with default settings
@@ -59,6 +199,9 @@ func NewAgentStore() *AgentStore {
 		maxSamplesPerAgent:   defaultMaxSamplesPerAgent,
 		maxSamplesPerRequest: MaxSamplesPerRequest,
 		agentTimeout:         5 * time.Minute,
+
+		logs:                  make(map[string][]agent.LogEntry),
+		maxLogEntriesPerAgent: defaultMaxLogEntriesPerAgent,
 	}
 }
 
@@ -73,6 +216,9 @@ func NewAgentStoreWithConfig(maxSamples int, timeout time.Duration) *AgentStore
 		maxSamplesPerAgent:   maxSamples,
 		maxSamplesPerRequest: MaxSamplesPerRequest,
 		agentTimeout:         timeout,
+
+		logs:                  make(map[string][]agent.LogEntry),
+		maxLogEntriesPerAgent: defaultMaxLogEntriesPerAgent,
 	}
 }
 
@@ -101,11 +247,12 @@ func (s *AgentStore) Register(agentID, pairKey, hostname, os, arch, version stri
 
 	// Check if agent already exists
 	if existing, ok := s.agents[agentID]; ok {
-		// Save old pair key before updating
-		oldPairKey := existing.PairKey
+		// Save old pair keys before updating
+		oldPairKeys := existing.pairKeys()
 
 		// Update existing agent
 		existing.PairKey = pairKey
+		existing.PairKeys = nil
 		existing.Hostname = hostname
 		existing.OS = os
 		existing.Arch = arch
@@ -115,7 +262,7 @@ func (s *AgentStore) Register(agentID, pairKey, hostname, os, arch, version stri
 		existing.Online = true
 
 		// Update pair key index if changed
-		s.updatePairKeyIndex(agentID, oldPairKey, pairKey)
+		s.updatePairKeyIndex(agentID, oldPairKeys, existing.pairKeys())
 		return nil
 	}
 
@@ -143,32 +290,63 @@ func (s *AgentStore) Register(agentID, pairKey, hostname, os, arch, version stri
 	return nil
 }
 
-// updatePairKeyIndex updates the pair key index when an agent's pair key changes
-func (s *AgentStore) updatePairKeyIndex(agentID, oldPairKey, newPairKey string) {
-	if oldPairKey == newPairKey {
+// SetPairKeys sets every pair key a registered agent serves, so one agent
+// can report to several concurrent runs. The first becomes its PairKey.
+func (s *AgentStore) SetPairKeys(agentID string, pairKeys []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info, ok := s.agents[agentID]
+	if !ok {
 		return
 	}
-
-	// Remove from old pair key
-	if oldPairKey != "" {
-		agents := s.agentsByPairKey[oldPairKey]
-		for i, id := range agents {
-			if id == agentID {
-				s.agentsByPairKey[oldPairKey] = append(agents[:i], agents[i+1:]...)
-				break
-			}
-		}
-		if len(s.agentsByPairKey[oldPairKey]) == 0 {
-			delete(s.agentsByPairKey, oldPairKey)
+	var keys []string
+	seen := make(map[string]bool, len(pairKeys))
+	for _, key := range pairKeys {
+		if key != "" && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
 		}
 	}
 
-	// Add to new pair key
-	if newPairKey != "" {
+	oldPairKeys := info.pairKeys()
+	info.PairKey, info.PairKeys = "", nil
+	if len(keys) > 0 {
+		info.PairKey = keys[0]
+	}
+	if len(keys) > 1 {
+		info.PairKeys = keys
+	}
+	s.updatePairKeyIndex(agentID, oldPairKeys, info.pairKeys())
+}
+
+// updatePairKeyIndex updates the pair key index when an agent's pair keys change
+func (s *AgentStore) updatePairKeyIndex(agentID string, oldPairKeys, newPairKeys []string) {
+	// Remove from old pair keys
+	for _, oldPairKey := range oldPairKeys {
+		s.removeFromPairKey(agentID, oldPairKey)
+	}
+
+	// Add to new pair keys
+	for _, newPairKey := range newPairKeys {
 		s.agentsByPairKey[newPairKey] = append(s.agentsByPairKey[newPairKey], agentID)
 	}
 }
 
+// removeFromPairKey removes an agent from one pair key's index entry.
+func (s *AgentStore) removeFromPairKey(agentID, pairKey string) {
+	agents := s.agentsByPairKey[pairKey]
+	for i, id := range agents {
+		if id == agentID {
+			s.agentsByPairKey[pairKey] = append(agents[:i], agents[i+1:]...)
+			break
+		}
+	}
+	if len(s.agentsByPairKey[pairKey]) == 0 {
+		delete(s.agentsByPairKey, pairKey)
+	}
+}
+
 // UpdateLastSeen updates the last seen time for an agent
 func (s *AgentStore) UpdateLastSeen(agentID string) {
 	s.mu.Lock()
@@ -195,9 +373,11 @@ func (s *AgentStore) IngestMetrics(agentID string, samples []AgentMetricsSample)
 	}
 
 	// Update last seen
-	if info, ok := s.agents[agentID]; ok {
+	info, ok := s.agents[agentID]
+	if ok {
 		info.LastSeen = time.Now()
 		info.Online = true
+		samples = info.trackSeq(samples)
 	}
 
 	// Get or create metrics slice
@@ -215,6 +395,64 @@ func (s *AgentStore) IngestMetrics(agentID string, samples []AgentMetricsSample)
 	return nil
 }
 
+// trackSeq returns samples without those already received, which an agent
+// resends when a response is lost, and counts the sequence numbers skipped
+// as missing.
+func (a *AgentInfo) trackSeq(samples []AgentMetricsSample) []AgentMetricsSample {
+	fresh := samples[:0:0]
+	for _, sample := range samples {
+		if sample.Seq != 0 {
+			if sample.Seq <= a.LastSeq {
+				continue
+			}
+			a.MissingSamples += int64(sample.Seq - a.LastSeq - 1)
+			a.LastSeq = sample.Seq
+		}
+		fresh = append(fresh, sample)
+	}
+	return fresh
+}
+
+// IngestLogs stores server log entries shipped by an agent, along with the
+// number of entries its rate limit dropped since its previous batch. Only
+// the latest entries are kept once an agent exceeds its budget.
+func (s *AgentStore) IngestLogs(agentID string, entries []agent.LogEntry, dropped int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(entries) > MaxLogEntriesPerRequest {
+		log.Printf("[AgentStore] Rejecting log batch: agent=%s entries=%d limit=%d", agentID, len(entries), MaxLogEntriesPerRequest)
+		return ErrTooManyLogEntries
+	}
+
+	if info, ok := s.agents[agentID]; ok {
+		info.LastSeen = time.Now()
+		info.Online = true
+		if dropped > 0 {
+			info.LogEntriesDropped += int64(dropped)
+		}
+	}
+
+	existing := append(s.logs[agentID], entries...)
+	if len(existing) > s.maxLogEntriesPerAgent {
+		existing = existing[len(existing)-s.maxLogEntriesPerAgent:]
+	}
+	s.logs[agentID] = existing
+	return nil
+}
+
+// SetAgentClock records an agent's clock estimates and the skew measured on
+// its latest batch.
+func (s *AgentStore) SetAgentClock(agentID string, offsetMs int64, driftPPM float64, skewMs int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if info, ok := s.agents[agentID]; ok {
+		info.ClockOffsetMs = offsetMs
+		info.ClockDriftPPM = driftPPM
+		info.ClockSkewMs = skewMs
+	}
+}
+
 // GetAgent returns agent info by ID
 func (s *AgentStore) GetAgent(agentID string) (*AgentInfo, bool) {
 	s.mu.RLock()
@@ -236,6 +474,15 @@ func (s *AgentStore) GetAgent(agentID string) (*AgentInfo, bool) {
 	return &copy, true
 }
 
+// SetAgentProject moves an agent to project.
+func (s *AgentStore) SetAgentProject(agentID, project string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if info, ok := s.agents[agentID]; ok {
+		info.Project = project
+	}
+}
+
 // ListAgents returns all registered agents
 func (s *AgentStore) ListAgents() []*AgentInfo {
 	s.mu.RLock()
@@ -305,7 +552,8 @@ func (s *AgentStore) GetMetrics(agentID string, from, to int64) []AgentMetricsSa
 	return result
 }
 
-// GetMetricsByPairKey returns metrics for all agents matching a pair key
+// GetMetricsByPairKey returns metrics for all agents matching a pair key,
+// scoped to the targets serving it
 func (s *AgentStore) GetMetricsByPairKey(pairKey string, from, to int64) []AgentMetricsSample {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -324,7 +572,7 @@ func (s *AgentStore) GetMetricsByPairKey(pairKey string, from, to int64) []Agent
 
 		for _, sample := range samples {
 			if (from == 0 || sample.Timestamp >= from) && (to == 0 || sample.Timestamp <= to) {
-				result = append(result, sample)
+				result = append(result, scopeSample(sample, pairKey, ""))
 			}
 		}
 	}
@@ -335,6 +583,182 @@ func (s *AgentStore) GetMetricsByPairKey(pairKey string, from, to int64) []Agent
 	return result
 }
 
+// AgentSeries is the metrics of one agent within a pair key.
+type AgentSeries struct {
+	AgentID  string               `json:"agent_id"`
+	Hostname string               `json:"hostname"`
+	Samples  []AgentMetricsSample `json:"samples"`
+}
+
+// GetSeriesByPairKey returns the metrics of each agent matching a pair key,
+// kept separate per agent and ordered by hostname, scoped to the targets
+// serving the pair key. Agents without samples in the time range are
+// omitted.
+func (s *AgentStore) GetSeriesByPairKey(pairKey string, from, to int64) []AgentSeries {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []AgentSeries
+	for _, agentID := range s.agentsByPairKey[pairKey] {
+		var samples []AgentMetricsSample
+		for _, sample := range s.metrics[agentID] {
+			if (from == 0 || sample.Timestamp >= from) && (to == 0 || sample.Timestamp <= to) {
+				samples = append(samples, scopeSample(sample, pairKey, ""))
+			}
+		}
+		if len(samples) == 0 {
+			continue
+		}
+		sort.Slice(samples, func(i, j int) bool {
+			return samples[i].Timestamp < samples[j].Timestamp
+		})
+
+		series := AgentSeries{AgentID: agentID, Samples: samples}
+		if info, ok := s.agents[agentID]; ok {
+			series.Hostname = info.Hostname
+		}
+		result = append(result, series)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Hostname != result[j].Hostname {
+			return result[i].Hostname < result[j].Hostname
+		}
+		return result[i].AgentID < result[j].AgentID
+	})
+	return result
+}
+
+// AgentLogs is the server log of one agent within a pair key.
+type AgentLogs struct {
+	AgentID  string           `json:"agent_id"`
+	Hostname string           `json:"hostname"`
+	Entries  []agent.LogEntry `json:"entries"`
+}
+
+// GetLogsByPairKey returns the log entries of each agent matching a pair
+// key within a time range, in time order per agent. Agents without entries
+// in the range are omitted.
+func (s *AgentStore) GetLogsByPairKey(pairKey string, from, to int64) []AgentLogs {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []AgentLogs
+	for _, agentID := range s.agentsByPairKey[pairKey] {
+		var entries []agent.LogEntry
+		for _, entry := range s.logs[agentID] {
+			if (from == 0 || entry.Timestamp >= from) && (to == 0 || entry.Timestamp <= to) {
+				entries = append(entries, entry)
+			}
+		}
+		if len(entries) == 0 {
+			continue
+		}
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].Timestamp < entries[j].Timestamp
+		})
+
+		logs := AgentLogs{AgentID: agentID, Entries: entries}
+		if info, ok := s.agents[agentID]; ok {
+			logs.Hostname = info.Hostname
+		}
+		result = append(result, logs)
+	}
+	return result
+}
+
+// AgentMetricsSource serves an AgentStore's metrics to run analysis.
+type AgentMetricsSource struct {
+	store *AgentStore
+}
+
+// NewAgentMetricsSource creates a runmanager.ServerMetricsSource backed by store.
+func NewAgentMetricsSource(store *AgentStore) *AgentMetricsSource {
+	return &AgentMetricsSource{store: store}
+}
+
+// NodeSeries returns the per-agent metrics of a pair key for analysis.
+func (a *AgentMetricsSource) NodeSeries(pairKey string, fromMs, toMs int64) []analysis.NodeSeries {
+	return toNodeSeries(a.store.GetSeriesByPairKey(pairKey, fromMs, toMs))
+}
+
+// ServerLogs returns the server log entries of a pair key's agents for
+// analysis, in time order.
+func (a *AgentMetricsSource) ServerLogs(pairKey string, fromMs, toMs int64) []analysis.ServerLogEntry {
+	var entries []analysis.ServerLogEntry
+	for _, logs := range a.store.GetLogsByPairKey(pairKey, fromMs, toMs) {
+		for _, entry := range logs.Entries {
+			entries = append(entries, analysis.ServerLogEntry{
+				TimestampMs: entry.Timestamp,
+				AgentID:     logs.AgentID,
+				Hostname:    logs.Hostname,
+				File:        entry.File,
+				Text:        entry.Text,
+			})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].TimestampMs < entries[j].TimestampMs
+	})
+	return entries
+}
+
+// toNodeSeries reduces agent series to the host values used for rollups,
+// plus the monitored process's RSS when the agent reports one.
+func toNodeSeries(series []AgentSeries) []analysis.NodeSeries {
+	nodes := make([]analysis.NodeSeries, 0, len(series))
+	for _, s := range series {
+		node := analysis.NodeSeries{AgentID: s.AgentID, Hostname: s.Hostname}
+		for _, sample := range s.Samples {
+			if sample.Host == nil {
+				continue
+			}
+			ns := analysis.NodeSample{
+				TimestampMs: sample.Timestamp,
+				CPUPercent:  sample.Host.CPUPercent,
+				CPUCores:    sample.Host.CPUCores,
+				MemUsed:     sample.Host.MemUsed,
+				MemTotal:    sample.Host.MemTotal,
+				ClockSkewMs: sample.ClockSkewMs,
+				Seq:         sample.Seq,
+			}
+			if sample.Process != nil {
+				ns.ProcessRSS = sample.Process.MemRSS
+				ns.ProcessCPU = sample.Process.CPUPercent
+				ns.ProcessFDs = sample.Process.NumFDs
+				ns.ProcessConns = sample.Process.OpenConnections
+			}
+			if gpus := sample.Host.GPUs; len(gpus) > 0 {
+				ns.GPU = &analysis.GPUSample{GPUs: len(gpus)}
+				for _, g := range gpus {
+					ns.GPU.UtilPercent += g.UtilizationPercent / float64(len(gpus))
+					ns.GPU.MemUsed += g.MemUsed
+					ns.GPU.MemTotal += g.MemTotal
+					ns.GPU.TempMaxC = math.Max(ns.GPU.TempMaxC, g.TemperatureC)
+				}
+				if sample.Process != nil {
+					ns.GPU.ProcessMem = sample.Process.GPUMemUsed
+				}
+			}
+			for _, m := range sample.Scraped {
+				ns.Scraped = append(ns.Scraped, analysis.ScrapedValue{Name: m.Name, Labels: m.Labels, Type: m.Type, Value: m.Value})
+			}
+			if c := sample.Container; c != nil {
+				ns.Container = &analysis.ContainerSample{
+					CPULimitCores:    c.CPULimitCores,
+					CPUUsageCores:    c.CPUUsageCores,
+					ThrottledPercent: c.CPUThrottledPercent,
+					MemUsage:         c.MemUsage,
+					MemLimit:         c.MemLimit,
+					OOMKills:         c.OOMKills,
+				}
+			}
+			node.Samples = append(node.Samples, ns)
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
 // MarkOffline marks an agent as offline
 func (s *AgentStore) MarkOffline(agentID string) {
 	s.mu.Lock()
@@ -363,17 +787,8 @@ func (s *AgentStore) CleanupStale(timeout time.Duration) []string {
 			removed = append(removed, agentID)
 
 			// Remove from pair key index
-			if info.PairKey != "" {
-				agents := s.agentsByPairKey[info.PairKey]
-				for i, id := range agents {
-					if id == agentID {
-						s.agentsByPairKey[info.PairKey] = append(agents[:i], agents[i+1:]...)
-						break
-					}
-				}
-				if len(s.agentsByPairKey[info.PairKey]) == 0 {
-					delete(s.agentsByPairKey, info.PairKey)
-				}
+			for _, pairKey := range info.pairKeys() {
+				s.removeFromPairKey(agentID, pairKey)
 			}
 
 			// Remove agent
@@ -381,6 +796,7 @@ func (s *AgentStore) CleanupStale(timeout time.Duration) []string {
 
 			// Remove metrics
 			delete(s.metrics, agentID)
+			delete(s.logs, agentID)
 		}
 	}
 
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/bc-dunia/mcpdrill/internal/auth"
+)
+
+// handleAPIKeys serves GET /api-keys, listing managed keys, and POST
+// /api-keys, creating one. Only admins reach these handlers.
+func (s *Server) handleAPIKeys(w http.ResponseWriter, r *http.Request) {
+	store := s.keyStore(w)
+	if store == nil {
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		s.writeJSON(w, http.StatusOK, &ListAPIKeysResponse{Keys: store.List()})
+	case http.MethodPost:
+		var req CreateAPIKeyRequest
+		if err := json.NewDecoder(limitedBody(w, r)).Decode(&req); err != nil {
+			s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(
+				"Invalid JSON request body",
+				map[string]interface{}{"parse_error": err.Error()},
+			))
+			return
+		}
+		key, secret, err := store.Create(req.Name, req.Scopes, req.Projects, apiKeyActor(r))
+		if errors.Is(err, auth.ErrInvalidKey) {
+			s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(err.Error(), nil))
+			return
+		}
+		if err != nil {
+			s.writeError(w, http.StatusInternalServerError, NewInternalErrorResponse(err.Error()))
+			return
+		}
+		log.Printf("[Server] API key %s (%s) created by %s with scopes %v", key.ID, key.Name, key.CreatedBy, key.Scopes)
+		s.writeJSON(w, http.StatusCreated, &APIKeyResponse{ManagedKey: key, Key: secret})
+	default:
+		s.writeMethodNotAllowed(w, r.Method, "GET, POST")
+	}
+}
+
+// routeAPIKeys serves POST /api-keys/{id}/rotate and
+// POST /api-keys/{id}/revoke.
+func (s *Server) routeAPIKeys(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api-keys/"), "/"), "/")
+	if parts[0] == "" {
+		s.handleAPIKeys(w, r)
+		return
+	}
+	if len(parts) != 2 || (parts[1] != "rotate" && parts[1] != "revoke") {
+		s.writeError(w, http.StatusNotFound, &ErrorResponse{
+			ErrorType:    ErrorTypeNotFound,
+			ErrorCode:    "ENDPOINT_NOT_FOUND",
+			ErrorMessage: "Endpoint not found",
+			Retryable:    false,
+			Details:      map[string]interface{}{"path": r.URL.Path},
+		})
+		return
+	}
+	if r.Method != http.MethodPost {
+		s.writeMethodNotAllowed(w, r.Method, "POST")
+		return
+	}
+	store := s.keyStore(w)
+	if store == nil {
+		return
+	}
+
+	id := parts[0]
+	var (
+		key    auth.ManagedKey
+		secret string
+		err    error
+	)
+	if parts[1] == "rotate" {
+		key, secret, err = store.Rotate(id)
+	} else {
+		key, err = store.Revoke(id)
+	}
+	switch {
+	case errors.Is(err, auth.ErrKeyNotFound):
+		s.writeError(w, http.StatusNotFound, &ErrorResponse{
+			ErrorType:    ErrorTypeNotFound,
+			ErrorCode:    ErrorCodeAPIKeyNotFound,
+			ErrorMessage: "API key not found",
+			Retryable:    false,
+			Details:      map[string]interface{}{"id": id},
+		})
+		return
+	case err != nil:
+		s.writeError(w, http.StatusInternalServerError, NewInternalErrorResponse(err.Error()))
+		return
+	}
+	log.Printf("[Server] API key %s (%s) %sd by %s", key.ID, key.Name, parts[1], apiKeyActor(r))
+	s.writeJSON(w, http.StatusOK, &APIKeyResponse{ManagedKey: key, Key: secret})
+}
+
+// keyStore returns the managed key store, writing a 501 when API keys are
+// not managed by this server.
+func (s *Server) keyStore(w http.ResponseWriter) *auth.KeyStore {
+	if s.authConfig != nil && s.authConfig.KeyStore != nil {
+		return s.authConfig.KeyStore
+	}
+	s.writeError(w, http.StatusNotImplemented, &ErrorResponse{
+		ErrorType:    ErrorTypeNotImplemented,
+		ErrorCode:    "API_KEYS_NOT_MANAGED",
+		ErrorMessage: "API key management is not enabled on this server",
+		Retryable:    false,
+	})
+	return nil
+}
+
+func apiKeyActor(r *http.Request) string {
+	if user := auth.GetUserFromContext(r.Context()); user != nil && user.ID != "" {
+		return user.ID
+	}
+	return "api"
+}
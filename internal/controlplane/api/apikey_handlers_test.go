@@ -0,0 +1,115 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/auth"
+)
+
+func doAPIKeyRequest(t *testing.T, key, method, url string, body interface{}) (*http.Response, []byte) {
+	t.Helper()
+	var reader io.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewReader(data)
+	}
+	req, _ := http.NewRequest(method, url, reader)
+	req.Header.Set("X-API-Key", key)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	return resp, data
+}
+
+func TestAPIKeys_Management(t *testing.T) {
+	rm := newTestRunManager(t)
+	server := NewServer("127.0.0.1:0", rm)
+	server.SetAuthConfig(&auth.Config{
+		Mode:    auth.AuthModeAPIKey,
+		APIKeys: []string{"admin-key", "operator-key"},
+		APIKeyRoles: map[string][]auth.Role{
+			"admin-key": {auth.RoleAdmin},
+		},
+		KeyStore: auth.NewKeyStore(),
+	})
+	if err := server.Start(); err != nil {
+		t.Fatalf("failed to start server: %v", err)
+	}
+	defer server.Shutdown(context.Background())
+	base := server.URL()
+
+	if resp, _ := doAPIKeyRequest(t, "operator-key", http.MethodGet, base+"/api-keys", nil); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("operator listing keys: expected 403, got %d", resp.StatusCode)
+	}
+	resp, _ := doAPIKeyRequest(t, "admin-key", http.MethodPost, base+"/api-keys", CreateAPIKeyRequest{Name: "bad", Scopes: []auth.Scope{"runs:delete"}})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("unknown scope: expected 400, got %d", resp.StatusCode)
+	}
+
+	resp, body := doAPIKeyRequest(t, "admin-key", http.MethodPost, base+"/api-keys", CreateAPIKeyRequest{Name: "dashboards", Scopes: []auth.Scope{auth.ScopeRunsRead}})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d: %s", resp.StatusCode, body)
+	}
+	var reader APIKeyResponse
+	if err := json.Unmarshal(body, &reader); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if reader.Key == "" || reader.CreatedBy == "" || bytes.Contains(body, []byte("hash")) {
+		t.Fatalf("unexpected created key: %s", body)
+	}
+
+	if resp, _ := doAPIKeyRequest(t, reader.Key, http.MethodGet, base+"/runs", nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("runs:read key listing runs: expected 200, got %d", resp.StatusCode)
+	}
+	if resp, _ := doAPIKeyRequest(t, reader.Key, http.MethodPost, base+"/runs", map[string]interface{}{"config": map[string]interface{}{}}); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("runs:read key creating a run: expected 403, got %d", resp.StatusCode)
+	}
+
+	// A workers:register key is an operator, but may not touch runs.
+	_, body = doAPIKeyRequest(t, "admin-key", http.MethodPost, base+"/api-keys", CreateAPIKeyRequest{Name: "workers", Scopes: []auth.Scope{auth.ScopeWorkersRegister}})
+	var workers APIKeyResponse
+	json.Unmarshal(body, &workers)
+	resp, body = doAPIKeyRequest(t, workers.Key, http.MethodPost, base+"/runs", map[string]interface{}{"config": map[string]interface{}{}})
+	if resp.StatusCode != http.StatusForbidden || !bytes.Contains(body, []byte("INSUFFICIENT_SCOPE")) {
+		t.Errorf("workers:register key creating a run: expected INSUFFICIENT_SCOPE, got %d: %s", resp.StatusCode, body)
+	}
+
+	resp, body = doAPIKeyRequest(t, "admin-key", http.MethodPost, base+"/api-keys/"+reader.ID+"/rotate", nil)
+	var rotated APIKeyResponse
+	json.Unmarshal(body, &rotated)
+	if resp.StatusCode != http.StatusOK || rotated.Key == "" || rotated.Key == reader.Key {
+		t.Fatalf("rotate: unexpected %d: %s", resp.StatusCode, body)
+	}
+	if resp, _ := doAPIKeyRequest(t, reader.Key, http.MethodGet, base+"/runs", nil); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("rotated-out key: expected 401, got %d", resp.StatusCode)
+	}
+
+	if resp, _ := doAPIKeyRequest(t, "admin-key", http.MethodPost, base+"/api-keys/"+reader.ID+"/revoke", nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("revoke: expected 200, got %d", resp.StatusCode)
+	}
+	if resp, _ := doAPIKeyRequest(t, rotated.Key, http.MethodGet, base+"/runs", nil); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("revoked key: expected 401, got %d", resp.StatusCode)
+	}
+	if resp, _ := doAPIKeyRequest(t, "admin-key", http.MethodPost, base+"/api-keys/key_missing/rotate", nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown key: expected 404, got %d", resp.StatusCode)
+	}
+
+	_, body = doAPIKeyRequest(t, "admin-key", http.MethodGet, base+"/api-keys", nil)
+	var list ListAPIKeysResponse
+	if err := json.Unmarshal(body, &list); err != nil || len(list.Keys) != 2 {
+		t.Fatalf("list: expected 2 keys, got %s", body)
+	}
+	for _, key := range list.Keys {
+		if key.ID == reader.ID && (key.RevokedAtMs == 0 || key.RotatedAtMs == 0 || key.LastUsedAtMs == 0) {
+			t.Errorf("expected the key to be rotated, revoked and used, got %+v", key)
+		}
+	}
+}
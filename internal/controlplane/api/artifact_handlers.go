@@ -0,0 +1,187 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/artifacts"
+)
+
+// artifactURLExpiry is the validity of the signed URLs that downloads are
+// redirected to. Clients follow the redirect at once.
+const artifactURLExpiry = 15 * time.Minute
+
+// ArtifactEntry describes one stored artifact of a run.
+type ArtifactEntry struct {
+	ArtifactType artifacts.ArtifactType `json:"artifact_type"`
+	Filename     string                 `json:"filename"`
+	SizeBytes    int64                  `json:"size_bytes"`
+	DownloadURL  string                 `json:"download_url"`
+}
+
+// ArtifactListResponse is the response body for GET /runs/{id}/artifacts.
+type ArtifactListResponse struct {
+	RunID     string          `json:"run_id"`
+	Artifacts []ArtifactEntry `json:"artifacts"`
+}
+
+// SetArtifactStore configures where run artifacts are listed and downloaded
+// from.
+func (s *Server) SetArtifactStore(store artifacts.Store) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.artifactStore = store
+}
+
+// handleArtifacts serves GET /runs/{id}/artifacts and
+// GET /runs/{id}/artifacts/{type}/{filename}.
+func (s *Server) handleArtifacts(w http.ResponseWriter, r *http.Request, runID string, ref []string) {
+	if r.Method != http.MethodGet {
+		s.writeMethodNotAllowed(w, r.Method, "GET")
+		return
+	}
+
+	if _, err := s.runManager.GetRun(runID); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			s.writeError(w, http.StatusNotFound, NewNotFoundErrorResponse(runID))
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, NewInternalErrorResponse(err.Error()))
+		return
+	}
+
+	s.mu.Lock()
+	store := s.artifactStore
+	s.mu.Unlock()
+
+	if len(ref) == 0 || (len(ref) == 1 && ref[0] == "") {
+		s.handleListArtifacts(w, runID, store)
+		return
+	}
+	if len(ref) != 2 || !isArtifactType(ref[0]) || ref[1] == "" || strings.HasPrefix(ref[1], ".") {
+		s.writeError(w, http.StatusNotFound, newArtifactNotFoundResponse(runID, strings.Join(ref, "/")))
+		return
+	}
+	s.handleDownloadArtifact(w, r, runID, artifacts.ArtifactType(ref[0]), ref[1], store)
+}
+
+func (s *Server) handleListArtifacts(w http.ResponseWriter, runID string, store artifacts.Store) {
+	resp := &ArtifactListResponse{RunID: runID, Artifacts: []ArtifactEntry{}}
+	if store == nil {
+		s.writeJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	list, err := store.ListArtifacts(runID)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, NewInternalErrorResponse(err.Error()))
+		return
+	}
+	for _, a := range list {
+		resp.Artifacts = append(resp.Artifacts, ArtifactEntry{
+			ArtifactType: a.ArtifactType,
+			Filename:     a.Filename,
+			SizeBytes:    a.SizeBytes,
+			DownloadURL:  "/runs/" + runID + "/artifacts/" + string(a.ArtifactType) + "/" + a.Filename,
+		})
+	}
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+// handleDownloadArtifact sends an artifact as an attachment. Stores that sign
+// URLs get a redirect, so large artifacts bypass the control plane.
+func (s *Server) handleDownloadArtifact(w http.ResponseWriter, r *http.Request, runID string, artifactType artifacts.ArtifactType, filename string, store artifacts.Store) {
+	ref := string(artifactType) + "/" + filename
+	if store == nil {
+		s.writeError(w, http.StatusNotFound, newArtifactNotFoundResponse(runID, ref))
+		return
+	}
+
+	if signer, ok := store.(artifacts.URLSigner); ok {
+		// The object store answers 404 itself if the artifact is missing.
+		url, err := signer.SignedURL(runID, artifactType, filename, artifactURLExpiry)
+		if err != nil {
+			s.writeError(w, http.StatusInternalServerError, NewInternalErrorResponse(err.Error()))
+			return
+		}
+		http.Redirect(w, r, url, http.StatusTemporaryRedirect)
+		return
+	}
+
+	var body io.Reader
+	if opener, ok := store.(artifacts.ReaderStore); ok {
+		rc, err := opener.OpenArtifact(runID, artifactType, filename)
+		if err != nil {
+			s.writeArtifactError(w, runID, ref, err)
+			return
+		}
+		defer rc.Close()
+		body = rc
+	} else {
+		data, err := store.GetArtifact(runID, artifactType, filename)
+		if err != nil {
+			s.writeArtifactError(w, runID, ref, err)
+			return
+		}
+		body = bytes.NewReader(data)
+	}
+
+	w.Header().Set("Content-Type", artifactContentType(filename))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, body); err != nil {
+		slog.Warn("artifact download interrupted", "run_id", runID, "artifact", ref, "error", err)
+	}
+}
+
+func (s *Server) writeArtifactError(w http.ResponseWriter, runID, ref string, err error) {
+	if strings.Contains(err.Error(), "not found") {
+		s.writeError(w, http.StatusNotFound, newArtifactNotFoundResponse(runID, ref))
+		return
+	}
+	s.writeError(w, http.StatusInternalServerError, NewInternalErrorResponse(err.Error()))
+}
+
+func newArtifactNotFoundResponse(runID, ref string) *ErrorResponse {
+	return &ErrorResponse{
+		ErrorType:    ErrorTypeNotFound,
+		ErrorCode:    "ARTIFACT_NOT_FOUND",
+		ErrorMessage: "Artifact not found",
+		Retryable:    false,
+		Details:      map[string]interface{}{"run_id": runID, "artifact": ref},
+	}
+}
+
+func isArtifactType(t string) bool {
+	switch artifacts.ArtifactType(t) {
+	case artifacts.ArtifactTypeReport, artifacts.ArtifactTypeTelemetry, artifacts.ArtifactTypeConfig:
+		return true
+	}
+	return false
+}
+
+func artifactContentType(filename string) string {
+	switch path.Ext(filename) {
+	case ".gz":
+		return "application/gzip"
+	case ".sarif":
+		return "application/sarif+json"
+	case ".json":
+		return "application/json"
+	case ".jsonl":
+		return "application/x-ndjson"
+	case ".html":
+		return "text/html; charset=utf-8"
+	case ".xml":
+		return "application/xml"
+	default:
+		return "application/octet-stream"
+	}
+}
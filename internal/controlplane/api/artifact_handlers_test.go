@@ -0,0 +1,107 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/artifacts"
+)
+
+// signingStore is a filesystem store that redirects downloads.
+type signingStore struct {
+	*artifacts.FilesystemStore
+}
+
+func (s *signingStore) SignedURL(runID string, artifactType artifacts.ArtifactType, filename string, expires time.Duration) (string, error) {
+	return "https://bucket.example/" + runID + "/" + string(artifactType) + "/" + filename, nil
+}
+
+func TestArtifacts_ListAndDownload(t *testing.T) {
+	rm := newTestRunManager(t)
+	server, cleanup, err := StartTestServer(rm)
+	if err != nil {
+		t.Fatalf("Failed to start test server: %v", err)
+	}
+	defer cleanup()
+
+	store, _ := artifacts.NewFilesystemStore(t.TempDir())
+	server.SetArtifactStore(store)
+	runID, _ := rm.CreateRun(loadValidConfig(t), "test")
+	_, _ = store.SaveArtifact(runID, artifacts.ArtifactTypeReport, "report.json", []byte(`{"run_id":"x"}`))
+	_, _ = store.SaveArtifact(runID, artifacts.ArtifactTypeTelemetry, "operations.jsonl.gz", []byte{0x1f, 0x8b})
+
+	resp, err := http.Get(server.URL() + "/runs/" + runID + "/artifacts")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	var list ArtifactListResponse
+	_ = json.NewDecoder(resp.Body).Decode(&list)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || len(list.Artifacts) != 2 {
+		t.Fatalf("expected 2 artifacts, got %d: %+v", resp.StatusCode, list)
+	}
+	if got := list.Artifacts[1].DownloadURL; got != "/runs/"+runID+"/artifacts/telemetry/operations.jsonl.gz" {
+		t.Errorf("unexpected download URL %s", got)
+	}
+
+	resp, err = http.Get(server.URL() + list.Artifacts[1].DownloadURL)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || len(body) != 2 {
+		t.Fatalf("expected 2 byte download, got %d with %d bytes", resp.StatusCode, len(body))
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/gzip" {
+		t.Errorf("unexpected Content-Type %s", ct)
+	}
+	if cd := resp.Header.Get("Content-Disposition"); cd != "attachment; filename=operations.jsonl.gz" {
+		t.Errorf("unexpected Content-Disposition %s", cd)
+	}
+
+	for _, path := range []string{
+		"/runs/" + runID + "/artifacts/reports/missing.json",
+		"/runs/" + runID + "/artifacts/secrets/report.json",
+		"/runs/" + runID + "/artifacts/reports/.report.json.tmp",
+		"/runs/run_0000000000000999/artifacts",
+	} {
+		resp, err := http.Get(server.URL() + path)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("GET %s: expected 404, got %d", path, resp.StatusCode)
+		}
+	}
+}
+
+func TestArtifacts_RedirectsToSignedURL(t *testing.T) {
+	rm := newTestRunManager(t)
+	server, cleanup, err := StartTestServer(rm)
+	if err != nil {
+		t.Fatalf("Failed to start test server: %v", err)
+	}
+	defer cleanup()
+
+	fs, _ := artifacts.NewFilesystemStore(t.TempDir())
+	server.SetArtifactStore(&signingStore{FilesystemStore: fs})
+	runID, _ := rm.CreateRun(loadValidConfig(t), "test")
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(server.URL() + "/runs/" + runID + "/artifacts/reports/report.html")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTemporaryRedirect {
+		t.Fatalf("expected 307, got %d", resp.StatusCode)
+	}
+	if loc := resp.Header.Get("Location"); loc != "https://bucket.example/"+runID+"/reports/report.html" {
+		t.Errorf("unexpected Location %s", loc)
+	}
+}
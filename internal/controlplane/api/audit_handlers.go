@@ -0,0 +1,289 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/auth"
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/audit"
+)
+
+// SetAuditLog sets the log mutating API calls are recorded in.
+func (s *Server) SetAuditLog(log *audit.Log) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.auditLog = log
+}
+
+func (s *Server) getAuditLog() *audit.Log {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.auditLog
+}
+
+// auditCapture collects what the inner handlers learn about an audited
+// call: the authenticated user, and the run a new run's id.
+type auditCapture struct {
+	user  *auth.User
+	runID string
+}
+
+type auditContextKey struct{}
+
+// noteAuditUser records the authenticated user of r for the audit log.
+func noteAuditUser(r *http.Request) {
+	if c, ok := r.Context().Value(auditContextKey{}).(*auditCapture); ok {
+		c.user = auth.GetUserFromContext(r.Context())
+	}
+}
+
+// noteAuditRunID records the run r acted on when the path does not name
+// it, as for POST /runs.
+func noteAuditRunID(r *http.Request, runID string) {
+	if c, ok := r.Context().Value(auditContextKey{}).(*auditCapture); ok {
+		c.runID = runID
+	}
+}
+
+// isAudited reports whether r changes control-plane state. Config
+// validation and the calls workers and agents make while running are not
+// audited.
+func isAudited(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	path := r.URL.Path
+	if strings.HasSuffix(path, "/validate") || strings.HasPrefix(path, "/agents/v1/") {
+		return false
+	}
+	if strings.HasPrefix(path, "/workers/") && strings.Contains(strings.TrimPrefix(path, "/workers/"), "/") {
+		return false
+	}
+	return true
+}
+
+// auditMiddleware records mutating calls, including those rejected by
+// authentication, in the audit log.
+func (s *Server) auditMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auditLog := s.getAuditLog()
+		if auditLog == nil || !isAudited(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		capture := &auditCapture{}
+		rec := &auditResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), auditContextKey{}, capture)))
+
+		entry := audit.Entry{
+			Method:     r.Method,
+			Path:       r.URL.Path,
+			RunID:      capture.runID,
+			Status:     rec.status,
+			Outcome:    audit.OutcomeForStatus(rec.status),
+			SourceIP:   clientIPFromRequest(r),
+			DurationMs: time.Since(start).Milliseconds(),
+		}
+		if s.authConfig != nil {
+			entry.AuthMethod = string(s.authConfig.Mode)
+		}
+		if entry.RunID == "" {
+			entry.RunID = runIDFromPath(r.URL.Path)
+		}
+		if user := capture.user; user != nil {
+			entry.Actor = user.ID
+			entry.Claims = user.Claims
+			for _, role := range user.Roles {
+				entry.Roles = append(entry.Roles, string(role))
+			}
+			for _, scope := range user.Scopes {
+				entry.Scopes = append(entry.Scopes, string(scope))
+			}
+		}
+		auditLog.Record(context.WithoutCancel(r.Context()), entry)
+	})
+}
+
+// runIDFromPath returns the run id of /runs/{id}/... paths.
+func runIDFromPath(path string) string {
+	rest, ok := strings.CutPrefix(path, "/runs/")
+	if !ok {
+		return ""
+	}
+	runID, _, _ := strings.Cut(rest, "/")
+	if !strings.HasPrefix(runID, "run_") {
+		return ""
+	}
+	return runID
+}
+
+type auditResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *auditResponseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *auditResponseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *auditResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// handleAudit serves GET /audit, the newest matching entries first.
+func (s *Server) handleAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeMethodNotAllowed(w, r.Method, "GET")
+		return
+	}
+	filter, err := parseAuditFilter(r, 100)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(err.Error(), nil))
+		return
+	}
+	entries, err := s.queryAudit(r.Context(), filter)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, NewInternalErrorResponse(err.Error()))
+		return
+	}
+	if entries == nil {
+		entries = []audit.Entry{}
+	}
+	s.writeJSON(w, http.StatusOK, &AuditLogResponse{Entries: entries, Limit: filter.Limit})
+}
+
+// handleAuditExport serves GET /audit/export, the matching entries as JSON
+// Lines, oldest first unless order=desc.
+func (s *Server) handleAuditExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeMethodNotAllowed(w, r.Method, "GET")
+		return
+	}
+	filter, err := parseAuditFilter(r, 0)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(err.Error(), nil))
+		return
+	}
+	if r.URL.Query().Get("order") == "" {
+		filter.NewestFirst = false
+	}
+	entries, err := s.queryAudit(r.Context(), filter)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, NewInternalErrorResponse(err.Error()))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="audit.jsonl"`)
+	w.WriteHeader(http.StatusOK)
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	for _, entry := range entries {
+		if err := enc.Encode(entry); err != nil {
+			return
+		}
+	}
+	bw.Flush()
+}
+
+func (s *Server) queryAudit(ctx context.Context, filter audit.Filter) ([]audit.Entry, error) {
+	auditLog := s.getAuditLog()
+	if auditLog == nil {
+		return nil, nil
+	}
+	return auditLog.Query(ctx, filter)
+}
+
+// routeAudit serves /audit/export.
+func (s *Server) routeAudit(w http.ResponseWriter, r *http.Request) {
+	switch strings.Trim(strings.TrimPrefix(r.URL.Path, "/audit/"), "/") {
+	case "":
+		s.handleAudit(w, r)
+	case "export":
+		s.handleAuditExport(w, r)
+	default:
+		s.writeError(w, http.StatusNotFound, &ErrorResponse{
+			ErrorType:    ErrorTypeNotFound,
+			ErrorCode:    "ENDPOINT_NOT_FOUND",
+			ErrorMessage: "Endpoint not found",
+			Retryable:    false,
+			Details:      map[string]interface{}{"path": r.URL.Path},
+		})
+	}
+}
+
+// parseAuditFilter reads the filter query parameters. defaultLimit of zero
+// leaves the number of entries unlimited unless limit is given.
+func parseAuditFilter(r *http.Request, defaultLimit int) (audit.Filter, error) {
+	q := r.URL.Query()
+	filter := audit.Filter{
+		Actor:       q.Get("actor"),
+		RunID:       q.Get("run_id"),
+		Outcome:     q.Get("outcome"),
+		Method:      strings.ToUpper(q.Get("method")),
+		PathPrefix:  q.Get("path"),
+		Limit:       defaultLimit,
+		NewestFirst: true,
+	}
+
+	switch filter.Outcome {
+	case "", audit.OutcomeSuccess, audit.OutcomeDenied, audit.OutcomeFailed:
+	default:
+		return filter, &InvalidParamError{Param: "outcome", Value: filter.Outcome, Reason: "must be 'success', 'denied' or 'failed'"}
+	}
+
+	for _, p := range []struct {
+		name string
+		dst  *int64
+	}{{"since_ms", &filter.SinceMs}, {"until_ms", &filter.UntilMs}} {
+		if v := q.Get(p.name); v != "" {
+			ms, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || ms < 0 {
+				return filter, &InvalidParamError{Param: p.name, Value: v, Reason: "must be a non-negative integer"}
+			}
+			*p.dst = ms
+		}
+	}
+
+	if limitStr := q.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			return filter, &InvalidParamError{Param: "limit", Value: limitStr, Reason: "must be an integer"}
+		}
+		if limit < 1 {
+			return filter, &InvalidParamError{Param: "limit", Value: limitStr, Reason: "must be at least 1"}
+		}
+		if defaultLimit > 0 && limit > 1000 {
+			limit = 1000
+		}
+		filter.Limit = limit
+	}
+
+	if order := strings.ToLower(q.Get("order")); order != "" {
+		if order != "asc" && order != "desc" {
+			return filter, &InvalidParamError{Param: "order", Value: order, Reason: "must be 'asc' or 'desc'"}
+		}
+		filter.NewestFirst = order == "desc"
+	}
+	return filter, nil
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/auth"
+)
+
+func TestAuditLog_RecordsMutations(t *testing.T) {
+	rm := newTestRunManager(t)
+	server := NewServer("127.0.0.1:0", rm)
+	server.SetAuthConfig(&auth.Config{
+		Mode:    auth.AuthModeAPIKey,
+		APIKeys: []string{"admin-key", "operator-key", "viewer-key"},
+		APIKeyRoles: map[string][]auth.Role{
+			"admin-key":  {auth.RoleAdmin},
+			"viewer-key": {auth.RoleViewer},
+		},
+	})
+	if err := server.Start(); err != nil {
+		t.Fatalf("failed to start server: %v", err)
+	}
+	defer server.Shutdown(context.Background())
+	base := server.URL()
+
+	config := json.RawMessage(loadValidConfig(t))
+	resp, body := doAPIKeyRequest(t, "operator-key", http.MethodPost, base+"/runs", map[string]interface{}{"config": config})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create run: expected 201, got %d: %s", resp.StatusCode, body)
+	}
+	var created CreateRunResponse
+	json.Unmarshal(body, &created)
+
+	doAPIKeyRequest(t, "viewer-key", http.MethodPost, base+"/runs/"+created.RunID+"/stop", nil)
+	doAPIKeyRequest(t, "wrong-key", http.MethodDelete, base+"/scenarios/checkout", nil)
+	doAPIKeyRequest(t, "viewer-key", http.MethodGet, base+"/runs", nil)
+	doAPIKeyRequest(t, "viewer-key", http.MethodPost, base+"/runs/validate", map[string]interface{}{"config": config})
+
+	if resp, _ := doAPIKeyRequest(t, "viewer-key", http.MethodGet, base+"/audit", nil); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("viewer reading the audit log: expected 403, got %d", resp.StatusCode)
+	}
+
+	resp, body = doAPIKeyRequest(t, "admin-key", http.MethodGet, base+"/audit", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("audit: expected 200, got %d: %s", resp.StatusCode, body)
+	}
+	var log AuditLogResponse
+	if err := json.Unmarshal(body, &log); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(log.Entries) != 3 {
+		t.Fatalf("expected 3 mutating calls, got %+v", log.Entries)
+	}
+	unauthenticated, denied, createdEntry := log.Entries[0], log.Entries[1], log.Entries[2]
+	if createdEntry.Method != http.MethodPost || createdEntry.Path != "/runs" || createdEntry.RunID != created.RunID ||
+		createdEntry.Outcome != "success" || createdEntry.Status != http.StatusCreated || createdEntry.Actor == "" ||
+		createdEntry.AuthMethod != "api_key" || createdEntry.SourceIP != "127.0.0.1" {
+		t.Errorf("unexpected create entry %+v", createdEntry)
+	}
+	if denied.Outcome != "denied" || denied.Status != http.StatusForbidden || denied.RunID != created.RunID || denied.Actor == "" {
+		t.Errorf("unexpected denied entry %+v", denied)
+	}
+	if unauthenticated.Outcome != "denied" || unauthenticated.Status != http.StatusUnauthorized || unauthenticated.Actor != "" {
+		t.Errorf("unexpected unauthenticated entry %+v", unauthenticated)
+	}
+
+	_, body = doAPIKeyRequest(t, "admin-key", http.MethodGet, base+"/audit?outcome=denied&limit=1", nil)
+	json.Unmarshal(body, &log)
+	if len(log.Entries) != 1 || log.Entries[0].Status != http.StatusUnauthorized {
+		t.Errorf("expected the newest denied entry, got %+v", log.Entries)
+	}
+	if resp, _ := doAPIKeyRequest(t, "admin-key", http.MethodGet, base+"/audit?outcome=maybe", nil); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid outcome: expected 400, got %d", resp.StatusCode)
+	}
+
+	resp, body = doAPIKeyRequest(t, "admin-key", http.MethodGet, base+"/audit/export?run_id="+created.RunID, nil)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/x-ndjson" {
+		t.Fatalf("export: unexpected %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	var paths []string
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		var entry struct {
+			Path string `json:"path"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("export line %q: %v", scanner.Text(), err)
+		}
+		paths = append(paths, entry.Path)
+	}
+	if len(paths) != 2 || paths[0] != "/runs" {
+		t.Errorf("expected the run's entries oldest first, got %v", paths)
+	}
+}
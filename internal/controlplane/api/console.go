@@ -0,0 +1,68 @@
+package api
+
+import (
+	"embed"
+	"net/http"
+	"strings"
+)
+
+// The web console is a single page for operating the control plane from a
+// browser: runs, workers, agents, creating runs from scenarios, a live run
+// view and artifact downloads. Like the run dashboard it is plain HTML and
+// JavaScript embedded in the binary, so it needs no web UI build. The page
+// holds no data itself; it calls the API with the credentials the user
+// enters, so it is served without authentication.
+
+//go:embed console
+var consoleFS embed.FS
+
+const consolePrefix = "/console/"
+
+// consoleFiles are the files under /console/ and their content types.
+var consoleFiles = map[string]string{
+	"index.html":  "text/html; charset=utf-8",
+	"console.js":  "text/javascript; charset=utf-8",
+	"console.css": "text/css; charset=utf-8",
+}
+
+// handleConsole serves the console page and its assets.
+func (s *Server) handleConsole(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		s.writeMethodNotAllowed(w, r.Method, "GET")
+		return
+	}
+
+	// The page refers to its assets by relative URL.
+	if r.URL.Path == "/" || r.URL.Path == strings.TrimSuffix(consolePrefix, "/") {
+		http.Redirect(w, r, consolePrefix, http.StatusFound)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, consolePrefix)
+	if name == "" {
+		name = "index.html"
+	}
+	contentType, ok := consoleFiles[name]
+	if !ok {
+		s.writeError(w, http.StatusNotFound, &ErrorResponse{
+			ErrorType:    ErrorTypeNotFound,
+			ErrorCode:    "ENDPOINT_NOT_FOUND",
+			ErrorMessage: "Endpoint not found",
+			Retryable:    false,
+			Details:      map[string]interface{}{"path": r.URL.Path},
+		})
+		return
+	}
+	data, err := consoleFS.ReadFile("console/" + name)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, NewInternalErrorResponse(err.Error()))
+		return
+	}
+
+	// The page only loads its own assets and talks to this control plane.
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; script-src 'self'; style-src 'self'; connect-src 'self'; img-src 'self' data:; frame-ancestors 'none'")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(data)
+}
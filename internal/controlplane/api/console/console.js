@@ -0,0 +1,629 @@
+// Web console. A hash-routed page over the control plane API: lists of
+// runs, workers and agents, a form that creates runs from library
+// scenarios, and a live run view. Requests carry the saved API key or token
+// and project, so streams are read with fetch rather than EventSource,
+// which cannot send headers.
+(function () {
+  "use strict";
+
+  var MAX_POINTS = 150;
+  var MAX_EVENTS = 300;
+  var RECONNECT_MS = 3000;
+  var TERMINAL_STATES = { completed: true, failed: true, aborted: true, preflight_failed: true };
+  var SVG_NS = "http://www.w3.org/2000/svg";
+  var TOKEN_KEY = "mcpdrill.console.token";
+  var PROJECT_KEY = "mcpdrill.console.project";
+
+  // current holds what the open view needs to stop when the user leaves it.
+  var current = { streams: [] };
+
+  function $(id) {
+    return document.getElementById(id);
+  }
+
+  function headers(extra) {
+    var h = extra || {};
+    var token = localStorage.getItem(TOKEN_KEY);
+    var project = localStorage.getItem(PROJECT_KEY);
+    if (token) {
+      h["Authorization"] = "Bearer " + token;
+    }
+    if (project) {
+      h["X-Project"] = project;
+    }
+    return h;
+  }
+
+  function showError(message) {
+    var el = $("error");
+    el.textContent = message;
+    el.hidden = !message;
+  }
+
+  // api calls the control plane and resolves to the decoded JSON body,
+  // rejecting with the API's error message on failure.
+  function api(method, path, body) {
+    var init = { method: method, headers: headers({ "Accept": "application/json" }) };
+    if (body !== undefined) {
+      init.headers["Content-Type"] = "application/json";
+      init.body = JSON.stringify(body);
+    }
+    return fetch(path, init).then(function (resp) {
+      return resp.text().then(function (text) {
+        var data = null;
+        try {
+          data = text ? JSON.parse(text) : null;
+        } catch (err) {
+          data = null;
+        }
+        if (!resp.ok) {
+          var message = data && data.error_message ? data.error_message : resp.status + " " + resp.statusText;
+          if (data && data.error_code) {
+            message = data.error_code + ": " + message;
+          }
+          throw new Error(message);
+        }
+        return data;
+      });
+    });
+  }
+
+  // stream reads a server-sent event stream and calls onEvent with each
+  // event's name and decoded data. It reconnects until stop is called, and
+  // calls onOpen on every (re)connect.
+  function stream(path, onEvent, onOpen, onClose) {
+    var stopped = false;
+    var controller = null;
+
+    function connect() {
+      if (stopped) {
+        return;
+      }
+      controller = new AbortController();
+      fetch(path, { headers: headers({ "Accept": "text/event-stream" }), signal: controller.signal })
+        .then(function (resp) {
+          if (!resp.ok || !resp.body) {
+            throw new Error("stream failed: " + resp.status);
+          }
+          onOpen();
+          var reader = resp.body.getReader();
+          var decoder = new TextDecoder();
+          var buffer = "";
+          var name = "message";
+          var data = [];
+
+          function dispatch() {
+            if (data.length > 0) {
+              var parsed = null;
+              try {
+                parsed = JSON.parse(data.join("\n"));
+              } catch (err) {
+                parsed = null;
+              }
+              if (parsed !== null) {
+                onEvent(name, parsed);
+              }
+            }
+            name = "message";
+            data = [];
+          }
+
+          function read() {
+            return reader.read().then(function (chunk) {
+              if (chunk.done) {
+                return;
+              }
+              buffer += decoder.decode(chunk.value, { stream: true });
+              var lines = buffer.split("\n");
+              buffer = lines.pop();
+              lines.forEach(function (line) {
+                line = line.replace(/\r$/, "");
+                if (line === "") {
+                  dispatch();
+                } else if (line.indexOf("event:") === 0) {
+                  name = line.slice(6).trim();
+                } else if (line.indexOf("data:") === 0) {
+                  data.push(line.slice(5).replace(/^ /, ""));
+                }
+              });
+              return read();
+            });
+          }
+          return read();
+        })
+        .catch(function () {})
+        .then(function () {
+          if (!stopped) {
+            onClose();
+            setTimeout(connect, RECONNECT_MS);
+          }
+        });
+    }
+
+    connect();
+    return function stop() {
+      stopped = true;
+      if (controller) {
+        controller.abort();
+      }
+    };
+  }
+
+  function formatNumber(v, digits) {
+    if (v === null || v === undefined || isNaN(v)) {
+      return "–";
+    }
+    return Number(v).toFixed(digits);
+  }
+
+  function formatBytes(v) {
+    if (!v) {
+      return "–";
+    }
+    var units = ["B", "KiB", "MiB", "GiB", "TiB"];
+    var i = 0;
+    while (v >= 1024 && i < units.length - 1) {
+      v /= 1024;
+      i++;
+    }
+    return v.toFixed(i === 0 ? 0 : 1) + " " + units[i];
+  }
+
+  function formatDate(v) {
+    if (!v) {
+      return "–";
+    }
+    var d = new Date(v);
+    return isNaN(d.getTime()) ? "–" : d.toLocaleString();
+  }
+
+  function cell(content) {
+    var td = document.createElement("td");
+    if (content instanceof Node) {
+      td.appendChild(content);
+    } else {
+      td.textContent = content === undefined || content === null || content === "" ? "–" : String(content);
+    }
+    return td;
+  }
+
+  function link(href, text, className) {
+    var a = document.createElement("a");
+    a.href = href;
+    a.textContent = text;
+    if (className) {
+      a.className = className;
+    }
+    return a;
+  }
+
+  function replaceRows(tbody, rows, emptyText, columns) {
+    while (tbody.firstChild) {
+      tbody.removeChild(tbody.firstChild);
+    }
+    if (rows.length === 0) {
+      var tr = document.createElement("tr");
+      var td = cell(emptyText);
+      td.colSpan = columns;
+      td.className = "empty";
+      tr.appendChild(td);
+      tbody.appendChild(tr);
+      return;
+    }
+    rows.forEach(function (cells) {
+      var tr = document.createElement("tr");
+      cells.forEach(function (content) {
+        tr.appendChild(cell(content));
+      });
+      tbody.appendChild(tr);
+    });
+  }
+
+  function stateBadge(el, state) {
+    el.textContent = state || "unknown";
+    el.className = "badge";
+    if (TERMINAL_STATES[state]) {
+      el.className += " terminal";
+    } else if (state === "stopping" || state === "analyzing" || state === "paused") {
+      el.className += " stopping";
+    } else if (/_running$/.test(state || "")) {
+      el.className += " running";
+    }
+  }
+
+  // Lists
+
+  function showRuns() {
+    return api("GET", "/runs").then(function (data) {
+      var runs = (data && data.runs) || [];
+      runs.sort(function (a, b) {
+        return b.created_at_ms - a.created_at_ms;
+      });
+      replaceRows($("runs"), runs.map(function (run) {
+        var badge = document.createElement("span");
+        stateBadge(badge, run.state);
+        var scenario = run.scenario_ref ? run.scenario_ref.name + " v" + run.scenario_ref.version : run.scenario_id;
+        return [
+          link("#/runs/" + encodeURIComponent(run.run_id), run.run_id, "mono"),
+          badge,
+          run.active_stage ? run.active_stage.stage : "",
+          scenario,
+          run.project,
+          formatDate(run.created_at_ms)
+        ];
+      }), "No runs in this project", 6);
+    });
+  }
+
+  function showWorkers() {
+    return api("GET", "/workers").then(function (data) {
+      var workers = (data && data.workers) || [];
+      replaceRows($("workers"), workers.map(function (w) {
+        return [
+          w.worker_id,
+          w.host_info ? w.host_info.hostname : "",
+          w.project || "shared",
+          w.capacity ? w.capacity.max_vus : "",
+          w.effective_capacity ? w.effective_capacity.max_vus : "",
+          w.saturated ? "yes" : "no",
+          formatDate(w.last_heartbeat)
+        ];
+      }), "No workers registered", 7);
+    });
+  }
+
+  function showAgents() {
+    return api("GET", "/agents").then(function (data) {
+      var agents = (data && data.agents) || [];
+      replaceRows($("agents"), agents.map(function (a) {
+        return [
+          a.agent_id,
+          a.hostname,
+          a.pair_key,
+          a.os && a.arch ? a.os + "/" + a.arch : a.os,
+          a.version,
+          a.online ? "online" : "offline",
+          formatDate(a.last_seen)
+        ];
+      }), "No agents registered", 7);
+    });
+  }
+
+  // New run
+
+  function showNewRun() {
+    return api("GET", "/scenarios").then(function (data) {
+      var scenarios = (data && data.scenarios) || [];
+      var select = $("new-scenario");
+      while (select.firstChild) {
+        select.removeChild(select.firstChild);
+      }
+      scenarios.forEach(function (s) {
+        var option = document.createElement("option");
+        option.value = s.name;
+        option.textContent = s.name + " (v" + s.latest_version + ")" + (s.description ? " – " + s.description : "");
+        select.appendChild(option);
+      });
+      $("new-run").hidden = scenarios.length === 0;
+      $("new-empty").hidden = scenarios.length > 0;
+    });
+  }
+
+  function createRun(e) {
+    e.preventDefault();
+    var body = { scenario_ref: { name: $("new-scenario").value } };
+    var version = parseInt($("new-version").value, 10);
+    if (version > 0) {
+      body.scenario_ref.version = version;
+    }
+    var parameters = {};
+    if ($("new-target").value) {
+      parameters.target_url = $("new-target").value;
+    }
+    if (parseFloat($("new-vus").value) > 0) {
+      parameters.vu_multiplier = parseFloat($("new-vus").value);
+    }
+    if (parseFloat($("new-duration").value) > 0) {
+      parameters.duration_multiplier = parseFloat($("new-duration").value);
+    }
+    if (Object.keys(parameters).length > 0) {
+      body.parameters = parameters;
+    }
+
+    var start = $("new-start").checked;
+    api("POST", "/runs", body)
+      .then(function (created) {
+        var next = start ? api("POST", "/runs/" + encodeURIComponent(created.run_id) + "/start") : Promise.resolve();
+        return next.then(function () {
+          location.hash = "#/runs/" + encodeURIComponent(created.run_id);
+        });
+      })
+      .catch(function (err) {
+        showError(err.message);
+      });
+  }
+
+  // Run view
+
+  function svgElement(name, attrs) {
+    var el = document.createElementNS(SVG_NS, name);
+    Object.keys(attrs).forEach(function (key) {
+      el.setAttribute(key, attrs[key]);
+    });
+    return el;
+  }
+
+  function drawChart(svg, lines) {
+    var width = 600;
+    var height = 160;
+    var top = 8;
+    var bottom = height - 4;
+    var maxValue = 0;
+    lines.forEach(function (line) {
+      line.points.forEach(function (v) {
+        if (v > maxValue) {
+          maxValue = v;
+        }
+      });
+    });
+    if (maxValue <= 0) {
+      maxValue = 1;
+    }
+    maxValue *= 1.1;
+
+    while (svg.firstChild) {
+      svg.removeChild(svg.firstChild);
+    }
+    for (var g = 0; g <= 3; g++) {
+      var y = top + ((bottom - top) * g) / 3;
+      svg.appendChild(svgElement("line", { x1: 0, x2: width, y1: y, y2: y, "class": "grid" }));
+      var label = svgElement("text", { x: 4, y: y - 2, "class": "axis-label" });
+      label.textContent = formatNumber(maxValue * (1 - g / 3), maxValue < 10 ? 2 : 0);
+      svg.appendChild(label);
+    }
+    lines.forEach(function (line) {
+      if (line.points.length === 0) {
+        return;
+      }
+      var step = width / (MAX_POINTS - 1);
+      var offset = width - step * (line.points.length - 1);
+      var coords = line.points.map(function (v, i) {
+        return (offset + i * step).toFixed(1) + "," + (bottom - ((bottom - top) * v) / maxValue).toFixed(1);
+      });
+      svg.appendChild(svgElement("polyline", { points: coords.join(" "), "class": line.className }));
+    });
+  }
+
+  function push(list, value) {
+    list.push(value);
+    if (list.length > MAX_POINTS) {
+      list.shift();
+    }
+  }
+
+  function showArtifacts(runPath) {
+    return api("GET", runPath + "/artifacts").then(function (data) {
+      var artifacts = (data && data.artifacts) || [];
+      replaceRows($("artifacts"), artifacts.map(function (a) {
+        var button = document.createElement("button");
+        button.type = "button";
+        button.className = "link";
+        button.textContent = "download";
+        button.addEventListener("click", function () {
+          download(a.download_url, a.filename);
+        });
+        return [a.filename, a.artifact_type, formatBytes(a.size_bytes), button];
+      }), "No artifacts yet; reports appear once the run is analyzed", 4);
+    });
+  }
+
+  // download fetches a file with the saved credentials and hands it to the
+  // browser, since a plain link could not carry them.
+  function download(url, filename) {
+    fetch(url, { headers: headers() })
+      .then(function (resp) {
+        if (!resp.ok) {
+          throw new Error("download failed: " + resp.status + " " + resp.statusText);
+        }
+        return resp.blob();
+      })
+      .then(function (blob) {
+        var a = document.createElement("a");
+        a.href = URL.createObjectURL(blob);
+        a.download = filename;
+        document.body.appendChild(a);
+        a.click();
+        document.body.removeChild(a);
+        setTimeout(function () {
+          URL.revokeObjectURL(a.href);
+        }, 1000);
+      })
+      .catch(function (err) {
+        showError(err.message);
+      });
+  }
+
+  function addEvent(event) {
+    var list = $("events");
+    var li = document.createElement("li");
+    var time = document.createElement("span");
+    time.className = "time";
+    time.textContent = new Date(event.ts_ms).toLocaleTimeString();
+    li.appendChild(time);
+    var type = document.createElement("span");
+    type.className = "type";
+    type.textContent = event.type;
+    li.appendChild(type);
+    if (event.payload && typeof event.payload === "object") {
+      var detail = document.createElement("span");
+      detail.className = "detail";
+      detail.textContent = JSON.stringify(event.payload);
+      li.appendChild(detail);
+    }
+    list.insertBefore(li, list.firstChild);
+    while (list.children.length > MAX_EVENTS) {
+      list.removeChild(list.lastChild);
+    }
+  }
+
+  function showRun(runID) {
+    var runPath = "/runs/" + encodeURIComponent(runID);
+    var series = { rps: [], errors: [], p95: [], p99: [] };
+    var open = 0;
+
+    $("run-id").textContent = runID;
+    $("events").textContent = "";
+    ["tile-rps", "tile-errors", "tile-p95", "tile-p99", "tile-total"].forEach(function (id) {
+      $(id).textContent = "–";
+    });
+    ["chart-rps", "chart-errors", "chart-latency"].forEach(function (id) {
+      drawChart($(id), []);
+    });
+    $("view-run").setAttribute("data-run-path", runPath);
+
+    function setConnection(delta) {
+      open += delta;
+      var el = $("run-connection");
+      el.textContent = open > 0 ? "live" : "reconnecting";
+      el.className = open > 0 ? "connection live" : "connection";
+    }
+
+    function setState(state, stage) {
+      stateBadge($("run-state"), state);
+      $("run-stage").textContent = stage ? "stage: " + stage : "";
+      if (TERMINAL_STATES[state]) {
+        showArtifacts(runPath).catch(function () {});
+      }
+    }
+
+    function onMetrics(name, snapshot) {
+      if (name !== "metrics") {
+        return;
+      }
+      setState(snapshot.state, snapshot.stage);
+      $("tile-rps").textContent = formatNumber(snapshot.rps, 1);
+      $("tile-errors").textContent = formatNumber((snapshot.error_rate || 0) * 100, 2) + "%";
+      $("tile-p95").textContent = snapshot.window_ops ? snapshot.latency_p95_ms + " ms" : "–";
+      $("tile-p99").textContent = snapshot.window_ops ? snapshot.latency_p99_ms + " ms" : "–";
+      $("tile-total").textContent = String(snapshot.total_ops || 0);
+      push(series.rps, snapshot.rps || 0);
+      push(series.errors, (snapshot.error_rate || 0) * 100);
+      push(series.p95, snapshot.latency_p95_ms || 0);
+      push(series.p99, snapshot.latency_p99_ms || 0);
+      drawChart($("chart-rps"), [{ points: series.rps, className: "rps" }]);
+      drawChart($("chart-errors"), [{ points: series.errors, className: "errors" }]);
+      drawChart($("chart-latency"), [
+        { points: series.p95, className: "p95" },
+        { points: series.p99, className: "p99" }
+      ]);
+    }
+
+    function onRunEvent(name, event) {
+      if (name === "run_event") {
+        addEvent(event);
+      }
+    }
+
+    current.streams.push(stream(runPath + "/metrics/stream", onMetrics,
+      function () { setConnection(1); }, function () { setConnection(-1); }));
+    // The replay stream resends the whole history on every connect.
+    current.streams.push(stream(runPath + "/events/replay", onRunEvent,
+      function () { $("events").textContent = ""; setConnection(1); }, function () { setConnection(-1); }));
+
+    return api("GET", runPath).then(function (run) {
+      setState(run.state, run.active_stage ? run.active_stage.stage : "");
+      return showArtifacts(runPath);
+    });
+  }
+
+  function runAction(action) {
+    var runPath = $("view-run").getAttribute("data-run-path");
+    if (!runPath) {
+      return;
+    }
+    if (action === "emergency-stop" && !confirm("Stop the run immediately, without draining in-flight operations?")) {
+      return;
+    }
+    if (action === "abort" && !confirm("Abort the run? It ends at once and no report is generated.")) {
+      return;
+    }
+    // Abort, pause and resume are custom methods, as in /runs/{id}:abort.
+    var custom = action === "abort" || action === "pause" || action === "resume";
+    var path = custom ? runPath + ":" + action : runPath + "/" + action;
+    api("POST", path, action === "start" ? undefined : {})
+      .then(function () {
+        showError("");
+      })
+      .catch(function (err) {
+        showError(err.message);
+      });
+  }
+
+  // Routing
+
+  var views = {
+    runs: showRuns,
+    "new": showNewRun,
+    workers: showWorkers,
+    agents: showAgents
+  };
+
+  function route() {
+    current.streams.forEach(function (stop) {
+      stop();
+    });
+    current.streams = [];
+    showError("");
+
+    var path = location.hash.replace(/^#\/?/, "");
+    var parts = path.split("/");
+    var name = parts[0] || "runs";
+    var load;
+    if (name === "runs" && parts[1]) {
+      name = "run";
+      load = function () {
+        return showRun(decodeURIComponent(parts[1]));
+      };
+    } else {
+      if (!views[name]) {
+        name = "runs";
+      }
+      load = views[name];
+    }
+
+    Array.prototype.forEach.call(document.querySelectorAll(".view"), function (el) {
+      el.hidden = el.id !== "view-" + name;
+    });
+    Array.prototype.forEach.call(document.querySelectorAll("nav a"), function (a) {
+      a.className = a.getAttribute("data-view") === name || (name === "run" && a.getAttribute("data-view") === "runs") ? "active" : "";
+    });
+    load().catch(function (err) {
+      showError(err.message);
+    });
+  }
+
+  $("project").value = localStorage.getItem(PROJECT_KEY) || "";
+  $("token").value = localStorage.getItem(TOKEN_KEY) || "";
+  $("settings").addEventListener("submit", function (e) {
+    e.preventDefault();
+    [[PROJECT_KEY, $("project").value.trim()], [TOKEN_KEY, $("token").value.trim()]].forEach(function (kv) {
+      if (kv[1]) {
+        localStorage.setItem(kv[0], kv[1]);
+      } else {
+        localStorage.removeItem(kv[0]);
+      }
+    });
+    route();
+  });
+  $("new-run").addEventListener("submit", createRun);
+  Array.prototype.forEach.call(document.querySelectorAll("[data-refresh]"), function (button) {
+    button.addEventListener("click", route);
+  });
+  Array.prototype.forEach.call(document.querySelectorAll("[data-action]"), function (button) {
+    button.addEventListener("click", function () {
+      runAction(button.getAttribute("data-action"));
+    });
+  });
+  window.addEventListener("hashchange", route);
+  route();
+})();
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/auth"
+)
+
+func TestConsole_ServesPageWithoutAuth(t *testing.T) {
+	rm := newTestRunManager(t)
+	server := NewServer("127.0.0.1:0", rm)
+	server.SetAuthConfig(&auth.Config{
+		Mode:        auth.AuthModeAPIKey,
+		APIKeys:     []string{"admin-key"},
+		APIKeyRoles: map[string][]auth.Role{"admin-key": {auth.RoleAdmin}},
+	})
+	if err := server.Start(); err != nil {
+		t.Fatalf("failed to start server: %v", err)
+	}
+	defer server.Shutdown(context.Background())
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	for _, path := range []string{"/", "/console"} {
+		resp, err := client.Get(server.URL() + path)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusFound || resp.Header.Get("Location") != "/console/" {
+			t.Errorf("GET %s: expected a redirect to /console/, got %d %q", path, resp.StatusCode, resp.Header.Get("Location"))
+		}
+	}
+
+	for path, want := range map[string]string{
+		"/console/":            `src="console.js"`,
+		"/console/console.js":  "/events/replay",
+		"/console/console.css": ".events",
+	} {
+		resp, err := http.Get(server.URL() + path)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET %s: expected 200, got %d: %s", path, resp.StatusCode, body)
+		}
+		if !strings.Contains(string(body), want) {
+			t.Errorf("GET %s: expected %q in the body", path, want)
+		}
+		if csp := resp.Header.Get("Content-Security-Policy"); !strings.Contains(csp, "connect-src 'self'") {
+			t.Errorf("GET %s: expected a same-origin CSP, got %q", path, csp)
+		}
+	}
+
+	for _, path := range []string{"/console/console.go", "/console/../server.go", "/unknown"} {
+		resp, err := http.Get(server.URL() + path)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("GET %s: expected 404, got %d", path, resp.StatusCode)
+		}
+	}
+
+	// The data the console shows still needs credentials.
+	resp, err := http.Get(server.URL() + "/runs")
+	if err != nil {
+		t.Fatalf("GET /runs failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("GET /runs without a key: expected 401, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,263 @@
+package api
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+)
+
+// The live dashboard is a single page served per run. It follows the run's
+// event stream and GET /runs/{id}/metrics/stream, so it works without the
+// web UI build.
+
+//go:embed dashboard
+var dashboardFS embed.FS
+
+var dashboardTemplate = template.Must(template.ParseFS(dashboardFS, "dashboard/dashboard.html"))
+
+// dashboardAssets are the static files the dashboard page loads, relative
+// to /runs/{id}/dashboard/.
+var dashboardAssets = map[string]string{
+	"dashboard.js":  "text/javascript; charset=utf-8",
+	"dashboard.css": "text/css; charset=utf-8",
+}
+
+const (
+	defaultLiveMetricsIntervalMs = 2000
+	defaultLiveMetricsWindowMs   = 10000
+	// liveHostLookbackMs is how old an agent sample may be and still be
+	// shown as the host's current value.
+	liveHostLookbackMs = 60000
+)
+
+// handleDashboard serves the dashboard page for a run, or one of its assets
+// when asset is not empty.
+func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request, runID, asset string) {
+	if r.Method != http.MethodGet {
+		s.writeMethodNotAllowed(w, r.Method, "GET")
+		return
+	}
+
+	if asset != "" {
+		contentType, ok := dashboardAssets[asset]
+		if !ok {
+			s.writeError(w, http.StatusNotFound, &ErrorResponse{
+				ErrorType:    ErrorTypeNotFound,
+				ErrorCode:    "ENDPOINT_NOT_FOUND",
+				ErrorMessage: "Endpoint not found",
+				Retryable:    false,
+				Details:      map[string]interface{}{"path": r.URL.Path},
+			})
+			return
+		}
+		data, err := dashboardFS.ReadFile("dashboard/" + asset)
+		if err != nil {
+			s.writeError(w, http.StatusInternalServerError, NewInternalErrorResponse(err.Error()))
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Write(data)
+		return
+	}
+
+	if _, err := s.runManager.GetRun(runID); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			s.writeError(w, http.StatusNotFound, NewNotFoundErrorResponse(runID))
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, NewInternalErrorResponse(err.Error()))
+		return
+	}
+
+	// The page refers to its assets and streams by relative URL.
+	if strings.HasSuffix(r.URL.Path, "/") {
+		http.Redirect(w, r, strings.TrimSuffix(r.URL.Path, "/"), http.StatusMovedPermanently)
+		return
+	}
+
+	// The page only loads its own assets and talks to this control plane.
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; script-src 'self'; style-src 'self'; connect-src 'self'; img-src 'self' data:")
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	if err := dashboardTemplate.Execute(w, map[string]string{"RunID": runID}); err != nil {
+		fmt.Fprintf(w, "<!-- dashboard render failed: %s -->", template.HTMLEscapeString(err.Error()))
+	}
+}
+
+// handleStreamMetrics streams a LiveMetricsSnapshot over SSE every
+// interval_ms (default 2s) until the client disconnects. window_ms (default
+// 10s) sets the span the rates and latencies are computed over.
+func (s *Server) handleStreamMetrics(w http.ResponseWriter, r *http.Request, runID string) {
+	if r.Method != http.MethodGet {
+		s.writeMethodNotAllowed(w, r.Method, "GET")
+		return
+	}
+
+	if _, err := s.runManager.GetRun(runID); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			s.writeError(w, http.StatusNotFound, NewNotFoundErrorResponse(runID))
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, NewInternalErrorResponse(err.Error()))
+		return
+	}
+
+	intervalMs, ok := s.parseBoundedMs(w, r, "interval_ms", defaultLiveMetricsIntervalMs, 500, 60000)
+	if !ok {
+		return
+	}
+	windowMs, ok := s.parseBoundedMs(w, r, "window_ms", defaultLiveMetricsWindowMs, 1000, 300000)
+	if !ok {
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.writeError(w, http.StatusInternalServerError, NewInternalErrorResponse("Streaming not supported"))
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err == nil {
+		defer rc.SetWriteDeadline(time.Now().Add(60 * time.Second))
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	ticker := time.NewTicker(time.Duration(intervalMs) * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		snapshot := s.liveMetricsSnapshot(runID, windowMs, time.Now().UnixMilli())
+		data, err := json.Marshal(snapshot)
+		if err == nil {
+			fmt.Fprintf(w, "event: metrics\n")
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			flusher.Flush()
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// parseBoundedMs reads a millisecond query parameter, writing a 400 and
+// returning false when it is not an integer within [minMs, maxMs].
+func (s *Server) parseBoundedMs(w http.ResponseWriter, r *http.Request, name string, defaultMs, minMs, maxMs int64) (int64, bool) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return defaultMs, true
+	}
+	parsed, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || parsed < minMs || parsed > maxMs {
+		s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(
+			fmt.Sprintf("Invalid '%s' parameter: must be an integer between %d and %d", name, minMs, maxMs),
+			map[string]interface{}{"field": name, "value": v},
+		))
+		return 0, false
+	}
+	return parsed, true
+}
+
+// liveMetricsSnapshot summarizes the operations of the last windowMs before
+// nowMs, and the latest sample of each agent paired with the run.
+func (s *Server) liveMetricsSnapshot(runID string, windowMs, nowMs int64) *LiveMetricsSnapshot {
+	snapshot := &LiveMetricsSnapshot{
+		RunID:       runID,
+		TimestampMs: nowMs,
+		WindowMs:    windowMs,
+		ByTool:      []LiveToolMetrics{},
+		Hosts:       []LiveHostMetrics{},
+	}
+	if run, err := s.runManager.GetRun(runID); err == nil {
+		snapshot.State = string(run.State)
+		if run.ActiveStage != nil {
+			snapshot.Stage = run.ActiveStage.Stage
+		}
+	}
+
+	if s.telemetryStore != nil {
+		fromMs := nowMs - windowMs
+		aggregator := analysis.NewAggregator()
+		aggregator.SetTimeRange(fromMs, nowMs)
+		for _, log := range s.telemetryStore.RecentLogs(runID, fromMs) {
+			if log.TimestampMs > nowMs {
+				continue
+			}
+			aggregator.AddOperation(analysis.OperationResult{
+				Operation: log.Operation,
+				ToolName:  log.ToolName,
+				LatencyMs: log.LatencyMs,
+				OK:        log.OK,
+				ErrorType: log.ErrorType,
+			})
+		}
+		window := aggregator.Compute()
+
+		windowSeconds := float64(windowMs) / 1000
+		snapshot.TotalOps = s.telemetryStore.GetReceivedOperationCount(runID)
+		snapshot.WindowOps = window.TotalOps
+		snapshot.RPS = float64(window.TotalOps) / windowSeconds
+		snapshot.ErrorRate = window.ErrorRate
+		snapshot.LatencyP50 = window.LatencyP50
+		snapshot.LatencyP95 = window.LatencyP95
+		snapshot.LatencyP99 = window.LatencyP99
+		for tool, m := range window.ByTool {
+			snapshot.ByTool = append(snapshot.ByTool, LiveToolMetrics{
+				Tool:       tool,
+				Ops:        m.TotalOps,
+				RPS:        float64(m.TotalOps) / windowSeconds,
+				ErrorRate:  m.ErrorRate,
+				LatencyP95: m.LatencyP95,
+				LatencyP99: m.LatencyP99,
+			})
+		}
+		sort.Slice(snapshot.ByTool, func(i, j int) bool {
+			if snapshot.ByTool[i].Ops != snapshot.ByTool[j].Ops {
+				return snapshot.ByTool[i].Ops > snapshot.ByTool[j].Ops
+			}
+			return snapshot.ByTool[i].Tool < snapshot.ByTool[j].Tool
+		})
+	}
+
+	if s.agentStore != nil {
+		if pairKey := s.runManager.GetRunServerTelemetryPairKey(runID); pairKey != "" {
+			for _, series := range s.agentStore.GetSeriesByPairKey(pairKey, nowMs-liveHostLookbackMs, nowMs) {
+				latest := series.Samples[len(series.Samples)-1]
+				host := LiveHostMetrics{
+					AgentID:     series.AgentID,
+					Hostname:    series.Hostname,
+					TimestampMs: latest.Timestamp,
+				}
+				if latest.Host != nil {
+					host.CPUPercent = latest.Host.CPUPercent
+					host.MemUsed = latest.Host.MemUsed
+					host.MemTotal = latest.Host.MemTotal
+				}
+				if latest.Process != nil {
+					host.ProcessCPUPercent = latest.Process.CPUPercent
+					host.ProcessRSS = latest.Process.MemRSS
+				}
+				snapshot.Hosts = append(snapshot.Hosts, host)
+			}
+		}
+	}
+
+	return snapshot
+}
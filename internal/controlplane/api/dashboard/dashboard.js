@@ -0,0 +1,265 @@
+// Live run dashboard. Follows the run's event stream and metrics stream
+// (both relative to /runs/{id}/dashboard) and redraws on every update.
+(function () {
+  "use strict";
+
+  var MAX_POINTS = 150;
+  var MAX_EVENTS = 200;
+  var TERMINAL_STATES = { completed: true, failed: true, aborted: true, preflight_failed: true };
+  var SVG_NS = "http://www.w3.org/2000/svg";
+
+  var series = {
+    rps: [],
+    errors: [],
+    p95: [],
+    p99: [],
+    hostCPU: [],
+    processCPU: []
+  };
+
+  function $(id) {
+    return document.getElementById(id);
+  }
+
+  function push(list, value) {
+    list.push(value);
+    if (list.length > MAX_POINTS) {
+      list.shift();
+    }
+  }
+
+  function formatNumber(v, digits) {
+    if (v === null || v === undefined || isNaN(v)) {
+      return "–";
+    }
+    return Number(v).toFixed(digits);
+  }
+
+  function formatBytes(v) {
+    if (!v) {
+      return "–";
+    }
+    var units = ["B", "KiB", "MiB", "GiB", "TiB"];
+    var i = 0;
+    while (v >= 1024 && i < units.length - 1) {
+      v /= 1024;
+      i++;
+    }
+    return v.toFixed(i === 0 ? 0 : 1) + " " + units[i];
+  }
+
+  function formatTime(ms) {
+    return new Date(ms).toLocaleTimeString();
+  }
+
+  function svgElement(name, attrs) {
+    var el = document.createElementNS(SVG_NS, name);
+    Object.keys(attrs).forEach(function (key) {
+      el.setAttribute(key, attrs[key]);
+    });
+    return el;
+  }
+
+  // drawChart renders each line as a polyline scaled to the largest value
+  // across all lines, with a few horizontal grid lines for reference.
+  function drawChart(svg, lines) {
+    var width = 600;
+    var height = 180;
+    var top = 8;
+    var bottom = height - 4;
+    var maxValue = 0;
+    lines.forEach(function (line) {
+      line.points.forEach(function (v) {
+        if (v > maxValue) {
+          maxValue = v;
+        }
+      });
+    });
+    if (maxValue <= 0) {
+      maxValue = 1;
+    }
+    maxValue *= 1.1;
+
+    while (svg.firstChild) {
+      svg.removeChild(svg.firstChild);
+    }
+
+    for (var g = 0; g <= 3; g++) {
+      var y = top + ((bottom - top) * g) / 3;
+      svg.appendChild(svgElement("line", { x1: 0, x2: width, y1: y, y2: y, "class": "grid" }));
+      var label = svgElement("text", { x: 4, y: y - 2, "class": "axis-label" });
+      label.textContent = formatNumber(maxValue * (1 - g / 3), maxValue < 10 ? 2 : 0);
+      svg.appendChild(label);
+    }
+
+    lines.forEach(function (line) {
+      if (line.points.length === 0) {
+        return;
+      }
+      var step = width / (MAX_POINTS - 1);
+      var offset = width - step * (line.points.length - 1);
+      var coords = line.points.map(function (v, i) {
+        var x = offset + i * step;
+        var y = bottom - ((bottom - top) * v) / maxValue;
+        return x.toFixed(1) + "," + y.toFixed(1);
+      });
+      svg.appendChild(svgElement("polyline", { points: coords.join(" "), "class": line.className }));
+    });
+  }
+
+  function setState(state, stage) {
+    var badge = $("state");
+    badge.textContent = state || "unknown";
+    badge.className = "badge";
+    if (TERMINAL_STATES[state]) {
+      badge.className += " terminal";
+    } else if (state === "stopping" || state === "analyzing") {
+      badge.className += " stopping";
+    } else if (/_running$/.test(state || "")) {
+      badge.className += " running";
+    }
+    $("stage").textContent = stage ? "stage: " + stage : "";
+  }
+
+  function setConnection(live) {
+    var el = $("connection");
+    el.textContent = live ? "live" : "reconnecting";
+    el.className = live ? "connection live" : "connection";
+  }
+
+  function replaceRows(tbody, rows, emptyText, columns) {
+    while (tbody.firstChild) {
+      tbody.removeChild(tbody.firstChild);
+    }
+    if (rows.length === 0) {
+      var tr = document.createElement("tr");
+      var td = document.createElement("td");
+      td.colSpan = columns;
+      td.className = "empty";
+      td.textContent = emptyText;
+      tr.appendChild(td);
+      tbody.appendChild(tr);
+      return;
+    }
+    rows.forEach(function (cells) {
+      var tr = document.createElement("tr");
+      cells.forEach(function (text) {
+        var td = document.createElement("td");
+        td.textContent = text;
+        tr.appendChild(td);
+      });
+      tbody.appendChild(tr);
+    });
+  }
+
+  function onMetrics(snapshot) {
+    setState(snapshot.state, snapshot.stage);
+
+    $("tile-rps").textContent = formatNumber(snapshot.rps, 1);
+    $("tile-errors").textContent = formatNumber(snapshot.error_rate * 100, 2) + "%";
+    $("tile-p95").textContent = snapshot.window_ops ? snapshot.latency_p95_ms + " ms" : "–";
+    $("tile-p99").textContent = snapshot.window_ops ? snapshot.latency_p99_ms + " ms" : "–";
+    $("tile-total").textContent = String(snapshot.total_ops || 0);
+
+    push(series.rps, snapshot.rps || 0);
+    push(series.errors, (snapshot.error_rate || 0) * 100);
+    push(series.p95, snapshot.latency_p95_ms || 0);
+    push(series.p99, snapshot.latency_p99_ms || 0);
+
+    var hosts = snapshot.hosts || [];
+    var hostCPU = 0;
+    var processCPU = 0;
+    hosts.forEach(function (h) {
+      hostCPU = Math.max(hostCPU, h.cpu_percent || 0);
+      processCPU = Math.max(processCPU, h.process_cpu_percent || 0);
+    });
+    push(series.hostCPU, hostCPU);
+    push(series.processCPU, processCPU);
+
+    drawChart($("chart-rps"), [{ points: series.rps, className: "rps" }]);
+    drawChart($("chart-errors"), [{ points: series.errors, className: "errors" }]);
+    drawChart($("chart-latency"), [
+      { points: series.p95, className: "p95" },
+      { points: series.p99, className: "p99" }
+    ]);
+    drawChart($("chart-cpu"), [
+      { points: series.hostCPU, className: "host" },
+      { points: series.processCPU, className: "process" }
+    ]);
+
+    replaceRows($("tools"), (snapshot.by_tool || []).map(function (t) {
+      return [
+        t.tool,
+        formatNumber(t.rps, 1),
+        formatNumber(t.error_rate * 100, 2) + "%",
+        t.latency_p95_ms + " ms",
+        t.latency_p99_ms + " ms"
+      ];
+    }), "No tool calls yet", 5);
+
+    replaceRows($("hosts"), hosts.map(function (h) {
+      var mem = h.mem_total ? formatBytes(h.mem_used) + " / " + formatBytes(h.mem_total) : "–";
+      return [
+        h.hostname || h.agent_id,
+        formatNumber(h.cpu_percent, 1) + "%",
+        mem,
+        formatNumber(h.process_cpu_percent, 1) + "%",
+        formatBytes(h.process_rss)
+      ];
+    }), "No agent paired with this run", 5);
+  }
+
+  function onRunEvent(event) {
+    var list = $("events");
+    var li = document.createElement("li");
+
+    var time = document.createElement("span");
+    time.className = "time";
+    time.textContent = formatTime(event.ts_ms);
+    li.appendChild(time);
+
+    var type = document.createElement("span");
+    type.className = "type";
+    type.textContent = event.type;
+    li.appendChild(type);
+
+    var payload = event.payload;
+    if (payload && typeof payload === "object") {
+      var detail = document.createElement("span");
+      detail.className = "detail";
+      detail.textContent = JSON.stringify(payload);
+      li.appendChild(detail);
+      if (event.type === "STATE_TRANSITION" && payload.to_state) {
+        setState(payload.to_state, $("stage").textContent.replace(/^stage: /, ""));
+      }
+    }
+
+    list.insertBefore(li, list.firstChild);
+    while (list.children.length > MAX_EVENTS) {
+      list.removeChild(list.lastChild);
+    }
+  }
+
+  function subscribe(url, name, handler) {
+    var source = new EventSource(url);
+    source.addEventListener(name, function (e) {
+      var data;
+      try {
+        data = JSON.parse(e.data);
+      } catch (err) {
+        return;
+      }
+      handler(data);
+    });
+    source.addEventListener("open", function () {
+      setConnection(true);
+    });
+    source.addEventListener("error", function () {
+      setConnection(false);
+    });
+    return source;
+  }
+
+  subscribe("events/stream", "run_event", onRunEvent);
+  subscribe("metrics/stream", "metrics", onMetrics);
+})();
@@ -0,0 +1,251 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/agent"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+func TestDashboard_ServesPageAndAssets(t *testing.T) {
+	rm := newTestRunManager(t)
+	server, cleanup, err := StartTestServer(rm)
+	if err != nil {
+		t.Fatalf("Failed to start test server: %v", err)
+	}
+	defer cleanup()
+
+	runID, err := rm.CreateRun(loadValidConfig(t), "test")
+	if err != nil {
+		t.Fatalf("CreateRun failed: %v", err)
+	}
+
+	resp, err := http.Get(server.URL() + "/runs/" + runID + "/dashboard")
+	if err != nil {
+		t.Fatalf("GET dashboard failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", resp.StatusCode, body)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("Expected HTML content type, got %s", ct)
+	}
+	if csp := resp.Header.Get("Content-Security-Policy"); !strings.Contains(csp, "script-src 'self'") {
+		t.Errorf("Expected a same-origin script CSP, got %q", csp)
+	}
+	if !strings.Contains(string(body), `data-run-id="`+runID+`"`) {
+		t.Errorf("Expected page to carry the run ID, got %s", body)
+	}
+	if !strings.Contains(string(body), `src="dashboard/dashboard.js"`) {
+		t.Errorf("Expected page to load dashboard.js, got %s", body)
+	}
+
+	resp, err = http.Get(server.URL() + "/runs/" + runID + "/dashboard/dashboard.js")
+	if err != nil {
+		t.Fatalf("GET dashboard.js failed: %v", err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200 for dashboard.js, got %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/javascript") {
+		t.Errorf("Expected JavaScript content type, got %s", ct)
+	}
+	if !strings.Contains(string(body), "metrics/stream") {
+		t.Error("Expected dashboard.js to subscribe to the metrics stream")
+	}
+
+	for _, path := range []string{
+		"/runs/" + runID + "/dashboard/dashboard.html",
+		"/runs/" + runID + "/dashboard/../server.go",
+		"/runs/run_0000000000000999/dashboard",
+	} {
+		resp, err := http.Get(server.URL() + path)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("GET %s: expected 404, got %d", path, resp.StatusCode)
+		}
+	}
+}
+
+func TestStreamMetrics_InvalidParameters(t *testing.T) {
+	rm := newTestRunManager(t)
+	server, cleanup, err := StartTestServer(rm)
+	if err != nil {
+		t.Fatalf("Failed to start test server: %v", err)
+	}
+	defer cleanup()
+
+	runID, err := rm.CreateRun(loadValidConfig(t), "test")
+	if err != nil {
+		t.Fatalf("CreateRun failed: %v", err)
+	}
+
+	for _, query := range []string{"interval_ms=10", "interval_ms=abc", "window_ms=999999"} {
+		resp, err := http.Get(server.URL() + "/runs/" + runID + "/metrics/stream?" + query)
+		if err != nil {
+			t.Fatalf("GET metrics stream failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, resp.StatusCode)
+		}
+	}
+}
+
+func TestStreamMetrics_SendsSnapshots(t *testing.T) {
+	rm := newTestRunManager(t)
+	server, cleanup, err := StartTestServer(rm)
+	if err != nil {
+		t.Fatalf("Failed to start test server: %v", err)
+	}
+	defer cleanup()
+
+	store := NewTelemetryStore()
+	server.SetTelemetryStore(store)
+
+	runID, err := rm.CreateRun(loadValidConfig(t), "test")
+	if err != nil {
+		t.Fatalf("CreateRun failed: %v", err)
+	}
+
+	now := time.Now().UnixMilli()
+	store.AddTelemetryBatch(runID, TelemetryBatchRequest{
+		RunID: runID,
+		Operations: []types.OperationOutcome{
+			{OpID: "op1", Operation: "tools/call", ToolName: "echo", LatencyMs: 10, OK: true, TimestampMs: now - 500},
+			{OpID: "op2", Operation: "tools/call", ToolName: "echo", LatencyMs: 20, OK: false, ErrorType: "timeout", TimestampMs: now - 400},
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL()+"/runs/"+runID+"/metrics/stream?interval_ms=500", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("metrics stream request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream; charset=utf-8" {
+		t.Errorf("Expected Content-Type text/event-stream; charset=utf-8, got %s", ct)
+	}
+
+	var snapshots []LiveMetricsSnapshot
+	eventName := ""
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() && len(snapshots) < 2 {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			eventName = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			if eventName != "metrics" {
+				t.Fatalf("Expected metrics event, got %q", eventName)
+			}
+			var snapshot LiveMetricsSnapshot
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &snapshot); err != nil {
+				t.Fatalf("Failed to parse snapshot: %v", err)
+			}
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	cancel()
+
+	if len(snapshots) != 2 {
+		t.Fatalf("Expected an immediate and a periodic snapshot, got %d", len(snapshots))
+	}
+	first := snapshots[0]
+	if first.RunID != runID || first.State != "created" {
+		t.Errorf("Unexpected run fields: %+v", first)
+	}
+	if first.TotalOps != 2 || first.WindowOps != 2 {
+		t.Errorf("Expected 2 total and window ops, got %d and %d", first.TotalOps, first.WindowOps)
+	}
+	if first.ErrorRate != 0.5 {
+		t.Errorf("Expected error rate 0.5, got %v", first.ErrorRate)
+	}
+	if len(first.ByTool) != 1 || first.ByTool[0].Tool != "echo" || first.ByTool[0].Ops != 2 {
+		t.Errorf("Expected one echo tool row, got %+v", first.ByTool)
+	}
+}
+
+func TestLiveMetricsSnapshot(t *testing.T) {
+	rm := newTestRunManager(t)
+	server := NewServer("127.0.0.1:0", rm)
+	store := NewTelemetryStore()
+	server.SetTelemetryStore(store)
+	agents := NewAgentStore()
+	server.SetAgentStore(agents)
+
+	var config map[string]interface{}
+	if err := json.Unmarshal(loadValidConfig(t), &config); err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+	config["server_telemetry"] = map[string]interface{}{"enabled": true, "pair_key": "dash"}
+	configJSON, _ := json.Marshal(config)
+	runID, err := rm.CreateRun(configJSON, "test")
+	if err != nil {
+		t.Fatalf("CreateRun failed: %v", err)
+	}
+
+	const nowMs = int64(100000)
+	store.AddTelemetryBatch(runID, TelemetryBatchRequest{
+		RunID: runID,
+		Operations: []types.OperationOutcome{
+			// Outside the 10s window, counted only in the total.
+			{OpID: "old", Operation: "tools/call", ToolName: "slow", LatencyMs: 5000, OK: true, TimestampMs: nowMs - 20000},
+			{OpID: "a1", Operation: "tools/call", ToolName: "alpha", LatencyMs: 10, OK: true, TimestampMs: nowMs - 5000},
+			{OpID: "b1", Operation: "tools/call", ToolName: "beta", LatencyMs: 30, OK: true, TimestampMs: nowMs - 4000},
+			{OpID: "b2", Operation: "tools/call", ToolName: "beta", LatencyMs: 40, OK: false, ErrorType: "timeout", TimestampMs: nowMs - 3000},
+			{OpID: "p1", Operation: "ping", LatencyMs: 1, OK: true, TimestampMs: nowMs - 2000},
+		},
+	})
+
+	agents.Register("agent_1", "dash", "mcp-host", "linux", "amd64", "1.0.0", nil)
+	agents.Register("agent_2", "other", "elsewhere", "linux", "amd64", "1.0.0", nil)
+	agents.IngestMetrics("agent_1", []AgentMetricsSample{
+		{Timestamp: nowMs - 30000, Host: &agent.HostMetrics{CPUPercent: 10}},
+		{Timestamp: nowMs - 1000, Host: &agent.HostMetrics{CPUPercent: 42, MemUsed: 100, MemTotal: 400}, Process: &agent.ProcessMetrics{CPUPercent: 12, MemRSS: 64}},
+	})
+	agents.IngestMetrics("agent_2", []AgentMetricsSample{{Timestamp: nowMs - 1000, Host: &agent.HostMetrics{CPUPercent: 99}}})
+
+	snapshot := server.liveMetricsSnapshot(runID, 10000, nowMs)
+
+	if snapshot.TotalOps != 5 || snapshot.WindowOps != 4 {
+		t.Errorf("Expected 5 total and 4 window ops, got %d and %d", snapshot.TotalOps, snapshot.WindowOps)
+	}
+	if snapshot.RPS != 0.4 {
+		t.Errorf("Expected 0.4 RPS over the window, got %v", snapshot.RPS)
+	}
+	if snapshot.ErrorRate != 0.25 {
+		t.Errorf("Expected error rate 0.25, got %v", snapshot.ErrorRate)
+	}
+	if len(snapshot.ByTool) != 2 || snapshot.ByTool[0].Tool != "beta" || snapshot.ByTool[1].Tool != "alpha" {
+		t.Fatalf("Expected beta then alpha, got %+v", snapshot.ByTool)
+	}
+	if snapshot.ByTool[0].ErrorRate != 0.5 || snapshot.ByTool[0].RPS != 0.2 {
+		t.Errorf("Unexpected beta metrics: %+v", snapshot.ByTool[0])
+	}
+
+	if len(snapshot.Hosts) != 1 {
+		t.Fatalf("Expected only the paired host, got %+v", snapshot.Hosts)
+	}
+	host := snapshot.Hosts[0]
+	if host.Hostname != "mcp-host" || host.CPUPercent != 42 || host.MemTotal != 400 || host.ProcessRSS != 64 {
+		t.Errorf("Expected the latest paired sample, got %+v", host)
+	}
+}
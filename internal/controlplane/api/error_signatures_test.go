@@ -357,3 +357,41 @@ func TestRouteRuns_ErrorsWithoutSignatures(t *testing.T) {
 		t.Fatalf("Expected status 404 for /errors without /signatures, got %d", w.Code)
 	}
 }
+
+func TestHandleGetErrorSignatures_MessagesAndExamples(t *testing.T) {
+	ts := NewTelemetryStore()
+	runID := "run_0000000000000128"
+	code := -32001
+
+	ts.AddTelemetryBatch(runID, TelemetryBatchRequest{
+		RunID: runID,
+		Operations: []types.OperationOutcome{
+			{OpID: "op_a", TimestampMs: 1000, Operation: "tools/call", ToolName: "search", ErrorType: "tool_error", ErrorCode: "TOOL_ERROR", ErrorMessage: "rate limit hit after 10 calls"},
+			{OpID: "op_b", TimestampMs: 2000, Operation: "tools/call", ToolName: "search", ErrorType: "tool_error", ErrorCode: "TOOL_ERROR", ErrorMessage: "rate limit hit after 20 calls"},
+			{OpID: "op_c", TimestampMs: 3000, Operation: "tools/call", ToolName: "search", ErrorType: "server_error", ErrorCode: "SERVER_ERROR", JSONRPCErrorCode: &code, ErrorMessage: "rate limit hit after 20 calls"},
+		},
+	})
+	server := &Server{telemetryStore: ts}
+
+	req := httptest.NewRequest(http.MethodGet, "/runs/"+runID+"/errors/signatures", nil)
+	w := httptest.NewRecorder()
+	server.handleGetErrorSignatures(w, req, runID)
+
+	var resp ErrorSignaturesResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(resp.Signatures) != 2 {
+		t.Fatalf("Expected 2 signatures, got %+v", resp.Signatures)
+	}
+	sig := resp.Signatures[0]
+	if sig.Pattern != "rate limit hit after <NUM> calls" || sig.Count != 2 || sig.ErrorCode != "TOOL_ERROR" {
+		t.Errorf("Unexpected first signature %+v", sig)
+	}
+	if len(sig.ExampleOpIDs) != 2 || sig.ExampleOpIDs[0] != "op_a" {
+		t.Errorf("ExampleOpIDs = %v, want [op_a op_b]", sig.ExampleOpIDs)
+	}
+	if c := resp.Signatures[1].JSONRPCCode; c == nil || *c != code {
+		t.Errorf("JSONRPCCode = %v, want %d", c, code)
+	}
+}
@@ -1,9 +1,11 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"regexp"
 	"strconv"
@@ -13,6 +15,7 @@ import (
 	"github.com/bc-dunia/mcpdrill/internal/auth"
 	"github.com/bc-dunia/mcpdrill/internal/controlplane/runmanager"
 	"github.com/bc-dunia/mcpdrill/internal/validation"
+	"github.com/bc-dunia/mcpdrill/internal/websocket"
 )
 
 const (
@@ -46,7 +49,30 @@ func (s *Server) handleCreateRun(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req CreateRunRequest
-	if err := json.NewDecoder(limitedBody(w, r)).Decode(&req); err != nil {
+	var yamlDoc *validation.YAMLDocument
+	if isYAMLContentType(r.Header.Get("Content-Type")) {
+		// A YAML body is the run config itself; the actor comes from the query.
+		body, err := io.ReadAll(limitedBody(w, r))
+		if err == nil {
+			yamlDoc, err = validation.ParseYAML(body)
+		}
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(
+				"Invalid YAML request body",
+				map[string]interface{}{"parse_error": err.Error()},
+			))
+			return
+		}
+		req.Config = yamlDoc.JSON
+		req.Actor = r.URL.Query().Get("actor")
+		for _, tag := range r.URL.Query()["tag"] {
+			key, value, _ := strings.Cut(tag, ":")
+			if req.Tags == nil {
+				req.Tags = make(map[string]string)
+			}
+			req.Tags[key] = value
+		}
+	} else if err := json.NewDecoder(limitedBody(w, r)).Decode(&req); err != nil {
 		s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(
 			"Invalid JSON request body",
 			map[string]interface{}{"parse_error": err.Error()},
@@ -54,6 +80,32 @@ func (s *Server) handleCreateRun(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := runmanager.ValidateRunTags(req.Tags); err != nil {
+		s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(err.Error(), map[string]interface{}{"field": "tags"}))
+		return
+	}
+
+	if req.ScenarioRef != nil {
+		if len(req.Config) != 0 && string(req.Config) != "null" {
+			s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(
+				"Config and scenario_ref are mutually exclusive",
+				map[string]interface{}{"field": "scenario_ref"},
+			))
+			return
+		}
+		if req.Actor == "" {
+			req.Actor = "api"
+		}
+		runID, err := s.runManager.CreateRunFromScenario(requestProject(r), *req.ScenarioRef, req.Overrides, req.Parameters, req.Tags, req.Actor)
+		if err != nil {
+			s.handleScenarioError(w, req.ScenarioRef.Name, err)
+			return
+		}
+		noteAuditRunID(r, runID)
+		s.writeJSON(w, http.StatusCreated, s.createRunResponse(runID))
+		return
+	}
+
 	if len(req.Config) == 0 {
 		s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(
 			"Config is required",
@@ -66,17 +118,59 @@ func (s *Server) handleCreateRun(w http.ResponseWriter, r *http.Request) {
 		req.Actor = "api"
 	}
 
-	runID, err := s.runManager.CreateRun(req.Config, req.Actor)
+	runID, err := s.runManager.CreateRunInProject(requestProject(r), req.Config, req.Parameters, req.Tags, req.Actor)
 	if err != nil {
 		if validationErr, ok := err.(*validation.ValidationError); ok {
+			if yamlDoc != nil {
+				yamlDoc.Annotate(validationErr.Report)
+			}
 			s.writeError(w, http.StatusBadRequest, NewValidationErrorResponse(validationErr.Report))
 			return
 		}
+		if runmanager.IsInvalidArgument(err) {
+			s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(err.Error(), map[string]interface{}{"field": "parameters"}))
+			return
+		}
 		s.writeError(w, http.StatusInternalServerError, NewInternalErrorResponse(err.Error()))
 		return
 	}
 
-	s.writeJSON(w, http.StatusCreated, &CreateRunResponse{RunID: runID})
+	noteAuditRunID(r, runID)
+	s.writeJSON(w, http.StatusCreated, s.createRunResponse(runID))
+}
+
+// createRunResponse describes a created run, warning of runs of the same
+// config in progress if the server is asked to.
+func (s *Server) createRunResponse(runID string) *CreateRunResponse {
+	resp := &CreateRunResponse{RunID: runID}
+	if !s.shouldWarnDuplicateRuns() {
+		return resp
+	}
+	duplicates, err := s.runManager.RunsInProgressWithSameConfig(runID)
+	if err != nil {
+		return resp
+	}
+	var target string
+	if run, err := s.runManager.GetRun(runID); err == nil && run.TargetHost != "" {
+		target = " against " + run.TargetHost
+	}
+	for _, id := range duplicates {
+		resp.Warnings = append(resp.Warnings, fmt.Sprintf("run %s of the identical config%s is in progress", id, target))
+	}
+	return resp
+}
+
+// isYAMLContentType reports whether a Content-Type header names YAML.
+func isYAMLContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch mediaType {
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return true
+	}
+	return false
 }
 
 func (s *Server) handleValidateConfig(w http.ResponseWriter, r *http.Request) {
@@ -141,8 +235,15 @@ func (s *Server) handleStartRun(w http.ResponseWriter, r *http.Request, runID st
 		req.Actor = "api"
 	}
 
-	err := s.runManager.StartRun(runID, req.Actor)
+	err := s.runManager.StartRunWithOptions(runID, req.Actor, runmanager.StartOptions{
+		Priority:   req.Priority,
+		Parameters: req.Parameters,
+	})
 	if err != nil {
+		if validationErr, ok := err.(*validation.ValidationError); ok {
+			s.writeError(w, http.StatusBadRequest, NewValidationErrorResponse(validationErr.Report))
+			return
+		}
 		s.handleRunManagerError(w, runID, "start", err)
 		return
 	}
@@ -277,9 +378,94 @@ func (s *Server) handleEmergencyStop(w http.ResponseWriter, r *http.Request, run
 	})
 }
 
+// handleAbortRun ends a run in ABORTED without draining or analyzing it.
+func (s *Server) handleAbortRun(w http.ResponseWriter, r *http.Request, runID string) {
+	if r.Method != http.MethodPost {
+		s.writeMethodNotAllowed(w, r.Method, "POST")
+		return
+	}
+
+	var req AbortRunRequest
+	if err := json.NewDecoder(limitedBody(w, r)).Decode(&req); err != nil && err.Error() != "EOF" {
+		s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(
+			"Invalid JSON request body",
+			map[string]interface{}{"parse_error": err.Error()},
+		))
+		return
+	}
+
+	if req.Actor == "" {
+		req.Actor = apiKeyActor(r)
+	}
+
+	if err := s.runManager.AbortRun(runID, req.Actor, req.Reason); err != nil {
+		s.handleRunManagerError(w, runID, "abort", err)
+		return
+	}
+
+	run, err := s.runManager.GetRun(runID)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, NewInternalErrorResponse(err.Error()))
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, &AbortRunResponse{
+		RunID:     runID,
+		State:     string(run.State),
+		AbortedBy: run.AbortedBy,
+	})
+}
+
+// handlePauseRun pauses a running run, or resumes a paused one when resume
+// is set.
+func (s *Server) handlePauseRun(w http.ResponseWriter, r *http.Request, runID string, resume bool) {
+	if r.Method != http.MethodPost {
+		s.writeMethodNotAllowed(w, r.Method, "POST")
+		return
+	}
+
+	var req PauseRunRequest
+	if err := json.NewDecoder(limitedBody(w, r)).Decode(&req); err != nil && err.Error() != "EOF" {
+		s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(
+			"Invalid JSON request body",
+			map[string]interface{}{"parse_error": err.Error()},
+		))
+		return
+	}
+
+	if req.Actor == "" {
+		req.Actor = apiKeyActor(r)
+	}
+
+	operation := "pause"
+	var err error
+	if resume {
+		operation = "resume"
+		err = s.runManager.ResumeRun(runID, req.Actor)
+	} else {
+		err = s.runManager.PauseRun(runID, req.Actor, req.Reason)
+	}
+	if err != nil {
+		s.handleRunManagerError(w, runID, operation, err)
+		return
+	}
+
+	run, err := s.runManager.GetRun(runID)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, NewInternalErrorResponse(err.Error()))
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, &PauseRunResponse{
+		RunID:    runID,
+		State:    string(run.State),
+		PausedMs: run.PausedMs,
+	})
+}
+
 func (s *Server) handleGetRun(w http.ResponseWriter, r *http.Request, runID string) {
 	if r.Method != http.MethodGet {
-		s.writeMethodNotAllowed(w, r.Method, "GET")
+		s.writeMethodNotAllowed(w, r.Method, "GET, DELETE")
 		return
 	}
 
@@ -296,6 +482,122 @@ func (s *Server) handleGetRun(w http.ResponseWriter, r *http.Request, runID stri
 	s.writeJSON(w, http.StatusOK, &GetRunResponse{RunView: run})
 }
 
+// handleDeleteRun deletes an ended run with its events, telemetry and
+// artifacts.
+func (s *Server) handleDeleteRun(w http.ResponseWriter, r *http.Request, runID string) {
+	if !s.requireOperatorRole(w, r) {
+		return
+	}
+	if err := s.runManager.DeleteRun(runID, apiKeyActor(r)); err != nil {
+		s.handleRunManagerError(w, runID, "delete", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleOpMix(w http.ResponseWriter, r *http.Request, runID string) {
+	switch r.Method {
+	case http.MethodGet:
+		view, err := s.runManager.GetOpMix(runID)
+		if err != nil {
+			s.handleRunManagerError(w, runID, "get op mix", err)
+			return
+		}
+		s.writeJSON(w, http.StatusOK, &OpMixResponse{OpMixView: view})
+	case http.MethodPost:
+		s.handleUpdateOpMix(w, r, runID)
+	default:
+		s.writeMethodNotAllowed(w, r.Method, "GET, POST")
+	}
+}
+
+func (s *Server) handleUpdateOpMix(w http.ResponseWriter, r *http.Request, runID string) {
+	// Check role - require operator or admin
+	if s.authConfig != nil && s.authConfig.Mode != auth.AuthModeNone {
+		if !auth.HasAnyRole(r.Context(), auth.RoleAdmin, auth.RoleOperator) {
+			s.writeError(w, http.StatusForbidden, &ErrorResponse{
+				ErrorType:    ErrorTypeForbidden,
+				ErrorCode:    "INSUFFICIENT_PERMISSIONS",
+				ErrorMessage: "This action requires operator or admin role",
+			})
+			return
+		}
+	}
+
+	var req UpdateOpMixRequest
+	if err := json.NewDecoder(limitedBody(w, r)).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(
+			"Invalid JSON request body",
+			map[string]interface{}{"parse_error": err.Error()},
+		))
+		return
+	}
+
+	if req.Actor == "" {
+		req.Actor = "api"
+	}
+
+	view, err := s.runManager.UpdateOpMix(runID, req.Weights, req.Actor)
+	if err != nil {
+		s.handleRunManagerError(w, runID, "update op mix", err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, &OpMixResponse{OpMixView: view})
+}
+
+func (s *Server) handleRunTags(w http.ResponseWriter, r *http.Request, runID string) {
+	switch r.Method {
+	case http.MethodGet:
+		run, err := s.runManager.GetRun(runID)
+		if err != nil {
+			s.handleRunManagerError(w, runID, "get tags", err)
+			return
+		}
+		s.writeJSON(w, http.StatusOK, &RunTagsResponse{RunID: runID, Tags: tagsOrEmpty(run.Tags)})
+	case http.MethodPut, http.MethodPatch:
+		var req RunTagsRequest
+		if err := json.NewDecoder(limitedBody(w, r)).Decode(&req); err != nil {
+			s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(
+				"Invalid JSON request body",
+				map[string]interface{}{"parse_error": err.Error()},
+			))
+			return
+		}
+		if req.Actor == "" {
+			req.Actor = "api"
+		}
+
+		var tags map[string]string
+		var err error
+		if r.Method == http.MethodPatch {
+			tags, err = s.runManager.PatchRunTags(runID, req.Tags, req.Actor)
+		} else {
+			replace := make(map[string]string, len(req.Tags))
+			for key, value := range req.Tags {
+				if value != nil {
+					replace[key] = *value
+				}
+			}
+			tags, err = s.runManager.SetRunTags(runID, replace, req.Actor)
+		}
+		if err != nil {
+			s.handleRunManagerError(w, runID, "update tags", err)
+			return
+		}
+		s.writeJSON(w, http.StatusOK, &RunTagsResponse{RunID: runID, Tags: tagsOrEmpty(tags)})
+	default:
+		s.writeMethodNotAllowed(w, r.Method, "GET, PUT, PATCH")
+	}
+}
+
+func tagsOrEmpty(tags map[string]string) map[string]string {
+	if tags == nil {
+		return map[string]string{}
+	}
+	return tags
+}
+
 func (s *Server) handleCloneRun(w http.ResponseWriter, r *http.Request, runID string) {
 	if r.Method != http.MethodPost {
 		s.writeMethodNotAllowed(w, r.Method, "POST")
@@ -418,6 +720,9 @@ func (s *Server) handleRunManagerError(w http.ResponseWriter, runID, operation s
 		case runmanager.ErrKindInvalidState, runmanager.ErrKindInvalidTransition:
 			s.writeError(w, http.StatusConflict, NewInvalidStateErrorResponse(rmErr.RunID, string(rmErr.State), operation))
 			return
+		case runmanager.ErrKindInvalidArgument:
+			s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(rmErr.Message, map[string]interface{}{"run_id": rmErr.RunID}))
+			return
 		default:
 			s.writeError(w, http.StatusInternalServerError, NewInternalErrorResponse(rmErr.Message))
 			return
@@ -452,9 +757,9 @@ func (s *Server) handleRunManagerError(w http.ResponseWriter, runID, operation s
 func extractState(errMsg string) string {
 	// Check for lowercase state strings (actual RunState values)
 	states := []string{
-		"created", "preflight_running", "preflight_passed", "preflight_failed",
-		"baseline_running", "ramp_running", "soak_running",
-		"stopping", "analyzing", "completed", "failed", "aborted",
+		"created", "queued", "preflight_running", "preflight_passed", "preflight_failed",
+		"baseline_running", "ramp_running", "soak_running", "spike_running", "step_running",
+		"paused", "stopping", "analyzing", "completed", "failed", "aborted",
 	}
 	for _, state := range states {
 		if strings.Contains(errMsg, state) {
@@ -464,8 +769,8 @@ func extractState(errMsg string) string {
 	// Fallback: check for uppercase tokens (legacy)
 	upperStates := []string{
 		"CREATED", "PREFLIGHT_RUNNING", "PREFLIGHT_PASSED", "PREFLIGHT_FAILED",
-		"BASELINE_RUNNING", "RAMP_RUNNING", "SOAK_RUNNING",
-		"STOPPING", "ANALYZING", "COMPLETED", "FAILED", "ABORTED",
+		"BASELINE_RUNNING", "RAMP_RUNNING", "SOAK_RUNNING", "SPIKE_RUNNING", "STEP_RUNNING",
+		"PAUSED", "STOPPING", "ANALYZING", "COMPLETED", "FAILED", "ABORTED",
 	}
 	for _, state := range upperStates {
 		if strings.Contains(errMsg, state) {
@@ -504,7 +809,71 @@ func (s *Server) writeMethodNotAllowed(w http.ResponseWriter, method, allowed st
 // eventIDPattern validates event IDs: evt_<hex> format
 var eventIDPattern = regexp.MustCompile(`^evt_[0-9a-f]+$`)
 
+// handleStreamEvents streams run events over SSE, or over a WebSocket when
+// the request asks for an upgrade, resuming after Last-Event-ID, cursor or
+// since when given.
 func (s *Server) handleStreamEvents(w http.ResponseWriter, r *http.Request, runID string) {
+	s.streamEvents(w, r, runID, false)
+}
+
+// handleReplayEvents streams every historical event of the run, marks the end
+// of the history with a replay_complete event, then continues with live
+// events. Like handleStreamEvents it also accepts WebSocket upgrades. Resume
+// parameters are ignored, so a reconnecting client always rebuilds its state
+// from the full history.
+func (s *Server) handleReplayEvents(w http.ResponseWriter, r *http.Request, runID string) {
+	s.streamEvents(w, r, runID, true)
+}
+
+// parseEventTypesFilter parses the comma-separated types query parameter.
+// Returns a nil filter when the parameter is absent, and the unknown type
+// names when any are not run event types.
+func parseEventTypesFilter(r *http.Request) (map[runmanager.EventType]bool, []string) {
+	param := r.URL.Query().Get("types")
+	if param == "" {
+		return nil, nil
+	}
+
+	filter := make(map[runmanager.EventType]bool)
+	var unknown []string
+	for _, name := range strings.Split(param, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		eventType := runmanager.EventType(strings.ToUpper(name))
+		if !runmanager.IsKnownEventType(eventType) {
+			unknown = append(unknown, name)
+			continue
+		}
+		filter[eventType] = true
+	}
+	return filter, unknown
+}
+
+// writeSSEEvents writes the events that pass the type filter and returns how
+// many were written. A nil filter passes every event.
+func writeSSEEvents(w io.Writer, events []runmanager.RunEvent, filter map[runmanager.EventType]bool) int {
+	written := 0
+	for _, event := range events {
+		if filter != nil && !filter[event.Type] {
+			continue
+		}
+		eventData, err := json.Marshal(event)
+		if err != nil {
+			continue
+		}
+
+		// Emit SSE event per spec: event, id, data fields
+		fmt.Fprintf(w, "event: run_event\n")
+		fmt.Fprintf(w, "id: %s\n", event.EventID)
+		fmt.Fprintf(w, "data: %s\n\n", eventData)
+		written++
+	}
+	return written
+}
+
+func (s *Server) streamEvents(w http.ResponseWriter, r *http.Request, runID string, replay bool) {
 	if r.Method != http.MethodGet {
 		s.writeMethodNotAllowed(w, r.Method, "GET")
 		return
@@ -519,11 +888,24 @@ func (s *Server) handleStreamEvents(w http.ResponseWriter, r *http.Request, runI
 		return
 	}
 
+	typeFilter, unknownTypes := parseEventTypesFilter(r)
+	if len(unknownTypes) > 0 {
+		s.writeError(w, http.StatusBadRequest, &ErrorResponse{
+			ErrorType:    ErrorTypeInvalidArgument,
+			ErrorCode:    "INVALID_EVENT_TYPES",
+			ErrorMessage: "Unknown event types in types parameter",
+			Retryable:    false,
+			Details:      map[string]interface{}{"unknown_types": unknownTypes},
+		})
+		return
+	}
+
 	cursor := 0
-	cursorSet := false
+	// Replay always starts from the beginning of the event log.
+	cursorSet := replay
 
 	// Handle Last-Event-ID header (highest precedence per SSE spec)
-	if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+	if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" && !replay {
 		// Validate format: must be evt_<hex>
 		if !eventIDPattern.MatchString(lastEventID) {
 			s.writeError(w, http.StatusBadRequest, &ErrorResponse{
@@ -598,6 +980,11 @@ func (s *Server) handleStreamEvents(w http.ResponseWriter, r *http.Request, runI
 		}
 	}
 
+	if isWebSocketUpgrade(r) {
+		s.streamEventsWebSocket(w, r, runID, cursor, typeFilter, replay)
+		return
+	}
+
 	flusher, ok := w.(http.Flusher)
 	if !ok {
 		s.writeError(w, http.StatusInternalServerError, NewInternalErrorResponse("Streaming not supported"))
@@ -617,7 +1004,97 @@ func (s *Server) handleStreamEvents(w http.ResponseWriter, r *http.Request, runI
 	w.WriteHeader(http.StatusOK)
 	flusher.Flush()
 
-	ctx := r.Context()
+	s.pumpEvents(r.Context(), runID, cursor, typeFilter, replay, &sseEventWriter{w: w, flusher: flusher})
+}
+
+// streamEventsWebSocket upgrades the request and streams events as JSON text
+// messages, one per event, in the same order and with the same resume
+// semantics as the SSE stream.
+func (s *Server) streamEventsWebSocket(w http.ResponseWriter, r *http.Request, runID string, cursor int, typeFilter map[runmanager.EventType]bool, replay bool) {
+	if err := checkWebSocketHandshake(r); err != nil {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		s.writeError(w, http.StatusBadRequest, &ErrorResponse{
+			ErrorType:    ErrorTypeInvalidArgument,
+			ErrorCode:    "INVALID_WEBSOCKET_HANDSHAKE",
+			ErrorMessage: err.Error(),
+			Retryable:    false,
+			Details:      map[string]interface{}{},
+		})
+		return
+	}
+
+	ws, err := acceptWebSocket(w, r, nil)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, NewInternalErrorResponse("WebSocket not supported"))
+		return
+	}
+
+	s.mu.Lock()
+	stopCh := s.stopCh
+	s.mu.Unlock()
+
+	// A hijacked connection is not closed by the server on shutdown and
+	// its request context does not see the client leave.
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	go func() {
+		select {
+		case <-ws.Done():
+		case <-stopCh:
+		case <-ctx.Done():
+		}
+		cancel()
+	}()
+
+	s.pumpEvents(ctx, runID, cursor, typeFilter, replay, &wsEventWriter{ws: ws})
+
+	select {
+	case <-stopCh:
+		ws.Close(websocket.CloseGoingAway)
+	default:
+		ws.Close(websocket.CloseNormal)
+	}
+}
+
+// eventStreamWriter delivers run events to one streaming client.
+type eventStreamWriter interface {
+	// writeEvents writes the events that pass the type filter and returns
+	// how many were written.
+	writeEvents(events []runmanager.RunEvent, filter map[runmanager.EventType]bool) (int, error)
+	writeReplayComplete(replayed int, lastEventID string) error
+	keepalive() error
+}
+
+// pumpEvents writes the run's events from cursor on to out until ctx is done
+// or a write fails. With replay, the history is followed by a
+// replay_complete marker before live events.
+func (s *Server) pumpEvents(ctx context.Context, runID string, cursor int, typeFilter map[runmanager.EventType]bool, replay bool, out eventStreamWriter) {
+	if replay {
+		replayed := 0
+		lastEventID := ""
+		for {
+			events, err := s.runManager.TailEvents(runID, cursor, sseEventBatchLimit)
+			if err != nil {
+				return
+			}
+			n, err := out.writeEvents(events, typeFilter)
+			if err != nil {
+				return
+			}
+			replayed += n
+			cursor += len(events)
+			if len(events) > 0 {
+				lastEventID = events[len(events)-1].EventID
+			}
+			if len(events) < sseEventBatchLimit {
+				break
+			}
+		}
+		if err := out.writeReplayComplete(replayed, lastEventID); err != nil {
+			return
+		}
+	}
+
 	heartbeatTicker := time.NewTicker(sseHeartbeatInterval)
 	defer heartbeatTicker.Stop()
 
@@ -629,33 +1106,99 @@ func (s *Server) handleStreamEvents(w http.ResponseWriter, r *http.Request, runI
 		case <-ctx.Done():
 			return
 		case <-heartbeatTicker.C:
-			// Per spec: :keepalive comment every 15s
-			fmt.Fprintf(w, ":keepalive\n\n")
-			flusher.Flush()
+			if err := out.keepalive(); err != nil {
+				return
+			}
 		case <-pollTicker.C:
 			events, err := s.runManager.TailEvents(runID, cursor, sseEventBatchLimit)
 			if err != nil {
 				return
 			}
 
-			for _, event := range events {
-				eventData, err := json.Marshal(event)
-				if err != nil {
-					continue
-				}
-
-				// Emit SSE event per spec: event, id, data fields
-				fmt.Fprintf(w, "event: run_event\n")
-				fmt.Fprintf(w, "id: %s\n", event.EventID)
-				fmt.Fprintf(w, "data: %s\n\n", eventData)
+			// Filtered-out events still advance the cursor.
+			if _, err := out.writeEvents(events, typeFilter); err != nil {
+				return
 			}
+			cursor += len(events)
+		}
+	}
+}
 
-			if len(events) > 0 {
-				flusher.Flush()
-				cursor += len(events)
-			}
+type sseEventWriter struct {
+	w       io.Writer
+	flusher http.Flusher
+}
+
+func (sw *sseEventWriter) writeEvents(events []runmanager.RunEvent, filter map[runmanager.EventType]bool) (int, error) {
+	n := writeSSEEvents(sw.w, events, filter)
+	if n > 0 {
+		sw.flusher.Flush()
+	}
+	return n, nil
+}
+
+func (sw *sseEventWriter) writeReplayComplete(replayed int, lastEventID string) error {
+	// The marker carries no id so it never becomes the Last-Event-ID.
+	markerData, _ := json.Marshal(map[string]interface{}{
+		"replayed_events": replayed,
+		"last_event_id":   lastEventID,
+	})
+	fmt.Fprintf(sw.w, "event: replay_complete\n")
+	fmt.Fprintf(sw.w, "data: %s\n\n", markerData)
+	sw.flusher.Flush()
+	return nil
+}
+
+func (sw *sseEventWriter) keepalive() error {
+	// Per spec: :keepalive comment every 15s
+	fmt.Fprintf(sw.w, ":keepalive\n\n")
+	sw.flusher.Flush()
+	return nil
+}
+
+// wsEventMessage is the WebSocket form of an SSE event: Event and ID mirror
+// the SSE event and id fields, Data the data field.
+type wsEventMessage struct {
+	Event string      `json:"event"`
+	ID    string      `json:"id,omitempty"`
+	Data  interface{} `json:"data"`
+}
+
+type wsEventWriter struct {
+	ws *wsConn
+}
+
+func (ww *wsEventWriter) writeEvents(events []runmanager.RunEvent, filter map[runmanager.EventType]bool) (int, error) {
+	written := 0
+	for _, event := range events {
+		if filter != nil && !filter[event.Type] {
+			continue
 		}
+		data, err := json.Marshal(wsEventMessage{Event: "run_event", ID: event.EventID, Data: event})
+		if err != nil {
+			continue
+		}
+		if err := ww.ws.WriteText(data); err != nil {
+			return written, err
+		}
+		written++
 	}
+	return written, nil
+}
+
+func (ww *wsEventWriter) writeReplayComplete(replayed int, lastEventID string) error {
+	data, _ := json.Marshal(wsEventMessage{
+		Event: "replay_complete",
+		Data: map[string]interface{}{
+			"replayed_events": replayed,
+			"last_event_id":   lastEventID,
+		},
+	})
+	return ww.ws.WriteText(data)
+}
+
+func (ww *wsEventWriter) keepalive() error {
+	return ww.ws.Ping()
 }
 
 // maxRequestBodySize is the maximum allowed request body size (10MB default).
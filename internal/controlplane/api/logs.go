@@ -61,17 +61,20 @@ func parseLogFilters(r *http.Request) (LogFilters, error) {
 	q := r.URL.Query()
 
 	filters := LogFilters{
-		Stage:     q.Get("stage"),
-		StageID:   q.Get("stage_id"),
-		WorkerID:  q.Get("worker_id"),
-		SessionID: q.Get("session_id"),
-		Operation: q.Get("operation"),
-		ToolName:  q.Get("tool_name"),
-		ErrorType: q.Get("error_type"),
-		ErrorCode: q.Get("error_code"),
-		Limit:     100,
-		Offset:    0,
-		Order:     "desc",
+		Stage:        q.Get("stage"),
+		StageID:      q.Get("stage_id"),
+		WorkerID:     q.Get("worker_id"),
+		Group:        q.Get("generator_group"),
+		Target:       q.Get("target"),
+		SessionID:    q.Get("session_id"),
+		Operation:    q.Get("operation"),
+		ToolName:     q.Get("tool_name"),
+		ErrorType:    q.Get("error_type"),
+		ErrorCode:    q.Get("error_code"),
+		FuzzMutation: q.Get("fuzz_mutation"),
+		Limit:        100,
+		Offset:       0,
+		Order:        "desc",
 	}
 
 	if vuIDStr := q.Get("vu_id"); vuIDStr != "" {
@@ -28,7 +28,7 @@ func TestQueryLogs_BasicFiltering(t *testing.T) {
 		Operations: []types.OperationOutcome{
 			{OpID: "op1", Operation: "tools/list", LatencyMs: 100, OK: true, TimestampMs: 1000},
 			{OpID: "op2", Operation: "tools/call", ToolName: "read_file", LatencyMs: 200, OK: true, TimestampMs: 2000},
-			{OpID: "op3", Operation: "tools/call", ToolName: "write_file", LatencyMs: 300, OK: false, ErrorType: "timeout", TimestampMs: 3000},
+			{OpID: "op3", Operation: "tools/call", ToolName: "write_file", LatencyMs: 300, OK: false, ErrorType: "timeout", TimestampMs: 3000, FuzzMutation: "deep_nesting"},
 		},
 	}
 	ts.AddTelemetryBatchWithContext("run_0000000000000001", batch, "worker-1", "baseline", "stg_000000000002", "5")
@@ -63,6 +63,12 @@ func TestQueryLogs_BasicFiltering(t *testing.T) {
 			expectedCount: 1,
 			expectedTotal: 1,
 		},
+		{
+			name:          "filter by fuzz_mutation",
+			filters:       LogFilters{FuzzMutation: "deep_nesting", Limit: 100, Order: "desc"},
+			expectedCount: 1,
+			expectedTotal: 1,
+		},
 		{
 			name:          "filter by stage",
 			filters:       LogFilters{Stage: "baseline", Limit: 100, Order: "desc"},
@@ -1,10 +1,13 @@
 package api
 
 import (
+	"encoding/json"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/bc-dunia/mcpdrill/internal/analysis"
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/runmanager"
 	"github.com/bc-dunia/mcpdrill/internal/metrics"
 )
 
@@ -14,8 +17,95 @@ func (s *Server) handleListRuns(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	runs := s.runManager.ListRuns()
-	s.writeJSON(w, http.StatusOK, &ListRunsResponse{Runs: runs})
+	filter, err := parseRunFilter(r)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(err.Error(), nil))
+		return
+	}
+	filter.Project = requestProject(r)
+	runs, total := s.runManager.QueryRuns(filter)
+	s.writeJSON(w, http.StatusOK, &ListRunsResponse{Runs: runs, Total: total, Offset: filter.Offset, Limit: filter.Limit})
+}
+
+// parseRunFilter reads the filter, sort and page query parameters of
+// GET /runs. Tags are given as repeated tag=key:value, or tag=key for runs
+// that have the key.
+func parseRunFilter(r *http.Request) (runmanager.RunFilter, error) {
+	q := r.URL.Query()
+	filter := runmanager.RunFilter{
+		ScenarioID: q.Get("scenario_id"),
+		TargetHost: q.Get("target_host"),
+		SortBy:     runmanager.RunSortCreatedAt,
+	}
+
+	if states := q.Get("state"); states != "" {
+		for _, state := range strings.Split(states, ",") {
+			state = strings.TrimSpace(state)
+			if !runmanager.ValidRunState(runmanager.RunState(state)) {
+				return filter, &InvalidParamError{Param: "state", Value: state, Reason: "must be a run state"}
+			}
+			filter.States = append(filter.States, runmanager.RunState(state))
+		}
+	}
+
+	for _, tag := range q["tag"] {
+		key, value, _ := strings.Cut(tag, ":")
+		if key == "" {
+			return filter, &InvalidParamError{Param: "tag", Value: tag, Reason: "must be key or key:value"}
+		}
+		if filter.Tags == nil {
+			filter.Tags = make(map[string]string)
+		}
+		filter.Tags[key] = value
+	}
+
+	for _, p := range []struct {
+		name string
+		dst  *int64
+	}{{"since_ms", &filter.CreatedSinceMs}, {"until_ms", &filter.CreatedUntilMs}} {
+		if v := q.Get(p.name); v != "" {
+			ms, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || ms < 0 {
+				return filter, &InvalidParamError{Param: p.name, Value: v, Reason: "must be a non-negative integer"}
+			}
+			*p.dst = ms
+		}
+	}
+
+	if sortBy := q.Get("sort"); sortBy != "" {
+		if sortBy != runmanager.RunSortCreatedAt && sortBy != runmanager.RunSortUpdatedAt {
+			return filter, &InvalidParamError{Param: "sort", Value: sortBy, Reason: "must be 'created_at' or 'updated_at'"}
+		}
+		filter.SortBy = sortBy
+	}
+	if order := strings.ToLower(q.Get("order")); order != "" {
+		if order != "asc" && order != "desc" {
+			return filter, &InvalidParamError{Param: "order", Value: order, Reason: "must be 'asc' or 'desc'"}
+		}
+		filter.Ascending = order == "asc"
+	}
+
+	if limitStr := q.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			return filter, &InvalidParamError{Param: "limit", Value: limitStr, Reason: "must be an integer"}
+		}
+		if limit < 1 {
+			return filter, &InvalidParamError{Param: "limit", Value: limitStr, Reason: "must be at least 1"}
+		}
+		filter.Limit = min(limit, 1000)
+	}
+	if offsetStr := q.Get("offset"); offsetStr != "" {
+		offset, err := strconv.Atoi(offsetStr)
+		if err != nil {
+			return filter, &InvalidParamError{Param: "offset", Value: offsetStr, Reason: "must be an integer"}
+		}
+		if offset < 0 {
+			return filter, &InvalidParamError{Param: "offset", Value: offsetStr, Reason: "must be non-negative"}
+		}
+		filter.Offset = offset
+	}
+	return filter, nil
 }
 
 func (s *Server) handleGetRunMetrics(w http.ResponseWriter, r *http.Request, runID string) {
@@ -46,8 +136,9 @@ func (s *Server) handleGetRunMetrics(w http.ResponseWriter, r *http.Request, run
 
 	aggregator := analysis.NewAggregator()
 	aggregator.SetTimeRange(data.StartTimeMs, data.EndTimeMs)
-	for _, op := range data.Operations {
-		aggregator.AddOperation(op)
+	if err := s.telemetryStore.addOperations(data, aggregator); err != nil {
+		s.writeError(w, http.StatusInternalServerError, NewInternalErrorResponse(err.Error()))
+		return
 	}
 	metricsData := aggregator.Compute()
 
@@ -135,6 +226,49 @@ func (s *Server) handleGetRunStability(w http.ResponseWriter, r *http.Request, r
 	})
 }
 
+func (s *Server) handleGetRunStickiness(w http.ResponseWriter, r *http.Request, runID string) {
+	if r.Method != http.MethodGet {
+		s.writeMethodNotAllowed(w, r.Method, "GET")
+		return
+	}
+
+	config, err := s.runManager.GetRunConfig(runID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			s.writeError(w, http.StatusNotFound, NewNotFoundErrorResponse(runID))
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, NewInternalErrorResponse(err.Error()))
+		return
+	}
+
+	var targetConfig struct {
+		Target struct {
+			BackendIDHeader string `json:"backend_id_header"`
+		} `json:"target"`
+	}
+	_ = json.Unmarshal(config, &targetConfig)
+
+	includeEvents := r.URL.Query().Get("include_events") == "true"
+	includeSessions := r.URL.Query().Get("include_sessions") == "true"
+
+	response := &StickinessResponse{
+		RunID:             runID,
+		BackendHeader:     targetConfig.Target.BackendIDHeader,
+		StickinessMetrics: &metrics.StickinessMetrics{Backends: []metrics.BackendLoad{}},
+	}
+
+	if s.telemetryStore != nil {
+		if stickiness := s.telemetryStore.GetStickinessMetrics(runID, includeEvents, includeSessions); stickiness != nil {
+			response.StickinessMetrics = stickiness
+		}
+		_, logsTruncated := s.telemetryStore.IsTruncated(runID)
+		response.DataTruncated = logsTruncated
+	}
+
+	s.writeJSON(w, http.StatusOK, response)
+}
+
 func (s *Server) handleCompareRuns(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		s.writeMethodNotAllowed(w, r.Method, "GET")
@@ -175,8 +309,9 @@ func (s *Server) handleCompareRuns(w http.ResponseWriter, r *http.Request) {
 	// Compute metrics for run A
 	aggregatorA := analysis.NewAggregator()
 	aggregatorA.SetTimeRange(dataA.StartTimeMs, dataA.EndTimeMs)
-	for _, op := range dataA.Operations {
-		aggregatorA.AddOperation(op)
+	if err := s.telemetryStore.addOperations(dataA, aggregatorA); err != nil {
+		s.writeError(w, http.StatusInternalServerError, NewInternalErrorResponse(err.Error()))
+		return
 	}
 	metricsA := aggregatorA.Compute()
 	durationA := dataA.EndTimeMs - dataA.StartTimeMs
@@ -188,8 +323,9 @@ func (s *Server) handleCompareRuns(w http.ResponseWriter, r *http.Request) {
 	// Compute metrics for run B
 	aggregatorB := analysis.NewAggregator()
 	aggregatorB.SetTimeRange(dataB.StartTimeMs, dataB.EndTimeMs)
-	for _, op := range dataB.Operations {
-		aggregatorB.AddOperation(op)
+	if err := s.telemetryStore.addOperations(dataB, aggregatorB); err != nil {
+		s.writeError(w, http.StatusInternalServerError, NewInternalErrorResponse(err.Error()))
+		return
 	}
 	metricsB := aggregatorB.Compute()
 	durationB := dataB.EndTimeMs - dataB.StartTimeMs
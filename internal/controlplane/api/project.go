@@ -0,0 +1,132 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/auth"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+// projectContextKey holds the project a request acts in.
+type projectContextKey struct{}
+
+// SetProjectRateLimiterConfig configures the rate limit every project gets
+// on top of the per-client one, so one busy project cannot starve the
+// others. Must be called before Start() for changes to take effect.
+func (s *Server) SetProjectRateLimiterConfig(config *RateLimiterConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.projectRateLimiter = nil
+	if config != nil && config.Enabled {
+		s.projectRateLimiter = newRateLimiter(config)
+	}
+}
+
+// isProjectScoped reports whether path acts within a project. API keys and
+// the audit log are global, and workers make their calls after
+// registration in the project they registered in.
+func isProjectScoped(path string) bool {
+	if isAdminPath(path) {
+		return false
+	}
+	if rest, ok := strings.CutPrefix(path, "/workers/"); ok {
+		return rest == "" || rest == "register" || rest == "capacity"
+	}
+	return true
+}
+
+// projectMiddleware resolves the project of a request, rejects callers that
+// may not act in it and applies the per-project rate limit.
+func (s *Server) projectMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isProjectScoped(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		project, explicit := r.Header.Get(types.ProjectHeader), true
+		if project == "" {
+			project = r.URL.Query().Get("project")
+		}
+		user := auth.GetUserFromContext(r.Context())
+		if project == "" {
+			project, explicit = types.DefaultProject, false
+			// A caller bound to a single project acts in it by default.
+			if user != nil && len(user.Projects) == 1 {
+				project = user.Projects[0]
+			}
+		}
+		if !types.ValidProjectName(project) {
+			s.writeError(w, http.StatusBadRequest, NewErrorResponse(ErrorTypeInvalidArgument, "INVALID_PROJECT",
+				fmt.Sprintf("Invalid project name %q: must be 1-63 lowercase letters, digits or '-'", project),
+				false, map[string]interface{}{"project": project}))
+			return
+		}
+		if s.authEnabled() && !user.CanAccessProject(project) {
+			message := fmt.Sprintf("Not allowed to act in project %q", project)
+			if !explicit {
+				message += "; select a project with the " + types.ProjectHeader + " header"
+			}
+			s.writeError(w, http.StatusForbidden, NewErrorResponse(ErrorTypeForbidden, "PROJECT_FORBIDDEN",
+				message, false, map[string]interface{}{"project": project}))
+			return
+		}
+
+		s.mu.Lock()
+		rl := s.projectRateLimiter
+		s.mu.Unlock()
+		if rl != nil && !rl.allowKey("project:"+project) {
+			log.Printf("[RateLimiter] Rate limit exceeded for project %s", project)
+			w.Header().Set("X-RateLimit-Limit", fmt.Sprintf("%d", rl.config.BurstSize))
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", fmt.Sprintf("%d", time.Now().Add(time.Second).Unix()))
+			w.Header().Set("Retry-After", "1")
+			s.writeError(w, http.StatusTooManyRequests, &ErrorResponse{
+				ErrorType:    ErrorTypeRateLimited,
+				ErrorCode:    "PROJECT_RATE_LIMIT_EXCEEDED",
+				ErrorMessage: fmt.Sprintf("Too many requests in project %q. Please slow down.", project),
+				Retryable:    true,
+				Details: map[string]interface{}{
+					"project":             project,
+					"retry_after_seconds": 1,
+				},
+			})
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), projectContextKey{}, project)))
+	})
+}
+
+// requestProject returns the project a request acts in.
+func requestProject(r *http.Request) string {
+	if project, ok := r.Context().Value(projectContextKey{}).(string); ok {
+		return project
+	}
+	return types.DefaultProject
+}
+
+// canAccessRun reports whether the caller may see a run. Runs are reached
+// by id from any project the caller may act in, so links keep working
+// without the project header. Unknown runs are left to the handlers.
+func (s *Server) canAccessRun(r *http.Request, runID string) bool {
+	if s.runManager == nil || !s.authEnabled() {
+		return true
+	}
+	project := s.runManager.GetRunProject(runID)
+	if project == "" {
+		return true
+	}
+	return auth.GetUserFromContext(r.Context()).CanAccessProject(project)
+}
+
+func (s *Server) authEnabled() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.authConfig != nil && s.authConfig.Mode != auth.AuthModeNone
+}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/auth"
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/scheduler"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+func doProjectRequest(t *testing.T, key, project, method, url string, body interface{}) (*http.Response, []byte) {
+	t.Helper()
+	var reader io.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewReader(data)
+	}
+	req, _ := http.NewRequest(method, url, reader)
+	req.Header.Set("X-API-Key", key)
+	if project != "" {
+		req.Header.Set(types.ProjectHeader, project)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	return resp, data
+}
+
+func TestProjects_IsolateRunsAndKeys(t *testing.T) {
+	rm := newTestRunManager(t)
+	server := NewServer("127.0.0.1:0", rm)
+	keyStore := auth.NewKeyStore()
+	server.SetAuthConfig(&auth.Config{
+		Mode:        auth.AuthModeAPIKey,
+		APIKeys:     []string{"admin-key"},
+		APIKeyRoles: map[string][]auth.Role{"admin-key": {auth.RoleAdmin}},
+		KeyStore:    keyStore,
+	})
+	if err := server.Start(); err != nil {
+		t.Fatalf("failed to start server: %v", err)
+	}
+	defer server.Shutdown(context.Background())
+	base := server.URL()
+
+	_, teamKey, err := keyStore.Create("team-a", []auth.Scope{auth.ScopeRunsRead, auth.ScopeRunsWrite}, []string{"team-a"}, "admin")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	config := json.RawMessage(loadValidConfig(t))
+
+	// A key bound to one project creates runs in it without a header.
+	resp, body := doProjectRequest(t, teamKey, "", http.MethodPost, base+"/runs", map[string]interface{}{"config": config})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create in team-a: expected 201, got %d: %s", resp.StatusCode, body)
+	}
+	var created CreateRunResponse
+	json.Unmarshal(body, &created)
+	if project := rm.GetRunProject(created.RunID); project != "team-a" {
+		t.Fatalf("expected the run in team-a, got %q", project)
+	}
+	if resp, body := doProjectRequest(t, "admin-key", "", http.MethodPost, base+"/runs", map[string]interface{}{"config": config}); resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create in default: expected 201, got %d: %s", resp.StatusCode, body)
+	}
+
+	var list ListRunsResponse
+	_, body = doProjectRequest(t, teamKey, "", http.MethodGet, base+"/runs", nil)
+	if err := json.Unmarshal(body, &list); err != nil || len(list.Runs) != 1 || list.Runs[0].RunID != created.RunID {
+		t.Errorf("team-a listing: expected only its own run, got %s", body)
+	}
+	_, body = doProjectRequest(t, "admin-key", "", http.MethodGet, base+"/runs", nil)
+	if err := json.Unmarshal(body, &list); err != nil || len(list.Runs) != 1 || list.Runs[0].Project != types.DefaultProject {
+		t.Errorf("default listing: expected only the default run, got %s", body)
+	}
+	_, body = doProjectRequest(t, "admin-key", "team-a", http.MethodGet, base+"/runs", nil)
+	if err := json.Unmarshal(body, &list); err != nil || len(list.Runs) != 1 || list.Runs[0].RunID != created.RunID {
+		t.Errorf("admin listing team-a: expected team-a's run, got %s", body)
+	}
+
+	resp, body = doProjectRequest(t, teamKey, types.DefaultProject, http.MethodGet, base+"/runs", nil)
+	if resp.StatusCode != http.StatusForbidden || !bytes.Contains(body, []byte("PROJECT_FORBIDDEN")) {
+		t.Errorf("team-a key in default: expected PROJECT_FORBIDDEN, got %d: %s", resp.StatusCode, body)
+	}
+	if resp, _ := doProjectRequest(t, "admin-key", "Team A", http.MethodGet, base+"/runs", nil); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid project: expected 400, got %d", resp.StatusCode)
+	}
+
+	// Runs of other projects are not found, wherever the caller acts.
+	var defaultRunID string
+	for _, run := range rm.ListRuns() {
+		if run.Project == types.DefaultProject {
+			defaultRunID = run.RunID
+		}
+	}
+	if resp, _ := doProjectRequest(t, teamKey, "", http.MethodGet, base+"/runs/"+defaultRunID, nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("team-a key reading a default run: expected 404, got %d", resp.StatusCode)
+	}
+	if resp, _ := doProjectRequest(t, "admin-key", "", http.MethodGet, base+"/runs/"+created.RunID, nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("admin reading a team-a run: expected 200, got %d", resp.StatusCode)
+	}
+
+	if resp, _ := doProjectRequest(t, "admin-key", "", http.MethodPost, base+"/api-keys", CreateAPIKeyRequest{Name: "bad", Scopes: []auth.Scope{auth.ScopeRunsRead}, Projects: []string{"Team A"}}); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("key with an invalid project: expected 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestProjects_WorkersAndRateLimit(t *testing.T) {
+	rm := newTestRunManager(t)
+	server, cleanup, err := StartTestServer(rm)
+	if err != nil {
+		t.Fatalf("Failed to start test server: %v", err)
+	}
+	defer cleanup()
+	server.SetRegistry(scheduler.NewRegistry())
+	server.SetProjectRateLimiterConfig(&RateLimiterConfig{Enabled: true, RequestsPerSecond: 0.001, BurstSize: 3})
+	base := server.URL()
+
+	register := func(project string) {
+		resp, body := doProjectRequest(t, "", "", http.MethodPost, base+"/workers/register", RegisterWorkerRequest{
+			HostInfo: types.HostInfo{Hostname: "host-" + project},
+			Capacity: types.WorkerCapacity{MaxVUs: 10},
+			Project:  project,
+		})
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("register %q: expected 201, got %d: %s", project, resp.StatusCode, body)
+		}
+	}
+	register("")
+	register("team-b")
+
+	var workers ListWorkersResponse
+	_, body := doProjectRequest(t, "", "team-a", http.MethodGet, base+"/workers", nil)
+	if err := json.Unmarshal(body, &workers); err != nil || len(workers.Workers) != 1 || workers.Workers[0].Project != "" {
+		t.Errorf("team-a listing: expected only the shared worker, got %s", body)
+	}
+
+	// Each project gets a burst of three requests; team-a has used one.
+	if resp, _ := doProjectRequest(t, "", "team-a", http.MethodGet, base+"/workers", nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("team-a second request: expected 200, got %d", resp.StatusCode)
+	}
+	doProjectRequest(t, "", "team-a", http.MethodGet, base+"/workers", nil)
+	resp, body := doProjectRequest(t, "", "team-a", http.MethodGet, base+"/workers", nil)
+	if resp.StatusCode != http.StatusTooManyRequests || !bytes.Contains(body, []byte("PROJECT_RATE_LIMIT_EXCEEDED")) {
+		t.Errorf("team-a over its limit: expected 429, got %d: %s", resp.StatusCode, body)
+	}
+	if resp, _ := doProjectRequest(t, "", "team-b", http.MethodGet, base+"/workers", nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("team-b: expected its own limit, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,189 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bc-dunia/mcpdrill/internal/auth"
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/runmanager"
+	"github.com/bc-dunia/mcpdrill/internal/validation"
+)
+
+// handleScenarios serves GET /scenarios and POST /scenarios. Like every
+// scenario endpoint, it acts on the scenarios of the request's project.
+func (s *Server) handleScenarios(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.writeJSON(w, http.StatusOK, &ListScenariosResponse{Scenarios: s.runManager.ListScenarios(requestProject(r))})
+	case http.MethodPost:
+		s.handleSaveScenario(w, r, "")
+	default:
+		s.writeMethodNotAllowed(w, r.Method, "GET, POST")
+	}
+}
+
+// routeScenarios serves /scenarios/{name}, /scenarios/{name}/versions and
+// /scenarios/{name}/versions/{version}.
+func (s *Server) routeScenarios(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/scenarios/"), "/"), "/")
+	name := parts[0]
+
+	switch {
+	case name == "":
+		s.handleScenarios(w, r)
+	case len(parts) == 1:
+		switch r.Method {
+		case http.MethodGet:
+			s.handleGetScenarioVersion(w, r, name, 0)
+		case http.MethodPut:
+			s.handleSaveScenario(w, r, name)
+		case http.MethodDelete:
+			s.handleDeleteScenario(w, r, name)
+		default:
+			s.writeMethodNotAllowed(w, r.Method, "GET, PUT, DELETE")
+		}
+	case len(parts) == 2 && parts[1] == "versions":
+		if r.Method != http.MethodGet {
+			s.writeMethodNotAllowed(w, r.Method, "GET")
+			return
+		}
+		versions, err := s.runManager.GetScenarioVersions(requestProject(r), name)
+		if err != nil {
+			s.handleScenarioError(w, name, err)
+			return
+		}
+		s.writeJSON(w, http.StatusOK, &ListScenarioVersionsResponse{Name: name, Versions: versions})
+	case len(parts) == 3 && parts[1] == "versions":
+		if r.Method != http.MethodGet {
+			s.writeMethodNotAllowed(w, r.Method, "GET")
+			return
+		}
+		version, err := strconv.Atoi(parts[2])
+		if err != nil || version < 1 {
+			s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(
+				"Version must be a positive integer",
+				map[string]interface{}{"version": parts[2]},
+			))
+			return
+		}
+		s.handleGetScenarioVersion(w, r, name, version)
+	default:
+		s.writeError(w, http.StatusNotFound, &ErrorResponse{
+			ErrorType:    ErrorTypeNotFound,
+			ErrorCode:    "ENDPOINT_NOT_FOUND",
+			ErrorMessage: "Endpoint not found",
+			Retryable:    false,
+			Details:      map[string]interface{}{"path": r.URL.Path},
+		})
+	}
+}
+
+func (s *Server) handleGetScenarioVersion(w http.ResponseWriter, r *http.Request, name string, version int) {
+	v, err := s.runManager.GetScenarioVersion(requestProject(r), name, version)
+	if err != nil {
+		s.handleScenarioError(w, name, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, v)
+}
+
+// handleSaveScenario saves a new version of a scenario, named by the path
+// for PUT or by the body for POST.
+func (s *Server) handleSaveScenario(w http.ResponseWriter, r *http.Request, name string) {
+	if !s.requireOperatorRole(w, r) {
+		return
+	}
+
+	var req SaveScenarioRequest
+	if err := json.NewDecoder(limitedBody(w, r)).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(
+			"Invalid JSON request body",
+			map[string]interface{}{"parse_error": err.Error()},
+		))
+		return
+	}
+	if name == "" {
+		name = req.Name
+	} else if req.Name != "" && req.Name != name {
+		s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(
+			"Name in body does not match the path",
+			map[string]interface{}{"name": req.Name, "path_name": name},
+		))
+		return
+	}
+	if len(req.Config) == 0 {
+		s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(
+			"Config is required",
+			map[string]interface{}{"field": "config"},
+		))
+		return
+	}
+	if req.Actor == "" {
+		req.Actor = "api"
+	}
+
+	version, created, err := s.runManager.SaveScenario(requestProject(r), name, req.Config, req.Description, req.Actor)
+	if err != nil {
+		s.handleScenarioError(w, name, err)
+		return
+	}
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	s.writeJSON(w, status, &SaveScenarioResponse{ScenarioVersion: version, Created: created})
+}
+
+func (s *Server) handleDeleteScenario(w http.ResponseWriter, r *http.Request, name string) {
+	if !s.requireOperatorRole(w, r) {
+		return
+	}
+	if err := s.runManager.DeleteScenario(requestProject(r), name); err != nil {
+		s.handleScenarioError(w, name, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleScenarioError maps scenario library errors to responses.
+func (s *Server) handleScenarioError(w http.ResponseWriter, name string, err error) {
+	var validationErr *validation.ValidationError
+	switch {
+	case errors.Is(err, runmanager.ErrScenarioNotFound):
+		s.writeError(w, http.StatusNotFound, &ErrorResponse{
+			ErrorType:    ErrorTypeNotFound,
+			ErrorCode:    ErrorCodeScenarioNotFound,
+			ErrorMessage: "Scenario not found",
+			Retryable:    false,
+			Details:      map[string]interface{}{"name": name},
+		})
+	case errors.Is(err, runmanager.ErrInvalidScenarioName):
+		s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(err.Error(), map[string]interface{}{"name": name}))
+	case errors.As(err, &validationErr):
+		s.writeError(w, http.StatusBadRequest, NewValidationErrorResponse(validationErr.Report))
+	case runmanager.IsInvalidArgument(err):
+		s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(err.Error(), map[string]interface{}{"name": name}))
+	default:
+		s.writeError(w, http.StatusInternalServerError, NewInternalErrorResponse(err.Error()))
+	}
+}
+
+// requireOperatorRole writes a 403 and returns false unless the caller has
+// the operator or admin role. It always passes when auth is disabled.
+func (s *Server) requireOperatorRole(w http.ResponseWriter, r *http.Request) bool {
+	if s.authConfig == nil || s.authConfig.Mode == auth.AuthModeNone {
+		return true
+	}
+	if auth.HasAnyRole(r.Context(), auth.RoleAdmin, auth.RoleOperator) {
+		return true
+	}
+	s.writeError(w, http.StatusForbidden, &ErrorResponse{
+		ErrorType:    ErrorTypeForbidden,
+		ErrorCode:    "INSUFFICIENT_PERMISSIONS",
+		ErrorMessage: "This action requires operator or admin role",
+	})
+	return false
+}
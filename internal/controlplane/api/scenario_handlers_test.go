@@ -0,0 +1,95 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/runmanager"
+)
+
+func doScenarioRequest(t *testing.T, method, url string, body interface{}) (*http.Response, []byte) {
+	t.Helper()
+	var reader io.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewReader(data)
+	}
+	req, _ := http.NewRequest(method, url, reader)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	return resp, data
+}
+
+func TestScenarios_CRUDAndCreateRun(t *testing.T) {
+	rm := newTestRunManager(t)
+	server, cleanup, err := StartTestServer(rm)
+	if err != nil {
+		t.Fatalf("Failed to start test server: %v", err)
+	}
+	defer cleanup()
+	base := server.URL()
+
+	config := loadValidConfig(t)
+	resp, data := doScenarioRequest(t, http.MethodPost, base+"/scenarios", SaveScenarioRequest{Name: "checkout", Config: config, Actor: "alice"})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", resp.StatusCode, data)
+	}
+
+	// Saving the same config again adds no version.
+	resp, data = doScenarioRequest(t, http.MethodPut, base+"/scenarios/checkout", SaveScenarioRequest{Config: config})
+	var saved SaveScenarioResponse
+	json.Unmarshal(data, &saved)
+	if resp.StatusCode != http.StatusOK || saved.Created || saved.Version != 1 {
+		t.Fatalf("expected the identical config to be deduped, got %d: %s", resp.StatusCode, data)
+	}
+
+	resp, data = doScenarioRequest(t, http.MethodGet, base+"/scenarios", nil)
+	var list ListScenariosResponse
+	json.Unmarshal(data, &list)
+	if resp.StatusCode != http.StatusOK || len(list.Scenarios) != 1 || list.Scenarios[0].Name != "checkout" {
+		t.Fatalf("unexpected scenario list %d: %s", resp.StatusCode, data)
+	}
+
+	resp, data = doScenarioRequest(t, http.MethodGet, base+"/scenarios/checkout/versions/1", nil)
+	var version runmanager.ScenarioVersion
+	json.Unmarshal(data, &version)
+	if resp.StatusCode != http.StatusOK || len(version.Config) == 0 {
+		t.Fatalf("expected version 1 with its config, got %d: %s", resp.StatusCode, data)
+	}
+
+	resp, data = doScenarioRequest(t, http.MethodPost, base+"/runs", CreateRunRequest{
+		ScenarioRef: &runmanager.ScenarioRef{Name: "checkout"},
+		Overrides:   json.RawMessage(`{"scenario_id":"scn_from_library"}`),
+	})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201 creating a run from the scenario, got %d: %s", resp.StatusCode, data)
+	}
+	var created CreateRunResponse
+	json.Unmarshal(data, &created)
+	run, err := rm.GetRun(created.RunID)
+	if err != nil || run.ScenarioID != "scn_from_library" || run.ScenarioRef == nil || run.ScenarioRef.Version != 1 {
+		t.Errorf("expected the run to come from checkout v1 with overrides, got %+v, %v", run, err)
+	}
+
+	resp, _ = doScenarioRequest(t, http.MethodPost, base+"/runs", CreateRunRequest{ScenarioRef: &runmanager.ScenarioRef{Name: "missing"}})
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for a missing scenario, got %d", resp.StatusCode)
+	}
+
+	resp, _ = doScenarioRequest(t, http.MethodDelete, base+"/scenarios/checkout", nil)
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("expected 204 deleting the scenario, got %d", resp.StatusCode)
+	}
+	resp, _ = doScenarioRequest(t, http.MethodGet, base+"/scenarios/checkout", nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 after delete, got %d", resp.StatusCode)
+	}
+}
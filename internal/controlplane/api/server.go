@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"log"
 	"net"
@@ -10,7 +11,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/bc-dunia/mcpdrill/internal/artifacts"
 	"github.com/bc-dunia/mcpdrill/internal/auth"
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/audit"
 	"github.com/bc-dunia/mcpdrill/internal/controlplane/runmanager"
 	"github.com/bc-dunia/mcpdrill/internal/controlplane/scheduler"
 	"github.com/bc-dunia/mcpdrill/internal/metrics"
@@ -48,13 +51,21 @@ type Server struct {
 	authConfig                     *auth.Config
 	authMiddleware                 *auth.Middleware
 	allowPrivateNets               bool
-	workerTokens                   map[string]string
+	workerTokens                   map[string]*workerCredential
+	workerTokenTTL                 time.Duration
 	workerAuthEnabled              bool
+	workerChannelEnabled           bool
+	workerChannels                 map[string]chan struct{}
 	redactAssignmentSecrets        bool
+	warnDuplicateRuns              bool
 	rateLimiter                    *rateLimiter
 	rateLimiterConfig              *RateLimiterConfig
+	projectRateLimiter             *rateLimiter
 	agentStore                     *AgentStore
+	artifactStore                  artifacts.Store
 	agentAuthConfig                *AgentAuthConfig
+	auditLog                       *audit.Log
+	tlsConfig                      *tls.Config
 	stopCh                         chan struct{}
 }
 
@@ -66,6 +77,8 @@ func NewServer(addr string, rm *runmanager.RunManager) *Server {
 		rateLimiterConfig:              DefaultRateLimiterConfig(),
 		maxPendingAssignmentsPerWorker: defaultMaxPendingAssignmentsPerWorker,
 		workerAuthEnabled:              true,
+		workerChannelEnabled:           true,
+		auditLog:                       audit.NewLog(audit.DefaultCapacity),
 	}
 }
 
@@ -101,6 +114,30 @@ func (s *Server) SetWorkerAuthEnabled(enabled bool) {
 	s.workerAuthEnabled = enabled
 }
 
+// SetWorkerChannelEnabled controls whether workers are offered the WebSocket
+// control channel at registration. Workers that are not fall back to polling.
+func (s *Server) SetWorkerChannelEnabled(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.workerChannelEnabled = enabled
+}
+
+// SetTLSConfig makes the server serve HTTPS with config, which must carry
+// a certificate. Must be called before Start.
+func (s *Server) SetTLSConfig(config *tls.Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tlsConfig = config
+}
+
+// SetWarnDuplicateRuns controls whether creating a run warns of runs of the
+// identical config that are in progress.
+func (s *Server) SetWarnDuplicateRuns(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.warnDuplicateRuns = enabled
+}
+
 // SetRedactAssignmentSecrets controls whether assignment responses redact sensitive values.
 func (s *Server) SetRedactAssignmentSecrets(enabled bool) {
 	s.mu.Lock()
@@ -137,6 +174,13 @@ func (s *Server) addAssignmentLocked(workerID string, assignment types.WorkerAss
 
 	queue = append(queue, assignment)
 	s.pendingAssignments[workerID] = queue
+
+	if notify, ok := s.workerChannels[workerID]; ok {
+		select {
+		case notify <- struct{}{}:
+		default:
+		}
+	}
 }
 
 func (s *Server) requeueExpiredPendingAcks(now time.Time, timeout time.Duration) int {
@@ -302,14 +346,25 @@ func (s *Server) Start() error {
 
 	mux.HandleFunc("/runs", s.rateLimitMiddleware(s.rbacMiddleware(http.HandlerFunc(s.handleCreateRun))).ServeHTTP)
 	mux.HandleFunc("/runs/", s.rateLimitMiddleware(s.rbacMiddleware(http.HandlerFunc(s.routeRuns))).ServeHTTP)
+	mux.HandleFunc("/scenarios", s.rateLimitMiddleware(s.rbacMiddleware(http.HandlerFunc(s.handleScenarios))).ServeHTTP)
+	mux.HandleFunc("/scenarios/", s.rateLimitMiddleware(s.rbacMiddleware(http.HandlerFunc(s.routeScenarios))).ServeHTTP)
 	mux.HandleFunc("/workers", s.rateLimitMiddleware(s.rbacMiddleware(http.HandlerFunc(s.handleListWorkers))).ServeHTTP)
 	mux.HandleFunc("/workers/register", s.rateLimitMiddleware(s.rbacMiddleware(http.HandlerFunc(s.handleRegisterWorker))).ServeHTTP)
+	mux.HandleFunc("/workers/capacity", s.rateLimitMiddleware(s.rbacMiddleware(http.HandlerFunc(s.handleCapacity))).ServeHTTP)
 	mux.HandleFunc("/workers/", s.rateLimitMiddleware(s.rbacMiddleware(http.HandlerFunc(s.routeWorkers))).ServeHTTP)
+	mux.HandleFunc("/api-keys", s.rateLimitMiddleware(s.rbacMiddleware(http.HandlerFunc(s.handleAPIKeys))).ServeHTTP)
+	mux.HandleFunc("/api-keys/", s.rateLimitMiddleware(s.rbacMiddleware(http.HandlerFunc(s.routeAPIKeys))).ServeHTTP)
+	mux.HandleFunc("/audit", s.rateLimitMiddleware(s.rbacMiddleware(http.HandlerFunc(s.handleAudit))).ServeHTTP)
+	mux.HandleFunc("/audit/", s.rateLimitMiddleware(s.rbacMiddleware(http.HandlerFunc(s.routeAudit))).ServeHTTP)
 	mux.HandleFunc("/agents/v1/register", s.rateLimitMiddleware(s.agentAuthMiddleware(http.HandlerFunc(s.handleAgentRegister))).ServeHTTP)
 	mux.HandleFunc("/agents/v1/metrics", s.rateLimitMiddleware(s.agentAuthMiddleware(http.HandlerFunc(s.handleAgentMetrics))).ServeHTTP)
+	mux.HandleFunc("/agents/v1/logs", s.rateLimitMiddleware(s.agentAuthMiddleware(http.HandlerFunc(s.handleAgentLogs))).ServeHTTP)
 	mux.HandleFunc("/agents", s.rateLimitMiddleware(s.rbacMiddleware(http.HandlerFunc(s.handleListAgents))).ServeHTTP)
 	mux.HandleFunc("/agents/", s.rateLimitMiddleware(s.rbacMiddleware(http.HandlerFunc(s.routeAgents))).ServeHTTP)
 	mux.HandleFunc("/healthz", s.handleHealthz)
+	mux.HandleFunc("/{$}", s.handleConsole)
+	mux.HandleFunc("/console", s.handleConsole)
+	mux.HandleFunc(consolePrefix, s.handleConsole)
 	mux.HandleFunc("/readyz", s.handleReadyz)
 	mux.HandleFunc("/metrics", s.handleMetrics)
 	mux.HandleFunc("/discover-tools", s.rateLimitMiddleware(s.rbacMiddleware(http.HandlerFunc(s.handleDiscoverTools))).ServeHTTP)
@@ -335,6 +390,9 @@ func (s *Server) Start() error {
 	if err != nil {
 		return fmt.Errorf("failed to listen: %w", err)
 	}
+	if s.tlsConfig != nil {
+		listener = tls.NewListener(listener, s.tlsConfig)
+	}
 	s.listener = listener
 
 	s.server = &http.Server{
@@ -413,7 +471,13 @@ func (s *Server) Addr() string {
 }
 
 func (s *Server) URL() string {
-	return fmt.Sprintf("http://%s", s.Addr())
+	s.mu.Lock()
+	scheme := "http"
+	if s.tlsConfig != nil {
+		scheme = "https"
+	}
+	s.mu.Unlock()
+	return fmt.Sprintf("%s://%s", scheme, s.Addr())
 }
 
 func isLoopbackBindAddr(addr string) bool {
@@ -457,14 +521,49 @@ func (s *Server) routeRuns(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if strings.Contains(path, "/compare/") {
+		runA, runB, _ := strings.Cut(path, "/compare/")
+		if !s.canAccessRun(r, runA) || !s.canAccessRun(r, runB) {
+			s.writeError(w, http.StatusNotFound, NewNotFoundErrorResponse(runA+", "+runB))
+			return
+		}
 		s.handleCompareRuns(w, r)
 		return
 	}
 
 	parts := strings.Split(path, "/")
-	runID := parts[0]
+	// Custom methods follow the run id after a colon, as in {id}:abort.
+	runID, method, hasMethod := strings.Cut(parts[0], ":")
+	if !s.canAccessRun(r, runID) {
+		s.writeError(w, http.StatusNotFound, NewNotFoundErrorResponse(runID))
+		return
+	}
+
+	if hasMethod {
+		if len(parts) == 1 {
+			switch method {
+			case "abort":
+				s.handleAbortRun(w, r, runID)
+				return
+			case "pause", "resume":
+				s.handlePauseRun(w, r, runID, method == "resume")
+				return
+			}
+		}
+		s.writeError(w, http.StatusNotFound, &ErrorResponse{
+			ErrorType:    ErrorTypeNotFound,
+			ErrorCode:    "ENDPOINT_NOT_FOUND",
+			ErrorMessage: "Endpoint not found",
+			Retryable:    false,
+			Details:      map[string]interface{}{"path": r.URL.Path},
+		})
+		return
+	}
 
 	if len(parts) == 1 {
+		if r.Method == http.MethodDelete {
+			s.handleDeleteRun(w, r, runID)
+			return
+		}
 		s.handleGetRun(w, r, runID)
 		return
 	}
@@ -481,14 +580,47 @@ func (s *Server) routeRuns(w http.ResponseWriter, r *http.Request) {
 		s.handleEmergencyStop(w, r, runID)
 	case "clone":
 		s.handleCloneRun(w, r, runID)
+	case "op-mix":
+		s.handleOpMix(w, r, runID)
+	case "tags":
+		s.handleRunTags(w, r, runID)
 	case "events":
-		s.handleStreamEvents(w, r, runID)
+		switch {
+		case len(parts) < 3 || parts[2] == "" || parts[2] == "stream":
+			s.handleStreamEvents(w, r, runID)
+		case parts[2] == "replay":
+			s.handleReplayEvents(w, r, runID)
+		default:
+			s.writeError(w, http.StatusNotFound, &ErrorResponse{
+				ErrorType:    ErrorTypeNotFound,
+				ErrorCode:    "ENDPOINT_NOT_FOUND",
+				ErrorMessage: "Endpoint not found",
+				Retryable:    false,
+				Details:      map[string]interface{}{"path": r.URL.Path},
+			})
+		}
 	case "logs":
 		s.handleGetLogs(w, r, runID)
+	case "artifacts":
+		s.handleArtifacts(w, r, runID, parts[2:])
 	case "metrics":
-		s.handleGetRunMetrics(w, r, runID)
+		if len(parts) >= 3 && parts[2] == "stream" {
+			s.handleStreamMetrics(w, r, runID)
+		} else {
+			s.handleGetRunMetrics(w, r, runID)
+		}
+	case "dashboard":
+		asset := ""
+		if len(parts) >= 3 {
+			asset = strings.Join(parts[2:], "/")
+		}
+		s.handleDashboard(w, r, runID, asset)
 	case "stability":
 		s.handleGetRunStability(w, r, runID)
+	case "stickiness":
+		s.handleGetRunStickiness(w, r, runID)
+	case "timeseries":
+		s.handleGetTimeSeries(w, r, runID)
 	case "server-metrics":
 		s.handleGetServerMetrics(w, r, runID)
 	case "errors":
@@ -543,6 +675,12 @@ func (s *Server) routeWorkers(w http.ResponseWriter, r *http.Request) {
 		s.handleWorkerHeartbeat(w, r, workerID)
 	case "telemetry":
 		s.handleWorkerTelemetry(w, r, workerID)
+	case "channel":
+		s.handleWorkerChannel(w, r, workerID)
+	case "target-profile":
+		s.handleWorkerTargetProfile(w, r, workerID)
+	case "conformance-report":
+		s.handleWorkerConformanceReport(w, r, workerID)
 	case "assignments":
 		if len(parts) == 3 && parts[2] == "ack" {
 			s.handleAckAssignments(w, r, workerID)
@@ -572,9 +710,95 @@ func (s *Server) routeWorkers(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) rbacMiddleware(next http.Handler) http.Handler {
 	if s.authMiddleware != nil {
-		return s.authMiddleware.Handler(next)
+		return s.auditMiddleware(s.authMiddleware.Handler(s.authorize(s.projectMiddleware(next))))
 	}
-	return s.getAuthMiddleware().Handler(next)
+	return s.auditMiddleware(s.getAuthMiddleware().Handler(s.authorize(s.projectMiddleware(next))))
+}
+
+// authorize rejects callers without one of the roles requiredRoles asks
+// for. Handlers that need more, such as admin for discovery, check it
+// themselves.
+func (s *Server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		noteAuditUser(r)
+		if s.authConfig == nil || s.authConfig.Mode == auth.AuthModeNone {
+			next.ServeHTTP(w, r)
+			return
+		}
+		roles := requiredRoles(r)
+		if !auth.HasAnyRole(r.Context(), roles...) {
+			message := "This action requires operator or admin role"
+			if len(roles) == 1 && roles[0] == auth.RoleAdmin {
+				message = "This action requires admin role"
+			}
+			s.writeError(w, http.StatusForbidden, &ErrorResponse{
+				ErrorType:    ErrorTypeForbidden,
+				ErrorCode:    "INSUFFICIENT_PERMISSIONS",
+				ErrorMessage: message,
+			})
+			return
+		}
+		if scope := requiredScope(r); !auth.GetUserFromContext(r.Context()).HasScope(scope) {
+			s.writeError(w, http.StatusForbidden, &ErrorResponse{
+				ErrorType:    ErrorTypeForbidden,
+				ErrorCode:    "INSUFFICIENT_SCOPE",
+				ErrorMessage: fmt.Sprintf("This API key lacks the %s scope", scope),
+				Details:      map[string]interface{}{"required_scope": string(scope)},
+			})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requiredRoles returns the roles, one of which a caller needs for r.
+// Viewers may read runs, events, workers and reports and validate configs;
+// anything that changes state, like starting or stopping runs or
+// registering workers, needs operator. Managing API keys and reading the
+// audit log need admin, which implies every role.
+func requiredRoles(r *http.Request) []auth.Role {
+	if isAdminPath(r.URL.Path) {
+		return []auth.Role{auth.RoleAdmin}
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return []auth.Role{auth.RoleViewer, auth.RoleOperator}
+	}
+	if strings.HasSuffix(r.URL.Path, "/validate") {
+		return []auth.Role{auth.RoleViewer, auth.RoleOperator}
+	}
+	return []auth.Role{auth.RoleOperator}
+}
+
+// requiredScope returns the scope a managed API key needs for r. Workers
+// need workers:register for registering and every call they make after it.
+func requiredScope(r *http.Request) auth.Scope {
+	path := r.URL.Path
+	switch {
+	case isAdminPath(path):
+		return auth.ScopeAdmin
+	case path == "/workers/register":
+		return auth.ScopeWorkersRegister
+	case strings.HasPrefix(path, "/workers/") && strings.Contains(strings.TrimPrefix(path, "/workers/"), "/"):
+		return auth.ScopeWorkersRegister
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return auth.ScopeRunsRead
+	}
+	if strings.HasSuffix(path, "/validate") {
+		return auth.ScopeRunsRead
+	}
+	return auth.ScopeRunsWrite
+}
+
+func isAdminPath(path string) bool {
+	for _, prefix := range []string{"/api-keys", "/audit"} {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
 }
 
 func (s *Server) rateLimitMiddleware(next http.Handler) http.Handler {
@@ -639,6 +863,18 @@ func (s *Server) isWorkerAuthEnabled() bool {
 	return s.workerAuthEnabled
 }
 
+func (s *Server) isWorkerChannelEnabled() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.workerChannelEnabled
+}
+
+func (s *Server) shouldWarnDuplicateRuns() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.warnDuplicateRuns
+}
+
 func (s *Server) shouldRedactAssignmentSecrets() bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
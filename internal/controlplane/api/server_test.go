@@ -13,7 +13,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/bc-dunia/mcpdrill/internal/auth"
 	"github.com/bc-dunia/mcpdrill/internal/controlplane/runmanager"
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/scheduler"
+	"github.com/bc-dunia/mcpdrill/internal/types"
 	"github.com/bc-dunia/mcpdrill/internal/validation"
 )
 
@@ -74,6 +77,47 @@ func TestCreateRun_Success(t *testing.T) {
 	}
 }
 
+func TestCreateRun_DuplicateConfig(t *testing.T) {
+	rm := newTestRunManager(t)
+	server, cleanup, err := StartTestServer(rm)
+	if err != nil {
+		t.Fatalf("Failed to start test server: %v", err)
+	}
+	defer cleanup()
+	server.SetWarnDuplicateRuns(true)
+
+	create := func() CreateRunResponse {
+		t.Helper()
+		body, _ := json.Marshal(CreateRunRequest{Config: loadValidConfig(t)})
+		resp, err := http.Post(server.URL()+"/runs", "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		var result CreateRunResponse
+		if resp.StatusCode != http.StatusCreated || json.NewDecoder(resp.Body).Decode(&result) != nil {
+			t.Fatalf("create run returned %d", resp.StatusCode)
+		}
+		return result
+	}
+	first := create()
+	if second := create(); len(second.Warnings) != 0 {
+		t.Errorf("expected no warnings while the first run has not started, got %v", second.Warnings)
+	}
+	if err := rm.StartRun(first.RunID, "test"); err != nil {
+		t.Fatalf("StartRun failed: %v", err)
+	}
+	third := create()
+	if len(third.Warnings) != 1 || !strings.Contains(third.Warnings[0], first.RunID) {
+		t.Errorf("expected a warning naming %s, got %v", first.RunID, third.Warnings)
+	}
+
+	run, _ := rm.GetRun(third.RunID)
+	if len(run.PreviousRunIDs) != 2 || run.PreviousRunIDs[1] != first.RunID {
+		t.Errorf("expected the earlier runs of the config to be linked, got %v", run.PreviousRunIDs)
+	}
+}
+
 func TestCreateRun_InvalidJSON(t *testing.T) {
 	rm := newTestRunManager(t)
 	server, cleanup, err := StartTestServer(rm)
@@ -162,6 +206,119 @@ func TestCreateRun_ValidationFailure(t *testing.T) {
 	}
 }
 
+func loadValidYAMLConfig(t *testing.T) string {
+	t.Helper()
+	data, err := os.ReadFile("../../../testdata/fixtures/valid/minimal_preflight_baseline_ramp.yaml")
+	if err != nil {
+		t.Fatalf("failed to load valid YAML config: %v", err)
+	}
+	return string(data)
+}
+
+func TestCreateRun_YAML(t *testing.T) {
+	rm := newTestRunManager(t)
+	server, cleanup, err := StartTestServer(rm)
+	if err != nil {
+		t.Fatalf("Failed to start test server: %v", err)
+	}
+	defer cleanup()
+
+	resp, err := http.Post(server.URL()+"/runs?actor=yaml-user", "application/yaml", strings.NewReader(loadValidYAMLConfig(t)))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		respBody, _ := io.ReadAll(resp.Body)
+		t.Fatalf("expected status 201, got %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var result CreateRunResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	view, err := rm.GetRun(result.RunID)
+	if err != nil {
+		t.Fatalf("GetRun failed: %v", err)
+	}
+	if view.ScenarioID != "scn_minimal_test" {
+		t.Errorf("expected scenario_id scn_minimal_test, got %s", view.ScenarioID)
+	}
+}
+
+func TestCreateRun_YAMLValidationFailure(t *testing.T) {
+	rm := newTestRunManager(t)
+	server, cleanup, err := StartTestServer(rm)
+	if err != nil {
+		t.Fatalf("Failed to start test server: %v", err)
+	}
+	defer cleanup()
+
+	config := strings.Replace(loadValidYAMLConfig(t), "target_vus: 50", "target_vus: -5", 1)
+	resp, err := http.Post(server.URL()+"/runs", "application/x-yaml; charset=utf-8", strings.NewReader(config))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", resp.StatusCode)
+	}
+
+	var errResp ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+		t.Fatalf("failed to decode error response: %v", err)
+	}
+	if errResp.ErrorCode != ErrorCodeValidationFailed {
+		t.Fatalf("expected error_code %s, got %s", ErrorCodeValidationFailed, errResp.ErrorCode)
+	}
+
+	errs, _ := errResp.Details["errors"].([]interface{})
+	for _, e := range errs {
+		issue := e.(map[string]interface{})
+		if issue["json_pointer"] != "/stages/1/load/target_vus" {
+			continue
+		}
+		if issue["yaml_path"] != "stages[1].load.target_vus" {
+			t.Errorf("expected yaml_path stages[1].load.target_vus, got %v", issue["yaml_path"])
+		}
+		if line, _ := issue["line"].(float64); line == 0 {
+			t.Errorf("expected a line number, got %v", issue["line"])
+		}
+		return
+	}
+	t.Fatalf("no error for /stages/1/load/target_vus in %v", errResp.Details)
+}
+
+func TestCreateRun_InvalidYAML(t *testing.T) {
+	rm := newTestRunManager(t)
+	server, cleanup, err := StartTestServer(rm)
+	if err != nil {
+		t.Fatalf("Failed to start test server: %v", err)
+	}
+	defer cleanup()
+
+	resp, err := http.Post(server.URL()+"/runs", "text/yaml", strings.NewReader("stages: [1, 2"))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", resp.StatusCode)
+	}
+
+	var errResp ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+		t.Fatalf("failed to decode error response: %v", err)
+	}
+	if errResp.ErrorType != ErrorTypeInvalidArgument {
+		t.Errorf("expected error_type %s, got %s", ErrorTypeInvalidArgument, errResp.ErrorType)
+	}
+}
+
 func TestCreateRun_MethodNotAllowed(t *testing.T) {
 	rm := newTestRunManager(t)
 	server, cleanup, err := StartTestServer(rm)
@@ -212,6 +369,78 @@ func TestListRuns_Success(t *testing.T) {
 	}
 }
 
+func TestListRuns_FiltersAndTags(t *testing.T) {
+	rm := newTestRunManager(t)
+	server, cleanup, err := StartTestServer(rm)
+	if err != nil {
+		t.Fatalf("Failed to start test server: %v", err)
+	}
+	defer cleanup()
+
+	send := func(method, path string, body interface{}) (int, []byte) {
+		t.Helper()
+		data, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, server.URL()+path, bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		respBody, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, respBody
+	}
+	create := func(tags map[string]string) string {
+		t.Helper()
+		status, body := send(http.MethodPost, "/runs", CreateRunRequest{Config: loadValidConfig(t), Tags: tags})
+		var created CreateRunResponse
+		if status != http.StatusCreated || json.Unmarshal(body, &created) != nil {
+			t.Fatalf("create run returned %d: %s", status, body)
+		}
+		return created.RunID
+	}
+	nightly := create(map[string]string{"schedule": "nightly"})
+	create(nil)
+
+	if status, body := send(http.MethodPost, "/runs", CreateRunRequest{Config: loadValidConfig(t), Tags: map[string]string{"a:b": "c"}}); status != http.StatusBadRequest || !strings.Contains(string(body), `"field":"tags"`) {
+		t.Errorf("expected 400 for an invalid tag key, got %d: %s", status, body)
+	}
+
+	list := func(query string) ListRunsResponse {
+		t.Helper()
+		status, body := send(http.MethodGet, "/runs?"+query, nil)
+		var result ListRunsResponse
+		if status != http.StatusOK || json.Unmarshal(body, &result) != nil {
+			t.Fatalf("list runs returned %d: %s", status, body)
+		}
+		return result
+	}
+	if got := list("tag=schedule:nightly&target_host=staging-gateway.example.com&state=created"); got.Total != 1 || got.Runs[0].RunID != nightly {
+		t.Errorf("expected only the nightly run, got %+v", got)
+	}
+	if got := list("limit=1&order=asc"); got.Total != 2 || len(got.Runs) != 1 || got.Runs[0].RunID != nightly || got.Limit != 1 {
+		t.Errorf("expected the first of 2 runs, got %+v", got)
+	}
+	for _, query := range []string{"state=sideways", "sort=name", "limit=0", "since_ms=yesterday", "tag=:x"} {
+		if status, _ := send(http.MethodGet, "/runs?"+query, nil); status != http.StatusBadRequest {
+			t.Errorf("expected 400 for %s, got %d", query, status)
+		}
+	}
+
+	status, body := send(http.MethodPatch, "/runs/"+nightly+"/tags", map[string]interface{}{"tags": map[string]interface{}{"schedule": nil, "env": "staging"}})
+	var tags RunTagsResponse
+	if status != http.StatusOK || json.Unmarshal(body, &tags) != nil || len(tags.Tags) != 1 || tags.Tags["env"] != "staging" {
+		t.Errorf("PATCH tags returned %d: %s", status, body)
+	}
+	status, body = send(http.MethodPut, "/runs/"+nightly+"/tags", map[string]interface{}{"tags": map[string]string{}})
+	if status != http.StatusOK || !strings.Contains(string(body), `"tags":{}`) {
+		t.Errorf("PUT tags returned %d: %s", status, body)
+	}
+	if status, _ := send(http.MethodGet, "/runs/run_missing/tags", nil); status != http.StatusNotFound {
+		t.Errorf("expected 404 for the tags of a missing run, got %d", status)
+	}
+}
+
 func TestValidateConfig_Success(t *testing.T) {
 	rm := newTestRunManager(t)
 	server, cleanup, err := StartTestServer(rm)
@@ -382,6 +611,50 @@ func TestStartRun_InvalidState(t *testing.T) {
 	}
 }
 
+func TestStartRun_Parameters(t *testing.T) {
+	rm := newTestRunManager(t)
+	server, cleanup, err := StartTestServer(rm)
+	if err != nil {
+		t.Fatalf("Failed to start test server: %v", err)
+	}
+	defer cleanup()
+
+	config := loadValidConfig(t)
+	runID, err := rm.CreateRun(config, "test")
+	if err != nil {
+		t.Fatalf("failed to create run: %v", err)
+	}
+
+	body, _ := json.Marshal(StartRunRequest{Parameters: &runmanager.RunParameters{TargetURL: "http://localhost/mcp"}})
+	resp, err := http.Post(server.URL()+"/runs/"+runID+"/start", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for a parameterized config that fails validation, got %d", resp.StatusCode)
+	}
+
+	body, _ = json.Marshal(StartRunRequest{Parameters: &runmanager.RunParameters{
+		TargetURL:    "https://preprod-gateway.example.com/mcp",
+		VUMultiplier: 0.5,
+	}})
+	resp, err = http.Post(server.URL()+"/runs/"+runID+"/start", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		t.Fatalf("expected status 200, got %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	run, err := rm.GetRun(runID)
+	if err != nil || run.Parameters == nil || run.Parameters.VUMultiplier != 0.5 {
+		t.Errorf("expected the run to record its parameters, got %+v, %v", run, err)
+	}
+}
+
 func TestStopRun_Drain(t *testing.T) {
 	rm := newTestRunManager(t)
 	server, cleanup, err := StartTestServer(rm)
@@ -505,6 +778,183 @@ func TestStopRun_DefaultMode(t *testing.T) {
 	}
 }
 
+func TestOpMix_GetAndUpdate(t *testing.T) {
+	rm := newTestRunManager(t)
+	server, cleanup, err := StartTestServer(rm)
+	if err != nil {
+		t.Fatalf("Failed to start test server: %v", err)
+	}
+	defer cleanup()
+
+	config := loadValidConfig(t)
+	runID, _ := rm.CreateRun(config, "test")
+	rm.StartRun(runID, "test")
+
+	resp, err := http.Get(server.URL() + "/runs/" + runID + "/op-mix")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	var current OpMixResponse
+	json.NewDecoder(resp.Body).Decode(&current)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+	if current.OpMixView == nil || current.Revision != 0 || len(current.OpMix) == 0 {
+		t.Fatalf("expected configured op mix at revision 0, got %+v", current.OpMixView)
+	}
+
+	reqBody := UpdateOpMixRequest{
+		Weights: []runmanager.OpMixWeight{{Operation: "tools/list", Weight: 25}},
+		Actor:   "test-user",
+	}
+	body, _ := json.Marshal(reqBody)
+
+	resp, err = http.Post(server.URL()+"/runs/"+runID+"/op-mix", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		t.Fatalf("expected status 200, got %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var updated OpMixResponse
+	if err := json.NewDecoder(resp.Body).Decode(&updated); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if updated.Revision != 1 {
+		t.Errorf("expected revision 1, got %d", updated.Revision)
+	}
+	for _, entry := range updated.OpMix {
+		if entry.Operation == "tools/list" && entry.Weight != 25 {
+			t.Errorf("expected tools/list weight 25, got %d", entry.Weight)
+		}
+	}
+}
+
+func TestOpMix_InvalidWeights(t *testing.T) {
+	rm := newTestRunManager(t)
+	server, cleanup, err := StartTestServer(rm)
+	if err != nil {
+		t.Fatalf("Failed to start test server: %v", err)
+	}
+	defer cleanup()
+
+	config := loadValidConfig(t)
+	runID, _ := rm.CreateRun(config, "test")
+	rm.StartRun(runID, "test")
+
+	reqBody := UpdateOpMixRequest{
+		Weights: []runmanager.OpMixWeight{{Operation: "prompts/get", Weight: 5}},
+	}
+	body, _ := json.Marshal(reqBody)
+
+	resp, err := http.Post(server.URL()+"/runs/"+runID+"/op-mix", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", resp.StatusCode)
+	}
+
+	var errResp ErrorResponse
+	json.NewDecoder(resp.Body).Decode(&errResp)
+	if errResp.ErrorCode != ErrorCodeInvalidRequest {
+		t.Errorf("expected error code %s, got %s", ErrorCodeInvalidRequest, errResp.ErrorCode)
+	}
+}
+
+func TestOpMix_RunNotRunning(t *testing.T) {
+	rm := newTestRunManager(t)
+	server, cleanup, err := StartTestServer(rm)
+	if err != nil {
+		t.Fatalf("Failed to start test server: %v", err)
+	}
+	defer cleanup()
+
+	config := loadValidConfig(t)
+	runID, _ := rm.CreateRun(config, "test")
+
+	body, _ := json.Marshal(UpdateOpMixRequest{
+		Weights: []runmanager.OpMixWeight{{Operation: "tools/list", Weight: 5}},
+	})
+
+	resp, err := http.Post(server.URL()+"/runs/"+runID+"/op-mix", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected status 409, got %d", resp.StatusCode)
+	}
+}
+
+func TestGetRunStickiness(t *testing.T) {
+	rm := newTestRunManager(t)
+	server, cleanup, err := StartTestServer(rm)
+	if err != nil {
+		t.Fatalf("Failed to start test server: %v", err)
+	}
+	defer cleanup()
+
+	var config map[string]interface{}
+	json.Unmarshal(loadValidConfig(t), &config)
+	config["target"].(map[string]interface{})["backend_id_header"] = "X-Backend-Id"
+	configBytes, _ := json.Marshal(config)
+
+	runID, err := rm.CreateRun(configBytes, "test")
+	if err != nil {
+		t.Fatalf("failed to create run: %v", err)
+	}
+
+	store := NewTelemetryStore()
+	server.SetTelemetryStore(store)
+	store.AddTelemetryBatch(runID, TelemetryBatchRequest{
+		Operations: []types.OperationOutcome{
+			{TimestampMs: 1000, Operation: "ping", SessionID: "sess_1", BackendID: "be-1", OK: true},
+			{TimestampMs: 1100, Operation: "ping", SessionID: "sess_1", BackendID: "be-2", OK: true},
+		},
+	})
+
+	resp, err := http.Get(server.URL() + "/runs/" + runID + "/stickiness?include_sessions=true")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		t.Fatalf("expected status 200, got %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var result StickinessResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if result.BackendHeader != "X-Backend-Id" {
+		t.Errorf("expected backend header X-Backend-Id, got %q", result.BackendHeader)
+	}
+	if result.StickinessMetrics == nil || result.RebalancedSessions != 1 || len(result.Sessions) != 1 {
+		t.Errorf("expected one rebalanced session, got %+v", result.StickinessMetrics)
+	}
+
+	notFound, err := http.Get(server.URL() + "/runs/run_0000000000009999/stickiness")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	notFound.Body.Close()
+	if notFound.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status 404 for unknown run, got %d", notFound.StatusCode)
+	}
+}
+
 func TestEmergencyStop_Success(t *testing.T) {
 	rm := newTestRunManager(t)
 	server, cleanup, err := StartTestServer(rm)
@@ -563,6 +1013,134 @@ func TestEmergencyStop_NotFound(t *testing.T) {
 	}
 }
 
+func TestAbortRun(t *testing.T) {
+	rm := newTestRunManager(t)
+	server, cleanup, err := StartTestServer(rm)
+	if err != nil {
+		t.Fatalf("Failed to start test server: %v", err)
+	}
+	defer cleanup()
+
+	runID, _ := rm.CreateRun(loadValidConfig(t), "test")
+	rm.StartRun(runID, "test")
+
+	body, _ := json.Marshal(AbortRunRequest{Actor: "test-user", Reason: "misconfigured"})
+	resp, err := http.Post(server.URL()+"/runs/"+runID+":abort", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		t.Fatalf("expected status 200, got %d: %s", resp.StatusCode, string(respBody))
+	}
+	var result AbortRunResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if result.State != string(runmanager.RunStateAborted) || result.AbortedBy != "test-user" {
+		t.Errorf("expected the run aborted by test-user, got %+v", result)
+	}
+
+	for path, want := range map[string]int{
+		"/runs/" + runID + ":abort":      http.StatusConflict,
+		"/runs/nonexistent:abort":        http.StatusNotFound,
+		"/runs/" + runID + ":rewind":     http.StatusNotFound,
+		"/runs/" + runID + ":abort/more": http.StatusNotFound,
+	} {
+		resp, err := http.Post(server.URL()+path, "application/json", nil)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("POST %s: expected status %d, got %d", path, want, resp.StatusCode)
+		}
+	}
+}
+
+func TestDeleteRun(t *testing.T) {
+	rm := newTestRunManager(t)
+	server, cleanup, err := StartTestServer(rm)
+	if err != nil {
+		t.Fatalf("Failed to start test server: %v", err)
+	}
+	defer cleanup()
+
+	telemetryStore := NewTelemetryStore()
+	server.SetTelemetryStore(telemetryStore)
+	rm.SetTelemetryStore(telemetryStore)
+	runID, _ := rm.CreateRun(loadValidConfig(t), "test")
+	telemetryStore.AddTelemetryBatch(runID, TelemetryBatchRequest{Operations: []types.OperationOutcome{{Operation: "tools/call", OK: true}}})
+
+	deleteRun := func() int {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodDelete, server.URL()+"/runs/"+runID, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if status := deleteRun(); status != http.StatusConflict {
+		t.Errorf("expected 409 for a run that has not ended, got %d", status)
+	}
+	if err := rm.AbortRun(runID, "test", ""); err != nil {
+		t.Fatalf("AbortRun failed: %v", err)
+	}
+	if status := deleteRun(); status != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", status)
+	}
+	if status := deleteRun(); status != http.StatusNotFound {
+		t.Errorf("expected 404 for a deleted run, got %d", status)
+	}
+	if telemetryStore.RunCount() != 0 {
+		t.Error("expected the run's telemetry to be deleted")
+	}
+}
+
+func TestPauseAndResumeRun(t *testing.T) {
+	rm := newTestRunManager(t)
+	server, cleanup, err := StartTestServer(rm)
+	if err != nil {
+		t.Fatalf("Failed to start test server: %v", err)
+	}
+	defer cleanup()
+
+	runID, _ := rm.CreateRun(loadValidConfig(t), "test")
+	rm.StartRun(runID, "test")
+	if err := rm.TransitionToBaseline(runID, "test"); err != nil {
+		t.Fatalf("TransitionToBaseline failed: %v", err)
+	}
+
+	post := func(method string, want int) PauseRunResponse {
+		t.Helper()
+		body, _ := json.Marshal(PauseRunRequest{Actor: "test-user", Reason: "maintenance"})
+		resp, err := http.Post(server.URL()+"/runs/"+runID+":"+method, "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != want {
+			respBody, _ := io.ReadAll(resp.Body)
+			t.Fatalf("POST :%s: expected status %d, got %d: %s", method, want, resp.StatusCode, string(respBody))
+		}
+		var result PauseRunResponse
+		_ = json.NewDecoder(resp.Body).Decode(&result)
+		return result
+	}
+
+	if result := post("pause", http.StatusOK); result.State != string(runmanager.RunStatePaused) {
+		t.Errorf("expected the run paused, got %+v", result)
+	}
+	post("pause", http.StatusConflict)
+	if result := post("resume", http.StatusOK); result.State != string(runmanager.RunStateBaselineRunning) {
+		t.Errorf("expected the run back in baseline, got %+v", result)
+	}
+	post("resume", http.StatusConflict)
+}
+
 func TestGetRun_Success(t *testing.T) {
 	rm := newTestRunManager(t)
 	server, cleanup, err := StartTestServer(rm)
@@ -1211,3 +1789,55 @@ func TestEmergencyStop_InvalidJSON(t *testing.T) {
 		t.Fatalf("expected status 400, got %d", resp.StatusCode)
 	}
 }
+
+func TestRBAC_EndpointRoles(t *testing.T) {
+	rm := newTestRunManager(t)
+	server := NewServer("127.0.0.1:0", rm)
+	server.SetAuthConfig(&auth.Config{
+		Mode:    auth.AuthModeAPIKey,
+		APIKeys: []string{"viewer-key", "operator-key"},
+		APIKeyRoles: map[string][]auth.Role{
+			"viewer-key": {auth.RoleViewer},
+		},
+	})
+	server.SetRegistry(scheduler.NewRegistry())
+	server.SetWorkerAuthEnabled(false)
+	if err := server.Start(); err != nil {
+		t.Fatalf("failed to start server: %v", err)
+	}
+	defer server.Shutdown(context.Background())
+
+	runID, err := rm.CreateRun(loadValidConfig(t), "test")
+	if err != nil {
+		t.Fatalf("failed to create run: %v", err)
+	}
+
+	tests := []struct {
+		key    string
+		method string
+		path   string
+		body   []byte
+		want   int
+	}{
+		{"viewer-key", http.MethodGet, "/runs", nil, http.StatusOK},
+		{"viewer-key", http.MethodGet, "/runs/" + runID + "/events", nil, http.StatusOK},
+		{"viewer-key", http.MethodPost, "/runs", []byte(`{"config":{}}`), http.StatusForbidden},
+		{"viewer-key", http.MethodPost, "/runs/" + runID + "/start", nil, http.StatusForbidden},
+		{"viewer-key", http.MethodPost, "/runs/" + runID + "/stop", nil, http.StatusForbidden},
+		{"viewer-key", http.MethodPost, "/workers/register", []byte(`{}`), http.StatusForbidden},
+		// Operators get past authorization; the run has not started yet.
+		{"operator-key", http.MethodPost, "/runs/" + runID + "/stop", []byte(`{"mode":"drain"}`), http.StatusConflict},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, server.URL()+tt.path, bytes.NewReader(tt.body))
+		req.Header.Set("X-API-Key", tt.key)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s failed: %v", tt.method, tt.path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.want {
+			t.Errorf("%s %s as %s: expected %d, got %d", tt.method, tt.path, tt.key, tt.want, resp.StatusCode)
+		}
+	}
+}
@@ -577,3 +577,165 @@ func TestSSE_LastEventIDPrecedesInvalidCursorParam(t *testing.T) {
 		t.Fatalf("Expected 200 with valid Last-Event-ID precedence, got %d", resp.StatusCode)
 	}
 }
+
+func TestSSE_TypesFilter(t *testing.T) {
+	rm := newTestRunManager(t)
+	server, cleanup, err := StartTestServer(rm)
+	if err != nil {
+		t.Fatalf("Failed to start test server: %v", err)
+	}
+	defer cleanup()
+
+	config := loadValidConfig(t)
+	runID, _ := rm.CreateRun(config, "test")
+	_ = rm.StartRun(runID, "test")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL()+"/runs/"+runID+"/events?types=state_transition", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	received := 0
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		var event runmanager.RunEvent
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event); err != nil {
+			t.Fatalf("Failed to parse event JSON: %v", err)
+		}
+		if event.Type != runmanager.EventTypeStateTransition {
+			t.Errorf("Expected only STATE_TRANSITION events, got %s", event.Type)
+		}
+		received++
+		if received >= 2 {
+			break
+		}
+	}
+
+	if received == 0 {
+		t.Error("Expected to receive STATE_TRANSITION events")
+	}
+}
+
+func TestSSE_UnknownEventType(t *testing.T) {
+	rm := newTestRunManager(t)
+	server, cleanup, err := StartTestServer(rm)
+	if err != nil {
+		t.Fatalf("Failed to start test server: %v", err)
+	}
+	defer cleanup()
+
+	config := loadValidConfig(t)
+	runID, _ := rm.CreateRun(config, "test")
+
+	resp, err := http.Get(server.URL() + "/runs/" + runID + "/events?types=DECISION,NOT_A_TYPE")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected 400, got %d", resp.StatusCode)
+	}
+
+	var errResp ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+		t.Fatalf("Failed to decode error response: %v", err)
+	}
+	if errResp.ErrorCode != "INVALID_EVENT_TYPES" {
+		t.Errorf("Expected INVALID_EVENT_TYPES, got %s", errResp.ErrorCode)
+	}
+}
+
+func TestSSE_ReplayThenLive(t *testing.T) {
+	rm := newTestRunManager(t)
+	server, cleanup, err := StartTestServer(rm)
+	if err != nil {
+		t.Fatalf("Failed to start test server: %v", err)
+	}
+	defer cleanup()
+
+	config := loadValidConfig(t)
+	runID, _ := rm.CreateRun(config, "test")
+	_ = rm.StartRun(runID, "test")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Resume headers are ignored by replay.
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL()+"/runs/"+runID+"/events/replay", nil)
+	req.Header.Set("Last-Event-ID", "evt_ffffffff")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	var replayedIDs []string
+	var marker map[string]interface{}
+	eventName := ""
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			eventName = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "id: "):
+			replayedIDs = append(replayedIDs, strings.TrimPrefix(line, "id: "))
+		case strings.HasPrefix(line, "data: ") && eventName == "replay_complete":
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &marker); err != nil {
+				t.Fatalf("Failed to parse replay_complete data: %v", err)
+			}
+		}
+		if marker != nil {
+			break
+		}
+	}
+
+	if marker == nil {
+		t.Fatal("Expected replay_complete marker")
+	}
+	if len(replayedIDs) == 0 {
+		t.Fatal("Expected historical events before the marker")
+	}
+	if int(marker["replayed_events"].(float64)) != len(replayedIDs) {
+		t.Errorf("Expected replayed_events %d, got %v", len(replayedIDs), marker["replayed_events"])
+	}
+	if marker["last_event_id"] != replayedIDs[len(replayedIDs)-1] {
+		t.Errorf("Expected last_event_id %s, got %v", replayedIDs[len(replayedIDs)-1], marker["last_event_id"])
+	}
+	if idx := rm.FindEventIndex(runID, replayedIDs[0]); idx != 0 {
+		t.Errorf("Expected replay to start at the first event, got index %d", idx)
+	}
+
+	if err := rm.RequestStop(runID, runmanager.StopModeImmediate, "test"); err != nil {
+		t.Fatalf("RequestStop failed: %v", err)
+	}
+
+	liveReceived := false
+	for scanner.Scan() {
+		if strings.HasPrefix(scanner.Text(), "id: ") {
+			liveReceived = true
+			break
+		}
+	}
+	if !liveReceived {
+		t.Error("Expected live events after the replay")
+	}
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/runmanager"
+)
+
+// aggregate folds op into the run's aggregate and its stage's.
+// Must be called with lock held.
+func (rt *runTelemetry) aggregate(op analysis.OperationResult) {
+	rt.aggregator("").AddOperation(op)
+	if op.Stage != "" {
+		rt.aggregator(op.Stage).AddOperation(op)
+	}
+}
+
+func (rt *runTelemetry) aggregator(key string) *analysis.Aggregator {
+	return aggregatorFor(rt.aggregates, key)
+}
+
+// summaryAggregator returns the aggregate worker summaries fold into: the
+// run's own if the store aggregates operations, otherwise one kept for
+// summaries alone. Must be called with lock held.
+func (rt *runTelemetry) summaryAggregator(key string) *analysis.Aggregator {
+	if rt.aggregates != nil {
+		return rt.aggregator(key)
+	}
+	if rt.summaries == nil {
+		rt.summaries = make(map[string]*analysis.Aggregator)
+	}
+	return aggregatorFor(rt.summaries, key)
+}
+
+func aggregatorFor(m map[string]*analysis.Aggregator, key string) *analysis.Aggregator {
+	agg := m[key]
+	if agg == nil {
+		agg = analysis.NewAggregator()
+		m[key] = agg
+	}
+	return agg
+}
+
+// discardOldest removes the n oldest in-memory operations, reusing the
+// backing array. Must be called with lock held.
+func (rt *runTelemetry) discardOldest(n int) {
+	kept := copy(rt.operations, rt.operations[n:])
+	clear(rt.operations[kept:])
+	rt.operations = rt.operations[:kept]
+}
+
+// trimOperations keeps the run's in-memory operations within
+// MaxInMemoryOpsPerRun. Once the window is full its older half is dropped
+// if the run is aggregated, and spilled to disk otherwise.
+// Must be called with lock held.
+func (ts *TelemetryStore) trimOperations(rt *runTelemetry) {
+	limit := ts.config.MaxInMemoryOpsPerRun
+	if limit <= 0 || len(rt.operations) < limit {
+		return
+	}
+	if rt.aggregates == nil {
+		ts.spillOperations(rt)
+		return
+	}
+	n := max(len(rt.operations)/2, 1)
+	rt.droppedOps += n
+	rt.discardOldest(n)
+}
+
+// MergeOperations merges the aggregate of the run's operations, or of one
+// stage's when stage is not empty, into a. It returns false if the store
+// keeps raw operations instead of aggregating them; it then merges only the
+// aggregate of the run's worker summaries, and the raw operations are left
+// to add.
+func (ts *TelemetryStore) MergeOperations(runID, stage string, a *analysis.Aggregator) (bool, error) {
+	ts.mu.RLock()
+	rt, ok := ts.runs[runID]
+	if !ok {
+		ts.mu.RUnlock()
+		return false, fmt.Errorf("telemetry not found for run: %s", runID)
+	}
+	aggregating := rt.aggregates != nil
+	agg := rt.aggregates[stage]
+	if !aggregating {
+		agg = rt.summaries[stage]
+	}
+	ts.mu.RUnlock()
+
+	if agg != nil {
+		a.Merge(agg)
+	}
+	return aggregating, nil
+}
+
+// addOperations feeds every operation of data to aggregator. It merges the
+// run's aggregate when there is one and otherwise streams spilled operations
+// back from disk. Stand-ins for summarized operations are skipped, as their
+// summaries are merged.
+func (ts *TelemetryStore) addOperations(data *runmanager.TelemetryData, aggregator *analysis.Aggregator) error {
+	if merged, err := ts.MergeOperations(data.RunID, "", aggregator); err != nil || merged {
+		return err
+	}
+	add := func(op analysis.OperationResult) error {
+		if !op.Summarized {
+			aggregator.AddOperation(op)
+		}
+		return nil
+	}
+	if data.SpilledOperations == 0 {
+		for _, op := range data.Operations {
+			_ = add(op)
+		}
+		return nil
+	}
+	return ts.ForEachOperation(data.RunID, add)
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+func TestTelemetryStore_AggregatesOperations(t *testing.T) {
+	ts := NewTelemetryStoreWithConfig(&TelemetryStoreConfig{
+		MaxOperationsPerRun:  100,
+		MaxInMemoryOpsPerRun: 4,
+		AggregateOperations:  true,
+	})
+
+	ts.AddTelemetryBatch("run_1", spillTestBatch(0, 10))
+	ts.AddTelemetryBatch("run_1", TelemetryBatchRequest{Operations: []types.OperationOutcome{
+		{TimestampMs: 2000, Operation: "tools_list", LatencyMs: 500, OK: true, Stage: "ramp"},
+	}})
+
+	data, err := ts.GetTelemetryData("run_1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(data.Operations) >= 4 {
+		t.Errorf("expected fewer than 4 operations in memory, got %d", len(data.Operations))
+	}
+	if total := data.DroppedOperations + len(data.Operations); total != 11 {
+		t.Errorf("expected 11 operations in total, got %d", total)
+	}
+	if data.SpilledOperations != 0 {
+		t.Errorf("expected no spilled operations, got %d", data.SpilledOperations)
+	}
+
+	run := analysis.NewAggregator()
+	if merged, err := ts.MergeOperations("run_1", "", run); err != nil || !merged {
+		t.Fatalf("expected run aggregate, got merged=%v err=%v", merged, err)
+	}
+	metrics := run.Compute()
+	if metrics.TotalOps != 11 || metrics.FailureOps != 4 {
+		t.Errorf("expected 11 operations with 4 failures, got %d with %d", metrics.TotalOps, metrics.FailureOps)
+	}
+	if metrics.ByTool["echo"].LatencyP95 != 9 || metrics.LatencyP99 != 500 {
+		t.Errorf("unexpected percentiles: echo p95 %d, p99 %d", metrics.ByTool["echo"].LatencyP95, metrics.LatencyP99)
+	}
+	if metrics.Checks == nil || metrics.Checks.CheckedOps != 10 {
+		t.Errorf("expected checks of 10 operations, got %+v", metrics.Checks)
+	}
+
+	ramp := analysis.NewAggregator()
+	if _, err := ts.MergeOperations("run_1", "ramp", ramp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if total := ramp.Compute().TotalOps; total != 1 {
+		t.Errorf("expected 1 ramp operation, got %d", total)
+	}
+	soak := analysis.NewAggregator()
+	if merged, _ := ts.MergeOperations("run_1", "soak", soak); !merged || soak.OperationCount() != 0 {
+		t.Errorf("expected an empty soak aggregate, got merged=%v with %d operations", merged, soak.OperationCount())
+	}
+}
+
+func TestTelemetryStore_AggregationKeepsRawWindowBelowLimit(t *testing.T) {
+	ts := NewTelemetryStoreWithConfig(&TelemetryStoreConfig{
+		MaxOperationsPerRun:  6,
+		MaxInMemoryOpsPerRun: 4,
+		AggregateOperations:  true,
+	})
+
+	ts.AddTelemetryBatch("run_1", spillTestBatch(0, 20))
+
+	if opsTrunc, _ := ts.IsTruncated("run_1"); opsTrunc {
+		t.Error("expected dropped operations not to count towards the limit")
+	}
+	data, _ := ts.GetTelemetryData("run_1")
+	if last := data.Operations[len(data.Operations)-1]; last.LatencyMs != 19 {
+		t.Errorf("expected the newest operation in memory, got latency %d", last.LatencyMs)
+	}
+}
+
+func TestTelemetryStore_MergeOperationsWithoutAggregation(t *testing.T) {
+	ts := NewTelemetryStore()
+	ts.AddTelemetryBatch("run_1", spillTestBatch(0, 3))
+
+	a := analysis.NewAggregator()
+	merged, err := ts.MergeOperations("run_1", "", a)
+	if err != nil || merged {
+		t.Errorf("expected raw operations, got merged=%v err=%v", merged, err)
+	}
+	if _, err := ts.MergeOperations("missing", "", a); err == nil {
+		t.Error("expected error for unknown run")
+	}
+}
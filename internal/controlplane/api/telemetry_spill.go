@@ -0,0 +1,239 @@
+package api
+
+import (
+	"bufio"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"sync"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+)
+
+// spillBufferSize is the write buffer in front of a spill file.
+const spillBufferSize = 1 << 20
+
+// spillFile is the append-only file holding the operations, or the
+// operation logs, of a run that no longer fit in memory. Records are
+// gob-encoded T values, oldest first.
+//
+// The file is unlinked right after it is created where the platform allows
+// it, so a crashed server leaves nothing behind; reads go through ReadAt on
+// the open descriptor.
+type spillFile[T any] struct {
+	mu       sync.Mutex
+	file     *os.File
+	buf      *bufio.Writer
+	enc      *gob.Encoder
+	count    int
+	unlinked bool
+}
+
+// newSpillFile creates a spill file in dir named after pattern, as for
+// os.CreateTemp.
+func newSpillFile[T any](dir, pattern string) (*spillFile[T], error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create spill directory: %w", err)
+	}
+	f, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create spill file: %w", err)
+	}
+	buf := bufio.NewWriterSize(f, spillBufferSize)
+	return &spillFile[T]{
+		file:     f,
+		buf:      buf,
+		enc:      gob.NewEncoder(buf),
+		unlinked: os.Remove(f.Name()) == nil,
+	}, nil
+}
+
+// append encodes records at the end of the file and returns how many were
+// written before an error, if any.
+func (s *spillFile[T]) append(records []T) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range records {
+		if err := s.enc.Encode(&records[i]); err != nil {
+			return i, err
+		}
+		s.count++
+	}
+	return len(records), nil
+}
+
+// snapshot flushes buffered records and returns a reader over the file and
+// the number of records it holds. Records appended later are not counted.
+func (s *spillFile[T]) snapshot() (io.Reader, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.buf.Flush(); err != nil {
+		return nil, 0, err
+	}
+	return io.NewSectionReader(s.file, 0, math.MaxInt64), s.count, nil
+}
+
+func (s *spillFile[T]) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.file.Close(); err != nil {
+		slog.Warn("telemetry_spill_close_failed", "file", s.file.Name(), "error", err)
+	}
+	if !s.unlinked {
+		_ = os.Remove(s.file.Name())
+	}
+}
+
+// spillOperations moves the older half of the run's in-memory operations to
+// its spill file. If the spill file cannot be written, the run keeps its
+// operations in memory. Must be called with lock held.
+func (ts *TelemetryStore) spillOperations(rt *runTelemetry) {
+	if ts.config.SpillDir == "" || rt.spillFailed {
+		return
+	}
+
+	if rt.spill == nil {
+		spill, err := newSpillFile[analysis.OperationResult](ts.config.SpillDir, "ops-*.spill")
+		if err != nil {
+			rt.spillFailed = true
+			slog.Warn("telemetry_spill_failed", "run_id", rt.runID, "error", err)
+			return
+		}
+		rt.spill = spill
+	}
+
+	n, err := rt.spill.append(rt.operations[:max(len(rt.operations)/2, 1)])
+	if err != nil {
+		rt.spillFailed = true
+		slog.Warn("telemetry_spill_failed", "run_id", rt.runID, "error", err)
+	}
+	rt.spilledOps += n
+	rt.discardOldest(n)
+}
+
+// spillLogs moves the run's older in-memory operation logs to its log spill
+// file once they outgrow MaxInMemoryOpsPerRun, keeping the newest half of
+// that many in memory; a batch may take them well past the limit. If the
+// file cannot be written, the run keeps its logs in memory. Must be called
+// with lock held, after the logs are sorted.
+func (ts *TelemetryStore) spillLogs(rt *runTelemetry) {
+	limit := ts.config.MaxInMemoryOpsPerRun
+	if limit <= 0 || len(rt.logs) < limit || ts.config.SpillDir == "" || rt.logSpillFailed {
+		return
+	}
+
+	if rt.logSpill == nil {
+		spill, err := newSpillFile[OperationLog](ts.config.SpillDir, "logs-*.spill")
+		if err != nil {
+			rt.logSpillFailed = true
+			slog.Warn("telemetry_log_spill_failed", "run_id", rt.runID, "error", err)
+			return
+		}
+		rt.logSpill = spill
+	}
+
+	n, err := rt.logSpill.append(rt.logs[:len(rt.logs)-limit/2])
+	if err != nil {
+		rt.logSpillFailed = true
+		slog.Warn("telemetry_log_spill_failed", "run_id", rt.runID, "error", err)
+	}
+	rt.spilledLogs += n
+	kept := copy(rt.logs, rt.logs[n:])
+	clear(rt.logs[kept:])
+	rt.logs = rt.logs[:kept]
+}
+
+// scanLogs calls fn for each of the run's operation logs, oldest first,
+// reading spilled ones back from disk, until fn returns false. Logs that
+// cannot be read back are skipped with a warning. Must be called with lock
+// held.
+func (rt *runTelemetry) scanLogs(fn func(*OperationLog) bool) {
+	if rt.logSpill != nil {
+		spilled, count, err := rt.logSpill.snapshot()
+		if err != nil {
+			slog.Warn("telemetry_log_spill_read_failed", "run_id", rt.runID, "error", err)
+			count = 0
+		}
+		var dec *gob.Decoder
+		if count > 0 {
+			dec = gob.NewDecoder(bufio.NewReader(spilled))
+		}
+		for i := 0; i < count; i++ {
+			var log OperationLog
+			if err := dec.Decode(&log); err != nil {
+				slog.Warn("telemetry_log_spill_read_failed", "run_id", rt.runID, "error", err)
+				break
+			}
+			if !fn(&log) {
+				return
+			}
+		}
+	}
+	for i := range rt.logs {
+		if !fn(&rt.logs[i]) {
+			return
+		}
+	}
+}
+
+// allLogs returns copies of the run's operation logs, spilled ones
+// included. Must be called with lock held.
+func (rt *runTelemetry) allLogs() []OperationLog {
+	logs := make([]OperationLog, 0, rt.spilledLogs+len(rt.logs))
+	rt.scanLogs(func(log *OperationLog) bool {
+		logs = append(logs, *log)
+		return true
+	})
+	return logs
+}
+
+// ForEachOperation calls fn for every stored operation of the run, oldest
+// first, reading spilled operations back from disk instead of loading them
+// all. It stops at the first error fn returns.
+func (ts *TelemetryStore) ForEachOperation(runID string, fn func(analysis.OperationResult) error) error {
+	ts.mu.RLock()
+	rt, ok := ts.runs[runID]
+	if !ok {
+		ts.mu.RUnlock()
+		return fmt.Errorf("telemetry not found for run: %s", runID)
+	}
+	window := make([]analysis.OperationResult, len(rt.operations))
+	copy(window, rt.operations)
+	var (
+		spilled io.Reader
+		count   int
+		err     error
+	)
+	if rt.spill != nil {
+		spilled, count, err = rt.spill.snapshot()
+	}
+	ts.mu.RUnlock()
+
+	if err != nil {
+		return fmt.Errorf("failed to read spilled operations: %w", err)
+	}
+	if spilled != nil {
+		dec := gob.NewDecoder(bufio.NewReader(spilled))
+		for i := 0; i < count; i++ {
+			var op analysis.OperationResult
+			if err := dec.Decode(&op); err != nil {
+				return fmt.Errorf("failed to read spilled operations: %w", err)
+			}
+			if err := fn(op); err != nil {
+				return err
+			}
+		}
+	}
+	for _, op := range window {
+		if err := fn(op); err != nil {
+			return err
+		}
+	}
+	return nil
+}
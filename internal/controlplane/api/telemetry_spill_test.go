@@ -0,0 +1,257 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+func spillTestBatch(from, to int) TelemetryBatchRequest {
+	batch := TelemetryBatchRequest{}
+	for i := from; i < to; i++ {
+		batch.Operations = append(batch.Operations, types.OperationOutcome{
+			TimestampMs: int64(1000 + i),
+			Operation:   "tools_call",
+			ToolName:    "echo",
+			LatencyMs:   i,
+			OK:          i%3 != 0,
+			Stage:       "baseline",
+			Checks:      []types.CheckResult{{Name: "has_content", Passed: true}},
+		})
+	}
+	return batch
+}
+
+func collectOperations(t *testing.T, ts *TelemetryStore, runID string) []analysis.OperationResult {
+	t.Helper()
+	var ops []analysis.OperationResult
+	err := ts.ForEachOperation(runID, func(op analysis.OperationResult) error {
+		ops = append(ops, op)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEachOperation failed: %v", err)
+	}
+	return ops
+}
+
+func TestTelemetryStore_SpillsOperationsToDisk(t *testing.T) {
+	ts := NewTelemetryStoreWithConfig(&TelemetryStoreConfig{
+		MaxOperationsPerRun:  100,
+		MaxLogsPerRun:        100,
+		MaxTotalRuns:         10,
+		MaxInMemoryOpsPerRun: 4,
+		SpillDir:             t.TempDir(),
+	})
+
+	ts.AddTelemetryBatch("run_1", spillTestBatch(0, 7))
+	ts.AddTelemetryBatch("run_1", spillTestBatch(7, 10))
+
+	data, err := ts.GetTelemetryData("run_1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(data.Operations) >= 4 {
+		t.Errorf("expected fewer than 4 operations in memory, got %d", len(data.Operations))
+	}
+	if total := data.SpilledOperations + len(data.Operations); total != 10 {
+		t.Errorf("expected 10 operations in total, got %d", total)
+	}
+	if last := data.Operations[len(data.Operations)-1]; last.LatencyMs != 9 {
+		t.Errorf("expected the newest operation in memory, got latency %d", last.LatencyMs)
+	}
+	if count := ts.GetOperationCount("run_1"); count != 10 {
+		t.Errorf("expected operation count 10, got %d", count)
+	}
+
+	ops := collectOperations(t, ts, "run_1")
+	if len(ops) != 10 {
+		t.Fatalf("expected 10 streamed operations, got %d", len(ops))
+	}
+	for i, op := range ops {
+		if op.LatencyMs != i || op.OK != (i%3 != 0) || op.Stage != "baseline" {
+			t.Errorf("operation %d streamed back as %+v", i, op)
+		}
+		if len(op.Checks) != 1 || op.Checks[0].Name != "has_content" {
+			t.Errorf("operation %d lost its checks: %+v", i, op.Checks)
+		}
+	}
+}
+
+func TestTelemetryStore_SpillsLogsToDisk(t *testing.T) {
+	ts := NewTelemetryStoreWithConfig(&TelemetryStoreConfig{
+		MaxLogsPerRun:        100,
+		MaxInMemoryOpsPerRun: 4,
+		SpillDir:             t.TempDir(),
+	})
+
+	ts.AddTelemetryBatch("run_1", spillTestBatch(0, 7))
+	ts.AddTelemetryBatch("run_1", spillTestBatch(7, 10))
+
+	ts.mu.RLock()
+	inMemory, spilled := len(ts.runs["run_1"].logs), ts.runs["run_1"].spilledLogs
+	ts.mu.RUnlock()
+	if inMemory >= 4 || inMemory+spilled != 10 {
+		t.Errorf("expected fewer than 4 of 10 logs in memory, got %d with %d spilled", inMemory, spilled)
+	}
+
+	var buf bytes.Buffer
+	written, err := ts.WriteOperationLog("run_1", &buf)
+	if err != nil || written != 10 {
+		t.Fatalf("expected 10 logs written, got %d: %v", written, err)
+	}
+	dec := json.NewDecoder(&buf)
+	for i := 0; i < 10; i++ {
+		var log OperationLog
+		if err := dec.Decode(&log); err != nil {
+			t.Fatalf("failed to decode log %d: %v", i, err)
+		}
+		if log.LatencyMs != i || len(log.Checks) != 1 {
+			t.Errorf("log %d written back as %+v", i, log)
+		}
+	}
+
+	if recent := ts.RecentLogs("run_1", 1002); len(recent) != 8 || recent[0].LatencyMs != 2 {
+		t.Errorf("expected 8 logs from 1002 on, got %d", len(recent))
+	}
+	if recent := ts.RecentLogs("run_1", 1009); len(recent) != 1 || recent[0].LatencyMs != 9 {
+		t.Errorf("expected the newest log, got %+v", recent)
+	}
+
+	logs, total, err := ts.QueryLogs("run_1", LogFilters{Limit: 3, Offset: 6})
+	if err != nil || total != 10 || len(logs) != 3 || logs[0].LatencyMs != 3 || logs[2].LatencyMs != 1 {
+		t.Errorf("expected logs 3 down to 1 of 10, got %d of %d: %+v", len(logs), total, logs)
+	}
+	logs, _, _ = ts.QueryLogs("run_1", LogFilters{Limit: 2, Offset: 1, Order: "asc"})
+	if len(logs) != 2 || logs[0].LatencyMs != 1 || logs[1].LatencyMs != 2 {
+		t.Errorf("expected logs 1 and 2, got %+v", logs)
+	}
+	if errorLogs, _ := ts.GetErrorLogs("run_1"); len(errorLogs) != 4 {
+		t.Errorf("expected 4 error logs, got %d", len(errorLogs))
+	}
+}
+
+func TestTelemetryStore_SpilledLogsCountTowardsLimit(t *testing.T) {
+	ts := NewTelemetryStoreWithConfig(&TelemetryStoreConfig{
+		MaxLogsPerRun:        6,
+		MaxInMemoryOpsPerRun: 2,
+		SpillDir:             t.TempDir(),
+	})
+
+	ts.AddTelemetryBatch("run_1", spillTestBatch(0, 4))
+	ts.AddTelemetryBatch("run_1", spillTestBatch(4, 10))
+
+	if written, err := ts.WriteOperationLog("run_1", io.Discard); err != nil || written != 6 {
+		t.Errorf("expected 6 logs stored, got %d: %v", written, err)
+	}
+	if _, logsTrunc := ts.IsTruncated("run_1"); !logsTrunc {
+		t.Error("expected logs to be truncated")
+	}
+}
+
+func TestTelemetryStore_SpilledOperationsCountTowardsLimit(t *testing.T) {
+	ts := NewTelemetryStoreWithConfig(&TelemetryStoreConfig{
+		MaxOperationsPerRun:  6,
+		MaxInMemoryOpsPerRun: 2,
+		SpillDir:             t.TempDir(),
+	})
+
+	ts.AddTelemetryBatch("run_1", spillTestBatch(0, 10))
+
+	if count := ts.GetOperationCount("run_1"); count != 6 {
+		t.Errorf("expected 6 operations stored, got %d", count)
+	}
+	if opsTrunc, _ := ts.IsTruncated("run_1"); !opsTrunc {
+		t.Error("expected operations to be truncated")
+	}
+	if ops := collectOperations(t, ts, "run_1"); len(ops) != 6 {
+		t.Errorf("expected 6 streamed operations, got %d", len(ops))
+	}
+}
+
+func TestTelemetryStore_DeleteRunReleasesSpill(t *testing.T) {
+	dir := t.TempDir()
+	ts := NewTelemetryStoreWithConfig(&TelemetryStoreConfig{
+		MaxInMemoryOpsPerRun: 2,
+		SpillDir:             dir,
+	})
+
+	ts.AddTelemetryBatch("run_1", spillTestBatch(0, 5))
+	ts.DeleteRun("run_1")
+
+	if err := ts.ForEachOperation("run_1", func(analysis.OperationResult) error { return nil }); err == nil {
+		t.Error("expected error for deleted run")
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read spill dir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected spill dir to be empty, found %d entries", len(entries))
+	}
+}
+
+func TestTelemetryStore_SpillFailureKeepsOperationsInMemory(t *testing.T) {
+	blocker := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	ts := NewTelemetryStoreWithConfig(&TelemetryStoreConfig{
+		MaxInMemoryOpsPerRun: 2,
+		SpillDir:             filepath.Join(blocker, "spill"),
+	})
+
+	ts.AddTelemetryBatch("run_1", spillTestBatch(0, 5))
+
+	data, err := ts.GetTelemetryData("run_1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(data.Operations) != 5 || data.SpilledOperations != 0 {
+		t.Errorf("expected 5 operations in memory, got %d (%d spilled)", len(data.Operations), data.SpilledOperations)
+	}
+}
+
+func TestServer_RunMetricsIncludeSpilledOperations(t *testing.T) {
+	rm := newTestRunManager(t)
+	server, cleanup, err := StartTestServer(rm)
+	if err != nil {
+		t.Fatalf("Failed to start test server: %v", err)
+	}
+	defer cleanup()
+
+	ts := NewTelemetryStoreWithConfig(&TelemetryStoreConfig{
+		MaxInMemoryOpsPerRun: 4,
+		SpillDir:             t.TempDir(),
+	})
+	server.SetTelemetryStore(ts)
+
+	runID, err := rm.CreateRun(loadValidConfig(t), "test")
+	if err != nil {
+		t.Fatalf("CreateRun failed: %v", err)
+	}
+	ts.AddTelemetryBatch(runID, spillTestBatch(0, 12))
+
+	resp, err := http.Get(server.URL() + "/runs/" + runID + "/metrics")
+	if err != nil {
+		t.Fatalf("GET metrics failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	var metrics RunMetricsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&metrics); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if metrics.TotalOps != 12 || metrics.FailedOps != 4 {
+		t.Errorf("expected 12 operations with 4 failures, got %d with %d", metrics.TotalOps, metrics.FailedOps)
+	}
+}
@@ -1,9 +1,14 @@
 package api
 
 import (
+	"bufio"
+	"encoding/gob"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"math"
+	"slices"
 	"sort"
 	"sync"
 	"time"
@@ -24,6 +29,23 @@ type TelemetryStoreConfig struct {
 	// MaxTotalRuns limits total runs in memory. 0 = unlimited.
 	// When exceeded, oldest runs are evicted.
 	MaxTotalRuns int
+	// MaxRunsPerProject limits the runs of one project in memory, so a busy
+	// project cannot evict every other project's telemetry. When exceeded,
+	// the project's oldest run is evicted. It needs a project resolver.
+	// 0 = unlimited.
+	MaxRunsPerProject int
+	// MaxInMemoryOpsPerRun caps the operations, and the operation logs, a
+	// run keeps in memory. Older ones beyond it spill to files in SpillDir
+	// and count towards MaxOperationsPerRun and MaxLogsPerRun. 0 or an empty
+	// SpillDir keeps everything in memory.
+	MaxInMemoryOpsPerRun int
+	// SpillDir is the directory for spilled operations and logs.
+	SpillDir string
+	// AggregateOperations folds every operation into per-run and per-stage
+	// latency histograms and counters as it arrives. Raw operations are then
+	// only kept as a window of the latest MaxInMemoryOpsPerRun, for stop
+	// conditions and soak checkpoints; older ones are dropped, not spilled.
+	AggregateOperations bool
 }
 
 // DefaultTelemetryStoreConfig returns sensible defaults.
@@ -41,22 +63,54 @@ type TelemetryStore struct {
 	config *TelemetryStoreConfig
 	// runOrder tracks insertion order for LRU eviction
 	runOrder []string
+	// resolveProject returns the project of a run, for MaxRunsPerProject.
+	resolveProject func(runID string) string
 }
 
 type runTelemetry struct {
 	runID       string
+	project     string
 	scenarioID  string
 	startTimeMs int64
 	endTimeMs   int64
 	stopReason  string
+	// operations holds the most recent operations; older ones are in spill.
 	operations  []analysis.OperationResult
-	logs        []OperationLog
-	logsSorted  bool
+	spill       *spillFile[analysis.OperationResult]
+	spilledOps  int
+	spillFailed bool
+	// logs holds the most recent operation logs; older ones are in logSpill.
+	logs           []OperationLog
+	logSpill       *spillFile[OperationLog]
+	spilledLogs    int
+	logSpillFailed bool
+	logsSorted     bool
+	// receivedOps counts every operation ingested, including truncated ones
+	receivedOps int64
 	// truncated flags indicate if data was dropped due to limits
 	operationsTruncated bool
 	logsTruncated       bool
+	// aggregates holds the run's aggregate under "" and each stage's under
+	// its name. Nil unless the store aggregates operations.
+	aggregates map[string]*analysis.Aggregator
+	// summaries holds, keyed the same way, the aggregates of the worker
+	// summaries of a run whose store keeps raw operations.
+	summaries map[string]*analysis.Aggregator
+	// droppedOps counts operations that left the in-memory window and were
+	// only kept in aggregates.
+	droppedOps int
+	// generatorWarnings holds the warnings workers raised about their own
+	// health, up to maxGeneratorWarningsPerRun.
+	generatorWarnings []analysis.GeneratorWarning
+	// timeSeries windows every operation ingested, truncated or not.
+	timeSeries *analysis.TimeSeries
 }
 
+// maxGeneratorWarningsPerRun caps the load generator warnings kept per run.
+// Workers raise one each time their shed rate changes, so a run that keeps
+// more than this is throttling throughout anyway.
+const maxGeneratorWarningsPerRun = 1000
+
 func NewTelemetryStore() *TelemetryStore {
 	return NewTelemetryStoreWithConfig(DefaultTelemetryStoreConfig())
 }
@@ -72,134 +126,390 @@ func NewTelemetryStoreWithConfig(config *TelemetryStoreConfig) *TelemetryStore {
 	}
 }
 
+// SetProjectResolver sets the function that returns the project of a run.
+func (ts *TelemetryStore) SetProjectResolver(resolve func(runID string) string) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.resolveProject = resolve
+}
+
+// newRunProject returns the project of a run the store does not hold yet.
+// It is called without the lock, as the resolver takes the run manager's.
+func (ts *TelemetryStore) newRunProject(runID string) string {
+	ts.mu.RLock()
+	resolve := ts.resolveProject
+	_, known := ts.runs[runID]
+	ts.mu.RUnlock()
+	if resolve == nil || known {
+		return ""
+	}
+	return resolve(runID)
+}
+
 func (ts *TelemetryStore) AddTelemetryBatch(runID string, batch TelemetryBatchRequest) {
+	project := ts.newRunProject(runID)
 	ts.mu.Lock()
 	defer ts.mu.Unlock()
 
-	rt := ts.getOrCreateRunTelemetry(runID)
+	rt := ts.getOrCreateRunTelemetry(runID, project)
 
 	for _, op := range batch.Operations {
-		if rt.startTimeMs == 0 || op.TimestampMs < rt.startTimeMs {
-			rt.startTimeMs = op.TimestampMs
-		}
-		if op.TimestampMs > rt.endTimeMs {
-			rt.endTimeMs = op.TimestampMs
+		ts.addOperation(rt, operationResult(op), op.TimestampMs)
+		ts.addLog(rt, op)
+	}
+	for _, summary := range batch.Summaries {
+		ts.addSummary(rt, summary)
+	}
+	for _, op := range batch.Samples {
+		ts.addLog(rt, op)
+	}
+	for _, w := range batch.GeneratorWarnings {
+		if len(rt.generatorWarnings) >= maxGeneratorWarningsPerRun {
+			break
 		}
+		rt.generatorWarnings = append(rt.generatorWarnings, analysis.GeneratorWarning{
+			WorkerID:    w.WorkerID,
+			TimestampMs: w.TimestampMs,
+			Kind:        w.Kind,
+			Resource:    w.Resource,
+			Usage:       w.Usage,
+			Limit:       w.Limit,
+			ShedRate:    w.ShedRate,
+		})
+	}
 
-		// Check operations limit
-		if ts.config.MaxOperationsPerRun > 0 && len(rt.operations) >= ts.config.MaxOperationsPerRun {
-			if !rt.operationsTruncated {
-				rt.operationsTruncated = true
-				slog.Warn("telemetry_operations_truncated",
-					"run_id", runID,
-					"limit", ts.config.MaxOperationsPerRun)
-			}
-		} else {
-			result := analysis.OperationResult{
-				Operation: op.Operation,
-				ToolName:  op.ToolName,
-				LatencyMs: op.LatencyMs,
-				OK:        op.OK,
-				ErrorType: op.ErrorType,
-				SessionID: op.SessionID,
-			}
-			rt.operations = append(rt.operations, result)
+	if !rt.logsSorted {
+		sort.Slice(rt.logs, func(i, j int) bool {
+			return rt.logs[i].TimestampMs < rt.logs[j].TimestampMs
+		})
+		rt.logsSorted = true
+	}
+	ts.spillLogs(rt)
+}
+
+// operationResult converts a worker's operation outcome for analysis.
+func operationResult(op types.OperationOutcome) analysis.OperationResult {
+	result := analysis.OperationResult{
+		Operation:    op.Operation,
+		ToolName:     op.ToolName,
+		LatencyMs:    op.LatencyMs,
+		OK:           op.OK,
+		ErrorType:    op.ErrorType,
+		SessionID:    op.SessionID,
+		Group:        op.GeneratorGroup,
+		Target:       op.Target,
+		Stage:        op.Stage,
+		WorkerID:     op.WorkerID,
+		FuzzMutation: op.FuzzMutation,
+		Attempt:      op.Attempt,
+		Warmup:       op.Warmup,
+
+		RetryAfterMs:   op.RetryAfterMs,
+		ThrottleWaitMs: op.ThrottleWaitMs,
+		ThinkTimeMs:    op.ThinkTimeMs,
+
+		OpID:         op.OpID,
+		ErrorCode:    op.ErrorCode,
+		JSONRPCCode:  op.JSONRPCErrorCode,
+		ErrorMessage: op.ErrorMessage,
+	}
+	if op.Connection != nil {
+		result.Connection = &analysis.ConnectionSample{
+			Reused:       op.Connection.Reused,
+			DNSLookup:    op.Connection.DNSLookup,
+			DNSLookupUs:  op.Connection.DNSUs,
+			DNSCoalesced: op.Connection.DNSCoalesced,
+			DNSFailed:    op.Connection.DNSFailed,
 		}
+	}
+	if op.RequestID != nil {
+		result.RequestID = &analysis.RequestIDSample{
+			Kind:             op.RequestID.Kind,
+			Duplicate:        op.RequestID.Duplicate,
+			EchoTypeMismatch: op.RequestID.EchoTypeMismatch,
+		}
+	}
+	if op.Shadow != nil {
+		result.Shadow = &analysis.ShadowSample{
+			LatencyMs:   op.Shadow.LatencyMs,
+			OK:          op.Shadow.OK,
+			ResultMatch: op.Shadow.ResultMatch,
+		}
+	}
+	if op.Workflow != nil {
+		result.Workflow = &analysis.WorkflowSample{
+			Name:   op.Workflow.Name,
+			Step:   op.Workflow.Step,
+			Index:  op.Workflow.Index,
+			Status: op.Workflow.Status,
+		}
+	}
+	if op.Chaos != nil {
+		result.Chaos = &analysis.ChaosSample{
+			DelayMs:     op.Chaos.DelayMs,
+			Reset:       op.Chaos.Reset,
+			ThrottledMs: op.Chaos.ThrottledMs,
+		}
+	}
+	if op.Cancellation != nil {
+		result.Cancellation = &analysis.CancellationSample{
+			Sent:    op.Cancellation.Sent,
+			Honored: op.Cancellation.Honored,
+		}
+	}
+	if op.Stream != nil && op.Stream.IsStreaming {
+		result.Stream = &analysis.StreamSample{
+			TimeToFirstEventMs:      op.Stream.TimeToFirstEventMs,
+			MaxEventGapMs:           op.Stream.MaxEventGapMs,
+			Progress:                op.Stream.ProgressCount,
+			TimeToFirstProgressMs:   op.Stream.TimeToFirstProgressMs,
+			ProgressTokenMismatches: op.Stream.ProgressTokenMismatches,
+			ProgressInvalid:         op.Stream.ProgressInvalid,
+			Stalled:                 op.Stream.Stalled,
+		}
+	}
+	if op.Stream != nil && len(op.Stream.ServerMessages) > 0 {
+		result.ServerMessages = &analysis.ServerMessageSample{
+			Methods:     op.Stream.ServerMessages,
+			Replies:     op.Stream.ServerReplies,
+			ReplyErrors: op.Stream.ServerReplyErrors,
+		}
+	}
+	if op.Stream != nil && op.Stream.ResumeAttempts > 0 {
+		result.StreamResume = &analysis.StreamResumeSample{
+			Attempts: op.Stream.ResumeAttempts,
+			Resumed:  op.Stream.Resumed,
+		}
+	}
+	if len(op.Checks) > 0 {
+		result.Checks = make([]analysis.CheckSample, len(op.Checks))
+		for i, c := range op.Checks {
+			result.Checks[i] = analysis.CheckSample{Name: c.Name, Passed: c.Passed}
+		}
+	}
+	return result
+}
 
-		stage := op.Stage
+// addOperation stores one operation that ran at timestampMs.
+// Must be called with lock held.
+func (ts *TelemetryStore) addOperation(rt *runTelemetry, result analysis.OperationResult, timestampMs int64) {
+	result.TimestampMs = timestampMs
+	rt.receivedOps++
+	rt.observe(timestampMs)
 
-		// Check logs limit
-		if ts.config.MaxLogsPerRun > 0 && len(rt.logs) >= ts.config.MaxLogsPerRun {
-			if !rt.logsTruncated {
-				rt.logsTruncated = true
-				slog.Warn("telemetry_logs_truncated",
-					"run_id", runID,
-					"limit", ts.config.MaxLogsPerRun)
-			}
-		} else {
-			streamCopy := op.Stream
-			if op.Stream != nil {
-				copiedStream := *op.Stream
-				streamCopy = &copiedStream
-			}
+	if rt.aggregates != nil {
+		rt.aggregate(result)
+	}
+	rt.timeSeries.Add(result)
+	ts.storeOperation(rt, result)
+}
 
-			tokenIndexCopy := op.TokenIndex
-			if op.TokenIndex != nil {
-				copiedTokenIndex := *op.TokenIndex
-				tokenIndexCopy = &copiedTokenIndex
-			}
+// observe widens the run's time range to timestampMs.
+func (rt *runTelemetry) observe(timestampMs int64) {
+	if rt.startTimeMs == 0 || timestampMs < rt.startTimeMs {
+		rt.startTimeMs = timestampMs
+	}
+	if timestampMs > rt.endTimeMs {
+		rt.endTimeMs = timestampMs
+	}
+}
 
-			log := OperationLog{
-				TimestampMs: op.TimestampMs,
-				RunID:       runID,
-				ExecutionID: op.ExecutionID,
-				Stage:       stage,
-				StageID:     op.StageID,
-				WorkerID:    op.WorkerID,
-				VUID:        op.VUID,
-				SessionID:   op.SessionID,
-				Operation:   op.Operation,
-				ToolName:    op.ToolName,
-				LatencyMs:   op.LatencyMs,
-				OK:          op.OK,
-				ErrorType:   op.ErrorType,
-				ErrorCode:   op.ErrorCode,
-				Stream:      streamCopy,
-				TokenIndex:  tokenIndexCopy,
-			}
-			rt.logs = append(rt.logs, log)
-			rt.logsSorted = rt.logsSorted && (len(rt.logs) < 2 ||
-				rt.logs[len(rt.logs)-2].TimestampMs <= log.TimestampMs)
+// storeOperation appends result to the run's operations, within
+// MaxOperationsPerRun. Must be called with lock held.
+func (ts *TelemetryStore) storeOperation(rt *runTelemetry, result analysis.OperationResult) {
+	// Check operations limit
+	if ts.config.MaxOperationsPerRun > 0 && rt.storedOperations() >= ts.config.MaxOperationsPerRun {
+		if !rt.operationsTruncated {
+			rt.operationsTruncated = true
+			slog.Warn("telemetry_operations_truncated",
+				"run_id", rt.runID,
+				"limit", ts.config.MaxOperationsPerRun)
 		}
+	} else {
+		rt.operations = append(rt.operations, result)
+		ts.trimOperations(rt)
 	}
+}
 
-	if !rt.logsSorted {
-		sort.Slice(rt.logs, func(i, j int) bool {
-			return rt.logs[i].TimestampMs < rt.logs[j].TimestampMs
-		})
-		rt.logsSorted = true
+// addLog stores the operation log of op. Must be called with lock held.
+func (ts *TelemetryStore) addLog(rt *runTelemetry, op types.OperationOutcome) {
+	// Check logs limit
+	if ts.config.MaxLogsPerRun > 0 && rt.spilledLogs+len(rt.logs) >= ts.config.MaxLogsPerRun {
+		if !rt.logsTruncated {
+			rt.logsTruncated = true
+			slog.Warn("telemetry_logs_truncated",
+				"run_id", rt.runID,
+				"limit", ts.config.MaxLogsPerRun)
+		}
+		return
+	}
+
+	streamCopy := op.Stream
+	if op.Stream != nil {
+		copiedStream := *op.Stream
+		streamCopy = &copiedStream
+	}
+
+	tokenIndexCopy := op.TokenIndex
+	if op.TokenIndex != nil {
+		copiedTokenIndex := *op.TokenIndex
+		tokenIndexCopy = &copiedTokenIndex
+	}
+
+	var requestIDCopy *types.RequestIDInfo
+	if op.RequestID != nil {
+		copiedRequestID := *op.RequestID
+		requestIDCopy = &copiedRequestID
+	}
+
+	var cancellationCopy *types.CancellationInfo
+	if op.Cancellation != nil {
+		copiedCancellation := *op.Cancellation
+		cancellationCopy = &copiedCancellation
+	}
+
+	var chaosCopy *types.ChaosInfo
+	if op.Chaos != nil {
+		copiedChaos := *op.Chaos
+		chaosCopy = &copiedChaos
+	}
+
+	var jsonrpcCodeCopy *int
+	if op.JSONRPCErrorCode != nil {
+		copiedCode := *op.JSONRPCErrorCode
+		jsonrpcCodeCopy = &copiedCode
+	}
+
+	var summaryCopy *types.ResultSummary
+	if op.ResultSummary != nil {
+		copiedSummary := *op.ResultSummary
+		summaryCopy = &copiedSummary
+	}
+
+	var workflowCopy *types.WorkflowStepInfo
+	if op.Workflow != nil {
+		copiedWorkflow := *op.Workflow
+		workflowCopy = &copiedWorkflow
+	}
+
+	log := OperationLog{
+		TimestampMs:   op.TimestampMs,
+		RunID:         rt.runID,
+		ExecutionID:   op.ExecutionID,
+		Stage:         op.Stage,
+		StageID:       op.StageID,
+		WorkerID:      op.WorkerID,
+		Group:         op.GeneratorGroup,
+		Target:        op.Target,
+		VUID:          op.VUID,
+		SessionID:     op.SessionID,
+		BackendID:     op.BackendID,
+		Operation:     op.Operation,
+		ToolName:      op.ToolName,
+		ResourceURI:   op.ResourceURI,
+		PromptName:    op.PromptName,
+		LatencyMs:     op.LatencyMs,
+		OK:            op.OK,
+		ErrorType:     op.ErrorType,
+		ErrorCode:     op.ErrorCode,
+		BytesIn:       op.BytesIn,
+		BytesOut:      op.BytesOut,
+		Stream:        streamCopy,
+		TokenIndex:    tokenIndexCopy,
+		ResultSummary: summaryCopy,
+		RequestID:     requestIDCopy,
+		Checks:        append([]types.CheckResult(nil), op.Checks...),
+		FuzzMutation:  op.FuzzMutation,
+		Cancellation:  cancellationCopy,
+		RetryAfterMs:  op.RetryAfterMs,
+		Attempt:       op.Attempt,
+		Warmup:        op.Warmup,
+		Chaos:         chaosCopy,
+		Workflow:      workflowCopy,
+
+		OpID:             op.OpID,
+		ErrorMessage:     op.ErrorMessage,
+		JSONRPCErrorCode: jsonrpcCodeCopy,
 	}
+	rt.logs = append(rt.logs, log)
+	rt.logsSorted = rt.logsSorted && (len(rt.logs) < 2 ||
+		rt.logs[len(rt.logs)-2].TimestampMs <= log.TimestampMs)
 }
 
-// evictIfNeeded removes oldest runs if MaxTotalRuns is exceeded.
+// evictIfNeeded removes oldest runs if MaxTotalRuns is exceeded, and the
+// oldest runs of project if MaxRunsPerProject is.
 // Must be called with lock held.
-func (ts *TelemetryStore) evictIfNeeded() {
-	if ts.config.MaxTotalRuns <= 0 {
+func (ts *TelemetryStore) evictIfNeeded(project string) {
+	if ts.config.MaxTotalRuns > 0 {
+		for len(ts.runs) >= ts.config.MaxTotalRuns && len(ts.runOrder) > 0 {
+			ts.evictRun(0, "max_runs_exceeded")
+		}
+	}
+
+	if ts.config.MaxRunsPerProject <= 0 || project == "" {
 		return
 	}
+	var projectRuns []int
+	for i, runID := range ts.runOrder {
+		if rt, ok := ts.runs[runID]; ok && rt.project == project {
+			projectRuns = append(projectRuns, i)
+		}
+	}
+	// Evict from the newest index down, so earlier indexes stay valid.
+	excess := len(projectRuns) - ts.config.MaxRunsPerProject + 1
+	for i := excess - 1; i >= 0; i-- {
+		ts.evictRun(projectRuns[i], "max_project_runs_exceeded")
+	}
+}
 
-	for len(ts.runs) >= ts.config.MaxTotalRuns && len(ts.runOrder) > 0 {
-		// Evict oldest run
-		oldestID := ts.runOrder[0]
-		ts.runOrder = ts.runOrder[1:]
-		delete(ts.runs, oldestID)
-		slog.Info("telemetry_run_evicted", "run_id", oldestID, "reason", "max_runs_exceeded")
+// evictRun removes the run at index i of the run order.
+// Must be called with lock held.
+func (ts *TelemetryStore) evictRun(i int, reason string) {
+	runID := ts.runOrder[i]
+	var project string
+	if rt, ok := ts.runs[runID]; ok {
+		project = rt.project
 	}
+	ts.runOrder = append(ts.runOrder[:i], ts.runOrder[i+1:]...)
+	ts.closeSpill(runID)
+	delete(ts.runs, runID)
+	slog.Info("telemetry_run_evicted", "run_id", runID, "project", project, "reason", reason)
 }
 
-// getOrCreateRunTelemetry returns the run telemetry entry, creating it if needed.
+// getOrCreateRunTelemetry returns the run telemetry entry, creating it in
+// project if needed.
 // Must be called with lock held so eviction and run order are consistent.
-func (ts *TelemetryStore) getOrCreateRunTelemetry(runID string) *runTelemetry {
+func (ts *TelemetryStore) getOrCreateRunTelemetry(runID, project string) *runTelemetry {
 	if rt, ok := ts.runs[runID]; ok {
 		return rt
 	}
 
 	// Check if we need to evict
-	ts.evictIfNeeded()
+	ts.evictIfNeeded(project)
 
 	rt := &runTelemetry{
 		runID:       runID,
+		project:     project,
 		startTimeMs: 0,
 		endTimeMs:   0,
 		operations:  make([]analysis.OperationResult, 0),
 		logs:        make([]OperationLog, 0),
 		logsSorted:  true,
+		timeSeries:  analysis.NewTimeSeries(),
+	}
+	if ts.config.AggregateOperations {
+		rt.aggregates = make(map[string]*analysis.Aggregator)
 	}
 	ts.runs[runID] = rt
 	ts.runOrder = append(ts.runOrder, runID)
 	return rt
 }
 
+// storedOperations counts the run's operations in memory and on disk.
+func (rt *runTelemetry) storedOperations() int {
+	return rt.spilledOps + len(rt.operations)
+}
+
 func (ts *TelemetryStore) AddTelemetryBatchWithContext(runID string, batch TelemetryBatchRequest, workerID, stage, stageID string, vuID string) {
 	if len(batch.Operations) > 0 {
 		operations := make([]types.OperationOutcome, len(batch.Operations))
@@ -225,10 +535,11 @@ func (ts *TelemetryStore) AddTelemetryBatchWithContext(runID string, batch Telem
 }
 
 func (ts *TelemetryStore) SetRunMetadata(runID, scenarioID, stopReason string) {
+	project := ts.newRunProject(runID)
 	ts.mu.Lock()
 	defer ts.mu.Unlock()
 
-	rt := ts.getOrCreateRunTelemetry(runID)
+	rt := ts.getOrCreateRunTelemetry(runID, project)
 
 	if scenarioID != "" {
 		rt.scenarioID = scenarioID
@@ -251,14 +562,21 @@ func (ts *TelemetryStore) GetTelemetryData(runID string) (*runmanager.TelemetryD
 	operations := make([]analysis.OperationResult, len(rt.operations))
 	copy(operations, rt.operations)
 
-	return &runmanager.TelemetryData{
-		RunID:       rt.runID,
-		ScenarioID:  rt.scenarioID,
-		StartTimeMs: rt.startTimeMs,
-		EndTimeMs:   rt.endTimeMs,
-		StopReason:  rt.stopReason,
-		Operations:  operations,
-	}, nil
+	data := &runmanager.TelemetryData{
+		RunID:             rt.runID,
+		ScenarioID:        rt.scenarioID,
+		StartTimeMs:       rt.startTimeMs,
+		EndTimeMs:         rt.endTimeMs,
+		StopReason:        rt.stopReason,
+		Operations:        operations,
+		SpilledOperations: rt.spilledOps,
+		DroppedOperations: rt.droppedOps,
+	}
+	if len(rt.generatorWarnings) > 0 {
+		data.GeneratorWarnings = make([]analysis.GeneratorWarning, len(rt.generatorWarnings))
+		copy(data.GeneratorWarnings, rt.generatorWarnings)
+	}
+	return data, nil
 }
 
 func (ts *TelemetryStore) GetOperationCount(runID string) int {
@@ -269,7 +587,119 @@ func (ts *TelemetryStore) GetOperationCount(runID string) int {
 	if !ok {
 		return 0
 	}
-	return len(rt.operations)
+	return rt.storedOperations()
+}
+
+// GetReceivedOperationCount returns the number of operations ingested for a run,
+// including operations dropped due to MaxOperationsPerRun.
+func (ts *TelemetryStore) GetReceivedOperationCount(runID string) int64 {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	rt, ok := ts.runs[runID]
+	if !ok {
+		return 0
+	}
+	return rt.receivedOps
+}
+
+// RecentLogs returns copies of the run's operation logs with TimestampMs at
+// or after fromMs, oldest first. Logs are kept sorted, so only the tail is
+// copied; spilled logs are read back only if fromMs reaches before the logs
+// in memory.
+func (ts *TelemetryStore) RecentLogs(runID string, fromMs int64) []OperationLog {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	rt, ok := ts.runs[runID]
+	if !ok {
+		return nil
+	}
+	if rt.spilledLogs > 0 && (len(rt.logs) == 0 || rt.logs[0].TimestampMs >= fromMs) {
+		var logs []OperationLog
+		rt.scanLogs(func(log *OperationLog) bool {
+			if log.TimestampMs >= fromMs {
+				logs = append(logs, *log)
+			}
+			return true
+		})
+		return logs
+	}
+	start := sort.Search(len(rt.logs), func(i int) bool {
+		return rt.logs[i].TimestampMs >= fromMs
+	})
+	logs := make([]OperationLog, len(rt.logs)-start)
+	copy(logs, rt.logs[start:])
+	return logs
+}
+
+// operationLogChunk is how many logs WriteOperationLog copies per lock, so
+// exporting a long run does not stall ingestion for other runs.
+const operationLogChunk = 10000
+
+// WriteOperationLog writes the run's operation logs to w as JSON lines,
+// oldest first, and returns how many were written. Logs are copied, or read
+// back from the run's log spill, a chunk at a time rather than all at once.
+func (ts *TelemetryStore) WriteOperationLog(runID string, w io.Writer) (int, error) {
+	enc := json.NewEncoder(w)
+	chunk := make([]OperationLog, 0, operationLogChunk)
+	var (
+		dec     *gob.Decoder
+		decoded int
+	)
+	written := 0
+	for {
+		ts.mu.RLock()
+		rt, ok := ts.runs[runID]
+		if !ok {
+			ts.mu.RUnlock()
+			return written, fmt.Errorf("telemetry not found for run: %s", runID)
+		}
+		var (
+			spilled io.Reader
+			count   int
+			err     error
+		)
+		chunk = chunk[:0]
+		if written < rt.spilledLogs {
+			spilled, count, err = rt.logSpill.snapshot()
+		} else {
+			start := written - rt.spilledLogs
+			end := min(start+operationLogChunk, len(rt.logs))
+			chunk = append(chunk, rt.logs[min(start, end):end]...)
+		}
+		ts.mu.RUnlock()
+
+		if err != nil {
+			return written, fmt.Errorf("failed to read spilled logs: %w", err)
+		}
+		if spilled != nil {
+			// Records only ever append, so one decoder reads the whole spill
+			// across snapshots.
+			if dec == nil {
+				dec = gob.NewDecoder(bufio.NewReader(spilled))
+			}
+			for ; decoded < count && len(chunk) < operationLogChunk; decoded++ {
+				var log OperationLog
+				if err := dec.Decode(&log); err != nil {
+					return written, fmt.Errorf("failed to read spilled logs: %w", err)
+				}
+				if decoded >= written {
+					chunk = append(chunk, log)
+				}
+			}
+		}
+
+		if len(chunk) == 0 {
+			return written, nil
+		}
+		for i := range chunk {
+			if err := enc.Encode(&chunk[i]); err != nil {
+				return written, err
+			}
+			written++
+		}
+	}
 }
 
 func (ts *TelemetryStore) HasRun(runID string) bool {
@@ -284,6 +714,7 @@ func (ts *TelemetryStore) DeleteRun(runID string) {
 	ts.mu.Lock()
 	defer ts.mu.Unlock()
 
+	ts.closeSpill(runID)
 	delete(ts.runs, runID)
 	// Remove from order tracking
 	for i, id := range ts.runOrder {
@@ -294,6 +725,23 @@ func (ts *TelemetryStore) DeleteRun(runID string) {
 	}
 }
 
+// closeSpill releases the run's spill files, if any. Must be called with
+// lock held.
+func (ts *TelemetryStore) closeSpill(runID string) {
+	rt, ok := ts.runs[runID]
+	if !ok {
+		return
+	}
+	if rt.spill != nil {
+		rt.spill.close()
+		rt.spill = nil
+	}
+	if rt.logSpill != nil {
+		rt.logSpill.close()
+		rt.logSpill = nil
+	}
+}
+
 func (ts *TelemetryStore) RunCount() int {
 	ts.mu.RLock()
 	defer ts.mu.RUnlock()
@@ -309,16 +757,26 @@ func (ts *TelemetryStore) QueryLogs(runID string, filters LogFilters) ([]Operati
 		return nil, 0, fmt.Errorf("run not found: %s", runID)
 	}
 
-	// Logs are appended chronologically, so rt.logs is already in ascending
+	// Logs are appended chronologically, so they are already in ascending
 	// TimestampMs order. We iterate to count matching entries and extract only
 	// the requested page — no full copy or sort needed.
 	logs := rt.logs
-	total := 0
+	inMemory := 0
 	for i := range logs {
 		if matchesFilters(logs[i], filters) {
-			total++
+			inMemory++
 		}
 	}
+	total := inMemory
+	if rt.spilledLogs > 0 {
+		total = 0
+		rt.scanLogs(func(log *OperationLog) bool {
+			if matchesFilters(*log, filters) {
+				total++
+			}
+			return true
+		})
+	}
 
 	if filters.Offset >= total {
 		ts.mu.RUnlock()
@@ -333,9 +791,9 @@ func (ts *TelemetryStore) QueryLogs(runID string, filters LogFilters) ([]Operati
 
 	result := make([]OperationLog, 0, pageSize)
 
-	if filters.Order == "asc" {
+	if filters.Order != "asc" && end <= inMemory {
 		matched := 0
-		for i := range logs {
+		for i := len(logs) - 1; i >= 0; i-- {
 			if !matchesFilters(logs[i], filters) {
 				continue
 			}
@@ -348,18 +806,25 @@ func (ts *TelemetryStore) QueryLogs(runID string, filters LogFilters) ([]Operati
 			}
 		}
 	} else {
+		// Newest first pages reaching into spilled logs are the same
+		// matches read oldest first, from the other end.
+		from := filters.Offset
+		if filters.Order != "asc" {
+			from = total - end
+		}
 		matched := 0
-		for i := len(logs) - 1; i >= 0; i-- {
-			if !matchesFilters(logs[i], filters) {
-				continue
+		rt.scanLogs(func(log *OperationLog) bool {
+			if !matchesFilters(*log, filters) {
+				return true
 			}
-			if matched >= filters.Offset && len(result) < pageSize {
-				result = append(result, logs[i])
+			if matched >= from {
+				result = append(result, *log)
 			}
 			matched++
-			if len(result) >= pageSize {
-				break
-			}
+			return len(result) < pageSize
+		})
+		if filters.Order != "asc" {
+			slices.Reverse(result)
 		}
 	}
 
@@ -388,6 +853,12 @@ func matchesFilters(log OperationLog, filters LogFilters) bool {
 	if filters.WorkerID != "" && log.WorkerID != filters.WorkerID {
 		return false
 	}
+	if filters.Group != "" && log.Group != filters.Group {
+		return false
+	}
+	if filters.Target != "" && log.Target != filters.Target {
+		return false
+	}
 	if filters.VUID != "" && log.VUID != filters.VUID {
 		return false
 	}
@@ -406,6 +877,9 @@ func matchesFilters(log OperationLog, filters LogFilters) bool {
 	if filters.ErrorCode != "" && log.ErrorCode != filters.ErrorCode {
 		return false
 	}
+	if filters.FuzzMutation != "" && log.FuzzMutation != filters.FuzzMutation {
+		return false
+	}
 	if filters.TokenIndex != nil {
 		if log.TokenIndex == nil || *log.TokenIndex != *filters.TokenIndex {
 			return false
@@ -440,8 +914,7 @@ func (ts *TelemetryStore) GetErrorLogs(runID string) ([]analysis.ErrorLog, error
 		ts.mu.RUnlock()
 		return nil, fmt.Errorf("run not found: %s", runID)
 	}
-	logs := make([]OperationLog, len(rt.logs))
-	copy(logs, rt.logs)
+	logs := rt.allLogs()
 	ts.mu.RUnlock()
 
 	errorLogs := make([]analysis.ErrorLog, 0)
@@ -452,6 +925,10 @@ func (ts *TelemetryStore) GetErrorLogs(runID string) ([]analysis.ErrorLog, error
 				Operation:   log.Operation,
 				ToolName:    log.ToolName,
 				ErrorType:   log.ErrorType,
+				ErrorCode:   log.ErrorCode,
+				JSONRPCCode: log.JSONRPCErrorCode,
+				Message:     log.ErrorMessage,
+				OpID:        log.OpID,
 			})
 		}
 	}
@@ -459,6 +936,36 @@ func (ts *TelemetryStore) GetErrorLogs(runID string) ([]analysis.ErrorLog, error
 	return errorLogs, nil
 }
 
+// GetTimeSeries returns the run's operations in windows of windowMs,
+// computed from the windows kept as operations arrive rather than from the
+// logs.
+func (ts *TelemetryStore) GetTimeSeries(runID string, windowMs int64) (*analysis.TimeSeriesReport, error) {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	rt, ok := ts.runs[runID]
+	if !ok {
+		return nil, fmt.Errorf("run not found: %s", runID)
+	}
+	return rt.timeSeries.Report(windowMs), nil
+}
+
+// TimeSeriesWindows returns, by run, the DefaultTimeSeriesWindowMs windows
+// that start from fromMs up to toMs, for the runs with operations in them.
+func (ts *TelemetryStore) TimeSeriesWindows(fromMs, toMs int64) map[string]*analysis.TimeSeriesReport {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	windows := make(map[string]*analysis.TimeSeriesReport)
+	for runID, rt := range ts.runs {
+		if rt.endTimeMs < fromMs || rt.startTimeMs >= toMs {
+			continue
+		}
+		if report := rt.timeSeries.Windows(fromMs, toMs); len(report.Total) > 0 {
+			windows[runID] = report
+		}
+	}
+	return windows
+}
+
 func (ts *TelemetryStore) GetStreamingMetrics(runID string) (*telemetry.StreamingMetrics, error) {
 	ts.mu.RLock()
 	rt, ok := ts.runs[runID]
@@ -466,8 +973,7 @@ func (ts *TelemetryStore) GetStreamingMetrics(runID string) (*telemetry.Streamin
 		ts.mu.RUnlock()
 		return nil, fmt.Errorf("run not found: %s", runID)
 	}
-	logs := make([]OperationLog, len(rt.logs))
-	copy(logs, rt.logs)
+	logs := rt.allLogs()
 	ts.mu.RUnlock()
 
 	metrics := &telemetry.StreamingMetrics{}
@@ -517,6 +1023,32 @@ func (ts *TelemetryStore) IsTruncated(runID string) (operationsTruncated, logsTr
 	return rt.operationsTruncated, rt.logsTruncated
 }
 
+// GetStickinessMetrics reports how sessions were spread across backend instances,
+// based on the backend IDs recorded in operation logs. Returns nil for unknown runs.
+func (ts *TelemetryStore) GetStickinessMetrics(runID string, includeEvents, includeSessions bool) *metrics.StickinessMetrics {
+	ts.mu.RLock()
+	rt, ok := ts.runs[runID]
+	if !ok {
+		ts.mu.RUnlock()
+		return nil
+	}
+	observations := make([]metrics.BackendObservation, 0, rt.spilledLogs+len(rt.logs))
+	rt.scanLogs(func(log *OperationLog) bool {
+		observations = append(observations, metrics.BackendObservation{
+			SessionID:   log.SessionID,
+			BackendID:   log.BackendID,
+			TimestampMs: log.TimestampMs,
+			WorkerID:    log.WorkerID,
+			Operation:   log.Operation,
+			OK:          log.OK,
+		})
+		return true
+	})
+	ts.mu.RUnlock()
+
+	return metrics.ComputeStickiness(observations, includeEvents, includeSessions)
+}
+
 func (ts *TelemetryStore) GetStabilityMetrics(runID string, includeEvents, includeTimeSeries bool) *metrics.StabilityMetrics {
 	ts.mu.RLock()
 	rt, ok := ts.runs[runID]
@@ -524,8 +1056,7 @@ func (ts *TelemetryStore) GetStabilityMetrics(runID string, includeEvents, inclu
 		ts.mu.RUnlock()
 		return nil
 	}
-	logs := make([]OperationLog, len(rt.logs))
-	copy(logs, rt.logs)
+	logs := rt.allLogs()
 	// endTimeMs is "last telemetry seen"; stopReason indicates actual completion.
 	runEnded := rt.stopReason != ""
 	ts.mu.RUnlock()
@@ -749,12 +1280,11 @@ func (ts *TelemetryStore) GetStabilityMetrics(runID string, includeEvents, inclu
 func (ts *TelemetryStore) GetMetricsTimeSeries(runID string) []metrics.MetricsTimePoint {
 	ts.mu.RLock()
 	rt, ok := ts.runs[runID]
-	if !ok || len(rt.logs) == 0 {
+	if !ok || rt.spilledLogs+len(rt.logs) == 0 {
 		ts.mu.RUnlock()
 		return nil
 	}
-	logs := make([]OperationLog, len(rt.logs))
-	copy(logs, rt.logs)
+	logs := rt.allLogs()
 	ts.mu.RUnlock()
 
 	// Calculate dynamic bucket size based on run duration
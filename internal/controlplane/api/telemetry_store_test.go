@@ -1,6 +1,9 @@
 package api
 
 import (
+	"bytes"
+	"encoding/json"
+	"strings"
 	"sync"
 	"testing"
 
@@ -173,6 +176,68 @@ func TestTelemetryStore_MultipleBatches(t *testing.T) {
 	}
 }
 
+func TestTelemetryStore_GeneratorWarnings(t *testing.T) {
+	ts := NewTelemetryStore()
+
+	ts.AddTelemetryBatch("run_0000000000000001", TelemetryBatchRequest{
+		GeneratorWarnings: []types.LoadGeneratorWarning{
+			{WorkerID: "wkr_1", TimestampMs: 1000, Kind: types.GeneratorWarningThrottle, Resource: "memory", Usage: 93, Limit: 90, ShedRate: 0.1},
+		},
+	})
+
+	data, err := ts.GetTelemetryData("run_0000000000000001")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(data.GeneratorWarnings) != 1 {
+		t.Fatalf("expected 1 generator warning, got %d", len(data.GeneratorWarnings))
+	}
+	if w := data.GeneratorWarnings[0]; w.WorkerID != "wkr_1" || w.Resource != "memory" || w.ShedRate != 0.1 {
+		t.Errorf("unexpected generator warning: %+v", w)
+	}
+}
+
+func TestTelemetryStore_WriteOperationLog(t *testing.T) {
+	ts := NewTelemetryStore()
+
+	// More than one chunk, submitted newest first.
+	total := operationLogChunk + 5
+	ops := make([]types.OperationOutcome, total)
+	for i := range ops {
+		ops[i] = types.OperationOutcome{OpID: "op", Operation: "tools_call", ToolName: "echo", LatencyMs: 10, OK: true, TimestampMs: int64(total - i)}
+	}
+	ts.AddTelemetryBatchWithContext("run_0000000000000001", TelemetryBatchRequest{Operations: ops}, "worker-a", "baseline", "stg_0000000000000001", "vu-1")
+
+	var buf bytes.Buffer
+	written, err := ts.WriteOperationLog("run_0000000000000001", &buf)
+	if err != nil {
+		t.Fatalf("WriteOperationLog failed: %v", err)
+	}
+	if written != total {
+		t.Errorf("expected %d logs written, got %d", total, written)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != total {
+		t.Fatalf("expected %d lines, got %d", total, len(lines))
+	}
+	var first, last OperationLog
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("invalid JSON line: %v", err)
+	}
+	_ = json.Unmarshal([]byte(lines[total-1]), &last)
+	if first.TimestampMs != 1 || last.TimestampMs != int64(total) {
+		t.Errorf("expected logs oldest first, got %d..%d", first.TimestampMs, last.TimestampMs)
+	}
+	if first.ToolName != "echo" || first.WorkerID != "worker-a" || first.VUID != "vu-1" || first.Stage != "baseline" {
+		t.Errorf("unexpected log line: %s", lines[0])
+	}
+
+	if _, err := ts.WriteOperationLog("nonexistent", &buf); err == nil {
+		t.Error("expected error for nonexistent run")
+	}
+}
+
 func TestTelemetryStore_RunNotFound(t *testing.T) {
 	ts := NewTelemetryStore()
 
@@ -548,6 +613,11 @@ func TestTelemetryStore_MemoryLimits(t *testing.T) {
 		t.Errorf("Expected 5 operations (limit), got %d", count)
 	}
 
+	// Received count includes truncated operations (used for max_total_ops)
+	if received := ts.GetReceivedOperationCount("run_1"); received != 7 {
+		t.Errorf("Expected 7 received operations, got %d", received)
+	}
+
 	// Check truncation flag
 	opsTrunc, logsTrunc := ts.IsTruncated("run_1")
 	if !opsTrunc {
@@ -611,6 +681,28 @@ func TestTelemetryStore_RunEviction(t *testing.T) {
 	}
 }
 
+func TestTelemetryStore_ProjectRunEviction(t *testing.T) {
+	ts := NewTelemetryStoreWithConfig(&TelemetryStoreConfig{MaxTotalRuns: 10, MaxRunsPerProject: 2})
+	projects := map[string]string{"run_a1": "team-a", "run_b1": "team-b", "run_a2": "team-a", "run_a3": "team-a"}
+	ts.SetProjectResolver(func(runID string) string { return projects[runID] })
+
+	for _, runID := range []string{"run_a1", "run_b1", "run_a2", "run_a3"} {
+		ts.AddTelemetryBatch(runID, TelemetryBatchRequest{
+			Operations: []types.OperationOutcome{{TimestampMs: 1000, Operation: "op", OK: true}},
+		})
+	}
+
+	// team-a's third run evicts its own oldest, not team-b's older run.
+	if ts.HasRun("run_a1") {
+		t.Error("Expected run_a1 to be evicted")
+	}
+	for _, runID := range []string{"run_b1", "run_a2", "run_a3"} {
+		if !ts.HasRun(runID) {
+			t.Errorf("Expected %s to exist", runID)
+		}
+	}
+}
+
 func TestTelemetryStore_DefaultConfig(t *testing.T) {
 	config := DefaultTelemetryStoreConfig()
 
@@ -698,6 +790,39 @@ func TestTelemetryStore_GetStabilityMetrics_IncludeEvents(t *testing.T) {
 	}
 }
 
+func TestTelemetryStore_GetStickinessMetrics(t *testing.T) {
+	ts := NewTelemetryStore()
+	runID := "run_0000000000000abc2"
+
+	if ts.GetStickinessMetrics(runID, false, false) != nil {
+		t.Fatal("expected nil stickiness metrics for unknown run")
+	}
+
+	// Out-of-order batch: ordering must follow timestamps, not arrival.
+	ts.AddTelemetryBatch(runID, TelemetryBatchRequest{
+		Operations: []types.OperationOutcome{
+			{TimestampMs: 1200, Operation: "ping", SessionID: "sess_1", BackendID: "be-1", OK: true},
+			{TimestampMs: 1000, Operation: "ping", SessionID: "sess_1", BackendID: "be-1", OK: true},
+			{TimestampMs: 1100, Operation: "ping", SessionID: "sess_1", BackendID: "be-2", OK: false},
+			{TimestampMs: 1050, Operation: "ping", SessionID: "sess_2", BackendID: "be-2", OK: true},
+		},
+	})
+
+	stickiness := ts.GetStickinessMetrics(runID, true, false)
+	if stickiness == nil {
+		t.Fatal("expected stickiness metrics")
+	}
+	if stickiness.SessionsObserved != 2 || stickiness.StickySessions != 1 {
+		t.Errorf("expected 2 sessions with 1 sticky, got %d with %d sticky", stickiness.SessionsObserved, stickiness.StickySessions)
+	}
+	if stickiness.RebalanceCount != 2 || stickiness.FailedRebalanceCount != 1 {
+		t.Errorf("expected 2 rebalances (1 failed), got %d (%d failed)", stickiness.RebalanceCount, stickiness.FailedRebalanceCount)
+	}
+	if len(stickiness.Events) != 2 || stickiness.Events[0].FromBackend != "be-1" || stickiness.Events[0].ToBackend != "be-2" {
+		t.Errorf("unexpected rebalance events: %+v", stickiness.Events)
+	}
+}
+
 func TestTelemetryStore_GetStabilityMetrics_TimeSeriesCountsDroppedSessionsUniquelyPerBucket(t *testing.T) {
 	ts := NewTelemetryStore()
 	runID := "run_0000000000000abc2"
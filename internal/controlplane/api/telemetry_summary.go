@@ -0,0 +1,170 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+// maxSummaryCount bounds the operations a single summary may stand for:
+// one worker running one operation a million times in one second.
+const maxSummaryCount = 1000000
+
+// maxSummarizedOpsPerBatch bounds the operations the summaries of one
+// telemetry batch may stand for together, so one batch cannot hold the
+// store's lock for long.
+const maxSummarizedOpsPerBatch = 1000000
+
+// addSummary folds a worker summary into the run's aggregates and time
+// series through its counts and latency histogram. Stop conditions and
+// soak checkpoints read operations one by one, so the summary also leaves
+// stand-ins for its operations in the run's operation window: each takes
+// the latency of its histogram bucket, and failures are spread evenly
+// across latencies and take the summary's error types in name order.
+// Must be called with lock held.
+func (ts *TelemetryStore) addSummary(rt *runTelemetry, summary types.OperationSummary) {
+	folded := analysisSummary(summary)
+	rt.summaryAggregator("").AddSummary(folded)
+	if folded.Stage != "" {
+		rt.summaryAggregator(folded.Stage).AddSummary(folded)
+	}
+	rt.timeSeries.AddSummary(folded)
+	rt.receivedOps += int64(summary.Count)
+	rt.observe(summary.BucketMs)
+
+	errorTypes := make([]string, 0, summary.Errors)
+	names := make([]string, 0, len(summary.ErrorTypes))
+	for name := range summary.ErrorTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for n := 0; n < summary.ErrorTypes[name] && len(errorTypes) < summary.Errors; n++ {
+			errorTypes = append(errorTypes, name)
+		}
+	}
+
+	result := analysis.OperationResult{
+		Operation:    summary.Operation,
+		ToolName:     summary.ToolName,
+		Group:        summary.GeneratorGroup,
+		Target:       summary.Target,
+		Stage:        summary.Stage,
+		WorkerID:     summary.WorkerID,
+		FuzzMutation: summary.FuzzMutation,
+		Attempt:      summary.Attempt,
+		Warmup:       summary.Warmup,
+		TimestampMs:  summary.BucketMs,
+		Summarized:   true,
+	}
+	i, failed := 0, 0
+	for _, bucket := range summary.Latency {
+		result.LatencyMs = bucket[0]
+		for n := 0; n < bucket[1]; n++ {
+			result.OK = (i+1)*summary.Errors/summary.Count == i*summary.Errors/summary.Count
+			result.ErrorType = ""
+			if !result.OK {
+				if failed < len(errorTypes) {
+					result.ErrorType = errorTypes[failed]
+				}
+				failed++
+			}
+			ts.storeOperation(rt, result)
+			i++
+		}
+	}
+}
+
+// analysisSummary converts a worker's operation summary for analysis.
+func analysisSummary(summary types.OperationSummary) analysis.OperationSummary {
+	folded := analysis.OperationSummary{
+		Operation:    summary.Operation,
+		ToolName:     summary.ToolName,
+		Group:        summary.GeneratorGroup,
+		Target:       summary.Target,
+		Stage:        summary.Stage,
+		WorkerID:     summary.WorkerID,
+		FuzzMutation: summary.FuzzMutation,
+		Attempt:      summary.Attempt,
+		Warmup:       summary.Warmup,
+		TimestampMs:  summary.BucketMs,
+		Count:        summary.Count,
+		Errors:       summary.Errors,
+		ErrorTypes:   summary.ErrorTypes,
+		Latency:      summary.Latency,
+	}
+	if wf := summary.Workflow; wf != nil {
+		folded.Workflow = &analysis.WorkflowSummary{
+			Name:          wf.Name,
+			Step:          wf.Step,
+			Index:         wf.Index,
+			Completed:     wf.Completed,
+			Failed:        wf.Failed,
+			ExtractFailed: wf.ExtractFailed,
+		}
+	}
+	return folded
+}
+
+// validateOperationSummary checks that a summary's counts agree with each
+// other, describing the first problem found.
+func validateOperationSummary(summary types.OperationSummary) error {
+	if summary.Count <= 0 || summary.Count > maxSummaryCount {
+		return fmt.Errorf("count must be between 1 and %d", maxSummaryCount)
+	}
+	if summary.Attempt < 0 || summary.Attempt > analysis.MaxAttempt {
+		return fmt.Errorf("attempt must be between 0 and %d", analysis.MaxAttempt)
+	}
+	if summary.Errors < 0 || summary.Errors > summary.Count {
+		return fmt.Errorf("errors must be between 0 and count")
+	}
+	typed := 0
+	for _, n := range summary.ErrorTypes {
+		if n < 0 || n > summary.Errors {
+			return fmt.Errorf("error_types counts must be between 0 and errors")
+		}
+		typed += n
+	}
+	if typed > summary.Errors {
+		return fmt.Errorf("error_types add up to more than errors")
+	}
+	total := 0
+	for i, bucket := range summary.Latency {
+		if bucket[0] < 0 || bucket[1] <= 0 || bucket[1] > summary.Count {
+			return fmt.Errorf("latency buckets need a latency of at least 0 and a count between 1 and count")
+		}
+		if i > 0 && bucket[0] <= summary.Latency[i-1][0] {
+			return fmt.Errorf("latency buckets must be in increasing order")
+		}
+		total += bucket[1]
+	}
+	if total != summary.Count {
+		return fmt.Errorf("latency bucket counts must add up to count")
+	}
+	if wf := summary.Workflow; wf != nil {
+		if wf.Name == "" || wf.Index < 0 {
+			return fmt.Errorf("workflow needs a name and an index of at least 0")
+		}
+		if wf.Completed < 0 || wf.Failed < 0 || wf.ExtractFailed < 0 {
+			return fmt.Errorf("workflow counts must be at least 0")
+		}
+		if wf.Failed > summary.Errors {
+			return fmt.Errorf("workflow failed must not exceed errors")
+		}
+		if wf.Completed+wf.ExtractFailed > summary.Count-summary.Errors {
+			return fmt.Errorf("workflow completed and extract_failed must not exceed the successful operations")
+		}
+	}
+	return nil
+}
+
+// summarizedOperations returns how many operations the summaries cover.
+func summarizedOperations(summaries []types.OperationSummary) int {
+	total := 0
+	for _, summary := range summaries {
+		total += summary.Count
+	}
+	return total
+}
@@ -0,0 +1,241 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+func summaryTestBatch() TelemetryBatchRequest {
+	return TelemetryBatchRequest{
+		RunID: "run_0000000000000001",
+		Summaries: []types.OperationSummary{{
+			BucketMs:    1000,
+			Operation:   "tools_call",
+			ToolName:    "echo",
+			ExecutionID: "exe_00000000000001",
+			Stage:       "baseline",
+			StageID:     "stg_000000000001",
+			Count:       10,
+			Errors:      2,
+			ErrorTypes:  map[string]int{"timeout": 1},
+			Latency:     [][2]int{{5, 4}, {20, 5}, {3000, 1}},
+		}},
+		Samples: []types.OperationOutcome{{
+			Operation:   "tools_call",
+			ToolName:    "echo",
+			LatencyMs:   20,
+			ErrorType:   "timeout",
+			TimestampMs: 1500,
+			ExecutionID: "exe_00000000000001",
+			Stage:       "baseline",
+			StageID:     "stg_000000000001",
+		}},
+	}
+}
+
+func TestTelemetryStore_AddsSummarizedOperations(t *testing.T) {
+	for _, aggregate := range []bool{false, true} {
+		ts := NewTelemetryStoreWithConfig(&TelemetryStoreConfig{
+			MaxOperationsPerRun:  100,
+			MaxInMemoryOpsPerRun: 100,
+			AggregateOperations:  aggregate,
+		})
+		ts.AddTelemetryBatch("run_1", summaryTestBatch())
+
+		data, err := ts.GetTelemetryData("run_1")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(data.Operations) != 10 {
+			t.Fatalf("expected 10 stand-in operations, got %d", len(data.Operations))
+		}
+		failed, timeouts, slow := 0, 0, 0
+		for _, op := range data.Operations {
+			if !op.Summarized {
+				t.Errorf("expected a stand-in, got %+v", op)
+			}
+			if !op.OK {
+				failed++
+			}
+			if op.ErrorType == "timeout" {
+				timeouts++
+			}
+			if op.LatencyMs == 3000 {
+				slow++
+			}
+			if op.Operation != "tools_call" || op.ToolName != "echo" || op.Stage != "baseline" {
+				t.Errorf("unexpected operation %+v", op)
+			}
+		}
+		if failed != 2 || timeouts != 1 || slow != 1 {
+			t.Errorf("expected 2 failures, 1 timeout and 1 slow operation, got %d, %d and %d", failed, timeouts, slow)
+		}
+		if data.StartTimeMs != 1000 || data.EndTimeMs != 1000 {
+			t.Errorf("expected time range of the summary bucket, got %d-%d", data.StartTimeMs, data.EndTimeMs)
+		}
+
+		run := analysis.NewAggregator()
+		if err := ts.addOperations(data, run); err != nil {
+			t.Fatalf("addOperations failed: %v", err)
+		}
+		metrics := run.Compute()
+		if metrics.TotalOps != 10 || metrics.FailureOps != 2 {
+			t.Errorf("aggregate=%v: expected 10 operations with 2 failures, got %d with %d", aggregate, metrics.TotalOps, metrics.FailureOps)
+		}
+		if echo := metrics.ByTool["echo"]; echo == nil || echo.LatencyP99 != 3000 {
+			t.Errorf("aggregate=%v: expected echo p99 of 3000ms, got %+v", aggregate, echo)
+		}
+		baseline := analysis.NewAggregator()
+		if _, err := ts.MergeOperations("run_1", "baseline", baseline); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if total := baseline.Compute().TotalOps; total != 10 {
+			t.Errorf("aggregate=%v: expected 10 baseline operations, got %d", aggregate, total)
+		}
+
+		logs, total, err := ts.QueryLogs("run_1", LogFilters{Limit: 10})
+		if err != nil {
+			t.Fatalf("QueryLogs failed: %v", err)
+		}
+		if total != 1 || len(logs) != 1 || logs[0].ErrorType != "timeout" {
+			t.Errorf("expected only the sampled operation as a log, got %d logs", total)
+		}
+	}
+}
+
+func TestTelemetryStore_AddsSummarizedWorkflowSteps(t *testing.T) {
+	ts := NewTelemetryStore()
+	batch := summaryTestBatch()
+	batch.Samples = nil
+	batch.Summaries[0].Workflow = &types.WorkflowStepSummary{
+		Name:          "discover",
+		Step:          "call",
+		Index:         1,
+		Completed:     5,
+		Failed:        2,
+		ExtractFailed: 1,
+	}
+	ts.AddTelemetryBatch("run_1", batch)
+
+	data, err := ts.GetTelemetryData("run_1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	run := analysis.NewAggregator()
+	if err := ts.addOperations(data, run); err != nil {
+		t.Fatalf("addOperations failed: %v", err)
+	}
+	wf := run.Compute().Workflows["discover"]
+	if wf == nil {
+		t.Fatal("expected the discover workflow reported")
+	}
+	if wf.Completed != 5 || wf.Failed != 2 || wf.ExtractFailed != 1 {
+		t.Errorf("expected 5 completed, 2 failed and 1 extract_failed runs, got %+v", wf)
+	}
+	if len(wf.Steps) != 1 || wf.Steps[0].Step != "call" || wf.Steps[0].Index != 1 || wf.Steps[0].TotalOps != 10 {
+		t.Errorf("expected 10 operations of step call, got %+v", wf.Steps)
+	}
+}
+
+func TestTelemetry_AcceptsSummaries(t *testing.T) {
+	server, registry := setupWorkerTestServer(t)
+	ts := NewTelemetryStore()
+	server.SetTelemetryStore(ts)
+	workerID, token := registerWorkerWithToken(t, server, registry, "worker-1")
+
+	body, _ := json.Marshal(summaryTestBatch())
+	httpReq := httptest.NewRequest(http.MethodPost, "/workers/"+string(workerID)+"/telemetry", bytes.NewReader(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-Worker-Token", token)
+	w := httptest.NewRecorder()
+
+	server.handleWorkerTelemetry(w, httpReq, string(workerID))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var resp TelemetryBatchResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Accepted != 10 {
+		t.Errorf("expected 10 accepted, got %d", resp.Accepted)
+	}
+	if count := ts.GetOperationCount("run_0000000000000001"); count != 10 {
+		t.Errorf("expected 10 operations stored, got %d", count)
+	}
+}
+
+func TestTelemetry_RejectsInconsistentSummaries(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*types.OperationSummary)
+	}{
+		{"latency counts short of count", func(s *types.OperationSummary) { s.Count = 11 }},
+		{"more errors than operations", func(s *types.OperationSummary) { s.Errors = 11 }},
+		{"more typed errors than errors", func(s *types.OperationSummary) { s.ErrorTypes["refused"] = 2 }},
+		{"unordered latencies", func(s *types.OperationSummary) { s.Latency[0], s.Latency[1] = s.Latency[1], s.Latency[0] }},
+		{"count too large", func(s *types.OperationSummary) { s.Count = maxSummaryCount + 1 }},
+		{"missing stage", func(s *types.OperationSummary) { s.Stage = "" }},
+		{"attempt out of range", func(s *types.OperationSummary) { s.Attempt = analysis.MaxAttempt + 1 }},
+		{"workflow without a name", func(s *types.OperationSummary) { s.Workflow = &types.WorkflowStepSummary{Step: "call"} }},
+		{"more failed runs than errors", func(s *types.OperationSummary) { s.Workflow = &types.WorkflowStepSummary{Name: "w", Failed: 3} }},
+		{"more ended runs than successes", func(s *types.OperationSummary) {
+			s.Workflow = &types.WorkflowStepSummary{Name: "w", Completed: 7, ExtractFailed: 2}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, registry := setupWorkerTestServer(t)
+			workerID, token := registerWorkerWithToken(t, server, registry, "worker-1")
+
+			req := summaryTestBatch()
+			tt.modify(&req.Summaries[0])
+			body, _ := json.Marshal(req)
+			httpReq := httptest.NewRequest(http.MethodPost, "/workers/"+string(workerID)+"/telemetry", bytes.NewReader(body))
+			httpReq.Header.Set("Content-Type", "application/json")
+			httpReq.Header.Set("X-Worker-Token", token)
+			w := httptest.NewRecorder()
+
+			server.handleWorkerTelemetry(w, httpReq, string(workerID))
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+			}
+		})
+	}
+}
+
+func TestTelemetry_RejectsBatchesSummarizingTooManyOperations(t *testing.T) {
+	server, registry := setupWorkerTestServer(t)
+	workerID, token := registerWorkerWithToken(t, server, registry, "worker-1")
+
+	req := summaryTestBatch()
+	req.Samples = nil
+	summary := req.Summaries[0]
+	summary.Count, summary.Errors, summary.ErrorTypes = maxSummaryCount, 0, nil
+	summary.Latency = [][2]int{{5, maxSummaryCount}}
+	req.Summaries = []types.OperationSummary{summary}
+	for len(req.Summaries)*maxSummaryCount <= maxSummarizedOpsPerBatch {
+		req.Summaries = append(req.Summaries, summary)
+	}
+	body, _ := json.Marshal(req)
+	httpReq := httptest.NewRequest(http.MethodPost, "/workers/"+string(workerID)+"/telemetry", bytes.NewReader(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-Worker-Token", token)
+	w := httptest.NewRecorder()
+
+	server.handleWorkerTelemetry(w, httpReq, string(workerID))
+
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "per batch") {
+		t.Errorf("expected status %d for too many operations, got %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+)
+
+// maxTimeSeriesWindowMs is the widest window /runs/{id}/timeseries accepts.
+const maxTimeSeriesWindowMs = 3_600_000
+
+type TimeSeriesResponse struct {
+	RunID string `json:"run_id"`
+	*analysis.TimeSeriesReport
+}
+
+func (s *Server) handleGetTimeSeries(w http.ResponseWriter, r *http.Request, runID string) {
+	if r.Method != http.MethodGet {
+		s.writeMethodNotAllowed(w, r.Method, "GET")
+		return
+	}
+
+	if s.telemetryStore == nil {
+		s.writeError(w, http.StatusServiceUnavailable, &ErrorResponse{
+			ErrorType:    ErrorTypeInternal,
+			ErrorCode:    "TELEMETRY_NOT_CONFIGURED",
+			ErrorMessage: "Telemetry store not configured",
+			Retryable:    false,
+		})
+		return
+	}
+
+	q := r.URL.Query()
+	windowMs := int64(analysis.DefaultTimeSeriesWindowMs)
+	if v := q.Get("window_ms"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 1 || n > maxTimeSeriesWindowMs {
+			s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(
+				(&InvalidParamError{Param: "window_ms", Value: v, Reason: "must be an integer from 1 to 3600000"}).Error(),
+				nil,
+			))
+			return
+		}
+		windowMs = n
+	}
+
+	report, err := s.telemetryStore.GetTimeSeries(runID, windowMs)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			s.writeError(w, http.StatusNotFound, NewNotFoundErrorResponse(runID))
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, NewInternalErrorResponse(err.Error()))
+		return
+	}
+
+	// operation and tool_name narrow the breakdowns to one series each, as
+	// a dashboard panel needs.
+	if op := q.Get("operation"); op != "" {
+		report.ByOperation = onlySeries(report.ByOperation, op)
+	}
+	if tool := q.Get("tool_name"); tool != "" {
+		report.ByTool = onlySeries(report.ByTool, tool)
+	}
+
+	s.writeJSON(w, http.StatusOK, &TimeSeriesResponse{
+		RunID:            runID,
+		TimeSeriesReport: report,
+	})
+}
+
+// onlySeries returns the series of name alone, or nil if there is none.
+func onlySeries(series map[string][]analysis.WindowPoint, name string) map[string][]analysis.WindowPoint {
+	if points, ok := series[name]; ok {
+		return map[string][]analysis.WindowPoint{name: points}
+	}
+	return nil
+}
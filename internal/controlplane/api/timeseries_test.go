@@ -0,0 +1,110 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+func TestHandleGetTimeSeries(t *testing.T) {
+	ts := NewTelemetryStoreWithConfig(&TelemetryStoreConfig{MaxLogsPerRun: 1})
+	runID := "run_0000000000000140"
+	ts.AddTelemetryBatch(runID, TelemetryBatchRequest{
+		RunID: runID,
+		Operations: []types.OperationOutcome{
+			{TimestampMs: 1_000, Operation: "tools/call", ToolName: "search", LatencyMs: 40, OK: true},
+			{TimestampMs: 2_000, Operation: "tools/call", ToolName: "fetch", LatencyMs: 80, OK: false},
+			{TimestampMs: 7_000, Operation: "tools/call", ToolName: "search", LatencyMs: 20, OK: true},
+		},
+	})
+	server := &Server{telemetryStore: ts}
+
+	req := httptest.NewRequest(http.MethodGet, "/runs/"+runID+"/timeseries?tool_name=search", nil)
+	w := httptest.NewRecorder()
+	server.handleGetTimeSeries(w, req, runID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp TimeSeriesResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.RunID != runID || resp.WindowMs != 5000 || len(resp.Total) != 2 {
+		t.Fatalf("Expected 2 windows of 5000ms despite the truncated logs, got %+v", resp.TimeSeriesReport)
+	}
+	if resp.Total[0].Ops != 2 || resp.Total[0].ErrorRate != 0.5 {
+		t.Errorf("Unexpected first window %+v", resp.Total[0])
+	}
+	if len(resp.ByTool) != 1 || len(resp.ByTool["search"]) != 2 {
+		t.Errorf("Expected only the search series, got %+v", resp.ByTool)
+	}
+	if len(resp.ByOperation["tools/call"]) != 2 {
+		t.Errorf("Expected the tools/call series, got %+v", resp.ByOperation)
+	}
+}
+
+func TestHandleGetTimeSeries_Errors(t *testing.T) {
+	ts := NewTelemetryStore()
+	runID := "run_0000000000000141"
+	ts.AddTelemetryBatch(runID, TelemetryBatchRequest{
+		RunID:      runID,
+		Operations: []types.OperationOutcome{{TimestampMs: 1_000, Operation: "ping", OK: true}},
+	})
+	server := &Server{telemetryStore: ts}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		runID  string
+		want   int
+	}{
+		{"bad window", http.MethodGet, "/runs/" + runID + "/timeseries?window_ms=0", runID, http.StatusBadRequest},
+		{"window too wide", http.MethodGet, "/runs/" + runID + "/timeseries?window_ms=3600001", runID, http.StatusBadRequest},
+		{"unknown run", http.MethodGet, "/runs/run_0000000000000999/timeseries", "run_0000000000000999", http.StatusNotFound},
+		{"wrong method", http.MethodPost, "/runs/" + runID + "/timeseries", runID, http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			server.handleGetTimeSeries(w, httptest.NewRequest(tt.method, tt.path, nil), tt.runID)
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	(&Server{}).handleGetTimeSeries(w, httptest.NewRequest(http.MethodGet, "/runs/"+runID+"/timeseries", nil), runID)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 without a telemetry store, got %d", w.Code)
+	}
+}
+
+func TestTelemetryStore_TimeSeriesWindows(t *testing.T) {
+	ts := NewTelemetryStore()
+	ts.AddTelemetryBatch("run_0000000000000142", TelemetryBatchRequest{
+		RunID: "run_0000000000000142",
+		Operations: []types.OperationOutcome{
+			{TimestampMs: 1_000, Operation: "ping", Stage: "baseline", OK: true},
+			{TimestampMs: 6_000, Operation: "ping", Stage: "ramp", OK: true},
+		},
+	})
+	ts.AddTelemetryBatch("run_0000000000000143", TelemetryBatchRequest{
+		RunID:      "run_0000000000000143",
+		Operations: []types.OperationOutcome{{TimestampMs: 20_000, Operation: "ping", OK: true}},
+	})
+
+	windows := ts.TimeSeriesWindows(5_000, 10_000)
+	if len(windows) != 1 {
+		t.Fatalf("Expected only the run with operations in the range, got %v", windows)
+	}
+	total := windows["run_0000000000000142"].Total
+	if len(total) != 1 || total[0].TimestampMs != 5_000 || total[0].Stage != "ramp" {
+		t.Errorf("Expected the ramp window alone, got %+v", total)
+	}
+}
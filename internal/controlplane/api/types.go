@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 
 	"github.com/bc-dunia/mcpdrill/internal/analysis"
+	"github.com/bc-dunia/mcpdrill/internal/auth"
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/audit"
 	"github.com/bc-dunia/mcpdrill/internal/controlplane/runmanager"
 	"github.com/bc-dunia/mcpdrill/internal/controlplane/scheduler"
 	"github.com/bc-dunia/mcpdrill/internal/metrics"
@@ -15,11 +17,25 @@ import (
 type CreateRunRequest struct {
 	Config json.RawMessage `json:"config"`
 	Actor  string          `json:"actor"`
+
+	// ScenarioRef creates the run from a library scenario instead of
+	// Config, with Overrides merged into its config as a JSON merge patch.
+	ScenarioRef *runmanager.ScenarioRef `json:"scenario_ref,omitempty"`
+	Overrides   json.RawMessage         `json:"overrides,omitempty"`
+
+	// Parameters are applied to the config before it is validated.
+	Parameters *runmanager.RunParameters `json:"parameters,omitempty"`
+
+	// Tags label the run; they can be changed later at /runs/{id}/tags.
+	Tags map[string]string `json:"tags,omitempty"`
 }
 
 // CreateRunResponse is the response body for POST /runs.
 type CreateRunResponse struct {
 	RunID string `json:"run_id"`
+	// Warnings name the runs of the identical config already in progress,
+	// when the server is started with --warn-duplicate-runs.
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 // ValidateConfigRequest is the request body for POST /runs/{id}/validate.
@@ -37,6 +53,12 @@ type ValidateConfigResponse struct {
 // StartRunRequest is the request body for POST /runs/{id}/start.
 type StartRunRequest struct {
 	Actor string `json:"actor"`
+	// Priority orders the run in the run queue, higher first. It has no
+	// effect when the server runs without a queue.
+	Priority int `json:"priority,omitempty"`
+	// Parameters are applied to the run's stored config, which is validated
+	// again before the run starts.
+	Parameters *runmanager.RunParameters `json:"parameters,omitempty"`
 }
 
 // StartRunResponse is the response body for POST /runs/{id}/start.
@@ -68,12 +90,68 @@ type EmergencyStopResponse struct {
 	State string `json:"state"`
 }
 
+// AbortRunRequest is the request body for POST /runs/{id}:abort. Actor
+// defaults to the authenticated user.
+type AbortRunRequest struct {
+	Actor  string `json:"actor"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// AbortRunResponse is the response body for POST /runs/{id}:abort.
+type AbortRunResponse struct {
+	RunID     string `json:"run_id"`
+	State     string `json:"state"`
+	AbortedBy string `json:"aborted_by"`
+}
+
+// PauseRunRequest is the request body for POST /runs/{id}:pause and
+// POST /runs/{id}:resume, which ignores Reason. Actor defaults to the
+// authenticated user.
+type PauseRunRequest struct {
+	Actor  string `json:"actor"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// PauseRunResponse is the response body for POST /runs/{id}:pause and
+// POST /runs/{id}:resume. PausedMs is the time the run has spent paused,
+// not counting a pause in progress.
+type PauseRunResponse struct {
+	RunID    string `json:"run_id"`
+	State    string `json:"state"`
+	PausedMs int64  `json:"paused_ms"`
+}
+
 // GetRunResponse is the response body for GET /runs/{id}.
 // It wraps RunView from runmanager.
 type GetRunResponse struct {
 	*runmanager.RunView
 }
 
+// UpdateOpMixRequest is the request body for POST /runs/{id}/op-mix.
+type UpdateOpMixRequest struct {
+	Weights []runmanager.OpMixWeight `json:"weights"`
+	Actor   string                   `json:"actor"`
+}
+
+// OpMixResponse is the response body for GET and POST /runs/{id}/op-mix.
+type OpMixResponse struct {
+	*runmanager.OpMixView
+}
+
+// RunTagsRequest is the request body for PUT and PATCH /runs/{id}/tags.
+// PUT replaces the run's tags; PATCH sets the tags given and removes those
+// given as null.
+type RunTagsRequest struct {
+	Tags  map[string]*string `json:"tags"`
+	Actor string             `json:"actor"`
+}
+
+// RunTagsResponse is the response body for /runs/{id}/tags.
+type RunTagsResponse struct {
+	RunID string            `json:"run_id"`
+	Tags  map[string]string `json:"tags"`
+}
+
 // CloneRunRequest is the request body for POST /runs/{id}/clone.
 type CloneRunRequest struct {
 	Actor string `json:"actor"`
@@ -84,6 +162,60 @@ type CloneRunResponse struct {
 	RunID string `json:"run_id"`
 }
 
+// SaveScenarioRequest is the request body for POST /scenarios and
+// PUT /scenarios/{name}.
+type SaveScenarioRequest struct {
+	Name        string          `json:"name"`
+	Config      json.RawMessage `json:"config"`
+	Description string          `json:"description,omitempty"`
+	Actor       string          `json:"actor"`
+}
+
+// SaveScenarioResponse is the saved scenario version. Created is false when
+// the config matched the latest version and no version was added.
+type SaveScenarioResponse struct {
+	*runmanager.ScenarioVersion
+	Created bool `json:"created"`
+}
+
+// ListScenariosResponse is the response body for GET /scenarios.
+type ListScenariosResponse struct {
+	Scenarios []runmanager.ScenarioSummary `json:"scenarios"`
+}
+
+// ListScenarioVersionsResponse is the response body for
+// GET /scenarios/{name}/versions.
+type ListScenarioVersionsResponse struct {
+	Name     string                        `json:"name"`
+	Versions []*runmanager.ScenarioVersion `json:"versions"`
+}
+
+// CreateAPIKeyRequest is the request body for POST /api-keys.
+type CreateAPIKeyRequest struct {
+	Name   string       `json:"name"`
+	Scopes []auth.Scope `json:"scopes"`
+	// Projects binds the key to these projects; empty allows every project.
+	Projects []string `json:"projects,omitempty"`
+}
+
+// APIKeyResponse is a managed API key. Key holds the secret and is only
+// set when the key is created or rotated.
+type APIKeyResponse struct {
+	auth.ManagedKey
+	Key string `json:"key,omitempty"`
+}
+
+// ListAPIKeysResponse is the response body for GET /api-keys.
+type ListAPIKeysResponse struct {
+	Keys []auth.ManagedKey `json:"keys"`
+}
+
+// AuditLogResponse is the response body for GET /audit.
+type AuditLogResponse struct {
+	Entries []audit.Entry `json:"entries"`
+	Limit   int           `json:"limit"`
+}
+
 // ErrorResponse is the standard error response format.
 // Matches ref/04-data-models.md Section 5.1 error envelope.
 type ErrorResponse struct {
@@ -126,6 +258,8 @@ const (
 const (
 	ErrorCodeValidationFailed = "VALIDATION_FAILED"
 	ErrorCodeRunNotFound      = "RUN_NOT_FOUND"
+	ErrorCodeScenarioNotFound = "SCENARIO_NOT_FOUND"
+	ErrorCodeAPIKeyNotFound   = "API_KEY_NOT_FOUND"
 	ErrorCodeInvalidState     = "INVALID_STATE"
 	ErrorCodeInvalidRequest   = "INVALID_REQUEST"
 	ErrorCodeInvalidStopMode  = "INVALID_STOP_MODE"
@@ -158,6 +292,11 @@ func NewValidationErrorResponse(report *validation.ValidationReport) *ErrorRespo
 			if e.JSONPointer != "" {
 				errors[i]["json_pointer"] = e.JSONPointer
 			}
+			if e.YAMLPath != "" {
+				errors[i]["yaml_path"] = e.YAMLPath
+				errors[i]["line"] = e.Line
+				errors[i]["column"] = e.Column
+			}
 		}
 		details["errors"] = errors
 	}
@@ -171,6 +310,11 @@ func NewValidationErrorResponse(report *validation.ValidationReport) *ErrorRespo
 			if w.JSONPointer != "" {
 				warnings[i]["json_pointer"] = w.JSONPointer
 			}
+			if w.YAMLPath != "" {
+				warnings[i]["yaml_path"] = w.YAMLPath
+				warnings[i]["line"] = w.Line
+				warnings[i]["column"] = w.Column
+			}
 		}
 		details["warnings"] = warnings
 	}
@@ -253,12 +397,24 @@ func NewInternalErrorResponse(message string) *ErrorResponse {
 type RegisterWorkerRequest struct {
 	HostInfo types.HostInfo       `json:"host_info"`
 	Capacity types.WorkerCapacity `json:"capacity"`
+	// ControlChannels lists the control channels the worker supports.
+	ControlChannels []string `json:"control_channels,omitempty"`
+	// Project dedicates the worker to runs of one project. Without it the
+	// worker is shared by every project, unless the caller is bound to
+	// projects, in which case it joins the request's project.
+	Project string `json:"project,omitempty"`
 }
 
 // RegisterWorkerResponse is the response body for POST /workers/register.
 type RegisterWorkerResponse struct {
 	WorkerID    string `json:"worker_id"`
 	WorkerToken string `json:"worker_token,omitempty"`
+	// WorkerTokenExpiresAtMs is when the token expires unless rotated, or
+	// 0 if it does not.
+	WorkerTokenExpiresAtMs int64 `json:"worker_token_expires_at_ms,omitempty"`
+	// ControlChannel is the control channel the worker should use, or
+	// empty to poll over HTTP.
+	ControlChannel string `json:"control_channel,omitempty"`
 }
 
 // ListWorkersResponse is the response body for GET /workers.
@@ -266,9 +422,30 @@ type ListWorkersResponse struct {
 	Workers []*scheduler.WorkerInfo `json:"workers"`
 }
 
+// CapacityResponse is the response body for GET /workers/capacity.
+type CapacityResponse struct {
+	AvailableVUs     int `json:"available_vus"`
+	AvailableWorkers int `json:"available_workers"`
+	// ShortfallVUs and ShortfallWorkers are what the neediest run lacks,
+	// the capacity to add so that every pending run can be allocated.
+	ShortfallVUs     int           `json:"shortfall_vus"`
+	ShortfallWorkers int           `json:"shortfall_workers"`
+	Runs             []RunCapacity `json:"runs"`
+}
+
+// RunCapacity is the capacity a pending run needs and what it lacks.
+type RunCapacity struct {
+	runmanager.CapacityRequirement
+	ShortfallVUs     int `json:"shortfall_vus"`
+	ShortfallWorkers int `json:"shortfall_workers"`
+}
+
 // HeartbeatRequest is the request body for POST /workers/{id}/heartbeat.
 type HeartbeatRequest struct {
 	Health *types.WorkerHealth `json:"health,omitempty"`
+	// SecretAccesses are the secret references the worker resolved for
+	// runs since its last heartbeat.
+	SecretAccesses []types.SecretAccess `json:"secret_accesses,omitempty"`
 }
 
 // HeartbeatResponse is the response body for POST /workers/{id}/heartbeat.
@@ -276,13 +453,25 @@ type HeartbeatResponse struct {
 	OK                  bool     `json:"ok"`
 	StopRunIDs          []string `json:"stop_run_ids,omitempty"`
 	ImmediateStopRunIDs []string `json:"immediate_stop_run_ids,omitempty"`
+	PausedRunIDs        []string `json:"paused_run_ids,omitempty"`
+	// WorkerToken, when set, replaces the worker's token, which stays
+	// valid for a short grace period.
+	WorkerToken            string `json:"worker_token,omitempty"`
+	WorkerTokenExpiresAtMs int64  `json:"worker_token_expires_at_ms,omitempty"`
 }
 
 // TelemetryBatchRequest is the request body for POST /workers/{id}/telemetry.
+// Workers that summarize telemetry send Summaries and, as Samples, a few of
+// the operations they cover in full. Samples are only kept as operation logs
+// so they are not counted twice.
 type TelemetryBatchRequest struct {
 	RunID      string                   `json:"run_id"`
 	Operations []types.OperationOutcome `json:"operations"`
+	Summaries  []types.OperationSummary `json:"summaries,omitempty"`
+	Samples    []types.OperationOutcome `json:"samples,omitempty"`
 	Health     *types.WorkerHealth      `json:"health,omitempty"`
+
+	GeneratorWarnings []types.LoadGeneratorWarning `json:"generator_warnings,omitempty"`
 }
 
 // TelemetryBatchResponse is the response body for POST /workers/{id}/telemetry.
@@ -290,6 +479,34 @@ type TelemetryBatchResponse struct {
 	Accepted int `json:"accepted"`
 }
 
+// TargetProfileRequest is the request body for POST
+// /workers/{id}/target-profile.
+type TargetProfileRequest struct {
+	RunID   string               `json:"run_id"`
+	Profile *types.TargetProfile `json:"profile"`
+}
+
+// TargetProfileResponse is the response body for POST
+// /workers/{id}/target-profile. Recorded is false if the run already had a
+// profile.
+type TargetProfileResponse struct {
+	Recorded bool `json:"recorded"`
+}
+
+// ConformanceReportRequest is the request body for POST
+// /workers/{id}/conformance-report.
+type ConformanceReportRequest struct {
+	RunID  string                      `json:"run_id"`
+	Report *analysis.ConformanceReport `json:"report"`
+}
+
+// ConformanceReportResponse is the response body for POST
+// /workers/{id}/conformance-report. Recorded is false if the run already
+// had a report.
+type ConformanceReportResponse struct {
+	Recorded bool `json:"recorded"`
+}
+
 // ErrorCode constants for worker-related errors.
 const (
 	ErrorCodeWorkerNotFound = "WORKER_NOT_FOUND"
@@ -313,39 +530,63 @@ type AckAssignmentsResponse struct {
 // OperationLog represents a single operation log entry with full context.
 // Used for log query API responses.
 type OperationLog struct {
-	TimestampMs int64             `json:"timestamp_ms"`
-	RunID       string            `json:"run_id"`
-	ExecutionID string            `json:"execution_id,omitempty"`
-	Stage       string            `json:"stage,omitempty"`
-	StageID     string            `json:"stage_id,omitempty"`
-	WorkerID    string            `json:"worker_id,omitempty"`
-	VUID        string            `json:"vu_id,omitempty"`
-	SessionID   string            `json:"session_id,omitempty"`
-	Operation   string            `json:"operation"`
-	ToolName    string            `json:"tool_name,omitempty"`
-	LatencyMs   int               `json:"latency_ms"`
-	OK          bool              `json:"ok"`
-	ErrorType   string            `json:"error_type,omitempty"`
-	ErrorCode   string            `json:"error_code,omitempty"`
-	Stream      *types.StreamInfo `json:"stream,omitempty"`
-	TokenIndex  *int              `json:"token_index,omitempty"`
+	TimestampMs   int64                   `json:"timestamp_ms"`
+	RunID         string                  `json:"run_id"`
+	ExecutionID   string                  `json:"execution_id,omitempty"`
+	Stage         string                  `json:"stage,omitempty"`
+	StageID       string                  `json:"stage_id,omitempty"`
+	WorkerID      string                  `json:"worker_id,omitempty"`
+	Group         string                  `json:"generator_group,omitempty"`
+	Target        string                  `json:"target,omitempty"`
+	VUID          string                  `json:"vu_id,omitempty"`
+	SessionID     string                  `json:"session_id,omitempty"`
+	BackendID     string                  `json:"backend_id,omitempty"`
+	Operation     string                  `json:"operation"`
+	ToolName      string                  `json:"tool_name,omitempty"`
+	ResourceURI   string                  `json:"resource_uri,omitempty"`
+	PromptName    string                  `json:"prompt_name,omitempty"`
+	LatencyMs     int                     `json:"latency_ms"`
+	OK            bool                    `json:"ok"`
+	ErrorType     string                  `json:"error_type,omitempty"`
+	ErrorCode     string                  `json:"error_code,omitempty"`
+	BytesIn       int64                   `json:"bytes_in,omitempty"`
+	BytesOut      int64                   `json:"bytes_out,omitempty"`
+	Stream        *types.StreamInfo       `json:"stream,omitempty"`
+	TokenIndex    *int                    `json:"token_index,omitempty"`
+	ResultSummary *types.ResultSummary    `json:"result_summary,omitempty"`
+	RequestID     *types.RequestIDInfo    `json:"request_id,omitempty"`
+	Checks        []types.CheckResult     `json:"checks,omitempty"`
+	FuzzMutation  string                  `json:"fuzz_mutation,omitempty"`
+	Cancellation  *types.CancellationInfo `json:"cancellation,omitempty"`
+	RetryAfterMs  int64                   `json:"retry_after_ms,omitempty"`
+	Attempt       int                     `json:"attempt,omitempty"`
+	Warmup        bool                    `json:"warmup,omitempty"`
+	Chaos         *types.ChaosInfo        `json:"chaos,omitempty"`
+	Workflow      *types.WorkflowStepInfo `json:"workflow,omitempty"`
+
+	OpID             string `json:"op_id,omitempty"`
+	ErrorMessage     string `json:"error_message,omitempty"`
+	JSONRPCErrorCode *int   `json:"jsonrpc_error_code,omitempty"`
 }
 
 // LogFilters contains filter parameters for log queries.
 type LogFilters struct {
-	Stage      string
-	StageID    string
-	WorkerID   string
-	VUID       string
-	SessionID  string
-	Operation  string
-	ToolName   string
-	ErrorType  string
-	ErrorCode  string
-	TokenIndex *int
-	Limit      int
-	Offset     int
-	Order      string // "asc" or "desc"
+	Stage        string
+	StageID      string
+	WorkerID     string
+	Group        string
+	Target       string
+	VUID         string
+	SessionID    string
+	Operation    string
+	ToolName     string
+	ErrorType    string
+	ErrorCode    string
+	FuzzMutation string
+	TokenIndex   *int
+	Limit        int
+	Offset       int
+	Order        string // "asc" or "desc"
 }
 
 // LogQueryResponse is the response body for GET /runs/{id}/logs.
@@ -358,9 +599,13 @@ type LogQueryResponse struct {
 	LogsTruncated bool           `json:"logs_truncated,omitempty"`
 }
 
-// ListRunsResponse is the response body for GET /runs.
+// ListRunsResponse is the response body for GET /runs. Total counts the
+// runs that match the filters, of which Runs is the page from Offset.
 type ListRunsResponse struct {
-	Runs []*runmanager.RunView `json:"runs"`
+	Runs   []*runmanager.RunView `json:"runs"`
+	Total  int                   `json:"total"`
+	Offset int                   `json:"offset,omitempty"`
+	Limit  int                   `json:"limit,omitempty"`
 }
 
 // RunMetricsResponse is the response body for GET /runs/{id}/metrics.
@@ -379,6 +624,48 @@ type RunMetricsResponse struct {
 	OperationsTruncated bool                                  `json:"operations_truncated,omitempty"`
 }
 
+// LiveMetricsSnapshot is one event of GET /runs/{id}/metrics/stream. Rates,
+// latencies and the per-tool breakdown cover the last WindowMs; TotalOps
+// counts every operation ingested for the run.
+type LiveMetricsSnapshot struct {
+	RunID       string            `json:"run_id"`
+	State       string            `json:"state"`
+	Stage       string            `json:"stage,omitempty"`
+	TimestampMs int64             `json:"timestamp_ms"`
+	WindowMs    int64             `json:"window_ms"`
+	TotalOps    int64             `json:"total_ops"`
+	WindowOps   int               `json:"window_ops"`
+	RPS         float64           `json:"rps"`
+	ErrorRate   float64           `json:"error_rate"`
+	LatencyP50  int               `json:"latency_p50_ms"`
+	LatencyP95  int               `json:"latency_p95_ms"`
+	LatencyP99  int               `json:"latency_p99_ms"`
+	ByTool      []LiveToolMetrics `json:"by_tool"`
+	Hosts       []LiveHostMetrics `json:"hosts"`
+}
+
+// LiveToolMetrics is one tool's share of a LiveMetricsSnapshot window.
+type LiveToolMetrics struct {
+	Tool       string  `json:"tool"`
+	Ops        int     `json:"ops"`
+	RPS        float64 `json:"rps"`
+	ErrorRate  float64 `json:"error_rate"`
+	LatencyP95 int     `json:"latency_p95_ms"`
+	LatencyP99 int     `json:"latency_p99_ms"`
+}
+
+// LiveHostMetrics is the latest sample of an agent paired with the run.
+type LiveHostMetrics struct {
+	AgentID           string  `json:"agent_id"`
+	Hostname          string  `json:"hostname"`
+	TimestampMs       int64   `json:"timestamp_ms"`
+	CPUPercent        float64 `json:"cpu_percent"`
+	MemUsed           uint64  `json:"mem_used"`
+	MemTotal          uint64  `json:"mem_total"`
+	ProcessCPUPercent float64 `json:"process_cpu_percent,omitempty"`
+	ProcessRSS        uint64  `json:"process_rss,omitempty"`
+}
+
 // CompareRunsResponse represents an A/B comparison of two runs
 type CompareRunsResponse struct {
 	RunA RunMetricsResponse `json:"run_a"`
@@ -403,3 +690,11 @@ type StabilityResponse struct {
 	TimeSeriesData       []metrics.StabilityTimePoint `json:"time_series,omitempty"`
 	DataTruncated        bool                         `json:"data_truncated,omitempty"`
 }
+
+// StickinessResponse is the response body for GET /runs/{id}/stickiness.
+type StickinessResponse struct {
+	RunID         string `json:"run_id"`
+	BackendHeader string `json:"backend_id_header,omitempty"`
+	*metrics.StickinessMetrics
+	DataTruncated bool `json:"data_truncated,omitempty"`
+}
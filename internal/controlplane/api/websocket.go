@@ -0,0 +1,182 @@
+package api
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/websocket"
+)
+
+// This is a minimal server side of RFC 6455 on the websocket package's
+// framing, enough to push run events to dashboards and talk to workers.
+// Clients may send pings and close frames.
+// Unfragmented text messages go to the connection's handler, if it has one;
+// other data messages are read and discarded.
+
+const (
+	// wsMaxClientMessage bounds a single frame sent by a client.
+	wsMaxClientMessage = 64 * 1024
+	// wsWriteTimeout bounds a single frame write to a slow client.
+	wsWriteTimeout = 10 * time.Second
+)
+
+// isWebSocketUpgrade reports whether r asks to switch to the WebSocket
+// protocol.
+func isWebSocketUpgrade(r *http.Request) bool {
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		return false
+	}
+	for _, value := range r.Header.Values("Connection") {
+		for _, token := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(token), "upgrade") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkWebSocketHandshake validates the version and key of an upgrade
+// request.
+func checkWebSocketHandshake(r *http.Request) error {
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		return fmt.Errorf("unsupported Sec-WebSocket-Version %q (expected 13)", r.Header.Get("Sec-WebSocket-Version"))
+	}
+	key, err := base64.StdEncoding.DecodeString(r.Header.Get("Sec-WebSocket-Key"))
+	if err != nil || len(key) != 16 {
+		return errors.New("invalid Sec-WebSocket-Key")
+	}
+	return nil
+}
+
+// wsConn is a server-side WebSocket connection. Writes are safe for
+// concurrent use; a background reader answers pings and closes done when the
+// client goes away.
+type wsConn struct {
+	conn   net.Conn
+	br     *bufio.Reader
+	onText func([]byte)
+
+	writeMu   sync.Mutex
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// acceptWebSocket completes the handshake of a request that passed
+// checkWebSocketHandshake and takes over its connection. onText, if not nil,
+// is called from the connection's reader with each text message.
+func acceptWebSocket(w http.ResponseWriter, r *http.Request, onText func([]byte)) (*wsConn, error) {
+	conn, brw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	accept := websocket.AcceptKey(r.Header.Get("Sec-WebSocket-Key"))
+
+	// The server's read and write timeouts are meant for ordinary requests.
+	_ = conn.SetDeadline(time.Time{})
+
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + accept + "\r\n\r\n"
+	_ = conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	if _, err := brw.WriteString(response); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := brw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	ws := &wsConn{conn: conn, br: brw.Reader, onText: onText, done: make(chan struct{})}
+	go ws.readLoop()
+	return ws, nil
+}
+
+// Done is closed when the connection has been closed by either side.
+func (c *wsConn) Done() <-chan struct{} {
+	return c.done
+}
+
+// WriteText sends a text message.
+func (c *wsConn) WriteText(data []byte) error {
+	return c.writeFrame(websocket.OpText, data)
+}
+
+// Ping sends a ping. The client's pong is not awaited; a dead connection
+// shows up as a failed write.
+func (c *wsConn) Ping() error {
+	return c.writeFrame(websocket.OpPing, nil)
+}
+
+// Close sends a close frame with code and closes the connection.
+func (c *wsConn) Close(code int) {
+	payload := make([]byte, 2)
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	_ = c.writeFrame(websocket.OpClose, payload)
+	c.shutdown()
+}
+
+func (c *wsConn) shutdown() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+func (c *wsConn) writeFrame(opcode byte, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	select {
+	case <-c.done:
+		return net.ErrClosed
+	default:
+	}
+
+	_ = c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	if _, err := c.conn.Write(websocket.AppendFrame(make([]byte, 0, 10+len(payload)), opcode, payload, nil)); err != nil {
+		c.shutdown()
+		return err
+	}
+	return nil
+}
+
+// readLoop reads client frames until the connection fails or the client
+// closes it.
+func (c *wsConn) readLoop() {
+	defer c.shutdown()
+	for {
+		frame, err := websocket.ReadFrame(c.br, wsMaxClientMessage, true)
+		if err != nil {
+			var closeErr websocket.CloseError
+			if errors.As(err, &closeErr) {
+				c.Close(int(closeErr))
+			}
+			return
+		}
+		switch frame.Opcode {
+		case websocket.OpText:
+			if c.onText != nil && frame.Fin {
+				c.onText(frame.Payload)
+			}
+		case websocket.OpPing:
+			if c.writeFrame(websocket.OpPong, frame.Payload) != nil {
+				return
+			}
+		case websocket.OpClose:
+			c.Close(websocket.CloseNormal)
+			return
+		}
+	}
+}
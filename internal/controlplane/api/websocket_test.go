@@ -0,0 +1,280 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/runmanager"
+	"github.com/bc-dunia/mcpdrill/internal/websocket"
+)
+
+// dialEventWebSocket opens a WebSocket to path using the handshake example
+// key from RFC 6455.
+func dialEventWebSocket(t *testing.T, server *Server, path string) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	conn, err := net.Dial("tcp", server.Addr())
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	req := "GET " + path + " HTTP/1.1\r\n" +
+		"Host: " + server.Addr() + "\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: keep-alive, Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n" +
+		"Sec-WebSocket-Version: 13\r\n\r\n"
+	if _, err := conn.Write([]byte(req)); err != nil {
+		t.Fatalf("handshake write failed: %v", err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatalf("handshake read failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("Expected 101, got %d", resp.StatusCode)
+	}
+	if accept := resp.Header.Get("Sec-WebSocket-Accept"); accept != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("Unexpected Sec-WebSocket-Accept %q", accept)
+	}
+	return conn, br
+}
+
+func readServerFrame(t *testing.T, br *bufio.Reader) (byte, []byte) {
+	t.Helper()
+	frame, err := websocket.ReadFrame(br, math.MaxInt32, false)
+	if err != nil {
+		t.Fatalf("frame read failed: %v", err)
+	}
+	return frame.Opcode, frame.Payload
+}
+
+func writeClientFrame(t *testing.T, conn net.Conn, opcode byte, payload []byte) {
+	t.Helper()
+	if _, err := conn.Write(websocket.AppendFrame(nil, opcode, payload, &[4]byte{1, 2, 3, 4})); err != nil {
+		t.Fatalf("frame write failed: %v", err)
+	}
+}
+
+// readWSMessage returns the next text message, skipping pings.
+func readWSMessage(t *testing.T, br *bufio.Reader) wsEventMessage {
+	t.Helper()
+	for {
+		opcode, payload := readServerFrame(t, br)
+		if opcode == websocket.OpPing {
+			continue
+		}
+		if opcode != websocket.OpText {
+			t.Fatalf("Expected text frame, got opcode %d", opcode)
+		}
+		var msg struct {
+			Event string          `json:"event"`
+			ID    string          `json:"id"`
+			Data  json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(payload, &msg); err != nil {
+			t.Fatalf("invalid message JSON: %v", err)
+		}
+		return wsEventMessage{Event: msg.Event, ID: msg.ID, Data: msg.Data}
+	}
+}
+
+func TestEventStream_SSE(t *testing.T) {
+	rm := newTestRunManager(t)
+	server, cleanup, err := StartTestServer(rm)
+	if err != nil {
+		t.Fatalf("Failed to start test server: %v", err)
+	}
+	defer cleanup()
+
+	runID, _ := rm.CreateRun(loadValidConfig(t), "test")
+	_ = rm.StartRun(runID, "test")
+	events, _ := rm.TailEvents(runID, 0, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL()+"/runs/"+runID+"/events/stream", nil)
+	req.Header.Set("Last-Event-ID", events[0].EventID)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("SSE request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	var nextID string
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.HasPrefix(line, "id: ") {
+			nextID = strings.TrimPrefix(line, "id: ")
+			break
+		}
+	}
+	if nextID == "" || nextID == events[0].EventID {
+		t.Errorf("Expected the event after %s, got %q", events[0].EventID, nextID)
+	}
+}
+
+func TestEventStream_UnknownSubpath(t *testing.T) {
+	rm := newTestRunManager(t)
+	server, cleanup, err := StartTestServer(rm)
+	if err != nil {
+		t.Fatalf("Failed to start test server: %v", err)
+	}
+	defer cleanup()
+
+	runID, _ := rm.CreateRun(loadValidConfig(t), "test")
+
+	resp, err := http.Get(server.URL() + "/runs/" + runID + "/events/bogus")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestEventStream_WebSocketLive(t *testing.T) {
+	rm := newTestRunManager(t)
+	server, cleanup, err := StartTestServer(rm)
+	if err != nil {
+		t.Fatalf("Failed to start test server: %v", err)
+	}
+	defer cleanup()
+
+	runID, _ := rm.CreateRun(loadValidConfig(t), "test")
+
+	conn, br := dialEventWebSocket(t, server, "/runs/"+runID+"/events/stream")
+
+	msg := readWSMessage(t, br)
+	var created runmanager.RunEvent
+	if err := json.Unmarshal(msg.Data.(json.RawMessage), &created); err != nil {
+		t.Fatalf("invalid event: %v", err)
+	}
+	if msg.Event != "run_event" || created.Type != runmanager.EventTypeRunCreated || msg.ID != created.EventID {
+		t.Fatalf("Expected RUN_CREATED run_event with matching id, got %s %s id=%s", msg.Event, created.Type, msg.ID)
+	}
+
+	// Events appended after connecting are pushed on the same socket.
+	if err := rm.StartRun(runID, "test"); err != nil {
+		t.Fatalf("StartRun failed: %v", err)
+	}
+	next := readWSMessage(t, br)
+	if next.Event != "run_event" || next.ID == created.EventID {
+		t.Errorf("Expected a new run_event, got %s id=%s", next.Event, next.ID)
+	}
+
+	// Pings are answered.
+	writeClientFrame(t, conn, websocket.OpPing, []byte("hi"))
+	for {
+		opcode, payload := readServerFrame(t, br)
+		if opcode == websocket.OpPong {
+			if string(payload) != "hi" {
+				t.Errorf("Expected pong payload hi, got %q", payload)
+			}
+			break
+		}
+	}
+
+	// A close frame is echoed and ends the stream.
+	writeClientFrame(t, conn, websocket.OpClose, []byte{0x03, 0xE8})
+	for {
+		opcode, payload := readServerFrame(t, br)
+		if opcode == websocket.OpClose {
+			if code := binary.BigEndian.Uint16(payload); code != websocket.CloseNormal {
+				t.Errorf("Expected close code %d, got %d", websocket.CloseNormal, code)
+			}
+			break
+		}
+	}
+}
+
+func TestEventStream_WebSocketResumeAndReplay(t *testing.T) {
+	rm := newTestRunManager(t)
+	server, cleanup, err := StartTestServer(rm)
+	if err != nil {
+		t.Fatalf("Failed to start test server: %v", err)
+	}
+	defer cleanup()
+
+	runID, _ := rm.CreateRun(loadValidConfig(t), "test")
+	_ = rm.StartRun(runID, "test")
+	events, _ := rm.TailEvents(runID, 0, 100)
+	if len(events) < 2 {
+		t.Fatalf("Expected at least 2 events, got %d", len(events))
+	}
+
+	// Browsers cannot set Last-Event-ID on a WebSocket, so resume uses cursor.
+	_, br := dialEventWebSocket(t, server, "/runs/"+runID+"/events/stream?cursor="+events[0].EventID)
+	if msg := readWSMessage(t, br); msg.ID != events[1].EventID {
+		t.Errorf("Expected resume at %s, got %s", events[1].EventID, msg.ID)
+	}
+
+	_, br = dialEventWebSocket(t, server, "/runs/"+runID+"/events/replay?types=RUN_CREATED")
+	if msg := readWSMessage(t, br); msg.ID != events[0].EventID {
+		t.Errorf("Expected replay to start at %s, got %s", events[0].EventID, msg.ID)
+	}
+	marker := readWSMessage(t, br)
+	if marker.Event != "replay_complete" || marker.ID != "" {
+		t.Fatalf("Expected replay_complete without id, got %s id=%s", marker.Event, marker.ID)
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(marker.Data.(json.RawMessage), &data); err != nil {
+		t.Fatal(err)
+	}
+	if data["replayed_events"] != float64(1) {
+		t.Errorf("Expected 1 replayed event, got %v", data["replayed_events"])
+	}
+}
+
+func TestEventStream_WebSocketBadHandshake(t *testing.T) {
+	rm := newTestRunManager(t)
+	server, cleanup, err := StartTestServer(rm)
+	if err != nil {
+		t.Fatalf("Failed to start test server: %v", err)
+	}
+	defer cleanup()
+
+	runID, _ := rm.CreateRun(loadValidConfig(t), "test")
+
+	req, _ := http.NewRequest("GET", server.URL()+"/runs/"+runID+"/events/stream", nil)
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	req.Header.Set("Sec-WebSocket-Version", "8")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected 400, got %d", resp.StatusCode)
+	}
+	var errResp ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+		t.Fatal(err)
+	}
+	if errResp.ErrorCode != "INVALID_WEBSOCKET_HANDSHAKE" {
+		t.Errorf("Expected INVALID_WEBSOCKET_HANDSHAKE, got %s", errResp.ErrorCode)
+	}
+	if v := resp.Header.Get("Sec-WebSocket-Version"); v != "13" {
+		t.Errorf("Expected Sec-WebSocket-Version 13, got %q", v)
+	}
+}
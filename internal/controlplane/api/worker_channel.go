@@ -0,0 +1,263 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/scheduler"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+	"github.com/bc-dunia/mcpdrill/internal/websocket"
+)
+
+const (
+	// workerChannelStopPollInterval is how often a control channel checks
+	// for runs its worker must stop.
+	workerChannelStopPollInterval = 100 * time.Millisecond
+	// workerChannelPingInterval keeps idle channels open through proxies.
+	workerChannelPingInterval = 15 * time.Second
+)
+
+// negotiateControlChannel picks the control channel offered to a
+// registering worker from those it supports.
+func (s *Server) negotiateControlChannel(supported []string) string {
+	if s.isWorkerChannelEnabled() && slices.Contains(supported, types.ControlChannelWebSocket) {
+		return types.ControlChannelWebSocket
+	}
+	return ""
+}
+
+// handleWorkerChannel serves GET /workers/{id}/channel, the WebSocket
+// control channel. Assignments are pushed as soon as they are queued and
+// stop commands within workerChannelStopPollInterval; the worker sends
+// heartbeats and assignment acks back. While the channel is open, the
+// worker does not need to poll.
+func (s *Server) handleWorkerChannel(w http.ResponseWriter, r *http.Request, workerID string) {
+	if r.Method != http.MethodGet {
+		s.writeMethodNotAllowed(w, r.Method, "GET")
+		return
+	}
+
+	if !s.verifyWorkerToken(w, r, workerID) {
+		return
+	}
+
+	if !s.isWorkerChannelEnabled() {
+		s.writeError(w, http.StatusNotFound, &ErrorResponse{
+			ErrorType:    ErrorTypeNotFound,
+			ErrorCode:    "ENDPOINT_NOT_FOUND",
+			ErrorMessage: "Worker control channel is disabled",
+			Retryable:    false,
+			Details:      map[string]interface{}{"path": r.URL.Path},
+		})
+		return
+	}
+
+	if s.registry == nil {
+		s.writeError(w, http.StatusInternalServerError, NewInternalErrorResponse("registry not configured"))
+		return
+	}
+
+	if _, err := s.registry.GetWorker(scheduler.WorkerID(workerID)); err != nil {
+		if err == scheduler.ErrWorkerNotFound {
+			s.writeError(w, http.StatusNotFound, &ErrorResponse{
+				ErrorType:    ErrorTypeNotFound,
+				ErrorCode:    ErrorCodeWorkerNotFound,
+				ErrorMessage: "Worker not found",
+				Retryable:    false,
+				Details:      map[string]interface{}{"worker_id": workerID},
+			})
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, NewInternalErrorResponse(err.Error()))
+		return
+	}
+
+	if !isWebSocketUpgrade(r) {
+		s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(
+			"WebSocket upgrade required",
+			map[string]interface{}{"path": r.URL.Path},
+		))
+		return
+	}
+	if err := checkWebSocketHandshake(r); err != nil {
+		s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(
+			"Invalid WebSocket handshake",
+			map[string]interface{}{"reason": err.Error()},
+		))
+		return
+	}
+
+	// A hijacked connection is not closed by the server on shutdown and
+	// its request context does not see the client leave.
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	incoming := make(chan []byte, 16)
+	ws, err := acceptWebSocket(w, r, func(data []byte) {
+		select {
+		case incoming <- data:
+		case <-ctx.Done():
+		}
+	})
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, NewInternalErrorResponse("WebSocket not supported"))
+		return
+	}
+
+	notify := s.openWorkerChannel(workerID)
+	defer s.closeWorkerChannel(workerID, notify)
+
+	s.mu.Lock()
+	stopCh := s.stopCh
+	s.mu.Unlock()
+
+	closeCode := s.pumpWorkerChannel(ctx, ws, workerID, notify, incoming, stopCh)
+	ws.Close(closeCode)
+}
+
+// pumpWorkerChannel runs an open control channel until either side ends it
+// and returns the close code to send.
+func (s *Server) pumpWorkerChannel(ctx context.Context, ws *wsConn, workerID string, notify <-chan struct{}, incoming <-chan []byte, stopCh <-chan struct{}) int {
+	send := func(msg types.ChannelMessage) error {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		return ws.WriteText(data)
+	}
+
+	pushAssignments := func() error {
+		assignments := s.getAssignmentsForWorker(workerID)
+		if len(assignments) == 0 {
+			return nil
+		}
+		if s.shouldRedactAssignmentSecrets() {
+			assignments = redactAssignments(assignments)
+		}
+		return send(types.ChannelMessage{Type: types.ChannelMessageAssignments, Assignments: assignments})
+	}
+
+	// Stop lists are sent when they change rather than on every poll.
+	var sentStop, sentImmediate []string
+	pushStops := func() error {
+		stop := sortedRunIDs(s.getStoppingRunsForWorker(workerID))
+		immediate := sortedRunIDs(s.getImmediateStopRunsForWorker(workerID))
+		if slices.Equal(stop, sentStop) && slices.Equal(immediate, sentImmediate) {
+			return nil
+		}
+		sentStop, sentImmediate = stop, immediate
+		if len(stop) == 0 && len(immediate) == 0 {
+			return nil
+		}
+		return send(types.ChannelMessage{Type: types.ChannelMessageStop, StopRunIDs: stop, ImmediateStopRunIDs: immediate})
+	}
+
+	// The paused list is sent whenever it changes, even to empty, since
+	// runs leaving it are resumed.
+	var sentPaused []string
+	pushPauses := func() error {
+		paused := sortedRunIDs(s.getPausedRunsForWorker(workerID))
+		if slices.Equal(paused, sentPaused) {
+			return nil
+		}
+		sentPaused = paused
+		return send(types.ChannelMessage{Type: types.ChannelMessagePause, PausedRunIDs: paused})
+	}
+
+	stopTicker := time.NewTicker(workerChannelStopPollInterval)
+	defer stopTicker.Stop()
+	pingTicker := time.NewTicker(workerChannelPingInterval)
+	defer pingTicker.Stop()
+
+	err := pushAssignments()
+	for err == nil {
+		select {
+		case <-ws.Done():
+			return websocket.CloseNormal
+		case <-ctx.Done():
+			return websocket.CloseNormal
+		case <-stopCh:
+			return websocket.CloseGoingAway
+		case <-notify:
+			err = pushAssignments()
+		case <-stopTicker.C:
+			if err = pushStops(); err == nil {
+				err = pushPauses()
+			}
+		case <-pingTicker.C:
+			err = ws.Ping()
+		case data := <-incoming:
+			var reply *types.ChannelMessage
+			if reply, err = s.handleWorkerChannelMessage(workerID, data); err == nil && reply != nil {
+				err = send(*reply)
+			}
+		}
+	}
+	log.Printf("[Server] Closing control channel of worker %s: %v", workerID, err)
+	return websocket.CloseProtocolError
+}
+
+// handleWorkerChannelMessage applies a message a worker sent on its control
+// channel and returns the reply to send, if any. Unknown message types are
+// ignored.
+func (s *Server) handleWorkerChannelMessage(workerID string, data []byte) (*types.ChannelMessage, error) {
+	var msg types.ChannelMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, err
+	}
+
+	switch msg.Type {
+	case types.ChannelMessageHeartbeat:
+		if err := s.registry.Heartbeat(scheduler.WorkerID(workerID), msg.Health); err != nil {
+			return nil, err
+		}
+		if s.leaseManager != nil {
+			_ = s.leaseManager.RenewWorkerLeases(scheduler.WorkerID(workerID))
+		}
+		s.recordSecretAccesses(workerID, msg.SecretAccesses)
+		token, expiresAtMs, err := s.rotateWorkerToken(workerID)
+		if err != nil {
+			log.Printf("[Server] Failed to rotate token of worker %s: %v", workerID, err)
+		}
+		if token != "" {
+			return &types.ChannelMessage{Type: types.ChannelMessageToken, WorkerToken: token, WorkerTokenExpiresAtMs: expiresAtMs}, nil
+		}
+	case types.ChannelMessageAck:
+		s.ackAssignmentsForWorker(workerID, msg.LeaseIDs)
+	}
+	return nil, nil
+}
+
+// openWorkerChannel registers a control channel for workerID and returns
+// the channel signalled when assignments are queued for it. A newer
+// connection of the same worker takes over from an older one.
+func (s *Server) openWorkerChannel(workerID string) chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.workerChannels == nil {
+		s.workerChannels = make(map[string]chan struct{})
+	}
+	notify := make(chan struct{}, 1)
+	s.workerChannels[workerID] = notify
+	return notify
+}
+
+func (s *Server) closeWorkerChannel(workerID string, notify chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.workerChannels[workerID] == notify {
+		delete(s.workerChannels, workerID)
+	}
+}
+
+func sortedRunIDs(runIDs []string) []string {
+	sort.Strings(runIDs)
+	return runIDs
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/scheduler"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+	"github.com/bc-dunia/mcpdrill/internal/worker"
+)
+
+func registerForChannel(t *testing.T, server *Server, channels []string) RegisterWorkerResponse {
+	t.Helper()
+	body, _ := json.Marshal(RegisterWorkerRequest{
+		HostInfo:        types.HostInfo{Hostname: "worker-host"},
+		Capacity:        types.WorkerCapacity{MaxVUs: 10},
+		ControlChannels: channels,
+	})
+	httpReq := httptest.NewRequest(http.MethodPost, "/workers/register", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	server.handleRegisterWorker(w, httpReq)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, w.Code)
+	}
+	var resp RegisterWorkerResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return resp
+}
+
+func TestRegisterWorker_NegotiatesControlChannel(t *testing.T) {
+	server, _ := setupWorkerTestServer(t)
+
+	if resp := registerForChannel(t, server, []string{types.ControlChannelWebSocket}); resp.ControlChannel != types.ControlChannelWebSocket {
+		t.Errorf("expected websocket control channel, got %q", resp.ControlChannel)
+	}
+	if resp := registerForChannel(t, server, nil); resp.ControlChannel != "" {
+		t.Errorf("expected polling for a worker without channel support, got %q", resp.ControlChannel)
+	}
+
+	server.SetWorkerChannelEnabled(false)
+	if resp := registerForChannel(t, server, []string{types.ControlChannelWebSocket}); resp.ControlChannel != "" {
+		t.Errorf("expected polling with the channel disabled, got %q", resp.ControlChannel)
+	}
+}
+
+func TestWorkerChannel_PushesAssignmentsAndTakesHeartbeats(t *testing.T) {
+	server, registry := setupWorkerTestServer(t)
+	server.SetWorkerAuthEnabled(true)
+	httpServer := httptest.NewServer(http.HandlerFunc(server.routeWorkers))
+	defer httpServer.Close()
+
+	resp := registerForChannel(t, server, []string{types.ControlChannelWebSocket})
+	ctx := context.Background()
+
+	if _, err := worker.DialControlChannel(ctx, httpServer.URL, resp.WorkerID, "wrong-token", nil); err == nil {
+		t.Fatal("expected a wrong worker token to be refused")
+	}
+
+	ch, err := worker.DialControlChannel(ctx, httpServer.URL, resp.WorkerID, resp.WorkerToken, nil)
+	if err != nil {
+		t.Fatalf("DialControlChannel failed: %v", err)
+	}
+	defer ch.Close()
+
+	// Wait for the channel to be registered before queueing an assignment.
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		server.mu.Lock()
+		_, open := server.workerChannels[resp.WorkerID]
+		server.mu.Unlock()
+		if open {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("control channel was not registered")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	server.AddAssignment(resp.WorkerID, types.WorkerAssignment{RunID: "run_0000000000000001", LeaseID: "lease-1"})
+
+	msg, err := ch.Receive()
+	if err != nil {
+		t.Fatalf("Receive failed: %v", err)
+	}
+	if msg.Type != types.ChannelMessageAssignments || len(msg.Assignments) != 1 || msg.Assignments[0].LeaseID != "lease-1" {
+		t.Fatalf("expected the queued assignment, got %+v", msg)
+	}
+
+	if err := ch.Send(types.ChannelMessage{Type: types.ChannelMessageAck, LeaseIDs: []string{"lease-1"}}); err != nil {
+		t.Fatalf("Send ack failed: %v", err)
+	}
+	if err := ch.Send(types.ChannelMessage{Type: types.ChannelMessageHeartbeat, Health: &types.WorkerHealth{ActiveVUs: 7}}); err != nil {
+		t.Fatalf("Send heartbeat failed: %v", err)
+	}
+
+	for {
+		server.mu.Lock()
+		pending := len(server.pendingAck[resp.WorkerID])
+		server.mu.Unlock()
+		info, err := registry.GetWorker(scheduler.WorkerID(resp.WorkerID))
+		if err != nil {
+			t.Fatalf("GetWorker failed: %v", err)
+		}
+		if pending == 0 && info.Health != nil && info.Health.ActiveVUs == 7 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected ack and heartbeat to be applied, %d assignments pending, health %+v", pending, info.Health)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestWorkerChannel_TLSAndTokenRotation(t *testing.T) {
+	server, _ := setupWorkerTestServer(t)
+	server.SetWorkerAuthEnabled(true)
+	server.SetWorkerTokenTTL(time.Hour)
+	httpServer := httptest.NewTLSServer(http.HandlerFunc(server.routeWorkers))
+	defer httpServer.Close()
+
+	resp := registerForChannel(t, server, []string{types.ControlChannelWebSocket})
+	ctx := context.Background()
+
+	if _, err := worker.DialControlChannel(ctx, httpServer.URL, resp.WorkerID, resp.WorkerToken, nil); err == nil {
+		t.Fatal("expected a certificate from an untrusted CA to be refused")
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(httpServer.Certificate())
+	ch, err := worker.DialControlChannel(ctx, httpServer.URL, resp.WorkerID, resp.WorkerToken, &tls.Config{RootCAs: pool})
+	if err != nil {
+		t.Fatalf("DialControlChannel with the pinned CA failed: %v", err)
+	}
+	defer ch.Close()
+
+	server.mu.Lock()
+	server.workerTokens[resp.WorkerID].expiresAt = time.Now().Add(time.Minute)
+	server.mu.Unlock()
+	if err := ch.Send(types.ChannelMessage{Type: types.ChannelMessageHeartbeat, Health: &types.WorkerHealth{}}); err != nil {
+		t.Fatalf("Send heartbeat failed: %v", err)
+	}
+	msg, err := ch.Receive()
+	if err != nil {
+		t.Fatalf("Receive failed: %v", err)
+	}
+	if msg.Type != types.ChannelMessageToken || msg.WorkerToken == "" || msg.WorkerToken == resp.WorkerToken || msg.WorkerTokenExpiresAtMs == 0 {
+		t.Fatalf("expected a rotated token in reply to the heartbeat, got %+v", msg)
+	}
+}
+
+func TestWorkerChannel_RequiresUpgrade(t *testing.T) {
+	server, registry := setupWorkerTestServer(t)
+	workerID, token := registerWorkerWithToken(t, server, registry, "worker-1")
+
+	httpReq := httptest.NewRequest(http.MethodGet, "/workers/"+string(workerID)+"/channel", nil)
+	httpReq.Header.Set("X-Worker-Token", token)
+	w := httptest.NewRecorder()
+
+	server.routeWorkers(w, httpReq)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
@@ -1,16 +1,16 @@
 package api
 
 import (
-	"crypto/rand"
-	"crypto/subtle"
-	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"log"
 	"net/http"
 	"regexp"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/bc-dunia/mcpdrill/internal/auth"
 	"github.com/bc-dunia/mcpdrill/internal/controlplane/scheduler"
 	"github.com/bc-dunia/mcpdrill/internal/types"
 )
@@ -44,7 +44,16 @@ func (s *Server) handleListWorkers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// A project sees its own workers and the shared ones.
 	workers := s.registry.ListWorkers()
+	project := requestProject(r)
+	visible := workers[:0]
+	for _, worker := range workers {
+		if worker.ServesProject(project) {
+			visible = append(visible, worker)
+		}
+	}
+	workers = visible
 	sort.Slice(workers, func(i, j int) bool {
 		return workers[i].WorkerID < workers[j].WorkerID
 	})
@@ -52,6 +61,45 @@ func (s *Server) handleListWorkers(w http.ResponseWriter, r *http.Request) {
 	s.writeJSON(w, http.StatusOK, &ListWorkersResponse{Workers: workers})
 }
 
+// handleCapacity serves GET /workers/capacity: the VUs and workers every
+// unfinished run of the request's project needs at its peak against those
+// that may run them, for autoscalers to act on before runs are started.
+func (s *Server) handleCapacity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeMethodNotAllowed(w, r.Method, "GET")
+		return
+	}
+
+	project := requestProject(r)
+	resp := &CapacityResponse{Runs: []RunCapacity{}}
+	if s.registry != nil {
+		for _, worker := range s.registry.ListWorkers() {
+			if worker.ServesProject(project) {
+				resp.AvailableWorkers++
+				resp.AvailableVUs += worker.EffectiveCapacity.MaxVUs
+			}
+		}
+	}
+
+	if s.runManager != nil {
+		for _, req := range s.runManager.PendingCapacity() {
+			if s.runManager.GetRunProject(req.RunID) != project {
+				continue
+			}
+			run := RunCapacity{
+				CapacityRequirement: req,
+				ShortfallVUs:        max(req.RequiredVUs-resp.AvailableVUs, 0),
+				ShortfallWorkers:    max(req.RequiredWorkers-resp.AvailableWorkers, 0),
+			}
+			resp.ShortfallVUs = max(resp.ShortfallVUs, run.ShortfallVUs)
+			resp.ShortfallWorkers = max(resp.ShortfallWorkers, run.ShortfallWorkers)
+			resp.Runs = append(resp.Runs, run)
+		}
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
 func (s *Server) handleRegisterWorker(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		s.writeMethodNotAllowed(w, r.Method, "POST")
@@ -88,7 +136,35 @@ func (s *Server) handleRegisterWorker(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	workerID, err := s.registry.RegisterWorker(req.HostInfo, req.Capacity)
+	project := req.Project
+	if user := auth.GetUserFromContext(r.Context()); project == "" && user != nil && user.Projects != nil {
+		project = requestProject(r)
+	}
+	if project != "" && !types.ValidProjectName(project) {
+		s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(
+			"project must be 1-63 lowercase letters, digits or '-'",
+			map[string]interface{}{"field": "project"},
+		))
+		return
+	}
+	if project != "" && s.authEnabled() && !auth.GetUserFromContext(r.Context()).CanAccessProject(project) {
+		s.writeError(w, http.StatusForbidden, NewErrorResponse(ErrorTypeForbidden, "PROJECT_FORBIDDEN",
+			fmt.Sprintf("Not allowed to register workers in project %q", project), false,
+			map[string]interface{}{"project": project}))
+		return
+	}
+
+	for key, value := range req.HostInfo.Labels {
+		if !types.ValidWorkerLabel(key, value) {
+			s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(
+				"worker labels must be 1-63 letters, digits, '_', '.' or '-', with lowercase keys",
+				map[string]interface{}{"field": "host_info.labels", "label": key},
+			))
+			return
+		}
+	}
+
+	workerID, err := s.registry.RegisterProjectWorker(project, req.HostInfo, req.Capacity)
 	if err != nil {
 		s.writeError(w, http.StatusInternalServerError, NewInternalErrorResponse(err.Error()))
 		return
@@ -101,11 +177,26 @@ func (s *Server) handleRegisterWorker(w http.ResponseWriter, r *http.Request) {
 	}
 
 	s.writeJSON(w, http.StatusCreated, &RegisterWorkerResponse{
-		WorkerID:    string(workerID),
-		WorkerToken: workerToken,
+		WorkerID:               string(workerID),
+		WorkerToken:            workerToken,
+		WorkerTokenExpiresAtMs: s.workerTokenExpiresAtMs(string(workerID)),
+		ControlChannel:         s.negotiateControlChannel(req.ControlChannels),
 	})
 }
 
+// recordSecretAccesses adds the secret accesses a worker reported to the
+// event logs of their runs.
+func (s *Server) recordSecretAccesses(workerID string, accesses []types.SecretAccess) {
+	if s.runManager == nil {
+		return
+	}
+	for _, access := range accesses {
+		if err := s.runManager.RecordSecretAccess(access.RunID, workerID, access.Ref, access.AtMs); err != nil {
+			log.Printf("[Server] Dropping secret access of worker %s: %v", workerID, err)
+		}
+	}
+}
+
 func (s *Server) handleWorkerHeartbeat(w http.ResponseWriter, r *http.Request, workerID string) {
 	if r.Method != http.MethodPost {
 		s.writeMethodNotAllowed(w, r.Method, "POST")
@@ -149,14 +240,22 @@ func (s *Server) handleWorkerHeartbeat(w http.ResponseWriter, r *http.Request, w
 	if s.leaseManager != nil {
 		_ = s.leaseManager.RenewWorkerLeases(scheduler.WorkerID(workerID))
 	}
+	s.recordSecretAccesses(workerID, req.SecretAccesses)
 
 	stopRunIDs := s.getStoppingRunsForWorker(workerID)
 	immediateStopRunIDs := s.getImmediateStopRunsForWorker(workerID)
+	token, expiresAtMs, err := s.rotateWorkerToken(workerID)
+	if err != nil {
+		log.Printf("[Server] Failed to rotate token of worker %s: %v", workerID, err)
+	}
 
 	s.writeJSON(w, http.StatusOK, &HeartbeatResponse{
-		OK:                  true,
-		StopRunIDs:          stopRunIDs,
-		ImmediateStopRunIDs: immediateStopRunIDs,
+		OK:                     true,
+		StopRunIDs:             stopRunIDs,
+		ImmediateStopRunIDs:    immediateStopRunIDs,
+		PausedRunIDs:           s.getPausedRunsForWorker(workerID),
+		WorkerToken:            token,
+		WorkerTokenExpiresAtMs: expiresAtMs,
 	})
 }
 
@@ -174,6 +273,13 @@ func (s *Server) getImmediateStopRunsForWorker(workerID string) []string {
 	return s.runManager.ListImmediateStopRunsForWorker(workerID)
 }
 
+func (s *Server) getPausedRunsForWorker(workerID string) []string {
+	if s.runManager == nil {
+		return nil
+	}
+	return s.runManager.ListPausedRunsForWorker(workerID)
+}
+
 func (s *Server) handleWorkerTelemetry(w http.ResponseWriter, r *http.Request, workerID string) {
 	if r.Method != http.MethodPost {
 		s.writeMethodNotAllowed(w, r.Method, "POST")
@@ -223,24 +329,118 @@ func (s *Server) handleWorkerTelemetry(w http.ResponseWriter, r *http.Request, w
 		_ = s.registry.Heartbeat(scheduler.WorkerID(workerID), req.Health)
 	}
 
-	if s.telemetryStore != nil && len(req.Operations) > 0 {
+	summarized := summarizedOperations(req.Summaries)
+	if s.telemetryStore != nil && len(req.Operations)+len(req.Summaries)+len(req.Samples)+len(req.GeneratorWarnings) > 0 {
 		// Add worker context to each operation before storing
 		for i := range req.Operations {
 			if req.Operations[i].WorkerID == "" {
 				req.Operations[i].WorkerID = workerID
 			}
 		}
+		for i := range req.Samples {
+			if req.Samples[i].WorkerID == "" {
+				req.Samples[i].WorkerID = workerID
+			}
+		}
+		for i := range req.GeneratorWarnings {
+			if req.GeneratorWarnings[i].WorkerID == "" {
+				req.GeneratorWarnings[i].WorkerID = workerID
+			}
+		}
 		runID := s.extractRunIDFromTelemetry(req)
 		if runID != "" {
 			s.telemetryStore.AddTelemetryBatch(runID, req)
 		}
 	}
 
-	s.writeJSON(w, http.StatusOK, &TelemetryBatchResponse{Accepted: len(req.Operations)})
+	s.writeJSON(w, http.StatusOK, &TelemetryBatchResponse{Accepted: len(req.Operations) + summarized})
+}
+
+// handleWorkerTargetProfile records the profile of a run's target a worker
+// took during preflight.
+func (s *Server) handleWorkerTargetProfile(w http.ResponseWriter, r *http.Request, workerID string) {
+	if r.Method != http.MethodPost {
+		s.writeMethodNotAllowed(w, r.Method, "POST")
+		return
+	}
+
+	if !s.verifyWorkerToken(w, r, workerID) {
+		return
+	}
+
+	var req TargetProfileRequest
+	if err := json.NewDecoder(limitedBody(w, r)).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(
+			"Invalid JSON request body",
+			map[string]interface{}{"parse_error": err.Error()},
+		))
+		return
+	}
+	if req.RunID == "" || req.Profile == nil {
+		s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(
+			"run_id and profile are required",
+			map[string]interface{}{"run_id": req.RunID},
+		))
+		return
+	}
+
+	if s.runManager == nil {
+		s.writeError(w, http.StatusInternalServerError, NewInternalErrorResponse("run manager not configured"))
+		return
+	}
+
+	recorded, err := s.runManager.RecordTargetProfile(req.RunID, workerID, req.Profile)
+	if err != nil {
+		s.handleRunManagerError(w, req.RunID, "record target profile", err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, &TargetProfileResponse{Recorded: recorded})
+}
+
+// handleWorkerConformanceReport records the protocol checks a worker ran
+// against the target of a conformance run.
+func (s *Server) handleWorkerConformanceReport(w http.ResponseWriter, r *http.Request, workerID string) {
+	if r.Method != http.MethodPost {
+		s.writeMethodNotAllowed(w, r.Method, "POST")
+		return
+	}
+
+	if !s.verifyWorkerToken(w, r, workerID) {
+		return
+	}
+
+	var req ConformanceReportRequest
+	if err := json.NewDecoder(limitedBody(w, r)).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(
+			"Invalid JSON request body",
+			map[string]interface{}{"parse_error": err.Error()},
+		))
+		return
+	}
+	if req.RunID == "" || req.Report == nil {
+		s.writeError(w, http.StatusBadRequest, NewInvalidRequestErrorResponse(
+			"run_id and report are required",
+			map[string]interface{}{"run_id": req.RunID},
+		))
+		return
+	}
+
+	if s.runManager == nil {
+		s.writeError(w, http.StatusInternalServerError, NewInternalErrorResponse("run manager not configured"))
+		return
+	}
+
+	recorded, err := s.runManager.RecordConformanceReport(req.RunID, workerID, req.Report)
+	if err != nil {
+		s.handleRunManagerError(w, req.RunID, "record conformance report", err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, &ConformanceReportResponse{Recorded: recorded})
 }
 
 // validateTelemetryCorrelationKeys validates required correlation keys in telemetry batch.
-// Required keys: run_id (batch level), execution_id, stage, stage_id, worker_id (per operation or inferred).
+// Required keys: run_id (batch level), execution_id, stage, stage_id, worker_id (per operation,
+// sample and summary, or inferred). Summary counts must also be consistent.
 // Also validates format of IDs and stage against allowed enum.
 func (s *Server) validateTelemetryCorrelationKeys(req TelemetryBatchRequest, workerID string) *ErrorResponse {
 	var missingKeys []string
@@ -253,81 +453,42 @@ func (s *Server) validateTelemetryCorrelationKeys(req TelemetryBatchRequest, wor
 		invalidKeys = append(invalidKeys, "run_id")
 	}
 
-	// Check each operation for required keys
+	// Check each operation, sample and summary for required keys
 	for i, op := range req.Operations {
-		var opMissing []string
-		var opInvalid []string
-
-		// worker_id must match URL path workerID if provided (prevent spoofing)
-		// Reject if op.WorkerID is set but does not match the authenticated workerID
-		if op.WorkerID != "" && op.WorkerID != workerID {
-			return &ErrorResponse{
-				ErrorType:    ErrorTypeInvalidArgument,
-				ErrorCode:    "INVALID_TELEMETRY",
-				ErrorMessage: "worker_id mismatch: operation worker_id does not match authenticated worker",
-				Retryable:    false,
-				Details: map[string]interface{}{
-					"operation_index": i,
-					"expected":        workerID,
-					"got":             op.WorkerID,
-				},
-			}
-		}
-		effectiveWorkerID := op.WorkerID
-		if effectiveWorkerID == "" {
-			effectiveWorkerID = workerID
-		}
-		if effectiveWorkerID == "" {
-			opMissing = append(opMissing, "worker_id")
-		} else if !workerIDPattern.MatchString(effectiveWorkerID) {
-			opInvalid = append(opInvalid, "worker_id")
-		}
-
-		// execution_id is required
-		if op.ExecutionID == "" {
-			opMissing = append(opMissing, "execution_id")
-		} else if !executionIDPattern.MatchString(op.ExecutionID) {
-			opInvalid = append(opInvalid, "execution_id")
+		if errResp := validateOperationCorrelationKeys("operation_index", i, op.WorkerID, op.ExecutionID, op.Stage, op.StageID, workerID); errResp != nil {
+			return errResp
 		}
-
-		// stage is required and must be valid enum
-		if op.Stage == "" {
-			opMissing = append(opMissing, "stage")
-		} else if !allowedStages[op.Stage] {
-			opInvalid = append(opInvalid, "stage")
+	}
+	for i, op := range req.Samples {
+		if errResp := validateOperationCorrelationKeys("sample_index", i, op.WorkerID, op.ExecutionID, op.Stage, op.StageID, workerID); errResp != nil {
+			return errResp
 		}
-
-		// stage_id is required
-		if op.StageID == "" {
-			opMissing = append(opMissing, "stage_id")
-		} else if !stageIDPattern.MatchString(op.StageID) {
-			opInvalid = append(opInvalid, "stage_id")
+	}
+	for i, summary := range req.Summaries {
+		if errResp := validateOperationCorrelationKeys("summary_index", i, summary.WorkerID, summary.ExecutionID, summary.Stage, summary.StageID, workerID); errResp != nil {
+			return errResp
 		}
-
-		if len(opMissing) > 0 {
+		if err := validateOperationSummary(summary); err != nil {
 			return &ErrorResponse{
 				ErrorType:    ErrorTypeInvalidArgument,
 				ErrorCode:    "INVALID_TELEMETRY",
-				ErrorMessage: "Missing required correlation keys in telemetry",
+				ErrorMessage: "Invalid operation summary in telemetry: " + err.Error(),
 				Retryable:    false,
 				Details: map[string]interface{}{
-					"operation_index": i,
-					"missing_keys":    opMissing,
+					"summary_index": i,
 				},
 			}
 		}
-
-		if len(opInvalid) > 0 {
-			return &ErrorResponse{
-				ErrorType:    ErrorTypeInvalidArgument,
-				ErrorCode:    "INVALID_TELEMETRY",
-				ErrorMessage: "Invalid correlation key format in telemetry",
-				Retryable:    false,
-				Details: map[string]interface{}{
-					"operation_index": i,
-					"invalid_keys":    opInvalid,
-				},
-			}
+	}
+	if total := summarizedOperations(req.Summaries); total > maxSummarizedOpsPerBatch {
+		return &ErrorResponse{
+			ErrorType:    ErrorTypeInvalidArgument,
+			ErrorCode:    "INVALID_TELEMETRY",
+			ErrorMessage: fmt.Sprintf("Telemetry summaries cover %d operations, more than %d per batch", total, maxSummarizedOpsPerBatch),
+			Retryable:    false,
+			Details: map[string]interface{}{
+				"summarized_operations": total,
+			},
 		}
 	}
 
@@ -358,6 +519,87 @@ func (s *Server) validateTelemetryCorrelationKeys(req TelemetryBatchRequest, wor
 	return nil
 }
 
+// validateOperationCorrelationKeys validates the correlation keys of one
+// operation, sample or summary of a telemetry batch. indexKey and i locate
+// it in error details.
+func validateOperationCorrelationKeys(indexKey string, i int, opWorkerID, executionID, stage, stageID, workerID string) *ErrorResponse {
+	var opMissing []string
+	var opInvalid []string
+
+	// worker_id must match URL path workerID if provided (prevent spoofing)
+	// Reject if the worker_id is set but does not match the authenticated workerID
+	if opWorkerID != "" && opWorkerID != workerID {
+		return &ErrorResponse{
+			ErrorType:    ErrorTypeInvalidArgument,
+			ErrorCode:    "INVALID_TELEMETRY",
+			ErrorMessage: "worker_id mismatch: operation worker_id does not match authenticated worker",
+			Retryable:    false,
+			Details: map[string]interface{}{
+				indexKey:   i,
+				"expected": workerID,
+				"got":      opWorkerID,
+			},
+		}
+	}
+	effectiveWorkerID := opWorkerID
+	if effectiveWorkerID == "" {
+		effectiveWorkerID = workerID
+	}
+	if effectiveWorkerID == "" {
+		opMissing = append(opMissing, "worker_id")
+	} else if !workerIDPattern.MatchString(effectiveWorkerID) {
+		opInvalid = append(opInvalid, "worker_id")
+	}
+
+	// execution_id is required
+	if executionID == "" {
+		opMissing = append(opMissing, "execution_id")
+	} else if !executionIDPattern.MatchString(executionID) {
+		opInvalid = append(opInvalid, "execution_id")
+	}
+
+	// stage is required and must be valid enum
+	if stage == "" {
+		opMissing = append(opMissing, "stage")
+	} else if !allowedStages[stage] {
+		opInvalid = append(opInvalid, "stage")
+	}
+
+	// stage_id is required
+	if stageID == "" {
+		opMissing = append(opMissing, "stage_id")
+	} else if !stageIDPattern.MatchString(stageID) {
+		opInvalid = append(opInvalid, "stage_id")
+	}
+
+	if len(opMissing) > 0 {
+		return &ErrorResponse{
+			ErrorType:    ErrorTypeInvalidArgument,
+			ErrorCode:    "INVALID_TELEMETRY",
+			ErrorMessage: "Missing required correlation keys in telemetry",
+			Retryable:    false,
+			Details: map[string]interface{}{
+				indexKey:       i,
+				"missing_keys": opMissing,
+			},
+		}
+	}
+
+	if len(opInvalid) > 0 {
+		return &ErrorResponse{
+			ErrorType:    ErrorTypeInvalidArgument,
+			ErrorCode:    "INVALID_TELEMETRY",
+			ErrorMessage: "Invalid correlation key format in telemetry",
+			Retryable:    false,
+			Details: map[string]interface{}{
+				indexKey:       i,
+				"invalid_keys": opInvalid,
+			},
+		}
+	}
+	return nil
+}
+
 func (s *Server) handleGetAssignments(w http.ResponseWriter, r *http.Request, workerID string) {
 	if r.Method != http.MethodGet {
 		s.writeMethodNotAllowed(w, r.Method, "GET")
@@ -531,55 +773,6 @@ func (s *Server) extractRunIDFromTelemetry(req TelemetryBatchRequest) string {
 	return req.RunID
 }
 
-func (s *Server) issueWorkerToken(workerID string) (string, error) {
-	tokenBytes := make([]byte, 32)
-	if _, err := rand.Read(tokenBytes); err != nil {
-		return "", err
-	}
-	token := hex.EncodeToString(tokenBytes)
-
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	if s.workerTokens == nil {
-		s.workerTokens = make(map[string]string)
-	}
-	s.workerTokens[workerID] = token
-	return token, nil
-}
-
-func (s *Server) verifyWorkerToken(w http.ResponseWriter, r *http.Request, workerID string) bool {
-	if !s.isWorkerAuthEnabled() {
-		return true
-	}
-
-	// Security: worker endpoints carry assignment secrets, so require a worker token.
-	token := r.Header.Get("X-Worker-Token")
-	if token == "" {
-		s.writeError(w, http.StatusUnauthorized, &ErrorResponse{
-			ErrorType:    ErrorTypeUnauthorized,
-			ErrorCode:    "WORKER_AUTH_REQUIRED",
-			ErrorMessage: "Worker token required",
-			Retryable:    false,
-		})
-		return false
-	}
-
-	s.mu.Lock()
-	expected, ok := s.workerTokens[workerID]
-	s.mu.Unlock()
-	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
-		s.writeError(w, http.StatusUnauthorized, &ErrorResponse{
-			ErrorType:    ErrorTypeUnauthorized,
-			ErrorCode:    "INVALID_WORKER_TOKEN",
-			ErrorMessage: "Invalid worker token",
-			Retryable:    false,
-		})
-		return false
-	}
-
-	return true
-}
-
 func redactAssignments(assignments []types.WorkerAssignment) []types.WorkerAssignment {
 	if len(assignments) == 0 {
 		return assignments
@@ -602,6 +795,7 @@ func redactAssignments(assignments []types.WorkerAssignment) []types.WorkerAssig
 			redacted[i].Target.Auth = &types.AuthConfig{
 				Type:   assignment.Target.Auth.Type,
 				Tokens: []string{"[redacted]"},
+				OAuth2: assignment.Target.Auth.OAuth2,
 			}
 		}
 	}
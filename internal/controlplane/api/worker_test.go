@@ -7,7 +7,10 @@ import (
 	"net/http/httptest"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/runmanager"
 	"github.com/bc-dunia/mcpdrill/internal/controlplane/scheduler"
 	"github.com/bc-dunia/mcpdrill/internal/types"
 )
@@ -108,6 +111,47 @@ func TestListWorkers_Success(t *testing.T) {
 	}
 }
 
+func TestCapacity_ReportsShortfall(t *testing.T) {
+	registry := scheduler.NewRegistry()
+	rm := newTestRunManager(t)
+	server := NewServer("127.0.0.1:0", rm)
+	server.SetRegistry(registry)
+
+	runID, err := rm.CreateRun(loadValidConfig(t), "test")
+	if err != nil {
+		t.Fatalf("CreateRun failed: %v", err)
+	}
+	if _, err := registry.RegisterWorker(types.HostInfo{Hostname: "worker-1"}, types.WorkerCapacity{MaxVUs: 20}); err != nil {
+		t.Fatalf("failed to register worker: %v", err)
+	}
+
+	httpReq := httptest.NewRequest(http.MethodGet, "/workers/capacity", nil)
+	w := httptest.NewRecorder()
+
+	server.handleCapacity(w, httpReq)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var resp CapacityResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.AvailableVUs != 20 || resp.AvailableWorkers != 1 {
+		t.Errorf("expected 20 VUs on 1 worker available, got %d VUs on %d workers", resp.AvailableVUs, resp.AvailableWorkers)
+	}
+	if len(resp.Runs) != 1 || resp.Runs[0].RunID != runID {
+		t.Fatalf("expected run %s to be pending, got %+v", runID, resp.Runs)
+	}
+	if resp.Runs[0].RequiredVUs != 50 || resp.Runs[0].ShortfallVUs != 30 || resp.ShortfallVUs != 30 {
+		t.Errorf("expected 50 VUs required and 30 short, got %+v (total shortfall %d)", resp.Runs[0], resp.ShortfallVUs)
+	}
+	if resp.ShortfallWorkers != 0 {
+		t.Errorf("expected no worker shortfall, got %d", resp.ShortfallWorkers)
+	}
+}
+
 func TestRegisterWorker_InvalidJSON(t *testing.T) {
 	server, _ := setupWorkerTestServer(t)
 
@@ -180,6 +224,45 @@ func TestRegisterWorker_InvalidCapacity(t *testing.T) {
 	}
 }
 
+func TestRegisterWorker_Labels(t *testing.T) {
+	server, registry := setupWorkerTestServer(t)
+
+	register := func(labels map[string]string) *httptest.ResponseRecorder {
+		req := RegisterWorkerRequest{
+			HostInfo: types.HostInfo{Hostname: "worker-1", Labels: labels},
+			Capacity: types.WorkerCapacity{MaxVUs: 100},
+		}
+		body, _ := json.Marshal(req)
+		httpReq := httptest.NewRequest(http.MethodPost, "/workers/register", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		server.handleRegisterWorker(w, httpReq)
+		return w
+	}
+
+	if w := register(map[string]string{"Region": "eu-west-1"}); w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for an uppercase label key, got %d", http.StatusBadRequest, w.Code)
+	}
+	if w := register(map[string]string{"region": ""}); w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for an empty label value, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	w := register(map[string]string{"region": "eu-west-1", "zone": "eu-west-1a"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, w.Code)
+	}
+	var resp RegisterWorkerResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	worker, err := registry.GetWorker(scheduler.WorkerID(resp.WorkerID))
+	if err != nil {
+		t.Fatalf("GetWorker failed: %v", err)
+	}
+	if worker.HostInfo.Labels["region"] != "eu-west-1" || worker.HostInfo.Labels["zone"] != "eu-west-1a" {
+		t.Errorf("expected the labels to be registered, got %v", worker.HostInfo.Labels)
+	}
+}
+
 func TestRegisterWorker_MethodNotAllowed(t *testing.T) {
 	server, _ := setupWorkerTestServer(t)
 
@@ -248,6 +331,98 @@ func TestHeartbeat_Success(t *testing.T) {
 	}
 }
 
+func TestHeartbeat_RotatesWorkerToken(t *testing.T) {
+	server, registry := setupWorkerTestServer(t)
+	server.SetWorkerAuthEnabled(true)
+	server.SetWorkerTokenTTL(time.Hour)
+	workerID, token := registerWorkerWithToken(t, server, registry, "worker-1")
+
+	heartbeat := func(token string) (int, HeartbeatResponse, string) {
+		t.Helper()
+		httpReq := httptest.NewRequest(http.MethodPost, "/workers/"+string(workerID)+"/heartbeat", nil)
+		httpReq.Header.Set("X-Worker-Token", token)
+		w := httptest.NewRecorder()
+		server.handleWorkerHeartbeat(w, httpReq, string(workerID))
+		var resp HeartbeatResponse
+		var errResp ErrorResponse
+		if w.Code == http.StatusOK {
+			json.NewDecoder(w.Body).Decode(&resp)
+		} else {
+			json.NewDecoder(w.Body).Decode(&errResp)
+		}
+		return w.Code, resp, errResp.ErrorCode
+	}
+	age := func(change func(*workerCredential)) {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		change(server.workerTokens[string(workerID)])
+	}
+
+	if code, resp, _ := heartbeat(token); code != http.StatusOK || resp.WorkerToken != "" {
+		t.Fatalf("expected a fresh token to be kept, got status %d and token %q", code, resp.WorkerToken)
+	}
+
+	age(func(c *workerCredential) { c.expiresAt = time.Now().Add(10 * time.Minute) })
+	code, resp, _ := heartbeat(token)
+	if code != http.StatusOK || resp.WorkerToken == "" || resp.WorkerToken == token {
+		t.Fatalf("expected a new token past half the TTL, got status %d and token %q", code, resp.WorkerToken)
+	}
+	if remaining := time.Until(time.UnixMilli(resp.WorkerTokenExpiresAtMs)); remaining < 59*time.Minute {
+		t.Errorf("expected the new token to last the full TTL, expires in %v", remaining)
+	}
+	rotated := resp.WorkerToken
+
+	if code, _, _ := heartbeat(token); code != http.StatusOK {
+		t.Errorf("expected the previous token to be accepted during the grace period, got status %d", code)
+	}
+	age(func(c *workerCredential) { c.previousUntil = time.Now().Add(-time.Second) })
+	if code, _, errCode := heartbeat(token); code != http.StatusUnauthorized || errCode != "INVALID_WORKER_TOKEN" {
+		t.Errorf("expected the stale token to be refused, got status %d %s", code, errCode)
+	}
+	if code, _, _ := heartbeat(rotated); code != http.StatusOK {
+		t.Errorf("expected the rotated token to be accepted, got status %d", code)
+	}
+
+	age(func(c *workerCredential) { c.expiresAt = time.Now().Add(-time.Second) })
+	if code, _, errCode := heartbeat(rotated); code != http.StatusUnauthorized || errCode != "WORKER_TOKEN_EXPIRED" {
+		t.Errorf("expected the expired token to be refused, got status %d %s", code, errCode)
+	}
+}
+
+func TestHeartbeat_RecordsSecretAccesses(t *testing.T) {
+	server, registry := setupWorkerTestServer(t)
+	rm := newTestRunManager(t)
+	server.runManager = rm
+	runID, err := rm.CreateRun(loadValidConfig(t), "test")
+	if err != nil {
+		t.Fatalf("failed to create run: %v", err)
+	}
+	workerID, token := registerWorkerWithToken(t, server, registry, "worker-1")
+
+	body, _ := json.Marshal(HeartbeatRequest{SecretAccesses: []types.SecretAccess{
+		{RunID: runID, Ref: "vault://secret/mcpdrill#client_secret", AtMs: 1700000000000},
+		{RunID: "run_0000000000000000", Ref: "env://MCPDRILL_TOKEN"},
+	}})
+	httpReq := httptest.NewRequest(http.MethodPost, "/workers/"+string(workerID)+"/heartbeat", bytes.NewReader(body))
+	httpReq.Header.Set("X-Worker-Token", token)
+	w := httptest.NewRecorder()
+	server.handleWorkerHeartbeat(w, httpReq, string(workerID))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	events, _ := rm.TailEvents(runID, 0, 100)
+	var payload map[string]interface{}
+	for _, event := range events {
+		if event.Type == runmanager.EventTypeSecretAccessed {
+			json.Unmarshal(event.Payload, &payload)
+		}
+	}
+	if payload["secret_ref"] != "vault://secret/mcpdrill#client_secret" || payload["backend"] != "vault" || payload["worker_id"] != string(workerID) {
+		t.Errorf("unexpected SECRET_ACCESSED payload %v", payload)
+	}
+}
+
 func TestHeartbeat_EmptyBody(t *testing.T) {
 	server, registry := setupWorkerTestServer(t)
 
@@ -664,3 +839,84 @@ func TestTelemetry_UpdatesHealth(t *testing.T) {
 		t.Errorf("expected CPU percent 75.5, got %f", worker.Health.CPUPercent)
 	}
 }
+
+func TestTargetProfile_RecordsFirstProfile(t *testing.T) {
+	server, registry := setupWorkerTestServer(t)
+	rm := newTestRunManager(t)
+	server.runManager = rm
+	runID, err := rm.CreateRun(loadValidConfig(t), "test")
+	if err != nil {
+		t.Fatalf("failed to create run: %v", err)
+	}
+	workerID, token := registerWorkerWithToken(t, server, registry, "worker-1")
+
+	post := func(req TargetProfileRequest) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(req)
+		httpReq := httptest.NewRequest(http.MethodPost, "/workers/"+string(workerID)+"/target-profile", bytes.NewReader(body))
+		httpReq.Header.Set("X-Worker-Token", token)
+		w := httptest.NewRecorder()
+		server.routeWorkers(w, httpReq)
+		return w
+	}
+
+	profile := &types.TargetProfile{ProtocolVersion: "2025-06-18", Tools: []types.Tool{{Name: "echo"}}}
+	for i, want := range []bool{true, false} {
+		w := post(TargetProfileRequest{RunID: runID, Profile: profile})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var resp TargetProfileResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if resp.Recorded != want {
+			t.Errorf("post %d: expected recorded=%v, got %v", i, want, resp.Recorded)
+		}
+	}
+
+	if w := post(TargetProfileRequest{RunID: runID}); w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d without a profile, got %d", http.StatusBadRequest, w.Code)
+	}
+	if w := post(TargetProfileRequest{RunID: "run_0000000000000000", Profile: profile}); w.Code != http.StatusNotFound {
+		t.Errorf("expected status %d for an unknown run, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestConformanceReport_RecordsFirstReport(t *testing.T) {
+	server, registry := setupWorkerTestServer(t)
+	rm := newTestRunManager(t)
+	server.runManager = rm
+	runID, err := rm.CreateRun(loadValidConfig(t), "test")
+	if err != nil {
+		t.Fatalf("failed to create run: %v", err)
+	}
+	workerID, token := registerWorkerWithToken(t, server, registry, "worker-1")
+
+	post := func(req ConformanceReportRequest) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(req)
+		httpReq := httptest.NewRequest(http.MethodPost, "/workers/"+string(workerID)+"/conformance-report", bytes.NewReader(body))
+		httpReq.Header.Set("X-Worker-Token", token)
+		w := httptest.NewRecorder()
+		server.routeWorkers(w, httpReq)
+		return w
+	}
+
+	report := &analysis.ConformanceReport{Target: "http://127.0.0.1:3000/mcp", Passed: 1,
+		Checks: []analysis.ConformanceCheck{{ID: "ping.result", Status: analysis.ConformancePass}}}
+	for i, want := range []bool{true, false} {
+		w := post(ConformanceReportRequest{RunID: runID, Report: report})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var resp ConformanceReportResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if resp.Recorded != want {
+			t.Errorf("post %d: expected recorded=%v, got %v", i, want, resp.Recorded)
+		}
+	}
+
+	if w := post(ConformanceReportRequest{RunID: runID}); w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d without a report, got %d", http.StatusBadRequest, w.Code)
+	}
+	if w := post(ConformanceReportRequest{RunID: "run_0000000000000000", Report: report}); w.Code != http.StatusNotFound {
+		t.Errorf("expected status %d for an unknown run, got %d", http.StatusNotFound, w.Code)
+	}
+}
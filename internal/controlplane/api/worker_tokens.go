@@ -0,0 +1,159 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"time"
+)
+
+// workerTokenGrace is how long a worker's previous token is still accepted
+// after it was rotated, covering requests already in flight.
+const workerTokenGrace = time.Minute
+
+// workerCredential is the token a worker authenticates with. A rotated
+// token stays valid as previous until previousUntil.
+type workerCredential struct {
+	token     string
+	expiresAt time.Time // zero when tokens do not expire
+
+	previous      string
+	previousUntil time.Time
+}
+
+// SetWorkerTokenTTL makes worker tokens expire ttl after they are issued.
+// Workers are handed a new token in a heartbeat response once half of it
+// has passed, so only workers that stop heartbeating lose their token.
+// Zero, the default, keeps tokens valid for as long as the server runs.
+func (s *Server) SetWorkerTokenTTL(ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.workerTokenTTL = ttl
+}
+
+func newWorkerToken() (string, error) {
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(tokenBytes), nil
+}
+
+func (s *Server) issueWorkerToken(workerID string) (string, error) {
+	token, err := newWorkerToken()
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.workerTokens == nil {
+		s.workerTokens = make(map[string]*workerCredential)
+	}
+	cred := &workerCredential{token: token}
+	if s.workerTokenTTL > 0 {
+		cred.expiresAt = time.Now().Add(s.workerTokenTTL)
+	}
+	s.workerTokens[workerID] = cred
+	return token, nil
+}
+
+// workerTokenExpiresAtMs returns when the current token of workerID
+// expires, or 0 if it does not.
+func (s *Server) workerTokenExpiresAtMs(workerID string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cred := s.workerTokens[workerID]; cred != nil && !cred.expiresAt.IsZero() {
+		return cred.expiresAt.UnixMilli()
+	}
+	return 0
+}
+
+// rotateWorkerToken replaces the token of workerID once half its lifetime
+// has passed and returns the new one with its expiry. It returns an empty
+// token while the current one is still fresh or tokens do not expire.
+func (s *Server) rotateWorkerToken(workerID string) (string, int64, error) {
+	now := time.Now()
+	s.mu.Lock()
+	cred := s.workerTokens[workerID]
+	ttl := s.workerTokenTTL
+	s.mu.Unlock()
+	if cred == nil || ttl <= 0 || cred.expiresAt.IsZero() || cred.expiresAt.Sub(now) > ttl/2 {
+		return "", 0, nil
+	}
+
+	token, err := newWorkerToken()
+	if err != nil {
+		return "", 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.workerTokens[workerID] != cred {
+		return "", 0, nil
+	}
+	rotated := &workerCredential{
+		token:         token,
+		expiresAt:     now.Add(ttl),
+		previous:      cred.token,
+		previousUntil: now.Add(workerTokenGrace),
+	}
+	if cred.expiresAt.Before(rotated.previousUntil) {
+		rotated.previousUntil = cred.expiresAt
+	}
+	s.workerTokens[workerID] = rotated
+	return token, rotated.expiresAt.UnixMilli(), nil
+}
+
+func (s *Server) verifyWorkerToken(w http.ResponseWriter, r *http.Request, workerID string) bool {
+	if !s.isWorkerAuthEnabled() {
+		return true
+	}
+
+	// Security: worker endpoints carry assignment secrets, so require a worker token.
+	token := r.Header.Get("X-Worker-Token")
+	if token == "" {
+		s.writeError(w, http.StatusUnauthorized, &ErrorResponse{
+			ErrorType:    ErrorTypeUnauthorized,
+			ErrorCode:    "WORKER_AUTH_REQUIRED",
+			ErrorMessage: "Worker token required",
+			Retryable:    false,
+		})
+		return false
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	cred := s.workerTokens[workerID]
+	s.mu.Unlock()
+
+	switch {
+	case cred != nil && tokensEqual(token, cred.token):
+		if cred.expiresAt.IsZero() || now.Before(cred.expiresAt) {
+			return true
+		}
+		s.writeError(w, http.StatusUnauthorized, &ErrorResponse{
+			ErrorType:    ErrorTypeUnauthorized,
+			ErrorCode:    "WORKER_TOKEN_EXPIRED",
+			ErrorMessage: "Worker token expired; register the worker again",
+			Retryable:    false,
+		})
+		return false
+	case cred != nil && cred.previous != "" && tokensEqual(token, cred.previous) && now.Before(cred.previousUntil):
+		return true
+	}
+
+	// A rotated token past its grace period is refused like any other.
+	s.writeError(w, http.StatusUnauthorized, &ErrorResponse{
+		ErrorType:    ErrorTypeUnauthorized,
+		ErrorCode:    "INVALID_WORKER_TOKEN",
+		ErrorMessage: "Invalid worker token",
+		Retryable:    false,
+	})
+	return false
+}
+
+func tokensEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -0,0 +1,202 @@
+// Package audit records the control plane's mutating API calls: who made
+// them, with which credentials, from where, and how they ended. The audit
+// log is separate from the per-run event logs and is only appended to.
+package audit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/runstore"
+)
+
+// DefaultCapacity is how many entries a Log without a store keeps.
+const DefaultCapacity = 10000
+
+// Outcomes of an audited call.
+const (
+	OutcomeSuccess = "success"
+	OutcomeDenied  = "denied"
+	OutcomeFailed  = "failed"
+)
+
+// Entry is one audited call.
+type Entry struct {
+	ID          string `json:"id"`
+	TimestampMs int64  `json:"timestamp_ms"`
+	// Actor is the authenticated user: an API key hash prefix, a managed
+	// key id or a JWT subject. Empty when the caller did not authenticate.
+	Actor      string                 `json:"actor"`
+	AuthMethod string                 `json:"auth_method"`
+	Roles      []string               `json:"roles,omitempty"`
+	Scopes     []string               `json:"scopes,omitempty"`
+	Claims     map[string]interface{} `json:"claims,omitempty"`
+	Method     string                 `json:"method"`
+	Path       string                 `json:"path"`
+	RunID      string                 `json:"run_id,omitempty"`
+	Status     int                    `json:"status"`
+	Outcome    string                 `json:"outcome"`
+	SourceIP   string                 `json:"source_ip"`
+	DurationMs int64                  `json:"duration_ms"`
+}
+
+// OutcomeForStatus classifies an HTTP status: 401 and 403 are denied,
+// other errors failed.
+func OutcomeForStatus(status int) string {
+	switch {
+	case status == 401 || status == 403:
+		return OutcomeDenied
+	case status >= 400:
+		return OutcomeFailed
+	default:
+		return OutcomeSuccess
+	}
+}
+
+// Filter selects entries. Empty fields match every entry.
+type Filter = runstore.AuditFilter
+
+// Log is the audit log. Entries are kept in the run store when one is set,
+// and otherwise in memory, where the oldest are dropped past the capacity.
+type Log struct {
+	mu       sync.RWMutex
+	entries  []Entry
+	capacity int
+	store    runstore.Store
+	now      func() time.Time
+}
+
+// NewLog creates an in-memory log keeping up to capacity entries.
+func NewLog(capacity int) *Log {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	return &Log{capacity: capacity, now: time.Now}
+}
+
+// SetStore makes the log append to and query store.
+func (l *Log) SetStore(store runstore.Store) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.store = store
+}
+
+// Record appends entry, setting its id and timestamp if they are unset.
+// A failing store is logged and the entry kept in memory, so a database
+// outage does not fail the audited call.
+func (l *Log) Record(ctx context.Context, entry Entry) Entry {
+	if entry.ID == "" {
+		entry.ID = newEntryID()
+	}
+	if entry.TimestampMs == 0 {
+		entry.TimestampMs = l.now().UnixMilli()
+	}
+
+	l.mu.RLock()
+	store := l.store
+	l.mu.RUnlock()
+	if store != nil {
+		err := l.persist(ctx, store, entry)
+		if err == nil {
+			return entry
+		}
+		log.Printf("[Audit] Failed to store entry %s (%s %s by %q): %v", entry.ID, entry.Method, entry.Path, entry.Actor, err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.entries) >= l.capacity {
+		l.entries = append(l.entries[:0], l.entries[len(l.entries)-l.capacity+1:]...)
+	}
+	l.entries = append(l.entries, entry)
+	return entry
+}
+
+func (l *Log) persist(ctx context.Context, store runstore.Store, entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return store.AppendAuditEntry(ctx, &runstore.AuditEntry{
+		ID:          entry.ID,
+		TimestampMs: entry.TimestampMs,
+		Actor:       entry.Actor,
+		RunID:       entry.RunID,
+		Outcome:     entry.Outcome,
+		Method:      entry.Method,
+		Path:        entry.Path,
+		Data:        data,
+	})
+}
+
+// Query returns the entries matching filter.
+func (l *Log) Query(ctx context.Context, filter Filter) ([]Entry, error) {
+	l.mu.RLock()
+	store := l.store
+	l.mu.RUnlock()
+	if store == nil {
+		return l.queryMemory(filter), nil
+	}
+
+	stored, err := store.ListAuditEntries(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(stored))
+	for _, s := range stored {
+		var entry Entry
+		if err := json.Unmarshal(s.Data, &entry); err != nil {
+			return nil, fmt.Errorf("decode audit entry %s: %w", s.ID, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (l *Log) queryMemory(filter Filter) []Entry {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var entries []Entry
+	for i := range l.entries {
+		e := l.entries[i]
+		if filter.NewestFirst {
+			e = l.entries[len(l.entries)-1-i]
+		}
+		if !matches(e, filter) {
+			continue
+		}
+		entries = append(entries, e)
+		if filter.Limit > 0 && len(entries) == filter.Limit {
+			break
+		}
+	}
+	return entries
+}
+
+func matches(e Entry, f Filter) bool {
+	switch {
+	case f.Actor != "" && e.Actor != f.Actor,
+		f.RunID != "" && e.RunID != f.RunID,
+		f.Outcome != "" && e.Outcome != f.Outcome,
+		f.Method != "" && e.Method != f.Method,
+		f.PathPrefix != "" && !strings.HasPrefix(e.Path, f.PathPrefix),
+		f.SinceMs > 0 && e.TimestampMs < f.SinceMs,
+		f.UntilMs > 0 && e.TimestampMs >= f.UntilMs:
+		return false
+	}
+	return true
+}
+
+func newEntryID() string {
+	buf := make([]byte, 8)
+	_, _ = rand.Read(buf)
+	return "aud_" + hex.EncodeToString(buf)
+}
@@ -0,0 +1,77 @@
+package audit
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/runstore"
+)
+
+func TestLog_MemoryCapacityAndFilters(t *testing.T) {
+	ctx := context.Background()
+	l := NewLog(3)
+	for i, e := range []Entry{
+		{Actor: "alice", Method: "POST", Path: "/runs", Outcome: OutcomeSuccess, TimestampMs: 1000},
+		{Actor: "bob", Method: "POST", Path: "/runs/run_1/stop", RunID: "run_1", Outcome: OutcomeDenied, TimestampMs: 2000},
+		{Actor: "alice", Method: "DELETE", Path: "/scenarios/checkout", Outcome: OutcomeSuccess, TimestampMs: 3000},
+		{Actor: "alice", Method: "POST", Path: "/runs/run_1/start", RunID: "run_1", Outcome: OutcomeFailed, TimestampMs: 4000},
+	} {
+		if got := l.Record(ctx, e); got.ID == "" {
+			t.Fatalf("entry %d: expected an id", i)
+		}
+	}
+
+	all, _ := l.Query(ctx, Filter{})
+	if len(all) != 3 || all[0].TimestampMs != 2000 {
+		t.Fatalf("expected the oldest entry to be dropped, got %+v", all)
+	}
+	byRun, _ := l.Query(ctx, Filter{RunID: "run_1", NewestFirst: true})
+	if len(byRun) != 2 || byRun[0].Outcome != OutcomeFailed {
+		t.Errorf("unexpected run_1 entries %+v", byRun)
+	}
+	limited, _ := l.Query(ctx, Filter{Actor: "alice", PathPrefix: "/runs", Limit: 1})
+	if len(limited) != 1 || limited[0].TimestampMs != 4000 {
+		t.Errorf("unexpected filtered entries %+v", limited)
+	}
+}
+
+func TestLog_Store(t *testing.T) {
+	ctx := context.Background()
+	store, err := runstore.Open(ctx, "sqlite:"+filepath.Join(t.TempDir(), "runs.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer store.Close()
+
+	l := NewLog(1)
+	l.SetStore(store)
+	for _, e := range []Entry{
+		{Actor: "alice", Method: "POST", Path: "/runs", RunID: "run_1", Outcome: OutcomeSuccess, Roles: []string{"operator"}, TimestampMs: 1000},
+		{Actor: "bob", Method: "POST", Path: "/runs/run_1/stop", RunID: "run_1", Outcome: OutcomeDenied, TimestampMs: 2000},
+	} {
+		l.Record(ctx, e)
+	}
+
+	// A fresh log reads what the first one stored.
+	reopened := NewLog(1)
+	reopened.SetStore(store)
+	entries, err := reopened.Query(ctx, Filter{RunID: "run_1"})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected both entries from the store, got %+v", entries)
+	}
+	if entries[0].Actor != "alice" || len(entries[0].Roles) != 1 {
+		t.Errorf("unexpected entry %+v", entries[0])
+	}
+}
+
+func TestOutcomeForStatus(t *testing.T) {
+	for status, want := range map[int]string{200: OutcomeSuccess, 201: OutcomeSuccess, 401: OutcomeDenied, 403: OutcomeDenied, 409: OutcomeFailed, 500: OutcomeFailed} {
+		if got := OutcomeForStatus(status); got != want {
+			t.Errorf("OutcomeForStatus(%d) = %s, want %s", status, got, want)
+		}
+	}
+}
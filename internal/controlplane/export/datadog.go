@@ -0,0 +1,136 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	// datadogSite is the site metrics go to when the spec names none.
+	datadogSite = "datadoghq.com"
+	// datadogMaxSeries caps the series of one request, well under the
+	// intake's payload limit.
+	datadogMaxSeries = 1000
+
+	datadogTypeCount = 1
+	datadogTypeGauge = 3
+)
+
+// datadogSink submits samples to Datadog's v2 series API as mcpdrill.*
+// metrics: the counts of operations as counts, the rest as gauges.
+type datadogSink struct {
+	seriesURL string
+	apiKey    string
+	client    *http.Client
+}
+
+type datadogPayload struct {
+	Series []datadogSeries `json:"series"`
+}
+
+type datadogSeries struct {
+	Metric   string         `json:"metric"`
+	Type     int            `json:"type"`
+	Interval int64          `json:"interval"`
+	Points   []datadogPoint `json:"points"`
+	Tags     []string       `json:"tags"`
+}
+
+type datadogPoint struct {
+	Timestamp int64   `json:"timestamp"`
+	Value     float64 `json:"value"`
+}
+
+// newDatadogSink takes the site (datadoghq.eu, us5.datadoghq.com, ...) or
+// the full URL of the series endpoint, as for a proxy.
+func newDatadogSink(spec, apiKey string) (*datadogSink, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("datadog export needs an API key (--datadog-api-key)")
+	}
+	seriesURL := spec
+	switch {
+	case spec == "":
+		seriesURL = "https://api." + datadogSite + "/api/v2/series"
+	case !strings.HasPrefix(spec, "http://") && !strings.HasPrefix(spec, "https://"):
+		seriesURL = "https://api." + spec + "/api/v2/series"
+	}
+	return &datadogSink{
+		seriesURL: seriesURL,
+		apiKey:    apiKey,
+		client:    &http.Client{Timeout: writeTimeout},
+	}, nil
+}
+
+func (s *datadogSink) Name() string { return "datadog" }
+
+func (s *datadogSink) Write(ctx context.Context, samples []Sample) error {
+	series := datadogSeriesOf(samples)
+	for len(series) > 0 {
+		n := min(len(series), datadogMaxSeries)
+		if err := s.post(ctx, datadogPayload{Series: series[:n]}); err != nil {
+			return err
+		}
+		series = series[n:]
+	}
+	return nil
+}
+
+func (s *datadogSink) post(ctx context.Context, payload datadogPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.seriesURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("DD-API-KEY", s.apiKey)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("datadog series returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+func (s *datadogSink) Close() error { return nil }
+
+// datadogSeriesOf returns a one-point series per field of each sample.
+func datadogSeriesOf(samples []Sample) []datadogSeries {
+	series := make([]datadogSeries, 0, len(samples)*7)
+	for _, sample := range samples {
+		tags := datadogTags(sample.Tags())
+		for _, f := range sample.Fields() {
+			typ := datadogTypeGauge
+			if f.Count {
+				typ = datadogTypeCount
+			}
+			series = append(series, datadogSeries{
+				Metric:   "mcpdrill." + f.Name,
+				Type:     typ,
+				Interval: windowMs / 1000,
+				Points:   []datadogPoint{{Timestamp: sample.TimestampMs / 1000, Value: f.Value}},
+				Tags:     tags,
+			})
+		}
+	}
+	return series
+}
+
+func datadogTags(tags []Tag) []string {
+	out := make([]string, len(tags))
+	for i, t := range tags {
+		out[i] = t.Key + ":" + t.Value
+	}
+	return out
+}
@@ -0,0 +1,244 @@
+// Package export streams windowed run metrics from the control plane to
+// external time series stores, so results land next to the rest of an
+// organization's observability data.
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+)
+
+const (
+	// windowMs is the width of the windows exported, the telemetry store's.
+	windowMs = analysis.DefaultTimeSeriesWindowMs
+	// exportDelay is how long after a window ends it is exported, leaving
+	// time for the workers' telemetry batches to arrive. Operations that
+	// arrive later still than this are not exported.
+	exportDelay = 10 * time.Second
+	// writeTimeout bounds one write to a sink.
+	writeTimeout = 10 * time.Second
+)
+
+// Sample is one window of one series of a run: the run as a whole, one
+// operation or one tool.
+type Sample struct {
+	RunID     string
+	Operation string // empty unless the series is an operation's
+	Tool      string // empty unless the series is a tool's
+	analysis.WindowPoint
+}
+
+// Tag is a dimension samples are labelled with.
+type Tag struct {
+	Key, Value string
+}
+
+// Tags returns the sample's run_id, stage, operation and tool tags, leaving
+// out the empty ones.
+func (s Sample) Tags() []Tag {
+	tags := []Tag{{"run_id", s.RunID}}
+	for _, t := range []Tag{{"stage", s.Stage}, {"operation", s.Operation}, {"tool", s.Tool}} {
+		if t.Value != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// Field is one value of a sample.
+type Field struct {
+	Name  string
+	Value float64
+	// Count is set for counts of operations in the window; the other
+	// fields are gauges.
+	Count bool
+}
+
+// Fields returns the sample's values, in a fixed order.
+func (s Sample) Fields() []Field {
+	return []Field{
+		{"ops", float64(s.Ops), true},
+		{"failures", float64(s.Failures), true},
+		{"rps", s.RPS, false},
+		{"error_rate", s.ErrorRate, false},
+		{"latency_p50_ms", float64(s.LatencyP50), false},
+		{"latency_p95_ms", float64(s.LatencyP95), false},
+		{"latency_p99_ms", float64(s.LatencyP99), false},
+	}
+}
+
+// Sink writes samples to an external store.
+type Sink interface {
+	// Name identifies the sink in logs.
+	Name() string
+	Write(ctx context.Context, samples []Sample) error
+	Close() error
+}
+
+// Source provides the windows to export.
+type Source interface {
+	// TimeSeriesWindows returns, by run, the windows that start from fromMs
+	// up to toMs.
+	TimeSeriesWindows(fromMs, toMs int64) map[string]*analysis.TimeSeriesReport
+}
+
+// Exporter periodically writes the windows that ended to every sink. A sink
+// that fails loses the windows of that write; the others still get them.
+type Exporter struct {
+	source Source
+	sinks  []Sink
+	// nextMs is the start of the first window not exported yet.
+	nextMs int64
+}
+
+// New returns an exporter of source's windows to sinks. Windows that ended
+// before it starts are not exported.
+func New(source Source, sinks []Sink) *Exporter {
+	return &Exporter{source: source, sinks: sinks}
+}
+
+// Run exports every window until ctx is done, then exports the windows that
+// ended by then, waiting no longer for late telemetry, and closes the
+// sinks.
+func (e *Exporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(windowMs * time.Millisecond)
+	defer ticker.Stop()
+	e.nextMs = windowStart(time.Now().Add(-exportDelay).UnixMilli())
+	for {
+		select {
+		case <-ctx.Done():
+			e.flush(context.Background(), time.Now().UnixMilli())
+			for _, sink := range e.sinks {
+				if err := sink.Close(); err != nil {
+					slog.Warn("metrics_export_close_failed", "sink", sink.Name(), "error", err)
+				}
+			}
+			return
+		case now := <-ticker.C:
+			e.flush(ctx, now.Add(-exportDelay).UnixMilli())
+		}
+	}
+}
+
+// flush exports the windows that ended by untilMs and were not exported
+// yet.
+func (e *Exporter) flush(ctx context.Context, untilMs int64) {
+	toMs := windowStart(untilMs)
+	if toMs <= e.nextMs {
+		return
+	}
+	samples := samplesOf(e.source.TimeSeriesWindows(e.nextMs, toMs))
+	e.nextMs = toMs
+	if len(samples) == 0 {
+		return
+	}
+	for _, sink := range e.sinks {
+		writeCtx, cancel := context.WithTimeout(ctx, writeTimeout)
+		err := sink.Write(writeCtx, samples)
+		cancel()
+		if err != nil {
+			slog.Warn("metrics_export_failed", "sink", sink.Name(), "samples", len(samples), "error", err)
+		}
+	}
+}
+
+func windowStart(ms int64) int64 {
+	return ms - ms%windowMs
+}
+
+// samplesOf flattens reports into samples, ordered by run, series and time.
+func samplesOf(reports map[string]*analysis.TimeSeriesReport) []Sample {
+	runIDs := make([]string, 0, len(reports))
+	for runID := range reports {
+		runIDs = append(runIDs, runID)
+	}
+	sort.Strings(runIDs)
+
+	var samples []Sample
+	for _, runID := range runIDs {
+		report := reports[runID]
+		for _, p := range report.Total {
+			samples = append(samples, Sample{RunID: runID, WindowPoint: p})
+		}
+		for _, op := range sortedNames(report.ByOperation) {
+			for _, p := range report.ByOperation[op] {
+				samples = append(samples, Sample{RunID: runID, Operation: op, WindowPoint: p})
+			}
+		}
+		for _, tool := range sortedNames(report.ByTool) {
+			for _, p := range report.ByTool[tool] {
+				samples = append(samples, Sample{RunID: runID, Tool: tool, WindowPoint: p})
+			}
+		}
+	}
+	return samples
+}
+
+func sortedNames(series map[string][]analysis.WindowPoint) []string {
+	names := make([]string, 0, len(series))
+	for name := range series {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Credentials holds the secrets sinks authenticate with.
+type Credentials struct {
+	InfluxToken   string
+	DatadogAPIKey string
+}
+
+// Open returns the sink for spec:
+//
+//	influx:http://influx:8086?org=acme&bucket=drill  (InfluxDB 2 write API)
+//	influx:http://influx:8086?db=drill                (InfluxDB 1 /write)
+//	timescale:postgres://user:pass@db:5432/metrics    (TimescaleDB table)
+//	datadog  or  datadog:datadoghq.eu                 (Datadog series API, by site)
+//	statsd:localhost:8125                             (DogStatsD over UDP)
+func Open(ctx context.Context, spec string, creds Credentials) (Sink, error) {
+	kind, rest, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	switch kind {
+	case "influx", "influxdb":
+		return newInfluxSink(rest, creds.InfluxToken)
+	case "timescale", "timescaledb":
+		return openTimescaleSink(ctx, rest)
+	case "datadog":
+		return newDatadogSink(rest, creds.DatadogAPIKey)
+	case "statsd":
+		return newStatsDSink(rest)
+	default:
+		return nil, fmt.Errorf("unsupported export sink %q: expected influx, timescale, datadog or statsd", spec)
+	}
+}
+
+// OpenAll opens the sinks of a comma-separated list of specs, closing those
+// already open if one fails.
+func OpenAll(ctx context.Context, specs string, creds Credentials) ([]Sink, error) {
+	var sinks []Sink
+	for _, spec := range strings.Split(specs, ",") {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+		sink, err := Open(ctx, spec, creds)
+		if err != nil {
+			var errs []error
+			for _, s := range sinks {
+				errs = append(errs, s.Close())
+			}
+			return nil, errors.Join(append([]error{err}, errs...)...)
+		}
+		sinks = append(sinks, sink)
+	}
+	if len(sinks) == 0 {
+		return nil, errors.New("no export sinks given")
+	}
+	return sinks, nil
+}
@@ -0,0 +1,106 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+)
+
+type fakeSource struct {
+	calls [][2]int64
+}
+
+func (f *fakeSource) TimeSeriesWindows(fromMs, toMs int64) map[string]*analysis.TimeSeriesReport {
+	f.calls = append(f.calls, [2]int64{fromMs, toMs})
+	return map[string]*analysis.TimeSeriesReport{
+		"run_b": {Total: []analysis.WindowPoint{{TimestampMs: fromMs, Ops: 1}}},
+		"run_a": {
+			Total:  []analysis.WindowPoint{{TimestampMs: fromMs, Stage: "ramp", Ops: 3, Failures: 1}},
+			ByTool: map[string][]analysis.WindowPoint{"search": {{TimestampMs: fromMs, Stage: "ramp", Ops: 2}}},
+		},
+	}
+}
+
+type fakeSink struct {
+	name    string
+	err     error
+	written [][]Sample
+}
+
+func (f *fakeSink) Name() string { return f.name }
+
+func (f *fakeSink) Write(_ context.Context, samples []Sample) error {
+	f.written = append(f.written, samples)
+	return f.err
+}
+
+func (f *fakeSink) Close() error { return nil }
+
+func TestExporterFlush(t *testing.T) {
+	source := &fakeSource{}
+	failing := &fakeSink{name: "failing", err: errors.New("down")}
+	ok := &fakeSink{name: "ok"}
+	e := New(source, []Sink{failing, ok})
+	e.nextMs = 10_000
+
+	e.flush(context.Background(), 14_999)
+	if len(source.calls) != 0 {
+		t.Fatalf("expected no export before a window ends, got %v", source.calls)
+	}
+	e.flush(context.Background(), 21_000)
+	if len(source.calls) != 1 || source.calls[0] != [2]int64{10_000, 20_000} {
+		t.Fatalf("expected the windows from 10s to 20s, got %v", source.calls)
+	}
+	e.flush(context.Background(), 22_000)
+	if len(source.calls) != 1 {
+		t.Errorf("expected exported windows not to be exported again, got %v", source.calls)
+	}
+
+	if len(failing.written) != 1 || len(ok.written) != 1 {
+		t.Fatalf("expected every sink to get the windows despite one failing, got %d and %d writes", len(failing.written), len(ok.written))
+	}
+	samples := ok.written[0]
+	if len(samples) != 3 || samples[0].RunID != "run_a" || samples[1].Tool != "search" || samples[2].RunID != "run_b" {
+		t.Fatalf("unexpected samples %+v", samples)
+	}
+	tags := samples[1].Tags()
+	if len(tags) != 3 || tags[1] != (Tag{"stage", "ramp"}) || tags[2] != (Tag{"tool", "search"}) {
+		t.Errorf("unexpected tags %+v", tags)
+	}
+}
+
+func TestOpenAll(t *testing.T) {
+	if _, err := OpenAll(context.Background(), " , ", Credentials{}); err == nil {
+		t.Error("expected an error without sinks")
+	}
+	if _, err := OpenAll(context.Background(), "graphite:localhost:2003", Credentials{}); err == nil || !strings.Contains(err.Error(), "unsupported export sink") {
+		t.Errorf("expected an unsupported sink error, got %v", err)
+	}
+	if _, err := OpenAll(context.Background(), "statsd:127.0.0.1:8125,datadog", Credentials{}); err == nil || !strings.Contains(err.Error(), "API key") {
+		t.Errorf("expected datadog to need an API key, got %v", err)
+	}
+
+	sinks, err := OpenAll(context.Background(), "statsd:127.0.0.1:8125, influx:http://localhost:8086?db=drill", Credentials{})
+	if err != nil {
+		t.Fatalf("OpenAll: %v", err)
+	}
+	if len(sinks) != 2 || sinks[0].Name() != "statsd" || sinks[1].Name() != "influx" {
+		t.Errorf("unexpected sinks %v", sinks)
+	}
+	for _, s := range sinks {
+		s.Close()
+	}
+}
+
+func TestTimescaleRow(t *testing.T) {
+	row := timescaleRow(Sample{RunID: "run_a", Tool: "search", WindowPoint: analysis.WindowPoint{TimestampMs: 5_000, Ops: 2, LatencyP99: 40}})
+	if len(row) != strings.Count(timescaleInsert, "$") {
+		t.Fatalf("expected a value per placeholder, got %d", len(row))
+	}
+	if row[1] != "run_a" || row[3] != "" || row[4] != "search" || row[11] != 40 {
+		t.Errorf("unexpected row %v", row)
+	}
+}
@@ -0,0 +1,118 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// influxMeasurement is the measurement samples are written to.
+const influxMeasurement = "mcpdrill_window"
+
+// influxSink writes samples in line protocol to InfluxDB's write API: the
+// v2 /api/v2/write of an org and bucket, or the v1 /write of a database.
+type influxSink struct {
+	writeURL string
+	token    string
+	client   *http.Client
+}
+
+func newInfluxSink(spec, token string) (*influxSink, error) {
+	u, err := url.Parse(spec)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("influx export needs an http(s) URL, got %q", spec)
+	}
+	q := u.Query()
+	params := url.Values{"precision": {"ms"}}
+	switch {
+	case q.Get("bucket") != "":
+		params.Set("bucket", q.Get("bucket"))
+		params.Set("org", q.Get("org"))
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/api/v2/write"
+	case q.Get("db") != "":
+		params.Set("db", q.Get("db"))
+		if rp := q.Get("rp"); rp != "" {
+			params.Set("rp", rp)
+		}
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/write"
+	default:
+		return nil, fmt.Errorf("influx export URL %q needs a bucket (and org) or a db parameter", spec)
+	}
+	u.RawQuery = params.Encode()
+	return &influxSink{
+		writeURL: u.String(),
+		token:    token,
+		client:   &http.Client{Timeout: writeTimeout},
+	}, nil
+}
+
+func (s *influxSink) Name() string { return "influx" }
+
+func (s *influxSink) Write(ctx context.Context, samples []Sample) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.writeURL, bytes.NewReader(influxLines(samples)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Token "+s.token)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("influx write returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+func (s *influxSink) Close() error { return nil }
+
+// influxLines encodes samples in line protocol, one line per sample with
+// millisecond timestamps.
+func influxLines(samples []Sample) []byte {
+	var b bytes.Buffer
+	for _, sample := range samples {
+		b.WriteString(influxMeasurement)
+		for _, t := range sample.Tags() {
+			b.WriteByte(',')
+			b.WriteString(influxEscape(t.Key))
+			b.WriteByte('=')
+			b.WriteString(influxEscape(t.Value))
+		}
+		for i, f := range sample.Fields() {
+			if i == 0 {
+				b.WriteByte(' ')
+			} else {
+				b.WriteByte(',')
+			}
+			b.WriteString(f.Name)
+			b.WriteByte('=')
+			if f.Count {
+				b.WriteString(strconv.FormatInt(int64(f.Value), 10))
+				b.WriteByte('i')
+			} else {
+				b.WriteString(strconv.FormatFloat(f.Value, 'f', -1, 64))
+			}
+		}
+		b.WriteByte(' ')
+		b.WriteString(strconv.FormatInt(sample.TimestampMs, 10))
+		b.WriteByte('\n')
+	}
+	return b.Bytes()
+}
+
+var influxEscaper = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `, "\n", `\n`)
+
+// influxEscape escapes a tag key or value.
+func influxEscape(s string) string {
+	return influxEscaper.Replace(s)
+}
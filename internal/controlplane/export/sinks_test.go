@@ -0,0 +1,162 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+)
+
+var testSamples = []Sample{
+	{RunID: "run_a", Tool: "web search", WindowPoint: analysis.WindowPoint{
+		TimestampMs: 1_700_000_005_000, Stage: "ramp", Ops: 10, Failures: 1, RPS: 2, ErrorRate: 0.1, LatencyP50: 20, LatencyP95: 40, LatencyP99: 80,
+	}},
+}
+
+func TestInfluxSink(t *testing.T) {
+	var gotPath, gotQuery, gotAuth, gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotPath, gotQuery, gotAuth, gotBody = r.URL.Path, r.URL.RawQuery, r.Header.Get("Authorization"), string(body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	sink, err := newInfluxSink(srv.URL+"?org=acme&bucket=drill", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Write(context.Background(), testSamples); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if gotPath != "/api/v2/write" || gotQuery != "bucket=drill&org=acme&precision=ms" || gotAuth != "Token secret" {
+		t.Errorf("unexpected request %s?%s (auth %q)", gotPath, gotQuery, gotAuth)
+	}
+	want := `mcpdrill_window,run_id=run_a,stage=ramp,tool=web\ search ops=10i,failures=1i,rps=2,error_rate=0.1,latency_p50_ms=20,latency_p95_ms=40,latency_p99_ms=80 1700000005000` + "\n"
+	if gotBody != want {
+		t.Errorf("unexpected line protocol\n got %q\nwant %q", gotBody, want)
+	}
+
+	v1, err := newInfluxSink(srv.URL+"/base?db=drill", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v1.Write(context.Background(), testSamples); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if gotPath != "/base/write" || gotQuery != "db=drill&precision=ms" || gotAuth != "" {
+		t.Errorf("unexpected v1 request %s?%s (auth %q)", gotPath, gotQuery, gotAuth)
+	}
+
+	if _, err := newInfluxSink(srv.URL, ""); err == nil {
+		t.Error("expected an error without a bucket or db")
+	}
+}
+
+func TestInfluxSink_ErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bucket not found", http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	sink, err := newInfluxSink(srv.URL+"?db=drill", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Write(context.Background(), testSamples); err == nil || !strings.Contains(err.Error(), "bucket not found") {
+		t.Errorf("expected the server's error, got %v", err)
+	}
+}
+
+func TestDatadogSink(t *testing.T) {
+	var gotKey string
+	var payload datadogPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey = r.Header.Get("DD-API-KEY")
+		json.NewDecoder(r.Body).Decode(&payload)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	sink, err := newDatadogSink(srv.URL+"/api/v2/series", "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Write(context.Background(), testSamples); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if gotKey != "key" || len(payload.Series) != 7 {
+		t.Fatalf("expected 7 series with the API key, got %q and %+v", gotKey, payload)
+	}
+	ops, p99 := payload.Series[0], payload.Series[6]
+	if ops.Metric != "mcpdrill.ops" || ops.Type != datadogTypeCount || ops.Interval != 5 || ops.Points[0].Timestamp != 1_700_000_005 {
+		t.Errorf("unexpected ops series %+v", ops)
+	}
+	if p99.Metric != "mcpdrill.latency_p99_ms" || p99.Type != datadogTypeGauge || p99.Points[0].Value != 80 {
+		t.Errorf("unexpected p99 series %+v", p99)
+	}
+	if strings.Join(ops.Tags, ",") != "run_id:run_a,stage:ramp,tool:web search" {
+		t.Errorf("unexpected tags %v", ops.Tags)
+	}
+
+	eu, err := newDatadogSink("datadoghq.eu", "key")
+	if err != nil || eu.seriesURL != "https://api.datadoghq.eu/api/v2/series" {
+		t.Errorf("expected the EU site's series URL, got %v, %v", eu, err)
+	}
+}
+
+func TestStatsDSink(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	sink, err := newStatsDSink(conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	if err := sink.Write(context.Background(), testSamples); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	buf := make([]byte, statsdMaxPacket)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("ReadFrom: %v", err)
+	}
+	lines := strings.Split(string(buf[:n]), "\n")
+	if len(lines) != 7 || lines[0] != "mcpdrill.ops:10|g|#run_id:run_a,stage:ramp,tool:web search" {
+		t.Errorf("unexpected packet %q", buf[:n])
+	}
+}
+
+func TestStatsDPackets_Split(t *testing.T) {
+	samples := make([]Sample, 50)
+	for i := range samples {
+		samples[i] = testSamples[0]
+	}
+	packets := statsdPackets(samples)
+	if len(packets) < 2 {
+		t.Fatalf("expected several packets, got %d", len(packets))
+	}
+	lines := 0
+	for _, p := range packets {
+		if len(p) > statsdMaxPacket {
+			t.Errorf("packet of %d bytes over the limit", len(p))
+		}
+		lines += strings.Count(string(p), "\n") + 1
+	}
+	if lines != 50*7 {
+		t.Errorf("expected %d lines, got %d", 50*7, lines)
+	}
+}
@@ -0,0 +1,83 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// statsdMaxPacket keeps packets within a 1500 byte MTU.
+const statsdMaxPacket = 1432
+
+// statsdSink sends samples to a StatsD agent over UDP as mcpdrill.* gauges,
+// with tags in the DogStatsD format the Datadog agent and Telegraf read.
+// StatsD has no timestamps, so points land at the time they are sent, a few
+// seconds after their window.
+type statsdSink struct {
+	conn net.Conn
+}
+
+func newStatsDSink(addr string) (*statsdSink, error) {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		return nil, fmt.Errorf("statsd export needs host:port, got %q", addr)
+	}
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("open statsd export: %w", err)
+	}
+	return &statsdSink{conn: conn}, nil
+}
+
+func (s *statsdSink) Name() string { return "statsd" }
+
+func (s *statsdSink) Write(ctx context.Context, samples []Sample) error {
+	for _, packet := range statsdPackets(samples) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, err := s.conn.Write(packet); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *statsdSink) Close() error { return s.conn.Close() }
+
+// statsdPackets encodes samples as newline-separated gauge lines, packed
+// into packets of at most statsdMaxPacket bytes.
+func statsdPackets(samples []Sample) [][]byte {
+	var packets [][]byte
+	var packet []byte
+	for _, sample := range samples {
+		tags := make([]string, 0, 4)
+		for _, t := range sample.Tags() {
+			tags = append(tags, statsdEscape(t.Key)+":"+statsdEscape(t.Value))
+		}
+		suffix := "|g|#" + strings.Join(tags, ",")
+		for _, f := range sample.Fields() {
+			line := "mcpdrill." + f.Name + ":" + strconv.FormatFloat(f.Value, 'f', -1, 64) + suffix
+			if len(packet) > 0 && len(packet)+1+len(line) > statsdMaxPacket {
+				packets = append(packets, packet)
+				packet = nil
+			}
+			if len(packet) > 0 {
+				packet = append(packet, '\n')
+			}
+			packet = append(packet, line...)
+		}
+	}
+	if len(packet) > 0 {
+		packets = append(packets, packet)
+	}
+	return packets
+}
+
+var statsdEscaper = strings.NewReplacer(",", "_", "|", "_", "#", "_", "\n", "_")
+
+// statsdEscape replaces the characters that delimit a DogStatsD line.
+func statsdEscape(s string) string {
+	return statsdEscaper.Replace(s)
+}
@@ -0,0 +1,96 @@
+package export
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+// timescaleSchema creates the table samples are written to and makes it a
+// hypertable partitioned on time. Empty tags are stored as ”.
+var timescaleSchema = []string{
+	`CREATE TABLE IF NOT EXISTS mcpdrill_windows (
+		time TIMESTAMPTZ NOT NULL,
+		run_id TEXT NOT NULL,
+		stage TEXT NOT NULL DEFAULT '',
+		operation TEXT NOT NULL DEFAULT '',
+		tool TEXT NOT NULL DEFAULT '',
+		ops BIGINT NOT NULL,
+		failures BIGINT NOT NULL,
+		rps DOUBLE PRECISION NOT NULL,
+		error_rate DOUBLE PRECISION NOT NULL,
+		latency_p50_ms INTEGER NOT NULL,
+		latency_p95_ms INTEGER NOT NULL,
+		latency_p99_ms INTEGER NOT NULL
+	)`,
+	`SELECT create_hypertable('mcpdrill_windows', 'time', if_not_exists => TRUE)`,
+	`CREATE INDEX IF NOT EXISTS mcpdrill_windows_run_id_time ON mcpdrill_windows (run_id, time DESC)`,
+}
+
+const timescaleInsert = `INSERT INTO mcpdrill_windows
+	(time, run_id, stage, operation, tool, ops, failures, rps, error_rate, latency_p50_ms, latency_p95_ms, latency_p99_ms)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+
+// timescaleSink inserts samples into the mcpdrill_windows hypertable of a
+// TimescaleDB database, one row per sample.
+type timescaleSink struct {
+	db *sql.DB
+}
+
+func openTimescaleSink(ctx context.Context, dsn string) (*timescaleSink, error) {
+	if dsn == "" {
+		return nil, fmt.Errorf("timescale export needs a postgres DSN")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open timescale export: %w", err)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("connect to timescale export: %w", err)
+	}
+	for _, stmt := range timescaleSchema {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("create timescale export table (is the timescaledb extension installed?): %w", err)
+		}
+	}
+	return &timescaleSink{db: db}, nil
+}
+
+func (s *timescaleSink) Name() string { return "timescale" }
+
+func (s *timescaleSink) Write(ctx context.Context, samples []Sample) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, timescaleInsert)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, sample := range samples {
+		if _, err := stmt.ExecContext(ctx, timescaleRow(sample)...); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *timescaleSink) Close() error { return s.db.Close() }
+
+// timescaleRow returns the values of the insert for sample.
+func timescaleRow(s Sample) []interface{} {
+	return []interface{}{
+		time.UnixMilli(s.TimestampMs).UTC(),
+		s.RunID, s.Stage, s.Operation, s.Tool,
+		s.Ops, s.Failures, s.RPS, s.ErrorRate,
+		s.LatencyP50, s.LatencyP95, s.LatencyP99,
+	}
+}
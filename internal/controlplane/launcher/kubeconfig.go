@@ -0,0 +1,217 @@
+package launcher
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// In-cluster service account files, mounted into every pod.
+const (
+	serviceAccountTokenFile     = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	serviceAccountCAFile        = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+	serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
+
+// kubeconfigFile is the subset of a kubeconfig file the launcher supports:
+// bearer tokens and client certificates. Exec and auth-provider plugins are
+// not supported.
+type kubeconfigFile struct {
+	CurrentContext string `yaml:"current-context"`
+	Clusters       []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server                   string `yaml:"server"`
+			CertificateAuthority     string `yaml:"certificate-authority"`
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+			InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Users []struct {
+		Name string `yaml:"name"`
+		User struct {
+			Token                 string    `yaml:"token"`
+			TokenFile             string    `yaml:"tokenFile"`
+			ClientCertificate     string    `yaml:"client-certificate"`
+			ClientCertificateData string    `yaml:"client-certificate-data"`
+			ClientKey             string    `yaml:"client-key"`
+			ClientKeyData         string    `yaml:"client-key-data"`
+			Exec                  yaml.Node `yaml:"exec"`
+			AuthProvider          yaml.Node `yaml:"auth-provider"`
+		} `yaml:"user"`
+	} `yaml:"users"`
+	Contexts []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster   string `yaml:"cluster"`
+			User      string `yaml:"user"`
+			Namespace string `yaml:"namespace"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+}
+
+// kubeClient is a minimal client for the Kubernetes API server.
+type kubeClient struct {
+	server    string
+	token     string
+	tokenFile string // re-read on every request, as projected tokens rotate
+	namespace string
+	http      *http.Client
+}
+
+// loadKubeClient builds a client from the kubeconfig at path, or from the
+// pod's service account when path is empty.
+func loadKubeClient(path string) (*kubeClient, error) {
+	if path == "" {
+		return inClusterKubeClient()
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kubeconfig: %w", err)
+	}
+	var cfg kubeconfigFile
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+	dir := filepath.Dir(path)
+	resolve := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(dir, p)
+	}
+
+	ctxIndex := -1
+	for i := range cfg.Contexts {
+		if cfg.Contexts[i].Name == cfg.CurrentContext {
+			ctxIndex = i
+		}
+	}
+	if ctxIndex < 0 {
+		return nil, fmt.Errorf("kubeconfig current context %q not found", cfg.CurrentContext)
+	}
+	kctx := cfg.Contexts[ctxIndex].Context
+
+	client := &kubeClient{namespace: kctx.Namespace}
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	found := false
+	for _, c := range cfg.Clusters {
+		if c.Name != kctx.Cluster {
+			continue
+		}
+		found = true
+		client.server = strings.TrimSuffix(c.Cluster.Server, "/")
+		tlsConfig.InsecureSkipVerify = c.Cluster.InsecureSkipTLSVerify
+		ca, err := pemData(c.Cluster.CertificateAuthorityData, resolve(c.Cluster.CertificateAuthority))
+		if err != nil {
+			return nil, fmt.Errorf("cluster %s certificate authority: %w", c.Name, err)
+		}
+		if ca != nil {
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(ca) {
+				return nil, fmt.Errorf("cluster %s certificate authority contains no certificates", c.Name)
+			}
+			tlsConfig.RootCAs = pool
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("kubeconfig cluster %q not found", kctx.Cluster)
+	}
+	if client.server == "" {
+		return nil, fmt.Errorf("kubeconfig cluster %q has no server", kctx.Cluster)
+	}
+
+	for _, u := range cfg.Users {
+		if u.Name != kctx.User {
+			continue
+		}
+		if !u.User.Exec.IsZero() || !u.User.AuthProvider.IsZero() {
+			return nil, fmt.Errorf("kubeconfig user %s uses an auth plugin, which is not supported; use a token or client certificate", u.Name)
+		}
+		client.token = u.User.Token
+		client.tokenFile = resolve(u.User.TokenFile)
+		cert, err := pemData(u.User.ClientCertificateData, resolve(u.User.ClientCertificate))
+		if err != nil {
+			return nil, fmt.Errorf("user %s client certificate: %w", u.Name, err)
+		}
+		key, err := pemData(u.User.ClientKeyData, resolve(u.User.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("user %s client key: %w", u.Name, err)
+		}
+		if cert != nil || key != nil {
+			pair, err := tls.X509KeyPair(cert, key)
+			if err != nil {
+				return nil, fmt.Errorf("user %s client certificate: %w", u.Name, err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{pair}
+		}
+	}
+
+	client.http = newKubeHTTPClient(tlsConfig)
+	return client, nil
+}
+
+func inClusterKubeClient() (*kubeClient, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, errors.New("no kubeconfig given and not running in a Kubernetes cluster")
+	}
+	ca, err := os.ReadFile(serviceAccountCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service account CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("service account CA contains no certificates")
+	}
+	namespace, _ := os.ReadFile(serviceAccountNamespaceFile)
+
+	return &kubeClient{
+		server:    "https://" + net.JoinHostPort(host, port),
+		tokenFile: serviceAccountTokenFile,
+		namespace: strings.TrimSpace(string(namespace)),
+		http:      newKubeHTTPClient(&tls.Config{MinVersion: tls.VersionTLS12, RootCAs: pool}),
+	}, nil
+}
+
+func newKubeHTTPClient(tlsConfig *tls.Config) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Transport: transport, Timeout: 30 * time.Second}
+}
+
+// pemData returns inline base64 data if set, else the contents of file, else
+// nil.
+func pemData(inline, file string) ([]byte, error) {
+	if inline != "" {
+		return base64.StdEncoding.DecodeString(inline)
+	}
+	if file != "" {
+		return os.ReadFile(file)
+	}
+	return nil, nil
+}
+
+// bearerToken returns the token to authenticate requests with, if any.
+func (c *kubeClient) bearerToken() (string, error) {
+	if c.tokenFile != "" {
+		data, err := os.ReadFile(c.tokenFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read token file: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	return c.token, nil
+}
@@ -0,0 +1,287 @@
+// Package launcher starts workers on demand for runs and removes them when
+// the runs end.
+package launcher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultVUsPerWorker matches the worker's default --max-vus.
+	DefaultVUsPerWorker = 100
+	// DefaultMaxWorkers bounds the pods launched for a single run.
+	DefaultMaxWorkers = 50
+
+	// LabelRunID labels every pod with the run it was launched for.
+	LabelRunID = "mcpdrill.io/run-id"
+	// LabelManagedBy marks pods launched by the control plane.
+	LabelManagedBy = "app.kubernetes.io/managed-by"
+)
+
+// KubernetesConfig configures a Kubernetes worker launcher.
+type KubernetesConfig struct {
+	// Kubeconfig is the kubeconfig file to use. When empty, the control
+	// plane must run in the cluster and uses its service account.
+	Kubeconfig string
+	// Namespace to launch pods in. Defaults to the kubeconfig context's
+	// namespace, then "default".
+	Namespace string
+	// PodTemplate is a YAML or JSON file holding the worker pod: a Pod
+	// manifest or a pod template with metadata and spec. Its worker
+	// container must point the worker at the control plane.
+	PodTemplate string
+	// VUsPerWorker is the --max-vus given to each launched worker.
+	VUsPerWorker int
+	// MaxWorkers is the most pods launched for a single run.
+	MaxWorkers int
+}
+
+// Kubernetes launches worker pods for a run from a pod template and deletes
+// them once the run has ended. It talks to the API server directly and
+// needs permission to create, list and delete pods in its namespace.
+type Kubernetes struct {
+	client       *kubeClient
+	namespace    string
+	template     map[string]interface{}
+	vusPerWorker int
+	maxWorkers   int
+}
+
+// NewKubernetes returns a launcher for cfg.
+func NewKubernetes(cfg KubernetesConfig) (*Kubernetes, error) {
+	if cfg.PodTemplate == "" {
+		return nil, errors.New("pod template is required")
+	}
+	if cfg.VUsPerWorker < 0 || cfg.MaxWorkers < 0 {
+		return nil, errors.New("VUs per worker and max workers cannot be negative")
+	}
+
+	template, err := loadPodTemplate(cfg.PodTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := loadKubeClient(cfg.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	k := &Kubernetes{
+		client:       client,
+		namespace:    cfg.Namespace,
+		template:     template,
+		vusPerWorker: cfg.VUsPerWorker,
+		maxWorkers:   cfg.MaxWorkers,
+	}
+	if k.namespace == "" {
+		k.namespace = client.namespace
+	}
+	if k.namespace == "" {
+		k.namespace = "default"
+	}
+	if k.vusPerWorker == 0 {
+		k.vusPerWorker = DefaultVUsPerWorker
+	}
+	if k.maxWorkers == 0 {
+		k.maxWorkers = DefaultMaxWorkers
+	}
+	return k, nil
+}
+
+// loadPodTemplate reads the pod template file and returns its pod template:
+// the object itself, or its template field if it has one.
+func loadPodTemplate(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pod template: %w", err)
+	}
+	var template map[string]interface{}
+	if err := yaml.Unmarshal(data, &template); err != nil {
+		return nil, fmt.Errorf("failed to parse pod template: %w", err)
+	}
+	if inner, ok := template["template"].(map[string]interface{}); ok {
+		template = inner
+	}
+	spec, _ := template["spec"].(map[string]interface{})
+	if containers, _ := spec["containers"].([]interface{}); len(containers) == 0 {
+		return nil, errors.New("pod template has no spec.containers")
+	}
+	return template, nil
+}
+
+// LaunchWorkers creates enough worker pods for vus VUs, up to the launcher's
+// maximum, labelled with runID. It returns the names of the pods created,
+// which on error may be only some of them.
+func (k *Kubernetes) LaunchWorkers(ctx context.Context, runID string, vus int) ([]string, error) {
+	count := (vus + k.vusPerWorker - 1) / k.vusPerWorker
+	if count > k.maxWorkers {
+		log.Printf("[Launcher] Run %s needs %d workers, launching the maximum of %d", runID, count, k.maxWorkers)
+		count = k.maxWorkers
+	}
+
+	path := "/api/v1/namespaces/" + url.PathEscape(k.namespace) + "/pods"
+	names := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		pod, err := k.workerPod(runID)
+		if err != nil {
+			return names, err
+		}
+		var created struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+		}
+		if err := k.client.do(ctx, http.MethodPost, path, pod, &created); err != nil {
+			return names, fmt.Errorf("failed to create worker pod: %w", err)
+		}
+		names = append(names, created.Metadata.Name)
+	}
+	return names, nil
+}
+
+// ReleaseWorkers deletes the pods launched for runID and returns their names.
+func (k *Kubernetes) ReleaseWorkers(ctx context.Context, runID string) ([]string, error) {
+	query := url.Values{"labelSelector": {LabelRunID + "=" + runID}}
+	path := "/api/v1/namespaces/" + url.PathEscape(k.namespace) + "/pods?" + query.Encode()
+
+	var deleted struct {
+		Items []struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+		} `json:"items"`
+	}
+	if err := k.client.do(ctx, http.MethodDelete, path, nil, &deleted); err != nil {
+		return nil, fmt.Errorf("failed to delete worker pods: %w", err)
+	}
+	names := make([]string, 0, len(deleted.Items))
+	for _, item := range deleted.Items {
+		names = append(names, item.Metadata.Name)
+	}
+	return names, nil
+}
+
+// workerPod builds a worker pod for runID from the template. The worker
+// container, the one named "worker" or else the first, is given the
+// launcher's --max-vus unless the template sets it.
+func (k *Kubernetes) workerPod(runID string) (map[string]interface{}, error) {
+	// A JSON round trip deep-copies the template.
+	data, err := json.Marshal(k.template)
+	if err != nil {
+		return nil, err
+	}
+	var pod map[string]interface{}
+	if err := json.Unmarshal(data, &pod); err != nil {
+		return nil, err
+	}
+
+	pod["apiVersion"] = "v1"
+	pod["kind"] = "Pod"
+
+	metadata, _ := pod["metadata"].(map[string]interface{})
+	if metadata == nil {
+		metadata = make(map[string]interface{})
+		pod["metadata"] = metadata
+	}
+	delete(metadata, "name")
+	metadata["namespace"] = k.namespace
+	metadata["generateName"] = "mcpdrill-worker-" + strings.ReplaceAll(strings.ToLower(runID), "_", "-") + "-"
+	labels, _ := metadata["labels"].(map[string]interface{})
+	if labels == nil {
+		labels = make(map[string]interface{})
+		metadata["labels"] = labels
+	}
+	labels[LabelRunID] = runID
+	labels[LabelManagedBy] = "mcpdrill"
+
+	spec := pod["spec"].(map[string]interface{})
+	containers := spec["containers"].([]interface{})
+	container, _ := containers[0].(map[string]interface{})
+	for _, c := range containers {
+		if c, ok := c.(map[string]interface{}); ok && c["name"] == "worker" {
+			container = c
+		}
+	}
+	if container == nil {
+		return nil, errors.New("pod template worker container is not an object")
+	}
+
+	args, _ := container["args"].([]interface{})
+	hasMaxVUs := false
+	for _, arg := range args {
+		if s, ok := arg.(string); ok && (s == "--max-vus" || strings.HasPrefix(s, "--max-vus=")) {
+			hasMaxVUs = true
+		}
+	}
+	if !hasMaxVUs {
+		container["args"] = append(args, "--max-vus="+strconv.Itoa(k.vusPerWorker))
+	}
+
+	env, _ := container["env"].([]interface{})
+	container["env"] = append(env, map[string]interface{}{"name": "MCPDRILL_RUN_ID", "value": runID})
+
+	return pod, nil
+}
+
+// do sends a request to the API server and decodes the JSON response into
+// out.
+func (c *kubeClient) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.server+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	token, err := c.bearerToken()
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var status struct {
+			Message string `json:"message"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		if json.Unmarshal(data, &status) == nil && status.Message != "" {
+			return fmt.Errorf("%s: %s", resp.Status, status.Message)
+		}
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
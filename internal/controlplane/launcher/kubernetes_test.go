@@ -0,0 +1,208 @@
+package launcher
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const testPodTemplate = `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: ignored
+  labels:
+    app: mcpdrill-worker
+spec:
+  containers:
+  - name: sidecar
+    image: busybox
+  - name: worker
+    image: mcpdrill/worker:latest
+    args:
+    - --control-plane=http://control-plane:8080
+`
+
+type fakeAPIServer struct {
+	mu      sync.Mutex
+	created []map[string]interface{}
+	deletes []string
+}
+
+func (f *fakeAPIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer test-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"kind":"Status","message":"Unauthorized"}`))
+		return
+	}
+	if r.URL.Path != "/api/v1/namespaces/load/pods" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		var pod map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&pod)
+		f.created = append(f.created, pod)
+		name := pod["metadata"].(map[string]interface{})["generateName"].(string) + string(rune('a'+len(f.created)-1))
+		json.NewEncoder(w).Encode(map[string]interface{}{"metadata": map[string]interface{}{"name": name}})
+	case http.MethodDelete:
+		f.deletes = append(f.deletes, r.URL.Query().Get("labelSelector"))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"items": []interface{}{
+				map[string]interface{}{"metadata": map[string]interface{}{"name": "pod-a"}},
+				map[string]interface{}{"metadata": map[string]interface{}{"name": "pod-b"}},
+			},
+		})
+	}
+}
+
+func writeTestFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func writeTestKubeconfig(t *testing.T, dir, server, user string) string {
+	t.Helper()
+	return writeTestFile(t, dir, "kubeconfig", `
+apiVersion: v1
+kind: Config
+current-context: test
+clusters:
+- name: test-cluster
+  cluster:
+    server: `+server+`
+contexts:
+- name: test
+  context:
+    cluster: test-cluster
+    user: test-user
+    namespace: load
+users:
+- name: test-user
+  user:
+`+user)
+}
+
+func TestKubernetes_LaunchAndReleaseWorkers(t *testing.T) {
+	api := &fakeAPIServer{}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	dir := t.TempDir()
+	k, err := NewKubernetes(KubernetesConfig{
+		Kubeconfig:   writeTestKubeconfig(t, dir, server.URL, "    token: test-token\n"),
+		PodTemplate:  writeTestFile(t, dir, "pod.yaml", testPodTemplate),
+		VUsPerWorker: 40,
+	})
+	if err != nil {
+		t.Fatalf("NewKubernetes failed: %v", err)
+	}
+
+	names, err := k.LaunchWorkers(context.Background(), "run_00000000000000ab", 100)
+	if err != nil {
+		t.Fatalf("LaunchWorkers failed: %v", err)
+	}
+	if len(names) != 3 || len(api.created) != 3 {
+		t.Fatalf("expected 3 pods for 100 VUs at 40 per worker, got %v", names)
+	}
+	if !strings.HasPrefix(names[0], "mcpdrill-worker-run-00000000000000ab-") {
+		t.Errorf("unexpected pod name %q", names[0])
+	}
+
+	pod := api.created[0]
+	metadata := pod["metadata"].(map[string]interface{})
+	if _, ok := metadata["name"]; ok {
+		t.Error("expected the template's pod name to be replaced by generateName")
+	}
+	labels := metadata["labels"].(map[string]interface{})
+	if labels[LabelRunID] != "run_00000000000000ab" || labels["app"] != "mcpdrill-worker" {
+		t.Errorf("expected run and template labels, got %v", labels)
+	}
+	containers := pod["spec"].(map[string]interface{})["containers"].([]interface{})
+	worker := containers[1].(map[string]interface{})
+	args := worker["args"].([]interface{})
+	if len(args) != 2 || args[1] != "--max-vus=40" {
+		t.Errorf("expected --max-vus to be added to the worker container, got %v", args)
+	}
+	if _, ok := containers[0].(map[string]interface{})["args"]; ok {
+		t.Error("expected the sidecar container to be left alone")
+	}
+
+	released, err := k.ReleaseWorkers(context.Background(), "run_00000000000000ab")
+	if err != nil {
+		t.Fatalf("ReleaseWorkers failed: %v", err)
+	}
+	if len(released) != 2 {
+		t.Errorf("expected the deleted pods to be returned, got %v", released)
+	}
+	if len(api.deletes) != 1 || api.deletes[0] != LabelRunID+"=run_00000000000000ab" {
+		t.Errorf("expected pods to be deleted by run label, got %v", api.deletes)
+	}
+}
+
+func TestKubernetes_CapsWorkersAndReportsAPIErrors(t *testing.T) {
+	api := &fakeAPIServer{}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	dir := t.TempDir()
+	template := writeTestFile(t, dir, "pod.yaml", testPodTemplate)
+	k, err := NewKubernetes(KubernetesConfig{
+		Kubeconfig:  writeTestKubeconfig(t, dir, server.URL, "    token: test-token\n"),
+		PodTemplate: template,
+		MaxWorkers:  2,
+	})
+	if err != nil {
+		t.Fatalf("NewKubernetes failed: %v", err)
+	}
+	if names, err := k.LaunchWorkers(context.Background(), "run_00000000000000ab", 1000); err != nil || len(names) != 2 {
+		t.Errorf("expected launches capped at 2 workers, got %v, %v", names, err)
+	}
+
+	unauthorized, err := NewKubernetes(KubernetesConfig{
+		Kubeconfig:  writeTestKubeconfig(t, t.TempDir(), server.URL, "    token: wrong\n"),
+		PodTemplate: template,
+	})
+	if err != nil {
+		t.Fatalf("NewKubernetes failed: %v", err)
+	}
+	if _, err := unauthorized.LaunchWorkers(context.Background(), "run_00000000000000ab", 10); err == nil || !strings.Contains(err.Error(), "Unauthorized") {
+		t.Errorf("expected the API server's message in the error, got %v", err)
+	}
+}
+
+func TestNewKubernetes_RejectsUnsupportedConfig(t *testing.T) {
+	dir := t.TempDir()
+	template := writeTestFile(t, dir, "pod.yaml", testPodTemplate)
+
+	_, err := NewKubernetes(KubernetesConfig{
+		Kubeconfig:  writeTestKubeconfig(t, dir, "https://k8s.example", "    exec:\n      command: aws\n"),
+		PodTemplate: template,
+	})
+	if err == nil || !strings.Contains(err.Error(), "auth plugin") {
+		t.Errorf("expected exec auth to be rejected, got %v", err)
+	}
+
+	_, err = NewKubernetes(KubernetesConfig{
+		Kubeconfig:  writeTestKubeconfig(t, dir, "https://k8s.example", "    token: t\n"),
+		PodTemplate: writeTestFile(t, dir, "empty.yaml", "spec: {}\n"),
+	})
+	if err == nil || !strings.Contains(err.Error(), "containers") {
+		t.Errorf("expected a template without containers to be rejected, got %v", err)
+	}
+}
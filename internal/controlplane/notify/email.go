@@ -0,0 +1,157 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTPConfig configures EmailSender.
+type SMTPConfig struct {
+	// Addr is the SMTP server's host:port. Connections are upgraded with
+	// STARTTLS when the server offers it.
+	Addr string
+	// Username and Password, if set, authenticate with PLAIN auth, which
+	// net/smtp only sends over TLS or to localhost.
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// EmailSender mails messages as plain text through an SMTP server.
+type EmailSender struct {
+	config SMTPConfig
+	host   string
+}
+
+// NewEmailSender returns a sender through the server of config.
+func NewEmailSender(config SMTPConfig) (*EmailSender, error) {
+	host, _, err := net.SplitHostPort(config.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("smtp server must be host:port: %w", err)
+	}
+	if config.From == "" || len(config.To) == 0 {
+		return nil, fmt.Errorf("email notifications need a sender and at least one recipient")
+	}
+	return &EmailSender{config: config, host: host}, nil
+}
+
+func (s *EmailSender) Name() string { return "email" }
+
+func (s *EmailSender) Send(ctx context.Context, msg *Message) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", s.config.Addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.host}); err != nil {
+			return fmt.Errorf("starttls: %w", err)
+		}
+	}
+	if s.config.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.config.Username, s.config.Password, s.host)); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+	}
+	if err := client.Mail(s.config.From); err != nil {
+		return err
+	}
+	for _, to := range s.config.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("recipient %s: %w", to, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(emailMessage(s.config.From, s.config.To, msg, time.Now())); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// emailMessage returns msg as an RFC 5322 message with a plain text body.
+func emailMessage(from string, to []string, msg *Message, now time.Time) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", "[mcpdrill] "+msg.Subject()))
+	fmt.Fprintf(&b, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	for _, line := range strings.Split(emailBody(msg), "\n") {
+		b.WriteString(line)
+		b.WriteString("\r\n")
+	}
+	return b.Bytes()
+}
+
+// emailBody lays msg out as text: the run's facts and numbers, its SLOs and
+// findings, and links.
+func emailBody(msg *Message) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Run:       %s\n", msg.RunID)
+	fmt.Fprintf(&b, "State:     %s\n", msg.State)
+	if msg.ScenarioID != "" {
+		fmt.Fprintf(&b, "Scenario:  %s\n", msg.ScenarioID)
+	}
+	if msg.Project != "" {
+		fmt.Fprintf(&b, "Project:   %s\n", msg.Project)
+	}
+	fmt.Fprintf(&b, "Duration:  %s\n", msg.duration())
+	if reason := msg.reason(); reason != "" {
+		fmt.Fprintf(&b, "Reason:    %s\n", reason)
+	}
+
+	if a := msg.Analysis; a != nil {
+		fmt.Fprintf(&b, "\nOperations:  %d at %.1f/s\n", a.TotalOps, a.RPS)
+		fmt.Fprintf(&b, "Error rate:  %s\n", formatPercent(a.ErrorRate))
+		fmt.Fprintf(&b, "Latency:     p50 %d ms, p95 %d ms, p99 %d ms\n", a.LatencyP50, a.LatencyP95, a.LatencyP99)
+		if a.SLOs != nil && len(a.SLOs.Results) > 0 {
+			fmt.Fprintf(&b, "\nSLOs: %s\n", a.SLOs.Verdict)
+			for _, r := range a.SLOs.Results {
+				fmt.Fprintf(&b, "  %s\n", sloLine(r))
+			}
+		}
+		if len(a.Findings) > 0 {
+			b.WriteString("\nTop findings:\n")
+			for _, f := range a.Findings {
+				fmt.Fprintf(&b, "  - %s (%s)\n    %s\n", f.Title, f.Severity, f.Detail)
+			}
+		}
+	}
+
+	if msg.DashboardURL != "" || len(msg.Reports) > 0 {
+		b.WriteString("\nLinks:\n")
+		if msg.DashboardURL != "" {
+			fmt.Fprintf(&b, "  Dashboard: %s\n", msg.DashboardURL)
+		}
+		for _, r := range msg.Reports {
+			fmt.Fprintf(&b, "  %s report: %s\n", strings.ToUpper(r.Format), r.URL)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,185 @@
+// Package notify tells people about finished runs through Slack and email,
+// with the run's headline numbers, SLO verdict, top findings and links to
+// its reports.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/runmanager"
+)
+
+// sendTimeout bounds one notification through one sender.
+const sendTimeout = 30 * time.Second
+
+// Message is a finished run with the links of its notification resolved.
+type Message struct {
+	runmanager.RunNotification
+
+	// DashboardURL is the run's dashboard on the control plane, set when
+	// its public URL is known.
+	DashboardURL string
+	// Reports are the run's stored reports that have a URL: signed by the
+	// artifact store, or served by the control plane.
+	Reports []runmanager.ReportLink
+}
+
+// Sender delivers messages to one destination.
+type Sender interface {
+	// Name identifies the sender in logs.
+	Name() string
+	Send(ctx context.Context, msg *Message) error
+}
+
+// DefaultStates are the final states notified of unless configured.
+var DefaultStates = []runmanager.RunState{runmanager.RunStateCompleted, runmanager.RunStateFailed, runmanager.RunStateAborted}
+
+// Notifier implements runmanager.RunNotifier, sending runs that reach one of
+// its states through every sender in the background. A sender that fails is
+// logged; the others are unaffected.
+type Notifier struct {
+	senders   []Sender
+	states    map[runmanager.RunState]bool
+	publicURL string
+}
+
+// New returns a notifier of runs ending in states through senders.
+// publicURL is the control plane's address as readers of the messages reach
+// it, e.g. https://drill.example.com; without it messages only link reports
+// the artifact store signed.
+func New(senders []Sender, states []runmanager.RunState, publicURL string) *Notifier {
+	n := &Notifier{
+		senders:   senders,
+		states:    make(map[runmanager.RunState]bool, len(states)),
+		publicURL: strings.TrimSuffix(publicURL, "/"),
+	}
+	for _, s := range states {
+		n.states[s] = true
+	}
+	return n
+}
+
+// ParseStates parses a comma-separated list of final run states.
+func ParseStates(spec string) ([]runmanager.RunState, error) {
+	var states []runmanager.RunState
+	for _, s := range strings.Split(spec, ",") {
+		state := runmanager.RunState(strings.ToLower(strings.TrimSpace(s)))
+		switch state {
+		case "":
+			continue
+		case runmanager.RunStateCompleted, runmanager.RunStateFailed, runmanager.RunStateAborted:
+			states = append(states, state)
+		default:
+			return nil, fmt.Errorf("cannot notify of run state %q: expected completed, failed or aborted", s)
+		}
+	}
+	if len(states) == 0 {
+		return nil, fmt.Errorf("no run states to notify of")
+	}
+	return states, nil
+}
+
+func (n *Notifier) RunFinished(notification runmanager.RunNotification) {
+	if !n.states[notification.State] {
+		return
+	}
+	msg := n.message(notification)
+	go func() {
+		for _, sender := range n.senders {
+			ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+			err := sender.Send(ctx, msg)
+			cancel()
+			if err != nil {
+				slog.Warn("run_notification_failed", "sender", sender.Name(), "run_id", msg.RunID, "error", err)
+			}
+		}
+	}()
+}
+
+func (n *Notifier) message(notification runmanager.RunNotification) *Message {
+	msg := &Message{RunNotification: notification}
+	runURL := ""
+	if n.publicURL != "" {
+		runURL = n.publicURL + "/runs/" + url.PathEscape(notification.RunID)
+		msg.DashboardURL = runURL + "/dashboard"
+	}
+	if notification.Analysis == nil {
+		return msg
+	}
+	for _, r := range notification.Analysis.Reports {
+		if r.URL == "" && runURL != "" {
+			r.URL = runURL + "/artifacts/report/" + url.PathEscape(r.Filename)
+		}
+		if r.URL != "" {
+			msg.Reports = append(msg.Reports, r)
+		}
+	}
+	return msg
+}
+
+// Subject returns a one-line summary of the message, such as
+// "Run run_0000000000000001 completed: SLOs failed".
+func (m *Message) Subject() string {
+	subject := "Run " + m.RunID + " " + string(m.State)
+	if verdict := m.verdict(); verdict != "" {
+		subject += ": " + verdict
+	}
+	return subject
+}
+
+// verdict describes the run's SLO verdict, if it has one.
+func (m *Message) verdict() string {
+	if m.Analysis == nil || m.Analysis.SLOs == nil {
+		return ""
+	}
+	if m.Analysis.SLOs.Verdict == analysis.SLOVerdictPass {
+		return "SLOs passed"
+	}
+	return "SLOs failed"
+}
+
+// duration returns how long the run took, rounded to the second.
+func (m *Message) duration() string {
+	if m.EndedAtMs <= m.CreatedAtMs {
+		return "-"
+	}
+	return (time.Duration(m.EndedAtMs-m.CreatedAtMs) * time.Millisecond).Round(time.Second).String()
+}
+
+// reason describes why the run ended when it did not simply complete.
+func (m *Message) reason() string {
+	if m.StopReason != nil && m.StopReason.Reason != "" {
+		return m.StopReason.Reason
+	}
+	if m.State != runmanager.RunStateCompleted {
+		return m.Trigger
+	}
+	return ""
+}
+
+// sloLine describes one SLO result, e.g. "✗ p95_latency_ms < 500 (actual 612)".
+// Unnamed SLOs are already named after their objective and scope.
+func sloLine(r analysis.SLOResult) string {
+	mark := "✓"
+	if !r.Passed {
+		mark = "✗"
+	}
+	line := mark + " " + r.Name
+	if objective := strings.Join(strings.Fields(r.Objective), " "); !strings.Contains(r.Name, objective) {
+		line += ": " + objective
+	}
+	if r.Actual != nil {
+		line += fmt.Sprintf(" (actual %.4g)", *r.Actual)
+	}
+	return line
+}
+
+func formatPercent(rate float64) string {
+	return fmt.Sprintf("%.2f%%", rate*100)
+}
@@ -0,0 +1,233 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/runmanager"
+)
+
+func testNotification() runmanager.RunNotification {
+	actual := 612.0
+	return runmanager.RunNotification{
+		RunID:       "run_0000000000000001",
+		ScenarioID:  "checkout",
+		State:       runmanager.RunStateCompleted,
+		Trigger:     "analysis_completed",
+		CreatedAtMs: 1_000,
+		EndedAtMs:   91_000,
+		Analysis: &runmanager.RunAnalysisSummary{
+			TotalOps: 1200, RPS: 20, ErrorRate: 0.025, LatencyP50: 80, LatencyP95: 420, LatencyP99: 612,
+			SLOs: &analysis.SLOReport{Verdict: analysis.SLOVerdictFail, Results: []analysis.SLOResult{
+				{Name: "latency gate", Objective: "p99_latency_ms < 500", Actual: &actual},
+			}},
+			Findings: []analysis.Insight{{Severity: "critical", Title: "search <fails> often", Detail: "12% of calls time out"}},
+			Reports: []runmanager.ReportLink{
+				{Format: "json", Filename: "report.json"},
+				{Format: "html", Filename: "report.html", URL: "https://bucket.example.com/report.html?sig=1"},
+			},
+		},
+	}
+}
+
+type recordingSender struct {
+	mu       sync.Mutex
+	messages []*Message
+	done     chan struct{}
+}
+
+func (s *recordingSender) Name() string { return "recording" }
+
+func (s *recordingSender) Send(_ context.Context, msg *Message) error {
+	s.mu.Lock()
+	s.messages = append(s.messages, msg)
+	s.mu.Unlock()
+	s.done <- struct{}{}
+	return nil
+}
+
+func TestNotifier(t *testing.T) {
+	sender := &recordingSender{done: make(chan struct{}, 2)}
+	n := New([]Sender{sender}, []runmanager.RunState{runmanager.RunStateCompleted}, "https://drill.example.com/")
+
+	failed := testNotification()
+	failed.State = runmanager.RunStateFailed
+	n.RunFinished(failed)
+	n.RunFinished(testNotification())
+
+	select {
+	case <-sender.done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the completed run to be sent")
+	}
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+	if len(sender.messages) != 1 {
+		t.Fatalf("expected only the completed run, got %d messages", len(sender.messages))
+	}
+	msg := sender.messages[0]
+	if msg.DashboardURL != "https://drill.example.com/runs/run_0000000000000001/dashboard" {
+		t.Errorf("unexpected dashboard URL %q", msg.DashboardURL)
+	}
+	if len(msg.Reports) != 2 || msg.Reports[0].URL != "https://drill.example.com/runs/run_0000000000000001/artifacts/report/report.json" ||
+		msg.Reports[1].URL != "https://bucket.example.com/report.html?sig=1" {
+		t.Errorf("expected the signed URL kept and the other served by the control plane, got %+v", msg.Reports)
+	}
+	if subject := msg.Subject(); subject != "Run run_0000000000000001 completed: SLOs failed" {
+		t.Errorf("unexpected subject %q", subject)
+	}
+}
+
+func TestNotifier_WithoutPublicURL(t *testing.T) {
+	msg := New(nil, DefaultStates, "").message(testNotification())
+	if msg.DashboardURL != "" || len(msg.Reports) != 1 || msg.Reports[0].Format != "html" {
+		t.Errorf("expected only the signed report linked, got %+v", msg)
+	}
+}
+
+func TestParseStates(t *testing.T) {
+	states, err := ParseStates(" Failed, aborted")
+	if err != nil || len(states) != 2 || states[0] != runmanager.RunStateFailed {
+		t.Errorf("unexpected states %v, %v", states, err)
+	}
+	for _, spec := range []string{"", "running", "completed,stopping"} {
+		if _, err := ParseStates(spec); err == nil {
+			t.Errorf("expected %q to be rejected", spec)
+		}
+	}
+}
+
+func TestSlackSender(t *testing.T) {
+	var payload slackMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&payload)
+		io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	sender, err := NewSlackSender(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := New(nil, DefaultStates, "https://drill.example.com").message(testNotification())
+	if err := sender.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	if payload.Text != msg.Subject() || len(payload.Blocks) != 5 {
+		t.Fatalf("expected a header, facts, SLOs, findings and actions, got %+v", payload)
+	}
+	var fields []string
+	for _, f := range payload.Blocks[1].Fields {
+		fields = append(fields, f.Text)
+	}
+	if joined := strings.Join(fields, "|"); !strings.Contains(joined, "*Duration*\n1m30s") || !strings.Contains(joined, "80 / 420 / 612 ms") || !strings.Contains(joined, "2.50%") {
+		t.Errorf("unexpected fields %q", joined)
+	}
+	if slos := payload.Blocks[2].Text.Text; !strings.Contains(slos, "*SLOs: FAIL*") || !strings.Contains(slos, "✗ latency gate: p99_latency_ms &lt; 500 (actual 612)") {
+		t.Errorf("unexpected SLO section %q", slos)
+	}
+	if findings := payload.Blocks[3].Text.Text; !strings.Contains(findings, "search &lt;fails&gt; often") {
+		t.Errorf("expected findings escaped for Slack, got %q", findings)
+	}
+	if buttons := payload.Blocks[4].Elements; len(buttons) != 3 || buttons[0].Text.Text != "Dashboard" || buttons[2].Text.Text != "HTML report" {
+		t.Errorf("unexpected buttons %+v", buttons)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer failing.Close()
+	sender, _ = NewSlackSender(failing.URL)
+	if err := sender.Send(context.Background(), msg); err == nil || !strings.Contains(err.Error(), "invalid_token") {
+		t.Errorf("expected Slack's error, got %v", err)
+	}
+}
+
+// fakeSMTPServer accepts one message and returns what it received.
+func fakeSMTPServer(t *testing.T) (addr string, received <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	ch := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		tp := textproto.NewConn(conn)
+		var transcript strings.Builder
+		tp.PrintfLine("220 localhost ESMTP")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			transcript.WriteString(line + "\n")
+			switch cmd := strings.ToUpper(strings.Fields(line + " ")[0]); cmd {
+			case "EHLO", "HELO":
+				tp.PrintfLine("250 localhost")
+			case "DATA":
+				tp.PrintfLine("354 go ahead")
+				data, _ := io.ReadAll(tp.DotReader())
+				transcript.Write(data)
+				tp.PrintfLine("250 queued")
+			case "QUIT":
+				tp.PrintfLine("221 bye")
+				ch <- transcript.String()
+				return
+			default:
+				tp.PrintfLine("250 ok")
+			}
+		}
+	}()
+	return ln.Addr().String(), ch
+}
+
+func TestEmailSender(t *testing.T) {
+	addr, received := fakeSMTPServer(t)
+	sender, err := NewEmailSender(SMTPConfig{Addr: addr, From: "drill@example.com", To: []string{"sre@example.com", "qa@example.com"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := New(nil, DefaultStates, "https://drill.example.com").message(testNotification())
+	if err := sender.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	transcript := <-received
+	for _, want := range []string{
+		"MAIL FROM:<drill@example.com>",
+		"RCPT TO:<qa@example.com>",
+		"Subject: [mcpdrill] Run run_0000000000000001 completed: SLOs failed",
+		"Latency:     p50 80 ms, p95 420 ms, p99 612 ms",
+		"  ✗ latency gate: p99_latency_ms < 500 (actual 612)",
+		"  - search <fails> often (critical)",
+		"  HTML report: https://bucket.example.com/report.html?sig=1",
+	} {
+		if !strings.Contains(transcript, want) {
+			t.Errorf("expected %q in the message, got:\n%s", want, transcript)
+		}
+	}
+
+	if _, err := NewEmailSender(SMTPConfig{Addr: "mail.example.com", From: "a@example.com", To: []string{"b@example.com"}}); err == nil {
+		t.Error("expected an SMTP address without a port to be rejected")
+	}
+	if _, err := NewEmailSender(SMTPConfig{Addr: "mail.example.com:25", From: "a@example.com"}); err == nil {
+		t.Error("expected an error without recipients")
+	}
+}
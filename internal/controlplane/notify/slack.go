@@ -0,0 +1,151 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// slackMaxButtons caps the report links of a message, Slack's limit for an
+// actions block being 25.
+const slackMaxButtons = 5
+
+// SlackSender posts messages to a Slack incoming webhook as Block Kit
+// blocks, with a plain text fallback for notifications.
+type SlackSender struct {
+	webhookURL string
+	client     *http.Client
+}
+
+// NewSlackSender returns a sender to the incoming webhook at webhookURL.
+func NewSlackSender(webhookURL string) (*SlackSender, error) {
+	if !strings.HasPrefix(webhookURL, "https://") && !strings.HasPrefix(webhookURL, "http://") {
+		return nil, fmt.Errorf("slack webhook must be an http(s) URL")
+	}
+	return &SlackSender{webhookURL: webhookURL, client: &http.Client{Timeout: sendTimeout}}, nil
+}
+
+func (s *SlackSender) Name() string { return "slack" }
+
+func (s *SlackSender) Send(ctx context.Context, msg *Message) error {
+	body, err := json.Marshal(slackPayload(msg))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("slack webhook returned %s: %s", resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type slackElement struct {
+	Type string    `json:"type"`
+	Text slackText `json:"text"`
+	URL  string    `json:"url"`
+}
+
+type slackBlock struct {
+	Type     string         `json:"type"`
+	Text     *slackText     `json:"text,omitempty"`
+	Fields   []slackText    `json:"fields,omitempty"`
+	Elements []slackElement `json:"elements,omitempty"`
+}
+
+type slackMessage struct {
+	Text   string       `json:"text"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+func mrkdwn(text string) slackText { return slackText{Type: "mrkdwn", Text: text} }
+
+func slackField(name, value string) slackText {
+	return mrkdwn("*" + name + "*\n" + slackEscape(value))
+}
+
+// slackPayload lays msg out as a header, the run's facts and numbers, its
+// SLOs and findings, and buttons to its reports.
+func slackPayload(msg *Message) slackMessage {
+	header := msg.Subject()
+	blocks := []slackBlock{{Type: "header", Text: &slackText{Type: "plain_text", Text: header}}}
+
+	fields := []slackText{slackField("State", string(msg.State))}
+	if msg.ScenarioID != "" {
+		fields = append(fields, slackField("Scenario", msg.ScenarioID))
+	}
+	if msg.Project != "" {
+		fields = append(fields, slackField("Project", msg.Project))
+	}
+	fields = append(fields, slackField("Duration", msg.duration()))
+	if reason := msg.reason(); reason != "" {
+		fields = append(fields, slackField("Reason", reason))
+	}
+	if a := msg.Analysis; a != nil {
+		fields = append(fields,
+			slackField("Operations", fmt.Sprintf("%d at %.1f/s", a.TotalOps, a.RPS)),
+			slackField("Error rate", formatPercent(a.ErrorRate)),
+			slackField("Latency p50 / p95 / p99", fmt.Sprintf("%d / %d / %d ms", a.LatencyP50, a.LatencyP95, a.LatencyP99)),
+		)
+	}
+	blocks = append(blocks, slackBlock{Type: "section", Fields: fields})
+
+	if a := msg.Analysis; a != nil {
+		if a.SLOs != nil && len(a.SLOs.Results) > 0 {
+			lines := []string{"*SLOs: " + a.SLOs.Verdict + "*"}
+			for _, r := range a.SLOs.Results {
+				lines = append(lines, slackEscape(sloLine(r)))
+			}
+			blocks = append(blocks, slackBlock{Type: "section", Text: textPtr(mrkdwn(strings.Join(lines, "\n")))})
+		}
+		if len(a.Findings) > 0 {
+			lines := []string{"*Top findings*"}
+			for _, f := range a.Findings {
+				lines = append(lines, "• *"+slackEscape(f.Title)+"* ("+f.Severity+")\n"+slackEscape(f.Detail))
+			}
+			blocks = append(blocks, slackBlock{Type: "section", Text: textPtr(mrkdwn(strings.Join(lines, "\n")))})
+		}
+	}
+
+	var buttons []slackElement
+	if msg.DashboardURL != "" {
+		buttons = append(buttons, slackElement{Type: "button", Text: slackText{Type: "plain_text", Text: "Dashboard"}, URL: msg.DashboardURL})
+	}
+	for _, r := range msg.Reports {
+		if len(buttons) == slackMaxButtons {
+			break
+		}
+		buttons = append(buttons, slackElement{Type: "button", Text: slackText{Type: "plain_text", Text: strings.ToUpper(r.Format) + " report"}, URL: r.URL})
+	}
+	if len(buttons) > 0 {
+		blocks = append(blocks, slackBlock{Type: "actions", Elements: buttons})
+	}
+	return slackMessage{Text: header, Blocks: blocks}
+}
+
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// slackEscape escapes the characters Slack reserves for links and mentions.
+func slackEscape(s string) string {
+	return slackEscaper.Replace(s)
+}
+
+func textPtr(t slackText) *slackText { return &t }
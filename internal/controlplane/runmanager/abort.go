@@ -0,0 +1,138 @@
+package runmanager
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/scheduler"
+)
+
+// abortNoticeTTL is how long the workers of an aborted run keep being told
+// to stop it. The run's leases are revoked on abort, so its workers can no
+// longer be found through them.
+const abortNoticeTTL = 2 * time.Minute
+
+// AbortRun ends a run in ABORTED at once. Unlike a stop there is no drain and
+// no analysis, so no report is generated; it is meant for runs whose results
+// are of no use, such as misconfigured ones. Stage progression and an
+// analysis in progress are cancelled, the run's leases are revoked and the
+// workers that held them are told to stop immediately. A cancelled analysis
+// saves no further report artifacts and emits no REPORT_GENERATED event,
+// though one it is writing at the time may land. actor is recorded as the
+// run's AbortedBy.
+func (rm *RunManager) AbortRun(runID, actor, reason string) error {
+	if reason == "" {
+		reason = "aborted"
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	record, ok := rm.runs[runID]
+	if !ok {
+		return NewNotFoundError(runID)
+	}
+	if record.State == RunStateCompleted || record.State == RunStateFailed || record.State == RunStateAborted {
+		return NewTerminalStateError(runID, record.State, "abort")
+	}
+	if !CanTransition(record.State, RunStateAborted) {
+		return NewInvalidTransitionError(runID, record.State, RunStateAborted)
+	}
+
+	rm.cancelStageProgressionLocked(record)
+	rm.stopStopConditionEvaluatorLocked(record)
+	if record.analysisCancel != nil {
+		record.analysisCancel()
+		record.analysisCancel = nil
+	}
+	if record.drainCancel != nil {
+		close(record.drainCancel)
+		record.drainCancel = nil
+	}
+
+	oldState := record.State
+	record.State = RunStateAborted
+	record.UpdatedAtMs = time.Now().UnixMilli()
+	record.AbortedBy = actor
+	record.immediateStop = true
+	record.StopReason = &StopReason{
+		Mode:   StopModeImmediate,
+		Reason: reason,
+		Actor:  actor,
+		AtMs:   record.UpdatedAtMs,
+	}
+
+	if rm.leaseManager != nil {
+		rm.noteAbortedRunLocked(runID, rm.leaseManager.ListLeases(runID))
+		if err := rm.leaseManager.RevokeLeasesByRun(runID); err != nil {
+			log.Printf("[RunManager] Failed to revoke leases of aborted run %s: %v", runID, err)
+		}
+	}
+
+	eventLog := rm.eventLogs[runID]
+	abortPayload, _ := json.Marshal(map[string]interface{}{
+		"aborted_by": actor,
+		"reason":     reason,
+		"from_state": oldState,
+	})
+	appendEventWithLog(eventLog, RunEvent{
+		RunID:       runID,
+		ExecutionID: record.ExecutionID,
+		Type:        EventTypeRunAborted,
+		Actor:       ActorType(actor),
+		Payload:     abortPayload,
+		Evidence:    []Evidence{},
+	}, "AbortRun")
+
+	transitionPayload, _ := json.Marshal(map[string]interface{}{
+		"from_state": oldState,
+		"to_state":   record.State,
+		"trigger":    "aborted",
+		"actor":      actor,
+	})
+	appendEventWithLog(eventLog, RunEvent{
+		RunID:       runID,
+		ExecutionID: record.ExecutionID,
+		Type:        EventTypeStateTransition,
+		Actor:       ActorType(actor),
+		Payload:     transitionPayload,
+		Evidence:    []Evidence{},
+	}, "AbortRun")
+	rm.notifyRunFinishedLocked(record, reason)
+
+	if rm.telemetryStore != nil {
+		rm.telemetryStore.SetRunMetadata(runID, "", reason)
+	}
+	return nil
+}
+
+// noteAbortedRunLocked remembers the workers holding active leases of an
+// aborted run, so their heartbeats tell them to stop it. Expired notices of
+// other runs are dropped on the way. Must be called with rm.mu held.
+func (rm *RunManager) noteAbortedRunLocked(runID string, leases []*scheduler.Lease) {
+	nowMs := time.Now().UnixMilli()
+	for workerID, runs := range rm.abortNotices {
+		for id, untilMs := range runs {
+			if untilMs <= nowMs {
+				delete(runs, id)
+			}
+		}
+		if len(runs) == 0 {
+			delete(rm.abortNotices, workerID)
+		}
+	}
+
+	for _, lease := range leases {
+		if lease.State != scheduler.LeaseStateActive {
+			continue
+		}
+		if rm.abortNotices == nil {
+			rm.abortNotices = make(map[scheduler.WorkerID]map[string]int64)
+		}
+		if rm.abortNotices[lease.WorkerID] == nil {
+			rm.abortNotices[lease.WorkerID] = make(map[string]int64)
+		}
+		rm.abortNotices[lease.WorkerID][runID] = nowMs + abortNoticeTTL.Milliseconds()
+	}
+}
@@ -0,0 +1,155 @@
+package runmanager
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/artifacts"
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/scheduler"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+func TestAbortRun_SkipsAnalysisAndStopsWorkers(t *testing.T) {
+	rm := NewRunManager(createTestValidatorForWorkerTest(t))
+
+	registry := scheduler.NewRegistry()
+	lm := scheduler.NewLeaseManager(60000)
+	rm.SetScheduler(registry, scheduler.NewAllocator(registry, lm), lm)
+	rm.SetAssignmentSender(&mockAssignmentSender{assignments: make(map[string][]types.WorkerAssignment)})
+	workerID, err := registry.RegisterWorker(types.HostInfo{Hostname: "host1"}, types.WorkerCapacity{MaxVUs: 100})
+	if err != nil {
+		t.Fatalf("RegisterWorker failed: %v", err)
+	}
+
+	runID := createTestRunWithPolicy(t, rm, "")
+	if err := rm.StartRun(runID, "test"); err != nil {
+		t.Fatalf("StartRun failed: %v", err)
+	}
+	if len(lm.ListWorkerRunIDs(workerID)) != 1 {
+		t.Fatal("expected the worker to hold a lease of the run")
+	}
+
+	if err := rm.AbortRun(runID, "alice", "wrong target"); err != nil {
+		t.Fatalf("AbortRun failed: %v", err)
+	}
+
+	view, _ := rm.GetRun(runID)
+	if view.State != RunStateAborted || view.AbortedBy != "alice" {
+		t.Fatalf("expected the run aborted by alice, got %s by %q", view.State, view.AbortedBy)
+	}
+	if view.StopReason == nil || view.StopReason.Reason != "wrong target" || view.StopReason.Mode != StopModeImmediate {
+		t.Errorf("expected an immediate stop reason, got %+v", view.StopReason)
+	}
+	if ids := lm.ListWorkerRunIDs(workerID); len(ids) != 0 {
+		t.Errorf("expected the run's leases revoked, got %v", ids)
+	}
+	if ids := rm.ListImmediateStopRunsForWorker(string(workerID)); len(ids) != 1 || ids[0] != runID {
+		t.Errorf("expected the worker told to stop the run at once, got %v", ids)
+	}
+
+	var aborted bool
+	for _, event := range rm.eventLogs[runID].GetAll() {
+		switch event.Type {
+		case EventTypeRunAborted:
+			aborted = true
+		case EventTypeStopRequested, EventTypeAnalysisStarted:
+			t.Errorf("unexpected %s event for an aborted run", event.Type)
+		}
+	}
+	if !aborted {
+		t.Error("expected a RUN_ABORTED event")
+	}
+
+	if err := rm.AbortRun(runID, "alice", ""); err == nil {
+		t.Error("expected aborting an aborted run to fail")
+	}
+	if err := rm.AbortRun("run_missing", "alice", ""); err == nil {
+		t.Error("expected aborting an unknown run to fail")
+	}
+}
+
+func TestAbortRun_WhileStopping(t *testing.T) {
+	rm := NewRunManager(createTestValidator(t))
+	runID, _ := rm.CreateRun(createValidConfig(), "test-user")
+	_ = rm.StartRun(runID, "test-user")
+	if err := rm.RequestStop(runID, StopModeDrain, "test-user"); err != nil {
+		t.Fatalf("RequestStop failed: %v", err)
+	}
+
+	if err := rm.AbortRun(runID, "test-user", ""); err != nil {
+		t.Fatalf("AbortRun failed: %v", err)
+	}
+	view, _ := rm.GetRun(runID)
+	if view.State != RunStateAborted || view.StopReason.Reason != "aborted" {
+		t.Errorf("expected the stopping run aborted, got %s (%+v)", view.State, view.StopReason)
+	}
+}
+
+// blockingArtifactStore holds the first save until released.
+type blockingArtifactStore struct {
+	*artifacts.FilesystemStore
+	saving  chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func (s *blockingArtifactStore) SaveArtifact(runID string, artifactType artifacts.ArtifactType, filename string, data []byte) (*artifacts.ArtifactInfo, error) {
+	s.once.Do(func() {
+		close(s.saving)
+		<-s.release
+	})
+	return s.FilesystemStore.SaveArtifact(runID, artifactType, filename, data)
+}
+
+func TestAbortRun_DuringReportStorage(t *testing.T) {
+	rm := NewRunManager(createTestValidator(t))
+	defer rm.Shutdown()
+	fs, _ := artifacts.NewFilesystemStore(t.TempDir())
+	store := &blockingArtifactStore{FilesystemStore: fs, saving: make(chan struct{}), release: make(chan struct{})}
+	rm.SetArtifactStore(store)
+	telemetryStore := &mockTelemetryStore{data: make(map[string]*TelemetryData)}
+	rm.SetTelemetryStore(telemetryStore)
+
+	runID, err := rm.CreateRun(createValidConfig(), "test-user")
+	if err != nil {
+		t.Fatalf("CreateRun failed: %v", err)
+	}
+	telemetryStore.data[runID] = &TelemetryData{RunID: runID, StartTimeMs: 1000, EndTimeMs: 2000}
+	rm.mu.Lock()
+	rm.runs[runID].State = RunStateAnalyzing
+	rm.mu.Unlock()
+
+	done := make(chan error, 1)
+	go func() { done <- rm.analyzeRunWithTimeout(runID, time.Minute) }()
+	<-store.saving
+	if err := rm.AbortRun(runID, "alice", "wrong target"); err != nil {
+		t.Fatalf("AbortRun failed: %v", err)
+	}
+	close(store.release)
+	if err := <-done; err == nil {
+		t.Error("expected the aborted analysis to return an error")
+	}
+
+	view, _ := rm.GetRun(runID)
+	if view.State != RunStateAborted {
+		t.Fatalf("expected the run aborted, got %s", view.State)
+	}
+	infos, _ := fs.ListArtifacts(runID)
+	for _, info := range infos {
+		if info.Filename != "report.json" {
+			t.Errorf("expected no report saved after the abort, got %s", info.Filename)
+		}
+	}
+	for _, event := range rm.eventLogs[runID].GetAll() {
+		if event.Type == EventTypeReportGenerated || event.Type == EventTypeAnalysisCompleted {
+			t.Errorf("unexpected %s event for an aborted run", event.Type)
+		}
+	}
+	rm.mu.RLock()
+	record := rm.runs[runID]
+	if record.analysisSummary != nil || record.analysisCancel != nil {
+		t.Errorf("expected no analysis summary or cancel func left on the run, got %+v and %v", record.analysisSummary, record.analysisCancel != nil)
+	}
+	rm.mu.RUnlock()
+}
@@ -0,0 +1,305 @@
+package runmanager
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/stopconditions"
+)
+
+// RampModeAdaptive is the ramp mode that searches for the most load the
+// target sustains instead of climbing to a fixed target.
+const RampModeAdaptive = "adaptive"
+
+// capacitySearch picks the VUs of each probe of an adaptive ramp. It climbs
+// by stepVUs until a probe trips a guardrail, then bisects between the
+// highest level that held and the lowest that tripped until they are within
+// resolution of each other.
+type capacitySearch struct {
+	stepVUs    int
+	maxVUs     int
+	resolution int
+
+	passed int // highest level that held, 0 if none
+	failed int // lowest level that tripped, 0 if none
+}
+
+// next records the outcome of the probe at vus and returns the level to
+// probe next. done is set once the search has converged or reached maxVUs.
+func (s *capacitySearch) next(vus int, held bool) (next int, done bool) {
+	if held {
+		s.passed = max(s.passed, vus)
+	} else if s.failed == 0 || vus < s.failed {
+		s.failed = vus
+	}
+
+	if s.failed == 0 {
+		if vus >= s.maxVUs {
+			return vus, true
+		}
+		return min(vus+s.stepVUs, s.maxVUs), false
+	}
+	if s.failed-s.passed <= s.resolution {
+		return s.passed, true
+	}
+	return s.passed + (s.failed-s.passed)/2, false
+}
+
+// converged reports whether the search bracketed capacity within its
+// resolution, or found it beyond maxVUs.
+func (s *capacitySearch) converged() bool {
+	if s.failed == 0 {
+		return s.passed >= s.maxVUs
+	}
+	return s.failed-s.passed <= s.resolution
+}
+
+// newCapacitySearch sets up the search of an adaptive ramp stage and returns
+// it with the level of the first probe.
+func newCapacitySearch(stage *parsedStage, maxVUsCap int) (*capacitySearch, int) {
+	ramp := stage.Ramp
+	s := &capacitySearch{stepVUs: max(ramp.StepVUs, 1), maxVUs: ramp.MaxVUs}
+	if s.maxVUs <= 0 {
+		s.maxVUs = stage.Load.TargetVUs
+	}
+	s.maxVUs = max(capVUs(s.maxVUs, maxVUsCap), 1)
+	s.resolution = ramp.ResolutionVUs
+	if s.resolution <= 0 {
+		s.resolution = max(s.stepVUs/10, 1)
+	}
+
+	start := stage.Load.StartVUs
+	if start <= 0 {
+		start = s.stepVUs
+	}
+	return s, min(start, s.maxVUs)
+}
+
+// startAdaptiveRamp runs a ramp stage in adaptive mode. Each probe holds a
+// load level for step_every_ms, re-allocating the stage's VUs like a load
+// plateau. The second half of the probe is judged against the guardrails;
+// the first half lets the load settle. Once the search ends the ramp holds
+// the highest level that passed for the rest of the stage and records the
+// capacity found.
+func (rm *RunManager) startAdaptiveRamp(runID, executionID string, eventLog *EventLog, stage *parsedStage, parsedConfig *parsedRunConfig) {
+	search, vus := newCapacitySearch(stage, parsedConfig.Safety.HardCaps.MaxVUs)
+	stepMs := stage.Ramp.StepEveryMs
+	guardrails := toStopConditions(stage.Ramp.Guardrails)
+	for i := range guardrails {
+		if guardrails[i].ID == "" {
+			guardrails[i].ID = guardrails[i].Metric
+		}
+		if guardrails[i].ID == "" {
+			guardrails[i].ID = stopconditions.MetricComposite
+		}
+	}
+
+	log.Printf("[RunManager] Starting adaptive ramp for run %s: from %d VUs by %d up to %d, %dms per probe",
+		runID, vus, search.stepVUs, search.maxVUs, stepMs)
+
+	ctx, cancel := context.WithCancel(rm.ctx)
+	rm.mu.Lock()
+	if record, ok := rm.runs[runID]; ok {
+		record.rampCancel = cancel
+	}
+	rm.mu.Unlock()
+
+	startedAt := time.Now()
+	dispatch := func(vus int) {
+		rm.mu.RLock()
+		leaseManager := rm.leaseManager
+		rm.mu.RUnlock()
+		if leaseManager != nil {
+			if err := leaseManager.RevokeLeasesByRunAndStage(runID, stage.StageID); err != nil {
+				log.Printf("[RunManager] Failed to revoke ramp leases for run %s: %v", runID, err)
+			}
+		}
+		if vus <= 0 {
+			return
+		}
+		elapsed := time.Since(startedAt).Milliseconds()
+		remaining := max(stage.DurationMs-elapsed, 1000)
+		rm.dispatchVURange(runID, executionID, eventLog, stage, StageNameRamp, parsedConfig, 0, vus, remaining, remainingWarmup(stage, elapsed), vus)
+	}
+
+	go func() {
+		defer cancel()
+		var (
+			probes []analysis.CapacityProbe
+			seen   int
+		)
+		for {
+			dispatch(vus)
+			if !rm.waitRunning(ctx, runID, time.Duration(stepMs/2)*time.Millisecond) {
+				break
+			}
+			_, seen = rm.stageOperationsSince(runID, stage.Stage, seen)
+			judgedFrom := time.Now()
+			if !rm.waitRunning(ctx, runID, time.Duration(stepMs-stepMs/2)*time.Millisecond) {
+				break
+			}
+			var ops []analysis.OperationResult
+			ops, seen = rm.stageOperationsSince(runID, stage.Stage, seen)
+			probe := rm.judgeCapacityProbe(runID, guardrails, ops, vus, judgedFrom, time.Now())
+			probes = append(probes, probe)
+
+			next, done := search.next(vus, probe.Passed)
+			log.Printf("[RunManager] Adaptive ramp probe for run %s: %d VUs at %.1f RPS passed=%v, next %d VUs",
+				runID, vus, probe.RPS, probe.Passed, next)
+			rm.emitCapacityDecision(runID, executionID, eventLog, stage, map[string]interface{}{
+				"decision_type": "capacity_probe",
+				"probe":         probe,
+				"next_vus":      next,
+				"done":          done,
+			})
+			vus = next
+			if done {
+				break
+			}
+			if time.Since(startedAt).Milliseconds()+stepMs > stage.DurationMs {
+				log.Printf("[RunManager] Adaptive ramp for run %s ran out of stage time before converging", runID)
+				break
+			}
+		}
+
+		if len(probes) == 0 {
+			return
+		}
+		report := capacityReport(search, probes)
+		if ctx.Err() == nil {
+			dispatch(report.MaxSustainableVUs)
+		}
+		rm.mu.Lock()
+		if record, ok := rm.runs[runID]; ok {
+			record.Capacity = report
+		}
+		rm.mu.Unlock()
+		log.Printf("[RunManager] Adaptive ramp for run %s found capacity of %d VUs (%.1f RPS), converged=%v",
+			runID, report.MaxSustainableVUs, report.MaxSustainableRPS, report.Converged)
+		rm.emitCapacityDecision(runID, executionID, eventLog, stage, map[string]interface{}{
+			"decision_type": "capacity_found",
+			"capacity":      report,
+		})
+	}()
+}
+
+// stageOperationsSince returns the run's operations of stage received after
+// the first seen, and the count to pass as seen next time. Spilled and
+// dropped operations count towards seen but are not returned.
+func (rm *RunManager) stageOperationsSince(runID, stage string, seen int) ([]analysis.OperationResult, int) {
+	rm.mu.RLock()
+	telemetryStore := rm.telemetryStore
+	rm.mu.RUnlock()
+	if telemetryStore == nil {
+		return nil, seen
+	}
+	data, err := telemetryStore.GetTelemetryData(runID)
+	if err != nil || data == nil {
+		return nil, seen
+	}
+	older := data.SpilledOperations + data.DroppedOperations
+	total := older + len(data.Operations)
+	if total < seen {
+		seen = 0
+	}
+	var ops []analysis.OperationResult
+	for _, op := range data.Operations[min(max(seen-older, 0), len(data.Operations)):] {
+		if op.Stage == stage {
+			ops = append(ops, op)
+		}
+	}
+	return ops, total
+}
+
+// judgeCapacityProbe measures the throughput of a probe and checks its
+// operations and the server's agent metrics against the guardrails.
+func (rm *RunManager) judgeCapacityProbe(runID string, guardrails []stopconditions.Condition, ops []analysis.OperationResult, vus int, from, to time.Time) analysis.CapacityProbe {
+	measured := 0
+	for _, op := range ops {
+		if !op.Warmup {
+			measured++
+		}
+	}
+	probe := analysis.CapacityProbe{VUs: vus, DurationMs: to.Sub(from).Milliseconds(), Operations: measured}
+	probe.RPS, _, _ = analysis.ThroughputInterval(measured, probe.DurationMs)
+
+	var server stopconditions.ServerMetricsProvider
+	rm.mu.RLock()
+	serverMetricsSource := rm.serverMetricsSource
+	rm.mu.RUnlock()
+	if serverMetricsSource != nil {
+		if pairKey := rm.GetRunServerTelemetryPairKey(runID); pairKey != "" {
+			server = stopconditions.ServerMetricsProviderFunc(func(_ string, fromMs, toMs int64) []analysis.NodeSeries {
+				return serverMetricsSource.NodeSeries(pairKey, fromMs, toMs)
+			})
+		}
+	}
+
+	// A probe without operations cannot show the target coped.
+	trigger, tripped := stopconditions.Judge(runID, guardrails, ops, server, probe.DurationMs, to.UnixMilli())
+	probe.Passed = !tripped && measured > 0
+	if tripped {
+		probe.Guardrail = trigger.Condition.ID
+		probe.Observed = trigger.Observed
+	}
+	return probe
+}
+
+// capacityReport summarizes an adaptive ramp's probes. The throughput is
+// that of the last probe at the sustainable level.
+func capacityReport(search *capacitySearch, probes []analysis.CapacityProbe) *analysis.CapacityReport {
+	report := &analysis.CapacityReport{
+		MaxSustainableVUs: search.passed,
+		VUsLowerBound:     search.passed,
+		VUsUpperBound:     search.failed,
+		Converged:         search.converged(),
+		Probes:            probes,
+	}
+	for i := len(probes) - 1; i >= 0; i-- {
+		p := probes[i]
+		if !p.Passed || p.VUs != search.passed {
+			continue
+		}
+		report.MaxSustainableRPS, report.RPSLow, report.RPSHigh = analysis.ThroughputInterval(p.Operations, p.DurationMs)
+		break
+	}
+	return report
+}
+
+func (rm *RunManager) emitCapacityDecision(runID, executionID string, eventLog *EventLog, stage *parsedStage, fields map[string]interface{}) {
+	fields["stage"] = stage.Stage
+	fields["stage_id"] = stage.StageID
+	payload, _ := json.Marshal(fields)
+
+	stageName := StageName(stage.Stage)
+	stageID := stage.StageID
+	event := RunEvent{
+		RunID:       runID,
+		ExecutionID: executionID,
+		Type:        EventTypeDecision,
+		Actor:       ActorAutoramp,
+		Correlation: CorrelationContext{
+			Stage:   &stageName,
+			StageID: &stageID,
+		},
+		Payload:  payload,
+		Evidence: []Evidence{},
+	}
+	appendEventWithLog(eventLog, event, "emitCapacityDecision")
+}
+
+// capacityFromEvent recovers the capacity carried by the capacity_found
+// DECISION event of an adaptive ramp.
+func capacityFromEvent(event RunEvent) *analysis.CapacityReport {
+	var payload struct {
+		DecisionType string                   `json:"decision_type"`
+		Capacity     *analysis.CapacityReport `json:"capacity"`
+	}
+	if err := json.Unmarshal(event.Payload, &payload); err != nil || payload.DecisionType != "capacity_found" {
+		return nil
+	}
+	return payload.Capacity
+}
@@ -0,0 +1,81 @@
+package runmanager
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+)
+
+func TestCapacitySearch(t *testing.T) {
+	stage := &parsedStage{
+		Load: parsedLoad{TargetVUs: 200},
+		Ramp: &parsedRamp{Mode: RampModeAdaptive, StepVUs: 20, MaxVUs: 100, ResolutionVUs: 5},
+	}
+	search, vus := newCapacitySearch(stage, 80)
+	if vus != 20 || search.maxVUs != 80 {
+		t.Fatalf("expected to start at step_vus under the 80 VU hard cap, got %d up to %d", vus, search.maxVUs)
+	}
+
+	// Capacity lies between 45 and 50 VUs.
+	var probed []int
+	done := false
+	for !done && len(probed) < 20 {
+		probed = append(probed, vus)
+		vus, done = search.next(vus, vus < 50)
+	}
+	want := []int{20, 40, 60, 50, 45}
+	if len(probed) != len(want) {
+		t.Fatalf("expected probes %v, got %v", want, probed)
+	}
+	for i := range want {
+		if probed[i] != want[i] {
+			t.Fatalf("expected probes %v, got %v", want, probed)
+		}
+	}
+	if vus != 45 || !search.converged() || search.failed != 50 {
+		t.Errorf("expected to settle on 45 VUs below 50, got %d (%+v)", vus, search)
+	}
+
+	search, vus = newCapacitySearch(&parsedStage{Load: parsedLoad{StartVUs: 30, TargetVUs: 40}, Ramp: &parsedRamp{StepVUs: 20}}, 0)
+	if vus != 30 || search.resolution != 2 {
+		t.Fatalf("expected to start at start_vus with a default resolution of 2, got %d and %d", vus, search.resolution)
+	}
+	if vus, done = search.next(vus, true); vus != 40 || done {
+		t.Fatalf("expected to climb to max_vus, got %d done=%v", vus, done)
+	}
+	if _, done = search.next(vus, true); !done || !search.converged() {
+		t.Error("expected the search to end once max_vus held")
+	}
+}
+
+func TestCapacityReport(t *testing.T) {
+	search := &capacitySearch{stepVUs: 10, maxVUs: 100, resolution: 5, passed: 20, failed: 30}
+	probes := []analysis.CapacityProbe{
+		{VUs: 10, DurationMs: 10000, Operations: 400, Passed: true},
+		{VUs: 20, DurationMs: 10000, Operations: 700, Passed: true},
+		{VUs: 30, DurationMs: 10000, Operations: 900, Guardrail: "error_rate", Observed: 0.2},
+		{VUs: 20, DurationMs: 10000, Operations: 800, Passed: true},
+	}
+	report := capacityReport(search, probes)
+	if report.MaxSustainableVUs != 20 || report.VUsLowerBound != 20 || report.VUsUpperBound != 30 {
+		t.Errorf("expected 20 VUs bracketed by 20 and 30, got %+v", report)
+	}
+	if report.MaxSustainableRPS != 80 || report.RPSLow >= 80 || report.RPSHigh <= 80 {
+		t.Errorf("expected 80 RPS from the last probe at 20 VUs inside its bounds, got %v [%v, %v]",
+			report.MaxSustainableRPS, report.RPSLow, report.RPSHigh)
+	}
+	if report.Converged {
+		t.Error("expected a gap of 10 VUs not to have converged at a resolution of 5")
+	}
+
+	payload, _ := json.Marshal(map[string]interface{}{"decision_type": "capacity_found", "capacity": report})
+	restored := capacityFromEvent(RunEvent{Type: EventTypeDecision, Payload: payload})
+	if restored == nil || restored.MaxSustainableVUs != 20 || len(restored.Probes) != 4 {
+		t.Errorf("expected the capacity back from the event, got %+v", restored)
+	}
+	payload, _ = json.Marshal(map[string]interface{}{"decision_type": "capacity_probe", "probe": probes[0]})
+	if capacityFromEvent(RunEvent{Type: EventTypeDecision, Payload: payload}) != nil {
+		t.Error("expected no capacity from a probe decision")
+	}
+}
@@ -1,9 +1,13 @@
 package runmanager
 
 import (
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"log"
+	"slices"
 	"time"
 
 	"github.com/bc-dunia/mcpdrill/internal/analysis"
@@ -14,6 +18,19 @@ func (rm *RunManager) analyzeRunWithTimeout(runID string, timeout time.Duration)
 	ctx, cancel := context.WithTimeout(rm.ctx, timeout)
 	defer cancel()
 
+	rm.mu.Lock()
+	if record, ok := rm.runs[runID]; ok {
+		record.analysisCancel = cancel
+	}
+	rm.mu.Unlock()
+	defer func() {
+		rm.mu.Lock()
+		if record, ok := rm.runs[runID]; ok {
+			record.analysisCancel = nil
+		}
+		rm.mu.Unlock()
+	}()
+
 	done := make(chan error, 1)
 	go func() {
 		done <- rm.analyzeRunWithContext(ctx, runID)
@@ -53,9 +70,16 @@ func (rm *RunManager) analyzeRunWithContext(ctx context.Context, runID string) e
 
 	telemetryStore := rm.telemetryStore
 	artifactStore := rm.artifactStore
+	serverMetricsSource := rm.serverMetricsSource
 	eventLog := rm.eventLogs[runID]
 	executionID := record.ExecutionID
 	scenarioID := record.ScenarioID
+	slos := getSLOs(record.Config)
+	latencyBuckets := getLatencyBuckets(record.Config)
+	formats := getReportFormats(record.Config)
+	storeRawLogs := getStoreRawLogs(record.Config)
+	capacity := record.Capacity
+	conformance := record.Conformance
 	rm.mu.RUnlock()
 
 	if telemetryStore == nil {
@@ -86,16 +110,22 @@ func (rm *RunManager) analyzeRunWithContext(ctx context.Context, runID string) e
 
 	aggregator := analysis.NewAggregator()
 	aggregator.SetTimeRange(telemetryData.StartTimeMs, telemetryData.EndTimeMs)
-	for _, op := range telemetryData.Operations {
-		aggregator.AddOperation(op)
+	aggregator.SetLatencyBuckets(latencyBuckets)
+	aggregator.SetWorkerLabels(workerLabelsFromEvents(eventLog))
+	if err := addOperations(telemetryStore, telemetryData, "", aggregator); err != nil {
+		rm.failAnalysis(runID, "telemetry_retrieval_failed", err.Error())
+		return fmt.Errorf("failed to retrieve telemetry data: %w", err)
 	}
+	addGeneratorWarnings(aggregator, telemetryData.GeneratorWarnings, 0, 0)
 	metrics := aggregator.Compute()
+	rm.persistTelemetrySummary(runID, telemetryData, metrics)
 
 	// Check context before report generation
 	if err := ctx.Err(); err != nil {
 		return err
 	}
 
+	load := aggregator.LoadTimeline()
 	report := &analysis.Report{
 		RunID:      runID,
 		ScenarioID: scenarioID,
@@ -104,7 +134,15 @@ func (rm *RunManager) analyzeRunWithContext(ctx context.Context, runID string) e
 		Duration:   telemetryData.EndTimeMs - telemetryData.StartTimeMs,
 		Metrics:    metrics,
 		StopReason: telemetryData.StopReason,
+		Load:       load,
+
+		ServerMetrics: rm.serverMetricsReport(serverMetricsSource, runID, load, telemetryData.StartTimeMs, telemetryData.EndTimeMs),
+		ServerLogs:    rm.serverLogsReport(serverMetricsSource, runID, aggregator, telemetryData.StartTimeMs, telemetryData.EndTimeMs),
+		SLOs:          analysis.EvaluateSLOs(slos, metrics),
+		Capacity:      capacity,
+		Conformance:   conformance,
 	}
+	report.Insights = analysis.GenerateInsights(report)
 
 	reporter := analysis.NewReporter()
 
@@ -125,30 +163,122 @@ func (rm *RunManager) analyzeRunWithContext(ctx context.Context, runID string) e
 		return fmt.Errorf("failed to generate HTML report: %w", err)
 	}
 
-	// Check context before artifact storage
-	if err := ctx.Err(); err != nil {
-		return err
+	// An aborted run gets no report, so each save first checks the run is
+	// still being analyzed.
+	saveReport := func(filename string, data []byte) (*artifacts.ArtifactInfo, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return artifactStore.SaveArtifact(runID, artifacts.ArtifactTypeReport, filename, data)
 	}
 
-	jsonInfo, err := artifactStore.SaveArtifact(runID, artifacts.ArtifactTypeReport, "report.json", jsonData)
+	jsonInfo, err := saveReport("report.json", jsonData)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
 	if err != nil {
 		rm.failAnalysis(runID, "json_artifact_storage_failed", err.Error())
 		return fmt.Errorf("failed to store JSON report: %w", err)
 	}
 
-	htmlInfo, err := artifactStore.SaveArtifact(runID, artifacts.ArtifactTypeReport, "report.html", htmlData)
+	htmlInfo, err := saveReport("report.html", htmlData)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
 	if err != nil {
 		rm.failAnalysis(runID, "html_artifact_storage_failed", err.Error())
 		return fmt.Errorf("failed to store HTML report: %w", err)
 	}
 
-	rm.emitReportGeneratedEvent(runID, executionID, eventLog, jsonInfo, htmlInfo)
+	reports := []storedReport{
+		{format: "json", note: "JSON report", info: jsonInfo},
+		{format: "html", note: "HTML report", info: htmlInfo},
+	}
+	for _, exporter := range ciReportExporters {
+		if !slices.Contains(formats, exporter.format) {
+			continue
+		}
+		data, err := exporter.export(report)
+		if err != nil {
+			rm.failAnalysis(runID, exporter.format+"_report_generation_failed", err.Error())
+			return fmt.Errorf("failed to generate %s: %w", exporter.note, err)
+		}
+		info, err := saveReport(exporter.filename, data)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			rm.failAnalysis(runID, exporter.format+"_artifact_storage_failed", err.Error())
+			return fmt.Errorf("failed to store %s: %w", exporter.note, err)
+		}
+		reports = append(reports, storedReport{format: exporter.format, note: exporter.note, info: info})
+	}
+	if signer, ok := artifactStore.(artifacts.URLSigner); ok {
+		for i := range reports {
+			url, err := signer.SignedURL(runID, artifacts.ArtifactTypeReport, reports[i].info.Filename, ReportURLExpiry)
+			if err != nil {
+				log.Printf("[RunManager] Failed to sign download URL for %s of run %s: %v", reports[i].info.Filename, runID, err)
+				continue
+			}
+			reports[i].url = url
+		}
+	}
+
+	if source, ok := telemetryStore.(OperationLogSource); ok && storeRawLogs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rm.storeOperationLog(runID, executionID, eventLog, source, artifactStore)
+	}
 
-	rm.completeAnalysis(runID)
+	// AbortRun cancels ctx under rm.mu, so checking it there keeps an abort
+	// from landing between the check and the event.
+	rm.mu.Lock()
+	record, ok = rm.runs[runID]
+	if !ok || record.State != RunStateAnalyzing || ctx.Err() != nil {
+		rm.mu.Unlock()
+		return ctx.Err()
+	}
+	rm.emitReportGeneratedEvent(runID, executionID, eventLog, reports)
+	record.analysisSummary = summarizeAnalysis(report, reports)
+	rm.mu.Unlock()
+	rm.completeAnalysis(runID, report.SLOs)
 
 	return nil
 }
 
+// serverMetricsReport summarizes the server telemetry paired with a run over
+// [fromMs, toMs] and lines it up with the client load. Returns nil without a
+// source or pair key.
+func (rm *RunManager) serverMetricsReport(source ServerMetricsSource, runID string, load []analysis.LoadPoint, fromMs, toMs int64) *analysis.ServerMetricsReport {
+	if source == nil {
+		return nil
+	}
+	pairKey := rm.GetRunServerTelemetryPairKey(runID)
+	if pairKey == "" {
+		return nil
+	}
+	report := analysis.ComputeServerMetrics(source.NodeSeries(pairKey, fromMs, toMs), analysis.DefaultServerMetricsBucketMs)
+	report.CorrelateLoad(load)
+	return report
+}
+
+// serverLogsReport correlates the server logs paired with a run over
+// [fromMs, toMs] with the client errors aggregator saw. Returns nil unless
+// source keeps logs and some were shipped.
+func (rm *RunManager) serverLogsReport(source ServerMetricsSource, runID string, aggregator *analysis.Aggregator, fromMs, toMs int64) *analysis.ServerLogReport {
+	logSource, ok := source.(ServerLogSource)
+	if !ok {
+		return nil
+	}
+	pairKey := rm.GetRunServerTelemetryPairKey(runID)
+	if pairKey == "" {
+		return nil
+	}
+	logs := logSource.ServerLogs(pairKey, fromMs-analysis.ServerLogWindowMs, toMs)
+	return analysis.CorrelateServerLogs(aggregator.FailuresBySecond(), logs, fromMs, toMs)
+}
+
 // AnalyzeRun performs analysis on a run's telemetry data and generates reports.
 // It aggregates telemetry, generates HTML and JSON reports, stores them as artifacts,
 // and emits appropriate events. On success, transitions to COMPLETED state.
@@ -158,40 +288,112 @@ func (rm *RunManager) AnalyzeRun(runID string) error {
 	return rm.analyzeRunWithContext(rm.ctx, runID)
 }
 
-func (rm *RunManager) emitReportGeneratedEvent(runID, executionID string, eventLog *EventLog, jsonInfo, htmlInfo *artifacts.ArtifactInfo) {
+// ciReportExporters write the optional CI report formats, in addition to
+// report.json and report.html, when listed in reporting.formats.
+var ciReportExporters = []struct {
+	format   string
+	filename string
+	note     string
+	export   func(*analysis.Report) ([]byte, error)
+}{
+	{format: "junit", filename: "report.junit.xml", note: "JUnit XML report", export: artifacts.ExportJUnit},
+	{format: "sarif", filename: "report.sarif", note: "SARIF report", export: artifacts.ExportSARIF},
+}
+
+// OperationLogFilename is the telemetry artifact holding a run's raw
+// operation log, one JSON object per line, gzipped.
+const OperationLogFilename = "operations.jsonl.gz"
+
+// storeOperationLog streams the raw operation log of a run into the
+// artifact store. A failure is logged but does not fail analysis, since the
+// reports do not depend on it.
+func (rm *RunManager) storeOperationLog(runID, executionID string, eventLog *EventLog, source OperationLogSource, store artifacts.Store) {
+	count := 0
+	info, err := artifacts.SaveStream(store, runID, artifacts.ArtifactTypeTelemetry, OperationLogFilename, func(w io.Writer) error {
+		gz := gzip.NewWriter(w)
+		n, err := source.WriteOperationLog(runID, gz)
+		count = n
+		if err != nil {
+			return err
+		}
+		return gz.Close()
+	})
+	if err != nil {
+		log.Printf("[RunManager] Failed to store operation log for run %s: %v", runID, err)
+		return
+	}
+
 	payload, _ := json.Marshal(map[string]interface{}{
 		"run_id": runID,
-		"reports": []map[string]interface{}{
-			{
-				"type":     "json",
-				"filename": jsonInfo.Filename,
-				"path":     jsonInfo.Path,
-				"size":     jsonInfo.SizeBytes,
-			},
+		"artifacts": []map[string]interface{}{
 			{
-				"type":     "html",
-				"filename": htmlInfo.Filename,
-				"path":     htmlInfo.Path,
-				"size":     htmlInfo.SizeBytes,
+				"type":       "operation_log",
+				"filename":   info.Filename,
+				"path":       info.Path,
+				"size":       info.SizeBytes,
+				"operations": count,
 			},
 		},
 	})
-
 	event := RunEvent{
 		RunID:       runID,
 		ExecutionID: executionID,
-		Type:        EventTypeReportGenerated,
+		Type:        EventTypeArtifactStored,
 		Actor:       ActorAnalysis,
 		Payload:     payload,
 		Evidence: []Evidence{
-			{Kind: "artifact", Ref: jsonInfo.Path, Note: stringPtr("JSON report")},
-			{Kind: "artifact", Ref: htmlInfo.Path, Note: stringPtr("HTML report")},
+			{Kind: "artifact", Ref: info.Path, Note: stringPtr("Raw operation log")},
 		},
 	}
+	appendEventWithLog(eventLog, event, "storeOperationLog")
+}
+
+// ReportURLExpiry is how long the download links in REPORT_GENERATED stay
+// valid, for artifact stores that can sign them.
+const ReportURLExpiry = 7 * 24 * time.Hour
+
+// storedReport is a report artifact announced by REPORT_GENERATED.
+type storedReport struct {
+	format string
+	note   string
+	info   *artifacts.ArtifactInfo
+	url    string
+}
+
+func (rm *RunManager) emitReportGeneratedEvent(runID, executionID string, eventLog *EventLog, reports []storedReport) {
+	entries := make([]map[string]interface{}, len(reports))
+	evidence := make([]Evidence, len(reports))
+	for i, r := range reports {
+		entries[i] = map[string]interface{}{
+			"type":     r.format,
+			"filename": r.info.Filename,
+			"path":     r.info.Path,
+			"size":     r.info.SizeBytes,
+		}
+		if r.url != "" {
+			entries[i]["url"] = r.url
+		}
+		evidence[i] = Evidence{Kind: "artifact", Ref: r.info.Path, Note: stringPtr(r.note)}
+	}
+	payload, _ := json.Marshal(map[string]interface{}{
+		"run_id":  runID,
+		"reports": entries,
+	})
+
+	event := RunEvent{
+		RunID:       runID,
+		ExecutionID: executionID,
+		Type:        EventTypeReportGenerated,
+		Actor:       ActorAnalysis,
+		Payload:     payload,
+		Evidence:    evidence,
+	}
 	appendEventWithLog(eventLog, event, "emitReportGeneratedEvent")
 }
 
-func (rm *RunManager) completeAnalysis(runID string) {
+// completeAnalysis moves an analyzed run to its final state. The SLO verdict,
+// if any, is kept on the run and carried by the completion events.
+func (rm *RunManager) completeAnalysis(runID string, slos *analysis.SLOReport) {
 	rm.mu.Lock()
 	defer rm.mu.Unlock()
 
@@ -218,13 +420,18 @@ func (rm *RunManager) completeAnalysis(runID string) {
 	}
 
 	record.State = finalState
+	record.SLOs = slos
 	record.UpdatedAtMs = time.Now().UnixMilli()
 
 	eventLog := rm.eventLogs[runID]
 
-	completedPayload, _ := json.Marshal(map[string]interface{}{
+	completed := map[string]interface{}{
 		"run_id": runID,
-	})
+	}
+	if slos != nil {
+		completed["slos"] = slos
+	}
+	completedPayload, _ := json.Marshal(completed)
 	completedEvent := RunEvent{
 		RunID:       runID,
 		ExecutionID: record.ExecutionID,
@@ -235,12 +442,16 @@ func (rm *RunManager) completeAnalysis(runID string) {
 	}
 	appendEventWithLog(eventLog, completedEvent, "completeAnalysis")
 
-	transitionPayload, _ := json.Marshal(map[string]interface{}{
+	transition := map[string]interface{}{
 		"from_state": oldState,
 		"to_state":   record.State,
 		"trigger":    trigger,
 		"actor":      "analysis",
-	})
+	}
+	if slos != nil {
+		transition["slo_verdict"] = slos.Verdict
+	}
+	transitionPayload, _ := json.Marshal(transition)
 	transitionEvent := RunEvent{
 		RunID:       runID,
 		ExecutionID: record.ExecutionID,
@@ -250,6 +461,7 @@ func (rm *RunManager) completeAnalysis(runID string) {
 		Evidence:    []Evidence{},
 	}
 	appendEventWithLog(eventLog, transitionEvent, "completeAnalysis")
+	rm.notifyRunFinishedLocked(record, trigger)
 }
 
 func (rm *RunManager) failAnalysis(runID, reason, details string) {
@@ -302,4 +514,5 @@ func (rm *RunManager) failAnalysis(runID, reason, details string) {
 		Evidence:    []Evidence{},
 	}
 	appendEventWithLog(eventLog, transitionEvent, "failAnalysis")
+	rm.notifyRunFinishedLocked(record, reason)
 }
@@ -0,0 +1,27 @@
+package runmanager
+
+import (
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+// assignmentArrival returns an assignment's share of its stage's arrival
+// rate, or nil when the stage runs closed-loop VUs. The rate and the
+// in-flight cap are split in proportion to the assignment's VUs, so a ramp
+// stage dispatching part of its VUs offers the same part of the rate.
+func assignmentArrival(stage *parsedStage, vuCount, totalVUs int) *types.ArrivalConfig {
+	arrival := stage.Load.Arrival
+	if arrival == nil || arrival.RateRPS <= 0 || vuCount <= 0 {
+		return nil
+	}
+	totalVUs = max(totalVUs, vuCount)
+
+	share := float64(vuCount) / float64(totalVUs)
+	cfg := &types.ArrivalConfig{
+		RateRPS:  arrival.RateRPS * share,
+		Schedule: arrival.Schedule,
+	}
+	if arrival.MaxInFlight > 0 {
+		cfg.MaxInFlight = max((arrival.MaxInFlight*vuCount+totalVUs-1)/totalVUs, 1)
+	}
+	return cfg
+}
@@ -0,0 +1,41 @@
+package runmanager
+
+import (
+	"testing"
+)
+
+func TestAssignmentArrival(t *testing.T) {
+	closed := &parsedStage{Load: parsedLoad{TargetVUs: 10}}
+	if got := assignmentArrival(closed, 5, 10); got != nil {
+		t.Errorf("expected no arrival config for a closed-model stage, got %+v", got)
+	}
+
+	open := &parsedStage{Load: parsedLoad{
+		TargetVUs: 10,
+		Arrival:   &parsedArrival{RateRPS: 200, Schedule: "constant", MaxInFlight: 15},
+	}}
+
+	tests := []struct {
+		name        string
+		vuCount     int
+		totalVUs    int
+		rate        float64
+		maxInFlight int
+	}{
+		{"whole stage", 10, 10, 200, 15},
+		{"proportional share", 4, 10, 80, 6},
+		{"cap share rounds up", 1, 10, 20, 2},
+		{"total smaller than share", 10, 5, 200, 15},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := assignmentArrival(open, tt.vuCount, tt.totalVUs)
+			if got == nil {
+				t.Fatal("expected arrival config")
+			}
+			if got.RateRPS != tt.rate || got.MaxInFlight != tt.maxInFlight || got.Schedule != "constant" {
+				t.Errorf("expected %.0f rps with cap %d, got %+v", tt.rate, tt.maxInFlight, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package runmanager
+
+import (
+	"errors"
+	"log"
+	"sort"
+
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/scheduler"
+)
+
+// CapacityRequirement is the worker capacity an unfinished run needs at the
+// peak of its enabled stages.
+type CapacityRequirement struct {
+	RunID           string   `json:"run_id"`
+	State           RunState `json:"state"`
+	RequiredVUs     int      `json:"required_vus"`
+	RequiredWorkers int      `json:"required_workers"`
+}
+
+// CapacityShortfall describes an allocation that failed for lack of worker
+// capacity.
+type CapacityShortfall struct {
+	RunID            string    `json:"run_id"`
+	ExecutionID      string    `json:"execution_id"`
+	Stage            StageName `json:"stage"`
+	RequiredVUs      int       `json:"required_vus"`
+	AvailableVUs     int       `json:"available_vus"`
+	RequiredWorkers  int       `json:"required_workers"`
+	AvailableWorkers int       `json:"available_workers"`
+	Error            string    `json:"error"`
+}
+
+// ScaleHook is told when allocation fails for lack of worker capacity, so
+// that more workers can be started. CapacityShortfall must not block.
+type ScaleHook interface {
+	CapacityShortfall(shortfall CapacityShortfall)
+}
+
+// SetScaleHook configures the hook notified of capacity shortfalls.
+func (rm *RunManager) SetScaleHook(hook ScaleHook) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.scaleHook = hook
+}
+
+// PendingCapacity returns the capacity requirement of every run that has not
+// reached a terminal state, oldest first. Runs are allocated independently,
+// so each requirement is to be compared with the whole worker pool.
+func (rm *RunManager) PendingCapacity() []CapacityRequirement {
+	rm.mu.RLock()
+	type pendingRun struct {
+		requirement CapacityRequirement
+		createdAtMs int64
+		config      []byte
+	}
+	pending := make([]pendingRun, 0, len(rm.runs))
+	for _, record := range rm.runs {
+		switch record.State {
+		case RunStateCompleted, RunStateFailed, RunStateAborted, RunStateStopping, RunStateAnalyzing:
+			continue
+		}
+		pending = append(pending, pendingRun{
+			requirement: CapacityRequirement{RunID: record.RunID, State: record.State},
+			createdAtMs: record.CreatedAtMs,
+			config:      record.Config,
+		})
+	}
+	rm.mu.RUnlock()
+
+	sort.Slice(pending, func(i, j int) bool {
+		if pending[i].createdAtMs != pending[j].createdAtMs {
+			return pending[i].createdAtMs < pending[j].createdAtMs
+		}
+		return pending[i].requirement.RunID < pending[j].requirement.RunID
+	})
+
+	requirements := make([]CapacityRequirement, 0, len(pending))
+	for _, p := range pending {
+		parsedConfig, err := parseRunConfig(p.config)
+		if err != nil {
+			continue
+		}
+		p.requirement.RequiredVUs = peakVUs(parsedConfig)
+		p.requirement.RequiredWorkers = requiredWorkers(parsedConfig)
+		requirements = append(requirements, p.requirement)
+	}
+	return requirements
+}
+
+// peakVUs returns the most VUs any enabled stage of the run holds at once,
+// after the run's VU hard cap.
+func peakVUs(parsedConfig *parsedRunConfig) int {
+	maxVUs := parsedConfig.Safety.HardCaps.MaxVUs
+	peak := 0
+	for i := range parsedConfig.Stages {
+		stage := &parsedConfig.Stages[i]
+		if !stage.Enabled {
+			continue
+		}
+		var plateaus []loadPlateau
+		switch StageName(stage.Stage) {
+		case StageNameSpike:
+			baselineVUs := 0
+			if baseline := findStageByName(parsedConfig, StageNameBaseline); baseline != nil {
+				baselineVUs = baseline.Load.TargetVUs
+			}
+			plateaus = spikePlateaus(stage, baselineVUs, maxVUs)
+		case StageNameStep:
+			plateaus = stepPlateaus(stage, maxVUs)
+		default:
+			plateaus = []loadPlateau{{VUs: capVUs(stage.Load.TargetVUs, maxVUs)}}
+		}
+		for _, p := range plateaus {
+			peak = max(peak, p.VUs)
+		}
+	}
+	return peak
+}
+
+// projectWorkers returns the registered workers that may run runs of
+// project: the project's own and the shared ones.
+func projectWorkers(registry *scheduler.Registry, project string) []*scheduler.WorkerInfo {
+	workers := registry.ListWorkers()
+	eligible := workers[:0]
+	for _, w := range workers {
+		if w.ServesProject(project) {
+			eligible = append(eligible, w)
+		}
+	}
+	return eligible
+}
+
+// requiredWorkers returns the fewest workers the run can be allocated over:
+// one per generator group.
+func requiredWorkers(parsedConfig *parsedRunConfig) int {
+	return max(len(parsedConfig.GeneratorGroups), 1)
+}
+
+// notifyCapacityShortfall calls the scale hook if an allocation of
+// requiredVUs failed with err for lack of worker capacity.
+func (rm *RunManager) notifyCapacityShortfall(runID, executionID string, stageName StageName, parsedConfig *parsedRunConfig, requiredVUs int, err error) {
+	rm.mu.RLock()
+	hook := rm.scaleHook
+	registry := rm.registry
+	rm.mu.RUnlock()
+
+	if hook == nil || registry == nil {
+		return
+	}
+
+	workers := placementWorkers(registry, rm.GetRunProject(runID), parsedConfig.Placement)
+	availableVUs := 0
+	for _, w := range workers {
+		availableVUs += w.EffectiveCapacity.MaxVUs
+	}
+	workersNeeded := requiredWorkers(parsedConfig)
+
+	insufficient := errors.Is(err, scheduler.ErrInsufficientCapacity) ||
+		errors.Is(err, scheduler.ErrNoWorkersAvailable) ||
+		len(workers) < workersNeeded
+	if !insufficient {
+		return
+	}
+
+	log.Printf("[RunManager] Run %s needs %d VUs on %d workers, %d VUs on %d workers available, calling scale hook",
+		runID, requiredVUs, workersNeeded, availableVUs, len(workers))
+	hook.CapacityShortfall(CapacityShortfall{
+		RunID:            runID,
+		ExecutionID:      executionID,
+		Stage:            stageName,
+		RequiredVUs:      requiredVUs,
+		AvailableVUs:     availableVUs,
+		RequiredWorkers:  workersNeeded,
+		AvailableWorkers: len(workers),
+		Error:            err.Error(),
+	})
+}
@@ -0,0 +1,117 @@
+package runmanager
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/scheduler"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+type recordingScaleHook struct {
+	shortfalls []CapacityShortfall
+}
+
+func (h *recordingScaleHook) CapacityShortfall(shortfall CapacityShortfall) {
+	h.shortfalls = append(h.shortfalls, shortfall)
+}
+
+func TestPendingCapacity(t *testing.T) {
+	rm := NewRunManager(createTestValidatorForWorkerTest(t))
+	pending := createTestRunWithPolicy(t, rm, "")
+	finished := createTestRunWithPolicy(t, rm, "")
+	setRunState(t, rm, finished, RunStateCompleted)
+
+	requirements := rm.PendingCapacity()
+	if len(requirements) != 1 {
+		t.Fatalf("expected only the unfinished run, got %+v", requirements)
+	}
+	got := requirements[0]
+	if got.RunID != pending || got.State != RunStateCreated {
+		t.Errorf("expected run %s in state created, got %+v", pending, got)
+	}
+	if got.RequiredVUs != 50 || got.RequiredWorkers != 1 {
+		t.Errorf("expected the 50 VU peak on 1 worker, got %d VUs on %d workers", got.RequiredVUs, got.RequiredWorkers)
+	}
+}
+
+func TestStartRun_CallsScaleHookOnInsufficientCapacity(t *testing.T) {
+	rm := NewRunManager(createTestValidatorForWorkerTest(t))
+
+	registry := scheduler.NewRegistry()
+	lm := scheduler.NewLeaseManager(60000)
+	rm.SetScheduler(registry, scheduler.NewAllocator(registry, lm), lm)
+	rm.SetAssignmentSender(&mockAssignmentSender{assignments: make(map[string][]types.WorkerAssignment)})
+	hook := &recordingScaleHook{}
+	rm.SetScaleHook(hook)
+
+	registry.RegisterWorker(types.HostInfo{Hostname: "host1"}, types.WorkerCapacity{MaxVUs: 2})
+
+	runID := createTestRunWithPolicy(t, rm, "")
+	if err := rm.StartRun(runID, "test"); err == nil {
+		t.Fatal("expected StartRun to fail")
+	}
+
+	if len(hook.shortfalls) != 1 {
+		t.Fatalf("expected one shortfall, got %+v", hook.shortfalls)
+	}
+	got := hook.shortfalls[0]
+	if got.RunID != runID || got.Stage != StageNamePreflight {
+		t.Errorf("expected preflight shortfall of run %s, got %+v", runID, got)
+	}
+	if got.RequiredVUs != 5 || got.AvailableVUs != 2 || got.AvailableWorkers != 1 {
+		t.Errorf("expected 5 VUs required and 2 available on 1 worker, got %+v", got)
+	}
+}
+
+func TestStartRun_IgnoresWorkersOfOtherProjects(t *testing.T) {
+	rm := NewRunManager(createTestValidatorForWorkerTest(t))
+
+	registry := scheduler.NewRegistry()
+	lm := scheduler.NewLeaseManager(60000)
+	rm.SetScheduler(registry, scheduler.NewAllocator(registry, lm), lm)
+	rm.SetAssignmentSender(&mockAssignmentSender{assignments: make(map[string][]types.WorkerAssignment)})
+	hook := &recordingScaleHook{}
+	rm.SetScaleHook(hook)
+
+	registry.RegisterWorker(types.HostInfo{Hostname: "shared"}, types.WorkerCapacity{MaxVUs: 2})
+	registry.RegisterProjectWorker("team-b", types.HostInfo{Hostname: "team-b"}, types.WorkerCapacity{MaxVUs: 100})
+
+	runID := createTestRunWithPolicy(t, rm, "")
+	if err := rm.StartRun(runID, "test"); err == nil {
+		t.Fatal("expected StartRun to fail without the other project's worker")
+	}
+	if len(hook.shortfalls) != 1 || hook.shortfalls[0].AvailableVUs != 2 || hook.shortfalls[0].AvailableWorkers != 1 {
+		t.Fatalf("expected only the shared worker to count, got %+v", hook.shortfalls)
+	}
+}
+
+func TestOpenScaleHook_PostsShortfall(t *testing.T) {
+	received := make(chan CapacityShortfall, 1)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var shortfall CapacityShortfall
+		if err := json.NewDecoder(r.Body).Decode(&shortfall); err != nil {
+			t.Errorf("failed to decode shortfall: %v", err)
+		}
+		received <- shortfall
+	}))
+	defer webhook.Close()
+
+	hook, err := OpenScaleHook(webhook.URL)
+	if err != nil {
+		t.Fatalf("OpenScaleHook failed: %v", err)
+	}
+	hook.CapacityShortfall(CapacityShortfall{RunID: "run_0000000000000001", RequiredVUs: 100, AvailableVUs: 40})
+
+	select {
+	case got := <-received:
+		if got.RunID != "run_0000000000000001" || got.RequiredVUs != 100 || got.AvailableVUs != 40 {
+			t.Errorf("unexpected shortfall %+v", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not called")
+	}
+}
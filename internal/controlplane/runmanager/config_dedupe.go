@@ -0,0 +1,139 @@
+package runmanager
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+
+	"github.com/bc-dunia/mcpdrill/internal/validation"
+)
+
+const (
+	// maxCachedValidations caps the validation reports kept by config hash.
+	maxCachedValidations = 256
+	// maxPreviousRunIDs caps the earlier runs of the same config listed in
+	// a run view.
+	maxPreviousRunIDs = 20
+)
+
+// validationCache keeps the reports of configs that passed validation, by
+// config hash, so configs submitted again, as CI does, are not validated
+// again. Validation depends only on the config and the validator's policy,
+// which is fixed when the run manager is created. The oldest report is
+// dropped when the cache is full.
+type validationCache struct {
+	mu      sync.Mutex
+	reports map[string]*validation.ValidationReport
+	order   []string
+}
+
+// validate returns the cached report for configHash, or the report of
+// validate, caching it if it passed.
+func (c *validationCache) validate(configHash string, validate func() *validation.ValidationReport) *validation.ValidationReport {
+	c.mu.Lock()
+	cached, ok := c.reports[configHash]
+	c.mu.Unlock()
+	if ok {
+		return copyReport(cached)
+	}
+
+	report := validate()
+	if !report.OK {
+		return report
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.reports == nil {
+		c.reports = make(map[string]*validation.ValidationReport)
+	}
+	if _, ok := c.reports[configHash]; !ok {
+		if len(c.order) >= maxCachedValidations {
+			delete(c.reports, c.order[0])
+			c.order = c.order[1:]
+		}
+		c.reports[configHash] = copyReport(report)
+		c.order = append(c.order, configHash)
+	}
+	return report
+}
+
+// copyReport keeps callers from changing a cached report.
+func copyReport(r *validation.ValidationReport) *validation.ValidationReport {
+	return &validation.ValidationReport{
+		OK:       r.OK,
+		Errors:   slices.Clone(r.Errors),
+		Warnings: slices.Clone(r.Warnings),
+	}
+}
+
+// configKey groups the runs of a project with the same config.
+type configKey struct {
+	project    string
+	configHash string
+}
+
+// indexConfigLocked records the run under its config, keeping the runs of
+// each config in creation order. Must be called with rm.mu held and the run
+// in rm.runs.
+func (rm *RunManager) indexConfigLocked(record *RunRecord) {
+	if rm.configRuns == nil {
+		rm.configRuns = make(map[configKey][]string)
+	}
+	key := configKey{record.Project, record.ConfigHash}
+	ids := append(rm.configRuns[key], record.RunID)
+	slices.SortFunc(ids, func(a, b string) int {
+		if c := cmp.Compare(rm.runs[a].CreatedAtMs, rm.runs[b].CreatedAtMs); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	})
+	rm.configRuns[key] = ids
+}
+
+// unindexConfigLocked removes the run from the runs of its config. Must be
+// called with rm.mu held, before the run's config hash changes.
+func (rm *RunManager) unindexConfigLocked(record *RunRecord) {
+	key := configKey{record.Project, record.ConfigHash}
+	ids := slices.DeleteFunc(rm.configRuns[key], func(id string) bool { return id == record.RunID })
+	if len(ids) == 0 {
+		delete(rm.configRuns, key)
+		return
+	}
+	rm.configRuns[key] = ids
+}
+
+// previousRunIDsLocked returns the runs of the project created from the
+// same config before record, newest first. Must be called with rm.mu held.
+func (rm *RunManager) previousRunIDsLocked(record *RunRecord) []string {
+	ids := rm.configRuns[configKey{record.Project, record.ConfigHash}]
+	i := slices.Index(ids, record.RunID)
+	if i <= 0 {
+		return nil
+	}
+	previous := make([]string, 0, min(i, maxPreviousRunIDs))
+	for j := i - 1; j >= 0 && len(previous) < maxPreviousRunIDs; j-- {
+		previous = append(previous, ids[j])
+	}
+	return previous
+}
+
+// RunsInProgressWithSameConfig returns the other runs of the run's project
+// with the same config, and so the same target, that have been started
+// and have not ended, oldest first.
+func (rm *RunManager) RunsInProgressWithSameConfig(runID string) ([]string, error) {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	record, ok := rm.runs[runID]
+	if !ok {
+		return nil, NewNotFoundError(runID)
+	}
+	var inProgress []string
+	for _, id := range rm.configRuns[configKey{record.Project, record.ConfigHash}] {
+		other := rm.runs[id]
+		if id != runID && other.State != RunStateCreated && !isTerminalRunState(other.State) {
+			inProgress = append(inProgress, id)
+		}
+	}
+	return inProgress, nil
+}
@@ -0,0 +1,55 @@
+package runmanager
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestConfigDedupe(t *testing.T) {
+	rm := NewRunManager(createTestValidator(t))
+	defer rm.Shutdown()
+
+	config := createValidConfig()
+	var runIDs []string
+	for range 3 {
+		runID, err := rm.CreateRunInProject("default", config, nil, nil, "alice")
+		if err != nil {
+			t.Fatalf("CreateRunInProject failed: %v", err)
+		}
+		runIDs = append(runIDs, runID)
+	}
+	otherProject, _ := rm.CreateRunInProject("team-b", config, nil, nil, "alice")
+
+	if cached := len(rm.validations.reports); cached != 1 {
+		t.Errorf("expected the config to be validated once and cached, got %d cached reports", cached)
+	}
+	if report := rm.ValidateRunConfig([]byte(`{"scenario_id": 1}`)); report.OK || len(rm.validations.reports) != 1 {
+		t.Errorf("expected a failing report not to be cached, got %+v", report)
+	}
+
+	run, _ := rm.GetRun(runIDs[2])
+	if want := []string{runIDs[1], runIDs[0]}; !slices.Equal(run.PreviousRunIDs, want) {
+		t.Errorf("expected previous runs %v, got %v", want, run.PreviousRunIDs)
+	}
+	if run, _ := rm.GetRun(runIDs[0]); run.PreviousRunIDs != nil {
+		t.Errorf("expected no previous runs for the first run, got %v", run.PreviousRunIDs)
+	}
+	if run, _ := rm.GetRun(otherProject); run.PreviousRunIDs != nil {
+		t.Errorf("expected runs of other projects not to be linked, got %v", run.PreviousRunIDs)
+	}
+
+	rm.mu.Lock()
+	rm.runs[runIDs[0]].State = RunStateBaselineRunning
+	rm.runs[runIDs[1]].State = RunStateCompleted
+	rm.mu.Unlock()
+	if inProgress, _ := rm.RunsInProgressWithSameConfig(runIDs[2]); !slices.Equal(inProgress, []string{runIDs[0]}) {
+		t.Errorf("expected only the running run to be in progress, got %v", inProgress)
+	}
+
+	if err := rm.DeleteRun(runIDs[1], "alice"); err != nil {
+		t.Fatalf("DeleteRun failed: %v", err)
+	}
+	if run, _ := rm.GetRun(runIDs[2]); !slices.Equal(run.PreviousRunIDs, []string{runIDs[0]}) {
+		t.Errorf("expected the deleted run to be unlinked, got %v", run.PreviousRunIDs)
+	}
+}
@@ -6,15 +6,75 @@ import (
 	"strings"
 	"time"
 
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
 	"github.com/bc-dunia/mcpdrill/internal/types"
 )
 
 type parsedRunConfig struct {
-	Target        parsedTarget        `json:"target"`
-	Stages        []parsedStage       `json:"stages"`
-	Workload      parsedWorkload      `json:"workload"`
-	SessionPolicy parsedSessionPolicy `json:"session_policy"`
-	Safety        parsedSafety        `json:"safety"`
+	RunType         string                 `json:"run_type,omitempty"`
+	Conformance     *parsedConformance     `json:"conformance,omitempty"`
+	Target          parsedTarget           `json:"target"`
+	Targets         []parsedTargetEntry    `json:"targets,omitempty"`
+	Shadow          *parsedShadow          `json:"shadow,omitempty"`
+	Stages          []parsedStage          `json:"stages"`
+	Workload        parsedWorkload         `json:"workload"`
+	GeneratorGroups []parsedGeneratorGroup `json:"generator_groups,omitempty"`
+	Placement       *parsedPlacement       `json:"placement,omitempty"`
+	SessionPolicy   parsedSessionPolicy    `json:"session_policy"`
+	Safety          parsedSafety           `json:"safety"`
+	SLOs            []analysis.SLO         `json:"slos,omitempty"`
+	Reporting       parsedReporting        `json:"reporting"`
+}
+
+type parsedReporting struct {
+	Formats []string `json:"formats,omitempty"`
+	Include struct {
+		StoreRawLogs bool `json:"store_raw_logs"`
+	} `json:"include"`
+	LatencyBuckets *analysis.LatencyBucketConfig `json:"latency_buckets,omitempty"`
+}
+
+type parsedGeneratorGroup struct {
+	Name          string             `json:"name"`
+	Weight        int                `json:"weight"`
+	InFlightPerVU int                `json:"in_flight_per_vu,omitempty"`
+	ThinkTime     *parsedThinkTime   `json:"think_time,omitempty"`
+	OperationMix  []parsedOpMixEntry `json:"operation_mix,omitempty"`
+}
+
+// parsedTargetEntry is one of the weighted targets of a multi-target run.
+// Its fields override those of the run's target for its share of the VUs.
+type parsedTargetEntry struct {
+	Name            string            `json:"name"`
+	Weight          int               `json:"weight"`
+	URL             string            `json:"url"`
+	Headers         map[string]string `json:"headers,omitempty"`
+	Auth            *parsedAuth       `json:"auth,omitempty"`
+	BackendIDHeader string            `json:"backend_id_header,omitempty"`
+}
+
+// parsedShadow is the shadow target operations are duplicated to.
+type parsedShadow struct {
+	URL         string            `json:"url"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Auth        *parsedAuth       `json:"auth,omitempty"`
+	StableTools []string          `json:"stable_tools,omitempty"`
+}
+
+// parsedConformance is the options of a conformance run's protocol checks.
+type parsedConformance struct {
+	Tool             string                 `json:"tool,omitempty"`
+	ToolArguments    map[string]interface{} `json:"tool_arguments,omitempty"`
+	ProtocolVersion  string                 `json:"protocol_version,omitempty"`
+	CancelAfterMs    int64                  `json:"cancel_after_ms,omitempty"`
+	RequestTimeoutMs int64                  `json:"request_timeout_ms,omitempty"`
+}
+
+type parsedThinkTime struct {
+	Mode     string `json:"mode"`
+	BaseMs   int64  `json:"base_ms"`
+	JitterMs int64  `json:"jitter_ms"`
+	MaxMs    int64  `json:"max_ms,omitempty"`
 }
 
 type parsedRedirectPolicy struct {
@@ -24,19 +84,33 @@ type parsedRedirectPolicy struct {
 }
 
 type parsedAuth struct {
-	Type   string   `json:"type"`
-	Tokens []string `json:"tokens,omitempty"`
+	Type   string              `json:"type"`
+	Tokens []string            `json:"tokens,omitempty"`
+	OAuth2 *types.OAuth2Config `json:"oauth2,omitempty"`
 }
 
 type parsedTarget struct {
-	URL                   string                `json:"url"`
-	Transport             string                `json:"transport"`
-	Headers               map[string]string     `json:"headers,omitempty"`
-	Auth                  *parsedAuth           `json:"auth,omitempty"`
-	Identification        *parsedIdentification `json:"identification,omitempty"`
-	RedirectPolicy        *parsedRedirectPolicy `json:"redirect_policy,omitempty"`
-	ProtocolVersion       string                `json:"protocol_version,omitempty"`
-	ProtocolVersionPolicy string                `json:"protocol_version_policy,omitempty"`
+	URL                   string                  `json:"url"`
+	Transport             string                  `json:"transport"`
+	Headers               map[string]string       `json:"headers,omitempty"`
+	Auth                  *parsedAuth             `json:"auth,omitempty"`
+	Identification        *parsedIdentification   `json:"identification,omitempty"`
+	RedirectPolicy        *parsedRedirectPolicy   `json:"redirect_policy,omitempty"`
+	ProtocolVersion       string                  `json:"protocol_version,omitempty"`
+	ProtocolVersionPolicy string                  `json:"protocol_version_policy,omitempty"`
+	BackendIDHeader       string                  `json:"backend_id_header,omitempty"`
+	StreamResumption      *parsedStreamResumption `json:"stream_resumption,omitempty"`
+	ServerRequests        *parsedServerRequests   `json:"server_requests,omitempty"`
+	ProgressTokens        bool                    `json:"progress_tokens,omitempty"`
+}
+
+type parsedServerRequests struct {
+	Responses map[string]json.RawMessage `json:"responses,omitempty"`
+}
+
+type parsedStreamResumption struct {
+	MaxAttempts int   `json:"max_attempts"`
+	BackoffMs   int64 `json:"backoff_ms,omitempty"`
 }
 
 type parsedIdentification struct {
@@ -59,14 +133,49 @@ type parsedStreamingConfig struct {
 }
 
 type parsedStage struct {
-	StageID             string                 `json:"stage_id"`
-	Stage               string                 `json:"stage"`
-	Enabled             bool                   `json:"enabled"`
-	DurationMs          int64                  `json:"duration_ms"`
-	MaxDurationMs       int64                  `json:"max_duration_ms,omitempty"`
-	Load                parsedLoad             `json:"load"`
-	StopConditions      []parsedStopCondition  `json:"stop_conditions"`
-	StreamingStopConfig *parsedStreamingConfig `json:"streaming_stop_conditions,omitempty"`
+	StageID              string                 `json:"stage_id"`
+	Stage                string                 `json:"stage"`
+	Enabled              bool                   `json:"enabled"`
+	DurationMs           int64                  `json:"duration_ms"`
+	MaxDurationMs        int64                  `json:"max_duration_ms,omitempty"`
+	CheckpointIntervalMs int64                  `json:"checkpoint_interval_ms,omitempty"`
+	WarmupMs             int64                  `json:"warmup_ms,omitempty"`
+	Load                 parsedLoad             `json:"load"`
+	StopConditions       []parsedStopCondition  `json:"stop_conditions"`
+	StreamingStopConfig  *parsedStreamingConfig `json:"streaming_stop_conditions,omitempty"`
+	Spike                *parsedSpike           `json:"spike,omitempty"`
+	Steps                []parsedLoadStep       `json:"steps,omitempty"`
+	Ramp                 *parsedRamp            `json:"ramp,omitempty"`
+	Chaos                *parsedChaos           `json:"chaos,omitempty"`
+}
+
+// parsedChaos is the network faults injected into a stage's requests.
+type parsedChaos struct {
+	LatencyMs            int64   `json:"latency_ms,omitempty"`
+	JitterMs             int64   `json:"jitter_ms,omitempty"`
+	ResetRate            float64 `json:"reset_rate,omitempty"`
+	BandwidthBytesPerSec int64   `json:"bandwidth_bytes_per_sec,omitempty"`
+}
+
+// parsedRamp is the ramp block of a ramp stage. Only the adaptive mode is
+// acted on; step ramps are driven by the stage's load.
+type parsedRamp struct {
+	Mode          string                `json:"mode"`
+	StepEveryMs   int64                 `json:"step_every_ms"`
+	StepVUs       int                   `json:"step_vus"`
+	MaxVUs        int                   `json:"max_vus"`
+	ResolutionVUs int                   `json:"resolution_vus,omitempty"`
+	Guardrails    []parsedStopCondition `json:"guardrails,omitempty"`
+}
+
+type parsedSpike struct {
+	Multiplier float64 `json:"multiplier"`
+	HoldMs     int64   `json:"hold_ms,omitempty"`
+}
+
+type parsedLoadStep struct {
+	TargetVUs int   `json:"target_vus"`
+	HoldMs    int64 `json:"hold_ms"`
 }
 
 type parsedStopCondition struct {
@@ -77,6 +186,10 @@ type parsedStopCondition struct {
 	WindowMs       int64             `json:"window_ms"`
 	SustainWindows int               `json:"sustain_windows"`
 	Scope          map[string]string `json:"scope"`
+
+	// All and Any hold the terms of a composite condition.
+	All []parsedStopCondition `json:"all,omitempty"`
+	Any []parsedStopCondition `json:"any,omitempty"`
 }
 
 type parsedLoad struct {
@@ -84,12 +197,78 @@ type parsedLoad struct {
 	StartVUs   int `json:"start_vus,omitempty"`    // Starting VUs for ramp (default: 10% of target)
 	RampSteps  int `json:"ramp_steps,omitempty"`   // Number of steps to reach target (default: 5)
 	StepHoldMs int `json:"step_hold_ms,omitempty"` // How long to hold each step (default: duration/steps)
+
+	Arrival *parsedArrival `json:"arrival,omitempty"` // Open-model arrival rate; nil runs closed-loop VUs
+}
+
+type parsedArrival struct {
+	RateRPS     float64 `json:"rate_rps"`
+	Schedule    string  `json:"schedule,omitempty"`
+	MaxInFlight int     `json:"max_in_flight,omitempty"`
 }
 
 type parsedWorkload struct {
-	OpMix        []parsedOpMixEntry `json:"op_mix"`
-	OperationMix []parsedOpMixEntry `json:"operation_mix"`
-	Tools        *parsedToolsConfig `json:"tools,omitempty"`
+	OpMix         []parsedOpMixEntry   `json:"op_mix"`
+	OperationMix  []parsedOpMixEntry   `json:"operation_mix"`
+	Tools         *parsedToolsConfig   `json:"tools,omitempty"`
+	ResultCapture *parsedResultCapture `json:"result_capture,omitempty"`
+	RequestIDs    *parsedRequestIDs    `json:"request_ids,omitempty"`
+	DataFeeds     []parsedDataFeed     `json:"data_feeds,omitempty"`
+	Fuzz          *parsedFuzz          `json:"fuzz,omitempty"`
+	Throttle      *parsedThrottle      `json:"throttle,omitempty"`
+	Retry         *parsedRetry         `json:"retry,omitempty"`
+	ThinkTime     *parsedThinkTime     `json:"think_time,omitempty"`
+	// ToolThinkTime is the think time after calls to each tool it names.
+	ToolThinkTime map[string]parsedThinkTime `json:"tool_think_time,omitempty"`
+	Workflows     []parsedWorkflow           `json:"workflows,omitempty"`
+
+	CancellationRate       float64 `json:"cancellation_rate,omitempty"`
+	CancellationMaxDelayMs int64   `json:"cancellation_max_delay_ms,omitempty"`
+	CancellationGraceMs    int64   `json:"cancellation_grace_ms,omitempty"`
+}
+
+type parsedWorkflow struct {
+	Name   string                     `json:"name"`
+	Weight int                        `json:"weight"`
+	Steps  []types.WorkflowStepConfig `json:"steps"`
+}
+
+type parsedThrottle struct {
+	Policy        string `json:"policy"`
+	BaseBackoffMs int64  `json:"base_backoff_ms,omitempty"`
+	MaxBackoffMs  int64  `json:"max_backoff_ms,omitempty"`
+}
+
+type parsedRetry struct {
+	MaxRetries   int      `json:"max_retries"`
+	BackoffMs    int64    `json:"backoff_ms,omitempty"`
+	MaxBackoffMs int64    `json:"max_backoff_ms,omitempty"`
+	RetryOn      []string `json:"retry_on,omitempty"`
+}
+
+type parsedFuzz struct {
+	Ratio                float64  `json:"ratio"`
+	Mutations            []string `json:"mutations,omitempty"`
+	OversizedStringBytes int      `json:"oversized_string_bytes,omitempty"`
+	NestingDepth         int      `json:"nesting_depth,omitempty"`
+}
+
+type parsedDataFeed struct {
+	Name         string `json:"name"`
+	Format       string `json:"format"`
+	Distribution string `json:"distribution,omitempty"`
+	Data         string `json:"data"`
+}
+
+type parsedRequestIDs struct {
+	Format         string `json:"format,omitempty"`
+	LongLength     int    `json:"long_length,omitempty"`
+	DuplicateEvery int    `json:"duplicate_every,omitempty"`
+}
+
+type parsedResultCapture struct {
+	SummarizeAboveBytes *int `json:"summarize_above_bytes,omitempty"`
+	FingerprintMaxDepth int  `json:"fingerprint_max_depth,omitempty"`
 }
 
 type parsedToolsConfig struct {
@@ -98,14 +277,21 @@ type parsedToolsConfig struct {
 }
 
 type parsedToolSelection struct {
-	Mode string `json:"mode"`
+	Mode               string   `json:"mode"`
+	IncludeDestructive bool     `json:"include_destructive,omitempty"`
+	ExcludeTools       []string `json:"exclude_tools,omitempty"`
 }
 
+// toolSelectionAutoDiscover is the tools.selection.mode that calls the
+// tools the target lists instead of templates.
+const toolSelectionAutoDiscover = "auto_discover"
+
 type parsedToolTemplate struct {
 	TemplateID string                 `json:"template_id"`
 	ToolName   string                 `json:"tool_name"`
 	Weight     int                    `json:"weight"`
 	Arguments  map[string]interface{} `json:"arguments,omitempty"`
+	Checks     []types.CheckConfig    `json:"checks,omitempty"`
 }
 
 type parsedOpMixEntry struct {
@@ -115,13 +301,16 @@ type parsedOpMixEntry struct {
 	Arguments  map[string]interface{} `json:"arguments,omitempty"`
 	URI        string                 `json:"uri,omitempty"`
 	PromptName string                 `json:"prompt_name,omitempty"`
+	Checks     []types.CheckConfig    `json:"checks,omitempty"`
 }
 
 type parsedSessionPolicy struct {
-	Mode      string `json:"mode"`
-	PoolSize  int    `json:"pool_size,omitempty"`
-	TTLMs     int64  `json:"ttl_ms,omitempty"`
-	MaxIdleMs int64  `json:"max_idle_ms,omitempty"`
+	Mode             string `json:"mode"`
+	PoolSize         int    `json:"pool_size,omitempty"`
+	TTLMs            int64  `json:"ttl_ms,omitempty"`
+	MaxIdleMs        int64  `json:"max_idle_ms,omitempty"`
+	ChurnIntervalMs  int64  `json:"churn_interval_ms,omitempty"`
+	ChurnIntervalOps int64  `json:"churn_interval_ops,omitempty"`
 }
 
 type parsedSafety struct {
@@ -139,6 +328,7 @@ type parsedHardCaps struct {
 	MaxVUs        int   `json:"max_vus"`
 	MaxDurationMs int64 `json:"max_duration_ms"`
 	MaxErrors     int   `json:"max_errors"`
+	MaxTotalOps   int64 `json:"max_total_ops"`
 }
 
 func parseRunConfig(config []byte) (*parsedRunConfig, error) {
@@ -157,11 +347,30 @@ func parseRunConfig(config []byte) (*parsedRunConfig, error) {
 
 	parsed.Workload.OpMix = expandToolsTemplates(parsed.Workload.OpMix, parsed.Workload.Tools)
 
+	for i := range parsed.Workload.Workflows {
+		steps := parsed.Workload.Workflows[i].Steps
+		for j := range steps {
+			steps[j].Operation = normalizeOperationName(steps[j].Operation)
+			if steps[j].Name == "" {
+				steps[j].Name = fmt.Sprintf("step_%d", j+1)
+			}
+		}
+	}
+
+	for i := range parsed.GeneratorGroups {
+		group := &parsed.GeneratorGroups[i]
+		for j := range group.OperationMix {
+			group.OperationMix[j].Operation = normalizeOperationName(group.OperationMix[j].Operation)
+		}
+		group.OperationMix = expandToolsTemplates(group.OperationMix, parsed.Workload.Tools)
+	}
+
 	return &parsed, nil
 }
 
 func expandToolsTemplates(opMix []parsedOpMixEntry, tools *parsedToolsConfig) []parsedOpMixEntry {
-	if tools == nil || len(tools.Templates) == 0 {
+	// With auto discovery, workers fill in nameless tools/call entries.
+	if tools == nil || len(tools.Templates) == 0 || tools.Selection.Mode == toolSelectionAutoDiscover {
 		return opMix
 	}
 
@@ -175,6 +384,7 @@ func expandToolsTemplates(opMix []parsedOpMixEntry, tools *parsedToolsConfig) []
 					Weight:    tmpl.Weight,
 					ToolName:  tmpl.ToolName,
 					Arguments: tmpl.Arguments,
+					Checks:    append(append([]types.CheckConfig(nil), op.Checks...), tmpl.Checks...),
 				})
 			}
 		} else {
@@ -236,6 +446,46 @@ func buildTargetHeaders(runID string, target *parsedTarget) map[string]string {
 	return headers
 }
 
+func buildSessionPolicyConfig(policy parsedSessionPolicy) types.SessionPolicyConfig {
+	return types.SessionPolicyConfig{
+		Mode:             policy.Mode,
+		PoolSize:         policy.PoolSize,
+		TTLMs:            policy.TTLMs,
+		MaxIdleMs:        policy.MaxIdleMs,
+		ChurnIntervalMs:  policy.ChurnIntervalMs,
+		ChurnIntervalOps: policy.ChurnIntervalOps,
+	}
+}
+
+func buildChaosConfig(stage *parsedStage) *types.ChaosConfig {
+	if stage == nil || stage.Chaos == nil {
+		return nil
+	}
+	return &types.ChaosConfig{
+		LatencyMs:            stage.Chaos.LatencyMs,
+		JitterMs:             stage.Chaos.JitterMs,
+		ResetRate:            stage.Chaos.ResetRate,
+		BandwidthBytesPerSec: stage.Chaos.BandwidthBytesPerSec,
+	}
+}
+
+func buildStreamResumptionConfig(r *parsedStreamResumption) *types.StreamResumptionConfig {
+	if r == nil {
+		return nil
+	}
+	return &types.StreamResumptionConfig{
+		MaxAttempts: r.MaxAttempts,
+		BackoffMs:   r.BackoffMs,
+	}
+}
+
+func buildServerRequestsConfig(r *parsedServerRequests) *types.ServerRequestsConfig {
+	if r == nil {
+		return nil
+	}
+	return &types.ServerRequestsConfig{Responses: r.Responses}
+}
+
 func buildRedirectPolicy(policy *parsedRedirectPolicy) *types.RedirectPolicyConfig {
 	if policy == nil {
 		return nil
@@ -254,6 +504,139 @@ func buildAuthConfig(auth *parsedAuth) *types.AuthConfig {
 	return &types.AuthConfig{
 		Type:   auth.Type,
 		Tokens: auth.Tokens,
+		OAuth2: auth.OAuth2,
+	}
+}
+
+func buildResultCaptureConfig(capture *parsedResultCapture) *types.ResultCaptureConfig {
+	if capture == nil {
+		return nil
+	}
+	// Keep the worker default threshold when only the depth is configured.
+	summarizeAbove := -1
+	if capture.SummarizeAboveBytes != nil {
+		summarizeAbove = *capture.SummarizeAboveBytes
+	}
+	return &types.ResultCaptureConfig{
+		SummarizeAboveBytes: summarizeAbove,
+		FingerprintMaxDepth: capture.FingerprintMaxDepth,
+	}
+}
+
+func buildRequestIDConfig(ids *parsedRequestIDs) *types.RequestIDConfig {
+	if ids == nil {
+		return nil
+	}
+	return &types.RequestIDConfig{
+		Format:         ids.Format,
+		LongLength:     ids.LongLength,
+		DuplicateEvery: ids.DuplicateEvery,
+	}
+}
+
+func buildDataFeedConfigs(feeds []parsedDataFeed) []types.DataFeedConfig {
+	if len(feeds) == 0 {
+		return nil
+	}
+	configs := make([]types.DataFeedConfig, len(feeds))
+	for i, f := range feeds {
+		configs[i] = types.DataFeedConfig{
+			Name:         f.Name,
+			Format:       f.Format,
+			Distribution: f.Distribution,
+			Data:         f.Data,
+		}
+	}
+	return configs
+}
+
+func buildCancellationConfig(w parsedWorkload) *types.CancellationConfig {
+	if w.CancellationRate <= 0 {
+		return nil
+	}
+	return &types.CancellationConfig{
+		Rate:       w.CancellationRate,
+		MaxDelayMs: w.CancellationMaxDelayMs,
+		GraceMs:    w.CancellationGraceMs,
+	}
+}
+
+func buildThrottleConfig(t *parsedThrottle) *types.ThrottleConfig {
+	if t == nil {
+		return nil
+	}
+	return &types.ThrottleConfig{
+		Policy:        t.Policy,
+		BaseBackoffMs: t.BaseBackoffMs,
+		MaxBackoffMs:  t.MaxBackoffMs,
+	}
+}
+
+func buildRetryConfig(r *parsedRetry) *types.RetryConfig {
+	if r == nil || r.MaxRetries <= 0 {
+		return nil
+	}
+	return &types.RetryConfig{
+		MaxRetries:   r.MaxRetries,
+		BackoffMs:    r.BackoffMs,
+		MaxBackoffMs: r.MaxBackoffMs,
+		RetryOn:      r.RetryOn,
+	}
+}
+
+func buildThinkTimeConfig(tt *parsedThinkTime) *types.ThinkTimeConfig {
+	if tt == nil {
+		return nil
+	}
+	return &types.ThinkTimeConfig{
+		Mode:     tt.Mode,
+		BaseMs:   tt.BaseMs,
+		JitterMs: tt.JitterMs,
+		MaxMs:    tt.MaxMs,
+	}
+}
+
+func buildToolThinkTimeConfig(configs map[string]parsedThinkTime) map[string]types.ThinkTimeConfig {
+	if len(configs) == 0 {
+		return nil
+	}
+	result := make(map[string]types.ThinkTimeConfig, len(configs))
+	for tool, tt := range configs {
+		result[tool] = *buildThinkTimeConfig(&tt)
+	}
+	return result
+}
+
+func buildWorkflowConfigs(workflows []parsedWorkflow) []types.WorkflowConfig {
+	if len(workflows) == 0 {
+		return nil
+	}
+	result := make([]types.WorkflowConfig, len(workflows))
+	for i, wf := range workflows {
+		result[i] = types.WorkflowConfig{Name: wf.Name, Weight: wf.Weight, Steps: wf.Steps}
+	}
+	return result
+}
+
+func buildFuzzConfig(f *parsedFuzz) *types.FuzzConfig {
+	if f == nil {
+		return nil
+	}
+	return &types.FuzzConfig{
+		Ratio:                f.Ratio,
+		Mutations:            f.Mutations,
+		OversizedStringBytes: f.OversizedStringBytes,
+		NestingDepth:         f.NestingDepth,
+	}
+}
+
+func buildAutoDiscoverConfig(tools *parsedToolsConfig) *types.AutoDiscoverToolsConfig {
+	if tools == nil || tools.Selection.Mode != toolSelectionAutoDiscover {
+		return nil
+	}
+	return &types.AutoDiscoverToolsConfig{
+		IncludeDestructive: tools.Selection.IncludeDestructive,
+		ExcludeTools:       tools.Selection.ExcludeTools,
 	}
 }
 
@@ -287,6 +670,43 @@ func getAnalysisTimeout(config []byte) time.Duration {
 	return time.Duration(parsed.Safety.AnalysisTimeoutMs) * time.Millisecond
 }
 
+// getSLOs returns the SLOs of a run config, nil if it defines none or does
+// not parse.
+func getSLOs(config []byte) []analysis.SLO {
+	parsed, err := parseRunConfig(config)
+	if err != nil {
+		return nil
+	}
+	return parsed.SLOs
+}
+
+// getLatencyBuckets returns the reporting.latency_buckets of a run config,
+// nil if it sets none or does not parse.
+func getLatencyBuckets(config []byte) *analysis.LatencyBucketConfig {
+	parsed, err := parseRunConfig(config)
+	if err != nil {
+		return nil
+	}
+	return parsed.Reporting.LatencyBuckets
+}
+
+// getStoreRawLogs reports whether a run config asks for the raw operation
+// log to be kept as an artifact.
+func getStoreRawLogs(config []byte) bool {
+	parsed, err := parseRunConfig(config)
+	return err == nil && parsed.Reporting.Include.StoreRawLogs
+}
+
+// getReportFormats returns the reporting.formats of a run config, nil if it
+// does not parse.
+func getReportFormats(config []byte) []string {
+	parsed, err := parseRunConfig(config)
+	if err != nil {
+		return nil
+	}
+	return parsed.Reporting.Formats
+}
+
 func convertOpMix(entries []parsedOpMixEntry) []types.OpMixEntry {
 	result := make([]types.OpMixEntry, len(entries))
 	for i, e := range entries {
@@ -297,6 +717,7 @@ func convertOpMix(entries []parsedOpMixEntry) []types.OpMixEntry {
 			Arguments:  e.Arguments,
 			URI:        e.URI,
 			PromptName: e.PromptName,
+			Checks:     e.Checks,
 		}
 	}
 	return result
@@ -0,0 +1,133 @@
+package runmanager
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+	"github.com/bc-dunia/mcpdrill/internal/artifacts"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+const (
+	// RunTypeConformance is the run_type of a run that checks the target's
+	// protocol handling instead of putting load on it.
+	RunTypeConformance = "conformance"
+	// ConformanceReportFilename is the artifact a conformance run's report
+	// is stored as.
+	ConformanceReportFilename = "conformance.json"
+)
+
+// isConformanceRun reports whether the run is a conformance run.
+func (c *parsedRunConfig) isConformanceRun() bool {
+	return c.RunType == RunTypeConformance
+}
+
+// buildConformanceConfig returns the conformance checks of a run's
+// assignments, or nil if the run is not a conformance run.
+func buildConformanceConfig(config *parsedRunConfig) *types.ConformanceConfig {
+	if !config.isConformanceRun() {
+		return nil
+	}
+	conformance := config.Conformance
+	if conformance == nil {
+		return &types.ConformanceConfig{}
+	}
+	return &types.ConformanceConfig{
+		Tool:             conformance.Tool,
+		ToolArguments:    conformance.ToolArguments,
+		ProtocolVersion:  conformance.ProtocolVersion,
+		CancelAfterMs:    conformance.CancelAfterMs,
+		RequestTimeoutMs: conformance.RequestTimeoutMs,
+	}
+}
+
+// conformanceVUs returns the VUs to allocate for a stage of targetVUs: a
+// conformance run checks the target once, from a single worker.
+func conformanceVUs(config *parsedRunConfig, targetVUs int) int {
+	if config.isConformanceRun() {
+		return 1
+	}
+	return targetVUs
+}
+
+// RecordConformanceReport records the protocol checks a worker ran against
+// the target of a conformance run. Only the first report of a run is kept;
+// it returns false for later ones. The report is stored as the
+// conformance.json artifact, announced by CONFORMANCE_CHECKED and included
+// in the run's report, and the run is stopped since it has nothing left
+// to do.
+func (rm *RunManager) RecordConformanceReport(runID, workerID string, report *analysis.ConformanceReport) (bool, error) {
+	rm.mu.Lock()
+	record, ok := rm.runs[runID]
+	if !ok {
+		rm.mu.Unlock()
+		return false, NewNotFoundError(runID)
+	}
+	if record.State == RunStateCompleted || record.State == RunStateFailed || record.State == RunStateAborted {
+		rm.mu.Unlock()
+		return false, NewTerminalStateError(runID, record.State, "record conformance report")
+	}
+	if record.conformanceChecked {
+		rm.mu.Unlock()
+		return false, nil
+	}
+	record.conformanceChecked = true
+	record.Conformance = report
+	executionID := record.ExecutionID
+	artifactStore := rm.artifactStore
+	eventLog := rm.eventLogs[runID]
+	rm.mu.Unlock()
+
+	evidence := []Evidence{}
+	var artifactPath string
+	if artifactStore != nil {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err == nil {
+			var info *artifacts.ArtifactInfo
+			info, err = artifactStore.SaveArtifact(runID, artifacts.ArtifactTypeReport, ConformanceReportFilename, data)
+			if err == nil {
+				artifactPath = info.Path
+				evidence = append(evidence, Evidence{Kind: "artifact", Ref: info.Path, Note: stringPtr("Conformance report")})
+			}
+		}
+		if err != nil {
+			log.Printf("[RunManager] Failed to store conformance report of run %s: %v", runID, err)
+		}
+	}
+
+	payload, _ := json.Marshal(map[string]interface{}{
+		"worker_id":        workerID,
+		"protocol_version": report.ProtocolVersion,
+		"passed":           report.Passed,
+		"failed":           report.Failed,
+		"skipped":          report.Skipped,
+		"artifact_path":    artifactPath,
+		"report":           report,
+	})
+	appendEventWithLog(eventLog, RunEvent{
+		RunID:       runID,
+		ExecutionID: executionID,
+		Type:        EventTypeConformanceChecked,
+		Actor:       ActorWorker,
+		Payload:     payload,
+		Evidence:    evidence,
+	}, "RecordConformanceReport")
+
+	if err := rm.requestStopWithReason(runID, StopModeDrain, string(ActorSystem), "completed", nil); err != nil {
+		log.Printf("[RunManager] Failed to stop run %s after its conformance checks: %v", runID, err)
+	}
+	return true, nil
+}
+
+// conformanceFromEvent recovers the report carried by a CONFORMANCE_CHECKED
+// event.
+func conformanceFromEvent(event RunEvent) *analysis.ConformanceReport {
+	var payload struct {
+		Report *analysis.ConformanceReport `json:"report"`
+	}
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return nil
+	}
+	return payload.Report
+}
@@ -0,0 +1,110 @@
+package runmanager
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+	"github.com/bc-dunia/mcpdrill/internal/artifacts"
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/scheduler"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+func TestRecordConformanceReport_StoresReportAndStopsRun(t *testing.T) {
+	rm, runID := startTestRunInPreflight(t)
+
+	report := &analysis.ConformanceReport{Target: "http://127.0.0.1:3000/mcp", ProtocolVersion: "2025-06-18"}
+	report.Add(analysis.ConformanceCheck{ID: "ping.result", Category: "ping", Status: analysis.ConformancePass})
+	report.Add(analysis.ConformanceCheck{ID: "errors.unknown_method", Category: "errors", Status: analysis.ConformanceFail, Detail: "got -32600"})
+	recorded, err := rm.RecordConformanceReport(runID, "worker-1", report)
+	if err != nil || !recorded {
+		t.Fatalf("expected the report recorded, got %v, %v", recorded, err)
+	}
+	if recorded, _ := rm.RecordConformanceReport(runID, "worker-2", report); recorded {
+		t.Error("expected a second report of the run to be ignored")
+	}
+
+	data, err := rm.artifactStore.GetArtifact(runID, artifacts.ArtifactTypeReport, ConformanceReportFilename)
+	if err != nil {
+		t.Fatalf("expected the conformance report artifact: %v", err)
+	}
+	var stored analysis.ConformanceReport
+	if err := json.Unmarshal(data, &stored); err != nil || stored.Passed != 1 || stored.Failed != 1 || len(stored.Checks) != 2 {
+		t.Errorf("unexpected stored report %s (%v)", data, err)
+	}
+
+	checked := 0
+	for _, event := range rm.eventLogs[runID].GetAll() {
+		if event.Type != EventTypeConformanceChecked {
+			continue
+		}
+		checked++
+		if restored := conformanceFromEvent(event); restored == nil || restored.Failed != 1 {
+			t.Errorf("expected the event to carry the report, got %s", event.Payload)
+		}
+	}
+	if checked != 1 {
+		t.Errorf("expected one CONFORMANCE_CHECKED event, got %d", checked)
+	}
+
+	view, _ := rm.GetRun(runID)
+	if view.State != RunStateStopping || view.StopReason == nil || view.StopReason.Reason != "completed" {
+		t.Errorf("expected the run stopping once checked, got %s (%+v)", view.State, view.StopReason)
+	}
+	if view.Conformance == nil || view.Conformance.Failed != 1 {
+		t.Errorf("expected the run to show its conformance report, got %+v", view.Conformance)
+	}
+}
+
+func TestConformanceRun_DispatchesChecksToOneWorker(t *testing.T) {
+	rm := NewRunManager(createTestValidatorForWorkerTest(t))
+	registry := scheduler.NewRegistry()
+	lm := scheduler.NewLeaseManager(60000)
+	rm.SetScheduler(registry, scheduler.NewAllocator(registry, lm), lm)
+	sender := &mockAssignmentSender{assignments: make(map[string][]types.WorkerAssignment)}
+	rm.SetAssignmentSender(sender)
+	for _, host := range []string{"host1", "host2"} {
+		if _, err := registry.RegisterWorker(types.HostInfo{Hostname: host}, types.WorkerCapacity{MaxVUs: 100}); err != nil {
+			t.Fatalf("RegisterWorker failed: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(getProjectRootForWorkerTest(), "testdata/fixtures/valid/minimal_preflight_baseline_ramp.json"))
+	if err != nil {
+		t.Fatalf("failed to read test fixture: %v", err)
+	}
+	var config map[string]interface{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatalf("failed to unmarshal test fixture: %v", err)
+	}
+	config["run_type"] = RunTypeConformance
+	config["conformance"] = map[string]interface{}{"tool": "slow", "cancel_after_ms": 50}
+	for _, s := range config["stages"].([]interface{})[1:] {
+		s.(map[string]interface{})["enabled"] = false
+	}
+	data, _ = json.Marshal(config)
+	runID, err := rm.CreateRun(data, "test")
+	if err != nil {
+		t.Fatalf("failed to create run: %v", err)
+	}
+	if err := rm.StartRun(runID, "test"); err != nil {
+		t.Fatalf("StartRun failed: %v", err)
+	}
+
+	var dispatched []types.WorkerAssignment
+	for _, assignments := range sender.assignments {
+		dispatched = append(dispatched, assignments...)
+	}
+	if len(dispatched) != 1 {
+		t.Fatalf("expected one assignment, got %d", len(dispatched))
+	}
+	a := dispatched[0]
+	if a.VUIDEnd-a.VUIDStart != 1 || a.Stage != string(StageNamePreflight) {
+		t.Errorf("expected one preflight VU, got %d in %s", a.VUIDEnd-a.VUIDStart, a.Stage)
+	}
+	if a.Conformance == nil || a.Conformance.Tool != "slow" || a.Conformance.CancelAfterMs != 50 {
+		t.Errorf("expected the conformance options in the assignment, got %+v", a.Conformance)
+	}
+}
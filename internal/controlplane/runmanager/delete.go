@@ -0,0 +1,63 @@
+package runmanager
+
+import (
+	"fmt"
+	"log"
+)
+
+// TelemetryDeleter is implemented by telemetry stores that can drop the
+// telemetry of a deleted run.
+type TelemetryDeleter interface {
+	DeleteRun(runID string)
+}
+
+// DeleteRun deletes an ended run with everything kept for it: its event log,
+// its stored state, events and telemetry summary, its telemetry and its
+// artifacts. Runs that have not ended must be stopped or aborted first.
+//
+// The run is gone once DeleteRun returns; the store rows are deleted after
+// the run's queued writes. A failure to delete the artifacts is logged, not
+// returned, so a later retention sweep can retry it.
+func (rm *RunManager) DeleteRun(runID, actor string) error {
+	rm.mu.Lock()
+	record, ok := rm.runs[runID]
+	if !ok {
+		rm.mu.Unlock()
+		return NewNotFoundError(runID)
+	}
+	if !isTerminalRunState(record.State) {
+		rm.mu.Unlock()
+		return &RunManagerError{
+			Kind:    ErrKindInvalidState,
+			RunID:   runID,
+			State:   record.State,
+			Message: fmt.Sprintf("cannot delete run in state %s: stop or abort it first", record.State),
+		}
+	}
+	if eventLog := rm.eventLogs[runID]; eventLog != nil {
+		eventLog.mu.Lock()
+		eventLog.onAppend = nil
+		eventLog.mu.Unlock()
+	}
+	rm.unindexConfigLocked(record)
+	delete(rm.runs, runID)
+	delete(rm.eventLogs, runID)
+	p := rm.persister
+	artifactStore := rm.artifactStore
+	telemetryStore := rm.telemetryStore
+	rm.mu.Unlock()
+
+	if p != nil {
+		p.enqueue(persistItem{runID: runID, delete: true})
+	}
+	if deleter, ok := telemetryStore.(TelemetryDeleter); ok {
+		deleter.DeleteRun(runID)
+	}
+	if artifactStore != nil {
+		if err := artifactStore.DeleteArtifacts(runID); err != nil {
+			log.Printf("[RunManager] Failed to delete artifacts of run %s: %v", runID, err)
+		}
+	}
+	log.Printf("[RunManager] Run %s deleted by %s", runID, actor)
+	return nil
+}
@@ -0,0 +1,66 @@
+package runmanager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/artifacts"
+)
+
+type deletingTelemetryStore struct {
+	mockTelemetryStore
+	deleted []string
+}
+
+func (d *deletingTelemetryStore) DeleteRun(runID string) {
+	d.deleted = append(d.deleted, runID)
+}
+
+func TestDeleteRun(t *testing.T) {
+	store := openTestRunStore(t)
+	rm := NewRunManager(createTestValidator(t))
+	rm.SetRunStore(store)
+	artifactStore, _ := artifacts.NewFilesystemStore(t.TempDir())
+	rm.SetArtifactStore(artifactStore)
+	telemetry := &deletingTelemetryStore{}
+	rm.SetTelemetryStore(telemetry)
+
+	runID, err := rm.CreateRun(createValidConfig(), "alice")
+	if err != nil {
+		t.Fatalf("CreateRun failed: %v", err)
+	}
+	if err := rm.DeleteRun(runID, "alice"); !IsInvalidState(err) {
+		t.Fatalf("expected an invalid state error for a run that has not ended, got %v", err)
+	}
+	if err := rm.AbortRun(runID, "alice", ""); err != nil {
+		t.Fatalf("AbortRun failed: %v", err)
+	}
+	if _, err := artifactStore.SaveArtifact(runID, artifacts.ArtifactTypeReport, "report.json", []byte("{}")); err != nil {
+		t.Fatalf("SaveArtifact failed: %v", err)
+	}
+
+	if err := rm.DeleteRun(runID, "alice"); err != nil {
+		t.Fatalf("DeleteRun failed: %v", err)
+	}
+	if _, err := rm.GetRun(runID); !IsNotFound(err) {
+		t.Errorf("expected the run to be gone, got %v", err)
+	}
+	if err := rm.DeleteRun(runID, "alice"); !IsNotFound(err) {
+		t.Errorf("expected a not found error for a deleted run, got %v", err)
+	}
+	if len(telemetry.deleted) != 1 || telemetry.deleted[0] != runID {
+		t.Errorf("expected the run's telemetry to be deleted, got %v", telemetry.deleted)
+	}
+	if list, _ := artifactStore.ListArtifacts(runID); len(list) != 0 {
+		t.Errorf("expected the run's artifacts to be deleted, got %v", list)
+	}
+
+	rm.Shutdown()
+	stored, err := store.ListRuns(context.Background())
+	if err != nil {
+		t.Fatalf("ListRuns failed: %v", err)
+	}
+	if len(stored) != 0 {
+		t.Errorf("expected the run to be deleted from the run store, got %d runs", len(stored))
+	}
+}
@@ -39,6 +39,7 @@ func (rm *RunManager) tryAllocateForStage(runID, executionID string, config []by
 	if parsedConfig.Safety.HardCaps.MaxVUs > 0 && targetVUs > parsedConfig.Safety.HardCaps.MaxVUs {
 		targetVUs = parsedConfig.Safety.HardCaps.MaxVUs
 	}
+	targetVUs = conformanceVUs(parsedConfig, targetVUs)
 
 	rm.mu.RLock()
 	registry := rm.registry
@@ -50,10 +51,11 @@ func (rm *RunManager) tryAllocateForStage(runID, executionID string, config []by
 		return false
 	}
 
-	workers := registry.ListWorkers()
+	workers := placementWorkers(registry, rm.GetRunProject(runID), parsedConfig.Placement)
 	if len(workers) == 0 {
 		log.Printf("[RunManager] No workers available for run %s", runID)
-		rm.emitAllocationFailedEvent(runID, executionID, eventLog, "no_workers", "no workers registered")
+		rm.emitAllocationFailedEvent(runID, executionID, eventLog, "no_workers", noWorkersDetails(parsedConfig))
+		rm.notifyCapacityShortfall(runID, executionID, stageName, parsedConfig, targetVUs, scheduler.ErrNoWorkersAvailable)
 		return false
 	}
 
@@ -62,10 +64,11 @@ func (rm *RunManager) tryAllocateForStage(runID, executionID string, config []by
 		workerIDs[i] = w.WorkerID
 	}
 
-	_, _, err = allocator.AllocateAssignments(runID, stage.StageID, targetVUs, workerIDs)
+	_, err = allocateGroups(allocator, runID, stage.StageID, parsedConfig.GeneratorGroups, parsedConfig.Targets, workerSpread(workers, parsedConfig.Placement), workerIDs, 0, targetVUs)
 	if err != nil {
 		log.Printf("[RunManager] Allocation failed for run %s: %v", runID, err)
 		rm.emitAllocationFailedEvent(runID, executionID, eventLog, "allocation_error", err.Error())
+		rm.notifyCapacityShortfall(runID, executionID, stageName, parsedConfig, targetVUs, err)
 		return false
 	}
 
@@ -102,6 +105,7 @@ func (rm *RunManager) createAndDispatchAssignmentsForStage(runID, executionID st
 		log.Printf("[RunManager] Capping target VUs from %d to hard cap %d for run %s", targetVUs, parsedConfig.Safety.HardCaps.MaxVUs, runID)
 		targetVUs = parsedConfig.Safety.HardCaps.MaxVUs
 	}
+	targetVUs = conformanceVUs(parsedConfig, targetVUs)
 
 	rm.mu.RLock()
 	registry := rm.registry
@@ -119,10 +123,11 @@ func (rm *RunManager) createAndDispatchAssignmentsForStage(runID, executionID st
 		log.Printf("[RunManager] Failed to revoke existing leases for run %s: %v", runID, err)
 	}
 
-	workers := registry.ListWorkers()
+	workers := placementWorkers(registry, rm.GetRunProject(runID), parsedConfig.Placement)
 	if len(workers) == 0 {
 		log.Printf("[RunManager] No workers available for run %s", runID)
-		rm.emitAllocationFailedEvent(runID, executionID, eventLog, "no_workers", "no workers registered")
+		rm.emitAllocationFailedEvent(runID, executionID, eventLog, "no_workers", noWorkersDetails(parsedConfig))
+		rm.notifyCapacityShortfall(runID, executionID, stageName, parsedConfig, targetVUs, scheduler.ErrNoWorkersAvailable)
 		return nil
 	}
 
@@ -133,16 +138,24 @@ func (rm *RunManager) createAndDispatchAssignmentsForStage(runID, executionID st
 
 	log.Printf("[RunManager] Allocating %d VUs across %d workers for run %s", targetVUs, len(workers), runID)
 
-	_, workerAssignmentsMap, err := allocator.AllocateAssignments(runID, stage.StageID, targetVUs, workerIDs)
+	dispatches, err := allocateGroups(allocator, runID, stage.StageID, parsedConfig.GeneratorGroups, parsedConfig.Targets, workerSpread(workers, parsedConfig.Placement), workerIDs, 0, targetVUs)
 	if err != nil {
 		log.Printf("[RunManager] Allocation failed for run %s: %v", runID, err)
 		rm.emitAllocationFailedEvent(runID, executionID, eventLog, "allocation_error", err.Error())
+		rm.notifyCapacityShortfall(runID, executionID, stageName, parsedConfig, targetVUs, err)
 		return nil
 	}
 
-	log.Printf("[RunManager] Created %d assignments for run %s", len(workerAssignmentsMap), runID)
+	log.Printf("[RunManager] Created %d assignments for run %s", len(dispatches), runID)
+
+	receivedOps := rm.receivedOperationCount(runID)
+	labels := workerLabels(workers)
+
+	for _, d := range dispatches {
+		workerID, assignment := d.workerID, d.assignment
+		workload, workloadRevision := rm.assignmentWorkload(runID, parsedConfig, d.group)
+		workload.Arrival = assignmentArrival(stage, assignment.VUIDRange.End-assignment.VUIDRange.Start, targetVUs)
 
-	for workerID, assignment := range workerAssignmentsMap {
 		leaseID, err := leaseManager.IssueLease(workerID, assignment)
 		if err != nil {
 			log.Printf("[RunManager] Failed to issue lease for worker %s: %v", workerID, err)
@@ -151,37 +164,33 @@ func (rm *RunManager) createAndDispatchAssignmentsForStage(runID, executionID st
 		}
 
 		workerAssignment := types.WorkerAssignment{
-			RunID:       assignment.RunID,
-			ExecutionID: executionID,
-			Stage:       string(stageName),
-			StageID:     assignment.StageID,
-			LeaseID:     string(leaseID),
-			VUIDStart:   assignment.VUIDRange.Start,
-			VUIDEnd:     assignment.VUIDRange.End,
-			DurationMs:  stage.DurationMs,
-			Target: types.TargetConfig{
-				URL:                   parsedConfig.Target.URL,
-				Transport:             parsedConfig.Target.Transport,
-				Headers:               buildTargetHeaders(runID, &parsedConfig.Target),
-				RedirectPolicy:        buildRedirectPolicy(parsedConfig.Target.RedirectPolicy),
-				Auth:                  buildAuthConfig(parsedConfig.Target.Auth),
-				ProtocolVersion:       parsedConfig.Target.ProtocolVersion,
-				ProtocolVersionPolicy: parsedConfig.Target.ProtocolVersionPolicy,
-			},
-			Workload: types.WorkloadConfig{
-				OpMix: convertOpMix(parsedConfig.Workload.OpMix),
-			},
-			SessionPolicy: types.SessionPolicyConfig{
-				Mode:      parsedConfig.SessionPolicy.Mode,
-				PoolSize:  parsedConfig.SessionPolicy.PoolSize,
-				TTLMs:     parsedConfig.SessionPolicy.TTLMs,
-				MaxIdleMs: parsedConfig.SessionPolicy.MaxIdleMs,
-			},
+			RunID:            assignment.RunID,
+			ExecutionID:      executionID,
+			Stage:            string(stageName),
+			StageID:          assignment.StageID,
+			LeaseID:          string(leaseID),
+			VUIDStart:        assignment.VUIDRange.Start,
+			VUIDEnd:          assignment.VUIDRange.End,
+			DurationMs:       stage.DurationMs,
+			WarmupMs:         stage.WarmupMs,
+			Chaos:            buildChaosConfig(stage),
+			Target:           buildTargetConfig(runID, parsedConfig, d.target),
+			Workload:         workload,
+			WorkloadRevision: workloadRevision,
+			GeneratorGroup:   d.groupName(),
+			TargetName:       d.targetName(),
+			Shadow:           buildShadowConfig(runID, parsedConfig),
+			Conformance:      buildConformanceConfig(parsedConfig),
+			SessionPolicy:    buildSessionPolicyConfig(parsedConfig.SessionPolicy),
+			MaxTotalOps: assignmentOpsBudget(parsedConfig.Safety.HardCaps.MaxTotalOps, receivedOps,
+				assignment.VUIDRange.End-assignment.VUIDRange.Start, targetVUs),
 		}
 
+		endDispatch := traceDispatch(eventLog, string(workerID), &workerAssignment)
 		assignmentSender.AddAssignment(string(workerID), workerAssignment)
+		endDispatch()
 
-		rm.emitWorkerAssignedEvent(runID, executionID, eventLog, string(workerID), string(leaseID), assignment.VUIDRange.Start, assignment.VUIDRange.End, stage.StageID, stageName)
+		rm.emitWorkerAssignedEvent(runID, executionID, eventLog, string(workerID), string(leaseID), assignment.VUIDRange.Start, assignment.VUIDRange.End, stage.StageID, stageName, d.groupName(), d.targetName(), labels[workerID])
 
 		log.Printf("[RunManager] Assigned VUs [%d, %d) to worker %s with lease %s", assignment.VUIDRange.Start, assignment.VUIDRange.End, workerID, leaseID)
 	}
@@ -207,14 +216,24 @@ func (rm *RunManager) emitAllocationFailedEvent(runID, executionID string, event
 	appendEventWithLog(eventLog, event, "emitAllocationFailedEvent")
 }
 
-func (rm *RunManager) emitWorkerAssignedEvent(runID, executionID string, eventLog *EventLog, workerID, leaseID string, vuStart, vuEnd int, stageID string, stageName StageName) {
-	payload, _ := json.Marshal(map[string]interface{}{
+func (rm *RunManager) emitWorkerAssignedEvent(runID, executionID string, eventLog *EventLog, workerID, leaseID string, vuStart, vuEnd int, stageID string, stageName StageName, generatorGroup, target string, workerLabels map[string]string) {
+	fields := map[string]interface{}{
 		"worker_id": workerID,
 		"lease_id":  leaseID,
 		"vu_start":  vuStart,
 		"vu_end":    vuEnd,
 		"stage_id":  stageID,
-	})
+	}
+	if generatorGroup != "" {
+		fields["generator_group"] = generatorGroup
+	}
+	if target != "" {
+		fields["target"] = target
+	}
+	if len(workerLabels) > 0 {
+		fields["worker_labels"] = workerLabels
+	}
+	payload, _ := json.Marshal(fields)
 
 	event := RunEvent{
 		RunID:       runID,
@@ -233,6 +252,39 @@ func (rm *RunManager) emitWorkerAssignedEvent(runID, executionID string, eventLo
 }
 
 func (rm *RunManager) dispatchRampAssignments(runID, executionID string, config []byte, eventLog *EventLog, stage *parsedStage, parsedConfig *parsedRunConfig, numVUs int, vuOffset int) {
+	// Budget shares are split across the stage's full target, not just this ramp step.
+	stageTotalVUs := stage.Load.TargetVUs
+	if parsedConfig.Safety.HardCaps.MaxVUs > 0 && stageTotalVUs > parsedConfig.Safety.HardCaps.MaxVUs {
+		stageTotalVUs = parsedConfig.Safety.HardCaps.MaxVUs
+	}
+
+	remainingDurationMs := stage.DurationMs
+	var elapsed int64
+	rm.mu.RLock()
+	if record, ok := rm.runs[runID]; ok && record.ActiveStage != nil {
+		elapsed = time.Now().UnixMilli() - record.UpdatedAtMs
+		remainingDurationMs = stage.DurationMs - elapsed
+		if remainingDurationMs < 1000 {
+			remainingDurationMs = 1000
+		}
+	}
+	rm.mu.RUnlock()
+
+	rm.dispatchVURange(runID, executionID, eventLog, stage, StageNameRamp, parsedConfig, vuOffset, numVUs, remainingDurationMs, remainingWarmup(stage, elapsed), stageTotalVUs)
+}
+
+// remainingWarmup returns what is left of the stage's warmup elapsedMs into
+// the stage.
+func remainingWarmup(stage *parsedStage, elapsedMs int64) int64 {
+	return max(stage.WarmupMs-elapsedMs, 0)
+}
+
+// dispatchVURange allocates VUs [vuOffset, vuOffset+numVUs) across the
+// registered workers and sends each worker an assignment lasting durationMs,
+// the first warmupMs of which are warmup.
+// budgetVUs is the VU count the run's remaining op budget is shared across.
+// It returns the number of assignments sent.
+func (rm *RunManager) dispatchVURange(runID, executionID string, eventLog *EventLog, stage *parsedStage, stageName StageName, parsedConfig *parsedRunConfig, vuOffset, numVUs int, durationMs, warmupMs int64, budgetVUs int) int {
 	rm.mu.RLock()
 	registry := rm.registry
 	allocator := rm.allocator
@@ -241,13 +293,14 @@ func (rm *RunManager) dispatchRampAssignments(runID, executionID string, config
 	rm.mu.RUnlock()
 
 	if registry == nil || allocator == nil || leaseManager == nil || assignmentSender == nil {
-		return
+		return 0
 	}
 
-	workers := registry.ListWorkers()
+	workers := placementWorkers(registry, rm.GetRunProject(runID), parsedConfig.Placement)
 	if len(workers) == 0 {
-		log.Printf("[RunManager] No workers available for ramp assignments")
-		return
+		log.Printf("[RunManager] No workers available for %s assignments", stageName)
+		rm.notifyCapacityShortfall(runID, executionID, stageName, parsedConfig, vuOffset+numVUs, scheduler.ErrNoWorkersAvailable)
+		return 0
 	}
 
 	workerIDs := make([]scheduler.WorkerID, len(workers))
@@ -255,32 +308,21 @@ func (rm *RunManager) dispatchRampAssignments(runID, executionID string, config
 		workerIDs[i] = w.WorkerID
 	}
 
-	_, workerAssignmentsMap, err := allocator.AllocateAssignments(runID, stage.StageID, numVUs, workerIDs)
+	dispatches, err := allocateGroups(allocator, runID, stage.StageID, parsedConfig.GeneratorGroups, parsedConfig.Targets, workerSpread(workers, parsedConfig.Placement), workerIDs, vuOffset, numVUs)
 	if err != nil {
-		log.Printf("[RunManager] Ramp allocation failed: %v", err)
-		return
+		log.Printf("[RunManager] %s allocation failed: %v", stageName, err)
+		rm.notifyCapacityShortfall(runID, executionID, stageName, parsedConfig, vuOffset+numVUs, err)
+		return 0
 	}
 
-	remainingDurationMs := stage.DurationMs
-	rm.mu.RLock()
-	if record, ok := rm.runs[runID]; ok && record.ActiveStage != nil {
-		elapsed := time.Now().UnixMilli() - record.UpdatedAtMs
-		remainingDurationMs = stage.DurationMs - elapsed
-		if remainingDurationMs < 1000 {
-			remainingDurationMs = 1000
-		}
-	}
-	rm.mu.RUnlock()
+	receivedOps := rm.receivedOperationCount(runID)
+	labels := workerLabels(workers)
 
-	for workerID, assignment := range workerAssignmentsMap {
-		offsetAssignment := scheduler.Assignment{
-			RunID:   assignment.RunID,
-			StageID: assignment.StageID,
-			VUIDRange: scheduler.VUIDRange{
-				Start: assignment.VUIDRange.Start + vuOffset,
-				End:   assignment.VUIDRange.End + vuOffset,
-			},
-		}
+	sent := 0
+	for _, d := range dispatches {
+		workerID, offsetAssignment := d.workerID, d.assignment
+		workload, workloadRevision := rm.assignmentWorkload(runID, parsedConfig, d.group)
+		workload.Arrival = assignmentArrival(stage, offsetAssignment.VUIDRange.End-offsetAssignment.VUIDRange.Start, budgetVUs)
 
 		leaseID, err := leaseManager.IssueLease(workerID, offsetAssignment)
 		if err != nil {
@@ -290,39 +332,36 @@ func (rm *RunManager) dispatchRampAssignments(runID, executionID string, config
 		}
 
 		workerAssignment := types.WorkerAssignment{
-			RunID:       offsetAssignment.RunID,
-			ExecutionID: executionID,
-			Stage:       string(StageNameRamp),
-			StageID:     offsetAssignment.StageID,
-			LeaseID:     string(leaseID),
-			VUIDStart:   offsetAssignment.VUIDRange.Start,
-			VUIDEnd:     offsetAssignment.VUIDRange.End,
-			DurationMs:  remainingDurationMs,
-			Target: types.TargetConfig{
-				URL:                   parsedConfig.Target.URL,
-				Transport:             parsedConfig.Target.Transport,
-				Headers:               buildTargetHeaders(runID, &parsedConfig.Target),
-				RedirectPolicy:        buildRedirectPolicy(parsedConfig.Target.RedirectPolicy),
-				Auth:                  buildAuthConfig(parsedConfig.Target.Auth),
-				ProtocolVersion:       parsedConfig.Target.ProtocolVersion,
-				ProtocolVersionPolicy: parsedConfig.Target.ProtocolVersionPolicy,
-			},
-			Workload: types.WorkloadConfig{
-				OpMix: convertOpMix(parsedConfig.Workload.OpMix),
-			},
-			SessionPolicy: types.SessionPolicyConfig{
-				Mode:      parsedConfig.SessionPolicy.Mode,
-				PoolSize:  parsedConfig.SessionPolicy.PoolSize,
-				TTLMs:     parsedConfig.SessionPolicy.TTLMs,
-				MaxIdleMs: parsedConfig.SessionPolicy.MaxIdleMs,
-			},
+			RunID:            offsetAssignment.RunID,
+			ExecutionID:      executionID,
+			Stage:            string(stageName),
+			StageID:          offsetAssignment.StageID,
+			LeaseID:          string(leaseID),
+			VUIDStart:        offsetAssignment.VUIDRange.Start,
+			VUIDEnd:          offsetAssignment.VUIDRange.End,
+			DurationMs:       durationMs,
+			WarmupMs:         warmupMs,
+			Chaos:            buildChaosConfig(stage),
+			Target:           buildTargetConfig(runID, parsedConfig, d.target),
+			Workload:         workload,
+			WorkloadRevision: workloadRevision,
+			GeneratorGroup:   d.groupName(),
+			TargetName:       d.targetName(),
+			Shadow:           buildShadowConfig(runID, parsedConfig),
+			SessionPolicy:    buildSessionPolicyConfig(parsedConfig.SessionPolicy),
+			MaxTotalOps: assignmentOpsBudget(parsedConfig.Safety.HardCaps.MaxTotalOps, receivedOps,
+				offsetAssignment.VUIDRange.End-offsetAssignment.VUIDRange.Start, budgetVUs),
 		}
 
+		endDispatch := traceDispatch(eventLog, string(workerID), &workerAssignment)
 		assignmentSender.AddAssignment(string(workerID), workerAssignment)
+		endDispatch()
 
 		rm.emitWorkerAssignedEvent(runID, executionID, eventLog, string(workerID), string(leaseID),
-			offsetAssignment.VUIDRange.Start, offsetAssignment.VUIDRange.End, stage.StageID, StageNameRamp)
+			offsetAssignment.VUIDRange.Start, offsetAssignment.VUIDRange.End, stage.StageID, stageName, d.groupName(), d.targetName(), labels[workerID])
+		sent++
 	}
+	return sent
 }
 
 func (rm *RunManager) emitRampStepEvent(runID, executionID string, eventLog *EventLog, step, currentVUs, targetVUs int, trigger string) {
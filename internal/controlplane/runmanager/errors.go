@@ -24,6 +24,7 @@ const (
 	ErrKindInvalidTransition
 	ErrKindConfigNotAvailable
 	ErrKindInternal
+	ErrKindInvalidArgument
 )
 
 func (e *RunManagerError) Error() string {
@@ -85,6 +86,16 @@ func NewConfigNotAvailableError(runID string) *RunManagerError {
 	}
 }
 
+// NewInvalidArgumentError creates an error for a request rejected by validation.
+func NewInvalidArgumentError(runID string, cause error) *RunManagerError {
+	return &RunManagerError{
+		Kind:    ErrKindInvalidArgument,
+		RunID:   runID,
+		Message: cause.Error(),
+		Cause:   cause,
+	}
+}
+
 // NewInternalError wraps an internal error.
 func NewInternalError(runID string, cause error) *RunManagerError {
 	return &RunManagerError{
@@ -122,3 +133,9 @@ func IsInvalidState(err error) bool {
 	rmErr := AsRunManagerError(err)
 	return rmErr != nil && (rmErr.Kind == ErrKindInvalidState || rmErr.Kind == ErrKindInvalidTransition)
 }
+
+// IsInvalidArgument checks if the error is an invalid-argument error.
+func IsInvalidArgument(err error) bool {
+	rmErr := AsRunManagerError(err)
+	return rmErr != nil && rmErr.Kind == ErrKindInvalidArgument
+}
@@ -21,6 +21,10 @@ const (
 	EventTypeStageStarted             EventType = "STAGE_STARTED"
 	EventTypeStageCompleted           EventType = "STAGE_COMPLETED"
 	EventTypeStageFailed              EventType = "STAGE_FAILED"
+	EventTypeStageCheckpoint          EventType = "STAGE_CHECKPOINT"
+	EventTypeStageStep                EventType = "STAGE_STEP"
+	EventTypeTargetProfiled           EventType = "TARGET_PROFILED"
+	EventTypeConformanceChecked       EventType = "CONFORMANCE_CHECKED"
 	EventTypeSchedulerTargetSet       EventType = "SCHEDULER_TARGET_SET"
 	EventTypeWorkerAssigned           EventType = "WORKER_ASSIGNED"
 	EventTypeWorkerAssignmentRejected EventType = "WORKER_ASSIGNMENT_REJECTED"
@@ -28,8 +32,15 @@ const (
 	EventTypeWorkerHeartbeat          EventType = "WORKER_HEARTBEAT"
 	EventTypeWorkerCapacityLost       EventType = "WORKER_CAPACITY_LOST"
 	EventTypeWorkerReplaced           EventType = "WORKER_REPLACED"
+	EventTypeLeaseReassigned          EventType = "LEASE_REASSIGNED"
+	EventTypeWorkerProvisioned        EventType = "WORKER_PROVISIONED"
+	EventTypeWorkerReleased           EventType = "WORKER_RELEASED"
 	EventTypeStopRequested            EventType = "STOP_REQUESTED"
 	EventTypeEmergencyStop            EventType = "EMERGENCY_STOP"
+	EventTypeRunAborted               EventType = "RUN_ABORTED"
+	EventTypeRunPaused                EventType = "RUN_PAUSED"
+	EventTypeRunResumed               EventType = "RUN_RESUMED"
+	EventTypeRunTagsUpdated           EventType = "RUN_TAGS_UPDATED"
 	EventTypeStopConditionTriggered   EventType = "STOP_CONDITION_TRIGGERED"
 	EventTypeStageTimeout             EventType = "STAGE_TIMEOUT"
 	EventTypeDecision                 EventType = "DECISION"
@@ -37,10 +48,57 @@ const (
 	EventTypeAnalysisCompleted        EventType = "ANALYSIS_COMPLETED"
 	EventTypeReportGenerated          EventType = "REPORT_GENERATED"
 	EventTypeArtifactStored           EventType = "ARTIFACT_STORED"
+	EventTypeSecretAccessed           EventType = "SECRET_ACCESSED"
 	EventTypeSystemRecovery           EventType = "SYSTEM_RECOVERY"
 	EventTypeSystemWarning            EventType = "SYSTEM_WARNING"
 )
 
+// knownEventTypes is the set of event types the run manager emits.
+var knownEventTypes = map[EventType]bool{
+	EventTypeRunCreated:               true,
+	EventTypeValidationCompleted:      true,
+	EventTypeStateTransition:          true,
+	EventTypeAllocationFailed:         true,
+	EventTypeStageStarted:             true,
+	EventTypeStageCompleted:           true,
+	EventTypeStageFailed:              true,
+	EventTypeStageCheckpoint:          true,
+	EventTypeStageStep:                true,
+	EventTypeTargetProfiled:           true,
+	EventTypeConformanceChecked:       true,
+	EventTypeSchedulerTargetSet:       true,
+	EventTypeWorkerAssigned:           true,
+	EventTypeWorkerAssignmentRejected: true,
+	EventTypeWorkerRegistered:         true,
+	EventTypeWorkerHeartbeat:          true,
+	EventTypeWorkerCapacityLost:       true,
+	EventTypeWorkerReplaced:           true,
+	EventTypeLeaseReassigned:          true,
+	EventTypeWorkerProvisioned:        true,
+	EventTypeWorkerReleased:           true,
+	EventTypeStopRequested:            true,
+	EventTypeEmergencyStop:            true,
+	EventTypeRunAborted:               true,
+	EventTypeRunPaused:                true,
+	EventTypeRunResumed:               true,
+	EventTypeRunTagsUpdated:           true,
+	EventTypeStopConditionTriggered:   true,
+	EventTypeStageTimeout:             true,
+	EventTypeDecision:                 true,
+	EventTypeAnalysisStarted:          true,
+	EventTypeAnalysisCompleted:        true,
+	EventTypeReportGenerated:          true,
+	EventTypeArtifactStored:           true,
+	EventTypeSecretAccessed:           true,
+	EventTypeSystemRecovery:           true,
+	EventTypeSystemWarning:            true,
+}
+
+// IsKnownEventType reports whether t is an event type the run manager emits.
+func IsKnownEventType(t EventType) bool {
+	return knownEventTypes[t]
+}
+
 // ActorType represents who triggered the event.
 type ActorType string
 
@@ -62,6 +120,7 @@ const (
 	StageNameRamp      StageName = "ramp"
 	StageNameSoak      StageName = "soak"
 	StageNameSpike     StageName = "spike"
+	StageNameStep      StageName = "step"
 	StageNameCustom    StageName = "custom"
 )
 
@@ -106,6 +165,14 @@ type EventLog struct {
 	maxEvents int
 	truncated bool
 	runID     string // For logging purposes
+
+	// onAppend, if set, is called with each stored event and its index while
+	// the log is locked. It must not block or call back into the log.
+	onAppend func(seq int, event RunEvent)
+
+	// trace, if set, turns state transitions into spans. It is set before
+	// the log is shared and never changed.
+	trace *runTrace
 }
 
 // NewEventLog creates a new append-only event log with default limits.
@@ -167,6 +234,11 @@ func (el *EventLog) Append(event RunEvent) error {
 		el.runID = event.RunID
 	}
 
+	// Tracing does not depend on the log's capacity.
+	if el.trace != nil {
+		el.trace.observe(event)
+	}
+
 	// Check memory limit
 	if el.maxEvents > 0 && len(el.events) >= el.maxEvents {
 		if !el.truncated {
@@ -180,6 +252,9 @@ func (el *EventLog) Append(event RunEvent) error {
 	}
 
 	el.events = append(el.events, event)
+	if el.onAppend != nil {
+		el.onAppend(len(el.events)-1, event)
+	}
 	return nil
 }
 
@@ -832,3 +832,12 @@ func TestEventLog_DefaultLimit(t *testing.T) {
 		t.Errorf("Expected default limit of 10000, got %d", DefaultMaxEventsPerLog)
 	}
 }
+
+func TestIsKnownEventType(t *testing.T) {
+	if !IsKnownEventType(EventTypeStateTransition) || !IsKnownEventType(EventTypeDecision) {
+		t.Error("expected emitted event types to be known")
+	}
+	if IsKnownEventType("state_transition") || IsKnownEventType("NOT_A_TYPE") {
+		t.Error("expected unknown or lowercase names to be rejected")
+	}
+}
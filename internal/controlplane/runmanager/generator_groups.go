@@ -0,0 +1,231 @@
+package runmanager
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/scheduler"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+// groupDispatch is one worker's share of a stage dispatch.
+type groupDispatch struct {
+	group      *parsedGeneratorGroup // nil when the run defines no generator groups
+	target     *parsedTargetEntry    // nil when the run has a single target
+	workerID   scheduler.WorkerID
+	assignment scheduler.Assignment
+}
+
+// groupName returns the generator group of the dispatch, or "" without groups.
+func (d groupDispatch) groupName() string {
+	if d.group == nil {
+		return ""
+	}
+	return d.group.Name
+}
+
+// targetName returns the target of the dispatch, or "" for a single target.
+func (d groupDispatch) targetName() string {
+	if d.target == nil {
+		return ""
+	}
+	return d.target.Name
+}
+
+// allocateGroups allocates the VU range [vuOffset, vuOffset+numVUs) of a stage.
+// Without generator groups the range is allocated across all workers. With
+// groups, workers are partitioned so that every group runs on its own workers,
+// and the VUs are split across groups by weight. The split is computed on the
+// cumulative VU count, so ramp steps converge on the same shares as a single
+// dispatch of the full target. Each group's VUs are then split across the
+// run's targets, see allocateTargets, and spread across worker label values
+// when spread is set, see allocateSpread.
+func allocateGroups(allocator *scheduler.Allocator, runID, stageID string, groups []parsedGeneratorGroup, targets []parsedTargetEntry, spread map[scheduler.WorkerID]string, workerIDs []scheduler.WorkerID, vuOffset, numVUs int) ([]groupDispatch, error) {
+	if len(groups) == 0 {
+		return allocateTargets(allocator, runID, stageID, targets, spread, workerIDs, vuOffset, numVUs, vuOffset)
+	}
+
+	if len(workerIDs) < len(groups) {
+		return nil, fmt.Errorf("%d generator groups need at least %d workers, %d available", len(groups), len(groups), len(workerIDs))
+	}
+
+	weights := make([]int, len(groups))
+	for i, g := range groups {
+		weights[i] = g.Weight
+	}
+
+	sorted := make([]scheduler.WorkerID, len(workerIDs))
+	copy(sorted, workerIDs)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	sorted = interleaveSpread(spread, sorted)
+	workerCounts := apportion(len(sorted), weights, 1)
+
+	before := apportion(vuOffset, weights, 0)
+	after := apportion(vuOffset+numVUs, weights, 0)
+
+	var dispatches []groupDispatch
+	workerStart := 0
+	vuCursor := vuOffset
+	for i := range groups {
+		group := &groups[i]
+		groupWorkers := sorted[workerStart : workerStart+workerCounts[i]]
+		workerStart += workerCounts[i]
+
+		groupVUs := after[i] - before[i]
+		if groupVUs <= 0 {
+			continue
+		}
+
+		groupDispatches, err := allocateTargets(allocator, runID, stageID, targets, spread, groupWorkers, before[i], groupVUs, vuCursor)
+		if err != nil {
+			return nil, fmt.Errorf("generator group %s: %w", group.Name, err)
+		}
+		for _, d := range groupDispatches {
+			d.group = group
+			d.assignment.Group = group.Name
+			dispatches = append(dispatches, d)
+		}
+		vuCursor += groupVUs
+	}
+
+	return dispatches, nil
+}
+
+// allocateTargets allocates numVUs VUs across workerIDs, numbering them from
+// firstVUID. With several targets the VUs are split across them by weight,
+// and every target's share is spread over all the workers, so a worker runs
+// one assignment per target. offset is the number of VUs of the same pool
+// allocated before; like groups, the split is computed on the cumulative
+// count.
+func allocateTargets(allocator *scheduler.Allocator, runID, stageID string, targets []parsedTargetEntry, spread map[scheduler.WorkerID]string, workerIDs []scheduler.WorkerID, offset, numVUs, firstVUID int) ([]groupDispatch, error) {
+	if len(targets) == 0 {
+		workerAssignments, err := allocateSpread(allocator, runID, stageID, spread, workerIDs, offset, numVUs)
+		if err != nil {
+			return nil, err
+		}
+		dispatches := make([]groupDispatch, 0, len(workerAssignments))
+		for workerID, assignment := range workerAssignments {
+			assignment.VUIDRange.Start += firstVUID
+			assignment.VUIDRange.End += firstVUID
+			dispatches = append(dispatches, groupDispatch{workerID: workerID, assignment: assignment})
+		}
+		return dispatches, nil
+	}
+
+	weights := make([]int, len(targets))
+	for i, t := range targets {
+		weights[i] = t.Weight
+	}
+	before := apportion(offset, weights, 0)
+	after := apportion(offset+numVUs, weights, 0)
+
+	var dispatches []groupDispatch
+	vuCursor := firstVUID
+	for i := range targets {
+		target := &targets[i]
+		targetVUs := after[i] - before[i]
+		if targetVUs <= 0 {
+			continue
+		}
+
+		workerAssignments, err := allocateSpread(allocator, runID, stageID, spread, workerIDs, before[i], targetVUs)
+		if err != nil {
+			return nil, fmt.Errorf("target %s: %w", target.Name, err)
+		}
+		for workerID, assignment := range workerAssignments {
+			assignment.VUIDRange.Start += vuCursor
+			assignment.VUIDRange.End += vuCursor
+			assignment.Target = target.Name
+			dispatches = append(dispatches, groupDispatch{target: target, workerID: workerID, assignment: assignment})
+		}
+		vuCursor += targetVUs
+	}
+
+	return dispatches, nil
+}
+
+// apportion distributes total units across weights using the Sainte-Laguë
+// divisor method, after giving every entry the minimum. Divisor methods never
+// take units away from an entry when the total grows, which keeps group shares
+// stable across ramp steps. Ties go to the earlier entry.
+func apportion(total int, weights []int, minimum int) []int {
+	shares := make([]int, len(weights))
+	remaining := total
+	for i := range shares {
+		if remaining < minimum {
+			break
+		}
+		shares[i] = minimum
+		remaining -= minimum
+	}
+
+	for ; remaining > 0; remaining-- {
+		best := 0
+		bestPriority := -1.0
+		for i, weight := range weights {
+			priority := float64(weight) / float64(2*shares[i]+1)
+			if priority > bestPriority {
+				best = i
+				bestPriority = priority
+			}
+		}
+		shares[best]++
+	}
+
+	return shares
+}
+
+// ownOpMixGroups returns the names of the generator groups that define their
+// own operation mix and therefore do not follow live op mix updates.
+func ownOpMixGroups(config *parsedRunConfig) map[string]bool {
+	groups := make(map[string]bool)
+	for _, g := range config.GeneratorGroups {
+		if len(g.OperationMix) > 0 {
+			groups[g.Name] = true
+		}
+	}
+	return groups
+}
+
+// assignmentWorkload returns the workload and workload revision for a new
+// assignment of the given generator group.
+func (rm *RunManager) assignmentWorkload(runID string, parsedConfig *parsedRunConfig, group *parsedGeneratorGroup) (types.WorkloadConfig, int64) {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	return rm.assignmentWorkloadLocked(runID, parsedConfig, group)
+}
+
+// assignmentWorkloadLocked is assignmentWorkload for callers holding rm.mu.
+func (rm *RunManager) assignmentWorkloadLocked(runID string, parsedConfig *parsedRunConfig, group *parsedGeneratorGroup) (types.WorkloadConfig, int64) {
+	workload := types.WorkloadConfig{
+		ResultCapture:     buildResultCaptureConfig(parsedConfig.Workload.ResultCapture),
+		RequestIDs:        buildRequestIDConfig(parsedConfig.Workload.RequestIDs),
+		DataFeeds:         buildDataFeedConfigs(parsedConfig.Workload.DataFeeds),
+		AutoDiscoverTools: buildAutoDiscoverConfig(parsedConfig.Workload.Tools),
+		Fuzz:              buildFuzzConfig(parsedConfig.Workload.Fuzz),
+		Cancellation:      buildCancellationConfig(parsedConfig.Workload),
+		Throttle:          buildThrottleConfig(parsedConfig.Workload.Throttle),
+		Retry:             buildRetryConfig(parsedConfig.Workload.Retry),
+		ThinkTime:         buildThinkTimeConfig(parsedConfig.Workload.ThinkTime),
+		ToolThinkTime:     buildToolThinkTimeConfig(parsedConfig.Workload.ToolThinkTime),
+		Workflows:         buildWorkflowConfigs(parsedConfig.Workload.Workflows),
+	}
+
+	var revision int64
+	if group != nil && len(group.OperationMix) > 0 {
+		workload.OpMix = convertOpMix(group.OperationMix)
+	} else {
+		workload.OpMix, revision = rm.assignmentOpMixLocked(runID, parsedConfig)
+	}
+	if group != nil {
+		applyGroupLoad(&workload, group)
+	}
+	return workload, revision
+}
+
+func applyGroupLoad(workload *types.WorkloadConfig, group *parsedGeneratorGroup) {
+	workload.InFlightPerVU = group.InFlightPerVU
+	if group.ThinkTime != nil {
+		workload.ThinkTime = buildThinkTimeConfig(group.ThinkTime)
+	}
+}
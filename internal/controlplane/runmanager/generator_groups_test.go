@@ -0,0 +1,374 @@
+package runmanager
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/scheduler"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+func newGroupTestAllocator(t *testing.T, workers, maxVUs int) (*scheduler.Allocator, []scheduler.WorkerID) {
+	t.Helper()
+	registry := scheduler.NewRegistry()
+	allocator := scheduler.NewAllocator(registry, scheduler.NewLeaseManager(60000))
+
+	ids := make([]scheduler.WorkerID, 0, workers)
+	for i := 0; i < workers; i++ {
+		wid, err := registry.RegisterWorker(types.HostInfo{Hostname: "host"}, types.WorkerCapacity{MaxVUs: maxVUs})
+		if err != nil {
+			t.Fatalf("RegisterWorker failed: %v", err)
+		}
+		ids = append(ids, wid)
+	}
+	return allocator, ids
+}
+
+func groupVUs(dispatches []groupDispatch) map[string]int {
+	vus := make(map[string]int)
+	for _, d := range dispatches {
+		vus[d.groupName()] += d.assignment.VUIDRange.End - d.assignment.VUIDRange.Start
+	}
+	return vus
+}
+
+func TestApportion(t *testing.T) {
+	if got := apportion(4, []int{3, 1}, 0); got[0] != 3 || got[1] != 1 {
+		t.Errorf("expected [3 1], got %v", got)
+	}
+	if got := apportion(3, []int{1, 1, 1, 100}, 1); got[0] != 1 || got[1] != 1 || got[2] != 1 || got[3] != 0 {
+		t.Errorf("expected minimum to run out after 3 entries, got %v", got)
+	}
+	if got := apportion(5, []int{1, 100}, 1); got[0] != 1 || got[1] != 4 {
+		t.Errorf("expected every entry to keep its minimum, got %v", got)
+	}
+
+	prev := apportion(0, []int{5, 3, 1}, 0)
+	for total := 1; total <= 50; total++ {
+		shares := apportion(total, []int{5, 3, 1}, 0)
+		sum := 0
+		for i, share := range shares {
+			if share < prev[i] {
+				t.Fatalf("share %d decreased from %d to %d at total %d", i, prev[i], share, total)
+			}
+			sum += share
+		}
+		if sum != total {
+			t.Fatalf("expected shares to sum to %d, got %v", total, shares)
+		}
+		prev = shares
+	}
+}
+
+func TestAllocateGroups_PartitionsWorkers(t *testing.T) {
+	allocator, workerIDs := newGroupTestAllocator(t, 4, 100)
+	groups := []parsedGeneratorGroup{
+		{Name: "steady", Weight: 3},
+		{Name: "bursty", Weight: 1, InFlightPerVU: 8},
+	}
+
+	dispatches, err := allocateGroups(allocator, "run_1", "stg_1", groups, nil, nil, workerIDs, 0, 40)
+	if err != nil {
+		t.Fatalf("allocateGroups failed: %v", err)
+	}
+
+	workersByGroup := make(map[string]map[scheduler.WorkerID]bool)
+	for _, d := range dispatches {
+		if d.assignment.Group != d.groupName() {
+			t.Errorf("expected assignment group %q, got %q", d.groupName(), d.assignment.Group)
+		}
+		if workersByGroup[d.groupName()] == nil {
+			workersByGroup[d.groupName()] = make(map[scheduler.WorkerID]bool)
+		}
+		workersByGroup[d.groupName()][d.workerID] = true
+	}
+	for wid := range workersByGroup["bursty"] {
+		if workersByGroup["steady"][wid] {
+			t.Errorf("worker %s assigned to both groups", wid)
+		}
+	}
+
+	vus := groupVUs(dispatches)
+	if vus["steady"] != 30 || vus["bursty"] != 10 {
+		t.Errorf("expected 30 steady and 10 bursty VUs, got %v", vus)
+	}
+
+	sort.Slice(dispatches, func(i, j int) bool {
+		return dispatches[i].assignment.VUIDRange.Start < dispatches[j].assignment.VUIDRange.Start
+	})
+	next := 0
+	for _, d := range dispatches {
+		if d.assignment.VUIDRange.Start != next {
+			t.Fatalf("expected range starting at %d, got %+v", next, d.assignment.VUIDRange)
+		}
+		next = d.assignment.VUIDRange.End
+	}
+	if next != 40 {
+		t.Errorf("expected ranges to cover 40 VUs, got %d", next)
+	}
+}
+
+func TestAllocateGroups_RampStepsConverge(t *testing.T) {
+	allocator, workerIDs := newGroupTestAllocator(t, 3, 100)
+	groups := []parsedGeneratorGroup{
+		{Name: "steady", Weight: 2},
+		{Name: "bursty", Weight: 1},
+	}
+
+	totals := make(map[string]int)
+	offset := 0
+	for _, step := range []int{1, 4, 7, 13} {
+		dispatches, err := allocateGroups(allocator, "run_1", "stg_1", groups, nil, nil, workerIDs, offset, step)
+		if err != nil {
+			t.Fatalf("allocateGroups failed at offset %d: %v", offset, err)
+		}
+		for _, d := range dispatches {
+			if d.assignment.VUIDRange.Start < offset || d.assignment.VUIDRange.End > offset+step {
+				t.Errorf("range %+v outside step [%d, %d)", d.assignment.VUIDRange, offset, offset+step)
+			}
+		}
+		for name, n := range groupVUs(dispatches) {
+			totals[name] += n
+		}
+		offset += step
+	}
+
+	want := apportion(offset, []int{2, 1}, 0)
+	if totals["steady"] != want[0] || totals["bursty"] != want[1] {
+		t.Errorf("expected ramp totals %v, got %v", want, totals)
+	}
+}
+
+func TestAllocateGroups_Errors(t *testing.T) {
+	allocator, workerIDs := newGroupTestAllocator(t, 1, 100)
+	groups := []parsedGeneratorGroup{
+		{Name: "steady", Weight: 1},
+		{Name: "bursty", Weight: 1},
+	}
+
+	if _, err := allocateGroups(allocator, "run_1", "stg_1", groups, nil, nil, workerIDs, 0, 10); err == nil {
+		t.Error("expected error with fewer workers than groups")
+	}
+}
+
+func TestAllocateGroups_NoGroupsAppliesOffset(t *testing.T) {
+	allocator, workerIDs := newGroupTestAllocator(t, 1, 100)
+
+	dispatches, err := allocateGroups(allocator, "run_1", "stg_1", nil, nil, nil, workerIDs, 10, 5)
+	if err != nil {
+		t.Fatalf("allocateGroups failed: %v", err)
+	}
+	if len(dispatches) != 1 {
+		t.Fatalf("expected 1 dispatch, got %d", len(dispatches))
+	}
+	d := dispatches[0]
+	if d.group != nil || d.assignment.Group != "" {
+		t.Errorf("expected no group, got %+v", d)
+	}
+	if d.assignment.VUIDRange.Start != 10 || d.assignment.VUIDRange.End != 15 {
+		t.Errorf("expected range [10, 15), got %+v", d.assignment.VUIDRange)
+	}
+}
+
+func TestAllocateGroups_SplitsTargetsByWeight(t *testing.T) {
+	allocator, workerIDs := newGroupTestAllocator(t, 2, 100)
+	targets := []parsedTargetEntry{
+		{Name: "east", Weight: 3, URL: "https://east.example.com/mcp"},
+		{Name: "west", Weight: 1, URL: "https://west.example.com/mcp"},
+	}
+
+	totals := make(map[string]int)
+	offset := 0
+	for _, step := range []int{1, 6, 13} {
+		dispatches, err := allocateGroups(allocator, "run_1", "stg_1", nil, targets, nil, workerIDs, offset, step)
+		if err != nil {
+			t.Fatalf("allocateGroups failed at offset %d: %v", offset, err)
+		}
+		for _, d := range dispatches {
+			if d.assignment.Target != d.targetName() || d.target == nil {
+				t.Errorf("expected assignment target %q, got %q", d.targetName(), d.assignment.Target)
+			}
+			if d.assignment.VUIDRange.Start < offset || d.assignment.VUIDRange.End > offset+step {
+				t.Errorf("range %+v outside step [%d, %d)", d.assignment.VUIDRange, offset, offset+step)
+			}
+			totals[d.targetName()] += d.assignment.VUIDRange.End - d.assignment.VUIDRange.Start
+		}
+		offset += step
+	}
+	if totals["east"] != 15 || totals["west"] != 5 {
+		t.Errorf("expected 15 east and 5 west VUs, got %v", totals)
+	}
+
+	groups := []parsedGeneratorGroup{{Name: "steady", Weight: 1}, {Name: "bursty", Weight: 1}}
+	dispatches, err := allocateGroups(allocator, "run_1", "stg_1", groups, targets, nil, workerIDs, 0, 8)
+	if err != nil {
+		t.Fatalf("allocateGroups failed: %v", err)
+	}
+	perGroupTarget := make(map[string]int)
+	for _, d := range dispatches {
+		if d.assignment.Group != d.groupName() || d.assignment.Target != d.targetName() {
+			t.Errorf("expected group %q and target %q, got %+v", d.groupName(), d.targetName(), d.assignment)
+		}
+		perGroupTarget[d.groupName()+"/"+d.targetName()] += d.assignment.VUIDRange.End - d.assignment.VUIDRange.Start
+	}
+	if perGroupTarget["steady/east"] != 3 || perGroupTarget["steady/west"] != 1 || perGroupTarget["bursty/east"] != 3 || perGroupTarget["bursty/west"] != 1 {
+		t.Errorf("expected each group split 3:1 across targets, got %v", perGroupTarget)
+	}
+}
+
+func TestBuildTargetConfig_EntryOverrides(t *testing.T) {
+	config := &parsedRunConfig{
+		Target: parsedTarget{
+			URL:             "https://main.example.com/mcp",
+			Headers:         map[string]string{"X-Team": "load", "X-Region": "any"},
+			BackendIDHeader: "X-Backend",
+		},
+	}
+	entry := &parsedTargetEntry{
+		Name:    "east",
+		Weight:  1,
+		URL:     "https://east.example.com/mcp",
+		Headers: map[string]string{"X-Region": "east"},
+	}
+
+	target := buildTargetConfig("run_1", config, entry)
+	if target.URL != entry.URL {
+		t.Errorf("expected the entry URL, got %s", target.URL)
+	}
+	if target.Headers["X-Team"] != "load" || target.Headers["X-Region"] != "east" {
+		t.Errorf("expected merged headers with the entry winning, got %v", target.Headers)
+	}
+	if target.BackendIDHeader != "X-Backend" {
+		t.Errorf("expected the run's backend header, got %q", target.BackendIDHeader)
+	}
+	if config.Target.Headers["X-Region"] != "any" {
+		t.Error("expected the run's target headers to be left alone")
+	}
+
+	if got := buildTargetConfig("run_1", config, nil); got.URL != config.Target.URL {
+		t.Errorf("expected the run's target without an entry, got %s", got.URL)
+	}
+}
+
+func TestBuildShadowConfig(t *testing.T) {
+	config := &parsedRunConfig{
+		Target: parsedTarget{
+			URL:       "https://stable.example.com/mcp",
+			Transport: "streamable_http",
+			Headers:   map[string]string{"X-Team": "load"},
+			Auth:      &parsedAuth{Type: "bearer_token", Tokens: []string{"stable"}},
+		},
+	}
+	if buildShadowConfig("run_1", config) != nil {
+		t.Fatal("expected no shadow without one configured")
+	}
+
+	config.Shadow = &parsedShadow{
+		URL:         "https://canary.example.com/mcp",
+		Headers:     map[string]string{"X-Build": "canary"},
+		StableTools: []string{"lookup"},
+	}
+	shadow := buildShadowConfig("run_1", config)
+	if shadow == nil {
+		t.Fatal("expected a shadow")
+	}
+	if shadow.Target.URL != "https://canary.example.com/mcp" || shadow.Target.Transport != "streamable_http" {
+		t.Errorf("expected the shadow URL with the target's transport, got %+v", shadow.Target)
+	}
+	if shadow.Target.Headers["X-Team"] != "load" || shadow.Target.Headers["X-Build"] != "canary" {
+		t.Errorf("expected the target's headers with the shadow's added, got %v", shadow.Target.Headers)
+	}
+	if shadow.Target.Auth == nil || shadow.Target.Auth.Tokens[0] != "stable" {
+		t.Errorf("expected the target's auth without one of the shadow's, got %+v", shadow.Target.Auth)
+	}
+	if len(shadow.StableTools) != 1 || shadow.StableTools[0] != "lookup" {
+		t.Errorf("expected the stable tools, got %v", shadow.StableTools)
+	}
+}
+
+func TestAssignmentWorkload_Workflows(t *testing.T) {
+	rm := NewRunManager(nil)
+	config, err := parseRunConfig([]byte(`{"workload": {
+		"op_mix": [{"operation": "ping", "weight": 1}],
+		"workflows": [{"name": "discover", "weight": 3, "steps": [
+			{"operation": "tools_list", "extract": {"tool": "$.tools[0].name"}},
+			{"name": "call", "operation": "tools_call", "tool_name": "${vars.tool}"}
+		]}]
+	}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	workload, _ := rm.assignmentWorkload("run_missing", config, nil)
+	if len(workload.Workflows) != 1 {
+		t.Fatalf("expected the workflow in the assignment, got %+v", workload.Workflows)
+	}
+	wf := workload.Workflows[0]
+	if wf.Name != "discover" || wf.Weight != 3 || len(wf.Steps) != 2 {
+		t.Fatalf("unexpected workflow: %+v", wf)
+	}
+	if s := wf.Steps[0]; s.Name != "step_1" || s.Operation != "tools/list" || s.Extract["tool"] != "$.tools[0].name" {
+		t.Errorf("expected a named, normalized first step, got %+v", s)
+	}
+	if s := wf.Steps[1]; s.Name != "call" || s.Operation != "tools/call" || s.ToolName != "${vars.tool}" {
+		t.Errorf("unexpected second step: %+v", s)
+	}
+}
+
+func TestAssignmentWorkload_ThinkTime(t *testing.T) {
+	rm := NewRunManager(nil)
+	config := &parsedRunConfig{
+		Workload: parsedWorkload{
+			OpMix:         []parsedOpMixEntry{{Operation: "tools/list", Weight: 1}},
+			ThinkTime:     &parsedThinkTime{Mode: "exponential", BaseMs: 500, MaxMs: 4000},
+			ToolThinkTime: map[string]parsedThinkTime{"search": {Mode: "uniform", BaseMs: 2000, JitterMs: 500}},
+		},
+	}
+
+	workload, _ := rm.assignmentWorkload("run_missing", config, nil)
+	if tt := workload.ThinkTime; tt == nil || tt.Mode != "exponential" || tt.BaseMs != 500 || tt.MaxMs != 4000 {
+		t.Errorf("expected the workload think time, got %+v", tt)
+	}
+	if tt := workload.ToolThinkTime["search"]; tt.Mode != "uniform" || tt.BaseMs != 2000 || tt.JitterMs != 500 {
+		t.Errorf("expected the search tool's think time, got %+v", workload.ToolThinkTime)
+	}
+
+	group := &parsedGeneratorGroup{Name: "fast", Weight: 1, ThinkTime: &parsedThinkTime{Mode: "none"}}
+	workload, _ = rm.assignmentWorkload("run_missing", config, group)
+	if workload.ThinkTime == nil || workload.ThinkTime.Mode != "none" {
+		t.Errorf("expected the group think time to replace the workload's, got %+v", workload.ThinkTime)
+	}
+}
+
+func TestAssignmentWorkload_GroupOverrides(t *testing.T) {
+	rm := NewRunManager(nil)
+	config := &parsedRunConfig{
+		Workload: parsedWorkload{OpMix: []parsedOpMixEntry{{Operation: "tools/list", Weight: 1}}},
+	}
+	group := &parsedGeneratorGroup{
+		Name:          "bursty",
+		Weight:        1,
+		InFlightPerVU: 8,
+		ThinkTime:     &parsedThinkTime{Mode: "fixed", BaseMs: 250},
+		OperationMix:  []parsedOpMixEntry{{Operation: "ping", Weight: 1}},
+	}
+
+	workload, revision := rm.assignmentWorkload("run_missing", config, group)
+	if revision != 0 {
+		t.Errorf("expected revision 0 for group mix, got %d", revision)
+	}
+	if len(workload.OpMix) != 1 || workload.OpMix[0].Operation != "ping" {
+		t.Errorf("expected group op mix, got %+v", workload.OpMix)
+	}
+	if workload.InFlightPerVU != 8 || workload.ThinkTime == nil || workload.ThinkTime.BaseMs != 250 {
+		t.Errorf("expected group load settings, got %+v", workload)
+	}
+
+	workload, _ = rm.assignmentWorkload("run_missing", config, nil)
+	if len(workload.OpMix) != 1 || workload.OpMix[0].Operation != "tools/list" {
+		t.Errorf("expected run op mix without a group, got %+v", workload.OpMix)
+	}
+	if workload.InFlightPerVU != 0 || workload.ThinkTime != nil {
+		t.Errorf("expected no load overrides without a group, got %+v", workload)
+	}
+}
@@ -0,0 +1,283 @@
+package runmanager
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/scheduler"
+)
+
+// leaseHandoff is a dispatch that takes over part of a lost worker's lease.
+// lostLeaseID is empty for dispatches of a full reallocation.
+type leaseHandoff struct {
+	groupDispatch
+	lostLeaseID scheduler.LeaseID
+}
+
+// assignedLease is what a WORKER_ASSIGNED event recorded about a lease.
+type assignedLease struct {
+	workerID string
+	group    string
+	target   string
+}
+
+// assignedLeasesFromEvents maps the lease IDs of a run's WORKER_ASSIGNED
+// events to the worker, generator group and target they were issued for.
+func assignedLeasesFromEvents(eventLog *EventLog) map[string]assignedLease {
+	leases := make(map[string]assignedLease)
+	if eventLog == nil {
+		return leases
+	}
+	for _, event := range eventLog.GetAll() {
+		if event.Type != EventTypeWorkerAssigned {
+			continue
+		}
+		var payload struct {
+			WorkerID       string `json:"worker_id"`
+			LeaseID        string `json:"lease_id"`
+			GeneratorGroup string `json:"generator_group"`
+			Target         string `json:"target"`
+		}
+		if err := json.Unmarshal(event.Payload, &payload); err != nil || payload.LeaseID == "" {
+			continue
+		}
+		leases[payload.LeaseID] = assignedLease{workerID: payload.WorkerID, group: payload.GeneratorGroup, target: payload.Target}
+	}
+	return leases
+}
+
+// lostLeases returns the leases of stageID that workerID held when it was
+// lost, ordered by VU range. The heartbeat monitor revokes a dead worker's
+// leases before reporting it, so revoked leases count too, unless they were
+// revoked before the stage's latest lease was issued: those were superseded
+// by a re-dispatch of the stage, not lost with the worker.
+func lostLeases(runLeases []*scheduler.Lease, stageID string, workerID scheduler.WorkerID) []*scheduler.Lease {
+	var latestIssued int64
+	for _, lease := range runLeases {
+		if lease.Assignment.StageID == stageID && lease.IssuedAt > latestIssued {
+			latestIssued = lease.IssuedAt
+		}
+	}
+
+	var lost []*scheduler.Lease
+	for _, lease := range runLeases {
+		if lease.WorkerID != workerID || lease.Assignment.StageID != stageID {
+			continue
+		}
+		switch lease.State {
+		case scheduler.LeaseStateActive:
+			lost = append(lost, lease)
+		case scheduler.LeaseStateRevoked:
+			if lease.RevokedAt != nil && *lease.RevokedAt >= latestIssued {
+				lost = append(lost, lease)
+			}
+		}
+	}
+	sort.Slice(lost, func(i, j int) bool {
+		return lost[i].Assignment.VUIDRange.Start < lost[j].Assignment.VUIDRange.Start
+	})
+	return lost
+}
+
+// spareVUs returns how many more VUs each worker can take on top of the
+// active leases it holds across all runs.
+func spareVUs(leaseManager *scheduler.LeaseManager, workers []*scheduler.WorkerInfo) map[scheduler.WorkerID]int {
+	spare := make(map[scheduler.WorkerID]int, len(workers))
+	for _, w := range workers {
+		free := w.EffectiveCapacity.MaxVUs
+		for _, lease := range leaseManager.ListWorkerLeases(w.WorkerID) {
+			if lease.State == scheduler.LeaseStateActive {
+				free -= lease.Assignment.VUIDRange.End - lease.Assignment.VUIDRange.Start
+			}
+		}
+		spare[w.WorkerID] = max(free, 0)
+	}
+	return spare
+}
+
+// planLeaseHandoff splits the VU range of each lost lease across workers with
+// spare capacity, so the stage keeps its load without disturbing the leases
+// of the remaining workers. A lost lease's VUs stay in its generator group
+// and on its target: they go to workers already running that group, or to
+// workers not yet running the stage. With a spread placement, workers with
+// the lost worker's label value are used first. Each lease is packed onto
+// the workers with the most spare VUs first.
+//
+// busy maps each worker holding an active lease of the stage to the
+// generator group it runs ("" without groups); spare and busy are updated as
+// VUs are handed off.
+func planLeaseHandoff(config *parsedRunConfig, lost []*scheduler.Lease, assigned map[string]assignedLease, candidates []*scheduler.WorkerInfo, spare map[scheduler.WorkerID]int, busy map[scheduler.WorkerID]string, lostSpread string) ([]leaseHandoff, error) {
+	var handoffs []leaseHandoff
+	for _, lease := range lost {
+		info := assigned[string(lease.LeaseID)]
+
+		var eligible []*scheduler.WorkerInfo
+		free := 0
+		for _, w := range candidates {
+			if group, ok := busy[w.WorkerID]; ok && group != info.group {
+				continue
+			}
+			eligible = append(eligible, w)
+			free += spare[w.WorkerID]
+		}
+		if len(eligible) == 0 {
+			return nil, scheduler.ErrNoWorkersAvailable
+		}
+		vuRange := lease.Assignment.VUIDRange
+		if free < vuRange.End-vuRange.Start {
+			return nil, scheduler.ErrInsufficientCapacity
+		}
+
+		sameSpread := func(w *scheduler.WorkerInfo) bool {
+			return config.Placement != nil && config.Placement.SpreadAcross != "" &&
+				w.HostInfo.Labels[config.Placement.SpreadAcross] == lostSpread
+		}
+		sort.Slice(eligible, func(i, j int) bool {
+			a, b := eligible[i], eligible[j]
+			if sameSpread(a) != sameSpread(b) {
+				return sameSpread(a)
+			}
+			if spare[a.WorkerID] != spare[b.WorkerID] {
+				return spare[a.WorkerID] > spare[b.WorkerID]
+			}
+			return a.WorkerID < b.WorkerID
+		})
+
+		group := generatorGroupByName(config, info.group)
+		target := targetEntryByName(config, info.target)
+		start := vuRange.Start
+		for _, w := range eligible {
+			if start >= vuRange.End {
+				break
+			}
+			n := min(spare[w.WorkerID], vuRange.End-start)
+			if n <= 0 {
+				continue
+			}
+			handoffs = append(handoffs, leaseHandoff{
+				groupDispatch: groupDispatch{
+					group:    group,
+					target:   target,
+					workerID: w.WorkerID,
+					assignment: scheduler.Assignment{
+						RunID:     lease.Assignment.RunID,
+						StageID:   lease.Assignment.StageID,
+						VUIDRange: scheduler.VUIDRange{Start: start, End: start + n},
+					},
+				},
+				lostLeaseID: lease.LeaseID,
+			})
+			spare[w.WorkerID] -= n
+			busy[w.WorkerID] = info.group
+			start += n
+		}
+	}
+	return handoffs, nil
+}
+
+// generatorGroupByName returns the generator group called name, or nil.
+func generatorGroupByName(config *parsedRunConfig, name string) *parsedGeneratorGroup {
+	for i := range config.GeneratorGroups {
+		if config.GeneratorGroups[i].Name == name {
+			return &config.GeneratorGroups[i]
+		}
+	}
+	return nil
+}
+
+// targetEntryByName returns the target called name, or nil.
+func targetEntryByName(config *parsedRunConfig, name string) *parsedTargetEntry {
+	for i := range config.Targets {
+		if config.Targets[i].Name == name {
+			return &config.Targets[i]
+		}
+	}
+	return nil
+}
+
+// planRunLeaseHandoff plans the handoff of workerID's leases of the run's
+// active stage to the workers the run may still use. It returns no handoffs
+// if no leases of the worker are on record. Must be called with rm.mu held.
+func (rm *RunManager) planRunLeaseHandoff(record *RunRecord, parsedConfig *parsedRunConfig, workerID string) ([]leaseHandoff, error) {
+	stageID := record.ActiveStage.StageID
+	runLeases := rm.leaseManager.ListLeases(record.RunID)
+	lost := lostLeases(runLeases, stageID, scheduler.WorkerID(workerID))
+	if len(lost) == 0 {
+		return nil, nil
+	}
+
+	busy := make(map[scheduler.WorkerID]string)
+	assigned := assignedLeasesFromEvents(rm.eventLogs[record.RunID])
+	for _, lease := range runLeases {
+		if lease.State == scheduler.LeaseStateActive && lease.Assignment.StageID == stageID && lease.WorkerID != scheduler.WorkerID(workerID) {
+			busy[lease.WorkerID] = assigned[string(lease.LeaseID)].group
+		}
+	}
+
+	var candidates []*scheduler.WorkerInfo
+	for _, w := range placementWorkers(rm.registry, record.Project, parsedConfig.Placement) {
+		if w.WorkerID != scheduler.WorkerID(workerID) {
+			candidates = append(candidates, w)
+		}
+	}
+
+	var lostSpread string
+	if parsedConfig.Placement != nil && parsedConfig.Placement.SpreadAcross != "" {
+		lostSpread = workerLabelsFromEvents(rm.eventLogs[record.RunID])[workerID][parsedConfig.Placement.SpreadAcross]
+	}
+
+	handoffs, err := planLeaseHandoff(parsedConfig, lost, assigned, candidates, spareVUs(rm.leaseManager, candidates), busy, lostSpread)
+	if err != nil {
+		return nil, err
+	}
+
+	// Leases the worker still holds are revoked so their VU ranges can be
+	// re-issued.
+	for _, lease := range lost {
+		if lease.State == scheduler.LeaseStateActive {
+			_ = rm.leaseManager.RevokeLease(lease.LeaseID)
+		}
+	}
+	return handoffs, nil
+}
+
+// emitLeaseReassignedEvent records that part of a lost worker's lease was
+// re-issued to another worker.
+func (rm *RunManager) emitLeaseReassignedEvent(record *RunRecord, eventLog *EventLog, lostWorker string, h leaseHandoff, leaseID string) {
+	stageName := StageName(record.ActiveStage.Stage)
+	stageID := h.assignment.StageID
+	workerID := string(h.workerID)
+	fields := map[string]interface{}{
+		"lost_worker":   lostWorker,
+		"lost_lease_id": string(h.lostLeaseID),
+		"worker_id":     workerID,
+		"lease_id":      leaseID,
+		"vu_start":      h.assignment.VUIDRange.Start,
+		"vu_end":        h.assignment.VUIDRange.End,
+		"stage_id":      stageID,
+	}
+	if group := h.groupName(); group != "" {
+		fields["generator_group"] = group
+	}
+	if target := h.targetName(); target != "" {
+		fields["target"] = target
+	}
+	payload, _ := json.Marshal(fields)
+
+	event := RunEvent{
+		RunID:       record.RunID,
+		ExecutionID: record.ExecutionID,
+		Type:        EventTypeLeaseReassigned,
+		Actor:       ActorScheduler,
+		Correlation: CorrelationContext{
+			Stage:    &stageName,
+			StageID:  &stageID,
+			WorkerID: &workerID,
+		},
+		Payload: payload,
+		Evidence: []Evidence{
+			{Kind: "worker", Ref: lostWorker, Note: stringPtr("lease handed off from lost worker")},
+		},
+	}
+	appendEventWithLog(eventLog, event, "emitLeaseReassignedEvent")
+}
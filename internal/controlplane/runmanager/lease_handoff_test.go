@@ -0,0 +1,113 @@
+package runmanager
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/scheduler"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+func TestLostLeases(t *testing.T) {
+	revokedAt := func(ms int64) *int64 { return &ms }
+	lease := func(id, worker, stage string, start, end int, state scheduler.LeaseState, issued int64, revoked *int64) *scheduler.Lease {
+		return &scheduler.Lease{
+			LeaseID:    scheduler.LeaseID(id),
+			WorkerID:   scheduler.WorkerID(worker),
+			Assignment: scheduler.Assignment{StageID: stage, VUIDRange: scheduler.VUIDRange{Start: start, End: end}},
+			State:      state,
+			IssuedAt:   issued,
+			RevokedAt:  revoked,
+		}
+	}
+	leases := []*scheduler.Lease{
+		// Superseded by the re-dispatch of the stage at 200.
+		lease("lse_old", "wkr_1", "stg_1", 0, 10, scheduler.LeaseStateRevoked, 100, revokedAt(150)),
+		lease("lse_b", "wkr_1", "stg_1", 30, 40, scheduler.LeaseStateRevoked, 200, revokedAt(300)),
+		lease("lse_a", "wkr_1", "stg_1", 0, 20, scheduler.LeaseStateActive, 200, nil),
+		lease("lse_other", "wkr_2", "stg_1", 20, 30, scheduler.LeaseStateActive, 200, nil),
+		lease("lse_stage", "wkr_1", "stg_0", 0, 10, scheduler.LeaseStateRevoked, 50, revokedAt(300)),
+		lease("lse_expired", "wkr_1", "stg_1", 40, 50, scheduler.LeaseStateExpired, 200, nil),
+	}
+
+	lost := lostLeases(leases, "stg_1", "wkr_1")
+	if len(lost) != 2 || lost[0].LeaseID != "lse_a" || lost[1].LeaseID != "lse_b" {
+		t.Fatalf("expected lse_a and lse_b in VU order, got %+v", lost)
+	}
+}
+
+func TestPlanLeaseHandoff_KeepsGroupAndTarget(t *testing.T) {
+	config := &parsedRunConfig{
+		GeneratorGroups: []parsedGeneratorGroup{{Name: "readers", Weight: 1}, {Name: "writers", Weight: 1}},
+		Targets:         []parsedTargetEntry{{Name: "primary", Weight: 1}},
+	}
+	worker := func(id string) *scheduler.WorkerInfo {
+		return &scheduler.WorkerInfo{WorkerID: scheduler.WorkerID(id)}
+	}
+	candidates := []*scheduler.WorkerInfo{worker("wkr_2"), worker("wkr_3"), worker("wkr_4")}
+	lost := []*scheduler.Lease{{
+		LeaseID:    "lse_lost",
+		WorkerID:   "wkr_1",
+		Assignment: scheduler.Assignment{RunID: "run_1", StageID: "stg_1", VUIDRange: scheduler.VUIDRange{Start: 10, End: 40}},
+	}}
+	assigned := map[string]assignedLease{"lse_lost": {workerID: "wkr_1", group: "readers", target: "primary"}}
+	busy := map[scheduler.WorkerID]string{"wkr_2": "readers", "wkr_3": "writers"}
+	spare := map[scheduler.WorkerID]int{"wkr_2": 10, "wkr_3": 50, "wkr_4": 25}
+
+	handoffs, err := planLeaseHandoff(config, lost, assigned, candidates, spare, busy, "")
+	if err != nil {
+		t.Fatalf("planLeaseHandoff failed: %v", err)
+	}
+	want := []struct {
+		worker     scheduler.WorkerID
+		start, end int
+	}{{"wkr_4", 10, 35}, {"wkr_2", 35, 40}}
+	if len(handoffs) != len(want) {
+		t.Fatalf("expected %d handoffs, got %+v", len(want), handoffs)
+	}
+	for i, w := range want {
+		h := handoffs[i]
+		if h.workerID != w.worker || h.assignment.VUIDRange.Start != w.start || h.assignment.VUIDRange.End != w.end {
+			t.Errorf("handoff %d: expected %s [%d, %d), got %s %+v", i, w.worker, w.start, w.end, h.workerID, h.assignment.VUIDRange)
+		}
+		if h.lostLeaseID != "lse_lost" || h.groupName() != "readers" || h.targetName() != "primary" {
+			t.Errorf("handoff %d: expected lse_lost of readers on primary, got %+v", i, h)
+		}
+	}
+	if busy["wkr_4"] != "readers" || spare["wkr_4"] != 0 || spare["wkr_2"] != 5 {
+		t.Errorf("expected wkr_4 to join readers and spare to be used, got busy %v spare %v", busy, spare)
+	}
+
+	// The writers' worker alone cannot take a readers lease.
+	_, err = planLeaseHandoff(config, lost, assigned, candidates[1:2], map[scheduler.WorkerID]int{"wkr_3": 50},
+		map[scheduler.WorkerID]string{"wkr_3": "writers"}, "")
+	if !errors.Is(err, scheduler.ErrNoWorkersAvailable) {
+		t.Errorf("expected ErrNoWorkersAvailable, got %v", err)
+	}
+	_, err = planLeaseHandoff(config, lost, assigned, candidates, map[scheduler.WorkerID]int{"wkr_2": 10, "wkr_4": 10},
+		map[scheduler.WorkerID]string{"wkr_3": "writers"}, "")
+	if !errors.Is(err, scheduler.ErrInsufficientCapacity) {
+		t.Errorf("expected ErrInsufficientCapacity, got %v", err)
+	}
+}
+
+func TestPlanLeaseHandoff_PrefersSpreadValue(t *testing.T) {
+	config := &parsedRunConfig{Placement: &parsedPlacement{SpreadAcross: "region"}}
+	candidates := []*scheduler.WorkerInfo{
+		{WorkerID: "wkr_2", HostInfo: types.HostInfo{Labels: map[string]string{"region": "us"}}},
+		{WorkerID: "wkr_3", HostInfo: types.HostInfo{Labels: map[string]string{"region": "eu"}}},
+	}
+	lost := []*scheduler.Lease{{
+		LeaseID:    "lse_lost",
+		Assignment: scheduler.Assignment{StageID: "stg_1", VUIDRange: scheduler.VUIDRange{Start: 0, End: 10}},
+	}}
+	spare := map[scheduler.WorkerID]int{"wkr_2": 100, "wkr_3": 20}
+
+	handoffs, err := planLeaseHandoff(config, lost, nil, candidates, spare, map[scheduler.WorkerID]string{}, "eu")
+	if err != nil {
+		t.Fatalf("planLeaseHandoff failed: %v", err)
+	}
+	if len(handoffs) != 1 || handoffs[0].workerID != "wkr_3" {
+		t.Errorf("expected the lease to stay in eu on wkr_3, got %+v", handoffs)
+	}
+}
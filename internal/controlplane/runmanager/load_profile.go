@@ -0,0 +1,189 @@
+package runmanager
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"math"
+	"time"
+)
+
+// DefaultSpikeMultiplier is the spike height, relative to the baseline stage's
+// VUs, when a spike stage sets neither spike.multiplier nor load.target_vus.
+const DefaultSpikeMultiplier = 3
+
+// loadPlateau is a period of constant load within a spike or step stage.
+type loadPlateau struct {
+	Phase  string // "base", "spike", "recovery" or "step"
+	VUs    int
+	HoldMs int64
+}
+
+// spikePlateaus plans a spike stage: baseline load, a jump to the spike
+// height held for spike.hold_ms (default a third of the stage), then baseline
+// load again for the rest of the stage. The time around the spike is split
+// evenly before and after it.
+func spikePlateaus(stage *parsedStage, baselineVUs, maxVUs int) []loadPlateau {
+	base := capVUs(max(baselineVUs, 1), maxVUs)
+
+	var peak int
+	switch {
+	case stage.Spike != nil && stage.Spike.Multiplier > 0:
+		peak = int(math.Ceil(stage.Spike.Multiplier * float64(base)))
+	case stage.Load.TargetVUs > 0:
+		peak = stage.Load.TargetVUs
+	default:
+		peak = DefaultSpikeMultiplier * base
+	}
+	peak = capVUs(peak, maxVUs)
+
+	holdMs := stage.DurationMs / 3
+	if stage.Spike != nil && stage.Spike.HoldMs > 0 {
+		holdMs = min(stage.Spike.HoldMs, stage.DurationMs)
+	}
+	beforeMs := (stage.DurationMs - holdMs) / 2
+	afterMs := stage.DurationMs - holdMs - beforeMs
+
+	plateaus := make([]loadPlateau, 0, 3)
+	if beforeMs > 0 {
+		plateaus = append(plateaus, loadPlateau{Phase: "base", VUs: base, HoldMs: beforeMs})
+	}
+	plateaus = append(plateaus, loadPlateau{Phase: "spike", VUs: peak, HoldMs: holdMs})
+	if afterMs > 0 {
+		plateaus = append(plateaus, loadPlateau{Phase: "recovery", VUs: base, HoldMs: afterMs})
+	}
+	return plateaus
+}
+
+// stepPlateaus plans a step stage from its steps. The last step is held until
+// the stage ends, and steps starting after duration_ms are dropped.
+func stepPlateaus(stage *parsedStage, maxVUs int) []loadPlateau {
+	var (
+		plateaus []loadPlateau
+		startMs  int64
+	)
+	for _, step := range stage.Steps {
+		if startMs >= stage.DurationMs {
+			break
+		}
+		holdMs := min(step.HoldMs, stage.DurationMs-startMs)
+		plateaus = append(plateaus, loadPlateau{Phase: "step", VUs: capVUs(step.TargetVUs, maxVUs), HoldMs: holdMs})
+		startMs += holdMs
+	}
+	if n := len(plateaus); n > 0 {
+		plateaus[n-1].HoldMs += stage.DurationMs - startMs
+	}
+	return plateaus
+}
+
+func capVUs(vus, maxVUs int) int {
+	if maxVUs > 0 && vus > maxVUs {
+		return maxVUs
+	}
+	return vus
+}
+
+// startLoadPlateaus runs a spike or step stage. At each plateau boundary the
+// stage's leases are revoked and the plateau's VUs are allocated afresh over
+// the workers registered at that moment, so workers that joined or left
+// mid-stage are accounted for. Each assignment lasts as long as its plateau.
+func (rm *RunManager) startLoadPlateaus(runID, executionID string, eventLog *EventLog, stage *parsedStage, parsedConfig *parsedRunConfig, state RunState) {
+	var plateaus []loadPlateau
+	switch StageName(stage.Stage) {
+	case StageNameSpike:
+		baselineVUs := 0
+		if baseline := findStageByName(parsedConfig, StageNameBaseline); baseline != nil {
+			baselineVUs = baseline.Load.TargetVUs
+		}
+		plateaus = spikePlateaus(stage, baselineVUs, parsedConfig.Safety.HardCaps.MaxVUs)
+	case StageNameStep:
+		plateaus = stepPlateaus(stage, parsedConfig.Safety.HardCaps.MaxVUs)
+	}
+	if len(plateaus) == 0 {
+		log.Printf("[RunManager] No load plateaus planned for %s stage of run %s", stage.Stage, runID)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(rm.ctx)
+	rm.mu.Lock()
+	if record, ok := rm.runs[runID]; ok {
+		record.rampCancel = cancel
+	}
+	rm.mu.Unlock()
+
+	previousVUs := 0
+	var heldMs int64
+	startPlateau := func(i int) bool {
+		rm.mu.RLock()
+		record, ok := rm.runs[runID]
+		running := ok && record.State == state
+		leaseManager := rm.leaseManager
+		rm.mu.RUnlock()
+		if !running {
+			log.Printf("[RunManager] %s stage stopped for run %s: state changed", stage.Stage, runID)
+			return false
+		}
+
+		plateau := plateaus[i]
+		if leaseManager != nil {
+			if err := leaseManager.RevokeLeasesByRunAndStage(runID, stage.StageID); err != nil {
+				log.Printf("[RunManager] Failed to revoke %s leases for run %s: %v", stage.Stage, runID, err)
+			}
+		}
+		workers := rm.dispatchVURange(runID, executionID, eventLog, stage, StageName(stage.Stage), parsedConfig, 0, plateau.VUs, plateau.HoldMs, remainingWarmup(stage, heldMs), plateau.VUs)
+		heldMs += plateau.HoldMs
+
+		log.Printf("[RunManager] %s stage step %d/%d for run %s: %d -> %d VUs on %d workers for %dms",
+			stage.Stage, i+1, len(plateaus), runID, previousVUs, plateau.VUs, workers, plateau.HoldMs)
+		rm.emitStageStepEvent(runID, executionID, eventLog, stage, i, len(plateaus), plateau, previousVUs, workers)
+		previousVUs = plateau.VUs
+		return true
+	}
+
+	if !startPlateau(0) {
+		cancel()
+		return
+	}
+
+	go func() {
+		defer cancel()
+		for i := 1; i < len(plateaus); i++ {
+			if !rm.waitRunning(ctx, runID, time.Duration(plateaus[i-1].HoldMs)*time.Millisecond) {
+				return
+			}
+			if !startPlateau(i) {
+				return
+			}
+		}
+	}()
+}
+
+func (rm *RunManager) emitStageStepEvent(runID, executionID string, eventLog *EventLog, stage *parsedStage, step, stepCount int, plateau loadPlateau, previousVUs, workers int) {
+	payload, _ := json.Marshal(map[string]interface{}{
+		"stage":        stage.Stage,
+		"stage_id":     stage.StageID,
+		"step":         step,
+		"step_count":   stepCount,
+		"phase":        plateau.Phase,
+		"previous_vus": previousVUs,
+		"target_vus":   plateau.VUs,
+		"hold_ms":      plateau.HoldMs,
+		"workers":      workers,
+	})
+
+	stageName := StageName(stage.Stage)
+	stageID := stage.StageID
+	event := RunEvent{
+		RunID:       runID,
+		ExecutionID: executionID,
+		Type:        EventTypeStageStep,
+		Actor:       ActorScheduler,
+		Correlation: CorrelationContext{
+			Stage:   &stageName,
+			StageID: &stageID,
+		},
+		Payload:  payload,
+		Evidence: []Evidence{},
+	}
+	appendEventWithLog(eventLog, event, "emitStageStepEvent")
+}
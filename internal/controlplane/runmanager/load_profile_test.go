@@ -0,0 +1,169 @@
+package runmanager
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/scheduler"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+func TestSpikePlateaus(t *testing.T) {
+	tests := []struct {
+		name        string
+		stage       parsedStage
+		baselineVUs int
+		maxVUs      int
+		expected    []loadPlateau
+	}{
+		{
+			name:        "multiplier with default hold",
+			stage:       parsedStage{DurationMs: 90000, Spike: &parsedSpike{Multiplier: 2.5}},
+			baselineVUs: 10,
+			expected: []loadPlateau{
+				{Phase: "base", VUs: 10, HoldMs: 30000},
+				{Phase: "spike", VUs: 25, HoldMs: 30000},
+				{Phase: "recovery", VUs: 10, HoldMs: 30000},
+			},
+		},
+		{
+			name:        "explicit hold and hard cap",
+			stage:       parsedStage{DurationMs: 60000, Spike: &parsedSpike{Multiplier: 10, HoldMs: 20000}},
+			baselineVUs: 10,
+			maxVUs:      50,
+			expected: []loadPlateau{
+				{Phase: "base", VUs: 10, HoldMs: 20000},
+				{Phase: "spike", VUs: 50, HoldMs: 20000},
+				{Phase: "recovery", VUs: 10, HoldMs: 20000},
+			},
+		},
+		{
+			name:        "absolute target without multiplier",
+			stage:       parsedStage{DurationMs: 30000, Load: parsedLoad{TargetVUs: 40}},
+			baselineVUs: 5,
+			expected: []loadPlateau{
+				{Phase: "base", VUs: 5, HoldMs: 10000},
+				{Phase: "spike", VUs: 40, HoldMs: 10000},
+				{Phase: "recovery", VUs: 5, HoldMs: 10000},
+			},
+		},
+		{
+			name:        "default multiplier",
+			stage:       parsedStage{DurationMs: 30000, Spike: &parsedSpike{HoldMs: 30000}},
+			baselineVUs: 4,
+			expected:    []loadPlateau{{Phase: "spike", VUs: 12, HoldMs: 30000}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := spikePlateaus(&tt.stage, tt.baselineVUs, tt.maxVUs)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestStepPlateaus(t *testing.T) {
+	steps := []parsedLoadStep{{TargetVUs: 10, HoldMs: 20000}, {TargetVUs: 20, HoldMs: 20000}, {TargetVUs: 80, HoldMs: 20000}}
+
+	got := stepPlateaus(&parsedStage{DurationMs: 100000, Steps: steps}, 50)
+	expected := []loadPlateau{
+		{Phase: "step", VUs: 10, HoldMs: 20000},
+		{Phase: "step", VUs: 20, HoldMs: 20000},
+		{Phase: "step", VUs: 50, HoldMs: 60000},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected last step held to the end and capped, got %+v", got)
+	}
+
+	got = stepPlateaus(&parsedStage{DurationMs: 30000, Steps: steps}, 0)
+	expected = []loadPlateau{
+		{Phase: "step", VUs: 10, HoldMs: 20000},
+		{Phase: "step", VUs: 20, HoldMs: 10000},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected steps past duration_ms to be dropped, got %+v", got)
+	}
+}
+
+func TestTransitionToStep_DispatchesFirstPlateau(t *testing.T) {
+	rm := NewRunManager(createTestValidatorForWorkerTest(t))
+
+	registry := scheduler.NewRegistry()
+	lm := scheduler.NewLeaseManager(60000)
+	allocator := scheduler.NewAllocator(registry, lm)
+	rm.SetScheduler(registry, allocator, lm)
+
+	mockSender := &mockAssignmentSender{assignments: make(map[string][]types.WorkerAssignment)}
+	rm.SetAssignmentSender(mockSender)
+
+	w1, _ := registry.RegisterWorker(types.HostInfo{Hostname: "host1"}, types.WorkerCapacity{MaxVUs: 100})
+	w2, _ := registry.RegisterWorker(types.HostInfo{Hostname: "host2"}, types.WorkerCapacity{MaxVUs: 100})
+
+	runID := createTestRunWithPolicy(t, rm, "")
+	config := rm.runs[runID].Config
+	var raw map[string]interface{}
+	json.Unmarshal(config, &raw)
+	raw["stages"] = append(raw["stages"].([]interface{}), map[string]interface{}{
+		"stage_id":    "stg_000000000004",
+		"stage":       "step",
+		"enabled":     true,
+		"duration_ms": 120000,
+		"warmup_ms":   30000,
+		"load":        map[string]interface{}{"target_vus": 0, "target_rps": nil},
+		"steps": []interface{}{
+			map[string]interface{}{"target_vus": 20, "hold_ms": 60000},
+			map[string]interface{}{"target_vus": 40, "hold_ms": 60000},
+		},
+		"stop_conditions": []interface{}{},
+	})
+	rm.runs[runID].Config, _ = json.Marshal(raw)
+	setRunState(t, rm, runID, RunStateRampRunning)
+	setActiveStage(t, rm, runID, "ramp", "stg_000000000003")
+
+	if err := rm.TransitionToStep(runID, "test"); err != nil {
+		t.Fatalf("TransitionToStep failed: %v", err)
+	}
+	defer rm.Shutdown()
+
+	view, _ := rm.GetRun(runID)
+	if view.State != RunStateStepRunning {
+		t.Fatalf("expected step_running, got %s", view.State)
+	}
+
+	totalVUs := 0
+	for _, wid := range []scheduler.WorkerID{w1, w2} {
+		for _, a := range mockSender.assignments[string(wid)] {
+			if a.Stage != "step" || a.DurationMs != 60000 || a.WarmupMs != 30000 {
+				t.Errorf("expected a 60s step assignment warming up for 30s, got stage=%s duration=%d warmup=%d", a.Stage, a.DurationMs, a.WarmupMs)
+			}
+			totalVUs += a.VUIDEnd - a.VUIDStart
+		}
+	}
+	if totalVUs != 20 {
+		t.Errorf("expected the first plateau's 20 VUs across workers, got %d", totalVUs)
+	}
+
+	events, _ := rm.TailEvents(runID, 0, 100)
+	var step *RunEvent
+	for i := range events {
+		if events[i].Type == EventTypeStageStep {
+			step = &events[i]
+		}
+	}
+	if step == nil {
+		t.Fatal("expected STAGE_STEP event")
+	}
+	var payload map[string]interface{}
+	json.Unmarshal(step.Payload, &payload)
+	if payload["step"] != float64(0) || payload["step_count"] != float64(2) || payload["target_vus"] != float64(20) {
+		t.Errorf("unexpected STAGE_STEP payload: %v", payload)
+	}
+
+	if err := rm.TransitionToSpike(runID, "test"); err == nil {
+		t.Error("expected transition to a missing spike stage to fail")
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
+	"maps"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -14,6 +16,7 @@ import (
 	"github.com/bc-dunia/mcpdrill/internal/analysis"
 	"github.com/bc-dunia/mcpdrill/internal/artifacts"
 	"github.com/bc-dunia/mcpdrill/internal/controlplane/scheduler"
+	"github.com/bc-dunia/mcpdrill/internal/otel"
 	"github.com/bc-dunia/mcpdrill/internal/types"
 	"github.com/bc-dunia/mcpdrill/internal/validation"
 )
@@ -43,6 +46,7 @@ type ActiveStageInfo struct {
 // RunRecord is the internal representation of a run.
 type RunRecord struct {
 	RunID       string           `json:"run_id"`
+	Project     string           `json:"project"`
 	ExecutionID string           `json:"execution_id"`
 	State       RunState         `json:"state"`
 	ConfigHash  string           `json:"config_hash"`
@@ -54,17 +58,58 @@ type RunRecord struct {
 	Actor       string           `json:"actor"`
 	Config      json.RawMessage  `json:"-"` // Raw config for assignment creation
 
+	// Priority and QueuedAtMs order the run in the run queue; they are set
+	// when the run is queued.
+	Priority   int   `json:"priority,omitempty"`
+	QueuedAtMs int64 `json:"queued_at_ms,omitempty"`
+
+	// ScenarioRef is the library scenario version the run was created from.
+	ScenarioRef *ScenarioRef `json:"scenario_ref,omitempty"`
+	// Parameters were applied to Config when the run was created or started.
+	Parameters *RunParameters `json:"parameters,omitempty"`
+	// Tags label the run for finding it later.
+	Tags map[string]string `json:"tags,omitempty"`
+
+	SLOs *analysis.SLOReport `json:"slos,omitempty"` // Set when analysis completes
+
+	// Capacity is the result of an adaptive ramp, set once the ramp settles.
+	Capacity *analysis.CapacityReport `json:"capacity,omitempty"`
+
+	// Conformance is the report of a conformance run's protocol checks, set
+	// once a worker reports them.
+	Conformance *analysis.ConformanceReport `json:"conformance,omitempty"`
+
+	// AbortedBy is the actor that aborted the run, if it was aborted.
+	AbortedBy string `json:"aborted_by,omitempty"`
+	// PausedAtMs is when the run was paused, while it is PAUSED, and
+	// PausedMs the time it has spent paused before.
+	PausedAtMs int64 `json:"paused_at_ms,omitempty"`
+	PausedMs   int64 `json:"paused_ms,omitempty"`
+
 	progressionCancel    context.CancelFunc
 	progressionTimers    []*time.Timer
 	stopConditionsCancel context.CancelFunc
 	rampCancel           context.CancelFunc
-	drainCancel          chan struct{} // Channel to cancel drain wait early (for emergency stop or worker loss)
-	immediateStop        bool          // True if emergency_stop escalated while in STOPPING (workers should terminate immediately)
+	analysisCancel       context.CancelFunc  // Cancels an analysis in progress, when the run is aborted
+	drainCancel          chan struct{}       // Channel to cancel drain wait early (for emergency stop or worker loss)
+	immediateStop        bool                // True if emergency_stop escalated while in STOPPING (workers should terminate immediately)
+	opMix                []types.OpMixEntry  // Live op mix override set via UpdateOpMix (nil = use config)
+	opMixRevision        int64               // Incremented on every accepted op mix update
+	admitted             bool                // True once the run queue has let a QUEUED run start
+	startActor           string              // Actor that started a QUEUED run
+	targetKey            string              // Target host, for per-target exclusion in the run queue
+	pausedFrom           RunState            // State a PAUSED run resumes in
+	pauseCh              chan struct{}       // Closed when the run is paused (nil until a stage timer needs it)
+	resumeCh             chan struct{}       // Closed when a paused run resumes or stops (nil unless paused)
+	analysisSummary      *RunAnalysisSummary // Headline results for the run's notification, set once analyzed
+	targetProfiled       bool                // True once a worker's target profile was recorded
+	conformanceChecked   bool                // True once a worker's conformance report was recorded
 }
 
 // RunView is the external representation of a run (matches run-view/v1 schema).
 type RunView struct {
 	RunID               string           `json:"run_id"`
+	Project             string           `json:"project"`
 	ExecutionID         string           `json:"execution_id"`
 	State               RunState         `json:"state"`
 	ScenarioID          string           `json:"scenario_id"`
@@ -74,6 +119,39 @@ type RunView struct {
 	ActiveStage         *ActiveStageInfo `json:"active_stage,omitempty"`
 	StopReason          *StopReason      `json:"stop_reason,omitempty"`
 	LastDecisionEventID *string          `json:"last_decision_event_id,omitempty"`
+
+	// SLOs is the run's SLO verdict, set once analysis completes if the run
+	// config defines SLOs.
+	SLOs *analysis.SLOReport `json:"slos,omitempty"`
+	// Capacity is the capacity an adaptive ramp found.
+	Capacity *analysis.CapacityReport `json:"capacity,omitempty"`
+	// Conformance is the report of a conformance run's protocol checks.
+	Conformance *analysis.ConformanceReport `json:"conformance,omitempty"`
+
+	// Priority is the priority the run was queued with, and QueuePosition
+	// its 1-based place among the runs waiting in the run queue.
+	Priority      int `json:"priority,omitempty"`
+	QueuePosition int `json:"queue_position,omitempty"`
+
+	// ScenarioRef is the library scenario version the run was created
+	// from, if it was.
+	ScenarioRef *ScenarioRef `json:"scenario_ref,omitempty"`
+	// Parameters are the run parameters applied to the run's config.
+	Parameters *RunParameters `json:"parameters,omitempty"`
+	// Tags are the run's labels, and TargetHost the host of its target.
+	Tags       map[string]string `json:"tags,omitempty"`
+	TargetHost string            `json:"target_host,omitempty"`
+	// PreviousRunIDs are the project's latest earlier runs with the same
+	// config hash, newest first.
+	PreviousRunIDs []string `json:"previous_run_ids,omitempty"`
+
+	// AbortedBy is the actor that aborted the run, if it was aborted.
+	AbortedBy string `json:"aborted_by,omitempty"`
+
+	// PausedAtMs is when the run was paused, while it is paused, and
+	// PausedMs the time it spent paused before.
+	PausedAtMs int64 `json:"paused_at_ms,omitempty"`
+	PausedMs   int64 `json:"paused_ms,omitempty"`
 }
 
 // AssignmentSender is an interface for sending assignments to workers.
@@ -88,7 +166,16 @@ type TelemetryData struct {
 	StartTimeMs int64
 	EndTimeMs   int64
 	StopReason  string
-	Operations  []analysis.OperationResult
+	// Operations holds the run's most recent operations. Stores that spill
+	// to disk leave the older SpilledOperations out; OperationStreamer
+	// visits all of them. Stores that aggregate operations drop the older
+	// DroppedOperations; OperationAggregator still covers them.
+	Operations        []analysis.OperationResult
+	SpilledOperations int
+	DroppedOperations int
+	// GeneratorWarnings are the warnings workers raised about their own
+	// health during the run, such as shedding load.
+	GeneratorWarnings []analysis.GeneratorWarning
 }
 
 // TelemetryStore provides access to telemetry data for a run.
@@ -97,6 +184,79 @@ type TelemetryStore interface {
 	SetRunMetadata(runID, scenarioID, stopReason string)
 }
 
+// OperationStreamer is implemented by telemetry stores that can spill
+// operations to disk, so analysis reads them back one at a time.
+type OperationStreamer interface {
+	// ForEachOperation calls fn for every operation of the run, oldest
+	// first, and stops at the first error fn returns.
+	ForEachOperation(runID string, fn func(analysis.OperationResult) error) error
+}
+
+// OperationAggregator is implemented by telemetry stores that aggregate
+// operations as they arrive instead of keeping all of them.
+type OperationAggregator interface {
+	// MergeOperations merges the aggregate of the run's operations, or of
+	// one stage's when stage is not empty, into a. It returns false if the
+	// store keeps the run's raw operations instead.
+	MergeOperations(runID, stage string, a *analysis.Aggregator) (bool, error)
+}
+
+// addOperations adds the operations of data to a, only those of one stage
+// when stage is not empty. It uses the store's aggregate when it has one
+// and reads spilled operations back from disk. Stand-ins for summarized
+// operations are skipped; the store merges their summaries.
+func addOperations(store TelemetryStore, data *TelemetryData, stage string, a *analysis.Aggregator) error {
+	if aggregator, ok := store.(OperationAggregator); ok {
+		if merged, err := aggregator.MergeOperations(data.RunID, stage, a); err != nil || merged {
+			return err
+		}
+	}
+	add := func(op analysis.OperationResult) error {
+		if !op.Summarized && (stage == "" || op.Stage == stage) {
+			a.AddOperation(op)
+		}
+		return nil
+	}
+	if streamer, ok := store.(OperationStreamer); ok && data.SpilledOperations > 0 {
+		return streamer.ForEachOperation(data.RunID, add)
+	}
+	for _, op := range data.Operations {
+		_ = add(op)
+	}
+	return nil
+}
+
+// addGeneratorWarnings adds the warnings raised between fromMs and toMs to
+// a; a toMs of 0 adds all of them.
+func addGeneratorWarnings(a *analysis.Aggregator, warnings []analysis.GeneratorWarning, fromMs, toMs int64) {
+	for _, w := range warnings {
+		if toMs == 0 || (w.TimestampMs >= fromMs && w.TimestampMs <= toMs) {
+			a.AddGeneratorWarning(w)
+		}
+	}
+}
+
+// OperationLogSource is implemented by telemetry stores that keep the raw
+// per-operation log of a run, so analysis can archive it.
+type OperationLogSource interface {
+	// WriteOperationLog writes the run's operation log to w as JSON lines
+	// and returns how many operations it wrote.
+	WriteOperationLog(runID string, w io.Writer) (int, error)
+}
+
+// ServerMetricsSource provides the agent metrics of every node paired with a
+// pair key, for inclusion in the run report.
+type ServerMetricsSource interface {
+	NodeSeries(pairKey string, fromMs, toMs int64) []analysis.NodeSeries
+}
+
+// ServerLogSource is implemented by server metrics sources that also keep
+// the log lines agents tail from the target, so the report can set them
+// against client errors.
+type ServerLogSource interface {
+	ServerLogs(pairKey string, fromMs, toMs int64) []analysis.ServerLogEntry
+}
+
 // RunManager is the core control plane component for managing run lifecycle.
 type RunManager struct {
 	mu        sync.RWMutex
@@ -111,9 +271,34 @@ type RunManager struct {
 	allocator        *scheduler.Allocator
 	leaseManager     *scheduler.LeaseManager
 	assignmentSender AssignmentSender
+	scaleHook        ScaleHook
+	runNotifier      RunNotifier
+
+	workerLauncher     WorkerLauncher
+	workerReadyTimeout time.Duration
+
+	runQueue        *RunQueueConfig
+	runQueueStarted bool
 
-	artifactStore  artifacts.Store
-	telemetryStore TelemetryStore
+	// abortNotices holds, per worker, the aborted runs it must still be
+	// told to stop and until when (Unix ms).
+	abortNotices map[scheduler.WorkerID]map[string]int64
+
+	// scenarioMu guards the scenario library, kept apart from mu so store
+	// writes do not block runs.
+	scenarioMu sync.Mutex
+	scenarios  map[scenarioKey][]*ScenarioVersion
+
+	// validations caches passed validation reports by config hash, and
+	// configRuns lists the runs of each config in creation order.
+	validations validationCache
+	configRuns  map[configKey][]string
+
+	artifactStore       artifacts.Store
+	telemetryStore      TelemetryStore
+	serverMetricsSource ServerMetricsSource
+	persister           *runPersister
+	tracer              *otel.Tracer
 
 	runIDCounter atomic.Int64
 	exeIDCounter atomic.Int64
@@ -128,12 +313,15 @@ func NewRunManager(validator *validation.UnifiedValidator) *RunManager {
 		validator: validator,
 		ctx:       ctx,
 		cancel:    cancel,
+		tracer:    otel.GetGlobalTracer(),
 	}
 }
 
-// Shutdown cancels all background goroutines (stage progression, ramp, analysis).
+// Shutdown cancels all background goroutines (stage progression, ramp, analysis)
+// and writes pending changes to the run store, if one is configured.
 func (rm *RunManager) Shutdown() {
 	rm.cancel()
+	rm.closeRunStore()
 }
 
 // SetScheduler configures the scheduler components for assignment creation.
@@ -166,6 +354,14 @@ func (rm *RunManager) SetTelemetryStore(store TelemetryStore) {
 	rm.telemetryStore = store
 }
 
+// SetServerMetricsSource configures where analysis reads server telemetry
+// from. Without a source, reports carry no server metrics.
+func (rm *RunManager) SetServerMetricsSource(source ServerMetricsSource) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.serverMetricsSource = source
+}
+
 // generateRunID generates a unique run ID.
 // Format: run_{20 hex chars} to match pattern ^run_[0-9a-f]{16,64}$
 func (rm *RunManager) generateRunID() string {
@@ -212,12 +408,53 @@ func (rm *RunManager) ValidateRunConfig(config []byte) *validation.ValidationRep
 		report.AddError("VALIDATOR_NOT_CONFIGURED", "No validator configured", "")
 		return report
 	}
-	return rm.validator.ValidateRunConfig(config)
+	return rm.validations.validate(computeConfigHash(config), func() *validation.ValidationReport {
+		return rm.validator.ValidateRunConfig(config)
+	})
 }
 
 // CreateRun creates a new run with the given configuration.
 // Returns the run ID on success, or an error if validation fails.
 func (rm *RunManager) CreateRun(config []byte, actor string) (string, error) {
+	return rm.createRun(types.DefaultProject, config, actor, nil, nil, nil)
+}
+
+// CreateRunWithParameters creates a run from config with params applied.
+func (rm *RunManager) CreateRunWithParameters(config []byte, params *RunParameters, actor string) (string, error) {
+	return rm.createRun(types.DefaultProject, config, actor, nil, params, nil)
+}
+
+// CreateRunInProject creates a run in project from config with params
+// applied and tags set. Only workers of the project, or shared ones, run it.
+func (rm *RunManager) CreateRunInProject(project string, config []byte, params *RunParameters, tags map[string]string, actor string) (string, error) {
+	return rm.createRun(project, config, actor, nil, params, tags)
+}
+
+// createRun creates a run in project from config with params applied and
+// tags set, recording the scenario version it came from when ref is set.
+func (rm *RunManager) createRun(project string, config []byte, actor string, ref *ScenarioRef, params *RunParameters, tags map[string]string) (string, error) {
+	if project == "" {
+		project = types.DefaultProject
+	}
+	if !types.ValidProjectName(project) {
+		return "", NewInvalidArgumentError("", fmt.Errorf("invalid project name %q", project))
+	}
+	if err := ValidateRunTags(tags); err != nil {
+		return "", NewInvalidArgumentError("", err)
+	}
+	if len(tags) == 0 {
+		tags = nil
+	}
+	if params.IsZero() {
+		params = nil
+	} else {
+		parameterized, err := params.Apply(config)
+		if err != nil {
+			return "", NewInvalidArgumentError("", err)
+		}
+		config = parameterized
+	}
+
 	report := rm.ValidateRunConfig(config)
 	if !report.OK {
 		return "", &validation.ValidationError{Report: report}
@@ -231,6 +468,7 @@ func (rm *RunManager) CreateRun(config []byte, actor string) (string, error) {
 
 	record := &RunRecord{
 		RunID:       runID,
+		Project:     project,
 		ExecutionID: executionID,
 		State:       RunStateCreated,
 		ConfigHash:  configHash,
@@ -239,20 +477,38 @@ func (rm *RunManager) CreateRun(config []byte, actor string) (string, error) {
 		UpdatedAtMs: nowMs,
 		Actor:       actor,
 		Config:      config,
+		ScenarioRef: ref,
+		Parameters:  params,
+		Tags:        maps.Clone(tags),
+		targetKey:   targetHost(config),
 	}
 
 	eventLog := NewEventLog()
 
 	rm.mu.Lock()
+	eventLog.trace = newRunTrace(rm.tracer, record, false)
 	rm.runs[runID] = record
 	rm.eventLogs[runID] = eventLog
+	rm.indexConfigLocked(record)
+	rm.persistEventLogLocked(runID, eventLog)
 	rm.mu.Unlock()
 
-	payload, err := json.Marshal(map[string]interface{}{
+	fields := map[string]interface{}{
+		"project":     project,
 		"config_hash": configHash,
 		"scenario_id": scenarioID,
 		"actor":       actor,
-	})
+	}
+	if ref != nil {
+		fields["scenario_ref"] = ref
+	}
+	if params != nil {
+		fields["parameters"] = params
+	}
+	if tags != nil {
+		fields["tags"] = tags
+	}
+	payload, err := json.Marshal(fields)
 	if err != nil {
 		log.Printf("[RunManager] Failed to marshal CreateRun event payload for run %s: %v", runID, err)
 		payload = []byte("{}")
@@ -273,10 +529,17 @@ func (rm *RunManager) CreateRun(config []byte, actor string) (string, error) {
 	return runID, nil
 }
 
-// StartRun transitions a run from CREATED to PREFLIGHT_RUNNING.
+// StartRun transitions a run from CREATED to PREFLIGHT_RUNNING, or to QUEUED
+// when a run queue is configured and the run cannot start yet.
 // Returns an error if the run is not in CREATED state or if allocation fails.
 // Per spec: allocation must succeed before transitioning to PREFLIGHT_RUNNING.
 func (rm *RunManager) StartRun(runID, actor string) error {
+	return rm.StartRunWithOptions(runID, actor, StartOptions{})
+}
+
+// startRun allocates workers for a run in state from and transitions it to
+// PREFLIGHT_RUNNING.
+func (rm *RunManager) startRun(runID, actor string, from RunState) error {
 	var (
 		configCopy       []byte
 		executionID      string
@@ -298,8 +561,8 @@ func (rm *RunManager) StartRun(runID, actor string) error {
 			return
 		}
 
-		if record.State != RunStateCreated {
-			err = NewInvalidStateError(runID, record.State, RunStateCreated, "start")
+		if record.State != from {
+			err = NewInvalidStateError(runID, record.State, from, "start")
 			return
 		}
 
@@ -327,14 +590,15 @@ func (rm *RunManager) StartRun(runID, actor string) error {
 	schedulerConfigured := registry != nil || allocator != nil || leaseManager != nil || assignmentSender != nil
 	schedulerComplete := registry != nil && allocator != nil && leaseManager != nil && assignmentSender != nil
 	if schedulerConfigured && !schedulerComplete {
-		rm.transitionToFailedFromCreated(runID, executionID, eventLog, actor, "scheduler_misconfiguration")
+		rm.transitionToFailedBeforeStart(runID, executionID, eventLog, actor, "scheduler_misconfiguration", from)
 		return fmt.Errorf("scheduler partially configured for run %s: some components are nil", runID)
 	}
 
 	// Per spec: attempt allocation BEFORE state transition
 	if registry != nil && allocator != nil && leaseManager != nil && assignmentSender != nil {
+		rm.provisionWorkers(runID, executionID, configCopy, eventLog)
 		if !rm.tryAllocateForStage(runID, executionID, configCopy, eventLog, StageNamePreflight) {
-			rm.transitionToFailedFromCreated(runID, executionID, eventLog, actor, "allocation_failed")
+			rm.transitionToFailedBeforeStart(runID, executionID, eventLog, actor, "allocation_failed", from)
 			return fmt.Errorf("allocation failed for run %s", runID)
 		}
 	}
@@ -349,7 +613,7 @@ func (rm *RunManager) StartRun(runID, actor string) error {
 			err = NewNotFoundError(runID)
 			return
 		}
-		if record.State != RunStateCreated {
+		if record.State != from {
 			err = fmt.Errorf("run state changed during allocation: %s", record.State)
 			return
 		}
@@ -387,16 +651,21 @@ func (rm *RunManager) StartRun(runID, actor string) error {
 
 	rm.startStageProgression(runID, configCopy, string(ActorAutoramp))
 
+	if parsedConfig, err := parseRunConfig(configCopy); err == nil {
+		rm.startTotalOpsCapWatcher(runID, parsedConfig.Safety.HardCaps.MaxTotalOps)
+	}
+
 	return nil
 }
 
-// transitionToFailedFromCreated transitions a run from CREATED to FAILED state.
-func (rm *RunManager) transitionToFailedFromCreated(runID, executionID string, eventLog *EventLog, actor, reason string) {
+// transitionToFailedBeforeStart transitions a run that failed to start from
+// CREATED or QUEUED to FAILED state.
+func (rm *RunManager) transitionToFailedBeforeStart(runID, executionID string, eventLog *EventLog, actor, reason string, from RunState) {
 	rm.mu.Lock()
 	defer rm.mu.Unlock()
 
 	record, ok := rm.runs[runID]
-	if !ok || record.State != RunStateCreated {
+	if !ok || record.State != from {
 		return
 	}
 
@@ -419,7 +688,8 @@ func (rm *RunManager) transitionToFailedFromCreated(runID, executionID string, e
 		Payload:     payload,
 		Evidence:    []Evidence{{Kind: "reason", Ref: reason}},
 	}
-	appendEventWithLog(eventLog, event, "transitionToFailedFromCreated")
+	appendEventWithLog(eventLog, event, "transitionToFailedBeforeStart")
+	rm.notifyRunFinishedLocked(record, reason)
 }
 
 // RequestStop transitions a run to STOPPING state with the specified mode.
@@ -440,6 +710,9 @@ func (rm *RunManager) requestStopWithReason(runID string, mode StopMode, actor s
 	if record.State == RunStateCompleted || record.State == RunStateFailed || record.State == RunStateAborted {
 		return NewTerminalStateError(runID, record.State, "stop")
 	}
+	if record.State == RunStateQueued {
+		return rm.abortQueuedRunLocked(record, mode, actor, reason, evidence)
+	}
 
 	rm.cancelStageProgressionLocked(record)
 	rm.stopStopConditionEvaluatorLocked(record)
@@ -538,6 +811,9 @@ func (rm *RunManager) EmergencyStop(runID, actor string) error {
 	if record.State == RunStateCompleted || record.State == RunStateFailed || record.State == RunStateAborted {
 		return NewTerminalStateError(runID, record.State, "emergency-stop")
 	}
+	if record.State == RunStateQueued {
+		return rm.abortQueuedRunLocked(record, StopModeImmediate, actor, "emergency_stop", nil)
+	}
 
 	rm.cancelStageProgressionLocked(record)
 
@@ -732,4 +1008,5 @@ func (rm *RunManager) transitionToCompleted(runID, actor, reason string) {
 		Evidence:    []Evidence{},
 	}
 	appendEventWithLog(eventLog, event, "transitionToStateWithActor")
+	rm.notifyRunFinishedLocked(record, reason)
 }
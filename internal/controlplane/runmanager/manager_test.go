@@ -1,7 +1,10 @@
 package runmanager
 
 import (
+	"bytes"
+	"compress/gzip"
 	"encoding/json"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -48,6 +51,90 @@ func createValidConfig() []byte {
 	return data
 }
 
+// createValidConfigWithSLOs returns the fixture config with a latency SLO
+// and an error rate SLO on the echo tool.
+func createValidConfigWithSLOs(t *testing.T) []byte {
+	t.Helper()
+
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(createValidConfig(), &parsed); err != nil {
+		t.Fatalf("failed to parse config fixture: %v", err)
+	}
+	parsed["slos"] = []interface{}{
+		map[string]interface{}{"name": "latency", "objective": "p95_latency_ms < 500"},
+		map[string]interface{}{"name": "echo errors", "objective": "error_rate < 1%", "tool_name": "echo"},
+	}
+	data, err := json.Marshal(parsed)
+	if err != nil {
+		t.Fatalf("failed to encode config: %v", err)
+	}
+	return data
+}
+
+// opLogTelemetryStore also writes a raw operation log, one line per
+// operation.
+type opLogTelemetryStore struct {
+	*mockTelemetryStore
+}
+
+func (s *opLogTelemetryStore) WriteOperationLog(runID string, w io.Writer) (int, error) {
+	data, err := s.GetTelemetryData(runID)
+	if err != nil {
+		return 0, err
+	}
+	enc := json.NewEncoder(w)
+	for i, op := range data.Operations {
+		if err := enc.Encode(op); err != nil {
+			return i, err
+		}
+	}
+	return len(data.Operations), nil
+}
+
+// spillingTelemetryStore keeps some operations out of TelemetryData, as a
+// store that spilled them to disk does.
+type spillingTelemetryStore struct {
+	*mockTelemetryStore
+	spilled map[string][]analysis.OperationResult
+}
+
+func (s *spillingTelemetryStore) ForEachOperation(runID string, fn func(analysis.OperationResult) error) error {
+	for _, op := range s.spilled[runID] {
+		if err := fn(op); err != nil {
+			return err
+		}
+	}
+	for _, op := range s.data[runID].Operations {
+		if err := fn(op); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// aggregatingTelemetryStore keeps aggregates by stage ("" for the whole run)
+// instead of operations, as a store in aggregation mode does.
+type aggregatingTelemetryStore struct {
+	*mockTelemetryStore
+	aggregates map[string]*analysis.Aggregator
+}
+
+func (s *aggregatingTelemetryStore) MergeOperations(runID, stage string, a *analysis.Aggregator) (bool, error) {
+	if agg := s.aggregates[stage]; agg != nil {
+		a.Merge(agg)
+	}
+	return true, nil
+}
+
+// signingArtifactStore is a filesystem store that also signs download URLs.
+type signingArtifactStore struct {
+	*artifacts.FilesystemStore
+}
+
+func (s *signingArtifactStore) SignedURL(runID string, artifactType artifacts.ArtifactType, filename string, expires time.Duration) (string, error) {
+	return "https://signed.example/" + filename + "?expires=" + expires.String(), nil
+}
+
 func createValidConfigWithDurations(t *testing.T, preflightMs, baselineMs, rampMs int64) []byte {
 	t.Helper()
 
@@ -1129,6 +1216,319 @@ func TestAnalyzeRun(t *testing.T) {
 		}
 	})
 
+	t.Run("slo verdict", func(t *testing.T) {
+		rm := NewRunManager(validator)
+		artifactStore, _ := artifacts.NewFilesystemStore(t.TempDir())
+		rm.SetArtifactStore(artifactStore)
+		telemetryStore := &mockTelemetryStore{data: make(map[string]*TelemetryData)}
+		rm.SetTelemetryStore(telemetryStore)
+
+		runID, err := rm.CreateRun(createValidConfigWithSLOs(t), "test-user")
+		if err != nil {
+			t.Fatalf("CreateRun failed: %v", err)
+		}
+		_ = rm.StartRun(runID, "test-user")
+		_ = rm.RequestStop(runID, StopModeDrain, "test-user")
+		telemetryStore.data[runID] = &TelemetryData{
+			RunID:       runID,
+			StartTimeMs: 1000,
+			EndTimeMs:   2000,
+			Operations: []analysis.OperationResult{
+				{Operation: "tools_call", ToolName: "echo", LatencyMs: 100, OK: true},
+				{Operation: "tools_call", ToolName: "echo", LatencyMs: 150, OK: false, ErrorType: "timeout"},
+			},
+		}
+
+		if err := rm.TransitionToAnalyzing(runID, "system"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		view, _ := rm.GetRun(runID)
+		if view.SLOs == nil || view.SLOs.Verdict != analysis.SLOVerdictFail || len(view.SLOs.Results) != 2 {
+			t.Fatalf("expected a FAIL verdict with 2 results, got %+v", view.SLOs)
+		}
+		if !view.SLOs.Results[0].Passed || view.SLOs.Results[1].Passed {
+			t.Errorf("expected latency SLO to pass and error SLO to fail, got %+v", view.SLOs.Results)
+		}
+
+		events, _ := rm.TailEvents(runID, 0, 100)
+		last := events[len(events)-1]
+		var payload map[string]interface{}
+		_ = json.Unmarshal(last.Payload, &payload)
+		if last.Type != EventTypeStateTransition || payload["to_state"] != string(RunStateCompleted) || payload["slo_verdict"] != analysis.SLOVerdictFail {
+			t.Errorf("expected terminal transition to carry the verdict, got %s %v", last.Type, payload)
+		}
+	})
+
+	t.Run("ci report formats", func(t *testing.T) {
+		rm := NewRunManager(validator)
+		artifactStore, _ := artifacts.NewFilesystemStore(t.TempDir())
+		rm.SetArtifactStore(artifactStore)
+		telemetryStore := &mockTelemetryStore{data: make(map[string]*TelemetryData)}
+		rm.SetTelemetryStore(telemetryStore)
+
+		var parsed map[string]interface{}
+		_ = json.Unmarshal(createValidConfigWithSLOs(t), &parsed)
+		parsed["reporting"].(map[string]interface{})["formats"] = []string{"json", "junit", "sarif"}
+		config, _ := json.Marshal(parsed)
+
+		runID, err := rm.CreateRun(config, "test-user")
+		if err != nil {
+			t.Fatalf("CreateRun failed: %v", err)
+		}
+		_ = rm.StartRun(runID, "test-user")
+		_ = rm.RequestStop(runID, StopModeDrain, "test-user")
+		telemetryStore.data[runID] = &TelemetryData{
+			RunID:       runID,
+			StartTimeMs: 1000,
+			EndTimeMs:   2000,
+			Operations: []analysis.OperationResult{
+				{Operation: "tools_call", ToolName: "echo", LatencyMs: 100, OK: false, ErrorType: "timeout"},
+			},
+		}
+
+		if err := rm.TransitionToAnalyzing(runID, "system"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		artifactsList, _ := artifactStore.ListArtifacts(runID)
+		if len(artifactsList) != 4 {
+			t.Fatalf("expected 4 artifacts, got %d", len(artifactsList))
+		}
+		junit, err := artifactStore.GetArtifact(runID, artifacts.ArtifactTypeReport, "report.junit.xml")
+		if err != nil {
+			t.Fatalf("expected report.junit.xml artifact: %v", err)
+		}
+		if !strings.Contains(string(junit), `<testsuite name="slos" tests="2" failures="1"`) {
+			t.Errorf("unexpected JUnit report:\n%s", junit)
+		}
+		if _, err := artifactStore.GetArtifact(runID, artifacts.ArtifactTypeReport, "report.sarif"); err != nil {
+			t.Errorf("expected report.sarif artifact: %v", err)
+		}
+
+		events, _ := rm.TailEvents(runID, 0, 100)
+		for _, e := range events {
+			if e.Type == EventTypeReportGenerated && len(e.Evidence) != 4 {
+				t.Errorf("expected 4 evidence items, got %d", len(e.Evidence))
+			}
+		}
+	})
+
+	t.Run("signed report urls", func(t *testing.T) {
+		rm := NewRunManager(validator)
+		fsStore, _ := artifacts.NewFilesystemStore(t.TempDir())
+		rm.SetArtifactStore(&signingArtifactStore{FilesystemStore: fsStore})
+		telemetryStore := &mockTelemetryStore{data: make(map[string]*TelemetryData)}
+		rm.SetTelemetryStore(telemetryStore)
+
+		runID, _ := rm.CreateRun(createValidConfig(), "test-user")
+		_ = rm.StartRun(runID, "test-user")
+		_ = rm.RequestStop(runID, StopModeDrain, "test-user")
+		telemetryStore.data[runID] = &TelemetryData{RunID: runID, StartTimeMs: 1000, EndTimeMs: 2000}
+
+		if err := rm.TransitionToAnalyzing(runID, "system"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		events, _ := rm.TailEvents(runID, 0, 100)
+		for _, e := range events {
+			if e.Type != EventTypeReportGenerated {
+				continue
+			}
+			var payload struct {
+				Reports []struct {
+					Filename string `json:"filename"`
+					URL      string `json:"url"`
+				} `json:"reports"`
+			}
+			_ = json.Unmarshal(e.Payload, &payload)
+			for _, r := range payload.Reports {
+				if r.URL != "https://signed.example/"+r.Filename+"?expires=168h0m0s" {
+					t.Errorf("unexpected URL for %s: %q", r.Filename, r.URL)
+				}
+			}
+			return
+		}
+		t.Error("expected REPORT_GENERATED event")
+	})
+
+	t.Run("raw operation log", func(t *testing.T) {
+		rm := NewRunManager(validator)
+		artifactStore, _ := artifacts.NewFilesystemStore(t.TempDir())
+		rm.SetArtifactStore(artifactStore)
+		telemetryStore := &opLogTelemetryStore{mockTelemetryStore: &mockTelemetryStore{data: make(map[string]*TelemetryData)}}
+		rm.SetTelemetryStore(telemetryStore)
+
+		runID, _ := rm.CreateRun(createValidConfig(), "test-user")
+		_ = rm.StartRun(runID, "test-user")
+		_ = rm.RequestStop(runID, StopModeDrain, "test-user")
+		telemetryStore.data[runID] = &TelemetryData{
+			RunID:       runID,
+			StartTimeMs: 1000,
+			EndTimeMs:   2000,
+			Operations: []analysis.OperationResult{
+				{Operation: "tools_call", ToolName: "echo", LatencyMs: 100, OK: true},
+				{Operation: "tools_call", ToolName: "echo", LatencyMs: 150, OK: false, ErrorType: "timeout"},
+			},
+		}
+
+		if err := rm.TransitionToAnalyzing(runID, "system"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		data, err := artifactStore.GetArtifact(runID, artifacts.ArtifactTypeTelemetry, OperationLogFilename)
+		if err != nil {
+			t.Fatalf("expected operation log artifact: %v", err)
+		}
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("operation log is not gzipped: %v", err)
+		}
+		raw, _ := io.ReadAll(gz)
+		if lines := strings.Count(string(raw), "\n"); lines != 2 {
+			t.Errorf("expected 2 JSON lines, got %d: %s", lines, raw)
+		}
+
+		events, _ := rm.TailEvents(runID, 0, 100)
+		found := false
+		for _, e := range events {
+			if e.Type == EventTypeArtifactStored && strings.Contains(string(e.Payload), `"operations":2`) {
+				found = true
+			}
+		}
+		if !found {
+			t.Error("expected ARTIFACT_STORED event for the operation log")
+		}
+	})
+
+	t.Run("spilled operations", func(t *testing.T) {
+		rm := NewRunManager(validator)
+		artifactStore, _ := artifacts.NewFilesystemStore(t.TempDir())
+		rm.SetArtifactStore(artifactStore)
+		telemetryStore := &spillingTelemetryStore{
+			mockTelemetryStore: &mockTelemetryStore{data: make(map[string]*TelemetryData)},
+			spilled:            make(map[string][]analysis.OperationResult),
+		}
+		rm.SetTelemetryStore(telemetryStore)
+
+		runID, _ := rm.CreateRun(createValidConfig(), "test-user")
+		_ = rm.StartRun(runID, "test-user")
+		_ = rm.RequestStop(runID, StopModeDrain, "test-user")
+		telemetryStore.spilled[runID] = []analysis.OperationResult{
+			{Operation: "tools_call", ToolName: "echo", LatencyMs: 100, OK: true},
+			{Operation: "tools_call", ToolName: "echo", LatencyMs: 120, OK: false, ErrorType: "timeout"},
+			{Operation: "tools_call", ToolName: "echo", LatencyMs: 140, OK: true},
+		}
+		telemetryStore.data[runID] = &TelemetryData{
+			RunID:       runID,
+			StartTimeMs: 1000,
+			EndTimeMs:   2000,
+			Operations: []analysis.OperationResult{
+				{Operation: "tools_list", LatencyMs: 50, OK: true},
+			},
+			SpilledOperations: 3,
+		}
+
+		if err := rm.TransitionToAnalyzing(runID, "system"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		data, err := artifactStore.GetArtifact(runID, artifacts.ArtifactTypeReport, "report.json")
+		if err != nil {
+			t.Fatalf("expected report.json: %v", err)
+		}
+		var report analysis.Report
+		if err := json.Unmarshal(data, &report); err != nil {
+			t.Fatalf("failed to decode report: %v", err)
+		}
+		if report.Metrics.TotalOps != 4 || report.Metrics.FailureOps != 1 {
+			t.Errorf("expected 4 operations with 1 failure, got %d with %d", report.Metrics.TotalOps, report.Metrics.FailureOps)
+		}
+	})
+
+	t.Run("aggregated operations", func(t *testing.T) {
+		rm := NewRunManager(validator)
+		artifactStore, _ := artifacts.NewFilesystemStore(t.TempDir())
+		rm.SetArtifactStore(artifactStore)
+		aggregate := analysis.NewAggregator()
+		for _, latency := range []int{100, 200, 300} {
+			aggregate.AddOperation(analysis.OperationResult{Operation: "tools_call", ToolName: "echo", LatencyMs: latency, OK: latency != 200})
+		}
+		telemetryStore := &aggregatingTelemetryStore{
+			mockTelemetryStore: &mockTelemetryStore{data: make(map[string]*TelemetryData)},
+			aggregates:         map[string]*analysis.Aggregator{"": aggregate},
+		}
+		rm.SetTelemetryStore(telemetryStore)
+
+		runID, _ := rm.CreateRun(createValidConfig(), "test-user")
+		_ = rm.StartRun(runID, "test-user")
+		_ = rm.RequestStop(runID, StopModeDrain, "test-user")
+		telemetryStore.data[runID] = &TelemetryData{
+			RunID:             runID,
+			StartTimeMs:       1000,
+			EndTimeMs:         2000,
+			DroppedOperations: 3,
+		}
+
+		if err := rm.TransitionToAnalyzing(runID, "system"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		data, err := artifactStore.GetArtifact(runID, artifacts.ArtifactTypeReport, "report.json")
+		if err != nil {
+			t.Fatalf("expected report.json: %v", err)
+		}
+		var report analysis.Report
+		if err := json.Unmarshal(data, &report); err != nil {
+			t.Fatalf("failed to decode report: %v", err)
+		}
+		if report.Metrics.TotalOps != 3 || report.Metrics.FailureOps != 1 || report.Metrics.LatencyP99 != 300 {
+			t.Errorf("expected 3 operations with 1 failure and p99 300, got %+v", report.Metrics)
+		}
+	})
+
+	t.Run("load timeline", func(t *testing.T) {
+		rm := NewRunManager(validator)
+		artifactStore, _ := artifacts.NewFilesystemStore(t.TempDir())
+		rm.SetArtifactStore(artifactStore)
+		telemetryStore := &mockTelemetryStore{data: make(map[string]*TelemetryData)}
+		rm.SetTelemetryStore(telemetryStore)
+
+		runID, _ := rm.CreateRun(createValidConfig(), "test-user")
+		_ = rm.StartRun(runID, "test-user")
+		_ = rm.RequestStop(runID, StopModeDrain, "test-user")
+		telemetryStore.data[runID] = &TelemetryData{
+			RunID:       runID,
+			StartTimeMs: 1000,
+			EndTimeMs:   30000,
+			Operations: []analysis.OperationResult{
+				{Operation: "ping", LatencyMs: 10, OK: true, TimestampMs: 1000},
+				{Operation: "ping", LatencyMs: 30, OK: false, ErrorType: "timeout", TimestampMs: 5000},
+				{Operation: "ping", LatencyMs: 50, OK: true, TimestampMs: 25000},
+			},
+		}
+
+		if err := rm.TransitionToAnalyzing(runID, "system"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		data, err := artifactStore.GetArtifact(runID, artifacts.ArtifactTypeReport, "report.json")
+		if err != nil {
+			t.Fatalf("expected report.json: %v", err)
+		}
+		var report analysis.Report
+		if err := json.Unmarshal(data, &report); err != nil {
+			t.Fatalf("failed to decode report: %v", err)
+		}
+		// Without server telemetry the load is still reported.
+		if len(report.Load) != 2 || report.Load[0].Ops != 2 || report.Load[0].Failures != 1 || report.Load[1].Ops != 1 {
+			t.Errorf("expected the load timeline from the aggregator, got %+v", report.Load)
+		}
+		if report.ServerMetrics != nil {
+			t.Errorf("expected no server metrics, got %+v", report.ServerMetrics)
+		}
+	})
+
 	t.Run("run not found", func(t *testing.T) {
 		rm := NewRunManager(validator)
 		err := rm.AnalyzeRun("nonexistent")
@@ -0,0 +1,276 @@
+package runmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/scheduler"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+// MaxOpMixWeight is the largest weight accepted for a single op mix entry,
+// matching the bound in the run-config schema.
+const MaxOpMixWeight = 100000
+
+// OpMixWeight sets the weight of the op mix entries that match Operation and,
+// when given, ToolName, URI and PromptName. A weight of 0 pauses the entries.
+type OpMixWeight struct {
+	Operation  string `json:"operation"`
+	ToolName   string `json:"tool_name,omitempty"`
+	URI        string `json:"uri,omitempty"`
+	PromptName string `json:"prompt_name,omitempty"`
+	Weight     int    `json:"weight"`
+}
+
+// OpMixView is the op mix currently in effect for a run. Revision is 0 until the
+// mix is re-weighted and increases by one with every accepted update.
+type OpMixView struct {
+	RunID    string             `json:"run_id"`
+	Revision int64              `json:"revision"`
+	OpMix    []types.OpMixEntry `json:"op_mix"`
+}
+
+// GetOpMix returns the op mix currently in effect for a run.
+func (rm *RunManager) GetOpMix(runID string) (*OpMixView, error) {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	record, ok := rm.runs[runID]
+	if !ok {
+		return nil, NewNotFoundError(runID)
+	}
+
+	opMix, revision, err := effectiveOpMixLocked(record)
+	if err != nil {
+		return nil, NewInternalError(runID, err)
+	}
+
+	return &OpMixView{RunID: runID, Revision: revision, OpMix: opMix}, nil
+}
+
+// UpdateOpMix re-weights the op mix of a running run. The new weights apply to
+// assignments dispatched from now on and are pushed to the workers holding
+// active leases for the run as in-place workload updates.
+func (rm *RunManager) UpdateOpMix(runID string, weights []OpMixWeight, actor string) (*OpMixView, error) {
+	var (
+		view             *OpMixView
+		executionID      string
+		eventLog         *EventLog
+		correlation      CorrelationContext
+		leaseManager     *scheduler.LeaseManager
+		assignmentSender AssignmentSender
+		ownMixGroups     map[string]bool
+		err              error
+	)
+
+	func() {
+		rm.mu.Lock()
+		defer rm.mu.Unlock()
+
+		record, ok := rm.runs[runID]
+		if !ok {
+			err = NewNotFoundError(runID)
+			return
+		}
+
+		if record.State == RunStateCompleted || record.State == RunStateFailed || record.State == RunStateAborted {
+			err = NewTerminalStateError(runID, record.State, "update op mix")
+			return
+		}
+		if !isRunningState(record.State) {
+			err = &RunManagerError{
+				Kind:    ErrKindInvalidState,
+				RunID:   runID,
+				State:   record.State,
+				Message: fmt.Sprintf("cannot update op mix of run in state %s: run is not running", record.State),
+			}
+			return
+		}
+
+		current, revision, parseErr := effectiveOpMixLocked(record)
+		if parseErr != nil {
+			err = NewInternalError(runID, parseErr)
+			return
+		}
+
+		updated, applyErr := applyOpMixWeights(current, weights)
+		if applyErr != nil {
+			err = NewInvalidArgumentError(runID, applyErr)
+			return
+		}
+
+		record.opMix = updated
+		record.opMixRevision = revision + 1
+
+		view = &OpMixView{RunID: runID, Revision: record.opMixRevision, OpMix: updated}
+		executionID = record.ExecutionID
+		eventLog = rm.eventLogs[runID]
+		if record.ActiveStage != nil {
+			stageName := StageName(record.ActiveStage.Stage)
+			stageID := record.ActiveStage.StageID
+			correlation.Stage = &stageName
+			correlation.StageID = &stageID
+		}
+		leaseManager = rm.leaseManager
+		assignmentSender = rm.assignmentSender
+		if parsedConfig, parseErr := parseRunConfig(record.Config); parseErr == nil {
+			ownMixGroups = ownOpMixGroups(parsedConfig)
+		}
+	}()
+
+	if err != nil {
+		return nil, err
+	}
+
+	payload, _ := json.Marshal(map[string]interface{}{
+		"decision_type": "op_mix_updated",
+		"actor":         actor,
+		"revision":      view.Revision,
+		"weights":       weights,
+		"op_mix":        view.OpMix,
+	})
+	event := RunEvent{
+		RunID:       runID,
+		ExecutionID: executionID,
+		Type:        EventTypeDecision,
+		Actor:       ActorType(actor),
+		Correlation: correlation,
+		Payload:     payload,
+		Evidence:    []Evidence{},
+	}
+	appendEventWithLog(eventLog, event, "UpdateOpMix")
+
+	pushed := 0
+	if leaseManager != nil && assignmentSender != nil {
+		pushed = pushOpMixUpdate(leaseManager, assignmentSender, executionID, view, ownMixGroups)
+	}
+	log.Printf("[RunManager] Op mix for run %s updated to revision %d by %s (pushed to %d lease(s))", runID, view.Revision, actor, pushed)
+
+	return view, nil
+}
+
+// pushOpMixUpdate sends a workload update for every active lease of the run,
+// except leases of generator groups in skipGroups, which run their own mix.
+// Returns the number of leases updated.
+func pushOpMixUpdate(leaseManager *scheduler.LeaseManager, sender AssignmentSender, executionID string, view *OpMixView, skipGroups map[string]bool) int {
+	pushed := 0
+	for _, lease := range leaseManager.ListLeases(view.RunID) {
+		if lease.State != scheduler.LeaseStateActive || skipGroups[lease.Assignment.Group] {
+			continue
+		}
+		sender.AddAssignment(string(lease.WorkerID), types.WorkerAssignment{
+			RunID:            view.RunID,
+			ExecutionID:      executionID,
+			StageID:          lease.Assignment.StageID,
+			LeaseID:          string(lease.LeaseID),
+			VUIDStart:        lease.Assignment.VUIDRange.Start,
+			VUIDEnd:          lease.Assignment.VUIDRange.End,
+			Workload:         types.WorkloadConfig{OpMix: view.OpMix},
+			WorkloadRevision: view.Revision,
+			WorkloadUpdate:   true,
+		})
+		pushed++
+	}
+	return pushed
+}
+
+// effectiveOpMixLocked returns the op mix and revision to use for a run's
+// assignments: the live override if one was set, otherwise the configured mix.
+// Must be called with rm.mu held.
+func effectiveOpMixLocked(record *RunRecord) ([]types.OpMixEntry, int64, error) {
+	if record.opMix != nil {
+		return copyOpMix(record.opMix), record.opMixRevision, nil
+	}
+
+	parsedConfig, err := parseRunConfig(record.Config)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to parse config: %w", err)
+	}
+	return convertOpMix(parsedConfig.Workload.OpMix), 0, nil
+}
+
+// assignmentOpMix returns the op mix and revision for a new assignment of the run,
+// falling back to the parsed config if the run has no live override.
+func (rm *RunManager) assignmentOpMix(runID string, parsedConfig *parsedRunConfig) ([]types.OpMixEntry, int64) {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	return rm.assignmentOpMixLocked(runID, parsedConfig)
+}
+
+// assignmentOpMixLocked is assignmentOpMix for callers holding rm.mu.
+func (rm *RunManager) assignmentOpMixLocked(runID string, parsedConfig *parsedRunConfig) ([]types.OpMixEntry, int64) {
+	if record, ok := rm.runs[runID]; ok && record.opMix != nil {
+		return copyOpMix(record.opMix), record.opMixRevision
+	}
+	return convertOpMix(parsedConfig.Workload.OpMix), 0
+}
+
+// applyOpMixWeights validates weight updates against the current op mix and
+// returns the re-weighted copy. Updates may only re-weight existing entries;
+// adding operations mid-run would bypass run config validation.
+func applyOpMixWeights(current []types.OpMixEntry, weights []OpMixWeight) ([]types.OpMixEntry, error) {
+	if len(weights) == 0 {
+		return nil, fmt.Errorf("at least one weight update is required")
+	}
+
+	updated := copyOpMix(current)
+	for i, w := range weights {
+		if w.Operation == "" {
+			return nil, fmt.Errorf("weights[%d]: operation is required", i)
+		}
+		if w.Weight < 0 || w.Weight > MaxOpMixWeight {
+			return nil, fmt.Errorf("weights[%d]: weight %d out of range [0, %d]", i, w.Weight, MaxOpMixWeight)
+		}
+
+		operation := normalizeOperationName(w.Operation)
+		matched := false
+		for j := range updated {
+			entry := &updated[j]
+			if entry.Operation != operation {
+				continue
+			}
+			if (w.ToolName != "" && entry.ToolName != w.ToolName) ||
+				(w.URI != "" && entry.URI != w.URI) ||
+				(w.PromptName != "" && entry.PromptName != w.PromptName) {
+				continue
+			}
+			entry.Weight = w.Weight
+			matched = true
+		}
+		if !matched {
+			return nil, fmt.Errorf("weights[%d]: no op_mix entry matches operation %q%s", i, w.Operation, opMixSelectorSuffix(w))
+		}
+	}
+
+	total := 0
+	for _, entry := range updated {
+		total += entry.Weight
+	}
+	if total <= 0 {
+		return nil, fmt.Errorf("op_mix total weight must be > 0")
+	}
+
+	return updated, nil
+}
+
+func opMixSelectorSuffix(w OpMixWeight) string {
+	switch {
+	case w.ToolName != "":
+		return fmt.Sprintf(" with tool_name %q", w.ToolName)
+	case w.URI != "":
+		return fmt.Sprintf(" with uri %q", w.URI)
+	case w.PromptName != "":
+		return fmt.Sprintf(" with prompt_name %q", w.PromptName)
+	default:
+		return ""
+	}
+}
+
+// copyOpMix returns a shallow copy of the entries so weights can be changed
+// without affecting assignments that were already dispatched.
+func copyOpMix(entries []types.OpMixEntry) []types.OpMixEntry {
+	result := make([]types.OpMixEntry, len(entries))
+	copy(result, entries)
+	return result
+}
@@ -0,0 +1,170 @@
+package runmanager
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/scheduler"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+func TestApplyOpMixWeights(t *testing.T) {
+	current := []types.OpMixEntry{
+		{Operation: "tools/list", Weight: 1},
+		{Operation: "tools/call", Weight: 10, ToolName: "echo"},
+		{Operation: "tools/call", Weight: 5, ToolName: "slow"},
+	}
+
+	tests := []struct {
+		name     string
+		weights  []OpMixWeight
+		expected []int
+		wantErr  bool
+	}{
+		{"reweights single entry", []OpMixWeight{{Operation: "tools/list", Weight: 7}}, []int{7, 10, 5}, false},
+		{"accepts config operation names", []OpMixWeight{{Operation: "tools_list", Weight: 3}}, []int{3, 10, 5}, false},
+		{"selects by tool name", []OpMixWeight{{Operation: "tools/call", ToolName: "slow", Weight: 0}}, []int{1, 10, 0}, false},
+		{"operation without selector matches all", []OpMixWeight{{Operation: "tools/call", Weight: 2}}, []int{1, 2, 2}, false},
+		{"empty update", nil, nil, true},
+		{"missing operation", []OpMixWeight{{Weight: 1}}, nil, true},
+		{"negative weight", []OpMixWeight{{Operation: "tools/list", Weight: -1}}, nil, true},
+		{"weight above bound", []OpMixWeight{{Operation: "tools/list", Weight: MaxOpMixWeight + 1}}, nil, true},
+		{"unknown operation", []OpMixWeight{{Operation: "prompts/list", Weight: 1}}, nil, true},
+		{"unknown tool", []OpMixWeight{{Operation: "tools/call", ToolName: "missing", Weight: 1}}, nil, true},
+		{"zero total weight", []OpMixWeight{{Operation: "tools/list", Weight: 0}, {Operation: "tools/call", Weight: 0}}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated, err := applyOpMixWeights(current, tt.weights)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got mix %+v", updated)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for i, weight := range tt.expected {
+				if updated[i].Weight != weight {
+					t.Errorf("entry %d: expected weight %d, got %d", i, weight, updated[i].Weight)
+				}
+			}
+		})
+	}
+
+	if current[0].Weight != 1 || current[1].Weight != 10 || current[2].Weight != 5 {
+		t.Errorf("expected current mix to be left untouched, got %+v", current)
+	}
+}
+
+func TestUpdateOpMix_PushesToActiveLeases(t *testing.T) {
+	rm := NewRunManager(createTestValidatorForWorkerTest(t))
+
+	registry := scheduler.NewRegistry()
+	lm := scheduler.NewLeaseManager(60000)
+	allocator := scheduler.NewAllocator(registry, lm)
+	rm.SetScheduler(registry, allocator, lm)
+
+	mockSender := &mockAssignmentSender{assignments: make(map[string][]types.WorkerAssignment)}
+	rm.SetAssignmentSender(mockSender)
+
+	wid, _ := registry.RegisterWorker(types.HostInfo{Hostname: "host1"}, types.WorkerCapacity{MaxVUs: 50})
+
+	runID := createTestRunWithPolicy(t, rm, "fail_fast")
+	setRunState(t, rm, runID, RunStateBaselineRunning)
+	setActiveStage(t, rm, runID, "baseline", "stg_000000000002")
+
+	leaseID, err := lm.IssueLease(wid, scheduler.Assignment{
+		RunID:     runID,
+		StageID:   "stg_000000000002",
+		VUIDRange: scheduler.VUIDRange{Start: 0, End: 5},
+	})
+	if err != nil {
+		t.Fatalf("IssueLease failed: %v", err)
+	}
+
+	view, err := rm.UpdateOpMix(runID, []OpMixWeight{{Operation: "tools/list", Weight: 4}}, "test-user")
+	if err != nil {
+		t.Fatalf("UpdateOpMix failed: %v", err)
+	}
+	if view.Revision != 1 {
+		t.Errorf("expected revision 1, got %d", view.Revision)
+	}
+
+	pushed := mockSender.assignments[string(wid)]
+	if len(pushed) != 1 {
+		t.Fatalf("expected 1 workload update, got %d", len(pushed))
+	}
+	update := pushed[0]
+	if !update.WorkloadUpdate || update.LeaseID != string(leaseID) || update.WorkloadRevision != 1 {
+		t.Errorf("unexpected workload update: %+v", update)
+	}
+	if update.Workload.OpMix[0].Operation != "tools/list" || update.Workload.OpMix[0].Weight != 4 {
+		t.Errorf("expected tools/list weight 4 in update, got %+v", update.Workload.OpMix)
+	}
+
+	current, err := rm.GetOpMix(runID)
+	if err != nil {
+		t.Fatalf("GetOpMix failed: %v", err)
+	}
+	if current.Revision != 1 || current.OpMix[0].Weight != 4 {
+		t.Errorf("expected GetOpMix to return revision 1 with updated weight, got %+v", current)
+	}
+
+	parsedConfig, _ := parseRunConfig(rm.runs[runID].Config)
+	opMix, revision := rm.assignmentOpMix(runID, parsedConfig)
+	if revision != 1 || opMix[0].Weight != 4 {
+		t.Errorf("expected new assignments to use the live op mix, got revision %d mix %+v", revision, opMix)
+	}
+
+	events, _ := rm.TailEvents(runID, 0, 100)
+	found := false
+	for _, event := range events {
+		if event.Type != EventTypeDecision {
+			continue
+		}
+		var payload map[string]interface{}
+		_ = json.Unmarshal(event.Payload, &payload)
+		if payload["decision_type"] == "op_mix_updated" && payload["revision"] == float64(1) {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected DECISION event with op_mix_updated")
+	}
+}
+
+func TestUpdateOpMix_Rejections(t *testing.T) {
+	rm := NewRunManager(createTestValidatorForWorkerTest(t))
+	runID := createTestRunWithPolicy(t, rm, "fail_fast")
+
+	weights := []OpMixWeight{{Operation: "tools/list", Weight: 2}}
+
+	if _, err := rm.UpdateOpMix("run_missing", weights, "test-user"); !IsNotFound(err) {
+		t.Errorf("expected not found error, got %v", err)
+	}
+
+	if _, err := rm.UpdateOpMix(runID, weights, "test-user"); !IsInvalidState(err) {
+		t.Errorf("expected invalid state error for created run, got %v", err)
+	}
+
+	setRunState(t, rm, runID, RunStateRampRunning)
+	if _, err := rm.UpdateOpMix(runID, []OpMixWeight{{Operation: "tools/list", Weight: -5}}, "test-user"); !IsInvalidArgument(err) {
+		t.Errorf("expected invalid argument error, got %v", err)
+	}
+
+	view, err := rm.GetOpMix(runID)
+	if err != nil {
+		t.Fatalf("GetOpMix failed: %v", err)
+	}
+	if view.Revision != 0 {
+		t.Errorf("expected rejected updates to leave revision 0, got %d", view.Revision)
+	}
+
+	setRunState(t, rm, runID, RunStateCompleted)
+	if _, err := rm.UpdateOpMix(runID, weights, "test-user"); !IsTerminalState(err) {
+		t.Errorf("expected terminal state error, got %v", err)
+	}
+}
@@ -0,0 +1,219 @@
+package runmanager
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/validation"
+)
+
+// RunParameters adjust a stored run config for one run, so a single config
+// can drive several targets and sizes. Safety hard caps are not scaled, so
+// they still bound a scaled-up run.
+type RunParameters struct {
+	// TargetURL replaces target.url.
+	TargetURL string `json:"target_url,omitempty"`
+	// Headers are added to target.headers, replacing headers of the same
+	// name.
+	Headers map[string]string `json:"headers,omitempty"`
+	// VUMultiplier scales every stage's VU counts: load.target_vus,
+	// load.start_vus, steps[].target_vus, ramp.step_vus and ramp.max_vus.
+	VUMultiplier float64 `json:"vu_multiplier,omitempty"`
+	// DurationMultiplier scales every stage's duration_ms, max_duration_ms
+	// and hold times.
+	DurationMultiplier float64 `json:"duration_multiplier,omitempty"`
+}
+
+// IsZero reports whether p changes nothing.
+func (p *RunParameters) IsZero() bool {
+	return p == nil || (p.TargetURL == "" && len(p.Headers) == 0 && p.VUMultiplier == 0 && p.DurationMultiplier == 0)
+}
+
+// Apply returns config with the parameters applied. It does not validate
+// the result.
+func (p *RunParameters) Apply(config []byte) ([]byte, error) {
+	if p.IsZero() {
+		return config, nil
+	}
+	if p.VUMultiplier < 0 || math.IsNaN(p.VUMultiplier) || math.IsInf(p.VUMultiplier, 0) {
+		return nil, errors.New("vu_multiplier must be a positive number")
+	}
+	if p.DurationMultiplier < 0 || math.IsNaN(p.DurationMultiplier) || math.IsInf(p.DurationMultiplier, 0) {
+		return nil, errors.New("duration_multiplier must be a positive number")
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(config, &doc); err != nil {
+		return nil, fmt.Errorf("invalid config JSON: %w", err)
+	}
+
+	if p.TargetURL != "" || len(p.Headers) > 0 {
+		target, _ := doc["target"].(map[string]interface{})
+		if target == nil {
+			return nil, errors.New("config has no target to parameterize")
+		}
+		if p.TargetURL != "" {
+			target["url"] = p.TargetURL
+		}
+		if len(p.Headers) > 0 {
+			headers, _ := target["headers"].(map[string]interface{})
+			if headers == nil {
+				headers = make(map[string]interface{})
+				target["headers"] = headers
+			}
+			for name, value := range p.Headers {
+				headers[name] = value
+			}
+		}
+	}
+
+	stages, _ := doc["stages"].([]interface{})
+	for _, s := range stages {
+		stage, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if p.VUMultiplier > 0 {
+			scaleFields(objectField(stage, "load"), p.VUMultiplier, 1, "target_vus", "start_vus")
+			scaleFields(objectField(stage, "ramp"), p.VUMultiplier, 1, "step_vus", "max_vus")
+			for _, step := range arrayField(stage, "steps") {
+				scaleFields(step, p.VUMultiplier, 1, "target_vus")
+			}
+		}
+		if p.DurationMultiplier > 0 {
+			scaleFields(stage, p.DurationMultiplier, 1000, "duration_ms", "max_duration_ms")
+			scaleFields(objectField(stage, "load"), p.DurationMultiplier, 1000, "step_hold_ms")
+			scaleFields(objectField(stage, "ramp"), p.DurationMultiplier, 1000, "step_every_ms", "hold_ms")
+			scaleFields(objectField(stage, "spike"), p.DurationMultiplier, 1000, "hold_ms")
+			for _, step := range arrayField(stage, "steps") {
+				scaleFields(step, p.DurationMultiplier, 1000, "hold_ms")
+			}
+		}
+	}
+
+	return json.Marshal(doc)
+}
+
+// scaleFields multiplies the positive numeric fields of obj by factor,
+// rounding to whole numbers no smaller than floor.
+func scaleFields(obj map[string]interface{}, factor float64, floor float64, fields ...string) {
+	if obj == nil {
+		return
+	}
+	for _, field := range fields {
+		value, ok := obj[field].(float64)
+		if !ok || value <= 0 {
+			continue
+		}
+		obj[field] = math.Max(math.Round(value*factor), floor)
+	}
+}
+
+func objectField(obj map[string]interface{}, field string) map[string]interface{} {
+	value, _ := obj[field].(map[string]interface{})
+	return value
+}
+
+func arrayField(obj map[string]interface{}, field string) []map[string]interface{} {
+	values, _ := obj[field].([]interface{})
+	result := make([]map[string]interface{}, 0, len(values))
+	for _, v := range values {
+		if m, ok := v.(map[string]interface{}); ok {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// parameterizeConfig applies params to config and validates the result.
+func (rm *RunManager) parameterizeConfig(runID string, config []byte, params *RunParameters) ([]byte, error) {
+	if params.IsZero() {
+		return config, nil
+	}
+	parameterized, err := params.Apply(config)
+	if err != nil {
+		return nil, NewInvalidArgumentError(runID, err)
+	}
+	report := rm.ValidateRunConfig(parameterized)
+	if !report.OK {
+		return nil, &validation.ValidationError{Report: report}
+	}
+	return parameterized, nil
+}
+
+// applyStartParameters replaces the config of a created run with its
+// parameterized version before the run starts.
+func (rm *RunManager) applyStartParameters(runID, actor string, params *RunParameters) error {
+	rm.mu.RLock()
+	record, ok := rm.runs[runID]
+	if !ok {
+		rm.mu.RUnlock()
+		return NewNotFoundError(runID)
+	}
+	if record.State != RunStateCreated {
+		state := record.State
+		rm.mu.RUnlock()
+		return NewInvalidStateError(runID, state, RunStateCreated, "start")
+	}
+	config := append([]byte(nil), record.Config...)
+	configHash := record.ConfigHash
+	rm.mu.RUnlock()
+
+	parameterized, err := rm.parameterizeConfig(runID, config, params)
+	if err != nil {
+		return err
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	record, ok = rm.runs[runID]
+	if !ok {
+		return NewNotFoundError(runID)
+	}
+	if record.State != RunStateCreated || record.ConfigHash != configHash {
+		return NewInvalidStateError(runID, record.State, RunStateCreated, "start")
+	}
+
+	rm.unindexConfigLocked(record)
+	record.Config = parameterized
+	record.ConfigHash = computeConfigHash(parameterized)
+	rm.indexConfigLocked(record)
+	record.ScenarioID = extractScenarioID(parameterized)
+	record.Parameters = params
+	record.UpdatedAtMs = time.Now().UnixMilli()
+
+	payload, _ := json.Marshal(map[string]interface{}{
+		"decision_type": "run_parameters_applied",
+		"parameters":    params,
+		"config_hash":   record.ConfigHash,
+		"actor":         actor,
+	})
+	appendEventWithLog(rm.eventLogs[runID], RunEvent{
+		RunID:       runID,
+		ExecutionID: record.ExecutionID,
+		Type:        EventTypeDecision,
+		Actor:       ActorType(actor),
+		Payload:     payload,
+		Evidence:    []Evidence{},
+	}, "applyStartParameters")
+	return nil
+}
+
+// parametersFromEvent recovers the parameters recorded by a RUN_CREATED
+// event or a run_parameters_applied DECISION event.
+func parametersFromEvent(event RunEvent) *RunParameters {
+	var payload struct {
+		DecisionType string         `json:"decision_type"`
+		Parameters   *RunParameters `json:"parameters"`
+	}
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return nil
+	}
+	if event.Type == EventTypeDecision && payload.DecisionType != "run_parameters_applied" {
+		return nil
+	}
+	return payload.Parameters
+}
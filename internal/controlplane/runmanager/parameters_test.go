@@ -0,0 +1,130 @@
+package runmanager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/validation"
+)
+
+func TestRunParameters_Apply(t *testing.T) {
+	params := &RunParameters{
+		TargetURL:          "https://preprod-gateway.example.com/mcp",
+		Headers:            map[string]string{"X-Env": "preprod"},
+		VUMultiplier:       0.5,
+		DurationMultiplier: 0.1,
+	}
+	data, err := params.Apply(createValidConfig())
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	var config struct {
+		Target struct {
+			URL     string            `json:"url"`
+			Headers map[string]string `json:"headers"`
+		} `json:"target"`
+		Stages []struct {
+			DurationMs int64 `json:"duration_ms"`
+			Load       struct {
+				TargetVUs int `json:"target_vus"`
+			} `json:"load"`
+			Ramp *struct {
+				StepVUs     int   `json:"step_vus"`
+				MaxVUs      int   `json:"max_vus"`
+				StepEveryMs int64 `json:"step_every_ms"`
+			} `json:"ramp"`
+		} `json:"stages"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatalf("failed to parse parameterized config: %v", err)
+	}
+
+	if config.Target.URL != params.TargetURL || config.Target.Headers["X-Env"] != "preprod" {
+		t.Errorf("expected the target to be replaced, got %+v", config.Target)
+	}
+	preflight, ramp := config.Stages[0], config.Stages[2]
+	if preflight.Load.TargetVUs != 3 || preflight.DurationMs != 6000 {
+		t.Errorf("expected preflight scaled to 3 VUs for 6s, got %+v", preflight)
+	}
+	if ramp.Ramp == nil || ramp.Ramp.MaxVUs != 250 || ramp.Ramp.StepVUs != 13 || ramp.Ramp.StepEveryMs != 12000 {
+		t.Errorf("expected the ramp to be scaled, got %+v", ramp.Ramp)
+	}
+
+	if _, err := (&RunParameters{VUMultiplier: -1}).Apply(createValidConfig()); err == nil {
+		t.Error("expected a negative multiplier to be rejected")
+	}
+}
+
+func TestStartRunWithOptions_AppliesParameters(t *testing.T) {
+	store := openTestRunStore(t)
+	validator := createTestValidator(t)
+
+	rm := NewRunManager(validator)
+	rm.SetRunStore(store)
+	runID, err := rm.CreateRun(createValidConfig(), "alice")
+	if err != nil {
+		t.Fatalf("CreateRun failed: %v", err)
+	}
+	before, _ := rm.GetRun(runID)
+
+	var validationErr *validation.ValidationError
+	err = rm.StartRunWithOptions(runID, "alice", StartOptions{Parameters: &RunParameters{TargetURL: "ftp://staging-gateway.example.com/mcp"}})
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected an invalid target URL to fail validation, got %v", err)
+	}
+	if view, _ := rm.GetRun(runID); view.State != RunStateCreated || view.ConfigHash != before.ConfigHash {
+		t.Fatalf("expected the rejected run to be unchanged, got %+v", view)
+	}
+
+	params := &RunParameters{Headers: map[string]string{"X-Env": "staging"}, DurationMultiplier: 0.5}
+	if err := rm.applyStartParameters(runID, "alice", params); err != nil {
+		t.Fatalf("applyStartParameters failed: %v", err)
+	}
+	view, _ := rm.GetRun(runID)
+	if view.Parameters == nil || view.ConfigHash == before.ConfigHash {
+		t.Errorf("expected the run to record its parameters and new config hash, got %+v", view)
+	}
+	events, _ := rm.TailEvents(runID, 0, 100)
+	found := false
+	for _, event := range events {
+		if params := parametersFromEvent(event); event.Type == EventTypeDecision && params != nil {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected a run_parameters_applied DECISION event")
+	}
+	rm.Shutdown()
+
+	restarted := NewRunManager(validator)
+	defer restarted.Shutdown()
+	restarted.SetRunStore(store)
+	if _, err := restarted.RecoverRuns(context.Background()); err != nil {
+		t.Fatalf("RecoverRuns failed: %v", err)
+	}
+	recovered, err := restarted.GetRun(runID)
+	if err != nil || recovered.Parameters == nil || recovered.Parameters.Headers["X-Env"] != "staging" {
+		t.Errorf("expected the recovered run to keep its parameters, got %+v, %v", recovered, err)
+	}
+}
+
+func TestCreateRunWithParameters(t *testing.T) {
+	rm := NewRunManager(createTestValidator(t))
+	defer rm.Shutdown()
+
+	runID, err := rm.CreateRunWithParameters(createValidConfig(), &RunParameters{VUMultiplier: 2}, "alice")
+	if err != nil {
+		t.Fatalf("CreateRunWithParameters failed: %v", err)
+	}
+	view, _ := rm.GetRun(runID)
+	if view.Parameters == nil || view.Parameters.VUMultiplier != 2 {
+		t.Errorf("expected the run to record its parameters, got %+v", view.Parameters)
+	}
+
+	if _, err := rm.CreateRunWithParameters(createValidConfig(), &RunParameters{DurationMultiplier: -2}, "alice"); !IsInvalidArgument(err) {
+		t.Errorf("expected an invalid argument error, got %v", err)
+	}
+}
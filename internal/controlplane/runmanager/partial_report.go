@@ -0,0 +1,153 @@
+package runmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+	"github.com/bc-dunia/mcpdrill/internal/artifacts"
+)
+
+// partialReportNote is shown on every partial report.
+const partialReportNote = "covers the %s stage only and was generated while the run was still in progress. " +
+	"The final report supersedes it."
+
+// partialReportFilename returns the artifact name of a stage's partial report,
+// e.g. report-baseline.json.
+func partialReportFilename(stage StageName, ext string) string {
+	return "report-" + string(stage) + "." + ext
+}
+
+// completeStageReport starts generating the partial report of a stage that
+// ran from startMs until now, and returns now as the start of the next stage.
+func (rm *RunManager) completeStageReport(runID string, stage StageName, startMs int64) int64 {
+	endMs := time.Now().UnixMilli()
+	go func() {
+		if err := rm.generatePartialReport(runID, stage, startMs, endMs); err != nil {
+			log.Printf("[RunManager] Partial %s report for run %s not generated: %v", stage, runID, err)
+		}
+	}()
+	return endMs
+}
+
+// generatePartialReport writes JSON and HTML reports covering the operations
+// of one completed stage, so long runs can be reviewed before final analysis.
+// It is best effort and never changes the run's state.
+func (rm *RunManager) generatePartialReport(runID string, stage StageName, startMs, endMs int64) error {
+	rm.mu.RLock()
+	record, ok := rm.runs[runID]
+	if !ok {
+		rm.mu.RUnlock()
+		return NewNotFoundError(runID)
+	}
+	telemetryStore := rm.telemetryStore
+	artifactStore := rm.artifactStore
+	serverMetricsSource := rm.serverMetricsSource
+	eventLog := rm.eventLogs[runID]
+	executionID := record.ExecutionID
+	scenarioID := record.ScenarioID
+	latencyBuckets := getLatencyBuckets(record.Config)
+	var capacity *analysis.CapacityReport
+	if stage == StageNameRamp {
+		capacity = record.Capacity
+	}
+	rm.mu.RUnlock()
+
+	if telemetryStore == nil || artifactStore == nil {
+		return fmt.Errorf("telemetry or artifact store not configured")
+	}
+
+	telemetryData, err := telemetryStore.GetTelemetryData(runID)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve telemetry data: %w", err)
+	}
+
+	aggregator := analysis.NewAggregator()
+	aggregator.SetTimeRange(startMs, endMs)
+	aggregator.SetLatencyBuckets(latencyBuckets)
+	aggregator.SetWorkerLabels(workerLabelsFromEvents(eventLog))
+	if err := addOperations(telemetryStore, telemetryData, string(stage), aggregator); err != nil {
+		return fmt.Errorf("failed to retrieve telemetry data: %w", err)
+	}
+	addGeneratorWarnings(aggregator, telemetryData.GeneratorWarnings, startMs, endMs)
+
+	load := aggregator.LoadTimeline()
+	report := &analysis.Report{
+		RunID:         runID,
+		ScenarioID:    scenarioID,
+		StartTime:     startMs,
+		EndTime:       endMs,
+		Duration:      endMs - startMs,
+		Metrics:       aggregator.Compute(),
+		Load:          load,
+		ServerMetrics: rm.serverMetricsReport(serverMetricsSource, runID, load, startMs, endMs),
+		ServerLogs:    rm.serverLogsReport(serverMetricsSource, runID, aggregator, startMs, endMs),
+		Partial: &analysis.PartialReport{
+			Stage: string(stage),
+			Note:  fmt.Sprintf(partialReportNote, stage),
+		},
+		Capacity: capacity,
+	}
+	report.Insights = analysis.GenerateInsights(report)
+
+	reporter := analysis.NewReporter()
+	jsonData, err := reporter.GenerateJSON(report)
+	if err != nil {
+		return fmt.Errorf("failed to generate JSON report: %w", err)
+	}
+	htmlData, err := reporter.GenerateHTML(report)
+	if err != nil {
+		return fmt.Errorf("failed to generate HTML report: %w", err)
+	}
+
+	jsonInfo, err := artifactStore.SaveArtifact(runID, artifacts.ArtifactTypeReport, partialReportFilename(stage, "json"), jsonData)
+	if err != nil {
+		return fmt.Errorf("failed to store JSON report: %w", err)
+	}
+	htmlInfo, err := artifactStore.SaveArtifact(runID, artifacts.ArtifactTypeReport, partialReportFilename(stage, "html"), htmlData)
+	if err != nil {
+		return fmt.Errorf("failed to store HTML report: %w", err)
+	}
+
+	rm.emitPartialReportEvent(runID, executionID, eventLog, stage, jsonInfo, htmlInfo)
+	return nil
+}
+
+// emitPartialReportEvent records partial reports as ARTIFACT_STORED rather
+// than REPORT_GENERATED, which consumers treat as the final report.
+func (rm *RunManager) emitPartialReportEvent(runID, executionID string, eventLog *EventLog, stage StageName, jsonInfo, htmlInfo *artifacts.ArtifactInfo) {
+	payload, _ := json.Marshal(map[string]interface{}{
+		"run_id":  runID,
+		"partial": true,
+		"stage":   stage,
+		"reports": []map[string]interface{}{
+			{
+				"type":     "json",
+				"filename": jsonInfo.Filename,
+				"path":     jsonInfo.Path,
+				"size":     jsonInfo.SizeBytes,
+			},
+			{
+				"type":     "html",
+				"filename": htmlInfo.Filename,
+				"path":     htmlInfo.Path,
+				"size":     htmlInfo.SizeBytes,
+			},
+		},
+	})
+
+	event := RunEvent{
+		RunID:       runID,
+		ExecutionID: executionID,
+		Type:        EventTypeArtifactStored,
+		Actor:       ActorAnalysis,
+		Payload:     payload,
+		Evidence: []Evidence{
+			{Kind: "artifact", Ref: jsonInfo.Path, Note: stringPtr("Partial JSON report")},
+			{Kind: "artifact", Ref: htmlInfo.Path, Note: stringPtr("Partial HTML report")},
+		},
+	}
+	appendEventWithLog(eventLog, event, "emitPartialReportEvent")
+}
@@ -0,0 +1,91 @@
+package runmanager
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+	"github.com/bc-dunia/mcpdrill/internal/artifacts"
+)
+
+func TestGeneratePartialReport(t *testing.T) {
+	validator := createTestValidator(t)
+	rm := NewRunManager(validator)
+	runID, _ := rm.CreateRun(createValidConfig(), "test-user")
+
+	artifactStore, _ := artifacts.NewFilesystemStore(t.TempDir())
+	rm.SetArtifactStore(artifactStore)
+	rm.SetTelemetryStore(&mockTelemetryStore{data: map[string]*TelemetryData{
+		runID: {
+			RunID: runID,
+			Operations: []analysis.OperationResult{
+				{Operation: "tools/list", LatencyMs: 10, OK: true, Stage: "preflight"},
+				{Operation: "tools/list", LatencyMs: 20, OK: true, Stage: "baseline"},
+				{Operation: "tools/list", LatencyMs: 30, OK: false, ErrorType: "timeout", Stage: "baseline"},
+			},
+		},
+	}})
+
+	if err := rm.generatePartialReport(runID, StageNameBaseline, 1000, 3000); err != nil {
+		t.Fatalf("generatePartialReport failed: %v", err)
+	}
+
+	data, err := artifactStore.GetArtifact(runID, artifacts.ArtifactTypeReport, "report-baseline.json")
+	if err != nil {
+		t.Fatalf("expected report-baseline.json: %v", err)
+	}
+	var report analysis.Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("invalid JSON report: %v", err)
+	}
+	if report.Partial == nil || report.Partial.Stage != "baseline" {
+		t.Fatalf("expected report marked partial for baseline, got %+v", report.Partial)
+	}
+	if report.Metrics.TotalOps != 2 || report.Metrics.FailureOps != 1 {
+		t.Errorf("expected only baseline operations, got %d total / %d failed", report.Metrics.TotalOps, report.Metrics.FailureOps)
+	}
+	if report.StartTime != 1000 || report.EndTime != 3000 {
+		t.Errorf("expected stage time range, got %d-%d", report.StartTime, report.EndTime)
+	}
+
+	html, err := artifactStore.GetArtifact(runID, artifacts.ArtifactTypeReport, "report-baseline.html")
+	if err != nil {
+		t.Fatalf("expected report-baseline.html: %v", err)
+	}
+	if !strings.Contains(string(html), "Partial report:") {
+		t.Error("expected HTML report to carry the partial banner")
+	}
+
+	events, _ := rm.TailEvents(runID, 0, 100)
+	var stored *RunEvent
+	for i := range events {
+		if events[i].Type == EventTypeArtifactStored {
+			stored = &events[i]
+		}
+		if events[i].Type == EventTypeReportGenerated {
+			t.Error("partial report must not emit REPORT_GENERATED")
+		}
+	}
+	if stored == nil {
+		t.Fatal("expected ARTIFACT_STORED event")
+	}
+	var payload map[string]interface{}
+	json.Unmarshal(stored.Payload, &payload)
+	if payload["partial"] != true || payload["stage"] != "baseline" {
+		t.Errorf("unexpected event payload: %v", payload)
+	}
+}
+
+func TestGeneratePartialReport_NoStores(t *testing.T) {
+	validator := createTestValidator(t)
+	rm := NewRunManager(validator)
+	runID, _ := rm.CreateRun(createValidConfig(), "test-user")
+
+	if err := rm.generatePartialReport(runID, StageNamePreflight, 0, 1); err == nil {
+		t.Error("expected error without telemetry and artifact stores")
+	}
+	if err := rm.generatePartialReport("run_missing", StageNamePreflight, 0, 1); err == nil {
+		t.Error("expected error for unknown run")
+	}
+}
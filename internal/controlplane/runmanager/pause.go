@@ -0,0 +1,264 @@
+package runmanager
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/scheduler"
+)
+
+// isPausableState reports whether a run in state can be paused: only while
+// a load stage is running. Preflight is short and gates the run, so it is
+// left to finish.
+func isPausableState(state RunState) bool {
+	switch state {
+	case RunStateBaselineRunning, RunStateRampRunning, RunStateSpikeRunning, RunStateStepRunning, RunStateSoakRunning:
+		return true
+	default:
+		return false
+	}
+}
+
+// PauseRun moves a running run to PAUSED. Its workers are told to hold
+// their VUs between operations, keeping their sessions open, and the stage
+// timers stop until the run is resumed, so a paused stage still runs for
+// its full duration. Stop conditions are not evaluated while paused.
+func (rm *RunManager) PauseRun(runID, actor, reason string) error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	record, ok := rm.runs[runID]
+	if !ok {
+		return NewNotFoundError(runID)
+	}
+	if record.State == RunStateCompleted || record.State == RunStateFailed || record.State == RunStateAborted {
+		return NewTerminalStateError(runID, record.State, "pause")
+	}
+	if !isPausableState(record.State) || !CanTransition(record.State, RunStatePaused) {
+		return NewInvalidTransitionError(runID, record.State, RunStatePaused)
+	}
+
+	rm.stopStopConditionEvaluatorLocked(record)
+
+	oldState := record.State
+	record.State = RunStatePaused
+	record.UpdatedAtMs = time.Now().UnixMilli()
+	record.PausedAtMs = record.UpdatedAtMs
+	record.pausedFrom = oldState
+	if record.pauseCh == nil {
+		record.pauseCh = make(chan struct{})
+	}
+	close(record.pauseCh)
+	record.resumeCh = make(chan struct{})
+
+	eventLog := rm.eventLogs[runID]
+	pausePayload, _ := json.Marshal(map[string]interface{}{
+		"paused_by":  actor,
+		"reason":     reason,
+		"from_state": oldState,
+	})
+	appendEventWithLog(eventLog, RunEvent{
+		RunID:       runID,
+		ExecutionID: record.ExecutionID,
+		Type:        EventTypeRunPaused,
+		Actor:       ActorType(actor),
+		Payload:     pausePayload,
+		Evidence:    []Evidence{},
+	}, "PauseRun")
+
+	transitionPayload, _ := json.Marshal(map[string]interface{}{
+		"from_state": oldState,
+		"to_state":   record.State,
+		"trigger":    "paused",
+		"actor":      actor,
+	})
+	appendEventWithLog(eventLog, RunEvent{
+		RunID:       runID,
+		ExecutionID: record.ExecutionID,
+		Type:        EventTypeStateTransition,
+		Actor:       ActorType(actor),
+		Payload:     transitionPayload,
+		Evidence:    []Evidence{},
+	}, "PauseRun")
+	return nil
+}
+
+// ResumeRun returns a paused run to the state it was paused in. Its workers
+// release their VUs again, re-ramping to the previous load, and the stage
+// timers pick up where they stopped.
+func (rm *RunManager) ResumeRun(runID, actor string) error {
+	var (
+		resumedState RunState
+		activeStage  string
+		configCopy   []byte
+	)
+
+	err := func() error {
+		rm.mu.Lock()
+		defer rm.mu.Unlock()
+
+		record, ok := rm.runs[runID]
+		if !ok {
+			return NewNotFoundError(runID)
+		}
+		if record.State == RunStateCompleted || record.State == RunStateFailed || record.State == RunStateAborted {
+			return NewTerminalStateError(runID, record.State, "resume")
+		}
+		if record.State != RunStatePaused {
+			return NewInvalidStateError(runID, record.State, RunStatePaused, "resume")
+		}
+		if !CanTransition(record.State, record.pausedFrom) {
+			return NewInvalidTransitionError(runID, record.State, record.pausedFrom)
+		}
+
+		pausedMs := time.Now().UnixMilli() - record.PausedAtMs
+		resumedState = record.pausedFrom
+		record.State = resumedState
+		record.UpdatedAtMs = time.Now().UnixMilli()
+		rm.endPauseLocked(record)
+		if record.ActiveStage != nil {
+			activeStage = record.ActiveStage.Stage
+		}
+		configCopy = append([]byte(nil), record.Config...)
+
+		eventLog := rm.eventLogs[runID]
+		resumePayload, _ := json.Marshal(map[string]interface{}{
+			"resumed_by": actor,
+			"to_state":   resumedState,
+			"paused_ms":  pausedMs,
+		})
+		appendEventWithLog(eventLog, RunEvent{
+			RunID:       runID,
+			ExecutionID: record.ExecutionID,
+			Type:        EventTypeRunResumed,
+			Actor:       ActorType(actor),
+			Payload:     resumePayload,
+			Evidence:    []Evidence{},
+		}, "ResumeRun")
+
+		transitionPayload, _ := json.Marshal(map[string]interface{}{
+			"from_state": RunStatePaused,
+			"to_state":   resumedState,
+			"trigger":    "resumed",
+			"actor":      actor,
+		})
+		appendEventWithLog(eventLog, RunEvent{
+			RunID:       runID,
+			ExecutionID: record.ExecutionID,
+			Type:        EventTypeStateTransition,
+			Actor:       ActorType(actor),
+			Payload:     transitionPayload,
+			Evidence:    []Evidence{},
+		}, "ResumeRun")
+		return nil
+	}()
+	if err != nil {
+		return err
+	}
+
+	// The stop condition windows start over, so the pause itself cannot
+	// trip a throughput or stall condition.
+	if parsedConfig, err := parseRunConfig(configCopy); err == nil && activeStage != "" {
+		rm.startStopConditionEvaluator(runID, findStageByName(parsedConfig, StageName(activeStage)))
+	}
+	return nil
+}
+
+// endPauseLocked ends the pause of a run, if it is paused, adding the time
+// spent paused to PausedMs and releasing the stage timers waiting for the
+// run to resume. Must be called with rm.mu held.
+func (rm *RunManager) endPauseLocked(record *RunRecord) {
+	if record.resumeCh == nil {
+		return
+	}
+	close(record.resumeCh)
+	record.resumeCh = nil
+	record.pauseCh = nil
+	record.PausedMs += time.Now().UnixMilli() - record.PausedAtMs
+	record.PausedAtMs = 0
+	record.pausedFrom = ""
+}
+
+// pauseSignals returns a channel closed once the run is paused and, if it is
+// paused already, a channel closed when it resumes. ok is false if the run
+// is gone.
+func (rm *RunManager) pauseSignals(runID string) (paused, resumed <-chan struct{}, ok bool) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	record, ok := rm.runs[runID]
+	if !ok {
+		return nil, nil, false
+	}
+	if record.pauseCh == nil {
+		record.pauseCh = make(chan struct{})
+	}
+	return record.pauseCh, record.resumeCh, true
+}
+
+// waitRunning waits until the run has spent d outside of pauses, so the
+// timers of a stage stand still while the run is paused. It returns false
+// if ctx is done first or the run is gone. The timer is registered as a
+// stage timer.
+func (rm *RunManager) waitRunning(ctx context.Context, runID string, d time.Duration) bool {
+	for {
+		if d <= 0 {
+			return true
+		}
+		paused, resumed, ok := rm.pauseSignals(runID)
+		if !ok {
+			return false
+		}
+		if resumed != nil {
+			select {
+			case <-ctx.Done():
+				return false
+			case <-resumed:
+			}
+			continue
+		}
+
+		started := time.Now()
+		timer := time.NewTimer(d)
+		rm.addStageTimer(runID, timer)
+		select {
+		case <-ctx.Done():
+			stopTimer(timer)
+			rm.removeStageTimer(runID, timer)
+			return false
+		case <-timer.C:
+			rm.removeStageTimer(runID, timer)
+			return true
+		case <-paused:
+			stopTimer(timer)
+			rm.removeStageTimer(runID, timer)
+			d -= time.Since(started)
+		}
+	}
+}
+
+// ListPausedRunsForWorker returns the paused runs the worker holds active
+// leases of.
+func (rm *RunManager) ListPausedRunsForWorker(workerID string) []string {
+	rm.mu.RLock()
+	leaseManager := rm.leaseManager
+	rm.mu.RUnlock()
+
+	if leaseManager == nil {
+		return nil
+	}
+
+	workerRunIDs := leaseManager.ListWorkerRunIDs(scheduler.WorkerID(workerID))
+
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	var pausedRuns []string
+	for _, runID := range workerRunIDs {
+		if record, ok := rm.runs[runID]; ok && record.State == RunStatePaused {
+			pausedRuns = append(pausedRuns, runID)
+		}
+	}
+	return pausedRuns
+}
@@ -0,0 +1,125 @@
+package runmanager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/scheduler"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+func TestPauseRun_ResumesInPreviousState(t *testing.T) {
+	rm := NewRunManager(createTestValidatorForWorkerTest(t))
+
+	registry := scheduler.NewRegistry()
+	lm := scheduler.NewLeaseManager(60000)
+	rm.SetScheduler(registry, scheduler.NewAllocator(registry, lm), lm)
+	rm.SetAssignmentSender(&mockAssignmentSender{assignments: make(map[string][]types.WorkerAssignment)})
+	workerID, err := registry.RegisterWorker(types.HostInfo{Hostname: "host1"}, types.WorkerCapacity{MaxVUs: 100})
+	if err != nil {
+		t.Fatalf("RegisterWorker failed: %v", err)
+	}
+
+	runID := createTestRunWithPolicy(t, rm, "")
+	if err := rm.StartRun(runID, "test"); err != nil {
+		t.Fatalf("StartRun failed: %v", err)
+	}
+	if err := rm.PauseRun(runID, "alice", "maintenance"); err == nil {
+		t.Fatal("expected pausing during preflight to fail")
+	}
+	if err := rm.TransitionToBaseline(runID, "test"); err != nil {
+		t.Fatalf("TransitionToBaseline failed: %v", err)
+	}
+
+	if err := rm.PauseRun(runID, "alice", "maintenance"); err != nil {
+		t.Fatalf("PauseRun failed: %v", err)
+	}
+	view, _ := rm.GetRun(runID)
+	if view.State != RunStatePaused || view.PausedAtMs == 0 {
+		t.Fatalf("expected the run paused, got %s (paused at %d)", view.State, view.PausedAtMs)
+	}
+	if ids := rm.ListPausedRunsForWorker(string(workerID)); len(ids) != 1 || ids[0] != runID {
+		t.Errorf("expected the worker told to pause the run, got %v", ids)
+	}
+	if err := rm.PauseRun(runID, "alice", ""); err == nil {
+		t.Error("expected pausing a paused run to fail")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if err := rm.ResumeRun(runID, "bob"); err != nil {
+		t.Fatalf("ResumeRun failed: %v", err)
+	}
+	view, _ = rm.GetRun(runID)
+	if view.State != RunStateBaselineRunning || view.PausedAtMs != 0 || view.PausedMs < 20 {
+		t.Fatalf("expected the run back in baseline after a 20ms pause, got %s (paused %dms)", view.State, view.PausedMs)
+	}
+	if ids := rm.ListPausedRunsForWorker(string(workerID)); len(ids) != 0 {
+		t.Errorf("expected no paused runs after resume, got %v", ids)
+	}
+	if err := rm.ResumeRun(runID, "bob"); err == nil {
+		t.Error("expected resuming a running run to fail")
+	}
+
+	var paused, resumed bool
+	for _, event := range rm.eventLogs[runID].GetAll() {
+		switch event.Type {
+		case EventTypeRunPaused:
+			paused = true
+		case EventTypeRunResumed:
+			resumed = true
+		}
+	}
+	if !paused || !resumed {
+		t.Errorf("expected RUN_PAUSED and RUN_RESUMED events, got paused=%v resumed=%v", paused, resumed)
+	}
+
+	// A paused run can still be stopped.
+	if err := rm.PauseRun(runID, "alice", ""); err != nil {
+		t.Fatalf("PauseRun failed: %v", err)
+	}
+	if err := rm.RequestStop(runID, StopModeDrain, "alice"); err != nil {
+		t.Fatalf("RequestStop of a paused run failed: %v", err)
+	}
+	view, _ = rm.GetRun(runID)
+	if view.State != RunStateStopping || view.PausedAtMs != 0 {
+		t.Errorf("expected the paused run stopping, got %s (paused at %d)", view.State, view.PausedAtMs)
+	}
+}
+
+func TestWaitRunning_StandsStillWhilePaused(t *testing.T) {
+	rm := NewRunManager(createTestValidator(t))
+	runID, _ := rm.CreateRun(createValidConfig(), "test-user")
+	rm.runs[runID].State = RunStateSoakRunning
+
+	done := make(chan bool, 1)
+	go func() {
+		done <- rm.waitRunning(context.Background(), runID, 100*time.Millisecond)
+	}()
+
+	time.Sleep(30 * time.Millisecond)
+	if err := rm.PauseRun(runID, "test-user", ""); err != nil {
+		t.Fatalf("PauseRun failed: %v", err)
+	}
+	select {
+	case <-done:
+		t.Fatal("expected the wait to stand still while the run is paused")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	resumedAt := time.Now()
+	if err := rm.ResumeRun(runID, "test-user"); err != nil {
+		t.Fatalf("ResumeRun failed: %v", err)
+	}
+	select {
+	case ok := <-done:
+		if !ok {
+			t.Fatal("expected the wait to complete")
+		}
+		if waited := time.Since(resumedAt); waited < 40*time.Millisecond {
+			t.Errorf("expected the remaining ~70ms to be waited after resume, waited %v", waited)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the wait to complete after resume")
+	}
+}
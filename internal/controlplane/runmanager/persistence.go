@@ -0,0 +1,490 @@
+package runmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/runstore"
+)
+
+// persistWriteTimeout bounds the store writes of one batch.
+const persistWriteTimeout = 30 * time.Second
+
+// runPersister writes runs, events and telemetry summaries to a run store.
+// Events are appended while the run manager lock is held, so they are only
+// queued there; a single goroutine writes them in order.
+type runPersister struct {
+	rm    *RunManager
+	store runstore.Store
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	pending []persistItem
+	closed  bool
+	done    chan struct{}
+}
+
+// persistItem is one queued write: an event, a telemetry summary or the
+// deletion of a run.
+type persistItem struct {
+	runID   string
+	seq     int
+	event   *RunEvent
+	summary *runstore.TelemetrySummary
+	delete  bool
+}
+
+func newRunPersister(rm *RunManager, store runstore.Store) *runPersister {
+	p := &runPersister{rm: rm, store: store, done: make(chan struct{})}
+	p.cond = sync.NewCond(&p.mu)
+	go p.run()
+	return p
+}
+
+func (p *runPersister) enqueue(item persistItem) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return
+	}
+	p.pending = append(p.pending, item)
+	p.cond.Signal()
+}
+
+// close writes the queued items and stops the writer.
+func (p *runPersister) close() {
+	p.mu.Lock()
+	p.closed = true
+	p.cond.Signal()
+	p.mu.Unlock()
+	<-p.done
+}
+
+func (p *runPersister) run() {
+	defer close(p.done)
+	for {
+		p.mu.Lock()
+		for len(p.pending) == 0 && !p.closed {
+			p.cond.Wait()
+		}
+		batch := p.pending
+		p.pending = nil
+		closed := p.closed
+		p.mu.Unlock()
+
+		p.write(batch)
+		if closed && len(batch) == 0 {
+			return
+		}
+	}
+}
+
+// write stores a batch. Each run touched by the batch is saved once, before
+// its events, from the run's current in-memory state. Failures are logged and
+// the item dropped; persistence never fails a run.
+func (p *runPersister) write(batch []persistItem) {
+	if len(batch) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), persistWriteTimeout)
+	defer cancel()
+
+	// Writes queued for a run before it was deleted are moot.
+	deleted := make(map[string]bool)
+	for _, item := range batch {
+		if item.delete {
+			deleted[item.runID] = true
+		}
+	}
+
+	saved := make(map[string]bool)
+	for _, item := range batch {
+		if deleted[item.runID] && !item.delete {
+			continue
+		}
+		if !saved[item.runID] {
+			saved[item.runID] = true
+			if run, ok := p.rm.storedRun(item.runID); ok {
+				if err := p.store.SaveRun(ctx, run); err != nil {
+					log.Printf("[RunManager] Failed to persist run %s: %v", item.runID, err)
+				}
+			}
+		}
+
+		var err error
+		switch {
+		case item.event != nil:
+			err = p.appendEvent(ctx, item.seq, item.event)
+		case item.summary != nil:
+			err = p.store.SaveTelemetrySummary(ctx, item.summary)
+		case item.delete:
+			err = p.store.DeleteRun(ctx, item.runID)
+		}
+		if err != nil {
+			log.Printf("[RunManager] Failed to persist run %s: %v", item.runID, err)
+		}
+	}
+}
+
+func (p *runPersister) appendEvent(ctx context.Context, seq int, event *RunEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encode event %s: %w", event.EventID, err)
+	}
+	return p.store.AppendEvent(ctx, &runstore.Event{
+		RunID:       event.RunID,
+		Seq:         seq,
+		EventID:     event.EventID,
+		Type:        string(event.Type),
+		TimestampMs: event.TimestampMs,
+		Data:        data,
+	})
+}
+
+// SetRunStore persists runs, their events and telemetry summaries to store
+// from now on. Call RecoverRuns afterwards to load the runs it already holds.
+// The caller owns the store and closes it after Shutdown.
+func (rm *RunManager) SetRunStore(store runstore.Store) {
+	rm.mu.Lock()
+	previous := rm.persister
+	rm.persister = nil
+	if store != nil {
+		rm.persister = newRunPersister(rm, store)
+	}
+	for runID, eventLog := range rm.eventLogs {
+		rm.persistEventLogLocked(runID, eventLog)
+	}
+	rm.mu.Unlock()
+
+	// The writer reads runs under rm.mu, so it is drained without the lock.
+	if previous != nil {
+		previous.close()
+	}
+}
+
+// closeRunStore writes queued items and detaches the run store.
+func (rm *RunManager) closeRunStore() {
+	rm.mu.Lock()
+	p := rm.persister
+	rm.persister = nil
+	for runID, eventLog := range rm.eventLogs {
+		rm.persistEventLogLocked(runID, eventLog)
+	}
+	rm.mu.Unlock()
+
+	if p != nil {
+		p.close()
+	}
+}
+
+// persistEventLogLocked queues every event appended to eventLog for the
+// current run store, or stops doing so if there is none. Must be called with
+// rm.mu held.
+func (rm *RunManager) persistEventLogLocked(runID string, eventLog *EventLog) {
+	if eventLog == nil {
+		return
+	}
+	var onAppend func(int, RunEvent)
+	if p := rm.persister; p != nil {
+		onAppend = func(seq int, event RunEvent) {
+			p.enqueue(persistItem{runID: runID, seq: seq, event: &event})
+		}
+	}
+	eventLog.mu.Lock()
+	eventLog.onAppend = onAppend
+	eventLog.mu.Unlock()
+}
+
+// persistTelemetrySummary queues the aggregated telemetry of an analyzed run
+// for the run store.
+func (rm *RunManager) persistTelemetrySummary(runID string, data *TelemetryData, metrics *analysis.AggregatedMetrics) {
+	rm.mu.RLock()
+	p := rm.persister
+	rm.mu.RUnlock()
+	if p == nil {
+		return
+	}
+
+	encoded, err := json.Marshal(metrics)
+	if err != nil {
+		log.Printf("[RunManager] Failed to encode telemetry summary for run %s: %v", runID, err)
+		return
+	}
+	p.enqueue(persistItem{runID: runID, summary: &runstore.TelemetrySummary{
+		RunID:           runID,
+		StartTimeMs:     data.StartTimeMs,
+		EndTimeMs:       data.EndTimeMs,
+		StopReason:      data.StopReason,
+		TotalOperations: metrics.TotalOps,
+		Metrics:         encoded,
+	}})
+}
+
+// storedRun converts the in-memory run to its stored form.
+func (rm *RunManager) storedRun(runID string) (*runstore.Run, bool) {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	record, ok := rm.runs[runID]
+	if !ok {
+		return nil, false
+	}
+	run := &runstore.Run{
+		RunID:       record.RunID,
+		Project:     record.Project,
+		ExecutionID: record.ExecutionID,
+		State:       string(record.State),
+		ScenarioID:  record.ScenarioID,
+		ConfigHash:  record.ConfigHash,
+		Actor:       record.Actor,
+		CreatedAtMs: record.CreatedAtMs,
+		UpdatedAtMs: record.UpdatedAtMs,
+		Config:      append(json.RawMessage(nil), record.Config...),
+	}
+	if record.ActiveStage != nil {
+		run.ActiveStage, _ = json.Marshal(record.ActiveStage)
+	}
+	if record.StopReason != nil {
+		run.StopReason, _ = json.Marshal(record.StopReason)
+	}
+	return run, true
+}
+
+// RecoverRuns loads the runs held by the run store that are not in memory
+// yet, with their event logs, and returns how many were loaded.
+//
+// Load generation cannot resume after a restart: worker assignments and
+// per-operation telemetry lived in the previous process. Each recovered
+// non-terminal run gets a SYSTEM_RECOVERY event and is then settled:
+// created runs are kept and can still be started, runs in a stage are
+// stopped with reason control_plane_restart, stopping runs are finalized
+// again and analyzing runs are analyzed again.
+func (rm *RunManager) RecoverRuns(ctx context.Context) (int, error) {
+	rm.mu.RLock()
+	p := rm.persister
+	rm.mu.RUnlock()
+	if p == nil {
+		return 0, fmt.Errorf("run store not configured")
+	}
+
+	stored, err := p.store.ListRuns(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var recovered []*RunRecord
+	for _, run := range stored {
+		record, eventLog, err := restoreRun(ctx, p.store, run)
+		if err != nil {
+			return len(recovered), err
+		}
+
+		rm.mu.Lock()
+		if _, exists := rm.runs[record.RunID]; exists {
+			rm.mu.Unlock()
+			continue
+		}
+		switch record.State {
+		case RunStateCompleted, RunStateFailed, RunStateAborted:
+		default:
+			eventLog.trace = newRunTrace(rm.tracer, record, true)
+		}
+		rm.runs[record.RunID] = record
+		rm.eventLogs[record.RunID] = eventLog
+		rm.indexConfigLocked(record)
+		rm.persistEventLogLocked(record.RunID, eventLog)
+		rm.mu.Unlock()
+
+		recovered = append(recovered, record)
+	}
+
+	for _, record := range recovered {
+		rm.resumeRecoveredRun(record.RunID)
+	}
+	return len(recovered), nil
+}
+
+// restoreRun rebuilds a run record and its event log from the store.
+func restoreRun(ctx context.Context, store runstore.Store, run *runstore.Run) (*RunRecord, *EventLog, error) {
+	record := &RunRecord{
+		RunID:       run.RunID,
+		Project:     run.Project,
+		ExecutionID: run.ExecutionID,
+		State:       RunState(run.State),
+		ConfigHash:  run.ConfigHash,
+		ScenarioID:  run.ScenarioID,
+		CreatedAtMs: run.CreatedAtMs,
+		UpdatedAtMs: run.UpdatedAtMs,
+		Actor:       run.Actor,
+		Config:      run.Config,
+		targetKey:   targetHost(run.Config),
+	}
+	if len(run.ActiveStage) > 0 {
+		record.ActiveStage = &ActiveStageInfo{}
+		if err := json.Unmarshal(run.ActiveStage, record.ActiveStage); err != nil {
+			return nil, nil, fmt.Errorf("decode active stage of run %s: %w", run.RunID, err)
+		}
+	}
+	if len(run.StopReason) > 0 {
+		record.StopReason = &StopReason{}
+		if err := json.Unmarshal(run.StopReason, record.StopReason); err != nil {
+			return nil, nil, fmt.Errorf("decode stop reason of run %s: %w", run.RunID, err)
+		}
+	}
+
+	stored, err := store.ListEvents(ctx, run.RunID)
+	if err != nil {
+		return nil, nil, err
+	}
+	eventLog := NewEventLog()
+	eventLog.runID = run.RunID
+	for _, e := range stored {
+		var event RunEvent
+		if err := json.Unmarshal(e.Data, &event); err != nil {
+			return nil, nil, fmt.Errorf("decode event %s of run %s: %w", e.EventID, run.RunID, err)
+		}
+		eventLog.events = append(eventLog.events, event)
+		if event.Type == EventTypeRunCreated {
+			record.ScenarioRef = scenarioRefFromEvent(event)
+		}
+		if event.Type == EventTypeRunCreated || event.Type == EventTypeRunTagsUpdated {
+			if tags, ok := tagsFromEvent(event); ok {
+				record.Tags = tags
+			}
+		}
+		if event.Type == EventTypeRunCreated || event.Type == EventTypeDecision {
+			if params := parametersFromEvent(event); params != nil {
+				record.Parameters = params
+			}
+		}
+		if event.Type == EventTypeAnalysisCompleted {
+			record.SLOs = sloReportFromEvent(event)
+		}
+		if event.Type == EventTypeDecision {
+			if capacity := capacityFromEvent(event); capacity != nil {
+				record.Capacity = capacity
+			}
+		}
+		if event.Type == EventTypeConformanceChecked {
+			record.Conformance = conformanceFromEvent(event)
+		}
+		if event.Type == EventTypeRunAborted {
+			record.AbortedBy = abortedByFromEvent(event)
+		}
+		if event.Type == EventTypeRunResumed {
+			record.PausedMs += pausedMsFromEvent(event)
+		}
+		if event.Type == EventTypeStateTransition {
+			if priority, ok := queuedPriorityFromEvent(event); ok {
+				record.Priority = priority
+				record.QueuedAtMs = event.TimestampMs
+			}
+		}
+	}
+	return record, eventLog, nil
+}
+
+// abortedByFromEvent recovers the actor carried by a RUN_ABORTED event.
+func abortedByFromEvent(event RunEvent) string {
+	var payload struct {
+		AbortedBy string `json:"aborted_by"`
+	}
+	_ = json.Unmarshal(event.Payload, &payload)
+	return payload.AbortedBy
+}
+
+// pausedMsFromEvent recovers how long a pause lasted from the RUN_RESUMED
+// event that ended it.
+func pausedMsFromEvent(event RunEvent) int64 {
+	var payload struct {
+		PausedMs int64 `json:"paused_ms"`
+	}
+	_ = json.Unmarshal(event.Payload, &payload)
+	return payload.PausedMs
+}
+
+// sloReportFromEvent recovers the SLO verdict carried by an
+// ANALYSIS_COMPLETED event, since the store keeps no column for it.
+func sloReportFromEvent(event RunEvent) *analysis.SLOReport {
+	var payload struct {
+		SLOs *analysis.SLOReport `json:"slos"`
+	}
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return nil
+	}
+	return payload.SLOs
+}
+
+// scenarioRefFromEvent recovers the scenario version named by a RUN_CREATED
+// event.
+func scenarioRefFromEvent(event RunEvent) *ScenarioRef {
+	var payload struct {
+		ScenarioRef *ScenarioRef `json:"scenario_ref"`
+	}
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return nil
+	}
+	return payload.ScenarioRef
+}
+
+// resumeRecoveredRun settles a non-terminal run loaded by RecoverRuns.
+func (rm *RunManager) resumeRecoveredRun(runID string) {
+	rm.mu.RLock()
+	record, ok := rm.runs[runID]
+	if !ok {
+		rm.mu.RUnlock()
+		return
+	}
+	state := record.State
+	executionID := record.ExecutionID
+	configCopy := append([]byte(nil), record.Config...)
+	eventLog := rm.eventLogs[runID]
+	rm.mu.RUnlock()
+
+	var action string
+	switch state {
+	case RunStateCompleted, RunStateFailed, RunStateAborted:
+		return
+	case RunStateCreated, RunStateQueued:
+		action = "none"
+	case RunStateStopping:
+		action = "finalize"
+	case RunStateAnalyzing:
+		action = "reanalyze"
+	default:
+		action = "stop"
+	}
+
+	payload, _ := json.Marshal(map[string]interface{}{
+		"recovered_state": state,
+		"action":          action,
+	})
+	appendEventWithLog(eventLog, RunEvent{
+		RunID:       runID,
+		ExecutionID: executionID,
+		Type:        EventTypeSystemRecovery,
+		Actor:       ActorSystem,
+		Payload:     payload,
+		Evidence:    []Evidence{{Kind: "reason", Ref: "control_plane_restart"}},
+	}, "resumeRecoveredRun")
+
+	switch action {
+	case "finalize":
+		go rm.finalizeRun(runID, getDrainTimeout(configCopy), string(ActorSystem))
+	case "reanalyze":
+		go func() {
+			if err := rm.analyzeRunWithTimeout(runID, getAnalysisTimeout(configCopy)); err != nil {
+				log.Printf("[RunManager] Analysis of recovered run %s failed: %v", runID, err)
+			}
+		}()
+	case "stop":
+		if err := rm.requestStopWithReason(runID, StopModeDrain, string(ActorSystem), "control_plane_restart", nil); err != nil {
+			log.Printf("[RunManager] Failed to stop recovered run %s: %v", runID, err)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package runmanager
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/runstore"
+)
+
+func openTestRunStore(t *testing.T) runstore.Store {
+	t.Helper()
+	store, err := runstore.Open(context.Background(), "sqlite:"+filepath.Join(t.TempDir(), "runs.db"))
+	if err != nil {
+		t.Fatalf("failed to open run store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestRunStore_RecoverRuns(t *testing.T) {
+	store := openTestRunStore(t)
+	validator := createTestValidator(t)
+
+	rm := NewRunManager(validator)
+	rm.SetRunStore(store)
+	createdID, err := rm.CreateRun(createValidConfig(), "test-user")
+	if err != nil {
+		t.Fatalf("CreateRun failed: %v", err)
+	}
+	runningID, err := rm.CreateRun(createValidConfigWithDurations(t, 60000, 60000, 60000), "test-user")
+	if err != nil {
+		t.Fatalf("CreateRun failed: %v", err)
+	}
+	if err := rm.StartRun(runningID, "test-user"); err != nil {
+		t.Fatalf("StartRun failed: %v", err)
+	}
+	eventsBefore := rm.GetEventCount(runningID)
+	rm.Shutdown()
+
+	restarted := NewRunManager(validator)
+	defer restarted.Shutdown()
+	restarted.SetRunStore(store)
+	n, err := restarted.RecoverRuns(context.Background())
+	if err != nil {
+		t.Fatalf("RecoverRuns failed: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("expected 2 recovered runs, got %d", n)
+	}
+
+	created, err := restarted.GetRun(createdID)
+	if err != nil {
+		t.Fatalf("GetRun failed: %v", err)
+	}
+	if created.State != RunStateCreated {
+		t.Errorf("expected created run to stay created, got %s", created.State)
+	}
+	if _, err := restarted.GetRunConfig(createdID); err != nil {
+		t.Errorf("expected recovered run config: %v", err)
+	}
+
+	running, err := restarted.GetRun(runningID)
+	if err != nil {
+		t.Fatalf("GetRun failed: %v", err)
+	}
+	if running.State != RunStateStopping {
+		t.Errorf("expected in-progress run to be stopping, got %s", running.State)
+	}
+	if running.StopReason == nil || running.StopReason.Reason != "control_plane_restart" {
+		t.Errorf("expected control_plane_restart stop reason, got %+v", running.StopReason)
+	}
+
+	events, _ := restarted.TailEvents(runningID, 0, 1000)
+	if len(events) <= eventsBefore {
+		t.Fatalf("expected recovered events plus new ones, got %d (had %d)", len(events), eventsBefore)
+	}
+	if events[0].Type != EventTypeRunCreated {
+		t.Errorf("expected first recovered event RUN_CREATED, got %s", events[0].Type)
+	}
+	if events[eventsBefore].Type != EventTypeSystemRecovery {
+		t.Errorf("expected SYSTEM_RECOVERY after recovered events, got %s", events[eventsBefore].Type)
+	}
+
+	// Runs already in memory are not loaded twice.
+	if n, err := restarted.RecoverRuns(context.Background()); err != nil || n != 0 {
+		t.Errorf("expected second RecoverRuns to load nothing, got %d, %v", n, err)
+	}
+}
+
+func TestRunStore_PersistsTelemetrySummary(t *testing.T) {
+	store := openTestRunStore(t)
+	rm := NewRunManager(createTestValidator(t))
+	rm.SetRunStore(store)
+
+	runID, err := rm.CreateRun(createValidConfig(), "test-user")
+	if err != nil {
+		t.Fatalf("CreateRun failed: %v", err)
+	}
+	rm.persistTelemetrySummary(runID, &TelemetryData{StartTimeMs: 1000, EndTimeMs: 2000, StopReason: "completed"},
+		&analysis.AggregatedMetrics{TotalOps: 3})
+	rm.Shutdown()
+
+	summary, err := store.GetTelemetrySummary(context.Background(), runID)
+	if err != nil {
+		t.Fatalf("GetTelemetrySummary failed: %v", err)
+	}
+	var metrics analysis.AggregatedMetrics
+	if err := json.Unmarshal(summary.Metrics, &metrics); err != nil {
+		t.Fatalf("invalid metrics JSON: %v", err)
+	}
+	if summary.TotalOperations != 3 || metrics.TotalOps != 3 || summary.EndTimeMs != 2000 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+}
+
+func TestRunStore_RecoversSLOVerdict(t *testing.T) {
+	store := openTestRunStore(t)
+	validator := createTestValidator(t)
+
+	rm := NewRunManager(validator)
+	rm.SetRunStore(store)
+	runID, err := rm.CreateRun(createValidConfigWithSLOs(t), "test-user")
+	if err != nil {
+		t.Fatalf("CreateRun failed: %v", err)
+	}
+	rm.mu.Lock()
+	rm.runs[runID].State = RunStateAnalyzing
+	rm.mu.Unlock()
+	rm.completeAnalysis(runID, &analysis.SLOReport{
+		Verdict: analysis.SLOVerdictPass,
+		Results: []analysis.SLOResult{{Name: "latency", Objective: "p95_latency_ms < 500", Passed: true}},
+	})
+	rm.Shutdown()
+
+	restarted := NewRunManager(validator)
+	defer restarted.Shutdown()
+	restarted.SetRunStore(store)
+	if _, err := restarted.RecoverRuns(context.Background()); err != nil {
+		t.Fatalf("RecoverRuns failed: %v", err)
+	}
+
+	view, err := restarted.GetRun(runID)
+	if err != nil {
+		t.Fatalf("GetRun failed: %v", err)
+	}
+	if view.SLOs == nil || view.SLOs.Verdict != analysis.SLOVerdictPass || view.SLOs.Results[0].Name != "latency" {
+		t.Errorf("expected the recovered run to keep its verdict, got %+v", view.SLOs)
+	}
+}
@@ -0,0 +1,191 @@
+package runmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/scheduler"
+)
+
+// parsedPlacement constrains the workers a run's VUs are placed on by their
+// labels.
+type parsedPlacement struct {
+	// Require lists key=value labels a worker must carry. Entries for the
+	// same key are alternatives; entries for different keys must all match.
+	Require []string `json:"require,omitempty"`
+	// SpreadAcross is a label key: VUs are split evenly across its values,
+	// and workers without the label are left out.
+	SpreadAcross string `json:"spread_across,omitempty"`
+}
+
+// admits reports whether a run with placement p may use w.
+func (p *parsedPlacement) admits(w *scheduler.WorkerInfo) bool {
+	if p == nil {
+		return true
+	}
+	labels := w.HostInfo.Labels
+	if p.SpreadAcross != "" && labels[p.SpreadAcross] == "" {
+		return false
+	}
+	allowed := make(map[string][]string)
+	for _, req := range p.Require {
+		key, value, _ := strings.Cut(req, "=")
+		allowed[key] = append(allowed[key], value)
+	}
+	for key, values := range allowed {
+		matched := false
+		for _, value := range values {
+			if labels[key] == value {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// placementWorkers returns the workers of project the run's placement
+// admits.
+func placementWorkers(registry *scheduler.Registry, project string, placement *parsedPlacement) []*scheduler.WorkerInfo {
+	workers := projectWorkers(registry, project)
+	eligible := workers[:0]
+	for _, w := range workers {
+		if placement.admits(w) {
+			eligible = append(eligible, w)
+		}
+	}
+	return eligible
+}
+
+// noWorkersDetails explains an allocation that found no workers for the run.
+func noWorkersDetails(parsedConfig *parsedRunConfig) string {
+	if parsedConfig.Placement != nil {
+		return "no registered workers match the run's placement"
+	}
+	return "no workers registered"
+}
+
+// workerSpread maps each worker to its value of the label the placement
+// spreads VUs across, or returns nil if the placement does not spread them.
+func workerSpread(workers []*scheduler.WorkerInfo, placement *parsedPlacement) map[scheduler.WorkerID]string {
+	if placement == nil || placement.SpreadAcross == "" {
+		return nil
+	}
+	spread := make(map[scheduler.WorkerID]string, len(workers))
+	for _, w := range workers {
+		spread[w.WorkerID] = w.HostInfo.Labels[placement.SpreadAcross]
+	}
+	return spread
+}
+
+// workerLabels returns the labels of each of workers.
+func workerLabels(workers []*scheduler.WorkerInfo) map[scheduler.WorkerID]map[string]string {
+	labels := make(map[scheduler.WorkerID]map[string]string, len(workers))
+	for _, w := range workers {
+		labels[w.WorkerID] = w.HostInfo.Labels
+	}
+	return labels
+}
+
+// spreadValues groups workerIDs by their spread label value, in value order.
+func spreadValues(spread map[scheduler.WorkerID]string, workerIDs []scheduler.WorkerID) ([]string, map[string][]scheduler.WorkerID) {
+	byValue := make(map[string][]scheduler.WorkerID)
+	for _, wid := range workerIDs {
+		value := spread[wid]
+		byValue[value] = append(byValue[value], wid)
+	}
+	values := make([]string, 0, len(byValue))
+	for value := range byValue {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	return values, byValue
+}
+
+// interleaveSpread reorders workerIDs to take one worker of each spread
+// value in turn, so that any run of them, such as a generator group's
+// share, spans as many values as it can.
+func interleaveSpread(spread map[scheduler.WorkerID]string, workerIDs []scheduler.WorkerID) []scheduler.WorkerID {
+	if spread == nil {
+		return workerIDs
+	}
+	values, byValue := spreadValues(spread, workerIDs)
+	interleaved := make([]scheduler.WorkerID, 0, len(workerIDs))
+	for i := 0; len(interleaved) < len(workerIDs); i++ {
+		for _, value := range values {
+			if i < len(byValue[value]) {
+				interleaved = append(interleaved, byValue[value][i])
+			}
+		}
+	}
+	return interleaved
+}
+
+// allocateSpread allocates numVUs VUs across workerIDs, numbering them from
+// 0 like Allocator.AllocateAssignments. With a spread the VUs are split
+// evenly across the label values of the workers and packed onto the workers
+// of each value. offset is the number of VUs of the same pool allocated
+// before; the split is computed on the cumulative count so ramp steps
+// converge on an even spread.
+func allocateSpread(allocator *scheduler.Allocator, runID, stageID string, spread map[scheduler.WorkerID]string, workerIDs []scheduler.WorkerID, offset, numVUs int) (map[scheduler.WorkerID]scheduler.Assignment, error) {
+	if spread == nil {
+		_, workerAssignments, err := allocator.AllocateAssignments(runID, stageID, numVUs, workerIDs)
+		return workerAssignments, err
+	}
+
+	values, byValue := spreadValues(spread, workerIDs)
+	weights := make([]int, len(values))
+	for i := range weights {
+		weights[i] = 1
+	}
+	before := apportion(offset, weights, 0)
+	after := apportion(offset+numVUs, weights, 0)
+
+	workerAssignments := make(map[scheduler.WorkerID]scheduler.Assignment)
+	vuCursor := 0
+	for i, value := range values {
+		valueVUs := after[i] - before[i]
+		if valueVUs <= 0 {
+			continue
+		}
+		_, assignments, err := allocator.AllocateAssignments(runID, stageID, valueVUs, byValue[value])
+		if err != nil {
+			return nil, fmt.Errorf("workers labelled %s: %w", value, err)
+		}
+		for workerID, assignment := range assignments {
+			assignment.VUIDRange.Start += vuCursor
+			assignment.VUIDRange.End += vuCursor
+			workerAssignments[workerID] = assignment
+		}
+		vuCursor += valueVUs
+	}
+	return workerAssignments, nil
+}
+
+// workerLabelsFromEvents recovers the labels of the workers a run was
+// assigned to from the worker_labels of its WORKER_ASSIGNED events.
+func workerLabelsFromEvents(eventLog *EventLog) map[string]map[string]string {
+	if eventLog == nil {
+		return nil
+	}
+	labels := make(map[string]map[string]string)
+	for _, event := range eventLog.GetAll() {
+		if event.Type != EventTypeWorkerAssigned {
+			continue
+		}
+		var payload struct {
+			WorkerID     string            `json:"worker_id"`
+			WorkerLabels map[string]string `json:"worker_labels"`
+		}
+		if err := json.Unmarshal(event.Payload, &payload); err != nil || len(payload.WorkerLabels) == 0 {
+			continue
+		}
+		labels[payload.WorkerID] = payload.WorkerLabels
+	}
+	return labels
+}
@@ -0,0 +1,108 @@
+package runmanager
+
+import (
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/scheduler"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+func newLabelledTestRegistry(t *testing.T, labels ...map[string]string) (*scheduler.Registry, *scheduler.Allocator) {
+	t.Helper()
+	registry := scheduler.NewRegistry()
+	for _, l := range labels {
+		if _, err := registry.RegisterWorker(types.HostInfo{Hostname: "host", Labels: l}, types.WorkerCapacity{MaxVUs: 100}); err != nil {
+			t.Fatalf("RegisterWorker failed: %v", err)
+		}
+	}
+	return registry, scheduler.NewAllocator(registry, scheduler.NewLeaseManager(60000))
+}
+
+func TestPlacementWorkers(t *testing.T) {
+	registry, _ := newLabelledTestRegistry(t,
+		map[string]string{"region": "eu-west-1", "tier": "premium"},
+		map[string]string{"region": "eu-west-1", "tier": "standard"},
+		map[string]string{"region": "us-east-1", "tier": "premium"},
+		map[string]string{"tier": "premium"},
+		nil,
+	)
+
+	count := func(placement *parsedPlacement) int {
+		return len(placementWorkers(registry, "", placement))
+	}
+	if got := count(nil); got != 5 {
+		t.Errorf("expected every worker without a placement, got %d", got)
+	}
+	if got := count(&parsedPlacement{Require: []string{"region=eu-west-1"}}); got != 2 {
+		t.Errorf("expected 2 eu-west-1 workers, got %d", got)
+	}
+	if got := count(&parsedPlacement{Require: []string{"region=eu-west-1", "region=us-east-1", "tier=premium"}}); got != 2 {
+		t.Errorf("expected 2 premium workers in either region, got %d", got)
+	}
+	if got := count(&parsedPlacement{SpreadAcross: "region"}); got != 3 {
+		t.Errorf("expected unlabelled workers to be left out of a spread, got %d", got)
+	}
+}
+
+func TestAllocateGroups_SpreadsAcrossLabel(t *testing.T) {
+	registry, allocator := newLabelledTestRegistry(t,
+		map[string]string{"region": "eu"},
+		map[string]string{"region": "eu"},
+		map[string]string{"region": "us"},
+		map[string]string{"region": "ap"},
+	)
+	placement := &parsedPlacement{SpreadAcross: "region"}
+	workers := placementWorkers(registry, "", placement)
+	spread := workerSpread(workers, placement)
+	workerIDs := make([]scheduler.WorkerID, len(workers))
+	for i, w := range workers {
+		workerIDs[i] = w.WorkerID
+	}
+
+	totals := make(map[string]int)
+	offset := 0
+	for _, step := range []int{1, 1, 5, 23} {
+		dispatches, err := allocateGroups(allocator, "run_1", "stg_1", nil, nil, spread, workerIDs, offset, step)
+		if err != nil {
+			t.Fatalf("allocateGroups failed at offset %d: %v", offset, err)
+		}
+		for _, d := range dispatches {
+			if d.assignment.VUIDRange.Start < offset || d.assignment.VUIDRange.End > offset+step {
+				t.Errorf("range %+v outside step [%d, %d)", d.assignment.VUIDRange, offset, offset+step)
+			}
+			totals[spread[d.workerID]] += d.assignment.VUIDRange.End - d.assignment.VUIDRange.Start
+		}
+		offset += step
+	}
+	if totals["eu"] != 10 || totals["us"] != 10 || totals["ap"] != 10 {
+		t.Errorf("expected 10 VUs per region, got %v", totals)
+	}
+
+	groups := []parsedGeneratorGroup{{Name: "steady", Weight: 1}, {Name: "bursty", Weight: 1}}
+	dispatches, err := allocateGroups(allocator, "run_1", "stg_1", groups, nil, spread, workerIDs, 0, 40)
+	if err != nil {
+		t.Fatalf("allocateGroups failed: %v", err)
+	}
+	regions := make(map[string]map[string]bool)
+	for _, d := range dispatches {
+		if regions[d.groupName()] == nil {
+			regions[d.groupName()] = make(map[string]bool)
+		}
+		regions[d.groupName()][spread[d.workerID]] = true
+	}
+	if len(regions["steady"]) != 2 || len(regions["bursty"]) != 2 {
+		t.Errorf("expected each group to span two regions, got %v", regions)
+	}
+}
+
+func TestWorkerLabelsFromEvents(t *testing.T) {
+	rm := NewRunManager(nil)
+	eventLog := NewEventLog()
+	rm.emitWorkerAssignedEvent("run_0000000000000001", "exe_00000001", eventLog, "wkr_01", "lse_01", 0, 5, "stg_1", StageNameBaseline, "", "", map[string]string{"region": "eu"})
+	rm.emitWorkerAssignedEvent("run_0000000000000001", "exe_00000001", eventLog, "wkr_02", "lse_02", 5, 10, "stg_1", StageNameBaseline, "", "", nil)
+
+	labels := workerLabelsFromEvents(eventLog)
+	if len(labels) != 1 || labels["wkr_01"]["region"] != "eu" {
+		t.Errorf("expected the labels of wkr_01 only, got %v", labels)
+	}
+}
@@ -1,7 +1,13 @@
 package runmanager
 
 import (
+	"cmp"
 	"encoding/json"
+	"maps"
+	"net"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/bc-dunia/mcpdrill/internal/controlplane/scheduler"
 )
@@ -13,32 +19,157 @@ func (rm *RunManager) ListRuns() []*RunView {
 
 	result := make([]*RunView, 0, len(rm.runs))
 	for _, record := range rm.runs {
-		var lastDecisionEventID *string
-		if eventLog, ok := rm.eventLogs[record.RunID]; ok {
-			events := eventLog.GetAll()
-			for i := len(events) - 1; i >= 0; i-- {
-				if events[i].Type == EventTypeDecision {
-					lastDecisionEventID = &events[i].EventID
-					break
-				}
-			}
+		result = append(result, rm.runViewLocked(record))
+	}
+	return result
+}
+
+// RunFilter selects runs. Empty fields match every run.
+type RunFilter struct {
+	Project    string
+	ScenarioID string
+	States     []RunState
+	// Tags must all be set on a run; an empty value matches any value.
+	Tags map[string]string
+	// TargetHost matches the host of the run's target, with or without
+	// its port, ignoring case.
+	TargetHost string
+	// CreatedSinceMs (inclusive) and CreatedUntilMs (exclusive) bound when
+	// the run was created.
+	CreatedSinceMs int64
+	CreatedUntilMs int64
+
+	// SortBy is RunSortCreatedAt, the default, or RunSortUpdatedAt.
+	SortBy    string
+	Ascending bool
+	// Offset skips the first matching runs and Limit caps the runs
+	// returned; zero returns all of them.
+	Offset int
+	Limit  int
+}
+
+// Sort orders of QueryRuns.
+const (
+	RunSortCreatedAt = "created_at"
+	RunSortUpdatedAt = "updated_at"
+)
+
+// QueryRuns returns the page of runs filter selects, newest first unless it
+// asks otherwise, and the number of runs that match in all.
+func (rm *RunManager) QueryRuns(filter RunFilter) ([]*RunView, int) {
+	rm.mu.RLock()
+	var matched []*RunRecord
+	for _, record := range rm.runs {
+		if filter.matches(record) {
+			matched = append(matched, record)
 		}
+	}
 
-		view := &RunView{
-			RunID:               record.RunID,
-			ExecutionID:         record.ExecutionID,
-			State:               record.State,
-			ScenarioID:          record.ScenarioID,
-			ConfigHash:          record.ConfigHash,
-			CreatedAtMs:         record.CreatedAtMs,
-			UpdatedAtMs:         record.UpdatedAtMs,
-			ActiveStage:         record.ActiveStage,
-			StopReason:          record.StopReason,
-			LastDecisionEventID: lastDecisionEventID,
+	sortKey := func(r *RunRecord) int64 { return r.CreatedAtMs }
+	if filter.SortBy == RunSortUpdatedAt {
+		sortKey = func(r *RunRecord) int64 { return r.UpdatedAtMs }
+	}
+	slices.SortFunc(matched, func(a, b *RunRecord) int {
+		c := cmp.Compare(sortKey(a), sortKey(b))
+		if c == 0 {
+			c = cmp.Compare(a.RunID, b.RunID)
+		}
+		if !filter.Ascending {
+			c = -c
 		}
-		result = append(result, view)
+		return c
+	})
+
+	total := len(matched)
+	page := matched[min(filter.Offset, total):]
+	if filter.Limit > 0 && len(page) > filter.Limit {
+		page = page[:filter.Limit]
+	}
+	views := make([]*RunView, 0, len(page))
+	for _, record := range page {
+		views = append(views, rm.runViewLocked(record))
+	}
+	rm.mu.RUnlock()
+	return views, total
+}
+
+func (f *RunFilter) matches(r *RunRecord) bool {
+	if f.Project != "" && r.Project != f.Project {
+		return false
+	}
+	if f.ScenarioID != "" && r.ScenarioID != f.ScenarioID {
+		return false
+	}
+	if len(f.States) > 0 && !slices.Contains(f.States, r.State) {
+		return false
+	}
+	for key, value := range f.Tags {
+		if got, ok := r.Tags[key]; !ok || (value != "" && got != value) {
+			return false
+		}
+	}
+	if f.TargetHost != "" {
+		host := strings.ToLower(f.TargetHost)
+		if r.targetKey != host && hostWithoutPort(r.targetKey) != host {
+			return false
+		}
+	}
+	if f.CreatedSinceMs > 0 && r.CreatedAtMs < f.CreatedSinceMs {
+		return false
+	}
+	if f.CreatedUntilMs > 0 && r.CreatedAtMs >= f.CreatedUntilMs {
+		return false
+	}
+	return true
+}
+
+func hostWithoutPort(hostport string) string {
+	if host, _, err := net.SplitHostPort(hostport); err == nil {
+		return host
+	}
+	return hostport
+}
+
+// runViewLocked builds the external view of a run. Must be called with
+// rm.mu held.
+func (rm *RunManager) runViewLocked(record *RunRecord) *RunView {
+	var lastDecisionEventID *string
+	if eventLog, ok := rm.eventLogs[record.RunID]; ok {
+		events := eventLog.GetAll()
+		for i := len(events) - 1; i >= 0; i-- {
+			if events[i].Type == EventTypeDecision {
+				lastDecisionEventID = &events[i].EventID
+				break
+			}
+		}
+	}
+
+	return &RunView{
+		RunID:               record.RunID,
+		Project:             record.Project,
+		ExecutionID:         record.ExecutionID,
+		State:               record.State,
+		ScenarioID:          record.ScenarioID,
+		ConfigHash:          record.ConfigHash,
+		CreatedAtMs:         record.CreatedAtMs,
+		UpdatedAtMs:         record.UpdatedAtMs,
+		ActiveStage:         record.ActiveStage,
+		StopReason:          record.StopReason,
+		LastDecisionEventID: lastDecisionEventID,
+		SLOs:                record.SLOs,
+		Capacity:            record.Capacity,
+		Conformance:         record.Conformance,
+		Priority:            record.Priority,
+		QueuePosition:       rm.queuePositionLocked(record),
+		ScenarioRef:         record.ScenarioRef,
+		Parameters:          record.Parameters,
+		Tags:                maps.Clone(record.Tags),
+		TargetHost:          record.targetKey,
+		PreviousRunIDs:      rm.previousRunIDsLocked(record),
+		AbortedBy:           record.AbortedBy,
+		PausedAtMs:          record.PausedAtMs,
+		PausedMs:            record.PausedMs,
 	}
-	return result
 }
 
 func (rm *RunManager) ListStoppingRunsForWorker(workerID string) []string {
@@ -91,6 +222,13 @@ func (rm *RunManager) ListImmediateStopRunsForWorker(workerID string) []string {
 			}
 		}
 	}
+	// Aborted runs have no leases left to find them by.
+	nowMs := time.Now().UnixMilli()
+	for runID, untilMs := range rm.abortNotices[scheduler.WorkerID(workerID)] {
+		if untilMs > nowMs && !slices.Contains(immediateStopRuns, runID) {
+			immediateStopRuns = append(immediateStopRuns, runID)
+		}
+	}
 	return immediateStopRuns
 }
 
@@ -114,7 +252,8 @@ func (rm *RunManager) GetRunConfig(runID string) ([]byte, error) {
 	return configCopy, nil
 }
 
-// CloneRun creates a new run with the same configuration as an existing run.
+// CloneRun creates a new run with the same configuration, in the same
+// project, as an existing run.
 // Returns the new run ID on success, or an error if the source run is not found.
 func (rm *RunManager) CloneRun(sourceRunID, actor string) (string, error) {
 	config, err := rm.GetRunConfig(sourceRunID)
@@ -122,7 +261,18 @@ func (rm *RunManager) CloneRun(sourceRunID, actor string) (string, error) {
 		return "", err
 	}
 
-	return rm.CreateRun(config, actor)
+	return rm.createRun(rm.GetRunProject(sourceRunID), config, actor, nil, nil, nil)
+}
+
+// GetRunProject returns the project a run belongs to, or "" if the run is
+// not found.
+func (rm *RunManager) GetRunProject(runID string) string {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	if record, ok := rm.runs[runID]; ok {
+		return record.Project
+	}
+	return ""
 }
 
 // GetRun returns the current view of a run.
@@ -135,32 +285,7 @@ func (rm *RunManager) GetRun(runID string) (*RunView, error) {
 	if !ok {
 		return nil, NewNotFoundError(runID)
 	}
-
-	var lastDecisionEventID *string
-	if eventLog, ok := rm.eventLogs[runID]; ok {
-		events := eventLog.GetAll()
-		for i := len(events) - 1; i >= 0; i-- {
-			if events[i].Type == EventTypeDecision {
-				lastDecisionEventID = &events[i].EventID
-				break
-			}
-		}
-	}
-
-	view := &RunView{
-		RunID:               record.RunID,
-		ExecutionID:         record.ExecutionID,
-		State:               record.State,
-		ScenarioID:          record.ScenarioID,
-		ConfigHash:          record.ConfigHash,
-		CreatedAtMs:         record.CreatedAtMs,
-		UpdatedAtMs:         record.UpdatedAtMs,
-		ActiveStage:         record.ActiveStage,
-		StopReason:          record.StopReason,
-		LastDecisionEventID: lastDecisionEventID,
-	}
-
-	return view, nil
+	return rm.runViewLocked(record), nil
 }
 
 // GetRunServerTelemetryPairKey returns the server_telemetry.pair_key from a run's config.
@@ -0,0 +1,105 @@
+package runmanager
+
+import (
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+)
+
+// maxNotificationFindings caps the insights a RunNotification carries.
+const maxNotificationFindings = 3
+
+// RunNotifier is told when a run reaches a final state, for chat and email
+// integrations. RunFinished must not block.
+type RunNotifier interface {
+	RunFinished(notification RunNotification)
+}
+
+// RunNotification describes a run that completed, failed or was aborted.
+type RunNotification struct {
+	RunID       string      `json:"run_id"`
+	ExecutionID string      `json:"execution_id"`
+	Project     string      `json:"project,omitempty"`
+	ScenarioID  string      `json:"scenario_id,omitempty"`
+	State       RunState    `json:"state"`
+	Trigger     string      `json:"trigger"`
+	StopReason  *StopReason `json:"stop_reason,omitempty"`
+	CreatedAtMs int64       `json:"created_at_ms"`
+	EndedAtMs   int64       `json:"ended_at_ms"`
+
+	// Analysis is set when the run was analyzed, which failed runs and runs
+	// aborted before analysis are not.
+	Analysis *RunAnalysisSummary `json:"analysis,omitempty"`
+}
+
+// RunAnalysisSummary holds the headline results of a run's report.
+type RunAnalysisSummary struct {
+	TotalOps   int     `json:"total_ops"`
+	RPS        float64 `json:"rps"`
+	ErrorRate  float64 `json:"error_rate"`
+	LatencyP50 int     `json:"latency_p50"`
+	LatencyP95 int     `json:"latency_p95"`
+	LatencyP99 int     `json:"latency_p99"`
+
+	SLOs *analysis.SLOReport `json:"slos,omitempty"`
+	// Findings are the report's top insights, most severe first.
+	Findings []analysis.Insight `json:"findings,omitempty"`
+	Reports  []ReportLink       `json:"reports,omitempty"`
+}
+
+// ReportLink is a stored report of a run. URL is set when the artifact
+// store signs download links.
+type ReportLink struct {
+	Format   string `json:"format"`
+	Filename string `json:"filename"`
+	URL      string `json:"url,omitempty"`
+}
+
+// SetRunNotifier configures the notifier told of finished runs.
+func (rm *RunManager) SetRunNotifier(notifier RunNotifier) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.runNotifier = notifier
+}
+
+// summarizeAnalysis returns the summary of report notifications carry.
+func summarizeAnalysis(report *analysis.Report, reports []storedReport) *RunAnalysisSummary {
+	summary := &RunAnalysisSummary{SLOs: report.SLOs}
+	if m := report.Metrics; m != nil {
+		summary.TotalOps = m.TotalOps
+		summary.RPS = m.RPS
+		summary.ErrorRate = m.ErrorRate
+		summary.LatencyP50 = m.LatencyP50
+		summary.LatencyP95 = m.LatencyP95
+		summary.LatencyP99 = m.LatencyP99
+	}
+	if n := min(len(report.Insights), maxNotificationFindings); n > 0 {
+		summary.Findings = report.Insights[:n]
+	}
+	for _, r := range reports {
+		summary.Reports = append(summary.Reports, ReportLink{Format: r.format, Filename: r.info.Filename, URL: r.url})
+	}
+	return summary
+}
+
+// notifyRunFinishedLocked tells the notifier, if any, that record reached a
+// final state. Must be called with rm.mu held.
+func (rm *RunManager) notifyRunFinishedLocked(record *RunRecord, trigger string) {
+	if rm.runNotifier == nil {
+		return
+	}
+	notification := RunNotification{
+		RunID:       record.RunID,
+		ExecutionID: record.ExecutionID,
+		Project:     record.Project,
+		ScenarioID:  record.ScenarioID,
+		State:       record.State,
+		Trigger:     trigger,
+		CreatedAtMs: record.CreatedAtMs,
+		EndedAtMs:   record.UpdatedAtMs,
+		Analysis:    record.analysisSummary,
+	}
+	if record.StopReason != nil {
+		stopReason := *record.StopReason
+		notification.StopReason = &stopReason
+	}
+	rm.runNotifier.RunFinished(notification)
+}
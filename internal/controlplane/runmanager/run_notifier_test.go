@@ -0,0 +1,78 @@
+package runmanager
+
+import (
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+	"github.com/bc-dunia/mcpdrill/internal/artifacts"
+)
+
+type recordingRunNotifier struct {
+	notifications []RunNotification
+}
+
+func (n *recordingRunNotifier) RunFinished(notification RunNotification) {
+	n.notifications = append(n.notifications, notification)
+}
+
+func TestRunNotifier_AnalyzedRun(t *testing.T) {
+	rm := NewRunManager(createTestValidator(t))
+	artifactStore, _ := artifacts.NewFilesystemStore(t.TempDir())
+	rm.SetArtifactStore(artifactStore)
+	telemetryStore := &mockTelemetryStore{data: make(map[string]*TelemetryData)}
+	rm.SetTelemetryStore(telemetryStore)
+	notifier := &recordingRunNotifier{}
+	rm.SetRunNotifier(notifier)
+
+	runID, _ := rm.CreateRun(createValidConfig(), "test-user")
+	_ = rm.StartRun(runID, "test-user")
+	_ = rm.RequestStop(runID, StopModeDrain, "test-user")
+	telemetryStore.data[runID] = &TelemetryData{
+		RunID:       runID,
+		StartTimeMs: 1000,
+		EndTimeMs:   2000,
+		Operations: []analysis.OperationResult{
+			{Operation: "tools_call", ToolName: "echo", LatencyMs: 100, OK: true},
+			{Operation: "tools_call", ToolName: "echo", LatencyMs: 150, OK: false, ErrorType: "timeout"},
+		},
+	}
+	if err := rm.TransitionToAnalyzing(runID, "system"); err != nil {
+		t.Fatalf("TransitionToAnalyzing failed: %v", err)
+	}
+
+	if len(notifier.notifications) != 1 {
+		t.Fatalf("expected one notification, got %d", len(notifier.notifications))
+	}
+	n := notifier.notifications[0]
+	if n.RunID != runID || n.State != RunStateCompleted || n.Trigger != "analysis_completed" {
+		t.Errorf("unexpected notification %+v", n)
+	}
+	if n.Analysis == nil || n.Analysis.TotalOps != 2 || n.Analysis.ErrorRate != 0.5 {
+		t.Fatalf("expected the run's headline numbers, got %+v", n.Analysis)
+	}
+	if len(n.Analysis.Reports) != 2 || n.Analysis.Reports[1].Filename != "report.html" {
+		t.Errorf("expected the JSON and HTML reports, got %+v", n.Analysis.Reports)
+	}
+}
+
+func TestRunNotifier_AbortedRun(t *testing.T) {
+	rm := NewRunManager(createTestValidator(t))
+	notifier := &recordingRunNotifier{}
+	rm.SetRunNotifier(notifier)
+
+	runID, _ := rm.CreateRun(createValidConfig(), "test-user")
+	if err := rm.AbortRun(runID, "alice", "wrong target"); err != nil {
+		t.Fatalf("AbortRun failed: %v", err)
+	}
+
+	if len(notifier.notifications) != 1 {
+		t.Fatalf("expected one notification, got %d", len(notifier.notifications))
+	}
+	n := notifier.notifications[0]
+	if n.State != RunStateAborted || n.Trigger != "wrong target" || n.Analysis != nil {
+		t.Errorf("unexpected notification %+v", n)
+	}
+	if n.StopReason == nil || n.StopReason.Actor != "alice" {
+		t.Errorf("expected the stop reason, got %+v", n.StopReason)
+	}
+}
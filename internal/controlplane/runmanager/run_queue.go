@@ -0,0 +1,312 @@
+package runmanager
+
+import (
+	"encoding/json"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// runQueuePollInterval is how often the run queue looks for runs it can
+// start as others finish.
+const runQueuePollInterval = 500 * time.Millisecond
+
+// RunQueueConfig configures the run queue. With a queue, started runs wait
+// in QUEUED state until they may run, highest priority first and in the
+// order they were started within a priority.
+type RunQueueConfig struct {
+	// MaxConcurrentRuns is the most runs executing at once; 0 means no limit.
+	MaxConcurrentRuns int
+	// ExclusiveTargets keeps two runs against the same target host from
+	// executing at the same time.
+	ExclusiveTargets bool
+}
+
+// SetRunQueue enables the run queue. Without one, runs start as soon as
+// StartRun is called.
+func (rm *RunManager) SetRunQueue(cfg RunQueueConfig) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.runQueue = &cfg
+	if !rm.runQueueStarted {
+		rm.runQueueStarted = true
+		go rm.runQueueLoop()
+	}
+}
+
+// StartOptions adjust how a run is started.
+type StartOptions struct {
+	// Priority orders the run in the run queue, higher first.
+	Priority int
+	// Parameters are applied to the run's config, which is validated again,
+	// before the run starts.
+	Parameters *RunParameters
+}
+
+// StartRunWithOptions starts a run like StartRun. When a run queue is
+// configured, the run is queued with opts.Priority and starts once the queue
+// admits it; the call returns without waiting if that is not right away.
+func (rm *RunManager) StartRunWithOptions(runID, actor string, opts StartOptions) error {
+	if !opts.Parameters.IsZero() {
+		if err := rm.applyStartParameters(runID, actor, opts.Parameters); err != nil {
+			return err
+		}
+	}
+	priority := opts.Priority
+
+	rm.mu.Lock()
+	if rm.runQueue == nil {
+		rm.mu.Unlock()
+		return rm.startRun(runID, actor, RunStateCreated)
+	}
+
+	record, ok := rm.runs[runID]
+	if !ok {
+		rm.mu.Unlock()
+		return NewNotFoundError(runID)
+	}
+	if record.State != RunStateCreated {
+		rm.mu.Unlock()
+		return NewInvalidStateError(runID, record.State, RunStateCreated, "start")
+	}
+	if !CanTransition(record.State, RunStateQueued) {
+		rm.mu.Unlock()
+		return NewInvalidTransitionError(runID, record.State, RunStateQueued)
+	}
+
+	oldState := record.State
+	record.State = RunStateQueued
+	record.UpdatedAtMs = time.Now().UnixMilli()
+	record.Priority = priority
+	record.QueuedAtMs = record.UpdatedAtMs
+	record.startActor = actor
+
+	payload, _ := json.Marshal(map[string]interface{}{
+		"from_state": oldState,
+		"to_state":   record.State,
+		"trigger":    "queued",
+		"actor":      actor,
+		"priority":   priority,
+	})
+	appendEventWithLog(rm.eventLogs[runID], RunEvent{
+		RunID:       runID,
+		ExecutionID: record.ExecutionID,
+		Type:        EventTypeStateTransition,
+		Actor:       ActorType(actor),
+		Payload:     payload,
+		Evidence:    []Evidence{},
+	}, "StartRunWithOptions")
+
+	admitted := rm.admitQueuedRunsLocked()
+	rm.mu.Unlock()
+
+	startNow := false
+	for _, run := range admitted {
+		if run.runID == runID {
+			startNow = true
+			continue
+		}
+		go rm.startQueuedRun(run.runID, run.actor)
+	}
+	if startNow {
+		return rm.startRun(runID, actor, RunStateQueued)
+	}
+	return nil
+}
+
+// admittedRun is a queued run the run queue has let start.
+type admittedRun struct {
+	runID string
+	actor string
+}
+
+// admitQueuedRunsLocked marks the queued runs that may start now as admitted
+// and returns them. Must be called with rm.mu held.
+func (rm *RunManager) admitQueuedRunsLocked() []admittedRun {
+	if rm.runQueue == nil {
+		return nil
+	}
+
+	active := 0
+	busyTargets := make(map[string]bool)
+	for _, record := range rm.runs {
+		if !occupiesQueueSlot(record) {
+			continue
+		}
+		active++
+		if key := rm.targetKeyLocked(record); key != "" {
+			busyTargets[key] = true
+		}
+	}
+
+	var admitted []admittedRun
+	for _, record := range rm.waitingRunsLocked() {
+		if rm.runQueue.MaxConcurrentRuns > 0 && active >= rm.runQueue.MaxConcurrentRuns {
+			break
+		}
+		key := rm.targetKeyLocked(record)
+		if rm.runQueue.ExclusiveTargets && key != "" && busyTargets[key] {
+			continue
+		}
+		record.admitted = true
+		active++
+		if key != "" {
+			busyTargets[key] = true
+		}
+		actor := record.startActor
+		if actor == "" {
+			actor = record.Actor
+		}
+		admitted = append(admitted, admittedRun{runID: record.RunID, actor: actor})
+	}
+	return admitted
+}
+
+// waitingRunsLocked returns the queued runs not yet admitted, in the order
+// the queue admits them. Must be called with rm.mu held.
+func (rm *RunManager) waitingRunsLocked() []*RunRecord {
+	var waiting []*RunRecord
+	for _, record := range rm.runs {
+		if record.State == RunStateQueued && !record.admitted {
+			waiting = append(waiting, record)
+		}
+	}
+	sort.Slice(waiting, func(i, j int) bool {
+		if waiting[i].Priority != waiting[j].Priority {
+			return waiting[i].Priority > waiting[j].Priority
+		}
+		if waiting[i].QueuedAtMs != waiting[j].QueuedAtMs {
+			return waiting[i].QueuedAtMs < waiting[j].QueuedAtMs
+		}
+		return waiting[i].RunID < waiting[j].RunID
+	})
+	return waiting
+}
+
+// queuePositionLocked returns the 1-based position of a waiting run in the
+// queue, or 0 if the run is not waiting. Must be called with rm.mu held.
+func (rm *RunManager) queuePositionLocked(record *RunRecord) int {
+	if record.State != RunStateQueued || record.admitted {
+		return 0
+	}
+	for i, waiting := range rm.waitingRunsLocked() {
+		if waiting == record {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// occupiesQueueSlot reports whether a run counts against the concurrency
+// limit: it is executing, stopping, or admitted and starting. Runs being
+// analyzed no longer send load.
+func occupiesQueueSlot(record *RunRecord) bool {
+	switch record.State {
+	case RunStateCreated, RunStateAnalyzing:
+		return false
+	case RunStateQueued:
+		return record.admitted
+	default:
+		return !isTerminalRunState(record.State)
+	}
+}
+
+// targetKeyLocked returns the lowercased host of the run's target, caching
+// it on the record. Must be called with rm.mu held.
+func (rm *RunManager) targetKeyLocked(record *RunRecord) string {
+	if record.targetKey == "" {
+		record.targetKey = targetHost(record.Config)
+	}
+	return record.targetKey
+}
+
+func targetHost(config []byte) string {
+	parsed, err := parseRunConfig(config)
+	if err != nil {
+		return ""
+	}
+	u, err := url.Parse(parsed.Target.URL)
+	if err != nil || u.Host == "" {
+		return strings.ToLower(parsed.Target.URL)
+	}
+	return strings.ToLower(u.Host)
+}
+
+// runQueueLoop admits queued runs as running ones finish.
+func (rm *RunManager) runQueueLoop() {
+	ticker := time.NewTicker(runQueuePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-rm.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		rm.mu.Lock()
+		admitted := rm.admitQueuedRunsLocked()
+		rm.mu.Unlock()
+
+		for _, run := range admitted {
+			go rm.startQueuedRun(run.runID, run.actor)
+		}
+	}
+}
+
+func (rm *RunManager) startQueuedRun(runID, actor string) {
+	if err := rm.startRun(runID, actor, RunStateQueued); err != nil {
+		log.Printf("[RunManager] Failed to start queued run %s: %v", runID, err)
+	}
+}
+
+// abortQueuedRunLocked aborts a run waiting in the queue. A run the queue
+// has already admitted is starting and must be stopped once it has.
+// Must be called with rm.mu held.
+func (rm *RunManager) abortQueuedRunLocked(record *RunRecord, mode StopMode, actor, reason string, evidence []Evidence) error {
+	if record.admitted {
+		return NewInvalidTransitionError(record.RunID, record.State, RunStateStopping)
+	}
+
+	oldState := record.State
+	record.State = RunStateAborted
+	record.UpdatedAtMs = time.Now().UnixMilli()
+	record.StopReason = &StopReason{
+		Mode:   mode,
+		Reason: reason,
+		Actor:  actor,
+		AtMs:   record.UpdatedAtMs,
+	}
+
+	payload, _ := json.Marshal(map[string]interface{}{
+		"from_state": oldState,
+		"to_state":   record.State,
+		"trigger":    reason,
+		"actor":      actor,
+	})
+	appendEventWithLog(rm.eventLogs[record.RunID], RunEvent{
+		RunID:       record.RunID,
+		ExecutionID: record.ExecutionID,
+		Type:        EventTypeStateTransition,
+		Actor:       ActorType(actor),
+		Payload:     payload,
+		Evidence:    nonNilEvidence(evidence),
+	}, "abortQueuedRunLocked")
+	rm.notifyRunFinishedLocked(record, reason)
+	return nil
+}
+
+// queuedPriorityFromEvent recovers the priority carried by the STATE_TRANSITION
+// event that queued a run, since the store keeps no column for it.
+func queuedPriorityFromEvent(event RunEvent) (int, bool) {
+	var payload struct {
+		ToState  RunState `json:"to_state"`
+		Priority int      `json:"priority"`
+	}
+	if err := json.Unmarshal(event.Payload, &payload); err != nil || payload.ToState != RunStateQueued {
+		return 0, false
+	}
+	return payload.Priority, true
+}
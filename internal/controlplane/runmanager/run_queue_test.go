@@ -0,0 +1,100 @@
+package runmanager
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRunQueue_AdmitsByPriorityWithinLimit(t *testing.T) {
+	rm := NewRunManager(createTestValidatorForWorkerTest(t))
+	defer rm.Shutdown()
+	rm.SetRunQueue(RunQueueConfig{MaxConcurrentRuns: 1})
+
+	first := createTestRunWithPolicy(t, rm, "")
+	low := createTestRunWithPolicy(t, rm, "")
+	high := createTestRunWithPolicy(t, rm, "")
+
+	if err := rm.StartRun(first, "test"); err != nil {
+		t.Fatalf("StartRun failed: %v", err)
+	}
+	waitForRunState(t, rm, first, RunStatePreflightRunning, 5*time.Second)
+
+	if err := rm.StartRunWithOptions(low, "test", StartOptions{}); err != nil {
+		t.Fatalf("StartRunWithOptions failed: %v", err)
+	}
+	if err := rm.StartRunWithOptions(high, "test", StartOptions{Priority: 5}); err != nil {
+		t.Fatalf("StartRunWithOptions failed: %v", err)
+	}
+
+	for runID, want := range map[string]int{high: 1, low: 2} {
+		view, _ := rm.GetRun(runID)
+		if view.State != RunStateQueued || view.QueuePosition != want {
+			t.Errorf("run %s: expected queued at position %d, got %s at %d", runID, want, view.State, view.QueuePosition)
+		}
+	}
+
+	setRunState(t, rm, first, RunStateCompleted)
+	waitForRunState(t, rm, high, RunStatePreflightRunning, 5*time.Second)
+
+	view, _ := rm.GetRun(low)
+	if view.State != RunStateQueued || view.QueuePosition != 1 {
+		t.Errorf("expected the low-priority run to wait at position 1, got %s at %d", view.State, view.QueuePosition)
+	}
+	if view, _ := rm.GetRun(high); view.Priority != 5 || view.QueuePosition != 0 {
+		t.Errorf("expected the started run to keep its priority and leave the queue, got %+v", view)
+	}
+}
+
+func TestRunQueue_ExclusiveTargets(t *testing.T) {
+	rm := NewRunManager(createTestValidatorForWorkerTest(t))
+	defer rm.Shutdown()
+	rm.SetRunQueue(RunQueueConfig{ExclusiveTargets: true})
+
+	first := createTestRunWithPolicy(t, rm, "")
+	sameTarget := createTestRunWithPolicy(t, rm, "")
+	otherTarget := createTestRunWithPolicy(t, rm, "")
+	rm.mu.Lock()
+	rm.runs[otherTarget].targetKey = "other.example:443"
+	rm.mu.Unlock()
+
+	for _, runID := range []string{first, sameTarget, otherTarget} {
+		if err := rm.StartRun(runID, "test"); err != nil {
+			t.Fatalf("StartRun failed: %v", err)
+		}
+	}
+
+	waitForRunState(t, rm, first, RunStatePreflightRunning, 5*time.Second)
+	waitForRunState(t, rm, otherTarget, RunStatePreflightRunning, 5*time.Second)
+	if view, _ := rm.GetRun(sameTarget); view.State != RunStateQueued {
+		t.Fatalf("expected a second run against the same target to be queued, got %s", view.State)
+	}
+}
+
+func TestRunQueue_StopAbortsQueuedRun(t *testing.T) {
+	rm := NewRunManager(createTestValidatorForWorkerTest(t))
+	defer rm.Shutdown()
+	rm.SetRunQueue(RunQueueConfig{MaxConcurrentRuns: 1})
+
+	first := createTestRunWithPolicy(t, rm, "")
+	queued := createTestRunWithPolicy(t, rm, "")
+	if err := rm.StartRun(first, "test"); err != nil {
+		t.Fatalf("StartRun failed: %v", err)
+	}
+	if err := rm.StartRun(queued, "test"); err != nil {
+		t.Fatalf("StartRun failed: %v", err)
+	}
+
+	if err := rm.RequestStop(queued, StopModeDrain, "user"); err != nil {
+		t.Fatalf("RequestStop failed: %v", err)
+	}
+	view, _ := rm.GetRun(queued)
+	if view.State != RunStateAborted || view.StopReason == nil || view.StopReason.Actor != "user" {
+		t.Fatalf("expected the queued run to be aborted by user, got %+v", view)
+	}
+
+	setRunState(t, rm, first, RunStateCompleted)
+	time.Sleep(2 * runQueuePollInterval)
+	if view, _ := rm.GetRun(queued); view.State != RunStateAborted {
+		t.Errorf("expected the aborted run not to be started, got %s", view.State)
+	}
+}
@@ -0,0 +1,123 @@
+package runmanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// scaleHookTimeout bounds a single webhook request or command.
+const scaleHookTimeout = 30 * time.Second
+
+// externalScaleHook posts capacity shortfalls to a webhook or passes them to a
+// command. Calls run in the background, one at a time per run; shortfalls of
+// a run reported while its previous call is still running are dropped.
+type externalScaleHook struct {
+	url     string
+	command string
+	client  *http.Client
+
+	mu       sync.Mutex
+	inFlight map[string]bool
+}
+
+// OpenScaleHook returns the scale hook for spec. An http:// or https:// URL
+// is sent each shortfall as a JSON POST. Anything else is run with sh -c,
+// with the shortfall as JSON on stdin and its fields in MCPDRILL_*
+// environment variables.
+func OpenScaleHook(spec string) (ScaleHook, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, fmt.Errorf("scale hook is empty")
+	}
+	hook := &externalScaleHook{inFlight: make(map[string]bool)}
+	if strings.HasPrefix(spec, "http://") || strings.HasPrefix(spec, "https://") {
+		hook.url = spec
+		hook.client = &http.Client{Timeout: scaleHookTimeout}
+	} else {
+		hook.command = spec
+	}
+	return hook, nil
+}
+
+func (h *externalScaleHook) CapacityShortfall(shortfall CapacityShortfall) {
+	h.mu.Lock()
+	if h.inFlight[shortfall.RunID] {
+		h.mu.Unlock()
+		return
+	}
+	h.inFlight[shortfall.RunID] = true
+	h.mu.Unlock()
+
+	go func() {
+		defer func() {
+			h.mu.Lock()
+			delete(h.inFlight, shortfall.RunID)
+			h.mu.Unlock()
+		}()
+
+		var err error
+		if h.url != "" {
+			err = h.post(shortfall)
+		} else {
+			err = h.exec(shortfall)
+		}
+		if err != nil {
+			log.Printf("[RunManager] Scale hook failed for run %s: %v", shortfall.RunID, err)
+		}
+	}()
+}
+
+func (h *externalScaleHook) post(shortfall CapacityShortfall) error {
+	body, err := json.Marshal(shortfall)
+	if err != nil {
+		return err
+	}
+	resp, err := h.client.Post(h.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+func (h *externalScaleHook) exec(shortfall CapacityShortfall) error {
+	body, err := json.Marshal(shortfall)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), scaleHookTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", h.command)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Env = append(os.Environ(),
+		"MCPDRILL_RUN_ID="+shortfall.RunID,
+		"MCPDRILL_EXECUTION_ID="+shortfall.ExecutionID,
+		"MCPDRILL_STAGE="+string(shortfall.Stage),
+		"MCPDRILL_REQUIRED_VUS="+strconv.Itoa(shortfall.RequiredVUs),
+		"MCPDRILL_AVAILABLE_VUS="+strconv.Itoa(shortfall.AvailableVUs),
+		"MCPDRILL_REQUIRED_WORKERS="+strconv.Itoa(shortfall.RequiredWorkers),
+		"MCPDRILL_AVAILABLE_WORKERS="+strconv.Itoa(shortfall.AvailableWorkers),
+	)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,341 @@
+package runmanager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/runstore"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+	"github.com/bc-dunia/mcpdrill/internal/validation"
+)
+
+var (
+	// ErrScenarioNotFound is returned for a scenario or version that is not
+	// in the library.
+	ErrScenarioNotFound = errors.New("scenario not found")
+	// ErrInvalidScenarioName is returned when saving under a name that does
+	// not match scenarioNamePattern.
+	ErrInvalidScenarioName = errors.New("scenario name must be 1-128 letters, digits, '.', '_' or '-', starting with a letter or digit")
+)
+
+var scenarioNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,127}$`)
+
+// ScenarioVersion is one saved version of a named run config. Names are
+// unique within a project.
+type ScenarioVersion struct {
+	Project     string          `json:"project"`
+	Name        string          `json:"name"`
+	Version     int             `json:"version"`
+	ConfigHash  string          `json:"config_hash"`
+	Description string          `json:"description,omitempty"`
+	Actor       string          `json:"actor"`
+	CreatedAtMs int64           `json:"created_at_ms"`
+	Config      json.RawMessage `json:"config,omitempty"`
+}
+
+// ScenarioSummary describes a scenario by its latest version.
+type ScenarioSummary struct {
+	Project       string `json:"project"`
+	Name          string `json:"name"`
+	LatestVersion int    `json:"latest_version"`
+	VersionCount  int    `json:"version_count"`
+	ConfigHash    string `json:"config_hash"`
+	Description   string `json:"description,omitempty"`
+	CreatedAtMs   int64  `json:"created_at_ms"`
+	UpdatedAtMs   int64  `json:"updated_at_ms"`
+}
+
+// ScenarioRef names the scenario version a run was created from, in the
+// run's project. A zero Version refers to the latest version.
+type ScenarioRef struct {
+	Name    string `json:"name"`
+	Version int    `json:"version,omitempty"`
+}
+
+// scenarioKey identifies a scenario in the library.
+type scenarioKey struct {
+	project, name string
+}
+
+func newScenarioKey(project, name string) scenarioKey {
+	if project == "" {
+		project = types.DefaultProject
+	}
+	return scenarioKey{project: project, name: name}
+}
+
+// SaveScenario validates config and saves it as the next version of the
+// project's named scenario. If config is identical to the latest version, no version
+// is added and the latest is returned with created false. Configs are
+// stored in canonical JSON, so formatting and key order do not matter.
+func (rm *RunManager) SaveScenario(project, name string, config []byte, description, actor string) (*ScenarioVersion, bool, error) {
+	if !scenarioNamePattern.MatchString(name) {
+		return nil, false, ErrInvalidScenarioName
+	}
+	report := rm.ValidateRunConfig(config)
+	if !report.OK {
+		return nil, false, &validation.ValidationError{Report: report}
+	}
+	canonical, err := canonicalJSON(config)
+	if err != nil {
+		return nil, false, err
+	}
+	configHash := computeConfigHash(canonical)
+
+	key := newScenarioKey(project, name)
+	rm.scenarioMu.Lock()
+	defer rm.scenarioMu.Unlock()
+
+	versions := rm.scenarios[key]
+	if n := len(versions); n > 0 && versions[n-1].ConfigHash == configHash {
+		return copyScenarioVersion(versions[n-1], true), false, nil
+	}
+
+	version := &ScenarioVersion{
+		Project:     key.project,
+		Name:        name,
+		Version:     len(versions) + 1,
+		ConfigHash:  configHash,
+		Description: description,
+		Actor:       actor,
+		CreatedAtMs: time.Now().UnixMilli(),
+		Config:      canonical,
+	}
+	if n := len(versions); n > 0 {
+		version.Version = versions[n-1].Version + 1
+		if description == "" {
+			version.Description = versions[n-1].Description
+		}
+	}
+
+	if store := rm.scenarioStore(); store != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), persistWriteTimeout)
+		defer cancel()
+		if err := store.SaveScenarioVersion(ctx, &runstore.ScenarioVersion{
+			Project:     version.Project,
+			Name:        version.Name,
+			Version:     version.Version,
+			ConfigHash:  version.ConfigHash,
+			Description: version.Description,
+			Actor:       version.Actor,
+			CreatedAtMs: version.CreatedAtMs,
+			Config:      version.Config,
+		}); err != nil {
+			return nil, false, err
+		}
+	}
+
+	if rm.scenarios == nil {
+		rm.scenarios = make(map[scenarioKey][]*ScenarioVersion)
+	}
+	rm.scenarios[key] = append(versions, version)
+	return copyScenarioVersion(version, true), true, nil
+}
+
+// ListScenarios returns a summary of every scenario of project, ordered by
+// name.
+func (rm *RunManager) ListScenarios(project string) []ScenarioSummary {
+	if project == "" {
+		project = types.DefaultProject
+	}
+	rm.scenarioMu.Lock()
+	defer rm.scenarioMu.Unlock()
+
+	result := make([]ScenarioSummary, 0, len(rm.scenarios))
+	for key, versions := range rm.scenarios {
+		if key.project != project {
+			continue
+		}
+		latest := versions[len(versions)-1]
+		result = append(result, ScenarioSummary{
+			Project:       key.project,
+			Name:          key.name,
+			LatestVersion: latest.Version,
+			VersionCount:  len(versions),
+			ConfigHash:    latest.ConfigHash,
+			Description:   latest.Description,
+			CreatedAtMs:   versions[0].CreatedAtMs,
+			UpdatedAtMs:   latest.CreatedAtMs,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// GetScenarioVersions returns the version history of a scenario, oldest
+// first, without configs.
+func (rm *RunManager) GetScenarioVersions(project, name string) ([]*ScenarioVersion, error) {
+	rm.scenarioMu.Lock()
+	defer rm.scenarioMu.Unlock()
+
+	versions, ok := rm.scenarios[newScenarioKey(project, name)]
+	if !ok {
+		return nil, ErrScenarioNotFound
+	}
+	result := make([]*ScenarioVersion, len(versions))
+	for i, v := range versions {
+		result[i] = copyScenarioVersion(v, false)
+	}
+	return result, nil
+}
+
+// GetScenarioVersion returns one version of a scenario with its config, the
+// latest when version is 0.
+func (rm *RunManager) GetScenarioVersion(project, name string, version int) (*ScenarioVersion, error) {
+	rm.scenarioMu.Lock()
+	defer rm.scenarioMu.Unlock()
+
+	versions, ok := rm.scenarios[newScenarioKey(project, name)]
+	if !ok {
+		return nil, ErrScenarioNotFound
+	}
+	if version == 0 {
+		return copyScenarioVersion(versions[len(versions)-1], true), nil
+	}
+	for _, v := range versions {
+		if v.Version == version {
+			return copyScenarioVersion(v, true), nil
+		}
+	}
+	return nil, ErrScenarioNotFound
+}
+
+// DeleteScenario removes a scenario and all its versions. Runs created from
+// it keep their config.
+func (rm *RunManager) DeleteScenario(project, name string) error {
+	key := newScenarioKey(project, name)
+	rm.scenarioMu.Lock()
+	defer rm.scenarioMu.Unlock()
+
+	if _, ok := rm.scenarios[key]; !ok {
+		return ErrScenarioNotFound
+	}
+	if store := rm.scenarioStore(); store != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), persistWriteTimeout)
+		defer cancel()
+		if err := store.DeleteScenario(ctx, key.project, name); err != nil {
+			return err
+		}
+	}
+	delete(rm.scenarios, key)
+	return nil
+}
+
+// CreateRunFromScenario creates a run in project from one of the project's
+// saved scenario versions, with overrides applied to its config as a JSON
+// merge patch (RFC 7386) and then params. The result is validated like any
+// run config.
+func (rm *RunManager) CreateRunFromScenario(project string, ref ScenarioRef, overrides json.RawMessage, params *RunParameters, tags map[string]string, actor string) (string, error) {
+	version, err := rm.GetScenarioVersion(project, ref.Name, ref.Version)
+	if err != nil {
+		return "", err
+	}
+
+	config := []byte(version.Config)
+	if len(overrides) > 0 && string(overrides) != "null" {
+		config, err = applyMergePatch(config, overrides)
+		if err != nil {
+			return "", NewInvalidArgumentError("", err)
+		}
+	}
+
+	return rm.createRun(version.Project, config, actor, &ScenarioRef{Name: version.Name, Version: version.Version}, params, tags)
+}
+
+// LoadScenarios loads the scenario library held by the run store and
+// returns how many versions were loaded.
+func (rm *RunManager) LoadScenarios(ctx context.Context) (int, error) {
+	store := rm.scenarioStore()
+	if store == nil {
+		return 0, fmt.Errorf("run store not configured")
+	}
+	stored, err := store.ListScenarioVersions(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	scenarios := make(map[scenarioKey][]*ScenarioVersion)
+	for _, v := range stored {
+		key := newScenarioKey(v.Project, v.Name)
+		scenarios[key] = append(scenarios[key], &ScenarioVersion{
+			Project:     key.project,
+			Name:        v.Name,
+			Version:     v.Version,
+			ConfigHash:  v.ConfigHash,
+			Description: v.Description,
+			Actor:       v.Actor,
+			CreatedAtMs: v.CreatedAtMs,
+			Config:      v.Config,
+		})
+	}
+
+	rm.scenarioMu.Lock()
+	rm.scenarios = scenarios
+	rm.scenarioMu.Unlock()
+	return len(stored), nil
+}
+
+// scenarioStore returns the run store scenarios are persisted to, if any.
+func (rm *RunManager) scenarioStore() runstore.Store {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	if rm.persister == nil {
+		return nil
+	}
+	return rm.persister.store
+}
+
+func copyScenarioVersion(v *ScenarioVersion, withConfig bool) *ScenarioVersion {
+	c := *v
+	c.Config = nil
+	if withConfig {
+		c.Config = append(json.RawMessage(nil), v.Config...)
+	}
+	return &c
+}
+
+// canonicalJSON re-encodes a JSON document with sorted keys and no
+// insignificant whitespace.
+func canonicalJSON(data []byte) ([]byte, error) {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("invalid config JSON: %w", err)
+	}
+	return json.Marshal(v)
+}
+
+// applyMergePatch applies an RFC 7386 JSON merge patch to a document.
+func applyMergePatch(doc, patch []byte) ([]byte, error) {
+	var target, p interface{}
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, fmt.Errorf("invalid config JSON: %w", err)
+	}
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return nil, fmt.Errorf("invalid overrides JSON: %w", err)
+	}
+	return json.Marshal(mergePatch(target, p))
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = make(map[string]interface{})
+	}
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+			continue
+		}
+		targetObj[key] = mergePatch(targetObj[key], value)
+	}
+	return targetObj
+}
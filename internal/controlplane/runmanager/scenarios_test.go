@@ -0,0 +1,163 @@
+package runmanager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/validation"
+)
+
+func TestSaveScenario_VersionsAndDedupes(t *testing.T) {
+	rm := NewRunManager(createTestValidator(t))
+	defer rm.Shutdown()
+
+	first, created, err := rm.SaveScenario("default", "checkout", createValidConfig(), "checkout flow", "alice")
+	if err != nil || !created || first.Version != 1 {
+		t.Fatalf("expected version 1 to be created, got %+v, %v, %v", first, created, err)
+	}
+
+	// The same config with different formatting is not a new version.
+	var reformatted map[string]interface{}
+	json.Unmarshal(createValidConfig(), &reformatted)
+	indented, _ := json.MarshalIndent(reformatted, "", "    ")
+	again, created, err := rm.SaveScenario("default", "checkout", indented, "", "bob")
+	if err != nil || created || again.Version != 1 {
+		t.Errorf("expected the identical config to dedupe to version 1, got %+v, %v, %v", again, created, err)
+	}
+
+	reformatted["scenario_id"] = "scn_checkout_v2"
+	changed, _ := json.Marshal(reformatted)
+	second, created, err := rm.SaveScenario("default", "checkout", changed, "", "bob")
+	if err != nil || !created || second.Version != 2 || second.Description != "checkout flow" {
+		t.Fatalf("expected version 2 to inherit the description, got %+v, %v, %v", second, created, err)
+	}
+
+	versions, err := rm.GetScenarioVersions("default", "checkout")
+	if err != nil || len(versions) != 2 || versions[0].Config != nil {
+		t.Errorf("expected 2 versions without configs, got %+v, %v", versions, err)
+	}
+	if list := rm.ListScenarios("default"); len(list) != 1 || list[0].LatestVersion != 2 || list[0].VersionCount != 2 {
+		t.Errorf("unexpected scenario list: %+v", list)
+	}
+
+	if _, _, err := rm.SaveScenario("default", "bad name", createValidConfig(), "", "alice"); !errors.Is(err, ErrInvalidScenarioName) {
+		t.Errorf("expected ErrInvalidScenarioName, got %v", err)
+	}
+	var validationErr *validation.ValidationError
+	if _, _, err := rm.SaveScenario("default", "broken", []byte(`{"schema_version":"run-config/v1"}`), "", "alice"); !errors.As(err, &validationErr) {
+		t.Errorf("expected a validation error, got %v", err)
+	}
+}
+
+func TestCreateRunFromScenario_AppliesOverrides(t *testing.T) {
+	rm := NewRunManager(createTestValidator(t))
+	defer rm.Shutdown()
+
+	if _, _, err := rm.SaveScenario("default", "checkout", createValidConfig(), "", "alice"); err != nil {
+		t.Fatalf("SaveScenario failed: %v", err)
+	}
+
+	runID, err := rm.CreateRunFromScenario("default", ScenarioRef{Name: "checkout"}, json.RawMessage(`{"scenario_id":"scn_override"}`), nil, nil, "alice")
+	if err != nil {
+		t.Fatalf("CreateRunFromScenario failed: %v", err)
+	}
+	view, err := rm.GetRun(runID)
+	if err != nil {
+		t.Fatalf("GetRun failed: %v", err)
+	}
+	if view.ScenarioID != "scn_override" {
+		t.Errorf("expected the override to be applied, got scenario_id %q", view.ScenarioID)
+	}
+	if view.ScenarioRef == nil || view.ScenarioRef.Name != "checkout" || view.ScenarioRef.Version != 1 {
+		t.Errorf("expected the run to record checkout version 1, got %+v", view.ScenarioRef)
+	}
+
+	if _, err := rm.CreateRunFromScenario("default", ScenarioRef{Name: "checkout", Version: 7}, nil, nil, nil, "alice"); !errors.Is(err, ErrScenarioNotFound) {
+		t.Errorf("expected ErrScenarioNotFound for a missing version, got %v", err)
+	}
+	if _, err := rm.CreateRunFromScenario("default", ScenarioRef{Name: "checkout"}, json.RawMessage(`{"stages":null}`), nil, nil, "alice"); err == nil {
+		t.Error("expected overrides that break the config to fail validation")
+	}
+}
+
+func TestScenarios_ScopedToProject(t *testing.T) {
+	rm := NewRunManager(createTestValidator(t))
+	defer rm.Shutdown()
+
+	if _, _, err := rm.SaveScenario("team-a", "checkout", createValidConfig(), "", "alice"); err != nil {
+		t.Fatalf("SaveScenario failed: %v", err)
+	}
+	if list := rm.ListScenarios("default"); len(list) != 0 {
+		t.Errorf("expected no scenarios in the default project, got %+v", list)
+	}
+	if _, err := rm.CreateRunFromScenario("team-b", ScenarioRef{Name: "checkout"}, nil, nil, nil, "bob"); !errors.Is(err, ErrScenarioNotFound) {
+		t.Errorf("expected another project's scenario to be not found, got %v", err)
+	}
+
+	runID, err := rm.CreateRunFromScenario("team-a", ScenarioRef{Name: "checkout"}, nil, nil, nil, "alice")
+	if err != nil {
+		t.Fatalf("CreateRunFromScenario failed: %v", err)
+	}
+	if view, _ := rm.GetRun(runID); view.Project != "team-a" {
+		t.Errorf("expected the run in team-a, got %q", view.Project)
+	}
+	cloneID, err := rm.CloneRun(runID, "alice")
+	if err != nil {
+		t.Fatalf("CloneRun failed: %v", err)
+	}
+	if view, _ := rm.GetRun(cloneID); view.Project != "team-a" {
+		t.Errorf("expected the clone to stay in team-a, got %q", view.Project)
+	}
+	if _, err := rm.CreateRunInProject("Team A", createValidConfig(), nil, nil, "alice"); err == nil {
+		t.Error("expected an invalid project name to be rejected")
+	}
+}
+
+func TestRunStore_PersistsScenarios(t *testing.T) {
+	store := openTestRunStore(t)
+	validator := createTestValidator(t)
+
+	rm := NewRunManager(validator)
+	rm.SetRunStore(store)
+	if _, _, err := rm.SaveScenario("default", "checkout", createValidConfig(), "checkout flow", "alice"); err != nil {
+		t.Fatalf("SaveScenario failed: %v", err)
+	}
+	if _, _, err := rm.SaveScenario("default", "browse", createValidConfig(), "", "alice"); err != nil {
+		t.Fatalf("SaveScenario failed: %v", err)
+	}
+	if _, _, err := rm.SaveScenario("team-a", "browse", createValidConfig(), "", "alice"); err != nil {
+		t.Fatalf("SaveScenario failed: %v", err)
+	}
+	if err := rm.DeleteScenario("default", "browse"); err != nil {
+		t.Fatalf("DeleteScenario failed: %v", err)
+	}
+	runID, err := rm.CreateRunFromScenario("default", ScenarioRef{Name: "checkout"}, nil, nil, nil, "alice")
+	if err != nil {
+		t.Fatalf("CreateRunFromScenario failed: %v", err)
+	}
+	rm.Shutdown()
+
+	restarted := NewRunManager(validator)
+	defer restarted.Shutdown()
+	restarted.SetRunStore(store)
+	if n, err := restarted.LoadScenarios(context.Background()); err != nil || n != 2 {
+		t.Fatalf("expected 2 stored scenario versions, got %d, %v", n, err)
+	}
+	if list := restarted.ListScenarios("team-a"); len(list) != 1 || list[0].Name != "browse" {
+		t.Errorf("expected team-a's browse scenario to survive deleting the default one, got %+v", list)
+	}
+	if _, err := restarted.RecoverRuns(context.Background()); err != nil {
+		t.Fatalf("RecoverRuns failed: %v", err)
+	}
+
+	version, err := restarted.GetScenarioVersion("default", "checkout", 0)
+	if err != nil || version.Description != "checkout flow" || len(version.Config) == 0 {
+		t.Errorf("expected the checkout scenario to be loaded, got %+v, %v", version, err)
+	}
+	view, err := restarted.GetRun(runID)
+	if err != nil || view.ScenarioRef == nil || view.ScenarioRef.Name != "checkout" {
+		t.Errorf("expected the recovered run to keep its scenario ref, got %+v, %v", view, err)
+	}
+}
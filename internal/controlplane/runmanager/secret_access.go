@@ -0,0 +1,38 @@
+package runmanager
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// RecordSecretAccess records that workerID resolved the secret reference
+// ref for a run, as reported with the worker's heartbeats. Only the
+// reference is recorded; secret values never reach the control plane.
+func (rm *RunManager) RecordSecretAccess(runID, workerID, ref string, atMs int64) error {
+	rm.mu.RLock()
+	record, ok := rm.runs[runID]
+	if !ok {
+		rm.mu.RUnlock()
+		return NewNotFoundError(runID)
+	}
+	executionID := record.ExecutionID
+	eventLog := rm.eventLogs[runID]
+	rm.mu.RUnlock()
+
+	backend, _, _ := strings.Cut(ref, "://")
+	payload, _ := json.Marshal(map[string]interface{}{
+		"worker_id":      workerID,
+		"secret_ref":     ref,
+		"backend":        backend,
+		"accessed_at_ms": atMs,
+	})
+	appendEventWithLog(eventLog, RunEvent{
+		RunID:       runID,
+		ExecutionID: executionID,
+		Type:        EventTypeSecretAccessed,
+		Actor:       ActorWorker,
+		Payload:     payload,
+		Evidence:    []Evidence{{Kind: "worker", Ref: workerID}},
+	}, "RecordSecretAccess")
+	return nil
+}
@@ -0,0 +1,165 @@
+package runmanager
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+)
+
+// DefaultCheckpointIntervalMs is how often a soak stage reports progress when
+// the stage does not set checkpoint_interval_ms.
+const DefaultCheckpointIntervalMs = 900000 // 15 minutes
+
+// soakCheckpoints tracks what earlier checkpoints of a stage have covered.
+type soakCheckpoints struct {
+	seq          int
+	stageStartMs int64
+	lastMs       int64
+	opsSeen      int
+	totalOps     int
+	failedOps    int
+}
+
+// startSoakCheckpoints emits a STAGE_CHECKPOINT event every checkpoint
+// interval while a soak stage holds its load, so hours-long stages can be
+// followed without waiting for the final report. The returned function stops
+// it; it also stops when ctx is done.
+func (rm *RunManager) startSoakCheckpoints(ctx context.Context, runID string, stage *parsedStage) func() {
+	interval := time.Duration(stage.CheckpointIntervalMs) * time.Millisecond
+	if interval <= 0 {
+		interval = time.Duration(DefaultCheckpointIntervalMs) * time.Millisecond
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		nowMs := time.Now().UnixMilli()
+		state := &soakCheckpoints{stageStartMs: nowMs, lastMs: nowMs}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				rm.emitStageCheckpoint(runID, stage, state, now.UnixMilli())
+			}
+		}
+	}()
+	return cancel
+}
+
+// emitStageCheckpoint appends a checkpoint summarizing the stage's
+// operations since the previous checkpoint and since the stage started.
+func (rm *RunManager) emitStageCheckpoint(runID string, stage *parsedStage, state *soakCheckpoints, nowMs int64) {
+	rm.mu.RLock()
+	record, ok := rm.runs[runID]
+	if !ok {
+		rm.mu.RUnlock()
+		return
+	}
+	eventLog := rm.eventLogs[runID]
+	executionID := record.ExecutionID
+	telemetryStore := rm.telemetryStore
+	serverMetricsSource := rm.serverMetricsSource
+	rm.mu.RUnlock()
+
+	aggregator := analysis.NewAggregator()
+	aggregator.SetTimeRange(state.lastMs, nowMs)
+	if telemetryStore != nil {
+		if data, err := telemetryStore.GetTelemetryData(runID); err == nil && data != nil {
+			// opsSeen counts spilled and dropped operations too, which
+			// have left data.Operations.
+			older := data.SpilledOperations + data.DroppedOperations
+			total := older + len(data.Operations)
+			if total < state.opsSeen {
+				state.opsSeen = 0
+			}
+			for _, op := range data.Operations[max(state.opsSeen-older, 0):] {
+				if op.Stage == stage.Stage {
+					aggregator.AddOperation(op)
+				}
+			}
+			state.opsSeen = total
+		}
+	}
+	metrics := aggregator.Compute()
+
+	state.seq++
+	state.totalOps += metrics.TotalOps
+	state.failedOps += metrics.FailureOps
+	cumulativeErrorRate := 0.0
+	if state.totalOps > 0 {
+		cumulativeErrorRate = float64(state.failedOps) / float64(state.totalOps)
+	}
+
+	payload := map[string]interface{}{
+		"stage":       stage.Stage,
+		"stage_id":    stage.StageID,
+		"checkpoint":  state.seq,
+		"elapsed_ms":  nowMs - state.stageStartMs,
+		"interval_ms": nowMs - state.lastMs,
+		"interval": map[string]interface{}{
+			"total_ops":      metrics.TotalOps,
+			"failure_ops":    metrics.FailureOps,
+			"error_rate":     metrics.ErrorRate,
+			"rps":            metrics.RPS,
+			"latency_p50_ms": metrics.LatencyP50,
+			"latency_p95_ms": metrics.LatencyP95,
+			"latency_p99_ms": metrics.LatencyP99,
+		},
+		"cumulative": map[string]interface{}{
+			"total_ops":   state.totalOps,
+			"failure_ops": state.failedOps,
+			"error_rate":  cumulativeErrorRate,
+		},
+	}
+	if serverMetricsSource != nil {
+		if pairKey := rm.GetRunServerTelemetryPairKey(runID); pairKey != "" {
+			if rssMB, ok := latestProcessRSSMB(serverMetricsSource.NodeSeries(pairKey, state.lastMs, nowMs)); ok {
+				payload["server_rss_mb"] = rssMB
+			}
+		}
+	}
+	state.lastMs = nowMs
+
+	payloadBytes, _ := json.Marshal(payload)
+	stageName := StageName(stage.Stage)
+	stageID := stage.StageID
+	event := RunEvent{
+		RunID:       runID,
+		ExecutionID: executionID,
+		Type:        EventTypeStageCheckpoint,
+		Actor:       ActorSystem,
+		Correlation: CorrelationContext{
+			Stage:   &stageName,
+			StageID: &stageID,
+		},
+		Payload:  payloadBytes,
+		Evidence: []Evidence{},
+	}
+	appendEventWithLog(eventLog, event, "emitStageCheckpoint")
+}
+
+// latestProcessRSSMB returns the largest of the nodes' most recent process
+// RSS samples, in MiB.
+func latestProcessRSSMB(nodes []analysis.NodeSeries) (float64, bool) {
+	var (
+		maxRSS uint64
+		found  bool
+	)
+	for _, node := range nodes {
+		for i := len(node.Samples) - 1; i >= 0; i-- {
+			if rss := node.Samples[i].ProcessRSS; rss > 0 {
+				if rss > maxRSS {
+					maxRSS = rss
+				}
+				found = true
+				break
+			}
+		}
+	}
+	return float64(maxRSS) / (1024 * 1024), found
+}
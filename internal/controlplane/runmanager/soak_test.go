@@ -0,0 +1,86 @@
+package runmanager
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+)
+
+func TestEmitStageCheckpoint(t *testing.T) {
+	validator := createTestValidator(t)
+	rm := NewRunManager(validator)
+	runID, _ := rm.CreateRun(createValidConfig(), "test-user")
+
+	data := &TelemetryData{
+		RunID: runID,
+		Operations: []analysis.OperationResult{
+			{Operation: "tools/list", LatencyMs: 10, OK: true, Stage: "ramp"},
+			{Operation: "tools/list", LatencyMs: 20, OK: true, Stage: "soak"},
+			{Operation: "tools/list", LatencyMs: 30, OK: false, ErrorType: "timeout", Stage: "soak"},
+		},
+	}
+	rm.SetTelemetryStore(&mockTelemetryStore{data: map[string]*TelemetryData{runID: data}})
+
+	stage := &parsedStage{StageID: "stg_0000000000000003", Stage: "soak"}
+	state := &soakCheckpoints{stageStartMs: 0, lastMs: 0}
+	rm.emitStageCheckpoint(runID, stage, state, 1000)
+
+	data.Operations = append(data.Operations, analysis.OperationResult{Operation: "tools/list", LatencyMs: 40, OK: true, Stage: "soak"})
+	rm.emitStageCheckpoint(runID, stage, state, 3000)
+
+	events, _ := rm.TailEvents(runID, 0, 100)
+	var checkpoints []RunEvent
+	for _, event := range events {
+		if event.Type == EventTypeStageCheckpoint {
+			checkpoints = append(checkpoints, event)
+		}
+	}
+	if len(checkpoints) != 2 {
+		t.Fatalf("expected 2 STAGE_CHECKPOINT events, got %d", len(checkpoints))
+	}
+	if stageID := checkpoints[0].Correlation.StageID; stageID == nil || *stageID != stage.StageID {
+		t.Errorf("expected checkpoint correlated to %s, got %v", stage.StageID, stageID)
+	}
+
+	var first, second struct {
+		Checkpoint int   `json:"checkpoint"`
+		ElapsedMs  int64 `json:"elapsed_ms"`
+		IntervalMs int64 `json:"interval_ms"`
+		Interval   struct {
+			TotalOps   int `json:"total_ops"`
+			FailureOps int `json:"failure_ops"`
+		} `json:"interval"`
+		Cumulative struct {
+			TotalOps   int     `json:"total_ops"`
+			FailureOps int     `json:"failure_ops"`
+			ErrorRate  float64 `json:"error_rate"`
+		} `json:"cumulative"`
+	}
+	json.Unmarshal(checkpoints[0].Payload, &first)
+	json.Unmarshal(checkpoints[1].Payload, &second)
+
+	if first.Checkpoint != 1 || first.Interval.TotalOps != 2 || first.Interval.FailureOps != 1 {
+		t.Errorf("expected first checkpoint to cover the 2 soak operations, got %+v", first)
+	}
+	if second.Checkpoint != 2 || second.Interval.TotalOps != 1 || second.IntervalMs != 2000 || second.ElapsedMs != 3000 {
+		t.Errorf("expected second checkpoint to cover only the new operation, got %+v", second)
+	}
+	if second.Cumulative.TotalOps != 3 || second.Cumulative.FailureOps != 1 {
+		t.Errorf("expected cumulative totals of 3 ops / 1 failure, got %+v", second.Cumulative)
+	}
+}
+
+func TestLatestProcessRSSMB(t *testing.T) {
+	const mib = 1024 * 1024
+	nodes := []analysis.NodeSeries{
+		{AgentID: "a", Samples: []analysis.NodeSample{{ProcessRSS: 300 * mib}, {ProcessRSS: 100 * mib}, {}}},
+		{AgentID: "b", Samples: []analysis.NodeSample{{ProcessRSS: 250 * mib}}},
+	}
+	if rss, ok := latestProcessRSSMB(nodes); !ok || rss != 250 {
+		t.Errorf("expected 250 MiB, got %v (ok=%v)", rss, ok)
+	}
+	if _, ok := latestProcessRSSMB([]analysis.NodeSeries{{AgentID: "a", Samples: []analysis.NodeSample{{}}}}); ok {
+		t.Error("expected no RSS without process samples")
+	}
+}
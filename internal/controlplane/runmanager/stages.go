@@ -64,7 +64,8 @@ func (rm *RunManager) startStageProgression(runID string, config []byte, actor s
 	preflightStage := findStageByName(parsedConfig, StageNamePreflight)
 	baselineStage := findStageByName(parsedConfig, StageNameBaseline)
 	rampStage := findStageByName(parsedConfig, StageNameRamp)
-	if preflightStage == nil || baselineStage == nil || rampStage == nil {
+	conformanceRun := parsedConfig.isConformanceRun()
+	if preflightStage == nil || !conformanceRun && (baselineStage == nil || rampStage == nil) {
 		log.Printf("[RunManager] Stage progression disabled for run %s: missing required stages", runID)
 		return
 	}
@@ -85,9 +86,18 @@ func (rm *RunManager) startStageProgression(runID string, config []byte, actor s
 	rm.mu.Unlock()
 
 	go func() {
+		stageStartMs := time.Now().UnixMilli()
 		if !rm.waitForStageDurationWithTimeout(ctx, runID, preflightStage, actor) {
 			return
 		}
+		// The conformance report stops a conformance run as it arrives, so
+		// one still running at the end of preflight never got a report.
+		if conformanceRun {
+			log.Printf("[RunManager] No conformance report for run %s by the end of preflight, stopping run", runID)
+			_ = rm.requestStopWithReason(runID, StopModeDrain, string(ActorSystem), "conformance_not_reported", nil)
+			return
+		}
+		stageStartMs = rm.completeStageReport(runID, StageNamePreflight, stageStartMs)
 		if err := rm.TransitionToBaseline(runID, actor); err != nil {
 			log.Printf("[RunManager] Failed to transition run %s to baseline: %v, stopping run", runID, err)
 			_ = rm.requestStopWithReason(runID, StopModeImmediate, string(ActorSystem), "preflight_passed_timeout", nil)
@@ -97,6 +107,7 @@ func (rm *RunManager) startStageProgression(runID string, config []byte, actor s
 		if !rm.waitForStageDurationWithTimeout(ctx, runID, baselineStage, actor) {
 			return
 		}
+		stageStartMs = rm.completeStageReport(runID, StageNameBaseline, stageStartMs)
 		if err := rm.TransitionToRamp(runID, actor); err != nil {
 			log.Printf("[RunManager] Failed to transition run %s to ramp: %v, stopping run", runID, err)
 			_ = rm.requestStopWithReason(runID, StopModeImmediate, string(ActorSystem), "stage_transition_failed", nil)
@@ -107,17 +118,26 @@ func (rm *RunManager) startStageProgression(runID string, config []byte, actor s
 			return
 		}
 
-		soakStage := findStageByName(parsedConfig, StageNameSoak)
-		if soakStage != nil && soakStage.Enabled {
-			if err := rm.TransitionToSoak(runID, actor); err != nil {
-				log.Printf("[RunManager] Failed to transition run %s to soak: %v, stopping run", runID, err)
+		previous := StageNameRamp
+		for _, stage := range postRampStages(parsedConfig) {
+			stageName := StageName(stage.Stage)
+			stageStartMs = rm.completeStageReport(runID, previous, stageStartMs)
+			if err := rm.transitionToPostRampStage(runID, stageName, actor); err != nil {
+				log.Printf("[RunManager] Failed to transition run %s to %s: %v, stopping run", runID, stageName, err)
 				_ = rm.requestStopWithReason(runID, StopModeImmediate, string(ActorSystem), "stage_transition_failed", nil)
 				return
 			}
 
-			if !rm.waitForStageDurationWithTimeout(ctx, runID, soakStage, actor) {
+			stopCheckpoints := func() {}
+			if stageName == StageNameSoak {
+				stopCheckpoints = rm.startSoakCheckpoints(ctx, runID, stage)
+			}
+			completed := rm.waitForStageDurationWithTimeout(ctx, runID, stage, actor)
+			stopCheckpoints()
+			if !completed {
 				return
 			}
+			previous = stageName
 		}
 
 		if err := rm.RequestStop(runID, StopModeDrain, actor); err != nil {
@@ -127,25 +147,12 @@ func (rm *RunManager) startStageProgression(runID string, config []byte, actor s
 }
 
 func (rm *RunManager) waitForStageDuration(ctx context.Context, runID string, duration time.Duration) bool {
-	if duration <= 0 {
-		return true
-	}
-
-	timer := time.NewTimer(duration)
-	rm.addStageTimer(runID, timer)
-	defer rm.removeStageTimer(runID, timer)
-
-	select {
-	case <-ctx.Done():
-		stopTimer(timer)
-		return false
-	case <-timer.C:
-		return true
-	}
+	return rm.waitRunning(ctx, runID, duration)
 }
 
 // waitForStageDurationWithTimeout waits for stage duration with a max_duration safety timeout.
 // If max_duration fires first, emits STAGE_TIMEOUT and transitions to STOPPING.
+// Both clocks stand still while the run is paused.
 func (rm *RunManager) waitForStageDurationWithTimeout(ctx context.Context, runID string, stage *parsedStage, actor string) bool {
 	duration := time.Duration(stage.DurationMs) * time.Millisecond
 	maxDuration := getStageMaxDuration(stage)
@@ -153,41 +160,17 @@ func (rm *RunManager) waitForStageDurationWithTimeout(ctx context.Context, runID
 	if duration <= 0 {
 		return true
 	}
-
-	plannedTimer := time.NewTimer(duration)
-	rm.addStageTimer(runID, plannedTimer)
-	defer rm.removeStageTimer(runID, plannedTimer)
-
-	var maxTimer *time.Timer
-	var maxTimerChan <-chan time.Time
-	if maxDuration > 0 && maxDuration < duration {
-		maxTimer = time.NewTimer(maxDuration)
-		maxTimerChan = maxTimer.C
-		rm.addStageTimer(runID, maxTimer)
-		defer func() {
-			if maxTimer != nil {
-				rm.removeStageTimer(runID, maxTimer)
-			}
-		}()
+	if maxDuration <= 0 || maxDuration >= duration {
+		return rm.waitRunning(ctx, runID, duration)
 	}
 
-	select {
-	case <-ctx.Done():
-		stopTimer(plannedTimer)
-		if maxTimer != nil {
-			stopTimer(maxTimer)
-		}
-		return false
-	case <-maxTimerChan:
-		stopTimer(plannedTimer)
-		rm.handleStageTimeout(runID, stage, actor)
+	// The max_duration timeout is the shorter wait here, so waiting for it
+	// decides the stage: the planned duration cannot complete first.
+	if !rm.waitRunning(ctx, runID, maxDuration) {
 		return false
-	case <-plannedTimer.C:
-		if maxTimer != nil {
-			stopTimer(maxTimer)
-		}
-		return true
 	}
+	rm.handleStageTimeout(runID, stage, actor)
+	return false
 }
 
 // handleStageTimeout handles stage timeout by emitting event and transitioning to STOPPING.
@@ -262,6 +245,7 @@ func (rm *RunManager) removeStageTimer(runID string, timer *time.Timer) {
 }
 
 func (rm *RunManager) cancelStageProgressionLocked(record *RunRecord) {
+	rm.endPauseLocked(record)
 	if record.progressionCancel != nil {
 		record.progressionCancel()
 		record.progressionCancel = nil
@@ -283,6 +267,25 @@ func (rm *RunManager) stopStopConditionEvaluatorLocked(record *RunRecord) {
 	}
 }
 
+// stopConditionTelemetry feeds a run's in-memory operations to the stop
+// condition evaluator, along with how many have moved out of memory.
+type stopConditionTelemetry struct {
+	store TelemetryStore
+}
+
+func (t stopConditionTelemetry) GetOperations(runID string) ([]analysis.OperationResult, error) {
+	ops, _, err := t.GetRecentOperations(runID)
+	return ops, err
+}
+
+func (t stopConditionTelemetry) GetRecentOperations(runID string) ([]analysis.OperationResult, int, error) {
+	data, err := t.store.GetTelemetryData(runID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return data.Operations, data.SpilledOperations + data.DroppedOperations, nil
+}
+
 func (rm *RunManager) startStopConditionEvaluator(runID string, stage *parsedStage) {
 	// Start if either classic stop conditions OR streaming config present
 	if stage == nil || (len(stage.StopConditions) == 0 && stage.StreamingStopConfig == nil) {
@@ -292,6 +295,7 @@ func (rm *RunManager) startStopConditionEvaluator(runID string, stage *parsedSta
 	rm.mu.RLock()
 	record, ok := rm.runs[runID]
 	telemetryStore := rm.telemetryStore
+	serverMetricsSource := rm.serverMetricsSource
 	rm.mu.RUnlock()
 	if !ok {
 		return
@@ -314,29 +318,10 @@ func (rm *RunManager) startStopConditionEvaluator(runID string, stage *parsedSta
 	record.stopConditionsCancel = cancel
 	rm.mu.Unlock()
 
-	conditions := make([]stopconditions.Condition, len(stage.StopConditions))
-	for i, sc := range stage.StopConditions {
-		conditions[i] = stopconditions.Condition{
-			ID:             sc.ID,
-			Metric:         sc.Metric,
-			Comparator:     sc.Comparator,
-			Threshold:      sc.Threshold,
-			WindowMs:       sc.WindowMs,
-			SustainWindows: sc.SustainWindows,
-			Scope:          sc.Scope,
-		}
-	}
-
 	evaluator := stopconditions.NewEvaluator(
 		runID,
-		stopconditions.TelemetryProviderFunc(func(id string) ([]analysis.OperationResult, error) {
-			data, err := telemetryStore.GetTelemetryData(id)
-			if err != nil {
-				return nil, err
-			}
-			return data.Operations, nil
-		}),
-		conditions,
+		stopConditionTelemetry{telemetryStore},
+		toStopConditions(stage.StopConditions),
 		5*time.Second,
 	)
 	evaluator.StageID = stage.StageID
@@ -348,6 +333,14 @@ func (rm *RunManager) startStopConditionEvaluator(runID string, stage *parsedSta
 		}
 	}
 
+	if serverMetricsSource != nil {
+		if pairKey := rm.GetRunServerTelemetryPairKey(runID); pairKey != "" {
+			evaluator.ServerMetrics = stopconditions.ServerMetricsProviderFunc(func(_ string, fromMs, toMs int64) []analysis.NodeSeries {
+				return serverMetricsSource.NodeSeries(pairKey, fromMs, toMs)
+			})
+		}
+	}
+
 	if streamingProvider, ok := telemetryStore.(interface {
 		GetStreamingMetrics(string) (*telemetry.StreamingMetrics, error)
 	}); ok {
@@ -361,6 +354,29 @@ func (rm *RunManager) startStopConditionEvaluator(runID string, stage *parsedSta
 	go evaluator.Run(ctx.Done())
 }
 
+// toStopConditions converts parsed stop conditions, composite terms
+// included, for the evaluator.
+func toStopConditions(parsed []parsedStopCondition) []stopconditions.Condition {
+	if len(parsed) == 0 {
+		return nil
+	}
+	conditions := make([]stopconditions.Condition, len(parsed))
+	for i, sc := range parsed {
+		conditions[i] = stopconditions.Condition{
+			ID:             sc.ID,
+			Metric:         sc.Metric,
+			Comparator:     sc.Comparator,
+			Threshold:      sc.Threshold,
+			WindowMs:       sc.WindowMs,
+			SustainWindows: sc.SustainWindows,
+			Scope:          sc.Scope,
+			All:            toStopConditions(sc.All),
+			Any:            toStopConditions(sc.Any),
+		}
+	}
+	return conditions
+}
+
 func (rm *RunManager) handleStopConditionTrigger(runID string, stage *parsedStage, trigger stopconditions.Trigger) {
 	rm.mu.RLock()
 	record, ok := rm.runs[runID]
@@ -376,7 +392,7 @@ func (rm *RunManager) handleStopConditionTrigger(runID string, stage *parsedStag
 	stageName := StageName(stage.Stage)
 	stageID := stage.StageID
 
-	payload, _ := json.Marshal(map[string]interface{}{
+	fields := map[string]interface{}{
 		"condition_id": trigger.Condition.ID,
 		"metric":       trigger.Condition.Metric,
 		"comparator":   trigger.Condition.Comparator,
@@ -388,7 +404,14 @@ func (rm *RunManager) handleStopConditionTrigger(runID string, stage *parsedStag
 		"latency_p99":  trigger.LatencyP99,
 		"stage":        stage.Stage,
 		"stage_id":     stage.StageID,
-	})
+	}
+	if len(trigger.Condition.Scope) > 0 {
+		fields["scope"] = trigger.Condition.Scope
+	}
+	if len(trigger.Terms) > 0 {
+		fields["terms"] = trigger.Terms
+	}
+	payload, _ := json.Marshal(fields)
 
 	evidenceNote := fmt.Sprintf("observed=%v threshold=%v window_ms=%d", trigger.Observed, trigger.Condition.Threshold, trigger.WindowMs)
 	triggerEvent := RunEvent{
@@ -413,10 +436,16 @@ func (rm *RunManager) handleStopConditionTrigger(runID string, stage *parsedStag
 		trigger.Condition.Threshold,
 		trigger.Observed,
 	)
+	stopReasonMsg := fmt.Sprintf("%s threshold exceeded: %.2f > %.2f", trigger.Condition.Metric, trigger.Observed, trigger.Condition.Threshold)
+	if trigger.Condition.Metric == stopconditions.MetricComposite {
+		reason = fmt.Sprintf("stop_condition_triggered: %s (%d of %d terms breached)",
+			trigger.Condition.ID, int(trigger.Observed), len(trigger.Terms))
+		stopReasonMsg = fmt.Sprintf("%s composite condition met: %d of %d terms breached",
+			trigger.Condition.ID, int(trigger.Observed), len(trigger.Terms))
+	}
 
 	// Set stop reason in telemetry
 	if telemetryStore != nil {
-		stopReasonMsg := fmt.Sprintf("%s threshold exceeded: %.2f > %.2f", trigger.Condition.Metric, trigger.Observed, trigger.Condition.Threshold)
 		telemetryStore.SetRunMetadata(runID, "", stopReasonMsg)
 	}
 
@@ -648,6 +677,11 @@ func (rm *RunManager) TransitionToRamp(runID, actor string) error {
 
 // startAutoRamp implements progressive VU scaling during ramp stage
 func (rm *RunManager) startAutoRamp(runID, executionID string, config []byte, eventLog *EventLog, stage *parsedStage, parsedConfig *parsedRunConfig) {
+	if stage.Ramp != nil && stage.Ramp.Mode == RampModeAdaptive {
+		rm.startAdaptiveRamp(runID, executionID, eventLog, stage, parsedConfig)
+		return
+	}
+
 	targetVUs := stage.Load.TargetVUs
 	if targetVUs <= 0 {
 		log.Printf("[RunManager] Invalid target VUs for auto-ramp: %d", targetVUs)
@@ -703,17 +737,9 @@ func (rm *RunManager) startAutoRamp(runID, executionID string, config []byte, ev
 		defer cancel()
 		currentVUs := startVUs
 		for step := 1; step <= rampSteps; step++ {
-			timer := time.NewTimer(time.Duration(stepHoldMs) * time.Millisecond)
-			rm.addStageTimer(runID, timer)
-
-			select {
-			case <-ctx.Done():
-				timer.Stop()
-				rm.removeStageTimer(runID, timer)
+			if !rm.waitRunning(ctx, runID, time.Duration(stepHoldMs)*time.Millisecond) {
 				log.Printf("[RunManager] Auto-ramp cancelled for run %s", runID)
 				return
-			case <-timer.C:
-				rm.removeStageTimer(runID, timer)
 			}
 
 			rm.mu.RLock()
@@ -749,60 +775,113 @@ func (rm *RunManager) startAutoRamp(runID, executionID string, config []byte, ev
 	}()
 }
 
-// TransitionToSoak transitions a run from RAMP_RUNNING to SOAK_RUNNING.
-func (rm *RunManager) TransitionToSoak(runID, actor string) error {
-	rm.mu.Lock()
+// postRampStates maps the stages that may follow the ramp to the run state
+// they run in.
+var postRampStates = map[StageName]RunState{
+	StageNameSoak:  RunStateSoakRunning,
+	StageNameSpike: RunStateSpikeRunning,
+	StageNameStep:  RunStateStepRunning,
+}
 
-	record, ok := rm.runs[runID]
-	if !ok {
-		rm.mu.Unlock()
-		return NewNotFoundError(runID)
+// postRampStages returns the enabled soak, spike and step stages in config
+// order. Only the first enabled stage of each type runs.
+func postRampStages(config *parsedRunConfig) []*parsedStage {
+	var stages []*parsedStage
+	seen := make(map[StageName]bool)
+	for i := range config.Stages {
+		stage := &config.Stages[i]
+		name := StageName(stage.Stage)
+		if _, ok := postRampStates[name]; !ok || !stage.Enabled || seen[name] {
+			continue
+		}
+		seen[name] = true
+		stages = append(stages, stage)
 	}
+	return stages
+}
 
-	if record.State != RunStateRampRunning {
-		rm.mu.Unlock()
-		return NewInvalidStateError(runID, record.State, RunStateRampRunning, "transition to soak")
-	}
+// TransitionToSoak transitions a run from RAMP_RUNNING (or a spike or step
+// stage) to SOAK_RUNNING.
+func (rm *RunManager) TransitionToSoak(runID, actor string) error {
+	return rm.transitionToPostRampStage(runID, StageNameSoak, actor)
+}
 
-	if !CanTransition(record.State, RunStateSoakRunning) {
-		rm.mu.Unlock()
-		return NewInvalidTransitionError(runID, record.State, RunStateSoakRunning)
-	}
+// TransitionToSpike transitions a run from RAMP_RUNNING (or a step stage) to
+// SPIKE_RUNNING.
+func (rm *RunManager) TransitionToSpike(runID, actor string) error {
+	return rm.transitionToPostRampStage(runID, StageNameSpike, actor)
+}
 
-	oldState := record.State
-	record.State = RunStateSoakRunning
-	record.UpdatedAtMs = time.Now().UnixMilli()
+// TransitionToStep transitions a run from RAMP_RUNNING (or a spike stage) to
+// STEP_RUNNING.
+func (rm *RunManager) TransitionToStep(runID, actor string) error {
+	return rm.transitionToPostRampStage(runID, StageNameStep, actor)
+}
+
+func (rm *RunManager) transitionToPostRampStage(runID string, stageName StageName, actor string) error {
+	newState := postRampStates[stageName]
 
+	rm.mu.RLock()
+	record, ok := rm.runs[runID]
+	if !ok {
+		rm.mu.RUnlock()
+		return NewNotFoundError(runID)
+	}
 	configCopy := make([]byte, len(record.Config))
 	copy(configCopy, record.Config)
-	executionID := record.ExecutionID
-	eventLog := rm.eventLogs[runID]
-	rm.mu.Unlock()
+	rm.mu.RUnlock()
 
 	parsedConfig, err := parseRunConfig(configCopy)
 	if err != nil {
 		return fmt.Errorf("failed to parse config: %w", err)
 	}
-	soakStage := findStageByName(parsedConfig, StageNameSoak)
-	if soakStage == nil {
-		return fmt.Errorf("no enabled soak stage found")
+	stage := findStageByName(parsedConfig, stageName)
+	if stage == nil {
+		return fmt.Errorf("no enabled %s stage found", stageName)
 	}
 
 	rm.mu.Lock()
+
 	record, ok = rm.runs[runID]
-	if ok {
-		record.ActiveStage = &ActiveStageInfo{Stage: string(StageNameSoak), StageID: soakStage.StageID}
+	if !ok {
+		rm.mu.Unlock()
+		return NewNotFoundError(runID)
 	}
+
+	if record.State != RunStateRampRunning && record.State != RunStateSpikeRunning && record.State != RunStateStepRunning {
+		rm.mu.Unlock()
+		return NewInvalidStateError(runID, record.State, RunStateRampRunning, "transition to "+string(stageName))
+	}
+
+	if !CanTransition(record.State, newState) {
+		rm.mu.Unlock()
+		return NewInvalidTransitionError(runID, record.State, newState)
+	}
+
+	oldState := record.State
+	fromStage := string(StageNameRamp)
+	if record.ActiveStage != nil {
+		fromStage = record.ActiveStage.Stage
+	}
+	record.State = newState
+	record.UpdatedAtMs = time.Now().UnixMilli()
+	record.ActiveStage = &ActiveStageInfo{Stage: string(stageName), StageID: stage.StageID}
+	if record.rampCancel != nil {
+		record.rampCancel()
+		record.rampCancel = nil
+	}
+	executionID := record.ExecutionID
+	eventLog := rm.eventLogs[runID]
 	rm.mu.Unlock()
 
 	if l := events.GetGlobalEventLogger(); l != nil {
-		l.LogStageTransition(string(StageNameRamp), string(StageNameSoak), soakStage.StageID, "ramp_completed")
+		l.LogStageTransition(fromStage, string(stageName), stage.StageID, fromStage+"_completed")
 	}
 
 	transitionPayload, _ := json.Marshal(map[string]interface{}{
 		"from_state": oldState,
-		"to_state":   RunStateSoakRunning,
-		"trigger":    "soak_started",
+		"to_state":   newState,
+		"trigger":    string(stageName) + "_started",
 		"actor":      actor,
 	})
 	transitionEvent := RunEvent{
@@ -813,20 +892,21 @@ func (rm *RunManager) TransitionToSoak(runID, actor string) error {
 		Payload:     transitionPayload,
 		Evidence:    []Evidence{},
 	}
-	appendEventWithLog(eventLog, transitionEvent, "TransitionToSoak")
+	appendEventWithLog(eventLog, transitionEvent, "transitionToPostRampStage")
 
 	rm.mu.RLock()
-	registry := rm.registry
-	allocator := rm.allocator
-	leaseManager := rm.leaseManager
-	assignmentSender := rm.assignmentSender
+	schedulerReady := rm.registry != nil && rm.allocator != nil && rm.leaseManager != nil && rm.assignmentSender != nil
 	rm.mu.RUnlock()
 
-	if registry != nil && allocator != nil && leaseManager != nil && assignmentSender != nil {
-		rm.createAndDispatchAssignmentsForStage(runID, executionID, configCopy, eventLog, StageNameSoak)
+	if schedulerReady {
+		if stageName == StageNameSoak {
+			rm.createAndDispatchAssignmentsForStage(runID, executionID, configCopy, eventLog, StageNameSoak)
+		} else {
+			rm.startLoadPlateaus(runID, executionID, eventLog, stage, parsedConfig, newState)
+		}
 	}
 
-	rm.startStopConditionEvaluator(runID, soakStage)
+	rm.startStopConditionEvaluator(runID, stage)
 
 	return nil
 }
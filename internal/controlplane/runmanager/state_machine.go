@@ -2,6 +2,12 @@ package runmanager
 
 var allowedTransitions = map[RunState]map[RunState]struct{}{
 	RunStateCreated: {
+		RunStateQueued:           {},
+		RunStatePreflightRunning: {},
+		RunStateFailed:           {},
+		RunStateAborted:          {},
+	},
+	RunStateQueued: {
 		RunStatePreflightRunning: {},
 		RunStateFailed:           {},
 		RunStateAborted:          {},
@@ -10,28 +16,64 @@ var allowedTransitions = map[RunState]map[RunState]struct{}{
 		RunStatePreflightPassed: {},
 		RunStatePreflightFailed: {},
 		RunStateStopping:        {},
+		RunStateAborted:         {},
 	},
 	RunStatePreflightFailed: {
 		RunStateStopping: {},
+		RunStateAborted:  {},
 	},
 	RunStatePreflightPassed: {
 		RunStateBaselineRunning: {},
 		RunStateStopping:        {},
+		RunStateAborted:         {},
 	},
 	RunStateBaselineRunning: {
 		RunStateRampRunning: {},
+		RunStatePaused:      {},
 		RunStateStopping:    {},
+		RunStateAborted:     {},
 	},
 	RunStateRampRunning: {
+		RunStateSoakRunning:  {},
+		RunStateSpikeRunning: {},
+		RunStateStepRunning:  {},
+		RunStatePaused:       {},
+		RunStateStopping:     {},
+		RunStateAborted:      {},
+	},
+	RunStateSpikeRunning: {
 		RunStateSoakRunning: {},
+		RunStateStepRunning: {},
+		RunStatePaused:      {},
 		RunStateStopping:    {},
+		RunStateAborted:     {},
+	},
+	RunStateStepRunning: {
+		RunStateSoakRunning:  {},
+		RunStateSpikeRunning: {},
+		RunStatePaused:       {},
+		RunStateStopping:     {},
+		RunStateAborted:      {},
 	},
 	RunStateSoakRunning: {
+		RunStatePaused:   {},
 		RunStateStopping: {},
+		RunStateAborted:  {},
+	},
+	// A paused run resumes in the state it was paused in.
+	RunStatePaused: {
+		RunStateBaselineRunning: {},
+		RunStateRampRunning:     {},
+		RunStateSpikeRunning:    {},
+		RunStateStepRunning:     {},
+		RunStateSoakRunning:     {},
+		RunStateStopping:        {},
+		RunStateAborted:         {},
 	},
 	RunStateStopping: {
 		RunStateAnalyzing: {},
 		RunStateStopping:  {},
+		RunStateAborted:   {},
 	},
 	RunStateAnalyzing: {
 		RunStateCompleted: {},
@@ -40,6 +82,12 @@ var allowedTransitions = map[RunState]map[RunState]struct{}{
 	},
 }
 
+// ValidRunState reports whether state is a run state.
+func ValidRunState(state RunState) bool {
+	_, ok := allowedTransitions[state]
+	return ok || state == RunStateCompleted || state == RunStateFailed || state == RunStateAborted
+}
+
 // CanTransition reports whether a state transition is valid.
 func CanTransition(from, to RunState) bool {
 	allowed, ok := allowedTransitions[from]
@@ -21,13 +21,42 @@ func TestCanTransitionValid(t *testing.T) {
 		{RunStateBaselineRunning, RunStateRampRunning},
 		{RunStateBaselineRunning, RunStateStopping},
 		{RunStateRampRunning, RunStateSoakRunning},
+		{RunStateRampRunning, RunStateSpikeRunning},
+		{RunStateRampRunning, RunStateStepRunning},
 		{RunStateRampRunning, RunStateStopping},
+		{RunStateSpikeRunning, RunStateSoakRunning},
+		{RunStateSpikeRunning, RunStateStepRunning},
+		{RunStateSpikeRunning, RunStateStopping},
+		{RunStateStepRunning, RunStateSoakRunning},
+		{RunStateStepRunning, RunStateSpikeRunning},
+		{RunStateStepRunning, RunStateStopping},
 		{RunStateSoakRunning, RunStateStopping},
 		{RunStateStopping, RunStateAnalyzing},
 		{RunStateStopping, RunStateStopping},
 		{RunStateAnalyzing, RunStateCompleted},
 		{RunStateAnalyzing, RunStateFailed},
 		{RunStateAnalyzing, RunStateAborted},
+		{RunStatePreflightRunning, RunStateAborted},
+		{RunStatePreflightFailed, RunStateAborted},
+		{RunStatePreflightPassed, RunStateAborted},
+		{RunStateBaselineRunning, RunStateAborted},
+		{RunStateRampRunning, RunStateAborted},
+		{RunStateSpikeRunning, RunStateAborted},
+		{RunStateStepRunning, RunStateAborted},
+		{RunStateSoakRunning, RunStateAborted},
+		{RunStateStopping, RunStateAborted},
+		{RunStateBaselineRunning, RunStatePaused},
+		{RunStateRampRunning, RunStatePaused},
+		{RunStateSpikeRunning, RunStatePaused},
+		{RunStateStepRunning, RunStatePaused},
+		{RunStateSoakRunning, RunStatePaused},
+		{RunStatePaused, RunStateBaselineRunning},
+		{RunStatePaused, RunStateRampRunning},
+		{RunStatePaused, RunStateSpikeRunning},
+		{RunStatePaused, RunStateStepRunning},
+		{RunStatePaused, RunStateSoakRunning},
+		{RunStatePaused, RunStateStopping},
+		{RunStatePaused, RunStateAborted},
 	}
 
 	for _, tc := range valid {
@@ -51,13 +80,42 @@ func TestCanTransitionInvalid(t *testing.T) {
 		{RunStateBaselineRunning, RunStateRampRunning}:      {},
 		{RunStateBaselineRunning, RunStateStopping}:         {},
 		{RunStateRampRunning, RunStateSoakRunning}:          {},
+		{RunStateRampRunning, RunStateSpikeRunning}:         {},
+		{RunStateRampRunning, RunStateStepRunning}:          {},
 		{RunStateRampRunning, RunStateStopping}:             {},
+		{RunStateSpikeRunning, RunStateSoakRunning}:         {},
+		{RunStateSpikeRunning, RunStateStepRunning}:         {},
+		{RunStateSpikeRunning, RunStateStopping}:            {},
+		{RunStateStepRunning, RunStateSoakRunning}:          {},
+		{RunStateStepRunning, RunStateSpikeRunning}:         {},
+		{RunStateStepRunning, RunStateStopping}:             {},
 		{RunStateSoakRunning, RunStateStopping}:             {},
 		{RunStateStopping, RunStateAnalyzing}:               {},
 		{RunStateStopping, RunStateStopping}:                {},
 		{RunStateAnalyzing, RunStateCompleted}:              {},
 		{RunStateAnalyzing, RunStateFailed}:                 {},
 		{RunStateAnalyzing, RunStateAborted}:                {},
+		{RunStatePreflightRunning, RunStateAborted}:         {},
+		{RunStatePreflightFailed, RunStateAborted}:          {},
+		{RunStatePreflightPassed, RunStateAborted}:          {},
+		{RunStateBaselineRunning, RunStateAborted}:          {},
+		{RunStateRampRunning, RunStateAborted}:              {},
+		{RunStateSpikeRunning, RunStateAborted}:             {},
+		{RunStateStepRunning, RunStateAborted}:              {},
+		{RunStateSoakRunning, RunStateAborted}:              {},
+		{RunStateStopping, RunStateAborted}:                 {},
+		{RunStateBaselineRunning, RunStatePaused}:           {},
+		{RunStateRampRunning, RunStatePaused}:               {},
+		{RunStateSpikeRunning, RunStatePaused}:              {},
+		{RunStateStepRunning, RunStatePaused}:               {},
+		{RunStateSoakRunning, RunStatePaused}:               {},
+		{RunStatePaused, RunStateBaselineRunning}:           {},
+		{RunStatePaused, RunStateRampRunning}:               {},
+		{RunStatePaused, RunStateSpikeRunning}:              {},
+		{RunStatePaused, RunStateStepRunning}:               {},
+		{RunStatePaused, RunStateSoakRunning}:               {},
+		{RunStatePaused, RunStateStopping}:                  {},
+		{RunStatePaused, RunStateAborted}:                   {},
 	}
 
 	allStates := []RunState{
@@ -68,6 +126,9 @@ func TestCanTransitionInvalid(t *testing.T) {
 		RunStateBaselineRunning,
 		RunStateRampRunning,
 		RunStateSoakRunning,
+		RunStateSpikeRunning,
+		RunStateStepRunning,
+		RunStatePaused,
 		RunStateStopping,
 		RunStateAnalyzing,
 		RunStateCompleted,
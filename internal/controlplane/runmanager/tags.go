@@ -0,0 +1,126 @@
+package runmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"maps"
+	"regexp"
+	"time"
+)
+
+const (
+	// MaxRunTags caps the tags of one run.
+	MaxRunTags = 32
+	// maxTagValueLen caps the length of a tag value.
+	maxTagValueLen = 256
+)
+
+// tagKeyPattern is the form of a tag key. Keys leave out ':' so a filter
+// can be written as key:value.
+var tagKeyPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_./-]{0,62}$`)
+
+// ValidateRunTags reports whether tags can be set on a run.
+func ValidateRunTags(tags map[string]string) error {
+	if len(tags) > MaxRunTags {
+		return fmt.Errorf("a run has at most %d tags, got %d", MaxRunTags, len(tags))
+	}
+	for key, value := range tags {
+		if !tagKeyPattern.MatchString(key) {
+			return fmt.Errorf("invalid tag key %q: must be 1-63 letters, digits, '_', '.', '/' or '-', starting with a letter or digit", key)
+		}
+		if len(value) > maxTagValueLen {
+			return fmt.Errorf("value of tag %q is longer than %d bytes", key, maxTagValueLen)
+		}
+	}
+	return nil
+}
+
+// SetRunTags replaces the tags of a run, in any state.
+func (rm *RunManager) SetRunTags(runID string, tags map[string]string, actor string) (map[string]string, error) {
+	return rm.updateRunTags(runID, actor, func(map[string]string) map[string]string {
+		return maps.Clone(tags)
+	})
+}
+
+// PatchRunTags sets the tags of patch on a run and removes those given as
+// nil, keeping the others.
+func (rm *RunManager) PatchRunTags(runID string, patch map[string]*string, actor string) (map[string]string, error) {
+	return rm.updateRunTags(runID, actor, func(current map[string]string) map[string]string {
+		tags := maps.Clone(current)
+		if tags == nil {
+			tags = make(map[string]string, len(patch))
+		}
+		for key, value := range patch {
+			if value == nil {
+				delete(tags, key)
+			} else {
+				tags[key] = *value
+			}
+		}
+		return tags
+	})
+}
+
+// updateRunTags sets a run's tags to what update makes of the current ones
+// and records them in a RUN_TAGS_UPDATED event.
+func (rm *RunManager) updateRunTags(runID, actor string, update func(map[string]string) map[string]string) (map[string]string, error) {
+	rm.mu.Lock()
+	record, ok := rm.runs[runID]
+	if !ok {
+		rm.mu.Unlock()
+		return nil, NewNotFoundError(runID)
+	}
+	tags := update(record.Tags)
+	if err := ValidateRunTags(tags); err != nil {
+		rm.mu.Unlock()
+		return nil, NewInvalidArgumentError(runID, err)
+	}
+	if len(tags) == 0 {
+		tags = nil
+	}
+	record.Tags = tags
+	record.UpdatedAtMs = time.Now().UnixMilli()
+	executionID := record.ExecutionID
+	eventLog := rm.eventLogs[runID]
+	rm.mu.Unlock()
+
+	if eventLog != nil {
+		payload, _ := json.Marshal(map[string]interface{}{"tags": tagsOrEmpty(tags)})
+		event := RunEvent{
+			RunID:       runID,
+			ExecutionID: executionID,
+			Type:        EventTypeRunTagsUpdated,
+			Actor:       ActorType(actor),
+			Payload:     payload,
+			Evidence:    []Evidence{},
+		}
+		if err := eventLog.Append(event); err != nil {
+			log.Printf("[RunManager] Failed to append RUN_TAGS_UPDATED event for run %s: %v", runID, err)
+		}
+	}
+	return maps.Clone(tags), nil
+}
+
+// tagsOrEmpty keeps a run without tags encoded as {} in events.
+func tagsOrEmpty(tags map[string]string) map[string]string {
+	if tags == nil {
+		return map[string]string{}
+	}
+	return tags
+}
+
+// tagsFromEvent recovers the tags carried by a RUN_CREATED or
+// RUN_TAGS_UPDATED event; ok is false if the event carries none.
+func tagsFromEvent(event RunEvent) (tags map[string]string, ok bool) {
+	var payload struct {
+		Tags map[string]string `json:"tags"`
+	}
+	if err := json.Unmarshal(event.Payload, &payload); err != nil || payload.Tags == nil {
+		return nil, false
+	}
+	if len(payload.Tags) == 0 {
+		return nil, true
+	}
+	return payload.Tags, true
+}
@@ -0,0 +1,121 @@
+package runmanager
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRunTags(t *testing.T) {
+	store := openTestRunStore(t)
+	validator := createTestValidator(t)
+	rm := NewRunManager(validator)
+	rm.SetRunStore(store)
+
+	runID, err := rm.CreateRunInProject("", createValidConfig(), nil, map[string]string{"env": "staging", "nightly": ""}, "alice")
+	if err != nil {
+		t.Fatalf("CreateRunInProject failed: %v", err)
+	}
+	if _, err := rm.CreateRunInProject("", createValidConfig(), nil, map[string]string{"bad key": "x"}, "alice"); !IsInvalidArgument(err) {
+		t.Errorf("expected an invalid argument error for a bad tag key, got %v", err)
+	}
+
+	release := "1.4.0"
+	tags, err := rm.PatchRunTags(runID, map[string]*string{"nightly": nil, "release": &release}, "bob")
+	if err != nil {
+		t.Fatalf("PatchRunTags failed: %v", err)
+	}
+	if len(tags) != 2 || tags["env"] != "staging" || tags["release"] != "1.4.0" {
+		t.Errorf("unexpected tags after patch: %v", tags)
+	}
+	if err := rm.AbortRun(runID, "bob", "done"); err != nil {
+		t.Fatalf("AbortRun failed: %v", err)
+	}
+	if _, err := rm.SetRunTags(runID, map[string]string{"env": "prod", "verdict": "bad-target"}, "bob"); err != nil {
+		t.Fatalf("expected tags of an ended run to be editable, got %v", err)
+	}
+	long := map[string]string{"k": strings.Repeat("v", maxTagValueLen+1)}
+	if _, err := rm.SetRunTags(runID, long, "bob"); !IsInvalidArgument(err) {
+		t.Errorf("expected an invalid argument error for a long value, got %v", err)
+	}
+	rm.Shutdown()
+
+	restarted := NewRunManager(validator)
+	defer restarted.Shutdown()
+	restarted.SetRunStore(store)
+	if _, err := restarted.RecoverRuns(context.Background()); err != nil {
+		t.Fatalf("RecoverRuns failed: %v", err)
+	}
+	run, err := restarted.GetRun(runID)
+	if err != nil {
+		t.Fatalf("GetRun failed: %v", err)
+	}
+	if len(run.Tags) != 2 || run.Tags["env"] != "prod" || run.Tags["verdict"] != "bad-target" {
+		t.Errorf("expected the last tags to be recovered, got %v", run.Tags)
+	}
+	if run.TargetHost != "staging-gateway.example.com" {
+		t.Errorf("expected the target host to be recovered, got %q", run.TargetHost)
+	}
+}
+
+func TestQueryRuns(t *testing.T) {
+	rm := NewRunManager(createTestValidator(t))
+	defer rm.Shutdown()
+
+	create := func(project string, tags map[string]string, createdAtMs int64) string {
+		t.Helper()
+		runID, err := rm.CreateRunInProject(project, createValidConfig(), nil, tags, "alice")
+		if err != nil {
+			t.Fatalf("CreateRunInProject failed: %v", err)
+		}
+		rm.mu.Lock()
+		rm.runs[runID].CreatedAtMs = createdAtMs
+		rm.mu.Unlock()
+		return runID
+	}
+	oldNightly := create("default", map[string]string{"schedule": "nightly"}, 1000)
+	nightly := create("default", map[string]string{"schedule": "nightly", "env": "staging"}, 3000)
+	adHoc := create("default", nil, 2000)
+	create("team-b", map[string]string{"schedule": "nightly"}, 4000)
+	if err := rm.AbortRun(adHoc, "alice", ""); err != nil {
+		t.Fatalf("AbortRun failed: %v", err)
+	}
+
+	ids := func(views []*RunView) []string {
+		var out []string
+		for _, v := range views {
+			out = append(out, v.RunID)
+		}
+		return out
+	}
+	tests := []struct {
+		name   string
+		filter RunFilter
+		want   []string
+		total  int
+	}{
+		{"project, newest first", RunFilter{Project: "default"}, []string{nightly, adHoc, oldNightly}, 3},
+		{"tag with value", RunFilter{Project: "default", Tags: map[string]string{"schedule": "nightly"}}, []string{nightly, oldNightly}, 2},
+		{"tag key only", RunFilter{Project: "default", Tags: map[string]string{"env": ""}}, []string{nightly}, 1},
+		{"state", RunFilter{Project: "default", States: []RunState{RunStateAborted}}, []string{adHoc}, 1},
+		{"time range", RunFilter{Project: "default", CreatedSinceMs: 2000, CreatedUntilMs: 3000}, []string{adHoc}, 1},
+		{"target host", RunFilter{Project: "default", TargetHost: "Staging-Gateway.example.com"}, []string{nightly, adHoc, oldNightly}, 3},
+		{"other host", RunFilter{Project: "default", TargetHost: "api.example.com"}, nil, 0},
+		{"scenario", RunFilter{Project: "default", ScenarioID: "scn_other"}, nil, 0},
+		{"page", RunFilter{Project: "default", Ascending: true, Offset: 1, Limit: 1}, []string{adHoc}, 3},
+		{"past the end", RunFilter{Project: "default", Offset: 5}, nil, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			views, total := rm.QueryRuns(tt.filter)
+			if got := ids(views); strings.Join(got, ",") != strings.Join(tt.want, ",") || total != tt.total {
+				t.Errorf("got %v of %d, want %v of %d", got, total, tt.want, tt.total)
+			}
+		})
+	}
+
+	views, _ := rm.QueryRuns(RunFilter{Project: "default", SortBy: RunSortUpdatedAt})
+	if views[0].RunID != adHoc {
+		t.Errorf("expected the aborted run to be the most recently updated, got %v", ids(views))
+	}
+}
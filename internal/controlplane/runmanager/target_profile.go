@@ -0,0 +1,214 @@
+package runmanager
+
+import (
+	"encoding/json"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/artifacts"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+// TargetProfileFilename is the artifact a run's target profile is stored as.
+const TargetProfileFilename = "target_profile.json"
+
+// RecordTargetProfile records what a run's target advertised when a worker
+// probed it during preflight. Only the first profile of a run is kept; it
+// returns false for later ones. The profile is stored as the
+// target_profile.json artifact and announced by TARGET_PROFILED. If tools
+// the workload calls are missing from the target, preflight fails right
+// away: STAGE_FAILED names them and the run is stopped.
+func (rm *RunManager) RecordTargetProfile(runID, workerID string, profile *types.TargetProfile) (bool, error) {
+	rm.mu.Lock()
+	record, ok := rm.runs[runID]
+	if !ok {
+		rm.mu.Unlock()
+		return false, NewNotFoundError(runID)
+	}
+	if record.State == RunStateCompleted || record.State == RunStateFailed || record.State == RunStateAborted {
+		rm.mu.Unlock()
+		return false, NewTerminalStateError(runID, record.State, "record target profile")
+	}
+	if record.targetProfiled {
+		rm.mu.Unlock()
+		return false, nil
+	}
+	record.targetProfiled = true
+	executionID := record.ExecutionID
+	configCopy := append([]byte(nil), record.Config...)
+	liveOpMix := record.opMix
+	artifactStore := rm.artifactStore
+	eventLog := rm.eventLogs[runID]
+	rm.mu.Unlock()
+
+	evidence := []Evidence{}
+	var artifactPath string
+	if artifactStore != nil {
+		data, err := json.MarshalIndent(profile, "", "  ")
+		if err == nil {
+			var info *artifacts.ArtifactInfo
+			info, err = artifactStore.SaveArtifact(runID, artifacts.ArtifactTypeReport, TargetProfileFilename, data)
+			if err == nil {
+				artifactPath = info.Path
+				evidence = append(evidence, Evidence{Kind: "artifact", Ref: info.Path, Note: stringPtr("Target profile")})
+			}
+		}
+		if err != nil {
+			log.Printf("[RunManager] Failed to store target profile of run %s: %v", runID, err)
+		}
+	}
+
+	listErrors := profile.ListErrors
+	if listErrors == nil {
+		listErrors = map[string]string{}
+	}
+	payload, _ := json.Marshal(map[string]interface{}{
+		"worker_id":        workerID,
+		"protocol_version": profile.ProtocolVersion,
+		"server_name":      profile.ServerInfo.Name,
+		"server_version":   profile.ServerInfo.Version,
+		"capabilities":     capabilityNames(profile.Capabilities),
+		"tool_count":       len(profile.Tools),
+		"resource_count":   len(profile.Resources),
+		"prompt_count":     len(profile.Prompts),
+		"list_errors":      listErrors,
+		"artifact_path":    artifactPath,
+	})
+	appendEventWithLog(eventLog, RunEvent{
+		RunID:       runID,
+		ExecutionID: executionID,
+		Type:        EventTypeTargetProfiled,
+		Actor:       ActorWorker,
+		Payload:     payload,
+		Evidence:    evidence,
+	}, "RecordTargetProfile")
+
+	// Without a tools list there is nothing to check the workload against.
+	if _, failed := profile.ListErrors["tools/list"]; failed {
+		return true, nil
+	}
+	missing := missingTools(configCopy, liveOpMix, profile.Tools)
+	if len(missing) > 0 {
+		rm.failPreflightMissingTools(runID, missing, len(profile.Tools))
+	}
+	return true, nil
+}
+
+// failPreflightMissingTools emits STAGE_FAILED for the tools the target
+// lacks, moves a run still in preflight to PREFLIGHT_FAILED and stops it.
+func (rm *RunManager) failPreflightMissingTools(runID string, missing []string, advertised int) {
+	rm.mu.Lock()
+	record, ok := rm.runs[runID]
+	if !ok {
+		rm.mu.Unlock()
+		return
+	}
+	eventLog := rm.eventLogs[runID]
+
+	stageID := ""
+	if record.ActiveStage != nil {
+		stageID = record.ActiveStage.StageID
+	}
+	failedPayload, _ := json.Marshal(map[string]interface{}{
+		"stage":            StageNamePreflight,
+		"stage_id":         stageID,
+		"reason":           "target_tools_missing",
+		"message":          "The target does not advertise tools the workload calls: " + strings.Join(missing, ", "),
+		"missing_tools":    missing,
+		"advertised_tools": advertised,
+	})
+	appendEventWithLog(eventLog, RunEvent{
+		RunID:       runID,
+		ExecutionID: record.ExecutionID,
+		Type:        EventTypeStageFailed,
+		Actor:       ActorSystem,
+		Payload:     failedPayload,
+		Evidence:    []Evidence{},
+	}, "failPreflightMissingTools")
+
+	if record.State == RunStatePreflightRunning {
+		rm.cancelStageProgressionLocked(record)
+		record.State = RunStatePreflightFailed
+		record.UpdatedAtMs = time.Now().UnixMilli()
+		transitionPayload, _ := json.Marshal(map[string]interface{}{
+			"from_state": RunStatePreflightRunning,
+			"to_state":   RunStatePreflightFailed,
+			"trigger":    "target_tools_missing",
+			"actor":      ActorSystem,
+		})
+		appendEventWithLog(eventLog, RunEvent{
+			RunID:       runID,
+			ExecutionID: record.ExecutionID,
+			Type:        EventTypeStateTransition,
+			Actor:       ActorSystem,
+			Payload:     transitionPayload,
+			Evidence:    []Evidence{},
+		}, "failPreflightMissingTools")
+	}
+	rm.mu.Unlock()
+
+	if err := rm.requestStopWithReason(runID, StopModeImmediate, string(ActorSystem), "preflight_failed", nil); err != nil {
+		log.Printf("[RunManager] Failed to stop run %s after preflight failed: %v", runID, err)
+	}
+}
+
+// missingTools returns the tools the workload of config, or its live op
+// mix, calls that are not in advertised, sorted. Templated tool names are
+// only known per call, so they are not checked.
+func missingTools(config []byte, liveOpMix []types.OpMixEntry, advertised []types.Tool) []string {
+	parsedConfig, err := parseRunConfig(config)
+	if err != nil {
+		return nil
+	}
+
+	referenced := make(map[string]struct{})
+	add := func(name string) {
+		if name != "" && !strings.Contains(name, "{{") {
+			referenced[name] = struct{}{}
+		}
+	}
+	if parsedConfig.Workload.Tools != nil && parsedConfig.Workload.Tools.Selection.Mode != toolSelectionAutoDiscover {
+		for _, tmpl := range parsedConfig.Workload.Tools.Templates {
+			add(tmpl.ToolName)
+		}
+	}
+	for _, op := range parsedConfig.Workload.OpMix {
+		if op.Operation == "tools/call" {
+			add(op.ToolName)
+		}
+	}
+	for _, group := range parsedConfig.GeneratorGroups {
+		for _, op := range group.OperationMix {
+			if op.Operation == "tools/call" {
+				add(op.ToolName)
+			}
+		}
+	}
+	for _, op := range liveOpMix {
+		if normalizeOperationName(op.Operation) == "tools/call" {
+			add(op.ToolName)
+		}
+	}
+
+	for _, tool := range advertised {
+		delete(referenced, tool.Name)
+	}
+	missing := make([]string, 0, len(referenced))
+	for name := range referenced {
+		missing = append(missing, name)
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// capabilityNames returns the server capabilities advertised, sorted.
+func capabilityNames(capabilities map[string]interface{}) []string {
+	names := make([]string, 0, len(capabilities))
+	for name := range capabilities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
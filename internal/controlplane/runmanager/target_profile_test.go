@@ -0,0 +1,150 @@
+package runmanager
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/artifacts"
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/scheduler"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+func startTestRunInPreflight(t *testing.T) (*RunManager, string) {
+	t.Helper()
+	rm := NewRunManager(createTestValidatorForWorkerTest(t))
+	registry := scheduler.NewRegistry()
+	lm := scheduler.NewLeaseManager(60000)
+	rm.SetScheduler(registry, scheduler.NewAllocator(registry, lm), lm)
+	rm.SetAssignmentSender(&mockAssignmentSender{assignments: make(map[string][]types.WorkerAssignment)})
+	if _, err := registry.RegisterWorker(types.HostInfo{Hostname: "host1"}, types.WorkerCapacity{MaxVUs: 100}); err != nil {
+		t.Fatalf("RegisterWorker failed: %v", err)
+	}
+	store, err := artifacts.NewFilesystemStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFilesystemStore failed: %v", err)
+	}
+	rm.SetArtifactStore(store)
+
+	runID := createTestRunWithPolicy(t, rm, "")
+	if err := rm.StartRun(runID, "test"); err != nil {
+		t.Fatalf("StartRun failed: %v", err)
+	}
+	return rm, runID
+}
+
+func TestRecordTargetProfile_StoresArtifactOnce(t *testing.T) {
+	rm, runID := startTestRunInPreflight(t)
+
+	profile := &types.TargetProfile{
+		ProtocolVersion: "2025-06-18",
+		Capabilities:    map[string]interface{}{"tools": map[string]interface{}{}},
+		ServerInfo:      types.ServerInfo{Name: "mock", Version: "1.0"},
+		Tools:           []types.Tool{{Name: "echo", InputSchema: json.RawMessage(`{"type":"object"}`)}},
+	}
+	recorded, err := rm.RecordTargetProfile(runID, "worker-1", profile)
+	if err != nil || !recorded {
+		t.Fatalf("expected the profile recorded, got %v, %v", recorded, err)
+	}
+	if recorded, _ := rm.RecordTargetProfile(runID, "worker-2", profile); recorded {
+		t.Error("expected a second profile of the run to be ignored")
+	}
+
+	data, err := rm.artifactStore.GetArtifact(runID, artifacts.ArtifactTypeReport, TargetProfileFilename)
+	if err != nil {
+		t.Fatalf("expected the target profile artifact: %v", err)
+	}
+	var stored types.TargetProfile
+	if err := json.Unmarshal(data, &stored); err != nil || stored.ProtocolVersion != "2025-06-18" || len(stored.Tools) != 1 {
+		t.Errorf("unexpected stored profile %s (%v)", data, err)
+	}
+
+	profiled := 0
+	for _, event := range rm.eventLogs[runID].GetAll() {
+		switch event.Type {
+		case EventTypeTargetProfiled:
+			profiled++
+		case EventTypeStageFailed:
+			t.Errorf("expected preflight to go on, got %s", event.Payload)
+		}
+	}
+	if profiled != 1 {
+		t.Errorf("expected one TARGET_PROFILED event, got %d", profiled)
+	}
+	if view, _ := rm.GetRun(runID); view.State != RunStatePreflightRunning {
+		t.Errorf("expected the run still in preflight, got %s", view.State)
+	}
+}
+
+func TestRecordTargetProfile_FailsPreflightOnMissingTools(t *testing.T) {
+	rm, runID := startTestRunInPreflight(t)
+
+	profile := &types.TargetProfile{
+		ProtocolVersion: "2025-06-18",
+		Tools:           []types.Tool{{Name: "search"}},
+	}
+	if _, err := rm.RecordTargetProfile(runID, "worker-1", profile); err != nil {
+		t.Fatalf("RecordTargetProfile failed: %v", err)
+	}
+
+	view, _ := rm.GetRun(runID)
+	if view.State != RunStateStopping || view.StopReason == nil || view.StopReason.Reason != "preflight_failed" {
+		t.Fatalf("expected the run stopping for a failed preflight, got %s (%+v)", view.State, view.StopReason)
+	}
+
+	var failed, toPreflightFailed bool
+	for _, event := range rm.eventLogs[runID].GetAll() {
+		switch event.Type {
+		case EventTypeStageFailed:
+			var payload struct {
+				Reason       string   `json:"reason"`
+				MissingTools []string `json:"missing_tools"`
+			}
+			_ = json.Unmarshal(event.Payload, &payload)
+			failed = payload.Reason == "target_tools_missing" && len(payload.MissingTools) == 1 && payload.MissingTools[0] == "echo"
+		case EventTypeStateTransition:
+			var payload struct {
+				ToState RunState `json:"to_state"`
+			}
+			_ = json.Unmarshal(event.Payload, &payload)
+			toPreflightFailed = toPreflightFailed || payload.ToState == RunStatePreflightFailed
+		}
+	}
+	if !failed {
+		t.Error("expected STAGE_FAILED naming the missing echo tool")
+	}
+	if !toPreflightFailed {
+		t.Error("expected a transition to preflight_failed")
+	}
+}
+
+func TestRecordTargetProfile_SkipsCheckWithoutToolsList(t *testing.T) {
+	rm, runID := startTestRunInPreflight(t)
+
+	profile := &types.TargetProfile{
+		ProtocolVersion: "2025-06-18",
+		ListErrors:      map[string]string{"tools/list": "Method not found"},
+	}
+	if _, err := rm.RecordTargetProfile(runID, "worker-1", profile); err != nil {
+		t.Fatalf("RecordTargetProfile failed: %v", err)
+	}
+	if view, _ := rm.GetRun(runID); view.State != RunStatePreflightRunning {
+		t.Errorf("expected the run still in preflight, got %s", view.State)
+	}
+}
+
+func TestMissingTools(t *testing.T) {
+	config := []byte(`{"workload": {
+		"op_mix": [
+			{"operation": "tools_call", "weight": 1},
+			{"operation": "tools/call", "tool_name": "fetch", "weight": 1},
+			{"operation": "tools/call", "tool_name": "{{tool}}", "weight": 1}
+		],
+		"tools": {"templates": [{"template_id": "a", "tool_name": "echo", "weight": 1}]}
+	}}`)
+	live := []types.OpMixEntry{{Operation: "tools_call", ToolName: "resize"}}
+
+	missing := missingTools(config, live, []types.Tool{{Name: "echo"}})
+	if len(missing) != 2 || missing[0] != "fetch" || missing[1] != "resize" {
+		t.Errorf("expected fetch and resize missing, got %v", missing)
+	}
+}
@@ -0,0 +1,64 @@
+package runmanager
+
+import "github.com/bc-dunia/mcpdrill/internal/types"
+
+// resolveTarget returns the target the VUs of entry send to: the run's
+// target with the entry's URL, auth and backend header in place and its
+// headers added. A nil entry returns the run's target.
+func resolveTarget(config *parsedRunConfig, entry *parsedTargetEntry) parsedTarget {
+	target := config.Target
+	if entry == nil {
+		return target
+	}
+	target.URL = entry.URL
+	if len(entry.Headers) > 0 {
+		target.Headers = make(map[string]string, len(config.Target.Headers)+len(entry.Headers))
+		for k, v := range config.Target.Headers {
+			target.Headers[k] = v
+		}
+		for k, v := range entry.Headers {
+			target.Headers[k] = v
+		}
+	}
+	if entry.Auth != nil {
+		target.Auth = entry.Auth
+	}
+	if entry.BackendIDHeader != "" {
+		target.BackendIDHeader = entry.BackendIDHeader
+	}
+	return target
+}
+
+// buildTargetConfig returns the target of an assignment whose VUs send to
+// entry, or to the run's target if entry is nil.
+func buildTargetConfig(runID string, config *parsedRunConfig, entry *parsedTargetEntry) types.TargetConfig {
+	target := resolveTarget(config, entry)
+	return types.TargetConfig{
+		URL:                   target.URL,
+		Transport:             target.Transport,
+		Headers:               buildTargetHeaders(runID, &target),
+		RedirectPolicy:        buildRedirectPolicy(target.RedirectPolicy),
+		Auth:                  buildAuthConfig(target.Auth),
+		ProtocolVersion:       target.ProtocolVersion,
+		ProtocolVersionPolicy: target.ProtocolVersionPolicy,
+		BackendIDHeader:       target.BackendIDHeader,
+		StreamResumption:      buildStreamResumptionConfig(target.StreamResumption),
+		ServerRequests:        buildServerRequestsConfig(target.ServerRequests),
+		ProgressTokens:        target.ProgressTokens,
+	}
+}
+
+// buildShadowConfig returns the shadow target of a run's assignments, or nil
+// if the run has none. Like an entry of targets, the shadow takes its
+// settings from the run's target with its own URL, auth and headers.
+func buildShadowConfig(runID string, config *parsedRunConfig) *types.ShadowConfig {
+	shadow := config.Shadow
+	if shadow == nil {
+		return nil
+	}
+	entry := &parsedTargetEntry{URL: shadow.URL, Headers: shadow.Headers, Auth: shadow.Auth}
+	return &types.ShadowConfig{
+		Target:      buildTargetConfig(runID, config, entry),
+		StableTools: shadow.StableTools,
+	}
+}
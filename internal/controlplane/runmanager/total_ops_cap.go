@@ -0,0 +1,147 @@
+package runmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+)
+
+// TotalOpsCapStopReason is the stop reason recorded when a run exhausts
+// safety.hard_caps.max_total_ops.
+const TotalOpsCapStopReason = "TOTAL_OPS_CAP"
+
+// totalOpsCapPollInterval is how often the control plane compares ingested
+// telemetry against the run's total operation budget.
+const totalOpsCapPollInterval = 1 * time.Second
+
+// operationCounter is implemented by telemetry stores that can report how many
+// operations have been ingested for a run, regardless of storage truncation.
+type operationCounter interface {
+	GetReceivedOperationCount(runID string) int64
+}
+
+// receivedOperationCount returns the number of operations ingested for a run,
+// or 0 if the telemetry store cannot report it.
+func (rm *RunManager) receivedOperationCount(runID string) int64 {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	return rm.receivedOperationCountLocked(runID)
+}
+
+// receivedOperationCountLocked is receivedOperationCount for callers holding rm.mu.
+func (rm *RunManager) receivedOperationCountLocked(runID string) int64 {
+	counter, ok := rm.telemetryStore.(operationCounter)
+	if !ok {
+		return 0
+	}
+	return counter.GetReceivedOperationCount(runID)
+}
+
+// assignmentOpsBudget returns the share of the remaining total operation budget
+// for an assignment covering vuCount of totalVUs. Returns 0 when no cap is set.
+// Workers enforce this share cooperatively; the control plane watcher is the backstop.
+func assignmentOpsBudget(maxTotalOps, receivedOps int64, vuCount, totalVUs int) int64 {
+	if maxTotalOps <= 0 || vuCount <= 0 {
+		return 0
+	}
+	if totalVUs < vuCount {
+		totalVUs = vuCount
+	}
+
+	remaining := maxTotalOps - receivedOps
+	if remaining <= 0 {
+		// Zero would disable enforcement on the worker, so hand out the smallest budget.
+		return 1
+	}
+
+	return (remaining*int64(vuCount) + int64(totalVUs) - 1) / int64(totalVUs)
+}
+
+// startTotalOpsCapWatcher polls the telemetry store and stops the run once the
+// number of ingested operations reaches maxTotalOps. The watcher exits when the
+// run leaves its running states.
+func (rm *RunManager) startTotalOpsCapWatcher(runID string, maxTotalOps int64) {
+	if maxTotalOps <= 0 {
+		return
+	}
+
+	rm.mu.RLock()
+	_, isCounter := rm.telemetryStore.(operationCounter)
+	rm.mu.RUnlock()
+	if !isCounter {
+		log.Printf("[RunManager] Total ops cap watcher disabled for run %s: telemetry store cannot count operations", runID)
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(totalOpsCapPollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-rm.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			rm.mu.RLock()
+			record, ok := rm.runs[runID]
+			running := ok && isRunningState(record.State)
+			rm.mu.RUnlock()
+			if !running {
+				return
+			}
+
+			observed := rm.receivedOperationCount(runID)
+			if observed >= maxTotalOps {
+				rm.handleTotalOpsCapReached(runID, maxTotalOps, observed)
+				return
+			}
+		}
+	}()
+}
+
+// handleTotalOpsCapReached records the breach and stops the run with TOTAL_OPS_CAP.
+func (rm *RunManager) handleTotalOpsCapReached(runID string, maxTotalOps, observed int64) {
+	rm.mu.RLock()
+	record, ok := rm.runs[runID]
+	if !ok {
+		rm.mu.RUnlock()
+		return
+	}
+	eventLog := rm.eventLogs[runID]
+	executionID := record.ExecutionID
+	correlation := CorrelationContext{}
+	if record.ActiveStage != nil {
+		stageName := StageName(record.ActiveStage.Stage)
+		stageID := record.ActiveStage.StageID
+		correlation.Stage = &stageName
+		correlation.StageID = &stageID
+	}
+	rm.mu.RUnlock()
+
+	payload, _ := json.Marshal(map[string]interface{}{
+		"condition_id": "max_total_ops",
+		"metric":       "total_ops",
+		"comparator":   ">=",
+		"threshold":    maxTotalOps,
+		"observed":     observed,
+	})
+
+	event := RunEvent{
+		RunID:       runID,
+		ExecutionID: executionID,
+		Type:        EventTypeStopConditionTriggered,
+		Actor:       ActorSystem,
+		Correlation: correlation,
+		Payload:     payload,
+		Evidence: []Evidence{
+			{Kind: "hard_cap", Ref: fmt.Sprintf("max_total_ops=%d", maxTotalOps), Note: stringPtr(fmt.Sprintf("observed=%d", observed))},
+		},
+	}
+	appendEventWithLog(eventLog, event, "handleTotalOpsCapReached")
+
+	log.Printf("[RunManager] Total ops cap reached for run %s (observed=%d, max_total_ops=%d)", runID, observed, maxTotalOps)
+	_ = rm.requestStopWithReason(runID, StopModeImmediate, string(ActorSystem), TotalOpsCapStopReason, event.Evidence)
+}
@@ -0,0 +1,140 @@
+package runmanager
+
+import (
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type countingTelemetryStore struct {
+	mockTelemetryStore
+	received atomic.Int64
+}
+
+func (m *countingTelemetryStore) GetReceivedOperationCount(runID string) int64 {
+	return m.received.Load()
+}
+
+func createConfigWithMaxTotalOps(t *testing.T, maxTotalOps int64) []byte {
+	t.Helper()
+
+	config := createValidConfigWithDurations(t, 60000, 60000, 60000)
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(config, &parsed); err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+	safety := parsed["safety"].(map[string]interface{})
+	hardCaps := safety["hard_caps"].(map[string]interface{})
+	hardCaps["max_total_ops"] = maxTotalOps
+
+	updated, err := json.Marshal(parsed)
+	if err != nil {
+		t.Fatalf("failed to marshal config: %v", err)
+	}
+	return updated
+}
+
+func TestAssignmentOpsBudget(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxTotalOps int64
+		receivedOps int64
+		vuCount     int
+		totalVUs    int
+		expected    int64
+	}{
+		{"no cap", 0, 0, 10, 10, 0},
+		{"single assignment gets full budget", 1000, 0, 10, 10, 1000},
+		{"proportional share", 1000, 0, 5, 10, 500},
+		{"rounds up", 1000, 0, 1, 3, 334},
+		{"subtracts received ops", 1000, 400, 5, 10, 300},
+		{"exhausted budget still enforced", 1000, 1500, 5, 10, 1},
+		{"total smaller than share", 1000, 0, 10, 5, 1000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := assignmentOpsBudget(tt.maxTotalOps, tt.receivedOps, tt.vuCount, tt.totalVUs)
+			if got != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseRunConfig_MaxTotalOps(t *testing.T) {
+	parsed, err := parseRunConfig(createConfigWithMaxTotalOps(t, 5000))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parsed.Safety.HardCaps.MaxTotalOps != 5000 {
+		t.Errorf("expected max_total_ops 5000, got %d", parsed.Safety.HardCaps.MaxTotalOps)
+	}
+}
+
+func TestTotalOpsCap_StopsRun(t *testing.T) {
+	validator := createTestValidator(t)
+	rm := NewRunManager(validator)
+	defer rm.Shutdown()
+
+	store := &countingTelemetryStore{}
+	rm.SetTelemetryStore(store)
+
+	runID, err := rm.CreateRun(createConfigWithMaxTotalOps(t, 100), "test-user")
+	if err != nil {
+		t.Fatalf("failed to create run: %v", err)
+	}
+	if err := rm.StartRun(runID, "test-user"); err != nil {
+		t.Fatalf("failed to start run: %v", err)
+	}
+
+	store.received.Store(99)
+	time.Sleep(totalOpsCapPollInterval + 200*time.Millisecond)
+	if view, _ := rm.GetRun(runID); view.State != RunStatePreflightRunning {
+		t.Fatalf("expected run to keep running below cap, got %s", view.State)
+	}
+
+	store.received.Store(100)
+	waitForRunState(t, rm, runID, RunStateStopping, 3*time.Second)
+
+	view, _ := rm.GetRun(runID)
+	if view.StopReason == nil || view.StopReason.Reason != TotalOpsCapStopReason {
+		t.Fatalf("expected stop reason %s, got %+v", TotalOpsCapStopReason, view.StopReason)
+	}
+
+	events, _ := rm.TailEvents(runID, 0, 100)
+	found := false
+	for _, event := range events {
+		if event.Type == EventTypeStopConditionTriggered {
+			var payload map[string]interface{}
+			_ = json.Unmarshal(event.Payload, &payload)
+			if payload["metric"] == "total_ops" && payload["observed"] == float64(100) {
+				found = true
+			}
+		}
+	}
+	if !found {
+		t.Error("expected STOP_CONDITION_TRIGGERED event for total_ops")
+	}
+}
+
+func TestTotalOpsCap_NoCapNoWatcher(t *testing.T) {
+	validator := createTestValidator(t)
+	rm := NewRunManager(validator)
+	defer rm.Shutdown()
+
+	store := &countingTelemetryStore{}
+	store.received.Store(1000000)
+	rm.SetTelemetryStore(store)
+
+	runID, _ := rm.CreateRun(createValidConfigWithDurations(t, 60000, 60000, 60000), "test-user")
+	if err := rm.StartRun(runID, "test-user"); err != nil {
+		t.Fatalf("failed to start run: %v", err)
+	}
+
+	time.Sleep(totalOpsCapPollInterval + 200*time.Millisecond)
+	if view, _ := rm.GetRun(runID); view.State != RunStatePreflightRunning {
+		t.Errorf("expected run to keep running without max_total_ops, got %s", view.State)
+	}
+}
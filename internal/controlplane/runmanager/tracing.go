@@ -0,0 +1,150 @@
+package runmanager
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/bc-dunia/mcpdrill/internal/otel"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+// runTrace holds the spans of one run. The root span lasts from creation (or
+// recovery) to a terminal state; each state the run passes through gets a
+// child span, so the trace reads as the run's timeline. Assignment dispatch
+// spans are children of the state span that was current at dispatch.
+type runTrace struct {
+	tracer *otel.Tracer
+
+	mu        sync.Mutex
+	rootCtx   context.Context
+	root      trace.Span
+	stateCtx  context.Context
+	stateSpan trace.Span
+	ended     bool
+}
+
+// SetTracer sets the tracer used for run lifecycle and dispatch spans. Runs
+// created before the call are not traced.
+func (rm *RunManager) SetTracer(tracer *otel.Tracer) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.tracer = tracer
+}
+
+// newRunTrace starts the root span of a run. Returns nil if tracing is
+// disabled.
+func newRunTrace(tracer *otel.Tracer, record *RunRecord, recovered bool) *runTrace {
+	if tracer == nil || !tracer.Enabled() {
+		return nil
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("mcpdrill.run_id", record.RunID),
+		attribute.String("mcpdrill.execution_id", record.ExecutionID),
+		attribute.String("mcpdrill.scenario_id", record.ScenarioID),
+		attribute.String("mcpdrill.config_hash", record.ConfigHash),
+	}
+	if recovered {
+		attrs = append(attrs, attribute.Bool("mcpdrill.recovered", true))
+	}
+	ctx, root := tracer.StartSpan(context.Background(), "mcpdrill.run",
+		trace.WithNewRoot(),
+		trace.WithAttributes(attrs...),
+	)
+
+	rt := &runTrace{tracer: tracer, rootCtx: ctx, root: root, stateCtx: ctx}
+	if recovered {
+		rt.enterState(string(record.State), "control_plane_restart")
+	}
+	return rt
+}
+
+// observe updates the trace for an event appended to the run's event log.
+func (rt *runTrace) observe(event RunEvent) {
+	if event.Type != EventTypeStateTransition {
+		return
+	}
+	var payload struct {
+		ToState string `json:"to_state"`
+		Trigger string `json:"trigger"`
+	}
+	if err := json.Unmarshal(event.Payload, &payload); err != nil || payload.ToState == "" {
+		return
+	}
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	if rt.ended {
+		return
+	}
+
+	switch RunState(payload.ToState) {
+	case RunStateCompleted, RunStateFailed, RunStateAborted:
+		if rt.stateSpan != nil {
+			rt.stateSpan.End()
+		}
+		rt.root.SetAttributes(
+			attribute.String("mcpdrill.final_state", payload.ToState),
+			attribute.String("mcpdrill.trigger", payload.Trigger),
+		)
+		if RunState(payload.ToState) != RunStateCompleted {
+			rt.root.SetStatus(codes.Error, payload.Trigger)
+		}
+		rt.root.End()
+		rt.ended = true
+	default:
+		rt.enterState(payload.ToState, payload.Trigger)
+	}
+}
+
+// enterState ends the current state span and starts one for state. The
+// caller must hold rt.mu unless rt is not shared yet.
+func (rt *runTrace) enterState(state, trigger string) {
+	if rt.stateSpan != nil {
+		rt.stateSpan.End()
+	}
+	rt.stateCtx, rt.stateSpan = rt.tracer.StartSpan(rt.rootCtx, "mcpdrill.run."+state,
+		trace.WithAttributes(
+			attribute.String("mcpdrill.state", state),
+			attribute.String("mcpdrill.trigger", trigger),
+		),
+	)
+}
+
+// startDispatchSpan starts a span for dispatching an assignment to a worker
+// and stores its trace context in the assignment.
+func (rt *runTrace) startDispatchSpan(workerID string, a *types.WorkerAssignment) trace.Span {
+	rt.mu.Lock()
+	parent := rt.stateCtx
+	rt.mu.Unlock()
+
+	ctx, span := rt.tracer.StartSpan(parent, "mcpdrill.scheduler.dispatch",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("mcpdrill.run_id", a.RunID),
+			attribute.String("mcpdrill.stage", a.Stage),
+			attribute.String("mcpdrill.stage_id", a.StageID),
+			attribute.String("mcpdrill.worker_id", workerID),
+			attribute.String("mcpdrill.lease_id", a.LeaseID),
+			attribute.Int("mcpdrill.vu_id_start", a.VUIDStart),
+			attribute.Int("mcpdrill.vu_id_end", a.VUIDEnd),
+		),
+	)
+	a.TraceContext = otel.InjectMap(ctx, rt.tracer)
+	return span
+}
+
+// traceDispatch starts a dispatch span for an assignment of a traced run.
+// The returned function ends it; it is a no-op when the run is not traced.
+func traceDispatch(eventLog *EventLog, workerID string, a *types.WorkerAssignment) func() {
+	if eventLog == nil || eventLog.trace == nil {
+		return func() {}
+	}
+	span := eventLog.trace.startDispatchSpan(workerID, a)
+	return func() { span.End() }
+}
@@ -0,0 +1,156 @@
+package runmanager
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/scheduler"
+	"github.com/bc-dunia/mcpdrill/internal/otel"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+func newRecordingTracer(t *testing.T) (*otel.Tracer, *tracetest.SpanRecorder) {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	return otel.NewTracerWithProvider("test", tp), recorder
+}
+
+func findSpan(spans []sdktrace.ReadOnlySpan, name string) sdktrace.ReadOnlySpan {
+	for _, s := range spans {
+		if s.Name() == name {
+			return s
+		}
+	}
+	return nil
+}
+
+func startedSpans(recorder *tracetest.SpanRecorder) []sdktrace.ReadOnlySpan {
+	var spans []sdktrace.ReadOnlySpan
+	for _, s := range recorder.Started() {
+		spans = append(spans, s)
+	}
+	return spans
+}
+
+func TestTracing_StartRunDispatch(t *testing.T) {
+	tracer, recorder := newRecordingTracer(t)
+
+	rm := NewRunManager(createTestValidatorForWorkerTest(t))
+	defer rm.Shutdown()
+	rm.SetTracer(tracer)
+
+	registry := scheduler.NewRegistry()
+	lm := scheduler.NewLeaseManager(60000)
+	allocator := scheduler.NewAllocator(registry, lm)
+	rm.SetScheduler(registry, allocator, lm)
+	mockSender := &mockAssignmentSender{assignments: make(map[string][]types.WorkerAssignment)}
+	rm.SetAssignmentSender(mockSender)
+
+	wid, _ := registry.RegisterWorker(types.HostInfo{Hostname: "host1"}, types.WorkerCapacity{MaxVUs: 50})
+
+	runID := createTestRunWithPolicy(t, rm, "fail_fast")
+	if err := rm.StartRun(runID, "test-user"); err != nil {
+		t.Fatalf("StartRun failed: %v", err)
+	}
+
+	assignments := mockSender.assignments[string(wid)]
+	if len(assignments) == 0 {
+		t.Fatal("expected an assignment to be dispatched")
+	}
+	traceparent := assignments[0].TraceContext["traceparent"]
+	if traceparent == "" {
+		t.Fatalf("expected assignment to carry a traceparent, got %v", assignments[0].TraceContext)
+	}
+
+	dispatch := findSpan(recorder.Ended(), "mcpdrill.scheduler.dispatch")
+	if dispatch == nil {
+		t.Fatal("expected a dispatch span")
+	}
+	if dispatch.SpanKind() != trace.SpanKindProducer {
+		t.Errorf("dispatch span kind = %v, want producer", dispatch.SpanKind())
+	}
+	if want := dispatch.SpanContext().SpanID().String(); len(traceparent) < 52 || traceparent[36:52] != want {
+		t.Errorf("traceparent %q does not reference dispatch span %s", traceparent, want)
+	}
+
+	preflight := findSpan(startedSpans(recorder), "mcpdrill.run.preflight_running")
+	if preflight == nil {
+		t.Fatal("expected a preflight_running state span")
+	}
+	if dispatch.Parent().SpanID() != preflight.SpanContext().SpanID() {
+		t.Error("expected dispatch span to be a child of the preflight_running span")
+	}
+
+	root := findSpan(startedSpans(recorder), "mcpdrill.run")
+	if root == nil {
+		t.Fatal("expected a run root span")
+	}
+	if preflight.Parent().SpanID() != root.SpanContext().SpanID() {
+		t.Error("expected state span to be a child of the run span")
+	}
+	if dispatch.SpanContext().TraceID() != root.SpanContext().TraceID() {
+		t.Error("expected dispatch span to share the run's trace")
+	}
+}
+
+func TestTracing_TerminalStateEndsRun(t *testing.T) {
+	tracer, recorder := newRecordingTracer(t)
+
+	record := &RunRecord{RunID: "run_0000000000000001", ExecutionID: "exe_00000001", State: RunStateCreated}
+	rt := newRunTrace(tracer, record, false)
+
+	transition := func(to RunState, trigger string) RunEvent {
+		payload, _ := json.Marshal(map[string]string{"to_state": string(to), "trigger": trigger})
+		return RunEvent{Type: EventTypeStateTransition, Payload: payload}
+	}
+	rt.observe(transition(RunStatePreflightRunning, "start"))
+	rt.observe(transition(RunStateStopping, "emergency_stop"))
+	rt.observe(transition(RunStateAborted, "emergency_stop"))
+	// Events after the run has ended are ignored.
+	rt.observe(transition(RunStateBaselineRunning, "late"))
+
+	ended := recorder.Ended()
+	if len(ended) != 3 {
+		t.Fatalf("expected 3 ended spans, got %d", len(ended))
+	}
+	if len(recorder.Started()) != 3 {
+		t.Errorf("expected no spans after the terminal state, got %d started", len(recorder.Started()))
+	}
+
+	root := findSpan(ended, "mcpdrill.run")
+	if root == nil {
+		t.Fatal("expected the run span to end")
+	}
+	if root.Status().Code != codes.Error {
+		t.Errorf("aborted run status = %v, want Error", root.Status().Code)
+	}
+	var finalState string
+	for _, attr := range root.Attributes() {
+		if attr.Key == "mcpdrill.final_state" {
+			finalState = attr.Value.AsString()
+		}
+	}
+	if finalState != string(RunStateAborted) {
+		t.Errorf("final_state = %q, want %q", finalState, RunStateAborted)
+	}
+}
+
+func TestTracing_Disabled(t *testing.T) {
+	if rt := newRunTrace(otel.NoopTracer(), &RunRecord{}, false); rt != nil {
+		t.Error("expected no run trace with a disabled tracer")
+	}
+
+	a := &types.WorkerAssignment{}
+	traceDispatch(NewEventLog(), "worker-1", a)()
+	if a.TraceContext != nil {
+		t.Errorf("expected no trace context for an untraced run, got %v", a.TraceContext)
+	}
+}
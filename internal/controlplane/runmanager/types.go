@@ -5,12 +5,16 @@ type RunState string
 
 const (
 	RunStateCreated          RunState = "created"
+	RunStateQueued           RunState = "queued"
 	RunStatePreflightRunning RunState = "preflight_running"
 	RunStatePreflightPassed  RunState = "preflight_passed"
 	RunStatePreflightFailed  RunState = "preflight_failed"
 	RunStateBaselineRunning  RunState = "baseline_running"
 	RunStateRampRunning      RunState = "ramp_running"
 	RunStateSoakRunning      RunState = "soak_running"
+	RunStateSpikeRunning     RunState = "spike_running"
+	RunStateStepRunning      RunState = "step_running"
+	RunStatePaused           RunState = "paused"
 	RunStateStopping         RunState = "stopping"
 	RunStateAnalyzing        RunState = "analyzing"
 	RunStateCompleted        RunState = "completed"
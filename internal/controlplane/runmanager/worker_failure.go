@@ -65,7 +65,8 @@ func getWorkerFailurePolicy(config []byte) WorkerFailurePolicy {
 //
 // Policies:
 //   - fail_fast: Immediately transition run to STOPPING state
-//   - replace_if_possible: Attempt reallocation (stub for now, falls back to fail_fast)
+//   - replace_if_possible: Hand the lost worker's leases off to workers with
+//     spare capacity, falling back to fail_fast if they cannot take them
 //   - best_effort: Log warning and continue with reduced capacity
 func (rm *RunManager) HandleWorkerCapacityLost(runID string, workerID string) error {
 	rm.mu.Lock()
@@ -117,7 +118,8 @@ func (rm *RunManager) HandleWorkerCapacityLost(runID string, workerID string) er
 
 func isRunningState(state RunState) bool {
 	switch state {
-	case RunStatePreflightRunning, RunStateBaselineRunning, RunStateRampRunning, RunStateSoakRunning:
+	case RunStatePreflightRunning, RunStateBaselineRunning, RunStateRampRunning, RunStateSoakRunning,
+		RunStateSpikeRunning, RunStateStepRunning, RunStatePaused:
 		return true
 	default:
 		return false
@@ -234,6 +236,7 @@ func (rm *RunManager) handleReplaceIfPossibleLocked(record *RunRecord, workerID
 		log.Printf("[RunManager] Capping reallocation target VUs from %d to hard cap %d", targetVUs, parsedConfig.Safety.HardCaps.MaxVUs)
 		targetVUs = parsedConfig.Safety.HardCaps.MaxVUs
 	}
+	targetVUs = conformanceVUs(parsedConfig, targetVUs)
 
 	if rm.allocator == nil {
 		log.Printf("[RunManager] Allocator not configured, falling back to fail_fast")
@@ -241,12 +244,27 @@ func (rm *RunManager) handleReplaceIfPossibleLocked(record *RunRecord, workerID
 		return rm.handleFailFastLocked(record, workerID)
 	}
 
-	assignments, workerAssignments, err := rm.allocator.ReallocateAssignments(
-		record.RunID,
-		stageID,
-		targetVUs,
-		[]scheduler.WorkerID{scheduler.WorkerID(workerID)},
+	var (
+		handoffs []leaseHandoff
+		labels   map[scheduler.WorkerID]map[string]string
 	)
+	if rm.registry != nil {
+		labels = workerLabels(rm.registry.ListWorkers())
+	}
+	if rm.leaseManager != nil && rm.registry != nil {
+		// Only the lost worker's leases are re-issued, so the remaining
+		// workers keep running their VUs undisturbed.
+		handoffs, err = rm.planRunLeaseHandoff(record, parsedConfig, workerID)
+	}
+	if err == nil && len(handoffs) == 0 {
+		// Without a record of the lost worker's leases the whole stage is
+		// reallocated across the remaining workers.
+		var dispatches []groupDispatch
+		dispatches, err = rm.reallocateStageLocked(record, parsedConfig, workerID, stageID, targetVUs)
+		for _, d := range dispatches {
+			handoffs = append(handoffs, leaseHandoff{groupDispatch: d})
+		}
+	}
 
 	if err != nil {
 		log.Printf("[RunManager] Reallocation failed: %v, falling back to fail_fast", err)
@@ -260,7 +278,14 @@ func (rm *RunManager) handleReplaceIfPossibleLocked(record *RunRecord, workerID
 		return rm.handleFailFastLocked(record, workerID)
 	}
 
-	for wid, assignment := range workerAssignments {
+	receivedOps := rm.receivedOperationCountLocked(record.RunID)
+
+	reassigned := make(map[scheduler.LeaseID]bool)
+	for _, h := range handoffs {
+		d := h.groupDispatch
+		wid, assignment := d.workerID, d.assignment
+		workload, workloadRevision := rm.assignmentWorkloadLocked(record.RunID, parsedConfig, d.group)
+
 		leaseID, err := rm.leaseManager.IssueLease(wid, assignment)
 		if err != nil {
 			log.Printf("[RunManager] Failed to issue lease for worker %s: %v", wid, err)
@@ -268,49 +293,50 @@ func (rm *RunManager) handleReplaceIfPossibleLocked(record *RunRecord, workerID
 		}
 
 		workerAssignment := types.WorkerAssignment{
-			RunID:       assignment.RunID,
-			ExecutionID: record.ExecutionID,
-			StageID:     assignment.StageID,
-			Stage:       string(record.ActiveStage.Stage),
-			LeaseID:     string(leaseID),
-			VUIDStart:   assignment.VUIDRange.Start,
-			VUIDEnd:     assignment.VUIDRange.End,
-			DurationMs:  rm.getStageDuration(parsedConfig, stageID),
-			Target: types.TargetConfig{
-				URL:                   parsedConfig.Target.URL,
-				Transport:             parsedConfig.Target.Transport,
-				Headers:               buildTargetHeaders(record.RunID, &parsedConfig.Target),
-				RedirectPolicy:        buildRedirectPolicy(parsedConfig.Target.RedirectPolicy),
-				Auth:                  buildAuthConfig(parsedConfig.Target.Auth),
-				ProtocolVersion:       parsedConfig.Target.ProtocolVersion,
-				ProtocolVersionPolicy: parsedConfig.Target.ProtocolVersionPolicy,
-			},
-			Workload: types.WorkloadConfig{
-				OpMix: convertOpMix(parsedConfig.Workload.OpMix),
-			},
-			SessionPolicy: types.SessionPolicyConfig{
-				Mode:      parsedConfig.SessionPolicy.Mode,
-				PoolSize:  parsedConfig.SessionPolicy.PoolSize,
-				TTLMs:     parsedConfig.SessionPolicy.TTLMs,
-				MaxIdleMs: parsedConfig.SessionPolicy.MaxIdleMs,
-			},
+			RunID:            assignment.RunID,
+			ExecutionID:      record.ExecutionID,
+			StageID:          assignment.StageID,
+			Stage:            string(record.ActiveStage.Stage),
+			LeaseID:          string(leaseID),
+			VUIDStart:        assignment.VUIDRange.Start,
+			VUIDEnd:          assignment.VUIDRange.End,
+			DurationMs:       rm.getStageDuration(parsedConfig, stageID),
+			WarmupMs:         rm.getStageWarmup(parsedConfig, stageID),
+			Chaos:            rm.getStageChaos(parsedConfig, stageID),
+			Target:           buildTargetConfig(record.RunID, parsedConfig, d.target),
+			Workload:         workload,
+			WorkloadRevision: workloadRevision,
+			GeneratorGroup:   d.groupName(),
+			TargetName:       d.targetName(),
+			Shadow:           buildShadowConfig(record.RunID, parsedConfig),
+			Conformance:      buildConformanceConfig(parsedConfig),
+			SessionPolicy:    buildSessionPolicyConfig(parsedConfig.SessionPolicy),
+			MaxTotalOps: assignmentOpsBudget(parsedConfig.Safety.HardCaps.MaxTotalOps, receivedOps,
+				assignment.VUIDRange.End-assignment.VUIDRange.Start, targetVUs),
 		}
 
+		endDispatch := traceDispatch(eventLog, string(wid), &workerAssignment)
 		rm.assignmentSender.AddAssignment(string(wid), workerAssignment)
+		endDispatch()
 
 		rm.emitWorkerAssignedEvent(record.RunID, record.ExecutionID, eventLog, string(wid), string(leaseID),
-			assignment.VUIDRange.Start, assignment.VUIDRange.End, stageID, StageName(record.ActiveStage.Stage))
+			assignment.VUIDRange.Start, assignment.VUIDRange.End, stageID, StageName(record.ActiveStage.Stage), d.groupName(), d.targetName(), labels[wid])
+		if h.lostLeaseID != "" {
+			rm.emitLeaseReassignedEvent(record, eventLog, workerID, h, string(leaseID))
+			reassigned[h.lostLeaseID] = true
+		}
 
 		log.Printf("[RunManager] Reassigned VUs [%d, %d) to worker %s with lease %s",
 			assignment.VUIDRange.Start, assignment.VUIDRange.End, wid, leaseID)
 	}
 
 	replacedPayload, _ := json.Marshal(map[string]interface{}{
-		"lost_worker":     workerID,
-		"new_assignments": len(assignments),
-		"target_vus":      targetVUs,
-		"stage_id":        stageID,
-		"policy":          PolicyReplaceIfPossible,
+		"lost_worker":       workerID,
+		"new_assignments":   len(handoffs),
+		"reassigned_leases": len(reassigned),
+		"target_vus":        targetVUs,
+		"stage_id":          stageID,
+		"policy":            PolicyReplaceIfPossible,
 	})
 	replacedEvent := RunEvent{
 		RunID:       record.RunID,
@@ -328,7 +354,7 @@ func (rm *RunManager) handleReplaceIfPossibleLocked(record *RunRecord, workerID
 		"decision_type": "reallocation_success",
 		"policy":        PolicyReplaceIfPossible,
 		"lost_worker":   workerID,
-		"assignments":   len(assignments),
+		"assignments":   len(handoffs),
 	})
 	decisionEvent := RunEvent{
 		RunID:       record.RunID,
@@ -340,10 +366,52 @@ func (rm *RunManager) handleReplaceIfPossibleLocked(record *RunRecord, workerID
 	}
 	appendEventWithLog(eventLog, decisionEvent, "handleReplaceIfPossibleLocked")
 
-	log.Printf("[RunManager] Worker %s replaced, %d new assignments issued", workerID, len(assignments))
+	log.Printf("[RunManager] Worker %s replaced, %d new assignments issued", workerID, len(handoffs))
 	return nil
 }
 
+// reallocateStageLocked allocates the stage's targetVUs afresh across the
+// workers the run may use, leaving out workerID. Must be called with rm.mu
+// held.
+func (rm *RunManager) reallocateStageLocked(record *RunRecord, parsedConfig *parsedRunConfig, workerID, stageID string, targetVUs int) ([]groupDispatch, error) {
+	if (len(parsedConfig.GeneratorGroups) > 0 || len(parsedConfig.Targets) > 0 || parsedConfig.Placement != nil) && rm.registry != nil {
+		// Re-partition the remaining workers so each group keeps its own
+		// workers, each target its share of the VUs and the placement its
+		// constraints.
+		var remaining []*scheduler.WorkerInfo
+		for _, w := range placementWorkers(rm.registry, record.Project, parsedConfig.Placement) {
+			if w.WorkerID != scheduler.WorkerID(workerID) {
+				remaining = append(remaining, w)
+			}
+		}
+		remainingIDs := make([]scheduler.WorkerID, len(remaining))
+		for i, w := range remaining {
+			remainingIDs[i] = w.WorkerID
+		}
+		return allocateGroups(rm.allocator, record.RunID, stageID, parsedConfig.GeneratorGroups, parsedConfig.Targets,
+			workerSpread(remaining, parsedConfig.Placement), remainingIDs, 0, targetVUs)
+	}
+
+	// Workers of other projects are excluded along with the lost one.
+	exclude := []scheduler.WorkerID{scheduler.WorkerID(workerID)}
+	if rm.registry != nil {
+		for _, w := range rm.registry.ListWorkers() {
+			if !w.ServesProject(record.Project) {
+				exclude = append(exclude, w.WorkerID)
+			}
+		}
+	}
+	_, workerAssignments, err := rm.allocator.ReallocateAssignments(record.RunID, stageID, targetVUs, exclude)
+	if err != nil {
+		return nil, err
+	}
+	var dispatches []groupDispatch
+	for wid, assignment := range workerAssignments {
+		dispatches = append(dispatches, groupDispatch{workerID: wid, assignment: assignment})
+	}
+	return dispatches, nil
+}
+
 func (rm *RunManager) emitReallocationFailedDecision(eventLog *EventLog, record *RunRecord, workerID, reason string) {
 	decisionPayload, _ := json.Marshal(map[string]interface{}{
 		"decision_type": "reallocation_failed",
@@ -374,6 +442,26 @@ func (rm *RunManager) getStageDuration(config *parsedRunConfig, stageID string)
 	return 0
 }
 
+// getStageWarmup returns the warmup of the stage with stageID. Replacement
+// workers start cold, so they warm up for the stage's full warmup.
+func (rm *RunManager) getStageWarmup(config *parsedRunConfig, stageID string) int64 {
+	for _, stage := range config.Stages {
+		if stage.StageID == stageID {
+			return stage.WarmupMs
+		}
+	}
+	return 0
+}
+
+func (rm *RunManager) getStageChaos(config *parsedRunConfig, stageID string) *types.ChaosConfig {
+	for i := range config.Stages {
+		if config.Stages[i].StageID == stageID {
+			return buildChaosConfig(&config.Stages[i])
+		}
+	}
+	return nil
+}
+
 // handleBestEffortLocked handles worker failure with best_effort policy.
 // Logs a warning and continues with reduced capacity.
 // Must be called with rm.mu held.
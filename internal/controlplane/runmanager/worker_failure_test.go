@@ -450,6 +450,105 @@ func TestHandleReplaceIfPossible_Success(t *testing.T) {
 	_ = wid3
 }
 
+func TestHandleReplaceIfPossible_HandsOffLostLeases(t *testing.T) {
+	rm := NewRunManager(createTestValidatorForWorkerTest(t))
+
+	registry := scheduler.NewRegistry()
+	lm := scheduler.NewLeaseManager(60000)
+	allocator := scheduler.NewAllocator(registry, lm)
+	rm.SetScheduler(registry, allocator, lm)
+
+	mockSender := &mockAssignmentSender{assignments: make(map[string][]types.WorkerAssignment)}
+	rm.SetAssignmentSender(mockSender)
+
+	wid1, _ := registry.RegisterWorker(types.HostInfo{Hostname: "host1"}, types.WorkerCapacity{MaxVUs: 60})
+	wid2, _ := registry.RegisterWorker(types.HostInfo{Hostname: "host2"}, types.WorkerCapacity{MaxVUs: 60})
+	wid3, _ := registry.RegisterWorker(types.HostInfo{Hostname: "host3"}, types.WorkerCapacity{MaxVUs: 60})
+
+	const stageID = "stg_000000000002"
+	runID := createTestRunWithPolicy(t, rm, "replace_if_possible")
+	setRunState(t, rm, runID, RunStateBaselineRunning)
+	setActiveStage(t, rm, runID, "baseline", stageID)
+
+	remaining := make(map[scheduler.LeaseID]bool)
+	for i, wid := range []scheduler.WorkerID{wid1, wid2, wid3} {
+		assignment := scheduler.Assignment{RunID: runID, StageID: stageID, VUIDRange: scheduler.VUIDRange{Start: i * 40, End: (i + 1) * 40}}
+		leaseID, err := lm.IssueLease(wid, assignment)
+		if err != nil {
+			t.Fatalf("IssueLease failed: %v", err)
+		}
+		if wid != wid1 {
+			remaining[leaseID] = true
+		}
+		rm.emitWorkerAssignedEvent(runID, "exe_test", rm.eventLogs[runID], string(wid), string(leaseID),
+			assignment.VUIDRange.Start, assignment.VUIDRange.End, stageID, StageNameBaseline, "", "", nil)
+	}
+
+	// The heartbeat monitor revokes a dead worker's leases and removes it
+	// before reporting it lost.
+	_ = lm.RevokeWorkerLeases(wid1)
+	_ = registry.RemoveWorker(wid1)
+
+	if err := rm.HandleWorkerCapacityLost(runID, string(wid1)); err != nil {
+		t.Fatalf("HandleWorkerCapacityLost failed: %v", err)
+	}
+
+	view, _ := rm.GetRun(runID)
+	if view.State != RunStateBaselineRunning {
+		t.Errorf("expected the run to continue, got %s", view.State)
+	}
+
+	activeVUs := 0
+	for _, lease := range lm.ListLeases(runID) {
+		if lease.State != scheduler.LeaseStateActive {
+			continue
+		}
+		activeVUs += lease.Assignment.VUIDRange.End - lease.Assignment.VUIDRange.Start
+		delete(remaining, lease.LeaseID)
+	}
+	if len(remaining) != 0 {
+		t.Errorf("expected the remaining workers' leases to stay active, %d were lost", len(remaining))
+	}
+	if activeVUs != 120 {
+		t.Errorf("expected the stage to keep 120 VUs, got %d", activeVUs)
+	}
+
+	handedOff := 0
+	for wid, assignments := range mockSender.assignments {
+		if wid == string(wid1) {
+			t.Errorf("expected no assignments for the lost worker")
+		}
+		for _, a := range assignments {
+			if a.VUIDStart < 0 || a.VUIDEnd > 40 {
+				t.Errorf("expected only the lost VUs [0, 40) to be re-issued, got [%d, %d)", a.VUIDStart, a.VUIDEnd)
+			}
+			handedOff += a.VUIDEnd - a.VUIDStart
+		}
+	}
+	if handedOff != 40 {
+		t.Errorf("expected 40 VUs to be handed off, got %d", handedOff)
+	}
+
+	events, _ := rm.TailEvents(runID, 0, 100)
+	reassignedVUs := 0
+	for _, e := range events {
+		if e.Type != EventTypeLeaseReassigned {
+			continue
+		}
+		var payload map[string]interface{}
+		if err := json.Unmarshal(e.Payload, &payload); err != nil {
+			t.Fatalf("invalid LEASE_REASSIGNED payload: %v", err)
+		}
+		if payload["lost_worker"] != string(wid1) || payload["lost_lease_id"] == "" {
+			t.Errorf("unexpected LEASE_REASSIGNED payload %v", payload)
+		}
+		reassignedVUs += int(payload["vu_end"].(float64) - payload["vu_start"].(float64))
+	}
+	if reassignedVUs != 40 {
+		t.Errorf("expected LEASE_REASSIGNED events covering 40 VUs, got %d", reassignedVUs)
+	}
+}
+
 func TestHandleReplaceIfPossible_InsufficientCapacity(t *testing.T) {
 	rm := NewRunManager(createTestValidatorForWorkerTest(t))
 
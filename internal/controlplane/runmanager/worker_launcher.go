@@ -0,0 +1,180 @@
+package runmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+)
+
+const (
+	// workerLaunchPollInterval is how often a run with launched workers is
+	// checked for registered capacity while starting and for completion
+	// afterwards.
+	workerLaunchPollInterval = time.Second
+	// workerLaunchTimeout bounds a single launch or release call.
+	workerLaunchTimeout = 30 * time.Second
+)
+
+// WorkerLauncher starts workers dedicated to a run and removes them once the
+// run has ended. Launched workers register with the control plane like any
+// other worker.
+type WorkerLauncher interface {
+	// LaunchWorkers starts workers for runID providing at least vus VUs and
+	// returns their names.
+	LaunchWorkers(ctx context.Context, runID string, vus int) ([]string, error)
+	// ReleaseWorkers removes the workers launched for runID and returns
+	// their names.
+	ReleaseWorkers(ctx context.Context, runID string) ([]string, error)
+}
+
+// SetWorkerLauncher configures a launcher that starts workers for every run
+// when it is started. StartRun waits up to readyTimeout for the launched
+// workers to register before allocating the run.
+func (rm *RunManager) SetWorkerLauncher(launcher WorkerLauncher, readyTimeout time.Duration) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.workerLauncher = launcher
+	rm.workerReadyTimeout = readyTimeout
+}
+
+// provisionWorkers launches workers for the peak VUs of a starting run, waits
+// for them to register and arranges for their release when the run ends. A
+// run whose workers cannot be launched is left to the workers already
+// registered.
+func (rm *RunManager) provisionWorkers(runID, executionID string, config []byte, eventLog *EventLog) {
+	rm.mu.RLock()
+	launcher := rm.workerLauncher
+	readyTimeout := rm.workerReadyTimeout
+	registry := rm.registry
+	rm.mu.RUnlock()
+
+	if launcher == nil {
+		return
+	}
+
+	parsedConfig, err := parseRunConfig(config)
+	if err != nil {
+		return
+	}
+	vus := peakVUs(parsedConfig)
+	if vus <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(rm.ctx, workerLaunchTimeout)
+	workers, err := launcher.LaunchWorkers(ctx, runID, vus)
+	cancel()
+	if err != nil {
+		log.Printf("[RunManager] Failed to launch workers for run %s: %v", runID, err)
+		payload, _ := json.Marshal(map[string]interface{}{
+			"warning": "worker_launch_failed",
+			"message": fmt.Sprintf("Failed to launch workers for %d VUs: %v", vus, err),
+		})
+		appendEventWithLog(eventLog, RunEvent{
+			RunID:       runID,
+			ExecutionID: executionID,
+			Type:        EventTypeSystemWarning,
+			Actor:       ActorSystem,
+			Payload:     payload,
+			Evidence:    []Evidence{},
+		}, "provisionWorkers")
+		// Pods created before the failure are released with the run.
+	} else {
+		log.Printf("[RunManager] Launched %d workers for %d VUs of run %s", len(workers), vus, runID)
+		rm.emitWorkerLaunchEvent(runID, executionID, eventLog, EventTypeWorkerProvisioned, workers, vus)
+	}
+	rm.startWorkerReleaseWatcher(runID, executionID, eventLog, launcher)
+
+	if err != nil || registry == nil {
+		return
+	}
+
+	project := rm.GetRunProject(runID)
+	deadline := time.Now().Add(readyTimeout)
+	for {
+		available := 0
+		for _, w := range projectWorkers(registry, project) {
+			available += w.EffectiveCapacity.MaxVUs
+		}
+		if available >= vus {
+			return
+		}
+		if !time.Now().Before(deadline) {
+			log.Printf("[RunManager] Launched workers of run %s not ready after %s, %d of %d VUs available",
+				runID, readyTimeout, available, vus)
+			return
+		}
+		select {
+		case <-rm.ctx.Done():
+			return
+		case <-time.After(workerLaunchPollInterval):
+		}
+	}
+}
+
+// startWorkerReleaseWatcher releases the workers launched for a run once it
+// reaches a terminal state, or when the run manager shuts down.
+func (rm *RunManager) startWorkerReleaseWatcher(runID, executionID string, eventLog *EventLog, launcher WorkerLauncher) {
+	go func() {
+		ticker := time.NewTicker(workerLaunchPollInterval)
+		defer ticker.Stop()
+
+		for ended := false; !ended; {
+			select {
+			case <-rm.ctx.Done():
+				ended = true
+			case <-ticker.C:
+				rm.mu.RLock()
+				record, ok := rm.runs[runID]
+				ended = !ok || isTerminalRunState(record.State)
+				rm.mu.RUnlock()
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), workerLaunchTimeout)
+		defer cancel()
+		workers, err := launcher.ReleaseWorkers(ctx, runID)
+		if err != nil {
+			log.Printf("[RunManager] Failed to release workers of run %s: %v", runID, err)
+			return
+		}
+		log.Printf("[RunManager] Released %d workers of run %s", len(workers), runID)
+		rm.emitWorkerLaunchEvent(runID, executionID, eventLog, EventTypeWorkerReleased, workers, 0)
+	}()
+}
+
+func (rm *RunManager) emitWorkerLaunchEvent(runID, executionID string, eventLog *EventLog, eventType EventType, workers []string, vus int) {
+	fields := map[string]interface{}{
+		"workers":      workers,
+		"worker_count": len(workers),
+	}
+	if vus > 0 {
+		fields["vus"] = vus
+	}
+	payload, _ := json.Marshal(fields)
+
+	evidence := make([]Evidence, 0, len(workers))
+	for _, name := range workers {
+		evidence = append(evidence, Evidence{Kind: "worker", Ref: name})
+	}
+
+	appendEventWithLog(eventLog, RunEvent{
+		RunID:       runID,
+		ExecutionID: executionID,
+		Type:        eventType,
+		Actor:       ActorScheduler,
+		Payload:     payload,
+		Evidence:    evidence,
+	}, "emitWorkerLaunchEvent")
+}
+
+func isTerminalRunState(state RunState) bool {
+	switch state {
+	case RunStateCompleted, RunStateFailed, RunStateAborted:
+		return true
+	default:
+		return false
+	}
+}
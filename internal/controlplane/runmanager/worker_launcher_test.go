@@ -0,0 +1,96 @@
+package runmanager
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/scheduler"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+// fakeWorkerLauncher registers a worker with the requested VUs on launch,
+// as a launched pod would once started.
+type fakeWorkerLauncher struct {
+	registry *scheduler.Registry
+
+	mu       sync.Mutex
+	launched map[string]int
+	released []string
+}
+
+func (l *fakeWorkerLauncher) LaunchWorkers(ctx context.Context, runID string, vus int) ([]string, error) {
+	l.mu.Lock()
+	l.launched[runID] = vus
+	l.mu.Unlock()
+	go l.registry.RegisterWorker(types.HostInfo{Hostname: "launched"}, types.WorkerCapacity{MaxVUs: vus})
+	return []string{"worker-pod-a"}, nil
+}
+
+func (l *fakeWorkerLauncher) ReleaseWorkers(ctx context.Context, runID string) ([]string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.released = append(l.released, runID)
+	return []string{"worker-pod-a"}, nil
+}
+
+func (l *fakeWorkerLauncher) releasedRuns() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.released...)
+}
+
+func hasEventType(rm *RunManager, runID string, eventType EventType) bool {
+	rm.mu.RLock()
+	eventLog := rm.eventLogs[runID]
+	rm.mu.RUnlock()
+	for _, e := range eventLog.GetAll() {
+		if e.Type == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func TestStartRun_LaunchesAndReleasesWorkers(t *testing.T) {
+	rm := NewRunManager(createTestValidatorForWorkerTest(t))
+	defer rm.Shutdown()
+
+	registry := scheduler.NewRegistry()
+	lm := scheduler.NewLeaseManager(60000)
+	rm.SetScheduler(registry, scheduler.NewAllocator(registry, lm), lm)
+	rm.SetAssignmentSender(&mockAssignmentSender{assignments: make(map[string][]types.WorkerAssignment)})
+	launcher := &fakeWorkerLauncher{registry: registry, launched: make(map[string]int)}
+	rm.SetWorkerLauncher(launcher, 5*time.Second)
+
+	runID := createTestRunWithPolicy(t, rm, "")
+	if err := rm.StartRun(runID, "test"); err != nil {
+		t.Fatalf("StartRun failed: %v", err)
+	}
+
+	if vus := launcher.launched[runID]; vus != 50 {
+		t.Errorf("expected workers launched for the 50 VU peak, got %d", vus)
+	}
+	if !hasEventType(rm, runID, EventTypeWorkerProvisioned) {
+		t.Error("expected a WORKER_PROVISIONED event")
+	}
+	if len(launcher.releasedRuns()) != 0 {
+		t.Fatal("expected workers to be kept while the run is running")
+	}
+
+	setRunState(t, rm, runID, RunStateCompleted)
+	deadline := time.Now().Add(5 * time.Second)
+	for len(launcher.releasedRuns()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected workers to be released once the run completed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	for !hasEventType(rm, runID, EventTypeWorkerReleased) {
+		if time.Now().After(deadline) {
+			t.Fatal("expected a WORKER_RELEASED event")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,155 @@
+package runstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// migrations are applied in order, each in its own transaction; migration i
+// brings the schema to version i+1. Append new migrations, never edit
+// applied ones. Statements must run unchanged on SQLite and Postgres.
+var migrations = [][]string{
+	{
+		`CREATE TABLE runs (
+			run_id        TEXT PRIMARY KEY,
+			execution_id  TEXT NOT NULL,
+			state         TEXT NOT NULL,
+			scenario_id   TEXT NOT NULL,
+			config_hash   TEXT NOT NULL,
+			actor         TEXT NOT NULL,
+			created_at_ms BIGINT NOT NULL,
+			updated_at_ms BIGINT NOT NULL,
+			config        TEXT NOT NULL,
+			active_stage  TEXT,
+			stop_reason   TEXT
+		)`,
+		`CREATE INDEX runs_state_idx ON runs (state)`,
+		`CREATE TABLE run_events (
+			run_id   TEXT NOT NULL REFERENCES runs (run_id) ON DELETE CASCADE,
+			seq      INTEGER NOT NULL,
+			event_id TEXT NOT NULL,
+			type     TEXT NOT NULL,
+			ts_ms    BIGINT NOT NULL,
+			data     TEXT NOT NULL,
+			PRIMARY KEY (run_id, seq)
+		)`,
+		`CREATE TABLE telemetry_summaries (
+			run_id           TEXT PRIMARY KEY REFERENCES runs (run_id) ON DELETE CASCADE,
+			start_time_ms    BIGINT NOT NULL,
+			end_time_ms      BIGINT NOT NULL,
+			stop_reason      TEXT NOT NULL,
+			total_operations BIGINT NOT NULL,
+			metrics          TEXT NOT NULL,
+			saved_at_ms      BIGINT NOT NULL
+		)`,
+	},
+	{
+		`CREATE TABLE scenario_versions (
+			name          TEXT NOT NULL,
+			version       INTEGER NOT NULL,
+			config_hash   TEXT NOT NULL,
+			description   TEXT NOT NULL,
+			actor         TEXT NOT NULL,
+			created_at_ms BIGINT NOT NULL,
+			config        TEXT NOT NULL,
+			PRIMARY KEY (name, version)
+		)`,
+	},
+	{
+		`CREATE TABLE api_keys (
+			id              TEXT PRIMARY KEY,
+			name            TEXT NOT NULL,
+			prefix          TEXT NOT NULL,
+			key_hash        TEXT NOT NULL,
+			scopes          TEXT NOT NULL,
+			created_by      TEXT NOT NULL,
+			created_at_ms   BIGINT NOT NULL,
+			rotated_at_ms   BIGINT NOT NULL,
+			revoked_at_ms   BIGINT NOT NULL,
+			last_used_at_ms BIGINT NOT NULL
+		)`,
+	},
+	{
+		`CREATE TABLE audit_log (
+			id      TEXT PRIMARY KEY,
+			ts_ms   BIGINT NOT NULL,
+			actor   TEXT NOT NULL,
+			run_id  TEXT NOT NULL,
+			outcome TEXT NOT NULL,
+			method  TEXT NOT NULL,
+			path    TEXT NOT NULL,
+			data    TEXT NOT NULL
+		)`,
+		`CREATE INDEX audit_log_ts_idx ON audit_log (ts_ms)`,
+	},
+	{
+		`ALTER TABLE runs ADD COLUMN project TEXT NOT NULL DEFAULT 'default'`,
+		`ALTER TABLE api_keys ADD COLUMN projects TEXT NOT NULL DEFAULT ''`,
+		// Scenario names become unique per project, which needs a new
+		// primary key and so a new table.
+		`CREATE TABLE scenario_versions_v2 (
+			project       TEXT NOT NULL,
+			name          TEXT NOT NULL,
+			version       INTEGER NOT NULL,
+			config_hash   TEXT NOT NULL,
+			description   TEXT NOT NULL,
+			actor         TEXT NOT NULL,
+			created_at_ms BIGINT NOT NULL,
+			config        TEXT NOT NULL,
+			PRIMARY KEY (project, name, version)
+		)`,
+		`INSERT INTO scenario_versions_v2 (project, name, version, config_hash, description, actor, created_at_ms, config)
+			SELECT 'default', name, version, config_hash, description, actor, created_at_ms, config FROM scenario_versions`,
+		`DROP TABLE scenario_versions`,
+		`ALTER TABLE scenario_versions_v2 RENAME TO scenario_versions`,
+	},
+}
+
+// migrate brings the schema up to date.
+func (s *sqlStore) migrate(ctx context.Context) error {
+	if err := s.exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version       INTEGER PRIMARY KEY,
+		applied_at_ms BIGINT NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	var current sql.NullInt64
+	if err := s.db.QueryRowContext(ctx, `SELECT MAX(version) FROM schema_migrations`).Scan(&current); err != nil {
+		return fmt.Errorf("read schema version: %w", err)
+	}
+	if int(current.Int64) > len(migrations) {
+		return fmt.Errorf("store schema version %d is newer than this build supports (%d)", current.Int64, len(migrations))
+	}
+
+	for i := int(current.Int64); i < len(migrations); i++ {
+		if err := s.applyMigration(ctx, i+1, migrations[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sqlStore) applyMigration(ctx context.Context, version int, statements []string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("migrate to version %d: %w", version, err)
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("migrate to version %d: %w", version, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, s.dialect.rebind(`INSERT INTO schema_migrations (version, applied_at_ms) VALUES (?, ?)`),
+		version, time.Now().UnixMilli()); err != nil {
+		return fmt.Errorf("migrate to version %d: %w", version, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("migrate to version %d: %w", version, err)
+	}
+	return nil
+}
@@ -0,0 +1,442 @@
+package runstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
+)
+
+// dialect holds the differences between the supported databases. The schema
+// and queries are otherwise shared.
+type dialect struct {
+	name string
+	// numbered placeholders ($1, $2, ...) instead of ?
+	numbered bool
+}
+
+var (
+	sqliteDialect   = dialect{name: "sqlite"}
+	postgresDialect = dialect{name: "postgres", numbered: true}
+)
+
+// rebind rewrites ? placeholders for the dialect. Queries in this package
+// never contain ? inside string literals.
+func (d dialect) rebind(query string) string {
+	if !d.numbered {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// sqlStore implements Store on database/sql.
+type sqlStore struct {
+	db      *sql.DB
+	dialect dialect
+}
+
+func openSQLite(ctx context.Context, path string) (*sqlStore, error) {
+	// WAL lets readers proceed during writes; the busy timeout covers the
+	// rest. A single connection serializes writers within the process.
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	dsn := path + sep + "_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite store: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	return newSQLStore(ctx, db, sqliteDialect)
+}
+
+func openPostgres(ctx context.Context, dsn string) (*sqlStore, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open postgres store: %w", err)
+	}
+	return newSQLStore(ctx, db, postgresDialect)
+}
+
+func newSQLStore(ctx context.Context, db *sql.DB, d dialect) (*sqlStore, error) {
+	s := &sqlStore{db: db, dialect: d}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("connect to %s store: %w", d.name, err)
+	}
+	if err := s.migrate(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *sqlStore) exec(ctx context.Context, query string, args ...interface{}) error {
+	_, err := s.db.ExecContext(ctx, s.dialect.rebind(query), args...)
+	return err
+}
+
+func (s *sqlStore) SaveRun(ctx context.Context, run *Run) error {
+	err := s.exec(ctx, `
+		INSERT INTO runs (run_id, project, execution_id, state, scenario_id, config_hash, actor,
+			created_at_ms, updated_at_ms, config, active_stage, stop_reason)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (run_id) DO UPDATE SET
+			project = excluded.project,
+			execution_id = excluded.execution_id,
+			state = excluded.state,
+			scenario_id = excluded.scenario_id,
+			config_hash = excluded.config_hash,
+			actor = excluded.actor,
+			updated_at_ms = excluded.updated_at_ms,
+			config = excluded.config,
+			active_stage = excluded.active_stage,
+			stop_reason = excluded.stop_reason`,
+		run.RunID, projectOrDefault(run.Project), run.ExecutionID, run.State, run.ScenarioID, run.ConfigHash, run.Actor,
+		run.CreatedAtMs, run.UpdatedAtMs, string(run.Config),
+		nullJSON(run.ActiveStage), nullJSON(run.StopReason))
+	if err != nil {
+		return fmt.Errorf("save run %s: %w", run.RunID, err)
+	}
+	return nil
+}
+
+func (s *sqlStore) AppendEvent(ctx context.Context, event *Event) error {
+	err := s.exec(ctx, `
+		INSERT INTO run_events (run_id, seq, event_id, type, ts_ms, data)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (run_id, seq) DO NOTHING`,
+		event.RunID, event.Seq, event.EventID, event.Type, event.TimestampMs, string(event.Data))
+	if err != nil {
+		return fmt.Errorf("append event %s for run %s: %w", event.EventID, event.RunID, err)
+	}
+	return nil
+}
+
+func (s *sqlStore) SaveTelemetrySummary(ctx context.Context, summary *TelemetrySummary) error {
+	err := s.exec(ctx, `
+		INSERT INTO telemetry_summaries (run_id, start_time_ms, end_time_ms, stop_reason,
+			total_operations, metrics, saved_at_ms)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (run_id) DO UPDATE SET
+			start_time_ms = excluded.start_time_ms,
+			end_time_ms = excluded.end_time_ms,
+			stop_reason = excluded.stop_reason,
+			total_operations = excluded.total_operations,
+			metrics = excluded.metrics,
+			saved_at_ms = excluded.saved_at_ms`,
+		summary.RunID, summary.StartTimeMs, summary.EndTimeMs, summary.StopReason,
+		summary.TotalOperations, string(summary.Metrics), time.Now().UnixMilli())
+	if err != nil {
+		return fmt.Errorf("save telemetry summary for run %s: %w", summary.RunID, err)
+	}
+	return nil
+}
+
+func (s *sqlStore) ListRuns(ctx context.Context) ([]*Run, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT run_id, project, execution_id, state, scenario_id, config_hash, actor,
+			created_at_ms, updated_at_ms, config, active_stage, stop_reason
+		FROM runs ORDER BY created_at_ms, run_id`)
+	if err != nil {
+		return nil, fmt.Errorf("list runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []*Run
+	for rows.Next() {
+		var (
+			run                     Run
+			config                  string
+			activeStage, stopReason sql.NullString
+		)
+		if err := rows.Scan(&run.RunID, &run.Project, &run.ExecutionID, &run.State, &run.ScenarioID,
+			&run.ConfigHash, &run.Actor, &run.CreatedAtMs, &run.UpdatedAtMs,
+			&config, &activeStage, &stopReason); err != nil {
+			return nil, fmt.Errorf("list runs: %w", err)
+		}
+		run.Config = []byte(config)
+		if activeStage.Valid {
+			run.ActiveStage = []byte(activeStage.String)
+		}
+		if stopReason.Valid {
+			run.StopReason = []byte(stopReason.String)
+		}
+		runs = append(runs, &run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list runs: %w", err)
+	}
+	return runs, nil
+}
+
+func (s *sqlStore) ListEvents(ctx context.Context, runID string) ([]*Event, error) {
+	rows, err := s.db.QueryContext(ctx, s.dialect.rebind(`
+		SELECT seq, event_id, type, ts_ms, data
+		FROM run_events WHERE run_id = ? ORDER BY seq`), runID)
+	if err != nil {
+		return nil, fmt.Errorf("list events for run %s: %w", runID, err)
+	}
+	defer rows.Close()
+
+	var events []*Event
+	for rows.Next() {
+		event := Event{RunID: runID}
+		var data string
+		if err := rows.Scan(&event.Seq, &event.EventID, &event.Type, &event.TimestampMs, &data); err != nil {
+			return nil, fmt.Errorf("list events for run %s: %w", runID, err)
+		}
+		event.Data = []byte(data)
+		events = append(events, &event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list events for run %s: %w", runID, err)
+	}
+	return events, nil
+}
+
+func (s *sqlStore) GetTelemetrySummary(ctx context.Context, runID string) (*TelemetrySummary, error) {
+	summary := TelemetrySummary{RunID: runID}
+	var metrics string
+	err := s.db.QueryRowContext(ctx, s.dialect.rebind(`
+		SELECT start_time_ms, end_time_ms, stop_reason, total_operations, metrics
+		FROM telemetry_summaries WHERE run_id = ?`), runID).
+		Scan(&summary.StartTimeMs, &summary.EndTimeMs, &summary.StopReason, &summary.TotalOperations, &metrics)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get telemetry summary for run %s: %w", runID, err)
+	}
+	summary.Metrics = []byte(metrics)
+	return &summary, nil
+}
+
+func (s *sqlStore) DeleteRun(ctx context.Context, runID string) error {
+	// Events and the telemetry summary go with the run: ON DELETE CASCADE.
+	if err := s.exec(ctx, `DELETE FROM runs WHERE run_id = ?`, runID); err != nil {
+		return fmt.Errorf("delete run %s: %w", runID, err)
+	}
+	return nil
+}
+
+func (s *sqlStore) SaveScenarioVersion(ctx context.Context, version *ScenarioVersion) error {
+	err := s.exec(ctx, `
+		INSERT INTO scenario_versions (project, name, version, config_hash, description, actor, created_at_ms, config)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		projectOrDefault(version.Project), version.Name, version.Version, version.ConfigHash, version.Description, version.Actor,
+		version.CreatedAtMs, string(version.Config))
+	if err != nil {
+		return fmt.Errorf("save scenario %s version %d: %w", version.Name, version.Version, err)
+	}
+	return nil
+}
+
+func (s *sqlStore) ListScenarioVersions(ctx context.Context) ([]*ScenarioVersion, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT project, name, version, config_hash, description, actor, created_at_ms, config
+		FROM scenario_versions ORDER BY project, name, version`)
+	if err != nil {
+		return nil, fmt.Errorf("list scenario versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []*ScenarioVersion
+	for rows.Next() {
+		var (
+			version ScenarioVersion
+			config  string
+		)
+		if err := rows.Scan(&version.Project, &version.Name, &version.Version, &version.ConfigHash, &version.Description,
+			&version.Actor, &version.CreatedAtMs, &config); err != nil {
+			return nil, fmt.Errorf("list scenario versions: %w", err)
+		}
+		version.Config = []byte(config)
+		versions = append(versions, &version)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list scenario versions: %w", err)
+	}
+	return versions, nil
+}
+
+func (s *sqlStore) DeleteScenario(ctx context.Context, project, name string) error {
+	if err := s.exec(ctx, `DELETE FROM scenario_versions WHERE project = ? AND name = ?`, projectOrDefault(project), name); err != nil {
+		return fmt.Errorf("delete scenario %s: %w", name, err)
+	}
+	return nil
+}
+
+func (s *sqlStore) SaveAPIKey(ctx context.Context, key *APIKey) error {
+	err := s.exec(ctx, `
+		INSERT INTO api_keys (id, name, prefix, key_hash, scopes, projects, created_by, created_at_ms,
+			rotated_at_ms, revoked_at_ms, last_used_at_ms)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			name = excluded.name,
+			prefix = excluded.prefix,
+			key_hash = excluded.key_hash,
+			scopes = excluded.scopes,
+			projects = excluded.projects,
+			rotated_at_ms = excluded.rotated_at_ms,
+			revoked_at_ms = excluded.revoked_at_ms,
+			last_used_at_ms = excluded.last_used_at_ms`,
+		key.ID, key.Name, key.Prefix, key.KeyHash, strings.Join(key.Scopes, ","), strings.Join(key.Projects, ","), key.CreatedBy,
+		key.CreatedAtMs, key.RotatedAtMs, key.RevokedAtMs, key.LastUsedAtMs)
+	if err != nil {
+		return fmt.Errorf("save api key %s: %w", key.ID, err)
+	}
+	return nil
+}
+
+func (s *sqlStore) ListAPIKeys(ctx context.Context) ([]*APIKey, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, name, prefix, key_hash, scopes, projects, created_by, created_at_ms,
+			rotated_at_ms, revoked_at_ms, last_used_at_ms
+		FROM api_keys ORDER BY created_at_ms, id`)
+	if err != nil {
+		return nil, fmt.Errorf("list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*APIKey
+	for rows.Next() {
+		var (
+			key              APIKey
+			scopes, projects string
+		)
+		if err := rows.Scan(&key.ID, &key.Name, &key.Prefix, &key.KeyHash, &scopes, &projects, &key.CreatedBy,
+			&key.CreatedAtMs, &key.RotatedAtMs, &key.RevokedAtMs, &key.LastUsedAtMs); err != nil {
+			return nil, fmt.Errorf("list api keys: %w", err)
+		}
+		if scopes != "" {
+			key.Scopes = strings.Split(scopes, ",")
+		}
+		if projects != "" {
+			key.Projects = strings.Split(projects, ",")
+		}
+		keys = append(keys, &key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list api keys: %w", err)
+	}
+	return keys, nil
+}
+
+func (s *sqlStore) AppendAuditEntry(ctx context.Context, entry *AuditEntry) error {
+	err := s.exec(ctx, `
+		INSERT INTO audit_log (id, ts_ms, actor, run_id, outcome, method, path, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.ID, entry.TimestampMs, entry.Actor, entry.RunID, entry.Outcome, entry.Method,
+		entry.Path, string(entry.Data))
+	if err != nil {
+		return fmt.Errorf("append audit entry %s: %w", entry.ID, err)
+	}
+	return nil
+}
+
+func (s *sqlStore) ListAuditEntries(ctx context.Context, filter AuditFilter) ([]*AuditEntry, error) {
+	var (
+		where []string
+		args  []interface{}
+	)
+	for _, eq := range []struct{ column, value string }{
+		{"actor", filter.Actor},
+		{"run_id", filter.RunID},
+		{"outcome", filter.Outcome},
+		{"method", filter.Method},
+	} {
+		if eq.value != "" {
+			where = append(where, eq.column+" = ?")
+			args = append(args, eq.value)
+		}
+	}
+	if filter.PathPrefix != "" {
+		where = append(where, "substr(path, 1, ?) = ?")
+		args = append(args, len(filter.PathPrefix), filter.PathPrefix)
+	}
+	if filter.SinceMs > 0 {
+		where = append(where, "ts_ms >= ?")
+		args = append(args, filter.SinceMs)
+	}
+	if filter.UntilMs > 0 {
+		where = append(where, "ts_ms < ?")
+		args = append(args, filter.UntilMs)
+	}
+
+	query := `SELECT id, ts_ms, actor, run_id, outcome, method, path, data FROM audit_log`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	if filter.NewestFirst {
+		query += " ORDER BY ts_ms DESC, id DESC"
+	} else {
+		query += " ORDER BY ts_ms, id"
+	}
+	if filter.Limit > 0 {
+		query += " LIMIT " + strconv.Itoa(filter.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, s.dialect.rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("list audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*AuditEntry
+	for rows.Next() {
+		var (
+			entry AuditEntry
+			data  string
+		)
+		if err := rows.Scan(&entry.ID, &entry.TimestampMs, &entry.Actor, &entry.RunID, &entry.Outcome,
+			&entry.Method, &entry.Path, &data); err != nil {
+			return nil, fmt.Errorf("list audit entries: %w", err)
+		}
+		entry.Data = []byte(data)
+		entries = append(entries, &entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list audit entries: %w", err)
+	}
+	return entries, nil
+}
+
+func (s *sqlStore) Close() error {
+	return s.db.Close()
+}
+
+// projectOrDefault stores records saved without a project in the default
+// project.
+func projectOrDefault(project string) string {
+	if project == "" {
+		return "default"
+	}
+	return project
+}
+
+// nullJSON stores absent JSON values as NULL.
+func nullJSON(data []byte) sql.NullString {
+	if len(data) == 0 {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: string(data), Valid: true}
+}
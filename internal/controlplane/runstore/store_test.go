@@ -0,0 +1,286 @@
+package runstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func openTestSQLite(t *testing.T, path string) Store {
+	t.Helper()
+	store, err := Open(context.Background(), "sqlite:"+path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestSQLiteStore_RunsAndEvents(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "runs.db")
+	store := openTestSQLite(t, path)
+
+	run := &Run{
+		RunID:       "run_0000000000000001",
+		ExecutionID: "exe_00000001",
+		State:       "created",
+		ScenarioID:  "scenario",
+		ConfigHash:  "abc",
+		Actor:       "user",
+		Project:     "team-a",
+		CreatedAtMs: 1000,
+		UpdatedAtMs: 1000,
+		Config:      json.RawMessage(`{"scenario_id":"scenario"}`),
+	}
+	if err := store.SaveRun(ctx, run); err != nil {
+		t.Fatalf("SaveRun failed: %v", err)
+	}
+
+	run.State = "stopping"
+	run.UpdatedAtMs = 2000
+	run.StopReason = json.RawMessage(`{"mode":"drain"}`)
+	if err := store.SaveRun(ctx, run); err != nil {
+		t.Fatalf("SaveRun update failed: %v", err)
+	}
+
+	for seq, typ := range []string{"RUN_CREATED", "STATE_TRANSITION"} {
+		event := &Event{RunID: run.RunID, Seq: seq, EventID: "evt_" + typ, Type: typ, TimestampMs: int64(seq), Data: json.RawMessage(`{"type":"` + typ + `"}`)}
+		if err := store.AppendEvent(ctx, event); err != nil {
+			t.Fatalf("AppendEvent failed: %v", err)
+		}
+	}
+	// Re-appending a stored seq is ignored.
+	if err := store.AppendEvent(ctx, &Event{RunID: run.RunID, Seq: 1, EventID: "evt_dup", Type: "DECISION", Data: json.RawMessage(`{}`)}); err != nil {
+		t.Fatalf("AppendEvent duplicate failed: %v", err)
+	}
+
+	store.Close()
+	store = openTestSQLite(t, path)
+
+	runs, err := store.ListRuns(ctx)
+	if err != nil {
+		t.Fatalf("ListRuns failed: %v", err)
+	}
+	if len(runs) != 1 {
+		t.Fatalf("expected 1 run, got %d", len(runs))
+	}
+	got := runs[0]
+	if got.State != "stopping" || got.UpdatedAtMs != 2000 || got.CreatedAtMs != 1000 || got.Project != "team-a" {
+		t.Errorf("unexpected run: %+v", got)
+	}
+	if string(got.Config) != string(run.Config) || string(got.StopReason) != `{"mode":"drain"}` || got.ActiveStage != nil {
+		t.Errorf("unexpected run JSON fields: config=%s stop=%s stage=%s", got.Config, got.StopReason, got.ActiveStage)
+	}
+
+	events, err := store.ListEvents(ctx, run.RunID)
+	if err != nil {
+		t.Fatalf("ListEvents failed: %v", err)
+	}
+	if len(events) != 2 || events[0].Type != "RUN_CREATED" || events[1].EventID != "evt_STATE_TRANSITION" {
+		t.Errorf("unexpected events: %+v", events)
+	}
+}
+
+func TestSQLiteStore_TelemetrySummary(t *testing.T) {
+	ctx := context.Background()
+	store := openTestSQLite(t, filepath.Join(t.TempDir(), "runs.db"))
+
+	if err := store.SaveRun(ctx, &Run{RunID: "run_1", Config: json.RawMessage(`{}`)}); err != nil {
+		t.Fatalf("SaveRun failed: %v", err)
+	}
+	if _, err := store.GetTelemetrySummary(ctx, "run_1"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	summary := &TelemetrySummary{
+		RunID:           "run_1",
+		StartTimeMs:     100,
+		EndTimeMs:       200,
+		StopReason:      "completed",
+		TotalOperations: 42,
+		Metrics:         json.RawMessage(`{"total_ops":42}`),
+	}
+	if err := store.SaveTelemetrySummary(ctx, summary); err != nil {
+		t.Fatalf("SaveTelemetrySummary failed: %v", err)
+	}
+	summary.TotalOperations = 43
+	if err := store.SaveTelemetrySummary(ctx, summary); err != nil {
+		t.Fatalf("SaveTelemetrySummary update failed: %v", err)
+	}
+
+	got, err := store.GetTelemetrySummary(ctx, "run_1")
+	if err != nil {
+		t.Fatalf("GetTelemetrySummary failed: %v", err)
+	}
+	if got.TotalOperations != 43 || got.StopReason != "completed" || string(got.Metrics) != `{"total_ops":42}` {
+		t.Errorf("unexpected summary: %+v", got)
+	}
+
+	if err := store.AppendEvent(ctx, &Event{RunID: "run_1", Seq: 0, EventID: "evt_1", Type: "RUN_CREATED", Data: json.RawMessage(`{}`)}); err != nil {
+		t.Fatalf("AppendEvent failed: %v", err)
+	}
+	if err := store.DeleteRun(ctx, "run_1"); err != nil {
+		t.Fatalf("DeleteRun failed: %v", err)
+	}
+	if err := store.DeleteRun(ctx, "run_1"); err != nil {
+		t.Errorf("expected deleting a deleted run to be a no-op, got %v", err)
+	}
+	runs, _ := store.ListRuns(ctx)
+	events, _ := store.ListEvents(ctx, "run_1")
+	if _, err := store.GetTelemetrySummary(ctx, "run_1"); len(runs) != 0 || len(events) != 0 || !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the run, its events and summary to be deleted, got %d runs, %d events, %v", len(runs), len(events), err)
+	}
+}
+
+func TestSQLiteStore_ScenarioVersions(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "runs.db")
+	store := openTestSQLite(t, path)
+
+	for _, v := range []*ScenarioVersion{
+		{Name: "checkout", Version: 2, ConfigHash: "h2", Actor: "user", CreatedAtMs: 2000, Config: json.RawMessage(`{"v":2}`)},
+		{Name: "checkout", Version: 1, ConfigHash: "h1", Description: "first", Actor: "user", CreatedAtMs: 1000, Config: json.RawMessage(`{"v":1}`)},
+		{Name: "browse", Version: 1, ConfigHash: "b1", Actor: "user", CreatedAtMs: 1500, Config: json.RawMessage(`{}`)},
+		{Project: "team-a", Name: "checkout", Version: 1, ConfigHash: "a1", Actor: "user", CreatedAtMs: 1200, Config: json.RawMessage(`{}`)},
+	} {
+		if err := store.SaveScenarioVersion(ctx, v); err != nil {
+			t.Fatalf("SaveScenarioVersion failed: %v", err)
+		}
+	}
+	if err := store.SaveScenarioVersion(ctx, &ScenarioVersion{Name: "checkout", Version: 1, Config: json.RawMessage(`{}`)}); err == nil {
+		t.Error("expected saving an existing version to fail")
+	}
+
+	store.Close()
+	store = openTestSQLite(t, path)
+
+	versions, err := store.ListScenarioVersions(ctx)
+	if err != nil {
+		t.Fatalf("ListScenarioVersions failed: %v", err)
+	}
+	if len(versions) != 4 || versions[0].Name != "browse" || versions[1].Version != 1 || versions[2].Version != 2 || versions[3].Project != "team-a" {
+		t.Fatalf("expected versions ordered by project, name and version, got %+v", versions)
+	}
+	if versions[0].Project != "default" {
+		t.Errorf("expected versions saved without a project in the default project, got %q", versions[0].Project)
+	}
+	if versions[1].Description != "first" || string(versions[1].Config) != `{"v":1}` {
+		t.Errorf("unexpected version: %+v", versions[1])
+	}
+
+	if err := store.DeleteScenario(ctx, "default", "checkout"); err != nil {
+		t.Fatalf("DeleteScenario failed: %v", err)
+	}
+	versions, err = store.ListScenarioVersions(ctx)
+	if err != nil || len(versions) != 2 || versions[0].Name != "browse" || versions[1].Project != "team-a" {
+		t.Errorf("expected browse and team-a's checkout after deleting checkout, got %+v, %v", versions, err)
+	}
+}
+
+func TestSQLiteStore_APIKeys(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "runs.db")
+	store := openTestSQLite(t, path)
+
+	key := &APIKey{
+		ID: "key_1", Name: "ci", Prefix: "mcpd_0123", KeyHash: "h1",
+		Scopes: []string{"runs:read", "runs:write"}, Projects: []string{"team-a", "team-b"}, CreatedBy: "admin", CreatedAtMs: 1000,
+	}
+	if err := store.SaveAPIKey(ctx, key); err != nil {
+		t.Fatalf("SaveAPIKey failed: %v", err)
+	}
+	if err := store.SaveAPIKey(ctx, &APIKey{ID: "key_0", Name: "agents", KeyHash: "h0", Scopes: []string{"agents:ingest"}, CreatedAtMs: 500}); err != nil {
+		t.Fatalf("SaveAPIKey failed: %v", err)
+	}
+	key.KeyHash = "h2"
+	key.RotatedAtMs = 2000
+	key.LastUsedAtMs = 2500
+	if err := store.SaveAPIKey(ctx, key); err != nil {
+		t.Fatalf("SaveAPIKey update failed: %v", err)
+	}
+
+	store.Close()
+	store = openTestSQLite(t, path)
+
+	keys, err := store.ListAPIKeys(ctx)
+	if err != nil {
+		t.Fatalf("ListAPIKeys failed: %v", err)
+	}
+	if len(keys) != 2 || keys[0].ID != "key_0" {
+		t.Fatalf("expected keys ordered by creation, got %+v", keys)
+	}
+	got := keys[1]
+	if got.KeyHash != "h2" || got.RotatedAtMs != 2000 || got.LastUsedAtMs != 2500 || got.CreatedBy != "admin" {
+		t.Errorf("unexpected key: %+v", got)
+	}
+	if len(got.Projects) != 2 || got.Projects[1] != "team-b" || keys[0].Projects != nil {
+		t.Errorf("unexpected projects: %v, %v", got.Projects, keys[0].Projects)
+	}
+	if len(got.Scopes) != 2 || got.Scopes[1] != "runs:write" {
+		t.Errorf("unexpected scopes: %v", got.Scopes)
+	}
+}
+
+func TestSQLiteStore_AuditLog(t *testing.T) {
+	ctx := context.Background()
+	store := openTestSQLite(t, filepath.Join(t.TempDir(), "runs.db"))
+
+	for _, e := range []*AuditEntry{
+		{ID: "aud_1", TimestampMs: 1000, Actor: "alice", Outcome: "success", Method: "POST", Path: "/runs", RunID: "run_1"},
+		{ID: "aud_2", TimestampMs: 2000, Actor: "bob", Outcome: "denied", Method: "POST", Path: "/runs/run_1/stop", RunID: "run_1"},
+		{ID: "aud_3", TimestampMs: 3000, Actor: "alice", Outcome: "success", Method: "DELETE", Path: "/scenarios/checkout"},
+	} {
+		e.Data = json.RawMessage(`{"id":"` + e.ID + `"}`)
+		if err := store.AppendAuditEntry(ctx, e); err != nil {
+			t.Fatalf("AppendAuditEntry failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter AuditFilter
+		want   []string
+	}{
+		{"all", AuditFilter{}, []string{"aud_1", "aud_2", "aud_3"}},
+		{"actor", AuditFilter{Actor: "alice"}, []string{"aud_1", "aud_3"}},
+		{"run", AuditFilter{RunID: "run_1", Outcome: "denied"}, []string{"aud_2"}},
+		{"path prefix", AuditFilter{PathPrefix: "/runs"}, []string{"aud_1", "aud_2"}},
+		{"time range", AuditFilter{SinceMs: 2000, UntilMs: 3000}, []string{"aud_2"}},
+		{"newest first", AuditFilter{NewestFirst: true, Limit: 2}, []string{"aud_3", "aud_2"}},
+	}
+	for _, tt := range tests {
+		entries, err := store.ListAuditEntries(ctx, tt.filter)
+		if err != nil {
+			t.Fatalf("%s: ListAuditEntries failed: %v", tt.name, err)
+		}
+		var got []string
+		for _, e := range entries {
+			got = append(got, e.ID)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestOpen_InvalidSpec(t *testing.T) {
+	for _, spec := range []string{"", "sqlite", "sqlite:", "mysql:root@/db"} {
+		if _, err := Open(context.Background(), spec); err == nil {
+			t.Errorf("Open(%q): expected error", spec)
+		}
+	}
+}
+
+func TestDialectRebind(t *testing.T) {
+	query := "SELECT a FROM t WHERE b = ? AND c = ?"
+	if got := sqliteDialect.rebind(query); got != query {
+		t.Errorf("sqlite rebind changed query: %s", got)
+	}
+	if got := postgresDialect.rebind(query); got != "SELECT a FROM t WHERE b = $1 AND c = $2" {
+		t.Errorf("unexpected postgres query: %s", got)
+	}
+}
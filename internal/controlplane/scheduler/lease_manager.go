@@ -197,6 +197,22 @@ func (lm *LeaseManager) ListLeases(runID string) []*Lease {
 	return result
 }
 
+// ListWorkerLeases returns copies of all leases issued to workerID, in any
+// state.
+func (lm *LeaseManager) ListWorkerLeases(workerID WorkerID) []*Lease {
+	lm.mu.RLock()
+	defer lm.mu.RUnlock()
+
+	var result []*Lease
+	for _, lease := range lm.leases {
+		if lease.WorkerID == workerID {
+			result = append(result, lease.Copy())
+		}
+	}
+
+	return result
+}
+
 func (lm *LeaseManager) ListWorkerRunIDs(workerID WorkerID) []string {
 	lm.mu.RLock()
 	defer lm.mu.RUnlock()
@@ -483,6 +483,41 @@ func TestListLeases(t *testing.T) {
 	})
 }
 
+func TestListWorkerLeases(t *testing.T) {
+	lm := NewLeaseManager(60000)
+
+	leaseID1, _ := lm.IssueLease("worker_1", Assignment{
+		RunID:     "run_0000000000000001",
+		StageID:   "stage_1",
+		VUIDRange: VUIDRange{0, 100},
+	})
+	lm.IssueLease("worker_1", Assignment{
+		RunID:     "run_0000000000000002",
+		StageID:   "stage_1",
+		VUIDRange: VUIDRange{0, 50},
+	})
+	lm.IssueLease("worker_2", Assignment{
+		RunID:     "run_0000000000000001",
+		StageID:   "stage_1",
+		VUIDRange: VUIDRange{100, 200},
+	})
+	lm.RevokeLease(leaseID1)
+
+	leases := lm.ListWorkerLeases("worker_1")
+	if len(leases) != 2 {
+		t.Fatalf("Expected 2 leases for worker_1 across runs and states, got %d", len(leases))
+	}
+	for _, lease := range leases {
+		if lease.WorkerID != "worker_1" {
+			t.Errorf("Expected only worker_1 leases, got %s", lease.WorkerID)
+		}
+	}
+
+	if leases := lm.ListWorkerLeases("worker_3"); len(leases) != 0 {
+		t.Errorf("Expected no leases for unknown worker, got %d", len(leases))
+	}
+}
+
 func TestExpireLeases(t *testing.T) {
 	t.Run("no leases", func(t *testing.T) {
 		lm := NewLeaseManager(60000)
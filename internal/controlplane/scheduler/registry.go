@@ -34,6 +34,12 @@ func (r *Registry) generateWorkerID() WorkerID {
 }
 
 func (r *Registry) RegisterWorker(hostInfo types.HostInfo, capacity types.WorkerCapacity) (WorkerID, error) {
+	return r.RegisterProjectWorker("", hostInfo, capacity)
+}
+
+// RegisterProjectWorker registers a worker that only runs runs of project,
+// or a shared worker when project is empty.
+func (r *Registry) RegisterProjectWorker(project string, hostInfo types.HostInfo, capacity types.WorkerCapacity) (WorkerID, error) {
 	if r.closed.Load() {
 		return "", ErrRegistryClosed
 	}
@@ -50,6 +56,7 @@ func (r *Registry) RegisterWorker(hostInfo types.HostInfo, capacity types.Worker
 		RegisteredAt:      nowMs,
 		LastHeartbeat:     nowMs,
 		Health:            nil,
+		Project:           project,
 	}
 
 	r.mu.Lock()
@@ -96,7 +103,7 @@ func (r *Registry) updateEffectiveCapacity(worker *WorkerInfo) {
 	if worker.Saturated {
 		if worker.Health.CPUPercent < unsaturateThreshold && worker.Health.ActiveVUs < worker.Capacity.MaxVUs {
 			worker.Saturated = false
-			worker.EffectiveCapacity = worker.Capacity
+			worker.EffectiveCapacity = sheddingCapacity(worker)
 		} else {
 			worker.EffectiveCapacity = types.WorkerCapacity{MaxVUs: 0}
 		}
@@ -105,11 +112,21 @@ func (r *Registry) updateEffectiveCapacity(worker *WorkerInfo) {
 			worker.Saturated = true
 			worker.EffectiveCapacity = types.WorkerCapacity{MaxVUs: 0}
 		} else {
-			worker.EffectiveCapacity = worker.Capacity
+			worker.EffectiveCapacity = sheddingCapacity(worker)
 		}
 	}
 }
 
+// sheddingCapacity returns the capacity of a worker that is not saturated,
+// less the share of load it reports shedding to relieve its resources.
+func sheddingCapacity(worker *WorkerInfo) types.WorkerCapacity {
+	capacity := worker.Capacity
+	if shed := worker.Health.ShedRate; shed > 0 {
+		capacity.MaxVUs = int(float64(capacity.MaxVUs) * (1 - min(shed, 1)))
+	}
+	return capacity
+}
+
 func (r *Registry) GetWorker(workerID WorkerID) (*WorkerInfo, error) {
 	if r.closed.Load() {
 		return nil, ErrRegistryClosed
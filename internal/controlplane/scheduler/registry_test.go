@@ -131,6 +131,29 @@ func TestHeartbeat(t *testing.T) {
 	}
 }
 
+func TestHeartbeatShedRateReducesCapacity(t *testing.T) {
+	r := NewRegistry()
+
+	hostInfo := types.HostInfo{Hostname: "worker-1", IPAddr: "192.168.1.100", Platform: "linux"}
+	workerID, _ := r.RegisterWorker(hostInfo, types.WorkerCapacity{MaxVUs: 100})
+
+	if err := r.Heartbeat(workerID, &types.WorkerHealth{CPUPercent: 50, ActiveVUs: 10, ShedRate: 0.3}); err != nil {
+		t.Fatalf("Heartbeat failed: %v", err)
+	}
+	worker, _ := r.GetWorker(workerID)
+	if worker.EffectiveCapacity.MaxVUs != 70 {
+		t.Errorf("expected effective capacity of 70 VUs while shedding 30%%, got %d", worker.EffectiveCapacity.MaxVUs)
+	}
+
+	if err := r.Heartbeat(workerID, &types.WorkerHealth{CPUPercent: 50, ActiveVUs: 10}); err != nil {
+		t.Fatalf("Heartbeat failed: %v", err)
+	}
+	worker, _ = r.GetWorker(workerID)
+	if worker.EffectiveCapacity.MaxVUs != 100 {
+		t.Errorf("expected full capacity once shedding stops, got %d", worker.EffectiveCapacity.MaxVUs)
+	}
+}
+
 func TestHeartbeatNotFound(t *testing.T) {
 	r := NewRegistry()
 
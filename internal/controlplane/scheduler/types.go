@@ -20,6 +20,9 @@ type WorkerInfo struct {
 	RegisteredAt      int64                `json:"registered_at"`
 	LastHeartbeat     int64                `json:"last_heartbeat"`
 	Health            *types.WorkerHealth  `json:"health,omitempty"`
+	// Project restricts the worker to runs of one project. Workers without
+	// a project are shared by every project.
+	Project string `json:"project,omitempty"`
 }
 
 // Copy returns a deep copy of WorkerInfo.
@@ -35,14 +38,26 @@ func (w *WorkerInfo) Copy() *WorkerInfo {
 		Saturated:         w.Saturated,
 		RegisteredAt:      w.RegisteredAt,
 		LastHeartbeat:     w.LastHeartbeat,
+		Project:           w.Project,
 	}
 	if w.Health != nil {
 		healthCopy := *w.Health
 		copy.Health = &healthCopy
 	}
+	if w.HostInfo.Labels != nil {
+		copy.HostInfo.Labels = make(map[string]string, len(w.HostInfo.Labels))
+		for k, v := range w.HostInfo.Labels {
+			copy.HostInfo.Labels[k] = v
+		}
+	}
 	return copy
 }
 
+// ServesProject reports whether the worker may run runs of project.
+func (w *WorkerInfo) ServesProject(project string) bool {
+	return w.Project == "" || w.Project == project
+}
+
 // NowMs returns the current time in milliseconds.
 func NowMs() int64 {
 	return time.Now().UnixMilli()
@@ -81,6 +96,8 @@ type Assignment struct {
 	RunID     string    `json:"run_id"`
 	StageID   string    `json:"stage_id"`
 	VUIDRange VUIDRange `json:"vu_id_range"`
+	Group     string    `json:"group,omitempty"`  // generator group, empty without groups
+	Target    string    `json:"target,omitempty"` // target of a multi-target run, empty with one target
 }
 
 // Lease represents an assignment lease issued to a worker.
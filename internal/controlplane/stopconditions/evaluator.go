@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -26,9 +27,31 @@ type Condition struct {
 	Threshold      float64
 	WindowMs       int64
 	SustainWindows int
-	Scope          map[string]string
+	// Scope narrows the operations a windowed metric is computed over. It
+	// matches on the "operation", "tool_name" and "target" keys.
+	Scope map[string]string
+
+	// All and Any make the condition a composite that is breached when all
+	// or any of its terms are. Terms are evaluated over the condition's
+	// window and may be composites themselves.
+	All []Condition
+	Any []Condition
 }
 
+// Windowed metrics computed from the operations observed in a condition's
+// window, beyond error_rate and the latency percentiles.
+const (
+	// MetricStreamStallRate is the share of operations that failed because
+	// their response stream stalled.
+	MetricStreamStallRate = "stream_stall_rate"
+	// MetricConsecutiveErrors is the longest run of failed operations, in
+	// the order they were observed.
+	MetricConsecutiveErrors = "consecutive_errors"
+	// MetricComposite is the metric of conditions built from All or Any
+	// terms.
+	MetricComposite = "composite"
+)
+
 // StreamingConfig holds streaming-specific stop condition thresholds.
 type StreamingConfig struct {
 	StreamStallSeconds int     // Trigger if no events for X seconds
@@ -57,6 +80,22 @@ type Trigger struct {
 	FailedOps   int
 	LatencyP99  int
 	TimestampMs int64
+
+	// Terms holds each term of a composite condition; Observed is then the
+	// number of terms breached.
+	Terms []TermResult
+}
+
+// TermResult is the evaluation of one term of a composite condition.
+type TermResult struct {
+	Metric     string            `json:"metric"`
+	Comparator string            `json:"comparator,omitempty"`
+	Threshold  float64           `json:"threshold"`
+	Scope      map[string]string `json:"scope,omitempty"`
+	Observed   float64           `json:"observed"`
+	Breached   bool              `json:"breached"`
+	// Terms holds the terms of a nested composite.
+	Terms []TermResult `json:"terms,omitempty"`
 }
 
 // TelemetryProvider provides access to operation telemetry.
@@ -72,6 +111,15 @@ func (f TelemetryProviderFunc) GetOperations(runID string) ([]analysis.Operation
 	return f(runID)
 }
 
+// WindowedTelemetryProvider is implemented by providers that only hold a
+// run's most recent operations, so the slice they return can shrink as
+// older operations leave it.
+type WindowedTelemetryProvider interface {
+	// GetRecentOperations returns the run's recent operations, oldest
+	// first, and how many older operations precede them.
+	GetRecentOperations(runID string) (ops []analysis.OperationResult, older int, err error)
+}
+
 // Evaluator polls telemetry data and checks stop conditions.
 type Evaluator struct {
 	RunID           string
@@ -81,12 +129,18 @@ type Evaluator struct {
 	Telemetry       TelemetryProvider
 	Streaming       StreamingProvider
 	StreamingConfig *StreamingConfig
+	ServerMetrics   ServerMetricsProvider
 	OnTrigger       func(Trigger)
 
 	lastSeen      int
 	buffer        []timedOperation
 	sustainCounts map[string]int
 	maxWindowMs   int64
+
+	// trendWindows is the longest window of each latency trend metric;
+	// trendPoints holds that metric's samples over the window.
+	trendWindows map[string]int64
+	trendPoints  map[string][]trendPoint
 }
 
 type timedOperation struct {
@@ -97,14 +151,34 @@ type timedOperation struct {
 // NewEvaluator creates a new evaluator instance.
 func NewEvaluator(runID string, telemetry TelemetryProvider, conditions []Condition, pollInterval time.Duration) *Evaluator {
 	copied := make([]Condition, len(conditions))
-	copy(copied, conditions)
+	for i, cond := range conditions {
+		copied[i] = withTermWindows(cond)
+	}
 
+	// Trend conditions keep one point per evaluation instead of buffering
+	// every operation, and server gauges read agent metrics, so their
+	// windows do not count here.
 	maxWindow := int64(0)
-	for _, cond := range copied {
-		if cond.WindowMs > maxWindow {
+	trendWindows := make(map[string]int64)
+	var track func(cond Condition)
+	track = func(cond Condition) {
+		switch {
+		case cond.Metric == MetricComposite:
+			for _, term := range cond.terms() {
+				track(term)
+			}
+		case IsTrendMetric(cond.Metric):
+			if _, ok := trendPercentiles[cond.Metric]; ok && cond.WindowMs > trendWindows[cond.Metric] {
+				trendWindows[cond.Metric] = cond.WindowMs
+			}
+		case IsServerGauge(cond.Metric):
+		case cond.WindowMs > maxWindow:
 			maxWindow = cond.WindowMs
 		}
 	}
+	for _, cond := range copied {
+		track(cond)
+	}
 
 	return &Evaluator{
 		RunID:         runID,
@@ -113,6 +187,8 @@ func NewEvaluator(runID string, telemetry TelemetryProvider, conditions []Condit
 		Telemetry:     telemetry,
 		sustainCounts: make(map[string]int),
 		maxWindowMs:   maxWindow,
+		trendWindows:  trendWindows,
+		trendPoints:   make(map[string][]trendPoint),
 	}
 }
 
@@ -145,6 +221,25 @@ func (e *Evaluator) Run(ctxDone <-chan struct{}) {
 	}
 }
 
+// withoutWarmup returns ops without the operations run during a stage's
+// warmup, which stop conditions do not judge. ops is returned as is if it
+// holds none.
+func withoutWarmup(ops []analysis.OperationResult) []analysis.OperationResult {
+	for i, op := range ops {
+		if !op.Warmup {
+			continue
+		}
+		measured := append(make([]analysis.OperationResult, 0, len(ops)-1), ops[:i]...)
+		for _, op := range ops[i+1:] {
+			if !op.Warmup {
+				measured = append(measured, op)
+			}
+		}
+		return measured
+	}
+	return ops
+}
+
 // Evaluate runs a single evaluation pass and returns a trigger if breached.
 func (e *Evaluator) Evaluate(nowMs int64) (Trigger, error) {
 	if streamTrigger := e.evaluateStreamingConditions(nowMs); streamTrigger != nil {
@@ -155,25 +250,30 @@ func (e *Evaluator) Evaluate(nowMs int64) (Trigger, error) {
 		return Trigger{}, fmt.Errorf("telemetry provider not configured")
 	}
 
-	operations, err := e.Telemetry.GetOperations(e.RunID)
+	operations, older, err := e.operations()
 	if err != nil {
 		return Trigger{}, err
 	}
 
-	if len(operations) < e.lastSeen {
+	// lastSeen counts operations that have since left a windowed provider.
+	total := older + len(operations)
+	if total < e.lastSeen {
 		e.lastSeen = 0
 		e.buffer = nil
 		e.sustainCounts = make(map[string]int)
+		e.trendPoints = make(map[string][]trendPoint)
 	}
 
-	if e.lastSeen < len(operations) {
+	if e.lastSeen < total {
+		fresh := withoutWarmup(operations[max(e.lastSeen-older, 0):])
+		e.recordLatencyTrends(nowMs, fresh)
 		// Only buffer operations if we have time-windowed conditions
 		if e.maxWindowMs > 0 {
-			for _, op := range operations[e.lastSeen:] {
+			for _, op := range fresh {
 				e.buffer = append(e.buffer, timedOperation{op: op, observedMs: nowMs})
 			}
 		}
-		e.lastSeen = len(operations)
+		e.lastSeen = total
 	}
 
 	if e.maxWindowMs > 0 && len(e.buffer) > 0 {
@@ -188,59 +288,151 @@ func (e *Evaluator) Evaluate(nowMs int64) (Trigger, error) {
 	}
 
 	for i, cond := range e.Conditions {
+		key := e.conditionKey(cond, i)
 		if cond.WindowMs <= 0 {
-			e.sustainCounts[e.conditionKey(cond, i)] = 0
-			continue
-		}
-
-		totalOps, failedOps, latencies := e.windowStats(nowMs, cond.WindowMs)
-		if totalOps == 0 {
-			e.sustainCounts[e.conditionKey(cond, i)] = 0
-			if latencies != nil {
-				latencyPool.Put(latencies[:0])
-			}
+			e.sustainCounts[key] = 0
 			continue
 		}
 
-		observed, latencyP99 := evaluateMetric(cond.Metric, totalOps, failedOps, latencies)
-		if latencies != nil {
-			latencyPool.Put(latencies[:0])
-		}
-		if !compare(observed, cond.Comparator, cond.Threshold) {
-			e.sustainCounts[e.conditionKey(cond, i)] = 0
+		trigger, breached := e.check(cond, nowMs)
+		if !breached {
+			e.sustainCounts[key] = 0
 			continue
 		}
-
-		key := e.conditionKey(cond, i)
-		sustain := cond.SustainWindows
-		if sustain <= 0 {
-			sustain = 1
-		}
 		e.sustainCounts[key]++
-		if e.sustainCounts[key] < sustain {
+		if e.sustainCounts[key] < max(cond.SustainWindows, 1) {
 			continue
 		}
 
-		trigger := Trigger{
-			Condition:   cond,
-			Observed:    observed,
-			WindowMs:    cond.WindowMs,
-			TotalOps:    totalOps,
-			FailedOps:   failedOps,
-			LatencyP99:  latencyP99,
-			TimestampMs: nowMs,
+		reason := "metric_threshold_exceeded"
+		switch {
+		case cond.Metric == MetricComposite:
+			reason = "composite_condition_met"
+		case IsTrendMetric(cond.Metric):
+			reason = "trend_threshold_exceeded"
 		}
-
 		if l := events.GetGlobalEventLogger(); l != nil {
-			l.LogStopCondition(e.StageID, cond.Metric, observed, cond.Threshold, "metric_threshold_exceeded")
+			l.LogStopCondition(e.StageID, cond.Metric, trigger.Observed, cond.Threshold, reason)
 		}
-
 		return trigger, nil
 	}
 
 	return Trigger{}, nil
 }
 
+// check evaluates cond once and reports whether it is breached.
+func (e *Evaluator) check(cond Condition, nowMs int64) (Trigger, bool) {
+	if cond.Metric == MetricComposite {
+		return e.checkComposite(cond, nowMs)
+	}
+	trigger, ok := e.observe(cond, nowMs)
+	return trigger, ok && compareValue(trigger.Observed, cond.Comparator, cond.Threshold)
+}
+
+// checkComposite evaluates every term of cond, so the trigger shows all of
+// them, and reports whether all or any of them are breached.
+func (e *Evaluator) checkComposite(cond Condition, nowMs int64) (Trigger, bool) {
+	trigger := Trigger{Condition: cond, WindowMs: cond.WindowMs, TimestampMs: nowMs}
+	terms := cond.terms()
+	for _, term := range terms {
+		t, breached := e.check(term, nowMs)
+		trigger.Terms = append(trigger.Terms, TermResult{
+			Metric:     term.Metric,
+			Comparator: term.Comparator,
+			Threshold:  term.Threshold,
+			Scope:      term.Scope,
+			Observed:   t.Observed,
+			Breached:   breached,
+			Terms:      t.Terms,
+		})
+		if breached {
+			trigger.Observed++
+		}
+		trigger.TotalOps = max(trigger.TotalOps, t.TotalOps)
+		trigger.FailedOps = max(trigger.FailedOps, t.FailedOps)
+	}
+	if len(terms) == 0 {
+		return trigger, false
+	}
+	if len(cond.All) > 0 {
+		return trigger, int(trigger.Observed) == len(terms)
+	}
+	return trigger, trigger.Observed > 0
+}
+
+// observe computes cond's metric over its window. ok is false if there is
+// nothing to judge yet or the metric is unknown.
+func (e *Evaluator) observe(cond Condition, nowMs int64) (Trigger, bool) {
+	trigger := Trigger{Condition: cond, WindowMs: cond.WindowMs, TimestampMs: nowMs}
+	var ok bool
+	switch {
+	case IsTrendMetric(cond.Metric):
+		trigger.Observed, ok = e.evaluateTrend(cond, nowMs)
+		return trigger, ok
+	case IsServerGauge(cond.Metric):
+		trigger.Observed, ok = e.serverGauge(cond.Metric, nowMs-cond.WindowMs, nowMs)
+		return trigger, ok
+	}
+
+	stats := e.windowStats(nowMs, cond.WindowMs, cond.Scope)
+	defer stats.release()
+	if stats.total == 0 {
+		return trigger, false
+	}
+	observed, latency := evaluateMetric(cond.Metric, stats)
+	if observed < 0 {
+		return trigger, false
+	}
+	trigger.Observed = observed
+	trigger.TotalOps = stats.total
+	trigger.FailedOps = stats.failed
+	trigger.LatencyP99 = latency
+	return trigger, true
+}
+
+// terms returns the terms of a composite condition.
+func (c Condition) terms() []Condition {
+	if len(c.All) > 0 {
+		return c.All
+	}
+	return c.Any
+}
+
+// withTermWindows marks cond as a composite if it has terms, and gives its
+// terms its window.
+func withTermWindows(cond Condition) Condition {
+	if len(cond.All) == 0 && len(cond.Any) == 0 {
+		return cond
+	}
+	if cond.Metric == "" {
+		cond.Metric = MetricComposite
+	}
+	inherit := func(terms []Condition) []Condition {
+		if len(terms) == 0 {
+			return nil
+		}
+		out := make([]Condition, len(terms))
+		for i, term := range terms {
+			term.WindowMs = cond.WindowMs
+			out[i] = withTermWindows(term)
+		}
+		return out
+	}
+	cond.All = inherit(cond.All)
+	cond.Any = inherit(cond.Any)
+	return cond
+}
+
+// operations fetches the run's operations and how many older ones the
+// provider no longer holds.
+func (e *Evaluator) operations() ([]analysis.OperationResult, int, error) {
+	if windowed, ok := e.Telemetry.(WindowedTelemetryProvider); ok {
+		return windowed.GetRecentOperations(e.RunID)
+	}
+	operations, err := e.Telemetry.GetOperations(e.RunID)
+	return operations, 0, err
+}
+
 func (e *Evaluator) conditionKey(cond Condition, index int) string {
 	if cond.ID != "" {
 		return cond.ID
@@ -248,14 +440,30 @@ func (e *Evaluator) conditionKey(cond Condition, index int) string {
 	return fmt.Sprintf("%s-%d", cond.Metric, index)
 }
 
-func (e *Evaluator) windowStats(nowMs int64, windowMs int64) (int, int, []int) {
+// windowStats summarizes the buffered operations of a window.
+type windowStats struct {
+	total     int
+	failed    int
+	stalled   int // failed with a stream stall
+	maxStreak int // longest run of failures
+	latencies []int
+}
+
+// release returns the latency slice to latencyPool.
+func (s windowStats) release() {
+	if s.latencies != nil {
+		latencyPool.Put(s.latencies[:0])
+	}
+}
+
+func (e *Evaluator) windowStats(nowMs int64, windowMs int64, scope map[string]string) windowStats {
+	var stats windowStats
 	if len(e.buffer) == 0 {
-		return 0, 0, nil
+		return stats
 	}
 
 	cutoff := nowMs - windowMs
-	totalOps := 0
-	failedOps := 0
+	streak := 0
 	latencies := latencyPool.Get().([]int)
 	if cap(latencies) < len(e.buffer) {
 		latencies = make([]int, 0, len(e.buffer))
@@ -263,33 +471,68 @@ func (e *Evaluator) windowStats(nowMs int64, windowMs int64) (int, int, []int) {
 		latencies = latencies[:0]
 	}
 	for _, entry := range e.buffer {
-		if entry.observedMs < cutoff {
+		if entry.observedMs < cutoff || !inScope(entry.op, scope) {
 			continue
 		}
-		totalOps++
-		if !entry.op.OK {
-			failedOps++
+		stats.total++
+		if entry.op.OK {
+			streak = 0
+		} else {
+			stats.failed++
+			streak++
+			stats.maxStreak = max(stats.maxStreak, streak)
+			if entry.op.ErrorType == streamStallErrorType {
+				stats.stalled++
+			}
 		}
 		latencies = append(latencies, entry.op.LatencyMs)
 	}
-	return totalOps, failedOps, latencies
+	stats.latencies = latencies
+	return stats
+}
+
+// streamStallErrorType is the error type of operations whose response stream
+// stalled.
+const streamStallErrorType = "stream_stall"
+
+// inScope reports whether op matches every key of scope. Operation names
+// match in either the tools/call or the tools_call form.
+func inScope(op analysis.OperationResult, scope map[string]string) bool {
+	if tool, ok := scope["tool_name"]; ok && op.ToolName != tool {
+		return false
+	}
+	if target, ok := scope["target"]; ok && op.Target != target {
+		return false
+	}
+	if operation, ok := scope["operation"]; ok &&
+		strings.ReplaceAll(op.Operation, "_", "/") != strings.ReplaceAll(operation, "_", "/") {
+		return false
+	}
+	return true
 }
 
-func evaluateMetric(metric string, totalOps, failedOps int, latencies []int) (float64, int) {
+func evaluateMetric(metric string, stats windowStats) (float64, int) {
 	switch metric {
 	case "error_rate":
-		if totalOps == 0 {
+		if stats.total == 0 {
 			return 0, 0
 		}
-		return float64(failedOps) / float64(totalOps), 0
+		return float64(stats.failed) / float64(stats.total), 0
+	case MetricStreamStallRate:
+		if stats.total == 0 {
+			return 0, 0
+		}
+		return float64(stats.stalled) / float64(stats.total), 0
+	case MetricConsecutiveErrors:
+		return float64(stats.maxStreak), 0
 	case "latency_p50_ms":
-		p50 := percentile(latencies, 50)
+		p50 := percentile(stats.latencies, 50)
 		return float64(p50), p50
 	case "latency_p95_ms":
-		p95 := percentile(latencies, 95)
+		p95 := percentile(stats.latencies, 95)
 		return float64(p95), p95
 	case "latency_p99_ms":
-		p99 := percentile(latencies, 99)
+		p99 := percentile(stats.latencies, 99)
 		return float64(p99), p99
 	default:
 		// Unknown metric - return -1 to indicate invalid metric
@@ -298,11 +541,9 @@ func evaluateMetric(metric string, totalOps, failedOps int, latencies []int) (fl
 	}
 }
 
-func compare(observed float64, comparator string, threshold float64) bool {
-	// Invalid metric value - never trigger
-	if observed < 0 {
-		return false
-	}
+// compareValue compares an observed value with a threshold. Values may be
+// negative, as slopes are; invalid metrics are caught before comparing.
+func compareValue(observed float64, comparator string, threshold float64) bool {
 	switch comparator {
 	case ">":
 		return observed > threshold
@@ -89,6 +89,36 @@ func TestEvaluatorLatencyP99Trigger(t *testing.T) {
 	}
 }
 
+func TestEvaluatorIgnoresWarmup(t *testing.T) {
+	telemetry := &fakeTelemetry{}
+	cond := Condition{
+		ID:             "err_rate",
+		Metric:         "error_rate",
+		Comparator:     ">",
+		Threshold:      0.5,
+		WindowMs:       1000,
+		SustainWindows: 1,
+	}
+
+	evaluator := NewEvaluator("run_0000000000000003", telemetry, []Condition{cond}, time.Second)
+
+	telemetry.ops = []analysis.OperationResult{
+		{Operation: "ping", OK: false, LatencyMs: 900, Warmup: true},
+		{Operation: "ping", OK: false, LatencyMs: 800, Warmup: true},
+		{Operation: "ping", OK: false, LatencyMs: 700, Warmup: true},
+		{Operation: "ping", OK: true, LatencyMs: 10},
+		{Operation: "ping", OK: true, LatencyMs: 12},
+	}
+
+	trigger, err := evaluator.Evaluate(1000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if trigger.Condition.Metric != "" {
+		t.Fatalf("expected warmup failures to be ignored, got %+v", trigger)
+	}
+}
+
 func TestEvaluatorSustainWindows(t *testing.T) {
 	telemetry := &fakeTelemetry{}
 	cond := Condition{
@@ -123,3 +153,166 @@ func TestEvaluatorSustainWindows(t *testing.T) {
 		t.Fatalf("expected trigger after sustain windows, got %+v", trigger)
 	}
 }
+
+type windowedTelemetry struct {
+	ops   []analysis.OperationResult
+	older int
+}
+
+func (w *windowedTelemetry) GetOperations(runID string) ([]analysis.OperationResult, error) {
+	return w.ops, nil
+}
+
+func (w *windowedTelemetry) GetRecentOperations(runID string) ([]analysis.OperationResult, int, error) {
+	return w.ops, w.older, nil
+}
+
+func TestEvaluatorWindowedTelemetryKeepsState(t *testing.T) {
+	telemetry := &windowedTelemetry{}
+	cond := Condition{
+		ID:             "err_rate",
+		Metric:         "error_rate",
+		Comparator:     ">=",
+		Threshold:      0.5,
+		WindowMs:       1000,
+		SustainWindows: 2,
+	}
+
+	evaluator := NewEvaluator("run_0000000000000004", telemetry, []Condition{cond}, time.Second)
+
+	telemetry.ops = []analysis.OperationResult{
+		{Operation: "ping", OK: true, LatencyMs: 10},
+		{Operation: "ping", OK: false, LatencyMs: 12},
+	}
+
+	trigger, err := evaluator.Evaluate(3000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if trigger.Condition.Metric != "" {
+		t.Fatalf("expected no trigger on first breach, got %+v", trigger)
+	}
+
+	// Both earlier operations leave the window as a new failure arrives.
+	telemetry.ops = []analysis.OperationResult{{Operation: "ping", OK: false, LatencyMs: 14}}
+	telemetry.older = 2
+
+	trigger, err = evaluator.Evaluate(3100)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if trigger.Condition.Metric != "error_rate" {
+		t.Fatalf("expected trigger after sustain windows, got %+v", trigger)
+	}
+	if trigger.Observed < 0.66 || trigger.Observed > 0.67 {
+		t.Fatalf("expected error rate over all three operations, got %f", trigger.Observed)
+	}
+}
+
+func TestEvaluatorScopedLatency(t *testing.T) {
+	telemetry := &fakeTelemetry{ops: []analysis.OperationResult{
+		{Operation: "tools/call", ToolName: "search", OK: true, LatencyMs: 900},
+		{Operation: "tools/call", ToolName: "search", OK: true, LatencyMs: 800},
+		{Operation: "tools/call", ToolName: "echo", OK: true, LatencyMs: 5},
+		{Operation: "tools/list", OK: true, LatencyMs: 700},
+	}}
+	conditions := []Condition{
+		{ID: "echo_p95", Metric: "latency_p95_ms", Comparator: ">", Threshold: 100, WindowMs: 1000, SustainWindows: 1, Scope: map[string]string{"tool_name": "echo"}},
+		{ID: "search_p99", Metric: "latency_p99_ms", Comparator: ">", Threshold: 850, WindowMs: 1000, SustainWindows: 1, Scope: map[string]string{"operation": "tools_call", "tool_name": "search"}},
+	}
+	evaluator := NewEvaluator("run_0000000000000005", telemetry, conditions, time.Second)
+
+	trigger, err := evaluator.Evaluate(1000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if trigger.Condition.ID != "search_p99" {
+		t.Fatalf("expected the search p99 trigger, got %+v", trigger)
+	}
+	if trigger.Observed != 900 || trigger.TotalOps != 2 {
+		t.Fatalf("expected p99 900ms over the two search calls, got %+v", trigger)
+	}
+}
+
+func TestEvaluatorScopedTarget(t *testing.T) {
+	telemetry := &fakeTelemetry{ops: []analysis.OperationResult{
+		{Operation: "tools/call", Target: "east", OK: true},
+		{Operation: "tools/call", Target: "east", OK: true},
+		{Operation: "tools/call", Target: "west", OK: false, ErrorType: "timeout"},
+		{Operation: "tools/call", Target: "west", OK: true},
+	}}
+	conditions := []Condition{
+		{ID: "east_errors", Metric: "error_rate", Comparator: ">", Threshold: 0.25, WindowMs: 1000, SustainWindows: 1, Scope: map[string]string{"target": "east"}},
+		{ID: "west_errors", Metric: "error_rate", Comparator: ">", Threshold: 0.25, WindowMs: 1000, SustainWindows: 1, Scope: map[string]string{"target": "west"}},
+	}
+	evaluator := NewEvaluator("run_0000000000000007", telemetry, conditions, time.Second)
+
+	trigger, err := evaluator.Evaluate(1000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if trigger.Condition.ID != "west_errors" || trigger.Observed != 0.5 || trigger.TotalOps != 2 {
+		t.Fatalf("expected the west error rate of 0.5 over two operations, got %+v", trigger)
+	}
+}
+
+func TestEvaluatorStreamStallRateAndStreak(t *testing.T) {
+	telemetry := &fakeTelemetry{ops: []analysis.OperationResult{
+		{Operation: "tools/call", OK: false, ErrorType: "stream_stall"},
+		{Operation: "tools/call", OK: false, ErrorType: "timeout"},
+		{Operation: "tools/call", OK: false, ErrorType: "timeout"},
+		{Operation: "tools/call", OK: true},
+		{Operation: "tools/call", OK: false, ErrorType: "stream_stall"},
+	}}
+
+	stall := Condition{ID: "stalls", Metric: MetricStreamStallRate, Comparator: ">=", Threshold: 0.4, WindowMs: 1000, SustainWindows: 1}
+	trigger, err := NewEvaluator("run_0000000000000006", telemetry, []Condition{stall}, time.Second).Evaluate(1000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if trigger.Condition.Metric != MetricStreamStallRate || trigger.Observed != 0.4 {
+		t.Fatalf("expected a stall rate of 0.4, got %+v", trigger)
+	}
+
+	streak := Condition{ID: "streak", Metric: MetricConsecutiveErrors, Comparator: ">=", Threshold: 4, WindowMs: 1000, SustainWindows: 1}
+	evaluator := NewEvaluator("run_0000000000000006", telemetry, []Condition{streak}, time.Second)
+	if trigger, _ := evaluator.Evaluate(1000); trigger.Condition.Metric != "" {
+		t.Fatalf("expected no trigger for a streak of three, got %+v", trigger)
+	}
+	telemetry.ops = append(telemetry.ops, analysis.OperationResult{Operation: "tools/call", OK: false},
+		analysis.OperationResult{Operation: "tools/call", OK: false}, analysis.OperationResult{Operation: "tools/call", OK: false})
+	trigger, _ = evaluator.Evaluate(1100)
+	if trigger.Condition.Metric != MetricConsecutiveErrors || trigger.Observed != 4 {
+		t.Fatalf("expected a streak of four, got %+v", trigger)
+	}
+}
+
+func TestEvaluatorCompositeConditions(t *testing.T) {
+	telemetry := &fakeTelemetry{ops: []analysis.OperationResult{
+		{Operation: "tools/call", OK: false, LatencyMs: 400},
+		{Operation: "tools/call", OK: true, LatencyMs: 50},
+	}}
+	errorRate := Condition{Metric: "error_rate", Comparator: ">", Threshold: 0.2}
+	slow := Condition{Metric: "latency_p99_ms", Comparator: ">", Threshold: 1000}
+
+	all := Condition{ID: "both", WindowMs: 1000, SustainWindows: 1, All: []Condition{errorRate, slow}}
+	trigger, err := NewEvaluator("run_0000000000000007", telemetry, []Condition{all}, time.Second).Evaluate(1000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if trigger.Condition.Metric != "" {
+		t.Fatalf("expected no trigger with one of two terms breached, got %+v", trigger)
+	}
+
+	anyOf := Condition{ID: "either", WindowMs: 1000, SustainWindows: 1, Any: []Condition{slow, errorRate}}
+	trigger, err = NewEvaluator("run_0000000000000007", telemetry, []Condition{anyOf}, time.Second).Evaluate(1000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if trigger.Condition.Metric != MetricComposite || trigger.Observed != 1 {
+		t.Fatalf("expected the composite to trigger on one term, got %+v", trigger)
+	}
+	if len(trigger.Terms) != 2 || trigger.Terms[0].Breached || !trigger.Terms[1].Breached || trigger.Terms[1].Observed != 0.5 {
+		t.Fatalf("expected both terms with the error rate breached, got %+v", trigger.Terms)
+	}
+}
@@ -0,0 +1,27 @@
+package stopconditions
+
+import "github.com/bc-dunia/mcpdrill/internal/analysis"
+
+// Judge evaluates conditions once over ops, which are all taken to fall in a
+// window of windowMs ending at nowMs, and returns the first condition
+// breached. Sustain windows do not apply, and trend metrics never trip since
+// a single pass has no trend. server may be nil if the run has no paired
+// agent.
+func Judge(runID string, conditions []Condition, ops []analysis.OperationResult, server ServerMetricsProvider, windowMs, nowMs int64) (Trigger, bool) {
+	windowed := make([]Condition, len(conditions))
+	for i, cond := range conditions {
+		cond.WindowMs = windowMs
+		windowed[i] = cond
+	}
+	e := NewEvaluator(runID, nil, windowed, 0)
+	e.ServerMetrics = server
+	for _, op := range withoutWarmup(ops) {
+		e.buffer = append(e.buffer, timedOperation{op: op, observedMs: nowMs})
+	}
+	for _, cond := range e.Conditions {
+		if trigger, breached := e.check(cond, nowMs); breached {
+			return trigger, true
+		}
+	}
+	return Trigger{}, false
+}
@@ -0,0 +1,32 @@
+package stopconditions
+
+import (
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+)
+
+func TestJudge(t *testing.T) {
+	ops := []analysis.OperationResult{
+		{Operation: "tools/call", LatencyMs: 100, OK: true},
+		{Operation: "tools/call", LatencyMs: 100, OK: false},
+		{Operation: "tools/call", LatencyMs: 100, OK: true},
+		{Operation: "tools/call", LatencyMs: 100, OK: true},
+		// Warmup operations are not judged.
+		{Operation: "tools/call", LatencyMs: 100, OK: false, Warmup: true},
+	}
+	guardrails := []Condition{
+		{ID: "p95", Metric: "latency_p95_ms", Comparator: ">", Threshold: 500},
+		{ID: "errors", Metric: "error_rate", Comparator: ">", Threshold: 0.2},
+	}
+
+	trigger, tripped := Judge("run_0000000000000001", guardrails, ops, nil, 10000, 50000)
+	if !tripped || trigger.Condition.ID != "errors" || trigger.Observed != 0.25 {
+		t.Fatalf("expected the error rate of 0.25 to trip, got %+v tripped=%v", trigger, tripped)
+	}
+
+	guardrails[1].Threshold = 0.3
+	if trigger, tripped = Judge("run_0000000000000001", guardrails, ops, nil, 10000, 50000); tripped {
+		t.Errorf("expected the probe to hold, got %+v", trigger)
+	}
+}
@@ -0,0 +1,54 @@
+package stopconditions
+
+// Server gauges are read from the agent metrics of the server paired with a
+// run, over a condition's window.
+const (
+	// MetricServerRSS is the highest process RSS, in MiB, any agent-monitored
+	// node reported in the window.
+	MetricServerRSS = "server_rss_mb"
+	// MetricServerCPU is the highest mean process CPU percent of any
+	// agent-monitored node over the window.
+	MetricServerCPU = "server_cpu_percent"
+)
+
+// IsServerGauge reports whether metric is read from server agent metrics.
+func IsServerGauge(metric string) bool {
+	return metric == MetricServerRSS || metric == MetricServerCPU
+}
+
+// serverGauge returns metric over the nodes' samples between fromMs and toMs.
+// ok is false if no node reported the monitored process.
+func (e *Evaluator) serverGauge(metric string, fromMs, toMs int64) (float64, bool) {
+	if e.ServerMetrics == nil {
+		return 0, false
+	}
+	var (
+		highest float64
+		found   bool
+	)
+	for _, node := range e.ServerMetrics.NodeSeries(e.RunID, fromMs, toMs) {
+		var sum, peak float64
+		n := 0
+		for _, s := range node.Samples {
+			// Samples without an RSS did not see the server process.
+			if s.ProcessRSS == 0 {
+				continue
+			}
+			n++
+			sum += s.ProcessCPU
+			peak = max(peak, float64(s.ProcessRSS)/(1024*1024))
+		}
+		if n == 0 {
+			continue
+		}
+		value := peak
+		if metric == MetricServerCPU {
+			value = sum / float64(n)
+		}
+		if !found || value > highest {
+			highest = value
+			found = true
+		}
+	}
+	return highest, found
+}
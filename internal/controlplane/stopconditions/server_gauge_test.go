@@ -0,0 +1,54 @@
+package stopconditions
+
+import (
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+)
+
+func TestEvaluatorServerGauges(t *testing.T) {
+	const mib = 1024 * 1024
+	nodes := []analysis.NodeSeries{
+		{AgentID: "agent-a", Samples: []analysis.NodeSample{
+			{TimestampMs: 1000, ProcessRSS: 300 * mib, ProcessCPU: 20},
+			{TimestampMs: 2000, ProcessRSS: 500 * mib, ProcessCPU: 60},
+		}},
+		{AgentID: "agent-b", Samples: []analysis.NodeSample{
+			{TimestampMs: 1000, ProcessRSS: 100 * mib, ProcessCPU: 50},
+			{TimestampMs: 2000, ProcessRSS: 120 * mib, ProcessCPU: 50},
+			// No process seen: its CPU does not count.
+			{TimestampMs: 3000, ProcessCPU: 0},
+		}},
+	}
+	conditions := []Condition{
+		{ID: "cpu", Metric: MetricServerCPU, Comparator: ">", Threshold: 45, WindowMs: 60000, SustainWindows: 1},
+		{ID: "rss", Metric: MetricServerRSS, Comparator: ">", Threshold: 400, WindowMs: 60000, SustainWindows: 1},
+	}
+	evaluator := NewEvaluator("run_0000000000000001", &fakeTelemetry{}, conditions, 0)
+	if evaluator.maxWindowMs != 0 {
+		t.Fatalf("server gauges must not buffer operations, got maxWindowMs %d", evaluator.maxWindowMs)
+	}
+
+	trigger, err := evaluator.Evaluate(60000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if trigger.Condition.Metric != "" {
+		t.Fatalf("expected no trigger without server metrics, got %+v", trigger)
+	}
+
+	evaluator.ServerMetrics = ServerMetricsProviderFunc(func(string, int64, int64) []analysis.NodeSeries { return nodes })
+	trigger, err = evaluator.Evaluate(60000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if trigger.Condition.Metric != MetricServerCPU || trigger.Observed != 50 {
+		t.Fatalf("expected the highest mean CPU of 50%%, got %+v", trigger)
+	}
+
+	evaluator.Conditions = conditions[1:]
+	trigger, _ = evaluator.Evaluate(60000)
+	if trigger.Condition.Metric != MetricServerRSS || trigger.Observed != 500 {
+		t.Fatalf("expected the 500 MiB peak, got %+v", trigger)
+	}
+}
@@ -0,0 +1,153 @@
+package stopconditions
+
+import (
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+)
+
+// Trend metrics are slopes fitted over a condition's window rather than
+// values of the window itself. They catch slow degradation, such as a memory
+// leak or latency creeping up, during long soak stages.
+const (
+	MetricLatencyP50Slope = "latency_p50_slope_ms_per_hour"
+	MetricLatencyP95Slope = "latency_p95_slope_ms_per_hour"
+	MetricLatencyP99Slope = "latency_p99_slope_ms_per_hour"
+	MetricServerRSSSlope  = "server_rss_slope_mb_per_hour"
+)
+
+// trendPercentiles maps latency trend metrics to the percentile they track.
+var trendPercentiles = map[string]float64{
+	MetricLatencyP50Slope: 50,
+	MetricLatencyP95Slope: 95,
+	MetricLatencyP99Slope: 99,
+}
+
+// IsTrendMetric reports whether metric is a slope metric.
+func IsTrendMetric(metric string) bool {
+	_, ok := trendPercentiles[metric]
+	return ok || metric == MetricServerRSSSlope
+}
+
+// minTrendPoints is the fewest samples a slope is fitted to. A trend must
+// also cover at least half of the condition's window, so a short burst at the
+// start of a stage cannot trigger it.
+const minTrendPoints = 5
+
+const msPerHour = 3_600_000
+
+// ServerMetricsProvider provides the agent metrics of the server paired with
+// a run.
+type ServerMetricsProvider interface {
+	NodeSeries(runID string, fromMs, toMs int64) []analysis.NodeSeries
+}
+
+// ServerMetricsProviderFunc adapts a function to ServerMetricsProvider.
+type ServerMetricsProviderFunc func(runID string, fromMs, toMs int64) []analysis.NodeSeries
+
+// NodeSeries returns the agent metrics of a run's paired server.
+func (f ServerMetricsProviderFunc) NodeSeries(runID string, fromMs, toMs int64) []analysis.NodeSeries {
+	return f(runID, fromMs, toMs)
+}
+
+type trendPoint struct {
+	ms    int64
+	value float64
+}
+
+// recordLatencyTrends adds one point per tracked percentile, computed over
+// the operations observed since the previous evaluation.
+func (e *Evaluator) recordLatencyTrends(nowMs int64, ops []analysis.OperationResult) {
+	if len(e.trendWindows) == 0 || len(ops) == 0 {
+		return
+	}
+	latencies := make([]int, len(ops))
+	for i, op := range ops {
+		latencies[i] = op.LatencyMs
+	}
+	for metric, windowMs := range e.trendWindows {
+		p, ok := trendPercentiles[metric]
+		if !ok {
+			continue
+		}
+		points := append(e.trendPoints[metric], trendPoint{ms: nowMs, value: float64(percentile(latencies, p))})
+		cutoff := nowMs - windowMs
+		idx := 0
+		for idx < len(points) && points[idx].ms < cutoff {
+			idx++
+		}
+		e.trendPoints[metric] = points[idx:]
+	}
+}
+
+// evaluateTrend returns the slope of cond's metric per hour over its window.
+// ok is false until enough data has been collected.
+func (e *Evaluator) evaluateTrend(cond Condition, nowMs int64) (slope float64, ok bool) {
+	cutoff := nowMs - cond.WindowMs
+	if cond.Metric == MetricServerRSSSlope {
+		return e.serverRSSSlope(cutoff, nowMs, cond.WindowMs)
+	}
+
+	var points []trendPoint
+	for _, pt := range e.trendPoints[cond.Metric] {
+		if pt.ms >= cutoff {
+			points = append(points, pt)
+		}
+	}
+	perMs, ok := fitTrend(points, cond.WindowMs)
+	return perMs * msPerHour, ok
+}
+
+// serverRSSSlope fits the process RSS of each agent-monitored node and
+// returns the steepest growth in MiB per hour.
+func (e *Evaluator) serverRSSSlope(fromMs, toMs, windowMs int64) (float64, bool) {
+	if e.ServerMetrics == nil {
+		return 0, false
+	}
+	var (
+		maxSlope float64
+		found    bool
+	)
+	for _, node := range e.ServerMetrics.NodeSeries(e.RunID, fromMs, toMs) {
+		points := make([]trendPoint, 0, len(node.Samples))
+		for _, s := range node.Samples {
+			if s.ProcessRSS > 0 {
+				points = append(points, trendPoint{ms: s.TimestampMs, value: float64(s.ProcessRSS) / (1024 * 1024)})
+			}
+		}
+		perMs, ok := fitTrend(points, windowMs)
+		if !ok {
+			continue
+		}
+		if slope := perMs * msPerHour; !found || slope > maxSlope {
+			maxSlope = slope
+			found = true
+		}
+	}
+	return maxSlope, found
+}
+
+// fitTrend returns the least-squares slope of points per millisecond.
+func fitTrend(points []trendPoint, windowMs int64) (float64, bool) {
+	if len(points) < minTrendPoints {
+		return 0, false
+	}
+	if span := points[len(points)-1].ms - points[0].ms; span <= 0 || span*2 < windowMs {
+		return 0, false
+	}
+
+	// Center x on the first point to keep the sums small.
+	origin := points[0].ms
+	var sumX, sumY, sumXY, sumXX float64
+	for _, pt := range points {
+		x := float64(pt.ms - origin)
+		sumX += x
+		sumY += pt.value
+		sumXY += x * pt.value
+		sumXX += x * x
+	}
+	n := float64(len(points))
+	denom := n*sumXX - sumX*sumX
+	if denom == 0 {
+		return 0, false
+	}
+	return (n*sumXY - sumX*sumY) / denom, true
+}
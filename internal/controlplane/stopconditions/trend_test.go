@@ -0,0 +1,138 @@
+package stopconditions
+
+import (
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+)
+
+func TestEvaluatorLatencySlopeTrigger(t *testing.T) {
+	telemetry := &fakeTelemetry{}
+	cond := Condition{
+		ID:             "p99_creep",
+		Metric:         MetricLatencyP99Slope,
+		Comparator:     ">",
+		Threshold:      100,
+		WindowMs:       600000,
+		SustainWindows: 1,
+	}
+	evaluator := NewEvaluator("run_0000000000000001", telemetry, []Condition{cond}, 0)
+	if evaluator.maxWindowMs != 0 {
+		t.Fatalf("trend conditions must not buffer operations, got maxWindowMs %d", evaluator.maxWindowMs)
+	}
+
+	// Latency grows by 10ms per minute, i.e. 600ms per hour.
+	for minute := int64(0); minute <= 5; minute++ {
+		for i := 0; i < 10; i++ {
+			telemetry.ops = append(telemetry.ops, analysis.OperationResult{Operation: "ping", OK: true, LatencyMs: int(100 + minute*10)})
+		}
+		trigger, err := evaluator.Evaluate(minute * 60000)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if minute < 5 && trigger.Condition.Metric != "" {
+			t.Fatalf("minute %d: expected no trigger before the trend covers half the window, got %+v", minute, trigger)
+		}
+		if minute == 5 {
+			if trigger.Condition.Metric != MetricLatencyP99Slope {
+				t.Fatalf("expected slope trigger, got %+v", trigger)
+			}
+			if trigger.Observed < 599 || trigger.Observed > 601 {
+				t.Fatalf("expected slope of 600ms/hour, got %f", trigger.Observed)
+			}
+		}
+	}
+}
+
+func TestEvaluatorLatencySlopeFlat(t *testing.T) {
+	telemetry := &fakeTelemetry{}
+	cond := Condition{
+		ID:             "p50_creep",
+		Metric:         MetricLatencyP50Slope,
+		Comparator:     ">",
+		Threshold:      1,
+		WindowMs:       600000,
+		SustainWindows: 1,
+	}
+	evaluator := NewEvaluator("run_0000000000000001", telemetry, []Condition{cond}, 0)
+
+	for minute := int64(0); minute <= 10; minute++ {
+		telemetry.ops = append(telemetry.ops, analysis.OperationResult{Operation: "ping", OK: true, LatencyMs: 50})
+		trigger, err := evaluator.Evaluate(minute * 60000)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if trigger.Condition.Metric != "" {
+			t.Fatalf("expected no trigger for flat latency, got %+v", trigger)
+		}
+	}
+}
+
+func TestEvaluatorServerRSSSlope(t *testing.T) {
+	cond := Condition{
+		ID:             "rss_leak",
+		Metric:         MetricServerRSSSlope,
+		Comparator:     ">",
+		Threshold:      50,
+		WindowMs:       3600000,
+		SustainWindows: 2,
+	}
+
+	const mib = 1024 * 1024
+	var requested string
+	evaluator := NewEvaluator("run_0000000000000001", &fakeTelemetry{}, []Condition{cond}, 0)
+	evaluator.ServerMetrics = ServerMetricsProviderFunc(func(runID string, fromMs, toMs int64) []analysis.NodeSeries {
+		requested = runID
+		var steady, leaking analysis.NodeSeries
+		steady.AgentID = "agent-steady"
+		leaking.AgentID = "agent-leaking"
+		// One sample per 10 minutes; the leaking node grows 100 MiB per hour.
+		for ms := fromMs; ms <= toMs; ms += 600000 {
+			steady.Samples = append(steady.Samples, analysis.NodeSample{TimestampMs: ms, ProcessRSS: 200 * mib})
+			leaking.Samples = append(leaking.Samples, analysis.NodeSample{TimestampMs: ms, ProcessRSS: uint64(200*mib + ms*100*mib/3600000)})
+		}
+		return []analysis.NodeSeries{steady, leaking}
+	})
+
+	trigger, err := evaluator.Evaluate(3600000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if trigger.Condition.Metric != "" {
+		t.Fatalf("expected sustain to delay the trigger, got %+v", trigger)
+	}
+	if requested != "run_0000000000000001" {
+		t.Fatalf("expected metrics for the evaluator's run, got %q", requested)
+	}
+
+	trigger, err = evaluator.Evaluate(4200000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if trigger.Condition.Metric != MetricServerRSSSlope {
+		t.Fatalf("expected RSS slope trigger, got %+v", trigger)
+	}
+	if trigger.Observed < 99 || trigger.Observed > 101 {
+		t.Fatalf("expected the leaking node's 100MiB/hour, got %f", trigger.Observed)
+	}
+}
+
+func TestEvaluatorServerRSSSlopeWithoutAgent(t *testing.T) {
+	cond := Condition{
+		ID:             "rss_leak",
+		Metric:         MetricServerRSSSlope,
+		Comparator:     ">",
+		Threshold:      -1000,
+		WindowMs:       3600000,
+		SustainWindows: 1,
+	}
+	evaluator := NewEvaluator("run_0000000000000001", &fakeTelemetry{}, []Condition{cond}, 0)
+
+	trigger, err := evaluator.Evaluate(3600000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if trigger.Condition.Metric != "" {
+		t.Fatalf("expected no trigger without server metrics, got %+v", trigger)
+	}
+}
@@ -0,0 +1,217 @@
+// Package datafeed parses the CSV and JSONL data feeds of a workload and
+// hands their rows out to VUs. Templates reference a feed column as
+// ${feed.<name>.<column>}; every reference to a feed within one call reads
+// the same row.
+package datafeed
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// Format is the encoding of a feed's data.
+type Format string
+
+const (
+	// FormatCSV is comma-separated values with a header row naming the columns.
+	FormatCSV Format = "csv"
+
+	// FormatJSONL is one JSON object per line; the object keys are the columns.
+	FormatJSONL Format = "jsonl"
+)
+
+// Distribution decides which row a VU reads for a call.
+type Distribution string
+
+const (
+	// DistributionUnique binds each VU to its own row for its lifetime. The
+	// feed needs at least as many rows as the run has VUs.
+	DistributionUnique Distribution = "unique"
+
+	// DistributionRoundRobin starts each VU at its own row and advances one
+	// row per call, wrapping at the end of the feed.
+	DistributionRoundRobin Distribution = "round_robin"
+
+	// DistributionRandom picks a random row for every call.
+	DistributionRandom Distribution = "random"
+)
+
+// DefaultDistribution is used when a feed does not set one.
+const DefaultDistribution = DistributionRoundRobin
+
+// MaxDataBytes bounds the data of a single feed. Feeds travel inline in the
+// run config and in every worker assignment.
+const MaxDataBytes = 8 << 20
+
+// ReferencePrefix starts every template variable that reads a feed column.
+const ReferencePrefix = "feed."
+
+// Feed is a parsed data feed.
+type Feed struct {
+	Name         string
+	Distribution Distribution
+
+	// Columns lists the column names in the order they first appear.
+	Columns []string
+
+	// Rows holds the records. CSV values are strings; JSONL values keep
+	// their JSON types.
+	Rows []map[string]interface{}
+
+	columns map[string]bool
+}
+
+// Parse parses a feed's data. An empty distribution means
+// DefaultDistribution.
+func Parse(name string, format Format, distribution Distribution, data string) (*Feed, error) {
+	if distribution == "" {
+		distribution = DefaultDistribution
+	}
+	switch distribution {
+	case DistributionUnique, DistributionRoundRobin, DistributionRandom:
+	default:
+		return nil, fmt.Errorf("unknown distribution %q", distribution)
+	}
+	if len(data) > MaxDataBytes {
+		return nil, fmt.Errorf("data is %d bytes, limit is %d", len(data), MaxDataBytes)
+	}
+
+	feed := &Feed{Name: name, Distribution: distribution, columns: make(map[string]bool)}
+	var err error
+	switch format {
+	case FormatCSV:
+		err = feed.parseCSV(data)
+	case FormatJSONL:
+		err = feed.parseJSONL(data)
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(feed.Rows) == 0 {
+		return nil, fmt.Errorf("feed has no rows")
+	}
+	return feed, nil
+}
+
+func (f *Feed) parseCSV(data string) error {
+	r := csv.NewReader(strings.NewReader(data))
+	header, err := r.Read()
+	if err == io.EOF {
+		return fmt.Errorf("missing header row")
+	}
+	if err != nil {
+		return err
+	}
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff") // byte order mark
+		}
+		if name == "" {
+			return fmt.Errorf("column %d has an empty name", i+1)
+		}
+		if f.columns[name] {
+			return fmt.Errorf("duplicate column %q", name)
+		}
+		header[i] = name
+		f.addColumn(name)
+	}
+
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		row := make(map[string]interface{}, len(header))
+		for i, value := range record {
+			row[header[i]] = value
+		}
+		f.Rows = append(f.Rows, row)
+	}
+}
+
+func (f *Feed) parseJSONL(data string) error {
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		var row map[string]interface{}
+		dec := json.NewDecoder(bytes.NewReader([]byte(line)))
+		if err := dec.Decode(&row); err != nil || row == nil || dec.More() {
+			return fmt.Errorf("line %d is not a JSON object", i+1)
+		}
+		for _, name := range sortedKeys(row) {
+			f.addColumn(name)
+		}
+		f.Rows = append(f.Rows, row)
+	}
+	return nil
+}
+
+func (f *Feed) addColumn(name string) {
+	if !f.columns[name] {
+		f.columns[name] = true
+		f.Columns = append(f.Columns, name)
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// HasColumn reports whether any row of the feed has the column.
+func (f *Feed) HasColumn(name string) bool {
+	return f.columns[name]
+}
+
+// Row returns the row a VU reads for one call. vuIndex is the VU's index in
+// the run, from 0, and iteration counts the VU's calls from 1.
+func (f *Feed) Row(vuIndex int, iteration int64, rng *rand.Rand) (map[string]interface{}, error) {
+	n := len(f.Rows)
+	switch f.Distribution {
+	case DistributionUnique:
+		if vuIndex >= n {
+			return nil, fmt.Errorf("feed %q has %d rows, not enough for VU %d", f.Name, n, vuIndex+1)
+		}
+		return f.Rows[vuIndex], nil
+	case DistributionRandom:
+		return f.Rows[rng.Intn(n)], nil
+	default:
+		i := (int64(vuIndex) + iteration - 1) % int64(n)
+		if i < 0 {
+			i += int64(n)
+		}
+		return f.Rows[i], nil
+	}
+}
+
+// SplitReference splits a template variable such as feed.users.email into
+// the feed and column names. ok is false for other variables.
+func SplitReference(variable string) (feed, column string, ok bool) {
+	rest, found := strings.CutPrefix(variable, ReferencePrefix)
+	if !found {
+		return "", "", false
+	}
+	feed, column, found = strings.Cut(rest, ".")
+	if !found || feed == "" || column == "" || strings.Contains(column, ".") {
+		return "", "", false
+	}
+	return feed, column, true
+}
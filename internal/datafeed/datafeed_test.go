@@ -0,0 +1,114 @@
+package datafeed
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestParse_CSV(t *testing.T) {
+	feed, err := Parse("users", FormatCSV, "", "\ufeffemail, name\na@example.com,\"Ann, A.\"\nb@example.com,Bob\n")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if feed.Distribution != DefaultDistribution {
+		t.Errorf("distribution = %s, want default", feed.Distribution)
+	}
+	if strings.Join(feed.Columns, ",") != "email,name" {
+		t.Errorf("columns = %v", feed.Columns)
+	}
+	if len(feed.Rows) != 2 || feed.Rows[0]["name"] != "Ann, A." || feed.Rows[1]["email"] != "b@example.com" {
+		t.Errorf("rows = %v", feed.Rows)
+	}
+}
+
+func TestParse_JSONL(t *testing.T) {
+	feed, err := Parse("docs", FormatJSONL, DistributionRandom, "{\"id\": 1, \"tags\": [\"a\"]}\n\n{\"id\": 2, \"title\": \"x\"}\n")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(feed.Rows) != 2 || feed.Rows[0]["id"] != float64(1) {
+		t.Errorf("rows = %v", feed.Rows)
+	}
+	for _, col := range []string{"id", "tags", "title"} {
+		if !feed.HasColumn(col) {
+			t.Errorf("expected column %s", col)
+		}
+	}
+	if feed.HasColumn("missing") {
+		t.Error("unexpected column")
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name         string
+		format       Format
+		distribution Distribution
+		data         string
+	}{
+		{"no rows", FormatCSV, "", "email\n"},
+		{"empty", FormatCSV, "", ""},
+		{"ragged", FormatCSV, "", "a,b\n1\n"},
+		{"duplicate column", FormatCSV, "", "a,a\n1,2\n"},
+		{"empty column", FormatCSV, "", "a,\n1,2\n"},
+		{"not an object", FormatJSONL, "", "[1, 2]\n"},
+		{"two values", FormatJSONL, "", "{} {}\n"},
+		{"bad json", FormatJSONL, "", "{\"a\":\n"},
+		{"format", "xml", "", "<a/>"},
+		{"distribution", FormatCSV, "sequential", "a\n1\n"},
+		{"too large", FormatCSV, "", "a\n" + strings.Repeat("x", MaxDataBytes)},
+	}
+	for _, tt := range tests {
+		if _, err := Parse("f", tt.format, tt.distribution, tt.data); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestFeed_Row(t *testing.T) {
+	data := "n\n0\n1\n2\n"
+	rng := rand.New(rand.NewSource(1))
+
+	unique, _ := Parse("f", FormatCSV, DistributionUnique, data)
+	for iteration := int64(1); iteration <= 3; iteration++ {
+		row, err := unique.Row(2, iteration, rng)
+		if err != nil || row["n"] != "2" {
+			t.Errorf("unique row = %v, %v", row, err)
+		}
+	}
+	if _, err := unique.Row(3, 1, rng); err == nil {
+		t.Error("expected an error when a VU has no unique row")
+	}
+
+	roundRobin, _ := Parse("f", FormatCSV, DistributionRoundRobin, data)
+	var got []string
+	for iteration := int64(1); iteration <= 4; iteration++ {
+		row, _ := roundRobin.Row(1, iteration, rng)
+		got = append(got, row["n"].(string))
+	}
+	if strings.Join(got, "") != "1201" {
+		t.Errorf("round robin rows = %v", got)
+	}
+
+	random, _ := Parse("f", FormatCSV, DistributionRandom, data)
+	seen := map[interface{}]bool{}
+	for iteration := int64(1); iteration <= 100; iteration++ {
+		row, _ := random.Row(0, iteration, rng)
+		seen[row["n"]] = true
+	}
+	if len(seen) != 3 {
+		t.Errorf("random rows covered %d of 3", len(seen))
+	}
+}
+
+func TestSplitReference(t *testing.T) {
+	if feed, column, ok := SplitReference("feed.users.email"); !ok || feed != "users" || column != "email" {
+		t.Errorf("SplitReference = %s, %s, %v", feed, column, ok)
+	}
+	for _, name := range []string{"vu_id", "feed.users", "feed.users.a.b", "feeds.users.email"} {
+		if _, _, ok := SplitReference(name); ok {
+			t.Errorf("SplitReference(%s) should fail", name)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+// Package fuzz turns a share of tool call arguments into malformed inputs,
+// so a run shows how the target copes with wrong types, boundary numbers,
+// oversized strings, missing required fields and deeply nested values.
+//
+// Mutations change one top-level argument of a copy of the arguments; the
+// arguments stay a JSON object so the call itself remains well-formed.
+package fuzz
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// Mutation names a kind of malformed input.
+type Mutation string
+
+const (
+	// MutationWrongType replaces an argument with a value of another JSON type.
+	MutationWrongType Mutation = "wrong_type"
+	// MutationBoundaryNumber replaces an argument, numeric if there is one,
+	// with an extreme number such as 0, -1 or the largest int64.
+	MutationBoundaryNumber Mutation = "boundary_number"
+	// MutationOversizedString replaces an argument, a string if there is
+	// one, with a very long string.
+	MutationOversizedString Mutation = "oversized_string"
+	// MutationMissingRequired drops an argument, a required one if the
+	// tool's required arguments are known.
+	MutationMissingRequired Mutation = "missing_required"
+	// MutationDeepNesting replaces an argument with deeply nested objects
+	// and arrays.
+	MutationDeepNesting Mutation = "deep_nesting"
+)
+
+// Mutations lists every mutation in report order.
+var Mutations = []Mutation{
+	MutationWrongType,
+	MutationBoundaryNumber,
+	MutationOversizedString,
+	MutationMissingRequired,
+	MutationDeepNesting,
+}
+
+const (
+	// DefaultOversizedStringBytes is the length of oversized strings unless
+	// configured otherwise.
+	DefaultOversizedStringBytes = 64 * 1024
+	// MaxOversizedStringBytes caps oversized strings.
+	MaxOversizedStringBytes = 1024 * 1024
+	// DefaultNestingDepth is how deep nested values go unless configured
+	// otherwise.
+	DefaultNestingDepth = 100
+	// MaxNestingDepth caps the depth of nested values.
+	MaxNestingDepth = 1000
+)
+
+// boundaryNumbers are the values boundary_number draws from. JSON has no
+// NaN or infinity, so the largest finite doubles stand in for them.
+var boundaryNumbers = []interface{}{
+	int64(0),
+	int64(-1),
+	int64(math.MaxInt32),
+	int64(math.MinInt32),
+	int64(math.MaxInt64),
+	int64(math.MinInt64),
+	int64(1<<53 + 1),
+	math.MaxFloat64,
+	-math.MaxFloat64,
+	math.SmallestNonzeroFloat64,
+}
+
+// Config selects how often and how arguments are mutated.
+type Config struct {
+	// Ratio is the share of calls that are mutated, from 0 to 1.
+	Ratio float64
+	// Mutations are the kinds drawn from; empty means all of them.
+	Mutations []Mutation
+	// OversizedStringBytes is the length of oversized strings; zero means
+	// DefaultOversizedStringBytes.
+	OversizedStringBytes int
+	// NestingDepth is the depth of nested values; zero means
+	// DefaultNestingDepth.
+	NestingDepth int
+}
+
+// Fuzzer mutates tool call arguments. It is safe for concurrent use as long
+// as each caller passes its own rand.Rand.
+type Fuzzer struct {
+	ratio       float64
+	mutations   []Mutation
+	stringBytes int
+	depth       int
+}
+
+// New returns a fuzzer for cfg.
+func New(cfg Config) (*Fuzzer, error) {
+	if cfg.Ratio < 0 || cfg.Ratio > 1 || math.IsNaN(cfg.Ratio) {
+		return nil, fmt.Errorf("fuzz ratio %v is not between 0 and 1", cfg.Ratio)
+	}
+	f := &Fuzzer{
+		ratio:       cfg.Ratio,
+		stringBytes: cfg.OversizedStringBytes,
+		depth:       cfg.NestingDepth,
+	}
+	if f.stringBytes == 0 {
+		f.stringBytes = DefaultOversizedStringBytes
+	}
+	if f.stringBytes < 1 || f.stringBytes > MaxOversizedStringBytes {
+		return nil, fmt.Errorf("oversized string length %d is not between 1 and %d", f.stringBytes, MaxOversizedStringBytes)
+	}
+	if f.depth == 0 {
+		f.depth = DefaultNestingDepth
+	}
+	if f.depth < 1 || f.depth > MaxNestingDepth {
+		return nil, fmt.Errorf("nesting depth %d is not between 1 and %d", f.depth, MaxNestingDepth)
+	}
+
+	if len(cfg.Mutations) == 0 {
+		f.mutations = append(f.mutations, Mutations...)
+		return f, nil
+	}
+	seen := make(map[Mutation]bool, len(cfg.Mutations))
+	for _, m := range cfg.Mutations {
+		if !isMutation(m) {
+			return nil, fmt.Errorf("unknown fuzz mutation %q", m)
+		}
+		if !seen[m] {
+			seen[m] = true
+			f.mutations = append(f.mutations, m)
+		}
+	}
+	return f, nil
+}
+
+func isMutation(m Mutation) bool {
+	for _, known := range Mutations {
+		if m == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Mutate decides with rng whether this call is fuzzed. If so it returns a
+// mutated copy of args and the mutation applied; otherwise args and an empty
+// mutation. required names the tool's required arguments, if known. Calls
+// without arguments have nothing to mutate and are never fuzzed.
+func (f *Fuzzer) Mutate(args map[string]interface{}, required []string, rng *rand.Rand) (map[string]interface{}, Mutation) {
+	if len(args) == 0 || f.ratio == 0 || rng.Float64() >= f.ratio {
+		return args, ""
+	}
+
+	keys := make([]string, 0, len(args))
+	for key := range args {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	out := make(map[string]interface{}, len(args))
+	for key, value := range args {
+		out[key] = value
+	}
+
+	mutation := f.mutations[rng.Intn(len(f.mutations))]
+	switch mutation {
+	case MutationWrongType:
+		key := pick(keys, rng)
+		out[key] = wrongType(args[key], rng)
+	case MutationBoundaryNumber:
+		key := pick(preferKind(keys, args, "number"), rng)
+		out[key] = boundaryNumbers[rng.Intn(len(boundaryNumbers))]
+	case MutationOversizedString:
+		key := pick(preferKind(keys, args, "string"), rng)
+		out[key] = strings.Repeat("x", f.stringBytes)
+	case MutationMissingRequired:
+		candidates := make([]string, 0, len(required))
+		for _, name := range required {
+			if _, ok := args[name]; ok {
+				candidates = append(candidates, name)
+			}
+		}
+		if len(candidates) == 0 {
+			candidates = keys
+		}
+		delete(out, pick(candidates, rng))
+	case MutationDeepNesting:
+		out[pick(keys, rng)] = nested(f.depth)
+	}
+	return out, mutation
+}
+
+func pick(keys []string, rng *rand.Rand) string {
+	return keys[rng.Intn(len(keys))]
+}
+
+// preferKind returns the keys whose values are of the given JSON kind, or
+// all keys if there are none.
+func preferKind(keys []string, args map[string]interface{}, kind string) []string {
+	var matching []string
+	for _, key := range keys {
+		if jsonKind(args[key]) == kind {
+			matching = append(matching, key)
+		}
+	}
+	if len(matching) == 0 {
+		return keys
+	}
+	return matching
+}
+
+// wrongType returns a value whose JSON kind differs from v's.
+func wrongType(v interface{}, rng *rand.Rand) interface{} {
+	kind := jsonKind(v)
+	candidates := []interface{}{"fuzz", int64(12345), true, []interface{}{}, map[string]interface{}{}, nil}
+	others := candidates[:0]
+	for _, c := range candidates {
+		if jsonKind(c) != kind {
+			others = append(others, c)
+		}
+	}
+	return others[rng.Intn(len(others))]
+}
+
+// jsonKind returns the JSON type v encodes as.
+func jsonKind(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64, float32, int, int32, int64, json.Number:
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return "other"
+	}
+}
+
+// nested returns objects and arrays alternately nested depth levels deep.
+func nested(depth int) interface{} {
+	var v interface{} = "x"
+	for i := 0; i < depth; i++ {
+		if i%2 == 0 {
+			v = map[string]interface{}{"a": v}
+		} else {
+			v = []interface{}{v}
+		}
+	}
+	return v
+}
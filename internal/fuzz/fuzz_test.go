@@ -0,0 +1,107 @@
+package fuzz
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+)
+
+func mustNew(t *testing.T, cfg Config) *Fuzzer {
+	t.Helper()
+	f, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return f
+}
+
+func TestMutate_Ratio(t *testing.T) {
+	args := map[string]interface{}{"q": "hello"}
+	rng := rand.New(rand.NewSource(1))
+
+	none := mustNew(t, Config{Ratio: 0})
+	all := mustNew(t, Config{Ratio: 1})
+	half := mustNew(t, Config{Ratio: 0.5})
+	mutated := 0
+	for i := 0; i < 1000; i++ {
+		if _, m := none.Mutate(args, nil, rng); m != "" {
+			t.Fatalf("expected no mutation at ratio 0, got %s", m)
+		}
+		if _, m := all.Mutate(args, nil, rng); m == "" {
+			t.Fatal("expected every call mutated at ratio 1")
+		}
+		if _, m := half.Mutate(args, nil, rng); m != "" {
+			mutated++
+		}
+	}
+	if mutated < 400 || mutated > 600 {
+		t.Errorf("expected about half the calls mutated, got %d of 1000", mutated)
+	}
+	if _, m := all.Mutate(map[string]interface{}{}, nil, rng); m != "" {
+		t.Errorf("expected calls without arguments left alone, got %s", m)
+	}
+	if args["q"] != "hello" || len(args) != 1 {
+		t.Errorf("expected the original arguments untouched, got %v", args)
+	}
+}
+
+func TestMutate_Kinds(t *testing.T) {
+	args := map[string]interface{}{"name": "a", "count": float64(3), "tags": []interface{}{"x"}}
+	rng := rand.New(rand.NewSource(2))
+
+	for _, tc := range []struct {
+		mutation Mutation
+		check    func(out map[string]interface{}) bool
+	}{
+		{MutationWrongType, func(out map[string]interface{}) bool {
+			changed := 0
+			for key, value := range out {
+				if jsonKind(value) != jsonKind(args[key]) {
+					changed++
+				}
+			}
+			return changed == 1
+		}},
+		{MutationBoundaryNumber, func(out map[string]interface{}) bool {
+			return out["count"] != args["count"] && jsonKind(out["count"]) == "number"
+		}},
+		{MutationOversizedString, func(out map[string]interface{}) bool {
+			s, _ := out["name"].(string)
+			return len(s) == 10
+		}},
+		{MutationMissingRequired, func(out map[string]interface{}) bool {
+			_, ok := out["tags"]
+			return !ok && len(out) == 2
+		}},
+		{MutationDeepNesting, func(out map[string]interface{}) bool {
+			for key, value := range out {
+				if data, _ := json.Marshal(value); jsonKind(args[key]) != "object" && len(data) > 20 {
+					return true
+				}
+			}
+			return false
+		}},
+	} {
+		f := mustNew(t, Config{Ratio: 1, Mutations: []Mutation{tc.mutation}, OversizedStringBytes: 10, NestingDepth: 8})
+		for i := 0; i < 20; i++ {
+			out, m := f.Mutate(args, []string{"tags"}, rng)
+			if m != tc.mutation || !tc.check(out) {
+				t.Fatalf("%s: unexpected mutation %s to %v", tc.mutation, m, out)
+			}
+		}
+	}
+}
+
+func TestNew_RejectsInvalidConfig(t *testing.T) {
+	for _, cfg := range []Config{
+		{Ratio: 1.5},
+		{Ratio: -0.1},
+		{Ratio: 0.5, Mutations: []Mutation{"truncate"}},
+		{Ratio: 0.5, OversizedStringBytes: MaxOversizedStringBytes + 1},
+		{Ratio: 0.5, NestingDepth: -1},
+	} {
+		if _, err := New(cfg); err == nil {
+			t.Errorf("expected %+v to be rejected", cfg)
+		}
+	}
+}
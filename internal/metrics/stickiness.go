@@ -0,0 +1,175 @@
+package metrics
+
+import (
+	"sort"
+	"time"
+)
+
+// BackendObservation is one operation attributed to a session and, when the
+// target reported it, the backend instance that served it.
+type BackendObservation struct {
+	SessionID   string
+	BackendID   string
+	TimestampMs int64
+	WorkerID    string
+	Operation   string
+	OK          bool
+}
+
+// RebalanceEvent records a session whose requests moved to a different backend.
+type RebalanceEvent struct {
+	SessionID   string    `json:"session_id"`
+	Timestamp   time.Time `json:"timestamp"`
+	FromBackend string    `json:"from_backend"`
+	ToBackend   string    `json:"to_backend"`
+	Operation   string    `json:"operation"`
+	WorkerID    string    `json:"worker_id,omitempty"`
+	// OK reports whether the first request served by the new backend succeeded.
+	// Failures here usually mean the backend did not know the session.
+	OK bool `json:"ok"`
+}
+
+// SessionStickiness describes how one session was distributed across backends.
+type SessionStickiness struct {
+	SessionID    string   `json:"session_id"`
+	Backends     []string `json:"backends"`
+	FirstBackend string   `json:"first_backend"`
+	LastBackend  string   `json:"last_backend"`
+	Rebalances   int64    `json:"rebalances"`
+	RequestCount int64    `json:"request_count"`
+	Sticky       bool     `json:"sticky"`
+}
+
+// BackendLoad summarizes the traffic a backend instance served.
+type BackendLoad struct {
+	BackendID    string `json:"backend_id"`
+	RequestCount int64  `json:"request_count"`
+	SessionCount int64  `json:"session_count"`
+}
+
+// StickinessMetrics contains aggregated session-to-backend stickiness data.
+type StickinessMetrics struct {
+	SessionsObserved     int64               `json:"sessions_observed"`
+	StickySessions       int64               `json:"sticky_sessions"`
+	RebalancedSessions   int64               `json:"rebalanced_sessions"`
+	StickinessRatio      float64             `json:"stickiness_ratio"`
+	RebalanceCount       int64               `json:"rebalance_count"`
+	FailedRebalanceCount int64               `json:"failed_rebalance_count"`
+	RequestsWithBackend  int64               `json:"requests_with_backend"`
+	RequestsMissingID    int64               `json:"requests_missing_backend_id"`
+	Backends             []BackendLoad       `json:"backends"`
+	Events               []RebalanceEvent    `json:"events,omitempty"`
+	Sessions             []SessionStickiness `json:"sessions,omitempty"`
+}
+
+// ComputeStickiness derives stickiness from observations ordered by timestamp.
+// Only operations with both a session and a backend ID count toward stickiness;
+// session operations without a backend ID are reported as missing.
+func ComputeStickiness(observations []BackendObservation, includeEvents, includeSessions bool) *StickinessMetrics {
+	type sessionState struct {
+		backends     []string
+		seen         map[string]struct{}
+		current      string
+		rebalances   int64
+		requestCount int64
+	}
+
+	result := &StickinessMetrics{Backends: []BackendLoad{}}
+	sessions := make(map[string]*sessionState)
+	backendRequests := make(map[string]int64)
+	backendSessions := make(map[string]map[string]struct{})
+
+	for _, obs := range observations {
+		if obs.SessionID == "" {
+			continue
+		}
+		if obs.BackendID == "" {
+			result.RequestsMissingID++
+			continue
+		}
+		result.RequestsWithBackend++
+
+		backendRequests[obs.BackendID]++
+		if backendSessions[obs.BackendID] == nil {
+			backendSessions[obs.BackendID] = make(map[string]struct{})
+		}
+		backendSessions[obs.BackendID][obs.SessionID] = struct{}{}
+
+		state, ok := sessions[obs.SessionID]
+		if !ok {
+			state = &sessionState{seen: make(map[string]struct{})}
+			sessions[obs.SessionID] = state
+		}
+		state.requestCount++
+
+		if _, seen := state.seen[obs.BackendID]; !seen {
+			state.seen[obs.BackendID] = struct{}{}
+			state.backends = append(state.backends, obs.BackendID)
+		}
+
+		if state.current != "" && state.current != obs.BackendID {
+			state.rebalances++
+			result.RebalanceCount++
+			if !obs.OK {
+				result.FailedRebalanceCount++
+			}
+			if includeEvents {
+				result.Events = append(result.Events, RebalanceEvent{
+					SessionID:   obs.SessionID,
+					Timestamp:   time.UnixMilli(obs.TimestampMs),
+					FromBackend: state.current,
+					ToBackend:   obs.BackendID,
+					Operation:   obs.Operation,
+					WorkerID:    obs.WorkerID,
+					OK:          obs.OK,
+				})
+			}
+		}
+		state.current = obs.BackendID
+	}
+
+	sessionIDs := make([]string, 0, len(sessions))
+	for sessionID := range sessions {
+		sessionIDs = append(sessionIDs, sessionID)
+	}
+	sort.Strings(sessionIDs)
+
+	for _, sessionID := range sessionIDs {
+		state := sessions[sessionID]
+		result.SessionsObserved++
+		sticky := state.rebalances == 0
+		if sticky {
+			result.StickySessions++
+		} else {
+			result.RebalancedSessions++
+		}
+		if includeSessions {
+			result.Sessions = append(result.Sessions, SessionStickiness{
+				SessionID:    sessionID,
+				Backends:     state.backends,
+				FirstBackend: state.backends[0],
+				LastBackend:  state.current,
+				Rebalances:   state.rebalances,
+				RequestCount: state.requestCount,
+				Sticky:       sticky,
+			})
+		}
+	}
+
+	if result.SessionsObserved > 0 {
+		result.StickinessRatio = float64(result.StickySessions) / float64(result.SessionsObserved)
+	}
+
+	for backendID, requests := range backendRequests {
+		result.Backends = append(result.Backends, BackendLoad{
+			BackendID:    backendID,
+			RequestCount: requests,
+			SessionCount: int64(len(backendSessions[backendID])),
+		})
+	}
+	sort.Slice(result.Backends, func(i, j int) bool {
+		return result.Backends[i].BackendID < result.Backends[j].BackendID
+	})
+
+	return result
+}
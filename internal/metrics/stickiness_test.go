@@ -0,0 +1,76 @@
+package metrics
+
+import "testing"
+
+func TestComputeStickiness(t *testing.T) {
+	observations := []BackendObservation{
+		{SessionID: "ses_a", BackendID: "be-1", TimestampMs: 1000, Operation: "tools/list", OK: true},
+		{SessionID: "ses_b", BackendID: "be-2", TimestampMs: 1001, Operation: "tools/list", OK: true},
+		{SessionID: "ses_a", BackendID: "be-1", TimestampMs: 1002, Operation: "tools/call", OK: true},
+		{SessionID: "ses_b", BackendID: "be-1", TimestampMs: 1003, Operation: "tools/call", OK: false, WorkerID: "wkr_1"},
+		{SessionID: "ses_b", BackendID: "be-1", TimestampMs: 1004, Operation: "ping", OK: true},
+		{SessionID: "ses_b", BackendID: "be-2", TimestampMs: 1005, Operation: "ping", OK: true},
+		{SessionID: "ses_c", TimestampMs: 1006, Operation: "ping", OK: true},
+		{BackendID: "be-3", TimestampMs: 1007, Operation: "ping", OK: true},
+	}
+
+	result := ComputeStickiness(observations, true, true)
+
+	if result.SessionsObserved != 2 {
+		t.Errorf("expected 2 sessions observed, got %d", result.SessionsObserved)
+	}
+	if result.StickySessions != 1 || result.RebalancedSessions != 1 {
+		t.Errorf("expected 1 sticky and 1 rebalanced session, got %d and %d", result.StickySessions, result.RebalancedSessions)
+	}
+	if result.StickinessRatio != 0.5 {
+		t.Errorf("expected stickiness ratio 0.5, got %f", result.StickinessRatio)
+	}
+	if result.RebalanceCount != 2 || result.FailedRebalanceCount != 1 {
+		t.Errorf("expected 2 rebalances (1 failed), got %d (%d failed)", result.RebalanceCount, result.FailedRebalanceCount)
+	}
+	if result.RequestsWithBackend != 6 || result.RequestsMissingID != 1 {
+		t.Errorf("expected 6 requests with backend and 1 missing, got %d and %d", result.RequestsWithBackend, result.RequestsMissingID)
+	}
+
+	if len(result.Events) != 2 {
+		t.Fatalf("expected 2 rebalance events, got %d", len(result.Events))
+	}
+	first := result.Events[0]
+	if first.SessionID != "ses_b" || first.FromBackend != "be-2" || first.ToBackend != "be-1" || first.OK || first.WorkerID != "wkr_1" {
+		t.Errorf("unexpected first rebalance event: %+v", first)
+	}
+
+	if len(result.Sessions) != 2 {
+		t.Fatalf("expected 2 session entries, got %d", len(result.Sessions))
+	}
+	sessionB := result.Sessions[1]
+	if sessionB.SessionID != "ses_b" || sessionB.Sticky || sessionB.Rebalances != 2 || sessionB.FirstBackend != "be-2" || sessionB.LastBackend != "be-2" {
+		t.Errorf("unexpected stickiness for ses_b: %+v", sessionB)
+	}
+	if len(sessionB.Backends) != 2 {
+		t.Errorf("expected ses_b to touch 2 backends, got %v", sessionB.Backends)
+	}
+
+	if len(result.Backends) != 2 {
+		t.Fatalf("expected 2 backends, got %d", len(result.Backends))
+	}
+	if result.Backends[0].BackendID != "be-1" || result.Backends[0].RequestCount != 4 || result.Backends[0].SessionCount != 2 {
+		t.Errorf("unexpected load for be-1: %+v", result.Backends[0])
+	}
+}
+
+func TestComputeStickiness_NoBackendIDs(t *testing.T) {
+	result := ComputeStickiness([]BackendObservation{
+		{SessionID: "ses_a", TimestampMs: 1000, OK: true},
+	}, false, false)
+
+	if result.SessionsObserved != 0 || result.StickinessRatio != 0 {
+		t.Errorf("expected no observed sessions, got %+v", result)
+	}
+	if result.RequestsMissingID != 1 {
+		t.Errorf("expected 1 request missing backend id, got %d", result.RequestsMissingID)
+	}
+	if result.Events != nil || result.Sessions != nil {
+		t.Error("expected events and sessions to be omitted")
+	}
+}
@@ -0,0 +1,236 @@
+package mockserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// BehaviorPath is where the mock server serves its behavior control API.
+const BehaviorPath = "/admin/behavior"
+
+// Defaults for the behavior settings left at zero.
+const (
+	defaultRateLimitQuota          = 5
+	defaultRateLimitWindowMs       = 1000
+	defaultCircuitFailureThreshold = 3
+	defaultCircuitOpenMs           = 2000
+)
+
+// BehaviorProfile controls how the server answers: streaming, added
+// latency, injected errors, and the thresholds of the rate_limited and
+// circuit_breaker tools. It can be changed while the server runs, through
+// SetBehavior or the API at BehaviorPath.
+type BehaviorProfile struct {
+	StreamingChunkCount   int `json:"streaming_chunk_count,omitempty"`
+	StreamingChunkDelayMs int `json:"streaming_chunk_delay_ms,omitempty"`
+
+	// Latency, if set, delays the answer to every request but initialize.
+	Latency *LatencyProfile `json:"latency,omitempty"`
+
+	// ToolErrorRate is the share of tools/call answered with a tool error
+	// result. HTTPErrorRate is the share of requests but initialize
+	// answered with HTTPErrorStatus, 503 if unset, instead.
+	ToolErrorRate   float64 `json:"tool_error_rate,omitempty"`
+	HTTPErrorRate   float64 `json:"http_error_rate,omitempty"`
+	HTTPErrorStatus int     `json:"http_error_status,omitempty"`
+
+	// RateLimitQuota calls of the rate_limited tool succeed per
+	// RateLimitWindowMs; 5 per second if unset.
+	RateLimitQuota    int `json:"rate_limit_quota,omitempty"`
+	RateLimitWindowMs int `json:"rate_limit_window_ms,omitempty"`
+
+	// The circuit_breaker tool opens after CircuitFailureThreshold forced
+	// failures in a row and stays open for CircuitOpenMs; 3 and 2000 if
+	// unset.
+	CircuitFailureThreshold int `json:"circuit_failure_threshold,omitempty"`
+	CircuitOpenMs           int `json:"circuit_open_ms,omitempty"`
+
+	// Subscribed resources are reported updated every
+	// ResourceUpdateIntervalMs, every second if unset. Every
+	// ListChangedIntervalMs, if set, the resource list is reported changed.
+	ResourceUpdateIntervalMs int `json:"resource_update_interval_ms,omitempty"`
+	ListChangedIntervalMs    int `json:"list_changed_interval_ms,omitempty"`
+}
+
+// LatencyProfile is a distribution of added latency: "fixed" adds MeanMs,
+// "uniform" a value between MinMs and MaxMs, and "normal" a value around
+// MeanMs with StddevMs, kept between MinMs and MaxMs when MaxMs is set.
+type LatencyProfile struct {
+	Distribution string `json:"distribution"`
+	MeanMs       int    `json:"mean_ms,omitempty"`
+	StddevMs     int    `json:"stddev_ms,omitempty"`
+	MinMs        int    `json:"min_ms,omitempty"`
+	MaxMs        int    `json:"max_ms,omitempty"`
+}
+
+// Validate checks the profile's rates, durations and distribution.
+func (b *BehaviorProfile) Validate() error {
+	for name, v := range map[string]int{
+		"streaming_chunk_count":       b.StreamingChunkCount,
+		"streaming_chunk_delay_ms":    b.StreamingChunkDelayMs,
+		"rate_limit_quota":            b.RateLimitQuota,
+		"rate_limit_window_ms":        b.RateLimitWindowMs,
+		"circuit_failure_threshold":   b.CircuitFailureThreshold,
+		"circuit_open_ms":             b.CircuitOpenMs,
+		"resource_update_interval_ms": b.ResourceUpdateIntervalMs,
+		"list_changed_interval_ms":    b.ListChangedIntervalMs,
+	} {
+		if v < 0 {
+			return fmt.Errorf("%s must not be negative", name)
+		}
+	}
+	if b.ToolErrorRate < 0 || b.ToolErrorRate > 1 {
+		return errors.New("tool_error_rate must be between 0 and 1")
+	}
+	if b.HTTPErrorRate < 0 || b.HTTPErrorRate > 1 {
+		return errors.New("http_error_rate must be between 0 and 1")
+	}
+	if b.HTTPErrorStatus != 0 && (b.HTTPErrorStatus < 400 || b.HTTPErrorStatus > 599) {
+		return errors.New("http_error_status must be a 4xx or 5xx status")
+	}
+	if l := b.Latency; l != nil {
+		if l.MeanMs < 0 || l.StddevMs < 0 || l.MinMs < 0 || l.MaxMs < 0 {
+			return errors.New("latency must not be negative")
+		}
+		switch l.Distribution {
+		case "fixed", "normal":
+		case "uniform":
+			if l.MaxMs < l.MinMs {
+				return errors.New("latency max_ms must not be below min_ms")
+			}
+		default:
+			return fmt.Errorf("unknown latency distribution %q: want fixed, uniform or normal", l.Distribution)
+		}
+	}
+	return nil
+}
+
+// sample returns a latency drawn from the distribution.
+func (l *LatencyProfile) sample() time.Duration {
+	var ms float64
+	switch l.Distribution {
+	case "fixed":
+		ms = float64(l.MeanMs)
+	case "uniform":
+		ms = float64(l.MinMs) + rand.Float64()*float64(l.MaxMs-l.MinMs)
+	case "normal":
+		ms = float64(l.MeanMs) + rand.NormFloat64()*float64(l.StddevMs)
+		ms = max(ms, float64(l.MinMs))
+		if l.MaxMs > 0 {
+			ms = min(ms, float64(l.MaxMs))
+		}
+	}
+	return time.Duration(max(ms, 0) * float64(time.Millisecond))
+}
+
+// clone returns a copy of b that shares nothing with it.
+func (b BehaviorProfile) clone() BehaviorProfile {
+	if b.Latency != nil {
+		latency := *b.Latency
+		b.Latency = &latency
+	}
+	return b
+}
+
+func orDefault(v, def int) int {
+	if v <= 0 {
+		return def
+	}
+	return v
+}
+
+// Behavior returns the server's current behavior.
+func (s *mockServer) Behavior() BehaviorProfile {
+	s.behaviorMu.RLock()
+	defer s.behaviorMu.RUnlock()
+	return s.behavior.clone()
+}
+
+// SetBehavior replaces the server's behavior. A changed rate limit quota
+// starts from a full window.
+func (s *mockServer) SetBehavior(b BehaviorProfile) error {
+	if err := b.Validate(); err != nil {
+		return err
+	}
+	s.behaviorMu.Lock()
+	defer s.behaviorMu.Unlock()
+	if b.RateLimitQuota != s.behavior.RateLimitQuota || b.RateLimitWindowMs != s.behavior.RateLimitWindowMs {
+		s.mu.Lock()
+		s.rateLimiter = newRateLimiter(orDefault(b.RateLimitQuota, defaultRateLimitQuota),
+			time.Duration(orDefault(b.RateLimitWindowMs, defaultRateLimitWindowMs))*time.Millisecond)
+		s.mu.Unlock()
+	}
+	s.behavior = b.clone()
+	return nil
+}
+
+// handleBehavior serves the behavior control API: GET returns the current
+// behavior, PUT replaces it, PATCH changes the fields given, and DELETE
+// restores the behavior the server started with.
+func (s *mockServer) handleBehavior(w http.ResponseWriter, r *http.Request) {
+	var next BehaviorProfile
+	switch r.Method {
+	case http.MethodGet:
+		writeBehavior(w, s.Behavior())
+		return
+	case http.MethodPut, http.MethodPatch:
+		if r.Method == http.MethodPatch {
+			next = s.Behavior()
+		}
+		body, err := ioReadAll(r)
+		if err != nil {
+			http.Error(w, "failed to read body", http.StatusBadRequest)
+			return
+		}
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&next); err != nil {
+			http.Error(w, "invalid behavior: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	case http.MethodDelete:
+		next = s.cfg.behavior
+	default:
+		w.Header().Set("Allow", "GET, PUT, PATCH, DELETE")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if err := s.SetBehavior(next); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeBehavior(w, next)
+}
+
+func writeBehavior(w http.ResponseWriter, b BehaviorProfile) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(b)
+}
+
+// injectFaults applies the added latency and HTTP errors of the current
+// behavior to a request. It returns false if it answered the request.
+func (s *mockServer) injectFaults(w http.ResponseWriter, r *http.Request, method string) bool {
+	if method == "initialize" {
+		return true
+	}
+	b := s.Behavior()
+	if b.Latency != nil {
+		if !sleepWithContext(r.Context(), b.Latency.sample()) {
+			return false
+		}
+	}
+	if b.HTTPErrorRate > 0 && rand.Float64() < b.HTTPErrorRate {
+		status := b.HTTPErrorStatus
+		if status == 0 {
+			status = http.StatusServiceUnavailable
+		}
+		http.Error(w, "injected failure", status)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,111 @@
+package mockserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+func behaviorRequest(t *testing.T, srv Server, method, body string) (int, BehaviorProfile) {
+	t.Helper()
+	req, err := http.NewRequest(method, "http://"+srv.Addr()+BehaviorPath, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, BehaviorPath, err)
+	}
+	defer resp.Body.Close()
+	var b BehaviorProfile
+	if resp.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(&b); err != nil {
+			t.Fatalf("decode behavior: %v", err)
+		}
+	}
+	return resp.StatusCode, b
+}
+
+func callEcho(t *testing.T, srv Server) types.ToolsCallResult {
+	t.Helper()
+	var result types.ToolsCallResult
+	raw := callMCP(t, srv, "tools/call", map[string]interface{}{"name": "fast_echo", "arguments": map[string]interface{}{"message": "hi"}})
+	if err := json.Unmarshal(raw, &result); err != nil {
+		t.Fatalf("decode tools/call result: %v", err)
+	}
+	return result
+}
+
+func TestBehaviorAPI(t *testing.T) {
+	srv, cleanup := StartTestServer()
+	defer cleanup()
+
+	status, b := behaviorRequest(t, srv, http.MethodGet, "")
+	if status != http.StatusOK || b.StreamingChunkCount != 5 {
+		t.Fatalf("expected the startup behavior, got %d %+v", status, b)
+	}
+
+	status, b = behaviorRequest(t, srv, http.MethodPatch, `{"tool_error_rate": 1, "latency": {"distribution": "fixed", "mean_ms": 50}}`)
+	if status != http.StatusOK || b.ToolErrorRate != 1 || b.StreamingChunkCount != 5 {
+		t.Fatalf("expected the patch merged into the behavior, got %d %+v", status, b)
+	}
+	start := time.Now()
+	if result := callEcho(t, srv); !result.IsError {
+		t.Errorf("expected an injected tool error, got %+v", result)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("expected 50ms of added latency, took %v", elapsed)
+	}
+
+	for _, body := range []string{`{"tool_error_rate": 2}`, `{"latency": {"distribution": "pareto"}}`, `{"unknown": true}`} {
+		if status, _ := behaviorRequest(t, srv, http.MethodPatch, body); status != http.StatusBadRequest {
+			t.Errorf("expected %s to be rejected, got %d", body, status)
+		}
+	}
+
+	if status, _ := behaviorRequest(t, srv, http.MethodPut, `{"http_error_rate": 1, "http_error_status": 502}`); status != http.StatusOK {
+		t.Fatalf("PUT failed with %d", status)
+	}
+	body, _ := json.Marshal(types.JSONRPCRequest{JSONRPC: "2.0", ID: "1", Method: "ping"})
+	resp, err := http.Post(srv.MCPURL(), "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("expected an injected 502, got %d", resp.StatusCode)
+	}
+
+	status, b = behaviorRequest(t, srv, http.MethodDelete, "")
+	if status != http.StatusOK || b.HTTPErrorRate != 0 || b.StreamingChunkCount != 5 {
+		t.Fatalf("expected the startup behavior back, got %d %+v", status, b)
+	}
+	if result := callEcho(t, srv); result.IsError {
+		t.Errorf("expected no injected error after reset, got %+v", result)
+	}
+}
+
+func TestSetBehavior_Thresholds(t *testing.T) {
+	srv, cleanup := StartTestServer()
+	defer cleanup()
+	if err := srv.SetBehavior(BehaviorProfile{RateLimitQuota: 1, RateLimitWindowMs: 60000, CircuitFailureThreshold: 1, CircuitOpenMs: 60000}); err != nil {
+		t.Fatal(err)
+	}
+
+	call := func(name string, args map[string]interface{}) bool {
+		var result types.ToolsCallResult
+		_ = json.Unmarshal(callMCP(t, srv, "tools/call", map[string]interface{}{"name": name, "arguments": args}), &result)
+		return result.IsError
+	}
+	if call("rate_limited", nil) || !call("rate_limited", nil) {
+		t.Error("expected the second call to exceed a quota of 1")
+	}
+	if !call("circuit_breaker", map[string]interface{}{"force_error": true}) || !call("circuit_breaker", nil) {
+		t.Error("expected the circuit to open after 1 failure and stay open")
+	}
+}
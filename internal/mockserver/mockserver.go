@@ -25,8 +25,20 @@ import (
 
 // Config configures the mock server.
 type Config struct {
-	Addr     string
+	Addr string
+	// Strict makes the server hold clients to the protocol: it issues an
+	// Mcp-Session-Id on initialize and requires it, and the initialized
+	// notification, on every later request; it rejects unsupported protocol
+	// versions; and it answers notifications with 202 and no body.
+	Strict bool
+	// StateURL, if set, is the address of a mock server, such as
+	// http://10.0.0.5:3000, whose counters, rate limit quota and circuit
+	// breaker this server uses in place of its own. Servers sharing one
+	// act as a single service behind a load balancer.
+	StateURL string
+
 	behavior BehaviorProfile
+	timeline *Timeline
 }
 
 func (c *Config) SetBehavior(b *BehaviorProfile) {
@@ -36,6 +48,12 @@ func (c *Config) SetBehavior(b *BehaviorProfile) {
 	c.behavior = *b
 }
 
+// SetTimeline makes the server go through the phases of t from the moment
+// it starts.
+func (c *Config) SetTimeline(t *Timeline) {
+	c.timeline = t
+}
+
 func DefaultConfig() *Config {
 	return &Config{
 		Addr: "127.0.0.1:0",
@@ -46,18 +64,20 @@ func DefaultConfig() *Config {
 	}
 }
 
-// BehaviorProfile controls streaming behavior.
-type BehaviorProfile struct {
-	StreamingChunkCount   int
-	StreamingChunkDelayMs int
-}
-
 // Server is the mock server interface.
 type Server interface {
 	Start() error
 	Stop(ctx context.Context)
 	Addr() string
 	MCPURL() string
+	RegisterTool(name string, schema json.RawMessage, handler ToolHandler) error
+	// Behavior returns the current behavior, and SetBehavior replaces it
+	// while the server runs.
+	Behavior() BehaviorProfile
+	SetBehavior(b BehaviorProfile) error
+	// ServeStdio serves the same tools over stdin and stdout instead of
+	// HTTP; it does not need Start.
+	ServeStdio(ctx context.Context, in io.Reader, out io.Writer) error
 }
 
 // New creates a new mock server.
@@ -65,12 +85,20 @@ func New(config *Config) Server {
 	if config == nil {
 		config = DefaultConfig()
 	}
-	return &mockServer{
-		cfg:          config,
-		behavior:     config.behavior,
-		rateLimiter:  newRateLimiter(5, time.Second),
+	s := &mockServer{
+		cfg:      config,
+		behavior: config.behavior,
+		rateLimiter: newRateLimiter(orDefault(config.behavior.RateLimitQuota, defaultRateLimitQuota),
+			time.Duration(orDefault(config.behavior.RateLimitWindowMs, defaultRateLimitWindowMs))*time.Millisecond),
 		backpressure: make(chan struct{}, 5),
+		notifier:     newNotificationHub(),
+	}
+	if config.StateURL != "" {
+		s.state = newRemoteState(config.StateURL)
+	} else {
+		s.state = localState{s: s}
 	}
+	return s
 }
 
 // StartTestServer starts a server with defaults and returns cleanup.
@@ -90,10 +118,12 @@ func StartTestServer() (server Server, cleanup func()) {
 
 type mockServer struct {
 	cfg          *Config
+	behaviorMu   sync.RWMutex
 	behavior     BehaviorProfile
 	httpServer   *http.Server
 	listener     net.Listener
 	addr         string
+	timeline     *timelineRunner
 	stateCounter atomic.Int64
 	degradeCount atomic.Int64
 
@@ -102,6 +132,16 @@ type mockServer struct {
 	circuitOpenTo time.Time
 	rateLimiter   *tokenBucket
 	backpressure  chan struct{}
+
+	toolsMu     sync.RWMutex
+	customTools map[string]customTool
+
+	sessionsMu sync.Mutex
+	sessions   map[string]*strictSession
+
+	state             stateStore
+	notifier          *notificationHub
+	stopNotifications context.CancelFunc
 }
 
 func (s *mockServer) Start() error {
@@ -117,12 +157,27 @@ func (s *mockServer) Start() error {
 	s.listener = ln
 	s.addr = ln.Addr().String()
 
+	if s.cfg.timeline != nil {
+		if err := s.startTimeline(s.cfg.timeline); err != nil {
+			ln.Close()
+			return fmt.Errorf("timeline: %w", err)
+		}
+	}
+
 	mux := http.NewServeMux()
 	mux.HandleFunc("/mcp", s.handleMCP)
+	mux.HandleFunc(BehaviorPath, s.handleBehavior)
+	mux.HandleFunc(TimelinePath, s.handleTimeline)
+	mux.HandleFunc(StatePath, s.handleState)
 
 	s.httpServer = &http.Server{
 		Handler: mux,
 	}
+	s.httpServer.RegisterOnShutdown(s.notifier.close)
+
+	notifyCtx, cancel := context.WithCancel(context.Background())
+	s.stopNotifications = cancel
+	go s.runNotifications(notifyCtx)
 
 	go func() {
 		_ = s.httpServer.Serve(ln)
@@ -132,6 +187,12 @@ func (s *mockServer) Start() error {
 }
 
 func (s *mockServer) Stop(ctx context.Context) {
+	if s.timeline != nil {
+		s.timeline.stop()
+	}
+	if s.stopNotifications != nil {
+		s.stopNotifications()
+	}
 	if s.httpServer == nil {
 		return
 	}
@@ -150,6 +211,14 @@ func (s *mockServer) MCPURL() string {
 }
 
 func (s *mockServer) handleMCP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodDelete && s.cfg.Strict {
+		s.handleSessionDelete(w, r)
+		return
+	}
+	if r.Method == http.MethodGet {
+		s.handleNotificationStream(w, r)
+		return
+	}
 	if r.Method != http.MethodPost {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
@@ -171,6 +240,16 @@ func (s *mockServer) handleMCP(w http.ResponseWriter, r *http.Request) {
 		writeJSONRPCError(w, req.ID, -32600, "invalid jsonrpc version")
 		return
 	}
+	if s.cfg.Strict && !s.checkStrict(w, r, req) {
+		return
+	}
+	if !s.injectFaults(w, r, req.Method) {
+		return
+	}
+	if req.ID == nil && strings.HasPrefix(req.Method, "notifications/") {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
 
 	switch req.Method {
 	case "initialize":
@@ -179,13 +258,20 @@ func (s *mockServer) handleMCP(w http.ResponseWriter, r *http.Request) {
 			json.Unmarshal(req.Params, &initParams)
 		}
 		version := initParams.ProtocolVersion
-		if version == "" || !mcp.IsSupported(version) {
+		if s.cfg.Strict {
+			if !s.startSession(w, req.ID, version) {
+				return
+			}
+		} else if version == "" || !mcp.IsSupported(version) {
 			version = mcp.DefaultProtocolVersion
 		}
 		result := types.InitializeResult{
 			ProtocolVersion: version,
-			Capabilities:    map[string]interface{}{},
-			ServerInfo:      types.ServerInfo{Name: "mockserver", Version: "1.0.0"},
+			Capabilities: map[string]interface{}{
+				"tools":     map[string]interface{}{"listChanged": true},
+				"resources": map[string]interface{}{"subscribe": true, "listChanged": true},
+			},
+			ServerInfo: types.ServerInfo{Name: "mockserver", Version: "1.0.0"},
 		}
 		writeJSONRPCResult(w, req.ID, result)
 		return
@@ -193,7 +279,7 @@ func (s *mockServer) handleMCP(w http.ResponseWriter, r *http.Request) {
 		writeJSONRPCResult(w, req.ID, map[string]interface{}{"ok": true})
 		return
 	case "tools/list":
-		result := types.ToolsListResult{Tools: buildToolsList()}
+		result := types.ToolsListResult{Tools: s.buildToolsList()}
 		writeJSONRPCResult(w, req.ID, result)
 		return
 	case "tools/call":
@@ -206,6 +292,9 @@ func (s *mockServer) handleMCP(w http.ResponseWriter, r *http.Request) {
 	case "resources/read":
 		s.handleResourcesRead(w, req)
 		return
+	case "resources/subscribe", "resources/unsubscribe":
+		s.handleResourcesSubscribe(w, r, req)
+		return
 	case "prompts/list":
 		result := buildPromptsList()
 		writeJSONRPCResult(w, req.ID, result)
@@ -221,9 +310,13 @@ func (s *mockServer) handleMCP(w http.ResponseWriter, r *http.Request) {
 
 func (s *mockServer) handleToolsCall(w http.ResponseWriter, r *http.Request, req types.JSONRPCRequest) {
 	var params types.ToolsCallParams
-	if err := json.Unmarshal(req.Params, &params); err != nil {
-		result := toolErrorResult("invalid params")
-		writeJSONRPCResult(w, req.ID, result)
+	if err := json.Unmarshal(req.Params, &params); err != nil || params.Name == "" {
+		writeJSONRPCError(w, req.ID, -32602, "invalid params")
+		return
+	}
+
+	if rate := s.Behavior().ToolErrorRate; rate > 0 && rand.Float64() < rate {
+		writeJSONRPCResult(w, req.ID, toolErrorResult("injected tool error"))
 		return
 	}
 
@@ -259,11 +352,12 @@ func (s *mockServer) handleStreamingTool(w http.ResponseWriter, r *http.Request,
 	for i := 0; i < chunks; i++ {
 		progress := map[string]interface{}{
 			"jsonrpc": "2.0",
-			"method":  "progress",
-			"id":      id,
+			"method":  "notifications/progress",
+			// The request ID stands in for a progress token.
 			"params": map[string]interface{}{
-				"chunk": i + 1,
-				"total": chunks,
+				"progressToken": id,
+				"progress":      i + 1,
+				"total":         chunks,
 			},
 		}
 		if !writeSSE(w, progress) {
@@ -295,8 +389,9 @@ func (s *mockServer) handleStreamingTool(w http.ResponseWriter, r *http.Request,
 }
 
 func (s *mockServer) streamingParams(args map[string]interface{}) (int, int) {
-	chunks := s.behavior.StreamingChunkCount
-	delay := s.behavior.StreamingChunkDelayMs
+	behavior := s.Behavior()
+	chunks := behavior.StreamingChunkCount
+	delay := behavior.StreamingChunkDelayMs
 
 	if v, ok := args["chunks"]; ok {
 		if n, ok := toInt(v); ok && n > 0 {
@@ -368,44 +463,20 @@ func (s *mockServer) executeTool(ctx context.Context, name string, args map[stri
 	case "flaky_connection":
 		return flakyConnection(), true
 	case "rate_limited":
-		return s.rateLimited(), true
+		return s.rateLimited(ctx), true
 	case "circuit_breaker":
-		return s.circuitBreaker(args), true
+		return s.circuitBreaker(ctx, args), true
 	case "backpressure":
 		return s.backpressureTool(ctx), true
 	case "stateful_counter":
-		return s.statefulCounter(), true
+		return s.statefulCounter(ctx), true
 	case "realistic_latency":
 		return realisticLatency(ctx), true
 	default:
-		return types.ToolsCallResult{}, false
+		return s.executeCustomTool(ctx, name, args)
 	}
 }
 
-func buildToolsList() []types.Tool {
-	schema := json.RawMessage(`{"type":"object"}`)
-	names := []string{
-		"fast_echo", "slow_echo", "error_tool", "timeout_tool", "streaming_tool",
-		"json_transform", "text_processor", "list_operations",
-		"validate_email", "calculate", "hash_generator",
-		"weather_api", "geocode", "currency_convert",
-		"read_file", "write_file", "list_directory",
-		"large_payload", "random_latency", "conditional_error",
-		"degrading_performance", "flaky_connection", "rate_limited",
-		"circuit_breaker", "backpressure", "stateful_counter", "realistic_latency",
-	}
-
-	tools := make([]types.Tool, 0, len(names))
-	for _, name := range names {
-		tools = append(tools, types.Tool{
-			Name:        name,
-			Description: "mock tool",
-			InputSchema: schema,
-		})
-	}
-	return tools
-}
-
 // buildResourcesList returns a list of mock resources.
 func buildResourcesList() types.ResourcesListResult {
 	return types.ResourcesListResult{
@@ -1003,7 +1074,10 @@ func conditionalError(args map[string]interface{}) types.ToolsCallResult {
 }
 
 func (s *mockServer) degradingPerformance(ctx context.Context) types.ToolsCallResult {
-	count := s.degradeCount.Add(1)
+	count, err := s.state.increment(ctx, counterDegrading)
+	if err != nil {
+		return sharedStateError(err)
+	}
 	_ = sleepWithContext(ctx, time.Duration(count*20)*time.Millisecond)
 	return textResult(fmt.Sprintf("degraded step %d", count))
 }
@@ -1015,33 +1089,26 @@ func flakyConnection() types.ToolsCallResult {
 	return textResult("ok")
 }
 
-func (s *mockServer) rateLimited() types.ToolsCallResult {
-	if !s.rateLimiter.Allow() {
+func (s *mockServer) rateLimited(ctx context.Context) types.ToolsCallResult {
+	allowed, err := s.state.allowRate(ctx)
+	if err != nil {
+		return sharedStateError(err)
+	}
+	if !allowed {
 		return toolErrorResult("rate limited")
 	}
 	return textResult("ok")
 }
 
-func (s *mockServer) circuitBreaker(args map[string]interface{}) types.ToolsCallResult {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	if time.Now().Before(s.circuitOpenTo) {
-		return toolErrorResult("circuit open")
-	}
-
-	if fail, ok := getBoolArg(args, "force_error"); ok && fail {
-		s.circuitFails++
-	} else {
-		s.circuitFails = 0
+func (s *mockServer) circuitBreaker(ctx context.Context, args map[string]interface{}) types.ToolsCallResult {
+	fail, _ := getBoolArg(args, "force_error")
+	passed, err := s.state.passCircuit(ctx, fail)
+	if err != nil {
+		return sharedStateError(err)
 	}
-
-	if s.circuitFails >= 3 {
-		s.circuitOpenTo = time.Now().Add(2 * time.Second)
-		s.circuitFails = 0
+	if !passed {
 		return toolErrorResult("circuit open")
 	}
-
 	return textResult("ok")
 }
 
@@ -1058,11 +1125,18 @@ func (s *mockServer) backpressureTool(ctx context.Context) types.ToolsCallResult
 	return textResult("ok")
 }
 
-func (s *mockServer) statefulCounter() types.ToolsCallResult {
-	value := s.stateCounter.Add(1)
+func (s *mockServer) statefulCounter(ctx context.Context) types.ToolsCallResult {
+	value, err := s.state.increment(ctx, counterStateful)
+	if err != nil {
+		return sharedStateError(err)
+	}
 	return textResult(strconv.FormatInt(value, 10))
 }
 
+func sharedStateError(err error) types.ToolsCallResult {
+	return toolErrorResult("shared state unavailable: " + err.Error())
+}
+
 func realisticLatency(ctx context.Context) types.ToolsCallResult {
 	delay := 50 + rand.Intn(100)
 	_ = sleepWithContext(ctx, time.Duration(delay)*time.Millisecond)
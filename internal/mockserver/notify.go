@@ -0,0 +1,217 @@
+package mockserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+// defaultResourceUpdateIntervalMs is how often subscribed resources are
+// reported updated when the behavior leaves it unset.
+const defaultResourceUpdateIntervalMs = 1000
+
+// notification is a JSON-RPC notification pushed to clients.
+type notification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// notificationStream is a client's open GET stream.
+type notificationStream struct {
+	session string
+	ch      chan notification
+}
+
+// notificationHub tracks the clients' open streams and resource
+// subscriptions, both by session. Clients of a server that is not strict
+// have no session and share the empty one.
+type notificationHub struct {
+	mu            sync.Mutex
+	streams       map[*notificationStream]struct{}
+	subscriptions map[string]map[string]struct{}
+	closed        chan struct{}
+	closeOnce     sync.Once
+}
+
+func newNotificationHub() *notificationHub {
+	return &notificationHub{
+		streams:       make(map[*notificationStream]struct{}),
+		subscriptions: make(map[string]map[string]struct{}),
+		closed:        make(chan struct{}),
+	}
+}
+
+func (h *notificationHub) open(session string) *notificationStream {
+	stream := &notificationStream{session: session, ch: make(chan notification, 64)}
+	h.mu.Lock()
+	h.streams[stream] = struct{}{}
+	h.mu.Unlock()
+	return stream
+}
+
+func (h *notificationHub) release(stream *notificationStream) {
+	h.mu.Lock()
+	delete(h.streams, stream)
+	h.mu.Unlock()
+}
+
+// close ends every open stream, so the server can shut down.
+func (h *notificationHub) close() {
+	h.closeOnce.Do(func() { close(h.closed) })
+}
+
+func (h *notificationHub) subscribe(session, uri string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	uris := h.subscriptions[session]
+	if uris == nil {
+		uris = make(map[string]struct{})
+		h.subscriptions[session] = uris
+	}
+	uris[uri] = struct{}{}
+}
+
+func (h *notificationHub) unsubscribe(session, uri string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.subscriptions[session], uri)
+	if len(h.subscriptions[session]) == 0 {
+		delete(h.subscriptions, session)
+	}
+}
+
+// forget drops the subscriptions of a terminated session.
+func (h *notificationHub) forget(session string) {
+	h.mu.Lock()
+	delete(h.subscriptions, session)
+	h.mu.Unlock()
+}
+
+// broadcast pushes n to every open stream.
+func (h *notificationHub) broadcast(n notification) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for stream := range h.streams {
+		stream.push(n)
+	}
+}
+
+// pushUpdates reports every subscribed resource updated to the streams of
+// the sessions subscribed to it.
+func (h *notificationHub) pushUpdates() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for stream := range h.streams {
+		for uri := range h.subscriptions[stream.session] {
+			stream.push(notification{
+				JSONRPC: "2.0",
+				Method:  "notifications/resources/updated",
+				Params:  map[string]interface{}{"uri": uri},
+			})
+		}
+	}
+}
+
+// push queues n on the stream, dropping it if the client is too slow to
+// keep up.
+func (s *notificationStream) push(n notification) {
+	select {
+	case s.ch <- n:
+	default:
+	}
+}
+
+// runNotifications pushes resource updates and list changes at the
+// intervals of the current behavior until ctx is done.
+func (s *mockServer) runNotifications(ctx context.Context) {
+	// Checking often rather than on a timer per interval lets a change of
+	// behavior take effect without waiting out the old interval.
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	lastUpdate, lastListChanged := time.Now(), time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			b := s.Behavior()
+			if now.Sub(lastUpdate) >= time.Duration(orDefault(b.ResourceUpdateIntervalMs, defaultResourceUpdateIntervalMs))*time.Millisecond {
+				s.notifier.pushUpdates()
+				lastUpdate = now
+			}
+			if b.ListChangedIntervalMs <= 0 {
+				lastListChanged = now
+			} else if now.Sub(lastListChanged) >= time.Duration(b.ListChangedIntervalMs)*time.Millisecond {
+				s.notifier.broadcast(notification{JSONRPC: "2.0", Method: "notifications/resources/list_changed"})
+				lastListChanged = now
+			}
+		}
+	}
+}
+
+// handleNotificationStream serves the GET stream on which the server
+// pushes notifications to a client.
+func (s *mockServer) handleNotificationStream(w http.ResponseWriter, r *http.Request) {
+	if !acceptsSSE(r) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	session := r.Header.Get(headerSessionID)
+	if s.cfg.Strict {
+		if _, ok := s.session(w, session); !ok {
+			return
+		}
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	stream := s.notifier.open(session)
+	defer s.notifier.release(stream)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.notifier.closed:
+			return
+		case n := <-stream.ch:
+			if !writeSSE(w, n) {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// handleResourcesSubscribe handles resources/subscribe and
+// resources/unsubscribe.
+func (s *mockServer) handleResourcesSubscribe(w http.ResponseWriter, r *http.Request, req types.JSONRPCRequest) {
+	var params types.ResourcesReadParams
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		writeJSONRPCError(w, req.ID, -32602, "invalid params")
+		return
+	}
+	if params.URI == "" {
+		writeJSONRPCError(w, req.ID, -32602, "missing uri parameter")
+		return
+	}
+	session := r.Header.Get(headerSessionID)
+	if req.Method == "resources/subscribe" {
+		s.notifier.subscribe(session, params.URI)
+	} else {
+		s.notifier.unsubscribe(session, params.URI)
+	}
+	writeJSONRPCResult(w, req.ID, map[string]interface{}{})
+}
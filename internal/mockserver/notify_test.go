@@ -0,0 +1,133 @@
+package mockserver
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+// openStream opens the server's notification stream and returns the
+// notifications read from it on a channel, closed when the stream ends.
+func openStream(t *testing.T, srv Server) <-chan notification {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, srv.MCPURL(), nil)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("open stream: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		t.Fatalf("expected the stream opened, got %s", resp.Status)
+	}
+	out := make(chan notification, 64)
+	go func() {
+		defer close(out)
+		defer resp.Body.Close()
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+			if !ok {
+				continue
+			}
+			var n notification
+			if json.Unmarshal([]byte(data), &n) == nil {
+				out <- n
+			}
+		}
+	}()
+	return out
+}
+
+// waitFor returns the first notification of method on the stream.
+func waitFor(t *testing.T, stream <-chan notification, method string) notification {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case n, ok := <-stream:
+			if !ok {
+				t.Fatalf("stream ended waiting for %s", method)
+			}
+			if n.Method == method {
+				return n
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for %s", method)
+		}
+	}
+}
+
+func TestNotifications(t *testing.T) {
+	srv, cleanup := StartTestServer()
+	stopped := false
+	defer func() {
+		if !stopped {
+			cleanup()
+		}
+	}()
+	if err := srv.SetBehavior(BehaviorProfile{ResourceUpdateIntervalMs: 20, ListChangedIntervalMs: 20}); err != nil {
+		t.Fatal(err)
+	}
+
+	var init struct {
+		Capabilities map[string]map[string]bool `json:"capabilities"`
+	}
+	_ = json.Unmarshal(callMCP(t, srv, "initialize", map[string]interface{}{}), &init)
+	if !init.Capabilities["resources"]["subscribe"] || !init.Capabilities["tools"]["listChanged"] {
+		t.Errorf("expected subscriptions and list changes advertised, got %v", init.Capabilities)
+	}
+
+	stream := openStream(t, srv)
+	callMCP(t, srv, "resources/subscribe", map[string]interface{}{"uri": "file:///docs/readme.md"})
+	updated := waitFor(t, stream, "notifications/resources/updated")
+	if params, _ := updated.Params.(map[string]interface{}); params["uri"] != "file:///docs/readme.md" {
+		t.Errorf("expected the subscribed resource updated, got %+v", updated)
+	}
+	waitFor(t, stream, "notifications/resources/list_changed")
+
+	if err := srv.RegisterTool("added", nil, func(context.Context, map[string]interface{}) (types.ToolsCallResult, error) {
+		return textResult("ok"), nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, stream, "notifications/tools/list_changed")
+
+	callMCP(t, srv, "resources/unsubscribe", map[string]interface{}{"uri": "file:///docs/readme.md"})
+
+	start := time.Now()
+	cleanup()
+	stopped = true
+	for range stream {
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected Stop to end open streams, took %v", elapsed)
+	}
+}
+
+func TestNotifications_StrictRequiresSession(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Strict = true
+	srv := New(cfg)
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop(t.Context())
+
+	req, _ := http.NewRequest(http.MethodGet, srv.MCPURL(), nil)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected a stream without a session rejected, got %d", resp.StatusCode)
+	}
+}
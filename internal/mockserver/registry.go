@@ -0,0 +1,126 @@
+package mockserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+// ToolHandler executes a registered tool. A returned error is reported to the
+// client as a tool error result (isError: true), not as a JSON-RPC error.
+type ToolHandler func(ctx context.Context, args map[string]interface{}) (types.ToolsCallResult, error)
+
+// defaultToolSchema is advertised for tools registered without a schema.
+var defaultToolSchema = json.RawMessage(`{"type":"object"}`)
+
+// builtinToolNames lists the tools every mock server provides, in the order
+// tools/list reports them.
+var builtinToolNames = []string{
+	"fast_echo", "slow_echo", "error_tool", "timeout_tool", "streaming_tool",
+	"json_transform", "text_processor", "list_operations",
+	"validate_email", "calculate", "hash_generator",
+	"weather_api", "geocode", "currency_convert",
+	"read_file", "write_file", "list_directory",
+	"large_payload", "random_latency", "conditional_error",
+	"degrading_performance", "flaky_connection", "rate_limited",
+	"circuit_breaker", "backpressure", "stateful_counter", "realistic_latency",
+}
+
+// customTool is a tool added with RegisterTool.
+type customTool struct {
+	schema  json.RawMessage
+	handler ToolHandler
+}
+
+// RegisterTool adds a tool to the server. Tools can be registered before or
+// after Start; registered tools are listed after the built-in tools, sorted by
+// name. Built-in tool names cannot be reused and each name can be registered
+// once. An empty schema is advertised as {"type":"object"}.
+func (s *mockServer) RegisterTool(name string, schema json.RawMessage, handler ToolHandler) error {
+	if name == "" {
+		return errors.New("tool name is required")
+	}
+	if handler == nil {
+		return fmt.Errorf("tool %q: handler is required", name)
+	}
+	if len(schema) == 0 {
+		schema = defaultToolSchema
+	} else if !json.Valid(schema) {
+		return fmt.Errorf("tool %q: input schema is not valid JSON", name)
+	}
+	for _, builtin := range builtinToolNames {
+		if name == builtin {
+			return fmt.Errorf("tool %q is a built-in tool", name)
+		}
+	}
+
+	s.toolsMu.Lock()
+	defer s.toolsMu.Unlock()
+	if _, exists := s.customTools[name]; exists {
+		return fmt.Errorf("tool %q is already registered", name)
+	}
+	if s.customTools == nil {
+		s.customTools = make(map[string]customTool)
+	}
+	s.customTools[name] = customTool{
+		schema:  append(json.RawMessage(nil), schema...),
+		handler: handler,
+	}
+	s.notifier.broadcast(notification{JSONRPC: "2.0", Method: "notifications/tools/list_changed"})
+	return nil
+}
+
+// customTool returns the registered tool with the given name.
+func (s *mockServer) customTool(name string) (customTool, bool) {
+	s.toolsMu.RLock()
+	defer s.toolsMu.RUnlock()
+	tool, ok := s.customTools[name]
+	return tool, ok
+}
+
+// executeCustomTool runs a registered tool. It reports false if no tool with
+// that name is registered.
+func (s *mockServer) executeCustomTool(ctx context.Context, name string, args map[string]interface{}) (types.ToolsCallResult, bool) {
+	tool, ok := s.customTool(name)
+	if !ok {
+		return types.ToolsCallResult{}, false
+	}
+	result, err := tool.handler(ctx, args)
+	if err != nil {
+		return toolErrorResult(err.Error()), true
+	}
+	return result, true
+}
+
+// buildToolsList returns the built-in tools followed by the registered ones.
+func (s *mockServer) buildToolsList() []types.Tool {
+	s.toolsMu.RLock()
+	defer s.toolsMu.RUnlock()
+
+	tools := make([]types.Tool, 0, len(builtinToolNames)+len(s.customTools))
+	for _, name := range builtinToolNames {
+		tools = append(tools, types.Tool{
+			Name:        name,
+			Description: "mock tool",
+			InputSchema: defaultToolSchema,
+		})
+	}
+
+	custom := make([]string, 0, len(s.customTools))
+	for name := range s.customTools {
+		custom = append(custom, name)
+	}
+	sort.Strings(custom)
+	for _, name := range custom {
+		tools = append(tools, types.Tool{
+			Name:        name,
+			Description: "custom mock tool",
+			InputSchema: s.customTools[name].schema,
+		})
+	}
+	return tools
+}
@@ -0,0 +1,122 @@
+package mockserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+func callMCP(t *testing.T, srv Server, method string, params interface{}) json.RawMessage {
+	t.Helper()
+	rawParams, err := json.Marshal(params)
+	if err != nil {
+		t.Fatalf("marshal params: %v", err)
+	}
+	body, _ := json.Marshal(types.JSONRPCRequest{JSONRPC: "2.0", ID: "1", Method: method, Params: rawParams})
+	resp, err := http.Post(srv.MCPURL(), "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("post %s: %v", method, err)
+	}
+	defer resp.Body.Close()
+
+	var rpc struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&rpc); err != nil {
+		t.Fatalf("decode %s response: %v", method, err)
+	}
+	if rpc.Error != nil {
+		t.Fatalf("%s returned error: %s", method, rpc.Error.Message)
+	}
+	return rpc.Result
+}
+
+func TestRegisterTool_ListAndCall(t *testing.T) {
+	srv, cleanup := StartTestServer()
+	defer cleanup()
+
+	schema := json.RawMessage(`{"type":"object","properties":{"n":{"type":"number"}},"required":["n"]}`)
+	err := srv.RegisterTool("double", schema, func(ctx context.Context, args map[string]interface{}) (types.ToolsCallResult, error) {
+		n, ok := getFloatArg(args, "n")
+		if !ok {
+			return types.ToolsCallResult{}, errors.New("missing n")
+		}
+		return textResult(formatFloat(n * 2)), nil
+	})
+	if err != nil {
+		t.Fatalf("RegisterTool failed: %v", err)
+	}
+
+	var list types.ToolsListResult
+	if err := json.Unmarshal(callMCP(t, srv, "tools/list", map[string]interface{}{}), &list); err != nil {
+		t.Fatalf("decode tools/list: %v", err)
+	}
+	if len(list.Tools) != len(builtinToolNames)+1 {
+		t.Fatalf("expected %d tools, got %d", len(builtinToolNames)+1, len(list.Tools))
+	}
+	last := list.Tools[len(list.Tools)-1]
+	if last.Name != "double" || string(last.InputSchema) != string(schema) {
+		t.Errorf("unexpected registered tool in list: %s %s", last.Name, last.InputSchema)
+	}
+
+	var result types.ToolsCallResult
+	raw := callMCP(t, srv, "tools/call", types.ToolsCallParams{Name: "double", Arguments: map[string]interface{}{"n": 21}})
+	if err := json.Unmarshal(raw, &result); err != nil {
+		t.Fatalf("decode tools/call: %v", err)
+	}
+	if result.IsError || len(result.Content) != 1 || result.Content[0].Text != "42" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	raw = callMCP(t, srv, "tools/call", types.ToolsCallParams{Name: "double", Arguments: map[string]interface{}{}})
+	if err := json.Unmarshal(raw, &result); err != nil {
+		t.Fatalf("decode tools/call: %v", err)
+	}
+	if !result.IsError || result.Content[0].Text != "missing n" {
+		t.Errorf("expected handler error as tool error, got %+v", result)
+	}
+}
+
+func TestRegisterTool_Errors(t *testing.T) {
+	srv := New(nil)
+	handler := func(ctx context.Context, args map[string]interface{}) (types.ToolsCallResult, error) {
+		return textResult("ok"), nil
+	}
+
+	if err := srv.RegisterTool("custom", nil, handler); err != nil {
+		t.Fatalf("RegisterTool failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		toolName string
+		schema   json.RawMessage
+		handler  ToolHandler
+	}{
+		{"empty name", "", nil, handler},
+		{"nil handler", "other", nil, nil},
+		{"invalid schema", "other", json.RawMessage(`{"type":`), handler},
+		{"built-in name", "fast_echo", nil, handler},
+		{"duplicate", "custom", nil, handler},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := srv.RegisterTool(tt.toolName, tt.schema, tt.handler); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+
+	tools := srv.(*mockServer).buildToolsList()
+	if got := tools[len(tools)-1]; got.Name != "custom" || string(got.InputSchema) != `{"type":"object"}` {
+		t.Errorf("expected default schema for custom tool, got %s %s", got.Name, got.InputSchema)
+	}
+}
@@ -0,0 +1,176 @@
+package mockserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// StatePath is where a mock server serves the state of its stateful tools
+// to the servers that share it.
+const StatePath = "/admin/state"
+
+// Counters of the stateful tools.
+const (
+	counterStateful  = "stateful_counter"
+	counterDegrading = "degrading_performance"
+)
+
+// stateStore holds the state of the stateful tools: their counters, the
+// rate_limited quota and the circuit_breaker circuit.
+type stateStore interface {
+	// increment adds one to a counter and returns its new value.
+	increment(ctx context.Context, counter string) (int64, error)
+	// allowRate takes a call from the rate limit quota and reports whether
+	// there was one left.
+	allowRate(ctx context.Context) (bool, error)
+	// passCircuit records a call of the circuit breaker, failed or not, and
+	// reports whether the circuit let it through.
+	passCircuit(ctx context.Context, fail bool) (bool, error)
+}
+
+// localState is the state of the server itself.
+type localState struct {
+	s *mockServer
+}
+
+func (l localState) increment(_ context.Context, counter string) (int64, error) {
+	switch counter {
+	case counterStateful:
+		return l.s.stateCounter.Add(1), nil
+	case counterDegrading:
+		return l.s.degradeCount.Add(1), nil
+	}
+	return 0, fmt.Errorf("unknown counter %q", counter)
+}
+
+func (l localState) allowRate(context.Context) (bool, error) {
+	l.s.mu.Lock()
+	limiter := l.s.rateLimiter
+	l.s.mu.Unlock()
+	return limiter.Allow(), nil
+}
+
+func (l localState) passCircuit(_ context.Context, fail bool) (bool, error) {
+	s := l.s
+	behavior := s.Behavior()
+	threshold := orDefault(behavior.CircuitFailureThreshold, defaultCircuitFailureThreshold)
+	openFor := time.Duration(orDefault(behavior.CircuitOpenMs, defaultCircuitOpenMs)) * time.Millisecond
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if time.Now().Before(s.circuitOpenTo) {
+		return false, nil
+	}
+	if fail {
+		s.circuitFails++
+	} else {
+		s.circuitFails = 0
+	}
+	if s.circuitFails >= threshold {
+		s.circuitOpenTo = time.Now().Add(openFor)
+		s.circuitFails = 0
+		return false, nil
+	}
+	return true, nil
+}
+
+// stateRequest is a state operation sent to StatePath.
+type stateRequest struct {
+	Op      string `json:"op"`
+	Counter string `json:"counter,omitempty"`
+	Fail    bool   `json:"fail,omitempty"`
+}
+
+type stateResponse struct {
+	Value   int64 `json:"value,omitempty"`
+	Allowed bool  `json:"allowed,omitempty"`
+}
+
+// remoteState is the state of another mock server, reached at its
+// StatePath.
+type remoteState struct {
+	url    string
+	client *http.Client
+}
+
+func newRemoteState(baseURL string) *remoteState {
+	return &remoteState{
+		url:    strings.TrimSuffix(baseURL, "/") + StatePath,
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (r *remoteState) do(ctx context.Context, op stateRequest) (stateResponse, error) {
+	var out stateResponse
+	body, _ := json.Marshal(op)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, bytes.NewReader(body))
+	if err != nil {
+		return out, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return out, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return out, fmt.Errorf("%s: %s", r.url, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return out, fmt.Errorf("%s: %w", r.url, err)
+	}
+	return out, nil
+}
+
+func (r *remoteState) increment(ctx context.Context, counter string) (int64, error) {
+	resp, err := r.do(ctx, stateRequest{Op: "increment", Counter: counter})
+	return resp.Value, err
+}
+
+func (r *remoteState) allowRate(ctx context.Context) (bool, error) {
+	resp, err := r.do(ctx, stateRequest{Op: "allow_rate"})
+	return resp.Allowed, err
+}
+
+func (r *remoteState) passCircuit(ctx context.Context, fail bool) (bool, error) {
+	resp, err := r.do(ctx, stateRequest{Op: "pass_circuit", Fail: fail})
+	return resp.Allowed, err
+}
+
+// handleState applies the state operations of the servers sharing this
+// one's state.
+func (s *mockServer) handleState(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var op stateRequest
+	if err := json.NewDecoder(r.Body).Decode(&op); err != nil {
+		http.Error(w, "invalid state operation: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	var resp stateResponse
+	var err error
+	switch op.Op {
+	case "increment":
+		resp.Value, err = s.state.increment(r.Context(), op.Counter)
+	case "allow_rate":
+		resp.Allowed, err = s.state.allowRate(r.Context())
+	case "pass_circuit":
+		resp.Allowed, err = s.state.passCircuit(r.Context(), op.Fail)
+	default:
+		err = fmt.Errorf("unknown state operation %q", op.Op)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,68 @@
+package mockserver
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+func startServer(t *testing.T, cfg *Config) Server {
+	t.Helper()
+	srv := New(cfg)
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { srv.Stop(context.Background()) })
+	return srv
+}
+
+func callTool(t *testing.T, srv Server, name string, args map[string]interface{}) types.ToolsCallResult {
+	t.Helper()
+	var result types.ToolsCallResult
+	if err := json.Unmarshal(callMCP(t, srv, "tools/call", map[string]interface{}{"name": name, "arguments": args}), &result); err != nil {
+		t.Fatalf("decode %s result: %v", name, err)
+	}
+	return result
+}
+
+func TestSharedState(t *testing.T) {
+	hub := startServer(t, DefaultConfig())
+	if err := hub.SetBehavior(BehaviorProfile{RateLimitQuota: 2, RateLimitWindowMs: 60000, CircuitFailureThreshold: 2, CircuitOpenMs: 60000}); err != nil {
+		t.Fatal(err)
+	}
+	var cluster []Server
+	for i := 0; i < 2; i++ {
+		cfg := DefaultConfig()
+		cfg.StateURL = "http://" + hub.Addr()
+		cluster = append(cluster, startServer(t, cfg))
+	}
+	a, b := cluster[0], cluster[1]
+
+	for i, srv := range []Server{a, b, a} {
+		if got := callTool(t, srv, "stateful_counter", nil); got.Content[0].Text != []string{"1", "2", "3"}[i] {
+			t.Errorf("call %d: expected the counter shared, got %+v", i+1, got)
+		}
+	}
+
+	if callTool(t, a, "rate_limited", nil).IsError || callTool(t, b, "rate_limited", nil).IsError {
+		t.Error("expected the first two calls within the shared quota")
+	}
+	if !callTool(t, a, "rate_limited", nil).IsError {
+		t.Error("expected the third call across the cluster rate limited")
+	}
+
+	callTool(t, a, "circuit_breaker", map[string]interface{}{"force_error": true})
+	callTool(t, b, "circuit_breaker", map[string]interface{}{"force_error": true})
+	if result := callTool(t, a, "circuit_breaker", nil); !result.IsError || result.Content[0].Text != "circuit open" {
+		t.Errorf("expected failures on both servers to open the shared circuit, got %+v", result)
+	}
+
+	cfg := DefaultConfig()
+	cfg.StateURL = "http://127.0.0.1:1"
+	orphan := startServer(t, cfg)
+	if result := callTool(t, orphan, "stateful_counter", nil); !result.IsError {
+		t.Errorf("expected an error without the shared state, got %+v", result)
+	}
+}
@@ -0,0 +1,137 @@
+package mockserver
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+// ServeStdio serves MCP over the stdio transport: newline-delimited JSON-RPC
+// messages read from in, with responses and notifications written to out,
+// until in ends or ctx is done. Requests are handled concurrently, as over
+// HTTP, so responses may come out of order. Faults the behavior injects as
+// HTTP errors come back as JSON-RPC errors.
+func (s *mockServer) ServeStdio(ctx context.Context, in io.Reader, out io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	w := &stdioWriter{out: out}
+
+	if s.cfg.timeline != nil {
+		if err := s.startTimeline(s.cfg.timeline); err != nil {
+			return fmt.Errorf("timeline: %w", err)
+		}
+		defer s.timeline.stop()
+	}
+
+	go s.runNotifications(ctx)
+	stream := s.notifier.open("")
+	defer s.notifier.release(stream)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case n := <-stream.ch:
+				data, _ := json.Marshal(n)
+				w.write(data)
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	reader := bufio.NewReader(in)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if msg := bytes.TrimSpace(line); len(msg) > 0 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.serveStdioMessage(ctx, w, msg)
+			}()
+		}
+		if err == io.EOF || ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// serveStdioMessage handles a message as the HTTP endpoint would and
+// writes the response, if any, as a line.
+func (s *mockServer) serveStdioMessage(ctx context.Context, w *stdioWriter, msg []byte) {
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, "/mcp", bytes.NewReader(msg))
+	if err != nil {
+		return
+	}
+	r.Header.Set("Content-Type", "application/json")
+	resp := &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+	s.handleMCP(resp, r)
+
+	var envelope struct {
+		ID json.RawMessage `json:"id"`
+	}
+	if json.Unmarshal(msg, &envelope) == nil && (len(envelope.ID) == 0 || string(envelope.ID) == "null") {
+		// Notifications get no response.
+		return
+	}
+	if resp.status == http.StatusOK {
+		w.write(bytes.TrimSpace(resp.body.Bytes()))
+		return
+	}
+	var id interface{}
+	_ = json.Unmarshal(envelope.ID, &id)
+	data, _ := json.Marshal(types.JSONRPCResponse{
+		JSONRPC: "2.0",
+		ID:      id,
+		Error: &types.JSONRPCError{
+			Code:    -32000,
+			Message: fmt.Sprintf("HTTP %d: %s", resp.status, strings.TrimSpace(resp.body.String())),
+		},
+	})
+	w.write(data)
+}
+
+// stdioWriter writes messages to the stdio transport one line at a time.
+type stdioWriter struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func (w *stdioWriter) write(msg []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, _ = w.out.Write(append(msg, '\n'))
+}
+
+// bufferedResponse is an http.ResponseWriter that keeps the response.
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+	wrote  bool
+}
+
+func (b *bufferedResponse) Header() http.Header { return b.header }
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if !b.wrote {
+		b.status = status
+		b.wrote = true
+	}
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	b.wrote = true
+	return b.body.Write(p)
+}
@@ -0,0 +1,79 @@
+package mockserver
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+func TestServeStdio(t *testing.T) {
+	srv := New(DefaultConfig())
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	done := make(chan error, 1)
+	go func() { done <- srv.ServeStdio(context.Background(), inR, outW) }()
+
+	responses := make(chan types.JSONRPCResponse, 16)
+	go func() {
+		scanner := bufio.NewScanner(outR)
+		for scanner.Scan() {
+			var resp types.JSONRPCResponse
+			if err := json.Unmarshal(scanner.Bytes(), &resp); err == nil && resp.ID != nil {
+				responses <- resp
+			}
+		}
+	}()
+	send := func(msg string) {
+		if _, err := io.WriteString(inW, msg+"\n"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	receive := func() types.JSONRPCResponse {
+		select {
+		case resp := <-responses:
+			return resp
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for a response")
+		}
+		return types.JSONRPCResponse{}
+	}
+
+	send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-11-25"}}`)
+	if resp := receive(); resp.Error != nil || !strings.Contains(string(resp.Result), "2025-11-25") {
+		t.Fatalf("unexpected initialize response: %+v", resp)
+	}
+	// The notification gets no response, so the next one read is the call's.
+	send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	send(`{"jsonrpc":"2.0","id":"call","method":"tools/call","params":{"name":"fast_echo","arguments":{"message":"hi"}}}`)
+	resp := receive()
+	var result types.ToolsCallResult
+	_ = json.Unmarshal(resp.Result, &result)
+	if resp.ID != "call" || len(result.Content) == 0 || result.Content[0].Text != "echo: hi" {
+		t.Fatalf("unexpected tools/call response: %+v", resp)
+	}
+
+	if err := srv.SetBehavior(BehaviorProfile{HTTPErrorRate: 1}); err != nil {
+		t.Fatal(err)
+	}
+	send(`{"jsonrpc":"2.0","id":2,"method":"ping"}`)
+	if resp := receive(); resp.Error == nil || !strings.Contains(resp.Error.Message, "503") {
+		t.Errorf("expected the injected failure as a JSON-RPC error, got %+v", resp)
+	}
+
+	inW.Close()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("ServeStdio failed: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected ServeStdio to return at the end of input")
+	}
+	outW.Close()
+}
@@ -0,0 +1,149 @@
+package mockserver
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/bc-dunia/mcpdrill/internal/mcp"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+// Headers of the streamable HTTP transport a strict server checks.
+const (
+	headerSessionID       = "Mcp-Session-Id"
+	headerProtocolVersion = "MCP-Protocol-Version"
+)
+
+// strictSession is a session issued by a strict server.
+type strictSession struct {
+	protocolVersion string
+	initialized     bool
+}
+
+func newSessionID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// checkStrict enforces the protocol rules of a strict server on a request
+// that is not initialize: the Accept header, the session and protocol
+// version headers, the initialized notification, and that notifications
+// have no id and get no response. It returns false if it answered the
+// request.
+func (s *mockServer) checkStrict(w http.ResponseWriter, r *http.Request, req types.JSONRPCRequest) bool {
+	if !acceptsJSONAndSSE(r) {
+		http.Error(w, "Accept must list application/json and text/event-stream", http.StatusNotAcceptable)
+		return false
+	}
+	version := r.Header.Get(headerProtocolVersion)
+	if version != "" && !mcp.IsSupported(version) {
+		http.Error(w, fmt.Sprintf("unsupported %s %q", headerProtocolVersion, version), http.StatusBadRequest)
+		return false
+	}
+	notification := req.ID == nil
+	if req.Method == "initialize" {
+		if notification {
+			http.Error(w, "initialize must be a request", http.StatusBadRequest)
+			return false
+		}
+		return true
+	}
+
+	session, ok := s.session(w, r.Header.Get(headerSessionID))
+	if !ok {
+		return false
+	}
+	s.sessionsMu.Lock()
+	if notification && req.Method == "notifications/initialized" {
+		session.initialized = true
+	}
+	initialized := session.initialized
+	s.sessionsMu.Unlock()
+	if version != "" && version != session.protocolVersion {
+		http.Error(w, fmt.Sprintf("%s %q does not match the negotiated %q", headerProtocolVersion, version, session.protocolVersion), http.StatusBadRequest)
+		return false
+	}
+
+	isNotificationMethod := strings.HasPrefix(req.Method, "notifications/")
+	switch {
+	case notification && !isNotificationMethod:
+		http.Error(w, fmt.Sprintf("request %s has no id", req.Method), http.StatusBadRequest)
+		return false
+	case notification:
+		w.WriteHeader(http.StatusAccepted)
+		return false
+	case isNotificationMethod:
+		writeJSONRPCError(w, req.ID, -32600, fmt.Sprintf("notification %s must not have an id", req.Method))
+		return false
+	case !initialized && req.Method != "ping":
+		writeJSONRPCError(w, req.ID, -32600, "session is not initialized: send notifications/initialized first")
+		return false
+	}
+	return true
+}
+
+// session returns the session of a strict server that id names. It returns
+// false if it answered the request because there is none.
+func (s *mockServer) session(w http.ResponseWriter, id string) (*strictSession, bool) {
+	if id == "" {
+		http.Error(w, "missing "+headerSessionID, http.StatusBadRequest)
+		return nil, false
+	}
+	s.sessionsMu.Lock()
+	session, ok := s.sessions[id]
+	s.sessionsMu.Unlock()
+	if !ok {
+		http.Error(w, "unknown or terminated session", http.StatusNotFound)
+		return nil, false
+	}
+	return session, true
+}
+
+// startSession negotiates the protocol version of a strict server's
+// initialize and issues a session for it. It returns false if it answered
+// the request.
+func (s *mockServer) startSession(w http.ResponseWriter, id interface{}, version string) bool {
+	if !mcp.IsSupported(version) {
+		writeJSONRPCError(w, id, -32602, fmt.Sprintf("unsupported protocol version %q: supported are %s",
+			version, strings.Join(mcp.SupportedProtocolVersions, ", ")))
+		return false
+	}
+	sessionID := newSessionID()
+	s.sessionsMu.Lock()
+	if s.sessions == nil {
+		s.sessions = make(map[string]*strictSession)
+	}
+	s.sessions[sessionID] = &strictSession{protocolVersion: version}
+	s.sessionsMu.Unlock()
+	w.Header().Set(headerSessionID, sessionID)
+	return true
+}
+
+// handleSessionDelete terminates the session of a strict server a client
+// is done with.
+func (s *mockServer) handleSessionDelete(w http.ResponseWriter, r *http.Request) {
+	id := r.Header.Get(headerSessionID)
+	if id == "" {
+		http.Error(w, "missing "+headerSessionID, http.StatusBadRequest)
+		return
+	}
+	s.sessionsMu.Lock()
+	_, ok := s.sessions[id]
+	delete(s.sessions, id)
+	s.sessionsMu.Unlock()
+	if !ok {
+		http.Error(w, "unknown or terminated session", http.StatusNotFound)
+		return
+	}
+	s.notifier.forget(id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func acceptsJSONAndSSE(r *http.Request) bool {
+	accept := r.Header.Get("Accept")
+	return strings.Contains(accept, "application/json") && strings.Contains(accept, "text/event-stream")
+}
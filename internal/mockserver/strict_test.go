@@ -0,0 +1,124 @@
+package mockserver
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type strictClient struct {
+	t       *testing.T
+	url     string
+	session string
+	version string
+}
+
+// send posts a JSON-RPC message and returns the status and the error
+// message of a JSON-RPC error, if any.
+func (c *strictClient) send(method string, id interface{}, params string) (int, string) {
+	c.t.Helper()
+	msg := map[string]interface{}{"jsonrpc": "2.0", "method": method}
+	if id != nil {
+		msg["id"] = id
+	}
+	if params != "" {
+		msg["params"] = json.RawMessage(params)
+	}
+	body, _ := json.Marshal(msg)
+	req, _ := http.NewRequest(http.MethodPost, c.url, strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	if c.session != "" {
+		req.Header.Set(headerSessionID, c.session)
+	}
+	if c.version != "" {
+		req.Header.Set(headerProtocolVersion, c.version)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Fatalf("post %s: %v", method, err)
+	}
+	defer resp.Body.Close()
+	if id := resp.Header.Get(headerSessionID); id != "" {
+		c.session = id
+	}
+	raw, _ := io.ReadAll(resp.Body)
+	var rpc struct {
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(raw, &rpc) == nil && rpc.Error != nil {
+		return resp.StatusCode, rpc.Error.Message
+	}
+	return resp.StatusCode, ""
+}
+
+func TestStrictMode(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Strict = true
+	srv := New(cfg)
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop(context.Background())
+	c := &strictClient{t: t, url: srv.MCPURL()}
+
+	if status, _ := c.send("tools/list", 1, ""); status != http.StatusBadRequest {
+		t.Errorf("expected 400 without a session, got %d", status)
+	}
+	if _, msg := c.send("initialize", 1, `{"protocolVersion": "1999-01-01"}`); !strings.Contains(msg, "unsupported protocol version") {
+		t.Errorf("expected an unknown protocol version rejected, got %q", msg)
+	}
+	if c.session != "" {
+		t.Fatal("expected no session for a rejected initialize")
+	}
+	if status, msg := c.send("initialize", 1, `{"protocolVersion": "2025-11-25"}`); status != http.StatusOK || msg != "" || c.session == "" {
+		t.Fatalf("expected a session from initialize, got %d %q", status, msg)
+	}
+
+	if _, msg := c.send("tools/list", 2, ""); !strings.Contains(msg, "not initialized") {
+		t.Errorf("expected requests before notifications/initialized rejected, got %q", msg)
+	}
+	if status, msg := c.send("ping", 3, ""); status != http.StatusOK || msg != "" {
+		t.Errorf("expected ping allowed before initialized, got %d %q", status, msg)
+	}
+	if _, msg := c.send("notifications/initialized", 4, ""); !strings.Contains(msg, "must not have an id") {
+		t.Errorf("expected a notification with an id rejected, got %q", msg)
+	}
+	if status, _ := c.send("notifications/initialized", nil, ""); status != http.StatusAccepted {
+		t.Errorf("expected 202 for a notification, got %d", status)
+	}
+	if status, _ := c.send("tools/list", nil, ""); status != http.StatusBadRequest {
+		t.Errorf("expected a request without an id rejected, got %d", status)
+	}
+	if status, msg := c.send("tools/list", 5, ""); status != http.StatusOK || msg != "" {
+		t.Errorf("expected tools/list to succeed, got %d %q", status, msg)
+	}
+
+	c.version = "2025-03-26"
+	if status, _ := c.send("tools/list", 6, ""); status != http.StatusBadRequest {
+		t.Errorf("expected a protocol version other than the negotiated one rejected, got %d", status)
+	}
+	c.version = "2025-11-25"
+	if status, _ := c.send("tools/list", 7, ""); status != http.StatusOK {
+		t.Errorf("expected the negotiated protocol version accepted, got %d", status)
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, c.url, nil)
+	req.Header.Set(headerSessionID, c.session)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("expected the session terminated, got %d", resp.StatusCode)
+	}
+	if status, _ := c.send("tools/list", 8, ""); status != http.StatusNotFound {
+		t.Errorf("expected 404 for a terminated session, got %d", status)
+	}
+}
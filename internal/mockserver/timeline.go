@@ -0,0 +1,212 @@
+package mockserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/validation"
+)
+
+// TimelinePath is where the mock server reports the phase of its timeline.
+const TimelinePath = "/admin/timeline"
+
+// Timeline is a script of behaviors the server goes through from the moment
+// it starts, so a run against it meets the same faults at the same times.
+type Timeline struct {
+	Phases []TimelinePhase `json:"phases"`
+	// Repeat starts the timeline over after its last phase. Otherwise the
+	// server returns to the behavior it started with, unless the last phase
+	// has no duration and lasts until the server stops.
+	Repeat bool `json:"repeat,omitempty"`
+}
+
+// TimelinePhase is a behavior held for DurationMs. Behavior holds the fields
+// that differ from the behavior the server started with, as for a PATCH of
+// BehaviorPath; a phase without it runs with the startup behavior.
+type TimelinePhase struct {
+	Name       string          `json:"name,omitempty"`
+	DurationMs int64           `json:"duration_ms"`
+	Behavior   json.RawMessage `json:"behavior,omitempty"`
+}
+
+// TimelineStatus is the progress of a running timeline.
+type TimelineStatus struct {
+	Phase     string `json:"phase"`
+	Index     int    `json:"index"`
+	Cycle     int    `json:"cycle"`
+	ElapsedMs int64  `json:"elapsed_ms"`
+	// Done is set once a timeline that does not repeat is over.
+	Done bool `json:"done,omitempty"`
+}
+
+// ParseTimeline reads a timeline from JSON or YAML and validates it.
+func ParseTimeline(data []byte) (*Timeline, error) {
+	doc := data
+	if !json.Valid(data) {
+		parsed, err := validation.ParseYAML(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeline: %w", err)
+		}
+		doc = parsed.JSON
+	}
+	var t Timeline
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&t); err != nil {
+		return nil, fmt.Errorf("invalid timeline: %w", err)
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// LoadTimeline reads a timeline from a JSON or YAML file.
+func LoadTimeline(path string) (*Timeline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTimeline(data)
+}
+
+// Validate checks the phases' durations and behaviors.
+func (t *Timeline) Validate() error {
+	if len(t.Phases) == 0 {
+		return errors.New("timeline has no phases")
+	}
+	for i, p := range t.Phases {
+		if p.DurationMs < 0 {
+			return fmt.Errorf("phase %s: duration_ms must not be negative", t.phaseName(i))
+		}
+		if p.DurationMs == 0 && (i < len(t.Phases)-1 || t.Repeat) {
+			return fmt.Errorf("phase %s: duration_ms is required but on the last phase of a timeline that does not repeat", t.phaseName(i))
+		}
+		if _, err := p.resolve(BehaviorProfile{}); err != nil {
+			return fmt.Errorf("phase %s: %w", t.phaseName(i), err)
+		}
+	}
+	return nil
+}
+
+func (t *Timeline) phaseName(i int) string {
+	if name := t.Phases[i].Name; name != "" {
+		return name
+	}
+	return fmt.Sprintf("%d", i+1)
+}
+
+// resolve returns the phase's behavior applied to base.
+func (p *TimelinePhase) resolve(base BehaviorProfile) (BehaviorProfile, error) {
+	b := base.clone()
+	if len(p.Behavior) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(p.Behavior))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&b); err != nil {
+			return b, fmt.Errorf("invalid behavior: %w", err)
+		}
+	}
+	if err := b.Validate(); err != nil {
+		return b, err
+	}
+	return b, nil
+}
+
+// timelineRunner applies the phases of a timeline to a server as time goes.
+type timelineRunner struct {
+	timeline  *Timeline
+	behaviors []BehaviorProfile
+	cancel    context.CancelFunc
+	done      chan struct{}
+
+	mu      sync.Mutex
+	started time.Time
+	status  TimelineStatus
+}
+
+// startTimeline resolves the timeline against the startup behavior and runs
+// it until the timeline ends or the server stops.
+func (s *mockServer) startTimeline(t *Timeline) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	behaviors := make([]BehaviorProfile, len(t.Phases))
+	for i := range t.Phases {
+		b, err := t.Phases[i].resolve(s.cfg.behavior)
+		if err != nil {
+			return fmt.Errorf("phase %s: %w", t.phaseName(i), err)
+		}
+		behaviors[i] = b
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &timelineRunner{timeline: t, behaviors: behaviors, cancel: cancel, done: make(chan struct{}), started: time.Now()}
+	s.timeline = r
+	go func() {
+		defer close(r.done)
+		r.run(ctx, s)
+	}()
+	return nil
+}
+
+func (r *timelineRunner) run(ctx context.Context, s *mockServer) {
+	// Each phase starts at its offset from the start rather than when the
+	// previous one ended, so the schedule does not drift.
+	next := r.started
+	for cycle := 1; ; cycle++ {
+		for i, phase := range r.timeline.Phases {
+			r.mu.Lock()
+			r.status = TimelineStatus{Phase: r.timeline.phaseName(i), Index: i, Cycle: cycle}
+			r.mu.Unlock()
+			_ = s.SetBehavior(r.behaviors[i])
+			if phase.DurationMs == 0 {
+				return
+			}
+			next = next.Add(time.Duration(phase.DurationMs) * time.Millisecond)
+			if !sleepWithContext(ctx, time.Until(next)) {
+				return
+			}
+		}
+		if !r.timeline.Repeat {
+			break
+		}
+	}
+	r.mu.Lock()
+	r.status.Done = true
+	r.mu.Unlock()
+	_ = s.SetBehavior(s.cfg.behavior)
+}
+
+func (r *timelineRunner) stop() {
+	r.cancel()
+	<-r.done
+}
+
+func (r *timelineRunner) currentStatus() TimelineStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	status := r.status
+	status.ElapsedMs = time.Since(r.started).Milliseconds()
+	return status
+}
+
+// handleTimeline reports the progress of the server's timeline.
+func (s *mockServer) handleTimeline(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if s.timeline == nil {
+		http.Error(w, "no timeline is running", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(s.timeline.currentStatus())
+}
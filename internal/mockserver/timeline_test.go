@@ -0,0 +1,126 @@
+package mockserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTimeline(t *testing.T) {
+	yaml := `
+phases:
+  - name: healthy
+    duration_ms: 300000
+  - name: errors
+    duration_ms: 120000
+    behavior:
+      http_error_rate: 0.2
+      http_error_status: 500
+  - name: slow
+    duration_ms: 180000
+    behavior:
+      latency: {distribution: fixed, mean_ms: 300}
+`
+	timeline, err := ParseTimeline([]byte(yaml))
+	if err != nil {
+		t.Fatalf("ParseTimeline failed: %v", err)
+	}
+	if len(timeline.Phases) != 3 || timeline.Phases[1].DurationMs != 120000 {
+		t.Fatalf("unexpected timeline: %+v", timeline)
+	}
+	b, err := timeline.Phases[1].resolve(DefaultConfig().behavior)
+	if err != nil || b.HTTPErrorRate != 0.2 || b.HTTPErrorStatus != 500 || b.StreamingChunkCount != 5 {
+		t.Errorf("expected the phase applied over the startup behavior, got %+v (%v)", b, err)
+	}
+
+	for name, doc := range map[string]string{
+		"no phases":        `{"phases": []}`,
+		"open-ended phase": `{"phases": [{"duration_ms": 0}, {"duration_ms": 10}]}`,
+		"open-ended loop":  `{"repeat": true, "phases": [{"duration_ms": 0}]}`,
+		"bad behavior":     `{"phases": [{"duration_ms": 10, "behavior": {"tool_error_rate": 2}}]}`,
+		"unknown field":    `{"phases": [{"duration_ms": 10, "behavior": {"error_rate": 0.5}}]}`,
+	} {
+		if _, err := ParseTimeline([]byte(doc)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if _, err := ParseTimeline([]byte(`{"phases": [{"duration_ms": 10}, {"duration_ms": 0}]}`)); err != nil {
+		t.Errorf("expected an open-ended last phase to be allowed, got %v", err)
+	}
+}
+
+func timelineStatus(t *testing.T, srv Server) TimelineStatus {
+	t.Helper()
+	resp, err := http.Get("http://" + srv.Addr() + TimelinePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var status TimelineStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatalf("decode timeline status: %v", err)
+	}
+	return status
+}
+
+func TestTimeline_Run(t *testing.T) {
+	timeline, err := ParseTimeline([]byte(`{"phases": [
+		{"name": "healthy", "duration_ms": 100},
+		{"name": "failing", "duration_ms": 200, "behavior": {"tool_error_rate": 1}}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := DefaultConfig()
+	cfg.SetTimeline(timeline)
+	srv := New(cfg)
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop(context.Background())
+
+	if status := timelineStatus(t, srv); status.Phase != "healthy" || status.Cycle != 1 {
+		t.Errorf("expected the first phase, got %+v", status)
+	}
+	if result := callEcho(t, srv); result.IsError {
+		t.Errorf("expected no error while healthy, got %+v", result)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	if status := timelineStatus(t, srv); status.Phase != "failing" || status.Index != 1 {
+		t.Errorf("expected the second phase, got %+v", status)
+	}
+	if result := callEcho(t, srv); !result.IsError {
+		t.Errorf("expected an injected error while failing, got %+v", result)
+	}
+
+	time.Sleep(250 * time.Millisecond)
+	if status := timelineStatus(t, srv); !status.Done {
+		t.Errorf("expected the timeline to be over, got %+v", status)
+	}
+	if b := srv.Behavior(); b.ToolErrorRate != 0 || b.StreamingChunkCount != 5 {
+		t.Errorf("expected the startup behavior back, got %+v", b)
+	}
+}
+
+func TestTimeline_NotRunning(t *testing.T) {
+	srv, cleanup := StartTestServer()
+	defer cleanup()
+	resp, err := http.Get("http://" + srv.Addr() + TimelinePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 without a timeline, got %d", resp.StatusCode)
+	}
+
+	cfg := DefaultConfig()
+	cfg.SetTimeline(&Timeline{Phases: []TimelinePhase{{DurationMs: 10, Behavior: json.RawMessage(`{"latency": {"distribution": "none"}}`)}}})
+	if err := New(cfg).Start(); err == nil || !strings.Contains(err.Error(), "timeline") {
+		t.Errorf("expected an invalid timeline to fail Start, got %v", err)
+	}
+}
@@ -62,6 +62,29 @@ func ExtractContext(ctx context.Context, headers http.Header, tracer *Tracer) co
 	return tracer.Propagator().Extract(ctx, propagation.HeaderCarrier(headers))
 }
 
+// InjectMap returns the trace context of ctx as a map, for carrying it in
+// messages other than HTTP requests. Returns nil if tracing is disabled or
+// ctx has no span.
+func InjectMap(ctx context.Context, tracer *Tracer) map[string]string {
+	if tracer == nil || !tracer.Enabled() {
+		return nil
+	}
+	carrier := propagation.MapCarrier{}
+	tracer.Propagator().Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
+
+// ExtractMap returns ctx with the trace context stored by InjectMap.
+func ExtractMap(ctx context.Context, carrier map[string]string, tracer *Tracer) context.Context {
+	if tracer == nil || !tracer.Enabled() || len(carrier) == 0 {
+		return ctx
+	}
+	return tracer.Propagator().Extract(ctx, propagation.MapCarrier(carrier))
+}
+
 type responseWriter struct {
 	http.ResponseWriter
 	statusCode int
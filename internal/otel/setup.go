@@ -0,0 +1,78 @@
+package otel
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// OTLP protocols accepted by EndpointConfig.
+const (
+	ProtocolGRPC = "grpc"
+	ProtocolHTTP = "http"
+)
+
+// EndpointConfig returns a tracing config that exports spans to endpoint,
+// which is "stdout", a collector address such as "localhost:4317", or a URL.
+// Bare addresses and http:// URLs are plaintext; https:// URLs use TLS.
+// protocol selects OTLP over gRPC or HTTP and defaults to gRPC.
+func EndpointConfig(serviceName, endpoint, protocol string) (*Config, error) {
+	cfg := DefaultConfig()
+	cfg.ServiceName = serviceName
+	if endpoint == "" {
+		return cfg, nil
+	}
+	cfg.Enabled = true
+
+	if endpoint == "stdout" {
+		cfg.ExporterType = ExporterStdout
+		return cfg, nil
+	}
+
+	switch protocol {
+	case "", ProtocolGRPC:
+		cfg.ExporterType = ExporterOTLPGRPC
+	case ProtocolHTTP:
+		cfg.ExporterType = ExporterOTLPHTTP
+	default:
+		return nil, fmt.Errorf("unknown OTLP protocol %q (use grpc or http)", protocol)
+	}
+
+	cfg.OTLPEndpoint = endpoint
+	cfg.OTLPInsecure = true
+	if strings.Contains(endpoint, "://") {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OTLP endpoint: %w", err)
+		}
+		switch u.Scheme {
+		case "http":
+		case "https":
+			cfg.OTLPInsecure = false
+		default:
+			return nil, fmt.Errorf("invalid OTLP endpoint scheme %q (use http or https)", u.Scheme)
+		}
+		if u.Host == "" || (u.Path != "" && u.Path != "/") {
+			return nil, fmt.Errorf("invalid OTLP endpoint %q: expected scheme://host:port", endpoint)
+		}
+		cfg.OTLPEndpoint = u.Host
+	}
+	return cfg, nil
+}
+
+// SetupTracing builds a tracer from EndpointConfig and installs it as the
+// global tracer. With an empty endpoint tracing stays disabled. Callers
+// should Shutdown the returned tracer to flush pending spans.
+func SetupTracing(ctx context.Context, serviceName, endpoint, protocol string) (*Tracer, error) {
+	cfg, err := EndpointConfig(serviceName, endpoint, protocol)
+	if err != nil {
+		return nil, err
+	}
+	tracer, err := NewTracer(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	SetGlobalTracer(tracer)
+	return tracer, nil
+}
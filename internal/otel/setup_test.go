@@ -0,0 +1,107 @@
+package otel
+
+import (
+	"context"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestEndpointConfig(t *testing.T) {
+	tests := []struct {
+		endpoint     string
+		protocol     string
+		wantEnabled  bool
+		wantExporter ExporterType
+		wantEndpoint string
+		wantInsecure bool
+	}{
+		{"", "", false, ExporterNone, "", false},
+		{"stdout", "", true, ExporterStdout, "", false},
+		{"localhost:4317", "", true, ExporterOTLPGRPC, "localhost:4317", true},
+		{"localhost:4318", "http", true, ExporterOTLPHTTP, "localhost:4318", true},
+		{"http://collector:4317", "grpc", true, ExporterOTLPGRPC, "collector:4317", true},
+		{"https://collector:4318/", "http", true, ExporterOTLPHTTP, "collector:4318", false},
+	}
+	for _, tt := range tests {
+		cfg, err := EndpointConfig("svc", tt.endpoint, tt.protocol)
+		if err != nil {
+			t.Errorf("EndpointConfig(%q, %q) failed: %v", tt.endpoint, tt.protocol, err)
+			continue
+		}
+		if cfg.ServiceName != "svc" {
+			t.Errorf("EndpointConfig(%q): ServiceName = %q, want svc", tt.endpoint, cfg.ServiceName)
+		}
+		if cfg.Enabled != tt.wantEnabled || cfg.ExporterType != tt.wantExporter {
+			t.Errorf("EndpointConfig(%q, %q): enabled=%v exporter=%q, want %v %q",
+				tt.endpoint, tt.protocol, cfg.Enabled, cfg.ExporterType, tt.wantEnabled, tt.wantExporter)
+		}
+		if tt.wantEndpoint != "" && (cfg.OTLPEndpoint != tt.wantEndpoint || cfg.OTLPInsecure != tt.wantInsecure) {
+			t.Errorf("EndpointConfig(%q): endpoint=%q insecure=%v, want %q %v",
+				tt.endpoint, cfg.OTLPEndpoint, cfg.OTLPInsecure, tt.wantEndpoint, tt.wantInsecure)
+		}
+	}
+}
+
+func TestEndpointConfigErrors(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		protocol string
+	}{
+		{"localhost:4317", "thrift"},
+		{"ftp://collector:4317", "grpc"},
+		{"http://collector:4318/v1/traces", "http"},
+		{"http://", "grpc"},
+	}
+	for _, tt := range tests {
+		if _, err := EndpointConfig("svc", tt.endpoint, tt.protocol); err == nil {
+			t.Errorf("EndpointConfig(%q, %q): expected error", tt.endpoint, tt.protocol)
+		}
+	}
+}
+
+func TestSetupTracingDisabled(t *testing.T) {
+	original := GetGlobalTracer()
+	defer SetGlobalTracer(original)
+
+	tracer, err := SetupTracing(context.Background(), "svc", "", "")
+	if err != nil {
+		t.Fatalf("SetupTracing failed: %v", err)
+	}
+	if tracer.Enabled() {
+		t.Error("expected tracing to be disabled without an endpoint")
+	}
+	if GetGlobalTracer() != tracer {
+		t.Error("expected SetupTracing to install the global tracer")
+	}
+}
+
+func TestInjectExtractMap(t *testing.T) {
+	tp := sdktrace.NewTracerProvider()
+	defer tp.Shutdown(context.Background())
+	tracer := NewTracerWithProvider("svc", tp)
+
+	ctx, span := tracer.StartSpan(context.Background(), "parent")
+	defer span.End()
+
+	carrier := InjectMap(ctx, tracer)
+	if carrier["traceparent"] == "" {
+		t.Fatalf("expected traceparent in carrier, got %v", carrier)
+	}
+
+	extracted := trace.SpanContextFromContext(ExtractMap(context.Background(), carrier, tracer))
+	if extracted.TraceID() != span.SpanContext().TraceID() {
+		t.Errorf("extracted trace ID %s, want %s", extracted.TraceID(), span.SpanContext().TraceID())
+	}
+	if !extracted.IsRemote() {
+		t.Error("expected extracted span context to be remote")
+	}
+
+	if m := InjectMap(ctx, NoopTracer()); m != nil {
+		t.Errorf("expected nil carrier from disabled tracer, got %v", m)
+	}
+	if got := ExtractMap(context.Background(), carrier, NoopTracer()); trace.SpanContextFromContext(got).IsValid() {
+		t.Error("expected disabled tracer to ignore carrier")
+	}
+}
@@ -31,6 +31,8 @@ const (
 	ExporterOTLPGRPC ExporterType = "otlp-grpc"
 	// ExporterOTLPHTTP exports traces via OTLP over HTTP.
 	ExporterOTLPHTTP ExporterType = "otlp-http"
+	// ExporterCustom marks a tracer built on a caller-supplied provider.
+	ExporterCustom ExporterType = "custom"
 )
 
 // Config holds configuration for the OpenTelemetry tracer.
@@ -144,6 +146,18 @@ func NewTracer(ctx context.Context, cfg *Config) (*Tracer, error) {
 	return t, nil
 }
 
+// NewTracerWithProvider creates an enabled Tracer that records spans through
+// tp, such as an SDK provider with an in-memory exporter in tests.
+func NewTracerWithProvider(serviceName string, tp trace.TracerProvider) *Tracer {
+	return &Tracer{
+		config:         &Config{Enabled: true, ServiceName: serviceName, ExporterType: ExporterCustom, SampleRate: 1.0},
+		tracerProvider: tp,
+		tracer:         tp.Tracer(serviceName),
+		propagator:     propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}),
+		shutdown:       func(context.Context) error { return nil },
+	}
+}
+
 // createExporter creates the appropriate exporter based on configuration.
 func (t *Tracer) createExporter(ctx context.Context, cfg *Config) (sdktrace.SpanExporter, error) {
 	switch cfg.ExporterType {
@@ -0,0 +1,97 @@
+package recorder
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// harLog is the part of a HAR capture the importer reads.
+type harLog struct {
+	Log struct {
+		Entries []struct {
+			StartedDateTime string  `json:"startedDateTime"`
+			Time            float64 `json:"time"`
+			Request         struct {
+				Method   string `json:"method"`
+				PostData *struct {
+					Text string `json:"text"`
+				} `json:"postData"`
+			} `json:"request"`
+			Response struct {
+				Status int `json:"status"`
+			} `json:"response"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+// Read reads a capture, telling a HAR file, a JSON object with a "log",
+// from the JSONL the proxy writes.
+func Read(r io.Reader) ([]Entry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var probe struct {
+		Log json.RawMessage `json:"log"`
+	}
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("{")) && json.Unmarshal(trimmed, &probe) == nil && probe.Log != nil {
+		return ReadHAR(bytes.NewReader(trimmed))
+	}
+	return ReadJSONL(bytes.NewReader(data))
+}
+
+// ReadJSONL reads the entries written by a Proxy, one per line.
+func ReadJSONL(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxRecordedBody)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(text, &entry); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// ReadHAR reads the JSON-RPC requests in the POST bodies of a HAR capture,
+// as browsers' developer tools and most HTTP debugging proxies export it.
+// Other requests are skipped.
+func ReadHAR(r io.Reader) ([]Entry, error) {
+	var har harLog
+	if err := json.NewDecoder(r).Decode(&har); err != nil {
+		return nil, fmt.Errorf("invalid HAR: %w", err)
+	}
+	var entries []Entry
+	for _, e := range har.Log.Entries {
+		if e.Request.Method != "POST" || e.Request.PostData == nil {
+			continue
+		}
+		var timeMs int64
+		if started, err := time.Parse(time.RFC3339Nano, e.StartedDateTime); err == nil {
+			timeMs = started.UnixMilli()
+		}
+		for _, entry := range parseRequests([]byte(e.Request.PostData.Text)) {
+			entry.TimeMs = timeMs
+			entry.Status = e.Response.Status
+			entry.LatencyMs = int64(e.Time)
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
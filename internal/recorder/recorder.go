@@ -0,0 +1,186 @@
+// Package recorder captures the MCP traffic of real clients, through a
+// recording reverse proxy or from HAR and JSONL captures, and turns it into
+// the workload of a run config so load tests mirror production usage.
+package recorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+// maxRecordedBody is the largest request body the proxy parses. Larger
+// bodies are forwarded without being recorded.
+const maxRecordedBody = 8 << 20
+
+// Entry is one recorded JSON-RPC request.
+type Entry struct {
+	TimeMs int64  `json:"time_ms"`
+	Method string `json:"method"`
+	// Tool and Arguments are those of tools/call; Arguments are also those
+	// of prompts/get.
+	Tool       string                 `json:"tool,omitempty"`
+	Arguments  map[string]interface{} `json:"arguments,omitempty"`
+	URI        string                 `json:"uri,omitempty"`
+	PromptName string                 `json:"prompt_name,omitempty"`
+	// Status and LatencyMs describe the HTTP exchange the request was part
+	// of. They are zero when unknown.
+	Status    int   `json:"status,omitempty"`
+	LatencyMs int64 `json:"latency_ms,omitempty"`
+}
+
+// callParams are the params of the requests recorded in detail.
+type callParams struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments"`
+	URI       string                 `json:"uri"`
+}
+
+// parseRequests returns the entries of a JSON-RPC request or batch body,
+// or nil if it is neither.
+func parseRequests(body []byte) []Entry {
+	body = bytes.TrimSpace(body)
+	var requests []types.JSONRPCRequest
+	if len(body) > 0 && body[0] == '[' {
+		if err := json.Unmarshal(body, &requests); err != nil {
+			return nil
+		}
+	} else {
+		var request types.JSONRPCRequest
+		if err := json.Unmarshal(body, &request); err != nil {
+			return nil
+		}
+		requests = []types.JSONRPCRequest{request}
+	}
+
+	entries := make([]Entry, 0, len(requests))
+	for _, req := range requests {
+		if req.Method == "" {
+			continue
+		}
+		entry := Entry{Method: req.Method}
+		var params callParams
+		if len(req.Params) > 0 {
+			_ = json.Unmarshal(req.Params, &params)
+		}
+		switch req.Method {
+		case "tools/call":
+			entry.Tool = params.Name
+			entry.Arguments = params.Arguments
+		case "resources/read":
+			entry.URI = params.URI
+		case "prompts/get":
+			entry.PromptName = params.Name
+			entry.Arguments = params.Arguments
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// Proxy is a reverse proxy to an MCP server that records the JSON-RPC
+// requests passing through it as JSONL entries. Point clients at the proxy
+// instead of the server; request headers, which may carry credentials, are
+// not recorded.
+type Proxy struct {
+	proxy *httputil.ReverseProxy
+
+	mu      sync.Mutex
+	enc     *json.Encoder
+	count   int
+	onEntry func(Entry)
+}
+
+// NewProxy returns a proxy forwarding to target, a server URL without the
+// MCP path, and writing entries to w.
+func NewProxy(target *url.URL, w io.Writer) *Proxy {
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	director := proxy.Director
+	proxy.Director = func(r *http.Request) {
+		director(r)
+		r.Host = target.Host
+	}
+	return &Proxy{proxy: proxy, enc: json.NewEncoder(w)}
+}
+
+// OnEntry sets a function called with every entry recorded.
+func (p *Proxy) OnEntry(fn func(Entry)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onEntry = fn
+}
+
+// Count returns the number of entries recorded.
+func (p *Proxy) Count() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.count
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var entries []Entry
+	if r.Method == http.MethodPost && r.Body != nil {
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxRecordedBody+1))
+		if err != nil {
+			http.Error(w, "reading request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(body) <= maxRecordedBody {
+			entries = parseRequests(body)
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		} else {
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+		}
+	}
+
+	start := time.Now()
+	rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	p.proxy.ServeHTTP(rec, r)
+	if len(entries) == 0 {
+		return
+	}
+
+	latencyMs := time.Since(start).Milliseconds()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, entry := range entries {
+		entry.TimeMs = start.UnixMilli()
+		entry.Status = rec.status
+		entry.LatencyMs = latencyMs
+		if err := p.enc.Encode(entry); err != nil {
+			log.Printf("[Recorder] Failed to write entry: %v", err)
+			return
+		}
+		p.count++
+		if p.onEntry != nil {
+			p.onEntry(entry)
+		}
+	}
+}
+
+// statusRecorder remembers the status of a response. Unwrap lets the proxy
+// flush streamed responses through it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
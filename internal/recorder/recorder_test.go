@@ -0,0 +1,209 @@
+package recorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/mockserver"
+	"github.com/bc-dunia/mcpdrill/internal/validation"
+)
+
+func post(t *testing.T, url, body string) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request through the proxy failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected the server's answer through the proxy, got %s", resp.Status)
+	}
+}
+
+func TestProxyRecordsRequests(t *testing.T) {
+	server, cleanup := mockserver.StartTestServer()
+	defer cleanup()
+	target, _ := url.Parse("http://" + server.Addr())
+
+	var out bytes.Buffer
+	proxy := NewProxy(target, &out)
+	var seen []Entry
+	proxy.OnEntry(func(e Entry) { seen = append(seen, e) })
+	front := httptest.NewServer(proxy)
+	defer front.Close()
+
+	post(t, front.URL+"/mcp", `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	post(t, front.URL+"/mcp", `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"fast_echo","arguments":{"message":"hi"}}}`)
+	post(t, front.URL+"/mcp", `[{"jsonrpc":"2.0","id":3,"method":"ping"},{"jsonrpc":"2.0","id":4,"method":"resources/read","params":{"uri":"file:///readme"}}]`)
+
+	if proxy.Count() != 4 || len(seen) != 4 {
+		t.Fatalf("expected 4 recorded requests, got %d (%d seen)", proxy.Count(), len(seen))
+	}
+	entries, err := ReadJSONL(&out)
+	if err != nil {
+		t.Fatalf("ReadJSONL failed: %v", err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("expected 4 entries written, got %d", len(entries))
+	}
+	call := entries[1]
+	if call.Method != "tools/call" || call.Tool != "fast_echo" || call.Arguments["message"] != "hi" || call.Status != http.StatusOK {
+		t.Errorf("unexpected tools/call entry: %+v", call)
+	}
+	if entries[3].URI != "file:///readme" {
+		t.Errorf("expected the read URI to be recorded, got %+v", entries[3])
+	}
+}
+
+func TestReadHAR(t *testing.T) {
+	har := `{"log": {"entries": [
+		{"startedDateTime": "2026-01-02T03:04:05.000Z", "time": 12.5,
+		 "request": {"method": "POST", "url": "http://mcp/mcp", "postData": {"mimeType": "application/json",
+		  "text": "{\"jsonrpc\":\"2.0\",\"id\":1,\"method\":\"tools/call\",\"params\":{\"name\":\"search\",\"arguments\":{\"query\":\"mcp\"}}}"}},
+		 "response": {"status": 200}},
+		{"startedDateTime": "2026-01-02T03:04:06.000Z", "time": 1,
+		 "request": {"method": "GET", "url": "http://mcp/mcp"}, "response": {"status": 405}},
+		{"startedDateTime": "2026-01-02T03:04:07.000Z", "time": 3,
+		 "request": {"method": "POST", "url": "http://mcp/other", "postData": {"text": "not json-rpc"}}, "response": {"status": 200}}
+	]}}`
+	entries, err := Read(strings.NewReader(har))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected the one JSON-RPC request, got %+v", entries)
+	}
+	e := entries[0]
+	if e.Tool != "search" || e.Arguments["query"] != "mcp" || e.LatencyMs != 12 || e.Status != 200 || e.TimeMs == 0 {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+
+	if _, err := ReadJSONL(strings.NewReader("{\"method\":\"ping\"}\nnot json\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected the bad line to be reported, got %v", err)
+	}
+}
+
+func TestBuildWorkload(t *testing.T) {
+	var entries []Entry
+	add := func(n int, e Entry) {
+		for i := 0; i < n; i++ {
+			entries = append(entries, e)
+		}
+	}
+	add(1, Entry{Method: "initialize"})
+	add(2, Entry{Method: "tools/list"})
+	add(6, Entry{Method: "tools/call", Tool: "search", Arguments: map[string]interface{}{"query": "a"}})
+	add(3, Entry{Method: "tools/call", Tool: "search", Arguments: map[string]interface{}{"query": "b", "limit": 5.0}})
+	add(1, Entry{Method: "tools/call", Tool: "fetch", Arguments: map[string]interface{}{"url": "x"}})
+	add(2, Entry{Method: "resources/read", URI: "docs://a"})
+	add(1, Entry{Method: "resources/read", URI: "docs://b"})
+
+	w, err := BuildWorkload(entries)
+	if err != nil {
+		t.Fatalf("BuildWorkload failed: %v", err)
+	}
+	if w.Replayed != 15 || w.Ignored["initialize"] != 1 {
+		t.Errorf("expected 15 replayed and initialize ignored, got %d and %v", w.Replayed, w.Ignored)
+	}
+
+	wantMix := []MixEntry{
+		{Operation: "tools_call", Weight: 10},
+		{Operation: "tools_list", Weight: 2},
+		{Operation: "resources_read", Weight: 2, URI: "docs://a"},
+		{Operation: "resources_read", Weight: 1, URI: "docs://b"},
+	}
+	if len(w.OperationMix) != len(wantMix) {
+		t.Fatalf("expected mix %+v, got %+v", wantMix, w.OperationMix)
+	}
+	for i, want := range wantMix {
+		got := w.OperationMix[i]
+		if got.Operation != want.Operation || got.Weight != want.Weight || got.URI != want.URI {
+			t.Errorf("mix entry %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+
+	wantTemplates := []struct {
+		id     string
+		tool   string
+		weight int
+	}{{"search", "search", 6}, {"search_2", "search", 3}, {"fetch", "fetch", 1}}
+	if len(w.Templates) != len(wantTemplates) {
+		t.Fatalf("expected %d templates, got %+v", len(wantTemplates), w.Templates)
+	}
+	for i, want := range wantTemplates {
+		got := w.Templates[i]
+		if got.TemplateID != want.id || got.ToolName != want.tool || got.Weight != want.weight {
+			t.Errorf("template %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+	if w.Templates[1].Arguments["limit"] != 5.0 {
+		t.Errorf("expected the arguments of the first call of a shape, got %v", w.Templates[1].Arguments)
+	}
+
+	if _, err := BuildWorkload([]Entry{{Method: "initialize"}}); err == nil {
+		t.Error("expected an error without replayable requests")
+	}
+}
+
+func TestScaleWeights(t *testing.T) {
+	weights := scaleWeights([]*group{{count: 400000}, {count: 1000}, {count: 1}})
+	if weights[0] != maxWeight || weights[1] != 250 || weights[2] != 1 {
+		t.Errorf("expected weights scaled to the maximum, got %v", weights)
+	}
+}
+
+func TestApplyValidates(t *testing.T) {
+	config, err := os.ReadFile("../../testdata/fixtures/valid/minimal_preflight_baseline_ramp.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := BuildWorkload([]Entry{
+		{Method: "tools/call", Tool: "search", Arguments: map[string]interface{}{"query": "a"}},
+		{Method: "tools/list"},
+		{Method: "prompts/get", PromptName: "summarize", Arguments: map[string]interface{}{"text": "x"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	applied, err := Apply(config, w)
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	validator, err := validation.NewUnifiedValidator(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report := validator.ValidateRunConfig(applied); !report.OK {
+		t.Fatalf("expected the converted config to be valid, got %+v", report.Errors)
+	}
+
+	var root struct {
+		Workload struct {
+			OperationMix []MixEntry `json:"operation_mix"`
+			Tools        struct {
+				Selection struct {
+					Mode string `json:"mode"`
+				} `json:"selection"`
+				Templates []ToolTemplate `json:"templates"`
+			} `json:"tools"`
+		} `json:"workload"`
+	}
+	if err := json.Unmarshal(applied, &root); err != nil {
+		t.Fatal(err)
+	}
+	if len(root.Workload.OperationMix) != 3 || root.Workload.Tools.Selection.Mode != "weighted" || len(root.Workload.Tools.Templates) != 1 {
+		t.Errorf("expected the recorded workload in the config, got %+v", root.Workload)
+	}
+}
@@ -0,0 +1,245 @@
+package recorder
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/bc-dunia/mcpdrill/internal/validation"
+)
+
+// Limits of the run config schema on a workload.
+const (
+	maxMixEntries = 50
+	maxTemplates  = 500
+	maxWeight     = 100000
+)
+
+// operations maps the JSON-RPC methods a workload can replay to their
+// operation. Session setup such as initialize is left out: every VU does it
+// on its own.
+var operations = map[string]string{
+	"tools/list":     "tools_list",
+	"tools/call":     "tools_call",
+	"resources/list": "resources_list",
+	"resources/read": "resources_read",
+	"prompts/list":   "prompts_list",
+	"prompts/get":    "prompts_get",
+	"ping":           "ping",
+}
+
+// MixEntry is an entry of workload.operation_mix.
+type MixEntry struct {
+	Operation  string                 `json:"operation"`
+	Weight     int                    `json:"weight"`
+	URI        string                 `json:"uri,omitempty"`
+	PromptName string                 `json:"prompt_name,omitempty"`
+	Arguments  map[string]interface{} `json:"arguments,omitempty"`
+}
+
+// ToolTemplate is an entry of workload.tools.templates.
+type ToolTemplate struct {
+	TemplateID string                 `json:"template_id"`
+	ToolName   string                 `json:"tool_name"`
+	Weight     int                    `json:"weight"`
+	Arguments  map[string]interface{} `json:"arguments"`
+}
+
+// Workload is the operation mix and tool templates derived from recorded
+// traffic.
+type Workload struct {
+	OperationMix []MixEntry     `json:"operation_mix"`
+	Templates    []ToolTemplate `json:"templates"`
+	// Replayed counts the entries the workload reproduces. Ignored counts
+	// the rest by method, and Dropped the distinct resources, prompts and
+	// tool argument shapes past the schema's limits.
+	Replayed int            `json:"replayed"`
+	Ignored  map[string]int `json:"ignored,omitempty"`
+	Dropped  int            `json:"dropped,omitempty"`
+}
+
+// group is a kind of request seen in the traffic and how often it was.
+type group struct {
+	key    string
+	sample Entry
+	count  int
+}
+
+// groupBy counts entries by key, keeping the first entry of each key as its
+// sample, and returns the groups most frequent first.
+func groupBy(entries []Entry, key func(Entry) string) []*group {
+	byKey := make(map[string]*group)
+	var groups []*group
+	for _, e := range entries {
+		k := key(e)
+		g, ok := byKey[k]
+		if !ok {
+			g = &group{key: k, sample: e}
+			byKey[k] = g
+			groups = append(groups, g)
+		}
+		g.count++
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].count > groups[j].count })
+	return groups
+}
+
+// argumentShape identifies the argument names of a call.
+func argumentShape(args map[string]interface{}) string {
+	names := make([]string, 0, len(args))
+	for name := range args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// BuildWorkload derives a workload from recorded entries. Operations are
+// weighted by how often they were seen, reads and prompts by resource and
+// prompt, and tool calls by tool and argument shape, the set of argument
+// names. Each weighted entry replays the first call of its kind.
+func BuildWorkload(entries []Entry) (*Workload, error) {
+	w := &Workload{}
+	var replayable []Entry
+	for _, e := range entries {
+		if _, ok := operations[e.Method]; !ok {
+			if w.Ignored == nil {
+				w.Ignored = make(map[string]int)
+			}
+			w.Ignored[e.Method]++
+			continue
+		}
+		replayable = append(replayable, e)
+	}
+	if len(replayable) == 0 {
+		return nil, fmt.Errorf("no replayable MCP requests among %d recorded", len(entries))
+	}
+	w.Replayed = len(replayable)
+
+	mix := groupBy(replayable, func(e Entry) string {
+		switch e.Method {
+		case "resources/read":
+			return e.Method + " " + e.URI
+		case "prompts/get":
+			return e.Method + " " + e.PromptName + " " + argumentShape(e.Arguments)
+		}
+		return e.Method
+	})
+	if len(mix) > maxMixEntries {
+		for _, g := range mix[maxMixEntries:] {
+			w.Dropped += g.count
+		}
+		mix = mix[:maxMixEntries]
+	}
+	mixWeights := scaleWeights(mix)
+	for i, g := range mix {
+		entry := MixEntry{Operation: operations[g.sample.Method], Weight: mixWeights[i]}
+		switch g.sample.Method {
+		case "resources/read":
+			entry.URI = g.sample.URI
+		case "prompts/get":
+			entry.PromptName = g.sample.PromptName
+			entry.Arguments = g.sample.Arguments
+		}
+		w.OperationMix = append(w.OperationMix, entry)
+	}
+
+	var calls []Entry
+	for _, e := range replayable {
+		if e.Method == "tools/call" && e.Tool != "" {
+			calls = append(calls, e)
+		}
+	}
+	tools := groupBy(calls, func(e Entry) string { return e.Tool + " " + argumentShape(e.Arguments) })
+	if len(tools) > maxTemplates {
+		for _, g := range tools[maxTemplates:] {
+			w.Dropped += g.count
+		}
+		tools = tools[:maxTemplates]
+	}
+	toolWeights := scaleWeights(tools)
+	shapes := make(map[string]int)
+	for i, g := range tools {
+		shapes[g.sample.Tool]++
+		id := g.sample.Tool
+		if n := shapes[g.sample.Tool]; n > 1 {
+			id = fmt.Sprintf("%s_%d", id, n)
+		}
+		args := g.sample.Arguments
+		if args == nil {
+			args = map[string]interface{}{}
+		}
+		w.Templates = append(w.Templates, ToolTemplate{
+			TemplateID: id,
+			ToolName:   g.sample.Tool,
+			Weight:     toolWeights[i],
+			Arguments:  args,
+		})
+	}
+	return w, nil
+}
+
+// scaleWeights returns the counts of groups as weights, scaled down to the
+// schema's maximum weight if need be. groups are sorted by count.
+func scaleWeights(groups []*group) []int {
+	weights := make([]int, len(groups))
+	if len(groups) == 0 {
+		return weights
+	}
+	scale := 1.0
+	if top := groups[0].count; top > maxWeight {
+		scale = float64(maxWeight) / float64(top)
+	}
+	for i, g := range groups {
+		weights[i] = max(1, int(math.Round(float64(g.count)*scale)))
+	}
+	return weights
+}
+
+// Apply replaces the operation mix and tool templates of a run config, JSON
+// or YAML, with those of w, selecting tools by weight. It returns the config
+// as indented JSON.
+func Apply(config []byte, w *Workload) ([]byte, error) {
+	doc := config
+	if !json.Valid(config) {
+		parsed, err := validation.ParseYAML(config)
+		if err != nil {
+			return nil, err
+		}
+		doc = parsed.JSON
+	}
+	var root map[string]interface{}
+	if err := json.Unmarshal(doc, &root); err != nil {
+		return nil, fmt.Errorf("invalid run config: %w", err)
+	}
+	if root == nil {
+		return nil, fmt.Errorf("invalid run config: not an object")
+	}
+
+	workload, _ := root["workload"].(map[string]interface{})
+	if workload == nil {
+		workload = make(map[string]interface{})
+		root["workload"] = workload
+	}
+	tools, _ := workload["tools"].(map[string]interface{})
+	if tools == nil {
+		tools = make(map[string]interface{})
+		workload["tools"] = tools
+	}
+	selection, _ := tools["selection"].(map[string]interface{})
+	if selection == nil {
+		selection = make(map[string]interface{})
+		tools["selection"] = selection
+	}
+	selection["mode"] = "weighted"
+	delete(selection, "single_template_id")
+	templates := w.Templates
+	if templates == nil {
+		templates = []ToolTemplate{}
+	}
+	tools["templates"] = templates
+	workload["operation_mix"] = w.OperationMix
+	return json.MarshalIndent(root, "", "  ")
+}
@@ -1,6 +1,9 @@
 package retention
 
-import "github.com/bc-dunia/mcpdrill/internal/controlplane/api"
+import (
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/api"
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/runmanager"
+)
 
 type TelemetryStoreAdapter struct {
 	store *api.TelemetryStore
@@ -25,3 +28,36 @@ func (a *TelemetryStoreAdapter) ListRunsForRetention() []RunRetentionInfo {
 func (a *TelemetryStoreAdapter) DeleteRun(runID string) {
 	a.store.DeleteRun(runID)
 }
+
+// RunManagerAdapter exposes the runs of a run manager to retention. Runs are
+// deleted through the run manager, which deletes them from every store.
+type RunManagerAdapter struct {
+	rm *runmanager.RunManager
+}
+
+func NewRunManagerAdapter(rm *runmanager.RunManager) *RunManagerAdapter {
+	return &RunManagerAdapter{rm: rm}
+}
+
+func (a *RunManagerAdapter) ListRunsForRetention() []RunRetentionInfo {
+	views, _ := a.rm.QueryRuns(runmanager.RunFilter{})
+	result := make([]RunRetentionInfo, len(views))
+	for i, v := range views {
+		result[i] = RunRetentionInfo{
+			RunID:       v.RunID,
+			Project:     v.Project,
+			ScenarioID:  v.ScenarioID,
+			CreatedAtMs: v.CreatedAtMs,
+			Tags:        v.Tags,
+		}
+		switch v.State {
+		case runmanager.RunStateCompleted, runmanager.RunStateFailed, runmanager.RunStateAborted:
+			result[i].EndTimeMs = v.UpdatedAtMs
+		}
+	}
+	return result
+}
+
+func (a *RunManagerAdapter) DeleteRun(runID string) error {
+	return a.rm.DeleteRun(runID, "retention")
+}
@@ -16,6 +16,23 @@ type Config struct {
 	// CleanupIntervalHours is the interval between cleanup runs in hours.
 	// Default: 24 (once per day)
 	CleanupIntervalHours int
+
+	// RunsTTLHours is how long ended runs are kept, counted from their
+	// creation. Older runs are deleted with their events, telemetry and
+	// artifacts. Needs a RunStore.
+	// Default: 0 (runs are kept)
+	RunsTTLHours int
+
+	// KeepRunsPerScenario keeps the newest ended runs of each scenario up
+	// to this many and deletes older ones. Needs a RunStore.
+	// Default: 0 (no limit)
+	KeepRunsPerScenario int
+
+	// KeepTag is a tag key that exempts runs from deletion, such as the
+	// baselines later runs are compared with. Tagged runs do not count
+	// toward KeepRunsPerScenario.
+	// Default: "baseline"
+	KeepTag string
 }
 
 // DefaultConfig returns a Config with default values.
@@ -24,6 +41,7 @@ func DefaultConfig() Config {
 		ArtifactsTTLHours:    168, // 7 days
 		LogsTTLHours:         168, // 7 days
 		CleanupIntervalHours: 24,  // once per day
+		KeepTag:              "baseline",
 	}
 }
 
@@ -39,5 +57,8 @@ func (c Config) WithDefaults() Config {
 	if result.CleanupIntervalHours <= 0 {
 		result.CleanupIntervalHours = 24
 	}
+	if result.KeepTag == "" {
+		result.KeepTag = "baseline"
+	}
 	return result
 }
@@ -1,9 +1,11 @@
 package retention
 
 import (
+	"cmp"
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
 	"time"
 )
@@ -15,9 +17,16 @@ type ArtifactStore interface {
 }
 
 // RunRetentionInfo contains metadata about a run for retention purposes.
+// EndTimeMs is zero while the run has not ended.
 type RunRetentionInfo struct {
 	RunID     string
 	EndTimeMs int64
+
+	// Set by run stores only.
+	Project     string
+	ScenarioID  string
+	CreatedAtMs int64
+	Tags        map[string]string
 }
 
 // TelemetryStore defines the interface for telemetry storage operations needed by retention.
@@ -26,11 +35,19 @@ type TelemetryStore interface {
 	DeleteRun(runID string)
 }
 
-// Manager handles periodic cleanup of old artifacts and logs.
+// RunStore defines the interface for run operations needed by retention.
+// DeleteRun must delete everything kept for the run, in every store.
+type RunStore interface {
+	ListRunsForRetention() []RunRetentionInfo
+	DeleteRun(runID string) error
+}
+
+// Manager handles periodic cleanup of old runs, artifacts and logs.
 type Manager struct {
 	config         Config
 	artifactStore  ArtifactStore
 	telemetryStore TelemetryStore
+	runStore       RunStore
 	stopCh         chan struct{}
 	stoppedCh      chan struct{}
 	mu             sync.Mutex
@@ -48,7 +65,18 @@ func NewManager(config Config, artifactStore ArtifactStore, telemetryStore Telem
 	}
 }
 
-// Start begins the background cleanup goroutine.
+// SetRunStore applies the run policy of the config to the runs of store.
+// The artifacts and logs of its runs are then left to the run policy: the
+// artifact and log TTLs only apply to those of runs store does not hold.
+// Call it before Start.
+func (m *Manager) SetRunStore(store RunStore) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runStore = store
+}
+
+// Start begins the background cleanup goroutine. The first cleanup runs
+// right away.
 func (m *Manager) Start() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -90,6 +118,7 @@ func (m *Manager) run() {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
+	m.cleanup()
 	for {
 		select {
 		case <-ticker.C:
@@ -101,9 +130,13 @@ func (m *Manager) run() {
 }
 
 func (m *Manager) cleanup() {
-	artifactsDeleted := m.cleanupArtifacts()
-	logsDeleted := m.cleanupLogs()
+	runsDeleted, kept := m.cleanupRuns()
+	artifactsDeleted := m.cleanupArtifacts(kept)
+	logsDeleted := m.cleanupLogs(kept)
 
+	if runsDeleted > 0 {
+		log.Printf("Deleted %d runs under the run retention policy", runsDeleted)
+	}
 	if artifactsDeleted > 0 {
 		log.Printf("Deleted %d artifacts older than %d hours", artifactsDeleted, m.config.ArtifactsTTLHours)
 	}
@@ -112,7 +145,70 @@ func (m *Manager) cleanup() {
 	}
 }
 
-func (m *Manager) cleanupArtifacts() int {
+// cleanupRuns deletes the ended runs the run policy does not keep. It
+// returns how many it deleted and the IDs of the runs that remain.
+func (m *Manager) cleanupRuns() (int, map[string]bool) {
+	m.mu.Lock()
+	store := m.runStore
+	m.mu.Unlock()
+	if store == nil {
+		return 0, nil
+	}
+
+	runs := store.ListRunsForRetention()
+	expired := m.expiredRuns(runs, time.Now().UnixMilli())
+	kept := make(map[string]bool, len(runs))
+	for _, run := range runs {
+		kept[run.RunID] = true
+	}
+	deleted := 0
+	for _, runID := range expired {
+		if err := store.DeleteRun(runID); err != nil {
+			log.Printf("Failed to delete run %s: %v", runID, err)
+			continue
+		}
+		delete(kept, runID)
+		deleted++
+	}
+	return deleted, kept
+}
+
+// expiredRuns returns the IDs of the ended runs that are older than
+// RunsTTLHours or not among the newest KeepRunsPerScenario of their
+// scenario, leaving out runs tagged KeepTag.
+func (m *Manager) expiredRuns(runs []RunRetentionInfo, nowMs int64) []string {
+	ttlMs := int64(m.config.RunsTTLHours) * 60 * 60 * 1000
+	ended := make([]RunRetentionInfo, 0, len(runs))
+	for _, run := range runs {
+		if _, keep := run.Tags[m.config.KeepTag]; run.EndTimeMs > 0 && !keep {
+			ended = append(ended, run)
+		}
+	}
+	slices.SortFunc(ended, func(a, b RunRetentionInfo) int {
+		if c := cmp.Compare(b.CreatedAtMs, a.CreatedAtMs); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.RunID, a.RunID)
+	})
+
+	type scenarioKey struct{ project, scenarioID string }
+	newer := make(map[scenarioKey]int)
+	var expired []string
+	for _, run := range ended {
+		key := scenarioKey{run.Project, run.ScenarioID}
+		tooOld := ttlMs > 0 && nowMs-run.CreatedAtMs > ttlMs
+		tooMany := m.config.KeepRunsPerScenario > 0 && newer[key] >= m.config.KeepRunsPerScenario
+		newer[key]++
+		if tooOld || tooMany {
+			expired = append(expired, run.RunID)
+		}
+	}
+	return expired
+}
+
+// cleanupArtifacts deletes the artifacts of runs outside kept whose files
+// are older than ArtifactsTTLHours.
+func (m *Manager) cleanupArtifacts(kept map[string]bool) int {
 	if m.artifactStore == nil {
 		return 0
 	}
@@ -140,6 +236,9 @@ func (m *Manager) cleanupArtifacts() int {
 		}
 
 		runID := entry.Name()
+		if kept[runID] {
+			continue
+		}
 		runDir := filepath.Join(baseDir, runID)
 
 		modTime, err := getDirectoryModTime(runDir)
@@ -160,7 +259,9 @@ func (m *Manager) cleanupArtifacts() int {
 	return deleted
 }
 
-func (m *Manager) cleanupLogs() int {
+// cleanupLogs deletes the telemetry of runs outside kept that ended more
+// than LogsTTLHours ago.
+func (m *Manager) cleanupLogs(kept map[string]bool) int {
 	if m.telemetryStore == nil {
 		return 0
 	}
@@ -171,7 +272,7 @@ func (m *Manager) cleanupLogs() int {
 	deleted := 0
 
 	for _, runInfo := range runs {
-		if runInfo.EndTimeMs == 0 {
+		if runInfo.EndTimeMs == 0 || kept[runInfo.RunID] {
 			continue
 		}
 
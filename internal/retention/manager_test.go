@@ -475,3 +475,43 @@ func TestManager_TTLCalculation(t *testing.T) {
 		})
 	}
 }
+
+type mockRunStore struct {
+	mockTelemetryStore
+}
+
+func (m *mockRunStore) DeleteRun(runID string) error {
+	m.mockTelemetryStore.DeleteRun(runID)
+	return nil
+}
+
+func TestManager_CleanupRuns(t *testing.T) {
+	const day = 24 * 60 * 60 * 1000
+	now := time.Now().UnixMilli()
+	runStore := &mockRunStore{mockTelemetryStore{runs: []RunRetentionInfo{
+		{RunID: "run_nightly_1", ScenarioID: "nightly", CreatedAtMs: now - 3*day, EndTimeMs: now - 3*day},
+		{RunID: "run_nightly_2", ScenarioID: "nightly", CreatedAtMs: now - 2*day, EndTimeMs: now - 2*day},
+		{RunID: "run_nightly_3", ScenarioID: "nightly", CreatedAtMs: now - 1*day, EndTimeMs: now - 1*day},
+		{RunID: "run_nightly_4", ScenarioID: "nightly", CreatedAtMs: now - 1000},
+		{RunID: "run_other_1", ScenarioID: "other", CreatedAtMs: now - 2*day, EndTimeMs: now - 2*day},
+		{RunID: "run_other_2", ScenarioID: "other", CreatedAtMs: now - 40*day, EndTimeMs: now - 40*day},
+		{RunID: "run_baseline", ScenarioID: "other", CreatedAtMs: now - 90*day, EndTimeMs: now - 90*day, Tags: map[string]string{"baseline": ""}},
+	}}}
+	telemetryStore := &mockTelemetryStore{runs: []RunRetentionInfo{
+		{RunID: "run_other_1", EndTimeMs: now - 30*day},
+		{RunID: "run_evicted", EndTimeMs: now - 30*day},
+	}}
+
+	mgr := NewManager(Config{RunsTTLHours: 30 * 24, KeepRunsPerScenario: 2}, nil, telemetryStore)
+	mgr.SetRunStore(runStore)
+	mgr.RunCleanupNow()
+
+	// run_nightly_4 has not ended, so run_nightly_1 is the third newest
+	// ended nightly run.
+	if got := runStore.getDeleteCalls(); len(got) != 2 || got[0] != "run_nightly_1" || got[1] != "run_other_2" {
+		t.Errorf("expected the oldest nightly run and the run past the TTL to be deleted, got %v", got)
+	}
+	if got := telemetryStore.getDeleteCalls(); len(got) != 1 || got[0] != "run_evicted" {
+		t.Errorf("expected only telemetry of unknown runs to follow the log TTL, got %v", got)
+	}
+}
@@ -0,0 +1,95 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/sigv4"
+)
+
+// AWSSecretsManagerBackend reads AWS Secrets Manager secrets. A reference
+// aws-sm://prod/mcpdrill#client_secret reads the JSON key client_secret of
+// the secret prod/mcpdrill; without a key the whole secret string is used.
+type AWSSecretsManagerBackend struct {
+	endpoint string
+	signer   *sigv4.Signer
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewAWSSecretsManagerBackend creates a backend for region. endpoint
+// overrides the regional endpoint, e.g. for LocalStack or a VPC endpoint.
+// Credentials come from the standard AWS environment variables.
+func NewAWSSecretsManagerBackend(region, endpoint string) *AWSSecretsManagerBackend {
+	if endpoint == "" {
+		endpoint = "https://secretsmanager." + region + ".amazonaws.com"
+	}
+	return &AWSSecretsManagerBackend{
+		endpoint: endpoint,
+		signer: &sigv4.Signer{
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+			Region:          region,
+			Service:         "secretsmanager",
+		},
+		client: &http.Client{Timeout: 10 * time.Second},
+		now:    time.Now,
+	}
+}
+
+// Fetch implements Backend.
+func (b *AWSSecretsManagerBackend) Fetch(ctx context.Context, ref Ref) (string, error) {
+	if b.signer.AccessKeyID == "" || b.signer.SecretAccessKey == "" {
+		return "", errors.New("aws secrets manager: AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are not set")
+	}
+	body, err := json.Marshal(map[string]string{"SecretId": ref.Path})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	b.signer.Sign(req, sigv4.SHA256Hex(body), b.now())
+
+	respBody, err := doSecretRequest(b.client, req)
+	if err != nil {
+		return "", fmt.Errorf("aws secrets manager: %w", err)
+	}
+	var payload struct {
+		SecretString *string `json:"SecretString"`
+		SecretBinary string  `json:"SecretBinary"`
+	}
+	if err := json.Unmarshal(respBody, &payload); err != nil {
+		return "", fmt.Errorf("aws secrets manager: decode response: %w", err)
+	}
+
+	var value string
+	if payload.SecretString != nil {
+		value = *payload.SecretString
+	} else {
+		data, err := base64.StdEncoding.DecodeString(payload.SecretBinary)
+		if err != nil {
+			return "", fmt.Errorf("aws secrets manager: decode SecretBinary: %w", err)
+		}
+		value = string(data)
+	}
+	if ref.Key == "" {
+		return value, nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return "", fmt.Errorf("aws secrets manager: #%s needs a JSON secret: %w", ref.Key, err)
+	}
+	return selectField(fields, ref.Key)
+}
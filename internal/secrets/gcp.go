@@ -0,0 +1,131 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const gcpMetadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+// GCPSecretManagerBackend reads GCP Secret Manager secrets. A reference is
+// either a full resource name, gcp-sm://projects/p/secrets/token/versions/3,
+// or a secret in the configured project, gcp-sm://token. The latest version
+// is read when none is given. A #key treats the payload as a JSON object.
+//
+// The access token is GOOGLE_OAUTH_ACCESS_TOKEN when set and otherwise the
+// default service account token of the metadata server.
+type GCPSecretManagerBackend struct {
+	project  string
+	endpoint string
+	client   *http.Client
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+	tokenURL    string
+}
+
+// NewGCPSecretManagerBackend creates a backend whose short references
+// resolve in project. endpoint overrides the Secret Manager API endpoint.
+func NewGCPSecretManagerBackend(project, endpoint string) *GCPSecretManagerBackend {
+	if endpoint == "" {
+		endpoint = "https://secretmanager.googleapis.com"
+	}
+	return &GCPSecretManagerBackend{
+		project:  project,
+		endpoint: strings.TrimRight(endpoint, "/"),
+		client:   &http.Client{Timeout: 10 * time.Second},
+		tokenURL: gcpMetadataTokenURL,
+	}
+}
+
+// Fetch implements Backend.
+func (b *GCPSecretManagerBackend) Fetch(ctx context.Context, ref Ref) (string, error) {
+	name := strings.Trim(ref.Path, "/")
+	if !strings.HasPrefix(name, "projects/") {
+		name = "projects/" + b.project + "/secrets/" + name
+	}
+	if !strings.Contains(name, "/versions/") {
+		name += "/versions/latest"
+	}
+
+	token, err := b.accessToken(ctx)
+	if err != nil {
+		return "", fmt.Errorf("gcp secret manager: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.endpoint+"/v1/"+name+":access", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	body, err := doSecretRequest(b.client, req)
+	if err != nil {
+		return "", fmt.Errorf("gcp secret manager: %w", err)
+	}
+	var payload struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return "", fmt.Errorf("gcp secret manager: decode response: %w", err)
+	}
+	data, err := base64.StdEncoding.DecodeString(payload.Payload.Data)
+	if err != nil {
+		return "", fmt.Errorf("gcp secret manager: decode payload: %w", err)
+	}
+	if ref.Key == "" {
+		return string(data), nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return "", fmt.Errorf("gcp secret manager: #%s needs a JSON secret: %w", ref.Key, err)
+	}
+	return selectField(fields, ref.Key)
+}
+
+// accessToken returns the token for Secret Manager requests, caching the
+// metadata server's token until shortly before it expires.
+func (b *GCPSecretManagerBackend) accessToken(ctx context.Context) (string, error) {
+	if token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); token != "" {
+		return token, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.token != "" && time.Now().Before(b.tokenExpiry) {
+		return b.token, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.tokenURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	body, err := doSecretRequest(b.client, req)
+	if err != nil {
+		return "", fmt.Errorf("metadata token: %w", err)
+	}
+	var payload struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return "", fmt.Errorf("metadata token: %w", err)
+	}
+	if payload.AccessToken == "" {
+		return "", errors.New("metadata token: no access_token")
+	}
+	b.token = payload.AccessToken
+	b.tokenExpiry = time.Now().Add(time.Duration(payload.ExpiresIn)*time.Second - time.Minute)
+	return b.token, nil
+}
@@ -0,0 +1,233 @@
+// Package secrets resolves the secret references used in run configs, such
+// as env://MCPDRILL_TOKEN or file:///run/secrets/mcpdrill/token, to their
+// values. References are resolved where the secret is used, so the values
+// never pass through the control plane.
+//
+// A Resolver adds remote backends (vault://, aws-sm://, gcp-sm://) and
+// caches what they return.
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnsupportedRef is returned for references with an unknown scheme.
+var ErrUnsupportedRef = errors.New("unsupported secret reference")
+
+// DefaultCacheTTL is how long a Resolver reuses a remote secret.
+const DefaultCacheTTL = 5 * time.Minute
+
+// Resolve returns the value of a local secret reference. Values read from
+// files have surrounding whitespace trimmed.
+func Resolve(ref string) (string, error) {
+	switch {
+	case strings.HasPrefix(ref, "env://"):
+		name := strings.TrimPrefix(ref, "env://")
+		value, ok := os.LookupEnv(name)
+		if !ok || name == "" {
+			return "", fmt.Errorf("secret %s: environment variable not set", ref)
+		}
+		return value, nil
+	case strings.HasPrefix(ref, "file://"):
+		data, err := os.ReadFile(strings.TrimPrefix(ref, "file://"))
+		if err != nil {
+			return "", fmt.Errorf("secret %s: %w", ref, err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedRef, ref)
+	}
+}
+
+// Ref is a parsed secret reference of the form scheme://path#key. Key
+// selects one field of a secret that holds several.
+type Ref struct {
+	Scheme string
+	Path   string
+	Key    string
+}
+
+// ParseRef splits a reference into its scheme, path and key.
+func ParseRef(ref string) (Ref, error) {
+	scheme, rest, ok := strings.Cut(ref, "://")
+	if !ok || scheme == "" || rest == "" {
+		return Ref{}, fmt.Errorf("%w: %q", ErrUnsupportedRef, ref)
+	}
+	path, key, _ := strings.Cut(rest, "#")
+	if path == "" {
+		return Ref{}, fmt.Errorf("secret %s: empty path", ref)
+	}
+	return Ref{Scheme: scheme, Path: path, Key: key}, nil
+}
+
+// Backend fetches secrets from one secret store.
+type Backend interface {
+	Fetch(ctx context.Context, ref Ref) (string, error)
+}
+
+// Config selects and configures the remote backends of a Resolver. A
+// backend is only registered when it is configured.
+type Config struct {
+	// CacheTTL is how long remote secrets are reused. Zero uses
+	// DefaultCacheTTL; a negative value disables the cache.
+	CacheTTL time.Duration
+
+	// VaultAddr enables vault:// references. VaultToken defaults to
+	// VAULT_TOKEN.
+	VaultAddr      string
+	VaultToken     string
+	VaultNamespace string
+
+	// AWSRegion enables aws-sm:// references. Credentials are read from
+	// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN.
+	AWSRegion   string
+	AWSEndpoint string
+
+	// GCPProject enables gcp-sm:// references and is the project of
+	// references that name only a secret.
+	GCPProject  string
+	GCPEndpoint string
+}
+
+// RegisterFlags defines the command-line flags for c on fs. Defaults come
+// from the usual VAULT_* and AWS_REGION environment variables; tokens and
+// credentials are only read from the environment.
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.DurationVar(&c.CacheTTL, "secret-cache-ttl", DefaultCacheTTL, "How long secrets from Vault, AWS or GCP are cached before they are fetched again")
+	fs.StringVar(&c.VaultAddr, "vault-addr", os.Getenv("VAULT_ADDR"), "Vault server for vault:// secret references (token from VAULT_TOKEN)")
+	fs.StringVar(&c.VaultNamespace, "vault-namespace", os.Getenv("VAULT_NAMESPACE"), "Vault Enterprise namespace")
+	fs.StringVar(&c.AWSRegion, "aws-secrets-region", os.Getenv("AWS_REGION"), "AWS region for aws-sm:// secret references (credentials from AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY)")
+	fs.StringVar(&c.AWSEndpoint, "aws-secrets-endpoint", "", "AWS Secrets Manager endpoint override")
+	fs.StringVar(&c.GCPProject, "gcp-secrets-project", "", "GCP project for gcp-sm:// secret references")
+	fs.StringVar(&c.GCPEndpoint, "gcp-secrets-endpoint", "", "GCP Secret Manager endpoint override")
+}
+
+// Resolver resolves local references directly and remote ones through the
+// registered backends. Remote values are cached for the TTL; when a refresh
+// fails the previous value is used until the store is reachable again.
+type Resolver struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu       sync.RWMutex
+	backends map[string]Backend
+	cache    map[string]cachedSecret
+}
+
+type cachedSecret struct {
+	value     string
+	fetchedAt time.Time
+}
+
+// NewResolver creates a resolver with the backends cfg configures.
+func NewResolver(cfg Config) *Resolver {
+	ttl := cfg.CacheTTL
+	if ttl == 0 {
+		ttl = DefaultCacheTTL
+	}
+	r := &Resolver{
+		ttl:      ttl,
+		now:      time.Now,
+		backends: make(map[string]Backend),
+		cache:    make(map[string]cachedSecret),
+	}
+	if cfg.VaultAddr != "" {
+		if cfg.VaultToken == "" {
+			cfg.VaultToken = os.Getenv("VAULT_TOKEN")
+		}
+		r.Register("vault", NewVaultBackend(cfg.VaultAddr, cfg.VaultToken, cfg.VaultNamespace))
+	}
+	if cfg.AWSRegion != "" {
+		r.Register("aws-sm", NewAWSSecretsManagerBackend(cfg.AWSRegion, cfg.AWSEndpoint))
+	}
+	if cfg.GCPProject != "" {
+		r.Register("gcp-sm", NewGCPSecretManagerBackend(cfg.GCPProject, cfg.GCPEndpoint))
+	}
+	return r
+}
+
+// Register sets the backend for a reference scheme.
+func (r *Resolver) Register(scheme string, backend Backend) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.backends[scheme] = backend
+}
+
+// Resolve returns the value of ref.
+func (r *Resolver) Resolve(ctx context.Context, ref string) (string, error) {
+	if strings.HasPrefix(ref, "env://") || strings.HasPrefix(ref, "file://") {
+		return Resolve(ref)
+	}
+	parsed, err := ParseRef(ref)
+	if err != nil {
+		return "", err
+	}
+
+	r.mu.RLock()
+	backend, ok := r.backends[parsed.Scheme]
+	cached, hit := r.cache[ref]
+	r.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("%w: no %s backend is configured for %q", ErrUnsupportedRef, parsed.Scheme, ref)
+	}
+	if hit && r.ttl > 0 && r.now().Sub(cached.fetchedAt) < r.ttl {
+		return cached.value, nil
+	}
+
+	value, err := backend.Fetch(ctx, parsed)
+	if err != nil {
+		if hit {
+			log.Printf("[Secrets] Refreshing %s failed, using the cached value: %v", ref, err)
+			return cached.value, nil
+		}
+		return "", fmt.Errorf("secret %s: %w", ref, err)
+	}
+	if r.ttl > 0 {
+		r.mu.Lock()
+		r.cache[ref] = cachedSecret{value: value, fetchedAt: r.now()}
+		r.mu.Unlock()
+	}
+	return value, nil
+}
+
+// selectField returns the field key of a secret that holds several. An
+// empty key is allowed when the secret has exactly one field.
+func selectField(fields map[string]interface{}, key string) (string, error) {
+	if key == "" {
+		if len(fields) != 1 {
+			return "", fmt.Errorf("secret has %d fields, select one with #key", len(fields))
+		}
+		for _, value := range fields {
+			return fieldString(value)
+		}
+	}
+	value, ok := fields[key]
+	if !ok {
+		return "", fmt.Errorf("secret has no field %q", key)
+	}
+	return fieldString(value)
+}
+
+func fieldString(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case nil:
+		return "", errors.New("secret field is null")
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+}
@@ -0,0 +1,183 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolve(t *testing.T) {
+	t.Setenv("MCPDRILL_TEST_SECRET", "s3cret")
+	path := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0600); err != nil {
+		t.Fatalf("failed to write secret file: %v", err)
+	}
+
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{"env://MCPDRILL_TEST_SECRET", "s3cret"},
+		{"file://" + path, "from-file"},
+	}
+	for _, tt := range tests {
+		got, err := Resolve(tt.ref)
+		if err != nil || got != tt.want {
+			t.Errorf("Resolve(%q) = %q, %v; want %q", tt.ref, got, err, tt.want)
+		}
+	}
+
+	if _, err := Resolve("env://MCPDRILL_TEST_UNSET"); err == nil {
+		t.Error("expected an unset environment variable to fail")
+	}
+	if _, err := Resolve("vault://secret/token"); !errors.Is(err, ErrUnsupportedRef) {
+		t.Errorf("expected ErrUnsupportedRef, got %v", err)
+	}
+}
+
+func TestParseRef(t *testing.T) {
+	ref, err := ParseRef("vault://secret/mcpdrill/target#token")
+	if err != nil || ref != (Ref{Scheme: "vault", Path: "secret/mcpdrill/target", Key: "token"}) {
+		t.Errorf("unexpected ref %+v, %v", ref, err)
+	}
+	for _, bad := range []string{"secret", "vault://", "vault://#token"} {
+		if _, err := ParseRef(bad); err == nil {
+			t.Errorf("expected ParseRef(%q) to fail", bad)
+		}
+	}
+}
+
+type countingBackend struct {
+	calls int
+	value string
+	err   error
+}
+
+func (b *countingBackend) Fetch(ctx context.Context, ref Ref) (string, error) {
+	b.calls++
+	return b.value + ref.Key, b.err
+}
+
+func TestResolver_CacheAndStaleOnError(t *testing.T) {
+	backend := &countingBackend{value: "v1-"}
+	resolver := NewResolver(Config{CacheTTL: time.Minute})
+	resolver.Register("test", backend)
+	now := time.Now()
+	resolver.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if got, err := resolver.Resolve(context.Background(), "test://name#key"); err != nil || got != "v1-key" {
+			t.Fatalf("Resolve = %q, %v", got, err)
+		}
+	}
+	if backend.calls != 1 {
+		t.Errorf("expected 1 fetch while cached, got %d", backend.calls)
+	}
+
+	now = now.Add(2 * time.Minute)
+	backend.value = "v2-"
+	if got, _ := resolver.Resolve(context.Background(), "test://name#key"); got != "v2-key" {
+		t.Errorf("expected the refreshed value after the TTL, got %q", got)
+	}
+
+	now = now.Add(2 * time.Minute)
+	backend.err = errors.New("store unavailable")
+	if got, err := resolver.Resolve(context.Background(), "test://name#key"); err != nil || got != "v2-key" {
+		t.Errorf("expected the stale value when a refresh fails, got %q, %v", got, err)
+	}
+	if _, err := resolver.Resolve(context.Background(), "test://other"); err == nil {
+		t.Error("expected an uncached secret to fail when the store does")
+	}
+	if _, err := resolver.Resolve(context.Background(), "vault://secret/token"); !errors.Is(err, ErrUnsupportedRef) {
+		t.Errorf("expected ErrUnsupportedRef for an unconfigured backend, got %v", err)
+	}
+}
+
+func TestVaultBackend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" || r.Header.Get("X-Vault-Namespace") != "team" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/v1/secret/data/mcpdrill/target" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"data":{"data":{"token":"vault-token","user":"drill"},"metadata":{"version":2}}}`)
+	}))
+	defer server.Close()
+
+	resolver := NewResolver(Config{VaultAddr: server.URL, VaultToken: "root", VaultNamespace: "team"})
+	if got, err := resolver.Resolve(context.Background(), "vault://secret/mcpdrill/target#token"); err != nil || got != "vault-token" {
+		t.Errorf("Resolve = %q, %v", got, err)
+	}
+	if _, err := resolver.Resolve(context.Background(), "vault://secret/mcpdrill/target"); err == nil {
+		t.Error("expected a secret with several fields to need a key")
+	}
+	if _, err := resolver.Resolve(context.Background(), "vault://secret/missing#token"); err == nil {
+		t.Error("expected a missing secret to fail")
+	}
+}
+
+func TestAWSSecretsManagerBackend(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" ||
+			!strings.Contains(r.Header.Get("Authorization"), "/us-east-1/secretsmanager/aws4_request") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var req struct{ SecretId string }
+		json.NewDecoder(r.Body).Decode(&req)
+		json.NewEncoder(w).Encode(map[string]string{"SecretString": `{"client_secret":"aws-secret"}`, "Name": req.SecretId})
+	}))
+	defer server.Close()
+
+	resolver := NewResolver(Config{AWSRegion: "us-east-1", AWSEndpoint: server.URL})
+	if got, err := resolver.Resolve(context.Background(), "aws-sm://prod/mcpdrill#client_secret"); err != nil || got != "aws-secret" {
+		t.Errorf("Resolve = %q, %v", got, err)
+	}
+	if got, _ := resolver.Resolve(context.Background(), "aws-sm://prod/raw"); got != `{"client_secret":"aws-secret"}` {
+		t.Errorf("expected the whole secret string without a key, got %q", got)
+	}
+}
+
+func TestGCPSecretManagerBackend(t *testing.T) {
+	t.Setenv("GOOGLE_OAUTH_ACCESS_TOKEN", "ya29.test")
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer ya29.test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		paths = append(paths, r.URL.Path)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"payload": map[string]string{"data": base64.StdEncoding.EncodeToString([]byte("gcp-secret"))},
+		})
+	}))
+	defer server.Close()
+
+	resolver := NewResolver(Config{GCPProject: "drill", GCPEndpoint: server.URL})
+	for _, ref := range []string{"gcp-sm://token", "gcp-sm://projects/other/secrets/token/versions/3"} {
+		if got, err := resolver.Resolve(context.Background(), ref); err != nil || got != "gcp-secret" {
+			t.Errorf("Resolve(%q) = %q, %v", ref, got, err)
+		}
+	}
+	want := []string{
+		"/v1/projects/drill/secrets/token/versions/latest:access",
+		"/v1/projects/other/secrets/token/versions/3:access",
+	}
+	if strings.Join(paths, ",") != strings.Join(want, ",") {
+		t.Errorf("unexpected request paths %v", paths)
+	}
+}
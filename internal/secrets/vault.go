@@ -0,0 +1,83 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// VaultBackend reads HashiCorp Vault KV version 2 secrets. A reference
+// vault://secret/mcpdrill/target#token reads the field token of the secret
+// mcpdrill/target in the KV mount secret.
+type VaultBackend struct {
+	addr      string
+	token     string
+	namespace string
+	client    *http.Client
+}
+
+// NewVaultBackend creates a Vault backend for the server at addr.
+func NewVaultBackend(addr, token, namespace string) *VaultBackend {
+	return &VaultBackend{
+		addr:      strings.TrimRight(addr, "/"),
+		token:     token,
+		namespace: namespace,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Fetch implements Backend.
+func (b *VaultBackend) Fetch(ctx context.Context, ref Ref) (string, error) {
+	mount, path, ok := strings.Cut(strings.Trim(ref.Path, "/"), "/")
+	if !ok || path == "" {
+		return "", fmt.Errorf("vault reference must be vault://<mount>/<path>")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.addr+"/v1/"+mount+"/data/"+path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", b.token)
+	if b.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", b.namespace)
+	}
+
+	body, err := doSecretRequest(b.client, req)
+	if err != nil {
+		return "", fmt.Errorf("vault: %w", err)
+	}
+	var payload struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return "", fmt.Errorf("vault: decode response: %w", err)
+	}
+	if payload.Data.Data == nil {
+		return "", fmt.Errorf("vault: secret has no data (deleted version?)")
+	}
+	return selectField(payload.Data.Data, ref.Key)
+}
+
+// doSecretRequest sends req and returns the body of a 200 response.
+func doSecretRequest(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		// Error bodies of secret stores name the secret, never its value.
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body[:min(len(body), 256)])))
+	}
+	return body, nil
+}
@@ -17,9 +17,13 @@ func buildInitializeParams(config *SessionConfig) *transport.InitializeParams {
 	if version == "" {
 		version = mcp.DefaultProtocolVersion
 	}
+	var serverRequests *transport.ServerRequestsConfig
+	if config.TransportConfig != nil {
+		serverRequests = config.TransportConfig.ServerRequests
+	}
 	return &transport.InitializeParams{
 		ProtocolVersion: version,
-		Capabilities:    make(map[string]interface{}),
+		Capabilities:    serverRequests.ClientCapabilities(),
 		ClientInfo: transport.ClientInfo{
 			Name:    mcp.ClientName,
 			Version: mcp.ClientVersion,
@@ -67,10 +71,13 @@ func closeWithLog(closer io.Closer, name string) {
 	}
 }
 
-func connectAndInitialize(ctx context.Context, config *SessionConfig) (transport.Connection, string, error) {
+// connectAndInitialize opens a connection and runs the initialize
+// handshake on it. It returns the connection, the session ID and the outcome
+// of the initialize request.
+func connectAndInitialize(ctx context.Context, config *SessionConfig) (transport.Connection, string, *transport.OperationOutcome, error) {
 	conn, err := config.Adapter.Connect(ctx, config.TransportConfig)
 	if err != nil {
-		return nil, "", &SessionError{Op: "connect", Err: err}
+		return nil, "", nil, &SessionError{Op: "connect", Err: err}
 	}
 
 	params := buildInitializeParams(config)
@@ -78,26 +85,26 @@ func connectAndInitialize(ctx context.Context, config *SessionConfig) (transport
 	outcome, err := conn.Initialize(ctx, params)
 	if err != nil {
 		closeWithLog(conn, "connection")
-		return nil, "", &SessionError{Op: "initialize", Err: err}
+		return nil, "", nil, &SessionError{Op: "initialize", Err: err}
 	}
 
 	if !outcome.OK {
 		closeWithLog(conn, "connection")
 		if outcome.Error != nil {
-			return nil, "", &SessionError{Op: "initialize", Err: outcome.Error}
+			return nil, "", nil, &SessionError{Op: "initialize", Err: outcome.Error}
 		}
-		return nil, "", &SessionError{Op: "initialize", Err: errSessionClosed}
+		return nil, "", nil, &SessionError{Op: "initialize", Err: errSessionClosed}
 	}
 
 	if err := validateProtocolVersion(config, outcome); err != nil {
 		closeWithLog(conn, "connection")
-		return nil, "", &SessionError{Op: "version_negotiation", Err: err}
+		return nil, "", nil, &SessionError{Op: "version_negotiation", Err: err}
 	}
 
 	_, err = conn.SendInitialized(ctx)
 	if err != nil {
 		closeWithLog(conn, "connection")
-		return nil, "", &SessionError{Op: "send_initialized", Err: err}
+		return nil, "", nil, &SessionError{Op: "send_initialized", Err: err}
 	}
 
 	sessionID := conn.SessionID()
@@ -105,7 +112,7 @@ func connectAndInitialize(ctx context.Context, config *SessionConfig) (transport
 		sessionID = generateSessionID()
 	}
 
-	return conn, sessionID, nil
+	return conn, sessionID, outcome, nil
 }
 
 type ReuseMode struct {
@@ -290,11 +297,12 @@ func (rm *ReuseMode) Metrics() *SessionMetrics {
 }
 
 func (rm *ReuseMode) createSession(ctx context.Context, vuID string) (*SessionInfo, error) {
-	conn, sessionID, err := connectAndInitialize(ctx, rm.config)
+	conn, sessionID, initOutcome, err := connectAndInitialize(ctx, rm.config)
 	if err != nil {
 		return nil, err
 	}
 	session := NewSessionInfo(sessionID, conn, rm.config.TTLMs, rm.config.MaxIdleMs)
+	session.InitializeOutcome = initOutcome
 	session.VUID = vuID
 	return session, nil
 }
@@ -371,11 +379,12 @@ func (pm *PerRequestMode) Metrics() *SessionMetrics {
 }
 
 func (pm *PerRequestMode) createSession(ctx context.Context, vuID string) (*SessionInfo, error) {
-	conn, sessionID, err := connectAndInitialize(ctx, pm.config)
+	conn, sessionID, initOutcome, err := connectAndInitialize(ctx, pm.config)
 	if err != nil {
 		return nil, err
 	}
 	session := NewSessionInfo(sessionID, conn, 0, 0)
+	session.InitializeOutcome = initOutcome
 	session.VUID = vuID
 	return session, nil
 }
@@ -459,23 +468,29 @@ func (pm *PoolMode) Metrics() *SessionMetrics {
 }
 
 func (pm *PoolMode) createSession(ctx context.Context) (*SessionInfo, error) {
-	conn, sessionID, err := connectAndInitialize(ctx, pm.config)
+	conn, sessionID, initOutcome, err := connectAndInitialize(ctx, pm.config)
 	if err != nil {
 		return nil, err
 	}
-	return NewSessionInfo(sessionID, conn, pm.config.TTLMs, pm.config.MaxIdleMs), nil
+	session := NewSessionInfo(sessionID, conn, pm.config.TTLMs, pm.config.MaxIdleMs)
+	session.InitializeOutcome = initOutcome
+	return session, nil
 }
 
+// ChurnMode gives each VU its own session and replaces it once it is due:
+// after ChurnIntervalOps operations, or ChurnIntervalMs after it was created.
+// A due session stops being handed out right away but stays open until the
+// operations still using it release it.
 type ChurnMode struct {
 	config           *SessionConfig
 	churnInterval    time.Duration
 	churnIntervalOps int64
 	useOpsBased      bool
 
-	mu          sync.RWMutex
-	sessions    map[string]*churnSession
-	sessionToVU map[string]string
-	closed      atomic.Bool
+	mu       sync.Mutex
+	sessions map[string]*churnSession // vuID -> current session
+	byID     map[string]*churnSession // session.ID -> current or retired session
+	closed   atomic.Bool
 
 	totalCreated atomic.Int64
 	totalEvicted atomic.Int64
@@ -483,15 +498,18 @@ type ChurnMode struct {
 
 type churnSession struct {
 	session   *SessionInfo
+	vuID      string
 	createdAt time.Time
 	opCount   int64
+	inUse     int
+	retired   bool
 }
 
 func NewChurnMode(config *SessionConfig) *ChurnMode {
 	cm := &ChurnMode{
-		config:      config,
-		sessions:    make(map[string]*churnSession),
-		sessionToVU: make(map[string]string),
+		config:   config,
+		sessions: make(map[string]*churnSession),
+		byID:     make(map[string]*churnSession),
 	}
 
 	if config.ChurnIntervalOps > 0 {
@@ -512,33 +530,19 @@ func (cm *ChurnMode) Acquire(ctx context.Context, vuID string) (*SessionInfo, er
 		return nil, ErrManagerClosed
 	}
 
-	cm.mu.RLock()
-	cs, exists := cm.sessions[vuID]
-	cm.mu.RUnlock()
-
-	if exists {
-		shouldChurn := false
-		if cm.useOpsBased {
-			if cs.opCount >= cm.churnIntervalOps {
-				shouldChurn = true
-			}
+	cm.mu.Lock()
+	if cs, exists := cm.sessions[vuID]; exists {
+		if cm.due(cs) || cs.session.IsExpired() || cs.session.GetState() == StateClosed {
+			cm.retireLocked(cs)
 		} else {
-			if time.Since(cs.createdAt) >= cm.churnInterval {
-				shouldChurn = true
-			}
+			cs.inUse++
+			cs.session.SetState(StateActive)
+			cs.session.Touch(cm.config.MaxIdleMs)
+			cm.mu.Unlock()
+			return cs.session, nil
 		}
-
-		if shouldChurn || cs.session.IsExpired() || cs.session.GetState() == StateClosed {
-			cm.Invalidate(ctx, cs.session)
-			exists = false
-		}
-	}
-
-	if exists {
-		cs.session.SetState(StateActive)
-		cs.session.Touch(cm.config.MaxIdleMs)
-		return cs.session, nil
 	}
+	cm.mu.Unlock()
 
 	session, err := cm.createSession(ctx, vuID)
 	if err != nil {
@@ -546,35 +550,87 @@ func (cm *ChurnMode) Acquire(ctx context.Context, vuID string) (*SessionInfo, er
 	}
 
 	cm.mu.Lock()
-	cm.sessions[vuID] = &churnSession{
+	defer cm.mu.Unlock()
+
+	if cm.closed.Load() {
+		session.SetState(StateClosed)
+		closeWithLog(session.Connection, "session connection")
+		return nil, ErrManagerClosed
+	}
+
+	// Another operation of the VU replaced the session first; share its.
+	if existing, ok := cm.sessions[vuID]; ok {
+		session.SetState(StateClosed)
+		closeWithLog(session.Connection, "session connection")
+		existing.inUse++
+		existing.session.SetState(StateActive)
+		existing.session.Touch(cm.config.MaxIdleMs)
+		return existing.session, nil
+	}
+
+	cs := &churnSession{
 		session:   session,
+		vuID:      vuID,
 		createdAt: time.Now(),
-		opCount:   0,
+		inUse:     1,
 	}
-	cm.sessionToVU[session.ID] = vuID
-	cm.mu.Unlock()
-
+	cm.sessions[vuID] = cs
+	cm.byID[session.ID] = cs
 	cm.totalCreated.Add(1)
 	return session, nil
 }
 
+// due reports whether cs has reached the churn interval.
+func (cm *ChurnMode) due(cs *churnSession) bool {
+	if cm.useOpsBased {
+		return cs.opCount >= cm.churnIntervalOps
+	}
+	return time.Since(cs.createdAt) >= cm.churnInterval
+}
+
+// retireLocked stops handing out cs and closes it once no operation uses
+// it. The caller holds cm.mu.
+func (cm *ChurnMode) retireLocked(cs *churnSession) {
+	delete(cm.sessions, cs.vuID)
+	cs.retired = true
+	cm.totalEvicted.Add(1)
+	if cs.inUse == 0 {
+		cm.closeLocked(cs)
+	}
+}
+
+func (cm *ChurnMode) closeLocked(cs *churnSession) {
+	delete(cm.byID, cs.session.ID)
+	cs.session.SetState(StateClosed)
+	if cs.session.Connection != nil {
+		closeWithLog(cs.session.Connection, "session connection")
+	}
+}
+
 func (cm *ChurnMode) Release(ctx context.Context, session *SessionInfo) error {
 	if cm.closed.Load() {
 		return nil
 	}
 
-	session.SetState(StateIdle)
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
 
-	if cm.useOpsBased {
-		cm.mu.Lock()
-		if vuID, ok := cm.sessionToVU[session.ID]; ok {
-			if cs, exists := cm.sessions[vuID]; exists {
-				cs.opCount++
-			}
+	cs, ok := cm.byID[session.ID]
+	if !ok {
+		return nil
+	}
+	cs.inUse--
+	if cs.retired {
+		if cs.inUse <= 0 {
+			cm.closeLocked(cs)
 		}
-		cm.mu.Unlock()
+		return nil
 	}
 
+	cs.opCount++
+	if cs.inUse <= 0 {
+		session.SetState(StateIdle)
+	}
 	return nil
 }
 
@@ -582,9 +638,11 @@ func (cm *ChurnMode) Invalidate(ctx context.Context, session *SessionInfo) error
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
 
-	if vuID, ok := cm.sessionToVU[session.ID]; ok {
-		delete(cm.sessions, vuID)
-		delete(cm.sessionToVU, session.ID)
+	if cs, ok := cm.byID[session.ID]; ok {
+		delete(cm.byID, session.ID)
+		if !cs.retired {
+			delete(cm.sessions, cs.vuID)
+		}
 	}
 
 	session.SetState(StateClosed)
@@ -604,31 +662,30 @@ func (cm *ChurnMode) Close(ctx context.Context) error {
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
 
-	for _, cs := range cm.sessions {
+	for _, cs := range cm.byID {
 		cs.session.SetState(StateClosed)
 		if cs.session.Connection != nil {
 			closeWithLog(cs.session.Connection, "session connection")
 		}
 	}
 	cm.sessions = make(map[string]*churnSession)
-	cm.sessionToVU = make(map[string]string)
+	cm.byID = make(map[string]*churnSession)
 
 	return nil
 }
 
 func (cm *ChurnMode) Metrics() *SessionMetrics {
-	cm.mu.RLock()
+	cm.mu.Lock()
 	active := int64(0)
 	idle := int64(0)
 	for _, cs := range cm.sessions {
-		switch cs.session.GetState() {
-		case StateActive:
+		if cs.inUse > 0 {
 			active++
-		case StateIdle:
+		} else {
 			idle++
 		}
 	}
-	cm.mu.RUnlock()
+	cm.mu.Unlock()
 
 	return &SessionMetrics{
 		ActiveSessions: active,
@@ -647,12 +704,13 @@ func (cm *ChurnMode) ChurnIntervalOps() int64 {
 }
 
 func (cm *ChurnMode) createSession(ctx context.Context, vuID string) (*SessionInfo, error) {
-	conn, sessionID, err := connectAndInitialize(ctx, cm.config)
+	conn, sessionID, initOutcome, err := connectAndInitialize(ctx, cm.config)
 	if err != nil {
 		return nil, err
 	}
 	session := NewSessionInfo(sessionID, conn, cm.config.TTLMs, cm.config.MaxIdleMs)
 	session.VUID = vuID
+	session.InitializeOutcome = initOutcome
 	return session, nil
 }
 
@@ -135,6 +135,13 @@ type SessionInfo struct {
 	// Connection is the underlying transport connection.
 	Connection transport.Connection
 
+	// InitializeOutcome is the outcome of the initialize request that
+	// created the session.
+	InitializeOutcome *transport.OperationOutcome
+
+	// initializeTaken is set once TakeInitializeOutcome has returned it.
+	initializeTaken bool
+
 	// mu protects mutable fields.
 	mu sync.RWMutex
 }
@@ -210,6 +217,19 @@ func (s *SessionInfo) GetState() SessionState {
 	return s.State
 }
 
+// TakeInitializeOutcome returns the outcome of the session's initialize
+// request the first time it is called, and nil after that, so a session
+// shared by several operations is reported once.
+func (s *SessionInfo) TakeInitializeOutcome() *transport.OperationOutcome {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.initializeTaken {
+		return nil
+	}
+	s.initializeTaken = true
+	return s.InitializeOutcome
+}
+
 // SessionMetrics contains metrics about session management.
 type SessionMetrics struct {
 	// ActiveSessions is the current number of active sessions.
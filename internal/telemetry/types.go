@@ -155,6 +155,18 @@ type StreamInfo struct {
 	EventGapMax int64   `json:"event_gap_max_ms,omitempty"`
 	EventGapAvg float64 `json:"event_gap_avg_ms,omitempty"`
 	EventGapP95 int64   `json:"event_gap_p95_ms,omitempty"`
+
+	ResumeAttempts int  `json:"resume_attempts,omitempty"`
+	Resumed        bool `json:"resumed,omitempty"`
+
+	ServerMessages    map[string]int `json:"server_messages,omitempty"`
+	ServerReplies     int            `json:"server_replies,omitempty"`
+	ServerReplyErrors int            `json:"server_reply_errors,omitempty"`
+
+	ProgressCount           int   `json:"progress_count,omitempty"`
+	TimeToFirstProgressMs   int64 `json:"time_to_first_progress_ms,omitempty"`
+	ProgressTokenMismatches int   `json:"progress_token_mismatches,omitempty"`
+	ProgressInvalid         int   `json:"progress_invalid,omitempty"`
 }
 
 type PhaseTimingInfo struct {
@@ -282,6 +294,16 @@ func NewOpLogFromOutcome(
 			TimeToFirstEventMs: outcome.Stream.TimeToFirstEventMs,
 			StallCount:         outcome.Stream.StallCount,
 			TotalStallSeconds:  outcome.Stream.TotalStallSeconds,
+			ResumeAttempts:     outcome.Stream.ResumeAttempts,
+			Resumed:            outcome.Stream.Resumed,
+			ServerMessages:     outcome.Stream.ServerMessages,
+			ServerReplies:      outcome.Stream.ServerReplies,
+			ServerReplyErrors:  outcome.Stream.ServerReplyErrors,
+
+			ProgressCount:           outcome.Stream.ProgressCount,
+			TimeToFirstProgressMs:   outcome.Stream.TimeToFirstProgressMs,
+			ProgressTokenMismatches: outcome.Stream.ProgressTokenMismatches,
+			ProgressInvalid:         outcome.Stream.ProgressInvalid,
 		}
 		if outcome.Stream.EventGapHistogram != nil {
 			log.Stream.EventGapMin = outcome.Stream.EventGapHistogram.MinGapMs
@@ -0,0 +1,191 @@
+package templating
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// expression is a parsed ${...} body: a variable reference when call is
+// false, otherwise a function call with literal arguments.
+type expression struct {
+	name string
+	call bool
+	args []interface{}
+}
+
+func parseExpression(src string) (*expression, error) {
+	src = strings.TrimSpace(src)
+	if src == "" {
+		return nil, fmt.Errorf("empty expression")
+	}
+
+	open := strings.IndexByte(src, '(')
+	if open < 0 {
+		if !isPath(src) {
+			return nil, fmt.Errorf("invalid variable name %q", src)
+		}
+		return &expression{name: src}, nil
+	}
+
+	name := strings.TrimSpace(src[:open])
+	if !isIdentifier(name) {
+		return nil, fmt.Errorf("invalid function name %q", name)
+	}
+	if !strings.HasSuffix(src, ")") {
+		return nil, fmt.Errorf("missing ) in %q", src)
+	}
+	args, err := parseArgs(src[open+1 : len(src)-1])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return &expression{name: name, call: true, args: args}, nil
+}
+
+// parseArgs splits a comma-separated list of quoted strings and numbers.
+func parseArgs(src string) ([]interface{}, error) {
+	var args []interface{}
+	src = strings.TrimSpace(src)
+	for src != "" {
+		var (
+			arg  interface{}
+			rest string
+		)
+		if src[0] == '"' || src[0] == '\'' {
+			end := closingQuote(src)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			unquoted, err := unquote(src[:end+1])
+			if err != nil {
+				return nil, err
+			}
+			arg, rest = unquoted, src[end+1:]
+		} else {
+			token := src
+			if comma := strings.IndexByte(src, ','); comma >= 0 {
+				token, rest = src[:comma], src[comma:]
+			}
+			token = strings.TrimSpace(token)
+			n, err := parseNumber(token)
+			if err != nil {
+				return nil, err
+			}
+			arg = n
+		}
+		args = append(args, arg)
+
+		rest = strings.TrimSpace(rest)
+		if rest == "" {
+			break
+		}
+		if rest[0] != ',' {
+			return nil, fmt.Errorf("expected , before %q", rest)
+		}
+		src = strings.TrimSpace(rest[1:])
+		if src == "" {
+			return nil, fmt.Errorf("trailing ,")
+		}
+	}
+	return args, nil
+}
+
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == s[0] {
+			return i
+		}
+	}
+	return -1
+}
+
+func unquote(s string) (string, error) {
+	if s[0] == '\'' {
+		s = `"` + strings.ReplaceAll(strings.ReplaceAll(s[1:len(s)-1], `\'`, `'`), `"`, `\"`) + `"`
+	}
+	unquoted, err := strconv.Unquote(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid string %s", s)
+	}
+	return unquoted, nil
+}
+
+func parseNumber(token string) (interface{}, error) {
+	if i, err := strconv.ParseInt(token, 10, 64); err == nil {
+		return i, nil
+	}
+	if f, err := strconv.ParseFloat(token, 64); err == nil {
+		return f, nil
+	}
+	return nil, fmt.Errorf("argument %q is not a number or quoted string", token)
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if r == '_' || unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r)) {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// isPath reports whether s is a dotted variable name such as feed.users.email.
+func isPath(s string) bool {
+	for _, segment := range strings.Split(s, ".") {
+		if !isIdentifier(segment) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *expression) check(isVariable func(string) bool) error {
+	if !e.call {
+		if builtinVars[e.name] || (isVariable != nil && isVariable(e.name)) {
+			return nil
+		}
+		return fmt.Errorf("unknown variable %q", e.name)
+	}
+	fn, ok := functions[e.name]
+	if !ok {
+		return fmt.Errorf("unknown function %q", e.name)
+	}
+	if len(e.args) < fn.minArgs || (fn.maxArgs >= 0 && len(e.args) > fn.maxArgs) {
+		return fmt.Errorf("%s: %s", e.name, fn.arity())
+	}
+	return nil
+}
+
+// checkArgs calls a function once to check its arguments. They are
+// literals, so a call that fails on them would fail every time.
+func (e *expression) checkArgs() error {
+	if !e.call {
+		return nil
+	}
+	_, err := e.eval(&Context{Rand: rand.New(rand.NewSource(1))})
+	return err
+}
+
+func (e *expression) eval(ctx *Context) (interface{}, error) {
+	if !e.call {
+		return ctx.variable(e.name)
+	}
+	if err := e.check(nil); err != nil {
+		return nil, err
+	}
+	v, err := functions[e.name].eval(ctx, e.args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", e.name, err)
+	}
+	return v, nil
+}
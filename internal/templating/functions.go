@@ -0,0 +1,235 @@
+package templating
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+type function struct {
+	minArgs int
+	maxArgs int // -1 for no limit
+	eval    func(ctx *Context, args []interface{}) (interface{}, error)
+}
+
+func (f function) arity() string {
+	switch {
+	case f.maxArgs < 0:
+		return fmt.Sprintf("takes at least %d arguments", f.minArgs)
+	case f.minArgs == f.maxArgs:
+		return fmt.Sprintf("takes %d arguments", f.minArgs)
+	default:
+		return fmt.Sprintf("takes %d to %d arguments", f.minArgs, f.maxArgs)
+	}
+}
+
+var functions = map[string]function{
+	"uuid":         {0, 0, fnUUID},
+	"randInt":      {2, 2, fnRandInt},
+	"randFloat":    {2, 2, fnRandFloat},
+	"randBool":     {0, 0, fnRandBool},
+	"randomString": {1, 1, fnRandomString},
+	"randomChoice": {1, -1, fnRandomChoice},
+	"timestamp":    {0, 0, fnTimestamp},
+	"now":          {0, 0, fnNow},
+	"firstName":    {0, 0, pick(firstNames)},
+	"lastName":     {0, 0, pick(lastNames)},
+	"fullName":     {0, 0, fnFullName},
+	"email":        {0, 0, fnEmail},
+	"word":         {0, 0, pick(words)},
+	"sentence":     {0, 1, fnSentence},
+	"ipv4":         {0, 0, fnIPv4},
+}
+
+func (c *Context) rng() *rand.Rand {
+	if c.Rand == nil {
+		c.Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return c.Rand
+}
+
+func intArg(args []interface{}, i int) (int64, error) {
+	switch v := args[i].(type) {
+	case int64:
+		return v, nil
+	case float64:
+		if v == float64(int64(v)) {
+			return int64(v), nil
+		}
+	}
+	return 0, fmt.Errorf("argument %d must be an integer", i+1)
+}
+
+func floatArg(args []interface{}, i int) (float64, error) {
+	switch v := args[i].(type) {
+	case int64:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	}
+	return 0, fmt.Errorf("argument %d must be a number", i+1)
+}
+
+func fnUUID(ctx *Context, _ []interface{}) (interface{}, error) {
+	var b [16]byte
+	ctx.rng().Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// fnRandInt returns an integer in [min, max].
+func fnRandInt(ctx *Context, args []interface{}) (interface{}, error) {
+	lo, err := intArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	hi, err := intArg(args, 1)
+	if err != nil {
+		return nil, err
+	}
+	if hi < lo {
+		return nil, fmt.Errorf("max %d is below min %d", hi, lo)
+	}
+	return lo + int64(uint64n(ctx.rng(), uint64(hi)-uint64(lo))), nil
+}
+
+// uint64n returns a uniform integer in [0, span]. Unlike Int63n it takes
+// spans too wide for an int64, up to the full range.
+func uint64n(rng *rand.Rand, span uint64) uint64 {
+	if span < math.MaxInt64 {
+		return uint64(rng.Int63n(int64(span) + 1))
+	}
+	if span == math.MaxUint64 {
+		return rng.Uint64()
+	}
+	// span+1 is above 2^63, so fewer than half of the draws are rejected.
+	for {
+		if v := rng.Uint64(); v <= span {
+			return v
+		}
+	}
+}
+
+// fnRandFloat returns a float in [min, max).
+func fnRandFloat(ctx *Context, args []interface{}) (interface{}, error) {
+	lo, err := floatArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	hi, err := floatArg(args, 1)
+	if err != nil {
+		return nil, err
+	}
+	if hi < lo {
+		return nil, fmt.Errorf("max %v is below min %v", hi, lo)
+	}
+	return lo + ctx.rng().Float64()*(hi-lo), nil
+}
+
+func fnRandBool(ctx *Context, _ []interface{}) (interface{}, error) {
+	return ctx.rng().Intn(2) == 1, nil
+}
+
+const alphanumeric = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// maxRandomStringLength bounds randomString so a typo cannot exhaust memory.
+const maxRandomStringLength = 1 << 20
+
+func fnRandomString(ctx *Context, args []interface{}) (interface{}, error) {
+	n, err := intArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 || n > maxRandomStringLength {
+		return nil, fmt.Errorf("length must be between 0 and %d", maxRandomStringLength)
+	}
+	rng := ctx.rng()
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = alphanumeric[rng.Intn(len(alphanumeric))]
+	}
+	return string(b), nil
+}
+
+func fnRandomChoice(ctx *Context, args []interface{}) (interface{}, error) {
+	return args[ctx.rng().Intn(len(args))], nil
+}
+
+func fnTimestamp(*Context, []interface{}) (interface{}, error) {
+	return time.Now().UnixMilli(), nil
+}
+
+func fnNow(*Context, []interface{}) (interface{}, error) {
+	return time.Now().UTC().Format(time.RFC3339Nano), nil
+}
+
+func pick(list []string) func(*Context, []interface{}) (interface{}, error) {
+	return func(ctx *Context, _ []interface{}) (interface{}, error) {
+		return list[ctx.rng().Intn(len(list))], nil
+	}
+}
+
+func fnFullName(ctx *Context, _ []interface{}) (interface{}, error) {
+	rng := ctx.rng()
+	return firstNames[rng.Intn(len(firstNames))] + " " + lastNames[rng.Intn(len(lastNames))], nil
+}
+
+func fnEmail(ctx *Context, _ []interface{}) (interface{}, error) {
+	rng := ctx.rng()
+	first := strings.ToLower(firstNames[rng.Intn(len(firstNames))])
+	last := strings.ToLower(lastNames[rng.Intn(len(lastNames))])
+	return fmt.Sprintf("%s.%s%d@%s", first, last, rng.Intn(10000), emailDomains[rng.Intn(len(emailDomains))]), nil
+}
+
+// fnSentence returns n random words (default 8) as a sentence.
+func fnSentence(ctx *Context, args []interface{}) (interface{}, error) {
+	n := int64(8)
+	if len(args) == 1 {
+		var err error
+		if n, err = intArg(args, 0); err != nil {
+			return nil, err
+		}
+		if n < 1 || n > 1000 {
+			return nil, fmt.Errorf("word count must be between 1 and 1000")
+		}
+	}
+	rng := ctx.rng()
+	out := make([]string, n)
+	for i := range out {
+		out[i] = words[rng.Intn(len(words))]
+	}
+	out[0] = strings.ToUpper(out[0][:1]) + out[0][1:]
+	return strings.Join(out, " ") + ".", nil
+}
+
+// fnIPv4 returns an address from the documentation ranges, so generated
+// values never point at real hosts.
+func fnIPv4(ctx *Context, _ []interface{}) (interface{}, error) {
+	rng := ctx.rng()
+	prefixes := []string{"192.0.2", "198.51.100", "203.0.113"}
+	return fmt.Sprintf("%s.%d", prefixes[rng.Intn(len(prefixes))], 1+rng.Intn(254)), nil
+}
+
+var firstNames = []string{
+	"Alex", "Sam", "Jordan", "Taylor", "Morgan", "Casey", "Riley", "Jamie",
+	"Avery", "Quinn", "Maria", "Wei", "Aisha", "Diego", "Yuki", "Omar",
+	"Priya", "Lukas", "Fatima", "Mateo", "Sofia", "Noah", "Elena", "Kofi",
+}
+
+var lastNames = []string{
+	"Smith", "Garcia", "Kim", "Nguyen", "Meyer", "Rossi", "Silva", "Khan",
+	"Chen", "Okafor", "Novak", "Johansson", "Dubois", "Tanaka", "Cohen",
+	"Patel", "Hernandez", "Kowalski", "Ivanova", "Mensah",
+}
+
+var emailDomains = []string{"example.com", "example.org", "example.net"}
+
+var words = []string{
+	"alpha", "bridge", "cloud", "data", "engine", "fabric", "graph", "harbor",
+	"index", "journal", "kernel", "ledger", "matrix", "node", "orbit", "packet",
+	"query", "router", "signal", "token", "update", "vector", "window", "yield",
+	"zone", "buffer", "cache", "daemon", "event", "filter", "gateway", "handle",
+}
@@ -0,0 +1,264 @@
+// Package templating renders ${...} expressions in workload arguments, so
+// each call can send a fresh payload. An expression is either a variable such
+// as ${vu_id} or a function call such as ${randInt(1, 100)}. A string that is
+// a single expression keeps the expression's type; otherwise the results are
+// formatted into the surrounding text. $${ produces a literal ${.
+package templating
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// Built-in variables set by the worker for every call.
+const (
+	VarVUID      = "vu_id"
+	VarIteration = "iteration"
+	VarRunID     = "run_id"
+	VarStageID   = "stage_id"
+	VarWorkerID  = "worker_id"
+)
+
+// WorkflowVarPrefix starts the references to workflow variables, which
+// earlier steps of a workflow take from their results: ${vars.<name>}.
+const WorkflowVarPrefix = "vars."
+
+var builtinVars = map[string]bool{
+	VarVUID:      true,
+	VarIteration: true,
+	VarRunID:     true,
+	VarStageID:   true,
+	VarWorkerID:  true,
+}
+
+// Context supplies variables and randomness to one render.
+type Context struct {
+	// Vars holds the variable values by name.
+	Vars map[string]interface{}
+
+	// Lookup resolves variables missing from Vars. Optional.
+	Lookup func(name string) (interface{}, bool)
+
+	// Rand is the random source for the faker functions. It is not safe for
+	// concurrent use, so callers serialize renders that share it.
+	Rand *rand.Rand
+}
+
+func (c *Context) variable(name string) (interface{}, error) {
+	if v, ok := c.Vars[name]; ok {
+		return v, nil
+	}
+	if c.Lookup != nil {
+		if v, ok := c.Lookup(name); ok {
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown variable %q", name)
+}
+
+// HasExpressions reports whether value contains any ${...} expression.
+func HasExpressions(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		return strings.Contains(v, "${")
+	case map[string]interface{}:
+		for _, child := range v {
+			if HasExpressions(child) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, child := range v {
+			if HasExpressions(child) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RenderArguments renders every string in args and returns a new map. args
+// is returned as is when it has no expressions.
+func RenderArguments(args map[string]interface{}, ctx *Context) (map[string]interface{}, error) {
+	if !HasExpressions(args) {
+		return args, nil
+	}
+	rendered, err := Render(args, ctx)
+	if err != nil {
+		return nil, err
+	}
+	return rendered.(map[string]interface{}), nil
+}
+
+// Render renders the strings in value, descending into maps and slices.
+// value itself is never modified.
+func Render(value interface{}, ctx *Context) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return renderString(v, ctx)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			rendered, err := Render(child, ctx)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			out[key] = rendered
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, child := range v {
+			rendered, err := Render(child, ctx)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			out[i] = rendered
+		}
+		return out, nil
+	default:
+		return value, nil
+	}
+}
+
+// Validate parses every expression in value and checks function names,
+// argument counts and argument values. Variables must be built-in or accepted by isVariable,
+// which may be nil.
+func Validate(value interface{}, isVariable func(name string) bool) error {
+	switch v := value.(type) {
+	case string:
+		parts, err := parseString(v)
+		if err != nil {
+			return err
+		}
+		for _, p := range parts {
+			if p.expr == nil {
+				continue
+			}
+			if err := p.expr.check(isVariable); err != nil {
+				return err
+			}
+			if err := p.expr.checkArgs(); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		for key, child := range v {
+			if err := Validate(child, isVariable); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			if err := Validate(child, isVariable); err != nil {
+				return fmt.Errorf("[%d]: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+func renderString(s string, ctx *Context) (interface{}, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+	parts, err := parseString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) == 1 && parts[0].expr != nil {
+		return parts[0].expr.eval(ctx)
+	}
+
+	var b strings.Builder
+	for _, p := range parts {
+		if p.expr == nil {
+			b.WriteString(p.text)
+			continue
+		}
+		v, err := p.expr.eval(ctx)
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString(format(v))
+	}
+	return b.String(), nil
+}
+
+func format(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// part is either literal text or an expression.
+type part struct {
+	text string
+	expr *expression
+}
+
+func parseString(s string) ([]part, error) {
+	var (
+		parts []part
+		text  strings.Builder
+	)
+	for i := 0; i < len(s); {
+		if strings.HasPrefix(s[i:], "$${") {
+			text.WriteString("${")
+			i += 3
+			continue
+		}
+		if !strings.HasPrefix(s[i:], "${") {
+			text.WriteByte(s[i])
+			i++
+			continue
+		}
+		end := closingBrace(s, i+2)
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated expression in %q", s)
+		}
+		expr, err := parseExpression(s[i+2 : end])
+		if err != nil {
+			return nil, err
+		}
+		if text.Len() > 0 {
+			parts = append(parts, part{text: text.String()})
+			text.Reset()
+		}
+		parts = append(parts, part{expr: expr})
+		i = end + 1
+	}
+	if text.Len() > 0 || len(parts) == 0 {
+		parts = append(parts, part{text: text.String()})
+	}
+	return parts, nil
+}
+
+// closingBrace returns the index of the } that ends the expression starting
+// at from, skipping braces inside quoted arguments.
+func closingBrace(s string, from int) int {
+	var quote byte
+	for i := from; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '}':
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,233 @@
+package templating
+
+import (
+	"math/rand"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func newTestContext() *Context {
+	return &Context{
+		Vars: map[string]interface{}{
+			VarVUID:      "vu_3",
+			VarIteration: int64(7),
+		},
+		Rand: rand.New(rand.NewSource(1)),
+	}
+}
+
+func TestRender_PreservesTypeOfWholeExpression(t *testing.T) {
+	ctx := newTestContext()
+	args := map[string]interface{}{
+		"id":     "${uuid()}",
+		"count":  "${randInt(1, 100)}",
+		"ratio":  "${randFloat(0, 1)}",
+		"flag":   "${randBool()}",
+		"n":      "${iteration}",
+		"label":  "user-${vu_id}-${iteration}",
+		"static": 42,
+		"nested": map[string]interface{}{"tags": []interface{}{"${word()}", "fixed"}},
+		"escape": "cost: $${price}",
+	}
+
+	out, err := RenderArguments(args, ctx)
+	if err != nil {
+		t.Fatalf("RenderArguments: %v", err)
+	}
+
+	uuidPattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if id, _ := out["id"].(string); !uuidPattern.MatchString(id) {
+		t.Errorf("id = %v, want a v4 UUID", out["id"])
+	}
+	if n, ok := out["count"].(int64); !ok || n < 1 || n > 100 {
+		t.Errorf("count = %#v, want int64 in [1, 100]", out["count"])
+	}
+	if f, ok := out["ratio"].(float64); !ok || f < 0 || f >= 1 {
+		t.Errorf("ratio = %#v, want float64 in [0, 1)", out["ratio"])
+	}
+	if _, ok := out["flag"].(bool); !ok {
+		t.Errorf("flag = %#v, want bool", out["flag"])
+	}
+	if out["n"] != int64(7) {
+		t.Errorf("n = %#v, want 7", out["n"])
+	}
+	if out["label"] != "user-vu_3-7" {
+		t.Errorf("label = %v", out["label"])
+	}
+	if out["static"] != 42 {
+		t.Errorf("static = %v", out["static"])
+	}
+	if out["escape"] != "cost: ${price}" {
+		t.Errorf("escape = %v", out["escape"])
+	}
+	tags := out["nested"].(map[string]interface{})["tags"].([]interface{})
+	if w, _ := tags[0].(string); w == "" || strings.Contains(w, "${") || tags[1] != "fixed" {
+		t.Errorf("nested tags = %v", tags)
+	}
+
+	if args["id"] != "${uuid()}" {
+		t.Error("input arguments must not be modified")
+	}
+}
+
+func TestRender_UniquePerCall(t *testing.T) {
+	ctx := newTestContext()
+	args := map[string]interface{}{"key": "${randomString(32)}"}
+	seen := map[string]bool{}
+	for i := 0; i < 50; i++ {
+		out, err := RenderArguments(args, ctx)
+		if err != nil {
+			t.Fatalf("RenderArguments: %v", err)
+		}
+		s := out["key"].(string)
+		if len(s) != 32 {
+			t.Fatalf("randomString(32) length = %d", len(s))
+		}
+		seen[s] = true
+	}
+	if len(seen) != 50 {
+		t.Errorf("expected 50 distinct values, got %d", len(seen))
+	}
+}
+
+func TestRenderArguments_StaticReturnsSameMap(t *testing.T) {
+	args := map[string]interface{}{"path": "/tmp/a", "n": 1}
+	out, err := RenderArguments(args, newTestContext())
+	if err != nil {
+		t.Fatalf("RenderArguments: %v", err)
+	}
+	out["probe"] = true
+	if _, ok := args["probe"]; !ok {
+		t.Error("expected static arguments to be returned without copying")
+	}
+}
+
+func TestRender_Functions(t *testing.T) {
+	ctx := newTestContext()
+	tests := []struct {
+		expr  string
+		check func(interface{}) bool
+	}{
+		{`${randomChoice("red", 'green', 3)}`, func(v interface{}) bool {
+			return v == "red" || v == "green" || v == int64(3)
+		}},
+		{"${email()}", func(v interface{}) bool {
+			s, _ := v.(string)
+			return regexp.MustCompile(`^[a-z]+\.[a-z]+\d+@example\.(com|org|net)$`).MatchString(s)
+		}},
+		{"${fullName()}", func(v interface{}) bool { s, _ := v.(string); return strings.Count(s, " ") == 1 }},
+		{"${sentence(3)}", func(v interface{}) bool {
+			s, _ := v.(string)
+			return strings.Count(s, " ") == 2 && strings.HasSuffix(s, ".")
+		}},
+		{"${ipv4()}", func(v interface{}) bool {
+			s, _ := v.(string)
+			return strings.HasPrefix(s, "192.0.2.") || strings.HasPrefix(s, "198.51.100.") || strings.HasPrefix(s, "203.0.113.")
+		}},
+		{"${timestamp()}", func(v interface{}) bool { n, ok := v.(int64); return ok && n > 0 }},
+		{"${now()}", func(v interface{}) bool { s, _ := v.(string); return strings.HasSuffix(s, "Z") }},
+		{`${randomChoice("a}b")}`, func(v interface{}) bool { return v == "a}b" }},
+	}
+	for _, tt := range tests {
+		v, err := Render(tt.expr, ctx)
+		if err != nil {
+			t.Errorf("%s: %v", tt.expr, err)
+			continue
+		}
+		if !tt.check(v) {
+			t.Errorf("%s = %#v", tt.expr, v)
+		}
+	}
+}
+
+func TestRender_LookupVariables(t *testing.T) {
+	ctx := newTestContext()
+	ctx.Lookup = func(name string) (interface{}, bool) {
+		if name == "feed.users.email" {
+			return "a@example.com", true
+		}
+		return nil, false
+	}
+	v, err := Render("${feed.users.email}", ctx)
+	if err != nil || v != "a@example.com" {
+		t.Errorf("lookup = %v, %v", v, err)
+	}
+	if _, err := Render("${feed.users.name}", ctx); err == nil {
+		t.Error("expected unknown variable error")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := []interface{}{
+		"plain",
+		"${uuid()}",
+		map[string]interface{}{"a": []interface{}{"${randInt(1, 5)}", "${vu_id}"}},
+		"${sentence()} ${sentence(4)}",
+		`${randomChoice("x", "y")}`,
+		"${randInt(0, 9223372036854775807)}",
+	}
+	for _, v := range valid {
+		if err := Validate(v, nil); err != nil {
+			t.Errorf("Validate(%v) = %v", v, err)
+		}
+	}
+
+	invalid := []interface{}{
+		"${nope()}",
+		"${randInt(1)}",
+		"${uuid(1)}",
+		"${unknown_var}",
+		"${uuid()",
+		"${}",
+		"${randInt(1, x)}",
+		`${randomChoice("x",)}`,
+		map[string]interface{}{"deep": []interface{}{"${bad-name}"}},
+		"${randInt(5, 1)}",
+		"${randomString(-1)}",
+		"${sentence(0)}",
+	}
+	for _, v := range invalid {
+		if err := Validate(v, nil); err == nil {
+			t.Errorf("Validate(%v) succeeded, want error", v)
+		}
+	}
+
+	isFeed := func(name string) bool { return strings.HasPrefix(name, "feed.") }
+	if err := Validate("${feed.users.email}", isFeed); err != nil {
+		t.Errorf("expected extra variable to be accepted: %v", err)
+	}
+}
+
+func TestRender_RandIntWideRanges(t *testing.T) {
+	ctx := newTestContext()
+	for _, expr := range []string{
+		"${randInt(0, 9223372036854775807)}",
+		"${randInt(-9223372036854775808, 9223372036854775807)}",
+		"${randInt(-9223372036854775808, 0)}",
+		"${randInt(-5, 9223372036854775807)}",
+	} {
+		for i := 0; i < 100; i++ {
+			v, err := Render(expr, ctx)
+			if err != nil {
+				t.Fatalf("Render(%s): %v", expr, err)
+			}
+			if _, ok := v.(int64); !ok {
+				t.Fatalf("Render(%s) = %#v, want an int64", expr, v)
+			}
+		}
+	}
+	v, err := Render("${randInt(-5, -5)}", ctx)
+	if err != nil || v != int64(-5) {
+		t.Errorf("randInt(-5, -5) = %v, %v", v, err)
+	}
+}
+
+func TestRender_EvaluationErrors(t *testing.T) {
+	ctx := newTestContext()
+	for _, expr := range []string{"${randInt(5, 1)}", "${randomString(-1)}", "${randInt(1.5, 2)}"} {
+		if _, err := Render(expr, ctx); err == nil {
+			t.Errorf("Render(%s) succeeded, want error", expr)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Package tlsconfig builds the TLS configuration of the control plane's
+// listener and of the workers and agents that connect to it.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// Server loads the certificate and key the control plane serves HTTPS with.
+func Server(certFile, keyFile string) (*tls.Config, error) {
+	if certFile == "" || keyFile == "" {
+		return nil, errors.New("both a certificate and a key file are required")
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading certificate: %w", err)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// Client returns the TLS configuration for connecting to the control plane.
+// With caFile, only certificates issued by the CAs in it are trusted rather
+// than the system roots, pinning the control plane's CA. serverName
+// overrides the name the certificate is checked against. Without either it
+// returns nil, the defaults.
+func Client(caFile, serverName string) (*tls.Config, error) {
+	if caFile == "" && serverName == "" {
+		return nil, nil
+	}
+	config := &tls.Config{
+		ServerName: serverName,
+		MinVersion: tls.VersionTLS12,
+	}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates in %s", caFile)
+		}
+		config.RootCAs = pool
+	}
+	return config, nil
+}
+
+// HTTPClient returns an HTTP client using config, or http.DefaultClient if
+// config is nil.
+func HTTPClient(config *tls.Config) *http.Client {
+	if config == nil {
+		return http.DefaultClient
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+	return &http.Client{Transport: transport}
+}
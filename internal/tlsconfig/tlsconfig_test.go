@@ -0,0 +1,120 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeSelfSigned writes a self-signed certificate for 127.0.0.1 and its
+// key to dir and returns their paths.
+func writeSelfSigned(t *testing.T, dir, name string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		DNSNames:              []string{"drill.internal"},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile = filepath.Join(dir, name+".crt")
+	keyFile = filepath.Join(dir, name+".key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestServerAndPinnedClient(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeSelfSigned(t, dir, "control-plane")
+	otherCA, _ := writeSelfSigned(t, dir, "other")
+
+	serverConfig, err := Server(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("Server failed: %v", err)
+	}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.TLS = serverConfig
+	srv.StartTLS()
+	defer srv.Close()
+
+	get := func(caFile, serverName string) error {
+		t.Helper()
+		config, err := Client(caFile, serverName)
+		if err != nil {
+			t.Fatalf("Client failed: %v", err)
+		}
+		resp, err := HTTPClient(config).Get(srv.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+	if err := get(certFile, ""); err != nil {
+		t.Errorf("expected the pinned CA to be trusted, got %v", err)
+	}
+	if err := get(certFile, "drill.internal"); err != nil {
+		t.Errorf("expected the certificate to be checked against the server name, got %v", err)
+	}
+	if err := get(otherCA, ""); err == nil {
+		t.Error("expected a certificate of another CA to be refused")
+	}
+	if err := get(certFile, "elsewhere.internal"); err == nil {
+		t.Error("expected a certificate for another name to be refused")
+	}
+}
+
+func TestConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+	certFile, _ := writeSelfSigned(t, dir, "control-plane")
+
+	if _, err := Server(certFile, ""); err == nil {
+		t.Error("expected a certificate without a key to be refused")
+	}
+	if _, err := Client(filepath.Join(dir, "missing.crt"), ""); err == nil {
+		t.Error("expected a missing CA file to be refused")
+	}
+	notPEM := filepath.Join(dir, "not.pem")
+	os.WriteFile(notPEM, []byte("not a certificate"), 0o600)
+	if _, err := Client(notPEM, ""); err == nil {
+		t.Error("expected a CA file without certificates to be refused")
+	}
+	if config, err := Client("", ""); config != nil || err != nil {
+		t.Errorf("expected the defaults without settings, got %v, %v", config, err)
+	}
+	if HTTPClient(nil) != http.DefaultClient {
+		t.Error("expected the default client without a TLS config")
+	}
+}
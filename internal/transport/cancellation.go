@@ -0,0 +1,122 @@
+package transport
+
+import (
+	"context"
+	"time"
+)
+
+// OpCancelled is the notification a client sends to cancel a request in
+// flight.
+const OpCancelled OperationType = "notifications/cancelled"
+
+// DefaultCancelGrace is how long a cancelled call waits for the server to
+// stop when no grace period is set.
+const DefaultCancelGrace = 2 * time.Second
+
+// CancellationInfo describes the cancellation of a tools/call in flight.
+type CancellationInfo struct {
+	// AfterMs is when, from the start of the call, the cancellation was due.
+	AfterMs int64 `json:"after_ms"`
+	// Sent is set if notifications/cancelled went out before the response
+	// arrived. A call whose response came first was not cancelled.
+	Sent bool `json:"sent"`
+	// Honored is set if the server stopped: it sent no response within the
+	// grace period after the cancellation, or ended the stream without one.
+	Honored bool `json:"honored"`
+}
+
+type cancellationKey struct{}
+
+type cancellation struct {
+	after time.Duration
+	grace time.Duration
+}
+
+// WithCancellation returns a context whose tools/call is cancelled with
+// notifications/cancelled after the given delay. The call then waits up to
+// grace for the server to stop before giving up on the response; zero grace
+// means DefaultCancelGrace.
+func WithCancellation(ctx context.Context, after, grace time.Duration) context.Context {
+	if grace <= 0 {
+		grace = DefaultCancelGrace
+	}
+	return context.WithValue(ctx, cancellationKey{}, cancellation{after: after, grace: grace})
+}
+
+func cancellationFrom(ctx context.Context) (cancellation, bool) {
+	c, ok := ctx.Value(cancellationKey{}).(cancellation)
+	return c, ok
+}
+
+// NewCancelledNotification returns the notification cancelling the request
+// with the given wire ID.
+func NewCancelledNotification(requestID interface{}, reason string) *JSONRPCRequest {
+	return &JSONRPCRequest{
+		JSONRPC: "2.0",
+		Method:  string(OpCancelled),
+		Params: map[string]interface{}{
+			"requestId": requestID,
+			"reason":    reason,
+		},
+	}
+}
+
+// doCancellableRequest runs req like doRequest, but sends
+// notifications/cancelled for it once plan.after has passed and then gives
+// the server plan.grace to stop. A call the server stopped on is reported as
+// OK without a result; one it completed anyway keeps its outcome. Either way
+// the outcome records the cancellation.
+func (c *StreamableHTTPConnection) doCancellableRequest(
+	ctx context.Context,
+	plan cancellation,
+	req *JSONRPCRequest,
+	opType OperationType,
+	id requestID,
+	toolName string,
+) *OperationOutcome {
+	callCtx, abandon := context.WithCancel(ctx)
+	defer abandon()
+
+	info := &CancellationInfo{AfterMs: plan.after.Milliseconds()}
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		timer := time.NewTimer(plan.after)
+		defer timer.Stop()
+		select {
+		case <-done:
+			return
+		case <-timer.C:
+		}
+		notified := c.doNotification(ctx, NewCancelledNotification(id.wire, "cancellation test"), OpCancelled)
+		if !notified.OK {
+			return
+		}
+		info.Sent = true
+		grace := time.NewTimer(plan.grace)
+		defer grace.Stop()
+		select {
+		case <-done:
+		case <-grace.C:
+			abandon()
+		}
+	}()
+
+	outcome := c.doRequest(callCtx, req, opType, id, toolName)
+	close(done)
+	<-finished
+
+	outcome.Cancellation = info
+	if !info.Sent {
+		return outcome
+	}
+	abandoned := callCtx.Err() != nil && ctx.Err() == nil
+	endedWithout := outcome.Error != nil && outcome.Error.Code == CodeSSEDisconnect
+	if !outcome.OK && (abandoned || endedWithout) {
+		info.Honored = true
+		outcome.OK = true
+		outcome.Error = nil
+	}
+	return outcome
+}
@@ -0,0 +1,97 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newCancellableServer answers tools/call after 300ms, or ends the stream
+// without a response once the call is cancelled if honor is set. Cancelled
+// request IDs are reported on the returned channel.
+func newCancellableServer(t *testing.T, honor bool) (*httptest.Server, chan string) {
+	t.Helper()
+	cancelled := make(chan string, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params struct {
+				RequestID json.RawMessage `json:"requestId"`
+			} `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&msg)
+		if msg.Method == string(OpCancelled) {
+			cancelled <- string(msg.Params.RequestID)
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		w.Header().Set(HeaderContentType, ContentTypeSSE)
+		w.(http.Flusher).Flush()
+		deadline := time.After(300 * time.Millisecond)
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-deadline:
+				fmt.Fprintf(w, "data: {\"jsonrpc\":\"2.0\",\"id\":%s,\"result\":{\"content\":[]}}\n\n", msg.ID)
+				return
+			case <-time.After(10 * time.Millisecond):
+				if honor && len(cancelled) > 0 {
+					return
+				}
+			}
+		}
+	}))
+	return server, cancelled
+}
+
+func TestToolsCallCancellation(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		honor   bool
+		after   time.Duration
+		sent    bool
+		honored bool
+	}{
+		{"honored", true, 50 * time.Millisecond, true, true},
+		{"completed anyway", false, 50 * time.Millisecond, true, false},
+		{"response first", false, time.Second, false, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			server, cancelled := newCancellableServer(t, tc.honor)
+			defer server.Close()
+
+			conn, err := NewStreamableHTTPAdapter().Connect(context.Background(), &TransportConfig{
+				AllowPrivateNetworks: []string{"127.0.0.0/8"},
+				Endpoint:             server.URL,
+				Timeouts:             DefaultTimeoutConfig(),
+			})
+			if err != nil {
+				t.Fatalf("connect failed: %v", err)
+			}
+			defer conn.Close()
+
+			ctx := WithCancellation(context.Background(), tc.after, time.Second)
+			outcome, _ := conn.ToolsCall(ctx, &ToolsCallParams{Name: "slow"})
+			if !outcome.OK {
+				t.Fatalf("expected the call to succeed, got %v", outcome.Error)
+			}
+			info := outcome.Cancellation
+			if info == nil || info.Sent != tc.sent || info.Honored != tc.honored {
+				t.Fatalf("unexpected cancellation %+v", info)
+			}
+			if tc.sent && <-cancelled != fmt.Sprintf("%q", outcome.JSONRPCID) {
+				t.Error("expected the cancellation to name the call's request id")
+			}
+			if tc.honored && outcome.Result != nil {
+				t.Errorf("expected no result for an honored cancellation, got %s", outcome.Result)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package transport
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// ChaosConfig injects network faults into the requests of a connection, to
+// test how clients and servers hold up on a degraded network.
+type ChaosConfig struct {
+	// LatencyMs is added before every request is sent, varied by up to
+	// JitterMs either way.
+	LatencyMs int64
+	JitterMs  int64
+	// ResetRate is the share of requests whose connection is reset after
+	// the request went out, so the server may have acted on it but the
+	// response is lost.
+	ResetRate float64
+	// BandwidthBytesPerSec caps how fast request and response bodies are
+	// transferred. Zero leaves bandwidth unlimited.
+	BandwidthBytesPerSec int64
+}
+
+// ChaosInfo records the faults injected into an operation's requests.
+type ChaosInfo struct {
+	// DelayMs is the latency added, jitter included.
+	DelayMs int64 `json:"delay_ms,omitempty"`
+	// Reset is set if the connection was reset in place of the response.
+	Reset bool `json:"reset,omitempty"`
+	// ThrottledMs is the time spent waiting on the bandwidth cap.
+	ThrottledMs int64 `json:"throttled_ms,omitempty"`
+}
+
+// injected reports whether any fault was injected.
+func (i *ChaosInfo) injected() bool {
+	return i.DelayMs > 0 || i.Reset || i.ThrottledMs > 0
+}
+
+// chaosRecorder collects the faults injected into one operation. The body
+// of a response may be read on another goroutine, so it is updated
+// atomically.
+type chaosRecorder struct {
+	delayMs     atomic.Int64
+	reset       atomic.Bool
+	throttledNs atomic.Int64
+}
+
+func (r *chaosRecorder) info() *ChaosInfo {
+	info := &ChaosInfo{
+		DelayMs:     r.delayMs.Load(),
+		Reset:       r.reset.Load(),
+		ThrottledMs: time.Duration(r.throttledNs.Load()).Milliseconds(),
+	}
+	if !info.injected() {
+		return nil
+	}
+	return info
+}
+
+type chaosRecorderKey struct{}
+
+// withChaosRecorder returns a context whose requests record the faults
+// injected into them in r.
+func withChaosRecorder(ctx context.Context, r *chaosRecorder) context.Context {
+	return context.WithValue(ctx, chaosRecorderKey{}, r)
+}
+
+// chaosTransport is a RoundTripper that injects the faults of its config
+// into the requests it forwards.
+type chaosTransport struct {
+	next   http.RoundTripper
+	config ChaosConfig
+}
+
+func newChaosTransport(next http.RoundTripper, config *ChaosConfig) *chaosTransport {
+	return &chaosTransport{next: next, config: *config}
+}
+
+func (t *chaosTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	recorder, _ := ctx.Value(chaosRecorderKey{}).(*chaosRecorder)
+	if recorder == nil {
+		recorder = &chaosRecorder{}
+	}
+
+	if delay := t.delay(); delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		recorder.delayMs.Add(delay.Milliseconds())
+	}
+	if t.config.BandwidthBytesPerSec > 0 && req.Body != nil {
+		req = req.Clone(ctx)
+		req.Body = &throttledBody{ReadCloser: req.Body, ctx: ctx, rate: t.config.BandwidthBytesPerSec, recorder: recorder}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if t.config.ResetRate > 0 && rand.Float64() < t.config.ResetRate {
+		resp.Body.Close()
+		recorder.reset.Store(true)
+		return nil, &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+	}
+	if t.config.BandwidthBytesPerSec > 0 {
+		resp.Body = &throttledBody{ReadCloser: resp.Body, ctx: ctx, rate: t.config.BandwidthBytesPerSec, recorder: recorder}
+	}
+	return resp, nil
+}
+
+// delay returns the latency to add to a request.
+func (t *chaosTransport) delay() time.Duration {
+	ms := t.config.LatencyMs
+	if jitter := t.config.JitterMs; jitter > 0 {
+		ms += rand.Int64N(2*jitter+1) - jitter
+	}
+	return time.Duration(max(ms, 0)) * time.Millisecond
+}
+
+// throttledBody paces reads of a body to a number of bytes per second.
+type throttledBody struct {
+	io.ReadCloser
+	ctx      context.Context
+	rate     int64
+	recorder *chaosRecorder
+
+	started time.Time
+	read    int64
+}
+
+func (b *throttledBody) Read(p []byte) (int, error) {
+	if b.started.IsZero() {
+		b.started = time.Now()
+	}
+	// Reading at most a tenth of a second's worth at once keeps the pace
+	// smooth rather than bursty.
+	if chunk := max(b.rate/10, 1); int64(len(p)) > chunk {
+		p = p[:chunk]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	due := b.started.Add(time.Duration(float64(b.read) / float64(b.rate) * float64(time.Second)))
+	if wait := time.Until(due); wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-b.ctx.Done():
+			timer.Stop()
+			return n, b.ctx.Err()
+		case <-timer.C:
+		}
+		b.recorder.throttledNs.Add(int64(wait))
+	}
+	return n, err
+}
@@ -0,0 +1,59 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func connectChaos(t *testing.T, url string, chaos *ChaosConfig) Connection {
+	t.Helper()
+	conn, err := NewStreamableHTTPAdapter().Connect(context.Background(), &TransportConfig{
+		AllowPrivateNetworks: []string{"127.0.0.0/8"},
+		Endpoint:             url,
+		Timeouts:             DefaultTimeoutConfig(),
+		Chaos:                chaos,
+	})
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestChaos(t *testing.T) {
+	var calls atomic.Int64
+	result := `{"content":[{"type":"text","text":"` + strings.Repeat("x", 2000) + `"}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set(HeaderContentType, ContentTypeJSON)
+		w.Write([]byte(`{"jsonrpc":"2.0","id":"req_1","result":` + result + `}`))
+	}))
+	defer server.Close()
+
+	outcome, _ := connectChaos(t, server.URL, &ChaosConfig{LatencyMs: 50, JitterMs: 10}).ToolsCall(context.Background(), &ToolsCallParams{Name: "echo"})
+	if !outcome.OK || outcome.Chaos == nil || outcome.Chaos.DelayMs < 40 || outcome.Chaos.DelayMs > 60 || outcome.LatencyMs < 40 {
+		t.Errorf("expected 40-60ms of added latency, got %+v in %dms", outcome.Chaos, outcome.LatencyMs)
+	}
+
+	outcome, _ = connectChaos(t, server.URL, &ChaosConfig{ResetRate: 1}).ToolsCall(context.Background(), &ToolsCallParams{Name: "echo"})
+	if outcome.OK || outcome.Error.Code != CodeConnectionReset || outcome.Chaos == nil || !outcome.Chaos.Reset {
+		t.Errorf("expected an injected connection reset, got %+v with %+v", outcome.Error, outcome.Chaos)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected the reset request to reach the server, got %d calls", calls.Load())
+	}
+
+	outcome, _ = connectChaos(t, server.URL, &ChaosConfig{BandwidthBytesPerSec: 10000}).ToolsCall(context.Background(), &ToolsCallParams{Name: "echo"})
+	if !outcome.OK || outcome.Chaos == nil || outcome.Chaos.ThrottledMs < 150 {
+		t.Errorf("expected about 200ms throttling a 2KB response at 10KB/s, got %+v in %dms", outcome.Chaos, outcome.LatencyMs)
+	}
+
+	outcome, _ = connectChaos(t, server.URL, nil).ToolsCall(context.Background(), &ToolsCallParams{Name: "echo"})
+	if !outcome.OK || outcome.Chaos != nil {
+		t.Errorf("expected no faults without chaos, got %+v", outcome.Chaos)
+	}
+}
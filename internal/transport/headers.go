@@ -0,0 +1,20 @@
+package transport
+
+import "context"
+
+type requestHeadersKey struct{}
+
+// WithRequestHeaders returns a context whose requests carry headers on top
+// of the connection's configured headers, replacing any with the same name.
+// It lets a caller vary headers per call on a shared connection.
+func WithRequestHeaders(ctx context.Context, headers map[string]string) context.Context {
+	if len(headers) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, requestHeadersKey{}, headers)
+}
+
+func requestHeaders(ctx context.Context) map[string]string {
+	headers, _ := ctx.Value(requestHeadersKey{}).(map[string]string)
+	return headers
+}
@@ -2,7 +2,6 @@ package transport
 
 import (
 	"encoding/json"
-	"fmt"
 
 	"github.com/bc-dunia/mcpdrill/internal/mcp"
 )
@@ -54,14 +53,15 @@ func NewToolsListRequest(id string, cursor *string) *JSONRPCRequest {
 }
 
 func NewToolsCallRequest(id string, toolName string, arguments map[string]interface{}) *JSONRPCRequest {
+	return newToolsCallRequest(id, ToolsCallParams{Name: toolName, Arguments: arguments})
+}
+
+func newToolsCallRequest(id string, params ToolsCallParams) *JSONRPCRequest {
 	return &JSONRPCRequest{
 		JSONRPC: "2.0",
 		ID:      id,
 		Method:  string(OpToolsCall),
-		Params: ToolsCallParams{
-			Name:      toolName,
-			Arguments: arguments,
-		},
+		Params:  params,
 	}
 }
 
@@ -164,16 +164,7 @@ func ValidateJSONRPCResponse(resp *JSONRPCResponse, expectedID string) *Operatio
 		}
 	}
 
-	respID, ok := resp.ID.(string)
-	if !ok {
-		if numID, ok := resp.ID.(float64); ok {
-			respID = fmt.Sprintf("%v", numID)
-		} else {
-			respID = fmt.Sprintf("%v", resp.ID)
-		}
-	}
-
-	if respID != expectedID {
+	if responseIDKey(resp.ID) != expectedID {
 		return &OperationError{
 			Type:    ErrorTypeProtocol,
 			Code:    CodeIDMismatch,
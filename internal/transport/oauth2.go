@@ -0,0 +1,230 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenSource supplies the bearer token a connection sends in its
+// Authorization header. It is called for every request, so implementations
+// cache tokens.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// tokenInvalidator is implemented by token sources that can drop a token
+// the target rejected, so the next request fetches a fresh one.
+type tokenInvalidator interface {
+	Invalidate(token string)
+}
+
+// DefaultTokenRefreshBefore is how long before expiry a client credentials
+// token is refreshed when the config does not say.
+const DefaultTokenRefreshBefore = 30 * time.Second
+
+// ClientCredentialsConfig configures an OAuth2 client credentials grant
+// (RFC 6749 section 4.4).
+type ClientCredentialsConfig struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	// ClientSecretFunc, if set, is called for every token request instead
+	// of using ClientSecret, so a rotated secret is picked up.
+	ClientSecretFunc func(ctx context.Context) (string, error)
+	Scopes           []string
+	// Audience is sent as the audience parameter some providers require.
+	Audience string
+	// PostCredentials sends the client credentials in the form body instead
+	// of an HTTP Basic Authorization header.
+	PostCredentials bool
+	// RefreshBefore is how long before expiry a token is refreshed.
+	// Zero uses DefaultTokenRefreshBefore.
+	RefreshBefore time.Duration
+
+	Timeout              time.Duration
+	AllowPrivateNetworks []string
+}
+
+// ClientCredentialsSource is a TokenSource that fetches tokens with the
+// client credentials grant. A token is reused until it is about to expire;
+// a token inside its refresh window is still returned while a single
+// background fetch replaces it.
+type ClientCredentialsSource struct {
+	config ClientCredentialsConfig
+	client *http.Client
+	now    func() time.Time
+
+	mu         sync.Mutex
+	token      string
+	expiresAt  time.Time // zero if the token does not expire
+	refreshAt  time.Time
+	refreshing bool
+	fetchMu    sync.Mutex
+}
+
+// NewClientCredentialsSource creates a token source for config. Token
+// requests go through the same private-network guard as target requests.
+func NewClientCredentialsSource(config ClientCredentialsConfig) *ClientCredentialsSource {
+	if config.RefreshBefore <= 0 {
+		config.RefreshBefore = DefaultTokenRefreshBefore
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	dialer := newSafeDialer(config.Timeout, config.AllowPrivateNetworks)
+	return &ClientCredentialsSource{
+		config: config,
+		client: &http.Client{
+			Timeout:   config.Timeout,
+			Transport: &http.Transport{DialContext: dialer.DialContext, TLSHandshakeTimeout: config.Timeout},
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		now: time.Now,
+	}
+}
+
+// Token returns a cached token, fetching a new one if there is none or it
+// has expired.
+func (s *ClientCredentialsSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	token, expiresAt := s.token, s.expiresAt
+	now := s.now()
+	if token != "" && (expiresAt.IsZero() || now.Before(expiresAt)) {
+		if !expiresAt.IsZero() && !now.Before(s.refreshAt) && !s.refreshing {
+			s.refreshing = true
+			go s.refreshInBackground()
+		}
+		s.mu.Unlock()
+		return token, nil
+	}
+	s.mu.Unlock()
+
+	return s.fetchShared(ctx, token)
+}
+
+// Invalidate drops token if it is still the cached one.
+func (s *ClientCredentialsSource) Invalidate(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token == token {
+		s.token = ""
+		s.expiresAt = time.Time{}
+	}
+}
+
+// fetchShared fetches a token unless another caller replaced stale while
+// this one waited, so concurrent callers share one request.
+func (s *ClientCredentialsSource) fetchShared(ctx context.Context, stale string) (string, error) {
+	s.fetchMu.Lock()
+	defer s.fetchMu.Unlock()
+
+	s.mu.Lock()
+	if s.token != "" && s.token != stale && (s.expiresAt.IsZero() || s.now().Before(s.expiresAt)) {
+		token := s.token
+		s.mu.Unlock()
+		return token, nil
+	}
+	s.mu.Unlock()
+
+	return s.fetch(ctx)
+}
+
+func (s *ClientCredentialsSource) refreshInBackground() {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeout)
+	defer cancel()
+
+	s.fetchMu.Lock()
+	_, _ = s.fetch(ctx)
+	s.fetchMu.Unlock()
+
+	s.mu.Lock()
+	s.refreshing = false
+	s.mu.Unlock()
+}
+
+// fetch requests a new token and caches it. Must be called with fetchMu held.
+func (s *ClientCredentialsSource) fetch(ctx context.Context) (string, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(s.config.Scopes) > 0 {
+		form.Set("scope", strings.Join(s.config.Scopes, " "))
+	}
+	if s.config.Audience != "" {
+		form.Set("audience", s.config.Audience)
+	}
+	secret := s.config.ClientSecret
+	if s.config.ClientSecretFunc != nil {
+		var err error
+		if secret, err = s.config.ClientSecretFunc(ctx); err != nil {
+			return "", fmt.Errorf("client secret: %w", err)
+		}
+	}
+	if s.config.PostCredentials {
+		form.Set("client_id", s.config.ClientID)
+		form.Set("client_secret", secret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if !s.config.PostCredentials {
+		req.SetBasicAuth(url.QueryEscape(s.config.ClientID), url.QueryEscape(secret))
+	}
+
+	requestedAt := s.now()
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("token request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("token response: %w", err)
+	}
+	var payload struct {
+		AccessToken      string `json:"access_token"`
+		TokenType        string `json:"token_type"`
+		ExpiresIn        int64  `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	_ = json.Unmarshal(body, &payload)
+	if resp.StatusCode != http.StatusOK {
+		if payload.Error != "" {
+			return "", fmt.Errorf("token endpoint returned %d: %s %s", resp.StatusCode, payload.Error, payload.ErrorDescription)
+		}
+		return "", fmt.Errorf("token endpoint returned %d", resp.StatusCode)
+	}
+	if payload.AccessToken == "" {
+		return "", fmt.Errorf("token response has no access_token")
+	}
+	if payload.TokenType != "" && !strings.EqualFold(payload.TokenType, "bearer") {
+		return "", fmt.Errorf("unsupported token type %q", payload.TokenType)
+	}
+
+	var expiresAt, refreshAt time.Time
+	if payload.ExpiresIn > 0 {
+		lifetime := time.Duration(payload.ExpiresIn) * time.Second
+		expiresAt = requestedAt.Add(lifetime)
+		// Short-lived tokens are refreshed halfway through instead.
+		refreshAt = expiresAt.Add(-min(s.config.RefreshBefore, lifetime/2))
+	}
+	s.mu.Lock()
+	s.token = payload.AccessToken
+	s.expiresAt = expiresAt
+	s.refreshAt = refreshAt
+	s.mu.Unlock()
+	return payload.AccessToken, nil
+}
@@ -0,0 +1,165 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTokenServer serves client credentials tokens tok_1, tok_2, ... that
+// expire after expiresIn seconds.
+func newTokenServer(t *testing.T, expiresIn int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var issued atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		clientID, secret, ok := r.BasicAuth()
+		if !ok {
+			clientID, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+		}
+		if r.PostForm.Get("grant_type") != "client_credentials" || clientID != "drill" || secret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+			return
+		}
+		if r.PostForm.Get("scope") != "mcp.read mcp.call" {
+			t.Errorf("unexpected scope %q", r.PostForm.Get("scope"))
+		}
+		n := issued.Add(1)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": fmt.Sprintf("tok_%d", n),
+			"token_type":   "Bearer",
+			"expires_in":   expiresIn,
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server, &issued
+}
+
+func newTestTokenSource(tokenURL string, post bool) *ClientCredentialsSource {
+	return NewClientCredentialsSource(ClientCredentialsConfig{
+		TokenURL:             tokenURL,
+		ClientID:             "drill",
+		ClientSecret:         "s3cret",
+		Scopes:               []string{"mcp.read", "mcp.call"},
+		PostCredentials:      post,
+		RefreshBefore:        30 * time.Second,
+		AllowPrivateNetworks: []string{"127.0.0.0/8"},
+	})
+}
+
+func TestClientCredentialsSource_CachesAndRefreshes(t *testing.T) {
+	server, issued := newTokenServer(t, 300)
+	source := newTestTokenSource(server.URL, false)
+	now := time.Now()
+	source.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		token, err := source.Token(context.Background())
+		if err != nil || token != "tok_1" {
+			t.Fatalf("expected the cached tok_1, got %q, %v", token, err)
+		}
+	}
+	if issued.Load() != 1 {
+		t.Fatalf("expected 1 token request, got %d", issued.Load())
+	}
+
+	// Inside the refresh window the current token is still returned while
+	// a new one is fetched in the background.
+	now = now.Add(280 * time.Second)
+	if token, _ := source.Token(context.Background()); token != "tok_1" {
+		t.Errorf("expected tok_1 while refreshing, got %q", token)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for issued.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if token, _ := source.Token(context.Background()); token != "tok_2" {
+		t.Errorf("expected the refreshed tok_2, got %q", token)
+	}
+
+	// An expired token is replaced before it is returned.
+	now = now.Add(time.Hour)
+	if token, _ := source.Token(context.Background()); token != "tok_3" {
+		t.Errorf("expected tok_3 after expiry, got %q", token)
+	}
+
+	source.Invalidate("tok_3")
+	if token, _ := source.Token(context.Background()); token != "tok_4" {
+		t.Errorf("expected tok_4 after invalidation, got %q", token)
+	}
+}
+
+func TestClientCredentialsSource_Errors(t *testing.T) {
+	server, _ := newTokenServer(t, 300)
+
+	bad := newTestTokenSource(server.URL, true)
+	bad.config.ClientSecret = "wrong"
+	if _, err := bad.Token(context.Background()); err == nil {
+		t.Error("expected a rejected client to fail")
+	}
+
+	post := newTestTokenSource(server.URL, true)
+	if token, err := post.Token(context.Background()); err != nil || token == "" {
+		t.Errorf("expected credentials in the form body to work, got %q, %v", token, err)
+	}
+}
+
+func TestStreamableHTTP_TokenSource(t *testing.T) {
+	tokenServer, issued := newTokenServer(t, 300)
+
+	var rejectNext atomic.Bool
+	rejectNext.Store(true)
+	var authHeaders []string
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+		if rejectNext.Swap(false) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var req JSONRPCRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set(HeaderContentType, ContentTypeJSON)
+		json.NewEncoder(w).Encode(JSONRPCResponse{JSONRPC: "2.0", ID: req.ID, Result: json.RawMessage(`{}`)})
+	}))
+	defer target.Close()
+
+	conn, err := NewStreamableHTTPAdapter().Connect(context.Background(), &TransportConfig{
+		AllowPrivateNetworks: []string{"127.0.0.0/8"},
+		Endpoint:             target.URL,
+		Headers:              map[string]string{"Authorization": "Bearer static"},
+		Timeouts:             DefaultTimeoutConfig(),
+		TokenSource:          newTestTokenSource(tokenServer.URL, false),
+	})
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	defer conn.Close()
+
+	// The first token is rejected, so the second request uses a new one.
+	conn.Ping(context.Background())
+	if outcome, _ := conn.Ping(context.Background()); !outcome.OK {
+		t.Fatalf("expected the retried ping to succeed, got %v", outcome.Error)
+	}
+	if len(authHeaders) != 2 || authHeaders[0] != "Bearer tok_1" || authHeaders[1] != "Bearer tok_2" || issued.Load() != 2 {
+		t.Errorf("unexpected Authorization headers %v after %d token requests", authHeaders, issued.Load())
+	}
+
+	failing, _ := NewStreamableHTTPAdapter().Connect(context.Background(), &TransportConfig{
+		AllowPrivateNetworks: []string{"127.0.0.0/8"},
+		Endpoint:             target.URL,
+		Timeouts:             DefaultTimeoutConfig(),
+		TokenSource:          newTestTokenSource(target.URL+"/missing", false),
+	})
+	defer failing.Close()
+	outcome, _ := failing.Ping(context.Background())
+	if outcome.OK || outcome.Error == nil || outcome.Error.Type != ErrorTypeAuth {
+		t.Errorf("expected an auth_error when no token can be obtained, got %+v", outcome.Error)
+	}
+}
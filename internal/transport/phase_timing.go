@@ -15,6 +15,8 @@ type phaseTimingTracker struct {
 	startTime        time.Time
 	dnsStart         time.Time
 	dnsEnd           time.Time
+	dnsCoalesced     bool
+	dnsFailed        bool
 	connectStart     time.Time
 	connectEnd       time.Time
 	tlsStart         time.Time
@@ -41,6 +43,8 @@ func (t *phaseTimingTracker) createClientTrace() *httptrace.ClientTrace {
 		DNSDone: func(info httptrace.DNSDoneInfo) {
 			t.mu.Lock()
 			t.dnsEnd = time.Now()
+			t.dnsCoalesced = info.Coalesced
+			t.dnsFailed = info.Err != nil
 			t.mu.Unlock()
 		},
 		ConnectStart: func(network, addr string) {
@@ -92,8 +96,14 @@ func (t *phaseTimingTracker) computePhaseTiming(endTime time.Time) *PhaseTiming
 	}
 
 	if !t.connectionReused {
+		if !t.dnsStart.IsZero() {
+			pt.DNSLookup = true
+			pt.DNSCoalesced = t.dnsCoalesced
+			pt.DNSFailed = t.dnsFailed
+		}
 		if !t.dnsStart.IsZero() && !t.dnsEnd.IsZero() {
 			pt.DNSMs = t.dnsEnd.Sub(t.dnsStart).Milliseconds()
+			pt.DNSUs = t.dnsEnd.Sub(t.dnsStart).Microseconds()
 		}
 		if !t.connectStart.IsZero() && !t.connectEnd.IsZero() {
 			pt.TCPConnectMs = t.connectEnd.Sub(t.connectStart).Milliseconds()
@@ -0,0 +1,57 @@
+package transport
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// MethodProgressNotification is the notification a server sends to report
+// the progress of a request that carried a progress token.
+const MethodProgressNotification = "notifications/progress"
+
+// progressParams are the params of a progress notification.
+type progressParams struct {
+	ProgressToken json.RawMessage `json:"progressToken"`
+	Progress      *float64        `json:"progress"`
+}
+
+// withProgressToken returns params with token set as the progress token in
+// their _meta.
+func withProgressToken(params ToolsCallParams, token string) ToolsCallParams {
+	meta := make(map[string]interface{}, len(params.Meta)+1)
+	for key, value := range params.Meta {
+		meta[key] = value
+	}
+	meta["progressToken"] = token
+	params.Meta = meta
+	return params
+}
+
+// recordProgress checks a progress notification against the request: its
+// token must be the one sent and its progress must increase with every
+// notification. Without a token sent, notifications are only counted.
+func (s *sseStream) recordProgress(raw json.RawMessage) {
+	signals := s.signals
+	signals.ProgressCount++
+	if signals.ProgressCount == 1 {
+		signals.TimeToFirstProgressMs = time.Since(s.startTime).Milliseconds()
+	}
+	if s.progressToken == "" {
+		return
+	}
+
+	var params progressParams
+	if err := json.Unmarshal(raw, &params); err != nil || params.Progress == nil {
+		signals.ProgressInvalid++
+		return
+	}
+	var token string
+	if json.Unmarshal(params.ProgressToken, &token) != nil || token != s.progressToken {
+		signals.ProgressTokenMismatches++
+		return
+	}
+	if s.lastProgress != nil && *params.Progress <= *s.lastProgress {
+		signals.ProgressInvalid++
+	}
+	s.lastProgress = params.Progress
+}
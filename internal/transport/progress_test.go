@@ -0,0 +1,65 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProgressTokens(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Params struct {
+				Meta map[string]interface{} `json:"_meta"`
+			} `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		token, _ := json.Marshal(req.Params.Meta["progressToken"])
+
+		w.Header().Set(HeaderContentType, ContentTypeSSE)
+		progress := `data: {"jsonrpc":"2.0","method":"notifications/progress","params":{"progressToken":%s,"progress":%d}}` + "\n\n"
+		fmt.Fprintf(w, progress, token, 10)
+		fmt.Fprintf(w, progress, token, 50)
+		fmt.Fprintf(w, progress, token, 40)
+		fmt.Fprintf(w, progress, `"other"`, 60)
+		fmt.Fprintf(w, "data: {\"jsonrpc\":\"2.0\",\"id\":%s,\"result\":{\"content\":[]}}\n\n", req.ID)
+	}))
+	defer server.Close()
+
+	for _, tc := range []struct {
+		name           string
+		progressTokens bool
+		mismatches     int
+		invalid        int
+	}{
+		{"checked", true, 1, 1},
+		{"without token", false, 0, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			conn, err := NewStreamableHTTPAdapter().Connect(context.Background(), &TransportConfig{
+				AllowPrivateNetworks: []string{"127.0.0.0/8"},
+				Endpoint:             server.URL,
+				Timeouts:             DefaultTimeoutConfig(),
+				ProgressTokens:       tc.progressTokens,
+			})
+			if err != nil {
+				t.Fatalf("connect failed: %v", err)
+			}
+			defer conn.Close()
+
+			outcome, _ := conn.ToolsCall(context.Background(), &ToolsCallParams{Name: "slow"})
+			if !outcome.OK {
+				t.Fatalf("expected the call to succeed, got %v", outcome.Error)
+			}
+			s := outcome.Stream
+			if s.ProgressCount != 4 || s.ProgressTokenMismatches != tc.mismatches || s.ProgressInvalid != tc.invalid {
+				t.Errorf("unexpected progress signals: count %d, mismatches %d, invalid %d",
+					s.ProgressCount, s.ProgressTokenMismatches, s.ProgressInvalid)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package transport
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// RequestIDFormat selects the JSON-RPC id values sent to the server.
+type RequestIDFormat string
+
+const (
+	// RequestIDFormatString sends string ids such as "req_12" (default).
+	RequestIDFormatString RequestIDFormat = "string"
+	// RequestIDFormatInteger sends integer ids such as 12.
+	RequestIDFormatInteger RequestIDFormat = "integer"
+	// RequestIDFormatLong sends string ids padded to LongLength characters.
+	RequestIDFormatLong RequestIDFormat = "long"
+	// RequestIDFormatMixed rotates through string, integer and long ids.
+	RequestIDFormatMixed RequestIDFormat = "mixed"
+)
+
+// RequestIDKind is the kind of id sent with a single request.
+type RequestIDKind string
+
+const (
+	RequestIDKindString  RequestIDKind = "string"
+	RequestIDKindInteger RequestIDKind = "integer"
+	RequestIDKindLong    RequestIDKind = "long"
+)
+
+// DefaultLongRequestIDLength is the length of long ids when none is configured.
+const DefaultLongRequestIDLength = 1024
+
+// RequestIDConfig varies the JSON-RPC ids sent on a connection to exercise
+// how strictly the server handles them.
+type RequestIDConfig struct {
+	Format RequestIDFormat
+
+	// LongLength is the length of long ids. Zero uses DefaultLongRequestIDLength.
+	LongLength int
+
+	// DuplicateEvery reuses the previous request's id on every Nth request.
+	// MCP forbids reusing ids within a session, so this is a negative test:
+	// strict servers reject the duplicate. Zero disables duplicates.
+	DuplicateEvery int
+}
+
+// RequestIDInfo records the id variant sent with a request and how the
+// server echoed it. It is only set when a RequestIDConfig is in use.
+type RequestIDInfo struct {
+	Kind RequestIDKind `json:"kind"`
+
+	// Duplicate is set when the id repeated the previous request's id.
+	Duplicate bool `json:"duplicate,omitempty"`
+
+	// EchoTypeMismatch is set when the response carried the id as a different
+	// JSON type than the request, e.g. integer 12 echoed as "12".
+	EchoTypeMismatch bool `json:"echo_type_mismatch,omitempty"`
+}
+
+// requestID is the id of a single request.
+type requestID struct {
+	key  string         // canonical string form, matched against response ids
+	wire interface{}    // value sent as the JSON-RPC id
+	info *RequestIDInfo // nil unless id variation is configured
+}
+
+// requestIDGenerator issues request ids for a connection.
+type requestIDGenerator struct {
+	config *RequestIDConfig
+	count  int64
+
+	mu   sync.Mutex
+	last requestID
+}
+
+// next returns the id for the next request. Initialize requests are never
+// given a duplicate id, as there is no earlier request to repeat.
+func (g *requestIDGenerator) next(op OperationType) requestID {
+	count := atomic.AddInt64(&g.count, 1)
+	if g.config == nil {
+		key := fmt.Sprintf("req_%d", count)
+		return requestID{key: key, wire: key}
+	}
+
+	if g.config.DuplicateEvery > 0 && count%int64(g.config.DuplicateEvery) == 0 && op != OpInitialize {
+		g.mu.Lock()
+		last := g.last
+		g.mu.Unlock()
+		if last.info != nil {
+			return requestID{
+				key:  last.key,
+				wire: last.wire,
+				info: &RequestIDInfo{Kind: last.info.Kind, Duplicate: true},
+			}
+		}
+	}
+
+	id := g.build(count)
+	g.mu.Lock()
+	g.last = id
+	g.mu.Unlock()
+	return id
+}
+
+func (g *requestIDGenerator) build(count int64) requestID {
+	kind := RequestIDKindString
+	switch g.config.Format {
+	case RequestIDFormatInteger:
+		kind = RequestIDKindInteger
+	case RequestIDFormatLong:
+		kind = RequestIDKindLong
+	case RequestIDFormatMixed:
+		kind = []RequestIDKind{RequestIDKindString, RequestIDKindInteger, RequestIDKindLong}[count%3]
+	}
+
+	switch kind {
+	case RequestIDKindInteger:
+		return requestID{
+			key:  strconv.FormatInt(count, 10),
+			wire: count,
+			info: &RequestIDInfo{Kind: kind},
+		}
+	case RequestIDKindLong:
+		length := g.config.LongLength
+		if length <= 0 {
+			length = DefaultLongRequestIDLength
+		}
+		key := fmt.Sprintf("req_%d_", count)
+		if pad := length - len(key); pad > 0 {
+			key += strings.Repeat("x", pad)
+		}
+		return requestID{key: key, wire: key, info: &RequestIDInfo{Kind: kind}}
+	default:
+		key := fmt.Sprintf("req_%d", count)
+		return requestID{key: key, wire: key, info: &RequestIDInfo{Kind: kind}}
+	}
+}
+
+// responseIDKey returns the canonical string form of a decoded response id.
+// Numbers are formatted without an exponent so large integer ids still match.
+func responseIDKey(id interface{}) string {
+	switch v := id.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// recordIDEcho flags responses that echoed the request id as a different
+// JSON type. Lenient clients accept these, strict ones do not.
+func recordIDEcho(outcome *OperationOutcome, respID interface{}) {
+	if outcome.RequestID == nil {
+		return
+	}
+	_, isNumber := respID.(float64)
+	outcome.RequestID.EchoTypeMismatch = isNumber != (outcome.RequestID.Kind == RequestIDKindInteger)
+}
@@ -0,0 +1,61 @@
+package transport
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRequestIDGenerator_Formats(t *testing.T) {
+	plain := &requestIDGenerator{}
+	if id := plain.next(OpPing); id.key != "req_1" || id.wire != "req_1" || id.info != nil {
+		t.Errorf("expected default string id without info, got %+v", id)
+	}
+
+	integer := &requestIDGenerator{config: &RequestIDConfig{Format: RequestIDFormatInteger}}
+	if id := integer.next(OpPing); id.key != "1" || id.wire != int64(1) || id.info.Kind != RequestIDKindInteger {
+		t.Errorf("expected integer id 1, got %+v", id)
+	}
+
+	long := &requestIDGenerator{config: &RequestIDConfig{Format: RequestIDFormatLong, LongLength: 100}}
+	id := long.next(OpPing)
+	if len(id.key) != 100 || !strings.HasPrefix(id.key, "req_1_") || id.info.Kind != RequestIDKindLong {
+		t.Errorf("expected 100 char long id, got %d chars %+v", len(id.key), id.info)
+	}
+
+	mixed := &requestIDGenerator{config: &RequestIDConfig{Format: RequestIDFormatMixed}}
+	kinds := make(map[RequestIDKind]int)
+	for i := 0; i < 6; i++ {
+		kinds[mixed.next(OpPing).info.Kind]++
+	}
+	if kinds[RequestIDKindString] != 2 || kinds[RequestIDKindInteger] != 2 || kinds[RequestIDKindLong] != 2 {
+		t.Errorf("expected mixed ids to rotate evenly, got %v", kinds)
+	}
+}
+
+func TestRequestIDGenerator_Duplicates(t *testing.T) {
+	g := &requestIDGenerator{config: &RequestIDConfig{Format: RequestIDFormatString, DuplicateEvery: 2}}
+
+	first := g.next(OpInitialize)
+	second := g.next(OpPing)
+	if second.key != first.key || second.info == nil || !second.info.Duplicate {
+		t.Errorf("expected second id to duplicate %s, got %+v", first.key, second)
+	}
+	third := g.next(OpPing)
+	if third.key == first.key || third.info.Duplicate {
+		t.Errorf("expected a fresh id, got %+v", third)
+	}
+
+	g = &requestIDGenerator{config: &RequestIDConfig{DuplicateEvery: 1}}
+	if id := g.next(OpInitialize); id.info.Duplicate {
+		t.Errorf("expected initialize to never use a duplicate id, got %+v", id)
+	}
+}
+
+func TestResponseIDKey(t *testing.T) {
+	if key := responseIDKey(float64(12345678)); key != "12345678" {
+		t.Errorf("expected large integer ids without exponent, got %s", key)
+	}
+	if key := responseIDKey("req_1"); key != "req_1" {
+		t.Errorf("expected string ids unchanged, got %s", key)
+	}
+}
@@ -0,0 +1,125 @@
+package transport
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// networkResourceSchemes are URI schemes a server is likely to fetch over the
+// network when asked to read the resource. Their hosts are checked like the
+// target's, so a run cannot make the server under test reach internal hosts.
+var networkResourceSchemes = map[string]bool{
+	"http":  true,
+	"https": true,
+	"ws":    true,
+	"wss":   true,
+	"ftp":   true,
+}
+
+// blockedResourceSchemes are classic SSRF schemes that are never sent.
+var blockedResourceSchemes = map[string]bool{
+	"gopher": true,
+	"dict":   true,
+	"ldap":   true,
+	"tftp":   true,
+	"jar":    true,
+	"netdoc": true,
+}
+
+// ValidateResourceURI checks a resources/read URI before it is sent. Non-network
+// schemes such as file:// or custom schemes are left to the server. Network
+// URIs must not carry credentials and must not name localhost or an IP literal
+// outside allowPrivateNetworks; hostnames are not resolved.
+func ValidateResourceURI(uri string, allowPrivateNetworks []string) *OperationError {
+	if uri == "" {
+		return resourceURIError("resource uri is required")
+	}
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return resourceURIError(fmt.Sprintf("invalid resource uri: %v", err))
+	}
+
+	scheme := strings.ToLower(parsed.Scheme)
+	if blockedResourceSchemes[scheme] {
+		return resourceURIError(fmt.Sprintf("resource uri scheme %q is not allowed", scheme))
+	}
+	if !networkResourceSchemes[scheme] {
+		return nil
+	}
+
+	if parsed.User != nil {
+		return resourceURIError("resource uri must not contain credentials")
+	}
+	host := strings.ToLower(parsed.Hostname())
+	if host == "" {
+		return resourceURIError("resource uri has no host")
+	}
+
+	dialer := newSafeDialer(0, allowPrivateNetworks)
+	if ip := net.ParseIP(host); ip != nil {
+		if dialer.isIPBlocked(ip) {
+			return resourceURIError(fmt.Sprintf("resource uri host %s is a blocked address", ip))
+		}
+		return nil
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		if dialer.isIPBlocked(net.ParseIP("127.0.0.1")) {
+			return resourceURIError("resource uri host localhost is blocked")
+		}
+	}
+	return nil
+}
+
+func resourceURIError(message string) *OperationError {
+	return &OperationError{
+		Type:    ErrorTypeProtocol,
+		Code:    "RESOURCE_URI_BLOCKED",
+		Message: message,
+	}
+}
+
+// resultFields names the array each resources and prompts result must carry.
+var resultFields = map[OperationType]string{
+	OpResourcesList: "resources",
+	OpResourcesRead: "contents",
+	OpPromptsList:   "prompts",
+	OpPromptsGet:    "messages",
+}
+
+// CheckResultShape returns a protocol error when a successful resources or
+// prompts result lacks its required array. Other operations are not checked.
+func CheckResultShape(op OperationType, result json.RawMessage) *OperationError {
+	field, ok := resultFields[op]
+	if !ok {
+		return nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(result, &fields); err != nil {
+		return MapProtocolError(fmt.Sprintf("invalid %s result: %v", op, err))
+	}
+	value, ok := fields[field]
+	if !ok {
+		return MapProtocolError(fmt.Sprintf("invalid %s result: missing %q", op, field))
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(value, &items); err != nil {
+		return MapProtocolError(fmt.Sprintf("invalid %s result: %q must be an array", op, field))
+	}
+	return nil
+}
+
+// countingReadCloser counts the bytes read through it.
+type countingReadCloser struct {
+	io.ReadCloser
+	n int64
+}
+
+func (c *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,252 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	// DefaultSummarizeAboveBytes is the result size above which results are
+	// summarized instead of kept verbatim.
+	DefaultSummarizeAboveBytes = 16 * 1024
+
+	// DefaultFingerprintMaxDepth is how deep the structural fingerprint descends
+	// into nested objects and arrays.
+	DefaultFingerprintMaxDepth = 4
+
+	// maxShapeLength caps the shape string stored on a summary. The fingerprint
+	// always covers the full shape.
+	maxShapeLength = 512
+
+	// maxShapeKeys caps the number of keys described per object so map-like
+	// results don't produce unbounded shapes.
+	maxShapeKeys = 32
+)
+
+// ResultCaptureConfig controls how operation results are kept on outcomes.
+type ResultCaptureConfig struct {
+	// SummarizeAboveBytes is the result size above which the raw result is
+	// replaced by a ResultSummary. Zero or less disables summarization.
+	SummarizeAboveBytes int
+
+	// FingerprintMaxDepth limits the nesting depth described by the shape.
+	FingerprintMaxDepth int
+}
+
+// DefaultResultCaptureConfig returns the result capture settings used by workers.
+func DefaultResultCaptureConfig() *ResultCaptureConfig {
+	return &ResultCaptureConfig{
+		SummarizeAboveBytes: DefaultSummarizeAboveBytes,
+		FingerprintMaxDepth: DefaultFingerprintMaxDepth,
+	}
+}
+
+// ResultSummary describes a result that was too large to keep verbatim.
+type ResultSummary struct {
+	// SizeBytes is the length of the raw JSON result.
+	SizeBytes int64 `json:"size_bytes"`
+
+	// SHA256 is the hex-encoded hash of the raw JSON result.
+	SHA256 string `json:"sha256"`
+
+	// Shape is a compact description of the result structure, e.g.
+	// {content:[{text:s,type:s}]}. Truncated shapes end with "...".
+	Shape string `json:"shape"`
+
+	// Fingerprint is a short hash of the full shape. Results with the same
+	// structure share a fingerprint regardless of their values.
+	Fingerprint string `json:"fingerprint"`
+}
+
+// SummarizeResult builds a summary of a raw JSON result. Invalid JSON is still
+// hashed and sized; its shape is reported as "invalid".
+func SummarizeResult(raw json.RawMessage, maxDepth int) *ResultSummary {
+	if maxDepth <= 0 {
+		maxDepth = DefaultFingerprintMaxDepth
+	}
+
+	sum := sha256.Sum256(raw)
+	shape := resultShape(raw, maxDepth)
+	shapeSum := sha256.Sum256([]byte(shape))
+
+	if len(shape) > maxShapeLength {
+		shape = shape[:maxShapeLength-3] + "..."
+	}
+
+	return &ResultSummary{
+		SizeBytes:   int64(len(raw)),
+		SHA256:      hex.EncodeToString(sum[:]),
+		Shape:       shape,
+		Fingerprint: hex.EncodeToString(shapeSum[:8]),
+	}
+}
+
+type resultObserverKey struct{}
+
+// WithResultObserver returns a context whose operations pass their raw
+// result to observe before it may be replaced by a summary. observe is
+// called synchronously, and not at all when there is no result.
+func WithResultObserver(ctx context.Context, observe func(result json.RawMessage)) context.Context {
+	return context.WithValue(ctx, resultObserverKey{}, observe)
+}
+
+func observeResult(ctx context.Context, outcome *OperationOutcome) {
+	if observe, ok := ctx.Value(resultObserverKey{}).(func(json.RawMessage)); ok && outcome.Result != nil {
+		observe(outcome.Result)
+	}
+}
+
+// summarizeResult replaces a large result on the outcome with its summary so
+// buffered outcomes do not hold full payloads in memory. Initialize results are
+// always kept because session setup parses them.
+func (c *StreamableHTTPConnection) summarizeResult(outcome *OperationOutcome) {
+	capture := c.config.ResultCapture
+	if capture == nil || capture.SummarizeAboveBytes <= 0 || outcome.Operation == OpInitialize {
+		return
+	}
+	if len(outcome.Result) <= capture.SummarizeAboveBytes {
+		return
+	}
+
+	outcome.ResultSummary = SummarizeResult(outcome.Result, capture.FingerprintMaxDepth)
+	outcome.Result = nil
+}
+
+// resultShape walks the JSON token stream and describes its structure without
+// decoding values into memory. Object keys are sorted; arrays are described by
+// their first element.
+func resultShape(raw json.RawMessage, maxDepth int) string {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	var sb strings.Builder
+	if err := writeShape(dec, &sb, 0, maxDepth); err != nil {
+		return "invalid"
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return "invalid"
+	}
+	return sb.String()
+}
+
+func writeShape(dec *json.Decoder, sb *strings.Builder, depth, maxDepth int) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch v := tok.(type) {
+	case json.Delim:
+		switch v {
+		case '{':
+			return writeObjectShape(dec, sb, depth, maxDepth)
+		case '[':
+			return writeArrayShape(dec, sb, depth, maxDepth)
+		}
+		return errors.New("unexpected closing delimiter")
+	case string:
+		sb.WriteString("s")
+	case json.Number:
+		sb.WriteString("n")
+	case bool:
+		sb.WriteString("b")
+	case nil:
+		sb.WriteString("null")
+	}
+	return nil
+}
+
+func writeObjectShape(dec *json.Decoder, sb *strings.Builder, depth, maxDepth int) error {
+	if depth >= maxDepth {
+		sb.WriteString("{...}")
+		return skipRest(dec)
+	}
+
+	fields := make(map[string]string)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := tok.(string)
+
+		var field strings.Builder
+		if err := writeShape(dec, &field, depth+1, maxDepth); err != nil {
+			return err
+		}
+		fields[key] = field.String()
+	}
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	sb.WriteString("{")
+	for i, key := range keys {
+		if i == maxShapeKeys {
+			sb.WriteString(",...")
+			break
+		}
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString(key)
+		sb.WriteString(":")
+		sb.WriteString(fields[key])
+	}
+	sb.WriteString("}")
+	return nil
+}
+
+func writeArrayShape(dec *json.Decoder, sb *strings.Builder, depth, maxDepth int) error {
+	if depth >= maxDepth {
+		sb.WriteString("[...]")
+		return skipRest(dec)
+	}
+
+	sb.WriteString("[")
+	if dec.More() {
+		if err := writeShape(dec, sb, depth+1, maxDepth); err != nil {
+			return err
+		}
+		if err := skipRest(dec); err != nil {
+			return err
+		}
+	} else if _, err := dec.Token(); err != nil {
+		return err
+	}
+	sb.WriteString("]")
+	return nil
+}
+
+// skipRest consumes tokens up to and including the delimiter that closes the
+// current object or array.
+func skipRest(dec *json.Decoder) error {
+	for nesting := 1; nesting > 0; {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if delim, ok := tok.(json.Delim); ok {
+			switch delim {
+			case '{', '[':
+				nesting++
+			case '}', ']':
+				nesting--
+			}
+		}
+	}
+	return nil
+}
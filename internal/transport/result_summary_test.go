@@ -0,0 +1,77 @@
+package transport
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSummarizeResult(t *testing.T) {
+	raw := json.RawMessage(`{"content":[{"type":"text","text":"hello"},{"type":"image","data":"abc"}],"isError":false,"meta":null,"count":3}`)
+
+	summary := SummarizeResult(raw, 4)
+
+	if summary.SizeBytes != int64(len(raw)) {
+		t.Errorf("expected size %d, got %d", len(raw), summary.SizeBytes)
+	}
+	sum := sha256.Sum256(raw)
+	if summary.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("unexpected sha256 %s", summary.SHA256)
+	}
+	expectedShape := "{content:[{text:s,type:s}],count:n,isError:b,meta:null}"
+	if summary.Shape != expectedShape {
+		t.Errorf("expected shape %q, got %q", expectedShape, summary.Shape)
+	}
+	if len(summary.Fingerprint) != 16 {
+		t.Errorf("expected 16 hex char fingerprint, got %q", summary.Fingerprint)
+	}
+}
+
+func TestSummarizeResult_FingerprintIgnoresValues(t *testing.T) {
+	a := SummarizeResult(json.RawMessage(`{"b":1,"a":["x","y"]}`), 4)
+	b := SummarizeResult(json.RawMessage(`{"a":["z"],"b":42}`), 4)
+	c := SummarizeResult(json.RawMessage(`{"a":[1],"b":42}`), 4)
+
+	if a.Fingerprint != b.Fingerprint {
+		t.Errorf("expected same structure to share fingerprint, got %s and %s", a.Fingerprint, b.Fingerprint)
+	}
+	if a.SHA256 == b.SHA256 {
+		t.Error("expected different content to have different hashes")
+	}
+	if a.Fingerprint == c.Fingerprint {
+		t.Error("expected different value types to change the fingerprint")
+	}
+}
+
+func TestSummarizeResult_Limits(t *testing.T) {
+	deep := SummarizeResult(json.RawMessage(`{"a":{"b":{"c":{"d":1}}},"e":[[["f"]]]}`), 2)
+	if deep.Shape != "{a:{b:{...}},e:[[...]]}" {
+		t.Errorf("expected depth-limited shape, got %q", deep.Shape)
+	}
+
+	keys := make([]string, 0, 40)
+	for i := 0; i < 40; i++ {
+		keys = append(keys, fmt.Sprintf(`"k%02d":%d`, i, i))
+	}
+	wide := SummarizeResult(json.RawMessage("{"+strings.Join(keys, ",")+"}"), 4)
+	if !strings.HasSuffix(wide.Shape, "k31:n,...}") {
+		t.Errorf("expected key-limited shape, got %q", wide.Shape)
+	}
+
+	longKeys := make([]string, 0, 30)
+	for i := 0; i < 30; i++ {
+		longKeys = append(longKeys, fmt.Sprintf(`"field_%03d_with_a_long_name":%d`, i, i))
+	}
+	long := SummarizeResult(json.RawMessage("{"+strings.Join(longKeys, ",")+"}"), 4)
+	if len(long.Shape) != maxShapeLength || !strings.HasSuffix(long.Shape, "...") {
+		t.Errorf("expected shape truncated to %d chars, got %d: %q", maxShapeLength, len(long.Shape), long.Shape)
+	}
+
+	invalid := SummarizeResult(json.RawMessage(`{"a":`), 4)
+	if invalid.Shape != "invalid" || invalid.SizeBytes != 5 {
+		t.Errorf("expected invalid JSON to be sized with invalid shape, got %+v", invalid)
+	}
+}
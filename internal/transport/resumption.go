@@ -0,0 +1,109 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/otel"
+)
+
+// resumeStream resumes the response stream that dropped with err: up to
+// ResumeAttempts times it opens the session's SSE stream with a GET that
+// carries Last-Event-ID, so the server replays the events after the last
+// one seen, and keeps reading the response from there. Streams without event
+// IDs cannot be resumed. It returns the response, or the error of the last
+// attempt.
+func (c *StreamableHTTPConnection) resumeStream(
+	ctx context.Context,
+	stream *sseStream,
+	requestID string,
+	outcome *OperationOutcome,
+	err error,
+) (*JSONRPCResponse, error) {
+	signals := stream.signals
+	for signals.ResumeAttempts < c.config.ResumeAttempts && signals.LastEventID != "" && resumable(err) {
+		if c.config.ResumeBackoff > 0 {
+			timer := time.NewTimer(c.config.ResumeBackoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, err
+			case <-timer.C:
+			}
+		}
+
+		signals.ResumeAttempts++
+		body, openErr := c.openResumeStream(ctx, signals.LastEventID)
+		if openErr != nil {
+			err = openErr
+			continue
+		}
+		counted := &countingReadCloser{ReadCloser: body}
+		var resp *JSONRPCResponse
+		resp, err = c.sseHandler.readStream(ctx, stream, counted, requestID)
+		outcome.BytesIn += counted.n
+		if err == nil {
+			signals.Resumed = true
+			return resp, nil
+		}
+	}
+	return nil, err
+}
+
+// resumable reports whether a stream that failed with err may be resumed:
+// it dropped, rather than stalled, sent garbage or ran out of time.
+func resumable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, ErrInvalidJSON) || errors.Is(err, ErrStreamClosed) {
+		return false
+	}
+	var opErr *OperationError
+	if errors.As(err, &opErr) {
+		return opErr.Code == CodeSSEDisconnect
+	}
+	return true
+}
+
+// openResumeStream opens the session's SSE stream from lastEventID. Network
+// errors are returned as is, so the attempt may be retried; a server that
+// refuses the stream or answers with something other than SSE gets an
+// OperationError, which ends resumption.
+func (c *StreamableHTTPConnection) openResumeStream(ctx context.Context, lastEventID string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.config.Endpoint, nil)
+	if err != nil {
+		return nil, MapError(err)
+	}
+	token, opErr := c.bearerToken(ctx)
+	if opErr != nil {
+		return nil, opErr
+	}
+	c.setHeaders(req, false, token)
+	req.Header.Del(HeaderContentType)
+	req.Header.Set(HeaderAccept, ContentTypeSSE)
+	req.Header.Set(HeaderLastEventID, lastEventID)
+	otel.InjectHeaders(ctx, req.Header, otel.GetGlobalTracer())
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	c.checkTokenRejected(resp, token)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, MapHTTPStatusWithBody(resp.StatusCode, strings.TrimSpace(string(bodyBytes)))
+	}
+	if contentType := resp.Header.Get(HeaderContentType); !isSSEContentType(contentType) {
+		resp.Body.Close()
+		return nil, MapProtocolError(fmt.Sprintf("resumed stream has content type %q, not %s", contentType, ContentTypeSSE))
+	}
+	return resp.Body, nil
+}
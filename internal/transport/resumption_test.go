@@ -0,0 +1,111 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// newResumableServer answers a POST with an SSE stream that sends one
+// progress event and drops before the response. A GET with the right session
+// and Last-Event-ID gets the response; getStatus overrides that with an error
+// status.
+func newResumableServer(t *testing.T, getStatus int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var gets atomic.Int32
+	var requestID atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			var req JSONRPCRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			data, _ := json.Marshal(req.ID)
+			requestID.Store(string(data))
+			w.Header().Set(HeaderMCPSessionID, "ses_resume")
+			w.Header().Set(HeaderContentType, ContentTypeSSE)
+			fmt.Fprint(w, "id: evt_1\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\"}\n\n")
+		case http.MethodGet:
+			gets.Add(1)
+			if r.Header.Get(HeaderMCPSessionID) != "ses_resume" || r.Header.Get(HeaderLastEventID) != "evt_1" {
+				t.Errorf("resume request without session or last event id: %v", r.Header)
+			}
+			if r.Header.Get(HeaderAccept) != ContentTypeSSE {
+				t.Errorf("expected resume request to accept %s, got %q", ContentTypeSSE, r.Header.Get(HeaderAccept))
+			}
+			if getStatus != 0 {
+				w.WriteHeader(getStatus)
+				return
+			}
+			w.Header().Set(HeaderContentType, ContentTypeSSE)
+			fmt.Fprintf(w, "id: evt_2\ndata: {\"jsonrpc\":\"2.0\",\"id\":%s,\"result\":{\"tools\":[]}}\n\n", requestID.Load())
+		}
+	}))
+	return server, &gets
+}
+
+func resumeTestConnection(t *testing.T, server *httptest.Server, attempts int) Connection {
+	t.Helper()
+	conn, err := NewStreamableHTTPAdapter().Connect(context.Background(), &TransportConfig{
+		AllowPrivateNetworks: []string{"127.0.0.0/8"},
+		Endpoint:             server.URL,
+		Timeouts:             DefaultTimeoutConfig(),
+		ResumeAttempts:       attempts,
+	})
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestResumeStream(t *testing.T) {
+	t.Run("resumes from last event id", func(t *testing.T) {
+		server, gets := newResumableServer(t, 0)
+		defer server.Close()
+
+		outcome, _ := resumeTestConnection(t, server, 2).ToolsList(context.Background(), nil)
+		if !outcome.OK {
+			t.Fatalf("expected the resumed call to succeed, got %v", outcome.Error)
+		}
+		s := outcome.Stream
+		if s == nil || !s.Resumed || s.ResumeAttempts != 1 || s.EventsCount != 2 || s.LastEventID != "evt_2" {
+			t.Errorf("unexpected stream signals %+v", s)
+		}
+		if gets.Load() != 1 {
+			t.Errorf("expected one resume request, got %d", gets.Load())
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		server, gets := newResumableServer(t, 0)
+		defer server.Close()
+
+		outcome, _ := resumeTestConnection(t, server, 0).ToolsList(context.Background(), nil)
+		if outcome.OK || outcome.Error == nil || outcome.Error.Code != CodeSSEDisconnect {
+			t.Fatalf("expected an SSE disconnect, got %+v", outcome.Error)
+		}
+		if gets.Load() != 0 || outcome.Stream.ResumeAttempts != 0 {
+			t.Errorf("expected no resume attempt, got %d", gets.Load())
+		}
+	})
+
+	t.Run("refused", func(t *testing.T) {
+		server, gets := newResumableServer(t, http.StatusMethodNotAllowed)
+		defer server.Close()
+
+		outcome, _ := resumeTestConnection(t, server, 3).ToolsList(context.Background(), nil)
+		if outcome.OK {
+			t.Fatal("expected the call to fail when the server refuses to resume")
+		}
+		if s := outcome.Stream; s.Resumed || s.ResumeAttempts != 1 {
+			t.Errorf("expected a single failed attempt, got %+v", s)
+		}
+		if gets.Load() != 1 {
+			t.Errorf("expected resumption to stop after a refusal, got %d requests", gets.Load())
+		}
+	})
+}
@@ -0,0 +1,36 @@
+package transport
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HeaderRetryAfter is the header a throttled response uses to say when to
+// retry.
+const HeaderRetryAfter = "Retry-After"
+
+// retryAfter reads the Retry-After header of a 429 or 503 response, given
+// in seconds or as an HTTP date. Returns 0 for other statuses or if the
+// header is missing, unreadable or in the past.
+func retryAfter(resp *http.Response, now time.Time) time.Duration {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0
+	}
+	value := strings.TrimSpace(resp.Header.Get(HeaderRetryAfter))
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	at, err := http.ParseTime(value)
+	if err != nil || !at.After(now) {
+		return 0
+	}
+	return at.Sub(now)
+}
@@ -0,0 +1,63 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name   string
+		status int
+		value  string
+		want   time.Duration
+	}{
+		{"seconds", http.StatusTooManyRequests, "3", 3 * time.Second},
+		{"http date", http.StatusServiceUnavailable, now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second},
+		{"date in the past", http.StatusTooManyRequests, now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		{"unreadable", http.StatusTooManyRequests, "soon", 0},
+		{"missing", http.StatusTooManyRequests, "", 0},
+		{"other status", http.StatusBadGateway, "3", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			if tt.value != "" {
+				resp.Header.Set(HeaderRetryAfter, tt.value)
+			}
+			if got := retryAfter(resp, now); got != tt.want {
+				t.Errorf("retryAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToolsCallRetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderRetryAfter, "2")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	conn, err := NewStreamableHTTPAdapter().Connect(context.Background(), &TransportConfig{
+		AllowPrivateNetworks: []string{"127.0.0.0/8"},
+		Endpoint:             server.URL,
+		Timeouts:             DefaultTimeoutConfig(),
+	})
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	defer conn.Close()
+
+	outcome, _ := conn.ToolsCall(context.Background(), &ToolsCallParams{Name: "echo"})
+	if outcome.OK || outcome.Error.Type != ErrorTypeRateLimited {
+		t.Fatalf("expected a rate limited outcome, got %+v", outcome.Error)
+	}
+	if outcome.RetryAfterMs != 2000 {
+		t.Errorf("expected Retry-After of 2000ms, got %d", outcome.RetryAfterMs)
+	}
+}
@@ -0,0 +1,132 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/bc-dunia/mcpdrill/internal/otel"
+)
+
+const (
+	// MethodSamplingCreateMessage is the request a server sends to have the
+	// client sample from a language model.
+	MethodSamplingCreateMessage = "sampling/createMessage"
+	// MethodRootsList is the request a server sends to list the client's
+	// roots.
+	MethodRootsList = "roots/list"
+
+	// jsonRPCMethodNotFound is the JSON-RPC error code for unknown methods.
+	jsonRPCMethodNotFound = -32601
+)
+
+// ServerRequestsConfig sets how a connection answers the requests a server
+// sends on a response stream. Every request is answered: with the canned
+// result configured for its method, an empty result for ping, or a
+// method-not-found error.
+type ServerRequestsConfig struct {
+	// Results maps a request method, such as sampling/createMessage or
+	// roots/list, to the result sent back.
+	Results map[string]json.RawMessage
+}
+
+// ClientCapabilities returns the capabilities to advertise in initialize:
+// sampling and roots if there are results for their requests.
+func (c *ServerRequestsConfig) ClientCapabilities() map[string]interface{} {
+	capabilities := make(map[string]interface{})
+	if c == nil {
+		return capabilities
+	}
+	if _, ok := c.Results[MethodSamplingCreateMessage]; ok {
+		capabilities["sampling"] = map[string]interface{}{}
+	}
+	if _, ok := c.Results[MethodRootsList]; ok {
+		capabilities["roots"] = map[string]interface{}{}
+	}
+	return capabilities
+}
+
+// reply returns the JSON-RPC response to the request method with id.
+func (c *ServerRequestsConfig) reply(id json.RawMessage, method string) map[string]interface{} {
+	msg := map[string]interface{}{"jsonrpc": "2.0", "id": id}
+	if result, ok := c.Results[method]; ok {
+		msg["result"] = result
+	} else if method == string(OpPing) {
+		msg["result"] = map[string]interface{}{}
+	} else {
+		msg["error"] = JSONRPCError{Code: jsonRPCMethodNotFound, Message: "method not found: " + method}
+	}
+	return msg
+}
+
+// serverMessage is a message on a response stream that is not a response:
+// a request from the server if it has an ID, otherwise a notification.
+type serverMessage struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+func (m *serverMessage) isRequest() bool {
+	return len(m.ID) > 0 && string(m.ID) != "null"
+}
+
+// recordServerMessage counts msg in the stream's signals and, if it is a
+// request and the stream answers requests, answers it. Progress
+// notifications are checked against the request's progress token.
+func (s *sseStream) recordServerMessage(ctx context.Context, msg *serverMessage) {
+	signals := s.signals
+	if msg.Method == MethodProgressNotification {
+		s.recordProgress(msg.Params)
+	}
+	if signals.ServerMessages == nil {
+		signals.ServerMessages = make(map[string]int)
+	}
+	signals.ServerMessages[msg.Method]++
+	if !msg.isRequest() || s.answer == nil {
+		return
+	}
+	if err := s.answer(ctx, msg); err != nil {
+		signals.ServerReplyErrors++
+		return
+	}
+	signals.ServerReplies++
+}
+
+// answerServerRequest posts the configured answer to a request the server
+// sent on a response stream.
+func (c *StreamableHTTPConnection) answerServerRequest(ctx context.Context, msg *serverMessage) error {
+	body, err := json.Marshal(c.config.ServerRequests.reply(msg.ID, msg.Method))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	token, opErr := c.bearerToken(ctx)
+	if opErr != nil {
+		return opErr
+	}
+	c.setHeaders(req, false, token)
+	otel.InjectHeaders(ctx, req.Header, otel.GetGlobalTracer())
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	c.checkTokenRejected(resp, token)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("answer to %s: %w", msg.Method,
+			MapHTTPStatusWithBody(resp.StatusCode, strings.TrimSpace(string(bodyBytes))))
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	return nil
+}
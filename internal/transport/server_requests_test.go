@@ -0,0 +1,99 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newBidirectionalServer answers tools/call with an SSE stream that sends a
+// sampling request, a roots/list request and a tools/list_changed
+// notification, and sends the tool result once both requests are answered.
+// Answers are reported on the returned channel.
+func newBidirectionalServer(t *testing.T) (*httptest.Server, chan map[string]json.RawMessage) {
+	t.Helper()
+	answers := make(chan map[string]json.RawMessage, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg map[string]json.RawMessage
+		json.NewDecoder(r.Body).Decode(&msg)
+		if _, ok := msg["method"]; !ok {
+			answers <- msg
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		w.Header().Set(HeaderContentType, ContentTypeSSE)
+		flusher := w.(http.Flusher)
+		fmt.Fprint(w, "data: {\"jsonrpc\":\"2.0\",\"id\":7,\"method\":\"sampling/createMessage\",\"params\":{}}\n\n")
+		fmt.Fprint(w, "data: {\"jsonrpc\":\"2.0\",\"id\":\"r1\",\"method\":\"roots/list\"}\n\n")
+		fmt.Fprint(w, "data: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/tools/list_changed\"}\n\n")
+		flusher.Flush()
+		for len(answers) < 2 {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+		fmt.Fprintf(w, "data: {\"jsonrpc\":\"2.0\",\"id\":%s,\"result\":{\"content\":[]}}\n\n", msg["id"])
+	}))
+	return server, answers
+}
+
+func TestServerRequests(t *testing.T) {
+	server, answers := newBidirectionalServer(t)
+	defer server.Close()
+
+	conn, err := NewStreamableHTTPAdapter().Connect(context.Background(), &TransportConfig{
+		AllowPrivateNetworks: []string{"127.0.0.0/8"},
+		Endpoint:             server.URL,
+		Timeouts:             DefaultTimeoutConfig(),
+		ServerRequests: &ServerRequestsConfig{Results: map[string]json.RawMessage{
+			MethodSamplingCreateMessage: json.RawMessage(`{"role":"assistant","content":{"type":"text","text":"ok"},"model":"canned"}`),
+		}},
+	})
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	defer conn.Close()
+
+	outcome, _ := conn.ToolsCall(context.Background(), &ToolsCallParams{Name: "ask"})
+	if !outcome.OK {
+		t.Fatalf("expected the call to succeed, got %v", outcome.Error)
+	}
+	s := outcome.Stream
+	if s.ServerMessages[MethodSamplingCreateMessage] != 1 || s.ServerMessages[MethodRootsList] != 1 ||
+		s.ServerMessages["notifications/tools/list_changed"] != 1 {
+		t.Errorf("unexpected server message counts %v", s.ServerMessages)
+	}
+	if s.ServerReplies != 2 || s.ServerReplyErrors != 0 {
+		t.Errorf("expected two accepted answers, got %d and %d refused", s.ServerReplies, s.ServerReplyErrors)
+	}
+
+	byID := make(map[string]map[string]json.RawMessage)
+	for i := 0; i < 2; i++ {
+		answer := <-answers
+		byID[string(answer["id"])] = answer
+	}
+	if sampling := byID["7"]; sampling == nil || len(sampling["result"]) == 0 {
+		t.Errorf("expected the canned sampling result, got %v", sampling)
+	}
+	if roots := byID[`"r1"`]; roots == nil || len(roots["error"]) == 0 {
+		t.Errorf("expected method not found for roots/list without a result, got %v", roots)
+	}
+}
+
+func TestServerRequestsConfig_ClientCapabilities(t *testing.T) {
+	var none *ServerRequestsConfig
+	if caps := none.ClientCapabilities(); len(caps) != 0 {
+		t.Errorf("expected no capabilities without config, got %v", caps)
+	}
+	cfg := &ServerRequestsConfig{Results: map[string]json.RawMessage{MethodRootsList: json.RawMessage(`{"roots":[]}`)}}
+	if caps := cfg.ClientCapabilities(); caps["roots"] == nil || caps["sampling"] != nil {
+		t.Errorf("expected only the roots capability, got %v", caps)
+	}
+}
@@ -242,32 +242,61 @@ func NewSSEResponseHandler(stallTimeout time.Duration) *SSEResponseHandler {
 	}
 }
 
+// sseStream is the state of one response read from SSE. A resumed response
+// keeps it across the streams it is read from.
+type sseStream struct {
+	signals        *StreamSignals
+	gapTracker     *eventGapTracker
+	startTime      time.Time
+	firstEventTime *time.Time
+	// answer, if set, answers requests the server sends on the stream.
+	answer func(context.Context, *serverMessage) error
+	// progressToken is the token sent with the request, empty if none.
+	progressToken string
+	lastProgress  *float64
+}
+
+func newSSEStream() *sseStream {
+	return &sseStream{
+		signals:    &StreamSignals{IsStreaming: true},
+		gapTracker: newEventGapTracker(),
+		startTime:  time.Now(),
+	}
+}
+
 func (h *SSEResponseHandler) HandleSSEStream(
 	ctx context.Context,
 	body io.ReadCloser,
 	requestID string,
 ) (*JSONRPCResponse, *StreamSignals, error) {
+	stream := newSSEStream()
+	resp, err := h.readStream(ctx, stream, body, requestID)
+	return resp, stream.signals, err
+}
+
+// readStream reads events from body into stream until the response to
+// requestID arrives or the stream ends.
+func (h *SSEResponseHandler) readStream(
+	ctx context.Context,
+	stream *sseStream,
+	body io.ReadCloser,
+	requestID string,
+) (*JSONRPCResponse, error) {
 	decoder := NewSSEDecoder(body, h.stallTimeout)
 	defer decoder.Close()
 
-	signals := &StreamSignals{
-		IsStreaming: true,
-	}
-
-	notifications := make([]json.RawMessage, 0, 16)
+	signals := stream.signals
+	gapTracker := stream.gapTracker
+	startTime := stream.startTime
 	var finalResponse *JSONRPCResponse
-	startTime := time.Now()
-	var firstEventTime *time.Time
 	var lastEventTime *time.Time
 
-	gapTracker := newEventGapTracker()
-
 	for {
 		select {
 		case <-ctx.Done():
 			signals.EndedNormally = false
-			h.finalizeStreamSignals(signals, gapTracker, firstEventTime, startTime)
-			return nil, signals, ctx.Err()
+			h.finalizeStreamSignals(signals, gapTracker, stream.firstEventTime, startTime)
+			return nil, ctx.Err()
 		default:
 		}
 
@@ -284,18 +313,18 @@ func (h *SSEResponseHandler) HandleSSEStream(
 				signals.Stalled = true
 				signals.StallDurationMs = int(h.stallTimeout.Milliseconds())
 				signals.EndedNormally = false
-				h.finalizeStreamSignals(signals, gapTracker, firstEventTime, startTime)
-				return nil, signals, NewStreamStallError(signals.StallDurationMs)
+				h.finalizeStreamSignals(signals, gapTracker, stream.firstEventTime, startTime)
+				return nil, NewStreamStallError(signals.StallDurationMs)
 			}
 			signals.EndedNormally = false
-			h.finalizeStreamSignals(signals, gapTracker, firstEventTime, startTime)
-			return nil, signals, err
+			h.finalizeStreamSignals(signals, gapTracker, stream.firstEventTime, startTime)
+			return nil, err
 		}
 
 		now := time.Now()
 
-		if firstEventTime == nil {
-			firstEventTime = &now
+		if stream.firstEventTime == nil {
+			stream.firstEventTime = &now
 			signals.StreamConnectMs = now.Sub(startTime).Milliseconds()
 			signals.TimeToFirstEventMs = signals.StreamConnectMs
 		}
@@ -307,6 +336,9 @@ func (h *SSEResponseHandler) HandleSSEStream(
 		lastEventTime = &now
 
 		signals.EventsCount++
+		if id := decoder.LastEventID(); id != "" {
+			signals.LastEventID = id
+		}
 
 		if event.Data == "" {
 			continue
@@ -314,21 +346,17 @@ func (h *SSEResponseHandler) HandleSSEStream(
 
 		var msg JSONRPCResponse
 		if err := json.Unmarshal([]byte(event.Data), &msg); err != nil {
-			var notification struct {
-				JSONRPC string `json:"jsonrpc"`
-				Method  string `json:"method"`
-			}
-			if json.Unmarshal([]byte(event.Data), &notification) == nil && notification.Method != "" {
-				notifications = append(notifications, json.RawMessage(event.Data))
+			var serverMsg serverMessage
+			if json.Unmarshal([]byte(event.Data), &serverMsg) == nil && serverMsg.Method != "" {
+				stream.recordServerMessage(ctx, &serverMsg)
 				continue
 			}
-			h.finalizeStreamSignals(signals, gapTracker, firstEventTime, startTime)
-			return nil, signals, fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+			h.finalizeStreamSignals(signals, gapTracker, stream.firstEventTime, startTime)
+			return nil, fmt.Errorf("%w: %v", ErrInvalidJSON, err)
 		}
 
 		if msg.ID != nil {
-			idStr := fmt.Sprintf("%v", msg.ID)
-			if idStr == requestID {
+			if responseIDKey(msg.ID) == requestID {
 				finalResponse = &msg
 				signals.EndedNormally = true
 				break
@@ -336,20 +364,22 @@ func (h *SSEResponseHandler) HandleSSEStream(
 		}
 
 		if msg.Result == nil && msg.Error == nil {
-			notifications = append(notifications, json.RawMessage(event.Data))
+			var serverMsg serverMessage
+			if json.Unmarshal([]byte(event.Data), &serverMsg) == nil && serverMsg.Method != "" {
+				stream.recordServerMessage(ctx, &serverMsg)
+			}
 		}
 	}
 
 	if finalResponse == nil {
 		signals.EndedNormally = false
-		h.finalizeStreamSignals(signals, gapTracker, firstEventTime, startTime)
-		return nil, signals, NewSSEDisconnectError(signals.EventsCount, decoder.LastEventID())
+		h.finalizeStreamSignals(signals, gapTracker, stream.firstEventTime, startTime)
+		return nil, NewSSEDisconnectError(signals.EventsCount, signals.LastEventID)
 	}
 
-	_ = notifications
-	h.finalizeStreamSignals(signals, gapTracker, firstEventTime, startTime)
+	h.finalizeStreamSignals(signals, gapTracker, stream.firstEventTime, startTime)
 
-	return finalResponse, signals, nil
+	return finalResponse, nil
 }
 
 func (h *SSEResponseHandler) finalizeStreamSignals(
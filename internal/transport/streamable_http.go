@@ -16,6 +16,8 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/otel"
 )
 
 const (
@@ -74,20 +76,25 @@ func (a *StreamableHTTPAdapter) Connect(ctx context.Context, config *TransportCo
 	// Build CheckRedirect function based on redirect policy
 	checkRedirect := buildCheckRedirect(config)
 
+	var roundTripper http.RoundTripper = transport
+	if config.Chaos != nil {
+		roundTripper = newChaosTransport(transport, config.Chaos)
+	}
+
 	client := &http.Client{
-		Transport:     transport,
+		Transport:     roundTripper,
 		Timeout:       0,
 		CheckRedirect: checkRedirect,
 	}
 
 	conn := &StreamableHTTPConnection{
-		client:       client,
-		transport:    transport,
-		config:       config,
-		sseHandler:   NewSSEResponseHandler(config.Timeouts.StreamStallTimeout),
-		sessionID:    config.SessionID,
-		lastEventID:  config.LastEventID,
-		requestCount: 0,
+		client:      client,
+		transport:   transport,
+		config:      config,
+		sseHandler:  NewSSEResponseHandler(config.Timeouts.StreamStallTimeout),
+		sessionID:   config.SessionID,
+		lastEventID: config.LastEventID,
+		requestIDs:  &requestIDGenerator{config: config.RequestIDs},
 	}
 
 	return conn, nil
@@ -163,15 +170,15 @@ func buildCheckRedirect(config *TransportConfig) func(req *http.Request, via []*
 }
 
 type StreamableHTTPConnection struct {
-	client       *http.Client
-	transport    *http.Transport
-	config       *TransportConfig
-	sseHandler   *SSEResponseHandler
-	sessionID    string
-	lastEventID  string
-	requestCount int64
-	mu           sync.RWMutex
-	closed       int32
+	client      *http.Client
+	transport   *http.Transport
+	config      *TransportConfig
+	sseHandler  *SSEResponseHandler
+	sessionID   string
+	lastEventID string
+	requestIDs  *requestIDGenerator
+	mu          sync.RWMutex
+	closed      int32
 }
 
 func (c *StreamableHTTPConnection) SessionID() string {
@@ -201,8 +208,8 @@ func (c *StreamableHTTPConnection) Close() error {
 }
 
 func (c *StreamableHTTPConnection) Initialize(ctx context.Context, params *InitializeParams) (*OperationOutcome, error) {
-	requestID := c.nextRequestID()
-	req := NewInitializeRequest(requestID, params)
+	requestID := c.requestIDs.next(OpInitialize)
+	req := NewInitializeRequest(requestID.key, params)
 
 	outcome := c.doRequest(ctx, req, OpInitialize, requestID)
 	return outcome, nil
@@ -216,97 +223,123 @@ func (c *StreamableHTTPConnection) SendInitialized(ctx context.Context) (*Operat
 }
 
 func (c *StreamableHTTPConnection) ToolsList(ctx context.Context, cursor *string) (*OperationOutcome, error) {
-	requestID := c.nextRequestID()
-	req := NewToolsListRequest(requestID, cursor)
+	requestID := c.requestIDs.next(OpToolsList)
+	req := NewToolsListRequest(requestID.key, cursor)
 
 	outcome := c.doRequest(ctx, req, OpToolsList, requestID)
 	return outcome, nil
 }
 
 func (c *StreamableHTTPConnection) ToolsCall(ctx context.Context, params *ToolsCallParams) (*OperationOutcome, error) {
-	requestID := c.nextRequestID()
+	requestID := c.requestIDs.next(OpToolsCall)
 
 	if c.config.ValidationConfig != nil && c.config.ValidationConfig.MaxArgumentSizeBytes > 0 {
 		if err := ValidateArgumentSize(params.Arguments, c.config.ValidationConfig.MaxArgumentSizeBytes); err != nil {
 			slog.Warn("argument validation failed",
-				"request_id", requestID,
+				"request_id", requestID.key,
 				"tool_name", params.Name,
 				"error", err.Error())
 		}
 	}
 
-	req := NewToolsCallRequest(requestID, params.Name, params.Arguments)
+	callParams := ToolsCallParams{Name: params.Name, Arguments: params.Arguments, Meta: params.Meta}
+	if c.config.ProgressTokens {
+		callParams = withProgressToken(callParams, requestID.key)
+	}
+	req := newToolsCallRequest(requestID.key, callParams)
 
+	if plan, ok := cancellationFrom(ctx); ok {
+		return c.doCancellableRequest(ctx, plan, req, OpToolsCall, requestID, params.Name), nil
+	}
 	outcome := c.doRequest(ctx, req, OpToolsCall, requestID, params.Name)
 	return outcome, nil
 }
 
 func (c *StreamableHTTPConnection) Ping(ctx context.Context) (*OperationOutcome, error) {
-	requestID := c.nextRequestID()
-	req := NewPingRequest(requestID)
+	requestID := c.requestIDs.next(OpPing)
+	req := NewPingRequest(requestID.key)
 
 	outcome := c.doRequest(ctx, req, OpPing, requestID)
 	return outcome, nil
 }
 
 func (c *StreamableHTTPConnection) ResourcesList(ctx context.Context, cursor *string) (*OperationOutcome, error) {
-	requestID := c.nextRequestID()
-	req := NewResourcesListRequest(requestID, cursor)
+	requestID := c.requestIDs.next(OpResourcesList)
+	req := NewResourcesListRequest(requestID.key, cursor)
 
 	outcome := c.doRequest(ctx, req, OpResourcesList, requestID)
 	return outcome, nil
 }
 
 func (c *StreamableHTTPConnection) ResourcesRead(ctx context.Context, params *ResourcesReadParams) (*OperationOutcome, error) {
-	requestID := c.nextRequestID()
-	req := NewResourcesReadRequest(requestID, params.URI)
+	if uriErr := ValidateResourceURI(params.URI, c.config.AllowPrivateNetworks); uriErr != nil {
+		return &OperationOutcome{
+			Operation:   OpResourcesRead,
+			ResourceURI: params.URI,
+			Transport:   TransportIDStreamableHTTP,
+			StartTime:   time.Now(),
+			OK:          false,
+			Error:       uriErr,
+		}, nil
+	}
+
+	requestID := c.requestIDs.next(OpResourcesRead)
+	req := NewResourcesReadRequest(requestID.key, params.URI)
 
 	outcome := c.doRequest(ctx, req, OpResourcesRead, requestID)
+	outcome.ResourceURI = params.URI
 	return outcome, nil
 }
 
 func (c *StreamableHTTPConnection) PromptsList(ctx context.Context, cursor *string) (*OperationOutcome, error) {
-	requestID := c.nextRequestID()
-	req := NewPromptsListRequest(requestID, cursor)
+	requestID := c.requestIDs.next(OpPromptsList)
+	req := NewPromptsListRequest(requestID.key, cursor)
 
 	outcome := c.doRequest(ctx, req, OpPromptsList, requestID)
 	return outcome, nil
 }
 
 func (c *StreamableHTTPConnection) PromptsGet(ctx context.Context, params *PromptsGetParams) (*OperationOutcome, error) {
-	requestID := c.nextRequestID()
-	req := NewPromptsGetRequest(requestID, params.Name, params.Arguments)
+	requestID := c.requestIDs.next(OpPromptsGet)
+	req := NewPromptsGetRequest(requestID.key, params.Name, params.Arguments)
 
 	outcome := c.doRequest(ctx, req, OpPromptsGet, requestID)
+	outcome.PromptName = params.Name
 	return outcome, nil
 }
 
-func (c *StreamableHTTPConnection) nextRequestID() string {
-	count := atomic.AddInt64(&c.requestCount, 1)
-	return fmt.Sprintf("req_%d", count)
-}
-
 func (c *StreamableHTTPConnection) doRequest(
 	ctx context.Context,
 	jsonrpcReq *JSONRPCRequest,
 	opType OperationType,
-	requestID string,
+	id requestID,
 	toolName ...string,
 ) *OperationOutcome {
+	requestID := id.key
+	jsonrpcReq.ID = id.wire
 	outcome := &OperationOutcome{
 		Operation: opType,
 		JSONRPCID: requestID,
 		Transport: TransportIDStreamableHTTP,
 		StartTime: time.Now(),
+		RequestID: id.info,
 	}
 	if len(toolName) > 0 {
 		outcome.ToolName = toolName[0]
 	}
 
+	ctx, span := startOperationSpan(ctx, c.config.Endpoint, opType, outcome.ToolName)
+	defer func() { endOperationSpan(span, outcome) }()
+
 	ctx, cancel := context.WithTimeout(ctx, c.config.Timeouts.RequestTimeout)
 	defer cancel()
 
 	tracedCtx, phaseTracker := createTracedContext(ctx)
+	if c.config.Chaos != nil {
+		recorder := &chaosRecorder{}
+		tracedCtx = withChaosRecorder(tracedCtx, recorder)
+		defer func() { outcome.Chaos = recorder.info() }()
+	}
 
 	body, err := json.Marshal(jsonrpcReq)
 	if err != nil {
@@ -325,10 +358,19 @@ func (c *StreamableHTTPConnection) doRequest(
 		return outcome
 	}
 
+	token, opErr := c.bearerToken(ctx)
+	if opErr != nil {
+		outcome.OK = false
+		outcome.Error = opErr
+		outcome.LatencyMs = time.Since(outcome.StartTime).Milliseconds()
+		return outcome
+	}
+
 	c.mu.RLock()
 	hasLastEventID := c.lastEventID != ""
 	c.mu.RUnlock()
-	c.setHeaders(httpReq, hasLastEventID)
+	c.setHeaders(httpReq, hasLastEventID, token)
+	otel.InjectHeaders(ctx, httpReq.Header, otel.GetGlobalTracer())
 
 	resp, err := c.client.Do(httpReq)
 	if err != nil {
@@ -339,9 +381,13 @@ func (c *StreamableHTTPConnection) doRequest(
 		return outcome
 	}
 	defer resp.Body.Close()
+	c.checkTokenRejected(resp, token)
 
 	outcome.HTTPStatus = &resp.StatusCode
 	outcome.ContentType = resp.Header.Get(HeaderContentType)
+	if c.config.BackendIDHeader != "" {
+		outcome.BackendID = resp.Header.Get(c.config.BackendIDHeader)
+	}
 
 	if sessionID := resp.Header.Get(HeaderMCPSessionID); sessionID != "" {
 		c.SetSessionID(sessionID)
@@ -352,12 +398,15 @@ func (c *StreamableHTTPConnection) doRequest(
 		outcome.OK = false
 		bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
 		outcome.Error = MapHTTPStatusWithBody(resp.StatusCode, strings.TrimSpace(string(bodyBytes)))
+		outcome.RetryAfterMs = retryAfter(resp, time.Now()).Milliseconds()
 		outcome.LatencyMs = time.Since(outcome.StartTime).Milliseconds()
 		outcome.PhaseTiming = phaseTracker.computePhaseTiming(time.Now())
 		return outcome
 	}
 
 	c.handleResponse(ctx, resp, outcome, requestID)
+	observeResult(ctx, outcome)
+	c.summarizeResult(outcome)
 	endTime := time.Now()
 	outcome.LatencyMs = endTime.Sub(outcome.StartTime).Milliseconds()
 	outcome.PhaseTiming = phaseTracker.computePhaseTiming(endTime)
@@ -376,10 +425,18 @@ func (c *StreamableHTTPConnection) doNotification(
 		StartTime: time.Now(),
 	}
 
+	ctx, span := startOperationSpan(ctx, c.config.Endpoint, opType, "")
+	defer func() { endOperationSpan(span, outcome) }()
+
 	ctx, cancel := context.WithTimeout(ctx, c.config.Timeouts.RequestTimeout)
 	defer cancel()
 
 	tracedCtx, phaseTracker := createTracedContext(ctx)
+	if c.config.Chaos != nil {
+		recorder := &chaosRecorder{}
+		tracedCtx = withChaosRecorder(tracedCtx, recorder)
+		defer func() { outcome.Chaos = recorder.info() }()
+	}
 
 	body, err := json.Marshal(jsonrpcReq)
 	if err != nil {
@@ -398,7 +455,16 @@ func (c *StreamableHTTPConnection) doNotification(
 		return outcome
 	}
 
-	c.setHeaders(httpReq, false)
+	token, opErr := c.bearerToken(ctx)
+	if opErr != nil {
+		outcome.OK = false
+		outcome.Error = opErr
+		outcome.LatencyMs = time.Since(outcome.StartTime).Milliseconds()
+		return outcome
+	}
+
+	c.setHeaders(httpReq, false, token)
+	otel.InjectHeaders(ctx, httpReq.Header, otel.GetGlobalTracer())
 
 	resp, err := c.client.Do(httpReq)
 	if err != nil {
@@ -409,9 +475,13 @@ func (c *StreamableHTTPConnection) doNotification(
 		return outcome
 	}
 	defer resp.Body.Close()
+	c.checkTokenRejected(resp, token)
 
 	outcome.HTTPStatus = &resp.StatusCode
 	outcome.ContentType = resp.Header.Get(HeaderContentType)
+	if c.config.BackendIDHeader != "" {
+		outcome.BackendID = resp.Header.Get(c.config.BackendIDHeader)
+	}
 
 	switch resp.StatusCode {
 	case http.StatusOK, http.StatusAccepted, http.StatusNoContent:
@@ -420,6 +490,7 @@ func (c *StreamableHTTPConnection) doNotification(
 		outcome.OK = false
 		bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
 		outcome.Error = MapHTTPStatusWithBody(resp.StatusCode, strings.TrimSpace(string(bodyBytes)))
+		outcome.RetryAfterMs = retryAfter(resp, time.Now()).Milliseconds()
 	}
 
 	endTime := time.Now()
@@ -428,7 +499,35 @@ func (c *StreamableHTTPConnection) doNotification(
 	return outcome
 }
 
-func (c *StreamableHTTPConnection) setHeaders(req *http.Request, includeLastEventID bool) {
+// bearerToken returns the token from the configured token source, or "" if
+// there is none.
+func (c *StreamableHTTPConnection) bearerToken(ctx context.Context) (string, *OperationError) {
+	if c.config.TokenSource == nil {
+		return "", nil
+	}
+	token, err := c.config.TokenSource.Token(ctx)
+	if err != nil {
+		return "", &OperationError{
+			Type:    ErrorTypeAuth,
+			Code:    CodeAuthTokenFailed,
+			Message: fmt.Sprintf("failed to obtain auth token: %v", err),
+		}
+	}
+	return token, nil
+}
+
+// checkTokenRejected drops a token the target answered 401 to, so the next
+// request fetches a new one instead of failing until the token expires.
+func (c *StreamableHTTPConnection) checkTokenRejected(resp *http.Response, token string) {
+	if token == "" || resp.StatusCode != http.StatusUnauthorized {
+		return
+	}
+	if invalidator, ok := c.config.TokenSource.(tokenInvalidator); ok {
+		invalidator.Invalidate(token)
+	}
+}
+
+func (c *StreamableHTTPConnection) setHeaders(req *http.Request, includeLastEventID bool, token string) {
 	req.Header.Set(HeaderContentType, ContentTypeJSON)
 	req.Header.Set(HeaderAccept, AcceptBoth)
 
@@ -448,6 +547,12 @@ func (c *StreamableHTTPConnection) setHeaders(req *http.Request, includeLastEven
 	for key, value := range c.config.Headers {
 		req.Header.Set(key, value)
 	}
+	for key, value := range requestHeaders(req.Context()) {
+		req.Header.Set(key, value)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
 }
 
 func (c *StreamableHTTPConnection) handleResponse(
@@ -502,6 +607,7 @@ func (c *StreamableHTTPConnection) handleJSONResponse(
 		outcome.Error = validationErr
 		return
 	}
+	recordIDEcho(outcome, jsonrpcResp.ID)
 
 	if jsonrpcErr := ExtractJSONRPCError(&jsonrpcResp); jsonrpcErr != nil {
 		outcome.OK = false
@@ -513,6 +619,12 @@ func (c *StreamableHTTPConnection) handleJSONResponse(
 	outcome.OK = true
 	outcome.Result = jsonrpcResp.Result
 
+	if shapeErr := CheckResultShape(outcome.Operation, jsonrpcResp.Result); shapeErr != nil {
+		outcome.OK = false
+		outcome.Error = shapeErr
+		return
+	}
+
 	if outcome.Operation == OpToolsCall {
 		var toolResult ToolsCallResult
 		if err := json.Unmarshal(jsonrpcResp.Result, &toolResult); err != nil {
@@ -542,9 +654,21 @@ func (c *StreamableHTTPConnection) handleSSEResponse(
 	outcome *OperationOutcome,
 	requestID string,
 ) {
-	jsonrpcResp, signals, err := c.sseHandler.HandleSSEStream(ctx, resp.Body, requestID)
+	body := &countingReadCloser{ReadCloser: resp.Body}
+	stream := newSSEStream()
+	if c.config.ServerRequests != nil {
+		stream.answer = c.answerServerRequest
+	}
+	if c.config.ProgressTokens && outcome.Operation == OpToolsCall {
+		stream.progressToken = requestID
+	}
+	jsonrpcResp, err := c.sseHandler.readStream(ctx, stream, body, requestID)
+	outcome.BytesIn = body.n
+	if err != nil && c.config.ResumeAttempts > 0 {
+		jsonrpcResp, err = c.resumeStream(ctx, stream, requestID, outcome, err)
+	}
 
-	outcome.Stream = signals
+	outcome.Stream = stream.signals
 
 	if err != nil {
 		outcome.OK = false
@@ -567,6 +691,7 @@ func (c *StreamableHTTPConnection) handleSSEResponse(
 		outcome.Error = validationErr
 		return
 	}
+	recordIDEcho(outcome, jsonrpcResp.ID)
 
 	if jsonrpcErr := ExtractJSONRPCError(jsonrpcResp); jsonrpcErr != nil {
 		outcome.OK = false
@@ -578,6 +703,12 @@ func (c *StreamableHTTPConnection) handleSSEResponse(
 	outcome.OK = true
 	outcome.Result = jsonrpcResp.Result
 
+	if shapeErr := CheckResultShape(outcome.Operation, jsonrpcResp.Result); shapeErr != nil {
+		outcome.OK = false
+		outcome.Error = shapeErr
+		return
+	}
+
 	if outcome.Operation == OpToolsCall {
 		var toolResult ToolsCallResult
 		if err := json.Unmarshal(jsonrpcResp.Result, &toolResult); err != nil {
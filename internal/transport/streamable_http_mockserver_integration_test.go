@@ -100,3 +100,43 @@ func TestStreamableHTTPAdapter_WithMockServer(t *testing.T) {
 		t.Fatalf("unexpected tool content: %q", call.Content[0].Text)
 	}
 }
+
+func TestStreamableHTTPAdapter_WithStrictMockServer(t *testing.T) {
+	config := mockserver.DefaultConfig()
+	config.Strict = true
+	server := mockserver.New(config)
+	if err := server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn, err := NewStreamableHTTPAdapter().Connect(ctx, &TransportConfig{
+		Endpoint:             server.MCPURL(),
+		AllowPrivateNetworks: []string{"127.0.0.0/8"},
+		Timeouts: TimeoutConfig{
+			ConnectTimeout:     2 * time.Second,
+			RequestTimeout:     5 * time.Second,
+			StreamStallTimeout: 5 * time.Second,
+		},
+	})
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	defer conn.Close()
+
+	initOutcome, _ := conn.Initialize(ctx, nil)
+	if !initOutcome.OK || initOutcome.SessionID == "" {
+		t.Fatalf("expected a session from initialize, got ok=%v session=%q error=%v", initOutcome.OK, initOutcome.SessionID, initOutcome.Error)
+	}
+	if outcome, _ := conn.SendInitialized(ctx); !outcome.OK || outcome.HTTPStatus == nil || *outcome.HTTPStatus != 202 {
+		t.Fatalf("expected the initialized notification accepted with 202, got %+v", outcome)
+	}
+	if outcome, _ := conn.ToolsList(ctx, nil); !outcome.OK {
+		t.Fatalf("tools/list outcome not OK: %v", outcome.Error)
+	}
+	if outcome, _ := conn.Ping(ctx); !outcome.OK {
+		t.Fatalf("ping outcome not OK: %v", outcome.Error)
+	}
+}
@@ -0,0 +1,51 @@
+package transport
+
+import (
+	"context"
+
+	"github.com/bc-dunia/mcpdrill/internal/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startOperationSpan starts a client span for one JSON-RPC exchange with the
+// target. The span's context is also sent to the target as a traceparent
+// header, so server-side spans join the same trace.
+func startOperationSpan(ctx context.Context, endpoint string, opType OperationType, toolName string) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		attribute.String("rpc.system", "jsonrpc"),
+		attribute.String("rpc.method", string(opType)),
+		attribute.String("url.full", endpoint),
+		attribute.String("mcpdrill.transport", TransportIDStreamableHTTP),
+	}
+	if toolName != "" {
+		attrs = append(attrs, attribute.String("mcpdrill.tool_name", toolName))
+	}
+	return otel.GetGlobalTracer().StartSpan(ctx, "mcpdrill.transport "+string(opType),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// endOperationSpan records the outcome of an exchange on its span and ends it.
+func endOperationSpan(span trace.Span, outcome *OperationOutcome) {
+	if outcome.HTTPStatus != nil {
+		span.SetAttributes(attribute.Int("http.response.status_code", *outcome.HTTPStatus))
+	}
+	if outcome.SessionID != "" {
+		span.SetAttributes(attribute.String("mcpdrill.session_id", outcome.SessionID))
+	}
+	span.SetAttributes(
+		attribute.Int64("mcpdrill.bytes_out", outcome.BytesOut),
+		attribute.Int64("mcpdrill.bytes_in", outcome.BytesIn),
+	)
+	if !outcome.OK && outcome.Error != nil {
+		span.SetAttributes(
+			attribute.String("error.type", string(outcome.Error.Type)),
+			attribute.String("mcpdrill.error_code", string(outcome.Error.Code)),
+		)
+		span.SetStatus(codes.Error, outcome.Error.Message)
+	}
+	span.End()
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net"
 	"net/http"
@@ -1045,6 +1046,188 @@ func TestStreamableHTTPConnection(t *testing.T) {
 		}
 	})
 
+	t.Run("Backend ID header", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var req JSONRPCRequest
+			json.NewDecoder(r.Body).Decode(&req)
+
+			w.Header().Set("X-Backend-Id", "backend-2")
+			w.Header().Set(HeaderContentType, ContentTypeJSON)
+			json.NewEncoder(w).Encode(JSONRPCResponse{
+				JSONRPC: "2.0",
+				ID:      req.ID,
+				Result:  json.RawMessage(`{}`),
+			})
+		}))
+		defer server.Close()
+
+		adapter := NewStreamableHTTPAdapter()
+		config := &TransportConfig{
+			AllowPrivateNetworks: []string{"127.0.0.0/8"},
+			Endpoint:             server.URL,
+			Timeouts:             DefaultTimeoutConfig(),
+			BackendIDHeader:      "X-Backend-Id",
+		}
+
+		conn, _ := adapter.Connect(context.Background(), config)
+		defer conn.Close()
+
+		outcome, _ := conn.Ping(context.Background())
+		if outcome.BackendID != "backend-2" {
+			t.Errorf("expected backend id 'backend-2', got '%s'", outcome.BackendID)
+		}
+
+		config.BackendIDHeader = ""
+		untracked, _ := adapter.Connect(context.Background(), config)
+		defer untracked.Close()
+
+		outcome, _ = untracked.Ping(context.Background())
+		if outcome.BackendID != "" {
+			t.Errorf("expected no backend id without header config, got '%s'", outcome.BackendID)
+		}
+	})
+
+	t.Run("DNS lookup timing", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var req JSONRPCRequest
+			json.NewDecoder(r.Body).Decode(&req)
+
+			w.Header().Set(HeaderContentType, ContentTypeJSON)
+			json.NewEncoder(w).Encode(JSONRPCResponse{
+				JSONRPC: "2.0",
+				ID:      req.ID,
+				Result:  json.RawMessage(`{}`),
+			})
+		}))
+		defer server.Close()
+
+		adapter := NewStreamableHTTPAdapter()
+		config := &TransportConfig{
+			AllowPrivateNetworks: []string{"127.0.0.0/8", "::1/128"},
+			Endpoint:             strings.Replace(server.URL, "127.0.0.1", "localhost", 1),
+			Timeouts:             DefaultTimeoutConfig(),
+		}
+
+		conn, _ := adapter.Connect(context.Background(), config)
+		defer conn.Close()
+
+		first, _ := conn.Ping(context.Background())
+		if !first.OK {
+			t.Fatalf("expected OK outcome, got error %+v", first.Error)
+		}
+		if first.PhaseTiming == nil || !first.PhaseTiming.DNSLookup || first.PhaseTiming.ConnectionReused {
+			t.Fatalf("expected DNS lookup on a new connection, got %+v", first.PhaseTiming)
+		}
+
+		second, _ := conn.Ping(context.Background())
+		if second.PhaseTiming == nil || second.PhaseTiming.DNSLookup || !second.PhaseTiming.ConnectionReused {
+			t.Errorf("expected reused connection without DNS lookup, got %+v", second.PhaseTiming)
+		}
+	})
+
+	t.Run("Large result summarized", func(t *testing.T) {
+		payload := strings.Repeat("x", 4096)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var req JSONRPCRequest
+			json.NewDecoder(r.Body).Decode(&req)
+
+			result := `{"content":[{"type":"text","text":"` + payload + `"}]}`
+			if req.Method == "ping" {
+				result = `{}`
+			}
+			w.Header().Set(HeaderContentType, ContentTypeJSON)
+			json.NewEncoder(w).Encode(JSONRPCResponse{
+				JSONRPC: "2.0",
+				ID:      req.ID,
+				Result:  json.RawMessage(result),
+			})
+		}))
+		defer server.Close()
+
+		adapter := NewStreamableHTTPAdapter()
+		config := &TransportConfig{
+			AllowPrivateNetworks: []string{"127.0.0.0/8"},
+			Endpoint:             server.URL,
+			Timeouts:             DefaultTimeoutConfig(),
+			ResultCapture:        &ResultCaptureConfig{SummarizeAboveBytes: 1024},
+		}
+
+		conn, _ := adapter.Connect(context.Background(), config)
+		defer conn.Close()
+
+		outcome, _ := conn.ToolsCall(context.Background(), &ToolsCallParams{Name: "large_payload"})
+		if !outcome.OK {
+			t.Fatalf("expected OK outcome, got error %+v", outcome.Error)
+		}
+		if outcome.Result != nil {
+			t.Errorf("expected large result to be dropped, got %d bytes", len(outcome.Result))
+		}
+		if outcome.ResultSummary == nil || outcome.ResultSummary.SizeBytes <= 4096 {
+			t.Fatalf("expected result summary with full size, got %+v", outcome.ResultSummary)
+		}
+		if outcome.ResultSummary.Shape != "{content:[{text:s,type:s}]}" {
+			t.Errorf("unexpected shape %q", outcome.ResultSummary.Shape)
+		}
+
+		outcome, _ = conn.Ping(context.Background())
+		if outcome.ResultSummary != nil || string(outcome.Result) != "{}" {
+			t.Errorf("expected small result to be kept verbatim, got result %s summary %+v", outcome.Result, outcome.ResultSummary)
+		}
+	})
+
+	t.Run("Integer request ids", func(t *testing.T) {
+		echoAsString := false
+		var sentIDs []interface{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var req JSONRPCRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			sentIDs = append(sentIDs, req.ID)
+
+			id := req.ID
+			if echoAsString {
+				id = fmt.Sprintf("%v", req.ID)
+			}
+			w.Header().Set(HeaderContentType, ContentTypeJSON)
+			json.NewEncoder(w).Encode(JSONRPCResponse{
+				JSONRPC: "2.0",
+				ID:      id,
+				Result:  json.RawMessage(`{}`),
+			})
+		}))
+		defer server.Close()
+
+		adapter := NewStreamableHTTPAdapter()
+		config := &TransportConfig{
+			AllowPrivateNetworks: []string{"127.0.0.0/8"},
+			Endpoint:             server.URL,
+			Timeouts:             DefaultTimeoutConfig(),
+			RequestIDs:           &RequestIDConfig{Format: RequestIDFormatInteger},
+		}
+
+		conn, _ := adapter.Connect(context.Background(), config)
+		defer conn.Close()
+
+		outcome, _ := conn.Ping(context.Background())
+		if !outcome.OK {
+			t.Fatalf("expected OK outcome, got error %+v", outcome.Error)
+		}
+		if _, ok := sentIDs[0].(float64); !ok {
+			t.Errorf("expected integer id on the wire, got %T", sentIDs[0])
+		}
+		if outcome.RequestID == nil || outcome.RequestID.Kind != RequestIDKindInteger || outcome.RequestID.EchoTypeMismatch {
+			t.Errorf("expected integer id echoed with the same type, got %+v", outcome.RequestID)
+		}
+
+		echoAsString = true
+		outcome, _ = conn.Ping(context.Background())
+		if !outcome.OK {
+			t.Fatalf("expected string echo to still match, got error %+v", outcome.Error)
+		}
+		if outcome.RequestID == nil || !outcome.RequestID.EchoTypeMismatch {
+			t.Errorf("expected echo type mismatch, got %+v", outcome.RequestID)
+		}
+	})
+
 	t.Run("Custom headers", func(t *testing.T) {
 		var receivedAuth string
 
@@ -39,6 +39,7 @@ const (
 	ErrorTypeUnknown     ErrorType = "unknown"
 	ErrorTypeCancelled   ErrorType = "cancelled"
 	ErrorTypeStreamStall ErrorType = "stream_stall"
+	ErrorTypeAuth        ErrorType = "auth_error"
 )
 
 // ErrorCode represents specific error codes within an error type.
@@ -91,6 +92,9 @@ const (
 
 	// Cancelled
 	CodeCancelled ErrorCode = "CANCELLED"
+
+	// Auth errors
+	CodeAuthTokenFailed ErrorCode = "AUTH_TOKEN_FAILED"
 )
 
 // OperationError represents an error that occurred during an operation.
@@ -124,6 +128,32 @@ type StreamSignals struct {
 	// Event gap histogram buckets (inter-event delays)
 	// Bucket boundaries: 0-10ms, 10-50ms, 50-100ms, 100-500ms, 500-1000ms, 1000ms+
 	EventGapHistogram *EventGapHistogram `json:"event_gap_histogram,omitempty"`
+
+	// LastEventID is the last event ID the server sent, which a dropped
+	// stream is resumed from.
+	LastEventID string `json:"last_event_id,omitempty"`
+	// ResumeAttempts counts the times the stream dropped before the response
+	// arrived and was reopened; Resumed is set if the response arrived on a
+	// reopened stream.
+	ResumeAttempts int  `json:"resume_attempts,omitempty"`
+	Resumed        bool `json:"resumed,omitempty"`
+
+	// ServerMessages counts the requests and notifications the server sent
+	// on the stream, by method. ServerReplies counts the requests answered
+	// and ServerReplyErrors the answers the server did not accept.
+	ServerMessages    map[string]int `json:"server_messages,omitempty"`
+	ServerReplies     int            `json:"server_replies,omitempty"`
+	ServerReplyErrors int            `json:"server_reply_errors,omitempty"`
+
+	// ProgressCount counts the progress notifications received and
+	// TimeToFirstProgressMs is when the first arrived. With a progress token
+	// sent, ProgressTokenMismatches counts notifications carrying another
+	// token and ProgressInvalid those without a progress value or whose
+	// progress did not increase.
+	ProgressCount           int   `json:"progress_count,omitempty"`
+	TimeToFirstProgressMs   int64 `json:"time_to_first_progress_ms,omitempty"`
+	ProgressTokenMismatches int   `json:"progress_token_mismatches,omitempty"`
+	ProgressInvalid         int   `json:"progress_invalid,omitempty"`
 }
 
 // EventGapHistogram tracks the distribution of inter-event delays in SSE streams.
@@ -152,6 +182,8 @@ type OperationOutcome struct {
 	Operation     OperationType `json:"operation"`
 	OperationName string        `json:"operation_name,omitempty"`
 	ToolName      string        `json:"tool_name,omitempty"`
+	ResourceURI   string        `json:"resource_uri,omitempty"`
+	PromptName    string        `json:"prompt_name,omitempty"`
 	JSONRPCID     string        `json:"jsonrpc_id,omitempty"`
 
 	// Timing - End-to-end latency
@@ -180,10 +212,31 @@ type OperationOutcome struct {
 	// Session
 	SessionID string `json:"session_id,omitempty"`
 
+	// BackendID is the value of the configured backend ID response header
+	BackendID string `json:"backend_id,omitempty"`
+
+	// RetryAfterMs is the delay a 429 or 503 response asked for in its
+	// Retry-After header, 0 if it gave none.
+	RetryAfterMs int64 `json:"retry_after_ms,omitempty"`
+
 	// Outcome
 	OK     bool            `json:"ok"`
 	Error  *OperationError `json:"error,omitempty"`
 	Result json.RawMessage `json:"result,omitempty"`
+
+	// ResultSummary replaces Result when the result exceeded the configured
+	// summarization threshold.
+	ResultSummary *ResultSummary `json:"result_summary,omitempty"`
+
+	// RequestID describes the JSON-RPC id variant sent, when id variation
+	// is configured.
+	RequestID *RequestIDInfo `json:"request_id,omitempty"`
+	// Cancellation describes the cancellation of the call, when it was
+	// picked for cancellation.
+	Cancellation *CancellationInfo `json:"cancellation,omitempty"`
+	// Chaos describes the network faults injected into the operation, nil
+	// if there were none.
+	Chaos *ChaosInfo `json:"chaos,omitempty"`
 }
 
 // PhaseTiming contains detailed phase timing decomposition for HTTP requests.
@@ -193,6 +246,20 @@ type PhaseTiming struct {
 	// DNSMs is the time spent on DNS lookup (0 if connection reused or IP literal)
 	DNSMs int64 `json:"dns_ms"`
 
+	// DNSUs is the DNS lookup time in microseconds. Lookups answered from a
+	// local cache usually take well under a millisecond.
+	DNSUs int64 `json:"dns_us,omitempty"`
+
+	// DNSLookup indicates a DNS lookup was performed for this request
+	DNSLookup bool `json:"dns_lookup,omitempty"`
+
+	// DNSCoalesced indicates the lookup was shared with a concurrent lookup
+	// for the same host instead of hitting the resolver
+	DNSCoalesced bool `json:"dns_coalesced,omitempty"`
+
+	// DNSFailed indicates the DNS lookup returned an error
+	DNSFailed bool `json:"dns_failed,omitempty"`
+
 	// TCPConnectMs is the time spent establishing TCP connection (0 if reused)
 	TCPConnectMs int64 `json:"tcp_connect_ms"`
 
@@ -275,6 +342,42 @@ type TransportConfig struct {
 
 	// LastEventID for SSE resumption
 	LastEventID string
+
+	// ResumeAttempts is how many times a response stream that drops before
+	// the response arrives is reopened with a GET that carries the session
+	// ID and the last event ID seen. Zero disables resumption.
+	ResumeAttempts int
+
+	// ResumeBackoff is the wait before each resumption attempt.
+	ResumeBackoff time.Duration
+
+	// ServerRequests sets the answers to requests the server sends on a
+	// response stream, such as sampling/createMessage. Nil leaves them
+	// unanswered; they are counted either way.
+	ServerRequests *ServerRequestsConfig
+
+	// ProgressTokens sends a progress token with every tools/call, so the
+	// server reports progress and the notifications can be checked.
+	ProgressTokens bool
+
+	// BackendIDHeader is the response header that identifies the backend
+	// instance serving a request. Empty disables backend tracking.
+	BackendIDHeader string
+
+	// ResultCapture controls summarization of large results. Nil keeps all
+	// results verbatim.
+	ResultCapture *ResultCaptureConfig
+
+	// RequestIDs varies the JSON-RPC ids sent to the server. Nil sends
+	// "req_N" string ids.
+	RequestIDs *RequestIDConfig
+
+	// TokenSource, if set, supplies the bearer token for the Authorization
+	// header of every request, replacing any configured Authorization header.
+	TokenSource TokenSource
+
+	// Chaos, if set, injects network faults into every request.
+	Chaos *ChaosConfig
 }
 
 // JSONRPCRequest represents a JSON-RPC 2.0 request.
@@ -372,6 +475,7 @@ type ToolsListResult struct {
 type ToolsCallParams struct {
 	Name      string                 `json:"name"`
 	Arguments map[string]interface{} `json:"arguments,omitempty"`
+	Meta      map[string]interface{} `json:"_meta,omitempty"`
 }
 
 // ToolContent represents content returned by a tool.
@@ -1,6 +1,8 @@
 // Package types provides shared type definitions used across multiple packages.
 package types
 
+import "encoding/json"
+
 // RedirectPolicyConfig holds redirect policy configuration for assignments.
 type RedirectPolicyConfig struct {
 	Mode         string   `json:"mode"`
@@ -12,6 +14,24 @@ type RedirectPolicyConfig struct {
 type AuthConfig struct {
 	Type   string   `json:"type"`
 	Tokens []string `json:"tokens,omitempty"`
+	// OAuth2 is set for the oauth2_client_credentials type.
+	OAuth2 *OAuth2Config `json:"oauth2,omitempty"`
+}
+
+// OAuth2Config configures the OAuth2 client credentials grant workers use
+// to obtain target bearer tokens. The client secret is a secret reference
+// resolved on the worker.
+type OAuth2Config struct {
+	TokenURL        string   `json:"token_url"`
+	ClientID        string   `json:"client_id"`
+	ClientSecretRef string   `json:"client_secret_ref"`
+	Scopes          []string `json:"scopes,omitempty"`
+	Audience        string   `json:"audience,omitempty"`
+	// ClientAuth is "basic" (the default) to send the client credentials
+	// in an Authorization header, or "post" to send them in the form body.
+	ClientAuth string `json:"client_auth,omitempty"`
+	// RefreshBeforeMs is how long before expiry a token is refreshed.
+	RefreshBeforeMs int64 `json:"refresh_before_ms,omitempty"`
 }
 
 // TargetConfig contains the target configuration for an assignment.
@@ -23,11 +43,149 @@ type TargetConfig struct {
 	Auth                  *AuthConfig           `json:"auth,omitempty"`
 	ProtocolVersion       string                `json:"protocol_version,omitempty"`
 	ProtocolVersionPolicy string                `json:"protocol_version_policy,omitempty"`
+	// BackendIDHeader names the response header that identifies the backend
+	// instance serving a request. Empty disables backend tracking.
+	BackendIDHeader string `json:"backend_id_header,omitempty"`
+	// StreamResumption, if set, resumes response streams that drop before
+	// the response arrives.
+	StreamResumption *StreamResumptionConfig `json:"stream_resumption,omitempty"`
+	// ServerRequests, if set, answers the requests the server sends on
+	// response streams.
+	ServerRequests *ServerRequestsConfig `json:"server_requests,omitempty"`
+	// ProgressTokens sends a progress token with every tools/call.
+	ProgressTokens bool `json:"progress_tokens,omitempty"`
+}
+
+// ServerRequestsConfig holds the canned results sent back to server-to-client
+// requests, by method.
+type ServerRequestsConfig struct {
+	Responses map[string]json.RawMessage `json:"responses,omitempty"`
+}
+
+// StreamResumptionConfig sets how dropped SSE response streams are resumed
+// from their last event ID.
+type StreamResumptionConfig struct {
+	MaxAttempts int   `json:"max_attempts"`
+	BackoffMs   int64 `json:"backoff_ms,omitempty"`
 }
 
 // WorkloadConfig contains the workload configuration for an assignment.
 type WorkloadConfig struct {
-	OpMix []OpMixEntry `json:"op_mix"`
+	OpMix         []OpMixEntry         `json:"op_mix"`
+	ResultCapture *ResultCaptureConfig `json:"result_capture,omitempty"`
+	RequestIDs    *RequestIDConfig     `json:"request_ids,omitempty"`
+	// InFlightPerVU is set for generator groups that configure their own
+	// load shape, and ThinkTime by the workload or the group. Zero values
+	// leave the worker defaults in place.
+	InFlightPerVU int              `json:"in_flight_per_vu,omitempty"`
+	ThinkTime     *ThinkTimeConfig `json:"think_time,omitempty"`
+	// ToolThinkTime replaces ThinkTime after calls to the tools it names.
+	ToolThinkTime map[string]ThinkTimeConfig `json:"tool_think_time,omitempty"`
+	// Arrival switches the assignment to an open model: operations start at
+	// Arrival.RateRPS regardless of how many are in flight. Nil runs VUs in
+	// a closed loop.
+	Arrival *ArrivalConfig `json:"arrival,omitempty"`
+	// DataFeeds are the CSV and JSONL feeds that ${feed.<name>.<column>}
+	// templates read from, with their data inline.
+	DataFeeds []DataFeedConfig `json:"data_feeds,omitempty"`
+	// AutoDiscoverTools, if set, makes tools/call entries without a tool
+	// name call the tools the target lists, with arguments generated from
+	// their input schemas.
+	AutoDiscoverTools *AutoDiscoverToolsConfig `json:"auto_discover_tools,omitempty"`
+	// Fuzz, if set, mutates a share of tools/call arguments into malformed
+	// inputs.
+	Fuzz *FuzzConfig `json:"fuzz,omitempty"`
+	// Cancellation, if set, cancels a share of tools/call requests in
+	// flight with notifications/cancelled.
+	Cancellation *CancellationConfig `json:"cancellation,omitempty"`
+	// Throttle, if set, makes VUs slow down after rate limited responses.
+	Throttle *ThrottleConfig `json:"throttle,omitempty"`
+	// Retry, if set, retries operations that failed with retryable errors.
+	Retry *RetryConfig `json:"retry,omitempty"`
+	// Workflows are chains of operations sampled alongside OpMix, each by
+	// its weight.
+	Workflows []WorkflowConfig `json:"workflows,omitempty"`
+}
+
+// AutoDiscoverToolsConfig selects the discovered tools a worker calls.
+type AutoDiscoverToolsConfig struct {
+	// IncludeDestructive also calls tools annotated as destructive.
+	IncludeDestructive bool `json:"include_destructive,omitempty"`
+	// ExcludeTools are never called.
+	ExcludeTools []string `json:"exclude_tools,omitempty"`
+}
+
+// CancellationConfig selects the tools/call requests cancelled in flight.
+type CancellationConfig struct {
+	Rate       float64 `json:"rate"`
+	MaxDelayMs int64   `json:"max_delay_ms,omitempty"`
+	GraceMs    int64   `json:"grace_ms,omitempty"`
+}
+
+// ThrottleConfig sets how VUs react to rate limited responses: ignore,
+// honor_retry_after or exponential_backoff.
+type ThrottleConfig struct {
+	Policy        string `json:"policy"`
+	BaseBackoffMs int64  `json:"base_backoff_ms,omitempty"`
+	MaxBackoffMs  int64  `json:"max_backoff_ms,omitempty"`
+}
+
+// RetryConfig sets how many times and after which errors operations are
+// retried. RetryOn holds error classes: connect, http_5xx, stream_stall,
+// timeout and rate_limited.
+type RetryConfig struct {
+	MaxRetries   int      `json:"max_retries"`
+	BackoffMs    int64    `json:"backoff_ms,omitempty"`
+	MaxBackoffMs int64    `json:"max_backoff_ms,omitempty"`
+	RetryOn      []string `json:"retry_on,omitempty"`
+}
+
+// FuzzConfig selects how often and how tools/call arguments are mutated.
+type FuzzConfig struct {
+	Ratio                float64  `json:"ratio"`
+	Mutations            []string `json:"mutations,omitempty"`
+	OversizedStringBytes int      `json:"oversized_string_bytes,omitempty"`
+	NestingDepth         int      `json:"nesting_depth,omitempty"`
+}
+
+// DataFeedConfig is a data feed shipped to workers.
+type DataFeedConfig struct {
+	Name         string `json:"name"`
+	Format       string `json:"format"`
+	Distribution string `json:"distribution,omitempty"`
+	Data         string `json:"data"`
+}
+
+// ArrivalConfig is an assignment's share of a stage's arrival rate.
+type ArrivalConfig struct {
+	RateRPS     float64 `json:"rate_rps"`
+	Schedule    string  `json:"schedule,omitempty"`
+	MaxInFlight int     `json:"max_in_flight,omitempty"`
+}
+
+// ThinkTimeConfig is the pause between a VU's operations. Mode is none,
+// fixed, jitter, uniform or exponential.
+type ThinkTimeConfig struct {
+	Mode     string `json:"mode"`
+	BaseMs   int64  `json:"base_ms"`
+	JitterMs int64  `json:"jitter_ms"`
+	MaxMs    int64  `json:"max_ms,omitempty"`
+}
+
+// ResultCaptureConfig controls summarization of large operation results.
+// Nil, or a negative threshold, leaves the worker defaults in place; a
+// threshold of 0 keeps all results verbatim.
+type ResultCaptureConfig struct {
+	SummarizeAboveBytes int `json:"summarize_above_bytes"`
+	FingerprintMaxDepth int `json:"fingerprint_max_depth,omitempty"`
+}
+
+// RequestIDConfig varies the JSON-RPC ids workers send to the target.
+// Nil sends the default "req_N" string ids.
+type RequestIDConfig struct {
+	Format         string `json:"format,omitempty"`
+	LongLength     int    `json:"long_length,omitempty"`
+	DuplicateEvery int    `json:"duplicate_every,omitempty"`
 }
 
 // OpMixEntry represents a single operation in the mix.
@@ -38,6 +196,49 @@ type OpMixEntry struct {
 	Arguments  map[string]interface{} `json:"arguments,omitempty"`
 	URI        string                 `json:"uri,omitempty"`
 	PromptName string                 `json:"prompt_name,omitempty"`
+	Checks     []CheckConfig          `json:"checks,omitempty"`
+}
+
+// WorkflowConfig is a chain of operations a VU runs in order on one
+// session.
+type WorkflowConfig struct {
+	Name   string               `json:"name"`
+	Weight int                  `json:"weight"`
+	Steps  []WorkflowStepConfig `json:"steps"`
+}
+
+// WorkflowStepConfig is one operation of a workflow. Extract maps variable
+// names to JSONPaths into the step's result; later steps reference the
+// values as ${vars.<name>} in their tool or prompt name, arguments and URI.
+type WorkflowStepConfig struct {
+	Name       string                 `json:"name"`
+	Operation  string                 `json:"operation"`
+	ToolName   string                 `json:"tool_name,omitempty"`
+	Arguments  map[string]interface{} `json:"arguments,omitempty"`
+	URI        string                 `json:"uri,omitempty"`
+	PromptName string                 `json:"prompt_name,omitempty"`
+	Checks     []CheckConfig          `json:"checks,omitempty"`
+	Extract    map[string]string      `json:"extract,omitempty"`
+}
+
+// CheckConfig is an assertion on an operation's response. Exactly one of
+// JSONPath, Regex, IsError, MaxLatencyMs or the result byte bounds is set.
+type CheckConfig struct {
+	Name string `json:"name,omitempty"`
+
+	// JSONPath selects values from the result. With neither Equals nor
+	// Matches the check passes when the path selects anything.
+	JSONPath string          `json:"jsonpath,omitempty"`
+	Equals   json.RawMessage `json:"equals,omitempty"`
+	Matches  string          `json:"matches,omitempty"`
+
+	// Regex must match the text content of the result.
+	Regex string `json:"regex,omitempty"`
+
+	IsError        *bool  `json:"is_error,omitempty"`
+	MaxLatencyMs   int64  `json:"max_latency_ms,omitempty"`
+	MinResultBytes *int64 `json:"min_result_bytes,omitempty"`
+	MaxResultBytes *int64 `json:"max_result_bytes,omitempty"`
 }
 
 // SessionPolicyConfig contains session policy for an assignment.
@@ -46,6 +247,10 @@ type SessionPolicyConfig struct {
 	PoolSize  int    `json:"pool_size,omitempty"`
 	TTLMs     int64  `json:"ttl_ms,omitempty"`
 	MaxIdleMs int64  `json:"max_idle_ms,omitempty"`
+	// ChurnIntervalMs and ChurnIntervalOps set how often churn mode replaces
+	// a VU's session; the operation count wins if both are set.
+	ChurnIntervalMs  int64 `json:"churn_interval_ms,omitempty"`
+	ChurnIntervalOps int64 `json:"churn_interval_ops,omitempty"`
 }
 
 // GetHeadersWithAuth returns the target headers with auth token injected if configured.
@@ -62,6 +267,36 @@ func (t *TargetConfig) GetHeadersWithAuth() map[string]string {
 	return headers
 }
 
+// ShadowConfig is the shadow target of a run, such as a new build of the
+// server under test, that operations are duplicated to. Its answers are
+// compared with the target's but never count toward stop conditions.
+type ShadowConfig struct {
+	Target TargetConfig `json:"target"`
+	// StableTools are the tools whose results do not change between calls,
+	// so a different result from the shadow is a regression.
+	StableTools []string `json:"stable_tools,omitempty"`
+}
+
+// ChaosConfig is the network faults a worker injects into its requests to
+// the target: added latency and jitter, a share of connections reset
+// before the response, and a bandwidth cap. Zero values inject nothing.
+type ChaosConfig struct {
+	LatencyMs            int64   `json:"latency_ms,omitempty"`
+	JitterMs             int64   `json:"jitter_ms,omitempty"`
+	ResetRate            float64 `json:"reset_rate,omitempty"`
+	BandwidthBytesPerSec int64   `json:"bandwidth_bytes_per_sec,omitempty"`
+}
+
+// ConformanceConfig is the options of a conformance run's protocol checks.
+// Zero values take the checks' defaults.
+type ConformanceConfig struct {
+	Tool             string                 `json:"tool,omitempty"`
+	ToolArguments    map[string]interface{} `json:"tool_arguments,omitempty"`
+	ProtocolVersion  string                 `json:"protocol_version,omitempty"`
+	CancelAfterMs    int64                  `json:"cancel_after_ms,omitempty"`
+	RequestTimeoutMs int64                  `json:"request_timeout_ms,omitempty"`
+}
+
 // WorkerAssignment represents a work assignment for a worker.
 type WorkerAssignment struct {
 	RunID         string              `json:"run_id"`
@@ -75,4 +310,36 @@ type WorkerAssignment struct {
 	Target        TargetConfig        `json:"target"`
 	Workload      WorkloadConfig      `json:"workload"`
 	SessionPolicy SessionPolicyConfig `json:"session_policy"`
+	// MaxTotalOps is this assignment's share of the run's total operation budget.
+	// Zero means no budget is enforced by the worker.
+	MaxTotalOps int64 `json:"max_total_ops,omitempty"`
+	// WarmupMs is the time from the start of the assignment during which
+	// operations are flagged as warmup. Zero means no warmup.
+	WarmupMs int64 `json:"warmup_ms,omitempty"`
+	// Chaos, if set, is the network faults the stage injects between the
+	// worker and the target.
+	Chaos *ChaosConfig `json:"chaos,omitempty"`
+	// WorkloadRevision orders live op mix updates; 0 is the configured mix.
+	WorkloadRevision int64 `json:"workload_revision,omitempty"`
+	// GeneratorGroup names the generator group this assignment belongs to.
+	// Empty when the run defines no groups.
+	GeneratorGroup string `json:"generator_group,omitempty"`
+	// TargetName names the target of a multi-target run this assignment's
+	// VUs send to; Target is already resolved for it. Empty with a single
+	// target.
+	TargetName string `json:"target_name,omitempty"`
+	// Shadow, if set, is the run's shadow target: the assignment's VUs send
+	// every operation there as well.
+	Shadow *ShadowConfig `json:"shadow,omitempty"`
+	// Conformance, if set, makes the worker run the protocol conformance
+	// checks against Target and report them instead of starting VUs.
+	Conformance *ConformanceConfig `json:"conformance,omitempty"`
+	// WorkloadUpdate marks a message that replaces the workload of the already
+	// running lease LeaseID instead of starting new work. Only Workload and
+	// WorkloadRevision are meaningful in an update.
+	WorkloadUpdate bool `json:"workload_update,omitempty"`
+	// TraceContext carries the W3C trace context (traceparent, tracestate) of
+	// the control plane's dispatch span, so the worker's spans join the run's
+	// trace. Empty when tracing is disabled.
+	TraceContext map[string]string `json:"trace_context,omitempty"`
 }
@@ -190,3 +190,21 @@ type PromptsGetResult struct {
 	Description string          `json:"description,omitempty"`
 	Messages    []PromptMessage `json:"messages"`
 }
+
+// TargetProfile is what a target advertised when a worker probed it during
+// preflight: the initialize result and everything tools/list,
+// resources/list and prompts/list returned, across all pages.
+type TargetProfile struct {
+	WorkerID        string                 `json:"worker_id"`
+	ProfiledAtMs    int64                  `json:"profiled_at_ms"`
+	ProtocolVersion string                 `json:"protocol_version"`
+	Capabilities    map[string]interface{} `json:"capabilities,omitempty"`
+	ServerInfo      ServerInfo             `json:"server_info"`
+	Instructions    string                 `json:"instructions,omitempty"`
+	Tools           []Tool                 `json:"tools"`
+	Resources       []Resource             `json:"resources"`
+	Prompts         []Prompt               `json:"prompts"`
+	// ListErrors maps each list method that failed, such as
+	// "resources/list" on a target without resources, to its error.
+	ListErrors map[string]string `json:"list_errors,omitempty"`
+}
@@ -7,27 +7,171 @@ type StreamInfo struct {
 	EndedNormally   bool  `json:"ended_normally"`
 	Stalled         bool  `json:"stalled"`
 	StallDurationMs int64 `json:"stall_duration_ms"`
+	// ResumeAttempts counts the times the stream dropped and was reopened
+	// from its last event ID; Resumed is set if the response then arrived.
+	ResumeAttempts int  `json:"resume_attempts,omitempty"`
+	Resumed        bool `json:"resumed,omitempty"`
+	// ServerMessages counts the requests and notifications the server sent
+	// on the stream, by method; ServerReplies and ServerReplyErrors count
+	// the answers to its requests that were accepted and refused.
+	ServerMessages    map[string]int `json:"server_messages,omitempty"`
+	ServerReplies     int            `json:"server_replies,omitempty"`
+	ServerReplyErrors int            `json:"server_reply_errors,omitempty"`
+	// TimeToFirstEventMs and MaxEventGapMs time the stream's events; the
+	// longest gap shows stalls too short to hit the stall timeout.
+	TimeToFirstEventMs int64 `json:"time_to_first_event_ms,omitempty"`
+	MaxEventGapMs      int64 `json:"max_event_gap_ms,omitempty"`
+	// ProgressCount counts progress notifications, TimeToFirstProgressMs is
+	// when the first arrived, and ProgressTokenMismatches and
+	// ProgressInvalid count the ones with a wrong token or progress value.
+	ProgressCount           int   `json:"progress_count,omitempty"`
+	TimeToFirstProgressMs   int64 `json:"time_to_first_progress_ms,omitempty"`
+	ProgressTokenMismatches int   `json:"progress_token_mismatches,omitempty"`
+	ProgressInvalid         int   `json:"progress_invalid,omitempty"`
+}
+
+// ConnectionInfo describes how an operation obtained its HTTP connection,
+// including the DNS lookup performed for new connections.
+type ConnectionInfo struct {
+	Reused       bool  `json:"reused"`
+	DNSLookup    bool  `json:"dns_lookup,omitempty"`
+	DNSUs        int64 `json:"dns_us,omitempty"`
+	DNSCoalesced bool  `json:"dns_coalesced,omitempty"`
+	DNSFailed    bool  `json:"dns_failed,omitempty"`
+}
+
+// RequestIDInfo describes the JSON-RPC id variant sent with an operation
+// and whether the server echoed it back with the same JSON type.
+type RequestIDInfo struct {
+	Kind             string `json:"kind"`
+	Duplicate        bool   `json:"duplicate,omitempty"`
+	EchoTypeMismatch bool   `json:"echo_type_mismatch,omitempty"`
+}
+
+// ResultSummary describes an operation result that was too large to ship
+// verbatim: its size, content hash and a structural fingerprint.
+type ResultSummary struct {
+	SizeBytes   int64  `json:"size_bytes"`
+	SHA256      string `json:"sha256"`
+	Shape       string `json:"shape"`
+	Fingerprint string `json:"fingerprint"`
 }
 
 // OperationOutcome represents a single operation result for telemetry.
 type OperationOutcome struct {
-	OpID        string      `json:"op_id"`
-	Operation   string      `json:"operation"`
-	ToolName    string      `json:"tool_name,omitempty"`
-	LatencyMs   int         `json:"latency_ms"`
-	OK          bool        `json:"ok"`
-	ErrorType   string      `json:"error_type,omitempty"`
-	ErrorCode   string      `json:"error_code,omitempty"`
-	HTTPStatus  int         `json:"http_status,omitempty"`
-	TimestampMs int64       `json:"ts_ms"`
-	Stream      *StreamInfo `json:"stream,omitempty"`
-	WorkerID    string      `json:"worker_id,omitempty"`
-	ExecutionID string      `json:"execution_id,omitempty"`
-	Stage       string      `json:"stage,omitempty"`
-	StageID     string      `json:"stage_id,omitempty"`
-	VUID        string      `json:"vu_id,omitempty"`
-	SessionID   string      `json:"session_id,omitempty"`
-	TokenIndex  *int        `json:"token_index,omitempty"`
+	OpID           string            `json:"op_id"`
+	Operation      string            `json:"operation"`
+	ToolName       string            `json:"tool_name,omitempty"`
+	ResourceURI    string            `json:"resource_uri,omitempty"`
+	PromptName     string            `json:"prompt_name,omitempty"`
+	LatencyMs      int               `json:"latency_ms"`
+	OK             bool              `json:"ok"`
+	ErrorType      string            `json:"error_type,omitempty"`
+	ErrorCode      string            `json:"error_code,omitempty"`
+	HTTPStatus     int               `json:"http_status,omitempty"`
+	BytesIn        int64             `json:"bytes_in,omitempty"`
+	BytesOut       int64             `json:"bytes_out,omitempty"`
+	TimestampMs    int64             `json:"ts_ms"`
+	Stream         *StreamInfo       `json:"stream,omitempty"`
+	Connection     *ConnectionInfo   `json:"connection,omitempty"`
+	WorkerID       string            `json:"worker_id,omitempty"`
+	ExecutionID    string            `json:"execution_id,omitempty"`
+	Stage          string            `json:"stage,omitempty"`
+	StageID        string            `json:"stage_id,omitempty"`
+	VUID           string            `json:"vu_id,omitempty"`
+	SessionID      string            `json:"session_id,omitempty"`
+	BackendID      string            `json:"backend_id,omitempty"`
+	TokenIndex     *int              `json:"token_index,omitempty"`
+	ResultSummary  *ResultSummary    `json:"result_summary,omitempty"`
+	GeneratorGroup string            `json:"generator_group,omitempty"`
+	Target         string            `json:"target,omitempty"`
+	RequestID      *RequestIDInfo    `json:"request_id,omitempty"`
+	Checks         []CheckResult     `json:"checks,omitempty"`
+	FuzzMutation   string            `json:"fuzz_mutation,omitempty"`
+	Cancellation   *CancellationInfo `json:"cancellation,omitempty"`
+	RetryAfterMs   int64             `json:"retry_after_ms,omitempty"`
+	ThrottleWaitMs int64             `json:"throttle_wait_ms,omitempty"`
+	// ThinkTimeMs is how long the VU paused after starting the operation
+	// before it started its next one.
+	ThinkTimeMs int64 `json:"think_time_ms,omitempty"`
+	// Attempt is the index of a retried operation's attempt, 0 for the
+	// first.
+	Attempt int `json:"attempt,omitempty"`
+	// Warmup is set for operations that started during the stage's warmup.
+	Warmup bool `json:"warmup,omitempty"`
+	// Workflow is set for operations run as a step of a workflow.
+	Workflow *WorkflowStepInfo `json:"workflow,omitempty"`
+	// Shadow is how the run's shadow target answered the same call, nil
+	// without a shadow.
+	Shadow *ShadowOutcome `json:"shadow,omitempty"`
+	// Chaos is the network faults injected into the operation, nil if
+	// there were none.
+	Chaos *ChaosInfo `json:"chaos,omitempty"`
+
+	// ErrorMessage is the text of a failed operation's error, or of what a
+	// tool returned with isError, cut to MaxErrorMessageBytes.
+	ErrorMessage     string `json:"error_message,omitempty"`
+	JSONRPCErrorCode *int   `json:"jsonrpc_error_code,omitempty"`
+}
+
+// Statuses a workflow run ends with, set on the step that ended it.
+const (
+	WorkflowCompleted = "completed"
+	// WorkflowFailed is a run whose step failed after any retries.
+	WorkflowFailed = "failed"
+	// WorkflowExtractFailed is a run whose step succeeded without yielding
+	// a variable later steps need.
+	WorkflowExtractFailed = "extract_failed"
+)
+
+// WorkflowStepInfo places an operation within a workflow run. Status is set
+// on the step that ended the run, empty on the others.
+type WorkflowStepInfo struct {
+	Name   string `json:"name"`
+	Step   string `json:"step"`
+	Index  int    `json:"index"`
+	Status string `json:"status,omitempty"`
+}
+
+// ShadowOutcome is the shadow target's answer to an operation.
+type ShadowOutcome struct {
+	LatencyMs int    `json:"latency_ms"`
+	OK        bool   `json:"ok"`
+	ErrorType string `json:"error_type,omitempty"`
+	// ResultMatch reports whether the shadow returned the same result as
+	// the target. It is set only for stable tools both answered without
+	// error.
+	ResultMatch *bool `json:"result_match,omitempty"`
+}
+
+// MaxErrorMessageBytes bounds OperationOutcome.ErrorMessage, so verbose
+// errors do not bloat telemetry batches.
+const MaxErrorMessageBytes = 512
+
+// CancellationInfo describes a tools/call picked for cancellation in
+// flight: when it was due, whether notifications/cancelled went out before
+// the response, and whether the server then stopped.
+type CancellationInfo struct {
+	AfterMs int64 `json:"after_ms"`
+	Sent    bool  `json:"sent"`
+	Honored bool  `json:"honored"`
+}
+
+// ChaosInfo describes the network faults injected into an operation: the
+// latency added, whether its connection was reset, and the time spent
+// waiting on the bandwidth cap.
+type ChaosInfo struct {
+	DelayMs     int64 `json:"delay_ms,omitempty"`
+	Reset       bool  `json:"reset,omitempty"`
+	ThrottledMs int64 `json:"throttled_ms,omitempty"`
+}
+
+// CheckResult is the outcome of one response check. Failed checks do not
+// make the operation fail; they are reported on their own.
+type CheckResult struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message,omitempty"`
 }
 
 // ErrorResponse represents a standard API error response.
@@ -36,3 +180,44 @@ type ErrorResponse struct {
 	ErrorCode    string `json:"error_code"`
 	ErrorMessage string `json:"error_message"`
 }
+
+// OperationSummary condenses the operations a worker ran within one second
+// that share an operation, tool, stage, generator group, target, fuzz
+// mutation, attempt index, warmup flag and workflow step.
+// Workers ship summaries instead of every OperationOutcome to cut telemetry
+// volume.
+type OperationSummary struct {
+	BucketMs       int64          `json:"bucket_ms"`
+	Operation      string         `json:"operation"`
+	ToolName       string         `json:"tool_name,omitempty"`
+	WorkerID       string         `json:"worker_id,omitempty"`
+	ExecutionID    string         `json:"execution_id,omitempty"`
+	Stage          string         `json:"stage,omitempty"`
+	StageID        string         `json:"stage_id,omitempty"`
+	GeneratorGroup string         `json:"generator_group,omitempty"`
+	Target         string         `json:"target,omitempty"`
+	FuzzMutation   string         `json:"fuzz_mutation,omitempty"`
+	Attempt        int            `json:"attempt,omitempty"`
+	Warmup         bool           `json:"warmup,omitempty"`
+	Count          int            `json:"count"`
+	Errors         int            `json:"errors,omitempty"`
+	ErrorTypes     map[string]int `json:"error_types,omitempty"`
+	// Latency holds [latency_ms, count] pairs in increasing order of
+	// latency. Latencies are rounded down to histogram buckets no wider
+	// than 0.1% of their value, and the counts add up to Count.
+	Latency [][2]int `json:"latency"`
+	// Workflow is set for operations run as a step of a workflow.
+	Workflow *WorkflowStepSummary `json:"workflow,omitempty"`
+}
+
+// WorkflowStepSummary places a summary's operations within a workflow and
+// counts the workflow runs that ended with them, by status. Failed runs
+// ended on failed operations, the others on successful ones.
+type WorkflowStepSummary struct {
+	Name          string `json:"name"`
+	Step          string `json:"step"`
+	Index         int    `json:"index"`
+	Completed     int    `json:"completed,omitempty"`
+	Failed        int    `json:"failed,omitempty"`
+	ExtractFailed int    `json:"extract_failed,omitempty"`
+}
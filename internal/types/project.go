@@ -0,0 +1,18 @@
+package types
+
+import "regexp"
+
+// DefaultProject is the project of runs, scenarios and keys created without
+// one, and of everything created before projects existed.
+const DefaultProject = "default"
+
+// ProjectHeader selects the project of an API request.
+const ProjectHeader = "X-Project"
+
+var projectNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// ValidProjectName reports whether name is a valid project name: 1-63
+// lowercase letters, digits or '-', starting with a letter or digit.
+func ValidProjectName(name string) bool {
+	return projectNamePattern.MatchString(name)
+}
@@ -1,10 +1,28 @@
 package types
 
+import "regexp"
+
 // HostInfo contains information about a worker's host.
 type HostInfo struct {
 	Hostname string `json:"hostname"`
 	IPAddr   string `json:"ip_addr"`
 	Platform string `json:"platform"`
+	// Labels describe where the worker runs, such as its region, zone or
+	// network tier. Run configs place VUs on workers by them.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+var (
+	labelKeyPattern   = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,62}$`)
+	labelValuePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,62}$`)
+)
+
+// ValidWorkerLabel reports whether key and value make a valid worker label:
+// a key of 1-63 lowercase letters, digits, '_', '.' or '-', and a value of
+// 1-63 letters, digits, '_', '.' or '-', both starting with a letter or
+// digit.
+func ValidWorkerLabel(key, value string) bool {
+	return labelKeyPattern.MatchString(key) && labelValuePattern.MatchString(value)
 }
 
 // WorkerCapacity describes the capacity limits of a worker.
@@ -22,4 +40,87 @@ type WorkerHealth struct {
 	ActiveSessions int     `json:"active_sessions"`
 	InFlightOps    int     `json:"in_flight_ops"`
 	QueueDepth     int     `json:"queue_depth"`
+
+	// MemPercent is the share of the host's memory in use, and OpenFDs and
+	// FDLimit the worker's open file descriptors and their soft limit. They
+	// are 0 where the worker cannot read them.
+	MemPercent float64 `json:"mem_percent,omitempty"`
+	OpenFDs    int     `json:"open_fds,omitempty"`
+	FDLimit    int     `json:"fd_limit,omitempty"`
+	// ShedRate is the share of its load, from 0 to 1, the worker sheds
+	// because it is short of CPU, memory or file descriptors. The control
+	// plane lowers the worker's capacity by the same share.
+	ShedRate float64 `json:"shed_rate,omitempty"`
+}
+
+// Load generator warning kinds.
+const (
+	// GeneratorWarningThrottle is raised when a worker starts shedding
+	// load or changes how much it sheds.
+	GeneratorWarningThrottle = "throttle"
+	// GeneratorWarningThrottleEnded is raised when a worker stops shedding.
+	GeneratorWarningThrottleEnded = "throttle_ended"
+)
+
+// LoadGeneratorWarning reports a problem with a worker's own health that
+// affects the load it generates, so results can be read with it in mind.
+type LoadGeneratorWarning struct {
+	WorkerID    string `json:"worker_id"`
+	TimestampMs int64  `json:"timestamp_ms"`
+	Kind        string `json:"kind"`
+	// Resource is the resource under the most pressure: "cpu", "memory"
+	// or "fds". Usage and Limit are its use and the throttle limit, both
+	// in percent.
+	Resource string  `json:"resource,omitempty"`
+	Usage    float64 `json:"usage,omitempty"`
+	Limit    float64 `json:"limit,omitempty"`
+	ShedRate float64 `json:"shed_rate"`
+}
+
+// SecretAccess records that a worker resolved a secret reference for a
+// run. It names the reference, never the value.
+type SecretAccess struct {
+	RunID string `json:"run_id"`
+	Ref   string `json:"ref"`
+	AtMs  int64  `json:"at_ms"`
+}
+
+// ControlChannelWebSocket names the WebSocket control channel between
+// workers and the control plane, served at /workers/{id}/channel.
+const ControlChannelWebSocket = "websocket"
+
+// Control channel message types. The control plane sends assignments, stop,
+// pause and token; workers send heartbeat and ack.
+const (
+	ChannelMessageAssignments = "assignments"
+	ChannelMessageStop        = "stop"
+	ChannelMessagePause       = "pause"
+	ChannelMessageHeartbeat   = "heartbeat"
+	ChannelMessageAck         = "ack"
+	ChannelMessageToken       = "token"
+)
+
+// ChannelMessage is one JSON message on the worker control channel. Only
+// the fields of its Type are set.
+type ChannelMessage struct {
+	Type string `json:"type"`
+	// Assignments are new assignments, to be acknowledged by lease id.
+	Assignments []WorkerAssignment `json:"assignments,omitempty"`
+	// StopRunIDs and ImmediateStopRunIDs list runs the worker must stop,
+	// as in a heartbeat response.
+	StopRunIDs          []string `json:"stop_run_ids,omitempty"`
+	ImmediateStopRunIDs []string `json:"immediate_stop_run_ids,omitempty"`
+	// PausedRunIDs lists every run the worker must hold paused; runs left
+	// out of a pause message are resumed.
+	PausedRunIDs []string `json:"paused_run_ids,omitempty"`
+	// Health is the worker's health with a heartbeat.
+	Health *WorkerHealth `json:"health,omitempty"`
+	// SecretAccesses are the secret accesses reported with a heartbeat.
+	SecretAccesses []SecretAccess `json:"secret_accesses,omitempty"`
+	// LeaseIDs are the assignments an ack acknowledges.
+	LeaseIDs []string `json:"lease_ids,omitempty"`
+	// WorkerToken is the worker's rotated token, replacing the current one
+	// for every later request and reconnect.
+	WorkerToken            string `json:"worker_token,omitempty"`
+	WorkerTokenExpiresAtMs int64  `json:"worker_token_expires_at_ms,omitempty"`
 }
@@ -22,6 +22,11 @@ type ValidationIssue struct {
 	Message     string          `json:"message"`
 	JSONPointer string          `json:"json_pointer,omitempty"`
 	Remediation string          `json:"remediation,omitempty"`
+
+	// Set when the config was submitted as YAML; see YAMLDocument.Annotate.
+	YAMLPath string `json:"yaml_path,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
 }
 
 // ValidationReport contains the results of validating a configuration.
@@ -189,6 +194,23 @@ const (
 	CodeInvalidStageOrder          = "INVALID_STAGE_ORDER"
 	CodeInvalidWorkerFailurePolicy = "INVALID_WORKER_FAILURE_POLICY"
 	CodeChurnIntervalOpsInvalid    = "CHURN_INTERVAL_OPS_INVALID"
+	CodeChurnIntervalMsInvalid     = "CHURN_INTERVAL_MS_INVALID"
+	CodeGeneratorGroupsInvalid     = "GENERATOR_GROUPS_INVALID"
+	CodeStopConditionInvalid       = "STOP_CONDITION_INVALID"
+	CodeSoakTrendConditionMissing  = "SOAK_TREND_CONDITION_MISSING"
+	CodeCheckpointIntervalIgnored  = "CHECKPOINT_INTERVAL_IGNORED"
+	CodeLoadProfileInvalid         = "LOAD_PROFILE_INVALID"
+	CodeArgumentTemplateInvalid    = "ARGUMENT_TEMPLATE_INVALID"
+	CodeDataFeedInvalid            = "DATA_FEED_INVALID"
+	CodeCheckInvalid               = "CHECK_INVALID"
+	CodeSLOInvalid                 = "SLO_INVALID"
+	CodeLatencyBucketsInvalid      = "LATENCY_BUCKETS_INVALID"
+	CodeAdaptiveRampInvalid        = "ADAPTIVE_RAMP_INVALID"
+	CodeAuthInvalid                = "AUTH_INVALID"
+	CodeTargetsInvalid             = "TARGETS_INVALID"
+	CodeThinkTimeInvalid           = "THINK_TIME_INVALID"
+	CodeWorkflowInvalid            = "WORKFLOW_INVALID"
+	CodeConformanceRunInvalid      = "CONFORMANCE_RUN_INVALID"
 )
 
 // ErrorEnvelope represents the canonical API error response format.
@@ -2,14 +2,27 @@ package validation
 
 import (
 	"encoding/json"
+	"math"
 	"net/url"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+	"github.com/bc-dunia/mcpdrill/internal/checks"
+	"github.com/bc-dunia/mcpdrill/internal/datafeed"
+	"github.com/bc-dunia/mcpdrill/internal/templating"
+	"github.com/bc-dunia/mcpdrill/internal/types"
 )
 
 var stageIDPatternSemantic = regexp.MustCompile(`^stg_[0-9a-f]{3,81}$`)
 
+var dataFeedNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,63}$`)
+
+// workflowNamePattern matches workflow and step names.
+var workflowNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+
 type SystemPolicy struct {
 	AllowedSecretRefs     []string         `json:"allowed_secret_refs"`
 	GlobalAllowlist       []AllowlistEntry `json:"global_allowlist"`
@@ -79,19 +92,38 @@ func (v *SemanticValidator) Validate(data []byte) *ValidationReport {
 	v.validateToolsCallRequiresTools(config, report)
 	v.validateResourcesReadRequiresURI(config, report)
 	v.validatePromptsGetRequiresName(config, report)
+	v.validateDataFeeds(config, report)
+	v.validateArgumentTemplates(config, report)
+	v.validateChecks(config, report)
+	v.validateSLOs(config, report)
+	v.validateLatencyBuckets(config, report)
 	v.validateCapsRequired(config, report)
 	v.validateCapsConsistent(config, report)
 	v.validateCapsWithinSystemPolicy(config, report)
 	v.validateAllowlistRequired(config, report)
 	v.validateTargetWithinSystemAllowlist(config, report)
+	v.validateTargetAuth(config, report)
 	v.validateSecretRefsAllowed(config, report)
 	v.validateIdentificationRequired(config, report)
 	v.validateRampByDefaultGuard(config, report)
 	v.validateStopConditionsRequired(config, report)
+	v.validateStopConditionMetrics(config, report)
+	v.validatePostRampStages(config, report)
+	v.validateSoakStages(config, report)
+	v.validateLoadProfiles(config, report)
+	v.validateChaos(config, report)
+	v.validateAdaptiveRamps(config, report)
+	v.validateArrivalRates(config, report)
 	v.validateStreamingGuardrails(config, report)
 	v.validateRedirectPolicyRequired(config, report)
 	v.validateWorkerFailurePolicy(config, report)
-	v.validateChurnIntervalOps(config, report)
+	v.validateChurnInterval(config, report)
+	v.validateThinkTimes(config, report)
+	v.validateWorkflows(config, report)
+	v.validateGeneratorGroups(config, report)
+	v.validateTargets(config, report)
+	v.validateShadow(config, report)
+	v.validateConformance(config, report)
 	v.validateTargetWithinRunAllowlist(config, report)
 	v.validateForbiddenPatterns(config, report)
 	v.validateStageIDFormats(config, report)
@@ -156,8 +188,9 @@ func (v *SemanticValidator) validateRequiredStagesPresent(config map[string]inte
 	hasPreflight := false
 	hasBaseline := false
 	hasRamp := false
+	conformanceRun := isConformanceRun(config)
 
-	for _, s := range stages {
+	for i, s := range stages {
 		stage, ok := s.(map[string]interface{})
 		if !ok {
 			continue
@@ -175,11 +208,20 @@ func (v *SemanticValidator) validateRequiredStagesPresent(config map[string]inte
 		case "ramp":
 			hasRamp = true
 		}
+		if conformanceRun && stageType != "preflight" {
+			report.AddErrorWithRemediation(CodeConformanceRunInvalid,
+				"A conformance run checks the target during preflight; the "+stageType+" stage would never run",
+				"/stages/"+strconv.Itoa(i),
+				"Disable every stage but preflight, or set run_type to load")
+		}
 	}
 
 	if !hasPreflight {
 		report.AddError(CodePreflightRequired, "Preflight stage must be enabled for MVP", "/stages")
 	}
+	if conformanceRun {
+		return
+	}
 	if !hasBaseline {
 		report.AddError(CodeBaselineRequired, "Baseline stage must be enabled for MVP", "/stages")
 	}
@@ -188,6 +230,13 @@ func (v *SemanticValidator) validateRequiredStagesPresent(config map[string]inte
 	}
 }
 
+// isConformanceRun reports whether config is a conformance run, which runs
+// the protocol conformance checks instead of a load test.
+func isConformanceRun(config map[string]interface{}) bool {
+	runType, _ := config["run_type"].(string)
+	return runType == "conformance"
+}
+
 func (v *SemanticValidator) validatePreflightFirst(config map[string]interface{}, report *ValidationReport) {
 	stages, ok := config["stages"].([]interface{})
 	if !ok || len(stages) == 0 {
@@ -238,6 +287,12 @@ func (v *SemanticValidator) validateDurationPositive(config map[string]interface
 				"Enabled stages must have duration_ms >= 1000",
 				"/stages/"+strconv.Itoa(i)+"/duration_ms")
 		}
+		if warmupMs, ok := stage["warmup_ms"].(float64); ok && warmupMs >= durationMs {
+			report.AddErrorWithRemediation(CodeDurationInvalid,
+				"warmup_ms must be shorter than the stage, or nothing is measured",
+				"/stages/"+strconv.Itoa(i)+"/warmup_ms",
+				"Lower warmup_ms or raise duration_ms")
+		}
 	}
 }
 
@@ -350,6 +405,11 @@ func (v *SemanticValidator) validateToolsCallRequiresTools(config map[string]int
 		return
 	}
 
+	// Auto discovery calls the tools the target lists instead.
+	if selection, ok := tools["selection"].(map[string]interface{}); ok && selection["mode"] == "auto_discover" {
+		return
+	}
+
 	templates, ok := tools["templates"].([]interface{})
 	if !ok || len(templates) == 0 {
 		report.AddError(CodeToolsCallRequiresTemplates,
@@ -547,15 +607,12 @@ func (v *SemanticValidator) validateTargetWithinSystemAllowlist(config map[strin
 		return
 	}
 
-	targetHost, ok := targetHostFromConfig(config)
-	if !ok {
-		return
-	}
-
-	if !v.matchesAllowlist(targetHost, v.systemPolicy.GlobalAllowlist) {
-		report.AddError(CodeAllowlistViolation,
-			"Target URL does not match system policy global allowlist",
-			"/target/url")
+	for _, target := range targetURLsFromConfig(config) {
+		if !v.matchesAllowlist(extractHost(target.url), v.systemPolicy.GlobalAllowlist) {
+			report.AddError(CodeAllowlistViolation,
+				"Target URL does not match system policy global allowlist",
+				target.pointer)
+		}
 	}
 }
 
@@ -577,13 +634,51 @@ func targetURLFromConfig(config map[string]interface{}) (string, bool) {
 	return targetURL, true
 }
 
-func targetHostFromConfig(config map[string]interface{}) (string, bool) {
-	targetURL, ok := targetURLFromConfig(config)
-	if !ok {
-		return "", false
+// targetURL is a URL the run sends operations to and the JSON pointer it
+// is configured at.
+type targetURL struct {
+	url     string
+	pointer string
+}
+
+// targetURLsFromConfig returns target.url, the url of every entry of
+// targets and shadow.url, skipping empty ones.
+func targetURLsFromConfig(config map[string]interface{}) []targetURL {
+	var urls []targetURL
+	if u, ok := targetURLFromConfig(config); ok {
+		urls = append(urls, targetURL{url: u, pointer: "/target/url"})
+	}
+	targets, _ := config["targets"].([]interface{})
+	for i, t := range targets {
+		entry, _ := t.(map[string]interface{})
+		if u, _ := entry["url"].(string); u != "" {
+			urls = append(urls, targetURL{url: u, pointer: "/targets/" + strconv.Itoa(i) + "/url"})
+		}
 	}
+	shadow, _ := config["shadow"].(map[string]interface{})
+	if u, _ := shadow["url"].(string); u != "" {
+		urls = append(urls, targetURL{url: u, pointer: "/shadow/url"})
+	}
+	return urls
+}
 
-	return extractHost(targetURL), true
+// targetObjectsFromConfig returns target, every entry of targets and
+// shadow, keyed by their JSON pointers, for the checks that apply to each.
+func targetObjectsFromConfig(config map[string]interface{}) map[string]map[string]interface{} {
+	objects := make(map[string]map[string]interface{})
+	if target, ok := config["target"].(map[string]interface{}); ok {
+		objects["/target"] = target
+	}
+	targets, _ := config["targets"].([]interface{})
+	for i, t := range targets {
+		if entry, ok := t.(map[string]interface{}); ok {
+			objects["/targets/"+strconv.Itoa(i)] = entry
+		}
+	}
+	if shadow, ok := config["shadow"].(map[string]interface{}); ok {
+		objects["/shadow"] = shadow
+	}
+	return objects
 }
 
 func extractHost(rawURL string) string {
@@ -609,34 +704,34 @@ func (v *SemanticValidator) validateSecretRefsAllowed(config map[string]interfac
 		return
 	}
 
-	target, ok := config["target"].(map[string]interface{})
-	if !ok {
-		return
-	}
-
-	auth, ok := target["auth"].(map[string]interface{})
-	if !ok {
-		return
-	}
-
-	secretRefs := []struct {
-		field string
-		path  string
-	}{
-		{"bearer_token_ref", "/target/auth/bearer_token_ref"},
-		{"api_key_ref", "/target/auth/api_key_ref"},
-	}
+	for pointer, target := range targetObjectsFromConfig(config) {
+		auth, ok := target["auth"].(map[string]interface{})
+		if !ok {
+			continue
+		}
 
-	for _, ref := range secretRefs {
-		if val, ok := auth[ref.field].(string); ok && val != "" {
-			if !v.matchesSecretRefPattern(val) {
+		for _, field := range []string{"bearer_token_ref", "api_key_ref"} {
+			if val, ok := auth[field].(string); ok && val != "" {
+				if !v.matchesSecretRefPattern(val) {
+					report.AddError(CodeSecretRefNotAllowed,
+						"Secret reference does not match allowed patterns",
+						pointer+"/auth/"+field)
+				}
+			}
+		}
+		if oauth2, ok := auth["oauth2"].(map[string]interface{}); ok {
+			if val, ok := oauth2["client_secret_ref"].(string); ok && val != "" && !v.matchesSecretRefPattern(val) {
 				report.AddError(CodeSecretRefNotAllowed,
 					"Secret reference does not match allowed patterns",
-					ref.path)
+					pointer+"/auth/oauth2/client_secret_ref")
 			}
 		}
 	}
 
+	target, ok := config["target"].(map[string]interface{})
+	if !ok {
+		return
+	}
 	tls, ok := target["tls"].(map[string]interface{})
 	if ok {
 		if caRef, ok := tls["ca_bundle_ref"].(string); ok && caRef != "" {
@@ -649,6 +744,38 @@ func (v *SemanticValidator) validateSecretRefsAllowed(config map[string]interfac
 	}
 }
 
+// validateTargetAuth checks that the oauth2_client_credentials auth type
+// has a usable token endpoint, in target and in each of targets.
+func (v *SemanticValidator) validateTargetAuth(config map[string]interface{}, report *ValidationReport) {
+	for pointer, target := range targetObjectsFromConfig(config) {
+		auth, _ := target["auth"].(map[string]interface{})
+		if authType, _ := auth["type"].(string); authType != "oauth2_client_credentials" {
+			continue
+		}
+
+		oauth2, ok := auth["oauth2"].(map[string]interface{})
+		if !ok {
+			report.AddError(CodeRequiredFieldMissing,
+				"auth type oauth2_client_credentials requires 'oauth2'",
+				pointer+"/auth/oauth2")
+			continue
+		}
+		tokenURL, _ := oauth2["token_url"].(string)
+		u, err := url.Parse(tokenURL)
+		if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+			report.AddError(CodeAuthInvalid,
+				"oauth2.token_url must be an absolute http or https URL",
+				pointer+"/auth/oauth2/token_url")
+			continue
+		}
+		if u.Scheme == "http" {
+			report.AddWarning(CodeAuthInvalid,
+				"oauth2.token_url uses http; the client secret is sent unencrypted",
+				pointer+"/auth/oauth2/token_url")
+		}
+	}
+}
+
 func (v *SemanticValidator) matchesSecretRefPattern(ref string) bool {
 	for _, pattern := range v.systemPolicy.AllowedSecretRefs {
 		if strings.HasSuffix(pattern, "*") {
@@ -803,7 +930,7 @@ func (v *SemanticValidator) validateStopConditionsRequired(config map[string]int
 		}
 		stageType, _ := stage["stage"].(string)
 
-		if stageType != "baseline" && stageType != "ramp" {
+		if stageType == "preflight" || stageType == "custom" {
 			continue
 		}
 
@@ -816,41 +943,123 @@ func (v *SemanticValidator) validateStopConditionsRequired(config map[string]int
 	}
 }
 
-func (v *SemanticValidator) validateStreamingGuardrails(config map[string]interface{}, report *ValidationReport) {
-	workload, ok := config["workload"].(map[string]interface{})
-	if !ok {
-		return
+// trendMetrics are the stop condition metrics evaluated as slopes over their
+// window (see stopconditions.IsTrendMetric). They keep one sample per
+// evaluation, so unlike other metrics they may use windows longer than an
+// hour.
+var trendMetrics = map[string]bool{
+	"latency_p50_slope_ms_per_hour": true,
+	"latency_p95_slope_ms_per_hour": true,
+	"latency_p99_slope_ms_per_hour": true,
+	"server_rss_slope_mb_per_hour":  true,
+}
+
+// serverAgentMetrics are the stop condition metrics read from the agent
+// metrics of the paired server. Server gauges, like trend metrics, do not
+// buffer operations.
+var serverAgentMetrics = map[string]bool{
+	"server_rss_slope_mb_per_hour": true,
+	"server_rss_mb":                true,
+	"server_cpu_percent":           true,
+}
+
+// maxBufferedWindowMs bounds the window of metrics computed from buffered
+// operations.
+const maxBufferedWindowMs = 3600000
+
+// maxStopConditionDepth bounds how deeply composite stop conditions nest.
+const maxStopConditionDepth = 4
+
+// stopConditionLeaves returns the metrics of cond, or of the terms of a
+// composite condition, at any depth.
+func stopConditionLeaves(cond map[string]interface{}) []string {
+	var metrics []string
+	terms := stopConditionTerms(cond)
+	if len(terms) == 0 {
+		metric, _ := cond["metric"].(string)
+		return append(metrics, metric)
 	}
+	for _, t := range terms {
+		if term, ok := t.(map[string]interface{}); ok {
+			metrics = append(metrics, stopConditionLeaves(term)...)
+		}
+	}
+	return metrics
+}
 
-	hasStreaming := false
-	tools, ok := workload["tools"].(map[string]interface{})
-	if ok {
-		templates, ok := tools["templates"].([]interface{})
-		if ok {
-			for _, t := range templates {
-				tmpl, ok := t.(map[string]interface{})
-				if !ok {
-					continue
-				}
-				if expectsStreaming, ok := tmpl["expects_streaming"].(bool); ok && expectsStreaming {
-					hasStreaming = true
-					break
-				}
+// stopConditionTerms returns the all or any terms of a composite condition.
+func stopConditionTerms(cond map[string]interface{}) []interface{} {
+	if all, _ := cond["all"].([]interface{}); len(all) > 0 {
+		return all
+	}
+	anyTerms, _ := cond["any"].([]interface{})
+	return anyTerms
+}
+
+// validateStopConditionTerm checks that cond, a stop condition or a term of
+// a composite one, sets either a metric to compare or terms, and that its
+// server metrics can be collected.
+func validateStopConditionTerm(cond map[string]interface{}, pointer, pairKey string, depth int, report *ValidationReport) {
+	metric, _ := cond["metric"].(string)
+	allTerms, _ := cond["all"].([]interface{})
+	anyTerms, _ := cond["any"].([]interface{})
+	switch {
+	case len(allTerms) > 0 && len(anyTerms) > 0:
+		report.AddError(CodeStopConditionInvalid,
+			"set all or any, not both; nest a composite term to combine them", pointer)
+		return
+	case len(allTerms) > 0 || len(anyTerms) > 0:
+		if metric != "" {
+			report.AddError(CodeStopConditionInvalid,
+				"a composite condition takes its metrics from its terms", pointer+"/metric")
+		}
+		if depth >= maxStopConditionDepth {
+			report.AddError(CodeStopConditionInvalid,
+				"composite stop conditions nest at most "+strconv.Itoa(maxStopConditionDepth)+" levels deep", pointer)
+			return
+		}
+		key := "all"
+		if len(anyTerms) > 0 {
+			key = "any"
+		}
+		for k, t := range stopConditionTerms(cond) {
+			if term, ok := t.(map[string]interface{}); ok {
+				validateStopConditionTerm(term, pointer+"/"+key+"/"+strconv.Itoa(k), pairKey, depth+1, report)
 			}
 		}
+		return
+	case metric == "":
+		report.AddError(CodeStopConditionInvalid,
+			"stop condition requires a metric, or all or any terms", pointer+"/metric")
+		return
 	}
 
-	if !hasStreaming {
-		return
+	if _, ok := cond["comparator"].(string); !ok {
+		report.AddError(CodeStopConditionInvalid, metric+" requires a comparator", pointer+"/comparator")
 	}
+	if _, ok := cond["threshold"].(float64); !ok {
+		report.AddError(CodeStopConditionInvalid, metric+" requires a threshold", pointer+"/threshold")
+	}
+	if serverAgentMetrics[metric] && pairKey == "" {
+		report.AddErrorWithRemediation(CodeStopConditionInvalid,
+			metric+" requires server telemetry from mcpdrill-agent",
+			pointer+"/metric",
+			"Set server_telemetry.pair_key and run mcpdrill-agent with --pid or --listen-port on the server host")
+	}
+}
 
+func (v *SemanticValidator) validateStopConditionMetrics(config map[string]interface{}, report *ValidationReport) {
 	stages, ok := config["stages"].([]interface{})
 	if !ok {
 		return
 	}
 
-	hasStreamStallCondition := false
-	for _, s := range stages {
+	pairKey := ""
+	if serverTelemetry, ok := config["server_telemetry"].(map[string]interface{}); ok {
+		pairKey, _ = serverTelemetry["pair_key"].(string)
+	}
+
+	for i, s := range stages {
 		stage, ok := s.(map[string]interface{})
 		if !ok {
 			continue
@@ -859,111 +1068,1141 @@ func (v *SemanticValidator) validateStreamingGuardrails(config map[string]interf
 		if !enabled {
 			continue
 		}
-
-		stopConditions, ok := stage["stop_conditions"].([]interface{})
-		if !ok {
-			continue
-		}
-
-		for _, sc := range stopConditions {
+		stopConditions, _ := stage["stop_conditions"].([]interface{})
+		for j, sc := range stopConditions {
 			cond, ok := sc.(map[string]interface{})
 			if !ok {
 				continue
 			}
-			metric, _ := cond["metric"].(string)
-			if strings.Contains(metric, "stream_stall") {
-				hasStreamStallCondition = true
-				break
+			pointer := "/stages/" + strconv.Itoa(i) + "/stop_conditions/" + strconv.Itoa(j)
+			validateStopConditionTerm(cond, pointer, pairKey, 0, report)
+
+			// Every term of a composite shares its window.
+			buffered := false
+			for _, metric := range stopConditionLeaves(cond) {
+				buffered = buffered || (!trendMetrics[metric] && !serverAgentMetrics[metric])
+			}
+			if windowMs, ok := cond["window_ms"].(float64); ok && windowMs > maxBufferedWindowMs && buffered {
+				report.AddErrorWithRemediation(CodeStopConditionInvalid,
+					"window_ms above 3600000 is only allowed for trend and server agent metrics",
+					pointer+"/window_ms",
+					"Use a window of at most one hour, or a *_slope_* metric to watch long-term trends")
 			}
 		}
 	}
-
-	if !hasStreamStallCondition {
-		report.AddError(CodeStreamingGuardrails,
-			"Streaming is enabled but no stream stall stop condition is defined",
-			"/stages")
-	}
 }
 
-func (v *SemanticValidator) validateRedirectPolicyRequired(config map[string]interface{}, report *ValidationReport) {
-	target, ok := config["target"].(map[string]interface{})
+// postRampStageTypes are the stages that stage progression runs after the
+// ramp, in config order.
+var postRampStageTypes = map[string]bool{"soak": true, "spike": true, "step": true}
+
+func (v *SemanticValidator) validatePostRampStages(config map[string]interface{}, report *ValidationReport) {
+	stages, ok := config["stages"].([]interface{})
 	if !ok {
 		return
 	}
 
-	redirectPolicy, ok := target["redirect_policy"].(map[string]interface{})
+	rampSeen := false
+	seen := make(map[string]bool)
+	for i, s := range stages {
+		stage, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		enabled, _ := stage["enabled"].(bool)
+		if !enabled {
+			continue
+		}
+		stageType, _ := stage["stage"].(string)
+		pointer := "/stages/" + strconv.Itoa(i)
+
+		if stageType == "ramp" {
+			rampSeen = true
+			continue
+		}
+		if !postRampStageTypes[stageType] {
+			continue
+		}
+		if !rampSeen {
+			report.AddErrorWithRemediation(CodeInvalidStageOrder,
+				stageType+" stage must come after the ramp stage",
+				pointer,
+				"Move the "+stageType+" stage after the enabled ramp stage")
+		}
+		if seen[stageType] {
+			report.AddWarning(CodeInvalidStageOrder,
+				"only the first enabled "+stageType+" stage runs",
+				pointer)
+		}
+		seen[stageType] = true
+	}
+}
+
+// validateSoakStages checks that a soak stage watches for slow degradation.
+func (v *SemanticValidator) validateSoakStages(config map[string]interface{}, report *ValidationReport) {
+	stages, ok := config["stages"].([]interface{})
 	if !ok {
-		report.AddError(CodeRedirectPolicyRequired,
-			"target.redirect_policy is required",
-			"/target/redirect_policy")
 		return
 	}
 
-	if _, ok := redirectPolicy["mode"].(string); !ok {
-		report.AddError(CodeRedirectPolicyRequired,
-			"target.redirect_policy.mode must be explicitly set",
-			"/target/redirect_policy/mode")
-	}
-}
+	for i, s := range stages {
+		stage, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		enabled, _ := stage["enabled"].(bool)
+		if !enabled {
+			continue
+		}
+		stageType, _ := stage["stage"].(string)
+		pointer := "/stages/" + strconv.Itoa(i)
+
+		if _, set := stage["checkpoint_interval_ms"].(float64); set && stageType != "soak" {
+			report.AddWarning(CodeCheckpointIntervalIgnored,
+				"checkpoint_interval_ms only applies to soak stages",
+				pointer+"/checkpoint_interval_ms")
+		}
+
+		if stageType == "soak" {
+			hasTrend := false
+			stopConditions, _ := stage["stop_conditions"].([]interface{})
+			for _, sc := range stopConditions {
+				if cond, ok := sc.(map[string]interface{}); ok {
+					for _, metric := range stopConditionLeaves(cond) {
+						hasTrend = hasTrend || trendMetrics[metric]
+					}
+				}
+			}
+			if !hasTrend && len(stopConditions) > 0 {
+				report.AddWarning(CodeSoakTrendConditionMissing,
+					"soak stage has no trend stop condition (e.g. latency_p99_slope_ms_per_hour or server_rss_slope_mb_per_hour), so slow leaks will not stop the run",
+					pointer+"/stop_conditions")
+			}
 
-var validWorkerFailurePolicies = map[string]bool{
-	"fail_fast":           true,
-	"replace_if_possible": true,
-	"best_effort":         true,
+			if interval, ok := stage["checkpoint_interval_ms"].(float64); ok {
+				if duration, ok := stage["duration_ms"].(float64); ok && interval >= duration {
+					report.AddWarning(CodeCheckpointIntervalIgnored,
+						"checkpoint_interval_ms is not shorter than the stage, so no checkpoint will be emitted",
+						pointer+"/checkpoint_interval_ms")
+				}
+			}
+		}
+	}
 }
 
-func (v *SemanticValidator) validateWorkerFailurePolicy(config map[string]interface{}, report *ValidationReport) {
-	safety, ok := config["safety"].(map[string]interface{})
+// validateLoadProfiles checks the plateaus of spike and step stages.
+func (v *SemanticValidator) validateLoadProfiles(config map[string]interface{}, report *ValidationReport) {
+	stages, ok := config["stages"].([]interface{})
 	if !ok {
 		return
 	}
 
-	policy, ok := safety["worker_failure_policy"].(string)
-	if !ok {
-		return
+	maxVUs := 0.0
+	if safety, ok := config["safety"].(map[string]interface{}); ok {
+		if hardCaps, ok := safety["hard_caps"].(map[string]interface{}); ok {
+			maxVUs, _ = hardCaps["max_vus"].(float64)
+		}
 	}
 
-	if !validWorkerFailurePolicies[policy] {
-		report.AddErrorWithRemediation(CodeInvalidWorkerFailurePolicy,
-			"Invalid worker_failure_policy value: "+policy,
-			"/safety/worker_failure_policy",
-			"Valid values are: fail_fast, replace_if_possible, best_effort")
+	for i, s := range stages {
+		stage, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		enabled, _ := stage["enabled"].(bool)
+		if !enabled {
+			continue
+		}
+		stageType, _ := stage["stage"].(string)
+		pointer := "/stages/" + strconv.Itoa(i)
+
+		spike, hasSpike := stage["spike"].(map[string]interface{})
+		if hasSpike && stageType != "spike" {
+			report.AddWarning(CodeLoadProfileInvalid, "spike only applies to spike stages", pointer+"/spike")
+		}
+		steps, _ := stage["steps"].([]interface{})
+		if len(steps) > 0 && stageType != "step" {
+			report.AddWarning(CodeLoadProfileInvalid, "steps only applies to step stages", pointer+"/steps")
+		}
+
+		switch stageType {
+		case "spike":
+			holdMs, _ := spike["hold_ms"].(float64)
+			if duration, ok := stage["duration_ms"].(float64); ok && holdMs >= duration {
+				report.AddErrorWithRemediation(CodeLoadProfileInvalid,
+					"spike.hold_ms must be shorter than the stage so load can return to baseline",
+					pointer+"/spike/hold_ms",
+					"Lower spike.hold_ms or raise duration_ms")
+			}
+		case "step":
+			if len(steps) == 0 {
+				report.AddErrorWithRemediation(CodeLoadProfileInvalid,
+					"step stage must define at least one step",
+					pointer+"/steps",
+					`Add steps such as [{"target_vus": 10, "hold_ms": 60000}, {"target_vus": 20, "hold_ms": 60000}]`)
+				continue
+			}
+			totalHoldMs := 0.0
+			for j, st := range steps {
+				step, ok := st.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				hold, _ := step["hold_ms"].(float64)
+				totalHoldMs += hold
+				if targetVUs, _ := step["target_vus"].(float64); maxVUs > 0 && targetVUs > maxVUs {
+					report.AddWarning(CodeLoadProfileInvalid,
+						"step target_vus exceeds safety.hard_caps.max_vus and will be capped",
+						pointer+"/steps/"+strconv.Itoa(j)+"/target_vus")
+				}
+			}
+			if duration, ok := stage["duration_ms"].(float64); ok && totalHoldMs > duration {
+				report.AddWarning(CodeLoadProfileInvalid,
+					"steps are held longer than duration_ms, so the last steps are cut short",
+					pointer+"/steps")
+			}
+		}
 	}
 }
 
-func (v *SemanticValidator) validateChurnIntervalOps(config map[string]interface{}, report *ValidationReport) {
-	sessionPolicy, ok := config["session_policy"].(map[string]interface{})
+// validateChaos warns about stage network faults that are unlikely to be
+// what was meant.
+func (v *SemanticValidator) validateChaos(config map[string]interface{}, report *ValidationReport) {
+	stages, ok := config["stages"].([]interface{})
 	if !ok {
 		return
 	}
-
-	mode, _ := sessionPolicy["mode"].(string)
-	churnIntervalOps, hasChurnOps := sessionPolicy["churn_interval_ops"].(float64)
-
-	if hasChurnOps && churnIntervalOps > 0 && mode != "churn" {
-		report.AddErrorWithRemediation(CodeChurnIntervalOpsInvalid,
-			"churn_interval_ops is only valid when session_policy.mode is 'churn'",
-			"/session_policy/churn_interval_ops",
-			"Either set mode to 'churn' or remove churn_interval_ops")
+	for i, s := range stages {
+		stage, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		chaos, ok := stage["chaos"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		pointer := "/stages/" + strconv.Itoa(i) + "/chaos"
+		if stageType, _ := stage["stage"].(string); stageType == "preflight" {
+			report.AddWarning(CodeLoadProfileInvalid,
+				"chaos on the preflight stage can fail the run before any load is applied",
+				pointer)
+		}
+		latency, _ := chaos["latency_ms"].(float64)
+		if jitter, _ := chaos["jitter_ms"].(float64); jitter > latency {
+			report.AddWarning(CodeLoadProfileInvalid,
+				"jitter_ms exceeds latency_ms; negative delays count as none, so latency averages more than latency_ms",
+				pointer+"/jitter_ms")
+		}
 	}
 }
-func (v *SemanticValidator) validateTargetWithinRunAllowlist(config map[string]interface{}, report *ValidationReport) {
-	targetURL, ok := targetURLFromConfig(config)
+
+// validateAdaptiveRamps checks ramp stages in adaptive mode. The search
+// varies VUs, so it needs guardrails it can judge a single probe by and the
+// closed load model.
+func (v *SemanticValidator) validateAdaptiveRamps(config map[string]interface{}, report *ValidationReport) {
+	stages, ok := config["stages"].([]interface{})
 	if !ok {
 		return
 	}
 
-	// Check if environment.allowlist section exists
-	env, envOk := config["environment"].(map[string]interface{})
-	if !envOk {
-		// No environment section - skip run allowlist validation
-		// (validateAllowlistRequired will handle this)
-		return
+	pairKey := ""
+	if serverTelemetry, ok := config["server_telemetry"].(map[string]interface{}); ok {
+		pairKey, _ = serverTelemetry["pair_key"].(string)
 	}
 
-	allowlistSection, allowlistOk := env["allowlist"].(map[string]interface{})
-	if !allowlistOk {
-		// No allowlist section - skip run allowlist validation
+	for i, s := range stages {
+		stage, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		enabled, _ := stage["enabled"].(bool)
+		ramp, _ := stage["ramp"].(map[string]interface{})
+		if mode, _ := ramp["mode"].(string); !enabled || mode != "adaptive" {
+			continue
+		}
+		pointer := "/stages/" + strconv.Itoa(i)
+
+		if stageType, _ := stage["stage"].(string); stageType != "ramp" {
+			report.AddError(CodeAdaptiveRampInvalid, "adaptive ramps only apply to ramp stages", pointer+"/ramp/mode")
+			continue
+		}
+		if stepVUs, _ := ramp["step_vus"].(float64); stepVUs < 1 {
+			report.AddError(CodeAdaptiveRampInvalid, "an adaptive ramp requires step_vus of at least 1", pointer+"/ramp/step_vus")
+		}
+		if load, ok := stage["load"].(map[string]interface{}); ok && load["arrival"] != nil {
+			report.AddErrorWithRemediation(CodeAdaptiveRampInvalid,
+				"an adaptive ramp searches over VUs and cannot use an arrival rate",
+				pointer+"/load/arrival",
+				"Remove load.arrival or use a step ramp")
+		}
+
+		guardrails, _ := ramp["guardrails"].([]interface{})
+		if len(guardrails) == 0 {
+			report.AddErrorWithRemediation(CodeAdaptiveRampInvalid,
+				"an adaptive ramp requires at least one guardrail",
+				pointer+"/ramp/guardrails",
+				`Add guardrails such as [{"metric": "error_rate", "comparator": ">", "threshold": 0.01}]`)
+			continue
+		}
+		for k, g := range guardrails {
+			term, ok := g.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			gp := pointer + "/ramp/guardrails/" + strconv.Itoa(k)
+			validateStopConditionTerm(term, gp, pairKey, 0, report)
+			for _, metric := range stopConditionLeaves(term) {
+				if trendMetrics[metric] {
+					report.AddError(CodeAdaptiveRampInvalid,
+						metric+" needs a trend across windows and cannot judge a single probe", gp)
+				}
+			}
+		}
+	}
+}
+
+// validateArrivalRates checks stages that use the open (arrival-rate) load
+// model. Their target_vus are the sessions arrivals are spread over.
+func (v *SemanticValidator) validateArrivalRates(config map[string]interface{}, report *ValidationReport) {
+	stages, ok := config["stages"].([]interface{})
+	if !ok {
+		return
+	}
+
+	maxRPS := 0.0
+	if safety, ok := config["safety"].(map[string]interface{}); ok {
+		if hardCaps, ok := safety["hard_caps"].(map[string]interface{}); ok {
+			maxRPS, _ = hardCaps["max_rps"].(float64)
+		}
+	}
+
+	for i, s := range stages {
+		stage, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		load, _ := stage["load"].(map[string]interface{})
+		arrival, ok := load["arrival"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if enabled, _ := stage["enabled"].(bool); !enabled {
+			continue
+		}
+		stageType, _ := stage["stage"].(string)
+		pointer := "/stages/" + strconv.Itoa(i) + "/load"
+
+		if targetVUs, _ := load["target_vus"].(float64); targetVUs < 1 && stageType != "spike" && stageType != "step" {
+			report.AddErrorWithRemediation(CodeLoadProfileInvalid,
+				"arrival-rate stages need target_vus of at least 1 to open sessions for the arrivals",
+				pointer+"/target_vus",
+				"Set target_vus to the number of sessions the arrivals should share")
+		}
+		if rate, _ := arrival["rate_rps"].(float64); maxRPS > 0 && rate > maxRPS {
+			report.AddError(CodeCapsInconsistent,
+				"load.arrival.rate_rps exceeds safety.hard_caps.max_rps",
+				pointer+"/arrival/rate_rps")
+		}
+		if stageType == "spike" || stageType == "step" {
+			report.AddWarning(CodeLoadProfileInvalid,
+				"arrival rate is held constant across "+stageType+" plateaus; only the number of sessions changes",
+				pointer+"/arrival")
+		}
+	}
+}
+
+func (v *SemanticValidator) validateStreamingGuardrails(config map[string]interface{}, report *ValidationReport) {
+	workload, ok := config["workload"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	hasStreaming := false
+	tools, ok := workload["tools"].(map[string]interface{})
+	if ok {
+		templates, ok := tools["templates"].([]interface{})
+		if ok {
+			for _, t := range templates {
+				tmpl, ok := t.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if expectsStreaming, ok := tmpl["expects_streaming"].(bool); ok && expectsStreaming {
+					hasStreaming = true
+					break
+				}
+			}
+		}
+	}
+
+	if !hasStreaming {
+		return
+	}
+
+	stages, ok := config["stages"].([]interface{})
+	if !ok {
+		return
+	}
+
+	hasStreamStallCondition := false
+	for _, s := range stages {
+		stage, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		enabled, _ := stage["enabled"].(bool)
+		if !enabled {
+			continue
+		}
+
+		stopConditions, ok := stage["stop_conditions"].([]interface{})
+		if !ok {
+			continue
+		}
+
+		for _, sc := range stopConditions {
+			cond, ok := sc.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			metric, _ := cond["metric"].(string)
+			if strings.Contains(metric, "stream_stall") {
+				hasStreamStallCondition = true
+				break
+			}
+		}
+	}
+
+	if !hasStreamStallCondition {
+		report.AddError(CodeStreamingGuardrails,
+			"Streaming is enabled but no stream stall stop condition is defined",
+			"/stages")
+	}
+}
+
+func (v *SemanticValidator) validateRedirectPolicyRequired(config map[string]interface{}, report *ValidationReport) {
+	target, ok := config["target"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	redirectPolicy, ok := target["redirect_policy"].(map[string]interface{})
+	if !ok {
+		report.AddError(CodeRedirectPolicyRequired,
+			"target.redirect_policy is required",
+			"/target/redirect_policy")
+		return
+	}
+
+	if _, ok := redirectPolicy["mode"].(string); !ok {
+		report.AddError(CodeRedirectPolicyRequired,
+			"target.redirect_policy.mode must be explicitly set",
+			"/target/redirect_policy/mode")
+	}
+}
+
+var validWorkerFailurePolicies = map[string]bool{
+	"fail_fast":           true,
+	"replace_if_possible": true,
+	"best_effort":         true,
+}
+
+func (v *SemanticValidator) validateWorkerFailurePolicy(config map[string]interface{}, report *ValidationReport) {
+	safety, ok := config["safety"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	policy, ok := safety["worker_failure_policy"].(string)
+	if !ok {
+		return
+	}
+
+	if !validWorkerFailurePolicies[policy] {
+		report.AddErrorWithRemediation(CodeInvalidWorkerFailurePolicy,
+			"Invalid worker_failure_policy value: "+policy,
+			"/safety/worker_failure_policy",
+			"Valid values are: fail_fast, replace_if_possible, best_effort")
+	}
+}
+
+func (v *SemanticValidator) validateChurnInterval(config map[string]interface{}, report *ValidationReport) {
+	sessionPolicy, ok := config["session_policy"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	mode, _ := sessionPolicy["mode"].(string)
+	churnIntervalOps, hasChurnOps := sessionPolicy["churn_interval_ops"].(float64)
+	churnIntervalMs, hasChurnMs := sessionPolicy["churn_interval_ms"].(float64)
+	hasChurnOps = hasChurnOps && churnIntervalOps > 0
+	hasChurnMs = hasChurnMs && churnIntervalMs > 0
+
+	if hasChurnOps && mode != "churn" {
+		report.AddErrorWithRemediation(CodeChurnIntervalOpsInvalid,
+			"churn_interval_ops is only valid when session_policy.mode is 'churn'",
+			"/session_policy/churn_interval_ops",
+			"Either set mode to 'churn' or remove churn_interval_ops")
+	}
+	if hasChurnMs && mode != "churn" {
+		report.AddErrorWithRemediation(CodeChurnIntervalMsInvalid,
+			"churn_interval_ms is only valid when session_policy.mode is 'churn'",
+			"/session_policy/churn_interval_ms",
+			"Either set mode to 'churn' or remove churn_interval_ms")
+	}
+	if hasChurnOps && hasChurnMs && mode == "churn" {
+		report.AddErrorWithRemediation(CodeChurnIntervalMsInvalid,
+			"churn_interval_ms and churn_interval_ops cannot both be set",
+			"/session_policy/churn_interval_ms",
+			"Churn either by operation count or by time; remove one of the two")
+	}
+}
+
+// validateArgumentTemplates checks the ${...} expressions in tool template,
+// op mix and generator group arguments, which workers evaluate per call.
+func (v *SemanticValidator) validateArgumentTemplates(config map[string]interface{}, report *ValidationReport) {
+	isVariable := dataFeedVariables(config)
+	const remediation = "Use ${name} for a variable or ${function(args)} with quoted strings and numbers; write $${ for a literal ${"
+	check := func(entries []interface{}, path string) {
+		for i, e := range entries {
+			entry, ok := e.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for _, field := range []string{"arguments", "uri"} {
+				value, ok := entry[field]
+				if !ok {
+					continue
+				}
+				if err := templating.Validate(value, isVariable); err != nil {
+					report.AddErrorWithRemediation(CodeArgumentTemplateInvalid,
+						"invalid "+field+" template: "+err.Error(),
+						path+"/"+strconv.Itoa(i)+"/"+field,
+						remediation)
+				}
+			}
+		}
+	}
+
+	if target, ok := config["target"].(map[string]interface{}); ok {
+		headers, _ := target["headers"].(map[string]interface{})
+		for name, value := range headers {
+			if err := templating.Validate(value, isVariable); err != nil {
+				report.AddErrorWithRemediation(CodeArgumentTemplateInvalid,
+					"invalid header template: "+err.Error(),
+					"/target/headers/"+escapePointerToken(name),
+					remediation)
+			}
+		}
+	}
+
+	if workload, ok := config["workload"].(map[string]interface{}); ok {
+		if tools, ok := workload["tools"].(map[string]interface{}); ok {
+			templates, _ := tools["templates"].([]interface{})
+			check(templates, "/workload/tools/templates")
+		}
+		for _, key := range []string{"operation_mix", "op_mix"} {
+			opMix, _ := workload[key].([]interface{})
+			check(opMix, "/workload/"+key)
+		}
+	}
+
+	groups, _ := config["generator_groups"].([]interface{})
+	for i, g := range groups {
+		if group, ok := g.(map[string]interface{}); ok {
+			opMix, _ := group["operation_mix"].([]interface{})
+			check(opMix, "/generator_groups/"+strconv.Itoa(i)+"/operation_mix")
+		}
+	}
+}
+
+// validateChecks compiles the response checks of op-mix entries and tool
+// templates, so a bad JSONPath or regex fails the run config instead of
+// failing every operation at run time.
+func (v *SemanticValidator) validateChecks(config map[string]interface{}, report *ValidationReport) {
+	check := func(entries []interface{}, path string) {
+		for i, e := range entries {
+			entry, ok := e.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			list, _ := entry["checks"].([]interface{})
+			for j, c := range list {
+				checkPath := path + "/" + strconv.Itoa(i) + "/checks/" + strconv.Itoa(j)
+				data, err := json.Marshal(c)
+				if err != nil {
+					continue
+				}
+				var cfg types.CheckConfig
+				if err := json.Unmarshal(data, &cfg); err != nil {
+					report.AddError(CodeCheckInvalid, "invalid check: "+err.Error(), checkPath)
+					continue
+				}
+				if err := checks.Validate(cfg); err != nil {
+					report.AddError(CodeCheckInvalid, "invalid check: "+err.Error(), checkPath)
+				}
+			}
+		}
+	}
+
+	if workload, ok := config["workload"].(map[string]interface{}); ok {
+		if tools, ok := workload["tools"].(map[string]interface{}); ok {
+			templates, _ := tools["templates"].([]interface{})
+			check(templates, "/workload/tools/templates")
+		}
+		for _, key := range []string{"operation_mix", "op_mix"} {
+			opMix, _ := workload[key].([]interface{})
+			check(opMix, "/workload/"+key)
+		}
+	}
+
+	groups, _ := config["generator_groups"].([]interface{})
+	for i, g := range groups {
+		if group, ok := g.(map[string]interface{}); ok {
+			opMix, _ := group["operation_mix"].([]interface{})
+			check(opMix, "/generator_groups/"+strconv.Itoa(i)+"/operation_mix")
+		}
+	}
+}
+
+// validateSLOs parses every SLO objective. An SLO that cannot be evaluated
+// would fail the run's verdict no matter how the target performed.
+func (v *SemanticValidator) validateSLOs(config map[string]interface{}, report *ValidationReport) {
+	slos, _ := config["slos"].([]interface{})
+	for i, s := range slos {
+		entry, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		slo := analysis.SLO{}
+		slo.Name, _ = entry["name"].(string)
+		slo.Objective, _ = entry["objective"].(string)
+		slo.Operation, _ = entry["operation"].(string)
+		slo.ToolName, _ = entry["tool_name"].(string)
+		if err := analysis.ValidateSLO(slo); err != nil {
+			report.AddErrorWithRemediation(CodeSLOInvalid,
+				"invalid SLO: "+err.Error(),
+				"/slos/"+strconv.Itoa(i),
+				"Write the objective as <metric> <op> <threshold>, e.g. \"p95_latency_ms < 500\" or \"error_rate < 1%\"")
+		}
+	}
+}
+
+// validateLatencyBuckets checks reporting.latency_buckets for what the schema
+// cannot: boundaries in increasing order and deadlines scoped to one
+// operation or tool at most.
+func (v *SemanticValidator) validateLatencyBuckets(config map[string]interface{}, report *ValidationReport) {
+	reporting, _ := config["reporting"].(map[string]interface{})
+	block, ok := reporting["latency_buckets"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	raw, err := json.Marshal(block)
+	if err != nil {
+		return
+	}
+	var cfg analysis.LatencyBucketConfig
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return
+	}
+	if err := analysis.ValidateLatencyBuckets(cfg); err != nil {
+		report.AddErrorWithRemediation(CodeLatencyBucketsInvalid,
+			"invalid latency buckets: "+err.Error(),
+			"/reporting/latency_buckets",
+			"List boundaries_ms in increasing order and give each deadline at most one of operation or tool_name")
+	}
+}
+
+// dataFeedVariables returns the template variable check for the run's data
+// feeds: feed.<name>.<column> must name a declared feed and one of its
+// columns. Columns of feeds that fail to parse are not checked, since
+// validateDataFeeds already reports the feed.
+func dataFeedVariables(config map[string]interface{}) func(string) bool {
+	workload, _ := config["workload"].(map[string]interface{})
+	entries, _ := workload["data_feeds"].([]interface{})
+	if len(entries) == 0 {
+		return nil
+	}
+	declared := make(map[string]*datafeed.Feed, len(entries))
+	for _, e := range entries {
+		entry, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := entry["name"].(string)
+		if _, duplicate := declared[name]; !duplicate {
+			declared[name] = parseDataFeedEntry(entry)
+		}
+	}
+	return func(variable string) bool {
+		feedName, column, ok := datafeed.SplitReference(variable)
+		if !ok {
+			return false
+		}
+		feed, declaredFeed := declared[feedName]
+		return declaredFeed && (feed == nil || feed.HasColumn(column))
+	}
+}
+
+func parseDataFeedEntry(entry map[string]interface{}) *datafeed.Feed {
+	name, _ := entry["name"].(string)
+	format, _ := entry["format"].(string)
+	distribution, _ := entry["distribution"].(string)
+	data, _ := entry["data"].(string)
+	feed, err := datafeed.Parse(name, datafeed.Format(format), datafeed.Distribution(distribution), data)
+	if err != nil {
+		return nil
+	}
+	return feed
+}
+
+// validateDataFeeds checks that data feeds have unique names, carry their
+// data inline and parse, and that unique feeds have a row for every VU.
+func (v *SemanticValidator) validateDataFeeds(config map[string]interface{}, report *ValidationReport) {
+	workload, _ := config["workload"].(map[string]interface{})
+	entries, _ := workload["data_feeds"].([]interface{})
+	if len(entries) == 0 {
+		return
+	}
+
+	peakVUs := peakStageVUs(config)
+	seen := make(map[string]bool, len(entries))
+	for i, e := range entries {
+		entry, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		pointer := "/workload/data_feeds/" + strconv.Itoa(i)
+
+		name, _ := entry["name"].(string)
+		if !dataFeedNamePattern.MatchString(name) {
+			report.AddErrorWithRemediation(CodeDataFeedInvalid,
+				"invalid data feed name '"+name+"'",
+				pointer+"/name",
+				"Use letters, digits and underscores, starting with a letter, so templates can reference it as ${feed.<name>.<column>}")
+		}
+		if seen[name] {
+			report.AddError(CodeDataFeedInvalid, "duplicate data feed name '"+name+"'", pointer+"/name")
+		}
+		seen[name] = true
+
+		data, _ := entry["data"].(string)
+		if _, hasPath := entry["path"]; hasPath {
+			report.AddErrorWithRemediation(CodeDataFeedInvalid,
+				"data feed path is not read by the control plane",
+				pointer+"/path",
+				"Create the run with the mcpdrill CLI, which inlines path as data, or set data to the file content")
+			continue
+		}
+		if data == "" {
+			report.AddError(CodeRequiredFieldMissing, "data feed requires 'data'", pointer+"/data")
+			continue
+		}
+
+		format, _ := entry["format"].(string)
+		distribution, _ := entry["distribution"].(string)
+		feed, err := datafeed.Parse(name, datafeed.Format(format), datafeed.Distribution(distribution), data)
+		if err != nil {
+			report.AddError(CodeDataFeedInvalid, "data feed '"+name+"': "+err.Error(), pointer+"/data")
+			continue
+		}
+		if feed.Distribution == datafeed.DistributionUnique && len(feed.Rows) < peakVUs {
+			report.AddErrorWithRemediation(CodeDataFeedInvalid,
+				"data feed '"+name+"' has "+strconv.Itoa(len(feed.Rows))+" rows but the run reaches "+strconv.Itoa(peakVUs)+" VUs",
+				pointer+"/distribution",
+				"Add rows, or use distribution round_robin or random to share rows between VUs")
+		}
+	}
+}
+
+// peakStageVUs returns the most VUs any enabled stage runs, within
+// safety.hard_caps.max_vus.
+func peakStageVUs(config map[string]interface{}) int {
+	stages, _ := config["stages"].([]interface{})
+	peak := 0.0
+	for _, s := range stages {
+		stage, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if enabled, _ := stage["enabled"].(bool); !enabled {
+			continue
+		}
+		load, _ := stage["load"].(map[string]interface{})
+		target, _ := load["target_vus"].(float64)
+		peak = max(peak, target)
+		if spike, ok := stage["spike"].(map[string]interface{}); ok && stage["stage"] == "spike" {
+			if multiplier, _ := spike["multiplier"].(float64); multiplier > 1 {
+				peak = max(peak, math.Ceil(target*multiplier))
+			}
+		}
+		steps, _ := stage["steps"].([]interface{})
+		for _, st := range steps {
+			if step, ok := st.(map[string]interface{}); ok {
+				stepTarget, _ := step["target_vus"].(float64)
+				peak = max(peak, stepTarget)
+			}
+		}
+	}
+	if safety, ok := config["safety"].(map[string]interface{}); ok {
+		if hardCaps, ok := safety["hard_caps"].(map[string]interface{}); ok {
+			if maxVUs, _ := hardCaps["max_vus"].(float64); maxVUs > 0 {
+				peak = min(peak, maxVUs)
+			}
+		}
+	}
+	return int(peak)
+}
+
+// validateTargets checks that the names of a multi-target run's targets are
+// unique. Their URLs and auth are checked with target's.
+func (v *SemanticValidator) validateTargets(config map[string]interface{}, report *ValidationReport) {
+	targets, _ := config["targets"].([]interface{})
+	seen := make(map[string]bool, len(targets))
+	for i, t := range targets {
+		entry, ok := t.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := entry["name"].(string)
+		if seen[name] {
+			report.AddErrorWithRemediation(CodeTargetsInvalid,
+				"target name '"+name+"' is used more than once",
+				"/targets/"+strconv.Itoa(i)+"/name",
+				"Give each target a unique name")
+		}
+		seen[name] = true
+	}
+}
+
+// validateShadow warns about a shadow that is also a target, which would
+// get every call twice, and about stable tools the workload never calls.
+func (v *SemanticValidator) validateShadow(config map[string]interface{}, report *ValidationReport) {
+	shadow, ok := config["shadow"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	shadowURL, _ := shadow["url"].(string)
+	for _, target := range targetURLsFromConfig(config) {
+		if target.pointer != "/shadow/url" && target.url == shadowURL {
+			report.AddWarning(CodeTargetsInvalid,
+				"shadow.url is the same as "+strings.TrimPrefix(target.pointer, "/")+"; that server gets every operation twice",
+				"/shadow/url")
+		}
+	}
+
+	workload, _ := config["workload"].(map[string]interface{})
+	tools, _ := workload["tools"].(map[string]interface{})
+	selection, _ := tools["selection"].(map[string]interface{})
+	if mode, _ := selection["mode"].(string); mode == "auto_discover" {
+		return
+	}
+	called := make(map[string]bool)
+	templates, _ := tools["templates"].([]interface{})
+	for _, t := range templates {
+		template, _ := t.(map[string]interface{})
+		if name, _ := template["tool_name"].(string); name != "" {
+			called[name] = true
+		}
+	}
+	stable, _ := shadow["stable_tools"].([]interface{})
+	for i, s := range stable {
+		if name, _ := s.(string); name != "" && !called[name] {
+			report.AddWarning(CodeTargetsInvalid,
+				"stable tool '"+name+"' is not called by any tool template, so its results are never compared",
+				"/shadow/stable_tools/"+strconv.Itoa(i))
+		}
+	}
+}
+
+// validateConformance warns about conformance options of a run that is not
+// a conformance run, which ignores them.
+func (v *SemanticValidator) validateConformance(config map[string]interface{}, report *ValidationReport) {
+	if _, ok := config["conformance"]; ok && !isConformanceRun(config) {
+		report.AddWarning(CodeConformanceRunInvalid,
+			"conformance options are ignored unless run_type is conformance",
+			"/conformance")
+	}
+}
+
+// validateThinkTimes checks the workload's, the generator groups' and the
+// per-tool think times, and that paced tools are ones the workload calls.
+func (v *SemanticValidator) validateThinkTimes(config map[string]interface{}, report *ValidationReport) {
+	type thinkTime struct {
+		pointer string
+		config  interface{}
+	}
+	workload, _ := config["workload"].(map[string]interface{})
+	thinkTimes := []thinkTime{{"/workload/think_time", workload["think_time"]}}
+	groups, _ := config["generator_groups"].([]interface{})
+	for i, g := range groups {
+		group, _ := g.(map[string]interface{})
+		thinkTimes = append(thinkTimes, thinkTime{"/generator_groups/" + strconv.Itoa(i) + "/think_time", group["think_time"]})
+	}
+	perTool, _ := workload["tool_think_time"].(map[string]interface{})
+	pacedTools := make([]string, 0, len(perTool))
+	for name := range perTool {
+		pacedTools = append(pacedTools, name)
+	}
+	sort.Strings(pacedTools)
+	for _, name := range pacedTools {
+		thinkTimes = append(thinkTimes, thinkTime{"/workload/tool_think_time/" + name, perTool[name]})
+	}
+	for _, t := range thinkTimes {
+		tt, ok := t.config.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		pointer := t.pointer
+		base, _ := tt["base_ms"].(float64)
+		if jitter, _ := tt["jitter_ms"].(float64); tt["mode"] == "uniform" && jitter > base {
+			report.AddWarning(CodeThinkTimeInvalid,
+				"jitter_ms exceeds base_ms; draws below 0 count as none, so think times average more than base_ms",
+				pointer+"/jitter_ms")
+		}
+	}
+
+	tools, _ := workload["tools"].(map[string]interface{})
+	selection, _ := tools["selection"].(map[string]interface{})
+	if mode, _ := selection["mode"].(string); mode == "auto_discover" || len(perTool) == 0 {
+		return
+	}
+	called := make(map[string]bool)
+	templates, _ := tools["templates"].([]interface{})
+	for _, t := range templates {
+		template, _ := t.(map[string]interface{})
+		if name, _ := template["tool_name"].(string); name != "" {
+			called[name] = true
+		}
+	}
+	workflows, _ := workload["workflows"].([]interface{})
+	for _, w := range workflows {
+		workflow, _ := w.(map[string]interface{})
+		steps, _ := workflow["steps"].([]interface{})
+		for _, s := range steps {
+			step, _ := s.(map[string]interface{})
+			if name, _ := step["tool_name"].(string); name != "" {
+				called[name] = true
+			}
+		}
+	}
+	for _, name := range pacedTools {
+		if !called[name] {
+			report.AddWarning(CodeThinkTimeInvalid,
+				"tool '"+name+"' is not called by any tool template or workflow, so its think time never applies",
+				"/workload/tool_think_time/"+name)
+		}
+	}
+}
+
+// validateWorkflows checks that workflows and their steps have unique names,
+// that steps carry the fields their operations need, and that extractions
+// parse and are set before a step references them.
+func (v *SemanticValidator) validateWorkflows(config map[string]interface{}, report *ValidationReport) {
+	workload, _ := config["workload"].(map[string]interface{})
+	workflows, _ := workload["workflows"].([]interface{})
+	isFeedVariable := dataFeedVariables(config)
+	seen := make(map[string]bool, len(workflows))
+	for i, w := range workflows {
+		workflow, ok := w.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		path := "/workload/workflows/" + strconv.Itoa(i)
+
+		name, _ := workflow["name"].(string)
+		if !workflowNamePattern.MatchString(name) {
+			report.AddError(CodeWorkflowInvalid,
+				"invalid workflow name '"+name+"'",
+				path+"/name")
+		} else if seen[name] {
+			report.AddErrorWithRemediation(CodeWorkflowInvalid,
+				"workflow name '"+name+"' is used more than once",
+				path+"/name",
+				"Give each workflow a unique name")
+		}
+		seen[name] = true
+
+		// vars are the variables set by the steps so far.
+		vars := make(map[string]bool)
+		isVariable := func(variable string) bool {
+			if name, ok := strings.CutPrefix(variable, templating.WorkflowVarPrefix); ok {
+				return vars[name]
+			}
+			return isFeedVariable != nil && isFeedVariable(variable)
+		}
+		stepNames := make(map[string]bool)
+		steps, _ := workflow["steps"].([]interface{})
+		for j, s := range steps {
+			step, ok := s.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			stepPath := path + "/steps/" + strconv.Itoa(j)
+
+			if stepName, _ := step["name"].(string); stepName != "" {
+				if !workflowNamePattern.MatchString(stepName) {
+					report.AddError(CodeWorkflowInvalid,
+						"invalid step name '"+stepName+"'",
+						stepPath+"/name")
+				} else if stepNames[stepName] {
+					report.AddError(CodeWorkflowInvalid,
+						"step name '"+stepName+"' is used more than once in workflow '"+name+"'",
+						stepPath+"/name")
+				}
+				stepNames[stepName] = true
+			}
+
+			for operation, field := range map[string]string{"tools_call": "tool_name", "resources_read": "uri", "prompts_get": "prompt_name"} {
+				if value, _ := step[field].(string); step["operation"] == operation && value == "" {
+					report.AddError(CodeRequiredFieldMissing,
+						operation+" step requires '"+field+"' field",
+						stepPath+"/"+field)
+				}
+			}
+
+			for _, field := range []string{"tool_name", "prompt_name", "arguments", "uri"} {
+				value, ok := step[field]
+				if !ok {
+					continue
+				}
+				if err := templating.Validate(value, isVariable); err != nil {
+					report.AddErrorWithRemediation(CodeArgumentTemplateInvalid,
+						"invalid "+field+" template: "+err.Error(),
+						stepPath+"/"+field,
+						"Reference only variables extracted by earlier steps of the workflow as ${vars.<name>}")
+				}
+			}
+
+			list, _ := step["checks"].([]interface{})
+			for k, c := range list {
+				checkPath := stepPath + "/checks/" + strconv.Itoa(k)
+				data, err := json.Marshal(c)
+				if err != nil {
+					continue
+				}
+				var cfg types.CheckConfig
+				if err := json.Unmarshal(data, &cfg); err != nil {
+					report.AddError(CodeCheckInvalid, "invalid check: "+err.Error(), checkPath)
+					continue
+				}
+				if err := checks.Validate(cfg); err != nil {
+					report.AddError(CodeCheckInvalid, "invalid check: "+err.Error(), checkPath)
+				}
+			}
+
+			extract, _ := step["extract"].(map[string]interface{})
+			varNames := make([]string, 0, len(extract))
+			for varName := range extract {
+				varNames = append(varNames, varName)
+			}
+			sort.Strings(varNames)
+			for _, varName := range varNames {
+				varPath := stepPath + "/extract/" + escapePointerToken(varName)
+				if !dataFeedNamePattern.MatchString(varName) {
+					report.AddError(CodeWorkflowInvalid,
+						"invalid variable name '"+varName+"'",
+						varPath)
+				}
+				src, _ := extract[varName].(string)
+				if _, err := checks.ParsePath(src); err != nil {
+					report.AddError(CodeWorkflowInvalid,
+						"invalid extract path for '"+varName+"': "+err.Error(),
+						varPath)
+				}
+			}
+			// A step's own variables are set after it is sent.
+			for _, varName := range varNames {
+				vars[varName] = true
+			}
+		}
+	}
+}
+
+// validateGeneratorGroups checks that generator group names are unique and
+// that group operation mixes carry the fields their operations need.
+func (v *SemanticValidator) validateGeneratorGroups(config map[string]interface{}, report *ValidationReport) {
+	groups, ok := config["generator_groups"].([]interface{})
+	if !ok {
+		return
+	}
+
+	hasTemplates := false
+	if workload, ok := config["workload"].(map[string]interface{}); ok {
+		if tools, ok := workload["tools"].(map[string]interface{}); ok {
+			templates, _ := tools["templates"].([]interface{})
+			hasTemplates = len(templates) > 0
+		}
+	}
+
+	seen := make(map[string]bool, len(groups))
+	for i, g := range groups {
+		group, ok := g.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		path := "/generator_groups/" + strconv.Itoa(i)
+
+		name, _ := group["name"].(string)
+		if seen[name] {
+			report.AddErrorWithRemediation(CodeGeneratorGroupsInvalid,
+				"generator group name '"+name+"' is used more than once",
+				path+"/name",
+				"Give each generator group a unique name")
+		}
+		seen[name] = true
+
+		opMix, _ := group["operation_mix"].([]interface{})
+		for j, op := range opMix {
+			opMap, ok := op.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			opPath := path + "/operation_mix/" + strconv.Itoa(j)
+			switch opMap["operation"] {
+			case "tools_call":
+				if !hasTemplates {
+					report.AddError(CodeToolsCallRequiresTemplates,
+						"tools_call operation requires at least one tool template in workload.tools.templates",
+						opPath)
+				}
+			case "resources_read":
+				if uri, _ := opMap["uri"].(string); uri == "" {
+					report.AddError(CodeRequiredFieldMissing,
+						"resources_read operation requires 'uri' field",
+						opPath+"/uri")
+				}
+			case "prompts_get":
+				if name, _ := opMap["prompt_name"].(string); name == "" {
+					report.AddError(CodeRequiredFieldMissing,
+						"prompts_get operation requires 'prompt_name' field",
+						opPath+"/prompt_name")
+				}
+			}
+		}
+	}
+}
+
+func (v *SemanticValidator) validateTargetWithinRunAllowlist(config map[string]interface{}, report *ValidationReport) {
+	for _, target := range targetURLsFromConfig(config) {
+		v.validateURLWithinRunAllowlist(config, target, report)
+	}
+}
+
+// validateURLWithinRunAllowlist checks one of the run's target URLs against
+// environment.allowlist and the system policy.
+func (v *SemanticValidator) validateURLWithinRunAllowlist(config map[string]interface{}, target targetURL, report *ValidationReport) {
+	// Check if environment.allowlist section exists
+	env, envOk := config["environment"].(map[string]interface{})
+	if !envOk {
+		// No environment section - skip run allowlist validation
+		// (validateAllowlistRequired will handle this)
+		return
+	}
+
+	allowlistSection, allowlistOk := env["allowlist"].(map[string]interface{})
+	if !allowlistOk {
+		// No allowlist section - skip run allowlist validation
 		return
 	}
 
@@ -976,14 +2215,14 @@ func (v *SemanticValidator) validateTargetWithinRunAllowlist(config map[string]i
 		if mode == "deny_by_default" {
 			report.AddError(CodeAllowlistViolation,
 				"Target URL provided but no allowed_targets defined in environment.allowlist (default-deny)",
-				"/target/url")
+				target.pointer)
 			return
 		}
 		// If mode is not deny_by_default, skip validation
 		return
 	}
 
-	targetHost := extractHost(targetURL)
+	targetHost := extractHost(target.url)
 
 	matchesRun := false
 	if allowlistMatchesHost(targetHost, runAllowlist) {
@@ -993,7 +2232,7 @@ func (v *SemanticValidator) validateTargetWithinRunAllowlist(config map[string]i
 	if !matchesRun {
 		report.AddError(CodeAllowlistViolation,
 			"Target URL does not match run config allowlist (environment.allowlist.allowed_targets)",
-			"/target/url")
+			target.pointer)
 		return
 	}
 
@@ -1001,7 +2240,7 @@ func (v *SemanticValidator) validateTargetWithinRunAllowlist(config map[string]i
 		if !allowlistMatchesHost(targetHost, v.systemPolicy.GlobalAllowlist) {
 			report.AddError(CodeAllowlistViolation,
 				"Target URL matches run config allowlist but not system policy global allowlist",
-				"/target/url")
+				target.pointer)
 		}
 	}
 }
@@ -1070,13 +2309,6 @@ func normalizeAllowlistSuffix(value string) string {
 }
 
 func (v *SemanticValidator) validateForbiddenPatterns(config map[string]interface{}, report *ValidationReport) {
-	targetURL, ok := targetURLFromConfig(config)
-	if !ok {
-		return
-	}
-
-	targetHost := extractHost(targetURL)
-
 	var forbiddenPatterns []string
 
 	if v.systemPolicy != nil {
@@ -1093,12 +2325,15 @@ func (v *SemanticValidator) validateForbiddenPatterns(config map[string]interfac
 		}
 	}
 
-	for _, pattern := range forbiddenPatterns {
-		if matchesForbiddenPattern(targetHost, pattern) || matchesForbiddenPattern(targetURL, pattern) {
-			report.AddError(CodeForbiddenPatternMatched,
-				"Target URL matches forbidden pattern: "+pattern,
-				"/target/url")
-			return
+	for _, target := range targetURLsFromConfig(config) {
+		targetHost := extractHost(target.url)
+		for _, pattern := range forbiddenPatterns {
+			if matchesForbiddenPattern(targetHost, pattern) || matchesForbiddenPattern(target.url, pattern) {
+				report.AddError(CodeForbiddenPatternMatched,
+					"Target URL matches forbidden pattern: "+pattern,
+					target.pointer)
+				break
+			}
 		}
 	}
 }
@@ -34,68 +34,60 @@ func (v *SSRFValidator) Validate(data []byte) *ValidationReport {
 		return report
 	}
 
-	target, ok := config["target"].(map[string]interface{})
-	if !ok {
-		return report
+	for _, target := range targetURLsFromConfig(config) {
+		v.validateURL(target.url, target.pointer, report)
 	}
-
-	urlStr, ok := target["url"].(string)
-	if !ok || urlStr == "" {
-		return report
-	}
-
-	v.validateURL(urlStr, report)
 	return report
 }
 
-func (v *SSRFValidator) validateURL(urlStr string, report *ValidationReport) {
+func (v *SSRFValidator) validateURL(urlStr, pointer string, report *ValidationReport) {
 	parsed, err := url.Parse(urlStr)
 	if err != nil {
-		report.AddError(CodeSchemaViolation, "Invalid URL format", "/target/url")
+		report.AddError(CodeSchemaViolation, "Invalid URL format", pointer)
 		return
 	}
 
-	v.validateScheme(parsed, report)
-	v.validateUserInfo(parsed, report)
-	v.validateHost(parsed, report)
+	v.validateScheme(parsed, pointer, report)
+	v.validateUserInfo(parsed, pointer, report)
+	v.validateHost(parsed, pointer, report)
 }
 
-func (v *SSRFValidator) validateScheme(parsed *url.URL, report *ValidationReport) {
+func (v *SSRFValidator) validateScheme(parsed *url.URL, pointer string, report *ValidationReport) {
 	scheme := strings.ToLower(parsed.Scheme)
 	if scheme != "http" && scheme != "https" {
 		report.AddErrorWithRemediation(CodeInvalidURLScheme,
 			"Only HTTP and HTTPS schemes are allowed",
-			"/target/url",
+			pointer,
 			"Use https:// or http:// URL scheme")
 	}
 }
 
-func (v *SSRFValidator) validateUserInfo(parsed *url.URL, report *ValidationReport) {
+func (v *SSRFValidator) validateUserInfo(parsed *url.URL, pointer string, report *ValidationReport) {
 	if parsed.User != nil {
 		report.AddErrorWithRemediation(CodeUserInfoBlocked,
 			"URLs with userinfo (user:pass@host) are not allowed",
-			"/target/url",
+			pointer,
 			"Remove credentials from URL and use auth configuration instead")
 	}
 }
 
-func (v *SSRFValidator) validateHost(parsed *url.URL, report *ValidationReport) {
+func (v *SSRFValidator) validateHost(parsed *url.URL, pointer string, report *ValidationReport) {
 	host := parsed.Hostname()
 	if host == "" {
-		report.AddError(CodeSchemaViolation, "URL must have a host", "/target/url")
+		report.AddError(CodeSchemaViolation, "URL must have a host", pointer)
 		return
 	}
 
 	ip := net.ParseIP(host)
 	if ip != nil {
-		v.validateIPAddress(ip, report)
+		v.validateIPAddress(ip, pointer, report)
 		return
 	}
 
-	v.validateHostname(host, report)
+	v.validateHostname(host, pointer, report)
 }
 
-func (v *SSRFValidator) validateIPAddress(ip net.IP, report *ValidationReport) {
+func (v *SSRFValidator) validateIPAddress(ip net.IP, pointer string, report *ValidationReport) {
 	// If IP is in allowed private networks, skip all IP literal blocking
 	if v.isPrivateNetworkAllowed(ip) {
 		return
@@ -103,29 +95,29 @@ func (v *SSRFValidator) validateIPAddress(ip net.IP, report *ValidationReport) {
 
 	report.AddErrorWithRemediation(CodeIPLiteralBlocked,
 		"IP literal targets are not allowed",
-		"/target/url",
+		pointer,
 		"Use a hostname instead of an IP address")
 
 	if ip.IsLoopback() {
-		report.AddError(CodeLoopbackBlocked, "Loopback addresses are blocked", "/target/url")
+		report.AddError(CodeLoopbackBlocked, "Loopback addresses are blocked", pointer)
 	}
 
 	if ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() {
-		report.AddError(CodeLinkLocalBlocked, "Link-local addresses are blocked", "/target/url")
+		report.AddError(CodeLinkLocalBlocked, "Link-local addresses are blocked", pointer)
 	}
 
 	if ip.IsMulticast() {
-		report.AddError(CodeMulticastBlocked, "Multicast addresses are blocked", "/target/url")
+		report.AddError(CodeMulticastBlocked, "Multicast addresses are blocked", pointer)
 	}
 
 	if ip4 := ip.To4(); ip4 != nil {
-		v.validateIPv4(ip4, report)
+		v.validateIPv4(ip4, pointer, report)
 	} else {
-		v.validateIPv6(ip, report)
+		v.validateIPv6(ip, pointer, report)
 	}
 }
 
-func (v *SSRFValidator) validateIPv4(ip net.IP, report *ValidationReport) {
+func (v *SSRFValidator) validateIPv4(ip net.IP, pointer string, report *ValidationReport) {
 	blockedRanges := []struct {
 		cidr string
 		code string
@@ -145,7 +137,7 @@ func (v *SSRFValidator) validateIPv4(ip net.IP, report *ValidationReport) {
 			continue
 		}
 		if cidr.Contains(ip) {
-			report.AddError(br.code, br.msg, "/target/url")
+			report.AddError(br.code, br.msg, pointer)
 		}
 	}
 
@@ -164,14 +156,14 @@ func (v *SSRFValidator) validateIPv4(ip net.IP, report *ValidationReport) {
 			if !v.isPrivateNetworkAllowed(ip) {
 				report.AddErrorWithRemediation(CodePrivateAddressBlocked,
 					"RFC 1918 private address is blocked by default",
-					"/target/url",
+					pointer,
 					"Configure system_policy.allow_private_networks to allow specific private ranges")
 			}
 		}
 	}
 }
 
-func (v *SSRFValidator) validateIPv6(ip net.IP, report *ValidationReport) {
+func (v *SSRFValidator) validateIPv6(ip net.IP, pointer string, report *ValidationReport) {
 	blockedRanges := []struct {
 		cidr string
 		code string
@@ -193,7 +185,7 @@ func (v *SSRFValidator) validateIPv6(ip net.IP, report *ValidationReport) {
 			continue
 		}
 		if cidr.Contains(ip) {
-			report.AddError(br.code, br.msg, "/target/url")
+			report.AddError(br.code, br.msg, pointer)
 		}
 	}
 
@@ -201,11 +193,11 @@ func (v *SSRFValidator) validateIPv6(ip net.IP, report *ValidationReport) {
 		ip[4] == 0 && ip[5] == 0 && ip[6] == 0 && ip[7] == 0 &&
 		ip[8] == 0 && ip[9] == 0 && ip[10] == 0xff && ip[11] == 0xff {
 		ipv4 := ip[12:16]
-		v.validateIPv4(ipv4, report)
+		v.validateIPv4(ipv4, pointer, report)
 	}
 }
 
-func (v *SSRFValidator) validateHostname(host string, report *ValidationReport) {
+func (v *SSRFValidator) validateHostname(host string, pointer string, report *ValidationReport) {
 	lowerHost := strings.ToLower(host)
 
 	localhostPatterns := []string{
@@ -221,7 +213,7 @@ func (v *SSRFValidator) validateHostname(host string, report *ValidationReport)
 			}
 			report.AddError(CodeLocalhostBlocked,
 				"Localhost hostnames are blocked",
-				"/target/url")
+				pointer)
 			return
 		}
 	}
@@ -231,7 +223,7 @@ func (v *SSRFValidator) validateHostname(host string, report *ValidationReport)
 		strings.HasSuffix(lowerHost, ".localhost") {
 		report.AddWarning(CodeLocalhostBlocked,
 			"Hostname appears to be a local/internal address",
-			"/target/url")
+			pointer)
 	}
 }
 
@@ -254,7 +246,7 @@ func (v *SSRFValidator) isLoopbackAllowed() bool {
 }
 
 func (v *SSRFValidator) ValidateRedirectTarget(targetURL string, report *ValidationReport) {
-	v.validateURL(targetURL, report)
+	v.validateURL(targetURL, "/target/url", report)
 }
 
 type RedirectPolicy struct {
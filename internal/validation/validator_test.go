@@ -1041,6 +1041,57 @@ func TestUnifiedValidatorOpLog(t *testing.T) {
 	})
 }
 
+func TestUnifiedValidatorAcceptsEveryStage(t *testing.T) {
+	v, err := NewUnifiedValidator(DefaultSystemPolicy())
+	if err != nil {
+		t.Fatalf("Failed to create validator: %v", err)
+	}
+
+	records := []struct {
+		name     string
+		pointer  string
+		validate func(stage string) *ValidationReport
+	}{
+		{"op-log", "/stage", func(stage string) *ValidationReport {
+			data, _ := json.Marshal(map[string]interface{}{"schema_version": "op-log/v1", "stage": stage})
+			return v.ValidateOpLog(data)
+		}},
+		{"event", "/correlation/stage", func(stage string) *ValidationReport {
+			data, _ := json.Marshal(map[string]interface{}{"schema_version": "event/v1", "correlation": map[string]interface{}{"stage": stage}})
+			return v.ValidateEvent(data)
+		}},
+		{"report", "/stage_results/0/stage", func(stage string) *ValidationReport {
+			data, _ := json.Marshal(map[string]interface{}{"schema_version": "report/v1", "stage_results": []interface{}{map[string]interface{}{"stage": stage}}})
+			return v.ValidateReport(data)
+		}},
+		{"metrics-window", "/stage", func(stage string) *ValidationReport {
+			report := NewValidationReport()
+			v.schemaValidator.validateObjectWithContext(map[string]interface{}{"stage": stage},
+				v.schemaValidator.schemas["metrics-window/v1"], "", "metrics-window/v1", report)
+			return report
+		}},
+	}
+	rejects := func(report *ValidationReport, pointer string) bool {
+		for _, e := range report.Errors {
+			if e.JSONPointer == pointer {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, record := range records {
+		for _, stage := range []string{"preflight", "baseline", "ramp", "soak", "spike", "step", "custom"} {
+			if report := record.validate(stage); rejects(report, record.pointer) {
+				t.Errorf("%s: expected stage %s accepted, got %+v", record.name, stage, report.Errors)
+			}
+		}
+		if !rejects(record.validate("warmup"), record.pointer) {
+			t.Errorf("%s: expected an unknown stage rejected at %s", record.name, record.pointer)
+		}
+	}
+}
+
 func TestCorrelationValidatorBatch(t *testing.T) {
 	v := NewCorrelationValidator()
 
@@ -1195,6 +1246,26 @@ func TestSemanticValidator_AllRules(t *testing.T) {
 		}
 	})
 
+	t.Run("warmup_shorter_than_stage", func(t *testing.T) {
+		config := map[string]interface{}{
+			"stages": []interface{}{
+				map[string]interface{}{"stage": "baseline", "enabled": true, "duration_ms": 60000.0, "warmup_ms": 60000.0},
+			},
+		}
+		data, _ := json.Marshal(config)
+		report := v.Validate(data)
+		hasCode := false
+		for _, e := range report.Errors {
+			if e.Code == CodeDurationInvalid && e.JSONPointer == "/stages/0/warmup_ms" {
+				hasCode = true
+				break
+			}
+		}
+		if !hasCode {
+			t.Error("Expected DURATION_INVALID error for warmup_ms")
+		}
+	})
+
 	t.Run("load_nonnegative", func(t *testing.T) {
 		config := map[string]interface{}{
 			"stages": []interface{}{
@@ -1264,6 +1335,50 @@ func TestSemanticValidator_AllRules(t *testing.T) {
 		}
 	})
 
+	t.Run("tools_call_auto_discover", func(t *testing.T) {
+		config := map[string]interface{}{
+			"workload": map[string]interface{}{
+				"operation_mix": []interface{}{
+					map[string]interface{}{"operation": "tools_call", "weight": 1.0},
+				},
+				"tools": map[string]interface{}{
+					"selection": map[string]interface{}{"mode": "auto_discover"},
+					"templates": []interface{}{},
+				},
+			},
+		}
+		data, _ := json.Marshal(config)
+		report := v.Validate(data)
+		for _, e := range report.Errors {
+			if e.Code == CodeToolsCallRequiresTemplates {
+				t.Error("Expected no TOOLS_CALL_REQUIRES_TEMPLATES error with auto discovery")
+			}
+		}
+	})
+
+	t.Run("churn_interval", func(t *testing.T) {
+		for _, tc := range []struct {
+			policy map[string]interface{}
+			want   string
+		}{
+			{map[string]interface{}{"mode": "reuse", "churn_interval_ms": 1000.0}, CodeChurnIntervalMsInvalid},
+			{map[string]interface{}{"mode": "churn", "churn_interval_ms": 1000.0, "churn_interval_ops": 5.0}, CodeChurnIntervalMsInvalid},
+			{map[string]interface{}{"mode": "churn", "churn_interval_ms": 1000.0}, ""},
+		} {
+			data, _ := json.Marshal(map[string]interface{}{"session_policy": tc.policy})
+			report := v.Validate(data)
+			got := ""
+			for _, e := range report.Errors {
+				if e.Code == CodeChurnIntervalMsInvalid || e.Code == CodeChurnIntervalOpsInvalid {
+					got = e.Code
+				}
+			}
+			if got != tc.want {
+				t.Errorf("session_policy %v: expected error %q, got %q", tc.policy, tc.want, got)
+			}
+		}
+	})
+
 	t.Run("redirect_policy_required", func(t *testing.T) {
 		config := map[string]interface{}{
 			"target": map[string]interface{}{
@@ -1398,4 +1513,991 @@ func TestSemanticValidator_AllRules(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("generator_groups_unique_names", func(t *testing.T) {
+		config := map[string]interface{}{
+			"generator_groups": []interface{}{
+				map[string]interface{}{"name": "steady", "weight": 1.0},
+				map[string]interface{}{"name": "steady", "weight": 2.0},
+			},
+		}
+		data, _ := json.Marshal(config)
+		report := v.Validate(data)
+		hasCode := false
+		for _, e := range report.Errors {
+			if e.Code == CodeGeneratorGroupsInvalid && e.JSONPointer == "/generator_groups/1/name" {
+				hasCode = true
+				break
+			}
+		}
+		if !hasCode {
+			t.Error("Expected GENERATOR_GROUPS_INVALID error for duplicate name")
+		}
+	})
+
+	t.Run("generator_group_op_mix_fields", func(t *testing.T) {
+		config := map[string]interface{}{
+			"generator_groups": []interface{}{
+				map[string]interface{}{
+					"name":   "readers",
+					"weight": 1.0,
+					"operation_mix": []interface{}{
+						map[string]interface{}{"operation": "resources_read", "weight": 1.0},
+					},
+				},
+			},
+		}
+		data, _ := json.Marshal(config)
+		report := v.Validate(data)
+		hasCode := false
+		for _, e := range report.Errors {
+			if e.Code == CodeRequiredFieldMissing && e.JSONPointer == "/generator_groups/0/operation_mix/0/uri" {
+				hasCode = true
+				break
+			}
+		}
+		if !hasCode {
+			t.Error("Expected REQUIRED_FIELD_MISSING error for group resources_read without uri")
+		}
+	})
+}
+
+func TestSemanticValidator_SoakStages(t *testing.T) {
+	v := NewSemanticValidator(DefaultSystemPolicy())
+
+	hasIssue := func(issues []ValidationIssue, code, pointer string) bool {
+		for _, issue := range issues {
+			if issue.Code == code && issue.JSONPointer == pointer {
+				return true
+			}
+		}
+		return false
+	}
+	stopCondition := func(metric string, windowMs float64) interface{} {
+		return map[string]interface{}{"id": "sc_" + metric, "metric": metric, "window_ms": windowMs}
+	}
+	stagesWithSoak := func(soak map[string]interface{}) map[string]interface{} {
+		soak["stage"] = "soak"
+		soak["enabled"] = true
+		soak["duration_ms"] = 14400000.0
+		return map[string]interface{}{
+			"stages": []interface{}{
+				map[string]interface{}{"stage": "preflight", "enabled": true, "duration_ms": 60000.0},
+				map[string]interface{}{"stage": "ramp", "enabled": true, "duration_ms": 60000.0, "stop_conditions": []interface{}{stopCondition("error_rate", 30000)}},
+				soak,
+			},
+		}
+	}
+
+	t.Run("stop_conditions_required", func(t *testing.T) {
+		data, _ := json.Marshal(stagesWithSoak(map[string]interface{}{"stop_conditions": []interface{}{}}))
+		report := v.Validate(data)
+		if !hasIssue(report.Errors, CodeStopConditionsRequired, "/stages/2/stop_conditions") {
+			t.Errorf("Expected STOP_CONDITIONS_REQUIRED for soak stage, got %v", report.Errors)
+		}
+	})
+
+	t.Run("must_follow_ramp", func(t *testing.T) {
+		config := map[string]interface{}{
+			"stages": []interface{}{
+				map[string]interface{}{"stage": "preflight", "enabled": true, "duration_ms": 60000.0},
+				map[string]interface{}{"stage": "soak", "enabled": true, "duration_ms": 60000.0, "stop_conditions": []interface{}{stopCondition("error_rate", 30000)}},
+				map[string]interface{}{"stage": "ramp", "enabled": true, "duration_ms": 60000.0, "stop_conditions": []interface{}{stopCondition("error_rate", 30000)}},
+			},
+		}
+		data, _ := json.Marshal(config)
+		report := v.Validate(data)
+		if !hasIssue(report.Errors, CodeInvalidStageOrder, "/stages/1") {
+			t.Errorf("Expected INVALID_STAGE_ORDER for soak before ramp, got %v", report.Errors)
+		}
+	})
+
+	t.Run("trend_condition_recommended", func(t *testing.T) {
+		data, _ := json.Marshal(stagesWithSoak(map[string]interface{}{"stop_conditions": []interface{}{stopCondition("error_rate", 30000)}}))
+		report := v.Validate(data)
+		if !hasIssue(report.Warnings, CodeSoakTrendConditionMissing, "/stages/2/stop_conditions") {
+			t.Errorf("Expected SOAK_TREND_CONDITION_MISSING warning, got %v", report.Warnings)
+		}
+
+		data, _ = json.Marshal(stagesWithSoak(map[string]interface{}{"stop_conditions": []interface{}{stopCondition("latency_p99_slope_ms_per_hour", 7200000)}}))
+		report = v.Validate(data)
+		if hasIssue(report.Warnings, CodeSoakTrendConditionMissing, "/stages/2/stop_conditions") {
+			t.Error("Should not warn when a trend condition is present")
+		}
+		if hasIssue(report.Errors, CodeStopConditionInvalid, "/stages/2/stop_conditions/0/window_ms") {
+			t.Error("Should accept a long window for a trend metric")
+		}
+	})
+
+	t.Run("long_window_requires_trend_metric", func(t *testing.T) {
+		data, _ := json.Marshal(stagesWithSoak(map[string]interface{}{"stop_conditions": []interface{}{stopCondition("latency_p95_ms", 7200000)}}))
+		report := v.Validate(data)
+		if !hasIssue(report.Errors, CodeStopConditionInvalid, "/stages/2/stop_conditions/0/window_ms") {
+			t.Errorf("Expected STOP_CONDITION_INVALID for a 2h latency_p95_ms window, got %v", report.Errors)
+		}
+	})
+
+	t.Run("rss_slope_requires_pair_key", func(t *testing.T) {
+		config := stagesWithSoak(map[string]interface{}{"stop_conditions": []interface{}{stopCondition("server_rss_slope_mb_per_hour", 3600000)}})
+		data, _ := json.Marshal(config)
+		report := v.Validate(data)
+		if !hasIssue(report.Errors, CodeStopConditionInvalid, "/stages/2/stop_conditions/0/metric") {
+			t.Errorf("Expected STOP_CONDITION_INVALID without a pair key, got %v", report.Errors)
+		}
+
+		config["server_telemetry"] = map[string]interface{}{"pair_key": "my-server"}
+		data, _ = json.Marshal(config)
+		report = v.Validate(data)
+		if hasIssue(report.Errors, CodeStopConditionInvalid, "/stages/2/stop_conditions/0/metric") {
+			t.Error("Should accept server_rss_slope_mb_per_hour with a pair key")
+		}
+	})
+
+	t.Run("composite_terms", func(t *testing.T) {
+		term := func(metric string) interface{} {
+			return map[string]interface{}{"metric": metric, "comparator": ">", "threshold": 1.0}
+		}
+		composite := map[string]interface{}{
+			"id":        "sc_composite",
+			"window_ms": 60000.0,
+			"all": []interface{}{
+				term("error_rate"),
+				map[string]interface{}{"any": []interface{}{term("server_rss_mb"), map[string]interface{}{"metric": "consecutive_errors"}}},
+			},
+		}
+		data, _ := json.Marshal(stagesWithSoak(map[string]interface{}{"stop_conditions": []interface{}{composite}}))
+		report := v.Validate(data)
+		for _, pointer := range []string{
+			"/stages/2/stop_conditions/0/all/1/any/0/metric",
+			"/stages/2/stop_conditions/0/all/1/any/1/comparator",
+			"/stages/2/stop_conditions/0/all/1/any/1/threshold",
+		} {
+			if !hasIssue(report.Errors, CodeStopConditionInvalid, pointer) {
+				t.Errorf("Expected STOP_CONDITION_INVALID at %s, got %v", pointer, report.Errors)
+			}
+		}
+		if hasIssue(report.Errors, CodeStopConditionInvalid, "/stages/2/stop_conditions/0/all/0/metric") {
+			t.Error("Should accept a complete error_rate term")
+		}
+
+		composite["any"] = []interface{}{term("error_rate")}
+		composite["window_ms"] = 7200000.0
+		data, _ = json.Marshal(stagesWithSoak(map[string]interface{}{"stop_conditions": []interface{}{composite}}))
+		report = v.Validate(data)
+		if !hasIssue(report.Errors, CodeStopConditionInvalid, "/stages/2/stop_conditions/0") {
+			t.Errorf("Expected STOP_CONDITION_INVALID for both all and any, got %v", report.Errors)
+		}
+		if !hasIssue(report.Errors, CodeStopConditionInvalid, "/stages/2/stop_conditions/0/window_ms") {
+			t.Errorf("Expected STOP_CONDITION_INVALID for a 2h window over buffered terms, got %v", report.Errors)
+		}
+
+		data, _ = json.Marshal(stagesWithSoak(map[string]interface{}{"stop_conditions": []interface{}{map[string]interface{}{"id": "sc_empty", "window_ms": 60000.0}}}))
+		report = v.Validate(data)
+		if !hasIssue(report.Errors, CodeStopConditionInvalid, "/stages/2/stop_conditions/0/metric") {
+			t.Errorf("Expected STOP_CONDITION_INVALID without a metric or terms, got %v", report.Errors)
+		}
+	})
+
+	t.Run("checkpoint_interval", func(t *testing.T) {
+		config := stagesWithSoak(map[string]interface{}{
+			"checkpoint_interval_ms": 86400000.0,
+			"stop_conditions":        []interface{}{stopCondition("latency_p99_slope_ms_per_hour", 3600000)},
+		})
+		config["stages"].([]interface{})[1].(map[string]interface{})["checkpoint_interval_ms"] = 60000.0
+		data, _ := json.Marshal(config)
+		report := v.Validate(data)
+		if !hasIssue(report.Warnings, CodeCheckpointIntervalIgnored, "/stages/1/checkpoint_interval_ms") {
+			t.Errorf("Expected CHECKPOINT_INTERVAL_IGNORED for the ramp stage, got %v", report.Warnings)
+		}
+		if !hasIssue(report.Warnings, CodeCheckpointIntervalIgnored, "/stages/2/checkpoint_interval_ms") {
+			t.Errorf("Expected CHECKPOINT_INTERVAL_IGNORED for an interval longer than the stage, got %v", report.Warnings)
+		}
+	})
+}
+
+func TestSemanticValidator_LoadProfiles(t *testing.T) {
+	v := NewSemanticValidator(DefaultSystemPolicy())
+
+	hasIssue := func(issues []ValidationIssue, code, pointer string) bool {
+		for _, issue := range issues {
+			if issue.Code == code && issue.JSONPointer == pointer {
+				return true
+			}
+		}
+		return false
+	}
+	withStage := func(stage map[string]interface{}) map[string]interface{} {
+		stage["enabled"] = true
+		stage["stop_conditions"] = []interface{}{map[string]interface{}{"id": "sc", "metric": "error_rate"}}
+		return map[string]interface{}{
+			"safety": map[string]interface{}{"hard_caps": map[string]interface{}{"max_vus": 100.0}},
+			"stages": []interface{}{
+				map[string]interface{}{"stage": "preflight", "enabled": true, "duration_ms": 60000.0},
+				map[string]interface{}{"stage": "ramp", "enabled": true, "duration_ms": 60000.0},
+				stage,
+			},
+		}
+	}
+
+	t.Run("step_requires_steps", func(t *testing.T) {
+		data, _ := json.Marshal(withStage(map[string]interface{}{"stage": "step", "duration_ms": 60000.0}))
+		report := v.Validate(data)
+		if !hasIssue(report.Errors, CodeLoadProfileInvalid, "/stages/2/steps") {
+			t.Errorf("Expected LOAD_PROFILE_INVALID for a step stage without steps, got %v", report.Errors)
+		}
+	})
+
+	t.Run("step_warnings", func(t *testing.T) {
+		data, _ := json.Marshal(withStage(map[string]interface{}{
+			"stage":       "step",
+			"duration_ms": 60000.0,
+			"steps": []interface{}{
+				map[string]interface{}{"target_vus": 50.0, "hold_ms": 30000.0},
+				map[string]interface{}{"target_vus": 200.0, "hold_ms": 60000.0},
+			},
+		}))
+		report := v.Validate(data)
+		if hasIssue(report.Errors, CodeLoadProfileInvalid, "/stages/2/steps") {
+			t.Errorf("Should accept a step stage with steps, got %v", report.Errors)
+		}
+		if !hasIssue(report.Warnings, CodeLoadProfileInvalid, "/stages/2/steps/1/target_vus") {
+			t.Errorf("Expected a warning for a step above max_vus, got %v", report.Warnings)
+		}
+		if !hasIssue(report.Warnings, CodeLoadProfileInvalid, "/stages/2/steps") {
+			t.Errorf("Expected a warning for steps longer than the stage, got %v", report.Warnings)
+		}
+	})
+
+	t.Run("spike_hold_shorter_than_stage", func(t *testing.T) {
+		data, _ := json.Marshal(withStage(map[string]interface{}{
+			"stage":       "spike",
+			"duration_ms": 60000.0,
+			"spike":       map[string]interface{}{"multiplier": 3.0, "hold_ms": 60000.0},
+		}))
+		report := v.Validate(data)
+		if !hasIssue(report.Errors, CodeLoadProfileInvalid, "/stages/2/spike/hold_ms") {
+			t.Errorf("Expected LOAD_PROFILE_INVALID for a spike as long as the stage, got %v", report.Errors)
+		}
+	})
+
+	t.Run("profile_on_other_stage", func(t *testing.T) {
+		data, _ := json.Marshal(withStage(map[string]interface{}{
+			"stage":       "soak",
+			"duration_ms": 60000.0,
+			"spike":       map[string]interface{}{"multiplier": 3.0},
+		}))
+		report := v.Validate(data)
+		if !hasIssue(report.Warnings, CodeLoadProfileInvalid, "/stages/2/spike") {
+			t.Errorf("Expected a warning for spike on a soak stage, got %v", report.Warnings)
+		}
+	})
+
+	t.Run("must_follow_ramp", func(t *testing.T) {
+		config := map[string]interface{}{
+			"stages": []interface{}{
+				map[string]interface{}{"stage": "preflight", "enabled": true, "duration_ms": 60000.0},
+				map[string]interface{}{"stage": "spike", "enabled": true, "duration_ms": 60000.0},
+				map[string]interface{}{"stage": "ramp", "enabled": true, "duration_ms": 60000.0},
+			},
+		}
+		data, _ := json.Marshal(config)
+		report := v.Validate(data)
+		if !hasIssue(report.Errors, CodeInvalidStageOrder, "/stages/1") {
+			t.Errorf("Expected INVALID_STAGE_ORDER for spike before ramp, got %v", report.Errors)
+		}
+		if !hasIssue(report.Errors, CodeStopConditionsRequired, "/stages/1/stop_conditions") {
+			t.Errorf("Expected STOP_CONDITIONS_REQUIRED for spike stage, got %v", report.Errors)
+		}
+	})
+
+	t.Run("adaptive_ramp", func(t *testing.T) {
+		ramp := map[string]interface{}{"mode": "adaptive", "step_every_ms": 30000.0, "step_vus": 10.0}
+		config := withStage(map[string]interface{}{"stage": "ramp", "duration_ms": 600000.0, "ramp": ramp})
+		data, _ := json.Marshal(config)
+		report := v.Validate(data)
+		if !hasIssue(report.Errors, CodeAdaptiveRampInvalid, "/stages/2/ramp/guardrails") {
+			t.Errorf("Expected ADAPTIVE_RAMP_INVALID without guardrails, got %v", report.Errors)
+		}
+
+		ramp["guardrails"] = []interface{}{
+			map[string]interface{}{"metric": "error_rate", "comparator": ">", "threshold": 0.01},
+			map[string]interface{}{"metric": "latency_p95_slope_ms_per_hour", "comparator": ">", "threshold": 100.0},
+		}
+		ramp["step_vus"] = 0.0
+		config["stages"].([]interface{})[2].(map[string]interface{})["load"] = map[string]interface{}{"arrival": map[string]interface{}{"rate_per_sec": 10.0}}
+		data, _ = json.Marshal(config)
+		report = v.Validate(data)
+		for _, pointer := range []string{"/stages/2/ramp/step_vus", "/stages/2/load/arrival", "/stages/2/ramp/guardrails/1"} {
+			if !hasIssue(report.Errors, CodeAdaptiveRampInvalid, pointer) {
+				t.Errorf("Expected ADAPTIVE_RAMP_INVALID at %s, got %v", pointer, report.Errors)
+			}
+		}
+		if hasIssue(report.Errors, CodeAdaptiveRampInvalid, "/stages/2/ramp/guardrails/0") {
+			t.Error("Should accept an error_rate guardrail")
+		}
+
+		data, _ = json.Marshal(withStage(map[string]interface{}{"stage": "soak", "duration_ms": 60000.0, "ramp": map[string]interface{}{"mode": "adaptive"}}))
+		report = v.Validate(data)
+		if !hasIssue(report.Errors, CodeAdaptiveRampInvalid, "/stages/2/ramp/mode") {
+			t.Errorf("Expected ADAPTIVE_RAMP_INVALID for an adaptive soak stage, got %v", report.Errors)
+		}
+	})
+}
+
+func TestSemanticValidator_ArrivalRates(t *testing.T) {
+	v := NewSemanticValidator(DefaultSystemPolicy())
+
+	hasIssue := func(issues []ValidationIssue, code, pointer string) bool {
+		for _, issue := range issues {
+			if issue.Code == code && issue.JSONPointer == pointer {
+				return true
+			}
+		}
+		return false
+	}
+	withLoad := func(stageType string, load map[string]interface{}) []byte {
+		data, _ := json.Marshal(map[string]interface{}{
+			"safety": map[string]interface{}{"hard_caps": map[string]interface{}{"max_vus": 100.0, "max_rps": 500.0}},
+			"stages": []interface{}{
+				map[string]interface{}{"stage": "preflight", "enabled": true, "duration_ms": 60000.0},
+				map[string]interface{}{"stage": "ramp", "enabled": true, "duration_ms": 60000.0},
+				map[string]interface{}{"stage": stageType, "enabled": true, "duration_ms": 60000.0, "load": load},
+			},
+		})
+		return data
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		report := v.Validate(withLoad("soak", map[string]interface{}{
+			"target_vus": 10.0,
+			"arrival":    map[string]interface{}{"rate_rps": 200.0, "schedule": "poisson"},
+		}))
+		if hasIssue(report.Errors, CodeLoadProfileInvalid, "/stages/2/load/target_vus") || hasIssue(report.Errors, CodeCapsInconsistent, "/stages/2/load/arrival/rate_rps") {
+			t.Errorf("Should accept an arrival rate within caps, got %v", report.Errors)
+		}
+	})
+
+	t.Run("needs_sessions", func(t *testing.T) {
+		report := v.Validate(withLoad("soak", map[string]interface{}{
+			"target_vus": 0.0,
+			"arrival":    map[string]interface{}{"rate_rps": 200.0},
+		}))
+		if !hasIssue(report.Errors, CodeLoadProfileInvalid, "/stages/2/load/target_vus") {
+			t.Errorf("Expected LOAD_PROFILE_INVALID for arrivals without sessions, got %v", report.Errors)
+		}
+	})
+
+	t.Run("exceeds_max_rps", func(t *testing.T) {
+		report := v.Validate(withLoad("soak", map[string]interface{}{
+			"target_vus": 10.0,
+			"arrival":    map[string]interface{}{"rate_rps": 1000.0},
+		}))
+		if !hasIssue(report.Errors, CodeCapsInconsistent, "/stages/2/load/arrival/rate_rps") {
+			t.Errorf("Expected CAPS_INCONSISTENT for a rate above max_rps, got %v", report.Errors)
+		}
+	})
+
+	t.Run("plateau_stage_warning", func(t *testing.T) {
+		report := v.Validate(withLoad("spike", map[string]interface{}{
+			"target_vus": 0.0,
+			"arrival":    map[string]interface{}{"rate_rps": 100.0},
+		}))
+		if !hasIssue(report.Warnings, CodeLoadProfileInvalid, "/stages/2/load/arrival") {
+			t.Errorf("Expected a warning for arrivals on a spike stage, got %v", report.Warnings)
+		}
+		if hasIssue(report.Errors, CodeLoadProfileInvalid, "/stages/2/load/target_vus") {
+			t.Errorf("Spike stages take their sessions from the plateaus, got %v", report.Errors)
+		}
+	})
+}
+
+func TestSemanticValidator_ArgumentTemplates(t *testing.T) {
+	v := NewSemanticValidator(DefaultSystemPolicy())
+
+	hasIssue := func(issues []ValidationIssue, pointer string) bool {
+		for _, issue := range issues {
+			if issue.Code == CodeArgumentTemplateInvalid && issue.JSONPointer == pointer {
+				return true
+			}
+		}
+		return false
+	}
+
+	data, _ := json.Marshal(map[string]interface{}{
+		"workload": map[string]interface{}{
+			"tools": map[string]interface{}{
+				"templates": []interface{}{
+					map[string]interface{}{"tool_name": "echo", "arguments": map[string]interface{}{"id": "${uuid()}", "who": "${vu_id}"}},
+					map[string]interface{}{"tool_name": "echo", "arguments": map[string]interface{}{"n": "${randInt(1)}"}},
+				},
+			},
+			"operation_mix": []interface{}{
+				map[string]interface{}{"operation": "prompts_get", "prompt_name": "p", "arguments": map[string]interface{}{"text": "${nope()}"}},
+			},
+		},
+		"generator_groups": []interface{}{
+			map[string]interface{}{"name": "g", "operation_mix": []interface{}{
+				map[string]interface{}{"operation": "tools_call", "arguments": map[string]interface{}{"x": "${missing"}},
+			}},
+		},
+	})
+	report := v.Validate(data)
+
+	if hasIssue(report.Errors, "/workload/tools/templates/0/arguments") {
+		t.Errorf("Valid template should pass, got %v", report.Errors)
+	}
+	for _, pointer := range []string{
+		"/workload/tools/templates/1/arguments",
+		"/workload/operation_mix/0/arguments",
+		"/generator_groups/0/operation_mix/0/arguments",
+	} {
+		if !hasIssue(report.Errors, pointer) {
+			t.Errorf("Expected ARGUMENT_TEMPLATE_INVALID at %s, got %v", pointer, report.Errors)
+		}
+	}
+}
+
+func TestSemanticValidator_DataFeeds(t *testing.T) {
+	v := NewSemanticValidator(DefaultSystemPolicy())
+
+	hasIssue := func(issues []ValidationIssue, code, pointer string) bool {
+		for _, issue := range issues {
+			if issue.Code == code && issue.JSONPointer == pointer {
+				return true
+			}
+		}
+		return false
+	}
+
+	data, _ := json.Marshal(map[string]interface{}{
+		"target": map[string]interface{}{
+			"headers": map[string]interface{}{"Authorization": "Bearer ${feed.users.token}", "X-Bad": "${feed.users.nope}"},
+		},
+		"stages": []interface{}{
+			map[string]interface{}{"stage": "baseline", "enabled": true, "load": map[string]interface{}{"target_vus": 3}},
+		},
+		"workload": map[string]interface{}{
+			"data_feeds": []interface{}{
+				map[string]interface{}{"name": "users", "format": "csv", "data": "email,token\na@example.com,t1\n"},
+				map[string]interface{}{"name": "ids", "format": "jsonl", "distribution": "unique", "data": "{\"id\": 1}\n{\"id\": 2}\n"},
+				map[string]interface{}{"name": "users", "format": "csv", "data": "a\n1\n"},
+				map[string]interface{}{"name": "bad-name", "format": "jsonl", "data": "not json\n"},
+				map[string]interface{}{"name": "local", "format": "csv", "path": "users.csv"},
+			},
+			"operation_mix": []interface{}{
+				map[string]interface{}{"operation": "tools_call", "tool_name": "t", "arguments": map[string]interface{}{"email": "${feed.users.email}", "id": "${feed.ids.id}"}},
+				map[string]interface{}{"operation": "resources_read", "uri": "file:///${feed.missing.id}"},
+			},
+		},
+	})
+	report := v.Validate(data)
+
+	for _, pointer := range []string{
+		"/workload/data_feeds/1/distribution",
+		"/workload/data_feeds/2/name",
+		"/workload/data_feeds/3/name",
+		"/workload/data_feeds/3/data",
+		"/workload/data_feeds/4/path",
+	} {
+		if !hasIssue(report.Errors, CodeDataFeedInvalid, pointer) {
+			t.Errorf("Expected DATA_FEED_INVALID at %s, got %v", pointer, report.Errors)
+		}
+	}
+	for _, pointer := range []string{"/target/headers/X-Bad", "/workload/operation_mix/1/uri"} {
+		if !hasIssue(report.Errors, CodeArgumentTemplateInvalid, pointer) {
+			t.Errorf("Expected ARGUMENT_TEMPLATE_INVALID at %s, got %v", pointer, report.Errors)
+		}
+	}
+	for _, pointer := range []string{"/target/headers/Authorization", "/workload/operation_mix/0/arguments"} {
+		if hasIssue(report.Errors, CodeArgumentTemplateInvalid, pointer) {
+			t.Errorf("Feed references should pass at %s, got %v", pointer, report.Errors)
+		}
+	}
+}
+
+func TestSemanticValidator_Checks(t *testing.T) {
+	v := NewSemanticValidator(DefaultSystemPolicy())
+
+	hasIssue := func(issues []ValidationIssue, pointer string) bool {
+		for _, issue := range issues {
+			if issue.Code == CodeCheckInvalid && issue.JSONPointer == pointer {
+				return true
+			}
+		}
+		return false
+	}
+
+	data, _ := json.Marshal(map[string]interface{}{
+		"workload": map[string]interface{}{
+			"tools": map[string]interface{}{
+				"templates": []interface{}{
+					map[string]interface{}{"template_id": "t", "tool_name": "t", "weight": 1, "checks": []interface{}{
+						map[string]interface{}{"regex": "("},
+					}},
+				},
+			},
+			"operation_mix": []interface{}{
+				map[string]interface{}{"operation": "tools_call", "tool_name": "t", "weight": 1, "checks": []interface{}{
+					map[string]interface{}{"name": "ok", "jsonpath": "$.content[0].text", "matches": "^ok"},
+					map[string]interface{}{"is_error": false},
+					map[string]interface{}{"jsonpath": "content"},
+					map[string]interface{}{"max_latency_ms": 100, "regex": "x"},
+				}},
+			},
+		},
+		"generator_groups": []interface{}{
+			map[string]interface{}{"operation_mix": []interface{}{
+				map[string]interface{}{"operation": "tools_call", "checks": []interface{}{
+					map[string]interface{}{"min_result_bytes": 10, "max_result_bytes": 5},
+				}},
+			}},
+		},
+	})
+	report := v.Validate(data)
+
+	for _, pointer := range []string{
+		"/workload/tools/templates/0/checks/0",
+		"/workload/operation_mix/0/checks/2",
+		"/workload/operation_mix/0/checks/3",
+		"/generator_groups/0/operation_mix/0/checks/0",
+	} {
+		if !hasIssue(report.Errors, pointer) {
+			t.Errorf("Expected CHECK_INVALID at %s, got %v", pointer, report.Errors)
+		}
+	}
+	for _, pointer := range []string{"/workload/operation_mix/0/checks/0", "/workload/operation_mix/0/checks/1"} {
+		if hasIssue(report.Errors, pointer) {
+			t.Errorf("Valid check rejected at %s, got %v", pointer, report.Errors)
+		}
+	}
+}
+
+func TestSemanticValidator_SLOs(t *testing.T) {
+	v := NewSemanticValidator(DefaultSystemPolicy())
+
+	data, _ := json.Marshal(map[string]interface{}{
+		"slos": []interface{}{
+			map[string]interface{}{"objective": "p95_latency_ms < 500"},
+			map[string]interface{}{"objective": "error_rate < 1%", "tool_name": "search"},
+			map[string]interface{}{"objective": "latency < 500"},
+			map[string]interface{}{"objective": "rps > 100", "operation": "ping"},
+		},
+	})
+	report := v.Validate(data)
+
+	var pointers []string
+	for _, issue := range report.Errors {
+		if issue.Code == CodeSLOInvalid {
+			pointers = append(pointers, issue.JSONPointer)
+		}
+	}
+	if len(pointers) != 2 || pointers[0] != "/slos/2" || pointers[1] != "/slos/3" {
+		t.Errorf("expected SLO_INVALID at /slos/2 and /slos/3, got %v", pointers)
+	}
+}
+
+func TestSemanticValidator_LatencyBuckets(t *testing.T) {
+	v := NewSemanticValidator(DefaultSystemPolicy())
+
+	validate := func(buckets map[string]interface{}) bool {
+		data, _ := json.Marshal(map[string]interface{}{
+			"reporting": map[string]interface{}{"latency_buckets": buckets},
+		})
+		for _, issue := range v.Validate(data).Errors {
+			if issue.Code == CodeLatencyBucketsInvalid && issue.JSONPointer == "/reporting/latency_buckets" {
+				return false
+			}
+		}
+		return true
+	}
+
+	if !validate(map[string]interface{}{
+		"boundaries_ms": []interface{}{50, 100, 250},
+		"deadlines":     []interface{}{map[string]interface{}{"tool_name": "search", "deadline_ms": 200}},
+	}) {
+		t.Error("expected valid latency buckets to be accepted")
+	}
+	if validate(map[string]interface{}{"boundaries_ms": []interface{}{100, 50}}) {
+		t.Error("expected LATENCY_BUCKETS_INVALID for decreasing boundaries")
+	}
+	if validate(map[string]interface{}{
+		"deadlines": []interface{}{map[string]interface{}{"operation": "tools_call", "tool_name": "search", "deadline_ms": 200}},
+	}) {
+		t.Error("expected LATENCY_BUCKETS_INVALID for a deadline scoped to both an operation and a tool")
+	}
+}
+
+func TestSemanticValidator_TargetAuth(t *testing.T) {
+	v := NewSemanticValidator(DefaultSystemPolicy())
+
+	validate := func(oauth2 map[string]interface{}) *ValidationReport {
+		auth := map[string]interface{}{"type": "oauth2_client_credentials"}
+		if oauth2 != nil {
+			auth["oauth2"] = oauth2
+		}
+		data, _ := json.Marshal(map[string]interface{}{"target": map[string]interface{}{"auth": auth}})
+		return v.Validate(data)
+	}
+	hasIssue := func(issues []ValidationIssue, code, pointer string) bool {
+		for _, issue := range issues {
+			if issue.Code == code && issue.JSONPointer == pointer {
+				return true
+			}
+		}
+		return false
+	}
+
+	report := validate(nil)
+	if !hasIssue(report.Errors, CodeRequiredFieldMissing, "/target/auth/oauth2") {
+		t.Errorf("expected a missing oauth2 block to be an error, got %+v", report.Errors)
+	}
+
+	report = validate(map[string]interface{}{"token_url": "idp.example.com/token", "client_secret_ref": "env://MCPDRILL_SECRET"})
+	if !hasIssue(report.Errors, CodeAuthInvalid, "/target/auth/oauth2/token_url") {
+		t.Errorf("expected a relative token_url to be an error, got %+v", report.Errors)
+	}
+
+	report = validate(map[string]interface{}{"token_url": "http://idp.example.com/token", "client_secret_ref": "env://OTHER_SECRET"})
+	if !hasIssue(report.Warnings, CodeAuthInvalid, "/target/auth/oauth2/token_url") {
+		t.Errorf("expected an http token_url to warn, got %+v", report.Warnings)
+	}
+	if !hasIssue(report.Errors, CodeSecretRefNotAllowed, "/target/auth/oauth2/client_secret_ref") {
+		t.Errorf("expected a disallowed client_secret_ref to be an error, got %+v", report.Errors)
+	}
+}
+
+func TestSemanticValidator_Targets(t *testing.T) {
+	policy := &SystemPolicy{
+		GlobalAllowlist: []AllowlistEntry{{Kind: "suffix", Value: ".staging.example.com"}},
+	}
+	v := NewSemanticValidator(policy)
+
+	hasIssue := func(issues []ValidationIssue, code, pointer string) bool {
+		for _, issue := range issues {
+			if issue.Code == code && issue.JSONPointer == pointer {
+				return true
+			}
+		}
+		return false
+	}
+
+	data, _ := json.Marshal(map[string]interface{}{
+		"target": map[string]interface{}{"url": "https://a.staging.example.com/mcp"},
+		"targets": []interface{}{
+			map[string]interface{}{"name": "a", "weight": 1.0, "url": "https://a.staging.example.com/mcp"},
+			map[string]interface{}{"name": "a", "weight": 1.0, "url": "https://production.example.com/mcp",
+				"auth": map[string]interface{}{"type": "oauth2_client_credentials"}},
+		},
+		"environment": map[string]interface{}{
+			"allowlist": map[string]interface{}{
+				"mode":            "deny_by_default",
+				"allowed_targets": []interface{}{map[string]interface{}{"kind": "suffix", "value": ".staging.example.com"}},
+			},
+		},
+	})
+	report := v.Validate(data)
+	if !hasIssue(report.Errors, CodeTargetsInvalid, "/targets/1/name") {
+		t.Errorf("expected a duplicate target name to be an error, got %+v", report.Errors)
+	}
+	if !hasIssue(report.Errors, CodeAllowlistViolation, "/targets/1/url") {
+		t.Errorf("expected the second target's URL to violate the allowlist, got %+v", report.Errors)
+	}
+	if hasIssue(report.Errors, CodeAllowlistViolation, "/targets/0/url") || hasIssue(report.Errors, CodeAllowlistViolation, "/target/url") {
+		t.Errorf("expected allowlisted URLs to pass, got %+v", report.Errors)
+	}
+	if !hasIssue(report.Errors, CodeRequiredFieldMissing, "/targets/1/auth/oauth2") {
+		t.Errorf("expected the second target's auth to be checked, got %+v", report.Errors)
+	}
+
+	ssrf := NewSSRFValidator(nil)
+	data, _ = json.Marshal(map[string]interface{}{
+		"target":  map[string]interface{}{"url": "https://a.staging.example.com/mcp"},
+		"targets": []interface{}{map[string]interface{}{"name": "local", "weight": 1.0, "url": "http://127.0.0.1:8080/mcp"}},
+	})
+	report = ssrf.Validate(data)
+	if len(report.Errors) == 0 || report.Errors[0].JSONPointer != "/targets/0/url" {
+		t.Errorf("expected an SSRF error at /targets/0/url, got %+v", report.Errors)
+	}
+}
+
+func TestSemanticValidator_Shadow(t *testing.T) {
+	policy := &SystemPolicy{
+		GlobalAllowlist: []AllowlistEntry{{Kind: "suffix", Value: ".staging.example.com"}},
+	}
+	v := NewSemanticValidator(policy)
+
+	hasIssue := func(issues []ValidationIssue, code, pointer string) bool {
+		for _, issue := range issues {
+			if issue.Code == code && issue.JSONPointer == pointer {
+				return true
+			}
+		}
+		return false
+	}
+	validate := func(shadow map[string]interface{}) *ValidationReport {
+		data, _ := json.Marshal(map[string]interface{}{
+			"target": map[string]interface{}{"url": "https://stable.staging.example.com/mcp"},
+			"shadow": shadow,
+			"workload": map[string]interface{}{
+				"tools": map[string]interface{}{
+					"selection": map[string]interface{}{"mode": "weighted"},
+					"templates": []interface{}{map[string]interface{}{"template_id": "t1", "tool_name": "lookup", "weight": 1.0}},
+				},
+			},
+		})
+		return v.Validate(data)
+	}
+
+	report := validate(map[string]interface{}{
+		"url":  "https://canary.production.example.com/mcp",
+		"auth": map[string]interface{}{"type": "oauth2_client_credentials"},
+	})
+	if !hasIssue(report.Errors, CodeAllowlistViolation, "/shadow/url") {
+		t.Errorf("expected the shadow URL to be checked against the allowlist, got %+v", report.Errors)
+	}
+	if !hasIssue(report.Errors, CodeRequiredFieldMissing, "/shadow/auth/oauth2") {
+		t.Errorf("expected the shadow's auth to be checked, got %+v", report.Errors)
+	}
+
+	report = validate(map[string]interface{}{
+		"url":          "https://stable.staging.example.com/mcp",
+		"stable_tools": []interface{}{"lookup", "unused"},
+	})
+	if hasIssue(report.Errors, CodeAllowlistViolation, "/shadow/url") {
+		t.Errorf("expected an allowlisted shadow URL to pass, got %+v", report.Errors)
+	}
+	if !hasIssue(report.Warnings, CodeTargetsInvalid, "/shadow/url") {
+		t.Errorf("expected a shadow that is the target to warn, got %+v", report.Warnings)
+	}
+	if !hasIssue(report.Warnings, CodeTargetsInvalid, "/shadow/stable_tools/1") || hasIssue(report.Warnings, CodeTargetsInvalid, "/shadow/stable_tools/0") {
+		t.Errorf("expected only the stable tool never called to warn, got %+v", report.Warnings)
+	}
+}
+
+func TestValidateConformanceRun(t *testing.T) {
+	data, err := os.ReadFile("../../testdata/fixtures/valid/minimal_preflight_baseline_ramp.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config map[string]interface{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatal(err)
+	}
+	config["run_type"] = "conformance"
+	config["conformance"] = map[string]interface{}{"tool": "slow", "tool_arguments": map[string]interface{}{"ms": 500}, "cancel_after_ms": 50}
+	stages := config["stages"].([]interface{})
+	for _, s := range stages[1:] {
+		s.(map[string]interface{})["enabled"] = false
+	}
+	data, _ = json.Marshal(config)
+
+	v, err := NewUnifiedValidator(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report := v.ValidateRunConfig(data); !report.OK {
+		t.Fatalf("expected a conformance run with only preflight to be valid, got %+v", report.Errors)
+	}
+
+	stages[1].(map[string]interface{})["enabled"] = true
+	data, _ = json.Marshal(config)
+	report := v.ValidateRunConfig(data)
+	rejected := false
+	for _, e := range report.Errors {
+		rejected = rejected || (e.Code == CodeConformanceRunInvalid && e.JSONPointer == "/stages/1")
+	}
+	if !rejected {
+		t.Errorf("expected a conformance run with a load stage to be rejected, got %+v", report.Errors)
+	}
+
+	delete(config, "run_type")
+	stages[1].(map[string]interface{})["enabled"] = true
+	data, _ = json.Marshal(config)
+	report = v.ValidateRunConfig(data)
+	warned := false
+	for _, w := range report.Warnings {
+		warned = warned || w.JSONPointer == "/conformance"
+	}
+	if !warned {
+		t.Errorf("expected conformance options of a load run to warn, got %+v", report.Warnings)
+	}
+}
+
+func TestValidateChaos(t *testing.T) {
+	data, err := os.ReadFile("../../testdata/fixtures/valid/minimal_preflight_baseline_ramp.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config map[string]interface{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatal(err)
+	}
+	stages := config["stages"].([]interface{})
+	stages[0].(map[string]interface{})["chaos"] = map[string]interface{}{"latency_ms": 50}
+	stages[1].(map[string]interface{})["chaos"] = map[string]interface{}{
+		"latency_ms": 100, "jitter_ms": 200, "reset_rate": 0.01, "bandwidth_bytes_per_sec": 65536,
+	}
+	data, _ = json.Marshal(config)
+
+	v, err := NewUnifiedValidator(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := v.ValidateRunConfig(data)
+	if !report.OK {
+		t.Fatalf("expected stage chaos to be valid, got %+v", report.Errors)
+	}
+	warned := map[string]bool{}
+	for _, w := range report.Warnings {
+		warned[w.JSONPointer] = true
+	}
+	if !warned["/stages/0/chaos"] || !warned["/stages/1/chaos/jitter_ms"] {
+		t.Errorf("expected warnings for preflight chaos and jitter above latency, got %+v", report.Warnings)
+	}
+
+	stages[1].(map[string]interface{})["chaos"] = map[string]interface{}{"reset_rate": 1.5}
+	data, _ = json.Marshal(config)
+	if report := v.ValidateRunConfig(data); report.OK {
+		t.Error("expected a reset_rate above 1 to be rejected")
+	}
+}
+
+func TestValidateThinkTime(t *testing.T) {
+	data, err := os.ReadFile("../../testdata/fixtures/valid/minimal_preflight_baseline_ramp.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config map[string]interface{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatal(err)
+	}
+	workload := config["workload"].(map[string]interface{})
+	workload["think_time"] = map[string]interface{}{"mode": "uniform", "base_ms": 100, "jitter_ms": 200}
+	workload["tool_think_time"] = map[string]interface{}{
+		"echo":    map[string]interface{}{"mode": "exponential", "base_ms": 2000, "jitter_ms": 0, "max_ms": 10000},
+		"missing": map[string]interface{}{"mode": "fixed", "base_ms": 500, "jitter_ms": 0},
+	}
+	data, _ = json.Marshal(config)
+
+	v, err := NewUnifiedValidator(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := v.ValidateRunConfig(data)
+	if !report.OK {
+		t.Fatalf("expected uniform and exponential think times to be valid, got %+v", report.Errors)
+	}
+	warned := map[string]bool{}
+	for _, w := range report.Warnings {
+		if w.Code == CodeThinkTimeInvalid {
+			warned[w.JSONPointer] = true
+		}
+	}
+	if len(warned) != 2 || !warned["/workload/think_time/jitter_ms"] || !warned["/workload/tool_think_time/missing"] {
+		t.Errorf("expected warnings for jitter above base and a tool never called, got %+v", report.Warnings)
+	}
+
+	workload["tool_think_time"] = map[string]interface{}{"echo": map[string]interface{}{"mode": "poisson", "base_ms": 10, "jitter_ms": 0}}
+	data, _ = json.Marshal(config)
+	if report := v.ValidateRunConfig(data); report.OK {
+		t.Error("expected an unknown think time mode to be rejected")
+	}
+}
+
+func TestValidateWorkflows(t *testing.T) {
+	data, err := os.ReadFile("../../testdata/fixtures/valid/minimal_preflight_baseline_ramp.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config map[string]interface{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatal(err)
+	}
+	workload := config["workload"].(map[string]interface{})
+	workload["tool_think_time"] = map[string]interface{}{"search": map[string]interface{}{"mode": "fixed", "base_ms": 500, "jitter_ms": 0}}
+	workload["workflows"] = []interface{}{
+		map[string]interface{}{
+			"name":   "discover",
+			"weight": 2,
+			"steps": []interface{}{
+				map[string]interface{}{"operation": "tools_list", "extract": map[string]interface{}{"tool": "$.tools[*].name"}},
+				map[string]interface{}{"operation": "tools_call", "tool_name": "${vars.tool}", "arguments": map[string]interface{}{}},
+				map[string]interface{}{
+					"name":      "search",
+					"operation": "tools_call",
+					"tool_name": "search",
+					"arguments": map[string]interface{}{"query": "${vars.tool}"},
+					"checks":    []interface{}{map[string]interface{}{"jsonpath": "$.content[0].text"}},
+					"extract":   map[string]interface{}{"uri": "$.content[0].uri"},
+				},
+				map[string]interface{}{"operation": "resources_read", "uri": "${vars.uri}"},
+			},
+		},
+	}
+	data, _ = json.Marshal(config)
+
+	v, err := NewUnifiedValidator(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := v.ValidateRunConfig(data)
+	if !report.OK {
+		t.Fatalf("expected the workflow to be valid, got %+v", report.Errors)
+	}
+	for _, w := range report.Warnings {
+		if w.Code == CodeThinkTimeInvalid {
+			t.Errorf("expected a tool called by a workflow to count as called, got %+v", w)
+		}
+	}
+
+	cases := []struct {
+		name    string
+		steps   []interface{}
+		code    string
+		pointer string
+	}{
+		{
+			name:    "variable used before it is extracted",
+			steps:   []interface{}{map[string]interface{}{"operation": "tools_call", "tool_name": "${vars.tool}", "extract": map[string]interface{}{"tool": "$.name"}}},
+			code:    CodeArgumentTemplateInvalid,
+			pointer: "/workload/workflows/0/steps/0/tool_name",
+		},
+		{
+			name:    "invalid extract path",
+			steps:   []interface{}{map[string]interface{}{"operation": "tools_list", "extract": map[string]interface{}{"tool": "tools[0]"}}},
+			code:    CodeWorkflowInvalid,
+			pointer: "/workload/workflows/0/steps/0/extract/tool",
+		},
+		{
+			name:    "missing uri",
+			steps:   []interface{}{map[string]interface{}{"operation": "resources_read"}},
+			code:    CodeRequiredFieldMissing,
+			pointer: "/workload/workflows/0/steps/0/uri",
+		},
+		{
+			name: "duplicate step name",
+			steps: []interface{}{
+				map[string]interface{}{"name": "list", "operation": "tools_list"},
+				map[string]interface{}{"name": "list", "operation": "ping"},
+			},
+			code:    CodeWorkflowInvalid,
+			pointer: "/workload/workflows/0/steps/1/name",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			workload["workflows"] = []interface{}{map[string]interface{}{"name": "broken", "weight": 1, "steps": tc.steps}}
+			data, _ := json.Marshal(config)
+			report := v.ValidateRunConfig(data)
+			if report.OK {
+				t.Fatal("expected the workflow to be rejected")
+			}
+			for _, e := range report.Errors {
+				if e.Code == tc.code && e.JSONPointer == tc.pointer {
+					return
+				}
+			}
+			t.Errorf("expected %s at %s, got %+v", tc.code, tc.pointer, report.Errors)
+		})
+	}
 }
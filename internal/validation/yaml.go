@@ -0,0 +1,283 @@
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YAMLLocation is where a value appears in a YAML document.
+type YAMLLocation struct {
+	Path   string // dotted path, e.g. stages[1].load.target_vus
+	Line   int
+	Column int
+}
+
+// YAMLDocument is a YAML run config converted to JSON, with the location of
+// every value so validation issues can point back into the YAML source.
+type YAMLDocument struct {
+	JSON      []byte
+	locations map[string]YAMLLocation // keyed by JSON pointer
+	sequences map[string]bool         // pointers of sequence values
+
+	nodes     int // values converted so far, counting each alias expansion
+	nodeLimit int // most values the document may expand to
+}
+
+// ParseYAML converts a YAML document to JSON. Only the first document of a
+// stream is read. Mapping keys must be strings and must not repeat; anchors,
+// aliases and merge keys are resolved.
+func ParseYAML(data []byte) (*YAMLDocument, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	if len(root.Content) == 0 {
+		return nil, fmt.Errorf("document is empty")
+	}
+
+	doc := &YAMLDocument{
+		locations: make(map[string]YAMLLocation),
+		sequences: make(map[string]bool),
+		nodeLimit: min(max(countYAMLNodes(root.Content[0], 0)*maxYAMLExpansion, minYAMLNodeLimit), maxYAMLNodes),
+	}
+	value, err := doc.convert(root.Content[0], "", "", 0)
+	if err != nil {
+		return nil, err
+	}
+	doc.JSON, err = json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// maxYAMLDepth bounds nesting, including nesting reached through aliases.
+const maxYAMLDepth = 100
+
+// Aliases and merge keys may expand a document to at most maxYAMLExpansion
+// times the values written in it, or minYAMLNodeLimit values for small
+// documents, and never past maxYAMLNodes, so a few nested aliases cannot
+// blow up into millions of values.
+const (
+	maxYAMLExpansion = 10
+	minYAMLNodeLimit = 10000
+	maxYAMLNodes     = 100000
+)
+
+// countYAMLNodes counts the values written in a document, without
+// following aliases. It stops counting past maxYAMLNodes.
+func countYAMLNodes(node *yaml.Node, count int) int {
+	count++
+	for _, child := range node.Content {
+		if count > maxYAMLNodes {
+			break
+		}
+		count = countYAMLNodes(child, count)
+	}
+	return count
+}
+
+func (d *YAMLDocument) convert(node *yaml.Node, pointer, path string, depth int) (interface{}, error) {
+	if depth > maxYAMLDepth {
+		return nil, fmt.Errorf("line %d: document nested too deeply", node.Line)
+	}
+	d.nodes++
+	if d.nodes > d.nodeLimit {
+		return nil, fmt.Errorf("line %d: aliases expand the document past %d values", node.Line, d.nodeLimit)
+	}
+	if node.Kind == yaml.AliasNode {
+		return d.convert(node.Alias, pointer, path, depth+1)
+	}
+	if _, seen := d.locations[pointer]; !seen {
+		d.locations[pointer] = YAMLLocation{Path: path, Line: node.Line, Column: node.Column}
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		obj := make(map[string]interface{})
+		if err := d.convertMapping(node, obj, make(map[string]bool), pointer, path, depth); err != nil {
+			return nil, err
+		}
+		return obj, nil
+
+	case yaml.SequenceNode:
+		d.sequences[pointer] = true
+		arr := make([]interface{}, len(node.Content))
+		for i, item := range node.Content {
+			v, err := d.convert(item, pointer+"/"+strconv.Itoa(i), fmt.Sprintf("%s[%d]", path, i), depth+1)
+			if err != nil {
+				return nil, err
+			}
+			arr[i] = v
+		}
+		return arr, nil
+
+	case yaml.ScalarNode:
+		if node.ShortTag() == "!!timestamp" {
+			return node.Value, nil
+		}
+		var v interface{}
+		if err := node.Decode(&v); err != nil {
+			return nil, fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		return v, nil
+	}
+
+	return nil, fmt.Errorf("line %d: unsupported YAML node", node.Line)
+}
+
+// convertMapping adds the entries of a mapping to obj. Explicit keys win over
+// keys brought in by a merge key, as the YAML merge key spec requires.
+func (d *YAMLDocument) convertMapping(node *yaml.Node, obj map[string]interface{}, explicit map[string]bool, pointer, path string, depth int) error {
+	var merges []*yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		if keyNode.ShortTag() == "!!merge" {
+			merges = append(merges, valueNode)
+			continue
+		}
+		if keyNode.Kind != yaml.ScalarNode || keyNode.ShortTag() != "!!str" {
+			return fmt.Errorf("line %d: mapping keys must be strings", keyNode.Line)
+		}
+		key := keyNode.Value
+		if explicit[key] {
+			return fmt.Errorf("line %d: key %q already defined", keyNode.Line, key)
+		}
+		explicit[key] = true
+
+		v, err := d.convert(valueNode, pointer+"/"+escapePointerToken(key), joinYAMLPath(path, key), depth+1)
+		if err != nil {
+			return err
+		}
+		obj[key] = v
+	}
+
+	for _, merge := range merges {
+		if merge.Kind == yaml.AliasNode {
+			merge = merge.Alias
+		}
+		sources := []*yaml.Node{merge}
+		if merge.Kind == yaml.SequenceNode {
+			sources = merge.Content
+		}
+		for _, src := range sources {
+			if src.Kind == yaml.AliasNode {
+				src = src.Alias
+			}
+			if src.Kind != yaml.MappingNode {
+				return fmt.Errorf("line %d: merge key value must be a mapping", merge.Line)
+			}
+			merged := make(map[string]interface{})
+			if err := d.convertMapping(src, merged, make(map[string]bool), pointer, path, depth+1); err != nil {
+				return err
+			}
+			for k, v := range merged {
+				if _, ok := obj[k]; !ok {
+					obj[k] = v
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Locate returns the YAML location of a JSON pointer. Pointers to values that
+// are absent from the document, such as a missing required field, resolve to
+// their closest present ancestor with the remaining tokens appended to Path.
+func (d *YAMLDocument) Locate(pointer string) (YAMLLocation, bool) {
+	if loc, ok := d.locations[pointer]; ok {
+		return loc, true
+	}
+
+	var missing []string
+	for pointer != "" {
+		i := strings.LastIndex(pointer, "/")
+		if i < 0 {
+			return YAMLLocation{}, false
+		}
+		missing = append([]string{unescapePointerToken(pointer[i+1:])}, missing...)
+		pointer = pointer[:i]
+		if loc, ok := d.locations[pointer]; ok {
+			for j, token := range missing {
+				if _, err := strconv.Atoi(token); err == nil && j == 0 && d.sequences[pointer] {
+					loc.Path = fmt.Sprintf("%s[%s]", loc.Path, token)
+					continue
+				}
+				loc.Path = joinYAMLPath(loc.Path, token)
+			}
+			return loc, true
+		}
+	}
+	return YAMLLocation{}, false
+}
+
+// Annotate fills in the YAML path, line and column of every issue in report
+// that has a JSON pointer.
+func (d *YAMLDocument) Annotate(report *ValidationReport) {
+	annotate := func(issues []ValidationIssue) {
+		for i := range issues {
+			if issues[i].JSONPointer == "" {
+				continue
+			}
+			if loc, ok := d.Locate(issues[i].JSONPointer); ok {
+				issues[i].YAMLPath = loc.Path
+				issues[i].Line = loc.Line
+				issues[i].Column = loc.Column
+			}
+		}
+	}
+	annotate(report.Errors)
+	annotate(report.Warnings)
+}
+
+// ValidateRunConfigYAML validates a run config written in YAML. Issues carry
+// both the JSON pointer and the YAML location of the offending value.
+func (v *UnifiedValidator) ValidateRunConfigYAML(data []byte) *ValidationReport {
+	doc, err := ParseYAML(data)
+	if err != nil {
+		report := NewValidationReport()
+		report.AddError(CodeSchemaViolation, fmt.Sprintf("Invalid YAML: %v", err), "")
+		return report
+	}
+	report := v.ValidateRunConfig(doc.JSON)
+	doc.Annotate(report)
+	return report
+}
+
+func joinYAMLPath(path, key string) string {
+	if needsQuoting(key) {
+		key = strconv.Quote(key)
+		if path == "" {
+			return "[" + key + "]"
+		}
+		return path + "[" + key + "]"
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func needsQuoting(key string) bool {
+	if key == "" {
+		return true
+	}
+	for _, r := range key {
+		if !(r == '_' || r == '-' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return true
+		}
+	}
+	return false
+}
+
+func escapePointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func unescapePointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
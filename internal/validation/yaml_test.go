@@ -0,0 +1,174 @@
+package validation
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func loadYAMLFixture(t *testing.T) string {
+	t.Helper()
+	data, err := os.ReadFile("../../testdata/fixtures/valid/minimal_preflight_baseline_ramp.yaml")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	return string(data)
+}
+
+func TestParseYAML_MatchesJSONFixture(t *testing.T) {
+	doc, err := ParseYAML([]byte(loadYAMLFixture(t)))
+	if err != nil {
+		t.Fatalf("ParseYAML failed: %v", err)
+	}
+
+	jsonData, err := os.ReadFile("../../testdata/fixtures/valid/minimal_preflight_baseline_ramp.json")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	var want, got interface{}
+	if err := json.Unmarshal(jsonData, &want); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(doc.JSON, &got); err != nil {
+		t.Fatalf("converted YAML is not valid JSON: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("converted YAML differs from JSON fixture:\n%s", doc.JSON)
+	}
+
+	validator, err := NewUnifiedValidator(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report := validator.ValidateRunConfigYAML([]byte(loadYAMLFixture(t))); !report.OK {
+		t.Errorf("expected YAML fixture to validate, got: %s", report)
+	}
+}
+
+func TestValidateRunConfigYAML_IssueLocations(t *testing.T) {
+	// Make the baseline stage's target_vus negative.
+	src := strings.Replace(loadYAMLFixture(t), "target_vus: 50", "target_vus: -5", 1)
+	lines := strings.Split(src, "\n")
+	wantLine := 0
+	for i, line := range lines {
+		if strings.Contains(line, "target_vus: -5") {
+			wantLine = i + 1
+		}
+	}
+
+	validator, err := NewUnifiedValidator(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := validator.ValidateRunConfigYAML([]byte(src))
+	if report.OK {
+		t.Fatal("expected validation to fail")
+	}
+
+	var found bool
+	for _, issue := range report.Errors {
+		if issue.JSONPointer != "/stages/1/load/target_vus" {
+			continue
+		}
+		found = true
+		if issue.YAMLPath != "stages[1].load.target_vus" {
+			t.Errorf("YAMLPath = %q, want stages[1].load.target_vus", issue.YAMLPath)
+		}
+		if issue.Line != wantLine || issue.Column != 17 {
+			t.Errorf("location = %d:%d, want %d:17", issue.Line, issue.Column, wantLine)
+		}
+	}
+	if !found {
+		t.Fatalf("no issue for /stages/1/load/target_vus: %s", report)
+	}
+}
+
+func TestYAMLDocument_Locate(t *testing.T) {
+	src := `defaults: &defaults
+  weight: 1
+  operation: tools_list
+mix:
+  - <<: *defaults
+    weight: 5
+  - <<: *defaults
+"odd.key": true
+`
+	doc, err := ParseYAML([]byte(src))
+	if err != nil {
+		t.Fatalf("ParseYAML failed: %v", err)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(doc.JSON, &got); err != nil {
+		t.Fatal(err)
+	}
+	mix := got["mix"].([]interface{})
+	if w := mix[0].(map[string]interface{})["weight"]; w != float64(5) {
+		t.Errorf("explicit key should override merge key, got weight %v", w)
+	}
+	if op := mix[1].(map[string]interface{})["operation"]; op != "tools_list" {
+		t.Errorf("merge key not applied, got operation %v", op)
+	}
+
+	tests := []struct {
+		pointer string
+		path    string
+		line    int
+	}{
+		{"/mix/0/weight", "mix[0].weight", 6},
+		{"/mix/1", "mix[1]", 7},
+		{"/mix/1/missing", "mix[1].missing", 7},
+		{"/mix/4", "mix[4]", 5},
+		{"/odd.key", `["odd.key"]`, 8},
+		{"/absent/0", "absent.0", 1},
+	}
+	for _, tt := range tests {
+		loc, ok := doc.Locate(tt.pointer)
+		if !ok {
+			t.Errorf("Locate(%q) found nothing", tt.pointer)
+			continue
+		}
+		if loc.Path != tt.path || loc.Line != tt.line {
+			t.Errorf("Locate(%q) = %s line %d, want %s line %d", tt.pointer, loc.Path, loc.Line, tt.path, tt.line)
+		}
+	}
+}
+
+func TestParseYAML_Errors(t *testing.T) {
+	tests := map[string]string{
+		"syntax":         "a: [1, 2",
+		"empty":          "",
+		"duplicate key":  "a: 1\na: 2\n",
+		"non-string key": "1: a\n",
+		"infinity":       "a: .inf\n",
+		"alias bomb": `a: &a ["x","x","x","x","x","x","x","x","x","x"]
+b: &b [*a,*a,*a,*a,*a,*a,*a,*a,*a,*a]
+c: &c [*b,*b,*b,*b,*b,*b,*b,*b,*b,*b]
+d: &d [*c,*c,*c,*c,*c,*c,*c,*c,*c,*c]
+e: &e [*d,*d,*d,*d,*d,*d,*d,*d,*d,*d]
+f: [*e,*e,*e,*e,*e,*e,*e,*e,*e,*e]
+`,
+		"merge bomb": `a: &a {a: 1, b: 2, c: 3, d: 4, e: 5, f: 6, g: 7, h: 8, i: 9, j: 10}
+b: &b {a: *a, b: *a, c: *a, d: *a, e: *a, f: *a, g: *a, h: *a, i: *a, j: *a}
+c: &c {a: *b, b: *b, c: *b, d: *b, e: *b, f: *b, g: *b, h: *b, i: *b, j: *b}
+d: &d {a: *c, b: *c, c: *c, d: *c, e: *c, f: *c, g: *c, h: *c, i: *c, j: *c}
+e: &e {<<: [*d], k: *d, l: *d, m: *d, n: *d, o: *d}
+`,
+	}
+	for name, src := range tests {
+		if _, err := ParseYAML([]byte(src)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	validator, err := NewUnifiedValidator(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := validator.ValidateRunConfigYAML([]byte("a: [1, 2"))
+	if report.OK || report.Errors[0].Code != CodeSchemaViolation {
+		t.Errorf("expected schema violation for invalid YAML, got: %s", report)
+	}
+}
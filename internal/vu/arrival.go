@@ -0,0 +1,236 @@
+package vu
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/session"
+)
+
+// maxArrivalLag is how far the arrival schedule may fall behind the clock,
+// e.g. after the process was descheduled. Arrivals older than that are
+// dropped rather than fired in one burst.
+const maxArrivalLag = time.Second
+
+// arrivalSchedule produces the gaps between arrivals.
+type arrivalSchedule struct {
+	schedule ArrivalSchedule
+	mean     float64 // seconds between arrivals
+	rng      *rand.Rand
+}
+
+func newArrivalSchedule(cfg *ArrivalConfig, seed int64) *arrivalSchedule {
+	return &arrivalSchedule{
+		schedule: cfg.Schedule,
+		mean:     1 / cfg.RateRPS,
+		rng:      rand.New(rand.NewSource(seed)),
+	}
+}
+
+// next returns the gap before the next arrival.
+func (s *arrivalSchedule) next() time.Duration {
+	gap := s.mean
+	if s.schedule != ArrivalConstant {
+		gap = s.rng.ExpFloat64() * s.mean
+	}
+	return time.Duration(gap * float64(time.Second))
+}
+
+// arrivalSlot is one of the sessions arrivals are spread over. In reuse mode
+// the slot keeps its session for the whole assignment and concurrent
+// arrivals share it; in other modes each arrival acquires its own session.
+type arrivalSlot struct {
+	executor *VUExecutor
+	mu       sync.Mutex
+	sess     *session.SessionInfo
+}
+
+// execute runs op as one arrival.
+func (s *arrivalSlot) execute(ctx context.Context, op *OperationWeight) {
+	e := s.executor
+	if e.sessionMode != session.ModeReuse {
+		acquireStart := time.Now()
+		sess, err := e.acquireSession(ctx)
+		if err != nil {
+			if e.shouldEmitSessionAcquireError(err) {
+				operation, toolName := op.firstOperation()
+				e.emitSessionAcquireError(operation, toolName, err, acquireStart, time.Now())
+			}
+			return
+		}
+		defer e.releaseSession(ctx, sess)
+		e.execute(ctx, sess, op, 0, 0)
+		return
+	}
+
+	acquireStart := time.Now()
+	sess, err := s.reuseSession(ctx)
+	if err != nil {
+		if e.shouldEmitSessionAcquireError(err) {
+			operation, toolName := op.firstOperation()
+			e.emitSessionAcquireError(operation, toolName, err, acquireStart, time.Now())
+		}
+		return
+	}
+	e.execute(ctx, sess, op, 0, 0)
+}
+
+// reuseSession returns the slot's session, replacing it once it has expired
+// or been closed.
+func (s *arrivalSlot) reuseSession(ctx context.Context) (*session.SessionInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.sess != nil && (s.sess.IsExpired() || s.sess.GetState() == session.StateClosed || s.sess.GetState() == session.StateExpired) {
+		s.executor.invalidateReuseSession(ctx, s.sess)
+		s.sess = nil
+	}
+	if s.sess == nil {
+		sess, err := s.executor.acquireSession(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.sess = sess
+		s.executor.vu.SetSession(sess)
+	}
+	return s.sess, nil
+}
+
+// close releases the slot's reuse session, if any.
+func (s *arrivalSlot) close(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sess != nil {
+		s.executor.releaseSession(ctx, s.sess)
+		s.sess = nil
+	}
+	s.executor.vu.SetState(StateStopped)
+	s.executor.vu.StoppedAt = time.Now()
+}
+
+// runArrivalMode starts operations at the configured arrival rate until the
+// engine stops. Arrivals do not wait for earlier operations to finish, so a
+// slow target does not lower the offered load; when MaxInFlight operations
+// are already running the arrival is dropped instead.
+func (e *Engine) runArrivalMode() {
+	defer e.wg.Done()
+
+	arrival := e.config.Arrival
+	maxInFlight := int64(arrival.MaxInFlight)
+	if maxInFlight <= 0 {
+		maxInFlight = DefaultArrivalMaxInFlight
+	}
+
+	slots := e.startArrivalSlots()
+	e.metrics.ActiveVUs.Add(int64(len(slots)))
+
+	var (
+		inFlight    atomic.Int64
+		operationWG sync.WaitGroup
+	)
+	defer func() {
+		operationWG.Wait()
+		// The engine context is cancelled by now; release with a fresh one.
+		for _, slot := range slots {
+			slot.close(context.Background())
+		}
+		e.metrics.ActiveVUs.Add(-int64(len(slots)))
+		e.metrics.TotalVUsTerminated.Add(int64(len(slots)))
+	}()
+
+	schedule := newArrivalSchedule(arrival, time.Now().UnixNano())
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	defer timer.Stop()
+
+	next := time.Now()
+	for n := 0; ; n++ {
+		if e.gate.paused() {
+			if !e.gate.wait(e.ctx, 0) {
+				return
+			}
+			next = time.Now()
+		}
+		// The rate climbs back after a resume by stretching the gaps.
+		next = next.Add(time.Duration(float64(schedule.next()) / e.gate.rate()))
+		if wait := time.Until(next); wait > 0 {
+			timer.Reset(wait)
+			select {
+			case <-e.ctx.Done():
+				return
+			case <-timer.C:
+			}
+			if e.gate.paused() {
+				continue
+			}
+		} else {
+			select {
+			case <-e.ctx.Done():
+				return
+			default:
+			}
+			if -wait > maxArrivalLag {
+				e.metrics.DroppedArrivals.Add(1)
+				continue
+			}
+		}
+
+		if e.gate.shedArrival() {
+			e.metrics.ShedOperations.Add(1)
+			continue
+		}
+
+		current := inFlight.Add(1)
+		if current > maxInFlight {
+			inFlight.Add(-1)
+			e.metrics.DroppedArrivals.Add(1)
+			continue
+		}
+		e.updateArrivalMaxInFlight(current)
+
+		op := e.sampler.Sample()
+		slot := slots[n%len(slots)]
+		operationWG.Add(1)
+		go func() {
+			defer operationWG.Done()
+			defer inFlight.Add(-1)
+			slot.execute(e.ctx, op)
+		}()
+	}
+}
+
+// startArrivalSlots creates one slot per target VU, and at least one.
+func (e *Engine) startArrivalSlots() []*arrivalSlot {
+	e.vuMu.Lock()
+	defer e.vuMu.Unlock()
+
+	slots := make([]*arrivalSlot, max(e.config.Load.TargetVUs, 1))
+	for i := range slots {
+		vuNum := e.vuCounter.Add(1)
+		vuID := fmt.Sprintf("%s-arrival-vu-%d", e.config.AssignmentID, vuNum)
+		vu := NewVUInstance(vuID, time.Now().UnixNano()+vuNum)
+		vu.Index = int(vuNum - 1)
+		vu.StartedAt = time.Now()
+		vu.SetState(StateRunning)
+		e.vus[vuID] = vu
+		e.metrics.TotalVUsCreated.Add(1)
+
+		executor := NewVUExecutor(vu, e.config, e.sampler, nil, e.metrics, e.resultChan)
+		e.executors[vuID] = executor
+		slots[i] = &arrivalSlot{executor: executor}
+	}
+	return slots
+}
+
+func (e *Engine) updateArrivalMaxInFlight(current int64) {
+	for {
+		reached := e.metrics.MaxInFlightReached.Load()
+		if current <= reached || e.metrics.MaxInFlightReached.CompareAndSwap(reached, current) {
+			return
+		}
+	}
+}
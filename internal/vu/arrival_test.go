@@ -0,0 +1,152 @@
+package vu
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/session"
+	"github.com/bc-dunia/mcpdrill/internal/transport"
+)
+
+// slowAdapter connects to a target that takes latencyMs per operation.
+type slowAdapter struct {
+	latencyMs int64
+}
+
+func (a *slowAdapter) ID() string { return "slow" }
+
+func (a *slowAdapter) Connect(ctx context.Context, config *transport.TransportConfig) (transport.Connection, error) {
+	return &mockConnection{sessionID: "slow-session", latencyMs: a.latencyMs}, nil
+}
+
+func createArrivalTestConfig(t *testing.T, latencyMs int64, arrival *ArrivalConfig) *VUConfig {
+	config := createTestConfig(t)
+	adapter := &slowAdapter{latencyMs: latencyMs}
+	sessionMgr, err := session.NewManager(&session.SessionConfig{
+		Mode:            session.ModeReuse,
+		TTLMs:           60000,
+		MaxIdleMs:       30000,
+		Adapter:         adapter,
+		TransportConfig: config.TransportConfig,
+	})
+	if err != nil {
+		t.Fatalf("failed to create session manager: %v", err)
+	}
+	config.SessionManager = sessionMgr
+	config.TransportAdapter = adapter
+	config.Load.TargetVUs = 1
+	config.InFlightPerVU = 1
+	config.OperationMix = &OperationMix{Operations: []OperationWeight{{Operation: OpPing, Weight: 1}}}
+	config.Mode = ModeArrivalRate
+	config.Arrival = arrival
+	return config
+}
+
+func runArrivalEngine(t *testing.T, config *VUConfig, d time.Duration) VUMetricsSnapshot {
+	t.Helper()
+	engine, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	config.SessionManager.(*session.Manager).Start(ctx)
+
+	if err := engine.Start(ctx); err != nil {
+		t.Fatalf("failed to start engine: %v", err)
+	}
+	go func() {
+		for range engine.Results() {
+		}
+	}()
+
+	time.Sleep(d)
+
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer stopCancel()
+	if err := engine.Stop(stopCtx); err != nil {
+		t.Fatalf("failed to stop engine: %v", err)
+	}
+	return engine.MetricsSnapshot()
+}
+
+func TestArrivalSchedule(t *testing.T) {
+	constant := newArrivalSchedule(&ArrivalConfig{RateRPS: 50, Schedule: ArrivalConstant}, 1)
+	for i := 0; i < 3; i++ {
+		if gap := constant.next(); gap != 20*time.Millisecond {
+			t.Fatalf("constant gap = %v, want 20ms", gap)
+		}
+	}
+
+	poisson := newArrivalSchedule(&ArrivalConfig{RateRPS: 50, Schedule: ArrivalPoisson}, 1)
+	var (
+		total    time.Duration
+		distinct = map[time.Duration]bool{}
+	)
+	const n = 20000
+	for i := 0; i < n; i++ {
+		gap := poisson.next()
+		total += gap
+		distinct[gap] = true
+	}
+	mean := total / n
+	if math.Abs(float64(mean-20*time.Millisecond)) > float64(time.Millisecond) {
+		t.Errorf("poisson mean gap = %v, want about 20ms", mean)
+	}
+	if len(distinct) < n/2 {
+		t.Errorf("poisson gaps should vary, got %d distinct of %d", len(distinct), n)
+	}
+}
+
+func TestEngine_ArrivalRateRequiresRate(t *testing.T) {
+	config := createArrivalTestConfig(t, 0, nil)
+	if _, err := NewEngine(config); !errors.Is(err, errNoArrivalRate) {
+		t.Fatalf("expected errNoArrivalRate, got %v", err)
+	}
+
+	config.Arrival = &ArrivalConfig{RateRPS: 0}
+	if _, err := NewEngine(config); !errors.Is(err, errNoArrivalRate) {
+		t.Fatalf("expected errNoArrivalRate for zero rate, got %v", err)
+	}
+}
+
+func TestEngine_ArrivalRateIgnoresLatency(t *testing.T) {
+	// One session and 200ms per operation: a closed model with one VU would
+	// manage about 3 operations in 600ms.
+	config := createArrivalTestConfig(t, 200, &ArrivalConfig{RateRPS: 100, Schedule: ArrivalConstant})
+
+	snapshot := runArrivalEngine(t, config, 600*time.Millisecond)
+
+	if snapshot.TotalOperations < 30 {
+		t.Errorf("expected about 60 operations started at 100/s, got %d", snapshot.TotalOperations)
+	}
+	if snapshot.MaxInFlightReached < 10 {
+		t.Errorf("expected operations to overlap, max in-flight %d", snapshot.MaxInFlightReached)
+	}
+	if snapshot.DroppedArrivals != 0 {
+		t.Errorf("expected no dropped arrivals, got %d", snapshot.DroppedArrivals)
+	}
+	if snapshot.SessionsCreated != 1 {
+		t.Errorf("expected arrivals to share one reuse session, created %d", snapshot.SessionsCreated)
+	}
+	if snapshot.ActiveVUs != 0 || snapshot.ActiveSessions != 0 {
+		t.Errorf("expected slots released after stop, active VUs %d sessions %d", snapshot.ActiveVUs, snapshot.ActiveSessions)
+	}
+}
+
+func TestEngine_ArrivalRateMaxInFlight(t *testing.T) {
+	config := createArrivalTestConfig(t, 200, &ArrivalConfig{RateRPS: 100, Schedule: ArrivalPoisson, MaxInFlight: 2})
+
+	snapshot := runArrivalEngine(t, config, 500*time.Millisecond)
+
+	if snapshot.MaxInFlightReached > 2 {
+		t.Errorf("max in-flight %d exceeded cap 2", snapshot.MaxInFlightReached)
+	}
+	if snapshot.DroppedArrivals == 0 {
+		t.Error("expected arrivals over the in-flight cap to be dropped")
+	}
+}
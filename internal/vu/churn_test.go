@@ -493,3 +493,112 @@ func TestChurnModeInvalidate(t *testing.T) {
 		t.Error("Should create new session after invalidation")
 	}
 }
+
+func TestChurnModeRetiredSessionClosesOnRelease(t *testing.T) {
+	adapter := &mockChurnAdapter{}
+	mgr, err := session.NewManager(&session.SessionConfig{
+		Mode:             session.ModeChurn,
+		ChurnIntervalOps: 1,
+		Adapter:          adapter,
+		TransportConfig:  &transport.TransportConfig{Endpoint: "http://localhost:8080"},
+	})
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	ctx := context.Background()
+	defer mgr.Close(ctx)
+
+	// Two operations of the VU in flight on the same session.
+	first, err := mgr.Acquire(ctx, "vu_1")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	second, err := mgr.Acquire(ctx, "vu_1")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	if second.ID != first.ID {
+		t.Fatal("expected the VU's operations to share its session")
+	}
+	mgr.Release(ctx, first)
+
+	replacement, err := mgr.Acquire(ctx, "vu_1")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	if replacement.ID == first.ID {
+		t.Fatal("expected the session to be churned after its interval")
+	}
+	conn := first.Connection.(*mockChurnConnection)
+	if conn.closed.Load() {
+		t.Fatal("churned session closed while an operation still uses it")
+	}
+
+	mgr.Release(ctx, second)
+	if !conn.closed.Load() {
+		t.Error("expected the churned session closed once its last operation released it")
+	}
+	mgr.Release(ctx, replacement)
+}
+
+func TestEngine_ChurnReportsInitialize(t *testing.T) {
+	config := createTestConfig(t)
+	adapter := &mockChurnAdapter{}
+	mgr, err := session.NewManager(&session.SessionConfig{
+		Mode:             session.ModeChurn,
+		ChurnIntervalOps: 2,
+		Adapter:          adapter,
+		TransportConfig:  &transport.TransportConfig{Endpoint: "http://localhost:8080"},
+	})
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	config.SessionManager = mgr
+	config.Load.TargetVUs = 1
+	config.InFlightPerVU = 1
+
+	engine, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := engine.Start(ctx); err != nil {
+		t.Fatalf("failed to start engine: %v", err)
+	}
+
+	var initializes, others int
+	resultsDone := make(chan struct{})
+	go func() {
+		for result := range engine.Results() {
+			if result.Operation != OpInitialize {
+				others++
+				continue
+			}
+			initializes++
+			if result.SessionID == "" || result.Outcome == nil || !result.Outcome.OK {
+				t.Errorf("unexpected initialize result %+v", result)
+			}
+		}
+		close(resultsDone)
+	}()
+
+	time.Sleep(200 * time.Millisecond)
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer stopCancel()
+	if err := engine.Stop(stopCtx); err != nil {
+		t.Errorf("failed to stop engine: %v", err)
+	}
+	<-resultsDone
+
+	if initializes < 2 {
+		t.Fatalf("expected an initialize result per churned session, got %d", initializes)
+	}
+	if int64(initializes) != adapter.connectCount.Load() {
+		t.Errorf("expected %d initialize results, one per session, got %d", adapter.connectCount.Load(), initializes)
+	}
+	if want := (others + 1) / 2; initializes < want {
+		t.Errorf("expected a session every 2 operations: %d initializes for %d operations", initializes, others)
+	}
+}
@@ -16,6 +16,7 @@ type Engine struct {
 	rateLimiter *RateLimiter
 	metrics     *VUMetrics
 	resultChan  chan *OperationResult
+	gate        *pauseGate
 
 	vus       map[string]*VUInstance
 	executors map[string]*VUExecutor
@@ -47,6 +48,10 @@ func NewEngine(config *VUConfig) (*Engine, error) {
 		config.InFlightPerVU = 1
 	}
 
+	if config.Mode == ModeArrivalRate && (config.Arrival == nil || config.Arrival.RateRPS <= 0) {
+		return nil, ErrNoArrivalRate
+	}
+
 	sampler, err := NewOperationSampler(config.OperationMix, time.Now().UnixNano())
 	if err != nil {
 		return nil, err
@@ -54,12 +59,16 @@ func NewEngine(config *VUConfig) (*Engine, error) {
 
 	rateLimiter := NewRateLimiter(config.Load.TargetRPS)
 
+	gate := &pauseGate{}
+	gate.vus.Store(int64(config.Load.TargetVUs))
+
 	return &Engine{
 		config:      config,
 		sampler:     sampler,
 		rateLimiter: rateLimiter,
 		metrics:     NewVUMetrics(),
 		resultChan:  make(chan *OperationResult, cfgpkg.DefaultChannelBufferSize),
+		gate:        gate,
 		vus:         make(map[string]*VUInstance),
 		executors:   make(map[string]*VUExecutor),
 	}, nil
@@ -80,6 +89,9 @@ func (e *Engine) Start(ctx context.Context) error {
 	case ModeSwarm:
 		e.wg.Add(1)
 		go e.runSwarmMode()
+	case ModeArrivalRate:
+		e.wg.Add(1)
+		go e.runArrivalMode()
 	default:
 		e.startNormalMode()
 	}
@@ -126,6 +138,7 @@ func (e *Engine) UpdateLoad(target LoadTarget) {
 	defer e.vuMu.Unlock()
 
 	e.config.Load = target
+	e.gate.vus.Store(int64(target.TargetVUs))
 
 	if e.rateLimiter != nil {
 		e.rateLimiter.UpdateTargetRPS(target.TargetRPS)
@@ -159,6 +172,15 @@ func (e *Engine) UpdateLoad(target LoadTarget) {
 	}
 }
 
+// UpdateOperationMix re-weights the operations sampled by all VUs, including
+// running ones. Each VU picks up the new mix on its next operation.
+func (e *Engine) UpdateOperationMix(mix *OperationMix) error {
+	if e.closed.Load() {
+		return ErrEngineClosed
+	}
+	return e.sampler.Update(mix)
+}
+
 func (e *Engine) Metrics() *VUMetrics {
 	return e.metrics
 }
@@ -190,6 +212,7 @@ func (e *Engine) spawnVULocked() {
 	seed := time.Now().UnixNano() + vuNum
 
 	vu := NewVUInstance(vuID, seed)
+	vu.Index = int(vuNum - 1)
 	e.vus[vuID] = vu
 	e.metrics.TotalVUsCreated.Add(1)
 
@@ -201,6 +224,7 @@ func (e *Engine) spawnVULocked() {
 		e.metrics,
 		e.resultChan,
 	)
+	executor.gate = e.gate
 	e.executors[vuID] = executor
 
 	e.wg.Add(1)
@@ -228,6 +252,9 @@ func (e *Engine) runSwarmMode() {
 			e.drainAllVUs()
 			return
 		case <-spawnTicker.C:
+			if e.gate.paused() {
+				continue
+			}
 			e.vuMu.Lock()
 			currentVUs := int(e.metrics.ActiveVUs.Load())
 			if currentVUs < swarmConfig.MaxConcurrentVUs && currentVUs < e.config.Load.TargetVUs {
@@ -244,6 +271,7 @@ func (e *Engine) spawnSwarmVULocked(lifetimeMs int64) {
 	seed := time.Now().UnixNano() + vuNum
 
 	vu := NewVUInstance(vuID, seed)
+	vu.Index = int(vuNum - 1)
 	e.vus[vuID] = vu
 	e.metrics.TotalVUsCreated.Add(1)
 
@@ -255,6 +283,7 @@ func (e *Engine) spawnSwarmVULocked(lifetimeMs int64) {
 		e.metrics,
 		e.resultChan,
 	)
+	executor.gate = e.gate
 	e.executors[vuID] = executor
 
 	vuCtx, vuCancel := context.WithTimeout(e.ctx, time.Duration(lifetimeMs)*time.Millisecond)
@@ -3,13 +3,19 @@ package vu
 import (
 	"context"
 	"encoding/json"
+	"math/rand"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/bc-dunia/mcpdrill/internal/checks"
+	"github.com/bc-dunia/mcpdrill/internal/datafeed"
+	"github.com/bc-dunia/mcpdrill/internal/fuzz"
 	"github.com/bc-dunia/mcpdrill/internal/session"
 	"github.com/bc-dunia/mcpdrill/internal/transport"
+	"github.com/bc-dunia/mcpdrill/internal/types"
 )
 
 type mockAdapter struct {
@@ -274,6 +280,47 @@ func TestOperationSampler_EmptyMix(t *testing.T) {
 	}
 }
 
+func TestOperationSampler_Update(t *testing.T) {
+	mix := &OperationMix{
+		Operations: []OperationWeight{
+			{Operation: OpToolsList, Weight: 10},
+			{Operation: OpPing, Weight: 10},
+		},
+	}
+
+	sampler, err := NewOperationSampler(mix, 12345)
+	if err != nil {
+		t.Fatalf("failed to create sampler: %v", err)
+	}
+
+	err = sampler.Update(&OperationMix{
+		Operations: []OperationWeight{
+			{Operation: OpToolsList, Weight: 0},
+			{Operation: OpPing, Weight: 3},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to update sampler: %v", err)
+	}
+
+	if sampler.TotalWeight() != 3 {
+		t.Errorf("expected total weight 3, got %d", sampler.TotalWeight())
+	}
+
+	for i := 0; i < 1000; i++ {
+		if op := sampler.Sample(); op.Operation != OpPing {
+			t.Fatalf("expected only ping after update, got %s", op.Operation)
+		}
+	}
+
+	if err := sampler.Update(&OperationMix{Operations: []OperationWeight{{Operation: OpPing, Weight: 0}}}); err != ErrNoOperations {
+		t.Errorf("expected ErrNoOperations for zero total weight, got %v", err)
+	}
+	if sampler.TotalWeight() != 3 {
+		t.Errorf("expected rejected update to keep total weight 3, got %d", sampler.TotalWeight())
+	}
+}
+
 func TestThinkTimeSampler(t *testing.T) {
 	config := ThinkTimeConfig{BaseMs: 100, JitterMs: 50}
 	sampler := NewThinkTimeSampler(config, 12345)
@@ -294,6 +341,59 @@ func TestThinkTimeSampler(t *testing.T) {
 	}
 }
 
+func TestThinkTimeSampler_Distributions(t *testing.T) {
+	uniform := NewThinkTimeSampler(ThinkTimeConfig{Distribution: ThinkTimeUniform, BaseMs: 100, JitterMs: 50}, 1)
+	var sum int64
+	for i := 0; i < 1000; i++ {
+		thinkTime := uniform.Sample()
+		if thinkTime < 50 || thinkTime > 150 {
+			t.Fatalf("uniform think time %d outside [50, 150]", thinkTime)
+		}
+		sum += thinkTime
+	}
+	if mean := sum / 1000; mean < 90 || mean > 110 {
+		t.Errorf("expected uniform think times to average about 100, got %d", mean)
+	}
+
+	exponential := NewThinkTimeSampler(ThinkTimeConfig{Distribution: ThinkTimeExponential, BaseMs: 100}, 1)
+	sum = 0
+	for i := 0; i < 1000; i++ {
+		thinkTime := exponential.Sample()
+		if thinkTime < 0 || thinkTime > 1000 {
+			t.Fatalf("exponential think time %d outside [0, 1000]", thinkTime)
+		}
+		sum += thinkTime
+	}
+	if mean := sum / 1000; mean < 85 || mean > 115 {
+		t.Errorf("expected exponential think times to average about 100, got %d", mean)
+	}
+
+	capped := NewThinkTimeSampler(ThinkTimeConfig{BaseMs: 100, JitterMs: 50, MaxMs: 120}, 1)
+	for i := 0; i < 100; i++ {
+		if thinkTime := capped.Sample(); thinkTime > 120 {
+			t.Fatalf("think time %d above max_ms 120", thinkTime)
+		}
+	}
+}
+
+func TestVUExecutor_ToolThinkTime(t *testing.T) {
+	vu := &VUInstance{ID: "vu_1", RNGSeed: 7}
+	executor := NewVUExecutor(vu, &VUConfig{
+		ThinkTime:     ThinkTimeConfig{BaseMs: 10},
+		ToolThinkTime: map[string]ThinkTimeConfig{"search": {BaseMs: 2000}},
+	}, nil, nil, NewVUMetrics(), nil)
+
+	if got := executor.thinkTime(&OperationWeight{Operation: OpToolsCall, ToolName: "search"}); got != 2*time.Second {
+		t.Errorf("expected the search tool's think time, got %v", got)
+	}
+	if got := executor.thinkTime(&OperationWeight{Operation: OpToolsCall, ToolName: "fetch"}); got != 10*time.Millisecond {
+		t.Errorf("expected the VU's think time for an unpaced tool, got %v", got)
+	}
+	if got := executor.thinkTime(&OperationWeight{Operation: OpPing}); got != 10*time.Millisecond {
+		t.Errorf("expected the VU's think time for ping, got %v", got)
+	}
+}
+
 func TestRateLimiter_Disabled(t *testing.T) {
 	limiter := NewRateLimiter(0)
 
@@ -668,6 +768,33 @@ func TestEngine_UpdateLoad(t *testing.T) {
 	}
 }
 
+func TestEngine_UpdateOperationMix(t *testing.T) {
+	config := createTestConfig(t)
+
+	engine, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+
+	updated := &OperationMix{Operations: []OperationWeight{{Operation: OpPing, Weight: 1}}}
+	if err := engine.UpdateOperationMix(updated); err != nil {
+		t.Fatalf("failed to update operation mix: %v", err)
+	}
+	if engine.sampler.OperationCount() != 1 {
+		t.Errorf("expected 1 operation after update, got %d", engine.sampler.OperationCount())
+	}
+
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer stopCancel()
+	if err := engine.Stop(stopCtx); err != nil {
+		t.Fatalf("failed to stop engine: %v", err)
+	}
+
+	if err := engine.UpdateOperationMix(updated); err != ErrEngineClosed {
+		t.Errorf("expected ErrEngineClosed after stop, got %v", err)
+	}
+}
+
 func TestEngine_RateShaping(t *testing.T) {
 	config := createTestConfig(t)
 	config.Load.TargetVUs = 5
@@ -901,3 +1028,429 @@ func TestVUEngineError(t *testing.T) {
 		t.Error("Unwrap should return underlying error")
 	}
 }
+
+func TestVUExecutor_RenderCall(t *testing.T) {
+	config := createTestConfig(t)
+	executor := NewVUExecutor(NewVUInstance("vu_1", 42), config, nil, nil, &VUMetrics{}, nil)
+
+	static := &OperationWeight{Operation: OpToolsCall, ToolName: "echo", Arguments: map[string]interface{}{"message": "hi"}}
+	call, err := executor.renderCall(static)
+	if err != nil || call.args["message"] != "hi" {
+		t.Fatalf("static arguments: %v, %v", call.args, err)
+	}
+
+	templated := &OperationWeight{Operation: OpToolsCall, ToolName: "echo", Arguments: map[string]interface{}{
+		"request": "${uuid()}",
+		"label":   "${vu_id}:${iteration}:${run_id}",
+	}}
+	first, err := executor.renderCall(templated)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	second, err := executor.renderCall(templated)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if first.args["request"] == second.args["request"] {
+		t.Errorf("expected a fresh uuid per call, got %v twice", first.args["request"])
+	}
+	if first.args["label"] != "vu_1:2:test-run" || second.args["label"] != "vu_1:3:test-run" {
+		t.Errorf("unexpected labels %v, %v", first.args["label"], second.args["label"])
+	}
+	if templated.Arguments["request"] != "${uuid()}" {
+		t.Error("operation arguments must not be modified")
+	}
+
+	broken := &OperationWeight{Operation: OpToolsCall, ToolName: "echo", Arguments: map[string]interface{}{"x": "${nope()}"}}
+	if _, err := executor.renderCall(broken); err == nil {
+		t.Error("expected an error for an unknown function")
+	}
+
+	generated := &OperationWeight{Operation: OpToolsCall, ToolName: "echo", GenerateArguments: func(rng *rand.Rand) map[string]interface{} {
+		return map[string]interface{}{"n": rng.Intn(1000), "raw": "${uuid()}"}
+	}}
+	call, err = executor.renderCall(generated)
+	if err != nil || call.args["n"] == nil {
+		t.Fatalf("generated arguments: %v, %v", call.args, err)
+	}
+	if call.args["raw"] != "${uuid()}" {
+		t.Errorf("expected generated arguments sent as drawn, got %v", call.args["raw"])
+	}
+}
+
+func TestVUExecutor_RenderCallFuzz(t *testing.T) {
+	fuzzer, err := fuzz.New(fuzz.Config{Ratio: 1, Mutations: []fuzz.Mutation{fuzz.MutationMissingRequired}})
+	if err != nil {
+		t.Fatalf("fuzz.New: %v", err)
+	}
+	config := createTestConfig(t)
+	config.Fuzzer = fuzzer
+	executor := NewVUExecutor(NewVUInstance("vu_1", 42), config, nil, nil, &VUMetrics{}, nil)
+
+	call, err := executor.renderCall(&OperationWeight{
+		Operation:         OpToolsCall,
+		ToolName:          "echo",
+		Arguments:         map[string]interface{}{"message": "hi", "count": 1},
+		RequiredArguments: []string{"message"},
+	})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if call.mutation != fuzz.MutationMissingRequired || call.args["message"] != nil || call.args["count"] != 1 {
+		t.Errorf("expected the required argument dropped, got %s: %v", call.mutation, call.args)
+	}
+
+	list, err := executor.renderCall(&OperationWeight{Operation: OpToolsList})
+	if err != nil || list.mutation != "" {
+		t.Errorf("expected only tools/call fuzzed, got %s (%v)", list.mutation, err)
+	}
+}
+
+func TestVUExecutor_CancelAfter(t *testing.T) {
+	config := createTestConfig(t)
+	config.Cancellation = &CancellationConfig{Rate: 0.5, MaxDelay: 200 * time.Millisecond}
+	executor := NewVUExecutor(NewVUInstance("vu_1", 42), config, nil, nil, &VUMetrics{}, nil)
+
+	call := &OperationWeight{Operation: OpToolsCall, ToolName: "echo"}
+	cancelled := 0
+	for i := 0; i < 1000; i++ {
+		after, ok := executor.cancelAfter(call)
+		if !ok {
+			continue
+		}
+		cancelled++
+		if after <= 0 || after > 200*time.Millisecond {
+			t.Fatalf("expected a delay up to 200ms, got %v", after)
+		}
+	}
+	if cancelled < 400 || cancelled > 600 {
+		t.Errorf("expected about half the calls cancelled, got %d of 1000", cancelled)
+	}
+	if _, ok := executor.cancelAfter(&OperationWeight{Operation: OpToolsList}); ok {
+		t.Error("expected only tools/call cancelled")
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	if newThrottle(&ThrottleConfig{Policy: ThrottleIgnore}) != nil {
+		t.Error("expected no throttle when ignoring rate limits")
+	}
+	limited := func(retryAfterMs int64) *transport.OperationOutcome {
+		return &transport.OperationOutcome{
+			Error:        &transport.OperationError{Type: transport.ErrorTypeRateLimited},
+			RetryAfterMs: retryAfterMs,
+		}
+	}
+	now := time.Now()
+	holdFor := func(th *throttle) time.Duration {
+		th.mu.Lock()
+		defer th.mu.Unlock()
+		d := th.until.Sub(now)
+		th.until = time.Time{}
+		return d
+	}
+
+	backoff := newThrottle(&ThrottleConfig{Policy: ThrottleBackoff, BaseBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond})
+	for i, want := range []time.Duration{100, 200, 300, 300} {
+		backoff.observe(limited(0), now)
+		if got := holdFor(backoff); got != want*time.Millisecond {
+			t.Errorf("backoff %d: expected %dms, got %v", i, want, got)
+		}
+	}
+	backoff.observe(&transport.OperationOutcome{OK: true}, now)
+	backoff.observe(limited(0), now)
+	if got := holdFor(backoff); got != 100*time.Millisecond {
+		t.Errorf("expected the backoff to restart after a success, got %v", got)
+	}
+
+	honor := newThrottle(&ThrottleConfig{Policy: ThrottleHonorRetryAfter, MaxBackoff: 5 * time.Second})
+	honor.observe(limited(2000), now)
+	if got := holdFor(honor); got != 2*time.Second {
+		t.Errorf("expected the Retry-After delay, got %v", got)
+	}
+	honor.observe(limited(60000), now)
+	if got := holdFor(honor); got != 5*time.Second {
+		t.Errorf("expected Retry-After capped at the max backoff, got %v", got)
+	}
+	honor.observe(limited(0), now)
+	if got := holdFor(honor); got != DefaultBaseBackoff {
+		t.Errorf("expected the base backoff without Retry-After, got %v", got)
+	}
+}
+
+func TestRetryConfig_RetryDelay(t *testing.T) {
+	failed := func(errorType transport.ErrorType, code transport.ErrorCode) *transport.OperationOutcome {
+		return &transport.OperationOutcome{Error: &transport.OperationError{Type: errorType, Code: code}}
+	}
+	cfg := &RetryConfig{MaxRetries: 3, Backoff: 100 * time.Millisecond, MaxBackoff: 250 * time.Millisecond, On: DefaultRetryClasses}
+
+	for attempt, want := range []time.Duration{100, 200, 250} {
+		delay, ok := cfg.retryDelay(attempt, failed(transport.ErrorTypeHTTP, transport.CodeHTTPServerError))
+		if !ok || delay != want*time.Millisecond {
+			t.Errorf("attempt %d: expected a retry after %dms, got %v, %v", attempt, want, delay, ok)
+		}
+	}
+	if _, ok := cfg.retryDelay(3, failed(transport.ErrorTypeConnect, transport.CodeConnectionRefused)); ok {
+		t.Error("expected no retry past max retries")
+	}
+	if _, ok := cfg.retryDelay(0, failed(transport.ErrorTypeHTTP, transport.CodeHTTPNotFound)); ok {
+		t.Error("expected no retry of a 404")
+	}
+	if _, ok := cfg.retryDelay(0, failed(transport.ErrorTypeTimeout, transport.CodeRequestTimeout)); ok {
+		t.Error("expected no retry of a timeout unless configured")
+	}
+	if _, ok := cfg.retryDelay(0, &transport.OperationOutcome{OK: true}); ok {
+		t.Error("expected no retry of a success")
+	}
+
+	limited := &RetryConfig{MaxRetries: 1, Backoff: 100 * time.Millisecond, MaxBackoff: time.Second, On: []RetryClass{RetryRateLimited}}
+	outcome := failed(transport.ErrorTypeRateLimited, transport.CodeHTTPRateLimited)
+	outcome.RetryAfterMs = 3000
+	if delay, ok := limited.retryDelay(0, outcome); !ok || delay != 3*time.Second {
+		t.Errorf("expected the retry to wait for Retry-After, got %v, %v", delay, ok)
+	}
+}
+
+func TestVUExecutor_RenderCallDataFeeds(t *testing.T) {
+	users, err := datafeed.Parse("users", datafeed.FormatCSV, datafeed.DistributionRoundRobin, "email,token\na@example.com,t1\nb@example.com,t2\nc@example.com,t3\n")
+	if err != nil {
+		t.Fatalf("parse feed: %v", err)
+	}
+	docs, err := datafeed.Parse("docs", datafeed.FormatJSONL, datafeed.DistributionUnique, "{\"id\": 1}\n{\"id\": 2}\n")
+	if err != nil {
+		t.Fatalf("parse feed: %v", err)
+	}
+
+	config := createTestConfig(t)
+	config.VUIndexBase = 1
+	config.DataFeeds = map[string]*datafeed.Feed{"users": users, "docs": docs}
+	config.HeaderTemplates = map[string]string{"Authorization": "Bearer ${feed.users.token}"}
+	executor := NewVUExecutor(NewVUInstance("vu_1", 42), config, nil, nil, &VUMetrics{}, nil)
+
+	op := &OperationWeight{
+		Operation: OpResourcesRead,
+		URI:       "file:///docs/${feed.docs.id}.txt",
+		Arguments: map[string]interface{}{"email": "${feed.users.email}", "again": "${feed.users.email}"},
+	}
+	want := []struct{ email, token string }{{"b@example.com", "t2"}, {"c@example.com", "t3"}, {"a@example.com", "t1"}}
+	for i, w := range want {
+		call, err := executor.renderCall(op)
+		if err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+		if call.args["email"] != w.email || call.args["again"] != w.email {
+			t.Errorf("call %d: args = %v, want email %s", i, call.args, w.email)
+		}
+		if call.headers["Authorization"] != "Bearer "+w.token {
+			t.Errorf("call %d: headers = %v", i, call.headers)
+		}
+		if call.uri != "file:///docs/2.txt" {
+			t.Errorf("call %d: uri = %s, want the unique row of VU index 1", i, call.uri)
+		}
+	}
+
+	config.VUIndexBase = 5
+	if _, err := executor.renderCall(op); err == nil || !strings.Contains(err.Error(), "not enough") {
+		t.Errorf("expected an error once unique rows run out, got %v", err)
+	}
+}
+
+func TestVUExecutor_EvaluateChecks(t *testing.T) {
+	config := createTestConfig(t)
+	metrics := &VUMetrics{}
+	executor := NewVUExecutor(NewVUInstance("vu_1", 42), config, nil, nil, metrics, nil)
+	isError := false
+	op := &OperationWeight{Operation: OpToolsCall, ToolName: "echo", Checks: checks.Compile([]types.CheckConfig{
+		{Name: "greeting", JSONPath: "$.content[0].text", Equals: json.RawMessage(`"hello"`)},
+		{IsError: &isError},
+		{MaxLatencyMs: 10},
+	})}
+
+	// A summarized outcome no longer carries the result; checks see the raw one.
+	raw := json.RawMessage(`{"content":[{"type":"text","text":"hello"}],"isError":false}`)
+	outcome := &transport.OperationOutcome{
+		OK:            true,
+		LatencyMs:     25,
+		ResultSummary: &transport.ResultSummary{SizeBytes: int64(len(raw))},
+	}
+	results := executor.evaluateChecks(op, outcome, raw)
+
+	if len(results) != 3 || !results[0].Passed || !results[1].Passed || results[2].Passed {
+		t.Fatalf("unexpected check results: %+v", results)
+	}
+	if results[2].Name != "max_latency_ms=10" || results[2].Message != "latency 25 ms" {
+		t.Errorf("unexpected latency check result: %+v", results[2])
+	}
+	if got := metrics.FailedChecks.Load(); got != 1 {
+		t.Errorf("FailedChecks = %d, want 1", got)
+	}
+	if !outcome.OK {
+		t.Error("check failures must not change the outcome")
+	}
+}
+
+func TestEngine_Workflow(t *testing.T) {
+	mustPath := func(src string) *checks.Path {
+		p, err := checks.ParsePath(src)
+		if err != nil {
+			t.Fatalf("ParsePath(%q): %v", src, err)
+		}
+		return p
+	}
+	config := createTestConfig(t)
+	config.Load.TargetVUs = 1
+	config.ThinkTime = ThinkTimeConfig{BaseMs: 5}
+	config.OperationMix = &OperationMix{Operations: []OperationWeight{
+		{Weight: 1, Workflow: &Workflow{Name: "discover", Steps: []WorkflowStep{
+			{Name: "list", Op: OperationWeight{Operation: OpToolsList}, Extract: map[string]*checks.Path{"tool": mustPath("$.tools[*].name")}},
+			{Name: "call", Op: OperationWeight{Operation: OpToolsCall, ToolName: "${vars.tool}"}},
+		}}},
+		{Weight: 1, Workflow: &Workflow{Name: "broken", Steps: []WorkflowStep{
+			{Name: "list", Op: OperationWeight{Operation: OpToolsList}, Extract: map[string]*checks.Path{"tool": mustPath("$.missing")}},
+			{Name: "call", Op: OperationWeight{Operation: OpToolsCall, ToolName: "${vars.tool}"}},
+		}}},
+	}}
+
+	engine, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	config.SessionManager.(*session.Manager).Start(ctx)
+	if err := engine.Start(ctx); err != nil {
+		t.Fatalf("failed to start engine: %v", err)
+	}
+
+	var results []*OperationResult
+	resultsDone := make(chan struct{})
+	go func() {
+		for result := range engine.Results() {
+			results = append(results, result)
+		}
+		close(resultsDone)
+	}()
+	time.Sleep(150 * time.Millisecond)
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer stopCancel()
+	if err := engine.Stop(stopCtx); err != nil {
+		t.Errorf("failed to stop engine: %v", err)
+	}
+	<-resultsDone
+
+	completed, extractFailed := 0, 0
+	for _, result := range results {
+		w := result.Workflow
+		if w == nil {
+			t.Fatalf("expected every operation to be a workflow step, got %+v", result)
+		}
+		switch {
+		case w.Name == "discover" && w.Index == 0:
+			if result.Operation != OpToolsList || w.Step != "list" || w.Status != "" {
+				t.Errorf("unexpected first step of discover: %+v %+v", result, w)
+			}
+		case w.Name == "discover" && w.Index == 1:
+			if result.ToolName != "echo" || w.Status != types.WorkflowCompleted {
+				t.Errorf("expected the extracted tool to be called, got %+v %+v", result, w)
+			}
+			completed++
+		case w.Name == "broken" && w.Index == 0:
+			if w.Status != types.WorkflowExtractFailed {
+				t.Errorf("expected the broken workflow to stop at its extraction, got %+v", w)
+			}
+			extractFailed++
+		default:
+			t.Errorf("unexpected step %+v", w)
+		}
+	}
+	if completed == 0 || extractFailed == 0 {
+		t.Errorf("expected both workflows to run, got %d completed and %d failed extractions", completed, extractFailed)
+	}
+}
+
+func TestEngine_PauseHoldsOperationsAndKeepsSessions(t *testing.T) {
+	config := createTestConfig(t)
+	config.ThinkTime = ThinkTimeConfig{BaseMs: 5}
+
+	engine, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	config.SessionManager.(*session.Manager).Start(ctx)
+	if err := engine.Start(ctx); err != nil {
+		t.Fatalf("failed to start engine: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	engine.Pause()
+	if !engine.Paused() {
+		t.Fatal("expected the engine paused")
+	}
+	// Let operations in flight finish before counting.
+	time.Sleep(50 * time.Millisecond)
+	paused := engine.MetricsSnapshot().TotalOperations
+	time.Sleep(100 * time.Millisecond)
+	if ops := engine.MetricsSnapshot().TotalOperations; ops != paused {
+		t.Errorf("expected no operations while paused, got %d more", ops-paused)
+	}
+	if vus := engine.ActiveVUs(); vus != 2 {
+		t.Errorf("expected the paused VUs kept, got %d active", vus)
+	}
+
+	engine.Resume(20 * time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	if ops := engine.MetricsSnapshot().TotalOperations; ops <= paused {
+		t.Error("expected operations to resume")
+	}
+
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer stopCancel()
+	if err := engine.Stop(stopCtx); err != nil {
+		t.Errorf("failed to stop engine: %v", err)
+	}
+}
+
+func TestPauseGate_Shed(t *testing.T) {
+	var nilGate *pauseGate
+	if nilGate.sheds(0.9) || nilGate.shedArrival() || nilGate.shedRate() != 0 {
+		t.Error("a nil gate never sheds")
+	}
+
+	g := &pauseGate{}
+	g.setShed(0.3)
+	shed := 0
+	for i := 0; i < 10; i++ {
+		if g.sheds(float64(i) / 10) {
+			shed++
+			if i < 7 {
+				t.Errorf("expected the VUs with the highest shares shed first, VU %d was", i)
+			}
+		}
+	}
+	if shed != 3 {
+		t.Errorf("expected 3 of 10 VUs shed, got %d", shed)
+	}
+
+	g.setShed(0.25)
+	arrivals := 0
+	for i := 0; i < 100; i++ {
+		if g.shedArrival() {
+			arrivals++
+		}
+	}
+	if arrivals != 25 {
+		t.Errorf("expected 25 of 100 arrivals shed, got %d", arrivals)
+	}
+
+	g.setShed(1.5)
+	if g.shedRate() != 1 {
+		t.Errorf("expected the shed rate capped at 1, got %v", g.shedRate())
+	}
+	g.setShed(0)
+	if g.sheds(0.99) || g.shedArrival() {
+		t.Error("expected nothing shed at rate 0")
+	}
+}
@@ -4,15 +4,24 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"hash/fnv"
 	"log"
+	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/bc-dunia/mcpdrill/internal/checks"
+	"github.com/bc-dunia/mcpdrill/internal/datafeed"
 	"github.com/bc-dunia/mcpdrill/internal/events"
+	"github.com/bc-dunia/mcpdrill/internal/fuzz"
 	"github.com/bc-dunia/mcpdrill/internal/otel"
 	"github.com/bc-dunia/mcpdrill/internal/plugin"
 	"github.com/bc-dunia/mcpdrill/internal/session"
+	"github.com/bc-dunia/mcpdrill/internal/templating"
 	"github.com/bc-dunia/mcpdrill/internal/transport"
+	"github.com/bc-dunia/mcpdrill/internal/types"
 	"go.opentelemetry.io/otel/attribute"
 )
 
@@ -21,14 +30,29 @@ type VUExecutor struct {
 	config           *VUConfig
 	sampler          *OperationSampler
 	thinkTimeSampler *ThinkTimeSampler
-	rateLimiter      *RateLimiter
-	inFlightLimiter  *InFlightLimiter
-	metrics          *VUMetrics
-	resultChan       chan<- *OperationResult
-	tracer           *otel.Tracer
-	userJourney      *UserJourneyExecutor
-	sessionMode      session.SessionMode
-	wg               sync.WaitGroup
+	// toolThinkTime are the samplers of the tools the config paces.
+	toolThinkTime   map[string]*ThinkTimeSampler
+	rateLimiter     *RateLimiter
+	inFlightLimiter *InFlightLimiter
+	metrics         *VUMetrics
+	resultChan      chan<- *OperationResult
+	tracer          *otel.Tracer
+	userJourney     *UserJourneyExecutor
+	sessionMode     session.SessionMode
+	gate            *pauseGate // Engine's pause gate, nil outside an engine
+	wg              sync.WaitGroup
+
+	// iteration counts the operations started by this VU; argRand feeds the
+	// argument templates and is guarded by argMu.
+	iteration atomic.Int64
+	argMu     sync.Mutex
+	argRand   *rand.Rand
+	// cancelRand picks the calls to cancel, apart from argRand so enabling
+	// cancellation does not change the arguments sent. Guarded by argMu.
+	cancelRand *rand.Rand
+	// throttle holds the VU back after rate limited responses, nil if the
+	// VU ignores them.
+	throttle *throttle
 }
 
 func NewVUExecutor(
@@ -48,6 +72,7 @@ func NewVUExecutor(
 		config:           config,
 		sampler:          sampler,
 		thinkTimeSampler: NewThinkTimeSampler(config.ThinkTime, vu.RNGSeed+1),
+		toolThinkTime:    newToolThinkTimeSamplers(config.ToolThinkTime, vu.RNGSeed+1),
 		rateLimiter:      rateLimiter,
 		inFlightLimiter:  NewInFlightLimiter(config.InFlightPerVU),
 		metrics:          metrics,
@@ -55,6 +80,7 @@ func NewVUExecutor(
 		tracer:           otel.GetGlobalTracer(),
 		userJourney:      NewUserJourneyExecutor(config.UserJourney, vu.RNGSeed+2),
 		sessionMode:      mode,
+		throttle:         newThrottle(config.Throttle),
 	}
 }
 
@@ -122,6 +148,22 @@ func (e *VUExecutor) Run(ctx context.Context) {
 			return
 		}
 
+		if e.gate.paused() && !e.gate.wait(ctx, e.resumeShare()) {
+			continue
+		}
+
+		if e.gate.shedRate() > 0 && e.gate.sheds(e.resumeShare()) {
+			// Idle for the think time the skipped operation would have
+			// been followed by, so shed VUs do not spin.
+			e.metrics.ShedOperations.Add(1)
+			hold := max(time.Duration(e.thinkTimeSampler.Sample())*time.Millisecond, shedHold)
+			select {
+			case <-ctx.Done():
+			case <-time.After(hold):
+			}
+			continue
+		}
+
 		if e.sessionMode == session.ModeReuse && reuseSess != nil {
 			if reuseSess.IsExpired() || reuseSess.GetState() == session.StateClosed || reuseSess.GetState() == session.StateExpired {
 				e.wg.Wait()
@@ -158,6 +200,8 @@ func (e *VUExecutor) Run(ctx context.Context) {
 			}
 		}
 
+		throttleWait := e.throttle.wait(ctx)
+
 		if e.rateLimiter != nil && e.rateLimiter.Enabled() {
 			if err := e.rateLimiter.Acquire(ctx); err != nil {
 				continue
@@ -169,6 +213,7 @@ func (e *VUExecutor) Run(ctx context.Context) {
 		}
 
 		op := e.sampler.Sample()
+		thinkTime := e.thinkTime(op)
 
 		currentSess := reuseSess
 		e.wg.Add(1)
@@ -186,7 +231,8 @@ func (e *VUExecutor) Run(ctx context.Context) {
 				acquireEnd := time.Now()
 				if err != nil {
 					if e.shouldEmitSessionAcquireError(err) {
-						e.emitSessionAcquireError(op.Operation, op.ToolName, err, acquireStart, acquireEnd)
+						operation, toolName := op.firstOperation()
+						e.emitSessionAcquireError(operation, toolName, err, acquireStart, acquireEnd)
 					}
 					return
 				}
@@ -195,20 +241,59 @@ func (e *VUExecutor) Run(ctx context.Context) {
 			if shouldRelease {
 				defer e.releaseSession(ctx, opSess)
 			}
-			e.executeOperation(ctx, opSess, op)
+			e.execute(ctx, opSess, op, throttleWait, thinkTime)
 		}(op, currentSess)
 
-		thinkTime := e.thinkTimeSampler.Sample()
 		if thinkTime > 0 {
-			e.metrics.ThinkTimeTotal.Add(thinkTime)
+			e.metrics.ThinkTimeTotal.Add(thinkTime.Milliseconds())
 			select {
 			case <-ctx.Done():
-			case <-time.After(time.Duration(thinkTime) * time.Millisecond):
+			case <-time.After(thinkTime):
 			}
 		}
 	}
 }
 
+// thinkTime draws the pause that follows op: from its tool's config if
+// the workload paces the tool, otherwise from the VU's.
+func (e *VUExecutor) thinkTime(op *OperationWeight) time.Duration {
+	sampler := e.thinkTimeSampler
+	if op.Operation == OpToolsCall {
+		if s, ok := e.toolThinkTime[op.ToolName]; ok {
+			sampler = s
+		}
+	}
+	return time.Duration(sampler.Sample()) * time.Millisecond
+}
+
+// newToolThinkTimeSamplers returns a sampler per paced tool, each seeded
+// from seed and the tool's name so tools draw apart from each other.
+func newToolThinkTimeSamplers(configs map[string]ThinkTimeConfig, seed int64) map[string]*ThinkTimeSampler {
+	if len(configs) == 0 {
+		return nil
+	}
+	samplers := make(map[string]*ThinkTimeSampler, len(configs))
+	for tool, config := range configs {
+		h := fnv.New64a()
+		h.Write([]byte(tool))
+		samplers[tool] = NewThinkTimeSampler(config, seed+int64(h.Sum64()))
+	}
+	return samplers
+}
+
+// shedHold is the least a shed VU idles before checking again.
+const shedHold = 100 * time.Millisecond
+
+// resumeShare places the VU among its assignment's VUs, from 0 to 1, which
+// decides when it comes back after a pause and whether it idles while the
+// engine sheds load.
+func (e *VUExecutor) resumeShare() float64 {
+	if e.gate == nil {
+		return 0
+	}
+	return e.gate.share(e.vu.Index)
+}
+
 func (e *VUExecutor) Stop() {
 	e.vu.SetState(StateDraining)
 }
@@ -239,6 +324,14 @@ func (e *VUExecutor) acquireSession(ctx context.Context) (*session.SessionInfo,
 		m.IncrementSessions(ctx)
 	}
 
+	// Churn mode exists to load the initialize path, so each handshake it
+	// performs is reported like an operation.
+	if e.sessionMode == session.ModeChurn {
+		if outcome := sess.TakeInitializeOutcome(); outcome != nil {
+			e.emitInitializeResult(sess, outcome)
+		}
+	}
+
 	return sess, nil
 }
 
@@ -364,6 +457,28 @@ func (e *VUExecutor) emitPeriodicToolsListResult(sess *session.SessionInfo, outc
 	}
 }
 
+func (e *VUExecutor) emitInitializeResult(sess *session.SessionInfo, outcome *transport.OperationOutcome) {
+	if e.resultChan == nil {
+		return
+	}
+
+	endTime := outcome.StartTime.Add(time.Duration(outcome.LatencyMs) * time.Millisecond)
+	result := &OperationResult{
+		Operation: OpInitialize,
+		Outcome:   outcome,
+		VUID:      e.vu.ID,
+		SessionID: sess.ID,
+		StartTime: outcome.StartTime,
+		EndTime:   endTime,
+	}
+
+	select {
+	case e.resultChan <- result:
+	default:
+		e.metrics.DroppedResults.Add(1)
+	}
+}
+
 func (e *VUExecutor) invalidateReuseSession(ctx context.Context, sess *session.SessionInfo) {
 	if sess == nil {
 		return
@@ -399,7 +514,12 @@ func (e *VUExecutor) releaseSession(ctx context.Context, sess *session.SessionIn
 	}
 }
 
-func (e *VUExecutor) executeOperation(ctx context.Context, sess *session.SessionInfo, op *OperationWeight) {
+// executeOperation runs op on sess and emits its result. throttleWait is
+// how long the VU was held back by throttling before it, and thinkTime how
+// long it pauses after starting it. With a retry
+// policy, failed attempts are retried with the same input, and each
+// attempt's result is emitted with its index.
+func (e *VUExecutor) executeOperation(ctx context.Context, sess *session.SessionInfo, op *OperationWeight, throttleWait, thinkTime time.Duration) {
 	e.metrics.TotalOperations.Add(1)
 	e.metrics.InFlightOperations.Add(1)
 	defer e.metrics.InFlightOperations.Add(-1)
@@ -453,6 +573,10 @@ func (e *VUExecutor) executeOperation(ctx context.Context, sess *session.Session
 				EndTime:   endTime,
 				TraceID:   traceID,
 				SpanID:    spanID,
+
+				ThrottleWait: throttleWait,
+				ThinkTime:    thinkTime,
+				Workflow:     op.step.info(types.WorkflowFailed),
 			}
 			select {
 			case e.resultChan <- result:
@@ -495,6 +619,10 @@ func (e *VUExecutor) executeOperation(ctx context.Context, sess *session.Session
 				EndTime:   endTime,
 				TraceID:   traceID,
 				SpanID:    spanID,
+
+				ThrottleWait: throttleWait,
+				ThinkTime:    thinkTime,
+				Workflow:     op.step.info(types.WorkflowFailed),
 			}
 			select {
 			case e.resultChan <- result:
@@ -505,18 +633,28 @@ func (e *VUExecutor) executeOperation(ctx context.Context, sess *session.Session
 		return
 	}
 
-	params := buildOperationParams(op)
+	call, validationErr := e.renderCall(op)
+	var errorCode transport.ErrorCode = "TEMPLATE_ERROR"
+	if call.mutation != "" {
+		span.SetAttributes(attribute.String("fuzz.mutation", string(call.mutation)))
+	}
+	params := buildOperationParams(op, call)
+	args := call.args
 
 	var toolMetrics *ToolCallMetrics
 	if op.Operation == OpToolsCall {
 		toolMetrics = &ToolCallMetrics{
 			ToolName:      op.ToolName,
-			ArgumentSize:  calculateArgumentSize(op.Arguments),
-			ArgumentDepth: calculateArgumentDepth(op.Arguments),
+			ArgumentSize:  calculateArgumentSize(args),
+			ArgumentDepth: calculateArgumentDepth(args),
 		}
 	}
 
-	if validationErr := registeredOp.Validate(params); validationErr != nil {
+	if validationErr == nil {
+		validationErr = registeredOp.Validate(params)
+		errorCode = "VALIDATION_ERROR"
+	}
+	if validationErr != nil {
 		e.metrics.FailedOperations.Add(1)
 		e.vu.OperationsFailed.Add(1)
 		e.userJourney.RecordOperationResult(false)
@@ -537,23 +675,27 @@ func (e *VUExecutor) executeOperation(ctx context.Context, sess *session.Session
 			OK:        false,
 			Error: &transport.OperationError{
 				Type:    transport.ErrorTypeProtocol,
-				Code:    "VALIDATION_ERROR",
+				Code:    errorCode,
 				Message: validationErr.Error(),
 			},
 		}
 
 		if e.resultChan != nil {
 			result := &OperationResult{
-				Operation:   op.Operation,
-				ToolName:    op.ToolName,
-				Outcome:     validationOutcome,
-				VUID:        e.vu.ID,
-				SessionID:   sess.ID,
-				StartTime:   startTime,
-				EndTime:     endTime,
-				TraceID:     traceID,
-				SpanID:      spanID,
-				ToolMetrics: toolMetrics,
+				Operation:    op.Operation,
+				ToolName:     op.ToolName,
+				Outcome:      validationOutcome,
+				VUID:         e.vu.ID,
+				SessionID:    sess.ID,
+				StartTime:    startTime,
+				EndTime:      endTime,
+				TraceID:      traceID,
+				SpanID:       spanID,
+				ToolMetrics:  toolMetrics,
+				FuzzMutation: call.mutation,
+				ThrottleWait: throttleWait,
+				ThinkTime:    thinkTime,
+				Workflow:     op.step.info(types.WorkflowFailed),
 			}
 
 			select {
@@ -565,91 +707,172 @@ func (e *VUExecutor) executeOperation(ctx context.Context, sess *session.Session
 		return
 	}
 
-	outcome, err = registeredOp.Execute(ctx, conn, params)
-
-	endTime := time.Now()
+	// Only the first attempt is shadowed, so retries against the target do
+	// not multiply the shadow's calls.
+	shadow := e.startShadow(ctx, registeredOp, params, call.headers)
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			e.metrics.TotalOperations.Add(1)
+			e.metrics.RetryAttempts.Add(1)
+			startTime = time.Now()
+			throttleWait = 0
+			thinkTime = 0
+			if toolMetrics != nil {
+				retryMetrics := *toolMetrics
+				retryMetrics.ExecutionError = false
+				retryMetrics.ResultSize = 0
+				toolMetrics = &retryMetrics
+			}
+		}
 
-	if outcome == nil && err == nil {
-		err = errors.New("plugin returned nil outcome without error")
-	}
+		execCtx := transport.WithRequestHeaders(ctx, call.headers)
+		var rawResult json.RawMessage
+		if len(op.Checks) > 0 || op.step != nil {
+			execCtx = transport.WithResultObserver(execCtx, func(result json.RawMessage) { rawResult = result })
+		}
+		if after, ok := e.cancelAfter(op); ok {
+			execCtx = transport.WithCancellation(execCtx, after, e.config.Cancellation.Grace)
+		}
+		outcome, err = registeredOp.Execute(execCtx, conn, params)
 
-	if err != nil || (outcome != nil && !outcome.OK) {
-		e.metrics.FailedOperations.Add(1)
-		e.vu.OperationsFailed.Add(1)
-		e.userJourney.RecordOperationResult(false)
+		endTime := time.Now()
+		e.throttle.observe(outcome, endTime)
 
-		if toolMetrics != nil {
-			toolMetrics.ExecutionError = true
+		if outcome == nil && err == nil {
+			err = errors.New("plugin returned nil outcome without error")
 		}
 
-		if outcome != nil && outcome.Error != nil {
-			otel.RecordError(span, err, string(outcome.Error.Type), false)
-			if outcome.Error.Type == transport.ErrorTypeRateLimited {
-				e.metrics.RateLimitedOperations.Add(1)
+		failed := err != nil || (outcome != nil && !outcome.OK)
+		if failed {
+			e.metrics.FailedOperations.Add(1)
+			e.vu.OperationsFailed.Add(1)
+			e.userJourney.RecordOperationResult(false)
+
+			if toolMetrics != nil {
+				toolMetrics.ExecutionError = true
+			}
+
+			if outcome != nil && outcome.Error != nil {
+				otel.RecordError(span, err, string(outcome.Error.Type), false)
+				if outcome.Error.Type == transport.ErrorTypeRateLimited {
+					e.metrics.RateLimitedOperations.Add(1)
+				}
+			} else if err != nil {
+				otel.RecordError(span, err, "internal", false)
 			}
-		} else if err != nil {
-			otel.RecordError(span, err, "internal", false)
+		} else {
+			e.metrics.SuccessfulOperations.Add(1)
+			e.vu.OperationsCompleted.Add(1)
+			e.userJourney.RecordOperationResult(true)
+			span.SetAttributes(attribute.Bool("ok", true))
 		}
-	} else {
-		e.metrics.SuccessfulOperations.Add(1)
-		e.vu.OperationsCompleted.Add(1)
-		e.userJourney.RecordOperationResult(true)
-		span.SetAttributes(attribute.Bool("ok", true))
-	}
 
-	if toolMetrics != nil && outcome != nil {
-		toolMetrics.ResultSize = int(outcome.BytesIn)
-	}
+		if toolMetrics != nil && outcome != nil {
+			toolMetrics.ResultSize = int(outcome.BytesIn)
+		}
 
-	if outcome != nil {
-		span.SetAttributes(
-			attribute.Int64("latency_ms", outcome.LatencyMs),
-			attribute.Int64("bytes_in", outcome.BytesIn),
-			attribute.Int64("bytes_out", outcome.BytesOut),
-		)
-		if outcome.HTTPStatus != nil {
-			span.SetAttributes(attribute.Int("http.status_code", *outcome.HTTPStatus))
+		if outcome != nil {
+			span.SetAttributes(
+				attribute.Int64("latency_ms", outcome.LatencyMs),
+				attribute.Int64("bytes_in", outcome.BytesIn),
+				attribute.Int64("bytes_out", outcome.BytesOut),
+			)
+			if outcome.HTTPStatus != nil {
+				span.SetAttributes(attribute.Int("http.status_code", *outcome.HTTPStatus))
+			}
 		}
-	}
 
-	if mgr, ok := e.config.SessionManager.(*session.Manager); ok {
-		sess.Touch(mgr.Config().MaxIdleMs)
-	}
+		if mgr, ok := e.config.SessionManager.(*session.Manager); ok {
+			sess.Touch(mgr.Config().MaxIdleMs)
+		}
 
-	traceID, spanID := otel.GetTraceInfo(ctx)
+		traceID, spanID := otel.GetTraceInfo(ctx)
 
-	// Record operation latency and errors in OTel metrics
-	if m := otel.GetGlobalMetrics(); m != nil {
-		if outcome != nil {
-			m.RecordOperationLatency(ctx, string(op.Operation), op.ToolName, float64(outcome.LatencyMs), outcome.OK)
-			if !outcome.OK && outcome.Error != nil {
-				m.RecordError(ctx, string(outcome.Error.Type))
+		// Record operation latency and errors in OTel metrics
+		if m := otel.GetGlobalMetrics(); m != nil {
+			if outcome != nil {
+				m.RecordOperationLatency(ctx, string(op.Operation), op.ToolName, float64(outcome.LatencyMs), outcome.OK)
+				if !outcome.OK && outcome.Error != nil {
+					m.RecordError(ctx, string(outcome.Error.Type))
+				}
 			}
 		}
-	}
 
-	if e.resultChan != nil {
-		result := &OperationResult{
-			Operation:   op.Operation,
-			ToolName:    op.ToolName,
-			Outcome:     outcome,
-			VUID:        e.vu.ID,
-			SessionID:   sess.ID,
-			StartTime:   startTime,
-			EndTime:     endTime,
-			TraceID:     traceID,
-			SpanID:      spanID,
-			ToolMetrics: toolMetrics,
+		// A call the server stopped on after its cancellation has no result to
+		// check.
+		var checkResults []types.CheckResult
+		if len(op.Checks) > 0 && outcome != nil && (outcome.Cancellation == nil || !outcome.Cancellation.Honored) {
+			checkResults = e.evaluateChecks(op, outcome, rawResult)
 		}
 
+		var shadowResult *ShadowResult
+		if shadow != nil && attempt == 0 {
+			shadowResult = e.config.Shadow.compare(op, outcome, <-shadow)
+		}
+
+		delay, retry := e.config.Retry.retryDelay(attempt, outcome)
+		workflow := e.finishStep(op, failed, retry, stepResult(outcome, rawResult))
+
+		if e.resultChan != nil {
+			result := &OperationResult{
+				Operation:    op.Operation,
+				ToolName:     op.ToolName,
+				Outcome:      outcome,
+				VUID:         e.vu.ID,
+				SessionID:    sess.ID,
+				StartTime:    startTime,
+				EndTime:      endTime,
+				TraceID:      traceID,
+				SpanID:       spanID,
+				ToolMetrics:  toolMetrics,
+				Checks:       checkResults,
+				FuzzMutation: call.mutation,
+				ThrottleWait: throttleWait,
+				ThinkTime:    thinkTime,
+				Attempt:      attempt,
+				Shadow:       shadowResult,
+				Workflow:     workflow,
+			}
+
+			select {
+			case e.resultChan <- result:
+			default:
+				e.metrics.DroppedResults.Add(1)
+			}
+		}
+
+		if !retry {
+			return
+		}
 		select {
-		case e.resultChan <- result:
-		default:
-			e.metrics.DroppedResults.Add(1)
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
 		}
 	}
 }
 
+// evaluateChecks runs the operation's response checks. rawResult is the
+// result as received, which the outcome no longer holds when the result was
+// summarized.
+func (e *VUExecutor) evaluateChecks(op *OperationWeight, outcome *transport.OperationOutcome, rawResult json.RawMessage) []types.CheckResult {
+	response := &checks.Response{Result: rawResult, LatencyMs: outcome.LatencyMs}
+	if response.Result == nil {
+		response.Result = outcome.Result
+	}
+	if outcome.ResultSummary != nil {
+		response.ResultBytes = outcome.ResultSummary.SizeBytes
+	}
+	results := checks.Evaluate(op.Checks, response)
+	for _, r := range results {
+		if !r.Passed {
+			e.metrics.FailedChecks.Add(1)
+		}
+	}
+	return results
+}
+
 func (e *VUExecutor) updateMaxInFlight() {
 	current := int64(e.inFlightLimiter.Current())
 	for {
@@ -663,23 +886,159 @@ func (e *VUExecutor) updateMaxInFlight() {
 	}
 }
 
-func buildOperationParams(op *OperationWeight) map[string]interface{} {
+// cancelAfter decides whether a tools/call is cancelled in flight, and if
+// so after how long.
+func (e *VUExecutor) cancelAfter(op *OperationWeight) (time.Duration, bool) {
+	cfg := e.config.Cancellation
+	if cfg == nil || cfg.Rate <= 0 || cfg.MaxDelay <= 0 || op.Operation != OpToolsCall {
+		return 0, false
+	}
+	e.argMu.Lock()
+	defer e.argMu.Unlock()
+	if e.cancelRand == nil {
+		e.cancelRand = rand.New(rand.NewSource(e.vu.RNGSeed + 4))
+	}
+	if e.cancelRand.Float64() >= cfg.Rate {
+		return 0, false
+	}
+	return time.Duration(1 + e.cancelRand.Int63n(int64(cfg.MaxDelay))), true
+}
+
+// renderedCall is an operation's input for one call after its ${...}
+// expressions are evaluated.
+type renderedCall struct {
+	args     map[string]interface{}
+	uri      string
+	headers  map[string]string
+	mutation fuzz.Mutation
+}
+
+// renderCall evaluates the ${...} expressions in the operation's arguments
+// and URI and in the templated target headers for this call. Inputs without
+// expressions are returned as is. Every feed reference in the call reads
+// the same row of its feed. Operations with GenerateArguments get fresh
+// arguments drawn from the VU's argument RNG, sent without evaluation.
+// With a fuzzer, tools/call arguments may then be mutated; the call records
+// the mutation.
+func (e *VUExecutor) renderCall(op *OperationWeight) (renderedCall, error) {
+	iteration := e.iteration.Add(1)
+	call := renderedCall{args: op.Arguments, uri: op.URI}
+	if op.step != nil && op.step.err != nil {
+		return call, op.step.err
+	}
+	fuzzing := e.config.Fuzzer != nil && op.Operation == OpToolsCall
+	if !fuzzing && op.GenerateArguments == nil && !templating.HasExpressions(op.Arguments) && !templating.HasExpressions(op.URI) && len(e.config.HeaderTemplates) == 0 {
+		return call, nil
+	}
+
+	e.argMu.Lock()
+	defer e.argMu.Unlock()
+	argRand := e.argRNG()
+
+	var (
+		rows    = make(map[string]map[string]interface{})
+		feedErr error
+	)
+	ctx := &templating.Context{
+		Vars: map[string]interface{}{
+			templating.VarVUID:      e.vu.ID,
+			templating.VarIteration: iteration,
+			templating.VarRunID:     e.config.RunID,
+			templating.VarStageID:   e.config.StageID,
+			templating.VarWorkerID:  e.config.WorkerID,
+		},
+		Lookup: func(name string) (interface{}, bool) {
+			if op.step != nil {
+				if v, ok := op.step.lookup(name); ok {
+					return v, true
+				}
+			}
+			feedName, column, ok := datafeed.SplitReference(name)
+			feed := e.config.DataFeeds[feedName]
+			if !ok || feed == nil || !feed.HasColumn(column) {
+				return nil, false
+			}
+			row, ok := rows[feedName]
+			if !ok {
+				var err error
+				if row, err = feed.Row(e.config.VUIndexBase+e.vu.Index, iteration, argRand); err != nil {
+					feedErr = err
+					return nil, false
+				}
+				rows[feedName] = row
+			}
+			return row[column], true
+		},
+		Rand: argRand,
+	}
+	render := func(value interface{}) (interface{}, error) {
+		v, err := templating.Render(value, ctx)
+		if feedErr != nil {
+			return nil, feedErr
+		}
+		return v, err
+	}
+
+	if templating.HasExpressions(call.args) {
+		args, err := render(call.args)
+		if err != nil {
+			return renderedCall{}, err
+		}
+		call.args = args.(map[string]interface{})
+	}
+	if templating.HasExpressions(op.URI) {
+		uri, err := render(op.URI)
+		if err != nil {
+			return renderedCall{}, fmt.Errorf("uri: %w", err)
+		}
+		call.uri = fmt.Sprint(uri)
+	}
+	if len(e.config.HeaderTemplates) > 0 {
+		call.headers = make(map[string]string, len(e.config.HeaderTemplates))
+		for name, tmpl := range e.config.HeaderTemplates {
+			value, err := render(tmpl)
+			if err != nil {
+				return renderedCall{}, fmt.Errorf("header %s: %w", name, err)
+			}
+			call.headers[name] = fmt.Sprint(value)
+		}
+	}
+	if op.GenerateArguments != nil {
+		call.args = op.GenerateArguments(argRand)
+	}
+	if fuzzing {
+		call.args, call.mutation = e.config.Fuzzer.Mutate(call.args, op.RequiredArguments, argRand)
+	}
+	return call, nil
+}
+
+// argRNG returns the VU's argument RNG, creating it on first use. The
+// caller holds argMu.
+func (e *VUExecutor) argRNG() *rand.Rand {
+	if e.argRand == nil {
+		e.argRand = rand.New(rand.NewSource(e.vu.RNGSeed + 3))
+	}
+	return e.argRand
+}
+
+func buildOperationParams(op *OperationWeight, call renderedCall) map[string]interface{} {
+	args := call.args
 	params := make(map[string]interface{})
 
 	switch op.Operation {
 	case OpToolsCall:
 		params["name"] = op.ToolName
-		if len(op.Arguments) > 0 {
-			params["arguments"] = op.Arguments
+		if len(args) > 0 {
+			params["arguments"] = args
 		}
 
 	case OpResourcesRead:
-		params["uri"] = op.URI
+		params["uri"] = call.uri
 
 	case OpPromptsGet:
 		params["name"] = op.PromptName
-		if len(op.Arguments) > 0 {
-			params["arguments"] = op.Arguments
+		if len(args) > 0 {
+			params["arguments"] = args
 		}
 	}
 
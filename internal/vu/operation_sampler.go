@@ -46,11 +46,34 @@ func (s *OperationSampler) Sample() *OperationWeight {
 	return &s.mix.Operations[len(s.mix.Operations)-1]
 }
 
+// Update replaces the sampled mix. Operations already returned by Sample keep
+// pointing into the previous mix, which is never modified.
+func (s *OperationSampler) Update(mix *OperationMix) error {
+	if mix == nil || len(mix.Operations) == 0 {
+		return ErrNoOperations
+	}
+
+	totalWeight := mix.TotalWeight()
+	if totalWeight <= 0 {
+		return ErrNoOperations
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mix = mix
+	s.totalWeight = totalWeight
+	return nil
+}
+
 func (s *OperationSampler) TotalWeight() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	return s.totalWeight
 }
 
 func (s *OperationSampler) OperationCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	return len(s.mix.Operations)
 }
 
@@ -67,13 +90,34 @@ func NewThinkTimeSampler(config ThinkTimeConfig, seed int64) *ThinkTimeSampler {
 	}
 }
 
+// exponentialThinkTimeCap bounds exponential think times without a MaxMs,
+// as a multiple of their mean, so a rare long draw does not idle a VU for
+// most of a stage.
+const exponentialThinkTimeCap = 10
+
 func (s *ThinkTimeSampler) Sample() int64 {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	thinkTime := s.config.BaseMs
-	if s.config.JitterMs > 0 {
-		thinkTime += s.rng.Int63n(s.config.JitterMs)
+	maxMs := s.config.MaxMs
+	var thinkTime int64
+	switch s.config.Distribution {
+	case ThinkTimeUniform:
+		low := max(s.config.BaseMs-s.config.JitterMs, 0)
+		thinkTime = low + s.rng.Int63n(s.config.BaseMs+s.config.JitterMs-low+1)
+	case ThinkTimeExponential:
+		thinkTime = int64(s.rng.ExpFloat64() * float64(s.config.BaseMs))
+		if maxMs <= 0 {
+			maxMs = exponentialThinkTimeCap * s.config.BaseMs
+		}
+	default:
+		thinkTime = s.config.BaseMs
+		if s.config.JitterMs > 0 {
+			thinkTime += s.rng.Int63n(s.config.JitterMs)
+		}
+	}
+	if maxMs > 0 {
+		thinkTime = min(thinkTime, maxMs)
 	}
 	return thinkTime
 }
@@ -0,0 +1,202 @@
+package vu
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// minResumeRate is the share of the arrival rate offered right after a
+// resume, so the first arrival is not scheduled absurdly far out.
+const minResumeRate = 0.05
+
+// pauseGate holds VUs before their next operation while an engine is
+// paused. A nil gate is never paused.
+type pauseGate struct {
+	mu        sync.Mutex
+	resume    chan struct{} // Non-nil while paused, closed on resume
+	ramp      time.Duration
+	resumedAt time.Time
+
+	// shed is the share of the load, from 0 to 1, held back while the
+	// worker is short of resources; shedCredit spreads shed arrivals evenly.
+	shed       float64
+	shedCredit float64
+
+	// vus is the assignment's target VU count, kept apart from the engine's
+	// config so running VUs can place themselves while the load changes.
+	vus atomic.Int64
+}
+
+func (g *pauseGate) pause() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.resume == nil {
+		g.resume = make(chan struct{})
+	}
+}
+
+func (g *pauseGate) release(ramp time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.resume == nil {
+		return
+	}
+	close(g.resume)
+	g.resume = nil
+	g.ramp = ramp
+	g.resumedAt = time.Now()
+}
+
+func (g *pauseGate) paused() bool {
+	if g == nil {
+		return false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.resume != nil
+}
+
+// share places the VU at index among the assignment's VUs, from 0 to 1.
+func (g *pauseGate) share(index int) float64 {
+	n := int(g.vus.Load())
+	if n <= 1 {
+		return 0
+	}
+	return float64(index%n) / float64(n)
+}
+
+// wait blocks while the gate is paused. After a resume, a VU holding share
+// (0 to 1) of the load waits that share of the resume ramp, so VUs come
+// back one after another. It returns false if ctx is done first.
+func (g *pauseGate) wait(ctx context.Context, share float64) bool {
+	if g == nil {
+		return true
+	}
+	g.mu.Lock()
+	resume := g.resume
+	g.mu.Unlock()
+	if resume == nil {
+		return true
+	}
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-resume:
+	}
+
+	g.mu.Lock()
+	delay := time.Duration(float64(g.ramp)*share) - time.Since(g.resumedAt)
+	g.mu.Unlock()
+	if delay <= 0 {
+		return true
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (g *pauseGate) setShed(rate float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.shed = min(max(rate, 0), 1)
+}
+
+func (g *pauseGate) shedRate() float64 {
+	if g == nil {
+		return 0
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.shed
+}
+
+// sheds reports whether a VU holding share (0 to 1) of the load skips its
+// next operation: the VUs with the highest shares go idle first.
+func (g *pauseGate) sheds(share float64) bool {
+	if g == nil {
+		return false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.shed > 0 && share >= 1-g.shed
+}
+
+// shedArrival reports whether the next arrival is shed. Arrivals are shed
+// at an even pace, one in every 1/shed.
+func (g *pauseGate) shedArrival() bool {
+	if g == nil {
+		return false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.shed <= 0 {
+		g.shedCredit = 0
+		return false
+	}
+	g.shedCredit += g.shed
+	if g.shedCredit < 1 {
+		return false
+	}
+	g.shedCredit--
+	return true
+}
+
+// rate returns the share of the load to offer now: it climbs from
+// minResumeRate to 1 over the ramp after a resume, and is 1 otherwise.
+func (g *pauseGate) rate() float64 {
+	if g == nil {
+		return 1
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.ramp <= 0 || g.resumedAt.IsZero() {
+		return 1
+	}
+	elapsed := time.Since(g.resumedAt)
+	if elapsed >= g.ramp {
+		return 1
+	}
+	return max(float64(elapsed)/float64(g.ramp), minResumeRate)
+}
+
+// Pause holds every VU before its next operation until Resume. Operations
+// in flight complete, and sessions stay open, so a paused engine resumes
+// without re-initializing.
+func (e *Engine) Pause() {
+	e.gate.pause()
+}
+
+// Resume releases paused VUs, spreading them over ramp so the load climbs
+// back to its target rather than returning at once. In arrival-rate mode
+// the rate climbs back over ramp instead.
+func (e *Engine) Resume(ramp time.Duration) {
+	e.gate.release(ramp)
+}
+
+// Paused reports whether the engine is paused.
+func (e *Engine) Paused() bool {
+	return e.gate.paused()
+}
+
+// Shed holds back rate (0 to 1) of the engine's load to relieve an
+// overloaded worker. Rather than letting operations queue up behind the
+// worker and inflate their latency, the engine skips them at a steady pace
+// and counts them in ShedOperations: VUs idle from the top of the
+// assignment down, and in arrival-rate mode arrivals are dropped evenly. A
+// rate of 0 restores the full load.
+func (e *Engine) Shed(rate float64) {
+	e.gate.setShed(rate)
+}
+
+// ShedRate returns the share of the load the engine sheds.
+func (e *Engine) ShedRate() float64 {
+	return e.gate.shedRate()
+}
@@ -0,0 +1,90 @@
+package vu
+
+import (
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/transport"
+)
+
+// RetryClass is a class of errors an operation can be retried after.
+type RetryClass string
+
+const (
+	// RetryConnect covers DNS and connection failures.
+	RetryConnect RetryClass = "connect"
+	// RetryHTTP5xx covers HTTP 5xx responses.
+	RetryHTTP5xx RetryClass = "http_5xx"
+	// RetryStreamStall covers response streams that stalled.
+	RetryStreamStall RetryClass = "stream_stall"
+	// RetryTimeout covers requests that timed out.
+	RetryTimeout RetryClass = "timeout"
+	// RetryRateLimited covers rate limited responses. The delay before the
+	// retry is at least what Retry-After asked for.
+	RetryRateLimited RetryClass = "rate_limited"
+)
+
+// DefaultRetryClasses are the errors retried when no classes are set.
+var DefaultRetryClasses = []RetryClass{RetryConnect, RetryHTTP5xx, RetryStreamStall}
+
+// RetryConfig sets how a VU retries failed operations. Every attempt is
+// reported as an operation of its own, with its attempt index.
+type RetryConfig struct {
+	// MaxRetries is the number of retries after the first attempt.
+	MaxRetries int
+	// Backoff is the delay before the first retry; it doubles with every
+	// retry up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// On are the error classes retried.
+	On []RetryClass
+}
+
+// retryClass returns the class of a failed outcome's error, or "" if it
+// belongs to none.
+func retryClass(outcome *transport.OperationOutcome) RetryClass {
+	if outcome == nil || outcome.OK || outcome.Error == nil {
+		return ""
+	}
+	switch outcome.Error.Type {
+	case transport.ErrorTypeDNS, transport.ErrorTypeConnect:
+		return RetryConnect
+	case transport.ErrorTypeStreamStall:
+		return RetryStreamStall
+	case transport.ErrorTypeTimeout:
+		return RetryTimeout
+	case transport.ErrorTypeRateLimited:
+		return RetryRateLimited
+	}
+	if outcome.Error.Code == transport.CodeHTTPServerError {
+		return RetryHTTP5xx
+	}
+	return ""
+}
+
+// retryDelay decides whether the attempt-th attempt (0 for the first) of
+// an operation is retried after outcome, and if so after how long.
+func (c *RetryConfig) retryDelay(attempt int, outcome *transport.OperationOutcome) (time.Duration, bool) {
+	if c == nil || attempt >= c.MaxRetries {
+		return 0, false
+	}
+	class := retryClass(outcome)
+	if class == "" {
+		return 0, false
+	}
+	retryable := false
+	for _, on := range c.On {
+		retryable = retryable || on == class
+	}
+	if !retryable {
+		return 0, false
+	}
+	delay := c.Backoff
+	for i := 0; i < attempt && delay < c.MaxBackoff; i++ {
+		delay *= 2
+	}
+	delay = min(delay, c.MaxBackoff)
+	if class == RetryRateLimited {
+		delay = max(delay, time.Duration(outcome.RetryAfterMs)*time.Millisecond)
+	}
+	return delay, true
+}
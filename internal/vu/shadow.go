@@ -0,0 +1,124 @@
+package vu
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/plugin"
+	"github.com/bc-dunia/mcpdrill/internal/session"
+	"github.com/bc-dunia/mcpdrill/internal/transport"
+)
+
+// ShadowConfig duplicates operations to a shadow target. Each operation's
+// first attempt is sent to the target and the shadow at the same time, and
+// the VU moves on once both have answered, so the shadow never gets more
+// calls in flight than the target.
+type ShadowConfig struct {
+	// SessionManager provides the sessions on the shadow target.
+	SessionManager session.SessionManager
+	// StableTools are the tools whose results are compared.
+	StableTools map[string]bool
+}
+
+// ShadowResult is the shadow target's answer to an operation.
+type ShadowResult struct {
+	Outcome *transport.OperationOutcome
+	// ResultMatch reports whether the shadow's result equals the target's.
+	// It is nil unless the operation called a stable tool and both answered
+	// without error.
+	ResultMatch *bool
+}
+
+// startShadow sends the call to the shadow target, if the VU has one. The
+// returned channel yields the shadow's outcome; it is nil without a shadow.
+func (e *VUExecutor) startShadow(ctx context.Context, registered plugin.Operation, params map[string]interface{}, headers map[string]string) <-chan *transport.OperationOutcome {
+	if e.config.Shadow == nil {
+		return nil
+	}
+	done := make(chan *transport.OperationOutcome, 1)
+	go func() {
+		done <- e.config.Shadow.execute(ctx, e.vu.ID, registered, params, headers)
+	}()
+	return done
+}
+
+// execute runs one call on a shadow session. Failures are returned as
+// outcomes, like the target's.
+func (s *ShadowConfig) execute(ctx context.Context, vuID string, registered plugin.Operation, params map[string]interface{}, headers map[string]string) *transport.OperationOutcome {
+	start := time.Now()
+	failed := func(errorType transport.ErrorType, code transport.ErrorCode, message string) *transport.OperationOutcome {
+		if errors.Is(ctx.Err(), context.Canceled) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			errorType, code = transport.ErrorTypeCancelled, transport.CodeCancelled
+		}
+		return &transport.OperationOutcome{
+			Operation: transport.OperationType(registered.Name()),
+			StartTime: start,
+			LatencyMs: time.Since(start).Milliseconds(),
+			Error:     &transport.OperationError{Type: errorType, Code: code, Message: message},
+		}
+	}
+
+	sess, err := s.SessionManager.Acquire(ctx, vuID)
+	if err != nil {
+		return failed(transport.ErrorTypeConnect, "SESSION_ACQUIRE_FAILED", "shadow session acquire failed: "+err.Error())
+	}
+	defer s.SessionManager.Release(ctx, sess)
+	if sess.Connection == nil {
+		return failed(transport.ErrorTypeConnect, "NO_CONNECTION", "no connection available for shadow session")
+	}
+
+	outcome, err := registered.Execute(transport.WithRequestHeaders(ctx, headers), sess.Connection, params)
+	if outcome == nil {
+		message := "plugin returned nil outcome without error"
+		if err != nil {
+			message = err.Error()
+		}
+		return failed(transport.ErrorTypeProtocol, "SHADOW_FAILED", message)
+	}
+	return outcome
+}
+
+// compare pairs the shadow's outcome with the target's, comparing the
+// results of stable tools.
+func (s *ShadowConfig) compare(op *OperationWeight, target, shadow *transport.OperationOutcome) *ShadowResult {
+	result := &ShadowResult{Outcome: shadow}
+	if op.Operation != OpToolsCall || !s.StableTools[op.ToolName] {
+		return result
+	}
+	if target == nil || !target.OK || !shadow.OK {
+		return result
+	}
+	a, okA := resultDigest(target)
+	b, okB := resultDigest(shadow)
+	if okA && okB {
+		match := a == b
+		result.ResultMatch = &match
+	}
+	return result
+}
+
+// resultDigest identifies an operation's result for comparison: its JSON
+// re-encoded with sorted keys, so formatting and key order do not count, or
+// the hash of the raw result when only a summary of it was kept.
+func resultDigest(outcome *transport.OperationOutcome) (string, bool) {
+	if outcome.Result == nil {
+		if outcome.ResultSummary != nil {
+			return "sha256:" + outcome.ResultSummary.SHA256, true
+		}
+		return "", false
+	}
+	dec := json.NewDecoder(bytes.NewReader(outcome.Result))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return string(outcome.Result), true
+	}
+	canonical, err := json.Marshal(v)
+	if err != nil {
+		return string(outcome.Result), true
+	}
+	return string(canonical), true
+}
@@ -0,0 +1,106 @@
+package vu
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/session"
+	"github.com/bc-dunia/mcpdrill/internal/transport"
+)
+
+func TestShadowConfig_Compare(t *testing.T) {
+	shadow := &ShadowConfig{StableTools: map[string]bool{"echo": true}}
+	call := &OperationWeight{Operation: OpToolsCall, ToolName: "echo"}
+	ok := func(result string) *transport.OperationOutcome {
+		return &transport.OperationOutcome{OK: true, Result: json.RawMessage(result)}
+	}
+
+	r := shadow.compare(call, ok(`{"a":1,"b":[1,2]}`), ok(`{ "b": [1, 2], "a": 1 }`))
+	if r.ResultMatch == nil || !*r.ResultMatch {
+		t.Errorf("expected results differing only in formatting to match, got %v", r.ResultMatch)
+	}
+	r = shadow.compare(call, ok(`{"a":1}`), ok(`{"a":2}`))
+	if r.ResultMatch == nil || *r.ResultMatch {
+		t.Errorf("expected different results not to match, got %v", r.ResultMatch)
+	}
+	summarized := &transport.OperationOutcome{OK: true, ResultSummary: &transport.ResultSummary{SHA256: "abc"}}
+	if r = shadow.compare(call, summarized, summarized); r.ResultMatch == nil || !*r.ResultMatch {
+		t.Errorf("expected summarized results to be compared by hash, got %v", r.ResultMatch)
+	}
+
+	if r = shadow.compare(&OperationWeight{Operation: OpToolsCall, ToolName: "now"}, ok(`1`), ok(`2`)); r.ResultMatch != nil {
+		t.Error("expected tools not listed as stable not to be compared")
+	}
+	if r = shadow.compare(call, ok(`1`), &transport.OperationOutcome{}); r.ResultMatch != nil {
+		t.Error("expected failed calls not to be compared")
+	}
+	if r = shadow.compare(&OperationWeight{Operation: OpToolsList}, ok(`1`), ok(`1`)); r.ResultMatch != nil || r.Outcome == nil {
+		t.Errorf("expected only the shadow's outcome for other operations, got %+v", r)
+	}
+}
+
+func TestEngine_Shadow(t *testing.T) {
+	config := createTestConfig(t)
+	config.Load.TargetVUs = 1
+	shadowMgr, err := session.NewManager(&session.SessionConfig{
+		Mode:            session.ModeReuse,
+		PoolSize:        10,
+		TTLMs:           60000,
+		MaxIdleMs:       30000,
+		Adapter:         config.TransportAdapter,
+		TransportConfig: config.TransportConfig,
+	})
+	if err != nil {
+		t.Fatalf("failed to create shadow session manager: %v", err)
+	}
+	config.Shadow = &ShadowConfig{SessionManager: shadowMgr, StableTools: map[string]bool{"echo": true}}
+
+	engine, err := NewEngine(config)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	config.SessionManager.(*session.Manager).Start(ctx)
+	shadowMgr.Start(ctx)
+	if err := engine.Start(ctx); err != nil {
+		t.Fatalf("failed to start engine: %v", err)
+	}
+
+	var results []*OperationResult
+	resultsDone := make(chan struct{})
+	go func() {
+		for result := range engine.Results() {
+			results = append(results, result)
+		}
+		close(resultsDone)
+	}()
+	time.Sleep(150 * time.Millisecond)
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer stopCancel()
+	if err := engine.Stop(stopCtx); err != nil {
+		t.Errorf("failed to stop engine: %v", err)
+	}
+	<-resultsDone
+
+	for _, result := range results {
+		if result.Outcome == nil || !result.Outcome.OK {
+			continue
+		}
+		if result.Shadow == nil || result.Shadow.Outcome == nil {
+			t.Fatalf("expected every operation to be shadowed, got %+v", result)
+		}
+		if result.Operation == OpToolsCall && result.Shadow.Outcome.OK {
+			if result.Shadow.ResultMatch == nil || !*result.Shadow.ResultMatch {
+				t.Errorf("expected the shadow's echo result to match, got %v", result.Shadow.ResultMatch)
+			}
+		} else if result.Shadow.ResultMatch != nil {
+			t.Errorf("expected only stable tool calls to be compared, got %+v", result)
+		}
+	}
+	if len(results) == 0 {
+		t.Fatal("expected some results to be emitted")
+	}
+}
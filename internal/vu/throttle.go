@@ -0,0 +1,116 @@
+package vu
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/transport"
+)
+
+// ThrottlePolicy is how a VU reacts to rate limited responses.
+type ThrottlePolicy string
+
+const (
+	// ThrottleIgnore keeps sending at the configured pace.
+	ThrottleIgnore ThrottlePolicy = "ignore"
+	// ThrottleHonorRetryAfter holds the VU for the delay the server asked
+	// for in Retry-After, or the base backoff if it asked for none.
+	ThrottleHonorRetryAfter ThrottlePolicy = "honor_retry_after"
+	// ThrottleBackoff holds the VU for a delay that doubles with every
+	// rate limited response in a row.
+	ThrottleBackoff ThrottlePolicy = "exponential_backoff"
+)
+
+const (
+	// DefaultBaseBackoff is the first backoff delay when none is set.
+	DefaultBaseBackoff = 100 * time.Millisecond
+	// DefaultMaxBackoff caps backoff delays when no cap is set.
+	DefaultMaxBackoff = 30 * time.Second
+)
+
+// ThrottleConfig sets how a VU slows down when the target rate limits it.
+type ThrottleConfig struct {
+	Policy ThrottlePolicy
+	// BaseBackoff is the first exponential backoff delay, and the delay
+	// used when honoring a response without Retry-After.
+	BaseBackoff time.Duration
+	// MaxBackoff caps every delay, including the ones Retry-After asks for.
+	MaxBackoff time.Duration
+}
+
+// throttle holds a VU back after rate limited responses. A nil throttle,
+// or one with the ignore policy, never holds.
+type throttle struct {
+	cfg ThrottleConfig
+
+	mu     sync.Mutex
+	until  time.Time
+	streak int // Rate limited responses in a row
+}
+
+func newThrottle(cfg *ThrottleConfig) *throttle {
+	if cfg == nil || cfg.Policy == "" || cfg.Policy == ThrottleIgnore {
+		return nil
+	}
+	t := &throttle{cfg: *cfg}
+	if t.cfg.BaseBackoff <= 0 {
+		t.cfg.BaseBackoff = DefaultBaseBackoff
+	}
+	if t.cfg.MaxBackoff <= 0 {
+		t.cfg.MaxBackoff = DefaultMaxBackoff
+	}
+	return t
+}
+
+// observe updates the throttle with an operation's outcome: a rate limited
+// one pushes the hold further out, any other ends the streak.
+func (t *throttle) observe(outcome *transport.OperationOutcome, now time.Time) {
+	if t == nil || outcome == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if outcome.Error == nil || outcome.Error.Type != transport.ErrorTypeRateLimited {
+		t.streak = 0
+		return
+	}
+	t.streak++
+	delay := t.cfg.BaseBackoff
+	switch t.cfg.Policy {
+	case ThrottleHonorRetryAfter:
+		if outcome.RetryAfterMs > 0 {
+			delay = time.Duration(outcome.RetryAfterMs) * time.Millisecond
+		}
+	case ThrottleBackoff:
+		for i := 1; i < t.streak && delay < t.cfg.MaxBackoff; i++ {
+			delay *= 2
+		}
+	}
+	delay = min(delay, t.cfg.MaxBackoff)
+	if until := now.Add(delay); until.After(t.until) {
+		t.until = until
+	}
+}
+
+// wait blocks until the hold is over and returns how long it waited. It
+// returns early if ctx is done.
+func (t *throttle) wait(ctx context.Context) time.Duration {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	delay := time.Until(t.until)
+	t.mu.Unlock()
+	if delay <= 0 {
+		return 0
+	}
+	start := time.Now()
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+	return time.Since(start)
+}
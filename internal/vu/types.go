@@ -3,12 +3,17 @@ package vu
 
 import (
 	"context"
+	"math/rand"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/bc-dunia/mcpdrill/internal/checks"
+	"github.com/bc-dunia/mcpdrill/internal/datafeed"
+	"github.com/bc-dunia/mcpdrill/internal/fuzz"
 	"github.com/bc-dunia/mcpdrill/internal/session"
 	"github.com/bc-dunia/mcpdrill/internal/transport"
+	"github.com/bc-dunia/mcpdrill/internal/types"
 )
 
 // OperationType represents the type of MCP operation to execute.
@@ -22,6 +27,10 @@ const (
 	OpResourcesRead OperationType = "resources/read"
 	OpPromptsList   OperationType = "prompts/list"
 	OpPromptsGet    OperationType = "prompts/get"
+
+	// OpInitialize is never sampled from the mix; it reports the handshake
+	// of a session created in churn mode.
+	OpInitialize OperationType = "initialize"
 )
 
 // OperationWeight represents a weighted operation in the mix.
@@ -38,11 +47,29 @@ type OperationWeight struct {
 	// Arguments are the arguments to pass to the tool (only for tools/call and prompts/get).
 	Arguments map[string]interface{} `json:"arguments,omitempty"`
 
+	// GenerateArguments, if set, draws fresh tool arguments for every call
+	// in place of Arguments.
+	GenerateArguments func(rng *rand.Rand) map[string]interface{} `json:"-"`
+
+	// RequiredArguments names the arguments the tool requires, if known.
+	// Fuzzing prefers them when it drops an argument.
+	RequiredArguments []string `json:"-"`
+
 	// URI is the resource URI (only for resources/read operations).
 	URI string `json:"uri,omitempty"`
 
 	// PromptName is the prompt name (only for prompts/get operations).
 	PromptName string `json:"prompt_name,omitempty"`
+
+	// Checks are the response assertions evaluated after each call.
+	Checks []*checks.Check `json:"-"`
+
+	// Workflow, if set, makes the entry run the workflow's steps in place
+	// of a single operation.
+	Workflow *Workflow `json:"-"`
+
+	// step is set on the copy of a workflow step a VU runs.
+	step *workflowStep
 }
 
 // OperationMix represents the weighted distribution of operations.
@@ -62,13 +89,34 @@ func (m *OperationMix) TotalWeight() int {
 
 // ThinkTimeConfig configures think time between operations.
 type ThinkTimeConfig struct {
+	// Distribution is how think times are drawn from BaseMs and JitterMs.
+	Distribution ThinkTimeDistribution `json:"distribution,omitempty"`
+
 	// BaseMs is the base think time in milliseconds.
 	BaseMs int64 `json:"base_ms"`
 
 	// JitterMs is the maximum random jitter to add in milliseconds.
 	JitterMs int64 `json:"jitter_ms"`
+
+	// MaxMs caps every think time, 0 for no cap. Exponential think times
+	// are capped at exponentialThinkTimeCap times BaseMs without one.
+	MaxMs int64 `json:"max_ms,omitempty"`
 }
 
+// ThinkTimeDistribution is the distribution think times are drawn from.
+type ThinkTimeDistribution string
+
+const (
+	// ThinkTimeJitter adds up to JitterMs to BaseMs.
+	ThinkTimeJitter ThinkTimeDistribution = ""
+	// ThinkTimeUniform draws uniformly from BaseMs-JitterMs to
+	// BaseMs+JitterMs, never below 0.
+	ThinkTimeUniform ThinkTimeDistribution = "uniform"
+	// ThinkTimeExponential draws exponentially with a mean of BaseMs, as
+	// the pauses of independent users are.
+	ThinkTimeExponential ThinkTimeDistribution = "exponential"
+)
+
 type UserJourneyConfig struct {
 	StartupSequence *StartupSequenceConfig `json:"startup_sequence,omitempty"`
 	PeriodicOps     *PeriodicOpsConfig     `json:"periodic_ops,omitempty"`
@@ -133,21 +181,61 @@ type LoadTarget struct {
 }
 
 type VUConfig struct {
-	RunID            string
-	StageID          string
-	AssignmentID     string
-	WorkerID         string
-	LeaseID          string
-	Load             LoadTarget
-	OperationMix     *OperationMix
-	InFlightPerVU    int
-	ThinkTime        ThinkTimeConfig
+	RunID         string
+	StageID       string
+	AssignmentID  string
+	WorkerID      string
+	LeaseID       string
+	Load          LoadTarget
+	OperationMix  *OperationMix
+	InFlightPerVU int
+	ThinkTime     ThinkTimeConfig
+	// ToolThinkTime paces calls to the tools it names: the VU pauses after
+	// them for a think time drawn from the tool's config instead of
+	// ThinkTime.
+	ToolThinkTime    map[string]ThinkTimeConfig
 	SessionManager   session.SessionManager
 	TransportAdapter transport.Adapter
 	TransportConfig  *transport.TransportConfig
 	Mode             VUMode
 	SwarmConfig      *SwarmConfig
+	Arrival          *ArrivalConfig
 	UserJourney      *UserJourneyConfig
+
+	// VUIndexBase is the run-wide index of the assignment's first VU, so
+	// VUs on different workers read different data feed rows.
+	VUIndexBase int
+	// DataFeeds are the parsed feeds templates can read, by name.
+	DataFeeds map[string]*datafeed.Feed
+	// HeaderTemplates are the target headers that contain ${...}
+	// expressions. They are rendered for every call and sent on top of the
+	// session's static headers.
+	HeaderTemplates map[string]string
+	// Fuzzer, if set, mutates a share of tools/call arguments into
+	// malformed inputs.
+	Fuzzer *fuzz.Fuzzer
+	// Cancellation, if set, cancels a share of tools/call requests in
+	// flight.
+	Cancellation *CancellationConfig
+	// Throttle, if set, slows a VU down after rate limited responses.
+	// Arrival mode ignores it, as arrivals do not wait for earlier calls.
+	Throttle *ThrottleConfig
+	// Retry, if set, retries operations that failed with retryable errors.
+	Retry *RetryConfig
+	// Shadow, if set, sends every operation to a shadow target as well.
+	Shadow *ShadowConfig
+}
+
+// CancellationConfig selects the tools/call requests cancelled with
+// notifications/cancelled while in flight.
+type CancellationConfig struct {
+	// Rate is the share of calls cancelled, from 0 to 1.
+	Rate float64
+	// MaxDelay bounds the delay before a call is cancelled; each delay is
+	// drawn uniformly up to it.
+	MaxDelay time.Duration
+	// Grace is how long a cancelled call waits for the server to stop.
+	Grace time.Duration
 }
 
 // VUMode represents the VU execution mode.
@@ -159,6 +247,10 @@ const (
 
 	// ModeSwarm continuously creates and terminates VUs.
 	ModeSwarm VUMode = "swarm"
+
+	// ModeArrivalRate starts operations on an arrival schedule, independent
+	// of how many are still in flight (an open model).
+	ModeArrivalRate VUMode = "arrival_rate"
 )
 
 // SwarmConfig configures swarm mode behavior.
@@ -182,6 +274,35 @@ func DefaultSwarmConfig() *SwarmConfig {
 	}
 }
 
+// ArrivalSchedule is how arrivals are spaced in arrival-rate mode.
+type ArrivalSchedule string
+
+const (
+	// ArrivalPoisson draws exponentially distributed gaps between arrivals.
+	ArrivalPoisson ArrivalSchedule = "poisson"
+
+	// ArrivalConstant spaces arrivals evenly.
+	ArrivalConstant ArrivalSchedule = "constant"
+)
+
+// DefaultArrivalMaxInFlight bounds concurrent operations in arrival-rate mode
+// when MaxInFlight is not set.
+const DefaultArrivalMaxInFlight = 10000
+
+// ArrivalConfig configures arrival-rate mode. The TargetVUs of the load are
+// the sessions arrivals are spread over; they do not limit concurrency.
+type ArrivalConfig struct {
+	// RateRPS is the number of operations started per second.
+	RateRPS float64 `json:"rate_rps"`
+
+	// Schedule spaces the arrivals. Empty means ArrivalPoisson.
+	Schedule ArrivalSchedule `json:"schedule,omitempty"`
+
+	// MaxInFlight is the most operations running at once. Arrivals beyond it
+	// are dropped and counted in DroppedArrivals, not delayed.
+	MaxInFlight int `json:"max_in_flight,omitempty"`
+}
+
 // VUState represents the state of a virtual user.
 type VUState string
 
@@ -207,6 +328,9 @@ type VUInstance struct {
 	// ID is the unique VU identifier.
 	ID string
 
+	// Index is the VU's position in its assignment, from 0.
+	Index int
+
 	// State is the current VU state.
 	state atomic.Value // VUState
 
@@ -292,6 +416,10 @@ type VUMetrics struct {
 	// RateLimitedOperations is the number of operations that were rate limited.
 	RateLimitedOperations atomic.Int64
 
+	// RetryAttempts is the number of retries of failed operations. Each
+	// also counts in TotalOperations.
+	RetryAttempts atomic.Int64
+
 	// InFlightOperations is the current number of in-flight operations.
 	InFlightOperations atomic.Int64
 
@@ -324,6 +452,17 @@ type VUMetrics struct {
 
 	// DroppedResults tracks results dropped due to full result channel.
 	DroppedResults atomic.Int64
+
+	// DroppedArrivals tracks arrivals skipped in arrival-rate mode because
+	// MaxInFlight operations were already running.
+	DroppedArrivals atomic.Int64
+
+	// FailedChecks is the number of response checks that failed.
+	FailedChecks atomic.Int64
+
+	// ShedOperations is the number of operations skipped while the engine
+	// sheds load, see Engine.Shed.
+	ShedOperations atomic.Int64
 }
 
 // NewVUMetrics creates a new VUMetrics instance.
@@ -341,6 +480,7 @@ func (m *VUMetrics) Snapshot() VUMetricsSnapshot {
 		SuccessfulOperations:  m.SuccessfulOperations.Load(),
 		FailedOperations:      m.FailedOperations.Load(),
 		RateLimitedOperations: m.RateLimitedOperations.Load(),
+		RetryAttempts:         m.RetryAttempts.Load(),
 		InFlightOperations:    m.InFlightOperations.Load(),
 		MaxInFlightReached:    m.MaxInFlightReached.Load(),
 		ThinkTimeTotal:        m.ThinkTimeTotal.Load(),
@@ -352,6 +492,9 @@ func (m *VUMetrics) Snapshot() VUMetricsSnapshot {
 		ActiveSessions:        m.ActiveSessions.Load(),
 		ReconnectAttempts:     m.ReconnectAttempts.Load(),
 		DroppedResults:        m.DroppedResults.Load(),
+		DroppedArrivals:       m.DroppedArrivals.Load(),
+		FailedChecks:          m.FailedChecks.Load(),
+		ShedOperations:        m.ShedOperations.Load(),
 	}
 }
 
@@ -364,6 +507,7 @@ type VUMetricsSnapshot struct {
 	SuccessfulOperations  int64
 	FailedOperations      int64
 	RateLimitedOperations int64
+	RetryAttempts         int64
 	InFlightOperations    int64
 	MaxInFlightReached    int64
 	ThinkTimeTotal        int64
@@ -375,6 +519,9 @@ type VUMetricsSnapshot struct {
 	ActiveSessions        int64
 	ReconnectAttempts     int64
 	DroppedResults        int64
+	DroppedArrivals       int64
+	FailedChecks          int64
+	ShedOperations        int64
 }
 
 // OperationResult represents the result of executing an operation.
@@ -408,6 +555,33 @@ type OperationResult struct {
 
 	// ToolMetrics contains tool-specific telemetry (for tools/call).
 	ToolMetrics *ToolCallMetrics
+
+	// Checks are the results of the operation's response checks.
+	Checks []types.CheckResult
+
+	// FuzzMutation is the mutation applied to the call's arguments, empty
+	// if they were sent as configured.
+	FuzzMutation fuzz.Mutation
+
+	// ThrottleWait is how long the VU held the operation back after rate
+	// limited responses.
+	ThrottleWait time.Duration
+
+	// ThinkTime is how long the VU paused after starting the operation
+	// before it started its next one.
+	ThinkTime time.Duration
+
+	// Attempt is the index of the attempt, 0 for the first and 1 for the
+	// first retry.
+	Attempt int
+
+	// Shadow is how the shadow target answered the first attempt, nil
+	// without a shadow.
+	Shadow *ShadowResult
+
+	// Workflow places the operation within a workflow run, nil for
+	// operations sampled on their own.
+	Workflow *types.WorkflowStepInfo
 }
 
 // ToolCallMetrics captures telemetry data for tool executions.
@@ -458,12 +632,14 @@ var (
 	ErrNoOperations     = &VUEngineError{Op: "sample", Err: errNoOperations}
 	ErrRateLimited      = &VUEngineError{Op: "execute", Err: errRateLimited}
 	ErrInFlightExceeded = &VUEngineError{Op: "execute", Err: errInFlightExceeded}
+	ErrNoArrivalRate    = &VUEngineError{Op: "create", Err: errNoArrivalRate}
 )
 
 // Internal error values.
 var (
 	errEngineClosed     = errorString("engine closed")
 	errInvalidConfig    = errorString("invalid configuration")
+	errNoArrivalRate    = errorString("arrival-rate mode requires a positive rate")
 	errNoOperations     = errorString("no operations in mix")
 	errRateLimited      = errorString("rate limited")
 	errInFlightExceeded = errorString("in-flight limit exceeded")
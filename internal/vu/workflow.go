@@ -0,0 +1,216 @@
+package vu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/checks"
+	"github.com/bc-dunia/mcpdrill/internal/session"
+	"github.com/bc-dunia/mcpdrill/internal/templating"
+	"github.com/bc-dunia/mcpdrill/internal/transport"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+// Workflow is a chain of operations a VU runs in order on one session, the
+// way an agent lists tools, calls one and reads what it returned. Values
+// taken from the result of a step feed the steps after it.
+type Workflow struct {
+	Name  string
+	Steps []WorkflowStep
+}
+
+// WorkflowStep is one operation of a workflow.
+type WorkflowStep struct {
+	Name string
+
+	// Op is the step's operation. Its tool and prompt names, arguments and
+	// URI may reference the variables of earlier steps as ${vars.<name>}.
+	Op OperationWeight
+
+	// Extract are the variables the step sets from its result. A path that
+	// selects several values sets one of them at random.
+	Extract map[string]*checks.Path
+}
+
+// workflowStep is a workflow step as a VU runs it.
+type workflowStep struct {
+	workflow *Workflow
+	index    int
+	// vars holds the variables of the run so far. Steps run one at a time,
+	// so it needs no lock.
+	vars map[string]interface{}
+	// err is why the step's names could not be resolved; the step fails
+	// without being sent.
+	err error
+	// ok is set once the step succeeded and set its variables.
+	ok bool
+}
+
+func (s *workflowStep) info(status string) *types.WorkflowStepInfo {
+	if s == nil {
+		return nil
+	}
+	return &types.WorkflowStepInfo{
+		Name:   s.workflow.Name,
+		Step:   s.workflow.Steps[s.index].Name,
+		Index:  s.index,
+		Status: status,
+	}
+}
+
+func (s *workflowStep) last() bool {
+	return s.index == len(s.workflow.Steps)-1
+}
+
+// lookup resolves the ${vars.<name>} references of the step.
+func (s *workflowStep) lookup(name string) (interface{}, bool) {
+	name, ok := strings.CutPrefix(name, templating.WorkflowVarPrefix)
+	if !ok {
+		return nil, false
+	}
+	v, ok := s.vars[name]
+	return v, ok
+}
+
+// firstOperation returns the operation and tool op starts with: its own,
+// or those of its workflow's first step.
+func (op *OperationWeight) firstOperation() (OperationType, string) {
+	if op.Workflow != nil && len(op.Workflow.Steps) > 0 {
+		first := &op.Workflow.Steps[0].Op
+		return first.Operation, first.ToolName
+	}
+	return op.Operation, op.ToolName
+}
+
+// execute runs op, or the steps of its workflow if it has one.
+func (e *VUExecutor) execute(ctx context.Context, sess *session.SessionInfo, op *OperationWeight, throttleWait, thinkTime time.Duration) {
+	if op.Workflow != nil {
+		e.runWorkflow(ctx, sess, op.Workflow, throttleWait, thinkTime)
+		return
+	}
+	e.executeOperation(ctx, sess, op, throttleWait, thinkTime)
+}
+
+// runWorkflow runs the steps of wf on sess until one fails or leaves a
+// variable unset. throttleWait is recorded on the first step and
+// thinkTime, the pause that follows the workflow, on the last. Closed-loop
+// VUs pause between steps for the think time of each step.
+func (e *VUExecutor) runWorkflow(ctx context.Context, sess *session.SessionInfo, wf *Workflow, throttleWait, thinkTime time.Duration) {
+	vars := make(map[string]interface{})
+	for i := range wf.Steps {
+		op := e.resolveStep(&workflowStep{workflow: wf, index: i, vars: vars})
+		pause := thinkTime
+		if !op.step.last() {
+			pause = 0
+			if e.config.Arrival == nil {
+				pause = e.thinkTime(&op)
+			}
+		}
+		e.executeOperation(ctx, sess, &op, throttleWait, pause)
+		if !op.step.ok || op.step.last() {
+			return
+		}
+		throttleWait = 0
+		if pause > 0 {
+			e.metrics.ThinkTimeTotal.Add(pause.Milliseconds())
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(pause):
+			}
+		}
+	}
+}
+
+// resolveStep returns the operation of step with its tool and prompt names
+// rendered from the run's variables.
+func (e *VUExecutor) resolveStep(step *workflowStep) OperationWeight {
+	op := step.workflow.Steps[step.index].Op
+	op.step = step
+	if !templating.HasExpressions(op.ToolName) && !templating.HasExpressions(op.PromptName) {
+		return op
+	}
+
+	e.argMu.Lock()
+	defer e.argMu.Unlock()
+	ctx := &templating.Context{Lookup: step.lookup, Rand: e.argRNG()}
+	for _, name := range []*string{&op.ToolName, &op.PromptName} {
+		if !templating.HasExpressions(*name) {
+			continue
+		}
+		v, err := templating.Render(*name, ctx)
+		if err != nil {
+			step.err = fmt.Errorf("name: %w", err)
+			return op
+		}
+		*name = fmt.Sprint(v)
+	}
+	return op
+}
+
+// finishStep records how a workflow step went once an attempt of it ended,
+// setting the step's variables from result if it succeeded. A failed
+// attempt that is retried does not end the run yet.
+func (e *VUExecutor) finishStep(op *OperationWeight, failed, retrying bool, result json.RawMessage) *types.WorkflowStepInfo {
+	step := op.step
+	if step == nil {
+		return nil
+	}
+	switch {
+	case failed && retrying:
+		return step.info("")
+	case failed:
+		return step.info(types.WorkflowFailed)
+	case !e.extract(step, result):
+		return step.info(types.WorkflowExtractFailed)
+	}
+	step.ok = true
+	if step.last() {
+		return step.info(types.WorkflowCompleted)
+	}
+	return step.info("")
+}
+
+// extract sets the step's variables from result, reporting whether every
+// path selected a value.
+func (e *VUExecutor) extract(step *workflowStep, result json.RawMessage) bool {
+	extract := step.workflow.Steps[step.index].Extract
+	if len(extract) == 0 {
+		return true
+	}
+	// Sorted, so a run draws its random picks in the same order each time.
+	names := make([]string, 0, len(extract))
+	for name := range extract {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	e.argMu.Lock()
+	defer e.argMu.Unlock()
+	for _, name := range names {
+		values := extract[name].Select(result)
+		if len(values) == 0 {
+			return false
+		}
+		v := values[0]
+		if len(values) > 1 {
+			v = values[e.argRNG().Intn(len(values))]
+		}
+		step.vars[name] = v
+	}
+	return true
+}
+
+// stepResult returns the result a step's variables are taken from: the
+// result as received, which the outcome no longer holds when the result was
+// summarized.
+func stepResult(outcome *transport.OperationOutcome, rawResult json.RawMessage) json.RawMessage {
+	if rawResult != nil || outcome == nil {
+		return rawResult
+	}
+	return outcome.Result
+}
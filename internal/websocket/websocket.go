@@ -0,0 +1,141 @@
+// Package websocket holds the parts of RFC 6455 the control plane and
+// workers share: the handshake's accept key and reading and writing
+// frames. The server in the control plane's API and the workers' control
+// channel client build their connections on it.
+package websocket
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// GUID is the handshake key suffix from RFC 6455 section 1.3.
+const GUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Opcodes of the frames in use.
+const (
+	OpText  = 0x1
+	OpClose = 0x8
+	OpPing  = 0x9
+	OpPong  = 0xA
+)
+
+// Close status codes from RFC 6455 section 7.4.1.
+const (
+	CloseNormal        = 1000
+	CloseGoingAway     = 1001
+	CloseProtocolError = 1002
+	CloseTooBig        = 1009
+)
+
+// AcceptKey returns the Sec-WebSocket-Accept value that answers the
+// Sec-WebSocket-Key key.
+func AcceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + GUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// CloseError is a protocol violation that ends the connection with the
+// given close code.
+type CloseError int
+
+func (e CloseError) Error() string {
+	return fmt.Sprintf("websocket protocol error (close %d)", int(e))
+}
+
+// Frame is a frame read from a peer.
+type Frame struct {
+	Opcode  byte
+	Fin     bool
+	Payload []byte
+}
+
+// AppendFrame appends a final, unfragmented frame to b. mask, if not nil,
+// masks the payload, as clients must (RFC 6455 section 5.3); servers pass
+// nil.
+func AppendFrame(b []byte, opcode byte, payload []byte, mask *[4]byte) []byte {
+	var maskBit byte
+	if mask != nil {
+		maskBit = 0x80
+	}
+	b = append(b, 0x80|opcode)
+	switch n := len(payload); {
+	case n <= 125:
+		b = append(b, maskBit|byte(n))
+	case n <= 0xFFFF:
+		b = append(b, maskBit|126, byte(n>>8), byte(n))
+	default:
+		b = append(b, maskBit|127)
+		b = binary.BigEndian.AppendUint64(b, uint64(n))
+	}
+	if mask == nil {
+		return append(b, payload...)
+	}
+	b = append(b, mask[:]...)
+	for i, c := range payload {
+		b = append(b, c^mask[i%4])
+	}
+	return b
+}
+
+// ReadFrame reads the next frame from r, unmasking its payload. fromClient
+// says whether the peer is a client, whose frames must be masked, or a
+// server, whose frames must not be (RFC 6455 section 5.1). Protocol
+// violations and payloads longer than maxPayload are returned as a
+// CloseError.
+func ReadFrame(r io.Reader, maxPayload uint64, fromClient bool) (Frame, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return Frame{}, err
+	}
+	opcode := head[0] & 0x0F
+	fin := head[0]&0x80 != 0
+	masked := head[1]&0x80 != 0
+	length := uint64(head[1] & 0x7F)
+
+	if masked != fromClient || head[0]&0x70 != 0 {
+		return Frame{}, CloseError(CloseProtocolError)
+	}
+	// Control frames are short and never fragmented (section 5.5).
+	if opcode >= OpClose && (length > 125 || !fin) {
+		return Frame{}, CloseError(CloseProtocolError)
+	}
+
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return Frame{}, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return Frame{}, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if length > maxPayload {
+		return Frame{}, CloseError(CloseTooBig)
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(r, mask[:]); err != nil {
+			return Frame{}, err
+		}
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return Frame{}, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return Frame{Opcode: opcode, Fin: fin, Payload: payload}, nil
+}
@@ -0,0 +1,66 @@
+package websocket
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestAcceptKey(t *testing.T) {
+	// The example of RFC 6455 section 1.3.
+	if got := AcceptKey("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("expected the RFC's accept key, got %s", got)
+	}
+}
+
+func TestFrameRoundTrip(t *testing.T) {
+	mask := [4]byte{0x37, 0xfa, 0x21, 0x3d}
+	for _, n := range []int{0, 5, 125, 126, 0xFFFF, 0x10000} {
+		payload := bytes.Repeat([]byte{'x'}, n)
+		for _, fromClient := range []bool{true, false} {
+			var m *[4]byte
+			if fromClient {
+				m = &mask
+			}
+			frame, err := ReadFrame(bytes.NewReader(AppendFrame(nil, OpText, payload, m)), 1<<20, fromClient)
+			if err != nil {
+				t.Fatalf("%d bytes, client %v: ReadFrame failed: %v", n, fromClient, err)
+			}
+			if frame.Opcode != OpText || !frame.Fin || !bytes.Equal(frame.Payload, payload) {
+				t.Errorf("%d bytes, client %v: got opcode %d, fin %v and %d bytes", n, fromClient, frame.Opcode, frame.Fin, len(frame.Payload))
+			}
+		}
+	}
+
+	// The masked "Hello" of RFC 6455 section 5.7.
+	frame, err := ReadFrame(bytes.NewReader([]byte{0x81, 0x85, 0x37, 0xfa, 0x21, 0x3d, 0x7f, 0x9f, 0x4d, 0x51, 0x58}), 125, true)
+	if err != nil || string(frame.Payload) != "Hello" {
+		t.Errorf("expected Hello, got %q (%v)", frame.Payload, err)
+	}
+}
+
+func TestReadFrame_ProtocolErrors(t *testing.T) {
+	mask := [4]byte{1, 2, 3, 4}
+	tests := []struct {
+		name       string
+		frame      []byte
+		fromClient bool
+		code       CloseError
+	}{
+		{"unmasked client frame", AppendFrame(nil, OpText, []byte("hi"), nil), true, CloseProtocolError},
+		{"masked server frame", AppendFrame(nil, OpText, []byte("hi"), &mask), false, CloseProtocolError},
+		{"reserved bits", append([]byte{0xC1}, AppendFrame(nil, OpText, nil, nil)[1:]...), false, CloseProtocolError},
+		{"long control frame", AppendFrame(nil, OpPing, make([]byte, 126), nil), false, CloseProtocolError},
+		{"fragmented control frame", append([]byte{OpPing}, AppendFrame(nil, OpPing, nil, nil)[1:]...), false, CloseProtocolError},
+		{"too big", AppendFrame(nil, OpText, make([]byte, 65), nil), false, CloseTooBig},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadFrame(bytes.NewReader(tt.frame), 64, tt.fromClient)
+			var closeErr CloseError
+			if !errors.As(err, &closeErr) || closeErr != tt.code {
+				t.Errorf("expected close code %d, got %v", tt.code, err)
+			}
+		})
+	}
+}
@@ -3,28 +3,55 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/bc-dunia/mcpdrill/internal/checks"
+	"github.com/bc-dunia/mcpdrill/internal/datafeed"
+	"github.com/bc-dunia/mcpdrill/internal/fuzz"
 	"github.com/bc-dunia/mcpdrill/internal/mcp"
+	"github.com/bc-dunia/mcpdrill/internal/otel"
+	"github.com/bc-dunia/mcpdrill/internal/secrets"
 	"github.com/bc-dunia/mcpdrill/internal/session"
+	"github.com/bc-dunia/mcpdrill/internal/templating"
 	"github.com/bc-dunia/mcpdrill/internal/transport"
 	"github.com/bc-dunia/mcpdrill/internal/types"
 	"github.com/bc-dunia/mcpdrill/internal/vu"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// resumeRamp is how long the VUs of a resumed run take to all come back,
+// so the target is not hit with the full load at once.
+const resumeRamp = 10 * time.Second
+
 // AssignmentExecutor manages the execution of work assignments from the control plane.
 type AssignmentExecutor struct {
 	workerID         string
 	allowPrivateNets []string
 	telemetryShipper *TelemetryShipper
+	secrets          *secrets.Resolver
 
 	mu        sync.RWMutex
 	active    map[string]*runningAssignment  // LeaseID -> assignment
 	runLeases map[string]map[string]struct{} // RunID -> set of LeaseIDs
+
+	// profiledRuns are the runs whose target this worker has profiled.
+	profiledRuns map[string]struct{}
+
+	accessMu       sync.Mutex
+	secretAccesses []types.SecretAccess
+	reportedAccess map[string]struct{} // RunID + "\x00" + ref
+
+	// shedRate is the share of load every assignment sheds while the
+	// worker is short of resources, and usage the latest sample of them;
+	// both guarded by mu.
+	shedRate float64
+	usage    ResourceUsage
 }
 
 // runningAssignment tracks a currently executing assignment.
@@ -35,6 +62,28 @@ type runningAssignment struct {
 	cancel        context.CancelFunc
 	startedAt     time.Time
 	immediateStop atomic.Bool
+	opsCompleted  atomic.Int64
+
+	// workloadMu orders live op mix updates and pauses against engine
+	// creation.
+	workloadMu       sync.Mutex
+	workload         types.WorkloadConfig
+	workloadRevision int64
+	// discovered holds the target's tools when the workload calls the
+	// tools it lists; set before the engine is created.
+	discovered []discoveredTool
+
+	// paused is set while the run is paused; pauseChanged is signalled when
+	// it changes, so the assignment's duration stands still meanwhile.
+	paused       atomic.Bool
+	pauseChanged chan struct{}
+
+	// warmupEnd is when the assignment's warmup ends, in Unix nanoseconds,
+	// and zero without one. Like the duration it stands still while paused:
+	// a pause that starts before it ends pushes it back. pausedAt is when
+	// the current pause started; it is guarded by workloadMu.
+	warmupEnd atomic.Int64
+	pausedAt  time.Time
 }
 
 // NewAssignmentExecutor creates a new assignment executor.
@@ -43,14 +92,22 @@ func NewAssignmentExecutor(workerID string, allowPrivateNets []string, shipper *
 		workerID:         workerID,
 		allowPrivateNets: allowPrivateNets,
 		telemetryShipper: shipper,
+		secrets:          secrets.NewResolver(secrets.Config{}),
 		active:           make(map[string]*runningAssignment),
 		runLeases:        make(map[string]map[string]struct{}),
+		profiledRuns:     make(map[string]struct{}),
+		reportedAccess:   make(map[string]struct{}),
 	}
 }
 
 // Execute starts executing an assignment. It is idempotent - calling with the same
-// LeaseID will be a no-op if already running.
+// LeaseID will be a no-op if already running. Workload updates are applied to the
+// running assignment with the same LeaseID instead.
 func (e *AssignmentExecutor) Execute(ctx context.Context, a types.WorkerAssignment) error {
+	if a.WorkloadUpdate {
+		return e.updateWorkload(a)
+	}
+
 	// Dedupe by LeaseID
 	e.mu.Lock()
 	if _, exists := e.active[a.LeaseID]; exists {
@@ -70,9 +127,15 @@ func (e *AssignmentExecutor) Execute(ctx context.Context, a types.WorkerAssignme
 
 	// Register assignment
 	running := &runningAssignment{
-		assignment: a,
-		cancel:     cancel,
-		startedAt:  time.Now(),
+		assignment:       a,
+		cancel:           cancel,
+		startedAt:        time.Now(),
+		workload:         a.Workload,
+		workloadRevision: a.WorkloadRevision,
+		pauseChanged:     make(chan struct{}, 1),
+	}
+	if a.WarmupMs > 0 {
+		running.warmupEnd.Store(running.startedAt.Add(time.Duration(a.WarmupMs) * time.Millisecond).UnixNano())
 	}
 	e.active[a.LeaseID] = running
 
@@ -102,12 +165,64 @@ func (e *AssignmentExecutor) executeAssignment(ctx context.Context, running *run
 	log.Printf("[Worker] Starting assignment: run=%s stage=%s lease=%s vus=%d-%d duration=%dms",
 		a.RunID, a.Stage, a.LeaseID, a.VUIDStart, a.VUIDEnd, a.DurationMs)
 
+	// Continue the run's trace from the control plane's dispatch span, so
+	// session and operation spans nest under this assignment.
+	tracer := otel.GetGlobalTracer()
+	ctx = otel.ExtractMap(ctx, a.TraceContext, tracer)
+	ctx, span := tracer.StartSpan(ctx, "mcpdrill.worker.assignment",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("mcpdrill.run_id", a.RunID),
+			attribute.String("mcpdrill.stage", a.Stage),
+			attribute.String("mcpdrill.stage_id", a.StageID),
+			attribute.String("mcpdrill.worker_id", e.workerID),
+			attribute.String("mcpdrill.lease_id", a.LeaseID),
+			attribute.Int("mcpdrill.vu_id_start", a.VUIDStart),
+			attribute.Int("mcpdrill.vu_id_end", a.VUIDEnd),
+		),
+	)
+	defer span.End()
+
+	feeds, err := parseDataFeeds(a.Workload.DataFeeds)
+	if err != nil {
+		return err
+	}
+	fuzzer, err := buildFuzzer(a.Workload.Fuzz)
+	if err != nil {
+		return err
+	}
+
+	tokenSource, err := e.buildTokenSource(ctx, a.RunID, a.Target.Auth)
+	if err != nil {
+		return err
+	}
+
 	// 1. Build transport config
 	transportCfg := e.buildTransportConfig(a)
+	transportCfg.TokenSource = tokenSource
+
+	// A conformance run checks the target's protocol handling and puts no
+	// load on it
+	if a.Conformance != nil {
+		return e.runConformance(ctx, a, transportCfg)
+	}
 
 	// 2. Build and create transport adapter
 	adapter := transport.NewStreamableHTTPAdapter()
 
+	// Preflight probes what the target advertises before putting load on it
+	e.profileTargetOnce(ctx, a, adapter, transportCfg)
+
+	// Nameless tools/call entries call whatever tools the target lists
+	var discovered []discoveredTool
+	if selection := a.Workload.AutoDiscoverTools; selection != nil {
+		discovered, err = discoverTools(ctx, adapter, transportCfg, selection)
+		if err != nil {
+			return fmt.Errorf("discover tools: %w", err)
+		}
+		log.Printf("[Worker] Assignment %s calls %d discovered tools", a.LeaseID, len(discovered))
+	}
+
 	// 3. Build session config
 	sessionCfg := e.buildSessionConfig(a, transportCfg, adapter)
 
@@ -119,32 +234,58 @@ func (e *AssignmentExecutor) executeAssignment(ctx context.Context, running *run
 	running.sessionMgr = sessionMgr
 	sessionMgr.Start(ctx)
 
-	// 5. Build VU config
+	var shadow *vu.ShadowConfig
+	var shadowMgr *session.Manager
+	if a.Shadow != nil {
+		shadow, shadowMgr, err = e.startShadow(ctx, a)
+		if err != nil {
+			sessionMgr.Close(ctx)
+			return err
+		}
+		log.Printf("[Worker] Assignment %s shadows operations to %s", a.LeaseID, a.Shadow.Target.URL)
+	}
+
+	// 5. Build VU config from the latest workload, which may have been updated
+	// while the session manager was starting
+	running.workloadMu.Lock()
+	a.Workload = running.workload
+	running.discovered = discovered
 	vuCfg := e.buildVUConfig(a, sessionMgr, adapter, transportCfg)
+	vuCfg.OperationMix = running.operationMix(a.Workload.OpMix)
+	vuCfg.DataFeeds = feeds
+	vuCfg.Fuzzer = fuzzer
+	vuCfg.Cancellation = buildCancellation(a.Workload.Cancellation)
+	vuCfg.Throttle = buildThrottle(a.Workload.Throttle)
+	vuCfg.Retry = buildRetry(a.Workload.Retry)
+	vuCfg.Shadow = shadow
 
 	// 6. Create VU engine
 	engine, err := vu.NewEngine(vuCfg)
 	if err != nil {
+		running.workloadMu.Unlock()
 		sessionMgr.Close(ctx)
+		closeShadow(ctx, shadowMgr)
 		return fmt.Errorf("create VU engine: %w", err)
 	}
 	running.engine = engine
+	if running.paused.Load() {
+		engine.Pause()
+	}
+	engine.Shed(e.ShedRate())
+	running.workloadMu.Unlock()
 
 	if err := engine.Start(ctx); err != nil {
 		sessionMgr.Close(ctx)
+		closeShadow(ctx, shadowMgr)
 		return fmt.Errorf("start VU engine: %w", err)
 	}
 
 	go e.collectResults(ctx, running)
 
 	// 9. Wait for duration or cancellation
-	durationTimer := time.NewTimer(time.Duration(a.DurationMs) * time.Millisecond)
-	defer durationTimer.Stop()
-
-	select {
-	case <-durationTimer.C:
+	if running.waitDuration(ctx, time.Duration(a.DurationMs)*time.Millisecond) {
 		log.Printf("[Worker] Assignment %s completed (duration expired)", a.LeaseID)
-	case <-ctx.Done():
+	} else {
 		log.Printf("[Worker] Assignment %s stopped (context cancelled)", a.LeaseID)
 	}
 
@@ -162,10 +303,105 @@ func (e *AssignmentExecutor) executeAssignment(ctx context.Context, running *run
 	if err := sessionMgr.Close(stopCtx); err != nil {
 		log.Printf("[Worker] Session manager close error: %v", err)
 	}
+	closeShadow(stopCtx, shadowMgr)
 
 	return nil
 }
 
+// waitDuration waits until the assignment has run for d outside of pauses.
+// It returns false if ctx is done first.
+func (r *runningAssignment) waitDuration(ctx context.Context, d time.Duration) bool {
+	for {
+		if r.paused.Load() {
+			select {
+			case <-ctx.Done():
+				return false
+			case <-r.pauseChanged:
+			}
+			continue
+		}
+
+		started := time.Now()
+		timer := time.NewTimer(d)
+		select {
+		case <-timer.C:
+			return true
+		case <-ctx.Done():
+			timer.Stop()
+			return false
+		case <-r.pauseChanged:
+			timer.Stop()
+			d -= time.Since(started)
+			if d <= 0 {
+				return true
+			}
+		}
+	}
+}
+
+// setPaused pauses or resumes the assignment's VUs. It reports whether that
+// changed anything.
+func (r *runningAssignment) setPaused(paused bool) bool {
+	r.workloadMu.Lock()
+	defer r.workloadMu.Unlock()
+
+	if r.paused.Swap(paused) == paused {
+		return false
+	}
+	now := time.Now()
+	if paused {
+		r.pausedAt = now
+	} else if end := r.warmupEnd.Load(); r.pausedAt.UnixNano() < end {
+		r.warmupEnd.Store(end + int64(now.Sub(r.pausedAt)))
+	}
+	if r.engine != nil {
+		if paused {
+			r.engine.Pause()
+		} else {
+			r.engine.Resume(resumeRamp)
+		}
+	}
+	select {
+	case r.pauseChanged <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// updateWorkload re-weights the op mix of a running assignment in place. Updates
+// for leases that are no longer running, or older than the applied revision, are ignored.
+func (e *AssignmentExecutor) updateWorkload(a types.WorkerAssignment) error {
+	e.mu.RLock()
+	running, exists := e.active[a.LeaseID]
+	e.mu.RUnlock()
+	if !exists {
+		log.Printf("[Worker] Ignoring workload update for inactive lease %s", a.LeaseID)
+		return nil
+	}
+
+	running.workloadMu.Lock()
+	defer running.workloadMu.Unlock()
+
+	if a.WorkloadRevision <= running.workloadRevision {
+		return nil
+	}
+
+	if running.engine != nil {
+		err := running.engine.UpdateOperationMix(running.operationMix(a.Workload.OpMix))
+		if errors.Is(err, vu.ErrEngineClosed) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("update op mix for lease %s: %w", a.LeaseID, err)
+		}
+	}
+	running.workload.OpMix = a.Workload.OpMix
+	running.workloadRevision = a.WorkloadRevision
+
+	log.Printf("[Worker] Assignment %s op mix updated to revision %d", a.LeaseID, a.WorkloadRevision)
+	return nil
+}
+
 // collectResults reads from engine results and forwards to telemetry shipper.
 // This runs in a separate goroutine to avoid blocking the engine.
 func (e *AssignmentExecutor) collectResults(ctx context.Context, running *runningAssignment) {
@@ -180,11 +416,14 @@ func (e *AssignmentExecutor) collectResults(ctx context.Context, running *runnin
 				return
 			}
 
-			// Convert to OperationOutcome
-			outcome := ConvertToOutcome(result, a, e.workerID)
-
 			// Send to shipper (non-blocking via buffered channel)
-			e.telemetryShipper.Ship(a.RunID, outcome)
+			e.ship(running, result)
+
+			// Stop cooperatively once this assignment's share of max_total_ops is spent
+			if a.MaxTotalOps > 0 && running.opsCompleted.Add(1) == a.MaxTotalOps {
+				log.Printf("[Worker] Assignment %s reached its total ops budget (%d), stopping (TOTAL_OPS_CAP)", a.LeaseID, a.MaxTotalOps)
+				running.cancel()
+			}
 
 		case <-ctx.Done():
 			e.drainResults(results, running)
@@ -194,11 +433,9 @@ func (e *AssignmentExecutor) collectResults(ctx context.Context, running *runnin
 }
 
 func (e *AssignmentExecutor) drainResults(results <-chan *vu.OperationResult, running *runningAssignment) {
-	a := running.assignment
 	if !running.immediateStop.Load() {
 		for result := range results {
-			outcome := ConvertToOutcome(result, a, e.workerID)
-			e.telemetryShipper.Ship(a.RunID, outcome)
+			e.ship(running, result)
 		}
 		return
 	}
@@ -209,26 +446,62 @@ func (e *AssignmentExecutor) drainResults(results <-chan *vu.OperationResult, ru
 			if !ok {
 				return
 			}
-			outcome := ConvertToOutcome(result, a, e.workerID)
-			e.telemetryShipper.Ship(a.RunID, outcome)
+			e.ship(running, result)
 		default:
 			return
 		}
 	}
 }
 
+// ship converts result to an OperationOutcome and hands it to the telemetry
+// shipper, flagging operations that started within the assignment's warmup.
+func (e *AssignmentExecutor) ship(running *runningAssignment, result *vu.OperationResult) {
+	a := running.assignment
+	outcome := ConvertToOutcome(result, a, e.workerID)
+	outcome.Warmup = running.inWarmup(result.StartTime)
+	e.telemetryShipper.Ship(a.RunID, outcome)
+}
+
+// inWarmup reports whether an operation started at start fell within the
+// assignment's warmup, not counting the time the assignment was paused.
+func (r *runningAssignment) inWarmup(start time.Time) bool {
+	end := r.warmupEnd.Load()
+	return end != 0 && start.UnixNano() < end
+}
+
 func (e *AssignmentExecutor) buildTransportConfig(a types.WorkerAssignment) *transport.TransportConfig {
 	cfg := &transport.TransportConfig{
 		Endpoint:             a.Target.URL,
-		Headers:              a.Target.GetHeadersWithAuth(),
+		Headers:              staticHeaders(a.Target.GetHeadersWithAuth()),
 		AllowPrivateNetworks: e.allowPrivateNets,
+		BackendIDHeader:      a.Target.BackendIDHeader,
 		Timeouts:             transport.DefaultTimeoutConfig(),
+		ResultCapture:        buildResultCapture(a.Workload.ResultCapture),
+		RequestIDs:           buildRequestIDs(a.Workload.RequestIDs),
 		ValidationConfig: &transport.ValidationConfig{
 			MaxArgumentSizeBytes: 10 * 1024 * 1024,
 			MaxResultSizeBytes:   100 * 1024 * 1024,
 		},
 	}
 
+	if r := a.Target.StreamResumption; r != nil {
+		cfg.ResumeAttempts = r.MaxAttempts
+		cfg.ResumeBackoff = time.Duration(r.BackoffMs) * time.Millisecond
+	}
+	cfg.ProgressTokens = a.Target.ProgressTokens
+	if r := a.Target.ServerRequests; r != nil {
+		cfg.ServerRequests = &transport.ServerRequestsConfig{Results: r.Responses}
+	}
+
+	if c := a.Chaos; c != nil {
+		cfg.Chaos = &transport.ChaosConfig{
+			LatencyMs:            c.LatencyMs,
+			JitterMs:             c.JitterMs,
+			ResetRate:            c.ResetRate,
+			BandwidthBytesPerSec: c.BandwidthBytesPerSec,
+		}
+	}
+
 	if a.Target.RedirectPolicy != nil {
 		cfg.RedirectPolicy = &transport.RedirectPolicyConfig{
 			Mode:         a.Target.RedirectPolicy.Mode,
@@ -240,6 +513,204 @@ func (e *AssignmentExecutor) buildTransportConfig(a types.WorkerAssignment) *tra
 	return cfg
 }
 
+// buildTokenSource returns the token source for an oauth2_client_credentials
+// target, resolving its client secret on this worker, or nil for other auth
+// types. The VUs of an assignment share the source and so its cached token.
+// The secret is resolved again for every token request, so a rotated secret
+// is picked up once the resolver's cache expires.
+func (e *AssignmentExecutor) buildTokenSource(ctx context.Context, runID string, auth *types.AuthConfig) (transport.TokenSource, error) {
+	if auth == nil || auth.Type != "oauth2_client_credentials" {
+		return nil, nil
+	}
+	if auth.OAuth2 == nil {
+		return nil, errors.New("oauth2_client_credentials auth has no oauth2 config")
+	}
+	ref := auth.OAuth2.ClientSecretRef
+	// Resolve once up front so a missing secret fails the assignment.
+	if _, err := e.resolveSecret(ctx, runID, ref); err != nil {
+		return nil, fmt.Errorf("resolve oauth2 client secret: %w", err)
+	}
+	return transport.NewClientCredentialsSource(transport.ClientCredentialsConfig{
+		TokenURL: auth.OAuth2.TokenURL,
+		ClientID: auth.OAuth2.ClientID,
+		ClientSecretFunc: func(ctx context.Context) (string, error) {
+			return e.resolveSecret(ctx, runID, ref)
+		},
+		Scopes:               auth.OAuth2.Scopes,
+		Audience:             auth.OAuth2.Audience,
+		PostCredentials:      auth.OAuth2.ClientAuth == "post",
+		RefreshBefore:        time.Duration(auth.OAuth2.RefreshBeforeMs) * time.Millisecond,
+		AllowPrivateNetworks: e.allowPrivateNets,
+	}), nil
+}
+
+// staticHeaders returns the headers without ${...} expressions. The others
+// are rendered per call by the VUs; see headerTemplates.
+func staticHeaders(headers map[string]string) map[string]string {
+	static := make(map[string]string, len(headers))
+	for name, value := range headers {
+		if !templating.HasExpressions(value) {
+			static[name] = value
+		}
+	}
+	return static
+}
+
+// headerTemplates returns the headers that contain ${...} expressions, or
+// nil if there are none.
+func headerTemplates(headers map[string]string) map[string]string {
+	var templates map[string]string
+	for name, value := range headers {
+		if templating.HasExpressions(value) {
+			if templates == nil {
+				templates = make(map[string]string)
+			}
+			templates[name] = value
+		}
+	}
+	return templates
+}
+
+// parseDataFeeds parses the assignment's data feeds once, so the VUs share
+// the rows.
+func parseDataFeeds(configs []types.DataFeedConfig) (map[string]*datafeed.Feed, error) {
+	if len(configs) == 0 {
+		return nil, nil
+	}
+	feeds := make(map[string]*datafeed.Feed, len(configs))
+	for _, c := range configs {
+		feed, err := datafeed.Parse(c.Name, datafeed.Format(c.Format), datafeed.Distribution(c.Distribution), c.Data)
+		if err != nil {
+			return nil, fmt.Errorf("data feed %s: %w", c.Name, err)
+		}
+		feeds[c.Name] = feed
+	}
+	return feeds, nil
+}
+
+// defaultCancelMaxDelay bounds the delay before a call is cancelled unless
+// the run sets its own.
+const defaultCancelMaxDelay = time.Second
+
+// buildCancellation returns the assignment's cancellation settings, or nil
+// if it cancels no calls.
+func buildCancellation(cfg *types.CancellationConfig) *vu.CancellationConfig {
+	if cfg == nil || cfg.Rate <= 0 {
+		return nil
+	}
+	c := &vu.CancellationConfig{
+		Rate:     cfg.Rate,
+		MaxDelay: time.Duration(cfg.MaxDelayMs) * time.Millisecond,
+		Grace:    time.Duration(cfg.GraceMs) * time.Millisecond,
+	}
+	if c.MaxDelay <= 0 {
+		c.MaxDelay = defaultCancelMaxDelay
+	}
+	return c
+}
+
+// buildThrottle returns the assignment's throttling policy, or nil if VUs
+// ignore rate limited responses.
+func buildThrottle(cfg *types.ThrottleConfig) *vu.ThrottleConfig {
+	if cfg == nil || cfg.Policy == "" || vu.ThrottlePolicy(cfg.Policy) == vu.ThrottleIgnore {
+		return nil
+	}
+	return &vu.ThrottleConfig{
+		Policy:      vu.ThrottlePolicy(cfg.Policy),
+		BaseBackoff: time.Duration(cfg.BaseBackoffMs) * time.Millisecond,
+		MaxBackoff:  time.Duration(cfg.MaxBackoffMs) * time.Millisecond,
+	}
+}
+
+// Retry delays used unless the run sets its own: the first retry waits
+// defaultRetryBackoff, doubling up to defaultRetryMaxBackoff.
+const (
+	defaultRetryBackoff    = 100 * time.Millisecond
+	defaultRetryMaxBackoff = 5 * time.Second
+)
+
+// buildRetry returns the assignment's retry policy, or nil if failed
+// operations are not retried.
+func buildRetry(cfg *types.RetryConfig) *vu.RetryConfig {
+	if cfg == nil || cfg.MaxRetries <= 0 {
+		return nil
+	}
+	r := &vu.RetryConfig{
+		MaxRetries: cfg.MaxRetries,
+		Backoff:    time.Duration(cfg.BackoffMs) * time.Millisecond,
+		MaxBackoff: time.Duration(cfg.MaxBackoffMs) * time.Millisecond,
+		On:         vu.DefaultRetryClasses,
+	}
+	if r.Backoff <= 0 {
+		r.Backoff = defaultRetryBackoff
+	}
+	if r.MaxBackoff <= 0 {
+		r.MaxBackoff = defaultRetryMaxBackoff
+	}
+	if len(cfg.RetryOn) > 0 {
+		r.On = make([]vu.RetryClass, len(cfg.RetryOn))
+		for i, class := range cfg.RetryOn {
+			r.On[i] = vu.RetryClass(class)
+		}
+	}
+	return r
+}
+
+// buildFuzzer returns the assignment's argument fuzzer, or nil if it does
+// not fuzz.
+func buildFuzzer(cfg *types.FuzzConfig) (*fuzz.Fuzzer, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	mutations := make([]fuzz.Mutation, len(cfg.Mutations))
+	for i, m := range cfg.Mutations {
+		mutations[i] = fuzz.Mutation(m)
+	}
+	fuzzer, err := fuzz.New(fuzz.Config{
+		Ratio:                cfg.Ratio,
+		Mutations:            mutations,
+		OversizedStringBytes: cfg.OversizedStringBytes,
+		NestingDepth:         cfg.NestingDepth,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fuzz: %w", err)
+	}
+	return fuzzer, nil
+}
+
+// buildResultCapture applies the run's result capture settings over the
+// worker defaults so large results don't dominate telemetry memory.
+func buildResultCapture(cfg *types.ResultCaptureConfig) *transport.ResultCaptureConfig {
+	capture := transport.DefaultResultCaptureConfig()
+	if cfg == nil {
+		return capture
+	}
+	if cfg.SummarizeAboveBytes >= 0 {
+		capture.SummarizeAboveBytes = cfg.SummarizeAboveBytes
+	}
+	if cfg.FingerprintMaxDepth > 0 {
+		capture.FingerprintMaxDepth = cfg.FingerprintMaxDepth
+	}
+	return capture
+}
+
+// buildRequestIDs maps the run's request id settings to the transport. Nil
+// keeps the default string ids.
+func buildRequestIDs(cfg *types.RequestIDConfig) *transport.RequestIDConfig {
+	if cfg == nil {
+		return nil
+	}
+	format := transport.RequestIDFormat(cfg.Format)
+	if format == "" {
+		format = transport.RequestIDFormatString
+	}
+	return &transport.RequestIDConfig{
+		Format:         format,
+		LongLength:     cfg.LongLength,
+		DuplicateEvery: cfg.DuplicateEvery,
+	}
+}
+
 // buildSessionConfig creates session configuration from assignment.
 func (e *AssignmentExecutor) buildSessionConfig(a types.WorkerAssignment, transportCfg *transport.TransportConfig, adapter transport.Adapter) *session.SessionConfig {
 	cfg := &session.SessionConfig{
@@ -247,6 +718,8 @@ func (e *AssignmentExecutor) buildSessionConfig(a types.WorkerAssignment, transp
 		PoolSize:              a.SessionPolicy.PoolSize,
 		TTLMs:                 a.SessionPolicy.TTLMs,
 		MaxIdleMs:             a.SessionPolicy.MaxIdleMs,
+		ChurnIntervalMs:       a.SessionPolicy.ChurnIntervalMs,
+		ChurnIntervalOps:      a.SessionPolicy.ChurnIntervalOps,
 		TransportConfig:       transportCfg,
 		Adapter:               adapter,
 		ProtocolVersion:       a.Target.ProtocolVersion,
@@ -265,7 +738,12 @@ func (e *AssignmentExecutor) buildSessionConfig(a types.WorkerAssignment, transp
 func (e *AssignmentExecutor) buildVUConfig(a types.WorkerAssignment, sessionMgr *session.Manager, adapter transport.Adapter, transportCfg *transport.TransportConfig) *vu.VUConfig {
 	vuCount := a.VUIDEnd - a.VUIDStart
 
-	return &vu.VUConfig{
+	inFlightPerVU := 1
+	if a.Workload.InFlightPerVU > 0 {
+		inFlightPerVU = a.Workload.InFlightPerVU
+	}
+
+	cfg := &vu.VUConfig{
 		RunID:            a.RunID,
 		StageID:          a.StageID,
 		AssignmentID:     a.LeaseID, // Use LeaseID for unique VU IDs
@@ -273,16 +751,60 @@ func (e *AssignmentExecutor) buildVUConfig(a types.WorkerAssignment, sessionMgr
 		LeaseID:          a.LeaseID,
 		Load:             vu.LoadTarget{TargetVUs: vuCount},
 		OperationMix:     mapOperationMix(a.Workload.OpMix),
-		InFlightPerVU:    1,
-		ThinkTime:        vu.ThinkTimeConfig{BaseMs: 0, JitterMs: 0},
+		InFlightPerVU:    inFlightPerVU,
+		ThinkTime:        mapThinkTime(a.Workload.ThinkTime),
+		ToolThinkTime:    mapToolThinkTime(a.Workload.ToolThinkTime),
 		SessionManager:   sessionMgr,
 		TransportAdapter: adapter,
 		TransportConfig:  transportCfg,
 		Mode:             vu.ModeNormal,
 		UserJourney:      vu.DefaultUserJourneyConfig(),
+		VUIndexBase:      a.VUIDStart,
+		HeaderTemplates:  headerTemplates(a.Target.GetHeadersWithAuth()),
+	}
+	if arrival := a.Workload.Arrival; arrival != nil && arrival.RateRPS > 0 {
+		cfg.Mode = vu.ModeArrivalRate
+		cfg.Arrival = &vu.ArrivalConfig{
+			RateRPS:     arrival.RateRPS,
+			Schedule:    vu.ArrivalSchedule(arrival.Schedule),
+			MaxInFlight: arrival.MaxInFlight,
+		}
+	}
+	return cfg
+}
+
+// mapThinkTime converts an assignment think time to the VU engine config.
+// No think time is used unless the assignment sets one.
+func mapThinkTime(tt *types.ThinkTimeConfig) vu.ThinkTimeConfig {
+	if tt == nil {
+		return vu.ThinkTimeConfig{}
+	}
+	switch tt.Mode {
+	case "fixed":
+		return vu.ThinkTimeConfig{BaseMs: tt.BaseMs, MaxMs: tt.MaxMs}
+	case "jitter":
+		return vu.ThinkTimeConfig{BaseMs: tt.BaseMs, JitterMs: tt.JitterMs, MaxMs: tt.MaxMs}
+	case "uniform":
+		return vu.ThinkTimeConfig{Distribution: vu.ThinkTimeUniform, BaseMs: tt.BaseMs, JitterMs: tt.JitterMs, MaxMs: tt.MaxMs}
+	case "exponential":
+		return vu.ThinkTimeConfig{Distribution: vu.ThinkTimeExponential, BaseMs: tt.BaseMs, MaxMs: tt.MaxMs}
+	default:
+		return vu.ThinkTimeConfig{}
 	}
 }
 
+// mapToolThinkTime converts the per-tool think times of an assignment.
+func mapToolThinkTime(configs map[string]types.ThinkTimeConfig) map[string]vu.ThinkTimeConfig {
+	if len(configs) == 0 {
+		return nil
+	}
+	result := make(map[string]vu.ThinkTimeConfig, len(configs))
+	for tool, tt := range configs {
+		result[tool] = mapThinkTime(&tt)
+	}
+	return result
+}
+
 // cleanupAssignment removes an assignment from tracking.
 func (e *AssignmentExecutor) cleanupAssignment(runID, leaseID string) {
 	e.mu.Lock()
@@ -294,6 +816,8 @@ func (e *AssignmentExecutor) cleanupAssignment(runID, leaseID string) {
 		delete(leases, leaseID)
 		if len(leases) == 0 {
 			delete(e.runLeases, runID)
+			delete(e.profiledRuns, runID)
+			e.forgetSecretAccesses(runID)
 		}
 	}
 }
@@ -330,6 +854,109 @@ func (e *AssignmentExecutor) StopRun(runID string, immediate bool) {
 	}
 }
 
+// SetPausedRuns pauses the assignments of the given runs and resumes those
+// of every other run, so it takes the full list of paused runs each time.
+// Paused VUs hold before their next operation and keep their sessions.
+func (e *AssignmentExecutor) SetPausedRuns(runIDs []string) {
+	paused := make(map[string]bool, len(runIDs))
+	for _, runID := range runIDs {
+		paused[runID] = true
+	}
+
+	e.mu.RLock()
+	running := make([]*runningAssignment, 0, len(e.active))
+	for _, r := range e.active {
+		running = append(running, r)
+	}
+	e.mu.RUnlock()
+
+	changed := make(map[string]bool)
+	for _, r := range running {
+		runID := r.assignment.RunID
+		if r.setPaused(paused[runID]) {
+			changed[runID] = paused[runID]
+		}
+	}
+	for runID, p := range changed {
+		if p {
+			log.Printf("[Worker] Paused run %s", runID)
+		} else {
+			log.Printf("[Worker] Resumed run %s", runID)
+		}
+	}
+}
+
+// SetShedRate makes every assignment, running or yet to start, shed rate
+// (0 to 1) of its load, see vu.Engine.Shed.
+func (e *AssignmentExecutor) SetShedRate(rate float64) {
+	e.mu.Lock()
+	e.shedRate = rate
+	running := make([]*runningAssignment, 0, len(e.active))
+	for _, r := range e.active {
+		running = append(running, r)
+	}
+	e.mu.Unlock()
+
+	for _, r := range running {
+		r.workloadMu.Lock()
+		if r.engine != nil {
+			r.engine.Shed(rate)
+		}
+		r.workloadMu.Unlock()
+	}
+}
+
+// ShedRate returns the share of load the worker's assignments shed.
+func (e *AssignmentExecutor) ShedRate() float64 {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.shedRate
+}
+
+// ObserveResources records a sample of the worker's resource use and feeds
+// it to throttle. When the throttle changes how much load to shed, every
+// assignment sheds the new share and the runs being executed are warned.
+func (e *AssignmentExecutor) ObserveResources(usage ResourceUsage, throttle *ResourceThrottle) {
+	e.mu.Lock()
+	e.usage = usage
+	e.mu.Unlock()
+
+	warning, changed := throttle.Observe(usage, time.Now().UnixMilli())
+	if !changed {
+		return
+	}
+	log.Printf("[Worker] %s at %.0f%% (limit %.0f%%), shedding %.0f%% of load",
+		warning.Resource, warning.Usage, warning.Limit, warning.ShedRate*100)
+	e.SetShedRate(warning.ShedRate)
+	e.ReportGeneratorWarning(warning)
+}
+
+// ResourceUsage returns the latest sample of the worker's resource use.
+func (e *AssignmentExecutor) ResourceUsage() ResourceUsage {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.usage
+}
+
+// ReportGeneratorWarning ships a warning about the worker's health to the
+// telemetry of every run it is executing.
+func (e *AssignmentExecutor) ReportGeneratorWarning(warning types.LoadGeneratorWarning) {
+	warning.WorkerID = e.workerID
+	e.mu.RLock()
+	runIDs := make([]string, 0, len(e.runLeases))
+	for runID := range e.runLeases {
+		runIDs = append(runIDs, runID)
+	}
+	e.mu.RUnlock()
+
+	if e.telemetryShipper == nil {
+		return
+	}
+	for _, runID := range runIDs {
+		e.telemetryShipper.ShipGeneratorWarning(runID, warning)
+	}
+}
+
 // ActiveAssignments returns the number of currently active assignments.
 func (e *AssignmentExecutor) ActiveAssignments() int {
 	e.mu.RLock()
@@ -340,12 +967,20 @@ func (e *AssignmentExecutor) ActiveAssignments() int {
 // ActiveVUs returns the total number of VUs across all active assignments.
 func (e *AssignmentExecutor) ActiveVUs() int {
 	e.mu.RLock()
-	defer e.mu.RUnlock()
+	running := make([]*runningAssignment, 0, len(e.active))
+	for _, r := range e.active {
+		running = append(running, r)
+	}
+	e.mu.RUnlock()
+
 	total := 0
-	for _, running := range e.active {
-		if running.engine != nil {
-			total += int(running.engine.Metrics().ActiveVUs.Load())
+	for _, r := range running {
+		// The engine is set under workloadMu once the assignment starts.
+		r.workloadMu.Lock()
+		if r.engine != nil {
+			total += int(r.engine.Metrics().ActiveVUs.Load())
 		}
+		r.workloadMu.Unlock()
 	}
 	return total
 }
@@ -366,6 +1001,67 @@ func mapSessionMode(mode string) session.SessionMode {
 	}
 }
 
+// operationMix maps entries to the assignment's op mix, filling in
+// discovered tools when its workload discovers them, and adds the
+// workload's workflows. The caller holds workloadMu.
+func (r *runningAssignment) operationMix(entries []types.OpMixEntry) *vu.OperationMix {
+	mix, scale := mapOperationMix(entries), 1
+	if r.workload.AutoDiscoverTools != nil {
+		// Discovery scales the weights of the other entries by the number
+		// of tools; workflows keep their share the same way.
+		mix, scale = withDiscoveredTools(entries, r.discovered), max(len(r.discovered), 1)
+	}
+	for _, wf := range mapWorkflows(r.workload.Workflows) {
+		wf.Weight *= scale
+		mix.Operations = append(mix.Operations, wf)
+	}
+	return mix
+}
+
+// mapWorkflows converts workflows to op mix entries that run them. A
+// workflow with an extraction path that does not compile is left out.
+func mapWorkflows(configs []types.WorkflowConfig) []vu.OperationWeight {
+	var ops []vu.OperationWeight
+	for _, cfg := range configs {
+		wf, err := mapWorkflow(cfg)
+		if err != nil {
+			log.Printf("[Worker] Skipping workflow %s: %v", cfg.Name, err)
+			continue
+		}
+		ops = append(ops, vu.OperationWeight{Weight: cfg.Weight, Workflow: wf})
+	}
+	return ops
+}
+
+func mapWorkflow(cfg types.WorkflowConfig) (*vu.Workflow, error) {
+	wf := &vu.Workflow{Name: cfg.Name, Steps: make([]vu.WorkflowStep, len(cfg.Steps))}
+	for i, s := range cfg.Steps {
+		step := vu.WorkflowStep{
+			Name: s.Name,
+			Op: vu.OperationWeight{
+				Operation:  vu.OperationType(s.Operation),
+				ToolName:   s.ToolName,
+				Arguments:  s.Arguments,
+				URI:        s.URI,
+				PromptName: s.PromptName,
+				Checks:     checks.Compile(s.Checks),
+			},
+		}
+		if len(s.Extract) > 0 {
+			step.Extract = make(map[string]*checks.Path, len(s.Extract))
+			for name, src := range s.Extract {
+				path, err := checks.ParsePath(src)
+				if err != nil {
+					return nil, fmt.Errorf("step %s: extract %s: %w", s.Name, name, err)
+				}
+				step.Extract[name] = path
+			}
+		}
+		wf.Steps[i] = step
+	}
+	return wf, nil
+}
+
 // mapOperationMix converts types.OpMixEntry to vu.OperationMix.
 func mapOperationMix(entries []types.OpMixEntry) *vu.OperationMix {
 	ops := make([]vu.OperationWeight, len(entries))
@@ -377,6 +1073,7 @@ func mapOperationMix(entries []types.OpMixEntry) *vu.OperationMix {
 			Arguments:  e.Arguments,
 			URI:        e.URI,
 			PromptName: e.PromptName,
+			Checks:     checks.Compile(e.Checks),
 		}
 	}
 	return &vu.OperationMix{Operations: ops}
@@ -0,0 +1,362 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/transport"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+	"github.com/bc-dunia/mcpdrill/internal/vu"
+)
+
+func TestAssignmentExecutor_WorkloadUpdate(t *testing.T) {
+	executor := NewAssignmentExecutor("worker-1", nil, nil)
+
+	running := &runningAssignment{
+		assignment: types.WorkerAssignment{RunID: "run-1", LeaseID: "lse_1"},
+		cancel:     func() {},
+		workload: types.WorkloadConfig{OpMix: []types.OpMixEntry{
+			{Operation: "tools/list", Weight: 1},
+			{Operation: "ping", Weight: 1},
+		}},
+	}
+	executor.active["lse_1"] = running
+
+	update := types.WorkerAssignment{
+		RunID:   "run-1",
+		LeaseID: "lse_1",
+		Workload: types.WorkloadConfig{OpMix: []types.OpMixEntry{
+			{Operation: "tools/list", Weight: 0},
+			{Operation: "ping", Weight: 5},
+		}},
+		WorkloadRevision: 2,
+		WorkloadUpdate:   true,
+	}
+	if err := executor.Execute(context.Background(), update); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if running.workloadRevision != 2 || running.workload.OpMix[1].Weight != 5 {
+		t.Fatalf("expected revision 2 to be applied, got revision %d mix %+v", running.workloadRevision, running.workload.OpMix)
+	}
+
+	stale := update
+	stale.WorkloadRevision = 1
+	stale.Workload = types.WorkloadConfig{OpMix: []types.OpMixEntry{{Operation: "ping", Weight: 1}}}
+	if err := executor.Execute(context.Background(), stale); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if running.workloadRevision != 2 || len(running.workload.OpMix) != 2 {
+		t.Errorf("expected stale update to be ignored, got revision %d mix %+v", running.workloadRevision, running.workload.OpMix)
+	}
+
+	unknown := update
+	unknown.LeaseID = "lse_unknown"
+	if err := executor.Execute(context.Background(), unknown); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if executor.ActiveAssignments() != 1 {
+		t.Errorf("expected update for unknown lease not to start work, got %d active", executor.ActiveAssignments())
+	}
+}
+
+func TestAssignmentExecutor_PausedRunsHoldTheirDuration(t *testing.T) {
+	executor := NewAssignmentExecutor("worker-1", nil, nil)
+	running := &runningAssignment{
+		assignment:   types.WorkerAssignment{RunID: "run-1", LeaseID: "lse_1"},
+		cancel:       func() {},
+		pauseChanged: make(chan struct{}, 1),
+	}
+	other := &runningAssignment{
+		assignment:   types.WorkerAssignment{RunID: "run-2", LeaseID: "lse_2"},
+		cancel:       func() {},
+		pauseChanged: make(chan struct{}, 1),
+	}
+	executor.active["lse_1"] = running
+	executor.active["lse_2"] = other
+
+	done := make(chan bool, 1)
+	go func() {
+		done <- running.waitDuration(context.Background(), 80*time.Millisecond)
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	executor.SetPausedRuns([]string{"run-1"})
+	if !running.paused.Load() || other.paused.Load() {
+		t.Fatalf("expected only run-1 paused, got run-1=%v run-2=%v", running.paused.Load(), other.paused.Load())
+	}
+	select {
+	case <-done:
+		t.Fatal("expected the duration to stand still while paused")
+	case <-time.After(150 * time.Millisecond):
+	}
+
+	executor.SetPausedRuns(nil)
+	select {
+	case ok := <-done:
+		if !ok {
+			t.Fatal("expected the duration to expire")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the duration to expire after resume")
+	}
+}
+
+func TestAssignmentExecutor_PausedRunsHoldTheirWarmup(t *testing.T) {
+	executor := NewAssignmentExecutor("worker-1", nil, nil)
+	running := &runningAssignment{
+		assignment:   types.WorkerAssignment{RunID: "run-1", LeaseID: "lse_1", WarmupMs: 100},
+		cancel:       func() {},
+		startedAt:    time.Now(),
+		pauseChanged: make(chan struct{}, 1),
+	}
+	running.warmupEnd.Store(running.startedAt.Add(100 * time.Millisecond).UnixNano())
+	executor.active["lse_1"] = running
+
+	time.Sleep(20 * time.Millisecond)
+	executor.SetPausedRuns([]string{"run-1"})
+	time.Sleep(150 * time.Millisecond)
+	executor.SetPausedRuns(nil)
+
+	// The warmup had about 80 ms left when the run paused, and still has
+	// them after it resumed.
+	resumed := time.Now()
+	if !running.inWarmup(resumed) {
+		t.Fatal("expected the warmup to stand still while paused")
+	}
+	if running.inWarmup(resumed.Add(100 * time.Millisecond)) {
+		t.Error("expected the warmup to end once its remaining time has run")
+	}
+
+	// A pause after the warmup ended does not bring it back.
+	time.Sleep(100 * time.Millisecond)
+	executor.SetPausedRuns([]string{"run-1"})
+	time.Sleep(20 * time.Millisecond)
+	executor.SetPausedRuns(nil)
+	if running.inWarmup(time.Now()) {
+		t.Error("expected a pause after the warmup not to extend it")
+	}
+
+	noWarmup := &runningAssignment{startedAt: time.Now()}
+	if noWarmup.inWarmup(noWarmup.startedAt) {
+		t.Error("expected no warmup without warmup_ms")
+	}
+}
+
+func TestBuildResultCapture(t *testing.T) {
+	defaults := buildResultCapture(nil)
+	if defaults.SummarizeAboveBytes != transport.DefaultSummarizeAboveBytes || defaults.FingerprintMaxDepth != transport.DefaultFingerprintMaxDepth {
+		t.Errorf("expected worker defaults, got %+v", defaults)
+	}
+
+	disabled := buildResultCapture(&types.ResultCaptureConfig{SummarizeAboveBytes: 0})
+	if disabled.SummarizeAboveBytes != 0 {
+		t.Errorf("expected threshold 0 to disable summarization, got %d", disabled.SummarizeAboveBytes)
+	}
+
+	depthOnly := buildResultCapture(&types.ResultCaptureConfig{SummarizeAboveBytes: -1, FingerprintMaxDepth: 8})
+	if depthOnly.SummarizeAboveBytes != transport.DefaultSummarizeAboveBytes || depthOnly.FingerprintMaxDepth != 8 {
+		t.Errorf("expected default threshold with depth 8, got %+v", depthOnly)
+	}
+}
+
+func TestMapThinkTime(t *testing.T) {
+	if got := mapThinkTime(nil); got.BaseMs != 0 || got.JitterMs != 0 {
+		t.Errorf("expected no think time by default, got %+v", got)
+	}
+	if got := mapThinkTime(&types.ThinkTimeConfig{Mode: "none", BaseMs: 100, JitterMs: 50}); got.BaseMs != 0 || got.JitterMs != 0 {
+		t.Errorf("expected mode none to ignore durations, got %+v", got)
+	}
+	if got := mapThinkTime(&types.ThinkTimeConfig{Mode: "fixed", BaseMs: 100, JitterMs: 50}); got.BaseMs != 100 || got.JitterMs != 0 {
+		t.Errorf("expected fixed think time without jitter, got %+v", got)
+	}
+	if got := mapThinkTime(&types.ThinkTimeConfig{Mode: "jitter", BaseMs: 100, JitterMs: 50}); got.BaseMs != 100 || got.JitterMs != 50 {
+		t.Errorf("expected jittered think time, got %+v", got)
+	}
+}
+
+func TestBuildVUConfig_Arrival(t *testing.T) {
+	executor := NewAssignmentExecutor("worker-1", nil, nil)
+	a := types.WorkerAssignment{RunID: "run-1", LeaseID: "lse_1", VUIDStart: 0, VUIDEnd: 4}
+
+	if cfg := executor.buildVUConfig(a, nil, nil, nil); cfg.Mode != vu.ModeNormal || cfg.Arrival != nil {
+		t.Errorf("expected closed model without arrival, got mode %s", cfg.Mode)
+	}
+
+	a.Workload.Arrival = &types.ArrivalConfig{RateRPS: 25, Schedule: "constant", MaxInFlight: 50}
+	cfg := executor.buildVUConfig(a, nil, nil, nil)
+	if cfg.Mode != vu.ModeArrivalRate || cfg.Arrival == nil {
+		t.Fatalf("expected arrival-rate mode, got %s", cfg.Mode)
+	}
+	if cfg.Arrival.RateRPS != 25 || cfg.Arrival.Schedule != vu.ArrivalConstant || cfg.Arrival.MaxInFlight != 50 || cfg.Load.TargetVUs != 4 {
+		t.Errorf("unexpected arrival config %+v with %d VUs", cfg.Arrival, cfg.Load.TargetVUs)
+	}
+}
+
+func TestBuildConfigs_DataFeedsAndHeaderTemplates(t *testing.T) {
+	executor := NewAssignmentExecutor("worker-1", nil, nil)
+	a := types.WorkerAssignment{RunID: "run-1", LeaseID: "lse_1", VUIDStart: 8, VUIDEnd: 12}
+	a.Target.Headers = map[string]string{"X-Static": "1", "X-User": "${feed.users.email}"}
+
+	transportCfg := executor.buildTransportConfig(a)
+	if transportCfg.Headers["X-Static"] != "1" || transportCfg.Headers["X-User"] != "" {
+		t.Errorf("expected only static headers on the session, got %v", transportCfg.Headers)
+	}
+	cfg := executor.buildVUConfig(a, nil, nil, transportCfg)
+	if cfg.HeaderTemplates["X-User"] != "${feed.users.email}" || len(cfg.HeaderTemplates) != 1 || cfg.VUIndexBase != 8 {
+		t.Errorf("unexpected VU config: templates %v, base %d", cfg.HeaderTemplates, cfg.VUIndexBase)
+	}
+
+	feeds, err := parseDataFeeds([]types.DataFeedConfig{{Name: "users", Format: "csv", Data: "email\na@example.com\n"}})
+	if err != nil || feeds["users"] == nil || len(feeds["users"].Rows) != 1 {
+		t.Fatalf("parseDataFeeds = %v, %v", feeds, err)
+	}
+	if _, err := parseDataFeeds([]types.DataFeedConfig{{Name: "users", Format: "xml", Data: "<a/>"}}); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestBuildTransportConfig_StreamResumption(t *testing.T) {
+	executor := NewAssignmentExecutor("worker-1", nil, nil)
+	a := types.WorkerAssignment{RunID: "run-1"}
+	if cfg := executor.buildTransportConfig(a); cfg.ResumeAttempts != 0 {
+		t.Errorf("expected resumption off by default, got %d attempts", cfg.ResumeAttempts)
+	}
+
+	a.Target.StreamResumption = &types.StreamResumptionConfig{MaxAttempts: 3, BackoffMs: 250}
+	cfg := executor.buildTransportConfig(a)
+	if cfg.ResumeAttempts != 3 || cfg.ResumeBackoff != 250*time.Millisecond {
+		t.Errorf("unexpected resumption config: %d attempts, %v backoff", cfg.ResumeAttempts, cfg.ResumeBackoff)
+	}
+}
+
+func TestBuildRequestIDs(t *testing.T) {
+	if cfg := buildRequestIDs(nil); cfg != nil {
+		t.Errorf("expected default ids without config, got %+v", cfg)
+	}
+	cfg := buildRequestIDs(&types.RequestIDConfig{DuplicateEvery: 10})
+	if cfg.Format != transport.RequestIDFormatString || cfg.DuplicateEvery != 10 {
+		t.Errorf("expected string ids with duplicates, got %+v", cfg)
+	}
+}
+
+func TestAssignmentExecutor_SecretAccesses(t *testing.T) {
+	t.Setenv("MCPDRILL_TEST_CLIENT_SECRET", "s3cret")
+	executor := NewAssignmentExecutor("worker-1", nil, nil)
+	executor.runLeases["run-1"] = map[string]struct{}{"lse_1": {}}
+
+	for i := 0; i < 2; i++ {
+		if value, err := executor.resolveSecret(context.Background(), "run-1", "env://MCPDRILL_TEST_CLIENT_SECRET"); err != nil || value != "s3cret" {
+			t.Fatalf("resolveSecret = %q, %v", value, err)
+		}
+	}
+	accesses := executor.DrainSecretAccesses()
+	if len(accesses) != 1 || accesses[0].RunID != "run-1" || accesses[0].Ref != "env://MCPDRILL_TEST_CLIENT_SECRET" {
+		t.Fatalf("expected one access per run and ref, got %+v", accesses)
+	}
+	executor.RequeueSecretAccesses(accesses)
+	if len(executor.DrainSecretAccesses()) != 1 {
+		t.Error("expected a requeued access to be drained again")
+	}
+
+	// Once the run has no assignments left a later use is reported again.
+	executor.cleanupAssignment("run-1", "lse_1")
+	executor.resolveSecret(context.Background(), "run-1", "env://MCPDRILL_TEST_CLIENT_SECRET")
+	if len(executor.DrainSecretAccesses()) != 1 {
+		t.Error("expected the access to be reported again after the run ended")
+	}
+}
+
+func TestBuildFuzzer(t *testing.T) {
+	if fuzzer, err := buildFuzzer(nil); fuzzer != nil || err != nil {
+		t.Errorf("expected no fuzzer without config, got %v, %v", fuzzer, err)
+	}
+	if _, err := buildFuzzer(&types.FuzzConfig{Ratio: 0.1, Mutations: []string{"wrong_type", "deep_nesting"}}); err != nil {
+		t.Errorf("buildFuzzer failed: %v", err)
+	}
+	if _, err := buildFuzzer(&types.FuzzConfig{Ratio: 0.1, Mutations: []string{"shuffle"}}); err == nil {
+		t.Error("expected an unknown mutation to fail")
+	}
+}
+
+func TestBuildCancellation(t *testing.T) {
+	if c := buildCancellation(&types.CancellationConfig{}); c != nil {
+		t.Errorf("expected no cancellation at rate 0, got %+v", c)
+	}
+	c := buildCancellation(&types.CancellationConfig{Rate: 0.2, GraceMs: 500})
+	if c == nil || c.Rate != 0.2 || c.MaxDelay != defaultCancelMaxDelay || c.Grace != 500*time.Millisecond {
+		t.Errorf("unexpected cancellation config %+v", c)
+	}
+}
+
+func TestBuildThrottle(t *testing.T) {
+	if th := buildThrottle(&types.ThrottleConfig{Policy: "ignore"}); th != nil {
+		t.Errorf("expected no throttle when ignoring rate limits, got %+v", th)
+	}
+	th := buildThrottle(&types.ThrottleConfig{Policy: "honor_retry_after", BaseBackoffMs: 250})
+	if th == nil || th.Policy != vu.ThrottleHonorRetryAfter || th.BaseBackoff != 250*time.Millisecond {
+		t.Errorf("unexpected throttle config %+v", th)
+	}
+}
+
+func TestBuildRetry(t *testing.T) {
+	if r := buildRetry(&types.RetryConfig{}); r != nil {
+		t.Errorf("expected no retries without max_retries, got %+v", r)
+	}
+	r := buildRetry(&types.RetryConfig{MaxRetries: 2})
+	if r == nil || r.Backoff != defaultRetryBackoff || r.MaxBackoff != defaultRetryMaxBackoff || len(r.On) != len(vu.DefaultRetryClasses) {
+		t.Errorf("expected the default retry policy, got %+v", r)
+	}
+	r = buildRetry(&types.RetryConfig{MaxRetries: 1, BackoffMs: 50, RetryOn: []string{"timeout"}})
+	if r.Backoff != 50*time.Millisecond || len(r.On) != 1 || r.On[0] != vu.RetryTimeout {
+		t.Errorf("unexpected retry policy %+v", r)
+	}
+}
+
+func TestAssignmentExecutor_ReportGeneratorWarning(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received = make(map[string][]types.LoadGeneratorWarning)
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			RunID             string                       `json:"run_id"`
+			GeneratorWarnings []types.LoadGeneratorWarning `json:"generator_warnings"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		mu.Lock()
+		received[req.RunID] = append(received[req.RunID], req.GeneratorWarnings...)
+		mu.Unlock()
+		_ = json.NewEncoder(w).Encode(map[string]int{"accepted": 0})
+	}))
+	defer server.Close()
+
+	client := NewRetryHTTPClient(context.Background(), server.URL, server.Client(), RetryConfig{})
+	shipper := NewTelemetryShipper(context.Background(), "worker-1", client)
+	defer shipper.Close()
+
+	executor := NewAssignmentExecutor("worker-1", nil, shipper)
+	executor.runLeases["run-1"] = map[string]struct{}{"lse_1": {}}
+	executor.runLeases["run-2"] = map[string]struct{}{"lse_2": {}}
+
+	executor.SetShedRate(0.2)
+	if got := executor.ShedRate(); got != 0.2 {
+		t.Errorf("expected shed rate 0.2, got %v", got)
+	}
+	executor.ReportGeneratorWarning(types.LoadGeneratorWarning{Kind: types.GeneratorWarningThrottle, Resource: "cpu", ShedRate: 0.2})
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, runID := range []string{"run-1", "run-2"} {
+		warnings := received[runID]
+		if len(warnings) != 1 || warnings[0].WorkerID != "worker-1" || warnings[0].ShedRate != 0.2 {
+			t.Errorf("%s: expected one warning from worker-1, got %+v", runID, warnings)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/bc-dunia/mcpdrill/internal/argsgen"
+	"github.com/bc-dunia/mcpdrill/internal/transport"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+	"github.com/bc-dunia/mcpdrill/internal/vu"
+)
+
+// discoveredTool is a tool the target lists, with the generator of its
+// arguments.
+type discoveredTool struct {
+	name      string
+	generator *argsgen.Generator
+	required  []string
+}
+
+// discoverTools lists the target's tools on a probe session and keeps the
+// ones selection allows: excluded tools are dropped, and so are tools hinted
+// destructive unless they are included explicitly. Tools whose input schema
+// cannot be read are skipped.
+func discoverTools(ctx context.Context, adapter transport.Adapter, cfg *transport.TransportConfig, selection *types.AutoDiscoverToolsConfig) ([]discoveredTool, error) {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	conn, _, err := openProbe(ctx, adapter, cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var tools []types.Tool
+	if err := listAll(ctx, conn.ToolsList, func(result json.RawMessage) (*string, error) {
+		var page types.ToolsListResult
+		err := json.Unmarshal(result, &page)
+		tools = append(tools, page.Tools...)
+		return page.NextCursor, err
+	}); err != nil {
+		return nil, fmt.Errorf("tools/list: %w", err)
+	}
+	return selectTools(tools, selection), nil
+}
+
+// selectTools keeps the tools selection allows and builds their generators.
+func selectTools(tools []types.Tool, selection *types.AutoDiscoverToolsConfig) []discoveredTool {
+	excluded := make(map[string]struct{}, len(selection.ExcludeTools))
+	for _, name := range selection.ExcludeTools {
+		excluded[name] = struct{}{}
+	}
+	discovered := make([]discoveredTool, 0, len(tools))
+	for _, tool := range tools {
+		if _, skip := excluded[tool.Name]; skip {
+			continue
+		}
+		if tool.Annotations != nil && tool.Annotations.DestructiveHint && !selection.IncludeDestructive {
+			continue
+		}
+		generator, err := argsgen.New(tool.InputSchema)
+		if err != nil {
+			log.Printf("[Worker] Skipping discovered tool %s: %v", tool.Name, err)
+			continue
+		}
+		discovered = append(discovered, discoveredTool{name: tool.Name, generator: generator, required: generator.Required()})
+	}
+	return discovered
+}
+
+// withDiscoveredTools maps entries to an op mix in which every tools/call
+// entry without a tool name becomes one entry per discovered tool, each with
+// the original weight and generated arguments. The weights of the other
+// entries are scaled by the number of tools so the mix keeps its proportions.
+func withDiscoveredTools(entries []types.OpMixEntry, tools []discoveredTool) *vu.OperationMix {
+	mix := mapOperationMix(entries)
+	ops := make([]vu.OperationWeight, 0, len(mix.Operations))
+	for _, op := range mix.Operations {
+		if op.Operation != vu.OpToolsCall || op.ToolName != "" {
+			op.Weight *= max(len(tools), 1)
+			ops = append(ops, op)
+			continue
+		}
+		for _, tool := range tools {
+			call := op
+			call.ToolName = tool.name
+			call.Arguments = nil
+			call.GenerateArguments = tool.generator.Generate
+			call.RequiredArguments = tool.required
+			ops = append(ops, call)
+		}
+	}
+	return &vu.OperationMix{Operations: ops}
+}
@@ -0,0 +1,94 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/mockserver"
+	"github.com/bc-dunia/mcpdrill/internal/transport"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+	"github.com/bc-dunia/mcpdrill/internal/vu"
+)
+
+func TestDiscoverTools_ListsSelectedTools(t *testing.T) {
+	target := mockserver.New(mockserver.DefaultConfig())
+	schema := json.RawMessage(`{"type":"object","properties":{"q":{"type":"string"}},"required":["q"]}`)
+	if err := target.RegisterTool("search", schema, func(ctx context.Context, args map[string]interface{}) (types.ToolsCallResult, error) {
+		return types.ToolsCallResult{}, nil
+	}); err != nil {
+		t.Fatalf("register tool: %v", err)
+	}
+	if err := target.Start(); err != nil {
+		t.Fatalf("start mock server: %v", err)
+	}
+	defer target.Stop(context.Background())
+
+	executor := NewAssignmentExecutor("worker-1", []string{"127.0.0.0/8", "::1/128"}, nil)
+	cfg := executor.buildTransportConfig(types.WorkerAssignment{
+		Target: types.TargetConfig{URL: target.MCPURL(), Transport: "streamable_http"},
+	})
+	tools, err := discoverTools(context.Background(), transport.NewStreamableHTTPAdapter(), cfg,
+		&types.AutoDiscoverToolsConfig{ExcludeTools: []string{"fast_echo"}})
+	if err != nil {
+		t.Fatalf("discoverTools failed: %v", err)
+	}
+
+	var search *discoveredTool
+	for i := range tools {
+		switch tools[i].name {
+		case "fast_echo":
+			t.Error("expected the excluded tool to be dropped")
+		case "search":
+			search = &tools[i]
+		}
+	}
+	if search == nil {
+		t.Fatalf("expected the registered tool among %d discovered tools", len(tools))
+	}
+	if args := search.generator.Generate(rand.New(rand.NewSource(1))); args["q"] == nil {
+		t.Errorf("expected arguments from the tool's schema, got %v", args)
+	}
+}
+
+func TestSelectTools_SkipsDestructiveTools(t *testing.T) {
+	tools := []types.Tool{
+		{Name: "read"},
+		{Name: "drop", Annotations: &types.ToolAnnotations{DestructiveHint: true}},
+		{Name: "broken", InputSchema: json.RawMessage(`[`)},
+	}
+
+	if got := selectTools(tools, &types.AutoDiscoverToolsConfig{}); len(got) != 1 || got[0].name != "read" {
+		t.Errorf("expected only read selected, got %+v", got)
+	}
+	if got := selectTools(tools, &types.AutoDiscoverToolsConfig{IncludeDestructive: true}); len(got) != 2 {
+		t.Errorf("expected destructive tools selected when included, got %+v", got)
+	}
+}
+
+func TestWithDiscoveredTools_KeepsProportions(t *testing.T) {
+	tools := selectTools([]types.Tool{{Name: "a"}, {Name: "b"}}, &types.AutoDiscoverToolsConfig{})
+	mix := withDiscoveredTools([]types.OpMixEntry{
+		{Operation: "tools/call", Weight: 3},
+		{Operation: "tools/call", ToolName: "fixed", Weight: 1},
+		{Operation: "tools/list", Weight: 2},
+	}, tools)
+
+	weights := map[string]int{}
+	for _, op := range mix.Operations {
+		weights[string(op.Operation)+":"+op.ToolName] = op.Weight
+		if op.Operation == vu.OpToolsCall && op.ToolName != "fixed" && op.GenerateArguments == nil {
+			t.Errorf("expected generated arguments for %s", op.ToolName)
+		}
+	}
+	want := map[string]int{"tools/call:a": 3, "tools/call:b": 3, "tools/call:fixed": 2, "tools/list:": 4}
+	if len(weights) != len(want) {
+		t.Fatalf("expected %v, got %v", want, weights)
+	}
+	for key, weight := range want {
+		if weights[key] != weight {
+			t.Errorf("expected %s weighted %d, got %d", key, weight, weights[key])
+		}
+	}
+}
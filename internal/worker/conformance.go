@@ -0,0 +1,77 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"net/http"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+	"github.com/bc-dunia/mcpdrill/internal/conformance"
+	"github.com/bc-dunia/mcpdrill/internal/transport"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+// conformanceReportRequest is the request body for POST
+// /workers/{id}/conformance-report.
+type conformanceReportRequest struct {
+	RunID  string                      `json:"run_id"`
+	Report *analysis.ConformanceReport `json:"report"`
+}
+
+// runConformance runs the protocol conformance checks of a conformance
+// run's assignment against its target, instead of starting VUs, and ships
+// the report to the control plane, which stops the run once it has it.
+func (e *AssignmentExecutor) runConformance(ctx context.Context, a types.WorkerAssignment, cfg *transport.TransportConfig) error {
+	checksCfg := conformance.Config{
+		Endpoint:             cfg.Endpoint,
+		Headers:              maps.Clone(cfg.Headers),
+		ProtocolVersion:      a.Conformance.ProtocolVersion,
+		Tool:                 a.Conformance.Tool,
+		ToolArguments:        a.Conformance.ToolArguments,
+		CancelAfter:          time.Duration(a.Conformance.CancelAfterMs) * time.Millisecond,
+		Timeout:              time.Duration(a.Conformance.RequestTimeoutMs) * time.Millisecond,
+		AllowPrivateNetworks: e.allowPrivateNets,
+	}
+	// The checks send their own requests, so an OAuth token is fetched once
+	// up front rather than per request.
+	if cfg.TokenSource != nil {
+		token, err := cfg.TokenSource.Token(ctx)
+		if err != nil {
+			return fmt.Errorf("obtain auth token: %w", err)
+		}
+		if checksCfg.Headers == nil {
+			checksCfg.Headers = make(map[string]string)
+		}
+		checksCfg.Headers["Authorization"] = "Bearer " + token
+	}
+
+	report, err := conformance.Run(ctx, checksCfg)
+	if err != nil {
+		return fmt.Errorf("run conformance checks: %w", err)
+	}
+	log.Printf("[Worker] Conformance checks of run %s: %d passed, %d failed, %d skipped",
+		a.RunID, report.Passed, report.Failed, report.Skipped)
+	if e.telemetryShipper != nil {
+		e.telemetryShipper.ShipConformanceReport(a.RunID, report)
+	}
+	return nil
+}
+
+// ShipConformanceReport sends the conformance report of a run to the
+// control plane. It is sent right away rather than batched.
+func (s *TelemetryShipper) ShipConformanceReport(runID string, report *analysis.ConformanceReport) {
+	path := "/workers/" + s.workerID + "/conformance-report"
+	resp, err := s.client.Post(path, conformanceReportRequest{RunID: runID, Report: report})
+	if err != nil {
+		log.Printf("[TelemetryShipper] Failed to ship conformance report: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ReadResponseBody(resp)
+		log.Printf("[TelemetryShipper] Conformance report ship failed: status=%d body=%s", resp.StatusCode, string(body))
+	}
+}
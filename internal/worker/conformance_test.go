@@ -0,0 +1,73 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/mockserver"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+func TestAssignmentExecutor_RunsConformanceChecks(t *testing.T) {
+	target := mockserver.New(mockserver.DefaultConfig())
+	if err := target.Start(); err != nil {
+		t.Fatalf("start mock server: %v", err)
+	}
+	defer target.Stop(context.Background())
+
+	var (
+		mu       sync.Mutex
+		received []conformanceReportRequest
+	)
+	controlPlane := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/workers/worker-1/conformance-report" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		var req conformanceReportRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		mu.Lock()
+		received = append(received, req)
+		mu.Unlock()
+		_ = json.NewEncoder(w).Encode(map[string]bool{"recorded": true})
+	}))
+	defer controlPlane.Close()
+
+	client := NewRetryHTTPClient(context.Background(), controlPlane.URL, controlPlane.Client(), RetryConfig{
+		MaxRetries: 0,
+		Backoff:    10 * time.Millisecond,
+		MaxBackoff: 10 * time.Millisecond,
+	})
+	shipper := NewTelemetryShipper(context.Background(), "worker-1", client)
+	defer shipper.Close()
+	executor := NewAssignmentExecutor("worker-1", []string{"127.0.0.0/8", "::1/128"}, shipper)
+
+	a := types.WorkerAssignment{
+		RunID:       "run-1",
+		Stage:       "preflight",
+		Target:      types.TargetConfig{URL: target.MCPURL(), Transport: "streamable_http"},
+		Conformance: &types.ConformanceConfig{RequestTimeoutMs: 5000},
+	}
+	if err := executor.runConformance(context.Background(), a, executor.buildTransportConfig(a)); err != nil {
+		t.Fatalf("runConformance failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 1 {
+		t.Fatalf("expected one conformance report, got %d", len(received))
+	}
+	report := received[0].Report
+	if received[0].RunID != "run-1" || report == nil {
+		t.Fatalf("unexpected request %+v", received[0])
+	}
+	if report.Target != target.MCPURL() || len(report.Checks) == 0 || report.Passed == 0 {
+		t.Errorf("expected the checks of the target in the report, got %+v", report)
+	}
+}
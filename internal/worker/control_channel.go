@@ -0,0 +1,206 @@
+package worker
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/types"
+	"github.com/bc-dunia/mcpdrill/internal/websocket"
+)
+
+const (
+	// controlChannelDialTimeout bounds connecting and the handshake.
+	controlChannelDialTimeout = 10 * time.Second
+	// controlChannelReadTimeout ends a channel that has been silent for
+	// three of the control plane's 15 second pings.
+	controlChannelReadTimeout = 45 * time.Second
+	// controlChannelWriteTimeout bounds a single frame write.
+	controlChannelWriteTimeout = 10 * time.Second
+	// controlChannelMaxMessage bounds a message from the control plane.
+	controlChannelMaxMessage = 64 << 20
+)
+
+// ErrControlChannelClosed is returned by Receive once the control plane has
+// closed the channel.
+var ErrControlChannelClosed = errors.New("control channel closed")
+
+// ControlChannel is a worker's end of the WebSocket control channel to the
+// control plane, a minimal client side of RFC 6455. Send is safe for
+// concurrent use; Receive must only be called from one goroutine.
+type ControlChannel struct {
+	conn net.Conn
+	br   *bufio.Reader
+
+	writeMu sync.Mutex
+}
+
+// DialControlChannel opens the control channel of workerID at the control
+// plane at baseURL. tlsConfig, if not nil, configures an https connection.
+func DialControlChannel(ctx context.Context, baseURL, workerID, workerToken string, tlsConfig *tls.Config) (*ControlChannel, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid control plane URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported control plane URL scheme %q", u.Scheme)
+	}
+	host := u.Host
+	if u.Port() == "" {
+		port := "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+		host = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, controlChannelDialTimeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "https" {
+		config := &tls.Config{}
+		if tlsConfig != nil {
+			config = tlsConfig.Clone()
+		}
+		if config.ServerName == "" {
+			config.ServerName = u.Hostname()
+		}
+		tlsConn := tls.Client(conn, config)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+
+	c, err := handshakeControlChannel(ctx, conn, u, workerID, workerToken)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func handshakeControlChannel(ctx context.Context, conn net.Conn, u *url.URL, workerID, workerToken string) (*ControlChannel, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	var nonce [16]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce[:])
+
+	target := *u
+	target.Path = strings.TrimSuffix(u.Path, "/") + "/workers/" + workerID + "/channel"
+	req, err := http.NewRequest(http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	if workerToken != "" {
+		req.Header.Set("X-Worker-Token", workerToken)
+	}
+	if err := req.Write(conn); err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+		return nil, fmt.Errorf("control channel refused: %s - %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != websocket.AcceptKey(key) {
+		return nil, errors.New("control channel handshake failed: bad Sec-WebSocket-Accept")
+	}
+
+	_ = conn.SetDeadline(time.Time{})
+	return &ControlChannel{conn: conn, br: br}, nil
+}
+
+// Send sends msg to the control plane.
+func (c *ControlChannel) Send(msg types.ChannelMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return c.writeFrame(websocket.OpText, data)
+}
+
+// Receive waits for the next message from the control plane, answering
+// pings meanwhile.
+func (c *ControlChannel) Receive() (types.ChannelMessage, error) {
+	for {
+		_ = c.conn.SetReadDeadline(time.Now().Add(controlChannelReadTimeout))
+		frame, err := websocket.ReadFrame(c.br, controlChannelMaxMessage, false)
+		if err != nil {
+			return types.ChannelMessage{}, err
+		}
+		switch frame.Opcode {
+		case websocket.OpText:
+			var msg types.ChannelMessage
+			if err := json.Unmarshal(frame.Payload, &msg); err != nil {
+				return types.ChannelMessage{}, fmt.Errorf("invalid control channel message: %w", err)
+			}
+			return msg, nil
+		case websocket.OpPing:
+			if err := c.writeFrame(websocket.OpPong, frame.Payload); err != nil {
+				return types.ChannelMessage{}, err
+			}
+		case websocket.OpClose:
+			_ = c.writeFrame(websocket.OpClose, frame.Payload)
+			return types.ChannelMessage{}, ErrControlChannelClosed
+		}
+	}
+}
+
+// Close closes the channel.
+func (c *ControlChannel) Close() error {
+	payload := binary.BigEndian.AppendUint16(nil, websocket.CloseNormal)
+	_ = c.writeFrame(websocket.OpClose, payload)
+	return c.conn.Close()
+}
+
+// writeFrame writes a single masked frame, as RFC 6455 requires of
+// clients.
+func (c *ControlChannel) writeFrame(opcode byte, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	var mask [4]byte
+	if _, err := rand.Read(mask[:]); err != nil {
+		return err
+	}
+
+	frame := websocket.AppendFrame(make([]byte, 0, 14+len(payload)), opcode, payload, &mask)
+	_ = c.conn.SetWriteDeadline(time.Now().Add(controlChannelWriteTimeout))
+	_, err := c.conn.Write(frame)
+	return err
+}
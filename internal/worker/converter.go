@@ -4,8 +4,10 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"math"
+	"strings"
 	"time"
 
+	"github.com/bc-dunia/mcpdrill/internal/transport"
 	"github.com/bc-dunia/mcpdrill/internal/types"
 	"github.com/bc-dunia/mcpdrill/internal/vu"
 )
@@ -24,35 +26,96 @@ func ConvertToOutcome(result *vu.OperationResult, a types.WorkerAssignment, work
 	}
 
 	outcome := types.OperationOutcome{
-		OpID:        result.TraceID,
-		Operation:   string(result.Operation),
-		ToolName:    result.ToolName,
-		LatencyMs:   latencyMs,
-		OK:          result.Outcome != nil && result.Outcome.OK,
-		TimestampMs: result.StartTime.UnixMilli(),
-		WorkerID:    workerID,
-		ExecutionID: a.ExecutionID,
-		Stage:       a.Stage,
-		StageID:     a.StageID,
-		VUID:        result.VUID,
-		SessionID:   result.SessionID,
+		OpID:           result.TraceID,
+		Operation:      string(result.Operation),
+		ToolName:       result.ToolName,
+		LatencyMs:      latencyMs,
+		OK:             result.Outcome != nil && result.Outcome.OK,
+		TimestampMs:    result.StartTime.UnixMilli(),
+		WorkerID:       workerID,
+		ExecutionID:    a.ExecutionID,
+		Stage:          a.Stage,
+		StageID:        a.StageID,
+		VUID:           result.VUID,
+		SessionID:      result.SessionID,
+		GeneratorGroup: a.GeneratorGroup,
+		Target:         a.TargetName,
+		Checks:         result.Checks,
+		FuzzMutation:   string(result.FuzzMutation),
+		ThrottleWaitMs: result.ThrottleWait.Milliseconds(),
+		ThinkTimeMs:    result.ThinkTime.Milliseconds(),
+		Attempt:        result.Attempt,
+		Shadow:         shadowOutcome(result.Shadow),
+		Workflow:       result.Workflow,
 	}
 
 	if result.Outcome != nil {
 		if result.Outcome.Error != nil {
 			outcome.ErrorType = string(result.Outcome.Error.Type)
 			outcome.ErrorCode = string(result.Outcome.Error.Code)
+			outcome.ErrorMessage = errorMessage(result.Outcome.Error)
+		}
+		outcome.JSONRPCErrorCode = result.Outcome.JSONRPCErrorCode
+		if c := result.Outcome.Cancellation; c != nil {
+			outcome.Cancellation = &types.CancellationInfo{AfterMs: c.AfterMs, Sent: c.Sent, Honored: c.Honored}
+		}
+		if c := result.Outcome.Chaos; c != nil {
+			outcome.Chaos = &types.ChaosInfo{DelayMs: c.DelayMs, Reset: c.Reset, ThrottledMs: c.ThrottledMs}
 		}
 		if result.Outcome.HTTPStatus != nil {
 			outcome.HTTPStatus = *result.Outcome.HTTPStatus
 		}
+		outcome.BackendID = result.Outcome.BackendID
+		outcome.RetryAfterMs = result.Outcome.RetryAfterMs
+		outcome.ResourceURI = result.Outcome.ResourceURI
+		outcome.PromptName = result.Outcome.PromptName
+		outcome.BytesIn = result.Outcome.BytesIn
+		outcome.BytesOut = result.Outcome.BytesOut
+		if summary := result.Outcome.ResultSummary; summary != nil {
+			outcome.ResultSummary = &types.ResultSummary{
+				SizeBytes:   summary.SizeBytes,
+				SHA256:      summary.SHA256,
+				Shape:       summary.Shape,
+				Fingerprint: summary.Fingerprint,
+			}
+		}
+		if id := result.Outcome.RequestID; id != nil {
+			outcome.RequestID = &types.RequestIDInfo{
+				Kind:             string(id.Kind),
+				Duplicate:        id.Duplicate,
+				EchoTypeMismatch: id.EchoTypeMismatch,
+			}
+		}
+		if pt := result.Outcome.PhaseTiming; pt != nil {
+			outcome.Connection = &types.ConnectionInfo{
+				Reused:       pt.ConnectionReused,
+				DNSLookup:    pt.DNSLookup,
+				DNSUs:        pt.DNSUs,
+				DNSCoalesced: pt.DNSCoalesced,
+				DNSFailed:    pt.DNSFailed,
+			}
+		}
 		if result.Outcome.Stream != nil {
 			outcome.Stream = &types.StreamInfo{
-				IsStreaming:     result.Outcome.Stream.IsStreaming,
-				EventsCount:     result.Outcome.Stream.EventsCount,
-				EndedNormally:   result.Outcome.Stream.EndedNormally,
-				Stalled:         result.Outcome.Stream.Stalled,
-				StallDurationMs: int64(result.Outcome.Stream.StallDurationMs),
+				IsStreaming:       result.Outcome.Stream.IsStreaming,
+				EventsCount:       result.Outcome.Stream.EventsCount,
+				EndedNormally:     result.Outcome.Stream.EndedNormally,
+				Stalled:           result.Outcome.Stream.Stalled,
+				StallDurationMs:   int64(result.Outcome.Stream.StallDurationMs),
+				ResumeAttempts:    result.Outcome.Stream.ResumeAttempts,
+				Resumed:           result.Outcome.Stream.Resumed,
+				ServerMessages:    result.Outcome.Stream.ServerMessages,
+				ServerReplies:     result.Outcome.Stream.ServerReplies,
+				ServerReplyErrors: result.Outcome.Stream.ServerReplyErrors,
+
+				TimeToFirstEventMs:      result.Outcome.Stream.TimeToFirstEventMs,
+				ProgressCount:           result.Outcome.Stream.ProgressCount,
+				TimeToFirstProgressMs:   result.Outcome.Stream.TimeToFirstProgressMs,
+				ProgressTokenMismatches: result.Outcome.Stream.ProgressTokenMismatches,
+				ProgressInvalid:         result.Outcome.Stream.ProgressInvalid,
+			}
+			if gaps := result.Outcome.Stream.EventGapHistogram; gaps != nil {
+				outcome.Stream.MaxEventGapMs = gaps.MaxGapMs
 			}
 		}
 	}
@@ -64,6 +127,19 @@ func ConvertToOutcome(result *vu.OperationResult, a types.WorkerAssignment, work
 	return outcome
 }
 
+// errorMessage returns the text of an operation's error: what the tool
+// returned for a tool error, the error message otherwise.
+func errorMessage(err *transport.OperationError) string {
+	msg := err.Message
+	if content, ok := err.Details["content"].([]string); ok && len(content) > 0 {
+		msg = strings.Join(content, "\n")
+	}
+	if len(msg) > types.MaxErrorMessageBytes {
+		msg = strings.ToValidUTF8(msg[:types.MaxErrorMessageBytes], "")
+	}
+	return msg
+}
+
 func generateOpID(t time.Time) string {
 	return "op_" + t.Format("20060102150405") + "_" + randomHex(8)
 }
@@ -0,0 +1,158 @@
+package worker
+
+import (
+	"math"
+	"os"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/mem"
+	"github.com/shirou/gopsutil/v3/process"
+
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+const (
+	// shedStep is how much the shed rate moves per sample, so load is shed
+	// and restored gradually rather than in one jump.
+	shedStep = 0.1
+	// maxShedRate keeps some load running however short the worker is.
+	maxShedRate = 0.9
+	// recoverFraction is the share of its limit a resource must fall below
+	// before shedding eases, so the rate does not flap at the limit.
+	recoverFraction = 0.9
+)
+
+// ResourceLimits are the usage levels, in percent, at which a worker starts
+// shedding load. A limit of 0 leaves the resource unchecked.
+type ResourceLimits struct {
+	CPUPercent float64
+	MemPercent float64
+	FDPercent  float64
+}
+
+// DefaultResourceLimits returns the limits workers throttle at by default.
+func DefaultResourceLimits() ResourceLimits {
+	return ResourceLimits{CPUPercent: 90, MemPercent: 90, FDPercent: 90}
+}
+
+// ResourceUsage is a sample of the resources a worker uses.
+type ResourceUsage struct {
+	CPUPercent float64 // host CPU in use
+	MemPercent float64 // host memory in use
+	MemBytes   int64   // worker process resident memory
+	OpenFDs    int
+	FDLimit    int // soft limit on open file descriptors, 0 if unlimited
+}
+
+// FDPercent returns the share of the file descriptor limit in use, or 0
+// without a limit.
+func (u ResourceUsage) FDPercent() float64 {
+	if u.FDLimit <= 0 {
+		return 0
+	}
+	return float64(u.OpenFDs) / float64(u.FDLimit) * 100
+}
+
+// SampleResourceUsage reads the host's CPU and memory use and the worker
+// process's memory and file descriptors. CPU is measured since the previous
+// call. Values that cannot be read on this platform are left 0.
+func SampleResourceUsage() ResourceUsage {
+	var usage ResourceUsage
+	if percents, err := cpu.Percent(0, false); err == nil && len(percents) > 0 {
+		usage.CPUPercent = percents[0]
+	}
+	if vm, err := mem.VirtualMemory(); err == nil {
+		usage.MemPercent = vm.UsedPercent
+	}
+	proc, err := process.NewProcess(int32(os.Getpid()))
+	if err != nil {
+		return usage
+	}
+	if info, err := proc.MemoryInfo(); err == nil {
+		usage.MemBytes = int64(info.RSS)
+	}
+	if fds, err := proc.NumFDs(); err == nil {
+		usage.OpenFDs = int(fds)
+	}
+	if limits, err := proc.Rlimit(); err == nil {
+		for _, l := range limits {
+			if l.Resource == process.RLIMIT_NOFILE && l.Soft <= math.MaxInt32 {
+				usage.FDLimit = int(l.Soft)
+			}
+		}
+	}
+	return usage
+}
+
+// ResourceThrottle decides how much of its load a worker sheds. Each sample
+// over a limit raises the shed rate by one step, up to maxShedRate; each
+// sample with every resource comfortably below its limit lowers it by one.
+type ResourceThrottle struct {
+	limits ResourceLimits
+	shed   float64
+}
+
+// NewResourceThrottle returns a throttle that sheds load above limits.
+func NewResourceThrottle(limits ResourceLimits) *ResourceThrottle {
+	return &ResourceThrottle{limits: limits}
+}
+
+// ShedRate returns the share of the load, from 0 to 1, to shed.
+func (t *ResourceThrottle) ShedRate() float64 {
+	return t.shed
+}
+
+// Observe updates the shed rate from a usage sample taken at nowMs. If the
+// rate changed, it returns a warning describing why, without a worker ID.
+func (t *ResourceThrottle) Observe(usage ResourceUsage, nowMs int64) (types.LoadGeneratorWarning, bool) {
+	resource, used, limit := t.pressure(usage)
+	shed := t.shed
+	switch {
+	case limit > 0 && used >= limit:
+		shed = min(shed+shedStep, maxShedRate)
+	case limit == 0 || used < limit*recoverFraction:
+		shed = max(shed-shedStep, 0)
+	}
+	// Steps are tenths; rounding keeps repeated steps from drifting.
+	shed = math.Round(shed*10) / 10
+	if shed == t.shed {
+		return types.LoadGeneratorWarning{}, false
+	}
+	t.shed = shed
+
+	warning := types.LoadGeneratorWarning{
+		TimestampMs: nowMs,
+		Kind:        types.GeneratorWarningThrottle,
+		Resource:    resource,
+		Usage:       used,
+		Limit:       limit,
+		ShedRate:    shed,
+	}
+	if shed == 0 {
+		warning.Kind = types.GeneratorWarningThrottleEnded
+	}
+	return warning, true
+}
+
+// pressure returns the resource closest to, or furthest over, its limit,
+// with its usage and limit. limit is 0 if no resource has one.
+func (t *ResourceThrottle) pressure(usage ResourceUsage) (resource string, used, limit float64) {
+	worst := -1.0
+	for _, r := range []struct {
+		name        string
+		used, limit float64
+	}{
+		{"cpu", usage.CPUPercent, t.limits.CPUPercent},
+		{"memory", usage.MemPercent, t.limits.MemPercent},
+		{"fds", usage.FDPercent(), t.limits.FDPercent},
+	} {
+		if r.limit <= 0 {
+			continue
+		}
+		if ratio := r.used / r.limit; ratio > worst {
+			worst = ratio
+			resource, used, limit = r.name, r.used, r.limit
+		}
+	}
+	return resource, used, limit
+}
@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"testing"
+
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+func TestResourceThrottle_ShedsAndRecoversGradually(t *testing.T) {
+	throttle := NewResourceThrottle(ResourceLimits{CPUPercent: 80, MemPercent: 90, FDPercent: 50})
+
+	steps := []struct {
+		usage    ResourceUsage
+		wantShed float64
+		wantKind string // "" if the rate should not change
+		resource string
+	}{
+		{ResourceUsage{CPUPercent: 50, MemPercent: 40}, 0, "", ""},
+		{ResourceUsage{CPUPercent: 95, MemPercent: 40}, 0.1, types.GeneratorWarningThrottle, "cpu"},
+		{ResourceUsage{CPUPercent: 85, MemPercent: 40}, 0.2, types.GeneratorWarningThrottle, "cpu"},
+		{ResourceUsage{CPUPercent: 40, MemPercent: 40, OpenFDs: 600, FDLimit: 1000}, 0.3, types.GeneratorWarningThrottle, "fds"},
+		// Below the limit but within the recovery margin: hold.
+		{ResourceUsage{CPUPercent: 75, MemPercent: 40}, 0.3, "", ""},
+		{ResourceUsage{CPUPercent: 60, MemPercent: 40}, 0.2, types.GeneratorWarningThrottle, "cpu"},
+		{ResourceUsage{CPUPercent: 60, MemPercent: 40}, 0.1, types.GeneratorWarningThrottle, "cpu"},
+		{ResourceUsage{CPUPercent: 60, MemPercent: 40}, 0, types.GeneratorWarningThrottleEnded, "cpu"},
+	}
+	for i, step := range steps {
+		warning, changed := throttle.Observe(step.usage, int64(i))
+		if got := throttle.ShedRate(); got != step.wantShed {
+			t.Errorf("step %d: expected shed rate %v, got %v", i, step.wantShed, got)
+		}
+		if changed != (step.wantKind != "") {
+			t.Errorf("step %d: expected changed=%v", i, step.wantKind != "")
+			continue
+		}
+		if changed && (warning.Kind != step.wantKind || warning.Resource != step.resource || warning.ShedRate != step.wantShed || warning.TimestampMs != int64(i)) {
+			t.Errorf("step %d: unexpected warning %+v", i, warning)
+		}
+	}
+}
+
+func TestResourceThrottle_CapsShedRate(t *testing.T) {
+	throttle := NewResourceThrottle(DefaultResourceLimits())
+	for i := 0; i < 20; i++ {
+		throttle.Observe(ResourceUsage{MemPercent: 99}, 0)
+	}
+	if got := throttle.ShedRate(); got != maxShedRate {
+		t.Errorf("expected the shed rate capped at %v, got %v", maxShedRate, got)
+	}
+
+	unchecked := NewResourceThrottle(ResourceLimits{})
+	if _, changed := unchecked.Observe(ResourceUsage{CPUPercent: 100, MemPercent: 100}, 0); changed {
+		t.Error("expected no shedding without limits")
+	}
+}
+
+func TestResourceUsage_FDPercent(t *testing.T) {
+	if got := (ResourceUsage{OpenFDs: 250, FDLimit: 1000}).FDPercent(); got != 25 {
+		t.Errorf("expected 25%%, got %v", got)
+	}
+	if got := (ResourceUsage{OpenFDs: 250}).FDPercent(); got != 0 {
+		t.Errorf("expected 0%% without a limit, got %v", got)
+	}
+}
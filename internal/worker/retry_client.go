@@ -7,6 +7,7 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"sync"
 	"time"
 )
 
@@ -19,10 +20,12 @@ type RetryConfig struct {
 }
 
 type RetryHTTPClient struct {
-	ctx         context.Context
-	baseURL     string
-	httpClient  *http.Client
-	config      RetryConfig
+	ctx        context.Context
+	baseURL    string
+	httpClient *http.Client
+	config     RetryConfig
+
+	tokenMu     sync.Mutex
 	workerToken string
 }
 
@@ -35,10 +38,20 @@ func NewRetryHTTPClient(ctx context.Context, baseURL string, httpClient *http.Cl
 	}
 }
 
+// SetWorkerToken sets the token later requests authenticate with. It is
+// safe to call while requests are in flight, as when the token rotates.
 func (c *RetryHTTPClient) SetWorkerToken(token string) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
 	c.workerToken = token
 }
 
+func (c *RetryHTTPClient) token() string {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	return c.workerToken
+}
+
 func (c *RetryHTTPClient) Post(path string, body interface{}) (*http.Response, error) {
 	url := c.baseURL + path
 
@@ -56,9 +69,6 @@ func (c *RetryHTTPClient) Post(path string, body interface{}) (*http.Response, e
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
-	if c.workerToken != "" {
-		req.Header.Set("X-Worker-Token", c.workerToken)
-	}
 	req.GetBody = func() (io.ReadCloser, error) {
 		return io.NopCloser(bytes.NewReader(jsonBytes)), nil
 	}
@@ -68,8 +78,8 @@ func (c *RetryHTTPClient) Post(path string, body interface{}) (*http.Response, e
 func (c *RetryHTTPClient) Do(req *http.Request) (*http.Response, error) {
 	var lastErr error
 	backoff := c.config.Backoff
-	if c.workerToken != "" && req.Header.Get("X-Worker-Token") == "" {
-		req.Header.Set("X-Worker-Token", c.workerToken)
+	if token := c.token(); token != "" && req.Header.Get("X-Worker-Token") == "" {
+		req.Header.Set("X-Worker-Token", token)
 	}
 
 	for attempt := 0; attempt <= c.config.MaxRetries; attempt++ {
@@ -0,0 +1,482 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/secrets"
+	"github.com/bc-dunia/mcpdrill/internal/tlsconfig"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+// RunnerConfig configures a worker that takes its assignments from a
+// control plane.
+type RunnerConfig struct {
+	// ControlPlaneURL is the base URL of the control plane.
+	ControlPlaneURL string
+	// TLSConfig, if not nil, configures connections to an https control
+	// plane, both HTTP calls and the control channel.
+	TLSConfig *tls.Config
+	// Project dedicates the worker to runs of one project; empty shares it
+	// with every project.
+	Project  string
+	HostInfo types.HostInfo
+	Capacity types.WorkerCapacity
+	// ControlChannel asks for the WebSocket control channel. Without it,
+	// or while it is down, the worker polls over HTTP.
+	ControlChannel    bool
+	HeartbeatInterval time.Duration
+	PollInterval      time.Duration
+	// PrivateNetworks are the CIDR ranges targets may resolve to.
+	PrivateNetworks []string
+	Telemetry       TelemetryShipperConfig
+	// Secrets resolves the secret references of assignments; nil leaves
+	// the executor's default resolver.
+	Secrets *secrets.Resolver
+	// Limits is the resource use at which the worker sheds load, sampled
+	// every ThrottleInterval. A zero ThrottleInterval never samples.
+	Limits           ResourceLimits
+	ThrottleInterval time.Duration
+}
+
+// Registration is the control plane's answer to a worker registering.
+type Registration struct {
+	WorkerID    string `json:"worker_id"`
+	WorkerToken string `json:"worker_token,omitempty"`
+	// WorkerTokenExpiresAtMs is when the token expires unless a heartbeat
+	// rotates it, or 0 if it does not.
+	WorkerTokenExpiresAtMs int64 `json:"worker_token_expires_at_ms,omitempty"`
+	// ControlChannel is the control channel offered, or empty to poll.
+	ControlChannel string `json:"control_channel,omitempty"`
+}
+
+type registerRequest struct {
+	HostInfo        types.HostInfo       `json:"host_info"`
+	Capacity        types.WorkerCapacity `json:"capacity"`
+	ControlChannels []string             `json:"control_channels,omitempty"`
+	Project         string               `json:"project,omitempty"`
+}
+
+type heartbeatRequest struct {
+	Health         *types.WorkerHealth  `json:"health,omitempty"`
+	SecretAccesses []types.SecretAccess `json:"secret_accesses,omitempty"`
+}
+
+type heartbeatResponse struct {
+	OK                  bool     `json:"ok"`
+	StopRunIDs          []string `json:"stop_run_ids,omitempty"`
+	ImmediateStopRunIDs []string `json:"immediate_stop_run_ids,omitempty"`
+	PausedRunIDs        []string `json:"paused_run_ids,omitempty"`
+	WorkerToken         string   `json:"worker_token,omitempty"`
+}
+
+type assignmentsResponse struct {
+	Assignments []types.WorkerAssignment `json:"assignments"`
+}
+
+type ackAssignmentsRequest struct {
+	LeaseIDs []string `json:"lease_ids"`
+}
+
+// Runner is a worker registered with a control plane. It heartbeats, takes
+// assignments over the control channel or by polling, and executes them
+// until it is closed. The worker binary is one Runner; the control plane
+// can embed another for local use.
+type Runner struct {
+	registration Registration
+	cp           *controlPlane
+	executor     *AssignmentExecutor
+	shipper      *TelemetryShipper
+	channel      *channelHolder
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// StartRunner registers a worker with the control plane and serves its
+// assignments in the background until ctx is done or Close is called.
+func StartRunner(ctx context.Context, cfg RunnerConfig) (*Runner, error) {
+	if cfg.HeartbeatInterval <= 0 {
+		cfg.HeartbeatInterval = 10 * time.Second
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	client := tlsconfig.HTTPClient(cfg.TLSConfig)
+	cp := &controlPlane{baseURL: cfg.ControlPlaneURL, client: client, tlsConfig: cfg.TLSConfig}
+
+	var controlChannels []string
+	if cfg.ControlChannel {
+		controlChannels = []string{types.ControlChannelWebSocket}
+	}
+	registration, err := register(ctx, cp, registerRequest{
+		HostInfo:        cfg.HostInfo,
+		Capacity:        cfg.Capacity,
+		ControlChannels: controlChannels,
+		Project:         cfg.Project,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	retryClient := NewRetryHTTPClient(ctx, cfg.ControlPlaneURL, client, RetryConfig{
+		MaxRetries: 3,
+		Backoff:    100 * time.Millisecond,
+		MaxBackoff: 5 * time.Second,
+	})
+	cp.onRotate = retryClient.SetWorkerToken
+	cp.setWorkerToken(registration.WorkerToken)
+
+	shipper := NewTelemetryShipperWithConfig(ctx, registration.WorkerID, retryClient, cfg.Telemetry)
+	executor := NewAssignmentExecutor(registration.WorkerID, cfg.PrivateNetworks, shipper)
+	if cfg.Secrets != nil {
+		executor.SetSecretResolver(cfg.Secrets)
+	}
+
+	r := &Runner{
+		registration: *registration,
+		cp:           cp,
+		executor:     executor,
+		shipper:      shipper,
+		channel:      &channelHolder{},
+		cancel:       cancel,
+	}
+	workerID := registration.WorkerID
+	if registration.ControlChannel == types.ControlChannelWebSocket {
+		r.goRun(func() { runControlChannel(ctx, cp, workerID, executor, r.channel) })
+	}
+	if cfg.ThrottleInterval > 0 {
+		throttle := NewResourceThrottle(cfg.Limits)
+		r.goRun(func() { throttleLoop(ctx, cfg.ThrottleInterval, executor, throttle) })
+	}
+	r.goRun(func() { heartbeatLoop(ctx, cp, workerID, cfg.HeartbeatInterval, executor, r.channel) })
+	r.goRun(func() { pollAssignments(ctx, cp, workerID, cfg.PollInterval, executor, r.channel) })
+	return r, nil
+}
+
+func (r *Runner) goRun(f func()) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		f()
+	}()
+}
+
+// Registration returns what the control plane answered at registration.
+func (r *Runner) Registration() Registration {
+	return r.registration
+}
+
+// ActiveAssignments returns the number of assignments still executing.
+func (r *Runner) ActiveAssignments() int {
+	return r.executor.ActiveAssignments()
+}
+
+// Close stops taking assignments, waits until the active ones end or ctx
+// is done, and flushes telemetry. It returns how many operations were
+// shipped and dropped over the worker's life.
+func (r *Runner) Close(ctx context.Context) (shipped, dropped int64) {
+	r.cancel()
+	r.wg.Wait()
+
+wait:
+	for r.executor.ActiveAssignments() > 0 {
+		select {
+		case <-ctx.Done():
+			log.Printf("[Worker] Shutdown timeout, abandoning %d active assignment(s)", r.executor.ActiveAssignments())
+			break wait
+		case <-time.After(500 * time.Millisecond):
+			log.Printf("[Worker] Waiting for %d active assignment(s) to complete...", r.executor.ActiveAssignments())
+		}
+	}
+	r.shipper.Close()
+	return r.shipper.Stats()
+}
+
+// controlPlane is how a worker reaches the control plane. Its worker token
+// changes when the control plane rotates it.
+type controlPlane struct {
+	baseURL   string
+	client    *http.Client
+	tlsConfig *tls.Config
+
+	mu    sync.Mutex
+	token string
+	// onRotate, if set, is called with every new token.
+	onRotate func(token string)
+}
+
+func (c *controlPlane) workerToken() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+// setWorkerToken adopts a token issued at registration or rotation.
+func (c *controlPlane) setWorkerToken(token string) {
+	c.mu.Lock()
+	c.token = token
+	onRotate := c.onRotate
+	c.mu.Unlock()
+	if onRotate != nil {
+		onRotate(token)
+	}
+}
+
+// rotateWorkerToken adopts a token handed out with a heartbeat, if any.
+func (c *controlPlane) rotateWorkerToken(token string) {
+	if token != "" {
+		c.setWorkerToken(token)
+	}
+}
+
+// newRequest builds a request to path at the control plane carrying the
+// current worker token.
+func (c *controlPlane) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token := c.workerToken(); token != "" {
+		req.Header.Set("X-Worker-Token", token)
+	}
+	return req, nil
+}
+
+func register(ctx context.Context, cp *controlPlane, req registerRequest) (*Registration, error) {
+	body, _ := json.Marshal(req)
+
+	httpReq, err := cp.newRequest(ctx, http.MethodPost, "/workers/register", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := cp.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("registration failed: %s - %s", resp.Status, string(respBody))
+	}
+
+	var result Registration
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func heartbeatLoop(ctx context.Context, cp *controlPlane, workerID string, interval time.Duration, executor *AssignmentExecutor, channel *channelHolder) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if channel.sendHeartbeat(executor) {
+				continue
+			}
+			resp, err := sendHeartbeat(ctx, cp, workerID, executor)
+			if err != nil {
+				log.Printf("[Worker] Heartbeat failed: %v", err)
+				continue
+			}
+			cp.rotateWorkerToken(resp.WorkerToken)
+
+			for _, runID := range resp.StopRunIDs {
+				executor.StopRun(runID, false)
+			}
+			for _, runID := range resp.ImmediateStopRunIDs {
+				executor.StopRun(runID, true)
+			}
+			executor.SetPausedRuns(resp.PausedRunIDs)
+		}
+	}
+}
+
+// throttleLoop samples the worker's resource use every interval, shedding
+// load while it runs short.
+func throttleLoop(ctx context.Context, interval time.Duration, executor *AssignmentExecutor, throttle *ResourceThrottle) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			executor.ObserveResources(SampleResourceUsage(), throttle)
+		}
+	}
+}
+
+func workerHealth(executor *AssignmentExecutor) *types.WorkerHealth {
+	usage := executor.ResourceUsage()
+	memBytes := usage.MemBytes
+	if memBytes == 0 {
+		var memStats runtime.MemStats
+		runtime.ReadMemStats(&memStats)
+		memBytes = int64(memStats.Alloc)
+	}
+
+	return &types.WorkerHealth{
+		CPUPercent: usage.CPUPercent,
+		MemBytes:   memBytes,
+		ActiveVUs:  executor.ActiveVUs(),
+		MemPercent: usage.MemPercent,
+		OpenFDs:    usage.OpenFDs,
+		FDLimit:    usage.FDLimit,
+		ShedRate:   executor.ShedRate(),
+	}
+}
+
+func sendHeartbeat(ctx context.Context, cp *controlPlane, workerID string, executor *AssignmentExecutor) (*heartbeatResponse, error) {
+	req := heartbeatRequest{Health: workerHealth(executor), SecretAccesses: executor.DrainSecretAccesses()}
+	body, _ := json.Marshal(req)
+	sent := false
+	defer func() {
+		if !sent {
+			executor.RequeueSecretAccesses(req.SecretAccesses)
+		}
+	}()
+
+	httpReq, err := cp.newRequest(ctx, http.MethodPost, "/workers/"+workerID+"/heartbeat", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := cp.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("heartbeat failed: %s", resp.Status)
+	}
+	sent = true
+
+	var result heartbeatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func pollAssignments(ctx context.Context, cp *controlPlane, workerID string, interval time.Duration, executor *AssignmentExecutor, channel *channelHolder) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if channel.connected() {
+				continue
+			}
+			assignments, err := getAssignments(ctx, cp, workerID)
+			if err != nil {
+				continue
+			}
+			started := executeAssignments(ctx, executor, assignments)
+			if len(started) > 0 {
+				if err := ackAssignments(ctx, cp, workerID, started); err != nil {
+					log.Printf("[Worker] Failed to ack assignments: %v", err)
+				}
+			}
+		}
+	}
+}
+
+// executeAssignments starts assignments and returns those that started.
+func executeAssignments(ctx context.Context, executor *AssignmentExecutor, assignments []types.WorkerAssignment) []types.WorkerAssignment {
+	var started []types.WorkerAssignment
+	for _, a := range assignments {
+		if err := executor.Execute(ctx, a); err != nil {
+			log.Printf("[Worker] Failed to execute assignment %s: %v", a.LeaseID, err)
+			continue
+		}
+		started = append(started, a)
+	}
+	return started
+}
+
+func getAssignments(ctx context.Context, cp *controlPlane, workerID string) ([]types.WorkerAssignment, error) {
+	httpReq, err := cp.newRequest(ctx, http.MethodGet, "/workers/"+workerID+"/assignments", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := cp.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get assignments failed: %s", resp.Status)
+	}
+
+	var result assignmentsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return result.Assignments, nil
+}
+
+func ackAssignments(ctx context.Context, cp *controlPlane, workerID string, assignments []types.WorkerAssignment) error {
+	if len(assignments) == 0 {
+		return nil
+	}
+
+	leaseIDs := make([]string, 0, len(assignments))
+	for _, assignment := range assignments {
+		if assignment.LeaseID == "" {
+			continue
+		}
+		leaseIDs = append(leaseIDs, assignment.LeaseID)
+	}
+	if len(leaseIDs) == 0 {
+		return nil
+	}
+
+	req := ackAssignmentsRequest{LeaseIDs: leaseIDs}
+	body, _ := json.Marshal(req)
+
+	httpReq, err := cp.newRequest(ctx, http.MethodPost, "/workers/"+workerID+"/assignments/ack", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	resp, err := cp.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("ack assignments failed: %s - %s", resp.Status, string(respBody))
+	}
+
+	return nil
+}
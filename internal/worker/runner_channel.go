@@ -0,0 +1,127 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+const (
+	controlChannelMinBackoff = time.Second
+	controlChannelMaxBackoff = 30 * time.Second
+)
+
+// channelHolder holds a runner's control channel while it is connected.
+// Heartbeats and assignment polling fall back to HTTP whenever it is not.
+type channelHolder struct {
+	mu sync.Mutex
+	ch *ControlChannel
+}
+
+func (c *channelHolder) set(ch *ControlChannel) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ch = ch
+}
+
+func (c *channelHolder) get() *ControlChannel {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ch
+}
+
+func (c *channelHolder) connected() bool {
+	return c.get() != nil
+}
+
+// sendHeartbeat sends a heartbeat over the channel and reports whether it
+// was sent.
+func (c *channelHolder) sendHeartbeat(executor *AssignmentExecutor) bool {
+	ch := c.get()
+	if ch == nil {
+		return false
+	}
+	msg := types.ChannelMessage{
+		Type:           types.ChannelMessageHeartbeat,
+		Health:         workerHealth(executor),
+		SecretAccesses: executor.DrainSecretAccesses(),
+	}
+	if err := ch.Send(msg); err != nil {
+		executor.RequeueSecretAccesses(msg.SecretAccesses)
+		log.Printf("[Worker] Heartbeat over control channel failed: %v", err)
+		return false
+	}
+	return true
+}
+
+// runControlChannel keeps the control channel connected until ctx is done,
+// reconnecting with backoff.
+func runControlChannel(ctx context.Context, cp *controlPlane, workerID string, executor *AssignmentExecutor, channel *channelHolder) {
+	backoff := controlChannelMinBackoff
+	for {
+		ch, err := DialControlChannel(ctx, cp.baseURL, workerID, cp.workerToken(), cp.tlsConfig)
+		if err != nil {
+			log.Printf("[Worker] Control channel unavailable, polling instead: %v", err)
+		} else {
+			backoff = controlChannelMinBackoff
+			channel.set(ch)
+			err = serveControlChannel(ctx, ch, cp, executor)
+			channel.set(nil)
+			ch.Close()
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("[Worker] Control channel lost, polling instead: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, controlChannelMaxBackoff)
+	}
+}
+
+// serveControlChannel handles messages from the control plane until the
+// channel fails.
+func serveControlChannel(ctx context.Context, ch *ControlChannel, cp *controlPlane, executor *AssignmentExecutor) error {
+	stop := context.AfterFunc(ctx, func() { ch.Close() })
+	defer stop()
+
+	for {
+		msg, err := ch.Receive()
+		if err != nil {
+			return err
+		}
+
+		switch msg.Type {
+		case types.ChannelMessageAssignments:
+			var leaseIDs []string
+			for _, a := range executeAssignments(ctx, executor, msg.Assignments) {
+				if a.LeaseID != "" {
+					leaseIDs = append(leaseIDs, a.LeaseID)
+				}
+			}
+			if len(leaseIDs) > 0 {
+				if err := ch.Send(types.ChannelMessage{Type: types.ChannelMessageAck, LeaseIDs: leaseIDs}); err != nil {
+					return err
+				}
+			}
+		case types.ChannelMessageStop:
+			for _, runID := range msg.StopRunIDs {
+				executor.StopRun(runID, false)
+			}
+			for _, runID := range msg.ImmediateStopRunIDs {
+				executor.StopRun(runID, true)
+			}
+		case types.ChannelMessagePause:
+			executor.SetPausedRuns(msg.PausedRunIDs)
+		case types.ChannelMessageToken:
+			cp.rotateWorkerToken(msg.WorkerToken)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package worker
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/secrets"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+// SetSecretResolver sets the resolver for the secret references of
+// assignments. Without one only env:// and file:// references resolve.
+func (e *AssignmentExecutor) SetSecretResolver(resolver *secrets.Resolver) {
+	e.secrets = resolver
+}
+
+// resolveSecret resolves ref for a run and records the access the first
+// time the run uses ref on this worker.
+func (e *AssignmentExecutor) resolveSecret(ctx context.Context, runID, ref string) (string, error) {
+	value, err := e.secrets.Resolve(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+
+	key := runID + "\x00" + ref
+	e.accessMu.Lock()
+	if _, ok := e.reportedAccess[key]; !ok {
+		e.reportedAccess[key] = struct{}{}
+		e.secretAccesses = append(e.secretAccesses, types.SecretAccess{
+			RunID: runID,
+			Ref:   ref,
+			AtMs:  time.Now().UnixMilli(),
+		})
+	}
+	e.accessMu.Unlock()
+	return value, nil
+}
+
+// DrainSecretAccesses returns the secret accesses not yet reported to the
+// control plane and forgets them.
+func (e *AssignmentExecutor) DrainSecretAccesses() []types.SecretAccess {
+	e.accessMu.Lock()
+	defer e.accessMu.Unlock()
+	accesses := e.secretAccesses
+	e.secretAccesses = nil
+	return accesses
+}
+
+// RequeueSecretAccesses puts back accesses whose heartbeat failed, so the
+// next one reports them.
+func (e *AssignmentExecutor) RequeueSecretAccesses(accesses []types.SecretAccess) {
+	if len(accesses) == 0 {
+		return
+	}
+	e.accessMu.Lock()
+	defer e.accessMu.Unlock()
+	e.secretAccesses = append(accesses, e.secretAccesses...)
+}
+
+// forgetSecretAccesses lets a run's accesses be recorded again once it has
+// no assignments left on this worker.
+func (e *AssignmentExecutor) forgetSecretAccesses(runID string) {
+	e.accessMu.Lock()
+	defer e.accessMu.Unlock()
+	for key := range e.reportedAccess {
+		if strings.HasPrefix(key, runID+"\x00") {
+			delete(e.reportedAccess, key)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/mockserver"
+	"github.com/bc-dunia/mcpdrill/internal/session"
+	"github.com/bc-dunia/mcpdrill/internal/transport"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+	"github.com/bc-dunia/mcpdrill/internal/vu"
+)
+
+// SelfTestConfig configures a worker self-benchmark against an in-process mock target.
+type SelfTestConfig struct {
+	// TargetRPS is the request rate to attempt. 0 means unlimited.
+	TargetRPS float64
+	// VUs is the number of virtual users driving the mock target.
+	VUs int
+	// Duration is how long to drive load.
+	Duration time.Duration
+	// Operation is the MCP operation to exercise: ping, tools_list or tools_call.
+	Operation string
+}
+
+// DefaultSelfTestConfig returns defaults suitable for sizing a typical worker host.
+func DefaultSelfTestConfig() SelfTestConfig {
+	return SelfTestConfig{
+		TargetRPS: 5000,
+		VUs:       50,
+		Duration:  10 * time.Second,
+		Operation: "ping",
+	}
+}
+
+// SelfTestResult reports what this host can sustain with a near-zero-cost target,
+// i.e. the load generator's own ceiling and per-request overhead.
+type SelfTestResult struct {
+	TargetRPS     float64 `json:"target_rps"`
+	AchievedRPS   float64 `json:"achieved_rps"`
+	VUs           int     `json:"vus"`
+	DurationMs    int64   `json:"duration_ms"`
+	TotalOps      int64   `json:"total_ops"`
+	FailedOps     int64   `json:"failed_ops"`
+	LatencyP50Ms  float64 `json:"latency_p50_ms"`
+	LatencyP95Ms  float64 `json:"latency_p95_ms"`
+	LatencyP99Ms  float64 `json:"latency_p99_ms"`
+	LatencyMaxMs  float64 `json:"latency_max_ms"`
+	NumCPU        int     `json:"num_cpu"`
+	TargetReached bool    `json:"target_reached"`
+}
+
+// selfTestTargetReachedRatio is the fraction of the target rate that counts as reached.
+const selfTestTargetReachedRatio = 0.95
+
+// RunSelfTest starts an in-process mock MCP server on loopback and drives it with the
+// regular VU engine, session manager and transport, so the numbers reflect the same
+// code paths used for real assignments.
+func RunSelfTest(ctx context.Context, cfg SelfTestConfig) (*SelfTestResult, error) {
+	if cfg.VUs <= 0 {
+		return nil, fmt.Errorf("vus must be > 0, got %d", cfg.VUs)
+	}
+	if cfg.Duration <= 0 {
+		return nil, fmt.Errorf("duration must be > 0, got %v", cfg.Duration)
+	}
+	if cfg.TargetRPS < 0 {
+		return nil, fmt.Errorf("rps must be >= 0, got %v", cfg.TargetRPS)
+	}
+
+	opMix, err := selfTestOpMix(cfg.Operation)
+	if err != nil {
+		return nil, err
+	}
+
+	server := mockserver.New(mockserver.DefaultConfig())
+	if err := server.Start(); err != nil {
+		return nil, fmt.Errorf("start mock server: %w", err)
+	}
+	defer func() {
+		stopCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Stop(stopCtx)
+	}()
+
+	assignment := types.WorkerAssignment{
+		RunID:         "selftest",
+		StageID:       "selftest",
+		LeaseID:       "selftest",
+		VUIDStart:     0,
+		VUIDEnd:       cfg.VUs,
+		DurationMs:    cfg.Duration.Milliseconds(),
+		Target:        types.TargetConfig{URL: server.MCPURL(), Transport: "streamable_http"},
+		Workload:      types.WorkloadConfig{OpMix: opMix},
+		SessionPolicy: types.SessionPolicyConfig{Mode: "reuse"},
+	}
+
+	executor := NewAssignmentExecutor("selftest", []string{"127.0.0.0/8", "::1/128"}, nil)
+	transportCfg := executor.buildTransportConfig(assignment)
+	adapter := transport.NewStreamableHTTPAdapter()
+
+	sessionMgr, err := session.NewManager(executor.buildSessionConfig(assignment, transportCfg, adapter))
+	if err != nil {
+		return nil, fmt.Errorf("create session manager: %w", err)
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	sessionMgr.Start(runCtx)
+
+	vuCfg := executor.buildVUConfig(assignment, sessionMgr, adapter, transportCfg)
+	vuCfg.Load.TargetRPS = cfg.TargetRPS
+
+	engine, err := vu.NewEngine(vuCfg)
+	if err != nil {
+		sessionMgr.Close(runCtx)
+		return nil, fmt.Errorf("create VU engine: %w", err)
+	}
+
+	// Latencies are sampled from delivered results; totals come from engine metrics
+	// so that results dropped under backpressure still count toward throughput.
+	latencies := make([]time.Duration, 0, 1024)
+	collected := make(chan struct{})
+	go func() {
+		defer close(collected)
+		for result := range engine.Results() {
+			latencies = append(latencies, result.EndTime.Sub(result.StartTime))
+		}
+	}()
+
+	start := time.Now()
+	if err := engine.Start(runCtx); err != nil {
+		sessionMgr.Close(runCtx)
+		return nil, fmt.Errorf("start VU engine: %w", err)
+	}
+
+	timer := time.NewTimer(cfg.Duration)
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		timer.Stop()
+	}
+	elapsed := time.Since(start)
+	// Snapshot before stopping so operations cancelled by the stop don't count as failures.
+	snapshot := engine.Metrics().Snapshot()
+
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer stopCancel()
+	if err := engine.Stop(stopCtx); err != nil {
+		return nil, fmt.Errorf("stop VU engine: %w", err)
+	}
+	<-collected
+	sessionMgr.Close(stopCtx)
+
+	return buildSelfTestResult(cfg, elapsed, latencies, snapshot.TotalOperations, snapshot.FailedOperations), nil
+}
+
+func buildSelfTestResult(cfg SelfTestConfig, elapsed time.Duration, latencies []time.Duration, totalOps, failedOps int64) *SelfTestResult {
+	result := &SelfTestResult{
+		TargetRPS:  cfg.TargetRPS,
+		VUs:        cfg.VUs,
+		DurationMs: elapsed.Milliseconds(),
+		TotalOps:   totalOps,
+		FailedOps:  failedOps,
+		NumCPU:     runtime.NumCPU(),
+	}
+
+	if elapsed > 0 {
+		result.AchievedRPS = float64(totalOps) / elapsed.Seconds()
+	}
+	if cfg.TargetRPS > 0 {
+		result.TargetReached = result.AchievedRPS >= cfg.TargetRPS*selfTestTargetReachedRatio
+	}
+
+	if len(latencies) > 0 {
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		result.LatencyP50Ms = durationMs(durationPercentile(latencies, 50))
+		result.LatencyP95Ms = durationMs(durationPercentile(latencies, 95))
+		result.LatencyP99Ms = durationMs(durationPercentile(latencies, 99))
+		result.LatencyMaxMs = durationMs(latencies[len(latencies)-1])
+	}
+
+	return result
+}
+
+// durationPercentile returns the p-th percentile of sorted durations (nearest rank).
+func durationPercentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := (len(sorted)*p + 99) / 100
+	if idx < 1 {
+		idx = 1
+	}
+	if idx > len(sorted) {
+		idx = len(sorted)
+	}
+	return sorted[idx-1]
+}
+
+// durationMs converts a duration to fractional milliseconds.
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// selfTestOpMix maps a self-test operation name to a single-entry op mix.
+func selfTestOpMix(operation string) ([]types.OpMixEntry, error) {
+	switch operation {
+	case "", "ping":
+		return []types.OpMixEntry{{Operation: string(vu.OpPing), Weight: 1}}, nil
+	case "tools_list":
+		return []types.OpMixEntry{{Operation: string(vu.OpToolsList), Weight: 1}}, nil
+	case "tools_call":
+		return []types.OpMixEntry{{
+			Operation: string(vu.OpToolsCall),
+			Weight:    1,
+			ToolName:  "fast_echo",
+			Arguments: map[string]interface{}{"message": "selftest"},
+		}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported self-test operation %q (use ping, tools_list or tools_call)", operation)
+	}
+}
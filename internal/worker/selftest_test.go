@@ -0,0 +1,76 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRunSelfTest(t *testing.T) {
+	result, err := RunSelfTest(context.Background(), SelfTestConfig{
+		TargetRPS: 200,
+		VUs:       4,
+		Duration:  500 * time.Millisecond,
+		Operation: "ping",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.TotalOps == 0 {
+		t.Fatal("expected operations to be executed")
+	}
+	if result.FailedOps != 0 {
+		t.Errorf("expected no failures against in-process mock, got %d", result.FailedOps)
+	}
+	if result.LatencyP50Ms <= 0 || result.LatencyP99Ms < result.LatencyP50Ms {
+		t.Errorf("unexpected latency percentiles: p50=%v p99=%v", result.LatencyP50Ms, result.LatencyP99Ms)
+	}
+	if result.NumCPU <= 0 {
+		t.Error("expected NumCPU to be set")
+	}
+}
+
+func TestRunSelfTest_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  SelfTestConfig
+	}{
+		{"zero vus", SelfTestConfig{VUs: 0, Duration: time.Second}},
+		{"zero duration", SelfTestConfig{VUs: 1, Duration: 0}},
+		{"negative rps", SelfTestConfig{VUs: 1, Duration: time.Second, TargetRPS: -1}},
+		{"unknown operation", SelfTestConfig{VUs: 1, Duration: time.Second, Operation: "resources_read"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := RunSelfTest(context.Background(), tt.cfg); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestBuildSelfTestResult(t *testing.T) {
+	latencies := make([]time.Duration, 100)
+	for i := range latencies {
+		latencies[i] = time.Duration(100-i) * time.Millisecond
+	}
+
+	result := buildSelfTestResult(SelfTestConfig{TargetRPS: 100, VUs: 2}, 2*time.Second, latencies, 200, 1)
+
+	if result.AchievedRPS != 100 {
+		t.Errorf("expected 100 rps, got %v", result.AchievedRPS)
+	}
+	if !result.TargetReached {
+		t.Error("expected target to be reached")
+	}
+	if result.LatencyP50Ms != 50 || result.LatencyP99Ms != 99 || result.LatencyMaxMs != 100 {
+		t.Errorf("unexpected percentiles: p50=%v p99=%v max=%v", result.LatencyP50Ms, result.LatencyP99Ms, result.LatencyMaxMs)
+	}
+
+	result = buildSelfTestResult(SelfTestConfig{TargetRPS: 1000, VUs: 2}, 2*time.Second, latencies, 200, 0)
+	if result.TargetReached {
+		t.Error("expected target not to be reached")
+	}
+}
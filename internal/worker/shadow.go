@@ -0,0 +1,71 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/bc-dunia/mcpdrill/internal/session"
+	"github.com/bc-dunia/mcpdrill/internal/transport"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+	"github.com/bc-dunia/mcpdrill/internal/vu"
+)
+
+// startShadow starts the sessions of an assignment on its run's shadow
+// target, with the session policy of the target's, and returns the VU
+// settings that send operations there as well. The caller closes the
+// session manager once the VUs have stopped.
+func (e *AssignmentExecutor) startShadow(ctx context.Context, a types.WorkerAssignment) (*vu.ShadowConfig, *session.Manager, error) {
+	shadow := a
+	shadow.Target = a.Shadow.Target
+
+	tokenSource, err := e.buildTokenSource(ctx, a.RunID, shadow.Target.Auth)
+	if err != nil {
+		return nil, nil, fmt.Errorf("shadow: %w", err)
+	}
+	transportCfg := e.buildTransportConfig(shadow)
+	transportCfg.TokenSource = tokenSource
+	adapter := transport.NewStreamableHTTPAdapter()
+
+	mgr, err := session.NewManager(e.buildSessionConfig(shadow, transportCfg, adapter))
+	if err != nil {
+		return nil, nil, fmt.Errorf("create shadow session manager: %w", err)
+	}
+	mgr.Start(ctx)
+
+	stable := make(map[string]bool, len(a.Shadow.StableTools))
+	for _, tool := range a.Shadow.StableTools {
+		stable[tool] = true
+	}
+	return &vu.ShadowConfig{SessionManager: mgr, StableTools: stable}, mgr, nil
+}
+
+// shadowOutcome converts the shadow's answer to an operation for telemetry.
+func shadowOutcome(result *vu.ShadowResult) *types.ShadowOutcome {
+	if result == nil || result.Outcome == nil {
+		return nil
+	}
+	outcome := &types.ShadowOutcome{
+		LatencyMs:   int(result.Outcome.LatencyMs),
+		OK:          result.Outcome.OK,
+		ResultMatch: result.ResultMatch,
+	}
+	if outcome.LatencyMs == 0 {
+		outcome.LatencyMs = 1
+	}
+	if result.Outcome.Error != nil {
+		outcome.ErrorType = string(result.Outcome.Error.Type)
+	}
+	return outcome
+}
+
+// closeShadow closes the shadow's session manager, if the assignment has
+// one.
+func closeShadow(ctx context.Context, mgr *session.Manager) {
+	if mgr == nil {
+		return
+	}
+	if err := mgr.Close(ctx); err != nil {
+		log.Printf("[Worker] Shadow session manager close error: %v", err)
+	}
+}
@@ -0,0 +1,201 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/mcp"
+	"github.com/bc-dunia/mcpdrill/internal/transport"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+const (
+	// probeTimeout bounds a probe of the target, for its preflight profile
+	// or the tools to call.
+	probeTimeout = 30 * time.Second
+	// maxListPages stops a list whose server keeps returning cursors.
+	maxListPages = 100
+)
+
+// targetProfileRequest is the request body for POST
+// /workers/{id}/target-profile.
+type targetProfileRequest struct {
+	RunID   string               `json:"run_id"`
+	Profile *types.TargetProfile `json:"profile"`
+}
+
+// openProbe initializes a session of its own with the target, apart from
+// the VUs' sessions, and returns its connection and initialize result.
+func openProbe(ctx context.Context, adapter transport.Adapter, cfg *transport.TransportConfig) (transport.Connection, *types.InitializeResult, error) {
+	// Results are read in full, and the probe's request IDs are plain.
+	probeCfg := *cfg
+	probeCfg.ResultCapture = nil
+	probeCfg.RequestIDs = nil
+
+	conn, err := adapter.Connect(ctx, &probeCfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("connect: %w", err)
+	}
+
+	outcome, err := conn.Initialize(ctx, &transport.InitializeParams{
+		ProtocolVersion: mcp.DefaultProtocolVersion,
+		Capabilities:    make(map[string]interface{}),
+		ClientInfo: transport.ClientInfo{
+			Name:    mcp.ClientName,
+			Version: mcp.ClientVersion,
+		},
+	})
+	if err := outcomeError(outcome, err); err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("initialize: %w", err)
+	}
+	var init types.InitializeResult
+	if err := json.Unmarshal(outcome.Result, &init); err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("initialize: parse result: %w", err)
+	}
+	if _, err := conn.SendInitialized(ctx); err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("initialized notification: %w", err)
+	}
+	return conn, &init, nil
+}
+
+// profileTarget lists the target's tools, resources and prompts on a probe
+// session. Only a failed initialize is an error; a list that fails is
+// recorded in the profile's ListErrors.
+func profileTarget(ctx context.Context, adapter transport.Adapter, cfg *transport.TransportConfig) (*types.TargetProfile, error) {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	conn, init, err := openProbe(ctx, adapter, cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	profile := &types.TargetProfile{
+		ProfiledAtMs:    time.Now().UnixMilli(),
+		ProtocolVersion: init.ProtocolVersion,
+		Capabilities:    init.Capabilities,
+		ServerInfo:      init.ServerInfo,
+		Instructions:    init.Instructions,
+		Tools:           []types.Tool{},
+		Resources:       []types.Resource{},
+		Prompts:         []types.Prompt{},
+	}
+	listErr := func(method string, err error) {
+		if profile.ListErrors == nil {
+			profile.ListErrors = make(map[string]string)
+		}
+		profile.ListErrors[method] = err.Error()
+	}
+
+	if err := listAll(ctx, conn.ToolsList, func(result json.RawMessage) (*string, error) {
+		var page types.ToolsListResult
+		err := json.Unmarshal(result, &page)
+		profile.Tools = append(profile.Tools, page.Tools...)
+		return page.NextCursor, err
+	}); err != nil {
+		listErr("tools/list", err)
+	}
+	if err := listAll(ctx, conn.ResourcesList, func(result json.RawMessage) (*string, error) {
+		var page types.ResourcesListResult
+		err := json.Unmarshal(result, &page)
+		profile.Resources = append(profile.Resources, page.Resources...)
+		return page.NextCursor, err
+	}); err != nil {
+		listErr("resources/list", err)
+	}
+	if err := listAll(ctx, conn.PromptsList, func(result json.RawMessage) (*string, error) {
+		var page types.PromptsListResult
+		err := json.Unmarshal(result, &page)
+		profile.Prompts = append(profile.Prompts, page.Prompts...)
+		return page.NextCursor, err
+	}); err != nil {
+		listErr("prompts/list", err)
+	}
+
+	return profile, nil
+}
+
+// listAll calls list until the server returns no cursor, handing each
+// page's result to add, which returns the next cursor.
+func listAll(ctx context.Context, list func(context.Context, *string) (*transport.OperationOutcome, error), add func(json.RawMessage) (*string, error)) error {
+	var cursor *string
+	for page := 0; page < maxListPages; page++ {
+		outcome, err := list(ctx, cursor)
+		if err := outcomeError(outcome, err); err != nil {
+			return err
+		}
+		next, err := add(outcome.Result)
+		if err != nil {
+			return fmt.Errorf("parse result: %w", err)
+		}
+		if next == nil || *next == "" {
+			return nil
+		}
+		cursor = next
+	}
+	return fmt.Errorf("more than %d pages", maxListPages)
+}
+
+// outcomeError returns err, or the error of a failed outcome.
+func outcomeError(outcome *transport.OperationOutcome, err error) error {
+	if err != nil {
+		return err
+	}
+	if !outcome.OK {
+		if outcome.Error != nil {
+			return errors.New(outcome.Error.Message)
+		}
+		return errors.New("operation failed")
+	}
+	return nil
+}
+
+// profileTargetOnce probes the target of a preflight assignment and ships
+// the profile to the control plane, at most once per run on this worker.
+// Failures are logged; the preflight load itself surfaces an unreachable
+// target.
+func (e *AssignmentExecutor) profileTargetOnce(ctx context.Context, a types.WorkerAssignment, adapter transport.Adapter, cfg *transport.TransportConfig) {
+	if a.Stage != "preflight" || e.telemetryShipper == nil {
+		return
+	}
+	e.mu.Lock()
+	if _, done := e.profiledRuns[a.RunID]; done {
+		e.mu.Unlock()
+		return
+	}
+	e.profiledRuns[a.RunID] = struct{}{}
+	e.mu.Unlock()
+
+	profile, err := profileTarget(ctx, adapter, cfg)
+	if err != nil {
+		log.Printf("[Worker] Failed to profile target of run %s: %v", a.RunID, err)
+		return
+	}
+	profile.WorkerID = e.workerID
+	e.telemetryShipper.ShipTargetProfile(a.RunID, profile)
+}
+
+// ShipTargetProfile sends the profile of a run's target to the control
+// plane. It is sent right away rather than batched.
+func (s *TelemetryShipper) ShipTargetProfile(runID string, profile *types.TargetProfile) {
+	path := "/workers/" + s.workerID + "/target-profile"
+	resp, err := s.client.Post(path, targetProfileRequest{RunID: runID, Profile: profile})
+	if err != nil {
+		log.Printf("[TelemetryShipper] Failed to ship target profile: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ReadResponseBody(resp)
+		log.Printf("[TelemetryShipper] Target profile ship failed: status=%d body=%s", resp.StatusCode, string(body))
+	}
+}
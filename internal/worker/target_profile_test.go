@@ -0,0 +1,82 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/mockserver"
+	"github.com/bc-dunia/mcpdrill/internal/transport"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+func TestAssignmentExecutor_ProfilesTargetOncePerRun(t *testing.T) {
+	target := mockserver.New(mockserver.DefaultConfig())
+	if err := target.Start(); err != nil {
+		t.Fatalf("start mock server: %v", err)
+	}
+	defer target.Stop(context.Background())
+
+	var (
+		mu       sync.Mutex
+		received []targetProfileRequest
+	)
+	controlPlane := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/workers/worker-1/target-profile" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		var req targetProfileRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		mu.Lock()
+		received = append(received, req)
+		mu.Unlock()
+		_ = json.NewEncoder(w).Encode(map[string]bool{"recorded": true})
+	}))
+	defer controlPlane.Close()
+
+	client := NewRetryHTTPClient(context.Background(), controlPlane.URL, controlPlane.Client(), RetryConfig{
+		MaxRetries: 0,
+		Backoff:    10 * time.Millisecond,
+		MaxBackoff: 10 * time.Millisecond,
+	})
+	shipper := NewTelemetryShipper(context.Background(), "worker-1", client)
+	defer shipper.Close()
+	executor := NewAssignmentExecutor("worker-1", []string{"127.0.0.0/8", "::1/128"}, shipper)
+
+	a := types.WorkerAssignment{
+		RunID:  "run-1",
+		Stage:  "preflight",
+		Target: types.TargetConfig{URL: target.MCPURL(), Transport: "streamable_http"},
+	}
+	adapter := transport.NewStreamableHTTPAdapter()
+	cfg := executor.buildTransportConfig(a)
+
+	executor.profileTargetOnce(context.Background(), a, adapter, cfg)
+	executor.profileTargetOnce(context.Background(), a, adapter, cfg)
+	baseline := a
+	baseline.RunID = "run-2"
+	baseline.Stage = "baseline"
+	executor.profileTargetOnce(context.Background(), baseline, adapter, cfg)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 1 {
+		t.Fatalf("expected one profile for the preflight of run-1, got %d", len(received))
+	}
+	profile := received[0].Profile
+	if received[0].RunID != "run-1" || profile == nil {
+		t.Fatalf("unexpected request %+v", received[0])
+	}
+	if profile.WorkerID != "worker-1" || profile.ProtocolVersion == "" || profile.ServerInfo.Name == "" {
+		t.Errorf("expected the initialize result in the profile, got %+v", profile)
+	}
+	if len(profile.Tools) == 0 || len(profile.Tools[0].InputSchema) == 0 {
+		t.Errorf("expected the tools with their schemas, got %+v", profile.Tools)
+	}
+}
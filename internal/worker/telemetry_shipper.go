@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"log"
+	"math/rand/v2"
 	"net/http"
 	"sync"
 	"sync/atomic"
@@ -18,9 +19,25 @@ const (
 	defaultBufferSize    = 10000
 )
 
+// TelemetryShipperConfig selects how a TelemetryShipper ships operations.
+type TelemetryShipperConfig struct {
+	// Summarize ships per-second summaries of operations instead of every
+	// operation: counts, errors and a latency histogram for each operation,
+	// tool, stage and generator group.
+	Summarize bool
+	// SampleRate is the fraction of successful operations, from 0 to 1,
+	// shipped in full alongside summaries. Failed operations always are.
+	SampleRate float64
+}
+
+// maxSummarizedOps is the most operations the control plane accepts in the
+// summaries of one telemetry batch.
+const maxSummarizedOps = 1000000
+
 type TelemetryShipper struct {
 	workerID string
 	client   *RetryHTTPClient
+	config   TelemetryShipperConfig
 
 	buffer      chan telemetryItem
 	batchSize   int
@@ -46,14 +63,24 @@ type telemetryItem struct {
 type telemetryBatchRequest struct {
 	RunID      string                   `json:"run_id"`
 	Operations []types.OperationOutcome `json:"operations"`
+	Summaries  []types.OperationSummary `json:"summaries,omitempty"`
+	Samples    []types.OperationOutcome `json:"samples,omitempty"`
+
+	GeneratorWarnings []types.LoadGeneratorWarning `json:"generator_warnings,omitempty"`
 }
 
+// NewTelemetryShipper returns a shipper that ships every operation.
 func NewTelemetryShipper(ctx context.Context, workerID string, client *RetryHTTPClient) *TelemetryShipper {
+	return NewTelemetryShipperWithConfig(ctx, workerID, client, TelemetryShipperConfig{})
+}
+
+func NewTelemetryShipperWithConfig(ctx context.Context, workerID string, client *RetryHTTPClient, config TelemetryShipperConfig) *TelemetryShipper {
 	shipperCtx, cancel := context.WithCancel(ctx)
 
 	s := &TelemetryShipper{
 		workerID:    workerID,
 		client:      client,
+		config:      config,
 		buffer:      make(chan telemetryItem, defaultBufferSize),
 		batchSize:   defaultBatchSize,
 		flushTicker: time.NewTicker(defaultFlushInterval),
@@ -96,6 +123,32 @@ func (s *TelemetryShipper) run() {
 	defer s.wg.Done()
 
 	batches := make(map[string][]types.OperationOutcome)
+	summaries := make(map[string]*telemetrySummary)
+
+	add := func(item telemetryItem) {
+		if s.config.Summarize {
+			summary := summaries[item.runID]
+			if summary == nil {
+				summary = newTelemetrySummary()
+				summaries[item.runID] = summary
+			}
+			summary.add(item.outcome, s.sample(item.outcome))
+			// Summaries of the same second merge on the control plane, so
+			// a run can be shipped early when its samples pile up or it
+			// reaches the operations one batch may summarize.
+			if len(summary.samples) >= s.batchSize || summary.count >= maxSummarizedOps {
+				s.shipSummary(item.runID, summary)
+				delete(summaries, item.runID)
+			}
+			return
+		}
+
+		batches[item.runID] = append(batches[item.runID], item.outcome)
+		if len(batches[item.runID]) >= s.batchSize {
+			s.shipBatch(item.runID, batches[item.runID])
+			delete(batches, item.runID)
+		}
+	}
 
 	flush := func() {
 		for runID, ops := range batches {
@@ -104,6 +157,10 @@ func (s *TelemetryShipper) run() {
 			}
 		}
 		batches = make(map[string][]types.OperationOutcome)
+		for runID, summary := range summaries {
+			s.shipSummary(runID, summary)
+		}
+		summaries = make(map[string]*telemetrySummary)
 	}
 
 	drainBuffer := func() {
@@ -114,12 +171,7 @@ func (s *TelemetryShipper) run() {
 					flush()
 					return
 				}
-
-				batches[item.runID] = append(batches[item.runID], item.outcome)
-				if len(batches[item.runID]) >= s.batchSize {
-					s.shipBatch(item.runID, batches[item.runID])
-					delete(batches, item.runID)
-				}
+				add(item)
 			default:
 				flush()
 				return
@@ -134,13 +186,7 @@ func (s *TelemetryShipper) run() {
 				flush()
 				return
 			}
-
-			batches[item.runID] = append(batches[item.runID], item.outcome)
-
-			if len(batches[item.runID]) >= s.batchSize {
-				s.shipBatch(item.runID, batches[item.runID])
-				delete(batches, item.runID)
-			}
+			add(item)
 
 		case <-s.flushTicker.C:
 			flush()
@@ -152,16 +198,45 @@ func (s *TelemetryShipper) run() {
 	}
 }
 
+// sample reports whether op is shipped in full alongside summaries.
+func (s *TelemetryShipper) sample(op types.OperationOutcome) bool {
+	return !op.OK || rand.Float64() < s.config.SampleRate
+}
+
 func (s *TelemetryShipper) shipBatch(runID string, ops []types.OperationOutcome) {
 	if len(ops) == 0 {
 		return
 	}
 
-	req := telemetryBatchRequest{
+	s.post(telemetryBatchRequest{
 		RunID:      runID,
 		Operations: ops,
+	}, len(ops))
+}
+
+func (s *TelemetryShipper) shipSummary(runID string, summary *telemetrySummary) {
+	if summary.count == 0 {
+		return
 	}
 
+	s.post(telemetryBatchRequest{
+		RunID:     runID,
+		Summaries: summary.summaries(),
+		Samples:   summary.samples,
+	}, summary.count)
+}
+
+// ShipGeneratorWarning sends a load generator warning to a run's telemetry
+// at once. Warnings are rare, so they are not batched.
+func (s *TelemetryShipper) ShipGeneratorWarning(runID string, warning types.LoadGeneratorWarning) {
+	if s.closed.Load() {
+		return
+	}
+	s.post(telemetryBatchRequest{RunID: runID, GeneratorWarnings: []types.LoadGeneratorWarning{warning}}, 0)
+}
+
+// post sends a telemetry batch covering count operations.
+func (s *TelemetryShipper) post(req telemetryBatchRequest, count int) {
 	path := "/workers/" + s.workerID + "/telemetry"
 	resp, err := s.client.Post(path, req)
 	if err != nil {
@@ -176,14 +251,14 @@ func (s *TelemetryShipper) shipBatch(runID string, ops []types.OperationOutcome)
 	}
 	defer resp.Body.Close()
 
-	s.shippedCount.Add(int64(len(ops)))
+	s.shippedCount.Add(int64(count))
 
 	var result struct {
 		Accepted int `json:"accepted"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err == nil {
-		if result.Accepted != len(ops) {
-			log.Printf("[TelemetryShipper] Partial accept: sent=%d accepted=%d", len(ops), result.Accepted)
+		if result.Accepted != count {
+			log.Printf("[TelemetryShipper] Partial accept: sent=%d accepted=%d", count, result.Accepted)
 		}
 	}
 }
@@ -76,3 +76,97 @@ func TestTelemetryShipperShipAfterCloseDrops(t *testing.T) {
 		t.Fatal("expected dropped > 0 after ship on closed shipper")
 	}
 }
+
+func TestTelemetryShipperSummarizesOperations(t *testing.T) {
+	var received atomic.Pointer[telemetryBatchRequest]
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req telemetryBatchRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		received.Store(&req)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]int{"accepted": 4})
+	}))
+	defer server.Close()
+
+	retryClient := NewRetryHTTPClient(context.Background(), server.URL, server.Client(), RetryConfig{
+		MaxRetries: 0,
+		Backoff:    10 * time.Millisecond,
+		MaxBackoff: 10 * time.Millisecond,
+	})
+	shipper := NewTelemetryShipperWithConfig(context.Background(), "worker-1", retryClient, TelemetryShipperConfig{
+		Summarize:  true,
+		SampleRate: 0,
+	})
+
+	for i, latency := range []int{10, 10, 30} {
+		shipper.Ship("run-1", types.OperationOutcome{Operation: "tools_call", ToolName: "echo", LatencyMs: latency, OK: true, TimestampMs: int64(5000 + i)})
+	}
+	shipper.Ship("run-1", types.OperationOutcome{Operation: "tools_call", ToolName: "echo", LatencyMs: 900, ErrorType: "timeout", TimestampMs: 5999})
+	shipper.Close()
+
+	req := received.Load()
+	if req == nil {
+		t.Fatal("expected a telemetry batch")
+	}
+	if len(req.Operations) != 0 {
+		t.Errorf("expected no raw operations, got %d", len(req.Operations))
+	}
+	if len(req.Samples) != 1 || req.Samples[0].ErrorType != "timeout" {
+		t.Errorf("expected only the failed operation sampled, got %+v", req.Samples)
+	}
+	if len(req.Summaries) != 1 {
+		t.Fatalf("expected 1 summary, got %d", len(req.Summaries))
+	}
+	summary := req.Summaries[0]
+	if summary.BucketMs != 5000 || summary.Count != 4 || summary.Errors != 1 || summary.ErrorTypes["timeout"] != 1 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+	want := [][2]int{{10, 2}, {30, 1}, {900, 1}}
+	if len(summary.Latency) != len(want) {
+		t.Fatalf("expected latency buckets %v, got %v", want, summary.Latency)
+	}
+	for i := range want {
+		if summary.Latency[i] != want[i] {
+			t.Errorf("expected latency buckets %v, got %v", want, summary.Latency)
+			break
+		}
+	}
+
+	if shipped, _ := shipper.Stats(); shipped != 4 {
+		t.Errorf("expected shipped=4, got %d", shipped)
+	}
+}
+
+func TestTelemetrySummaryCountsWorkflowRuns(t *testing.T) {
+	summary := newTelemetrySummary()
+	step := func(status string, ok bool) types.OperationOutcome {
+		return types.OperationOutcome{
+			Operation:   "tools_call",
+			ToolName:    "echo",
+			OK:          ok,
+			TimestampMs: 5000,
+			Workflow:    &types.WorkflowStepInfo{Name: "discover", Step: "call", Index: 1, Status: status},
+		}
+	}
+	summary.add(step(types.WorkflowCompleted, true), false)
+	summary.add(step(types.WorkflowCompleted, true), false)
+	summary.add(step(types.WorkflowExtractFailed, true), false)
+	summary.add(step(types.WorkflowFailed, false), false)
+	summary.add(step("", true), false)
+	summary.add(types.OperationOutcome{Operation: "tools_call", ToolName: "echo", OK: true, TimestampMs: 5000}, false)
+
+	summaries := summary.summaries()
+	if len(summaries) != 2 {
+		t.Fatalf("expected the workflow step summarized apart, got %d summaries", len(summaries))
+	}
+	if summaries[0].Workflow != nil || summaries[0].Count != 1 {
+		t.Errorf("expected the operation outside the workflow first, got %+v", summaries[0])
+	}
+	want := types.WorkflowStepSummary{Name: "discover", Step: "call", Index: 1, Completed: 2, Failed: 1, ExtractFailed: 1}
+	if wf := summaries[1].Workflow; wf == nil || *wf != want || summaries[1].Count != 5 {
+		t.Errorf("expected workflow step %+v over 5 operations, got %+v", want, summaries[1])
+	}
+}
@@ -0,0 +1,179 @@
+package worker
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+)
+
+// summaryKey identifies the operations condensed into one summary.
+type summaryKey struct {
+	bucketMs    int64
+	operation   string
+	toolName    string
+	workerID    string
+	executionID string
+	stage       string
+	stageID     string
+	group       string
+	target      string
+	mutation    string
+	attempt     int
+	warmup      bool
+	workflow    string
+	step        string
+	stepIndex   int
+}
+
+type summaryBucket struct {
+	count      int
+	errors     int
+	errorTypes map[string]int
+	latency    analysis.Histogram
+
+	// workflowStatuses counts the workflow runs that ended with the
+	// bucket's operations, by status.
+	workflowStatuses map[string]int
+}
+
+// telemetrySummary collects one run's operations into per-second summaries
+// until they are shipped, along with the operations sampled in full.
+type telemetrySummary struct {
+	buckets map[summaryKey]*summaryBucket
+	samples []types.OperationOutcome
+	count   int
+}
+
+func newTelemetrySummary() *telemetrySummary {
+	return &telemetrySummary{buckets: make(map[summaryKey]*summaryBucket)}
+}
+
+func (t *telemetrySummary) add(op types.OperationOutcome, sampled bool) {
+	key := summaryKey{
+		bucketMs:    op.TimestampMs - op.TimestampMs%1000,
+		operation:   op.Operation,
+		toolName:    op.ToolName,
+		workerID:    op.WorkerID,
+		executionID: op.ExecutionID,
+		stage:       op.Stage,
+		stageID:     op.StageID,
+		group:       op.GeneratorGroup,
+		target:      op.Target,
+		mutation:    op.FuzzMutation,
+		attempt:     op.Attempt,
+		warmup:      op.Warmup,
+	}
+	if op.Workflow != nil {
+		key.workflow = op.Workflow.Name
+		key.step = op.Workflow.Step
+		key.stepIndex = op.Workflow.Index
+	}
+	bucket := t.buckets[key]
+	if bucket == nil {
+		bucket = &summaryBucket{}
+		t.buckets[key] = bucket
+	}
+
+	bucket.count++
+	bucket.latency.Record(int64(op.LatencyMs))
+	if !op.OK {
+		bucket.errors++
+		if op.ErrorType != "" {
+			if bucket.errorTypes == nil {
+				bucket.errorTypes = make(map[string]int)
+			}
+			bucket.errorTypes[op.ErrorType]++
+		}
+	}
+	if op.Workflow != nil && op.Workflow.Status != "" {
+		if bucket.workflowStatuses == nil {
+			bucket.workflowStatuses = make(map[string]int)
+		}
+		bucket.workflowStatuses[op.Workflow.Status]++
+	}
+	if sampled {
+		t.samples = append(t.samples, op)
+	}
+	t.count++
+}
+
+// summaries returns the collected summaries, oldest first.
+func (t *telemetrySummary) summaries() []types.OperationSummary {
+	out := make([]types.OperationSummary, 0, len(t.buckets))
+	for key, bucket := range t.buckets {
+		summary := types.OperationSummary{
+			BucketMs:       key.bucketMs,
+			Operation:      key.operation,
+			ToolName:       key.toolName,
+			WorkerID:       key.workerID,
+			ExecutionID:    key.executionID,
+			Stage:          key.stage,
+			StageID:        key.stageID,
+			GeneratorGroup: key.group,
+			Target:         key.target,
+			FuzzMutation:   key.mutation,
+			Attempt:        key.attempt,
+			Warmup:         key.warmup,
+			Count:          bucket.count,
+			Errors:         bucket.errors,
+			ErrorTypes:     bucket.errorTypes,
+		}
+		bucket.latency.Buckets(func(value, count int64) {
+			summary.Latency = append(summary.Latency, [2]int{int(value), int(count)})
+		})
+		if key.workflow != "" {
+			summary.Workflow = &types.WorkflowStepSummary{
+				Name:          key.workflow,
+				Step:          key.step,
+				Index:         key.stepIndex,
+				Completed:     bucket.workflowStatuses[types.WorkflowCompleted],
+				Failed:        bucket.workflowStatuses[types.WorkflowFailed],
+				ExtractFailed: bucket.workflowStatuses[types.WorkflowExtractFailed],
+			}
+		}
+		out = append(out, summary)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.BucketMs != b.BucketMs {
+			return a.BucketMs < b.BucketMs
+		}
+		if a.Stage != b.Stage {
+			return a.Stage < b.Stage
+		}
+		if a.Operation != b.Operation {
+			return a.Operation < b.Operation
+		}
+		if a.ToolName != b.ToolName {
+			return a.ToolName < b.ToolName
+		}
+		if a.GeneratorGroup != b.GeneratorGroup {
+			return a.GeneratorGroup < b.GeneratorGroup
+		}
+		if a.Target != b.Target {
+			return a.Target < b.Target
+		}
+		if a.FuzzMutation != b.FuzzMutation {
+			return a.FuzzMutation < b.FuzzMutation
+		}
+		if a.Attempt != b.Attempt {
+			return a.Attempt < b.Attempt
+		}
+		if aw, bw := workflowStepKey(a), workflowStepKey(b); aw != bw {
+			return aw < bw
+		}
+		return !a.Warmup && b.Warmup
+	})
+	return out
+}
+
+// workflowStepKey orders summaries by workflow and step index, those
+// outside a workflow first.
+func workflowStepKey(summary types.OperationSummary) string {
+	if summary.Workflow == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s\x00%06d", summary.Workflow.Name, summary.Workflow.Index)
+}
@@ -0,0 +1,230 @@
+// Package drill runs mcpdrill load tests in-process, so Go test suites can
+// load test an MCP server, such as an httptest server, and assert on the
+// analysis of the run:
+//
+//	srv := httptest.NewServer(mcpHandler)
+//	defer srv.Close()
+//	result, err := drill.Run(ctx, drill.Scenario{
+//		TargetURL: srv.URL + "/mcp",
+//		VUs:       5,
+//		Tools:     []drill.Tool{{Name: "search", Arguments: map[string]any{"query": "mcp"}}},
+//	})
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	if m := result.Metrics(); m.ErrorRate > 0.01 || m.LatencyP99 > 200 {
+//		t.Errorf("error rate %.2f, p99 %dms", m.ErrorRate, m.LatencyP99)
+//	}
+//
+// Each run starts a control plane and a worker on loopback, the same code
+// the mcpdrill binaries run, and tears them down when it ends.
+package drill
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/analysis"
+	"github.com/bc-dunia/mcpdrill/internal/artifacts"
+	"github.com/bc-dunia/mcpdrill/internal/auth"
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/api"
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/runmanager"
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/scheduler"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+	"github.com/bc-dunia/mcpdrill/internal/validation"
+	"github.com/bc-dunia/mcpdrill/internal/worker"
+)
+
+// Report is the analysis of a run, as in the run's report.json.
+type Report = analysis.Report
+
+// Metrics are the aggregated metrics of a run.
+type Metrics = analysis.AggregatedMetrics
+
+// OperationMetrics are the metrics of one operation or tool of a run.
+type OperationMetrics = analysis.OperationMetrics
+
+// privateNetworks are the networks runs may target: tests usually load test
+// a server on loopback or in a private network.
+var privateNetworks = []string{
+	"127.0.0.0/8", "::1/128", "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "fc00::/7", "fe80::/10", "169.254.0.0/16",
+}
+
+// actor is the actor runs are created and started by.
+const actor = "drill"
+
+// pollInterval is how often a run's state is checked while it runs.
+const pollInterval = 50 * time.Millisecond
+
+// Result is the outcome of a run.
+type Result struct {
+	RunID string
+	// State is completed, failed or aborted.
+	State string
+	// StopReason says why the run stopped early, if it did.
+	StopReason string
+	// Report is the analysis of the run. It is nil if the run ended before
+	// it could be analyzed.
+	Report *Report
+}
+
+// Metrics returns the aggregated metrics of the run, empty if it was not
+// analyzed.
+func (r *Result) Metrics() *Metrics {
+	if r.Report == nil || r.Report.Metrics == nil {
+		return &Metrics{}
+	}
+	return r.Report.Metrics
+}
+
+// Run runs a scenario and returns its result once the run has ended and
+// been analyzed, or ctx is done.
+func Run(ctx context.Context, scenario Scenario) (*Result, error) {
+	config, err := scenario.Config()
+	if err != nil {
+		return nil, err
+	}
+	return RunConfig(ctx, config)
+}
+
+// RunConfig runs a run config, JSON or YAML as accepted by the control
+// plane, and returns its result once the run has ended and been analyzed.
+// If ctx is done first the run is aborted and ctx's error returned.
+func RunConfig(ctx context.Context, config []byte) (*Result, error) {
+	cp, err := startControlPlane(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cp.close()
+
+	runID, err := cp.rm.CreateRun(config, actor)
+	if err != nil {
+		return nil, fmt.Errorf("creating run: %w", err)
+	}
+	if err := cp.rm.StartRun(runID, actor); err != nil {
+		return nil, fmt.Errorf("starting run: %w", err)
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		run, err := cp.rm.GetRun(runID)
+		if err != nil {
+			return nil, err
+		}
+		switch run.State {
+		case runmanager.RunStateCompleted, runmanager.RunStateFailed, runmanager.RunStateAborted:
+			return cp.result(run)
+		}
+		select {
+		case <-ctx.Done():
+			if err := cp.rm.AbortRun(runID, actor, "context done"); err != nil && !runmanager.IsTerminalState(err) {
+				return nil, errors.Join(ctx.Err(), err)
+			}
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// controlPlane is a control plane with a single worker, listening on
+// loopback for the length of one run. Reports go to a temporary directory
+// removed with it.
+type controlPlane struct {
+	rm        *runmanager.RunManager
+	server    *api.Server
+	worker    *worker.Runner
+	artifacts *artifacts.FilesystemStore
+	dir       string
+}
+
+func startControlPlane(ctx context.Context) (*controlPlane, error) {
+	policy := validation.DefaultSystemPolicy()
+	policy.AllowPrivateNetworks = privateNetworks
+	validator, err := validation.NewUnifiedValidator(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	rm := runmanager.NewRunManager(validator)
+	registry := scheduler.NewRegistry()
+	leaseManager := scheduler.NewLeaseManager(60000)
+	rm.SetScheduler(registry, scheduler.NewAllocator(registry, leaseManager), leaseManager)
+	telemetryStore := api.NewTelemetryStore()
+	rm.SetTelemetryStore(telemetryStore)
+	dir, err := os.MkdirTemp("", "mcpdrill-")
+	if err != nil {
+		return nil, err
+	}
+	store, err := artifacts.NewFilesystemStore(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	rm.SetArtifactStore(store)
+
+	server := api.NewServer("127.0.0.1:0", rm)
+	server.SetRegistry(registry)
+	server.SetLeaseManager(leaseManager)
+	server.SetTelemetryStore(telemetryStore)
+	server.SetArtifactStore(store)
+	server.SetAuthConfig(&auth.Config{Mode: auth.AuthModeNone, InsecureMode: true})
+	server.SetRateLimiterConfig(&api.RateLimiterConfig{Enabled: false})
+	rm.SetAssignmentSender(api.NewServerAssignmentAdapter(server))
+	if err := server.Start(); err != nil {
+		rm.Shutdown()
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("starting control plane: %w", err)
+	}
+	cp := &controlPlane{rm: rm, server: server, artifacts: store, dir: dir}
+
+	vus := 1000
+	cp.worker, err = worker.StartRunner(ctx, worker.RunnerConfig{
+		ControlPlaneURL:   server.URL(),
+		HostInfo:          types.HostInfo{Hostname: "drill", Platform: runtime.GOOS},
+		Capacity:          types.WorkerCapacity{MaxVUs: vus, MaxConcurrentOps: vus * 10, MaxRPS: float64(vus) * 10},
+		ControlChannel:    true,
+		HeartbeatInterval: time.Second,
+		PollInterval:      100 * time.Millisecond,
+		PrivateNetworks:   privateNetworks,
+	})
+	if err != nil {
+		cp.close()
+		return nil, fmt.Errorf("starting worker: %w", err)
+	}
+	return cp, nil
+}
+
+func (cp *controlPlane) close() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if cp.worker != nil {
+		cp.worker.Close(ctx)
+	}
+	cp.server.Shutdown(ctx)
+	cp.rm.Shutdown()
+	os.RemoveAll(cp.dir)
+}
+
+// result returns the result of a run that has ended.
+func (cp *controlPlane) result(run *runmanager.RunView) (*Result, error) {
+	result := &Result{RunID: run.RunID, State: string(run.State)}
+	if run.StopReason != nil {
+		result.StopReason = run.StopReason.Reason
+	}
+	data, err := cp.artifacts.GetArtifact(run.RunID, artifacts.ArtifactTypeReport, "report.json")
+	if err != nil {
+		return result, nil
+	}
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("reading report: %w", err)
+	}
+	result.Report = &report
+	return result, nil
+}
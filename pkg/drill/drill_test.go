@@ -0,0 +1,141 @@
+package drill
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/mockserver"
+	"github.com/bc-dunia/mcpdrill/internal/validation"
+)
+
+func TestScenarioConfigValidates(t *testing.T) {
+	policy := validation.DefaultSystemPolicy()
+	policy.AllowPrivateNetworks = privateNetworks
+	validator, err := validation.NewUnifiedValidator(policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := map[string]Scenario{
+		"defaults": {TargetURL: "http://127.0.0.1:3000/mcp"},
+		"tools": {
+			TargetURL: "http://127.0.0.1:3000/mcp",
+			VUs:       2,
+			MaxVUs:    4,
+			RPS:       50,
+			ThinkTime: 10 * time.Millisecond,
+			Tools:     []Tool{{Name: "fast_echo", Arguments: map[string]any{"message": "hi"}, Weight: 3}, {Name: "calculate"}},
+		},
+		"mix": {
+			TargetURL:  "http://127.0.0.1:3000/mcp",
+			Headers:    map[string]string{"X-Tenant": "drill"},
+			Operations: []Operation{{Operation: "ping"}, {Operation: "resources_read", URI: "docs://readme", Weight: 2}},
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			config, err := scenario.Config()
+			if err != nil {
+				t.Fatalf("Config failed: %v", err)
+			}
+			if report := validator.ValidateRunConfig(config); !report.OK {
+				t.Fatalf("expected a valid run config, got %+v", report.Errors)
+			}
+		})
+	}
+
+	if _, err := (Scenario{TargetURL: "not a url"}).Config(); err == nil {
+		t.Error("expected a target URL without a host to be refused")
+	}
+}
+
+func TestScenarioConfigStages(t *testing.T) {
+	config, err := Scenario{TargetURL: "http://localhost:3000/mcp", VUs: 3, Duration: 2 * time.Second}.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parsed struct {
+		Stages []struct {
+			Stage      string `json:"stage"`
+			DurationMs int64  `json:"duration_ms"`
+			Load       struct {
+				TargetVUs int `json:"target_vus"`
+			} `json:"load"`
+		} `json:"stages"`
+	}
+	if err := json.Unmarshal(config, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		stage      string
+		durationMs int64
+		vus        int
+	}{{"preflight", 1000, 1}, {"baseline", 2000, 3}, {"ramp", 2000, 3}}
+	if len(parsed.Stages) != len(want) {
+		t.Fatalf("expected %d stages, got %d", len(want), len(parsed.Stages))
+	}
+	for i, w := range want {
+		got := parsed.Stages[i]
+		if got.Stage != w.stage || got.DurationMs != w.durationMs || got.Load.TargetVUs != w.vus {
+			t.Errorf("stage %d: expected %s for %dms at %d VUs, got %s for %dms at %d VUs",
+				i, w.stage, w.durationMs, w.vus, got.Stage, got.DurationMs, got.Load.TargetVUs)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in-process run in short mode")
+	}
+	server, cleanup := mockserver.StartTestServer()
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	result, err := Run(ctx, Scenario{
+		TargetURL: server.MCPURL(),
+		VUs:       2,
+		RPS:       20,
+		Tools:     []Tool{{Name: "fast_echo", Arguments: map[string]any{"message": "drill"}}},
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.State != "completed" {
+		t.Fatalf("expected the run to complete, got %s (%s)", result.State, result.StopReason)
+	}
+	if result.Report == nil {
+		t.Fatal("expected the run to be analyzed")
+	}
+	metrics := result.Metrics()
+	if metrics.TotalOps == 0 {
+		t.Fatal("expected operations in the report")
+	}
+	// Operations in flight when a stage ends may be cancelled.
+	if metrics.ErrorRate > 0.01 {
+		t.Errorf("expected next to no errors against the mock server, got an error rate of %g", metrics.ErrorRate)
+	}
+	if metrics.ByTool["fast_echo"] == nil {
+		t.Errorf("expected fast_echo calls in the report, got tools %v", metrics.ByTool)
+	}
+}
+
+func TestRunConfig_AbortsWhenContextDone(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in-process run in short mode")
+	}
+	server, cleanup := mockserver.StartTestServer()
+	defer cleanup()
+
+	config, err := Scenario{TargetURL: server.MCPURL(), RPS: 10, Duration: time.Minute}.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	if _, err := RunConfig(ctx, config); err != context.DeadlineExceeded {
+		t.Fatalf("expected the deadline to end the run, got %v", err)
+	}
+}
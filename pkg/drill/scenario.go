@@ -0,0 +1,244 @@
+package drill
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Scenario is a load test against one MCP server: a preflight with a
+// single VU, a baseline at VUs for Duration, then a ramp from VUs to MaxVUs
+// for another Duration.
+type Scenario struct {
+	// Name identifies the scenario in the report; it defaults to "drill".
+	Name string
+	// TargetURL is the MCP endpoint, e.g. the URL of an httptest server
+	// with "/mcp" appended.
+	TargetURL string
+	// Headers are sent with every request.
+	Headers map[string]string
+
+	// VUs is the baseline load, 1 by default.
+	VUs int
+	// MaxVUs is the load the ramp ends at, VUs by default.
+	MaxVUs int
+	// RPS caps the request rate of the baseline and ramp; 0 leaves it
+	// unlimited.
+	RPS float64
+	// Duration is how long the baseline and the ramp each last, at least
+	// and by default a second.
+	Duration time.Duration
+	// ThinkTime is the pause of each VU between operations.
+	ThinkTime time.Duration
+
+	// Operations is the operation mix; by default tools_call when Tools are
+	// given and tools_list otherwise.
+	Operations []Operation
+	// Tools are the tools tools_call operations call, by weight.
+	Tools []Tool
+
+	// MaxErrorRate stops the run once the error rate over 5 seconds
+	// exceeds it; 0 means 0.5.
+	MaxErrorRate float64
+}
+
+// Operation is an entry of a scenario's operation mix.
+type Operation struct {
+	// Operation is one of tools_list, tools_call, resources_list,
+	// resources_read, prompts_list, prompts_get and ping.
+	Operation string
+	// Weight is the operation's share of the mix, 1 by default.
+	Weight int
+	// URI is the resource resources_read reads.
+	URI string
+	// PromptName and Arguments are the prompt prompts_get gets.
+	PromptName string
+	Arguments  map[string]any
+}
+
+// Tool is a tool tools_call operations call.
+type Tool struct {
+	Name      string
+	Arguments map[string]any
+	// Weight is the tool's share of tools_call operations, 1 by default.
+	Weight int
+}
+
+// stageIDs are the IDs of the three stages a scenario runs.
+var stageIDs = [3]string{"stg_000000000001", "stg_000000000002", "stg_000000000003"}
+
+// Config returns the run config the scenario runs as. It can be saved and
+// run with the mcpdrill CLI, or adjusted and run with RunConfig.
+func (s Scenario) Config() ([]byte, error) {
+	target, err := url.Parse(s.TargetURL)
+	if err != nil || target.Hostname() == "" {
+		return nil, fmt.Errorf("invalid target URL %q", s.TargetURL)
+	}
+	name := s.Name
+	if name == "" {
+		name = "drill"
+	}
+	vus := max(s.VUs, 1)
+	maxVUs := max(s.MaxVUs, vus)
+	duration := max(s.Duration, time.Second)
+	maxErrorRate := s.MaxErrorRate
+	if maxErrorRate <= 0 {
+		maxErrorRate = 0.5
+	}
+	var rps any
+	if s.RPS > 0 {
+		rps = s.RPS
+	}
+	headers := s.Headers
+	if headers == nil {
+		headers = map[string]string{}
+	}
+	thinkTime := map[string]any{"mode": "none", "base_ms": 0, "jitter_ms": 0}
+	if s.ThinkTime > 0 {
+		thinkTime = map[string]any{"mode": "fixed", "base_ms": s.ThinkTime.Milliseconds(), "jitter_ms": 0}
+	}
+
+	operations := s.Operations
+	if len(operations) == 0 {
+		operations = []Operation{{Operation: "tools_list"}}
+		if len(s.Tools) > 0 {
+			operations = []Operation{{Operation: "tools_call"}}
+		}
+	}
+	opMix := make([]map[string]any, 0, len(operations))
+	for _, op := range operations {
+		entry := map[string]any{"operation": op.Operation, "weight": max(op.Weight, 1)}
+		if op.URI != "" {
+			entry["uri"] = op.URI
+		}
+		if op.PromptName != "" {
+			entry["prompt_name"] = op.PromptName
+		}
+		if op.Arguments != nil {
+			entry["arguments"] = op.Arguments
+		}
+		opMix = append(opMix, entry)
+	}
+	templates := make([]map[string]any, 0, len(s.Tools))
+	for i, tool := range s.Tools {
+		arguments := tool.Arguments
+		if arguments == nil {
+			arguments = map[string]any{}
+		}
+		templates = append(templates, map[string]any{
+			"template_id": fmt.Sprintf("tool_%d", i+1),
+			"tool_name":   tool.Name,
+			"weight":      max(tool.Weight, 1),
+			"arguments":   arguments,
+		})
+	}
+
+	stopConditions := []map[string]any{{
+		"id":              "max_error_rate",
+		"metric":          "error_rate",
+		"comparator":      ">",
+		"threshold":       maxErrorRate,
+		"window_ms":       5000,
+		"sustain_windows": 1,
+		"scope":           map[string]any{},
+	}}
+	stages := []map[string]any{
+		{
+			"stage_id":        stageIDs[0],
+			"stage":           "preflight",
+			"enabled":         true,
+			"duration_ms":     time.Second.Milliseconds(),
+			"load":            map[string]any{"target_vus": 1, "target_rps": rps},
+			"stop_conditions": []map[string]any{},
+		},
+		{
+			"stage_id":        stageIDs[1],
+			"stage":           "baseline",
+			"enabled":         true,
+			"duration_ms":     duration.Milliseconds(),
+			"load":            map[string]any{"target_vus": vus, "target_rps": rps},
+			"stop_conditions": stopConditions,
+		},
+		{
+			"stage_id":        stageIDs[2],
+			"stage":           "ramp",
+			"enabled":         true,
+			"duration_ms":     duration.Milliseconds(),
+			"load":            map[string]any{"target_vus": maxVUs, "target_rps": rps},
+			"stop_conditions": stopConditions,
+		},
+	}
+
+	config := map[string]any{
+		"schema_version": "run-config/v1",
+		"scenario_id":    name,
+		"target": map[string]any{
+			"kind":      "server",
+			"url":       s.TargetURL,
+			"transport": "streamable_http",
+			"headers":   headers,
+			"auth":      map[string]any{"type": "none"},
+			"identification": map[string]any{
+				"run_id_header": map[string]any{"name": "X-Test-Run-Id", "value_template": "${run_id}"},
+				"user_agent":    map[string]any{"value": "mcpdrill/1.0 (run=${run_id})"},
+			},
+			"timeouts": map[string]any{
+				"connect_timeout_ms":      5000,
+				"request_timeout_ms":      30000,
+				"stream_stall_timeout_ms": 15000,
+			},
+			"tls":             map[string]any{"verify": true, "ca_bundle_ref": nil},
+			"redirect_policy": map[string]any{"mode": "deny", "max_redirects": 3},
+		},
+		"environment": map[string]any{
+			"allowlist": map[string]any{
+				"mode":            "deny_by_default",
+				"allowed_targets": []map[string]any{{"kind": "suffix", "value": target.Hostname()}},
+			},
+			"forbidden_patterns": []string{},
+		},
+		"session_policy": map[string]any{"mode": "reuse", "pool_size": maxVUs, "ttl_ms": 60000, "max_idle_ms": 30000},
+		"workload": map[string]any{
+			"in_flight_per_vu": 1,
+			"think_time":       thinkTime,
+			"operation_mix":    opMix,
+			"tools": map[string]any{
+				"selection": map[string]any{"mode": "weighted"},
+				"templates": templates,
+			},
+			"payload_profiles": []any{},
+		},
+		"stages": stages,
+		"safety": map[string]any{
+			"ramp_by_default":        false,
+			"emergency_stop_enabled": true,
+			"worker_failure_policy":  "fail_fast",
+			"hard_caps": map[string]any{
+				"max_vus":               maxVUs,
+				"max_rps":               max(s.RPS, float64(maxVUs)*1000),
+				"max_connections":       maxVUs,
+				"max_duration_ms":       (time.Second + 2*duration + time.Minute).Milliseconds(),
+				"max_in_flight_per_vu":  1,
+				"max_telemetry_q_depth": 10000,
+			},
+			"stop_policy":             map[string]any{"mode": "drain", "drain_timeout_ms": 5000},
+			"identification_required": true,
+		},
+		"reporting": map[string]any{
+			"formats":   []string{"json"},
+			"retention": map[string]any{"raw_logs_days": 1, "metrics_days": 1, "reports_days": 1},
+			"include": map[string]any{
+				"store_raw_logs":         false,
+				"store_metrics_snapshot": false,
+				"store_event_log":        false,
+			},
+			"redaction": map[string]any{"redact_headers": []string{}},
+		},
+		"telemetry": map[string]any{
+			"structured_logs": map[string]any{"enabled": true, "sample_rate": 1.0},
+			"traces":          map[string]any{"enabled": false, "propagation": map[string]any{"accept_incoming_traceparent": false}},
+		},
+	}
+	return json.Marshal(config)
+}
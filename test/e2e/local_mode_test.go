@@ -0,0 +1,100 @@
+package e2e
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/api"
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/runmanager"
+	"github.com/bc-dunia/mcpdrill/internal/controlplane/scheduler"
+	"github.com/bc-dunia/mcpdrill/internal/mockserver"
+	"github.com/bc-dunia/mcpdrill/internal/types"
+	"github.com/bc-dunia/mcpdrill/internal/worker"
+)
+
+// TestLocalMode_EmbeddedWorkerRunsAgainstMockServer runs the control plane,
+// a worker.Runner and the mock server in one process, as --local does, and
+// checks a run's operations reach the mock server and come back as telemetry.
+func TestLocalMode_EmbeddedWorkerRunsAgainstMockServer(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping local mode test in short mode")
+	}
+	t.Setenv("MCPDRILL_TEST_TOKEN", "local-token")
+
+	mock := mockserver.New(mockserver.DefaultConfig())
+	if err := mock.Start(); err != nil {
+		t.Fatalf("Failed to start mock server: %v", err)
+	}
+	defer mock.Stop(context.Background())
+
+	rm := runmanager.NewRunManager(createTestValidator(t))
+	defer rm.Shutdown()
+	registry := scheduler.NewRegistry()
+	leaseManager := scheduler.NewLeaseManager(60000)
+	rm.SetScheduler(registry, scheduler.NewAllocator(registry, leaseManager), leaseManager)
+	telemetryStore := api.NewTelemetryStore()
+	rm.SetTelemetryStore(telemetryStore)
+
+	server := api.NewServer("127.0.0.1:0", rm)
+	server.SetRegistry(registry)
+	server.SetTelemetryStore(telemetryStore)
+	rm.SetAssignmentSender(api.NewServerAssignmentAdapter(server))
+	ConfigureTestServer(server)
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		server.Shutdown(ctx)
+		cancel()
+	}()
+
+	runner, err := worker.StartRunner(context.Background(), worker.RunnerConfig{
+		ControlPlaneURL:   server.URL(),
+		HostInfo:          types.HostInfo{Hostname: "local", Platform: "test"},
+		Capacity:          types.WorkerCapacity{MaxVUs: 10, MaxConcurrentOps: 100, MaxRPS: 100},
+		ControlChannel:    true,
+		HeartbeatInterval: 100 * time.Millisecond,
+		PollInterval:      100 * time.Millisecond,
+		PrivateNetworks:   []string{"127.0.0.0/8"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to start embedded worker: %v", err)
+	}
+	closed := false
+	defer func() {
+		if !closed {
+			runner.Close(context.Background())
+		}
+	}()
+	if registry.WorkerCount() != 1 {
+		t.Fatalf("Expected the embedded worker to be registered, got %d workers", registry.WorkerCount())
+	}
+
+	config := strings.Replace(string(validConfig), "https://test-gateway.example.com/mcp", mock.MCPURL(), 1)
+	config = strings.Replace(config, `{"kind": "suffix", "value": ".example.com"}`, `{"kind": "suffix", "value": "127.0.0.1"}`, 1)
+	runID := createRun(t, server.URL(), []byte(config))
+	startRun(t, server.URL(), runID)
+
+	deadline := time.Now().Add(15 * time.Second)
+	for telemetryStore.GetReceivedOperationCount(runID) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("No operations received from the embedded worker; run state %s", getRun(t, server.URL(), runID).State)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	stopRun(t, server.URL(), runID, "immediate")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	shipped, _ := runner.Close(ctx)
+	closed = true
+	if shipped == 0 {
+		t.Error("Expected the embedded worker to have shipped operations")
+	}
+	if runner.ActiveAssignments() != 0 {
+		t.Errorf("Expected no active assignments after Close, got %d", runner.ActiveAssignments())
+	}
+}
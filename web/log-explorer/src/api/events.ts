@@ -53,18 +53,23 @@ function createSSEHandler(
  * @param onEvent - Callback for each event
  * @param onError - Callback for errors (optional)
  * @param lastEventId - Resume from this event ID (optional)
+ * @param types - Only deliver these event types, e.g. ['STATE_TRANSITION'] (optional)
  * @returns Cleanup function to close the connection
  */
 export function subscribeToRunEvents(
   runId: string,
   onEvent: RunEventHandler,
   onError?: SSEErrorHandler,
-  lastEventId?: string
+  lastEventId?: string,
+  types?: string[]
 ): () => void {
   const params = new URLSearchParams();
   if (lastEventId) {
     params.set('cursor', lastEventId);
   }
+  if (types && types.length > 0) {
+    params.set('types', types.join(','));
+  }
   
   const url = params.toString() 
     ? `${API_BASE}/runs/${runId}/events?${params}` 
@@ -21,6 +21,7 @@ import type {
   ValidationResult,
   AgentDetail,
   ErrorSignaturesResponse,
+  TimeSeriesResponse,
 } from './types';
 
 const API_BASE = '';
@@ -140,6 +141,8 @@ function convertToBackendConfig(config: RunConfig): BackendRunConfig {
       pool_size: config.session_policy?.pool_size ?? 10,
       ttl_ms: config.session_policy?.ttl_ms ?? 60000,
       max_idle_ms: config.session_policy?.max_idle_ms ?? 30000,
+      churn_interval_ms: config.session_policy?.churn_interval_ms,
+      churn_interval_ops: config.session_policy?.churn_interval_ops,
     },
     workload: {
       in_flight_per_vu: 1,
@@ -580,6 +583,22 @@ export async function fetchErrorSignatures(runId: string): Promise<ErrorSignatur
   return response.json();
 }
 
+/**
+ * Fetch windowed throughput, error rate and latency percentiles for a run
+ */
+export async function fetchTimeSeries(
+  runId: string,
+  options: { windowMs?: number; operation?: string; toolName?: string } = {}
+): Promise<TimeSeriesResponse> {
+  const params = new URLSearchParams();
+  if (options.windowMs) params.set('window_ms', String(options.windowMs));
+  if (options.operation) params.set('operation', options.operation);
+  if (options.toolName) params.set('tool_name', options.toolName);
+  const query = params.toString();
+  const response = await fetch(`${API_BASE}/runs/${runId}/timeseries${query ? `?${query}` : ''}`);
+  return handleResponse<TimeSeriesResponse>(response, 'Failed to fetch time series');
+}
+
 export interface CloneRunResponse {
   run_id: string;
 }
@@ -1,3 +1,5 @@
+import type { FuzzMutation } from '../types';
+
 export interface BackendOperationMix {
   operation: string;
   weight: number;
@@ -14,14 +16,25 @@ export interface BackendToolTemplate {
   expects_streaming?: boolean;
 }
 
+export interface BackendStopConditionTerm {
+  metric?: string;
+  comparator?: string;
+  threshold?: number;
+  scope?: Record<string, string>;
+  all?: BackendStopConditionTerm[];
+  any?: BackendStopConditionTerm[];
+}
+
 export interface BackendStopCondition {
   id: string;
-  metric: string;
-  comparator: string;
-  threshold: number;
+  metric?: string;
+  comparator?: string;
+  threshold?: number;
   window_ms: number;
   sustain_windows: number;
   scope: Record<string, string>;
+  all?: BackendStopConditionTerm[];
+  any?: BackendStopConditionTerm[];
 }
 
 export interface BackendRunConfig {
@@ -64,6 +77,15 @@ export interface BackendRunConfig {
       mode: string;
       max_redirects: number;
     };
+    backend_id_header?: string;
+    stream_resumption?: {
+      max_attempts: number;
+      backoff_ms?: number;
+    };
+    progress_tokens?: boolean;
+    server_requests?: {
+      responses?: Record<string, Record<string, unknown>>;
+    };
   };
   environment: {
     allowlist: {
@@ -77,6 +99,8 @@ export interface BackendRunConfig {
     pool_size: number | null;
     ttl_ms: number | null;
     max_idle_ms: number | null;
+    churn_interval_ms?: number | null;
+    churn_interval_ops?: number | null;
   };
   workload: {
     in_flight_per_vu: number;
@@ -94,16 +118,83 @@ export interface BackendRunConfig {
       templates: BackendToolTemplate[];
     };
     payload_profiles: unknown[];
+    result_capture?: {
+      summarize_above_bytes?: number;
+      fingerprint_max_depth?: number;
+    };
+    request_ids?: {
+      format?: 'string' | 'integer' | 'long' | 'mixed';
+      long_length?: number;
+      duplicate_every?: number;
+    };
+    fuzz?: {
+      ratio: number;
+      mutations?: FuzzMutation[];
+      oversized_string_bytes?: number;
+      nesting_depth?: number;
+    };
+    cancellation_rate?: number;
+    cancellation_max_delay_ms?: number;
+    cancellation_grace_ms?: number;
+    throttle?: {
+      policy: 'ignore' | 'honor_retry_after' | 'exponential_backoff';
+      base_backoff_ms?: number;
+      max_backoff_ms?: number;
+    };
+    retry?: {
+      max_retries: number;
+      backoff_ms?: number;
+      max_backoff_ms?: number;
+      retry_on?: Array<'connect' | 'http_5xx' | 'stream_stall' | 'timeout' | 'rate_limited'>;
+    };
+  };
+  generator_groups?: Array<{
+    name: string;
+    weight: number;
+    in_flight_per_vu?: number;
+    think_time?: {
+      mode: string;
+      base_ms: number;
+      jitter_ms: number;
+    };
+    operation_mix?: BackendOperationMix[];
+  }>;
+  targets?: Array<{
+    name: string;
+    weight: number;
+    url: string;
+    headers?: Record<string, string>;
+    auth?: {
+      type: string;
+      tokens?: string[];
+    };
+    backend_id_header?: string;
+  }>;
+  placement?: {
+    require?: string[];
+    spread_across?: string;
   };
   stages: Array<{
     stage_id: string;
     stage: string;
     enabled: boolean;
     duration_ms: number;
+    warmup_ms?: number;
     load: {
       target_vus: number;
       target_rps: number | null;
     };
+    ramp?: {
+      mode: 'step' | 'adaptive';
+      step_every_ms: number;
+      step_vus: number;
+      step_rps: number | null;
+      max_vus: number;
+      max_rps: number | null;
+      hold_ms: number;
+      resolution_vus?: number;
+      guardrails?: BackendStopConditionTerm[];
+    } | null;
     stop_conditions: BackendStopCondition[];
   }>;
   safety: {
@@ -117,6 +208,7 @@ export interface BackendRunConfig {
       max_duration_ms: number;
       max_in_flight_per_vu: number;
       max_telemetry_q_depth: number;
+      max_total_ops?: number;
     };
     stop_policy: {
       mode: string;
@@ -136,6 +228,15 @@ export interface BackendRunConfig {
       store_metrics_snapshot: boolean;
       store_event_log: boolean;
     };
+    latency_buckets?: {
+      boundaries_ms?: number[];
+      deadlines?: Array<{
+        name?: string;
+        operation?: string;
+        tool_name?: string;
+        deadline_ms: number;
+      }>;
+    };
     redaction: {
       redact_headers: string[];
     };
@@ -297,6 +398,30 @@ export interface ErrorSignature {
   affected_operations: string[];
   affected_tools: string[];
   sample_error: string;
+  error_type?: string;
+  error_code?: string;
+  jsonrpc_code?: number;
+  example_op_ids?: string[];
+}
+
+export interface WindowPoint {
+  timestamp: number;
+  ops: number;
+  failures: number;
+  rps: number;
+  error_rate: number;
+  latency_p50: number;
+  latency_p95: number;
+  latency_p99: number;
+}
+
+export interface TimeSeriesResponse {
+  run_id: string;
+  window_ms: number;
+  total: WindowPoint[];
+  by_operation?: Record<string, WindowPoint[]>;
+  by_tool?: Record<string, WindowPoint[]>;
+  truncated?: boolean;
 }
 
 export interface ErrorSignaturesResponse {
@@ -5,6 +5,8 @@ export interface OperationLog {
   stage: string;
   stage_id: string;
   worker_id: string;
+  generator_group?: string;
+  target?: string;
   vu_id: string;
   session_id: string;
   operation: string;
@@ -15,8 +17,25 @@ export interface OperationLog {
   error_code: string;
   stream?: StreamInfo;
   token_index?: number;
+  request_id?: {
+    kind: 'string' | 'integer' | 'long';
+    duplicate?: boolean;
+    echo_type_mismatch?: boolean;
+  };
+  fuzz_mutation?: FuzzMutation;
+  warmup?: boolean;
+  op_id?: string;
+  error_message?: string;
+  jsonrpc_error_code?: number;
 }
 
+export type FuzzMutation =
+  | 'wrong_type'
+  | 'boundary_number'
+  | 'oversized_string'
+  | 'missing_required'
+  | 'deep_nesting';
+
 export interface LogQueryResponse {
   run_id: string;
   total: number;
@@ -125,6 +144,8 @@ export interface SessionPolicy {
   pool_size?: number;
   ttl_ms?: number;
   max_idle_ms?: number;
+  churn_interval_ms?: number;
+  churn_interval_ops?: number;
 }
 
 export interface HardCaps {
@@ -153,6 +174,7 @@ export interface ServerTelemetryConfig {
 export interface AgentInfo {
   agent_id: string;
   pair_key: string;
+  pair_keys?: string[];
   hostname: string;
   os: string;
   arch: string;
@@ -160,6 +182,12 @@ export interface AgentInfo {
   online: boolean;
   registered_at: string;
   last_seen: string;
+  clock_offset_ms?: number;
+  clock_drift_ppm?: number;
+  clock_skew_ms?: number;
+  log_entries_dropped?: number;
+  last_seq?: number;
+  missing_samples?: number;
 }
 
 export interface RunConfig {
@@ -341,6 +369,9 @@ export interface ToolMetrics {
 // Aggregated metrics response with per-tool breakdown
 export interface AggregatedMetrics extends LiveMetrics {
   by_tool?: Record<string, ToolMetrics>;
+  by_group?: Record<string, ToolMetrics>;
+  by_target?: Record<string, ToolMetrics>;
+  by_worker_label?: Record<string, Record<string, ToolMetrics>>;
 }
 
 // Tool result content types
@@ -433,12 +464,14 @@ export interface StabilityMetrics {
 // Server Telemetry Types (from mcpdrill-agent)
 export interface ServerHostMetrics {
   cpu_percent: number;
+  cpu_cores?: number;
   load_avg_1?: number;
   load_avg_5?: number;
   load_avg_15?: number;
   mem_total: number;
   mem_used: number;
   mem_available?: number;
+  gpus?: ServerGPUMetrics[];
 }
 
 export interface ServerProcessMetrics {
@@ -449,12 +482,56 @@ export interface ServerProcessMetrics {
   num_threads?: number;
   num_fds?: number;
   open_connections?: number;
+  gpu_mem_used?: number;
+  pids?: number[];
+}
+
+export interface ServerGPUMetrics {
+  index: number;
+  name?: string;
+  utilization_percent: number;
+  mem_used: number;
+  mem_total: number;
+  temperature_c?: number;
+  power_watts?: number;
+}
+
+export interface ServerContainerMetrics {
+  cgroup_version: number;
+  id?: string;
+  cpu_limit_cores?: number;
+  cpu_usage_cores: number;
+  cpu_throttled_percent: number;
+  cpu_throttled_periods?: number;
+  cpu_throttled_usec?: number;
+  mem_usage: number;
+  mem_limit?: number;
+  oom_kills?: number;
 }
 
 export interface ServerMetricsSample {
   timestamp: number;
   host?: ServerHostMetrics;
   process?: ServerProcessMetrics;
+  container?: ServerContainerMetrics;
+  targets?: ServerTargetMetrics[];
+  clock_skew_ms?: number;
+  seq?: number;
+  scraped?: ServerScrapedMetric[];
+}
+
+export interface ServerScrapedMetric {
+  name: string;
+  labels?: Record<string, string>;
+  type?: string;
+  value: number;
+}
+
+export interface ServerTargetMetrics {
+  label: string;
+  pair_key?: string;
+  process?: ServerProcessMetrics;
+  container?: ServerContainerMetrics;
 }
 
 export interface ServerMetricsAggregated {
@@ -465,10 +542,140 @@ export interface ServerMetricsAggregated {
   mem_avg: number;
 }
 
+export interface ServerNodeSeries {
+  agent_id: string;
+  hostname: string;
+  samples: ServerMetricsSample[];
+}
+
+export interface ServerNodeMetrics {
+  agent_id: string;
+  hostname: string;
+  samples: number;
+  cpu_cores?: number;
+  cpu_avg_percent: number;
+  cpu_max_percent: number;
+  cores_used_avg: number;
+  cores_used_max: number;
+  mem_used_max: number;
+  mem_total?: number;
+  skewed_samples?: number;
+  max_clock_skew_ms?: number;
+  missing_samples?: number;
+  container?: ServerNodeContainerMetrics;
+  gpu?: ServerNodeGPUMetrics;
+  scraped?: ServerNodeScrapedSeries[];
+  series?: ServerNodePoint[];
+}
+
+export interface ServerNodePoint {
+  timestamp: number;
+  cpu_percent: number;
+  mem_used: number;
+  container_cpu_percent?: number;
+  process_cpu_percent?: number;
+  process_rss?: number;
+  process_fds?: number;
+  process_connections?: number;
+}
+
+export interface ServerNodeScrapedSeries {
+  series: string;
+  type?: string;
+  samples: number;
+  distribution?: boolean;
+  last: number;
+  min: number;
+  max: number;
+  avg: number;
+  rate_per_sec?: number;
+  mean?: number;
+}
+
+export interface ServerNodeGPUMetrics {
+  gpus: number;
+  util_avg_percent: number;
+  util_max_percent: number;
+  mem_used_max: number;
+  mem_total: number;
+  temp_max_c?: number;
+  process_mem_max?: number;
+}
+
+export interface ServerNodeContainerMetrics {
+  cpu_limit_cores?: number;
+  cpu_usage_cores_max: number;
+  throttled_percent_avg: number;
+  throttled_percent_max: number;
+  mem_usage_max: number;
+  mem_limit?: number;
+  mem_limit_percent_max?: number;
+  oom_kills?: number;
+}
+
+export interface ServerClusterPoint {
+  timestamp: number;
+  nodes: number;
+  cores_used: number;
+  mem_used_max: number;
+}
+
+export interface ServerClusterMetrics {
+  nodes: number;
+  total_cores?: number;
+  cores_used_avg: number;
+  cores_used_peak: number;
+  node_mem_used_peak: number;
+  node_mem_peak_host?: string;
+  bucket_ms: number;
+  series: ServerClusterPoint[];
+}
+
+export interface ServerBottleneck {
+  agent_id: string;
+  hostname: string;
+  start: number;
+  end: number;
+  peak_cpu_percent: number;
+  baseline_p95_ms: number;
+  peak_p95_ms: number;
+}
+
+export interface ServerMetricsRollup {
+  nodes: ServerNodeMetrics[];
+  cluster: ServerClusterMetrics;
+  // Present in run reports
+  bottlenecks?: ServerBottleneck[];
+}
+
+export interface LoadPoint {
+  timestamp: number;
+  ops: number;
+  failures?: number;
+  stalls?: number;
+  latency_p95_ms: number;
+}
+
+// Top-level fields of a run's report.json
+export interface RunReport {
+  run_id: string;
+  scenario_id: string;
+  start_time: number;
+  end_time: number;
+  duration_ms: number;
+  stop_reason: string;
+  // Client load per 10 second bucket
+  load?: LoadPoint[];
+  server_metrics?: ServerMetricsRollup;
+}
+
 export interface ServerMetricsResponse {
   run_id: string;
   samples: ServerMetricsSample[];
   aggregated?: ServerMetricsAggregated;
+  // Present with by_node=true
+  nodes?: ServerNodeSeries[];
+  rollup?: ServerMetricsRollup;
 }
 
 export interface ServerMetricsDataPoint {